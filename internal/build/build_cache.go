@@ -0,0 +1,312 @@
+package build
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+	"github.com/tilt-dev/wmclient/pkg/dirs"
+
+	"github.com/tilt-dev/tilt/internal/dockerfile"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+const buildCacheFile = "build-cache.json"
+
+// Keep the cache file from growing forever.
+// When we go over, evict the least-recently-used entries.
+const buildCacheMaxEntries = 1000
+
+// BuildCache remembers which image a set of build inputs produced.
+//
+// The key is a hash of the Dockerfile, the build options, and the contents
+// of every file in the build context that isn't excluded by .dockerignore.
+// So if you switch branches (or check out a commit) that touches files
+// without changing what goes into the image, we can re-use the image
+// we built last time instead of sending the context to Docker again.
+//
+// The cache is persisted in the Tilt dev dir, so it survives restarts.
+type BuildCache struct {
+	path string
+
+	mu      sync.Mutex
+	loaded  bool
+	entries map[string]buildCacheEntry
+}
+
+type buildCacheEntry struct {
+	Digest   digest.Digest `json:"digest"`
+	LastUsed time.Time     `json:"lastUsed"`
+}
+
+type buildCacheFileContents struct {
+	Entries map[string]buildCacheEntry `json:"entries"`
+}
+
+func NewBuildCache(dir *dirs.TiltDevDir) *BuildCache {
+	path, err := dir.Abs(buildCacheFile)
+	if err != nil {
+		// Fall back to an in-memory cache.
+		path = ""
+	}
+	return &BuildCache{path: path}
+}
+
+// A cache that only lives as long as the process.
+func NewMemoryBuildCache() *BuildCache {
+	return &BuildCache{}
+}
+
+// Returns the image digest last built from the inputs with this key.
+func (c *BuildCache) Get(key string) (digest.Digest, bool) {
+	if c == nil {
+		return "", false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.loadLocked()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+	return entry.Digest, true
+}
+
+// Records that the inputs with this key produced the given image digest.
+func (c *BuildCache) Put(key string, dig digest.Digest) error {
+	if c == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.loadLocked()
+
+	c.entries[key] = buildCacheEntry{Digest: dig, LastUsed: time.Now()}
+	c.evictLocked()
+	return c.saveLocked()
+}
+
+// Removes an entry, e.g., because the image it points to was pruned.
+func (c *BuildCache) Delete(key string) error {
+	if c == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.loadLocked()
+
+	if _, ok := c.entries[key]; !ok {
+		return nil
+	}
+	delete(c.entries, key)
+	return c.saveLocked()
+}
+
+func (c *BuildCache) loadLocked() {
+	if c.loaded {
+		return
+	}
+	c.loaded = true
+	c.entries = make(map[string]buildCacheEntry)
+	if c.path == "" {
+		return
+	}
+
+	contents, err := os.ReadFile(c.path)
+	if err != nil {
+		return
+	}
+
+	var decoded buildCacheFileContents
+	err = json.Unmarshal(contents, &decoded)
+	if err != nil {
+		// A corrupt cache is the same as an empty cache.
+		return
+	}
+	for k, v := range decoded.Entries {
+		c.entries[k] = v
+	}
+}
+
+func (c *BuildCache) evictLocked() {
+	if len(c.entries) <= buildCacheMaxEntries {
+		return
+	}
+
+	keys := make([]string, 0, len(c.entries))
+	for k := range c.entries {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return c.entries[keys[i]].LastUsed.Before(c.entries[keys[j]].LastUsed)
+	})
+	for _, k := range keys[:len(keys)-buildCacheMaxEntries] {
+		delete(c.entries, k)
+	}
+}
+
+func (c *BuildCache) saveLocked() error {
+	if c.path == "" {
+		return nil
+	}
+
+	contents, err := json.Marshal(buildCacheFileContents{Entries: c.entries})
+	if err != nil {
+		return errors.Wrap(err, "build cache")
+	}
+
+	err = os.MkdirAll(filepath.Dir(c.path), os.FileMode(0755))
+	if err != nil {
+		return errors.Wrap(err, "build cache")
+	}
+
+	// Write to a temp file and rename, so that a concurrent Tilt
+	// never reads a half-written cache.
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), buildCacheFile+".*")
+	if err != nil {
+		return errors.Wrap(err, "build cache")
+	}
+	_, err = tmp.Write(contents)
+	closeErr := tmp.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), c.path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return errors.Wrap(err, "build cache")
+	}
+	return nil
+}
+
+// Returns whether the result of building this spec is fully
+// determined by the inputs we know how to hash.
+func canCacheBuild(spec v1alpha1.DockerImageSpec) bool {
+	// The user explicitly asked for fresh base images.
+	if spec.Pull {
+		return false
+	}
+
+	// Secrets are read from outside the build context.
+	if len(spec.Secrets) > 0 {
+		return false
+	}
+
+	return spec.Context != ""
+}
+
+// Computes a key over all the inputs to a Docker build.
+//
+// The spec should already have its image dependencies injected, so that
+// a change to a base image changes the Dockerfile we hash.
+func buildCacheKey(spec v1alpha1.DockerImageSpec, labels dockerfile.Labels, filter model.PathMatcher) (string, error) {
+	if filter == nil {
+		filter = model.EmptyMatcher
+	}
+
+	h := sha256.New()
+
+	// Everything that isn't the contents of the context.
+	spec.ImageMaps = nil
+	spec.ClusterNeeds = ""
+
+	// A no-cache build produces the image that these inputs should produce,
+	// so it replaces the entry of a normal build.
+	spec.NoCache = false
+	opts, err := json.Marshal(struct {
+		Spec   v1alpha1.DockerImageSpec
+		Labels dockerfile.Labels
+	}{spec, labels})
+	if err != nil {
+		return "", errors.Wrap(err, "build cache key")
+	}
+	_, _ = h.Write(opts)
+	_, _ = h.Write([]byte{0})
+
+	contextDir := spec.Context
+	err = filepath.Walk(contextDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return errors.Wrapf(err, "error walking to %s", path)
+		}
+
+		matches, err := filter.Matches(path)
+		if err != nil {
+			return err
+		}
+		if matches {
+			if info.IsDir() && path != contextDir {
+				shouldSkip, err := filter.MatchesEntireDir(path)
+				if err != nil {
+					return err
+				}
+				if shouldSkip {
+					return filepath.SkipDir
+				}
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(contextDir, path)
+		if err != nil {
+			return err
+		}
+
+		content := ""
+		mode := info.Mode()
+		switch {
+		case mode&os.ModeSymlink != 0:
+			content, err = os.Readlink(path)
+			if err != nil {
+				return err
+			}
+		case mode.IsRegular():
+			content, err = hashFile(path)
+			if err != nil {
+				return err
+			}
+		}
+
+		// Only the bits that Docker copies into the image matter,
+		// not timestamps.
+		_, _ = fmt.Fprintf(h, "%s\x00%o\x00%s\n", filepath.ToSlash(rel), mode.Type()|mode.Perm(), content)
+		return nil
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "build cache key")
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	h := sha256.New()
+	_, err = io.Copy(h, f)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,136 @@
+package build
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tilt-dev/wmclient/pkg/dirs"
+
+	"github.com/tilt-dev/tilt/internal/docker"
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestBuildCacheKeyIgnoresTimestamps(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.WriteFile("main.go", "package main")
+	spec := v1alpha1.DockerImageSpec{DockerfileContents: "FROM alpine", Context: f.Path()}
+
+	key1, err := buildCacheKey(spec, nil, model.EmptyMatcher)
+	require.NoError(t, err)
+
+	later := time.Now().Add(time.Hour)
+	require.NoError(t, os.Chtimes(f.JoinPath("main.go"), later, later))
+	key2, err := buildCacheKey(spec, nil, model.EmptyMatcher)
+	require.NoError(t, err)
+	assert.Equal(t, key1, key2)
+
+	f.WriteFile("main.go", "package main\n")
+	key3, err := buildCacheKey(spec, nil, model.EmptyMatcher)
+	require.NoError(t, err)
+	assert.NotEqual(t, key1, key3)
+}
+
+func TestBuildCacheKeyIgnoresFilteredFiles(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.WriteFile("main.go", "package main")
+	spec := v1alpha1.DockerImageSpec{DockerfileContents: "FROM alpine", Context: f.Path()}
+	filter := model.NewRelativeFileOrChildMatcher(f.Path(), "tmp")
+
+	key1, err := buildCacheKey(spec, nil, filter)
+	require.NoError(t, err)
+
+	f.WriteFile("tmp/scratch.txt", "hello")
+	key2, err := buildCacheKey(spec, nil, filter)
+	require.NoError(t, err)
+	assert.Equal(t, key1, key2)
+
+	spec.Args = []string{"VERSION=2"}
+	key3, err := buildCacheKey(spec, nil, filter)
+	require.NoError(t, err)
+	assert.NotEqual(t, key1, key3)
+}
+
+func TestBuildCachePersists(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	dir := dirs.NewTiltDevDirAt(f.Path())
+	dig := digest.Digest(docker.ExampleBuildSHA1)
+
+	require.NoError(t, NewBuildCache(dir).Put("key", dig))
+
+	actual, ok := NewBuildCache(dir).Get("key")
+	require.True(t, ok)
+	assert.Equal(t, dig, actual)
+
+	_, ok = NewBuildCache(dir).Get("other-key")
+	assert.False(t, ok)
+}
+
+func TestBuildImageSkipsUnchangedInputs(t *testing.T) {
+	f := newFakeDockerBuildFixture(t)
+	f.WriteFile("main.go", "package main")
+	spec := v1alpha1.DockerImageSpec{DockerfileContents: "FROM alpine", Context: f.Path()}
+
+	_, _, err := f.b.BuildImage(f.ctx, f.ps, refSetFromString("gcr.io/foo/my-app"),
+		spec, nil, nil, model.EmptyMatcher)
+	require.NoError(t, err)
+	require.Equal(t, 1, f.fakeDocker.BuildCount)
+
+	// The image was pruned, so we need to build again.
+	_, _, err = f.b.BuildImage(f.ctx, f.ps, refSetFromString("gcr.io/foo/my-app"),
+		spec, nil, nil, model.EmptyMatcher)
+	require.NoError(t, err)
+	require.Equal(t, 2, f.fakeDocker.BuildCount)
+
+	f.fakeDocker.Images[docker.ExampleBuildSHA1] = types.ImageInspect{ID: docker.ExampleBuildSHA1}
+	refs, stages, err := f.b.BuildImage(f.ctx, f.ps, refSetFromString("gcr.io/foo/my-app"),
+		spec, nil, nil, model.EmptyMatcher)
+	require.NoError(t, err)
+	assert.Equal(t, 2, f.fakeDocker.BuildCount)
+	assert.Equal(t, "gcr.io/foo/my-app:tilt-11cd0b38bc3ceb95", refs.LocalRef.String())
+	require.Len(t, stages, 1)
+	assert.True(t, stages[0].Cached)
+
+	f.WriteFile("main.go", "package main\n")
+	_, _, err = f.b.BuildImage(f.ctx, f.ps, refSetFromString("gcr.io/foo/my-app"),
+		spec, nil, nil, model.EmptyMatcher)
+	require.NoError(t, err)
+	assert.Equal(t, 3, f.fakeDocker.BuildCount)
+}
+
+func TestBuildImageNoCacheSkipsBuildCache(t *testing.T) {
+	f := newFakeDockerBuildFixture(t)
+	f.WriteFile("main.go", "package main")
+	spec := v1alpha1.DockerImageSpec{DockerfileContents: "FROM alpine", Context: f.Path()}
+
+	_, _, err := f.b.BuildImage(f.ctx, f.ps, refSetFromString("gcr.io/foo/my-app"),
+		spec, nil, nil, model.EmptyMatcher)
+	require.NoError(t, err)
+	require.Equal(t, 1, f.fakeDocker.BuildCount)
+	f.fakeDocker.Images[docker.ExampleBuildSHA1] = types.ImageInspect{ID: docker.ExampleBuildSHA1}
+
+	spec.NoCache = true
+	_, stages, err := f.b.BuildImage(f.ctx, f.ps, refSetFromString("gcr.io/foo/my-app"),
+		spec, nil, nil, model.EmptyMatcher)
+	require.NoError(t, err)
+	assert.Equal(t, 2, f.fakeDocker.BuildCount)
+	assert.True(t, f.fakeDocker.BuildOptions.NoCache)
+	for _, stage := range stages {
+		assert.False(t, stage.Cached)
+	}
+
+	// The no-cache build refreshed the entry for these inputs.
+	spec.NoCache = false
+	_, stages, err = f.b.BuildImage(f.ctx, f.ps, refSetFromString("gcr.io/foo/my-app"),
+		spec, nil, nil, model.EmptyMatcher)
+	require.NoError(t, err)
+	assert.Equal(t, 2, f.fakeDocker.BuildCount)
+	require.Len(t, stages, 1)
+	assert.True(t, stages[0].Cached)
+}
@@ -0,0 +1,42 @@
+package build
+
+import (
+	"context"
+	"os/exec"
+	"syscall"
+
+	"github.com/tilt-dev/tilt/pkg/procutil"
+)
+
+// Runs a build command, and kills it and any processes it started when
+// the context is canceled (e.g., because a newer change superseded the build).
+//
+// exec.CommandContext only kills the command itself, so a build script
+// that shells out to `docker build` would keep building.
+func runBuildCmd(ctx context.Context, cmd *exec.Cmd) error {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	procutil.SetOptNewProcessGroup(cmd.SysProcAttr)
+
+	err := cmd.Start()
+	if err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			procutil.KillProcessGroup(cmd)
+		case <-done:
+		}
+	}()
+
+	err = cmd.Wait()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
@@ -0,0 +1,340 @@
+package build
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/docker/cli/cli/connhelper/commandconn"
+	"github.com/docker/cli/opts"
+	"github.com/docker/distribution/reference"
+	bkclient "github.com/moby/buildkit/client"
+	"github.com/moby/buildkit/client/llb"
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/session/auth/authprovider"
+	"github.com/moby/buildkit/session/filesync"
+	"github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+	ktypes "k8s.io/apimachinery/pkg/types"
+
+	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/docker/buildkit"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// The exporter response key with the digest of the pushed image manifest.
+const exporterImageDigest = "containerimage.digest"
+
+// The subset of the BuildKit client that we build with.
+type buildkitSolver interface {
+	Solve(ctx context.Context, def *llb.Definition, opt bkclient.SolveOpt, statusChan chan *bkclient.SolveStatus) (*bkclient.SolveResponse, error)
+	Close() error
+}
+
+type buildkitDialer func(ctx context.Context, conn v1alpha1.BuildKitConnection, kubeContext string) (buildkitSolver, error)
+
+// Builds Dockerfiles on a remote BuildKit daemon, and pushes them to the registry.
+//
+// The daemon can't load images into a local Docker daemon, so we push directly
+// from BuildKit as part of the build, the same way OCIArtifactBuilder does.
+type BuildKitBuilder struct {
+	dial buildkitDialer
+}
+
+func NewBuildKitBuilder() *BuildKitBuilder {
+	return &BuildKitBuilder{dial: dialBuildKit}
+}
+
+// Whether images for this cluster are built on a remote BuildKit daemon.
+func usesRemoteBuildKit(cluster *v1alpha1.Cluster) bool {
+	return cluster != nil && cluster.Spec.BuildKit != nil
+}
+
+func (b *BuildKitBuilder) BuildImage(ctx context.Context, ps *PipelineState, refs container.RefSet,
+	spec v1alpha1.DockerImageSpec,
+	cluster *v1alpha1.Cluster,
+	imageMaps map[ktypes.NamespacedName]*v1alpha1.ImageMap,
+	filter model.PathMatcher) (container.TaggedRefs, []v1alpha1.DockerImageStageStatus, error) {
+	spec = InjectClusterPlatform(spec, cluster)
+	spec, err := InjectImageDependencies(spec, imageMaps)
+	if err != nil {
+		return container.TaggedRefs{}, nil, err
+	}
+
+	conn := *cluster.Spec.BuildKit
+	platformSuffix := ""
+	if spec.Platform != "" {
+		platformSuffix = fmt.Sprintf(" for platform %s", spec.Platform)
+	}
+	logger.Get(ctx).Infof("Building Dockerfile%s on BuildKit at %s:\n%s\n",
+		platformSuffix, conn.Address, indent(spec.DockerfileContents, "  "))
+	if len(spec.ExtraTags) > 0 {
+		logger.Get(ctx).Infof("Skipping extra_tag: the image is only pushed to the registry, not stored locally")
+	}
+
+	ps.StartBuildStep(ctx, "Building image")
+	ctx = ps.AttachLogger(ctx)
+
+	// We don't know the image digest until BuildKit pushes it, so tag
+	// with the build time, and pin the refs to the digest afterwards.
+	tagged, err := refs.AddTagSuffix(fmt.Sprintf("tilt-build-%d", ps.c.Now().Unix()))
+	if err != nil {
+		return container.TaggedRefs{}, nil, errors.Wrap(err, "buildkit")
+	}
+
+	c, err := b.dial(ctx, conn, k8sConnStatus(cluster).Context)
+	if err != nil {
+		return container.TaggedRefs{}, nil, errors.Wrapf(err, "connecting to BuildKit at %s", conn.Address)
+	}
+	defer func() {
+		_ = c.Close()
+	}()
+
+	dig, stages, err := b.solve(ctx, c, spec, filter, tagged.LocalRef)
+	if err != nil {
+		return container.TaggedRefs{}, stages, err
+	}
+	if dig == "" {
+		return tagged, stages, nil
+	}
+
+	result, err := pinToDigest(tagged, dig)
+	if err != nil {
+		return container.TaggedRefs{}, stages, errors.Wrap(err, "buildkit")
+	}
+	return result, stages, nil
+}
+
+// Runs the Dockerfile frontend on the daemon, streaming its progress to the logs.
+//
+// Returns the digest of the pushed image, if the daemon reported one.
+func (b *BuildKitBuilder) solve(ctx context.Context, c buildkitSolver, spec v1alpha1.DockerImageSpec, filter model.PathMatcher, ref reference.NamedTagged) (digest.Digest, []v1alpha1.DockerImageStageStatus, error) {
+	dockerfileDir, err := writeTempDockerfileSyncdir(spec.DockerfileContents)
+	if err != nil {
+		return "", nil, err
+	}
+	defer func() {
+		_ = os.RemoveAll(dockerfileDir)
+	}()
+
+	attachables, err := buildkitSessionAttachables(ctx, spec, toSyncedDirs(spec.Context, dockerfileDir, filter))
+	if err != nil {
+		return "", nil, err
+	}
+
+	opt := bkclient.SolveOpt{
+		Frontend:      "dockerfile.v0",
+		FrontendAttrs: buildkitFrontendAttrs(spec),
+		Session:       attachables,
+
+		// Lets the daemon reuse the context it pulled on the last build,
+		// so that we only send the files that changed.
+		SharedKey: spec.Context,
+
+		Exports: []bkclient.ExportEntry{
+			{
+				Type: bkclient.ExporterImage,
+				Attrs: map[string]string{
+					"name": ref.String(),
+					"push": "true",
+				},
+			},
+		},
+	}
+	for _, cacheFrom := range spec.CacheFrom {
+		opt.CacheImports = append(opt.CacheImports, bkclient.CacheOptionsEntry{
+			Type:  "registry",
+			Attrs: map[string]string{"ref": cacheFrom},
+		})
+	}
+
+	statusCh := make(chan *bkclient.SolveStatus)
+	printer := newBuildkitPrinter(logger.Get(ctx))
+	printer.onProgress = stageProgressFromContext(ctx)
+	printDone := make(chan error, 1)
+	go func() {
+		var printErr error
+		for status := range statusCh {
+			if printErr == nil {
+				printErr = printer.parseAndPrint(solveStatusToVertexes(status))
+			}
+		}
+		printDone <- printErr
+	}()
+
+	// Solve closes the status channel when it's done.
+	resp, err := c.Solve(ctx, nil, opt, statusCh)
+	printErr := <-printDone
+	stages := printer.toStageStatuses()
+	if err != nil {
+		return "", stages, errors.New(cleanupDockerBuildError(err.Error()))
+	}
+	if printErr != nil {
+		return "", stages, printErr
+	}
+	return digest.Digest(resp.ExporterResponse[exporterImageDigest]), stages, nil
+}
+
+// Serves the build context, registry credentials, secrets, and ssh agents
+// to the daemon.
+func buildkitSessionAttachables(ctx context.Context, spec v1alpha1.DockerImageSpec, syncedDirs []filesync.SyncedDir) ([]session.Attachable, error) {
+	result := []session.Attachable{
+		filesync.NewFSSyncProvider(syncedDirs),
+		authprovider.NewDockerAuthProvider(logger.Get(ctx).Writer(logger.InfoLvl)),
+	}
+
+	if len(spec.Secrets) > 0 {
+		ss, err := buildkit.ParseSecretSpecs(spec.Secrets)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not parse secret: %v", spec.Secrets)
+		}
+		result = append(result, ss)
+	}
+
+	if len(spec.SSHAgentConfigs) > 0 {
+		sshp, err := buildkit.ParseSSHSpecs(spec.SSHAgentConfigs)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not parse ssh: %v", spec.SSHAgentConfigs)
+		}
+		result = append(result, sshp)
+	}
+	return result, nil
+}
+
+// Translates the spec into options for the Dockerfile frontend,
+// the same way `docker build` does.
+func buildkitFrontendAttrs(spec v1alpha1.DockerImageSpec) map[string]string {
+	attrs := map[string]string{
+		"filename": DockerfileName,
+	}
+	if spec.Target != "" {
+		attrs["target"] = spec.Target
+	}
+	if spec.Platform != "" {
+		attrs["platform"] = spec.Platform
+	}
+	if spec.Network != "" {
+		attrs["force-network-mode"] = spec.Network
+	}
+	if spec.Pull {
+		attrs["image-resolve-mode"] = "pull"
+	}
+	if spec.NoCache {
+		attrs["no-cache"] = ""
+	}
+
+	for k, v := range opts.ConvertKVStringsToMapWithNil(spec.Args) {
+		// Like the Docker CLI, an arg without a value comes from the environment.
+		if v == nil {
+			val, ok := os.LookupEnv(k)
+			if !ok {
+				continue
+			}
+			v = &val
+		}
+		attrs["build-arg:"+k] = *v
+	}
+	return attrs
+}
+
+func solveStatusToVertexes(status *bkclient.SolveStatus) ([]*vertex, []*vertexLog, []*vertexStatus) {
+	vertexes := []*vertex{}
+	logs := []*vertexLog{}
+	statuses := []*vertexStatus{}
+
+	for _, v := range status.Vertexes {
+		started := v.Started != nil
+		completed := v.Completed != nil
+		duration := time.Duration(0)
+		if started && completed {
+			duration = v.Completed.Sub(*v.Started)
+		}
+		vertexes = append(vertexes, &vertex{
+			digest:        v.Digest,
+			name:          v.Name,
+			error:         v.Error,
+			started:       started,
+			completed:     completed,
+			cached:        v.Cached,
+			duration:      duration,
+			startedTime:   v.Started,
+			completedTime: v.Completed,
+		})
+	}
+	for _, l := range status.Logs {
+		logs = append(logs, &vertexLog{
+			vertex: l.Vertex,
+			msg:    l.Data,
+		})
+	}
+	for _, s := range status.Statuses {
+		statuses = append(statuses, &vertexStatus{
+			vertex:    s.Vertex,
+			id:        s.ID,
+			total:     s.Total,
+			current:   s.Current,
+			timestamp: s.Timestamp,
+		})
+	}
+	return vertexes, logs, statuses
+}
+
+// Pins both refs to the digest that BuildKit pushed, so that we deploy
+// exactly the image we built.
+func pinToDigest(refs container.TaggedRefs, dig digest.Digest) (container.TaggedRefs, error) {
+	localRef, err := withTagAndDigest(reference.TrimNamed(refs.LocalRef), refs.LocalRef.Tag(), dig)
+	if err != nil {
+		return container.TaggedRefs{}, err
+	}
+	clusterRef, err := withTagAndDigest(reference.TrimNamed(refs.ClusterRef), refs.ClusterRef.Tag(), dig)
+	if err != nil {
+		return container.TaggedRefs{}, err
+	}
+	return container.TaggedRefs{LocalRef: localRef, ClusterRef: clusterRef}, nil
+}
+
+func dialBuildKit(ctx context.Context, conn v1alpha1.BuildKitConnection, kubeContext string) (buildkitSolver, error) {
+	u, err := url.Parse(conn.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	clientOpts := []bkclient.ClientOpt{bkclient.WithFailFast()}
+	if conn.CACert != "" {
+		clientOpts = append(clientOpts, bkclient.WithCredentials(conn.ServerName, conn.CACert, conn.Cert, conn.Key))
+	}
+	if u.Scheme == "kube-pod" {
+		args := kubePodExecArgs(u, kubeContext)
+		clientOpts = append(clientOpts, bkclient.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return commandconn.New(ctx, "kubectl", args...)
+		}))
+	}
+	return bkclient.New(ctx, conn.Address, clientOpts...)
+}
+
+// Connects to a buildkitd pod the way the buildx kubernetes driver does:
+// by running `buildctl dial-stdio` in the pod, and talking over its stdio.
+func kubePodExecArgs(u *url.URL, kubeContext string) []string {
+	q := u.Query()
+	if q.Get("context") != "" {
+		kubeContext = q.Get("context")
+	}
+
+	var args []string
+	if kubeContext != "" {
+		args = append(args, "--context="+kubeContext)
+	}
+	if q.Get("namespace") != "" {
+		args = append(args, "--namespace="+q.Get("namespace"))
+	}
+	args = append(args, "exec", "-i", u.Host)
+	if q.Get("container") != "" {
+		args = append(args, "--container="+q.Get("container"))
+	}
+	return append(args, "--", "buildctl", "dial-stdio")
+}
@@ -0,0 +1,167 @@
+package build
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	bkclient "github.com/moby/buildkit/client"
+	"github.com/moby/buildkit/client/llb"
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestBuildKitBuild(t *testing.T) {
+	f := newBuildKitFixture(t)
+	dig := digest.FromString("manifest")
+	f.solver.resp = &bkclient.SolveResponse{ExporterResponse: map[string]string{exporterImageDigest: dig.String()}}
+
+	spec := v1alpha1.DockerImageSpec{
+		DockerfileContents: "FROM alpine\nARG VERSION\n",
+		Context:            f.Path(),
+		Args:               []string{"VERSION=1.2"},
+		Target:             "release",
+	}
+	refs, stages, err := f.b.BuildImage(f.ctx, f.ps, refSetWithRegistryFromString("my-app", TwoURLRegistry),
+		spec, f.cluster, nil, model.EmptyMatcher)
+	require.NoError(t, err)
+
+	assert.Equal(t, "kube-pod://buildkitd-0", f.dialedAddress)
+	assert.Equal(t, "my-kube-context", f.dialedKubeContext)
+	assert.True(t, f.solver.closed)
+
+	opt := f.solver.opt
+	assert.Equal(t, "dockerfile.v0", opt.Frontend)
+	assert.Equal(t, map[string]string{
+		"filename":          "Dockerfile",
+		"target":            "release",
+		"build-arg:VERSION": "1.2",
+	}, opt.FrontendAttrs)
+	require.Len(t, opt.Exports, 1)
+	assert.Equal(t, bkclient.ExporterImage, opt.Exports[0].Type)
+	assert.Equal(t, map[string]string{
+		"name": "localhost:1234/my-app:tilt-build-1600000000",
+		"push": "true",
+	}, opt.Exports[0].Attrs)
+
+	assert.Equal(t, "localhost:1234/my-app:tilt-build-1600000000@"+dig.String(), refs.LocalRef.String())
+	assert.Equal(t, "registry:1234/my-app:tilt-build-1600000000@"+dig.String(), refs.ClusterRef.String())
+
+	require.Len(t, stages, 1)
+	assert.Equal(t, "[1/1] FROM docker.io/library/alpine", stages[0].Name)
+	assert.Contains(t, f.out.String(), "on BuildKit at kube-pod://buildkitd-0")
+	assert.Contains(t, f.out.String(), "[1/1] FROM docker.io/library/alpine")
+}
+
+func TestBuildKitBuildError(t *testing.T) {
+	f := newBuildKitFixture(t)
+	f.solver.err = errors.New("failed to solve with frontend dockerfile.v0: failed to build LLB: boom")
+
+	spec := v1alpha1.DockerImageSpec{DockerfileContents: "FROM alpine", Context: f.Path()}
+	_, _, err := f.b.BuildImage(f.ctx, f.ps, refSetFromString("gcr.io/foo/my-app"),
+		spec, f.cluster, nil, model.EmptyMatcher)
+	require.EqualError(t, err, "boom")
+}
+
+func TestKubePodExecArgs(t *testing.T) {
+	u, err := url.Parse("kube-pod://buildkitd-0?namespace=buildkit&container=buildkitd")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"--context=my-kube-context", "--namespace=buildkit",
+		"exec", "-i", "buildkitd-0", "--container=buildkitd",
+		"--", "buildctl", "dial-stdio",
+	}, kubePodExecArgs(u, "my-kube-context"))
+
+	u, err = url.Parse("kube-pod://buildkitd-0?context=other")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"--context=other", "exec", "-i", "buildkitd-0", "--", "buildctl", "dial-stdio",
+	}, kubePodExecArgs(u, "my-kube-context"))
+}
+
+type buildKitFixture struct {
+	*tempdir.TempDirFixture
+	ctx     context.Context
+	out     *bytes.Buffer
+	ps      *PipelineState
+	b       *BuildKitBuilder
+	solver  *fakeBuildkitSolver
+	cluster *v1alpha1.Cluster
+
+	dialedAddress     string
+	dialedKubeContext string
+}
+
+func newBuildKitFixture(t *testing.T) *buildKitFixture {
+	out := &bytes.Buffer{}
+	ctx := logger.WithLogger(context.Background(), logger.NewTestLogger(out))
+	f := &buildKitFixture{
+		TempDirFixture: tempdir.NewTempDirFixture(t),
+		ctx:            ctx,
+		out:            out,
+		ps:             NewPipelineState(ctx, 1, fakeClock{now: time.Unix(1600000000, 0)}),
+		solver:         &fakeBuildkitSolver{},
+		cluster: &v1alpha1.Cluster{
+			Spec: v1alpha1.ClusterSpec{
+				BuildKit: &v1alpha1.BuildKitConnection{Address: "kube-pod://buildkitd-0"},
+			},
+			Status: v1alpha1.ClusterStatus{
+				Connection: &v1alpha1.ClusterConnectionStatus{
+					Kubernetes: &v1alpha1.KubernetesClusterConnectionStatus{Context: "my-kube-context"},
+				},
+			},
+		},
+	}
+	f.b = &BuildKitBuilder{
+		dial: func(ctx context.Context, conn v1alpha1.BuildKitConnection, kubeContext string) (buildkitSolver, error) {
+			f.dialedAddress = conn.Address
+			f.dialedKubeContext = kubeContext
+			return f.solver, nil
+		},
+	}
+	return f
+}
+
+type fakeBuildkitSolver struct {
+	opt    bkclient.SolveOpt
+	resp   *bkclient.SolveResponse
+	err    error
+	closed bool
+}
+
+func (s *fakeBuildkitSolver) Solve(ctx context.Context, def *llb.Definition, opt bkclient.SolveOpt, statusChan chan *bkclient.SolveStatus) (*bkclient.SolveResponse, error) {
+	defer close(statusChan)
+	s.opt = opt
+
+	started := time.Unix(1600000000, 0)
+	completed := started.Add(time.Second)
+	statusChan <- &bkclient.SolveStatus{
+		Vertexes: []*bkclient.Vertex{
+			{
+				Digest:    digest.FromString("from"),
+				Name:      "[1/1] FROM docker.io/library/alpine",
+				Started:   &started,
+				Completed: &completed,
+			},
+		},
+	}
+
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.resp, nil
+}
+
+func (s *fakeBuildkitSolver) Close() error {
+	s.closed = true
+	return nil
+}
@@ -18,6 +18,10 @@ type buildkitPrinter struct {
 	logger logger.Logger
 	vData  map[digest.Digest]*vertexAndLogs
 	vOrder []digest.Digest
+
+	// If set, receives the stage statuses as the build makes progress.
+	onProgress     StageProgressFunc
+	lastProgressAt time.Time
 }
 
 type vertex struct {
@@ -147,6 +151,9 @@ func (b *buildkitPrinter) toStageStatuses() []v1alpha1.DockerImageStageStatus {
 			if v.isError() {
 				status.Error = v.error
 			}
+			progress := vl.statuses.combined()
+			status.Current = progress.current
+			status.Total = progress.total
 			result = append(result, status)
 		}
 	}
@@ -280,9 +287,23 @@ func (b *buildkitPrinter) parseAndPrint(vertexes []*vertex, logs []*vertexLog, s
 		}
 	}
 
+	b.reportProgress()
 	return nil
 }
 
+func (b *buildkitPrinter) reportProgress() {
+	if b.onProgress == nil {
+		return
+	}
+
+	now := time.Now()
+	if now.Sub(b.lastProgressAt) < stageProgressInterval {
+		return
+	}
+	b.lastProgressAt = now
+	b.onProgress(b.toStageStatuses())
+}
+
 func (b *buildkitPrinter) flushLogs(vl *vertexAndLogs) {
 	for vl.logsPrinted < len(vl.logs) {
 		l := vl.logs[vl.logsPrinted]
@@ -11,7 +11,9 @@ import (
 
 	controlapi "github.com/moby/buildkit/api/services/control"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
 	"github.com/tilt-dev/tilt/pkg/logger"
 )
 
@@ -103,3 +105,43 @@ func TestBuildkitPrinter(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildkitPrinterProgress(t *testing.T) {
+	f, err := os.Open("testdata/TestBuildkitPrinter/rust-success.response.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	responses, err := buildkitTestCase{}.readResponse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var reported [][]v1alpha1.DockerImageStageStatus
+	p := newBuildkitPrinter(logger.NewLogger(logger.InfoLvl, io.Discard))
+	p.onProgress = func(stages []v1alpha1.DockerImageStageStatus) {
+		reported = append(reported, stages)
+	}
+	for _, resp := range responses {
+		err := p.parseAndPrint(toVertexes(resp))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Progress reports are rate-limited, so a replay only reports once.
+	require.Len(t, reported, 1)
+
+	// The build finished, so every stage that knows its total is done.
+	var total int64
+	for _, stage := range p.toStageStatuses() {
+		if stage.Total > 0 {
+			assert.Equal(t, stage.Total, stage.Current, stage.Name)
+		}
+		total += stage.Total
+	}
+	assert.Greater(t, total, int64(0))
+}
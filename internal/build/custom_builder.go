@@ -118,7 +118,7 @@ func (b *CustomBuilder) Build(ctx context.Context, refs container.RefSet,
 	cmd.Stderr = w
 
 	l.Infof("Running custom build cmd %q", model.Cmd{Argv: spec.Args}.String())
-	err = cmd.Run()
+	err = runBuildCmd(ctx, cmd)
 	if err != nil {
 		return container.TaggedRefs{}, errors.Wrap(err, "Custom build command failed")
 	}
@@ -178,7 +178,7 @@ func (b *CustomBuilder) readImageRef(ctx context.Context, outputsImageRefTo stri
 	}
 
 	refStr := strings.TrimSpace(string(contents))
-	ref, err := container.ParseNamedTagged(refStr)
+	ref, err := parseOutputImageRef(refStr)
 	if err != nil {
 		return container.TaggedRefs{}, fmt.Errorf("Output image ref in file %s was invalid: %v",
 			outputsImageRefTo, err)
@@ -190,7 +190,7 @@ func (b *CustomBuilder) readImageRef(ctx context.Context, outputsImageRefTo stri
 		if err != nil {
 			return container.TaggedRefs{}, fmt.Errorf("Error converting image ref for cluster: %w", err)
 		}
-		clusterRef, err = reference.WithTag(replacedName, ref.Tag())
+		clusterRef, err = withTagAndDigest(replacedName, ref.Tag(), refDigest(ref))
 		if err != nil {
 			return container.TaggedRefs{}, fmt.Errorf("Error converting image ref for cluster: %w", err)
 		}
@@ -201,3 +201,69 @@ func (b *CustomBuilder) readImageRef(ctx context.Context, outputsImageRefTo stri
 		ClusterRef: clusterRef,
 	}, nil
 }
+
+// Parses the image ref that the custom_build command wrote to its output file.
+//
+// Builders like ko, pack, and bazel rules_oci compute their own refs, and often
+// identify the image by digest (gcr.io/foo/bar@sha256:...) rather than by tag.
+// Digest-only refs get a tag derived from the digest, so that the rest of Tilt
+// can keep treating them as tagged refs. The digest is preserved, so the ref
+// we inject into the YAML still pins the exact image that was built.
+func parseOutputImageRef(s string) (reference.NamedTagged, error) {
+	ref, err := container.ParseNamed(s)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing %q", s)
+	}
+
+	if nt, ok := ref.(reference.NamedTagged); ok {
+		return nt, nil
+	}
+
+	digested, ok := ref.(reference.Digested)
+	if !ok {
+		return nil, fmt.Errorf("Expected reference %q to contain a tag or digest", s)
+	}
+
+	tag, err := digestAsTag(digested.Digest())
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing %q", s)
+	}
+
+	result, err := withTagAndDigest(reference.TrimNamed(ref), tag, digested.Digest())
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing %q", s)
+	}
+	return result, nil
+}
+
+// Adds a tag and an optional digest to an image name.
+func withTagAndDigest(name reference.Named, tag string, dig digest.Digest) (reference.NamedTagged, error) {
+	tagged, err := reference.WithTag(name, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	if dig == "" {
+		return tagged, nil
+	}
+
+	withDigest, err := reference.WithDigest(tagged, dig)
+	if err != nil {
+		return nil, err
+	}
+
+	// A ref with both a tag and a digest is always NamedTagged,
+	// but WithDigest only promises a Canonical.
+	result, ok := withDigest.(reference.NamedTagged)
+	if !ok {
+		return nil, fmt.Errorf("internal error: ref %q lost its tag", withDigest)
+	}
+	return result, nil
+}
+
+func refDigest(ref reference.Reference) digest.Digest {
+	if digested, ok := ref.(reference.Digested); ok {
+		return digested.Digest()
+	}
+	return ""
+}
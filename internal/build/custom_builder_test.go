@@ -105,6 +105,24 @@ func TestCustomBuildCmdFails(t *testing.T) {
 	assert.EqualError(t, err, "Custom build command failed: exit status 1")
 }
 
+func TestCustomBuildCanceledKillsChildren(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no sh on windows")
+	}
+	f := newFakeCustomBuildFixture(t)
+
+	// The sleep keeps the output open, so the build only ends
+	// if we kill it along with its parent.
+	cb := f.customBuild("sleep 60 & wait")
+	ctx, cancel := context.WithCancel(f.ctx)
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := f.cb.Build(ctx, refSetFromString("gcr.io/foo/bar"), cb.CmdImageSpec, nil)
+	assert.EqualError(t, err, "Custom build command failed: context canceled")
+	assert.Less(t, time.Since(start), 30*time.Second)
+}
+
 func TestCustomBuildImgNotFound(t *testing.T) {
 	f := newFakeCustomBuildFixture(t)
 
@@ -187,6 +205,37 @@ func TestCustomBuildOutputsToImageRefMalformedImage(t *testing.T) {
 			f.JoinPath("ref.txt")))
 }
 
+func TestCustomBuildOutputsToImageRefDigest(t *testing.T) {
+	f := newFakeCustomBuildFixture(t)
+
+	myRef := "gcr.io/foo/bar@sha256:11cd0eb38bc3ceb958ffb2f9bd70be3fb317ce7d255c8a4c3f4af30e298aa1aa"
+	cb := f.customBuild(fmt.Sprintf("echo %s > ref.txt", myRef))
+	cb.CmdImageSpec.OutputsImageRefTo = f.JoinPath("ref.txt")
+	cb.CmdImageSpec.OutputMode = v1alpha1.CmdImageOutputRemote
+	refs, err := f.cb.Build(f.ctx, refSetFromString("gcr.io/foo/bar"), cb.CmdImageSpec, nil)
+	require.NoError(t, err)
+
+	expected := "gcr.io/foo/bar:tilt-11cd0eb38bc3ceb9@sha256:11cd0eb38bc3ceb958ffb2f9bd70be3fb317ce7d255c8a4c3f4af30e298aa1aa"
+	assert.Equal(f.t, expected, refs.LocalRef.String())
+	assert.Equal(f.t, expected, refs.ClusterRef.String())
+}
+
+func TestCustomBuildOutputsToImageRefDigestClusterRef(t *testing.T) {
+	f := newFakeCustomBuildFixture(t)
+
+	myRef := "localhost:1234/foo_bar:dev@sha256:11cd0eb38bc3ceb958ffb2f9bd70be3fb317ce7d255c8a4c3f4af30e298aa1aa"
+	cb := f.customBuild(fmt.Sprintf("echo %s > ref.txt", myRef))
+	cb.CmdImageSpec.OutputsImageRefTo = f.JoinPath("ref.txt")
+	cb.CmdImageSpec.OutputMode = v1alpha1.CmdImageOutputRemote
+	refs, err := f.cb.Build(f.ctx, refSetWithRegistryFromString("foo/bar", TwoURLRegistry), cb.CmdImageSpec, nil)
+	require.NoError(t, err)
+
+	assert.Equal(f.t, myRef, refs.LocalRef.String())
+	assert.Equal(f.t,
+		"registry:1234/foo_bar:dev@sha256:11cd0eb38bc3ceb958ffb2f9bd70be3fb317ce7d255c8a4c3f4af30e298aa1aa",
+		refs.ClusterRef.String())
+}
+
 func TestCustomBuildOutputsToImageRefSkipsLocalDocker(t *testing.T) {
 	f := newFakeCustomBuildFixture(t)
 
@@ -26,6 +26,7 @@ import (
 	"github.com/tilt-dev/tilt/internal/docker"
 	"github.com/tilt-dev/tilt/internal/dockerfile"
 	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/pkg/apis"
 	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
 	"github.com/tilt-dev/tilt/pkg/logger"
 	"github.com/tilt-dev/tilt/pkg/model"
@@ -39,6 +40,12 @@ type DockerBuilder struct {
 	//
 	// By default, all builds are labeled with a build mode.
 	extraLabels dockerfile.Labels
+
+	// Maps build inputs to the images they produced,
+	// so that we can skip builds whose inputs haven't changed.
+	//
+	// May be nil, in which case we always build.
+	cache *BuildCache
 }
 
 // Describes how a docker instance connects to kubernetes instances.
@@ -47,10 +54,11 @@ type DockerKubeConnection interface {
 	WillBuildToKubeContext(kctx k8s.KubeContext) bool
 }
 
-func NewDockerBuilder(dCli docker.Client, extraLabels dockerfile.Labels) *DockerBuilder {
+func NewDockerBuilder(dCli docker.Client, extraLabels dockerfile.Labels, cache *BuildCache) *DockerBuilder {
 	return &DockerBuilder{
 		dCli:        dCli,
 		extraLabels: extraLabels,
+		cache:       cache,
 	}
 }
 
@@ -109,12 +117,15 @@ func (d *DockerBuilder) TagRefs(ctx context.Context, refs container.RefSet, dig
 // TODO(nick) In the future, I would like us to be smarter about checking if the kubernetes cluster
 // we're running in has access to the given registry. And if it doesn't, we should either emit an
 // error, or push to a registry that kubernetes does have access to (e.g., a local registry).
-func (d *DockerBuilder) PushImage(ctx context.Context, ref reference.NamedTagged) error {
+//
+// Returns the digest of the pushed image manifest, or an empty digest
+// if the registry didn't report one.
+func (d *DockerBuilder) PushImage(ctx context.Context, ref reference.NamedTagged) (digest.Digest, error) {
 	l := logger.Get(ctx)
 
 	imagePushResponse, err := d.dCli.ImagePush(ctx, ref)
 	if err != nil {
-		return errors.Wrap(err, "PushImage#ImagePush")
+		return "", errors.Wrap(err, "PushImage#ImagePush")
 	}
 
 	defer func() {
@@ -124,12 +135,12 @@ func (d *DockerBuilder) PushImage(ctx context.Context, ref reference.NamedTagged
 		}
 	}()
 
-	_, _, err = readDockerOutput(ctx, imagePushResponse)
+	output, _, err := readDockerOutput(ctx, imagePushResponse)
 	if err != nil {
-		return errors.Wrapf(err, "pushing image %q", ref.Name())
+		return "", errors.Wrapf(err, "pushing image %q", ref.Name())
 	}
 
-	return nil
+	return getDigestFromPushAux(output.aux), nil
 }
 
 func (d *DockerBuilder) ImageExists(ctx context.Context, ref reference.NamedTagged) (bool, error) {
@@ -160,6 +171,14 @@ func (d *DockerBuilder) BuildImage(ctx context.Context, ps *PipelineState, refs
 	}
 	logger.Get(ctx).Infof("Building Dockerfile%s:\n%s\n", platformSuffix, indent(spec.DockerfileContents, "  "))
 
+	cacheKey := d.buildCacheKey(ctx, spec, filter)
+	if cacheKey != "" && !spec.NoCache {
+		tagged, stage, ok := d.buildFromCache(ctx, ps, refs, spec, cacheKey)
+		if ok {
+			return tagged, []v1alpha1.DockerImageStageStatus{stage}, nil
+		}
+	}
+
 	ps.StartBuildStep(ctx, "Building image")
 	allowBuildkit := true
 	ctx = ps.AttachLogger(ctx)
@@ -197,9 +216,73 @@ func (d *DockerBuilder) BuildImage(ctx context.Context, ps *PipelineState, refs
 		return container.TaggedRefs{}, stages, errors.Wrap(err, "docker tag")
 	}
 
+	if cacheKey != "" {
+		err = d.cache.Put(cacheKey, digest)
+		if err != nil {
+			logger.Get(ctx).Debugf("Error saving build cache: %v", err)
+		}
+	}
+
 	return tagged, stages, nil
 }
 
+// Hashes the inputs to the build.
+//
+// Returns an empty key if the build can't be cached.
+func (d *DockerBuilder) buildCacheKey(ctx context.Context, spec v1alpha1.DockerImageSpec, filter model.PathMatcher) string {
+	if d.cache == nil || !canCacheBuild(spec) {
+		return ""
+	}
+
+	key, err := buildCacheKey(spec, d.extraLabels, filter)
+	if err != nil {
+		logger.Get(ctx).Debugf("Skipping build cache: %v", err)
+		return ""
+	}
+	return key
+}
+
+// If we've built these exact inputs before, and the image is still around,
+// tag the existing image instead of building it again.
+func (d *DockerBuilder) buildFromCache(ctx context.Context, ps *PipelineState, refs container.RefSet,
+	spec v1alpha1.DockerImageSpec, cacheKey string) (container.TaggedRefs, v1alpha1.DockerImageStageStatus, bool) {
+	dig, ok := d.cache.Get(cacheKey)
+	if !ok {
+		return container.TaggedRefs{}, v1alpha1.DockerImageStageStatus{}, false
+	}
+
+	startTime := apis.NowMicro()
+	_, _, err := d.dCli.ImageInspectWithRaw(ctx, dig.String())
+	if err != nil {
+		if client.IsErrNotFound(err) {
+			// The image was pruned, so the entry is no longer useful.
+			_ = d.cache.Delete(cacheKey)
+		}
+		return container.TaggedRefs{}, v1alpha1.DockerImageStageStatus{}, false
+	}
+
+	for _, extraTag := range spec.ExtraTags {
+		err := d.dCli.ImageTag(ctx, dig.String(), extraTag)
+		if err != nil {
+			return container.TaggedRefs{}, v1alpha1.DockerImageStageStatus{}, false
+		}
+	}
+
+	tagged, err := d.TagRefs(ctx, refs, dig)
+	if err != nil {
+		return container.TaggedRefs{}, v1alpha1.DockerImageStageStatus{}, false
+	}
+
+	ps.Printf(ctx, "Skipping build: inputs unchanged since %s was built", container.FamiliarString(tagged.LocalRef))
+	endTime := apis.NowMicro()
+	return tagged, v1alpha1.DockerImageStageStatus{
+		Name:       "content cache",
+		Cached:     true,
+		StartedAt:  &startTime,
+		FinishedAt: &endTime,
+	}, true
+}
+
 // A helper function that builds the paths to the given docker image,
 // then returns the output digest.
 func (d *DockerBuilder) buildToDigest(ctx context.Context, spec v1alpha1.DockerImageSpec, filter model.PathMatcher, allowBuildkit bool) (digest.Digest, []v1alpha1.DockerImageStageStatus, error) {
@@ -328,6 +411,7 @@ func readDockerOutput(ctx context.Context, reader io.Reader) (dockerOutput, []v1
 	result := dockerOutput{}
 	decoder := json.NewDecoder(reader)
 	b := newBuildkitPrinter(logger.Get(ctx))
+	b.onProgress = stageProgressFromContext(ctx)
 
 	for decoder.More() {
 		message := jsonmessage.JSONMessage{}
@@ -484,6 +568,26 @@ func getDigestFromAux(aux json.RawMessage) (digest.Digest, error) {
 	return digest.Digest(id), nil
 }
 
+// Docker reports the digest of the pushed manifest in the aux message,
+// if the registry sent one back.
+func getDigestFromPushAux(aux *json.RawMessage) digest.Digest {
+	if aux == nil {
+		return ""
+	}
+
+	var result types.PushResult
+	err := json.Unmarshal(*aux, &result)
+	if err != nil {
+		return ""
+	}
+
+	dig, err := digest.Parse(result.Digest)
+	if err != nil {
+		return ""
+	}
+	return dig
+}
+
 func digestAsTag(d digest.Digest) (string, error) {
 	str := d.Encoded()
 	if len(str) < 16 {
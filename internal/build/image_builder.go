@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/docker/distribution/reference"
+	"github.com/opencontainers/go-digest"
 	"k8s.io/apimachinery/pkg/types"
 
 	"github.com/tilt-dev/clusterid"
@@ -20,6 +21,9 @@ import (
 type ImageBuilder struct {
 	db    *DockerBuilder
 	custb *CustomBuilder
+	ociab *OCIArtifactBuilder
+	bkb   *BuildKitBuilder
+	kob   *KoBuilder
 	kl    KINDLoader
 }
 
@@ -27,6 +31,9 @@ func NewImageBuilder(db *DockerBuilder, custb *CustomBuilder, kl KINDLoader) *Im
 	return &ImageBuilder{
 		db:    db,
 		custb: custb,
+		ociab: NewOCIArtifactBuilder(),
+		bkb:   NewBuildKitBuilder(),
+		kob:   NewKoBuilder(db),
 		kl:    kl,
 	}
 }
@@ -34,11 +41,21 @@ func NewImageBuilder(db *DockerBuilder, custb *CustomBuilder, kl KINDLoader) *Im
 func (ib *ImageBuilder) CanReuseRef(ctx context.Context, iTarget model.ImageTarget, ref reference.NamedTagged) (bool, error) {
 	switch iTarget.BuildDetails.(type) {
 	case model.DockerBuild:
+		// Images built on a remote BuildKit daemon are pinned to a digest,
+		// and only exist in the registry, so assume they're still there.
+		if _, ok := ref.(reference.Digested); ok {
+			return true, nil
+		}
+		return ib.db.ImageExists(ctx, ref)
+	case model.KoBuild:
 		return ib.db.ImageExists(ctx, ref)
 	case model.CustomBuild:
 		// Custom build doesn't have a good way to check if the ref still exists in the image
 		// store, so just assume we can.
 		return true, nil
+	case model.OCIArtifactBuild:
+		// Artifacts are only stored in the registry, so assume they're still there.
+		return true, nil
 	}
 	return false, fmt.Errorf("image %q has no valid buildDetails (neither "+
 		"DockerBuild nor CustomBuild)", iTarget.ImageMapSpec.Selector)
@@ -59,7 +76,7 @@ func (ib *ImageBuilder) Build(ctx context.Context,
 		return refs, stages, err
 	}
 
-	pushStage := ib.push(ctx, refs, ps, iTarget, cluster)
+	refs, pushStage := ib.push(ctx, refs, ps, iTarget, cluster)
 	if pushStage != nil {
 		stages = append(stages, *pushStage)
 	}
@@ -91,6 +108,12 @@ func (ib *ImageBuilder) buildOnly(ctx context.Context,
 		defer ps.EndPipelineStep(ctx)
 
 		filter := ignore.CreateBuildContextFilter(bd.DockerImageSpec.ContextIgnores)
+		if usesRemoteBuildKit(cluster) {
+			return ib.bkb.BuildImage(ctx, ps, refs, bd.DockerImageSpec,
+				cluster,
+				imageMaps,
+				filter)
+		}
 		return ib.db.BuildImage(ctx, ps, refs, bd.DockerImageSpec,
 			cluster,
 			imageMaps,
@@ -101,6 +124,17 @@ func (ib *ImageBuilder) buildOnly(ctx context.Context,
 		defer ps.EndPipelineStep(ctx)
 		refs, err := ib.custb.Build(ctx, refs, bd.CmdImageSpec, imageMaps)
 		return refs, nil, err
+
+	case model.KoBuild:
+		ps.StartPipelineStep(ctx, "Building Go binary with ko: [%s]", userFacingRefName)
+		defer ps.EndPipelineStep(ctx)
+		return ib.kob.Build(ctx, ps, refs, bd, cluster, imageMaps)
+
+	case model.OCIArtifactBuild:
+		ps.StartPipelineStep(ctx, "Building OCI Artifact: [%s]", userFacingRefName)
+		defer ps.EndPipelineStep(ctx)
+		refs, err := ib.ociab.Build(ctx, refs, bd)
+		return refs, nil, err
 	}
 
 	// Theoretically this should never trip b/c we `validate` the manifest beforehand...?
@@ -110,13 +144,16 @@ func (ib *ImageBuilder) buildOnly(ctx context.Context,
 }
 
 // Push the image if the cluster requires it.
-func (ib *ImageBuilder) push(ctx context.Context, refs container.TaggedRefs, ps *PipelineState, iTarget model.ImageTarget, cluster *v1alpha1.Cluster) *v1alpha1.DockerImageStageStatus {
+//
+// If the registry reports the digest of the pushed image, returns
+// the refs with the cluster ref pinned to that digest.
+func (ib *ImageBuilder) push(ctx context.Context, refs container.TaggedRefs, ps *PipelineState, iTarget model.ImageTarget, cluster *v1alpha1.Cluster) (container.TaggedRefs, *v1alpha1.DockerImageStageStatus) {
 	// Skip the push phase entirely if we're on Docker Compose.
 	isDC := cluster != nil &&
 		cluster.Spec.Connection != nil &&
 		cluster.Spec.Connection.Docker != nil
 	if isDC {
-		return nil
+		return refs, nil
 	}
 
 	// On Kubernetes, we count each push() as a stage, and need to print why
@@ -131,19 +168,29 @@ func (ib *ImageBuilder) push(ctx context.Context, refs container.TaggedRefs, ps
 
 	if cbSkip {
 		ps.Printf(ctx, "Skipping push: custom_build() configured to handle push itself")
-		return nil
+		return refs, nil
+	}
+
+	if iTarget.IsOCIArtifactBuild() {
+		ps.Printf(ctx, "Skipping push: oci_artifact() pushes as part of the build")
+		return refs, nil
+	}
+
+	if iTarget.IsDockerBuild() && usesRemoteBuildKit(cluster) {
+		ps.Printf(ctx, "Skipping push: BuildKit pushes as part of the build")
+		return refs, nil
 	}
 
 	// We can also skip the push of the image if it isn't used
 	// in any k8s resources! (e.g., it's consumed by another image).
 	if iTarget.ClusterNeeds() != v1alpha1.ClusterImageNeedsPush {
 		ps.Printf(ctx, "Skipping push: base image does not need deploy")
-		return nil
+		return refs, nil
 	}
 
 	if ib.db.WillBuildToKubeContext(k8s.KubeContext(k8sConnStatus(cluster).Context)) {
 		ps.Printf(ctx, "Skipping push: building on cluster's container runtime")
-		return nil
+		return refs, nil
 	}
 
 	startTime := apis.NowMicro()
@@ -160,11 +207,11 @@ func (ib *ImageBuilder) push(ctx context.Context, refs container.TaggedRefs, ps
 		if err != nil {
 			stage.Error = fmt.Sprintf("Error loading image to KIND: %v", err)
 		}
-		return stage
+		return refs, stage
 	}
 
 	ps.Printf(ctx, "Pushing with Docker client")
-	err = ib.db.PushImage(ps.AttachLogger(ctx), refs.LocalRef)
+	dig, err := ib.db.PushImage(ps.AttachLogger(ctx), refs.LocalRef)
 
 	endTime := apis.NowMicro()
 	stage := &v1alpha1.DockerImageStageStatus{
@@ -174,8 +221,31 @@ func (ib *ImageBuilder) push(ctx context.Context, refs container.TaggedRefs, ps
 	}
 	if err != nil {
 		stage.Error = fmt.Sprintf("docker push: %v", err)
+		return refs, stage
+	}
+
+	pinned, err := pinClusterRefToDigest(refs, dig)
+	if err != nil {
+		stage.Error = fmt.Sprintf("docker push: %v", err)
+	}
+	return pinned, stage
+}
+
+// Pins the cluster ref to the digest that the registry reported, so that the
+// cluster pulls exactly the image we pushed, even if someone else pushes the
+// same tag to a shared registry.
+//
+// The local ref stays as-is, because the local image store
+// might not know about the registry digest.
+func pinClusterRefToDigest(refs container.TaggedRefs, dig digest.Digest) (container.TaggedRefs, error) {
+	if dig == "" {
+		return refs, nil
+	}
+	clusterRef, err := withTagAndDigest(reference.TrimNamed(refs.ClusterRef), refs.ClusterRef.Tag(), dig)
+	if err != nil {
+		return refs, err
 	}
-	return stage
+	return container.TaggedRefs{LocalRef: refs.LocalRef, ClusterRef: clusterRef}, nil
 }
 
 func (ib *ImageBuilder) shouldUseKINDLoad(refs container.TaggedRefs, cluster *v1alpha1.Cluster) bool {
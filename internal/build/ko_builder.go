@@ -0,0 +1,219 @@
+package build
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/pkg/errors"
+	ktypes "k8s.io/apimachinery/pkg/types"
+
+	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/xdg"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// Where ko puts the binary and static data in the image.
+const (
+	koAppDir      = "/ko-app"
+	koDataPath    = "/var/run/ko"
+	koDataDirName = "kodata"
+)
+
+// Builds Go services the way ko does: compile the binary on the host,
+// then layer it onto a base image.
+//
+// We do the layering with a generated Dockerfile, so that ko images
+// go through the same Docker build as everything else, and get pushed
+// or loaded into the cluster the same way.
+type KoBuilder struct {
+	db *DockerBuilder
+
+	// The directory to assemble the build context in.
+	//
+	// This needs to be stable across builds, so that an unchanged binary
+	// hits the build cache.
+	contextDir func(bd model.KoBuild) (string, error)
+}
+
+func NewKoBuilder(db *DockerBuilder) *KoBuilder {
+	return &KoBuilder{db: db, contextDir: koCacheContextDir}
+}
+
+func (kb *KoBuilder) Build(ctx context.Context, ps *PipelineState, refs container.RefSet,
+	bd model.KoBuild,
+	cluster *v1alpha1.Cluster,
+	imageMaps map[ktypes.NamespacedName]*v1alpha1.ImageMap) (container.TaggedRefs, []v1alpha1.DockerImageStageStatus, error) {
+	contextDir, err := kb.contextDir(bd)
+	if err != nil {
+		return container.TaggedRefs{}, nil, errors.Wrap(err, "ko_build")
+	}
+
+	// Clear out the binary and data from the last build.
+	err = os.RemoveAll(contextDir)
+	if err == nil {
+		err = os.MkdirAll(filepath.Join(contextDir, "ko-app"), 0755)
+	}
+	if err != nil {
+		return container.TaggedRefs{}, nil, errors.Wrap(err, "ko_build")
+	}
+
+	spec := InjectClusterPlatform(v1alpha1.DockerImageSpec{
+		Context:   contextDir,
+		Platform:  bd.Platform,
+		ImageMaps: bd.ImageMaps,
+	}, cluster)
+	goos, goarch, goarm := koGoPlatform(spec.Platform)
+	if spec.Platform == "" {
+		spec.Platform = fmt.Sprintf("%s/%s", goos, goarch)
+	}
+
+	binName := koBinaryName(bd)
+	ps.StartBuildStep(ctx, "Compiling %s for %s", bd.ImportPath, spec.Platform)
+	err = kb.compile(ctx, ps, bd, filepath.Join(contextDir, "ko-app", binName), goos, goarch, goarm)
+	if err != nil {
+		return container.TaggedRefs{}, nil, err
+	}
+
+	hasData, err := copyKoData(bd, filepath.Join(contextDir, koDataDirName))
+	if err != nil {
+		return container.TaggedRefs{}, nil, errors.Wrap(err, "ko_build: copying kodata")
+	}
+
+	spec.DockerfileContents = koDockerfile(bd, binName, hasData)
+	return kb.db.BuildImage(ctx, ps, refs, spec, cluster, imageMaps, model.EmptyMatcher)
+}
+
+func (kb *KoBuilder) compile(ctx context.Context, ps *PipelineState, bd model.KoBuild, out, goos, goarch, goarm string) error {
+	args := []string{"build", "-trimpath", "-o", out}
+	args = append(args, bd.BuildFlags...)
+	args = append(args, bd.ImportPath)
+
+	env := append(logger.DefaultEnv(ctx),
+		"CGO_ENABLED=0",
+		fmt.Sprintf("GOOS=%s", goos),
+		fmt.Sprintf("GOARCH=%s", goarch))
+	if goarm != "" {
+		env = append(env, fmt.Sprintf("GOARM=%s", goarm))
+	}
+	env = append(env, bd.Env...)
+
+	l := logger.Get(ps.AttachLogger(ctx))
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = bd.Dir
+	cmd.Env = env
+	w := l.Writer(logger.InfoLvl)
+	cmd.Stdout = w
+	cmd.Stderr = w
+
+	l.Infof("Running %q", model.Cmd{Argv: append([]string{"go"}, args...)}.String())
+	err := runBuildCmd(ctx, cmd)
+	if err != nil {
+		return errors.Wrap(err, "ko_build: go build failed")
+	}
+	return nil
+}
+
+// The Dockerfile that puts the binary on the base image.
+func koDockerfile(bd model.KoBuild, binName string, hasData bool) string {
+	baseImage := bd.BaseImage
+	if baseImage == "" {
+		baseImage = model.KoDefaultBaseImage
+	}
+
+	binPath := path.Join(koAppDir, binName)
+	lines := []string{fmt.Sprintf("FROM %s", baseImage)}
+	if hasData {
+		lines = append(lines, fmt.Sprintf("COPY %s %s", koDataDirName, koDataPath))
+	}
+	lines = append(lines,
+		fmt.Sprintf("COPY ko-app/%s %s", binName, binPath),
+		fmt.Sprintf("ENV KO_DATA_PATH=%s", koDataPath),
+		fmt.Sprintf("ENTRYPOINT [%q]", binPath),
+	)
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// Like ko, name the binary after the last element of the import path.
+func koBinaryName(bd model.KoBuild) string {
+	p := strings.TrimSuffix(filepath.ToSlash(bd.ImportPath), "/")
+	if p == "." || p == "" {
+		p = filepath.ToSlash(bd.Dir)
+	}
+	return path.Base(p)
+}
+
+// Converts a docker platform (e.g., linux/arm/v7) to GOOS, GOARCH, and GOARM.
+func koGoPlatform(platform string) (string, string, string) {
+	if platform == "" {
+		return "linux", runtime.GOARCH, ""
+	}
+
+	parts := strings.Split(platform, "/")
+	goos := parts[0]
+	goarch := runtime.GOARCH
+	if len(parts) > 1 {
+		goarch = parts[1]
+	}
+	goarm := ""
+	if goarch == "arm" && len(parts) > 2 {
+		goarm = strings.TrimPrefix(parts[2], "v")
+	}
+	return goos, goarch, goarm
+}
+
+// ko copies the kodata directory next to the main package into the image.
+//
+// We can only find it for import paths relative to the build dir.
+func copyKoData(bd model.KoBuild, dst string) (bool, error) {
+	if !strings.HasPrefix(bd.ImportPath, ".") {
+		return false, nil
+	}
+
+	src := filepath.Join(bd.Dir, bd.ImportPath, koDataDirName)
+	info, err := os.Stat(src)
+	if err != nil || !info.IsDir() {
+		return false, nil
+	}
+
+	err = filepath.Walk(src, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if info.IsDir() {
+			return os.MkdirAll(target, info.Mode().Perm()|0700)
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		contents, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, contents, info.Mode().Perm())
+	})
+	return err == nil, err
+}
+
+func koCacheContextDir(bd model.KoBuild) (string, error) {
+	h := sha256.Sum256([]byte(bd.Dir + "\x00" + bd.ImportPath))
+	p, err := xdg.NewTiltDevBase().CacheFile(filepath.Join("ko", hex.EncodeToString(h[:8]), "Dockerfile"))
+	if err != nil {
+		return "", err
+	}
+	return filepath.Dir(p), nil
+}
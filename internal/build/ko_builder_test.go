@@ -0,0 +1,78 @@
+package build
+
+import (
+	"archive/tar"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/testutils"
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestKoDockerfile(t *testing.T) {
+	bd := model.KoBuild{ImportPath: "./cmd/frontend"}
+	assert.Equal(t, `FROM cgr.dev/chainguard/static
+COPY ko-app/frontend /ko-app/frontend
+ENV KO_DATA_PATH=/var/run/ko
+ENTRYPOINT ["/ko-app/frontend"]
+`, koDockerfile(bd, koBinaryName(bd), false))
+
+	bd.BaseImage = "gcr.io/distroless/base"
+	assert.Equal(t, `FROM gcr.io/distroless/base
+COPY kodata /var/run/ko
+COPY ko-app/frontend /ko-app/frontend
+ENV KO_DATA_PATH=/var/run/ko
+ENTRYPOINT ["/ko-app/frontend"]
+`, koDockerfile(bd, koBinaryName(bd), true))
+}
+
+func TestKoBinaryName(t *testing.T) {
+	assert.Equal(t, "frontend", koBinaryName(model.KoBuild{ImportPath: "./cmd/frontend/"}))
+	assert.Equal(t, "frontend", koBinaryName(model.KoBuild{ImportPath: "github.com/example/app/cmd/frontend"}))
+	assert.Equal(t, "app", koBinaryName(model.KoBuild{
+		ImportPath:   ".",
+		CmdImageSpec: v1alpha1.CmdImageSpec{Dir: "/src/app"},
+	}))
+}
+
+func TestKoGoPlatform(t *testing.T) {
+	goos, goarch, goarm := koGoPlatform("linux/arm/v7")
+	assert.Equal(t, []string{"linux", "arm", "7"}, []string{goos, goarch, goarm})
+
+	goos, goarch, goarm = koGoPlatform("linux/arm64")
+	assert.Equal(t, []string{"linux", "arm64", ""}, []string{goos, goarch, goarm})
+}
+
+func TestKoBuild(t *testing.T) {
+	f := newFakeDockerBuildFixture(t)
+	f.WriteFile("go.mod", "module example.com/app\n\ngo 1.18\n")
+	f.WriteFile("cmd/app/main.go", "package main\n\nfunc main() {}\n")
+	f.WriteFile("cmd/app/kodata/index.html", "hello")
+
+	contextDir := tempdir.NewTempDirFixture(t)
+	kb := NewKoBuilder(f.b)
+	kb.contextDir = func(bd model.KoBuild) (string, error) {
+		return contextDir.Path(), nil
+	}
+
+	bd := model.KoBuild{
+		CmdImageSpec: v1alpha1.CmdImageSpec{Dir: f.Path()},
+		ImportPath:   "./cmd/app",
+		Platform:     "linux/amd64",
+		Env:          []string{"GOFLAGS=-mod=mod"},
+	}
+	refs, _, err := kb.Build(f.ctx, f.ps, refSetFromString("gcr.io/foo/app"), bd, nil, nil)
+	require.NoError(t, err)
+	assert.Contains(t, refs.LocalRef.String(), "gcr.io/foo/app:tilt-")
+	assert.Equal(t, "linux/amd64", f.fakeDocker.BuildOptions.Platform)
+
+	testutils.AssertFilesInTar(t, tar.NewReader(f.fakeDocker.BuildContext), []testutils.ExpectedFile{
+		{Path: "Dockerfile", Contents: koDockerfile(bd, "app", true)},
+		{Path: "kodata/index.html", Contents: "hello"},
+	})
+	assert.FileExists(t, contextDir.JoinPath("ko-app", "app"))
+}
@@ -0,0 +1,200 @@
+package build
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/containerd/containerd/errdefs"
+	"github.com/containerd/containerd/remotes"
+	"github.com/containerd/containerd/remotes/docker"
+	"github.com/docker/cli/cli/config"
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+
+	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// The config media type that ORAS uses for artifacts without a more specific type.
+const OCIArtifactDefaultType = "application/vnd.unknown.config.v1+json"
+
+// The layer media type that ORAS uses for files without a more specific type.
+const OCIArtifactDefaultLayerMediaType = "application/vnd.oci.image.layer.v1.tar"
+
+// Builds OCI artifacts that aren't container images (like WASM modules or
+// Helm charts), and pushes them to the registry.
+//
+// Docker can't store these, so we push directly to the registry
+// as part of the build.
+type OCIArtifactBuilder struct {
+	pusher func(ctx context.Context, ref string) (remotes.Pusher, error)
+}
+
+func NewOCIArtifactBuilder() *OCIArtifactBuilder {
+	return &OCIArtifactBuilder{pusher: registryPusher}
+}
+
+type ociBlob struct {
+	desc ocispec.Descriptor
+	data []byte
+}
+
+func (b *OCIArtifactBuilder) Build(ctx context.Context, refs container.RefSet, spec model.OCIArtifactBuild) (container.TaggedRefs, error) {
+	l := logger.Get(ctx)
+	if len(spec.Args) > 0 {
+		cmd := exec.CommandContext(ctx, spec.Args[0], spec.Args[1:]...)
+		cmd.Dir = spec.Dir
+		cmd.Env = logger.DefaultEnv(ctx)
+		w := l.Writer(logger.InfoLvl)
+		cmd.Stdout = w
+		cmd.Stderr = w
+
+		l.Infof("Running artifact build cmd %q", model.Cmd{Argv: spec.Args}.String())
+		err := runBuildCmd(ctx, cmd)
+		if err != nil {
+			return container.TaggedRefs{}, errors.Wrap(err, "oci_artifact build command failed")
+		}
+	}
+
+	manifest, blobs, err := packOCIArtifact(spec)
+	if err != nil {
+		return container.TaggedRefs{}, errors.Wrap(err, "oci_artifact")
+	}
+
+	// Tag by content, so that an unchanged artifact doesn't redeploy anything.
+	tag, err := digestAsTag(manifest.desc.Digest)
+	if err != nil {
+		return container.TaggedRefs{}, errors.Wrap(err, "oci_artifact")
+	}
+	taggedRefs, err := refs.AddTagSuffix(tag)
+	if err != nil {
+		return container.TaggedRefs{}, errors.Wrap(err, "oci_artifact")
+	}
+
+	l.Infof("Pushing %s", container.FamiliarString(taggedRefs.LocalRef))
+	pusher, err := b.pusher(ctx, taggedRefs.LocalRef.String())
+	if err != nil {
+		return container.TaggedRefs{}, errors.Wrap(err, "oci_artifact push")
+	}
+
+	// Blobs first, so that the manifest never points at missing content.
+	for _, blob := range append(blobs, manifest) {
+		err := pushBlob(ctx, pusher, blob)
+		if err != nil {
+			return container.TaggedRefs{}, errors.Wrapf(err, "oci_artifact push %s", blob.desc.Digest)
+		}
+	}
+	return taggedRefs, nil
+}
+
+// Packs the files into an OCI image manifest with one layer per file.
+//
+// Returns the manifest and the blobs (config and layers) it refers to.
+func packOCIArtifact(spec model.OCIArtifactBuild) (ociBlob, []ociBlob, error) {
+	artifactType := spec.ArtifactType
+	if artifactType == "" {
+		artifactType = OCIArtifactDefaultType
+	}
+	layerMediaType := spec.LayerMediaType
+	if layerMediaType == "" {
+		layerMediaType = OCIArtifactDefaultLayerMediaType
+	}
+
+	config := newOCIBlob(artifactType, []byte("{}"))
+	blobs := []ociBlob{config}
+	layers := make([]ocispec.Descriptor, 0, len(spec.Files))
+	for _, f := range spec.Files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return ociBlob{}, nil, err
+		}
+		layer := newOCIBlob(layerMediaType, data)
+		layer.desc.Annotations = map[string]string{
+			ocispec.AnnotationTitle: filepath.Base(f),
+		}
+		blobs = append(blobs, layer)
+		layers = append(layers, layer.desc)
+	}
+
+	manifestData, err := json.Marshal(ocispec.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    config.desc,
+		Layers:    layers,
+	})
+	if err != nil {
+		return ociBlob{}, nil, err
+	}
+	return newOCIBlob(ocispec.MediaTypeImageManifest, manifestData), blobs, nil
+}
+
+func newOCIBlob(mediaType string, data []byte) ociBlob {
+	return ociBlob{
+		desc: ocispec.Descriptor{
+			MediaType: mediaType,
+			Digest:    digest.FromBytes(data),
+			Size:      int64(len(data)),
+		},
+		data: data,
+	}
+}
+
+func pushBlob(ctx context.Context, pusher remotes.Pusher, blob ociBlob) error {
+	w, err := pusher.Push(ctx, blob.desc)
+	if errdefs.IsAlreadyExists(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer func() {
+		_ = w.Close()
+	}()
+
+	_, err = w.Write(blob.data)
+	if err != nil {
+		return err
+	}
+
+	err = w.Commit(ctx, blob.desc.Size, blob.desc.Digest)
+	if err != nil && !errdefs.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
+// Pushes to the registry with the same credentials as the docker CLI.
+// Registries on localhost (like most local cluster registries) use plain HTTP.
+func registryPusher(ctx context.Context, ref string) (remotes.Pusher, error) {
+	authorizer := docker.NewDockerAuthorizer(docker.WithAuthCreds(dockerConfigCreds))
+	resolver := docker.NewResolver(docker.ResolverOptions{
+		Hosts: docker.ConfigureDefaultRegistries(
+			docker.WithPlainHTTP(docker.MatchLocalhost),
+			docker.WithAuthorizer(authorizer),
+		),
+	})
+	return resolver.Pusher(ctx, ref)
+}
+
+func dockerConfigCreds(host string) (string, string, error) {
+	if host == "registry-1.docker.io" {
+		host = "https://index.docker.io/v1/"
+	}
+
+	configFile := config.LoadDefaultConfigFile(io.Discard)
+	auth, err := configFile.GetAuthConfig(host)
+	if err != nil {
+		// Fall back to an anonymous push, like the docker CLI does.
+		return "", "", nil
+	}
+	if auth.IdentityToken != "" {
+		return "", auth.IdentityToken, nil
+	}
+	return auth.Username, auth.Password, nil
+}
@@ -0,0 +1,156 @@
+package build
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/containerd/containerd/content"
+	"github.com/containerd/containerd/errdefs"
+	"github.com/containerd/containerd/remotes"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/testutils"
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestOCIArtifactBuild(t *testing.T) {
+	f := newOCIArtifactFixture(t)
+	f.WriteFile("plugin.wasm", "wasm-bytes")
+
+	refs, err := f.b.Build(f.ctx, refSetWithRegistryFromString("my-plugin", TwoURLRegistry), model.OCIArtifactBuild{
+		Files:        []string{f.JoinPath("plugin.wasm")},
+		ArtifactType: "application/vnd.wasm.config.v1+json",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "localhost:1234/my-plugin", refs.LocalRef.Name())
+	assert.Equal(t, "registry:1234/my-plugin", refs.ClusterRef.Name())
+	assert.Equal(t, "localhost:1234/my-plugin:"+refs.LocalRef.Tag(), f.pushedRef)
+	assert.Contains(t, refs.LocalRef.Tag(), ImageTagPrefix)
+
+	require.Len(t, f.pushed, 3)
+	manifestDesc := f.pushed[2]
+	assert.Equal(t, ocispec.MediaTypeImageManifest, manifestDesc.MediaType)
+
+	var manifest ocispec.Manifest
+	require.NoError(t, json.Unmarshal(f.blobs[manifestDesc.Digest], &manifest))
+	assert.Equal(t, "application/vnd.wasm.config.v1+json", manifest.Config.MediaType)
+	require.Len(t, manifest.Layers, 1)
+	assert.Equal(t, OCIArtifactDefaultLayerMediaType, manifest.Layers[0].MediaType)
+	assert.Equal(t, "plugin.wasm", manifest.Layers[0].Annotations[ocispec.AnnotationTitle])
+	assert.Equal(t, "wasm-bytes", string(f.blobs[manifest.Layers[0].Digest]))
+}
+
+func TestOCIArtifactTagIsContentAddressed(t *testing.T) {
+	f := newOCIArtifactFixture(t)
+	f.WriteFile("chart.tgz", "v1")
+	spec := model.OCIArtifactBuild{Files: []string{f.JoinPath("chart.tgz")}}
+
+	refs1, err := f.b.Build(f.ctx, refSetFromString("gcr.io/foo/chart"), spec)
+	require.NoError(t, err)
+	refs2, err := f.b.Build(f.ctx, refSetFromString("gcr.io/foo/chart"), spec)
+	require.NoError(t, err)
+	assert.Equal(t, refs1.LocalRef.String(), refs2.LocalRef.String())
+
+	f.WriteFile("chart.tgz", "v2")
+	refs3, err := f.b.Build(f.ctx, refSetFromString("gcr.io/foo/chart"), spec)
+	require.NoError(t, err)
+	assert.NotEqual(t, refs1.LocalRef.String(), refs3.LocalRef.String())
+}
+
+func TestOCIArtifactCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a unix shell command")
+	}
+
+	f := newOCIArtifactFixture(t)
+	_, err := f.b.Build(f.ctx, refSetFromString("gcr.io/foo/plugin"), model.OCIArtifactBuild{
+		CmdImageSpec: v1alpha1.CmdImageSpec{
+			Args: model.ToUnixCmd("echo built > out.wasm").Argv,
+			Dir:  f.Path(),
+		},
+		Files: []string{f.JoinPath("out.wasm")},
+	})
+	require.NoError(t, err)
+
+	var manifest ocispec.Manifest
+	require.NoError(t, json.Unmarshal(f.blobs[f.pushed[2].Digest], &manifest))
+	assert.Equal(t, "built\n", string(f.blobs[manifest.Layers[0].Digest]))
+}
+
+func TestOCIArtifactMissingFile(t *testing.T) {
+	f := newOCIArtifactFixture(t)
+	_, err := f.b.Build(f.ctx, refSetFromString("gcr.io/foo/plugin"), model.OCIArtifactBuild{
+		Files: []string{filepath.Join(f.Path(), "missing.wasm")},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "missing.wasm")
+	assert.Empty(t, f.pushed)
+}
+
+type ociArtifactFixture struct {
+	*tempdir.TempDirFixture
+	ctx       context.Context
+	b         *OCIArtifactBuilder
+	pushedRef string
+	pushed    []ocispec.Descriptor
+	blobs     map[digest.Digest][]byte
+}
+
+func newOCIArtifactFixture(t *testing.T) *ociArtifactFixture {
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	f := &ociArtifactFixture{
+		TempDirFixture: tempdir.NewTempDirFixture(t),
+		ctx:            ctx,
+		blobs:          make(map[digest.Digest][]byte),
+	}
+	f.b = &OCIArtifactBuilder{
+		pusher: func(ctx context.Context, ref string) (remotes.Pusher, error) {
+			f.pushedRef = ref
+			return f, nil
+		},
+	}
+	return f
+}
+
+func (f *ociArtifactFixture) Push(ctx context.Context, desc ocispec.Descriptor) (content.Writer, error) {
+	if _, ok := f.blobs[desc.Digest]; ok {
+		return nil, errdefs.ErrAlreadyExists
+	}
+	return &fakeContentWriter{f: f, desc: desc}, nil
+}
+
+type fakeContentWriter struct {
+	f    *ociArtifactFixture
+	desc ocispec.Descriptor
+	buf  bytes.Buffer
+}
+
+func (w *fakeContentWriter) Write(p []byte) (int, error) { return w.buf.Write(p) }
+func (w *fakeContentWriter) Close() error                { return nil }
+func (w *fakeContentWriter) Digest() digest.Digest       { return digest.FromBytes(w.buf.Bytes()) }
+func (w *fakeContentWriter) Status() (content.Status, error) {
+	return content.Status{}, nil
+}
+func (w *fakeContentWriter) Truncate(size int64) error { return nil }
+
+func (w *fakeContentWriter) Commit(ctx context.Context, size int64, expected digest.Digest, opts ...content.Opt) error {
+	if w.Digest() != expected || int64(w.buf.Len()) != size {
+		return fmt.Errorf("unexpected content for %s", expected)
+	}
+	w.f.blobs[expected] = w.buf.Bytes()
+	w.f.pushed = append(w.f.pushed, w.desc)
+	return nil
+}
+
+var _ remotes.Pusher = &ociArtifactFixture{}
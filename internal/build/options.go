@@ -23,6 +23,7 @@ func Options(archive io.Reader, spec v1alpha1.DockerImageSpec) docker.BuildOptio
 		SecretSpecs: spec.Secrets,
 		CacheFrom:   spec.CacheFrom,
 		PullParent:  spec.Pull,
+		NoCache:     spec.NoCache,
 		Platform:    spec.Platform,
 	}
 }
@@ -0,0 +1,29 @@
+package build
+
+import (
+	"context"
+	"time"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+// Don't report progress more often than this, so that a chatty build
+// doesn't flood the API server with status updates.
+const stageProgressInterval = 500 * time.Millisecond
+
+// StageProgressFunc receives the status of every stage of an image build
+// while the build is running.
+type StageProgressFunc func(stages []v1alpha1.DockerImageStageStatus)
+
+type stageProgressKey struct{}
+
+// WithStageProgress asks the image builders to report the progress
+// of BuildKit builds to fn.
+func WithStageProgress(ctx context.Context, fn StageProgressFunc) context.Context {
+	return context.WithValue(ctx, stageProgressKey{}, fn)
+}
+
+func stageProgressFromContext(ctx context.Context) StageProgressFunc {
+	fn, _ := ctx.Value(stageProgressKey{}).(StageProgressFunc)
+	return fn
+}
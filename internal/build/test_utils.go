@@ -67,7 +67,7 @@ func newDockerBuildFixture(t testing.TB) *dockerBuildFixture {
 		t:              t,
 		ctx:            ctx,
 		dCli:           dCli.(*docker.Cli),
-		b:              NewDockerBuilder(dCli, labels),
+		b:              NewDockerBuilder(dCli, labels, nil),
 		reaper:         NewImageReaper(dCli),
 		ps:             ps,
 	}
@@ -90,7 +90,7 @@ func newFakeDockerBuildFixture(t testing.TB) *dockerBuildFixture {
 		t:              t,
 		ctx:            ctx,
 		fakeDocker:     dCli,
-		b:              NewDockerBuilder(dCli, labels),
+		b:              NewDockerBuilder(dCli, labels, NewMemoryBuildCache()),
 		reaper:         NewImageReaper(dCli),
 		ps:             ps,
 	}
@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/tilt/internal/analytics"
+	"github.com/tilt-dev/tilt/internal/controllers/apis/uibutton"
+	engineanalytics "github.com/tilt-dev/tilt/internal/engine/analytics"
+	"github.com/tilt-dev/tilt/pkg/apis"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+type approveNamespacesCmd struct {
+	streams genericclioptions.IOStreams
+	yes     bool
+}
+
+var _ tiltCmd = &approveNamespacesCmd{}
+
+func newApproveNamespacesCmd(streams genericclioptions.IOStreams) *approveNamespacesCmd {
+	return &approveNamespacesCmd{
+		streams: streams,
+	}
+}
+
+func (c *approveNamespacesCmd) name() model.TiltSubcommand { return "approve-namespaces" }
+
+func (c *approveNamespacesCmd) register() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "approve-namespaces RESOURCE_NAME",
+		Short: "Approve deploying a resource to namespaces that the project hasn't deployed to before",
+		Long: `Tilt asks before it applies YAML that hardcodes a namespace
+the project hasn't deployed to before, in case the YAML was copied from
+someone else's project.
+
+Same as clicking the resource's "Approve Namespaces" button in the web UI.
+Tilt remembers the approval for this project, and deploys the resource.
+To allow namespaces in the Tiltfile instead, use allow_k8s_namespaces().
+`,
+		Args: cobra.ExactArgs(1),
+	}
+	cmd.Flags().BoolVarP(&c.yes, "yes", "y", false, "Approve without asking for confirmation")
+	addConnectServerFlags(cmd)
+	return cmd
+}
+
+func (c *approveNamespacesCmd) run(ctx context.Context, args []string) error {
+	resource := args[0]
+
+	a := analytics.Get(ctx)
+	a.Incr("cmd.approve-namespaces", make(engineanalytics.CmdTags))
+	defer a.Flush(time.Second)
+
+	ctrlclient, err := newClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	var button v1alpha1.UIButton
+	err = ctrlclient.Get(ctx, types.NamespacedName{Name: uibutton.ApproveNamespacesButtonName(resource)}, &button)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("resource %q isn't waiting for namespace approval", resource)
+		}
+		return err
+	}
+
+	// The resource's status says which namespaces it's waiting on.
+	var ka v1alpha1.KubernetesApply
+	err = ctrlclient.Get(ctx, types.NamespacedName{Name: resource}, &ka)
+	if err != nil {
+		return err
+	}
+	cond := meta.FindStatusCondition(ka.Status.Conditions, v1alpha1.ApplyConditionNamespaceApprovalRequired)
+	if cond == nil || cond.Status != metav1.ConditionTrue {
+		return fmt.Errorf("resource %q isn't waiting for namespace approval", resource)
+	}
+	namespaces := strings.ReplaceAll(cond.Message, ",", ", ")
+	if !c.yes {
+		_, _ = fmt.Fprintf(c.streams.Out, "Deploy resource %q to namespaces: %s? [y/N] ", resource, namespaces)
+		answer, _ := bufio.NewReader(c.streams.In).ReadString('\n')
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		if answer != "y" && answer != "yes" {
+			_, _ = fmt.Fprintf(c.streams.Out, "Not approved\n")
+			return nil
+		}
+	}
+
+	button.Status.LastClickedAt = apis.NowMicro()
+	err = ctrlclient.Status().Update(ctx, &button)
+	if err != nil {
+		return errors.Wrapf(err, "approving namespaces for resource %q", resource)
+	}
+
+	_, _ = fmt.Fprintf(c.streams.Out, "Approved namespaces for resource %q: %s\n", resource, namespaces)
+	return nil
+}
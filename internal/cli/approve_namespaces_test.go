@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/tilt/internal/controllers/apis/uibutton"
+	"github.com/tilt-dev/tilt/internal/k8s/testyaml"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+func TestApproveNamespaces(t *testing.T) {
+	f := newServerFixture(t)
+
+	createWaitingForApproval(t, f, "fe", "team-b")
+
+	out := bytes.NewBuffer(nil)
+	cmd := newApproveNamespacesCmd(genericclioptions.IOStreams{In: strings.NewReader("y\n"), Out: out})
+	cmd.register()
+	err := cmd.run(f.ctx, []string{"fe"})
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), `Deploy resource "fe" to namespaces: team-b? [y/N]`)
+	assert.Contains(t, out.String(), `Approved namespaces for resource "fe": team-b`)
+
+	var button v1alpha1.UIButton
+	err = f.client.Get(f.ctx, types.NamespacedName{Name: uibutton.ApproveNamespacesButtonName("fe")}, &button)
+	require.NoError(t, err)
+	assert.False(t, button.Status.LastClickedAt.IsZero())
+}
+
+func TestApproveNamespacesDeclined(t *testing.T) {
+	f := newServerFixture(t)
+
+	createWaitingForApproval(t, f, "fe", "team-b")
+
+	out := bytes.NewBuffer(nil)
+	cmd := newApproveNamespacesCmd(genericclioptions.IOStreams{In: strings.NewReader("\n"), Out: out})
+	cmd.register()
+	err := cmd.run(f.ctx, []string{"fe"})
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), "Not approved")
+
+	var button v1alpha1.UIButton
+	err = f.client.Get(f.ctx, types.NamespacedName{Name: uibutton.ApproveNamespacesButtonName("fe")}, &button)
+	require.NoError(t, err)
+	assert.True(t, button.Status.LastClickedAt.IsZero())
+}
+
+func TestApproveNamespacesNotWaiting(t *testing.T) {
+	f := newServerFixture(t)
+
+	cmd := newApproveNamespacesCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+	cmd.register()
+	err := cmd.run(f.ctx, []string{"fe"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `resource "fe" isn't waiting for namespace approval`)
+}
+
+func createWaitingForApproval(t *testing.T, f *serverFixture, resource string, namespaces string) {
+	ka := &v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{Name: resource},
+		Spec:       v1alpha1.KubernetesApplySpec{YAML: testyaml.SanchoYAML},
+	}
+	require.NoError(t, f.client.Create(f.ctx, ka))
+	meta.SetStatusCondition(&ka.Status.Conditions, metav1.Condition{
+		Type:    v1alpha1.ApplyConditionNamespaceApprovalRequired,
+		Status:  metav1.ConditionTrue,
+		Reason:  "UnapprovedNamespaces",
+		Message: namespaces,
+	})
+	require.NoError(t, f.client.Status().Update(f.ctx, ka))
+
+	err := f.client.Create(f.ctx, uibutton.ApproveNamespacesButton(resource, strings.Split(namespaces, ",")))
+	require.NoError(t, err)
+}
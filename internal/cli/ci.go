@@ -15,11 +15,15 @@ import (
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/pkg/logger"
 	"github.com/tilt-dev/tilt/pkg/model"
+	"github.com/tilt-dev/tilt/pkg/model/logstore"
 )
 
 type ciCmd struct {
 	fileName             string
 	outputSnapshotOnExit string
+	fakeCluster          bool
+
+	logPrefixFormat logstore.PrefixFormat
 }
 
 func (c *ciCmd) name() model.TiltSubcommand { return "ci" }
@@ -47,17 +51,26 @@ See blog post for additional information: https://blog.tilt.dev/2020/04/16/how-t
 	addStartServerFlags(cmd)
 	addDevServerFlags(cmd)
 	addTiltfileFlag(cmd, &c.fileName)
+	addLogPrefixFlags(cmd, &c.logPrefixFormat)
 	addKubeContextFlag(cmd)
 
 	cmd.Flags().BoolVar(&logActionsFlag, "logactions", false, "log all actions and state changes")
 	cmd.Flags().Lookup("logactions").Hidden = true
 	cmd.Flags().StringVar(&c.outputSnapshotOnExit, "output-snapshot-on-exit", "",
-		"If specified, Tilt will dump a snapshot of its state to the specified path when it exits")
+		"If specified, Tilt will dump a snapshot of its state to the specified path when it exits. Paths ending in .html get a self-contained HTML snapshot")
+	cmd.Flags().BoolVar(&c.fakeCluster, "fake-cluster", false,
+		"Run against an in-memory Kubernetes cluster and container runtime, instead of the real ones. "+
+			"Useful for testing Tiltfiles. Does not support docker_compose()")
 
 	return cmd
 }
 
 func (c *ciCmd) run(ctx context.Context, args []string) error {
+	err := c.logPrefixFormat.Validate()
+	if err != nil {
+		return err
+	}
+
 	a := analytics.Get(ctx)
 	a.Incr("cmd.ci", nil)
 	defer a.Flush(time.Second)
@@ -67,17 +80,29 @@ func (c *ciCmd) run(ctx context.Context, args []string) error {
 
 	log.SetFlags(log.Flags() &^ (log.Ldate | log.Ltime))
 
+	webAuthToken, err := resolveWebAuthToken()
+	if err != nil {
+		return err
+	}
+
 	webHost := provideWebHost()
 	webURL, _ := provideWebURL(webHost, provideWebPort())
 	startLine := prompt.StartStatusLine(webURL, webHost)
 	log.Print(startLine)
 	log.Print(buildStamp())
+	logWebAuthLogin(webURL, webAuthToken)
 
 	if ok, reason := analytics.IsAnalyticsDisabledFromEnv(); ok {
 		log.Printf("Tilt analytics disabled: %s", reason)
 	}
 
-	cmdCIDeps, err := wireCmdCI(ctx, a, "ci")
+	wireCI := wireCmdCI
+	if c.fakeCluster {
+		log.Print("Using an in-memory cluster (--fake-cluster)")
+		wireCI = wireCmdCIFakeCluster
+	}
+
+	cmdCIDeps, err := wireCI(ctx, a, "ci")
 	if err != nil {
 		deferred.SetOutput(deferred.Original())
 		return err
@@ -93,7 +118,7 @@ func (c *ciCmd) run(ctx context.Context, args []string) error {
 	}
 
 	err = upper.Start(ctx, args, cmdCIDeps.TiltBuild,
-		c.fileName, store.TerminalModeStream, a.UserOpt(), cmdCIDeps.Token,
+		c.fileName, store.TerminalModeStream, c.logPrefixFormat, a.UserOpt(), cmdCIDeps.Token,
 		string(cmdCIDeps.CloudAddress))
 	if err == nil {
 		_, _ = fmt.Fprintln(colorable.NewColorableStdout(),
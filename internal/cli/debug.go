@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tilt-dev/tilt/internal/controllers/core/liveupdate"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+func newDebugCmd() *cobra.Command {
+	result := &cobra.Command{
+		Use:   "debug",
+		Short: "Debug internal Tilt controllers",
+		Long: `Tools for debugging internal Tilt controllers.
+
+Intended to help Tilt developers reproduce bugs in Tilt's reconcilers.
+
+The formats read by these commands do not make any API or compatibility promises,
+and may change frequently.
+`,
+	}
+
+	result.AddCommand(newReplayLiveUpdateCmd())
+
+	return result
+}
+
+type replayLiveUpdateCmd struct {
+	showLogs bool
+}
+
+func newReplayLiveUpdateCmd() *cobra.Command {
+	c := &replayLiveUpdateCmd{}
+	cmd := &cobra.Command{
+		Use:   "replay-liveupdate FILE",
+		Short: "Replay a recording of LiveUpdate reconciles",
+		Long: fmt.Sprintf(`Replays a recording of LiveUpdate reconciles against a fresh reconciler.
+
+To record, run Tilt with %s set to a directory. Tilt writes
+one file per LiveUpdate to that directory.
+
+The replay loads each recorded FileWatch, KubernetesDiscovery, and other input
+into an in-memory apiserver, then reconciles. Nothing is copied to a container.
+Prints the updates and status after each reconcile, and flags any reconcile
+where the replay diverged from the recording.
+
+Files are read from the local filesystem, so the replay is most accurate when
+the files haven't changed since the recording.
+`, liveupdate.RecordDirEnvVar),
+		Example: fmt.Sprintf(`%s=/tmp/lu tilt up
+tilt debug replay-liveupdate /tmp/lu/frontend:update.jsonl`, liveupdate.RecordDirEnvVar),
+		Args: cobra.ExactArgs(1),
+		Run:  c.run,
+	}
+	cmd.Flags().BoolVar(&c.showLogs, "show-logs", false, "Print the logs of the reconciler during replay")
+	return cmd
+}
+
+func (c *replayLiveUpdateCmd) run(cmd *cobra.Command, args []string) {
+	ctx := preCommand(context.Background(), "debug")
+	err := c.replay(ctx, args[0], os.Stdout)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+func (c *replayLiveUpdateCmd) replay(ctx context.Context, path string, out io.Writer) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	records, err := liveupdate.ReadRecords(f)
+	if err != nil {
+		return fmt.Errorf("reading %s: %v", path, err)
+	}
+
+	logs := io.Discard
+	if c.showLogs {
+		logs = out
+	}
+	results, err := liveupdate.Replay(ctx, records, logs)
+	if err != nil {
+		return err
+	}
+
+	mismatches := 0
+	for i, result := range results {
+		record := records[i]
+		marker := ""
+		if !result.Matches {
+			marker = " MISMATCH"
+			mismatches++
+		}
+		_, _ = fmt.Fprintf(out, "#%d %s %s%s\n", i+1, record.Time.Format("15:04:05.000"), record.Name, marker)
+
+		for _, u := range result.Updates {
+			_, _ = fmt.Fprintf(out, "  update %s: %d copied, %d deleted",
+				u.ContainerName, len(u.Copied), len(u.Deleted))
+			if u.Error != "" {
+				_, _ = fmt.Fprintf(out, ", error: %s", u.Error)
+			}
+			_, _ = fmt.Fprintln(out)
+		}
+		_, _ = fmt.Fprintf(out, "  status: %s\n", formatLiveUpdateStatus(result.Status))
+
+		if !result.Matches {
+			_, _ = fmt.Fprintf(out, "  recorded: %d updates, status: %s\n",
+				len(record.Updates), formatLiveUpdateStatus(record.Status))
+		}
+	}
+
+	if mismatches > 0 {
+		return fmt.Errorf("%d of %d reconciles diverged from the recording", mismatches, len(results))
+	}
+	_, _ = fmt.Fprintf(out, "Replayed %d reconciles. All matched the recording.\n", len(results))
+	return nil
+}
+
+func formatLiveUpdateStatus(status v1alpha1.LiveUpdateStatus) string {
+	if status.Failed != nil {
+		return fmt.Sprintf("failed (%s: %s)", status.Failed.Reason, status.Failed.Message)
+	}
+
+	var parts []string
+	for _, c := range status.Containers {
+		parts = append(parts, fmt.Sprintf("%s synced %s",
+			c.ContainerName, c.LastFileTimeSynced.Format("15:04:05.000")))
+	}
+	if len(parts) == 0 {
+		return "no containers"
+	}
+	return strings.Join(parts, ", ")
+}
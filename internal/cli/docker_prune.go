@@ -83,10 +83,15 @@ func (c *dockerPruneCmd) run(ctx context.Context, args []string) error {
 		return err
 	}
 
-	dp := dockerprune.NewDockerPruner(deps.dCli)
+	// There's no API server to record the prune on, so just log it.
+	dp := dockerprune.NewDockerPruner(deps.dCli, nil, nil)
 
 	// TODO: print the commands being run
-	dp.Prune(ctx, tlr.DockerPruneSettings.MaxAge, tlr.DockerPruneSettings.KeepRecent, imgSelectors)
+	var run v1alpha1.DockerPruneRun
+	dp.Prune(ctx, tlr.DockerPruneSettings.MaxAge, dockerprune.KeepPolicyFromSettings(tlr.DockerPruneSettings), imgSelectors, &run)
+	if tlr.DockerPruneSettings.PruneVolumes {
+		dp.PruneVolumes(ctx, tlr.DockerPruneSettings.VolumeMaxAge, dockerprune.ComposeProjectNames(tlr.Manifests), &run)
+	}
 
 	return nil
 }
@@ -112,10 +112,13 @@ func (c *doctorCmd) run(ctx context.Context, args []string) error {
 	// errors getting the version aren't generally useful; in many cases it'll just mean that
 	// the command couldn't exec since Docker Compose isn't installed, for example, so they
 	// are just ignored and the field skipped
-	if composeVersion, composeBuild, err := dcCli.Version(ctx); err == nil {
-		composeField := composeVersion
-		if composeBuild != "" {
-			composeField += fmt.Sprintf(" (build %s)", composeBuild)
+	if composeVersion, err := dcCli.Version(ctx, ""); err == nil {
+		composeField := composeVersion.Version
+		if composeVersion.Build != "" {
+			composeField += fmt.Sprintf(" (build %s)", composeVersion.Build)
+		}
+		if composeVersion.Impl != dockercompose.ImplDockerCompose {
+			composeField += fmt.Sprintf(" (%s)", composeVersion.Impl)
 		}
 		printField("Compose Version", composeField, nil)
 	}
@@ -2,16 +2,21 @@ package cli
 
 import (
 	"context"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 
 	"github.com/tilt-dev/tilt/internal/analytics"
 	ctrltiltfile "github.com/tilt-dev/tilt/internal/controllers/apis/tiltfile"
+	"github.com/tilt-dev/tilt/internal/controllers/apiset"
+	"github.com/tilt-dev/tilt/internal/controllers/core/nestedtilt"
 	"github.com/tilt-dev/tilt/internal/k8s"
 	"github.com/tilt-dev/tilt/internal/localexec"
 	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
@@ -85,27 +90,71 @@ func (c *downCmd) down(ctx context.Context, downDeps DownDeps, args []string) er
 
 	sortedManifests := sortManifestsForDeletion(tlr.Manifests)
 
-	if err := deleteK8sEntities(ctx, sortedManifests, tlr.UpdateSettings, downDeps, c.deleteNamespaces); err != nil {
+	if err := deleteK8sEntitiesByCluster(ctx, sortedManifests, tlr.K8sClusters, tlr.UpdateSettings, downDeps, c.deleteNamespaces); err != nil {
 		return err
 	}
 
-	var dcProject v1alpha1.DockerComposeProject
-	for _, m := range sortedManifests {
-		if m.IsDC() {
-			dcProject = m.DockerComposeTarget().Spec.Project
-			break
+	// If the user wants Tilt to clean up its volumes, that includes the
+	// volumes of their compose projects.
+	pruneSettings := tlr.DockerPruneSettings
+	deleteVolumes := pruneSettings.Enabled && pruneSettings.PruneVolumes
+	for _, dcProject := range dockerComposeProjects(sortedManifests) {
+		dcc := downDeps.dcClient
+		err = dcc.Down(ctx, dcProject, deleteVolumes, logger.Get(ctx).Writer(logger.InfoLvl), logger.Get(ctx).Writer(logger.InfoLvl))
+		if err != nil {
+			return errors.Wrap(err, "Running `docker-compose down`")
 		}
 	}
 
-	if !model.IsEmptyDockerComposeProject(dcProject) {
-		dcc := downDeps.dcClient
-		err = dcc.Down(ctx, dcProject, logger.Get(ctx).Writer(logger.InfoLvl), logger.Get(ctx).Writer(logger.InfoLvl))
+	return downNestedTilts(ctx, tlr.ObjectSet, downDeps.execer)
+}
+
+// Runs the `tilt down` of each child Tilt, because stopping a child
+// doesn't delete what it deployed.
+func downNestedTilts(ctx context.Context, objects apiset.ObjectSet, execer localexec.Execer) error {
+	set := objects.GetSetForType(&v1alpha1.NestedTilt{})
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	tiltPath := nestedtilt.TiltPath()
+	errs := []error{}
+	for _, name := range names {
+		nt := set[name].(*v1alpha1.NestedTilt)
+		err := localexec.OneShotToLogger(ctx, execer, nestedtilt.DownCmd(tiltPath, nt.Spec))
 		if err != nil {
-			return errors.Wrap(err, "Running `docker-compose down`")
+			errs = append(errs, errors.Wrapf(err, "Running `tilt down` for nested tilt %q", name))
 		}
 	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// Returns each docker-compose project once, in the order that its services appear.
+func dockerComposeProjects(manifests []model.Manifest) []v1alpha1.DockerComposeProject {
+	var result []v1alpha1.DockerComposeProject
+	for _, m := range manifests {
+		if !m.IsDC() {
+			continue
+		}
+		dcProject := m.DockerComposeTarget().Spec.Project
+		if model.IsEmptyDockerComposeProject(dcProject) {
+			continue
+		}
 
-	return nil
+		seen := false
+		for _, p := range result {
+			if equality.Semantic.DeepEqual(p, dcProject) {
+				seen = true
+				break
+			}
+		}
+		if !seen {
+			result = append(result, dcProject)
+		}
+	}
+	return result
 }
 
 func sortManifestsForDeletion(manifests []model.Manifest) []model.Manifest {
@@ -156,8 +205,45 @@ func manifestsForNode(node *dependencyNode) []model.Manifest {
 	return append(manifests, node.manifest)
 }
 
-func deleteK8sEntities(ctx context.Context, manifests []model.Manifest, updateSettings model.UpdateSettings, downDeps DownDeps, deleteNamespaces bool) error {
-	entities, deleteCmds, err := k8sToDelete(manifests...)
+// Deletes the objects of each cluster with that cluster's client: the default
+// cluster first, then the clusters declared with k8s_cluster(), in order.
+func deleteK8sEntitiesByCluster(ctx context.Context, manifests []model.Manifest, clusters []*v1alpha1.Cluster, updateSettings model.UpdateSettings, downDeps DownDeps, deleteNamespaces bool) error {
+	byCluster := make(map[string][]model.Manifest)
+	for _, m := range manifests {
+		if m.IsK8s() {
+			byCluster[m.ClusterName()] = append(byCluster[m.ClusterName()], m)
+		}
+	}
+
+	errs := []error{}
+	if ms, ok := byCluster[v1alpha1.ClusterNameDefault]; ok {
+		err := deleteK8sEntities(ctx, downDeps.kClient, ms, updateSettings, downDeps, deleteNamespaces)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for _, c := range clusters {
+		ms, ok := byCluster[c.Name]
+		if !ok || c.Spec.Connection == nil || c.Spec.Connection.Kubernetes == nil {
+			continue
+		}
+		conn := c.Spec.Connection.Kubernetes
+		kCli, err := downDeps.kClientFactory.New(ctx, k8s.KubeContextOverride(conn.Context), k8s.NamespaceOverride(conn.Namespace))
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "Connecting to cluster %q", c.Name))
+			continue
+		}
+		err = deleteK8sEntities(ctx, kCli, ms, updateSettings, downDeps, deleteNamespaces)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "Cluster %q", c.Name))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func deleteK8sEntities(ctx context.Context, kCli k8s.Client, manifests []model.Manifest, updateSettings model.UpdateSettings, downDeps DownDeps, deleteNamespaces bool) error {
+	entities, handoff, deleteCmds, err := k8sToDelete(manifests...)
 	if err != nil {
 		return errors.Wrap(err, "Parsing manifest YAML")
 	}
@@ -189,12 +275,24 @@ func deleteK8sEntities(ctx context.Context, manifests []model.Manifest, updateSe
 	}
 
 	errs := []error{}
-	if len(entities) > 0 {
-		dCtx, cancel := context.WithTimeout(ctx, updateSettings.K8sUpsertTimeout())
-		err = downDeps.kClient.Delete(dCtx, entities, false)
-		cancel()
+	if len(handoff) > 0 {
+		entities, err = resumeGitOps(ctx, kCli, entities, handoff)
 		if err != nil {
-			errs = append(errs, errors.Wrap(err, "Deleting k8s entities"))
+			errs = append(errs, errors.Wrap(err, "Resuming GitOps reconciliation"))
+		}
+	}
+
+	if len(entities) > 0 {
+		// Objects with a higher delete weight are only deleted
+		// once the objects with lower weights are gone.
+		stages := k8s.DeleteWeightStages(entities)
+		for i, stage := range stages {
+			dCtx, cancel := context.WithTimeout(ctx, updateSettings.K8sUpsertTimeout())
+			err = kCli.Delete(dCtx, stage, i < len(stages)-1)
+			cancel()
+			if err != nil {
+				errs = append(errs, errors.Wrap(err, "Deleting k8s entities"))
+			}
 		}
 	}
 
@@ -211,8 +309,11 @@ func deleteK8sEntities(ctx context.Context, manifests []model.Manifest, updateSe
 	return utilerrors.NewAggregate(errs)
 }
 
-func k8sToDelete(manifests ...model.Manifest) ([]k8s.K8sEntity, []model.Cmd, error) {
+// Returns the objects to delete, and which of them belong to resources
+// that took them over from a GitOps tool (see k8s_resource(gitops_handoff=True)).
+func k8sToDelete(manifests ...model.Manifest) ([]k8s.K8sEntity, []k8s.K8sEntity, []model.Cmd, error) {
 	var allEntities []k8s.K8sEntity
+	var handoff []k8s.K8sEntity
 	var deleteCmds []model.Cmd
 	for _, m := range manifests {
 		if !m.IsK8s() {
@@ -229,10 +330,48 @@ func k8sToDelete(manifests ...model.Manifest) ([]k8s.K8sEntity, []model.Cmd, err
 		} else {
 			entities, err := k8s.ParseYAMLFromString(kt.YAML)
 			if err != nil {
-				return nil, nil, err
+				return nil, nil, nil, err
+			}
+			entities = k8s.ReverseSortedEntities(entities)
+			allEntities = append(allEntities, entities...)
+			if kt.GitOpsHandoff {
+				handoff = append(handoff, entities...)
 			}
-			allEntities = append(allEntities, k8s.ReverseSortedEntities(entities)...)
 		}
 	}
-	return allEntities, deleteCmds, nil
+	return allEntities, handoff, deleteCmds, nil
+}
+
+// Hands the objects that GitOps tools manage back to them, rather than deleting them.
+//
+// Returns the entities that we should still delete. If we can't tell which
+// objects the tools manage, we don't delete any of the handoff objects.
+func resumeGitOps(ctx context.Context, kCli k8s.Client, entities []k8s.K8sEntity, handoff []k8s.K8sEntity) ([]k8s.K8sEntity, error) {
+	toDelete := make(map[v1.ObjectReference]bool, len(entities))
+	for _, e := range entities {
+		toDelete[e.ToObjectReference()] = true
+	}
+
+	// Skip objects that we're keeping anyway.
+	var toResume []k8s.K8sEntity
+	isHandoff := make(map[v1.ObjectReference]bool, len(handoff))
+	for _, e := range handoff {
+		if toDelete[e.ToObjectReference()] {
+			toResume = append(toResume, e)
+			isHandoff[e.ToObjectReference()] = true
+		}
+	}
+
+	unmanaged, err := k8s.ResumeGitOps(ctx, kCli, toResume, "")
+	for _, e := range unmanaged {
+		delete(isHandoff, e.ToObjectReference())
+	}
+
+	result := make([]k8s.K8sEntity, 0, len(entities))
+	for _, e := range entities {
+		if !isHandoff[e.ToObjectReference()] {
+			result = append(result, e)
+		}
+	}
+	return result, err
 }
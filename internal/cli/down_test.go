@@ -8,8 +8,14 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
 
 	"github.com/tilt-dev/tilt/internal/analytics"
+	"github.com/tilt-dev/tilt/internal/controllers/apiset"
+	"github.com/tilt-dev/tilt/internal/controllers/core/cluster"
 	"github.com/tilt-dev/tilt/internal/dockercompose"
 	"github.com/tilt-dev/tilt/internal/k8s"
 	"github.com/tilt-dev/tilt/internal/k8s/testyaml"
@@ -29,6 +35,33 @@ func TestDownK8sYAML(t *testing.T) {
 	assert.Contains(t, f.kCli.DeletedYaml, "sancho")
 }
 
+func TestDownDeclaredCluster(t *testing.T) {
+	f := newDownFixture(t)
+
+	edge := newK8sPVCManifest("edge-pvc", "delete")
+	kt := edge.K8sTarget()
+	kt.Cluster = "edge"
+	edge = edge.WithDeployTarget(kt)
+	f.tfl.Result = tiltfile.TiltfileLoadResult{
+		Manifests: append(newK8sManifest(), edge),
+		K8sClusters: []*v1alpha1.Cluster{{
+			ObjectMeta: metav1.ObjectMeta{Name: "edge"},
+			Spec: v1alpha1.ClusterSpec{
+				Connection: &v1alpha1.ClusterConnection{
+					Kubernetes: &v1alpha1.KubernetesClusterConnection{Context: "kind-edge"},
+				},
+			},
+		}},
+	}
+	err := f.cmd.down(f.ctx, f.deps, nil)
+	assert.NoError(t, err)
+	assert.Contains(t, f.kCli.DeletedYaml, "sancho")
+	assert.NotContains(t, f.kCli.DeletedYaml, "edge-pvc")
+	assert.Contains(t, f.clusterKCli.DeletedYaml, "edge-pvc")
+	assert.NotContains(t, f.clusterKCli.DeletedYaml, "sancho")
+	assert.Equal(t, []k8s.KubeContextOverride{"kind-edge"}, *f.clusterContexts)
+}
+
 func TestDownPreservesEntitiesWithKeepLabel(t *testing.T) {
 	f := newDownFixture(t)
 
@@ -41,6 +74,80 @@ func TestDownPreservesEntitiesWithKeepLabel(t *testing.T) {
 	require.NotContains(t, f.kCli.DeletedYaml, "foo")
 }
 
+func TestDownDeletesByDeleteWeight(t *testing.T) {
+	f := newDownFixture(t)
+
+	operator := model.Manifest{Name: "operator"}.WithDeployTarget(model.NewK8sTargetForTesting(`
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: operator
+  annotations:
+    tilt.dev/delete-weight: "10"`))
+	manifests := append([]model.Manifest{}, operator, newK8sPVCManifest("bar", "delete"))
+
+	f.tfl.Result = tiltfile.TiltfileLoadResult{Manifests: manifests}
+	err := f.cmd.down(f.ctx, f.deps, nil)
+	require.NoError(t, err)
+
+	// The operator is deleted in a stage of its own, after everything else.
+	require.Contains(t, f.kCli.DeletedYaml, "operator")
+	require.NotContains(t, f.kCli.DeletedYaml, "bar")
+}
+
+func TestDownResumesGitOps(t *testing.T) {
+	f := newDownFixture(t)
+
+	live := func(apiVersion, kind, namespace, name string, annotations map[string]string) k8s.K8sEntity {
+		u := &unstructured.Unstructured{}
+		u.SetAPIVersion(apiVersion)
+		u.SetKind(kind)
+		u.SetNamespace(namespace)
+		u.SetName(name)
+		u.SetUID(types.UID(name + "-uid"))
+		u.SetAnnotations(annotations)
+		return k8s.NewK8sEntity(u)
+	}
+	f.kCli.Inject(
+		live("v1", "ConfigMap", "default", "web", map[string]string{
+			"argocd.argoproj.io/tracking-id": "shop:/ConfigMap:default/web",
+		}),
+		live("argoproj.io/v1alpha1", "Application", "argocd", "shop", map[string]string{
+			"argocd.argoproj.io/skip-reconcile": "true",
+			k8s.AnnotationGitOpsSuspendedBy:     "web",
+		}))
+
+	kt := model.NewK8sTargetForTesting(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web-dev-only`)
+	kt.GitOpsHandoff = true
+	manifests := []model.Manifest{model.Manifest{Name: "web"}.WithDeployTarget(kt)}
+
+	f.tfl.Result = tiltfile.TiltfileLoadResult{Manifests: manifests}
+	err := f.cmd.down(f.ctx, f.deps, nil)
+	require.NoError(t, err)
+
+	// Argo CD restores the object that it manages, and Tilt deletes the rest.
+	require.Contains(t, f.kCli.DeletedYaml, "web-dev-only")
+	require.NotContains(t, f.kCli.DeletedYaml, "name: web\n")
+
+	app, err := f.kCli.GetByReference(f.ctx, v1.ObjectReference{
+		APIVersion: "argoproj.io/v1alpha1",
+		Kind:       "Application",
+		Namespace:  "argocd",
+		Name:       "shop",
+	})
+	require.NoError(t, err)
+	assert.Empty(t, app.Annotations())
+}
+
 func TestDownPreservesNamespacesByDefault(t *testing.T) {
 	f := newDownFixture(t)
 
@@ -265,6 +372,29 @@ func TestDownK8sDeleteCmd_Error(t *testing.T) {
 	}
 }
 
+func TestDownNestedTilt(t *testing.T) {
+	f := newDownFixture(t)
+
+	objects := apiset.ObjectSet{}
+	objects.Add(&v1alpha1.NestedTilt{
+		ObjectMeta: metav1.ObjectMeta{Name: "payments"},
+		Spec: v1alpha1.NestedTiltSpec{
+			Path: "/src/payments",
+			Port: 10360,
+			Args: []string{"api"},
+		},
+	})
+	f.tfl.Result = tiltfile.TiltfileLoadResult{Manifests: newK8sManifest(), ObjectSet: objects}
+	err := f.cmd.down(f.ctx, f.deps, nil)
+	require.NoError(t, err)
+
+	calls := f.execer.Calls()
+	if assert.Len(t, calls, 1) {
+		assert.Equal(t, []string{"down", "--file", "/src/payments/Tiltfile", "--", "api"}, calls[0].Cmd.Argv[1:])
+		assert.Equal(t, "/src/payments", calls[0].Cmd.Dir)
+	}
+}
+
 func TestDownDCFails(t *testing.T) {
 	f := newDownFixture(t)
 
@@ -276,6 +406,52 @@ func TestDownDCFails(t *testing.T) {
 	}
 }
 
+func TestDownMultipleDCProjects(t *testing.T) {
+	f := newDownFixture(t)
+
+	manifests := append(newDCManifest(), model.Manifest{Name: "be"}.WithDeployTarget(model.DockerComposeTarget{
+		Name: "be",
+		Spec: v1alpha1.DockerComposeServiceSpec{
+			Service: "be",
+			Project: v1alpha1.DockerComposeProject{
+				Name:        "backend",
+				ConfigPaths: []string{"backend/dc.yaml"},
+			},
+		},
+	}))
+	fe2 := newDCManifest()[0]
+	fe2.Name = "fe2"
+	manifests = append(manifests, fe2)
+	f.tfl.Result = tiltfile.TiltfileLoadResult{Manifests: manifests}
+	err := f.cmd.down(f.ctx, f.deps, nil)
+	require.NoError(t, err)
+
+	calls := f.dcc.DownCalls()
+	require.Len(t, calls, 2)
+	assert.Equal(t, []string{"dc.yaml"}, calls[0].Proj.ConfigPaths)
+	assert.Equal(t, []string{"backend/dc.yaml"}, calls[1].Proj.ConfigPaths)
+}
+
+func TestDownDCPruneVolumes(t *testing.T) {
+	f := newDownFixture(t)
+
+	f.tfl.Result = tiltfile.TiltfileLoadResult{Manifests: newDCManifest()}
+	err := f.cmd.down(f.ctx, f.deps, nil)
+	require.NoError(t, err)
+
+	f.tfl.Result = tiltfile.TiltfileLoadResult{
+		Manifests:           newDCManifest(),
+		DockerPruneSettings: model.DockerPruneSettings{Enabled: true, PruneVolumes: true},
+	}
+	err = f.cmd.down(f.ctx, f.deps, nil)
+	require.NoError(t, err)
+
+	calls := f.dcc.DownCalls()
+	require.Len(t, calls, 2)
+	assert.False(t, calls[0].DeleteVolumes)
+	assert.True(t, calls[1].DeleteVolumes)
+}
+
 func TestDownArgs(t *testing.T) {
 	f := newDownFixture(t)
 
@@ -439,6 +615,11 @@ type downFixture struct {
 	dcc    *dockercompose.FakeDCClient
 	kCli   *k8s.FakeK8sClient
 	execer *localexec.FakeExecer
+
+	// The client for clusters declared with k8s_cluster(),
+	// and the contexts that the fixture created clients for.
+	clusterKCli     *k8s.FakeK8sClient
+	clusterContexts *[]k8s.KubeContextOverride
 }
 
 func newDownFixture(t *testing.T) downFixture {
@@ -448,20 +629,28 @@ func newDownFixture(t *testing.T) downFixture {
 	dcc := dockercompose.NewFakeDockerComposeClient(t, ctx)
 	kCli := k8s.NewFakeK8sClient(t)
 	execer := localexec.NewFakeExecer(t)
-	downDeps := DownDeps{tfl, dcc, kCli, execer}
+	clusterKCli := k8s.NewFakeK8sClient(t)
+	clusterContexts := &[]k8s.KubeContextOverride{}
+	kClientFactory := cluster.KubernetesClientFunc(func(_ context.Context, contextOverride k8s.KubeContextOverride, _ k8s.NamespaceOverride) (k8s.Client, error) {
+		*clusterContexts = append(*clusterContexts, contextOverride)
+		return clusterKCli, nil
+	})
+	downDeps := DownDeps{tfl, dcc, kCli, kClientFactory, execer}
 	cmd := &downCmd{downDepsProvider: func(ctx context.Context, tiltAnalytics *analytics.TiltAnalytics, subcommand model.TiltSubcommand) (deps DownDeps, err error) {
 		return downDeps, nil
 	}}
 	ret := downFixture{
-		t:      t,
-		ctx:    ctx,
-		cancel: cancel,
-		cmd:    cmd,
-		deps:   downDeps,
-		tfl:    tfl,
-		dcc:    dcc,
-		kCli:   kCli,
-		execer: execer,
+		t:               t,
+		ctx:             ctx,
+		cancel:          cancel,
+		cmd:             cmd,
+		deps:            downDeps,
+		tfl:             tfl,
+		dcc:             dcc,
+		kCli:            kCli,
+		execer:          execer,
+		clusterKCli:     clusterKCli,
+		clusterContexts: clusterContexts,
 	}
 
 	t.Cleanup(ret.TearDown)
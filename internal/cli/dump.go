@@ -37,6 +37,7 @@ and may change frequently.
 	result.AddCommand(newDumpWebviewCmd())
 	result.AddCommand(newDumpEngineCmd())
 	result.AddCommand(newDumpLogStoreCmd())
+	result.AddCommand(newDumpSchedulerCmd())
 	result.AddCommand(newDumpCliDocsCmd(rootCmd))
 	result.AddCommand(newDumpImageDeployRefCmd())
 	addCommand(result, newOpenapiCmd(streams))
@@ -90,7 +91,7 @@ the build specification, build history, and deployed resources.
 The format of the dump state does not make any API or compatibility promises,
 and may change frequently.
 
-Excludes logs.
+Excludes logs. Includes the decisions of the build scheduler, under Scheduler.
 `,
 		Run:  dumpEngine,
 		Args: cobra.NoArgs,
@@ -118,6 +119,27 @@ and may change frequently.
 	return cmd
 }
 
+func newDumpSchedulerCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "scheduler",
+		Short: "dump the build scheduler's decisions",
+		Long: `Dumps the decisions of the Tilt build scheduler to stdout.
+
+Lists the resources that are building, and the resources that are waiting
+to build, with an explanation of what each one is waiting on.
+
+Useful for figuring out why a resource isn't building.
+
+The format of the dump state does not make any API or compatibility promises,
+and may change frequently.
+`,
+		Run:  dumpScheduler,
+		Args: cobra.NoArgs,
+	}
+	addConnectServerFlags(cmd)
+	return cmd
+}
+
 type dumpCliDocsCmd struct {
 	rootCmd *cobra.Command
 	dir     string
@@ -236,6 +258,23 @@ func dumpEngine(cmd *cobra.Command, args []string) {
 	}
 }
 
+func dumpScheduler(cmd *cobra.Command, args []string) {
+	body := apiGet("dump/scheduler")
+	defer func() {
+		_ = body.Close()
+	}()
+
+	result, err := decodeJSON(body)
+	if err != nil {
+		cmdFail(fmt.Errorf("dump scheduler: %v", err))
+	}
+
+	err = encodeJSON(os.Stdout, result)
+	if err != nil {
+		cmdFail(fmt.Errorf("dump scheduler: %v", err))
+	}
+}
+
 func dumpLogStore(cmd *cobra.Command, args []string) {
 	body := apiGet("dump/engine")
 	defer func() {
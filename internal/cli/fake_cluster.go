@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"context"
+
+	"github.com/tilt-dev/clusterid"
+
+	"github.com/tilt-dev/tilt/internal/controllers/core/cluster"
+	"github.com/tilt-dev/tilt/internal/docker"
+	"github.com/tilt-dev/tilt/internal/k8s"
+)
+
+// The clients for an in-memory cluster and container runtime,
+// for running Tiltfiles end-to-end without a real cluster or Docker daemon
+// (e.g., `tilt ci --fake-cluster`).
+type fakeCluster struct {
+	K8sClient   k8s.Client
+	KubeContext k8s.KubeContext
+	Product     clusterid.Product
+	Namespace   k8s.Namespace
+
+	LocalEnv      docker.LocalEnv
+	ClusterEnv    docker.ClusterEnv
+	LocalClient   docker.LocalClient
+	ClusterClient docker.ClusterClient
+
+	KubernetesClientFactory cluster.KubernetesClientFactory
+	DockerClientFactory     cluster.DockerClientFactory
+}
+
+func provideFakeCluster(ctx context.Context) fakeCluster {
+	kCli := k8s.NewSimulatedClient(ctx)
+	kubeContext := k8s.KubeContext(kCli.APIConfig().CurrentContext)
+
+	// Images built on the fake container runtime are visible to the fake cluster,
+	// so there's never anything to push.
+	env := docker.Env{BuildToKubeContexts: []string{string(kubeContext)}}
+	dCli := docker.NewFakeClient()
+	dCli.FakeEnv = env
+
+	return fakeCluster{
+		K8sClient:   kCli,
+		KubeContext: kubeContext,
+		Product:     k8s.ClusterProductFromAPIConfig(kCli.APIConfig()),
+		Namespace:   k8s.DefaultNamespace,
+
+		LocalEnv:      docker.LocalEnv(env),
+		ClusterEnv:    docker.ClusterEnv(env),
+		LocalClient:   docker.LocalClient(dCli),
+		ClusterClient: docker.ClusterClient(dCli),
+
+		KubernetesClientFactory: cluster.FakeKubernetesClientOrError(kCli, nil),
+		DockerClientFactory:     cluster.FakeDockerClientOrError(dCli, nil),
+	}
+}
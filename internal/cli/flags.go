@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"fmt"
 	"os"
 	"strconv"
 
@@ -10,13 +11,17 @@ import (
 	"github.com/tilt-dev/tilt/internal/k8s"
 	"github.com/tilt-dev/tilt/internal/tiltfile"
 	"github.com/tilt-dev/tilt/pkg/model"
+	"github.com/tilt-dev/tilt/pkg/model/logstore"
 )
 
 var defaultWebHost = "localhost"
 var defaultWebPort = model.DefaultWebPort
 var defaultNamespace = ""
+var defaultWebToken = ""
 var webHostFlag = ""
 var webPortFlag = 0
+var webAuthFlag = false
+var webTokenFlag = ""
 var snapshotViewPortFlag = 0
 var namespaceOverride = ""
 
@@ -34,6 +39,8 @@ func readEnvDefaults() error {
 	if envHost != "" {
 		defaultWebHost = envHost
 	}
+
+	defaultWebToken = os.Getenv("TILT_WEB_TOKEN")
 	return nil
 }
 
@@ -44,6 +51,18 @@ func addTiltfileFlag(cmd *cobra.Command, s *string) {
 	cmd.Flags().StringVarP(s, "file", "f", tiltfile.FileName, "Path to Tiltfile")
 }
 
+// For commands that stream logs to the terminal.
+func addLogPrefixFlags(cmd *cobra.Command, f *logstore.PrefixFormat) {
+	cmd.Flags().IntVar(&f.Width, "log-prefix-width", logstore.DefaultPrefixWidth,
+		"Width of the resource name at the start of each log line")
+	cmd.Flags().StringVar((*string)(&f.Truncation), "log-prefix-truncation", string(logstore.PrefixTruncateEnd),
+		fmt.Sprintf("Where to shorten resource names that don't fit in the log prefix. Possible values: %v", logstore.AllPrefixTruncations))
+	cmd.Flags().StringVar((*string)(&f.Theme), "log-theme", string(logstore.PrefixThemeNone),
+		fmt.Sprintf("Colors for log prefixes. Possible values: %v", logstore.AllPrefixThemes))
+	cmd.Flags().StringVar((*string)(&f.Timestamps), "log-timestamps", string(logstore.TimestampsOff),
+		fmt.Sprintf("Which logs to prefix with a timestamp. Possible values: %v", logstore.AllTimestampModes))
+}
+
 func addKubeContextFlag(cmd *cobra.Command) {
 	cmd.Flags().StringVar(&kubeContextOverride, "context", "", "Kubernetes context override. Equivalent to kubectl --context")
 }
@@ -52,12 +71,15 @@ func addKubeContextFlag(cmd *cobra.Command) {
 func addConnectServerFlags(cmd *cobra.Command) {
 	cmd.Flags().IntVar(&webPortFlag, "port", defaultWebPort, "Port for the Tilt HTTP server. Only necessary if you started Tilt with --port. Overrides TILT_PORT env variable.")
 	cmd.Flags().StringVar(&webHostFlag, "host", defaultWebHost, "Host for the Tilt HTTP server. Only necessary if you started Tilt with --host. Overrides TILT_HOST env variable.")
+	cmd.Flags().StringVar(&webTokenFlag, "web-token", defaultWebToken, "Token for the Tilt HTTP server's API. Only necessary if you started Tilt with --web-auth. Overrides TILT_WEB_TOKEN env variable.")
 }
 
 // For commands that start a web server.
 func addStartServerFlags(cmd *cobra.Command) {
 	cmd.Flags().IntVar(&webPortFlag, "port", defaultWebPort, "Port for the Tilt HTTP server. Set to 0 to disable. Overrides TILT_PORT env variable.")
 	cmd.Flags().StringVar(&webHostFlag, "host", defaultWebHost, "Host for the Tilt HTTP server and default host for any port-forwards. Set to 0.0.0.0 to listen on all interfaces. Overrides TILT_HOST env variable.")
+	cmd.Flags().BoolVar(&webAuthFlag, "web-auth", false, "Require a token for everything on the HTTP server except health checks, e.g., when listening on 0.0.0.0 or behind a tunnel. Generates a token for the session, unless you set --web-token.")
+	cmd.Flags().StringVar(&webTokenFlag, "web-token", defaultWebToken, "Token for the HTTP server. Implies --web-auth. Overrides TILT_WEB_TOKEN env variable.")
 }
 
 // For commands that start a random snapshot view web server.
@@ -56,6 +56,10 @@ func (c *getCmd) register() *cobra.Command {
 		Use:                   "get TYPE [NAME | -l label]",
 		DisableFlagsInUseLine: true,
 		Short:                 "Display one or many resources",
+		Long: `Display one or many resources.
+
+'tilt get triggers [RESOURCE...]' lists why resources were rebuilt, most recent first.
+`,
 	}
 	c.cmd = cmd
 	o := c.options
@@ -77,6 +81,10 @@ func (c *getCmd) run(ctx context.Context, args []string) error {
 	a.Incr("cmd.get", cmdTags.AsMap())
 	defer a.Flush(time.Second)
 
+	if len(args) > 0 && isTriggerHistoryType(args[0]) {
+		return c.runTriggers(args[1:])
+	}
+
 	o := c.options
 	getter, err := wireClientGetter(ctx)
 	if err != nil {
@@ -1,10 +1,12 @@
 package cli
 
 import (
+	"bytes"
 	"context"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/phayes/freeport"
 	"github.com/stretchr/testify/assert"
@@ -47,6 +49,42 @@ func TestGet(t *testing.T) {
 my-sleep`)
 }
 
+func TestPrintTriggerHistory(t *testing.T) {
+	now := time.Unix(1000, 0)
+	triggers := []server.TriggerHistoryEntry{
+		{
+			Resource: "fe",
+			Time:     now.Add(-30 * time.Second),
+			Kind:     server.TriggerKindFileChange,
+			Reason:   "Changed Files",
+			Files:    []string{"a.go", "b.go", "c.go", "d.go"},
+			BuildID:  "3",
+		},
+		{
+			Resource: "be",
+			Time:     now.Add(-2 * time.Minute),
+			Kind:     server.TriggerKindManual,
+			Reason:   "CLI Trigger",
+			BuildID:  "2",
+		},
+	}
+
+	out := bytes.NewBuffer(nil)
+	require.NoError(t, printTriggerHistory(out, triggers, now, false))
+	assert.Equal(t, `RESOURCE   AGE   KIND          REASON          BUILD   FILES
+fe         30s   file-change   Changed Files   3       a.go,b.go,c.go (+1 more)
+be         2m    manual        CLI Trigger     2       <none>
+`, out.String())
+
+	out.Reset()
+	require.NoError(t, printTriggerHistory(out, triggers, now, true))
+	assert.Contains(t, out.String(), "a.go,b.go,c.go,d.go\n")
+
+	out.Reset()
+	require.NoError(t, printTriggerHistory(out, nil, now, false))
+	assert.Equal(t, "No triggers found.\n", out.String())
+}
+
 type serverFixture struct {
 	*tempdir.TempDirFixture
 	ctx       context.Context
@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/cli-runtime/pkg/printers"
+
+	"github.com/tilt-dev/tilt/internal/hud/server"
+)
+
+// The trigger history isn't an API object, so `tilt get triggers`
+// reads it from the web server instead.
+func isTriggerHistoryType(arg string) bool {
+	return arg == "triggers" || arg == "trigger"
+}
+
+// The most files to list for a single trigger in the table.
+const triggerFilesMaxShown = 3
+
+func (c *getCmd) runTriggers(resources []string) error {
+	query := url.Values{}
+	if len(resources) > 0 {
+		query.Set("resources", strings.Join(resources, ","))
+	}
+
+	body := apiGet("trigger_history?" + query.Encode())
+	defer func() {
+		_ = body.Close()
+	}()
+
+	var response server.TriggerHistoryResponse
+	err := json.NewDecoder(body).Decode(&response)
+	if err != nil {
+		return errors.Wrap(err, "reading trigger history")
+	}
+
+	out := c.options.Out
+	format := ""
+	if c.options.PrintFlags.OutputFormat != nil {
+		format = *c.options.PrintFlags.OutputFormat
+	}
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(response)
+	case "", "wide":
+		return printTriggerHistory(out, response.Triggers, time.Now(), format == "wide")
+	}
+	return fmt.Errorf("unsupported output format for triggers %q. Must be one of: json, wide", format)
+}
+
+func printTriggerHistory(out io.Writer, triggers []server.TriggerHistoryEntry, now time.Time, wide bool) error {
+	if len(triggers) == 0 {
+		_, err := fmt.Fprintln(out, "No triggers found.")
+		return err
+	}
+
+	w := printers.GetNewTabWriter(out)
+	_, _ = fmt.Fprintln(w, "RESOURCE\tAGE\tKIND\tREASON\tBUILD\tFILES")
+	for _, t := range triggers {
+		files := t.Files
+		more := ""
+		if !wide && len(files) > triggerFilesMaxShown {
+			more = fmt.Sprintf(" (+%d more)", len(files)-triggerFilesMaxShown)
+			files = files[:triggerFilesMaxShown]
+		}
+		filesText := "<none>"
+		if len(files) > 0 {
+			filesText = strings.Join(files, ",") + more
+		}
+		buildID := t.BuildID
+		if buildID == "" {
+			buildID = "<none>"
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			t.Resource, duration.HumanDuration(now.Sub(t.Time)), t.Kind, t.Reason, buildID, filesText)
+	}
+	return w.Flush()
+}
@@ -2,7 +2,9 @@ package cli
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"regexp"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -14,7 +16,9 @@ import (
 )
 
 type logsCmd struct {
-	follow bool // if true, follow logs (otherwise print current logs and exit)
+	follow  bool   // if true, follow logs (otherwise print current logs and exit)
+	grep    string // if present, only print lines that match this regular expression
+	context int    // how many lines of context to print around each match
 }
 
 func (c *logsCmd) name() model.TiltSubcommand { return "logs" }
@@ -28,17 +32,39 @@ func (c *logsCmd) register() *cobra.Command {
 
 By default, looks for a running Tilt instance on localhost:10350
 (this is configurable with the --port and --host flags).
+
+With --grep, only prints the lines that match a regular expression:
+
+  tilt logs --grep 'error|panic' --context 3 frontend
 `,
 	}
 
 	cmd.Flags().BoolVarP(&c.follow, "follow", "f", false, "If true, stream the requested logs; otherwise, print the requested logs at the current moment in time, then exit.")
 
+	cmd.Flags().StringVar(&c.grep, "grep", "", "If present, only print log lines that match this regular expression.")
+	cmd.Flags().IntVarP(&c.context, "context", "C", 0, "Print this many lines of context around each --grep match. Can't be used with --follow.")
+
 	// TODO: log level flags
 	addConnectServerFlags(cmd)
 	return cmd
 }
 
 func (c *logsCmd) run(ctx context.Context, args []string) error {
+	var grep *regexp.Regexp
+	if c.grep != "" {
+		var err error
+		grep, err = regexp.Compile(c.grep)
+		if err != nil {
+			return fmt.Errorf("invalid --grep %q: %v", c.grep, err)
+		}
+	}
+	if c.context < 0 {
+		return fmt.Errorf("--context must be non-negative")
+	}
+	if c.context > 0 && (grep == nil || c.follow) {
+		return fmt.Errorf("--context can only be used with --grep, and not with --follow")
+	}
+
 	a := analytics.Get(ctx)
 
 	a.Incr("cmd.logs", nil)
@@ -53,5 +79,8 @@ func (c *logsCmd) run(ctx context.Context, args []string) error {
 		return err
 	}
 
-	return server.StreamLogs(ctx, c.follow, logDeps.url, args, logDeps.printer)
+	if grep != nil && !c.follow {
+		return server.SearchLogs(ctx, logDeps.url, logDeps.token, c.grep, c.context, args, logDeps.printer)
+	}
+	return server.StreamLogs(ctx, c.follow, logDeps.url, logDeps.token, args, grep, logDeps.printer)
 }
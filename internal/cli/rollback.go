@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/tilt/internal/analytics"
+	"github.com/tilt-dev/tilt/internal/controllers/apis/uibutton"
+	engineanalytics "github.com/tilt-dev/tilt/internal/engine/analytics"
+	"github.com/tilt-dev/tilt/pkg/apis"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+type rollbackCmd struct {
+	streams genericclioptions.IOStreams
+}
+
+var _ tiltCmd = &rollbackCmd{}
+
+func newRollbackCmd(streams genericclioptions.IOStreams) *rollbackCmd {
+	return &rollbackCmd{
+		streams: streams,
+	}
+}
+
+func (c *rollbackCmd) name() model.TiltSubcommand { return "rollback" }
+
+func (c *rollbackCmd) register() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rollback RESOURCE_NAME",
+		Short: "Re-apply the YAML from a resource's previous successful deploy",
+		Long: `Reverts a bad deploy of a Kubernetes resource by re-applying
+the YAML from the deploy before it.
+
+Same as clicking the resource's "Roll Back" button in the web UI.
+Each rollback goes back one more deploy. Tilt keeps the YAML of the last
+10 successful deploys in the KubernetesApply status, which you can inspect
+with 'tilt get kubernetesapply RESOURCE_NAME -o yaml'. The next change to
+the resource deploys your current code.
+`,
+		Args: cobra.ExactArgs(1),
+	}
+	addConnectServerFlags(cmd)
+	return cmd
+}
+
+func (c *rollbackCmd) run(ctx context.Context, args []string) error {
+	resource := args[0]
+
+	a := analytics.Get(ctx)
+	a.Incr("cmd.rollback", make(engineanalytics.CmdTags))
+	defer a.Flush(time.Second)
+
+	ctrlclient, err := newClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	var button v1alpha1.UIButton
+	err = ctrlclient.Get(ctx, types.NamespacedName{Name: uibutton.RollbackButtonName(resource)}, &button)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("resource %q can't be rolled back. Only Kubernetes resources deployed from YAML support rollbacks", resource)
+		}
+		return err
+	}
+
+	button.Status.LastClickedAt = apis.NowMicro()
+	err = ctrlclient.Status().Update(ctx, &button)
+	if err != nil {
+		return errors.Wrapf(err, "rolling back resource %q", resource)
+	}
+
+	_, _ = fmt.Fprintf(c.streams.Out, "Successfully triggered rollback for resource: %q\n", resource)
+	return nil
+}
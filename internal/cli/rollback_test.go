@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/tilt/internal/controllers/apis/uibutton"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+func TestRollback(t *testing.T) {
+	f := newServerFixture(t)
+
+	err := f.client.Create(f.ctx, uibutton.RollbackButton("fe"))
+	require.NoError(t, err)
+
+	out := bytes.NewBuffer(nil)
+	cmd := newRollbackCmd(genericclioptions.IOStreams{Out: out})
+	cmd.register()
+	err = cmd.run(f.ctx, []string{"fe"})
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), `Successfully triggered rollback for resource: "fe"`)
+
+	var button v1alpha1.UIButton
+	err = f.client.Get(f.ctx, types.NamespacedName{Name: uibutton.RollbackButtonName("fe")}, &button)
+	require.NoError(t, err)
+	assert.False(t, button.Status.LastClickedAt.IsZero())
+}
+
+func TestRollbackNotSupported(t *testing.T) {
+	f := newServerFixture(t)
+
+	cmd := newRollbackCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+	cmd.register()
+	err := cmd.run(f.ctx, []string{"local"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `resource "local" can't be rolled back`)
+}
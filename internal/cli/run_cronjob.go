@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/tilt/internal/analytics"
+	"github.com/tilt-dev/tilt/internal/controllers/apis/uibutton"
+	engineanalytics "github.com/tilt-dev/tilt/internal/engine/analytics"
+	"github.com/tilt-dev/tilt/pkg/apis"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+type runCronJobCmd struct {
+	streams genericclioptions.IOStreams
+}
+
+var _ tiltCmd = &runCronJobCmd{}
+
+func newRunCronJobCmd(streams genericclioptions.IOStreams) *runCronJobCmd {
+	return &runCronJobCmd{
+		streams: streams,
+	}
+}
+
+func (c *runCronJobCmd) name() model.TiltSubcommand { return "run-cronjob" }
+
+func (c *runCronJobCmd) register() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "run-cronjob RESOURCE_NAME",
+		Short: "Run the CronJobs in a resource now",
+		Long: `Creates a Job from each CronJob in the specified resource,
+without waiting for the CronJob's schedule.
+
+Same as clicking the resource's "Run CronJob Now" button in the web UI.
+The Job's logs and status appear on the resource.
+`,
+		Args: cobra.ExactArgs(1),
+	}
+	addConnectServerFlags(cmd)
+	return cmd
+}
+
+func (c *runCronJobCmd) run(ctx context.Context, args []string) error {
+	resource := args[0]
+
+	a := analytics.Get(ctx)
+	a.Incr("cmd.run-cronjob", make(engineanalytics.CmdTags))
+	defer a.Flush(time.Second)
+
+	ctrlclient, err := newClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	var button v1alpha1.UIButton
+	err = ctrlclient.Get(ctx, types.NamespacedName{Name: uibutton.CronJobRunButtonName(resource)}, &button)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("resource %q has no CronJobs", resource)
+		}
+		return err
+	}
+
+	button.Status.LastClickedAt = apis.NowMicro()
+	err = ctrlclient.Status().Update(ctx, &button)
+	if err != nil {
+		return errors.Wrapf(err, "running CronJobs for resource %q", resource)
+	}
+
+	_, _ = fmt.Fprintf(c.streams.Out, "Successfully triggered CronJobs for resource: %q\n", resource)
+	return nil
+}
@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/tilt/internal/controllers/apis/uibutton"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+func TestRunCronJob(t *testing.T) {
+	f := newServerFixture(t)
+
+	err := f.client.Create(f.ctx, uibutton.CronJobRunButton("cron"))
+	require.NoError(t, err)
+
+	out := bytes.NewBuffer(nil)
+	cmd := newRunCronJobCmd(genericclioptions.IOStreams{Out: out})
+	cmd.register()
+	err = cmd.run(f.ctx, []string{"cron"})
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), `Successfully triggered CronJobs for resource: "cron"`)
+
+	var button v1alpha1.UIButton
+	err = f.client.Get(f.ctx, types.NamespacedName{Name: uibutton.CronJobRunButtonName("cron")}, &button)
+	require.NoError(t, err)
+	assert.False(t, button.Status.LastClickedAt.IsZero())
+}
+
+func TestRunCronJobNoCronJobs(t *testing.T) {
+	f := newServerFixture(t)
+
+	cmd := newRunCronJobCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+	cmd.register()
+	err := cmd.run(f.ctx, []string{"fe"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `resource "fe" has no CronJobs`)
+}
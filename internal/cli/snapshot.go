@@ -2,10 +2,12 @@ package cli
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net"
+	"net/http"
 	"os"
 	"strings"
 	"time"
@@ -30,6 +32,7 @@ func newSnapshotCmd() *cobra.Command {
 
 	result.AddCommand(newViewCommand())
 	result.AddCommand(newCreateSnapshotCommand())
+	result.AddCommand(newSaveSnapshotCommand())
 
 	return result
 }
@@ -50,6 +53,9 @@ tilt ci --output-snapshot-on-exit=snapshot.json
 # View that snapshot
 tilt snapshot view snapshot.json
 
+# HTML snapshots from 'tilt snapshot save' work too
+tilt snapshot view snapshot.html
+
 # Or pipe the snapshot to stdin and specify the snapshot as '-'
 curl http://myci.com/path/to/snapshot | tilt snapshot view -
 `,
@@ -144,7 +150,7 @@ func (c *serveCmd) serveSnapshot(snapshotPath string) error {
 		if err != nil {
 			return err
 		}
-		return snapshots.Serve(ctx, l, snapshot)
+		return snapshots.Serve(ctx, l, snapshots.ExtractJSON(snapshot))
 	})
 
 	// give the server a little bit of time to spin up
@@ -224,3 +230,83 @@ func createSnapshot(cmd *cobra.Command, args []string) {
 		cmdFail(fmt.Errorf("error serializing snapshot: %v", err))
 	}
 }
+
+type saveSnapshotCmd struct {
+	format string
+	upload bool
+}
+
+func newSaveSnapshotCommand() *cobra.Command {
+	c := &saveSnapshotCmd{}
+	result := &cobra.Command{
+		Use:   "save <file>",
+		Short: "Saves a self-contained snapshot of a running Tilt instance to a file",
+		Long: `Asks the running Tilt instance to render its resources and logs into a snapshot, and writes it to a file.
+
+JSON snapshots can be viewed with 'tilt snapshot view'. HTML snapshots can be opened
+directly in a browser without a network connection, or with 'tilt snapshot view'.
+
+The format defaults to the one that matches the file extension.`,
+		Example: `
+tilt snapshot save snapshot.json
+tilt snapshot save snapshot.html
+
+# Also upload it to the storage configured with snapshot_settings()
+tilt snapshot save snapshot.json --upload
+`,
+		Args: cobra.ExactArgs(1),
+		Run:  c.run,
+	}
+
+	result.Flags().StringVar(&c.format, "format", "", "Snapshot format: json or html. Defaults to the file extension")
+	result.Flags().BoolVar(&c.upload, "upload", false, "Also upload the snapshot (as JSON) and print a link to it")
+	addConnectServerFlags(result)
+
+	return result
+}
+
+func (c *saveSnapshotCmd) run(_ *cobra.Command, args []string) {
+	path := args[0]
+	format := snapshots.FormatFromPath(path)
+	if c.format != "" {
+		var err error
+		format, err = snapshots.ParseFormat(c.format)
+		if err != nil {
+			cmdFail(err)
+		}
+	}
+
+	body := apiGet("snapshot/export?format=" + string(format))
+	data, err := io.ReadAll(body)
+	_ = body.Close()
+	if err != nil {
+		cmdFail(fmt.Errorf("error reading snapshot from tilt: %v", err))
+	}
+
+	err = os.WriteFile(path, data, 0644)
+	if err != nil {
+		cmdFail(fmt.Errorf("error writing %s: %v", path, err))
+	}
+	fmt.Printf("Saved %s snapshot to %s\n", format, path)
+
+	if c.upload {
+		r, status := apiPostJson("snapshot/upload", nil)
+		b, err := io.ReadAll(r)
+		_ = r.Close()
+		if err != nil {
+			cmdFail(fmt.Errorf("error reading response from tilt api: %v", err))
+		}
+		if status != http.StatusOK {
+			cmdFail(fmt.Errorf("uploading snapshot (%d): %s", status, strings.TrimSpace(string(b))))
+		}
+
+		var response struct {
+			URL string `json:"url"`
+		}
+		err = json.Unmarshal(b, &response)
+		if err != nil {
+			cmdFail(fmt.Errorf("error parsing response from tilt api: %v", err))
+		}
+		fmt.Printf("Uploaded snapshot: %s\n", response.URL)
+	}
+}
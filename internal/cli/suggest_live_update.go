@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/tilt/internal/analytics"
+	"github.com/tilt-dev/tilt/internal/dockerfile"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+type suggestLiveUpdateCmd struct {
+	streams genericclioptions.IOStreams
+
+	contextDir string
+	container  string
+}
+
+var _ tiltCmd = &suggestLiveUpdateCmd{}
+
+func newSuggestLiveUpdateCmd(streams genericclioptions.IOStreams) *suggestLiveUpdateCmd {
+	return &suggestLiveUpdateCmd{streams: streams}
+}
+
+func (c *suggestLiveUpdateCmd) name() model.TiltSubcommand { return "suggest-live-update" }
+
+func (c *suggestLiveUpdateCmd) register() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "suggest-live-update DOCKERFILE",
+		Short: "Propose a live_update for an image, based on its Dockerfile",
+		Long: `Propose a live_update for an image, based on its Dockerfile.
+
+Prints a live_update argument for docker_build() that syncs the files the Dockerfile
+copies from the build context, and re-runs install commands when dependency files
+(like package.json or requirements.txt) change.
+
+This is a starting point, not a guarantee. Tilt can't know what your build steps do,
+so read the result before you paste it into your Tiltfile.
+
+Paths are relative to the current directory, so run this from your Tiltfile's directory.
+`,
+		Example: `  tilt alpha suggest-live-update ./frontend/Dockerfile
+  tilt alpha suggest-live-update ./Dockerfile --context ./src
+  tilt alpha suggest-live-update ./Dockerfile --container my-app-1`,
+		Args: cobra.ExactArgs(1),
+	}
+
+	cmd.Flags().StringVar(&c.contextDir, "context", "",
+		"The Docker build context. Defaults to the directory of the Dockerfile")
+	cmd.Flags().StringVar(&c.container, "container", "",
+		"A running container built from this Dockerfile. If set, its working directory is used for paths that the Dockerfile doesn't make absolute")
+	return cmd
+}
+
+func (c *suggestLiveUpdateCmd) run(ctx context.Context, args []string) error {
+	a := analytics.Get(ctx)
+	a.Incr("cmd.suggest-live-update", map[string]string{})
+
+	dfPath := args[0]
+	contents, err := os.ReadFile(dfPath)
+	if err != nil {
+		return errors.Wrap(err, "reading Dockerfile")
+	}
+
+	workDir := ""
+	if c.container != "" {
+		dCli, err := wireDockerLocalClient(ctx)
+		if err != nil {
+			return errors.Wrap(err, "Failed to init Docker client")
+		}
+		info, err := dCli.ContainerInspect(ctx, c.container)
+		if err != nil {
+			return errors.Wrapf(err, "inspecting container %s", c.container)
+		}
+		if info.Config != nil {
+			workDir = info.Config.WorkingDir
+		}
+	}
+
+	suggestion, err := dockerfile.SuggestLiveUpdate(dockerfile.Dockerfile(contents), workDir)
+	if err != nil {
+		return err
+	}
+	if len(suggestion.Syncs) == 0 {
+		return fmt.Errorf("%s doesn't copy any files from the build context, so there's nothing to live update", dfPath)
+	}
+
+	contextDir := c.contextDir
+	if contextDir == "" {
+		contextDir = filepath.Dir(dfPath)
+	}
+	return printLiveUpdate(c.streams.Out, suggestion, contextDir)
+}
+
+// Prints the suggestion as a live_update argument, with local paths relative
+// to the current directory (like the paths in a Tiltfile).
+func printLiveUpdate(w io.Writer, s dockerfile.LiveUpdateSuggestion, contextDir string) error {
+	localPath := func(p string) string {
+		result := filepath.ToSlash(filepath.Join(contextDir, filepath.FromSlash(p)))
+		if result != "." && !filepath.IsAbs(result) && !strings.HasPrefix(result, "../") {
+			result = "./" + result
+		}
+		return result
+	}
+
+	var sb strings.Builder
+	sb.WriteString("live_update=[\n")
+	for _, sync := range s.Syncs {
+		sb.WriteString(fmt.Sprintf("    sync(%s, %s),\n", starlarkString(localPath(sync.LocalPath)), starlarkString(sync.ContainerPath)))
+	}
+	for _, run := range s.Runs {
+		triggers := make([]string, len(run.Triggers))
+		for i, t := range run.Triggers {
+			triggers[i] = starlarkString(localPath(t))
+		}
+		sb.WriteString(fmt.Sprintf("    run(%s, trigger=[%s]),\n", starlarkString(run.Command), strings.Join(triggers, ", ")))
+	}
+	sb.WriteString("]\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// Tiltfiles conventionally use single quotes.
+func starlarkString(s string) string {
+	if strings.ContainsAny(s, "'\\\n") {
+		return strconv.Quote(s)
+	}
+	return "'" + s + "'"
+}
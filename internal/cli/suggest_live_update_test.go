@@ -0,0 +1,30 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/dockerfile"
+)
+
+func TestPrintLiveUpdate(t *testing.T) {
+	s := dockerfile.LiveUpdateSuggestion{
+		Syncs: []dockerfile.LiveUpdateSync{{LocalPath: ".", ContainerPath: "/app"}},
+		Runs: []dockerfile.LiveUpdateRun{
+			{Command: "yarn install", Triggers: []string{"package.json", "yarn.lock"}},
+			{Command: "echo 'hi'", Triggers: []string{"go.mod"}},
+		},
+	}
+
+	out := &bytes.Buffer{}
+	require.NoError(t, printLiveUpdate(out, s, "frontend"))
+	assert.Equal(t, `live_update=[
+    sync('./frontend', '/app'),
+    run('yarn install', trigger=['./frontend/package.json', './frontend/yarn.lock']),
+    run("echo 'hi'", trigger=['./frontend/go.mod']),
+]
+`, out.String())
+}
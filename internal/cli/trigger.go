@@ -2,6 +2,7 @@ package cli
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -14,11 +15,14 @@ import (
 
 	"github.com/tilt-dev/tilt/internal/analytics"
 	analytics2 "github.com/tilt-dev/tilt/internal/engine/analytics"
+	"github.com/tilt-dev/tilt/internal/hud/server"
 	"github.com/tilt-dev/tilt/pkg/model"
 )
 
 type triggerCmd struct {
 	streams genericclioptions.IOStreams
+	noCache bool
+	labels  []string
 }
 
 var _ tiltCmd = &triggerCmd{}
@@ -33,32 +37,48 @@ func (t triggerCmd) name() model.TiltSubcommand {
 	return "trigger"
 }
 
-func (t triggerCmd) register() *cobra.Command {
+func (t *triggerCmd) register() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "trigger [RESOURCE_NAME]",
-		Short: "Trigger an update for the specified resource",
-		Long: `Trigger an update for the specified resource.
+		Use:   "trigger [RESOURCE_NAME...]",
+		Short: "Trigger an update for the specified resources",
+		Long: `Trigger an update for the specified resources.
+
+With --labels, also triggers every resource with any of the labels.
 
 If the resource has Trigger Mode: Manual and has pending changes, this command will cause those pending changes to be applied.
 
 Otherwise, this command will force a full rebuild.
+
+With --no-cache, the rebuild skips the build cache, like 'docker build --no-cache',
+and won't re-use an image that Tilt built before from the same inputs.
 `,
-		Args: cobra.ExactArgs(1),
+		Example: `tilt trigger frontend backend
+tilt trigger --labels=backend`,
 	}
+	cmd.Flags().BoolVar(&t.noCache, "no-cache", false, "Force a full rebuild that doesn't use the build cache")
+	cmd.Flags().StringSliceVarP(&t.labels, "labels", "l", t.labels, "Trigger all resources with the specified labels")
 	addConnectServerFlags(cmd)
 	return cmd
 }
 
-func (t triggerCmd) run(ctx context.Context, args []string) error {
-	resource := args[0]
+func (t *triggerCmd) run(ctx context.Context, args []string) error {
+	if len(args) == 0 && len(t.labels) == 0 {
+		return errors.New("must specify at least one resource or --labels")
+	}
 
 	a := analytics.Get(ctx)
 	a.Incr("cmd.trigger", make(analytics2.CmdTags))
 	defer a.Flush(time.Second)
 
-	// TODO(maia): this should probably be the triggerPayload struct, but seems
-	//   like a lot of code to move over (to avoid import cycles) for one call.
-	payload := []byte(fmt.Sprintf(`{"manifest_names":[%q], "build_reason": %d}`, resource, model.BuildReasonFlagTriggerCLI))
+	payload, err := json.Marshal(server.TriggerPayload{
+		ManifestNames: args,
+		Labels:        t.labels,
+		BuildReason:   model.BuildReasonFlagTriggerCLI,
+		NoCache:       t.noCache,
+	})
+	if err != nil {
+		return err
+	}
 
 	r, status := apiPostJson("trigger", payload)
 
@@ -68,14 +88,34 @@ func (t triggerCmd) run(ctx context.Context, args []string) error {
 	}
 	_ = r.Close()
 
-	body := strings.TrimSpace(string(b))
-	if status != http.StatusOK {
-		return fmt.Errorf("(%d): %s", status, body)
-	}
-	if len(body) > 0 {
+	var response server.TriggerResponse
+	err = json.Unmarshal(b, &response)
+	if err != nil {
+		body := strings.TrimSpace(string(b))
+		if status != http.StatusOK {
+			return fmt.Errorf("(%d): %s", status, body)
+		}
 		return errors.New(body)
 	}
 
-	_, _ = fmt.Fprintf(t.streams.Out, "Successfully triggered update for resource: %q\n", resource)
+	if len(response.Results) == 0 {
+		return fmt.Errorf("no resources with labels: %s", strings.Join(t.labels, ", "))
+	}
+
+	update := "update"
+	if t.noCache {
+		update = "update without cache"
+	}
+	var failures []string
+	for _, result := range response.Results {
+		if result.Status != server.TriggerStatusQueued {
+			failures = append(failures, result.Error())
+			continue
+		}
+		_, _ = fmt.Fprintf(t.streams.Out, "Successfully triggered %s for resource: %q\n", update, result.Name)
+	}
+	if len(failures) > 0 {
+		return errors.New(strings.Join(failures, "\n"))
+	}
 	return nil
 }
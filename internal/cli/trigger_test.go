@@ -3,6 +3,7 @@ package cli
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"testing"
 
@@ -27,6 +28,41 @@ func TestTriggerSuccess(t *testing.T) {
 	require.Equal(t, 0, errOut.Len())
 }
 
+func TestTriggerNoCache(t *testing.T) {
+	f := newTriggerFixture(t)
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	cmd := newTriggerCmd(streams)
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--no-cache", "foo"})
+	require.NoError(t, err)
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+
+	require.Contains(t, f.requestBody, `"no_cache":true`)
+	require.Equal(t, "Successfully triggered update without cache for resource: \"foo\"\n", out.String())
+}
+
+func TestTriggerMultiple(t *testing.T) {
+	f := newTriggerFixture(t)
+	f.responseBody = `{"results":[` +
+		`{"name":"foo","status":"queued"},` +
+		`{"name":"bar","status":"disabled"},` +
+		`{"name":"baz","status":"not_found"},` +
+		`{"name":"be","status":"queued"}]}`
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	cmd := newTriggerCmd(streams)
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"foo", "bar", "baz", "--labels=backend"})
+	require.NoError(t, err)
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.EqualError(t, err, "resource \"bar\" is currently disabled\nresource \"baz\" does not exist")
+
+	require.Contains(t, f.requestBody, `"manifest_names":["foo","bar","baz"]`)
+	require.Contains(t, f.requestBody, `"labels":["backend"]`)
+	require.Equal(t, "Successfully triggered update for resource: \"foo\"\n"+
+		"Successfully triggered update for resource: \"be\"\n", out.String())
+}
+
 func TestTriggerFailure(t *testing.T) {
 	f := newTriggerFixture(t)
 	f.responseBody = "nothing ever works"
@@ -59,6 +95,7 @@ func TestTriggerNotFound(t *testing.T) {
 }
 
 type triggerFixture struct {
+	requestBody    string
 	responseBody   string
 	responseStatus int
 	ctx            context.Context
@@ -77,11 +114,14 @@ func newTriggerFixture(t *testing.T) *triggerFixture {
 
 	f := &triggerFixture{
 		ctx:            ctx,
+		responseBody:   `{"results":[{"name":"foo","status":"queued"}]}`,
 		responseStatus: http.StatusOK,
 	}
 
 	mux := &http.ServeMux{}
 	mux.HandleFunc("/api/trigger", func(w http.ResponseWriter, req *http.Request) {
+		b, _ := io.ReadAll(req.Body)
+		f.requestBody = string(b)
 		http.Error(w, f.responseBody, f.responseStatus)
 	})
 
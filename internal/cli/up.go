@@ -18,11 +18,13 @@ import (
 	"github.com/tilt-dev/tilt/internal/analytics"
 	engineanalytics "github.com/tilt-dev/tilt/internal/engine/analytics"
 	"github.com/tilt-dev/tilt/internal/hud/prompt"
+	"github.com/tilt-dev/tilt/internal/hud/server"
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/internal/store/liveupdates"
 	"github.com/tilt-dev/tilt/pkg/assets"
 	"github.com/tilt-dev/tilt/pkg/logger"
 	"github.com/tilt-dev/tilt/pkg/model"
+	"github.com/tilt-dev/tilt/pkg/model/logstore"
 	"github.com/tilt-dev/tilt/web"
 )
 
@@ -45,6 +47,8 @@ type upCmd struct {
 
 	legacy bool
 	stream bool
+
+	logPrefixFormat logstore.PrefixFormat
 }
 
 func (c *upCmd) name() model.TiltSubcommand { return "up" }
@@ -82,10 +86,11 @@ local resources--i.e. those using serve_cmd--are terminated when you exit Tilt.
 	addStartServerFlags(cmd)
 	addDevServerFlags(cmd)
 	addTiltfileFlag(cmd, &c.fileName)
+	addLogPrefixFlags(cmd, &c.logPrefixFormat)
 	addKubeContextFlag(cmd)
 	addNamespaceFlag(cmd)
 	cmd.Flags().Lookup("logactions").Hidden = true
-	cmd.Flags().StringVar(&c.outputSnapshotOnExit, "output-snapshot-on-exit", "", "If specified, Tilt will dump a snapshot of its state to the specified path when it exits")
+	cmd.Flags().StringVar(&c.outputSnapshotOnExit, "output-snapshot-on-exit", "", "If specified, Tilt will dump a snapshot of its state to the specified path when it exits. Paths ending in .html get a self-contained HTML snapshot")
 
 	return cmd
 }
@@ -113,6 +118,11 @@ func (c *upCmd) run(ctx context.Context, args []string) error {
 	a := analytics.Get(ctx)
 	defer a.Flush(time.Second)
 
+	err := c.logPrefixFormat.Validate()
+	if err != nil {
+		return err
+	}
+
 	log.SetFlags(log.Flags() &^ (log.Ldate | log.Ltime))
 	isTTY := isatty.IsTerminal(os.Stdout.Fd())
 	termMode := c.initialTermMode(isTTY)
@@ -135,11 +145,17 @@ func (c *upCmd) run(ctx context.Context, args []string) error {
 	deferred := logger.NewDeferredLogger(ctx)
 	ctx = redirectLogs(ctx, deferred)
 
+	webAuthToken, err := resolveWebAuthToken()
+	if err != nil {
+		return err
+	}
+
 	webHost := provideWebHost()
 	webURL, _ := provideWebURL(webHost, provideWebPort())
 	startLine := prompt.StartStatusLine(webURL, webHost)
 	log.Print(startLine)
 	log.Print(buildStamp())
+	logWebAuthLogin(webURL, webAuthToken)
 
 	if ok, reason := analytics.IsAnalyticsDisabledFromEnv(); ok {
 		log.Printf("Tilt analytics disabled: %s", reason)
@@ -166,7 +182,7 @@ func (c *upCmd) run(ctx context.Context, args []string) error {
 	}
 
 	err = upper.Start(ctx, args, cmdUpDeps.TiltBuild,
-		c.fileName, termMode, a.UserOpt(), cmdUpDeps.Token, string(cmdUpDeps.CloudAddress))
+		c.fileName, termMode, c.logPrefixFormat, a.UserOpt(), cmdUpDeps.Token, string(cmdUpDeps.CloudAddress))
 	if err != context.Canceled {
 		return err
 	} else {
@@ -214,6 +230,31 @@ func provideWebMode(b model.TiltBuild) (model.WebMode, error) {
 	return "", model.UnrecognizedWebModeError(string(webModeFlag))
 }
 
+func provideWebAuthToken() model.WebAuthToken {
+	return model.WebAuthToken(webTokenFlag)
+}
+
+// Generates a token for --web-auth for this session, unless the user set one.
+func resolveWebAuthToken() (model.WebAuthToken, error) {
+	if webAuthFlag && webTokenFlag == "" {
+		token, err := server.NewBearerToken()
+		if err != nil {
+			return "", fmt.Errorf("generating web token: %v", err)
+		}
+		webTokenFlag = string(token)
+	}
+	return provideWebAuthToken(), nil
+}
+
+// Tells the user how to log in to a web server that requires a token.
+func logWebAuthLogin(webURL model.WebURL, token model.WebAuthToken) {
+	if token == "" || webURL.Empty() {
+		return
+	}
+	log.Printf("The web UI and API require a token. Log in at %s", webURL.WithAuthToken(token))
+	log.Printf("Set TILT_WEB_TOKEN=%s (or pass --web-token) for Tilt commands that talk to this server.", token)
+}
+
 func provideWebHost() model.WebHost {
 	return model.WebHost(webHostFlag)
 }
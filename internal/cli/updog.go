@@ -24,6 +24,7 @@ import (
 	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
 	"github.com/tilt-dev/tilt/pkg/logger"
 	"github.com/tilt-dev/tilt/pkg/model"
+	"github.com/tilt-dev/tilt/pkg/model/logstore"
 )
 
 type updogCmd struct {
@@ -122,7 +123,7 @@ func (c *updogCmd) run(ctx context.Context, args []string) error {
 	// A lot of these parameters don't matter because we don't have any
 	// controllers registered.
 	err = deps.Upper.Start(ctx, args, deps.TiltBuild,
-		"Tiltfile", store.TerminalModeStream, a.UserOpt(), deps.Token,
+		"Tiltfile", store.TerminalModeStream, logstore.PrefixFormat{}, a.UserOpt(), deps.Token,
 		string(deps.CloudAddress))
 	if err != context.Canceled {
 		return err
@@ -7,6 +7,8 @@ import (
 	"net/http"
 	"os"
 	"strings"
+
+	"github.com/tilt-dev/tilt/internal/hud/server"
 )
 
 func apiHost() string {
@@ -20,7 +22,7 @@ func apiURL(path string) string {
 
 func apiGet(path string) (body io.ReadCloser) {
 	url := apiURL(path)
-	res, err := http.Get(url)
+	res, err := apiDo(http.MethodGet, url, "", nil)
 	if err != nil {
 		cmdFail(fmt.Errorf("Could not connect to Tilt at %s: %v", url, err))
 	}
@@ -33,7 +35,7 @@ func apiGet(path string) (body io.ReadCloser) {
 
 func apiPostJson(path string, payload []byte) (body io.ReadCloser, status int) {
 	url := apiURL(path)
-	res, err := http.Post(url, "application/json", bytes.NewBuffer(payload))
+	res, err := apiDo(http.MethodPost, url, "application/json", bytes.NewBuffer(payload))
 	if err != nil {
 		cmdFail(fmt.Errorf("Could not connect to Tilt at %s: %v", url, err))
 	}
@@ -41,6 +43,19 @@ func apiPostJson(path string, payload []byte) (body io.ReadCloser, status int) {
 	return res.Body, res.StatusCode
 }
 
+// Sends a request to the Tilt API, with the web auth token if there is one.
+func apiDo(method string, url string, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	server.AddWebAuth(req.Header, provideWebAuthToken())
+	return http.DefaultClient.Do(req)
+}
+
 func cmdFail(err error) {
 	_, _ = fmt.Fprintf(os.Stderr, "%v\n", err)
 	os.Exit(1)
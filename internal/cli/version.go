@@ -3,13 +3,17 @@ package cli
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/tilt-dev/tilt/internal/engine/versioncheck"
 	"github.com/tilt-dev/tilt/pkg/model"
 )
 
 type versionCmd struct {
+	check bool
 }
 
 func (c *versionCmd) name() model.TiltSubcommand { return "version" }
@@ -19,10 +23,40 @@ func (c *versionCmd) register() *cobra.Command {
 		Use:   "version",
 		Short: "Current Tilt version",
 	}
+	cmd.Flags().BoolVar(&c.check, "check", false,
+		"Check GitHub for a newer Tilt release, and for a different tilt binary on your PATH")
 	return cmd
 }
 
 func (c *versionCmd) run(ctx context.Context, args []string) error {
 	fmt.Println(buildStamp())
+	if !c.check {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	running := tiltInfo().Version
+	release, warnings, err := versioncheck.Check(ctx, http.DefaultClient, running)
+	if err != nil {
+		return fmt.Errorf("checking for updates: %v", err)
+	}
+
+	if len(warnings) == 0 {
+		fmt.Println("Tilt is up to date")
+		return nil
+	}
+
+	for _, w := range warnings {
+		fmt.Println(w)
+	}
+
+	if versioncheck.IsOutdated(running, release.Version) && len(release.Highlights) > 0 {
+		fmt.Printf("\nHighlights from v%s:\n", release.Version)
+		for _, h := range release.Highlights {
+			fmt.Printf("  * %s\n", h)
+		}
+	}
 	return nil
 }
@@ -28,13 +28,18 @@ import (
 	"github.com/tilt-dev/tilt/internal/cloud/cloudurl"
 	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/internal/controllers"
+	"github.com/tilt-dev/tilt/internal/controllers/core/cluster"
 	"github.com/tilt-dev/tilt/internal/controllers/core/kubernetesdiscovery"
 	"github.com/tilt-dev/tilt/internal/docker"
 	"github.com/tilt-dev/tilt/internal/dockercompose"
 	"github.com/tilt-dev/tilt/internal/engine"
 	engineanalytics "github.com/tilt-dev/tilt/internal/engine/analytics"
+	"github.com/tilt-dev/tilt/internal/engine/bandwidth"
 	"github.com/tilt-dev/tilt/internal/engine/configs"
+	"github.com/tilt-dev/tilt/internal/engine/crashreport"
 	"github.com/tilt-dev/tilt/internal/engine/dockerprune"
+	"github.com/tilt-dev/tilt/internal/engine/external"
+	"github.com/tilt-dev/tilt/internal/engine/idle"
 	"github.com/tilt-dev/tilt/internal/engine/k8srollout"
 	"github.com/tilt-dev/tilt/internal/engine/k8swatch"
 	"github.com/tilt-dev/tilt/internal/engine/local"
@@ -43,6 +48,7 @@ import (
 	"github.com/tilt-dev/tilt/internal/engine/telemetry"
 	"github.com/tilt-dev/tilt/internal/engine/uiresource"
 	"github.com/tilt-dev/tilt/internal/engine/uisession"
+	"github.com/tilt-dev/tilt/internal/engine/versioncheck"
 	"github.com/tilt-dev/tilt/internal/feature"
 	"github.com/tilt-dev/tilt/internal/git"
 	"github.com/tilt-dev/tilt/internal/hud"
@@ -76,8 +82,28 @@ var K8sWireSet = wire.NewSet(
 	ProvideKubeContextOverride,
 	ProvideNamespaceOverride)
 
-var BaseWireSet = wire.NewSet(
+// The clients for the user's Kubernetes cluster and container runtime.
+var ClusterClientWireSet = wire.NewSet(
 	K8sWireSet,
+	docker.SwitchWireSet,
+	dockercompose.NewDockerComposeClient,
+	cluster.ClientFactoryWireSet)
+
+// The clients for an in-memory cluster and container runtime.
+var FakeClusterClientWireSet = wire.NewSet(
+	provideFakeCluster,
+	wire.FieldsOf(new(fakeCluster),
+		"K8sClient", "KubeContext", "Product", "Namespace",
+		"LocalEnv", "ClusterEnv", "LocalClient", "ClusterClient",
+		"KubernetesClientFactory", "DockerClientFactory"),
+	ProvideKubeContextOverride,
+	ProvideNamespaceOverride,
+	docker.ProvideSwitchCli,
+	wire.Bind(new(docker.Client), new(docker.CompositeClient)),
+	dockercompose.NewDockerComposeClient)
+
+// Everything except the clients for the cluster and container runtime.
+var CoreWireSet = wire.NewSet(
 	tiltfile.WireSet,
 	git.ProvideGitRemote,
 
@@ -85,10 +111,6 @@ var BaseWireSet = wire.NewSet(
 	localexec.NewProcessExecer,
 	wire.Bind(new(localexec.Execer), new(*localexec.ProcessExecer)),
 
-	docker.SwitchWireSet,
-
-	dockercompose.NewDockerComposeClient,
-
 	clockwork.NewRealClock,
 	engine.DeployerWireSet,
 	engine.NewBuildController,
@@ -101,6 +123,11 @@ var BaseWireSet = wire.NewSet(
 	configs.NewConfigsController,
 	configs.NewTriggerQueueSubscriber,
 	telemetry.NewController,
+	crashreport.NewReporter,
+	versioncheck.NewController,
+	idle.NewController,
+	bandwidth.NewController,
+	external.NewHealthMonitor,
 	runtimelog.NewDockerComposeLogManager,
 	cloud.WireSet,
 	cloudurl.ProvideAddress,
@@ -120,6 +147,7 @@ var BaseWireSet = wire.NewSet(
 	wire.Bind(new(store.Dispatcher), new(*store.Store)),
 
 	dockerprune.NewDockerPruner,
+	wire.Bind(new(dockerprune.DockerClientProvider), new(*cluster.ConnectionManager)),
 
 	provideTiltInfo,
 	engine.NewUpper,
@@ -136,6 +164,7 @@ var BaseWireSet = wire.NewSet(
 	provideWebURL,
 	provideWebPort,
 	provideWebHost,
+	provideWebAuthToken,
 	server.WireSet,
 	provideAssetServer,
 
@@ -152,6 +181,11 @@ var BaseWireSet = wire.NewSet(
 	wire.Value(feature.MainDefaults),
 )
 
+var BaseWireSet = wire.NewSet(
+	ClusterClientWireSet,
+	CoreWireSet,
+)
+
 var CLIClientWireSet = wire.NewSet(
 	BaseWireSet,
 	cliclient.WireSet,
@@ -162,6 +196,12 @@ var UpWireSet = wire.NewSet(
 	engine.ProvideSubscribers,
 )
 
+var FakeClusterUpWireSet = wire.NewSet(
+	FakeClusterClientWireSet,
+	CoreWireSet,
+	engine.ProvideSubscribers,
+)
+
 func wireTiltfileResult(ctx context.Context, analytics *analytics.TiltAnalytics, subcommand model.TiltSubcommand) (cmdTiltfileResultDeps, error) {
 	wire.Build(UpWireSet, newTiltfileResultDeps)
 	return cmdTiltfileResultDeps{}, nil
@@ -199,6 +239,16 @@ func wireCmdCI(ctx context.Context, analytics *analytics.TiltAnalytics, subcomma
 	return CmdCIDeps{}, nil
 }
 
+func wireCmdCIFakeCluster(ctx context.Context, analytics *analytics.TiltAnalytics, subcommand model.TiltSubcommand) (CmdCIDeps, error) {
+	wire.Build(FakeClusterUpWireSet,
+		cloud.NewSnapshotter,
+		wire.Value(store.EngineModeCI),
+		wire.Value(engineanalytics.CmdTags(map[string]string{})),
+		wire.Struct(new(CmdCIDeps), "*"),
+	)
+	return CmdCIDeps{}, nil
+}
+
 type CmdCIDeps struct {
 	Upper        engine.Upper
 	TiltBuild    model.TiltBuild
@@ -293,19 +343,25 @@ type DownDeps struct {
 	tfl      tiltfile.TiltfileLoader
 	dcClient dockercompose.DockerComposeClient
 	kClient  k8s.Client
-	execer   localexec.Execer
+
+	// Creates clients for the clusters declared with k8s_cluster().
+	kClientFactory cluster.KubernetesClientFactory
+
+	execer localexec.Execer
 }
 
 func ProvideDownDeps(
 	tfl tiltfile.TiltfileLoader,
 	dcClient dockercompose.DockerComposeClient,
 	kClient k8s.Client,
+	kClientFactory cluster.KubernetesClientFactory,
 	execer localexec.Execer) DownDeps {
 	return DownDeps{
-		tfl:      tfl,
-		dcClient: dcClient,
-		kClient:  kClient,
-		execer:   execer,
+		tfl:            tfl,
+		dcClient:       dcClient,
+		kClient:        kClient,
+		kClientFactory: kClientFactory,
+		execer:         execer,
 	}
 }
 
@@ -316,12 +372,14 @@ func wireLogsDeps(ctx context.Context, tiltAnalytics *analytics.TiltAnalytics, s
 
 type LogsDeps struct {
 	url     model.WebURL
+	token   model.WebAuthToken
 	printer *hud.IncrementalPrinter
 }
 
-func ProvideLogsDeps(u model.WebURL, p *hud.IncrementalPrinter) LogsDeps {
+func ProvideLogsDeps(u model.WebURL, token model.WebAuthToken, p *hud.IncrementalPrinter) LogsDeps {
 	return LogsDeps{
 		url:     u,
+		token:   token,
 		printer: p,
 	}
 }
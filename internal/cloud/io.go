@@ -11,6 +11,7 @@ import (
 	"github.com/grpc-ecosystem/grpc-gateway/runtime"
 
 	"github.com/tilt-dev/tilt/internal/hud/webview"
+	"github.com/tilt-dev/tilt/internal/snapshots"
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/pkg/logger"
 	proto_webview "github.com/tilt-dev/tilt/pkg/webview"
@@ -49,7 +50,15 @@ func (s *Snapshotter) WriteSnapshot(ctx context.Context, path string) {
 		CreatedAt: timestamppb.Now(),
 	}
 
-	err = WriteSnapshotTo(ctx, snapshot, f)
+	if snapshots.FormatFromPath(path) == snapshots.FormatHTML {
+		var data []byte
+		data, err = snapshots.Render(snapshot, snapshots.FormatHTML)
+		if err == nil {
+			_, err = f.Write(data)
+		}
+	} else {
+		err = WriteSnapshotTo(ctx, snapshot, f)
+	}
 	if err != nil {
 		logger.Get(ctx).Errorf("Writing snapshot to file: %v", err)
 		return
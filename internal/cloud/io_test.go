@@ -82,12 +82,16 @@ func TestWriteSnapshotTo(t *testing.T) {
             {
               "type": "UpToDate",
               "status": "False",
-              "reason": "UpdatePending"
+              "reason": "UpdatePending",
+              "message": "Waiting to update",
+              "messageCode": "ResourceUpdatePending"
             },
             {
               "type": "Ready",
               "status": "False",
-              "reason": "UpdatePending"
+              "reason": "UpdatePending",
+              "message": "Waiting to update",
+              "messageCode": "ResourceUpdatePending"
             }
           ]
         }
@@ -0,0 +1,45 @@
+package containerupdate
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+)
+
+type compressKey struct{}
+
+// Asks container updaters that stream files over the network to gzip them
+// first. Trades CPU on both ends for less data over a slow link.
+func WithCompression(ctx context.Context) context.Context {
+	return context.WithValue(ctx, compressKey{}, true)
+}
+
+func shouldCompress(ctx context.Context) bool {
+	compress, _ := ctx.Value(compressKey{}).(bool)
+	return compress
+}
+
+// Gzips the reader as it's read.
+func gzipReader(r io.Reader) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		zw := gzip.NewWriter(pw)
+		_, err := io.Copy(zw, r)
+		if err == nil {
+			err = zw.Close()
+		}
+		_ = pw.CloseWithError(err)
+	}()
+	return pr
+}
+
+// An empty tar archive, gzipped. Extracting it checks that tar can read gzip.
+var emptyTarGz = func() []byte {
+	buf := bytes.NewBuffer(nil)
+	zw := gzip.NewWriter(buf)
+	_ = tar.NewWriter(zw).Close()
+	_ = zw.Close()
+	return buf.Bytes()
+}()
@@ -46,7 +46,7 @@ func (cu *DockerUpdater) UpdateContainer(ctx context.Context, cInfo liveupdates.
 	// (whereas the Exec API is part of the CRI and much more battle-tested).
 	// Discussion:
 	// https://github.com/tilt-dev/tilt/issues/3708
-	tarCmd := tarCmd()
+	tarCmd := tarCmd(false)
 	err = cu.dCli.ExecInContainer(ctx, cInfo.ContainerID, tarCmd, archiveToCopy, l.Writer(logger.InfoLvl))
 	if err != nil {
 		if exitCode, ok := ExtractExitCode(err); ok {
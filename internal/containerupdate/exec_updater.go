@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"sync"
 
+	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/internal/k8s"
 	"github.com/tilt-dev/tilt/internal/store/liveupdates"
 	"github.com/tilt-dev/tilt/pkg/logger"
@@ -15,12 +17,20 @@ import (
 
 type ExecUpdater struct {
 	kCli k8s.Client
+
+	mu sync.Mutex
+
+	// Whether each container's tar can extract gzipped archives.
+	gzipSupport map[container.ID]bool
 }
 
 var _ ContainerUpdater = &ExecUpdater{}
 
 func NewExecUpdater(kCli k8s.Client) *ExecUpdater {
-	return &ExecUpdater{kCli: kCli}
+	return &ExecUpdater{
+		kCli:        kCli,
+		gzipSupport: make(map[container.ID]bool),
+	}
 }
 
 func (cu *ExecUpdater) UpdateContainer(ctx context.Context, cInfo liveupdates.Container,
@@ -50,7 +60,13 @@ func (cu *ExecUpdater) UpdateContainer(ctx context.Context, cInfo liveupdates.Co
 	// copy files to container
 	buf := bytes.NewBuffer(nil)
 	tarWriter := io.MultiWriter(w, buf)
-	tarCmd := tarCmd()
+	compress := shouldCompress(ctx) && cu.supportsGzip(ctx, cInfo)
+	tarCmd := tarCmd(compress)
+	if compress {
+		gz := gzipReader(archiveToCopy)
+		defer func() { _ = gz.Close() }()
+		archiveToCopy = gz
+	}
 	err := cu.kCli.Exec(ctx, cInfo.PodID, cInfo.ContainerName, cInfo.Namespace,
 		tarCmd.Argv, archiveToCopy, tarWriter, tarWriter)
 	if err != nil {
@@ -75,6 +91,40 @@ func (cu *ExecUpdater) UpdateContainer(ctx context.Context, cInfo liveupdates.Co
 	return nil
 }
 
+// Checks whether the container's tar can extract gzipped archives.
+// Minimal images often have a tar without gzip.
+//
+// Probes once per container, by extracting an empty gzipped archive.
+func (cu *ExecUpdater) supportsGzip(ctx context.Context, cInfo liveupdates.Container) bool {
+	cu.mu.Lock()
+	supported, ok := cu.gzipSupport[cInfo.ContainerID]
+	cu.mu.Unlock()
+	if ok {
+		return supported
+	}
+
+	probe := tarCmd(true)
+	err := cu.kCli.Exec(ctx, cInfo.PodID, cInfo.ContainerName, cInfo.Namespace,
+		probe.Argv, bytes.NewReader(emptyTarGz), io.Discard, io.Discard)
+	if err == nil {
+		supported = true
+	} else if _, ok := ExtractExitCode(err); !ok {
+		// We couldn't reach the container, so we don't know.
+		// Copy uncompressed this time, and check again next time.
+		return false
+	}
+
+	if !supported {
+		logger.Get(ctx).Infof("Container %s can't extract gzipped files; copying them uncompressed",
+			cInfo.ContainerID.ShortStr())
+	}
+
+	cu.mu.Lock()
+	cu.gzipSupport[cInfo.ContainerID] = supported
+	cu.mu.Unlock()
+	return supported
+}
+
 // wrapK8sTarErr provides user-friendly diagnostics for common failures when
 // running `tar` as part of a Live Update.
 func wrapK8sTarErr(out *bytes.Buffer, err error, cmd model.Cmd, action string) error {
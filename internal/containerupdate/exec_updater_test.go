@@ -2,6 +2,7 @@ package containerupdate
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"errors"
 	"fmt"
@@ -10,6 +11,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"k8s.io/client-go/util/exec"
 
 	"github.com/tilt-dev/tilt/internal/build"
@@ -91,6 +93,64 @@ func TestUpdateContainerTarsArchive(t *testing.T) {
 	}
 }
 
+func TestUpdateContainerCompressesArchive(t *testing.T) {
+	f := newExecFixture(t)
+
+	ctx := WithCompression(f.ctx)
+	err := f.ecu.UpdateContainer(ctx, TestContainerInfo, newReader("hello world"), nil, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedCmd := []string{"tar", "-C", "/", "-x", "-f", "-", "-z"}
+	if assert.Len(t, f.kCli.ExecCalls, 2, "expect a gzip probe and a copy") {
+		assert.Equal(t, expectedCmd, f.kCli.ExecCalls[0].Cmd)
+		assert.Equal(t, emptyTarGz, f.kCli.ExecCalls[0].Stdin)
+
+		call := f.kCli.ExecCalls[1]
+		assert.Equal(t, expectedCmd, call.Cmd)
+
+		zr, err := gzip.NewReader(bytes.NewReader(call.Stdin))
+		require.NoError(t, err)
+		contents, err := io.ReadAll(zr)
+		require.NoError(t, err)
+		assert.Equal(t, "hello world", string(contents))
+	}
+}
+
+func TestUpdateContainerProbesGzipOnce(t *testing.T) {
+	f := newExecFixture(t)
+
+	ctx := WithCompression(f.ctx)
+	for i := 0; i < 2; i++ {
+		err := f.ecu.UpdateContainer(ctx, TestContainerInfo, newReader("hello world"), nil, nil, true)
+		require.NoError(t, err)
+	}
+
+	assert.Len(t, f.kCli.ExecCalls, 3, "expect one gzip probe and two copies")
+}
+
+func TestUpdateContainerWithoutGzipCopiesUncompressed(t *testing.T) {
+	f := newExecFixture(t)
+
+	// tar exits 2 when it can't run gzip.
+	f.kCli.ExecErrors = []error{exec.CodeExitError{Err: fmt.Errorf("gzip: not found"), Code: 2}}
+
+	ctx := WithCompression(f.ctx)
+	for i := 0; i < 2; i++ {
+		err := f.ecu.UpdateContainer(ctx, TestContainerInfo, newReader("hello world"), nil, nil, true)
+		require.NoError(t, err)
+	}
+
+	expectedCmd := []string{"tar", "-C", "/", "-x", "-f", "-"}
+	if assert.Len(t, f.kCli.ExecCalls, 3, "expect one gzip probe and two copies") {
+		for _, call := range f.kCli.ExecCalls[1:] {
+			assert.Equal(t, expectedCmd, call.Cmd)
+			assert.Equal(t, []byte("hello world"), call.Stdin)
+		}
+	}
+}
+
 func TestUpdateContainerRunsCommands(t *testing.T) {
 	f := newExecFixture(t)
 
@@ -158,9 +218,7 @@ type execUpdaterFixture struct {
 
 func newExecFixture(t testing.TB) *execUpdaterFixture {
 	fakeCli := k8s.NewFakeK8sClient(t)
-	cu := &ExecUpdater{
-		kCli: fakeCli,
-	}
+	cu := NewExecUpdater(fakeCli)
 	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
 
 	return &execUpdaterFixture{
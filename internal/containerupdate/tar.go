@@ -10,10 +10,12 @@ import (
 // sufficient permissions to write the extracted files.
 const TarExitCodePermissionDenied = 2
 
-func tarCmd() model.Cmd {
-	return model.Cmd{
-		Argv: []string{"tar", "-C", "/", "-x", "-f", "-"},
+func tarCmd(gzip bool) model.Cmd {
+	argv := []string{"tar", "-C", "/", "-x", "-f", "-"}
+	if gzip {
+		argv = append(argv, "-z")
 	}
+	return model.Cmd{Argv: argv}
 }
 
 func permissionDeniedErr(err error) error {
@@ -129,6 +129,37 @@ func (f *FakeClientProvider) EnsureK8sClusterError(ctx context.Context, clusterN
 		})
 }
 
+// EnsureK8sClusterUnreachable marks the cluster as unreachable, like the
+// Cluster reconciler does when a health check can't reach the cluster.
+//
+// The client stays in place, so that callers can keep using it.
+func (f *FakeClientProvider) EnsureK8sClusterUnreachable(ctx context.Context, clusterNN types.NamespacedName,
+	clusterErr error) {
+	f.t.Helper()
+
+	_, rev := f.EnsureK8sCluster(ctx, clusterNN)
+	f.upsertClusterStatus(ctx, clusterNN,
+		v1alpha1.ClusterStatus{
+			Arch:        "amd64",
+			Version:     "1.23.5",
+			ConnectedAt: &rev,
+			Connection: &v1alpha1.ClusterConnectionStatus{
+				Kubernetes: &v1alpha1.KubernetesClusterConnectionStatus{
+					Product: "kind",
+				},
+			},
+			Error: clusterErr.Error(),
+			Conditions: []metav1.Condition{
+				{
+					Type:    v1alpha1.ClusterConditionReachable,
+					Status:  metav1.ConditionFalse,
+					Reason:  v1alpha1.ClusterReasonUnreachable,
+					Message: clusterErr.Error(),
+				},
+			},
+		})
+}
+
 func (f *FakeClientProvider) EnsureDefaultK8sCluster(ctx context.Context) *k8s.FakeK8sClient {
 	kCli, _ := f.EnsureK8sCluster(ctx, types.NamespacedName{Name: "default"})
 	return kCli
@@ -0,0 +1,26 @@
+package cluster
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+// UnreachableError returns an error if the most recent health check
+// couldn't reach the cluster, or nil otherwise.
+//
+// Controllers that depend on the cluster use it to report that the cluster
+// is unreachable, rather than waiting for their own requests to time out.
+func UnreachableError(cluster *v1alpha1.Cluster) error {
+	if cluster == nil {
+		return nil
+	}
+	cond := meta.FindStatusCondition(cluster.Status.Conditions, v1alpha1.ClusterConditionReachable)
+	if cond == nil || cond.Status != metav1.ConditionFalse {
+		return nil
+	}
+	return fmt.Errorf("cluster %q is unreachable: %s", cluster.Name, cond.Message)
+}
@@ -0,0 +1,70 @@
+package configmap
+
+import (
+	"context"
+	"strconv"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+// Stores whether Tilt is in low-bandwidth mode, so that it can be toggled
+// at runtime from the UI (or with `tilt patch`).
+const BandwidthName = "tilt-bandwidth"
+const BandwidthLowKey = "low"
+
+// Whether Tilt is in low-bandwidth mode.
+//
+// Defaults to false if the ConfigMap doesn't exist or can't be read, so
+// that a missing ConfigMap never changes how Tilt behaves.
+func LowBandwidth(ctx context.Context, client client.Reader) bool {
+	var cm v1alpha1.ConfigMap
+	err := client.Get(ctx, types.NamespacedName{Name: BandwidthName}, &cm)
+	if err != nil {
+		return false
+	}
+	return IsLowBandwidth(&cm)
+}
+
+func IsLowBandwidth(cm *v1alpha1.ConfigMap) bool {
+	if cm == nil {
+		return false
+	}
+	low, _ := strconv.ParseBool(cm.Data[BandwidthLowKey])
+	return low
+}
+
+func BandwidthCreate(low bool) *v1alpha1.ConfigMap {
+	return &v1alpha1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: BandwidthName,
+		},
+		Data: map[string]string{BandwidthLowKey: strconv.FormatBool(low)},
+	}
+}
+
+// Switches low-bandwidth mode on or off, creating the ConfigMap if needed.
+func SetLowBandwidth(ctx context.Context, client client.Client, low bool) error {
+	var cm v1alpha1.ConfigMap
+	err := client.Get(ctx, types.NamespacedName{Name: BandwidthName}, &cm)
+	if apierrors.IsNotFound(err) {
+		return client.Create(ctx, BandwidthCreate(low))
+	}
+	if err != nil {
+		return err
+	}
+
+	if IsLowBandwidth(&cm) == low {
+		return nil
+	}
+	update := cm.DeepCopy()
+	if update.Data == nil {
+		update.Data = make(map[string]string)
+	}
+	update.Data[BandwidthLowKey] = strconv.FormatBool(low)
+	return client.Update(ctx, update)
+}
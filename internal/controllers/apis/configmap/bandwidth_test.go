@@ -0,0 +1,27 @@
+package configmap
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/controllers/fake"
+)
+
+func TestLowBandwidthMissingConfigMap(t *testing.T) {
+	fc := fake.NewFakeTiltClient()
+	require.False(t, LowBandwidth(context.Background(), fc))
+	require.False(t, IsLowBandwidth(nil))
+}
+
+func TestSetLowBandwidth(t *testing.T) {
+	ctx := context.Background()
+	fc := fake.NewFakeTiltClient()
+
+	require.NoError(t, SetLowBandwidth(ctx, fc, true))
+	require.True(t, LowBandwidth(ctx, fc))
+
+	require.NoError(t, SetLowBandwidth(ctx, fc, false))
+	require.False(t, LowBandwidth(ctx, fc))
+}
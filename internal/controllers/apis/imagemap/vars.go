@@ -0,0 +1,150 @@
+package imagemap
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/docker/distribution/reference"
+	dockertypes "github.com/docker/docker/api/types"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+// Placeholders that deploy specs can use to refer to the images they depend on,
+// like $(TILT_IMAGE_DIGEST:my-image).
+const (
+	// The digest of the image. If the image was pushed to a registry,
+	// this is the registry's manifest digest. Otherwise, it's the image ID.
+	VarImageDigest = "TILT_IMAGE_DIGEST"
+
+	// The tag that Tilt gave the image.
+	VarImageTag = "TILT_IMAGE_TAG"
+
+	// When Tilt started building the image, in RFC 3339 format.
+	VarImageBuildTime = "TILT_IMAGE_BUILD_TIME"
+)
+
+var varRegexp = regexp.MustCompile(`\$\((TILT_IMAGE_[A-Z_]+):([^()\s]+)\)`)
+
+// Looks up images in the local image store.
+//
+// Satisfied by docker.Client.
+type ImageInspector interface {
+	ImageInspectWithRaw(ctx context.Context, imageID string) (dockertypes.ImageInspect, []byte, error)
+}
+
+// Returns true if the string refers to any image placeholders.
+func HasVars(s string) bool {
+	return varRegexp.MatchString(s)
+}
+
+// Replaces the image placeholders in the string with the
+// current status of the given ImageMaps.
+//
+// Placeholders refer to images by the name they were built with
+// (e.g., the first argument of docker_build()).
+func ExpandVars(ctx context.Context, s string, imageMapNames []string, imageMaps map[types.NamespacedName]*v1alpha1.ImageMap, inspector ImageInspector) (string, error) {
+	var err error
+	result := varRegexp.ReplaceAllStringFunc(s, func(match string) string {
+		if err != nil {
+			return match
+		}
+
+		groups := varRegexp.FindStringSubmatch(match)
+		varName, imageName := groups[1], groups[2]
+		im := findImageMap(imageName, imageMapNames, imageMaps)
+		if im == nil {
+			err = fmt.Errorf("%s: no image named %q is deployed with this resource", match, imageName)
+			return match
+		}
+
+		var value string
+		value, err = varValue(ctx, varName, im, inspector)
+		if err != nil {
+			err = fmt.Errorf("%s: %v", match, err)
+			return match
+		}
+		return value
+	})
+	if err != nil {
+		return "", err
+	}
+	return result, nil
+}
+
+func findImageMap(imageName string, imageMapNames []string, imageMaps map[types.NamespacedName]*v1alpha1.ImageMap) *v1alpha1.ImageMap {
+	for _, name := range imageMapNames {
+		im, ok := imageMaps[types.NamespacedName{Name: name}]
+		if !ok {
+			continue
+		}
+		if im.Spec.Selector == imageName {
+			return im
+		}
+		ref, err := container.ParseNamed(im.Spec.Selector)
+		if err == nil && reference.FamiliarName(ref) == imageName {
+			return im
+		}
+	}
+	return nil
+}
+
+func varValue(ctx context.Context, varName string, im *v1alpha1.ImageMap, inspector ImageInspector) (string, error) {
+	switch varName {
+	case VarImageTag:
+		ref, err := container.ParseNamedTagged(im.Status.Image)
+		if err != nil {
+			return "", fmt.Errorf("image hasn't been built yet")
+		}
+		return ref.Tag(), nil
+
+	case VarImageBuildTime:
+		if im.Status.BuildStartTime == nil {
+			return "", fmt.Errorf("image hasn't been built yet")
+		}
+		return im.Status.BuildStartTime.UTC().Format(time.RFC3339), nil
+
+	case VarImageDigest:
+		return imageDigest(ctx, im, inspector)
+	}
+	return "", fmt.Errorf("unknown variable %s. Expected one of: %s, %s, %s",
+		varName, VarImageDigest, VarImageTag, VarImageBuildTime)
+}
+
+func imageDigest(ctx context.Context, im *v1alpha1.ImageMap, inspector ImageInspector) (string, error) {
+	localRef := im.Status.ImageFromLocal
+	if localRef == "" {
+		localRef = im.Status.Image
+	}
+	if localRef == "" {
+		return "", fmt.Errorf("image hasn't been built yet")
+	}
+
+	inspect, _, err := inspector.ImageInspectWithRaw(ctx, localRef)
+	if err != nil {
+		return "", fmt.Errorf("looking up digest: %v", err)
+	}
+
+	named, err := container.ParseNamed(localRef)
+	if err == nil {
+		for _, repoDigest := range inspect.RepoDigests {
+			canonical, err := reference.ParseNormalizedNamed(repoDigest)
+			if err != nil {
+				continue
+			}
+			digested, ok := canonical.(reference.Canonical)
+			if ok && digested.Name() == named.Name() {
+				return digested.Digest().String(), nil
+			}
+		}
+	}
+
+	if inspect.ID == "" {
+		return "", fmt.Errorf("looking up digest: image %s not found", localRef)
+	}
+	return inspect.ID, nil
+}
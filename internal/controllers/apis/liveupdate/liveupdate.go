@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 
 	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/k8s"
 	"github.com/tilt-dev/tilt/pkg/apis"
 	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
 	"github.com/tilt-dev/tilt/pkg/model"
@@ -69,12 +70,19 @@ func KubernetesSelectorMatchesContainer(
 	}
 
 	// LiveUpdateKubernetesSelector must specify EITHER image OR ImageMap OR container name
-	if selector.Image != "" {
-		return container.ImageNamesEqual(selector.Image, ctr.Image)
-	}
 	if selector.ContainerName != "" {
 		return selector.ContainerName == ctr.Name
 	}
+
+	// Never match service mesh sidecars by image. The mesh injects them, so an
+	// image match is a coincidence, and syncing files into them would break the mesh.
+	if k8s.IsServiceMeshSidecar(ctr.Name) {
+		return false
+	}
+
+	if selector.Image != "" {
+		return container.ImageNamesEqual(selector.Image, ctr.Image)
+	}
 	if selector.ImageMapName != "" {
 		return imageMap != nil && container.ImageNamesEqual(
 			imageMap.Status.ImageFromCluster, ctr.Image)
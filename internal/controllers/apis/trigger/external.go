@@ -0,0 +1,181 @@
+package trigger
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/jonboulle/clockwork"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/jsonpath"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/tilt-dev/tilt/internal/timecmp"
+	"github.com/tilt-dev/tilt/pkg/apis"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+// ParseWatchObject parses an API object reference in kind/name format,
+// like "configmap/schema-version".
+//
+// Returns an empty object of the right type, and the object name.
+func ParseWatchObject(ref string) (client.Object, string, error) {
+	parts := strings.Split(ref, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, "", fmt.Errorf("invalid object %q: expected kind/name", ref)
+	}
+
+	obj, _, err := ParseWatchKind(parts[0])
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid object %q: %v", ref, err)
+	}
+	return obj, parts[1], nil
+}
+
+// ParseWatchKind looks up an API type by kind, like "ConfigMap",
+// or by resource, like "configmaps". Case-insensitive.
+//
+// Returns an empty object of the right type, and its kind.
+func ParseWatchKind(kind string) (client.Object, string, error) {
+	for _, obj := range v1alpha1.AllResourceObjects() {
+		objKind := reflect.TypeOf(obj).Elem().Name()
+		resource := obj.GetGroupVersionResource().Resource
+		if strings.EqualFold(kind, objKind) || strings.EqualFold(kind, resource) {
+			return obj.New().(client.Object), objKind, nil
+		}
+	}
+	return nil, "", fmt.Errorf("unknown kind %q", kind)
+}
+
+// ParseWatchField parses a JSONPath expression, like "{.data.version}".
+//
+// The braces are optional.
+func ParseWatchField(field string) (*jsonpath.JSONPath, error) {
+	if !strings.HasPrefix(field, "{") {
+		field = fmt.Sprintf("{%s}", field)
+	}
+
+	jp := jsonpath.New("watch-field")
+	jp.AllowMissingKeys(true)
+	err := jp.Parse(field)
+	if err != nil {
+		return nil, fmt.Errorf("invalid field %q: %v", field, err)
+	}
+	return jp, nil
+}
+
+// WatchFieldValue reads a field from the object.
+//
+// Fields that are missing from the object are read as the empty string.
+func WatchFieldValue(obj runtime.Object, field *jsonpath.JSONPath) (string, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return "", err
+	}
+
+	buf := bytes.NewBuffer(nil)
+	err = field.Execute(buf, content)
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// ObjectFieldTracker remembers the values of the object fields
+// that a controller's RestartOnSpecs watch, and when each one last changed.
+type ObjectFieldTracker struct {
+	clock  clockwork.Clock
+	mu     sync.Mutex
+	fields map[objectFieldKey]objectFieldState
+}
+
+type objectFieldKey struct {
+	owner  types.NamespacedName
+	source v1alpha1.ObjectFieldSource
+}
+
+type objectFieldState struct {
+	value     string
+	changedAt metav1.MicroTime
+}
+
+func NewObjectFieldTracker(clock clockwork.Clock) *ObjectFieldTracker {
+	return &ObjectFieldTracker{
+		clock:  clock,
+		fields: make(map[objectFieldKey]objectFieldState),
+	}
+}
+
+// LastChange reads the object fields that the owner watches,
+// and returns the most recent time that one of them changed.
+//
+// The first value we read for a field doesn't count as a change.
+// If the object doesn't exist, the value is empty.
+func (t *ObjectFieldTracker) LastChange(ctx context.Context, cli client.Reader, owner types.NamespacedName, restartOn *v1alpha1.RestartOnSpec) (metav1.MicroTime, error) {
+	if restartOn == nil {
+		return metav1.MicroTime{}, nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var cur metav1.MicroTime
+	for _, src := range restartOn.ObjectFields {
+		value, err := objectFieldValue(ctx, cli, src)
+		if err != nil {
+			return metav1.MicroTime{}, err
+		}
+
+		key := objectFieldKey{owner: owner, source: src}
+		state, ok := t.fields[key]
+		if !ok {
+			state = objectFieldState{value: value}
+		} else if state.value != value {
+			state = objectFieldState{value: value, changedAt: apis.NewMicroTime(t.clock.Now())}
+		}
+		t.fields[key] = state
+
+		if timecmp.After(state.changedAt, cur) {
+			cur = state.changedAt
+		}
+	}
+	return cur, nil
+}
+
+// Forget drops the fields that the owner watches, e.g., when it's deleted.
+func (t *ObjectFieldTracker) Forget(owner types.NamespacedName) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for key := range t.fields {
+		if key.owner == owner {
+			delete(t.fields, key)
+		}
+	}
+}
+
+// Reads the current value of an object field.
+func objectFieldValue(ctx context.Context, cli client.Reader, src v1alpha1.ObjectFieldSource) (string, error) {
+	obj, _, err := ParseWatchKind(src.Kind)
+	if err != nil {
+		return "", err
+	}
+	field, err := ParseWatchField(src.FieldPath)
+	if err != nil {
+		return "", err
+	}
+
+	err = cli.Get(ctx, types.NamespacedName{Name: src.Name}, obj)
+	if apierrors.IsNotFound(err) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	return WatchFieldValue(obj, field)
+}
@@ -0,0 +1,103 @@
+package trigger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/tilt-dev/tilt/internal/controllers/apiset"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+func TestParseWatchObject(t *testing.T) {
+	for _, ref := range []string{"configmap/schema", "ConfigMap/schema", "configmaps/schema"} {
+		obj, name, err := ParseWatchObject(ref)
+		require.NoError(t, err)
+		assert.IsType(t, &v1alpha1.ConfigMap{}, obj)
+		assert.Equal(t, "schema", name)
+	}
+
+	_, _, err := ParseWatchObject("configmap")
+	assert.EqualError(t, err, `invalid object "configmap": expected kind/name`)
+
+	_, _, err = ParseWatchObject("widget/schema")
+	assert.EqualError(t, err, `invalid object "widget/schema": unknown kind "widget"`)
+}
+
+func TestWatchFieldValue(t *testing.T) {
+	cm := &v1alpha1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "schema"},
+		Data:       map[string]string{"version": "2"},
+	}
+
+	for field, expected := range map[string]string{
+		"{.data.version}": "2",
+		".data.version":   "2",
+		".data.missing":   "",
+	} {
+		jp, err := ParseWatchField(field)
+		require.NoError(t, err)
+		value, err := WatchFieldValue(cm, jp)
+		require.NoError(t, err)
+		assert.Equal(t, expected, value, field)
+	}
+}
+
+func TestObjectFieldTrackerLastChange(t *testing.T) {
+	ctx := context.Background()
+	cm := &v1alpha1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "schema"},
+		Data:       map[string]string{"version": "1"},
+	}
+	objs := make(apiset.ObjectSet)
+	objs.Add(cm)
+	r := &fakeReader{objs: objs}
+
+	spec := &v1alpha1.RestartOnSpec{
+		ObjectFields: []v1alpha1.ObjectFieldSource{
+			{Kind: "ConfigMap", Name: "schema", FieldPath: "{.data.version}"},
+			{Kind: "ConfigMap", Name: "missing", FieldPath: "{.data.version}"},
+		},
+	}
+	owner := types.NamespacedName{Name: "api"}
+	tracker := NewObjectFieldTracker(clockwork.NewFakeClock())
+
+	// The first value isn't a change.
+	ts, err := tracker.LastChange(ctx, r, owner, spec)
+	require.NoError(t, err)
+	assert.True(t, ts.IsZero())
+
+	ts, err = tracker.LastChange(ctx, r, owner, spec)
+	require.NoError(t, err)
+	assert.True(t, ts.IsZero())
+
+	cm.Data["version"] = "2"
+	ts, err = tracker.LastChange(ctx, r, owner, spec)
+	require.NoError(t, err)
+	assert.False(t, ts.IsZero())
+
+	// The change time sticks until the value changes again.
+	ts2, err := tracker.LastChange(ctx, r, owner, spec)
+	require.NoError(t, err)
+	assert.Equal(t, ts, ts2)
+
+	// Other owners start from the current value.
+	ts, err = tracker.LastChange(ctx, r, types.NamespacedName{Name: "web"}, spec)
+	require.NoError(t, err)
+	assert.True(t, ts.IsZero())
+
+	tracker.Forget(owner)
+	ts, err = tracker.LastChange(ctx, r, owner, spec)
+	require.NoError(t, err)
+	assert.True(t, ts.IsZero())
+
+	_, err = tracker.LastChange(ctx, r, owner, &v1alpha1.RestartOnSpec{
+		ObjectFields: []v1alpha1.ObjectFieldSource{{Kind: "widget", Name: "schema", FieldPath: "{.data}"}},
+	})
+	assert.EqualError(t, err, `unknown kind "widget"`)
+}
@@ -33,10 +33,23 @@ func SetupControllerRestartOn(builder *builder.Builder, idxer *indexer.Indexer,
 			var keys []indexer.Key
 			keys = append(keys, indexerKeys(fwGVK, obj.GetNamespace(), spec.FileWatches)...)
 			keys = append(keys, indexerKeys(btnGVK, obj.GetNamespace(), spec.UIButtons)...)
+			for _, src := range spec.ObjectFields {
+				_, kind, err := ParseWatchKind(src.Kind)
+				if err != nil {
+					continue
+				}
+				gvk := v1alpha1.SchemeGroupVersion.WithKind(kind)
+				keys = append(keys, indexerKeys(gvk, obj.GetNamespace(), []string{src.Name})...)
+			}
 			return keys
 		})
 
-	registerWatches(builder, idxer, []client.Object{&v1alpha1.FileWatch{}, &v1alpha1.UIButton{}})
+	// ObjectFields can watch any kind of object.
+	var typesToWatch []client.Object
+	for _, obj := range v1alpha1.AllResourceObjects() {
+		typesToWatch = append(typesToWatch, obj.New().(client.Object))
+	}
+	registerWatches(builder, idxer, typesToWatch)
 }
 
 // SetupControllerStartOn sets up watchers / indexers for a type with a StartOnSpec
@@ -31,6 +31,9 @@ func TestSetupControllerRestartOn(t *testing.T) {
 	spec := &v1alpha1.RestartOnSpec{
 		UIButtons:   []string{"btn1"},
 		FileWatches: []string{"fw1"},
+		ObjectFields: []v1alpha1.ObjectFieldSource{
+			{Kind: "configmaps", Name: "schema", FieldPath: "{.data.version}"},
+		},
 	}
 
 	c := &fakeReconciler{
@@ -54,6 +57,9 @@ func TestSetupControllerRestartOn(t *testing.T) {
 	reqs = c.indexer.Enqueue(&v1alpha1.FileWatch{ObjectMeta: metav1.ObjectMeta{Name: "fw1"}})
 	require.Equal(t, []reconcile.Request{{NamespacedName: types.NamespacedName{Name: "cmd1"}}}, reqs)
 
+	reqs = c.indexer.Enqueue(&v1alpha1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "schema"}})
+	require.Equal(t, []reconcile.Request{{NamespacedName: types.NamespacedName{Name: "cmd1"}}}, reqs)
+
 	// fw named btn1, which doesn't exist
 	reqs = c.indexer.Enqueue(&v1alpha1.FileWatch{ObjectMeta: metav1.ObjectMeta{Name: "btn1"}})
 	require.Len(t, reqs, 0)
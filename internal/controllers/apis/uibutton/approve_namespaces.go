@@ -0,0 +1,36 @@
+package uibutton
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+func ApproveNamespacesButtonName(resourceName string) string {
+	return fmt.Sprintf("%s-approve-namespaces", resourceName)
+}
+
+// A button that approves deploying the resource to namespaces that
+// the project hasn't deployed to before.
+//
+// Only exists while the resource is waiting for approval. The namespaces
+// are only displayed; the click approves whatever the resource needs.
+func ApproveNamespacesButton(resourceName string, namespaces []string) *v1alpha1.UIButton {
+	return &v1alpha1.UIButton{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: ApproveNamespacesButtonName(resourceName),
+		},
+		Spec: v1alpha1.UIButtonSpec{
+			Location: v1alpha1.UIComponentLocation{
+				ComponentID:   resourceName,
+				ComponentType: v1alpha1.ComponentTypeResource,
+			},
+			Text:                 fmt.Sprintf("Approve Namespaces (%s)", strings.Join(namespaces, ", ")),
+			IconName:             "verified_user",
+			RequiresConfirmation: true,
+		},
+	}
+}
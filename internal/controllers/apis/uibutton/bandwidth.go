@@ -0,0 +1,41 @@
+package uibutton
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/tilt-dev/tilt/internal/controllers/apis/configmap"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+const BandwidthToggleButtonName = "bandwidth"
+
+// A toggle in the global nav that switches low-bandwidth mode on and off.
+func BandwidthToggleButton() *v1alpha1.ToggleButton {
+	return &v1alpha1.ToggleButton{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: BandwidthToggleButtonName,
+		},
+		Spec: v1alpha1.ToggleButtonSpec{
+			Location: v1alpha1.UIComponentLocation{
+				ComponentID:   "nav",
+				ComponentType: v1alpha1.ComponentTypeGlobal,
+			},
+			On: v1alpha1.ToggleButtonStateSpec{
+				Text:     "Full Bandwidth",
+				IconName: "signal_cellular_4_bar",
+			},
+			Off: v1alpha1.ToggleButtonStateSpec{
+				Text:     "Low Bandwidth",
+				IconName: "signal_cellular_alt_1_bar",
+			},
+			StateSource: v1alpha1.StateSource{
+				ConfigMap: &v1alpha1.ConfigMapStateSource{
+					Name:     configmap.BandwidthName,
+					Key:      configmap.BandwidthLowKey,
+					OnValue:  "true",
+					OffValue: "false",
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,31 @@
+package uibutton
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+func CronJobRunButtonName(resourceName string) string {
+	return fmt.Sprintf("%s-cronjob-run", resourceName)
+}
+
+// A button that creates a Job from each CronJob in the resource,
+// so that you don't have to wait for the schedule.
+func CronJobRunButton(resourceName string) *v1alpha1.UIButton {
+	return &v1alpha1.UIButton{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: CronJobRunButtonName(resourceName),
+		},
+		Spec: v1alpha1.UIButtonSpec{
+			Location: v1alpha1.UIComponentLocation{
+				ComponentID:   resourceName,
+				ComponentType: v1alpha1.ComponentTypeResource,
+			},
+			Text:     "Run CronJob Now",
+			IconName: "play_arrow",
+		},
+	}
+}
@@ -0,0 +1,27 @@
+package uibutton
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+const IdleResumeButtonName = "idle-resume"
+
+// A button in the global nav that brings back the resources
+// that Tilt disabled because it was idle.
+func IdleResumeButton() *v1alpha1.UIButton {
+	return &v1alpha1.UIButton{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: IdleResumeButtonName,
+		},
+		Spec: v1alpha1.UIButtonSpec{
+			Location: v1alpha1.UIComponentLocation{
+				ComponentID:   "nav",
+				ComponentType: v1alpha1.ComponentTypeGlobal,
+			},
+			Text:     "Resume",
+			IconName: "play_circle",
+		},
+	}
+}
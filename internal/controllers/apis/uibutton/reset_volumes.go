@@ -0,0 +1,34 @@
+package uibutton
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+func ResetVolumesButtonName(resourceName string) string {
+	return fmt.Sprintf("%s-reset-volumes", resourceName)
+}
+
+// A button that deletes and recreates the resource's volume claims,
+// so that you can reset a database to a clean slate.
+//
+// Only the claims listed in the KubernetesApply spec are deleted.
+func ResetVolumesButton(resourceName string) *v1alpha1.UIButton {
+	return &v1alpha1.UIButton{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: ResetVolumesButtonName(resourceName),
+		},
+		Spec: v1alpha1.UIButtonSpec{
+			Location: v1alpha1.UIComponentLocation{
+				ComponentID:   resourceName,
+				ComponentType: v1alpha1.ComponentTypeResource,
+			},
+			Text:                 "Reset Volumes",
+			IconName:             "delete_sweep",
+			RequiresConfirmation: true,
+		},
+	}
+}
@@ -0,0 +1,33 @@
+package uibutton
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+func RollbackButtonName(resourceName string) string {
+	return fmt.Sprintf("%s-rollback", resourceName)
+}
+
+// A button that re-applies the YAML from the resource's previous
+// successful deploy, so that a bad deploy can be reverted while
+// you fix it.
+func RollbackButton(resourceName string) *v1alpha1.UIButton {
+	return &v1alpha1.UIButton{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: RollbackButtonName(resourceName),
+		},
+		Spec: v1alpha1.UIButtonSpec{
+			Location: v1alpha1.UIComponentLocation{
+				ComponentID:   resourceName,
+				ComponentType: v1alpha1.ComponentTypeResource,
+			},
+			Text:                 "Roll Back",
+			IconName:             "undo",
+			RequiresConfirmation: true,
+		},
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 
@@ -27,16 +28,30 @@ type Controller interface {
 // https://github.com/kubernetes-sigs/controller-runtime/issues/1752
 type ctrlWrapper struct {
 	ctx context.Context
+	st  store.RStore
 	reconcile.Reconciler
 }
 
-// Propagate the logger and analytics from setup
-func (w ctrlWrapper) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+// Propagate the logger and analytics from setup.
+//
+// If a CrashRecoverable reconciler panics, report the crash and return
+// an error, so that the request is retried with backoff and the other
+// controllers keep running.
+func (w ctrlWrapper) Reconcile(ctx context.Context, req reconcile.Request) (result reconcile.Result, err error) {
+	if store.IsCrashRecoverable(w.Reconciler) {
+		defer store.RecoverCrash(w.st, w.name(), &err)
+	}
+
 	ctx = logger.WithLogger(ctx, logger.Get(w.ctx))
 	ctx = analytics.WithAnalytics(ctx, analytics.Get(w.ctx))
 	return w.Reconciler.Reconcile(ctx, req)
 }
 
+// e.g., "kubernetesdiscovery.Reconciler"
+func (w ctrlWrapper) name() string {
+	return strings.TrimPrefix(fmt.Sprintf("%T", w.Reconciler), "*")
+}
+
 type ControllerBuilder struct {
 	tscm        *TiltServerControllerManager
 	controllers []Controller
@@ -78,7 +93,7 @@ func (c *ControllerBuilder) SetUp(ctx context.Context, st store.RStore) error {
 	}
 
 	for i, b := range builders {
-		wrapper := ctrlWrapper{ctx: ctx, Reconciler: c.controllers[i]}
+		wrapper := ctrlWrapper{ctx: ctx, st: st, Reconciler: c.controllers[i]}
 		if err := b.Complete(wrapper); err != nil {
 			return fmt.Errorf("error starting controller: %v", err)
 		}
@@ -2,28 +2,39 @@ package cluster
 
 import (
 	"context"
-	"errors"
+	"fmt"
 	"sync"
+	"time"
 
 	"github.com/jonboulle/clockwork"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/tilt-dev/tilt/internal/controllers/apis/configmap"
 	"github.com/tilt-dev/tilt/internal/controllers/indexer"
 	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
 )
 
+// If the cluster takes longer than this to answer a health check,
+// we report that it's degraded.
+const slowHealthCheckThreshold = 3 * time.Second
+
 type clusterHealthMonitor struct {
 	mu        sync.Mutex
 	globalCtx context.Context
 	clock     clockwork.Clock
+	reader    ctrlclient.Reader
 	requeuer  *indexer.Requeuer
 	monitors  map[types.NamespacedName]monitor
 }
 
-func newClusterHealthMonitor(globalCtx context.Context, clock clockwork.Clock, requeuer *indexer.Requeuer) *clusterHealthMonitor {
+func newClusterHealthMonitor(globalCtx context.Context, clock clockwork.Clock, reader ctrlclient.Reader, requeuer *indexer.Requeuer) *clusterHealthMonitor {
 	return &clusterHealthMonitor{
 		globalCtx: globalCtx,
 		clock:     clock,
+		reader:    reader,
 		requeuer:  requeuer,
 		monitors:  make(map[types.NamespacedName]monitor),
 	}
@@ -35,7 +46,13 @@ func (c *clusterHealthMonitor) Start(clusterNN types.NamespacedName, conn connec
 
 	c.cleanup(clusterNN)
 	ctx, cancel := context.WithCancel(c.globalCtx)
-	c.monitors[clusterNN] = monitor{cancel: cancel}
+	// Until the first health check says otherwise, assume that the
+	// cluster we just connected to is healthy.
+	c.monitors[clusterNN] = monitor{
+		cancel:    cancel,
+		monitored: conn.connType == connectionTypeK8s,
+		health:    healthStatus{since: c.clock.Now()},
+	}
 	go c.run(ctx, clusterNN, conn)
 
 	return ctx
@@ -44,10 +61,23 @@ func (c *clusterHealthMonitor) Start(clusterNN types.NamespacedName, conn connec
 func (c *clusterHealthMonitor) GetStatus(clusterNN types.NamespacedName) string {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	return c.monitors[clusterNN].error
+	return c.monitors[clusterNN].health.error
+}
+
+// GetConditions describes the health of a monitored cluster.
+//
+// Returns nil if the cluster isn't monitored.
+func (c *clusterHealthMonitor) GetConditions(clusterNN types.NamespacedName) []metav1.Condition {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	m, ok := c.monitors[clusterNN]
+	if !ok || !m.monitored {
+		return nil
+	}
+	return m.health.toConditions()
 }
 
-func (c *clusterHealthMonitor) UpdateStatus(ctx context.Context, clusterNN types.NamespacedName, error string) {
+func (c *clusterHealthMonitor) UpdateStatus(ctx context.Context, clusterNN types.NamespacedName, health healthStatus) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -59,10 +89,11 @@ func (c *clusterHealthMonitor) UpdateStatus(ctx context.Context, clusterNN types
 	}
 
 	if m, ok := c.monitors[clusterNN]; ok {
-		if m.error == error {
+		if m.health.equivalent(health) {
 			return
 		}
-		m.error = error
+		health.since = c.clock.Now()
+		m.health = health
 		c.monitors[clusterNN] = m
 		c.requeuer.Add(clusterNN)
 	}
@@ -85,7 +116,68 @@ func (c *clusterHealthMonitor) cleanup(clusterNN types.NamespacedName) {
 
 type monitor struct {
 	cancel context.CancelFunc
-	error  string
+
+	// Whether we're running health checks against the cluster.
+	monitored bool
+	health    healthStatus
+}
+
+// The result of a cluster health check.
+type healthStatus struct {
+	// Why the cluster failed the health check, if it did.
+	error string
+
+	// Whether the health check couldn't reach the cluster at all.
+	unreachable bool
+
+	// How long the cluster took to answer the health check,
+	// if it was slower than slowHealthCheckThreshold.
+	slowResponse time.Duration
+
+	// When the cluster entered this state.
+	since time.Time
+}
+
+// Whether two health checks describe the same state.
+//
+// Response times always vary a little, so we only care
+// whether the cluster was slow.
+func (h healthStatus) equivalent(other healthStatus) bool {
+	return h.error == other.error &&
+		h.unreachable == other.unreachable &&
+		(h.slowResponse > 0) == (other.slowResponse > 0)
+}
+
+func (h healthStatus) toConditions() []metav1.Condition {
+	since := metav1.NewTime(h.since)
+	reachable := metav1.Condition{
+		Type:               v1alpha1.ClusterConditionReachable,
+		Status:             metav1.ConditionTrue,
+		Reason:             v1alpha1.ClusterReasonReachable,
+		LastTransitionTime: since,
+	}
+	if h.unreachable {
+		reachable.Status = metav1.ConditionFalse
+		reachable.Reason = v1alpha1.ClusterReasonUnreachable
+		reachable.Message = h.error
+	}
+
+	responsive := metav1.Condition{
+		Type:               v1alpha1.ClusterConditionResponsive,
+		Status:             metav1.ConditionTrue,
+		Reason:             v1alpha1.ClusterReasonResponsive,
+		LastTransitionTime: since,
+	}
+	if h.unreachable {
+		responsive.Status = metav1.ConditionUnknown
+		responsive.Reason = v1alpha1.ClusterReasonUnreachable
+	} else if h.slowResponse > 0 {
+		responsive.Status = metav1.ConditionFalse
+		responsive.Reason = v1alpha1.ClusterReasonSlowResponse
+		responsive.Message = fmt.Sprintf("Cluster took %s to respond to a health check",
+			h.slowResponse.Round(100*time.Millisecond))
+	}
+	return []metav1.Condition{reachable, responsive}
 }
 
 func (c *clusterHealthMonitor) run(ctx context.Context, clusterNN types.NamespacedName, conn connection) {
@@ -97,36 +189,44 @@ func (c *clusterHealthMonitor) run(ctx context.Context, clusterNN types.Namespac
 	ticker := c.clock.NewTicker(clientHealthPollInterval)
 	defer ticker.Stop()
 	for {
-		err := doKubernetesHealthCheck(ctx, conn.k8sClient)
-		if err != nil {
-			c.UpdateStatus(ctx, clusterNN, err.Error())
-		} else {
-			c.UpdateStatus(ctx, clusterNN, "")
-		}
-
-		select {
-		case <-ticker.Chan():
-		case <-ctx.Done():
-			return
+		lastCheck := c.clock.Now()
+		c.UpdateStatus(ctx, clusterNN, c.doKubernetesHealthCheck(ctx, conn.k8sClient))
+
+		for {
+			select {
+			case <-ticker.Chan():
+			case <-ctx.Done():
+				return
+			}
+
+			// In low-bandwidth mode, skip ticks to poll less often.
+			if !configmap.LowBandwidth(ctx, c.reader) ||
+				c.clock.Since(lastCheck) >= lowBandwidthHealthPollInterval {
+				break
+			}
 		}
 	}
 }
 
-func doKubernetesHealthCheck(ctx context.Context, client k8s.Client) error {
+func (c *clusterHealthMonitor) doKubernetesHealthCheck(ctx context.Context, client k8s.Client) healthStatus {
 	// TODO(milas): use verbose=true and propagate the info to the Tilt API
 	// 	cluster obj to show in the web UI
+	start := c.clock.Now()
 	health, err := client.ClusterHealth(ctx, false)
 	if err != nil {
-		return err
+		// The cluster didn't answer (e.g., because the VPN dropped).
+		return healthStatus{error: err.Error(), unreachable: true}
 	}
 
-	if !health.Live {
-		return errors.New("cluster did not pass liveness check")
+	var status healthStatus
+	if latency := c.clock.Since(start); latency > slowHealthCheckThreshold {
+		status.slowResponse = latency
 	}
 
-	if !health.Ready {
-		return errors.New("cluster not ready")
+	if !health.Live {
+		status.error = "cluster did not pass liveness check"
+	} else if !health.Ready {
+		status.error = "cluster not ready"
 	}
-
-	return nil
+	return status
 }
@@ -10,6 +10,7 @@ import (
 	"github.com/docker/docker/client"
 	"github.com/jonboulle/clockwork"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
@@ -43,6 +44,9 @@ const ArchUnknown string = "unknown"
 const (
 	clientInitBackoff        = 30 * time.Second
 	clientHealthPollInterval = 15 * time.Second
+
+	// Health checks are cheap, but they add up over a slow link.
+	lowBandwidthHealthPollInterval = time.Minute
 )
 
 type Reconciler struct {
@@ -97,12 +101,14 @@ func NewReconciler(
 		dockerClientFactory: dockerClientFactory,
 		k8sClientFactory:    k8sClientFactory,
 		wsList:              wsList,
-		clusterHealth:       newClusterHealthMonitor(globalCtx, clock, requeuer),
+		clusterHealth:       newClusterHealthMonitor(globalCtx, clock, ctrlClient, requeuer),
 		base:                base,
 		apiServerName:       apiServerName,
 	}
 }
 
+func (r *Reconciler) CrashRecoverable() {}
+
 func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
 	nn := request.NamespacedName
 	ctx = store.WithManifestLogHandler(ctx, r.store, model.MainTiltfileManifestName, "cluster")
@@ -191,6 +197,11 @@ func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 	r.connManager.store(nn, conn)
 
 	status := conn.toStatus(r.clusterHealth.GetStatus(nn))
+	if conn.initError == "" {
+		status.Conditions = r.clusterHealth.GetConditions(nn)
+	}
+	// The Docker pruner owns its own history.
+	status.DockerPrune = obj.Status.DockerPrune
 	err = r.maybeUpdateStatus(ctx, &obj, status)
 	if err != nil {
 		return ctrl.Result{}, err
@@ -208,8 +219,10 @@ func (r *Reconciler) createDockerClient(obj *v1alpha1.DockerClusterConnection) (
 	// If no Host is specified, use the default Env from environment variables.
 	env := docker.Env(r.localDockerEnv)
 	if obj.Host != "" {
-		d, err := client.NewClientWithOpts(client.WithHost(obj.Host))
+		// Negotiate, because engines like Podman serve an older API version.
+		d, err := client.NewClientWithOpts(client.WithHost(obj.Host), client.WithAPIVersionNegotiation())
 		env.Client = d
+		env.Environ = []string{fmt.Sprintf("DOCKER_HOST=%s", obj.Host)}
 		if err != nil {
 			env.Error = err
 		}
@@ -285,6 +298,13 @@ func (r *Reconciler) maybeUpdateStatus(ctx context.Context, obj *v1alpha1.Cluste
 		logger.Get(ctx).Errorf("Cluster status error: %v", newStatus.Error)
 	}
 
+	oldResponsive := meta.FindStatusCondition(oldStatus.Conditions, v1alpha1.ClusterConditionResponsive)
+	newResponsive := meta.FindStatusCondition(newStatus.Conditions, v1alpha1.ClusterConditionResponsive)
+	if newResponsive != nil && newResponsive.Reason == v1alpha1.ClusterReasonSlowResponse &&
+		(oldResponsive == nil || oldResponsive.Reason != newResponsive.Reason) {
+		logger.Get(ctx).Warnf("Cluster is degraded: %s", newResponsive.Message)
+	}
+
 	r.reportConnectionEvent(ctx, obj)
 
 	return nil
@@ -11,6 +11,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 
@@ -20,6 +21,7 @@ import (
 	"github.com/tilt-dev/wmclient/pkg/analytics"
 
 	"github.com/tilt-dev/tilt/internal/controllers/apicmp"
+	"github.com/tilt-dev/tilt/internal/controllers/apis/configmap"
 	"github.com/tilt-dev/tilt/internal/controllers/fake"
 	"github.com/tilt-dev/tilt/internal/controllers/indexer"
 	"github.com/tilt-dev/tilt/internal/docker"
@@ -219,6 +221,95 @@ func TestKubernetesMonitor(t *testing.T) {
 	timecmp.RequireTimeEqual(t, connectedAt, cluster.Status.ConnectedAt)
 }
 
+func TestKubernetesMonitorConditions(t *testing.T) {
+	f := newFixture(t)
+	cluster := &v1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Spec: v1alpha1.ClusterSpec{
+			Connection: &v1alpha1.ClusterConnection{
+				Kubernetes: &v1alpha1.KubernetesClusterConnection{},
+			},
+		},
+	}
+	nn := apis.Key(cluster)
+
+	f.Create(cluster)
+	f.MustGet(nn, cluster)
+	assert.True(t, meta.IsStatusConditionTrue(cluster.Status.Conditions, v1alpha1.ClusterConditionReachable))
+	assert.True(t, meta.IsStatusConditionTrue(cluster.Status.Conditions, v1alpha1.ClusterConditionResponsive))
+	f.assertSteadyState(cluster)
+
+	f.k8sClient.ClusterHealthError = errors.New("dial tcp 10.0.0.1:6443: i/o timeout")
+	f.clock.Advance(time.Minute)
+	<-f.requeues
+
+	f.MustGet(nn, cluster)
+	reachable := meta.FindStatusCondition(cluster.Status.Conditions, v1alpha1.ClusterConditionReachable)
+	require.NotNil(t, reachable)
+	assert.Equal(t, metav1.ConditionFalse, reachable.Status)
+	assert.Equal(t, v1alpha1.ClusterReasonUnreachable, reachable.Reason)
+	assert.Equal(t, "dial tcp 10.0.0.1:6443: i/o timeout", reachable.Message)
+	assert.Equal(t, metav1.ConditionUnknown,
+		meta.FindStatusCondition(cluster.Status.Conditions, v1alpha1.ClusterConditionResponsive).Status)
+
+	f.k8sClient.ClusterHealthError = nil
+	f.clock.Advance(time.Minute)
+	<-f.requeues
+
+	f.MustGet(nn, cluster)
+	assert.True(t, meta.IsStatusConditionTrue(cluster.Status.Conditions, v1alpha1.ClusterConditionReachable))
+	assert.Equal(t, "", cluster.Status.Error)
+}
+
+func TestHealthStatusSlowResponse(t *testing.T) {
+	healthy := healthStatus{}
+	slow := healthStatus{slowResponse: 4240 * time.Millisecond}
+	assert.False(t, healthy.equivalent(slow))
+	assert.True(t, slow.equivalent(healthStatus{slowResponse: 5 * time.Second}),
+		"response times of a slow cluster shouldn't change its status")
+
+	conditions := slow.toConditions()
+	assert.True(t, meta.IsStatusConditionTrue(conditions, v1alpha1.ClusterConditionReachable))
+	responsive := meta.FindStatusCondition(conditions, v1alpha1.ClusterConditionResponsive)
+	require.NotNil(t, responsive)
+	assert.Equal(t, metav1.ConditionFalse, responsive.Status)
+	assert.Equal(t, v1alpha1.ClusterReasonSlowResponse, responsive.Reason)
+	assert.Equal(t, "Cluster took 4.2s to respond to a health check", responsive.Message)
+}
+
+func TestKubernetesMonitorLowBandwidth(t *testing.T) {
+	f := newFixture(t)
+	f.Create(configmap.BandwidthCreate(true))
+	cluster := &v1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Spec: v1alpha1.ClusterSpec{
+			Connection: &v1alpha1.ClusterConnection{
+				Kubernetes: &v1alpha1.KubernetesClusterConnection{},
+			},
+		},
+	}
+	nn := apis.Key(cluster)
+
+	// Wait for the first health check to report the error.
+	f.k8sClient.ClusterHealthError = errors.New("fake cluster health error")
+	f.Create(cluster)
+	<-f.requeues
+
+	f.k8sClient.ClusterHealthError = nil
+	f.clock.Advance(30 * time.Second)
+	select {
+	case <-f.requeues:
+		t.Fatal("health check ran before the low-bandwidth poll interval")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	f.clock.Advance(30 * time.Second)
+	<-f.requeues
+
+	f.MustGet(nn, cluster)
+	assert.Equal(t, "", cluster.Status.Error)
+}
+
 func TestDockerError(t *testing.T) {
 	f := newFixture(t)
 	cluster := &v1alpha1.Cluster{
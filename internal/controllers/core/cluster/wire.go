@@ -9,6 +9,10 @@ import (
 var WireSet = wire.NewSet(
 	NewConnectionManager,
 	wire.Bind(new(cluster.ClientProvider), new(*ConnectionManager)),
+)
+
+// Creates clients for Clusters from the user's environment.
+var ClientFactoryWireSet = wire.NewSet(
 	wire.InterfaceValue(new(KubernetesClientFactory), KubernetesClientFunc(KubernetesClientFromEnv)),
 	wire.InterfaceValue(new(DockerClientFactory), DockerClientFunc(DockerClientFromEnv)),
 )
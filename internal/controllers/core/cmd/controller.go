@@ -47,6 +47,7 @@ type Controller struct {
 	st            store.RStore
 	clock         clockwork.Clock
 	requeuer      *indexer.Requeuer
+	fieldTracker  *trigger.ObjectFieldTracker
 
 	mu sync.Mutex
 }
@@ -81,6 +82,7 @@ func NewController(ctx context.Context, execer Execer, proberManager ProberManag
 		client:        client,
 		st:            st,
 		requeuer:      indexer.NewRequeuer(),
+		fieldTracker:  trigger.NewObjectFieldTracker(clock),
 	}
 }
 
@@ -149,6 +151,7 @@ func (c *Controller) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	if apierrors.IsNotFound(err) || cmd.ObjectMeta.DeletionTimestamp != nil {
 		c.stop(name)
 		delete(c.procs, name)
+		c.fieldTracker.Forget(name)
 		return ctrl.Result{}, nil
 	}
 
@@ -172,10 +175,25 @@ func (c *Controller) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		proc.lastRestartOnEventTime = metav1.MicroTime{}
 	}
 
+	lastFieldChange, err := c.fieldTracker.LastChange(ctx, c.client, name, cmd.Spec.RestartOn)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
 	if cmd.Annotations[v1alpha1.AnnotationManagedBy] == "local_resource" {
 		// Until resource dependencies are expressed in the API,
 		// we can't use reconciliation to deploy Cmd objects
 		// that are part of local_resource.
+		//
+		// When a watched object field changes, queue a build instead.
+		if !disabled && timecmp.After(lastFieldChange, proc.lastFieldChange) {
+			proc.lastFieldChange = lastFieldChange
+			c.st.Dispatch(store.AppendToTriggerQueueAction{
+				Name:   model.ManifestName(cmd.Annotations[v1alpha1.AnnotationManifest]),
+				Reason: model.BuildReasonFlagTriggerExternal,
+			})
+		}
+
 		err := c.maybeUpdateObjectStatus(ctx, cmd)
 		if err != nil {
 			return ctrl.Result{}, err
@@ -189,6 +207,10 @@ func (c *Controller) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	if err != nil {
 		return ctrl.Result{}, err
 	}
+	if timecmp.After(lastFieldChange, te.lastRestartEventTime) {
+		te.lastRestartEventTime = lastFieldChange
+		te.lastRestartButton = nil
+	}
 	te.lastStartEventTime, te.lastStartButton, err = trigger.LastStartEvent(ctx, c.client, cmd.Spec.StartOn)
 	if err != nil {
 		return ctrl.Result{}, err
@@ -362,7 +384,7 @@ func (c *Controller) runInternal(ctx context.Context,
 
 	if spec.ReadinessProbe != nil {
 		probeResultFunc := c.handleProbeResultFunc(ctx, name, proc)
-		probeWorker, err := probeWorkerFromSpec(
+		probeWorker, err := ProbeWorkerFromSpec(
 			c.proberManager,
 			spec.ReadinessProbe,
 			probeResultFunc)
@@ -435,15 +457,17 @@ func (c *Controller) handleProbeResultFunc(ctx context.Context, name types.Names
 
 		ready := result == prober.Success || result == prober.Warning
 
-		// TODO(milas): this isn't quite right - we might end up setting
-		// 	a terminated process to ready, for example; in practice, we
-		// 	should update internal state on any goroutine/async trackers
-		// 	and trigger a reconciliation, which can then evaluate the full
-		// 	state + current spec
 		proc.statusMu.Lock()
 		defer proc.statusMu.Unlock()
 
 		status := &(proc.statusInternal)
+
+		// A probe that was in flight when the process exited may still
+		// report success. Only a running process can be ready.
+		if status.Running == nil || status.Terminated != nil {
+			ready = false
+		}
+
 		if status.Ready != ready {
 			status.Ready = ready
 			c.requeuer.Add(name)
@@ -500,6 +524,7 @@ func (c *Controller) processStatuses(
 			proc.mutateStatus(func(status *v1alpha1.CmdStatus) {
 				status.Waiting = nil
 				status.Running = nil
+				status.Ready = false
 				status.Terminated = &CmdStateTerminated{
 					PID:        int32(sm.pid),
 					Reason:     sm.reason,
@@ -564,6 +589,9 @@ type currentProcess struct {
 	lastRestartOnEventTime metav1.MicroTime
 	lastStartOnEventTime   metav1.MicroTime
 
+	// The last change to a RestartOn object field that we've queued a build for.
+	lastFieldChange metav1.MicroTime
+
 	// We have a lock that ONLY protects the status.
 	statusMu       sync.Mutex
 	statusInternal v1alpha1.CmdStatus
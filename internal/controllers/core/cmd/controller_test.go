@@ -137,6 +137,33 @@ func TestServeReadinessProbe(t *testing.T) {
 	assert.GreaterOrEqual(t, f.fpm.ProbeCount(), 1)
 }
 
+func TestServeReadinessProbeUnreadyAfterExit(t *testing.T) {
+	f := newFixture(t)
+
+	t1 := time.Unix(1, 0)
+
+	c := model.ToHostCmdInDir("sleep 60", "testdir")
+	localTarget := model.NewLocalTarget("foo", model.Cmd{}, c, nil)
+	localTarget.ReadinessProbe = &v1alpha1.Probe{
+		TimeoutSeconds: 5,
+		Handler: v1alpha1.Handler{
+			Exec: &v1alpha1.ExecAction{Command: []string{"sleep", "15"}},
+		},
+	}
+
+	f.resourceFromTarget("foo", localTarget, t1)
+	f.step()
+	f.assertCmdMatches("foo-serve-1", func(cmd *Cmd) bool {
+		return cmd.Status.Running != nil && cmd.Status.Ready
+	})
+
+	err := f.fe.stop("sleep 60", 1)
+	require.NoError(t, err)
+	f.assertCmdMatches("foo-serve-1", func(cmd *Cmd) bool {
+		return cmd.Status.Terminated != nil && !cmd.Status.Ready
+	})
+}
+
 func TestServeReadinessProbeInvalidSpec(t *testing.T) {
 	f := newFixture(t)
 
@@ -177,7 +204,7 @@ func TestFailure(t *testing.T) {
 	err := f.fe.stop("true", 5)
 	require.NoError(t, err)
 	f.assertCmdMatches("foo-serve-1", func(cmd *Cmd) bool {
-		return cmd.Status.Terminated != nil && cmd.Status.Terminated.ExitCode == 5
+		return cmd.Status.Terminated != nil && cmd.Status.Terminated.ExitCode == 5 && !cmd.Status.Ready
 	})
 
 	f.assertLogMessage("foo", "cmd true exited with code 5")
@@ -300,6 +327,96 @@ func TestRestartOnUIButton(t *testing.T) {
 		f.c.indexer.Enqueue(b))
 }
 
+func TestRestartOnObjectField(t *testing.T) {
+	f := newFixture(t)
+
+	cm := &ConfigMap{
+		ObjectMeta: ObjectMeta{Name: "schema"},
+		Data:       map[string]string{"version": "1"},
+	}
+	f.Create(cm)
+
+	f.resource("cmd", "true", ".", f.clock.Now())
+	f.step()
+
+	firstStart := f.assertCmdMatches("cmd-serve-1", func(cmd *Cmd) bool {
+		return cmd.Status.Running != nil
+	})
+
+	f.clock.Advance(time.Second)
+	f.updateSpec("cmd-serve-1", func(spec *v1alpha1.CmdSpec) {
+		spec.RestartOn = &RestartOnSpec{
+			ObjectFields: []v1alpha1.ObjectFieldSource{
+				{Kind: "ConfigMap", Name: "schema", FieldPath: "{.data.version}"},
+			},
+		}
+	})
+	f.reconcileCmd("cmd-serve-1")
+
+	f.clock.Advance(time.Second)
+	cm.Data["version"] = "2"
+	f.Update(cm)
+	f.reconcileCmd("cmd-serve-1")
+
+	f.assertCmdMatches("cmd-serve-1", func(cmd *Cmd) bool {
+		running := cmd.Status.Running
+		return running != nil && running.StartedAt.Time.After(firstStart.Status.Running.StartedAt.Time)
+	})
+
+	assert.Equal(f.T(),
+		[]reconcile.Request{
+			reconcile.Request{NamespacedName: types.NamespacedName{Name: "cmd-serve-1"}},
+		},
+		f.c.indexer.Enqueue(cm))
+}
+
+func TestObjectFieldQueuesLocalResource(t *testing.T) {
+	f := newFixture(t)
+
+	cm := &ConfigMap{
+		ObjectMeta: ObjectMeta{Name: "schema"},
+		Data:       map[string]string{"version": "1"},
+	}
+	f.Create(cm)
+
+	f.Create(&Cmd{
+		ObjectMeta: ObjectMeta{
+			Name: "fe-update",
+			Annotations: map[string]string{
+				v1alpha1.AnnotationManifest:  "fe",
+				v1alpha1.AnnotationManagedBy: "local_resource",
+			},
+		},
+		Spec: CmdSpec{
+			Args: []string{"make"},
+			RestartOn: &RestartOnSpec{
+				ObjectFields: []v1alpha1.ObjectFieldSource{
+					{Kind: "ConfigMap", Name: "schema", FieldPath: "{.data.version}"},
+				},
+			},
+		},
+	})
+	f.reconcileCmd("fe-update")
+
+	cm.Data["version"] = "2"
+	f.Update(cm)
+	f.reconcileCmd("fe-update")
+	f.reconcileCmd("fe-update")
+
+	var queued []store.AppendToTriggerQueueAction
+	for _, a := range f.st.Actions() {
+		if a, ok := a.(store.AppendToTriggerQueueAction); ok {
+			queued = append(queued, a)
+		}
+	}
+	assert.Equal(f.T(), []store.AppendToTriggerQueueAction{
+		{Name: "fe", Reason: model.BuildReasonFlagTriggerExternal},
+	}, queued)
+
+	// The local_resource runs the command, not the reconciler.
+	assert.Equal(f.T(), 0, len(f.fe.processes))
+}
+
 func setupStartOnTest(t *testing.T, f *fixture) {
 	cmd := &Cmd{
 		ObjectMeta: metav1.ObjectMeta{
@@ -57,6 +57,8 @@ func NewReconciler(client ctrlclient.Client, st store.RStore, scheme *runtime.Sc
 	}
 }
 
+func (r *Reconciler) CrashRecoverable() {}
+
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	nn := req.NamespacedName
 	obj := &v1alpha1.CmdImage{}
@@ -47,7 +47,7 @@ func newFixture(t testing.TB) *fixture {
 	clock := clockwork.NewFakeClock()
 	dockerCli := docker.NewFakeClient()
 	ib := build.NewImageBuilder(
-		build.NewDockerBuilder(dockerCli, nil),
+		build.NewDockerBuilder(dockerCli, nil, nil),
 		build.NewCustomBuilder(dockerCli, clock),
 		build.NewKINDLoader())
 
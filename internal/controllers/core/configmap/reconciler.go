@@ -28,6 +28,8 @@ func NewReconciler(client ctrlclient.Client, store store.RStore) *Reconciler {
 	}
 }
 
+func (r *Reconciler) CrashRecoverable() {}
+
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	cm := &v1alpha1.ConfigMap{}
 	err := r.client.Get(ctx, req.NamespacedName, cm)
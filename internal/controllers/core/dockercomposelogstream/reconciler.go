@@ -27,6 +27,8 @@ func NewReconciler(client ctrlclient.Client, store store.RStore) *Reconciler {
 	}
 }
 
+func (r *Reconciler) CrashRecoverable() {}
+
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	obj := &v1alpha1.DockerComposeLogStream{}
 	err := r.client.Get(ctx, req.NamespacedName, obj)
@@ -0,0 +1,101 @@
+package dockercomposeservice
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/tilt-dev/tilt/internal/controllers/apicmp"
+	"github.com/tilt-dev/tilt/internal/dockercompose"
+	"github.com/tilt-dev/tilt/internal/store/dockercomposeservices"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+// Reasons we bring up a service, recorded in its status.
+const (
+	applyReasonFirstDeploy   = "first deploy"
+	applyReasonSpecChanged   = "spec changed"
+	applyReasonConfigChanged = "service config changed"
+	applyReasonFilesChanged  = "secret or config changed"
+	applyReasonImagesChanged = "images changed"
+	applyReasonForced        = "update requested"
+)
+
+// Hashes the resolved configuration of the service.
+//
+// Every service in a project shares the project spec, so an edit to any one
+// service changes all their specs. The hash tells us which services the
+// edit actually touched.
+//
+// Loading the project isn't free, so we cache the hash for each version of
+// the project. Returns an empty string if the project can't be loaded.
+func (r *Reconciler) serviceConfigHash(ctx context.Context, nn types.NamespacedName, spec v1alpha1.DockerComposeServiceSpec) string {
+	projectHash := dockercomposeservices.MustHashProject(spec.Project)
+	r.mu.Lock()
+	result, ok := r.results[nn]
+	if ok && result.configHashProject == projectHash && result.configHashService == spec.Service {
+		hash := result.configHash
+		r.mu.Unlock()
+		return hash
+	}
+	r.mu.Unlock()
+
+	hash, err := r.computeServiceConfigHash(ctx, spec)
+	if err != nil {
+		logger.Get(ctx).Debugf("Hashing config of service %s: %v", spec.Service, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result = r.ensureResultExists(nn)
+	result.configHash = hash
+	result.configHashProject = projectHash
+	result.configHashService = spec.Service
+	return hash
+}
+
+func (r *Reconciler) computeServiceConfigHash(ctx context.Context, spec v1alpha1.DockerComposeServiceSpec) (string, error) {
+	proj, err := r.dcc.Project(ctx, spec.Project)
+	if err != nil {
+		return "", err
+	}
+	svc, err := proj.GetService(spec.Service)
+	if err != nil {
+		return "", err
+	}
+	return dockercomposeservices.HashServiceConfig(svc)
+}
+
+// Compares a spec to the spec we last applied, without the project.
+//
+// The project is shared by all services, so we compare the service's
+// config hash instead.
+func specChangedOutsideProject(a, b v1alpha1.DockerComposeServiceSpec) bool {
+	a.Project = v1alpha1.DockerComposeProject{}
+	b.Project = v1alpha1.DockerComposeProject{}
+	return !apicmp.DeepEqual(a, b)
+}
+
+// Why the build controller is bringing up a service.
+func forceApplyReason(status v1alpha1.DockerComposeServiceStatus, configHash string, appliedSpec, spec v1alpha1.DockerComposeServiceSpec) string {
+	if status.LastApplyStartTime.IsZero() {
+		return applyReasonFirstDeploy
+	}
+	if dockercompose.FileObjectsChanged(appliedSpec, spec) {
+		return applyReasonFilesChanged
+	}
+	if configHash != "" && status.ConfigHash != "" && configHash != status.ConfigHash {
+		return applyReasonConfigChanged
+	}
+	return applyReasonForced
+}
+
+func logApplyReason(ctx context.Context, service, reason string) {
+	switch reason {
+	case applyReasonConfigChanged:
+		logger.Get(ctx).Infof("Configuration of service %s changed, recreating", service)
+	case applyReasonFilesChanged:
+		logger.Get(ctx).Infof("A secret or config of service %s changed, recreating", service)
+	}
+}
@@ -0,0 +1,69 @@
+package dockercomposeservice
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	composeyaml "gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/tilt-dev/tilt/internal/controllers/apis/imagemap"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+// Replaces image placeholders like $(TILT_IMAGE_DIGEST:my-image)
+// in the project with the status of the images the service depends on.
+//
+// Docker Compose interpolates the project before we see it, so placeholders
+// in compose files need to be escaped as $$(TILT_IMAGE_DIGEST:my-image).
+//
+// Returns the spec to pass to docker-compose up.
+func (r *Reconciler) withImageVars(ctx context.Context, spec v1alpha1.DockerComposeServiceSpec,
+	imageMaps map[types.NamespacedName]*v1alpha1.ImageMap) (v1alpha1.DockerComposeServiceSpec, error) {
+	if len(imageMaps) == 0 || !projectHasImageVars(spec.Project) {
+		return spec, nil
+	}
+
+	proj, err := r.dcc.Project(ctx, spec.Project)
+	if err != nil {
+		return spec, err
+	}
+
+	rawYAML, err := composeyaml.Marshal(proj)
+	if err != nil {
+		return spec, errors.Wrap(err, "expanding image variables")
+	}
+
+	expanded, err := imagemap.ExpandVars(ctx, string(rawYAML), spec.ImageMaps, imageMaps, r.dc)
+	if err != nil {
+		return spec, errors.Wrap(err, "expanding image variables")
+	}
+
+	// The project is interpolated again when we pass it to docker-compose,
+	// so escape any variables that are left over.
+	expanded = strings.ReplaceAll(expanded, "$", "$$")
+
+	spec = *spec.DeepCopy()
+	spec.Project = v1alpha1.DockerComposeProject{
+		YAML:        expanded,
+		ProjectPath: spec.Project.ProjectPath,
+		Name:        spec.Project.Name,
+	}
+	return spec, nil
+}
+
+func projectHasImageVars(proj v1alpha1.DockerComposeProject) bool {
+	if imagemap.HasVars(proj.YAML) {
+		return true
+	}
+	for _, p := range proj.ConfigPaths {
+		// If we can't read the file, docker-compose will report the error.
+		contents, err := os.ReadFile(p)
+		if err == nil && imagemap.HasVars(string(contents)) {
+			return true
+		}
+	}
+	return false
+}
@@ -62,6 +62,9 @@ func (r *Reconciler) runProjectWatch(pw *ProjectWatch) {
 	ch, err := r.dcc.StreamEvents(ctx, project)
 	if err != nil {
 		// TODO(nick): Figure out where this error should be published.
+		// Some compose providers (e.g., podman-compose) can't stream events at all,
+		// so this isn't necessarily a problem.
+		logger.Get(ctx).Debugf("Not watching events for project %s: %v", project.Name, err)
 		return
 	}
 
@@ -94,7 +97,7 @@ func (r *Reconciler) runProjectWatch(pw *ProjectWatch) {
 
 			cState := containerJSON.ContainerJSONBase.State
 			dcState := dockercompose.ToContainerState(cState)
-			r.recordContainerEvent(evt, dcState)
+			r.recordContainerEvent(pw.hash, evt, dcState)
 
 		case <-ctx.Done():
 			return
@@ -103,11 +106,11 @@ func (r *Reconciler) runProjectWatch(pw *ProjectWatch) {
 }
 
 // Record the container event and re-reconcile the dockercompose service.
-func (r *Reconciler) recordContainerEvent(evt dockercompose.Event, state *v1alpha1.DockerContainerState) {
+func (r *Reconciler) recordContainerEvent(projectHash string, evt dockercompose.Event, state *v1alpha1.DockerContainerState) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	result, ok := r.resultsByServiceName[evt.Service]
+	result, ok := r.resultsByService[serviceKey{projectHash: projectHash, service: evt.Service}]
 	if !ok {
 		return
 	}
@@ -18,8 +18,6 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
-	"github.com/docker/go-connections/nat"
-
 	"github.com/tilt-dev/tilt/internal/controllers/apicmp"
 	"github.com/tilt-dev/tilt/internal/controllers/apis/configmap"
 	"github.com/tilt-dev/tilt/internal/controllers/apis/imagemap"
@@ -45,9 +43,16 @@ type Reconciler struct {
 	mu           sync.Mutex
 
 	// Protected by the mutex.
-	results              map[types.NamespacedName]*Result
-	resultsByServiceName map[string]*Result
-	projectWatches       map[string]*ProjectWatch
+	results          map[types.NamespacedName]*Result
+	resultsByService map[serviceKey]*Result
+	projectWatches   map[string]*ProjectWatch
+}
+
+// Two docker-compose projects may have services with the same name,
+// so we index services by project and name.
+type serviceKey struct {
+	projectHash string
+	service     string
 }
 
 func (r *Reconciler) CreateBuilder(mgr ctrl.Manager) (*builder.Builder, error) {
@@ -71,16 +76,16 @@ func NewReconciler(
 	disableQueue *DisableSubscriber,
 ) *Reconciler {
 	return &Reconciler{
-		ctrlClient:           ctrlClient,
-		dcc:                  dcc,
-		dc:                   dc.ForOrchestrator(model.OrchestratorDC),
-		indexer:              indexer.NewIndexer(scheme, indexDockerComposeService),
-		st:                   st,
-		requeuer:             indexer.NewRequeuer(),
-		disableQueue:         disableQueue,
-		results:              make(map[types.NamespacedName]*Result),
-		resultsByServiceName: make(map[string]*Result),
-		projectWatches:       make(map[string]*ProjectWatch),
+		ctrlClient:       ctrlClient,
+		dcc:              dcc,
+		dc:               dc.ForOrchestrator(model.OrchestratorDC),
+		indexer:          indexer.NewIndexer(scheme, indexDockerComposeService),
+		st:               st,
+		requeuer:         indexer.NewRequeuer(),
+		disableQueue:     disableQueue,
+		results:          make(map[types.NamespacedName]*Result),
+		resultsByService: make(map[serviceKey]*Result),
+		projectWatches:   make(map[string]*ProjectWatch),
 	}
 }
 
@@ -135,11 +140,12 @@ func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 		}
 
 		// Apply to the cluster if necessary.
-		if r.shouldDeployOnReconcile(request.NamespacedName, &obj, imageMaps) {
+		deploy, reason := r.shouldDeployOnReconcile(ctx, request.NamespacedName, &obj, imageMaps)
+		if deploy {
 			// If we have no image dependencies in tilt, tell docker compose
 			// to handle any necessary image builds.
 			dcManagedBuild := len(imageMaps) == 0
-			_ = r.forceApplyHelper(ctx, nn, obj.Spec, imageMaps, dcManagedBuild)
+			_ = r.forceApplyHelper(ctx, nn, obj.Spec, imageMaps, dcManagedBuild, reason)
 		}
 	}
 
@@ -160,22 +166,23 @@ func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 //  2. Either we haven't deployed before,
 //     or one of the inputs has changed since the last deploy.
 func (r *Reconciler) shouldDeployOnReconcile(
+	ctx context.Context,
 	nn types.NamespacedName,
 	obj *v1alpha1.DockerComposeService,
 	imageMaps map[types.NamespacedName]*v1alpha1.ImageMap,
-) bool {
+) (bool, string) {
 	if obj.Annotations[v1alpha1.AnnotationManagedBy] != "" {
 		// Until resource dependencies are expressed in the API,
 		// we can't use reconciliation to deploy KubernetesApply objects
 		// managed by the buildcontrol engine.
-		return false
+		return false, ""
 	}
 
 	for _, imageMapName := range obj.Spec.ImageMaps {
 		_, ok := imageMaps[types.NamespacedName{Name: imageMapName}]
 		if !ok {
 			// We haven't built the images yet to deploy.
-			return false
+			return false, ""
 		}
 	}
 
@@ -185,32 +192,45 @@ func (r *Reconciler) shouldDeployOnReconcile(
 
 	if !ok || result.Status.LastApplyStartTime.IsZero() {
 		// We've never successfully deployed before, so deploy now.
-		return true
+		return true, applyReasonFirstDeploy
+	}
+
+	if dockercompose.FileObjectsChanged(result.AppliedSpec, obj.Spec) {
+		return true, applyReasonFilesChanged
+	}
+
+	if specChangedOutsideProject(obj.Spec, result.AppliedSpec) {
+		return true, applyReasonSpecChanged
 	}
 
-	if !apicmp.DeepEqual(obj.Spec, result.Spec) {
-		// The YAML to deploy changed.
-		return true
+	if !apicmp.DeepEqual(obj.Spec.Project, result.AppliedSpec.Project) {
+		// The project changed, but maybe not this service.
+		hash := r.serviceConfigHash(ctx, nn, obj.Spec)
+		if hash == "" || hash != result.Status.ConfigHash {
+			return true, applyReasonConfigChanged
+		}
+		logger.Get(ctx).Debugf("Project changed, but service %s didn't. Not recreating it.", obj.Spec.Service)
+		r.recordAppliedSpec(nn, obj.Spec)
 	}
 
 	imageMapNames := obj.Spec.ImageMaps
 	if len(imageMapNames) != len(result.ImageMapSpecs) ||
 		len(imageMapNames) != len(result.ImageMapStatuses) {
-		return true
+		return true, applyReasonImagesChanged
 	}
 
 	for i, name := range obj.Spec.ImageMaps {
 		im := imageMaps[types.NamespacedName{Name: name}]
 		if !apicmp.DeepEqual(im.Spec, result.ImageMapSpecs[i]) {
 
-			return true
+			return true, applyReasonImagesChanged
 		}
 		if !apicmp.DeepEqual(im.Status, result.ImageMapStatuses[i]) {
-			return true
+			return true, applyReasonImagesChanged
 		}
 	}
 
-	return false
+	return false, ""
 }
 
 // We need to update the disable queue in two cases:
@@ -256,6 +276,7 @@ func (r *Reconciler) recordRmOnDisable(nn types.NamespacedName) {
 	result.Status.ContainerName = ""
 	result.Status.ContainerState = nil
 	result.Status.PortBindings = nil
+	result.Status.Networks = nil
 }
 
 // Removes all state for an object.
@@ -264,7 +285,7 @@ func (r *Reconciler) clearResult(nn types.NamespacedName) {
 	defer r.mu.Unlock()
 	result, ok := r.results[nn]
 	if ok {
-		delete(r.resultsByServiceName, result.Spec.Service)
+		delete(r.resultsByService, result.serviceKey())
 		delete(r.results, nn)
 	}
 }
@@ -291,10 +312,10 @@ func (r *Reconciler) recordSpecAndDisableStatus(
 
 	result := r.ensureResultExists(nn)
 	if !apicmp.DeepEqual(result.Spec, spec) {
-		delete(r.resultsByServiceName, result.Spec.Service)
+		delete(r.resultsByService, result.serviceKey())
 		result.Spec = spec
 		result.ProjectHash = dockercomposeservices.MustHashProject(spec.Project)
-		r.resultsByServiceName[result.Spec.Service] = result
+		r.resultsByService[result.serviceKey()] = result
 	}
 
 	if apicmp.DeepEqual(result.Status.DisableStatus, &disableStatus) {
@@ -319,7 +340,13 @@ func (r *Reconciler) ForceApply(
 	spec v1alpha1.DockerComposeServiceSpec,
 	imageMaps map[types.NamespacedName]*v1alpha1.ImageMap,
 	dcManagedBuild bool) v1alpha1.DockerComposeServiceStatus {
-	status := r.forceApplyHelper(ctx, nn, spec, imageMaps, dcManagedBuild)
+	configHash := r.serviceConfigHash(ctx, nn, spec)
+	r.mu.Lock()
+	result := r.ensureResultExists(nn)
+	reason := forceApplyReason(result.Status, configHash, result.AppliedSpec, spec)
+	r.mu.Unlock()
+
+	status := r.forceApplyHelper(ctx, nn, spec, imageMaps, dcManagedBuild, reason)
 	r.requeuer.Add(nn)
 	return status
 }
@@ -333,6 +360,7 @@ func (r *Reconciler) recordApplyError(
 	imageMaps map[types.NamespacedName]*v1alpha1.ImageMap,
 	err error,
 	startTime metav1.MicroTime,
+	reason string,
 ) v1alpha1.DockerComposeServiceStatus {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -342,7 +370,9 @@ func (r *Reconciler) recordApplyError(
 	status.LastApplyStartTime = startTime
 	status.LastApplyFinishTime = apis.NowMicro()
 	status.ApplyError = err.Error()
+	status.LastApplyReason = reason
 	result.Status = *status
+	result.AppliedSpec = spec
 	result.SetImageMapInputs(spec, imageMaps)
 	return *status
 }
@@ -361,11 +391,20 @@ func (r *Reconciler) recordApplyStatus(
 	disableStatus := result.Status.DisableStatus
 	newStatus.DisableStatus = disableStatus
 	result.Status = newStatus
+	result.AppliedSpec = spec
 	result.SetImageMapInputs(spec, imageMaps)
 
 	return newStatus
 }
 
+// Records that the spec is up-to-date, even though we didn't apply it,
+// because nothing changed for this service.
+func (r *Reconciler) recordAppliedSpec(nn types.NamespacedName, spec v1alpha1.DockerComposeServiceSpec) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ensureResultExists(nn).AppliedSpec = spec
+}
+
 // A helper that applies the given specs to the cluster,
 // tracking the state of the deploy in the results map.
 func (r *Reconciler) forceApplyHelper(
@@ -375,19 +414,29 @@ func (r *Reconciler) forceApplyHelper(
 	imageMaps map[types.NamespacedName]*v1alpha1.ImageMap,
 	// TODO(nick): Figure out a better way to infer the dcManagedBuild setting.
 	dcManagedBuild bool,
+	reason string,
 ) v1alpha1.DockerComposeServiceStatus {
 	startTime := apis.NowMicro()
 	stdout := logger.Get(ctx).Writer(logger.InfoLvl)
 	stderr := logger.Get(ctx).Writer(logger.InfoLvl)
-	err := r.dcc.Up(ctx, spec, dcManagedBuild, stdout, stderr)
+	configHash := r.serviceConfigHash(ctx, nn, spec)
+	logApplyReason(ctx, spec.Service, reason)
+	upSpec, err := r.withImageVars(ctx, spec, imageMaps)
+	if err != nil {
+		return r.recordApplyError(nn, spec, imageMaps, err, startTime, reason)
+	}
+
+	// Docker Compose won't recreate the container for new secret or config contents on its own.
+	recreate := reason == applyReasonFilesChanged
+	err = r.dcc.Up(ctx, upSpec, dcManagedBuild, recreate, stdout, stderr)
 	if err != nil {
-		return r.recordApplyError(nn, spec, imageMaps, err, startTime)
+		return r.recordApplyError(nn, spec, imageMaps, err, startTime, reason)
 	}
 
 	// grab the initial container state
 	cid, err := r.dcc.ContainerID(ctx, spec)
 	if err != nil {
-		return r.recordApplyError(nn, spec, imageMaps, err, startTime)
+		return r.recordApplyError(nn, spec, imageMaps, err, startTime, reason)
 	}
 
 	containerJSON, err := r.dc.ContainerInspect(ctx, string(cid))
@@ -405,14 +454,11 @@ func (r *Reconciler) forceApplyHelper(
 		name = strings.TrimPrefix(containerJSON.ContainerJSONBase.Name, "/")
 	}
 
-	var ports nat.PortMap
-	if containerJSON.NetworkSettings != nil {
-		ports = containerJSON.NetworkSettings.NetworkSettingsBase.Ports
-	}
-
-	status := dockercompose.ToServiceStatus(cid, name, containerState, ports)
+	status := dockercompose.ToServiceStatus(cid, name, containerState, containerJSON.NetworkSettings)
 	status.LastApplyStartTime = startTime
 	status.LastApplyFinishTime = apis.NowMicro()
+	status.ConfigHash = configHash
+	status.LastApplyReason = reason
 	return r.recordApplyStatus(nn, spec, imageMaps, status)
 }
 
@@ -430,6 +476,8 @@ func (r *Reconciler) maybeUpdateStatus(ctx context.Context, nn types.NamespacedN
 
 	oldError := obj.Status.ApplyError
 	newError := newStatus.ApplyError
+	oldHealth := containerHealth(obj.Status.ContainerState)
+	newHealth := containerHealth(newStatus.ContainerState)
 	update := obj.DeepCopy()
 	update.Status = *(newStatus.DeepCopy())
 
@@ -442,9 +490,30 @@ func (r *Reconciler) maybeUpdateStatus(ctx context.Context, nn types.NamespacedN
 	if newError != "" && oldError != newError && update.Annotations[v1alpha1.AnnotationManagedBy] == "" {
 		logger.Get(ctx).Errorf("dockercomposeservice %s: %s", obj.Name, newError)
 	}
+
+	// Print healthcheck failures, so that it's clear why the service is red.
+	if newHealth != oldHealth {
+		if newHealth == dockercompose.ContainerHealthUnhealthy {
+			output := newStatus.ContainerState.HealthOutput
+			if output == "" {
+				output = "(no output)"
+			}
+			logger.Get(ctx).Warnf("Service %s is unhealthy. Last healthcheck output:\n%s",
+				obj.Spec.Service, output)
+		} else if newHealth == dockercompose.ContainerHealthHealthy && oldHealth == dockercompose.ContainerHealthUnhealthy {
+			logger.Get(ctx).Infof("Service %s is healthy again", obj.Spec.Service)
+		}
+	}
 	return nil
 }
 
+func containerHealth(state *v1alpha1.DockerContainerState) string {
+	if state == nil {
+		return ""
+	}
+	return state.Health
+}
+
 var imGVK = v1alpha1.SchemeGroupVersion.WithKind("ImageMap")
 
 // indexDockerComposeService returns keys for all the objects we need to watch based on the spec.
@@ -480,9 +549,21 @@ type Result struct {
 	ImageMapStatuses []v1alpha1.ImageMapStatus
 	ProjectHash      string
 
+	// The spec we last brought the service up with.
+	AppliedSpec v1alpha1.DockerComposeServiceSpec
+
+	// The config hash of the service, and the project and service it's for.
+	configHash        string
+	configHashProject string
+	configHashService string
+
 	Status v1alpha1.DockerComposeServiceStatus
 }
 
+func (r *Result) serviceKey() serviceKey {
+	return serviceKey{projectHash: r.ProjectHash, service: r.Spec.Service}
+}
+
 func (r *Result) SetImageMapInputs(spec v1alpha1.DockerComposeServiceSpec, imageMaps map[types.NamespacedName]*v1alpha1.ImageMap) {
 	r.ImageMapSpecs = nil
 	r.ImageMapStatuses = nil
@@ -1,6 +1,7 @@
 package dockercomposeservice
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -99,6 +100,190 @@ func TestAutoApply(t *testing.T) {
 	f.assertSteadyState(&obj)
 }
 
+func TestBuildLogsInServiceSpan(t *testing.T) {
+	f := newFixture(t)
+	f.dcc.BuildOutput = "#1 [internal] load build definition from Dockerfile\n"
+	nn := types.NamespacedName{Name: "fe"}
+	obj := v1alpha1.DockerComposeService{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "fe",
+			Annotations: map[string]string{
+				v1alpha1.AnnotationManifest: "fe",
+				v1alpha1.AnnotationSpanID:   "dockercompose:fe",
+			},
+		},
+		Spec: v1alpha1.DockerComposeServiceSpec{
+			Service: "fe",
+			Project: v1alpha1.DockerComposeProject{
+				YAML: "fake-yaml",
+			},
+		},
+	}
+	f.Create(&obj)
+	f.MustReconcile(nn)
+
+	var buildLogs []store.LogAction
+	for _, a := range f.Actions() {
+		la, ok := a.(store.LogAction)
+		if ok && strings.Contains(string(la.Message()), "load build definition") {
+			buildLogs = append(buildLogs, la)
+		}
+	}
+	require.Len(t, buildLogs, 1)
+	assert.Equal(t, "fe", buildLogs[0].ManifestName().String())
+	assert.Equal(t, "dockercompose:fe", string(buildLogs[0].SpanID()))
+}
+
+func TestApplyWithImageVars(t *testing.T) {
+	f := newFixture(t)
+	f.Create(&v1alpha1.ImageMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "image-a",
+		},
+		Spec: v1alpha1.ImageMapSpec{
+			Selector: "image-a",
+		},
+		Status: v1alpha1.ImageMapStatus{
+			Image:          "image-a:tilt-123",
+			ImageFromLocal: "image-a:tilt-123",
+		},
+	})
+
+	projectYAML := `services:
+  fe:
+    image: image-a
+    environment:
+      IMAGE_TAG: $$(TILT_IMAGE_TAG:image-a)
+      PRICE: $$5
+`
+	f.dcc.ConfigOutput = projectYAML
+
+	nn := types.NamespacedName{Name: "fe"}
+	obj := v1alpha1.DockerComposeService{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "fe",
+		},
+		Spec: v1alpha1.DockerComposeServiceSpec{
+			Service:   "fe",
+			ImageMaps: []string{"image-a"},
+			Project: v1alpha1.DockerComposeProject{
+				YAML: projectYAML,
+			},
+		},
+	}
+	f.Create(&obj)
+	f.MustReconcile(nn)
+	f.MustGet(nn, &obj)
+	assert.Equal(t, "", obj.Status.ApplyError)
+
+	calls := f.dcc.UpCalls()
+	require.Len(t, calls, 1)
+	assert.Contains(t, calls[0].Spec.Project.YAML, "IMAGE_TAG: tilt-123")
+	assert.Contains(t, calls[0].Spec.Project.YAML, "PRICE: $$5")
+
+	// The status tracks the spec, not the expanded project.
+	assert.Equal(t, projectYAML, f.r.results[nn].Spec.Project.YAML)
+}
+
+func TestProjectChangeOnlyRecreatesChangedServices(t *testing.T) {
+	f := newFixture(t)
+	projectYAML := `services:
+  fe:
+    image: fe
+  be:
+    image: be
+`
+	f.dcc.ConfigOutput = projectYAML
+
+	for _, name := range []string{"fe", "be"} {
+		f.Create(&v1alpha1.DockerComposeService{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: v1alpha1.DockerComposeServiceSpec{
+				Service: name,
+				Project: v1alpha1.DockerComposeProject{YAML: projectYAML},
+			},
+		})
+		f.MustReconcile(types.NamespacedName{Name: name})
+	}
+	require.Len(t, f.dcc.UpCalls(), 2)
+
+	var fe, be v1alpha1.DockerComposeService
+	f.MustGet(types.NamespacedName{Name: "fe"}, &fe)
+	f.MustGet(types.NamespacedName{Name: "be"}, &be)
+	assert.Equal(t, "first deploy", be.Status.LastApplyReason)
+	assert.NotEqual(t, "", fe.Status.ConfigHash)
+	oldFEHash := fe.Status.ConfigHash
+	oldBEHash := be.Status.ConfigHash
+
+	projectYAML = `services:
+  fe:
+    image: fe
+  be:
+    image: be
+    environment:
+      DEBUG: "1"
+`
+	f.dcc.ConfigOutput = projectYAML
+	for _, obj := range []*v1alpha1.DockerComposeService{&fe, &be} {
+		obj.Spec.Project.YAML = projectYAML
+		f.Update(obj)
+		f.MustReconcile(types.NamespacedName{Name: obj.Name})
+	}
+
+	calls := f.dcc.UpCalls()
+	require.Len(t, calls, 3)
+	assert.Equal(t, "be", calls[2].Spec.Service)
+
+	f.MustGet(types.NamespacedName{Name: "fe"}, &fe)
+	f.MustGet(types.NamespacedName{Name: "be"}, &be)
+	assert.Equal(t, oldFEHash, fe.Status.ConfigHash)
+	assert.NotEqual(t, oldBEHash, be.Status.ConfigHash)
+	assert.Equal(t, "service config changed", be.Status.LastApplyReason)
+}
+
+func TestSecretChangeRecreatesConsumingServices(t *testing.T) {
+	f := newFixture(t)
+	secret := func(hash string) []v1alpha1.DockerComposeFileObject {
+		return []v1alpha1.DockerComposeFileObject{{Name: "token", File: "token.txt", ContentHash: hash}}
+	}
+
+	objs := map[string]*v1alpha1.DockerComposeService{}
+	for _, name := range []string{"fe", "be"} {
+		obj := &v1alpha1.DockerComposeService{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: v1alpha1.DockerComposeServiceSpec{
+				Service: name,
+				Project: v1alpha1.DockerComposeProject{YAML: "fake-yaml"},
+			},
+		}
+		if name == "be" {
+			obj.Spec.Secrets = secret("a")
+		}
+		f.Create(obj)
+		f.MustReconcile(types.NamespacedName{Name: name})
+		objs[name] = obj
+	}
+	require.Len(t, f.dcc.UpCalls(), 2)
+
+	// Tiltfile re-execution only changes the spec of services that use the secret.
+	be := objs["be"]
+	f.MustGet(types.NamespacedName{Name: "be"}, be)
+	be.Spec.Secrets = secret("b")
+	f.Update(be)
+	f.MustReconcile(types.NamespacedName{Name: "be"})
+	f.MustReconcile(types.NamespacedName{Name: "fe"})
+
+	calls := f.dcc.UpCalls()
+	require.Len(t, calls, 3)
+	assert.Equal(t, "be", calls[2].Spec.Service)
+	assert.True(t, calls[2].Recreate)
+	assert.False(t, calls[0].Recreate)
+	assert.False(t, calls[1].Recreate)
+
+	f.MustGet(types.NamespacedName{Name: "be"}, be)
+	assert.Equal(t, "secret or config changed", be.Status.LastApplyReason)
+}
+
 func TestLogObject(t *testing.T) {
 	f := newFixture(t)
 	nn := types.NamespacedName{Name: "fe"}
@@ -188,6 +373,47 @@ func TestContainerEvent(t *testing.T) {
 		s.ManifestTargets["fe"].State.DCRuntimeState().ContainerState.Status)
 }
 
+func TestContainerEventUnhealthy(t *testing.T) {
+	f := newFixture(t)
+	nn := types.NamespacedName{Name: "fe"}
+	obj := v1alpha1.DockerComposeService{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "fe",
+			Annotations: map[string]string{
+				v1alpha1.AnnotationManifest: "fe",
+			},
+		},
+		Spec: v1alpha1.DockerComposeServiceSpec{
+			Service: "fe",
+			Project: v1alpha1.DockerComposeProject{
+				YAML: "fake-yaml",
+			},
+		},
+	}
+	f.Create(&obj)
+	_ = f.r.ForceApply(f.Context(), nn, obj.Spec, nil, false)
+
+	containerID := "my-container-id"
+	f.dc.Containers[containerID] = dtypes.ContainerState{
+		Status:  "running",
+		Running: true,
+		Health: &dtypes.Health{
+			Status: dtypes.Unhealthy,
+			Log:    []*dtypes.HealthcheckResult{{ExitCode: 1, Output: "connection refused"}},
+		},
+	}
+	f.dcc.SendEvent(dockercompose.Event{Type: dockercompose.TypeContainer, ID: containerID, Service: "fe"})
+
+	require.Eventually(t, func() bool {
+		f.MustReconcile(nn)
+		f.MustGet(nn, &obj)
+		return obj.Status.ContainerState.Health == "unhealthy"
+	}, time.Second, 10*time.Millisecond, "container unhealthy")
+
+	assert.Equal(t, "connection refused", obj.Status.ContainerState.HealthOutput)
+	f.AssertStdOutContains("Service fe is unhealthy. Last healthcheck output:\nconnection refused")
+}
+
 type fixture struct {
 	*fake.ControllerFixture
 	r   *Reconciler
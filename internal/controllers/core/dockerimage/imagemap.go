@@ -39,6 +39,10 @@ func UpdateImageMap(
 		}
 
 		result = store.NewImageBuildResultSingleRef(iTarget.ID(), ref)
+	} else if digested, ok := taggedRefs.ClusterRef.(reference.Digested); ok {
+		// The image is in a registry, and its refs are pinned to the digest there.
+		result.ImageMapStatus.Digest = digested.Digest().String()
+		result.ImageMapStatus.Registry = reference.Domain(taggedRefs.LocalRef)
 	}
 
 	result.ImageMapStatus.BuildStartTime = startTime
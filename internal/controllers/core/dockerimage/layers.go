@@ -0,0 +1,56 @@
+package dockerimage
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/docker/distribution/reference"
+
+	"github.com/tilt-dev/tilt/internal/docker"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+// Fetches the layers of a built image, like `docker history`, so that
+// users can see which instructions made their image big, and which
+// ones the build had to run again.
+//
+// The layers are informational, so if we can't get them, we log
+// and move on rather than failing the build.
+func imageLayers(ctx context.Context, dcli docker.Client, ref reference.Named, buildStart time.Time) []v1alpha1.DockerImageLayer {
+	history, err := dcli.ImageHistory(ctx, ref.String())
+	if err != nil {
+		logger.Get(ctx).Debugf("Reading layers of %s: %v", ref, err)
+		return nil
+	}
+
+	layers := make([]v1alpha1.DockerImageLayer, 0, len(history))
+	for _, h := range history {
+		id := h.ID
+		if id == "<missing>" {
+			id = ""
+		}
+		layers = append(layers, v1alpha1.DockerImageLayer{
+			ID:        id,
+			CreatedBy: layerCreatedBy(h.CreatedBy),
+			SizeBytes: h.Size,
+
+			// Docker only records creation times to the second.
+			Cached: h.Created < buildStart.Unix(),
+		})
+	}
+	return layers
+}
+
+// Trims the noise that Docker adds to the instruction that created a layer.
+//
+// The legacy builder records non-RUN instructions as
+// `/bin/sh -c #(nop)  CMD ["app"]`, and BuildKit adds a `# buildkit` comment.
+func layerCreatedBy(createdBy string) string {
+	createdBy = strings.TrimSuffix(createdBy, " # buildkit")
+	if strings.HasPrefix(createdBy, "/bin/sh -c #(nop) ") {
+		return strings.TrimSpace(strings.TrimPrefix(createdBy, "/bin/sh -c #(nop) "))
+	}
+	return strings.TrimSpace(createdBy)
+}
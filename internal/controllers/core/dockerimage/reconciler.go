@@ -18,12 +18,14 @@ import (
 
 	"github.com/tilt-dev/tilt/internal/build"
 	"github.com/tilt-dev/tilt/internal/controllers/apicmp"
+	"github.com/tilt-dev/tilt/internal/controllers/apis/configmap"
 	"github.com/tilt-dev/tilt/internal/controllers/indexer"
 	"github.com/tilt-dev/tilt/internal/docker"
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/internal/store/dockerimages"
 	"github.com/tilt-dev/tilt/pkg/apis"
 	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/logger"
 	"github.com/tilt-dev/tilt/pkg/model"
 )
 
@@ -56,6 +58,8 @@ func NewReconciler(client ctrlclient.Client, st store.RStore, scheme *runtime.Sc
 	}
 }
 
+func (r *Reconciler) CrashRecoverable() {}
+
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -113,13 +117,29 @@ func (r *Reconciler) ForceApply(
 	r.requeuer.Add(nn)
 	defer r.requeuer.Add(nn)
 
+	if configmap.LowBandwidth(ctx, r.client) {
+		iTarget = skipPull(ctx, iTarget)
+	}
+
+	// Publish the stages as they run, so that the UI can show progress.
+	ctx = build.WithStageProgress(ctx, func(stages []v1alpha1.DockerImageStageStatus) {
+		r.setImageStatus(nn, ToBuildingStatusWithStages(iTarget, startTime, stages))
+		r.requeuer.Add(nn)
+	})
+
 	refs, stages, err := r.ib.Build(ctx, iTarget, cluster, imageMaps, ps)
 	if err != nil {
 		r.setImageStatus(nn, ToCompletedFailStatus(iTarget, startTime, stages, err))
 		return store.ImageBuildResult{}, err
 	}
 
-	r.setImageStatus(nn, ToCompletedSuccessStatus(iTarget, startTime, stages, refs))
+	status := ToCompletedSuccessStatus(iTarget, startTime, stages, refs)
+	status.Layers = imageLayers(ctx, r.docker, refs.LocalRef, startTime.Time)
+	r.recordImageSize(nn, &status)
+	r.setImageStatus(nn, status)
+	if report := buildReport(status); report != "" {
+		ps.Printf(ctx, "%s", report)
+	}
 
 	buildResult, err := UpdateImageMap(
 		ctx, r.docker,
@@ -131,6 +151,19 @@ func (r *Reconciler) ForceApply(
 	return buildResult, nil
 }
 
+// In low-bandwidth mode, build against the base images we already have,
+// even if the Tiltfile asked to pull newer ones.
+func skipPull(ctx context.Context, iTarget model.ImageTarget) model.ImageTarget {
+	db, ok := iTarget.BuildDetails.(model.DockerBuild)
+	if !ok || !db.Pull {
+		return iTarget
+	}
+
+	logger.Get(ctx).Infof("Low-bandwidth mode: skipping base image pull for %s", iTarget.ImageMapName())
+	db.Pull = false
+	return iTarget.WithBuildDetails(db)
+}
+
 func (r *Reconciler) ensureResult(nn types.NamespacedName) *result {
 	res, ok := r.results[nn]
 	if !ok {
@@ -232,4 +265,8 @@ type result struct {
 	image        v1alpha1.DockerImageStatus
 	imageMapName string
 	imageMap     v1alpha1.ImageMapStatus
+
+	// The size of the last successfully built image,
+	// so that we can report how much it changed.
+	lastImageSizeBytes int64
 }
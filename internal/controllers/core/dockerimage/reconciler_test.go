@@ -2,17 +2,23 @@ package dockerimage
 
 import (
 	"testing"
+	"time"
 
+	"github.com/docker/docker/api/types/image"
 	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/tilt-dev/tilt/internal/build"
+	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/internal/controllers/fake"
 	"github.com/tilt-dev/tilt/internal/docker"
+	"github.com/tilt-dev/tilt/internal/testutils"
 	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model"
 )
 
 func TestIndexCluster(t *testing.T) {
@@ -36,6 +42,106 @@ func TestIndexCluster(t *testing.T) {
 	require.Empty(t, reqs, "Index result for unknown cluster")
 }
 
+func TestSkipPull(t *testing.T) {
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	iTarget := model.MustNewImageTarget(container.MustParseSelector("gcr.io/some-project/sancho")).
+		WithBuildDetails(model.DockerBuild{DockerImageSpec: v1alpha1.DockerImageSpec{Context: ".", Pull: true}})
+
+	result := skipPull(ctx, iTarget)
+	assert.False(t, result.DockerBuildInfo().Pull)
+	assert.Equal(t, ".", result.DockerBuildInfo().Context)
+	assert.True(t, iTarget.DockerBuildInfo().Pull, "original target should be unchanged")
+}
+
+func TestImageLayers(t *testing.T) {
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	ref := container.MustParseNamed("gcr.io/some-project/sancho:tilt-123")
+	dockerCli := docker.NewFakeClient()
+	buildStart := time.Unix(1000, 500)
+	dockerCli.ImageHistories = map[string][]image.HistoryResponseItem{
+		ref.String(): {
+			{ID: "sha256:abc", CreatedBy: `/bin/sh -c #(nop)  CMD ["sancho"]`, Created: 1001},
+			{ID: "<missing>", CreatedBy: "RUN /bin/sh -c npm install # buildkit", Size: 120000000, Created: 1000},
+			{ID: "<missing>", CreatedBy: "/bin/sh -c #(nop) ADD file:123 in / ", Size: 7000000, Created: 10},
+		},
+	}
+
+	assert.Equal(t, []v1alpha1.DockerImageLayer{
+		{ID: "sha256:abc", CreatedBy: `CMD ["sancho"]`},
+		{CreatedBy: "RUN /bin/sh -c npm install", SizeBytes: 120000000},
+		{CreatedBy: "ADD file:123 in /", SizeBytes: 7000000, Cached: true},
+	}, imageLayers(ctx, dockerCli, ref, buildStart))
+
+	missing := container.MustParseNamed("gcr.io/some-project/missing:tilt-123")
+	assert.Nil(t, imageLayers(ctx, dockerCli, missing, buildStart))
+}
+
+func TestRecordImageSize(t *testing.T) {
+	f := newFixture(t)
+	nn := types.NamespacedName{Name: "my-image"}
+
+	status := v1alpha1.DockerImageStatus{
+		Layers: []v1alpha1.DockerImageLayer{{SizeBytes: 3000}, {SizeBytes: 7000}},
+	}
+	f.r.recordImageSize(nn, &status)
+	assert.Equal(t, int64(10000), status.ImageSizeBytes)
+	assert.Equal(t, int64(0), status.ImageSizeDeltaBytes, "first build has no delta")
+
+	// If we can't read the layers, we don't know the size.
+	status = v1alpha1.DockerImageStatus{}
+	f.r.recordImageSize(nn, &status)
+	assert.Equal(t, int64(0), status.ImageSizeBytes)
+
+	status = v1alpha1.DockerImageStatus{
+		Layers: []v1alpha1.DockerImageLayer{{SizeBytes: 3000}, {SizeBytes: 2000}},
+	}
+	f.r.recordImageSize(nn, &status)
+	assert.Equal(t, int64(5000), status.ImageSizeBytes)
+	assert.Equal(t, int64(-5000), status.ImageSizeDeltaBytes)
+}
+
+func TestBuildReport(t *testing.T) {
+	start := metav1.NewMicroTime(time.Unix(1000, 0))
+	at := func(d time.Duration) *metav1.MicroTime {
+		t := metav1.NewMicroTime(start.Add(d))
+		return &t
+	}
+
+	status := v1alpha1.DockerImageStatus{
+		Completed: &v1alpha1.DockerImageStateCompleted{
+			StartedAt:  start,
+			FinishedAt: *at(12 * time.Second),
+		},
+		StageStatuses: []v1alpha1.DockerImageStageStatus{
+			{Name: "[1/5] FROM node", Cached: true, StartedAt: at(0), FinishedAt: at(0)},
+			{Name: "[2/5] COPY package.json .", StartedAt: at(0), FinishedAt: at(500 * time.Millisecond)},
+			{Name: "[3/5] RUN npm install", StartedAt: at(time.Second), FinishedAt: at(9 * time.Second)},
+			{Name: "[4/5] COPY . .", StartedAt: at(9 * time.Second), FinishedAt: at(11 * time.Second)},
+			{Name: "[5/5] RUN npm run build", StartedAt: at(11 * time.Second), FinishedAt: at(12 * time.Second)},
+		},
+		Layers: []v1alpha1.DockerImageLayer{
+			{CreatedBy: "RUN npm run build", SizeBytes: 4000000},
+			{CreatedBy: "COPY . .", SizeBytes: 1000000},
+			{CreatedBy: "RUN npm install", SizeBytes: 80000000},
+			{CreatedBy: "COPY package.json .", SizeBytes: 1000},
+			{CreatedBy: "ADD file:123 in /", SizeBytes: 34999000, Cached: true},
+		},
+		ImageSizeBytes:      120000000,
+		ImageSizeDeltaBytes: 4200000,
+	}
+
+	assert.Equal(t, `Build report: 12.00s, 1 of 5 steps cached
+Slowest steps:
+  8.00s [3/5] RUN npm install
+  2.00s [4/5] COPY . .
+  1.00s [5/5] RUN npm run build
+Image size: 120MB (+4.2MB)
+Layers: 1 of 5 cached, 85MB rebuilt`, buildReport(status))
+
+	status.Completed = nil
+	assert.Equal(t, "", buildReport(status), "no report for unfinished builds")
+}
+
 type fixture struct {
 	*fake.ControllerFixture
 	r *Reconciler
@@ -47,7 +153,7 @@ func newFixture(t testing.TB) *fixture {
 	clock := clockwork.NewFakeClock()
 	dockerCli := docker.NewFakeClient()
 	ib := build.NewImageBuilder(
-		build.NewDockerBuilder(dockerCli, nil),
+		build.NewDockerBuilder(dockerCli, nil, nil),
 		build.NewCustomBuilder(dockerCli, clock),
 		build.NewKINDLoader())
 
@@ -0,0 +1,115 @@
+package dockerimage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/go-units"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+// How many of the slowest stages we show in the build report.
+const reportSlowestStages = 3
+
+// Records the total size of the built image, and how much it changed
+// since the last successful build.
+func (r *Reconciler) recordImageSize(nn types.NamespacedName, status *v1alpha1.DockerImageStatus) {
+	if len(status.Layers) == 0 {
+		// We couldn't read the layers, so we don't know.
+		return
+	}
+
+	size := int64(0)
+	for _, layer := range status.Layers {
+		size += layer.SizeBytes
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := r.ensureResult(nn)
+	if result.lastImageSizeBytes != 0 {
+		status.ImageSizeDeltaBytes = size - result.lastImageSizeBytes
+	}
+	status.ImageSizeBytes = size
+	result.lastImageSizeBytes = size
+}
+
+// Summarizes a successful image build, so that users can see why a build
+// was slow without digging through the BuildKit output.
+//
+// Returns an empty string if there's nothing interesting to report.
+func buildReport(status v1alpha1.DockerImageStatus) string {
+	if status.Completed == nil || len(status.StageStatuses) == 0 {
+		return ""
+	}
+
+	cached := 0
+	stages := []v1alpha1.DockerImageStageStatus{}
+	for _, stage := range status.StageStatuses {
+		if stage.Cached {
+			cached++
+			continue
+		}
+		if stageDuration(stage) > 0 {
+			stages = append(stages, stage)
+		}
+	}
+	sort.SliceStable(stages, func(i, j int) bool {
+		return stageDuration(stages[i]) > stageDuration(stages[j])
+	})
+	if len(stages) > reportSlowestStages {
+		stages = stages[:reportSlowestStages]
+	}
+
+	total := status.Completed.FinishedAt.Sub(status.Completed.StartedAt.Time)
+	lines := []string{
+		fmt.Sprintf("Build report: %.2fs, %d of %d steps cached",
+			total.Seconds(), cached, len(status.StageStatuses)),
+	}
+	if len(stages) > 0 {
+		lines = append(lines, "Slowest steps:")
+		for _, stage := range stages {
+			lines = append(lines, fmt.Sprintf("  %.2fs %s", stageDuration(stage).Seconds(), stage.Name))
+		}
+	}
+	if status.ImageSizeBytes != 0 {
+		lines = append(lines, fmt.Sprintf("Image size: %s%s",
+			units.HumanSize(float64(status.ImageSizeBytes)), sizeDelta(status.ImageSizeDeltaBytes)))
+	}
+	if len(status.Layers) > 0 {
+		cachedLayers := 0
+		rebuiltBytes := int64(0)
+		for _, layer := range status.Layers {
+			if layer.Cached {
+				cachedLayers++
+			} else {
+				rebuiltBytes += layer.SizeBytes
+			}
+		}
+		lines = append(lines, fmt.Sprintf("Layers: %d of %d cached, %s rebuilt",
+			cachedLayers, len(status.Layers), units.HumanSize(float64(rebuiltBytes))))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func stageDuration(stage v1alpha1.DockerImageStageStatus) time.Duration {
+	if stage.StartedAt == nil || stage.FinishedAt == nil {
+		return 0
+	}
+	return stage.FinishedAt.Sub(stage.StartedAt.Time)
+}
+
+func sizeDelta(delta int64) string {
+	switch {
+	case delta > 0:
+		return fmt.Sprintf(" (+%s)", units.HumanSize(float64(delta)))
+	case delta < 0:
+		return fmt.Sprintf(" (-%s)", units.HumanSize(float64(-delta)))
+	}
+	return ""
+}
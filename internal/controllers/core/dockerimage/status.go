@@ -18,6 +18,14 @@ func ToBuildingStatus(iTarget model.ImageTarget, startTime metav1.MicroTime) v1a
 	}
 }
 
+// Return a building status with the stages that have started so far.
+func ToBuildingStatusWithStages(iTarget model.ImageTarget, startTime metav1.MicroTime,
+	stages []v1alpha1.DockerImageStageStatus) v1alpha1.DockerImageStatus {
+	status := ToBuildingStatus(iTarget, startTime)
+	status.StageStatuses = stages
+	return status
+}
+
 // Return a completed status when the image build failed.
 func ToCompletedFailStatus(iTarget model.ImageTarget, startTime metav1.MicroTime,
 	stages []v1alpha1.DockerImageStageStatus, err error) v1alpha1.DockerImageStatus {
@@ -52,6 +52,8 @@ func NewReconciler(ctrlClient ctrlclient.Client, scheme *runtime.Scheme, analyti
 }
 
 // Verifies extension paths.
+func (r *Reconciler) CrashRecoverable() {}
+
 func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
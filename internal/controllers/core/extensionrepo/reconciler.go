@@ -64,6 +64,8 @@ func NewReconciler(ctrlClient ctrlclient.Client, st store.RStore, base xdg.Base)
 }
 
 // Downloads extension repos.
+func (r *Reconciler) CrashRecoverable() {}
+
 func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -69,6 +69,8 @@ func NewController(client ctrlclient.Client, store store.RStore, fsWatcherMaker
 	}
 }
 
+func (c *Controller) CrashRecoverable() {}
+
 func (c *Controller) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -0,0 +1,78 @@
+package kubernetesapply
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/tilt-dev/tilt/internal/controllers/apis/uibutton"
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/timecmp"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+// If the user clicked the resource's "Run CronJob Now" button,
+// create a Job from each CronJob that we applied.
+//
+// The Jobs are owned by their CronJobs, so the KubernetesDiscovery
+// picks up their pods, and they show up (with their logs and their
+// completion status) on the resource like any other pod.
+func (r *Reconciler) maybeRunCronJobs(ctx context.Context, nn types.NamespacedName) error {
+	var button v1alpha1.UIButton
+	err := r.ctrlClient.Get(ctx, types.NamespacedName{Name: uibutton.CronJobRunButtonName(nn.Name)}, &button)
+	if err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	lastClick := button.Status.LastClickedAt
+	r.mu.Lock()
+	result := r.ensureResultExists(nn)
+	isNewClick := timecmp.After(lastClick, result.LastCronJobRunClick)
+	if isNewClick {
+		result.LastCronJobRunClick = lastClick
+	}
+	resultYAML := result.Status.ResultYAML
+	r.mu.Unlock()
+
+	if !isNewClick {
+		return nil
+	}
+
+	l := logger.Get(ctx)
+	applied, err := k8s.ParseYAMLFromString(resultYAML)
+	if err != nil {
+		return fmt.Errorf("reading applied CronJobs: %v", err)
+	}
+
+	var jobs []k8s.K8sEntity
+	for _, e := range applied {
+		if !k8s.IsCronJob(e) {
+			continue
+		}
+		job, err := k8s.JobFromCronJob(e, lastClick.Time)
+		if err != nil {
+			return err
+		}
+		jobs = append(jobs, job)
+	}
+
+	if len(jobs) == 0 {
+		l.Warnf("No CronJobs have been applied to the cluster yet. Nothing to run.")
+		return nil
+	}
+
+	created, err := r.k8sClient.Upsert(ctx, jobs, v1alpha1.KubernetesApplyTimeoutDefault)
+	if err != nil {
+		return fmt.Errorf("creating Jobs from CronJobs: %v", err)
+	}
+	r.printAppliedReport(ctx, "Jobs created from CronJobs:", created)
+
+	r.mu.Lock()
+	result = r.ensureResultExists(nn)
+	result.CreatedJobs = append(result.CreatedJobs, created...)
+	r.mu.Unlock()
+	return nil
+}
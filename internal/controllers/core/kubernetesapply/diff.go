@@ -0,0 +1,271 @@
+package kubernetesapply
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
+
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+// Fields that change on every write, and would only be noise in the diff.
+var diffIgnoredMetadataFields = []string{
+	"creationTimestamp",
+	"generation",
+	"managedFields",
+	"resourceVersion",
+	"uid",
+}
+
+// If the KubernetesApply has opted in to diff previews, and its inputs
+// have changed since the last apply, compare the YAML against the cluster
+// and record what the next apply would change.
+//
+// Uses a server-side dry run, so that the diff includes defaults and
+// merges the way the real apply would (like `kubectl diff`).
+func (r *Reconciler) maybeComputeDiff(ctx context.Context, nn types.NamespacedName, ka *v1alpha1.KubernetesApply,
+	cluster *v1alpha1.Cluster, imageMaps map[types.NamespacedName]*v1alpha1.ImageMap) {
+	if !ka.Spec.DiffPreview {
+		r.recordDiff(nn, "", nil)
+		return
+	}
+
+	if ka.Spec.Cluster != "" && (cluster.Name == "" || cluster.Status.Error != "" || cluster.Status.Connection == nil) {
+		// Wait for the cluster to start.
+		return
+	}
+
+	inputHash, err := ComputeInputHash(ka.Spec, imageMaps)
+	if err != nil {
+		// Usually means that we haven't built the images yet.
+		return
+	}
+
+	r.mu.Lock()
+	result := r.ensureResultExists(nn)
+	upToDate := result.Status.AppliedInputHash == inputHash
+	computed := result.Status.Diff != nil && result.Status.Diff.InputHash == inputHash
+	r.mu.Unlock()
+
+	if upToDate {
+		r.recordDiff(nn, "", nil)
+		return
+	}
+	if computed {
+		return
+	}
+
+	diff := v1alpha1.KubernetesApplyDiff{
+		ComputeTime: metav1.NowMicro(),
+		InputHash:   inputHash,
+	}
+	objects, err := r.computeDiff(ctx, ka.Spec, imageMaps)
+	if err != nil {
+		diff.Error = err.Error()
+	} else {
+		diff.Objects = objects
+	}
+	r.recordDiff(nn, inputHash, &diff)
+}
+
+// Diff each object in the YAML against the version in the cluster.
+func (r *Reconciler) computeDiff(ctx context.Context, spec v1alpha1.KubernetesApplySpec,
+	imageMaps map[types.NamespacedName]*v1alpha1.ImageMap) ([]v1alpha1.KubernetesApplyObjectDiff, error) {
+	entities, err := r.createEntitiesToDeploy(ctx, imageMaps, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := spec.Timeout.Duration
+	if timeout == 0 {
+		timeout = v1alpha1.KubernetesApplyTimeoutDefault
+	}
+
+	merged, err := r.k8sClient.DryRunUpsert(ctx, entities, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []v1alpha1.KubernetesApplyObjectDiff{}
+	for _, e := range merged {
+		// Look up the live object by name, so that we find it even
+		// if it was applied by a previous Tilt session.
+		ref := e.ToObjectReference()
+		ref.UID = ""
+		ref.ResourceVersion = ""
+
+		action := v1alpha1.KubernetesApplyDiffActionUpdate
+		live, err := r.k8sClient.GetByReference(ctx, ref)
+		if err != nil {
+			if !apierrors.IsNotFound(err) {
+				return nil, fmt.Errorf("fetching %s: %v", e.Name(), err)
+			}
+			action = v1alpha1.KubernetesApplyDiffActionCreate
+		}
+
+		var liveContent map[string]interface{}
+		if action == v1alpha1.KubernetesApplyDiffActionUpdate {
+			liveContent, err = diffableContent(live)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		mergedContent, err := diffableContent(e)
+		if err != nil {
+			return nil, err
+		}
+
+		if e.GVK().Group == "" && e.GVK().Kind == "Secret" {
+			maskSecretValues(liveContent, mergedContent)
+		}
+
+		liveYAML, err := contentToYAML(liveContent)
+		if err != nil {
+			return nil, err
+		}
+		mergedYAML, err := contentToYAML(mergedContent)
+		if err != nil {
+			return nil, err
+		}
+
+		if liveYAML == mergedYAML {
+			continue
+		}
+
+		name := fmt.Sprintf("%s/%s", e.GVK().Kind, e.Name())
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        splitLines(liveYAML),
+			B:        splitLines(mergedYAML),
+			FromFile: fmt.Sprintf("live/%s", name),
+			ToFile:   fmt.Sprintf("merged/%s", name),
+			Context:  3,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, v1alpha1.KubernetesApplyObjectDiff{
+			Kind:      e.GVK().Kind,
+			Namespace: e.Namespace().String(),
+			Name:      e.Name(),
+			Action:    action,
+			Diff:      diff,
+		})
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Kind != result[j].Kind {
+			return result[i].Kind < result[j].Kind
+		}
+		if result[i].Namespace != result[j].Namespace {
+			return result[i].Namespace < result[j].Namespace
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+// The object's fields, without the ones that are managed by the server.
+func diffableContent(e k8s.K8sEntity) (map[string]interface{}, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(e.Obj)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, field := range diffIgnoredMetadataFields {
+		unstructured.RemoveNestedField(content, "metadata", field)
+	}
+	unstructured.RemoveNestedField(content, "metadata", "annotations", "kubectl.kubernetes.io/last-applied-configuration")
+	if annotations, _, _ := unstructured.NestedMap(content, "metadata", "annotations"); len(annotations) == 0 {
+		unstructured.RemoveNestedField(content, "metadata", "annotations")
+	}
+	unstructured.RemoveNestedField(content, "status")
+	return content, nil
+}
+
+func contentToYAML(content map[string]interface{}) (string, error) {
+	if content == nil {
+		return "", nil
+	}
+	out, err := yaml.Marshal(content)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// The diff ends up in the UI and in snapshots, so hide the values of
+// Secrets, and only show which keys changed (like `kubectl diff`).
+//
+// Either side may be nil, e.g., if the Secret doesn't exist yet.
+func maskSecretValues(live, merged map[string]interface{}) {
+	for _, field := range []string{"data", "stringData"} {
+		liveValues, _, _ := unstructured.NestedMap(live, field)
+		mergedValues, _, _ := unstructured.NestedMap(merged, field)
+
+		for key, liveValue := range liveValues {
+			mergedValue, ok := mergedValues[key]
+			switch {
+			case !ok:
+				liveValues[key] = secretMask
+			case liveValue == mergedValue:
+				liveValues[key] = secretMask
+				mergedValues[key] = secretMask
+			default:
+				liveValues[key] = secretMask + " (before)"
+				mergedValues[key] = secretMask + " (after)"
+			}
+		}
+		for key := range mergedValues {
+			if _, ok := liveValues[key]; !ok {
+				mergedValues[key] = secretMask
+			}
+		}
+
+		if liveValues != nil {
+			_ = unstructured.SetNestedMap(live, liveValues, field)
+		}
+		if mergedValues != nil {
+			_ = unstructured.SetNestedMap(merged, mergedValues, field)
+		}
+	}
+}
+
+const secretMask = "***"
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.SplitAfter(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// Record the diff for the given input hash. A nil diff clears it.
+func (r *Reconciler) recordDiff(nn types.NamespacedName, inputHash string, diff *v1alpha1.KubernetesApplyDiff) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := r.ensureResultExists(nn)
+	if diff == nil && result.Status.Diff == nil {
+		return
+	}
+	if diff != nil && result.Status.AppliedInputHash == inputHash {
+		// Applied while we were computing the diff.
+		return
+	}
+
+	update := result.Status.DeepCopy()
+	update.Diff = diff
+	result.Status = *update
+}
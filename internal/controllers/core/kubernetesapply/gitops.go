@@ -0,0 +1,32 @@
+package kubernetesapply
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+// Suspends reconciliation of the applied objects that a GitOps tool manages,
+// so that the tool doesn't revert them.
+func (r *Reconciler) suspendGitOps(ctx context.Context, nn types.NamespacedName, deployed []k8s.K8sEntity) {
+	err := k8s.SuspendGitOps(ctx, r.k8sClient, deployed, nn.Name)
+	if err != nil {
+		logger.Get(ctx).Warnf("Suspending GitOps reconciliation: %v", err)
+	}
+}
+
+// Hands the objects that a GitOps tool manages back to the tool, so that
+// they go back to their declared state rather than being deleted.
+//
+// Returns the objects that no GitOps tool manages, which we still need to delete.
+func (r *Reconciler) resumeGitOps(ctx context.Context, nn types.NamespacedName, entities []k8s.K8sEntity) []k8s.K8sEntity {
+	unmanaged, err := k8s.ResumeGitOps(ctx, r.k8sClient, entities, nn.Name)
+	if err != nil {
+		logger.Get(ctx).Errorf("Error resuming GitOps reconciliation: %v", err)
+		return entities
+	}
+	return unmanaged
+}
@@ -1,14 +1,23 @@
 package kubernetesapply
 
 import (
+	"bufio"
+	"bytes"
 	"crypto"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"hash"
+	"io"
+	"sort"
+	"strings"
 
 	jsoniter "github.com/json-iterator/go"
 	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	yamlutil "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
 
 	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
 )
@@ -26,8 +35,18 @@ func createDefaultJSONIterator() jsoniter.API {
 
 // Compute the hash of all the inputs we fed into this apply.
 func ComputeInputHash(spec v1alpha1.KubernetesApplySpec, imageMaps map[types.NamespacedName]*v1alpha1.ImageMap) (string, error) {
+	// Semantically identical YAML should hash the same, so that
+	// refactoring a Tiltfile doesn't trigger spurious re-applies.
+	normalized, err := normalizeYAML(spec.YAML)
+	if err == nil {
+		spec.YAML = normalized
+	}
+
+	// Previewing a diff doesn't change what we apply.
+	spec.DiffPreview = false
+
 	w := newHashWriter()
-	err := w.append(spec)
+	err = w.append(spec)
 	if err != nil {
 		return "", err
 	}
@@ -55,6 +74,140 @@ func ComputeInputHash(spec v1alpha1.KubernetesApplySpec, imageMaps map[types.Nam
 	return w.done(), nil
 }
 
+// Compute the hash of all the inputs to a custom apply command.
+//
+// We can't know everything that the command reads, so we fingerprint what it
+// declares: the files it depends on (from the FileWatches it restarts on)
+// and the cluster it deploys to.
+//
+// We don't read the files themselves. The FileWatches already track
+// every change, so we fingerprint their event watermarks. If a FileWatch isn't
+// watching yet, we can't know what changed, so we return an error.
+func ComputeCmdInputHash(spec v1alpha1.KubernetesApplySpec,
+	imageMaps map[types.NamespacedName]*v1alpha1.ImageMap,
+	cluster *v1alpha1.Cluster,
+	fileWatches []*v1alpha1.FileWatch) (string, error) {
+	inputHash, err := ComputeInputHash(spec, imageMaps)
+	if err != nil {
+		return "", err
+	}
+
+	w := newHashWriter()
+	err = w.append(inputHash)
+	if err != nil {
+		return "", err
+	}
+
+	if cluster != nil {
+		err = w.append(cluster.Spec)
+		if err != nil {
+			return "", fmt.Errorf("hashing cluster spec: %v", err)
+		}
+		err = w.append(cluster.Status.Connection)
+		if err != nil {
+			return "", fmt.Errorf("hashing cluster connection: %v", err)
+		}
+	}
+
+	for _, fw := range fileWatches {
+		if fw.Status.Error != "" {
+			return "", fmt.Errorf("%s: %s", fw.Name, fw.Status.Error)
+		}
+		if fw.Status.MonitorStartTime.IsZero() {
+			return "", fmt.Errorf("%s: not watching files yet", fw.Name)
+		}
+		err = w.append(fw.Spec)
+		if err != nil {
+			return "", fmt.Errorf("hashing %s spec: %v", fw.Name, err)
+		}
+
+		// If the watcher restarted, it may have missed changes, so treat it as a change.
+		err = w.append([]metav1.MicroTime{fw.Status.MonitorStartTime, fw.Status.LastEventTime})
+		if err != nil {
+			return "", fmt.Errorf("hashing %s status: %v", fw.Name, err)
+		}
+	}
+
+	return w.done(), nil
+}
+
+// Converts a multi-document YAML string into a canonical form:
+//
+//   - Documents are converted to JSON with sorted keys, so formatting,
+//     comments, and map ordering don't matter.
+//   - Fields with null values are dropped, as are fields that
+//     serializers add but apply ignores (status, creationTimestamp).
+//   - Documents are sorted, because we sort entities by kind before applying anyway.
+//
+// Empty maps and lists are left alone, because they're sometimes meaningful
+// (e.g., `emptyDir: {}`).
+func normalizeYAML(yamlStr string) (string, error) {
+	reader := yamlutil.NewYAMLReader(bufio.NewReader(strings.NewReader(yamlStr)))
+	var docs []string
+	for {
+		doc, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+
+		data, err := yaml.YAMLToJSON(doc)
+		if err != nil {
+			return "", err
+		}
+
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		var obj interface{}
+		err = decoder.Decode(&obj)
+		if err != nil {
+			return "", err
+		}
+
+		m, ok := pruneNulls(obj).(map[string]interface{})
+		if !ok || len(m) == 0 {
+			// Skip empty documents, e.g. from a trailing '---'
+			continue
+		}
+		delete(m, "status")
+		metadata, ok := m["metadata"].(map[string]interface{})
+		if ok {
+			delete(metadata, "creationTimestamp")
+		}
+
+		canonical, err := defaultJSONIterator.MarshalToString(m)
+		if err != nil {
+			return "", err
+		}
+		docs = append(docs, canonical)
+	}
+
+	sort.Strings(docs)
+	return strings.Join(docs, "\n"), nil
+}
+
+func pruneNulls(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, val := range v {
+			if val == nil {
+				delete(v, key)
+				continue
+			}
+			v[key] = pruneNulls(val)
+		}
+		return v
+	case []interface{}:
+		for i, val := range v {
+			v[i] = pruneNulls(val)
+		}
+		return v
+	}
+	return v
+}
+
 type hashWriter struct {
 	h hash.Hash
 }
@@ -2,6 +2,7 @@ package kubernetesapply
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -26,13 +27,13 @@ func MustComputeInputHash(t testing.TB, spec v1alpha1.KubernetesApplySpec, image
 func TestComputeHashSancho(t *testing.T) {
 	spec := v1alpha1.KubernetesApplySpec{YAML: testyaml.SanchoYAML}
 	hash := MustComputeInputHash(t, spec, nil)
-	assert.Equal(t, hash, "elgAsMuqc3wosKnB-vrr3mmMDPc=")
+	assert.Equal(t, hash, "LFGIqtR3XdaG-vuXhSxYq4epR7E=")
 }
 
 func TestComputeHashSanchoSidecar(t *testing.T) {
 	spec := v1alpha1.KubernetesApplySpec{YAML: testyaml.SanchoSidecarYAML}
 	hash := MustComputeInputHash(t, spec, nil)
-	assert.Equal(t, hash, "SE9Jjlp-yakBXqPs2IjnrhCous8=")
+	assert.Equal(t, hash, "9yzzK57jnDefuOG4DjBW5QUAyMk=")
 }
 
 func TestComputeHashSanchoImageMap(t *testing.T) {
@@ -45,7 +46,7 @@ func TestComputeHashSanchoImageMap(t *testing.T) {
 	}
 
 	hash := MustComputeInputHash(t, spec, imageMaps)
-	assert.Equal(t, hash, "xwdU6LXhS59jR4lfTApIkP26RJ0=")
+	assert.Equal(t, hash, "wBNAlWKjKlOYmq4-8fsku_-Td1c=")
 }
 
 func TestComputeHashSanchoIgnoresIrrelevantImageMap(t *testing.T) {
@@ -70,5 +71,113 @@ func TestComputeHashSanchoImageMapChange(t *testing.T) {
 	}
 
 	hash := MustComputeInputHash(t, spec, imageMaps)
-	assert.Equal(t, hash, "VzBtR4akztTBxoGNlWc56QtD9b0=")
+	assert.Equal(t, hash, "bFC3S5E8v3QrZjBysx8uYKVozF0=")
+}
+
+func TestComputeHashIgnoresFormatting(t *testing.T) {
+	a := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+  creationTimestamp: null
+data:
+  x: "1"
+  y: "2"
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: b
+spec:
+  selector: null
+  ports:
+  - port: 80
+status:
+  loadBalancer: {}
+`
+	b := `# reordered, with comments
+kind: Service
+apiVersion: v1
+metadata: {name: b}
+spec:
+  ports:
+  - port: 80
+---
+kind: ConfigMap
+apiVersion: v1
+data: {y: "2", x: "1"}
+metadata:
+  name: a
+---
+`
+	assert.Equal(t,
+		MustComputeInputHash(t, v1alpha1.KubernetesApplySpec{YAML: a}, nil),
+		MustComputeInputHash(t, v1alpha1.KubernetesApplySpec{YAML: b}, nil))
+}
+
+func TestComputeHashMeaningfulChange(t *testing.T) {
+	a := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+data:
+  x: "1"
+`
+	b := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+data:
+  x: "2"
+`
+	c := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+data: {}
+`
+	hashA := MustComputeInputHash(t, v1alpha1.KubernetesApplySpec{YAML: a}, nil)
+	assert.NotEqual(t, hashA, MustComputeInputHash(t, v1alpha1.KubernetesApplySpec{YAML: b}, nil))
+	assert.NotEqual(t, hashA, MustComputeInputHash(t, v1alpha1.KubernetesApplySpec{YAML: c}, nil))
+}
+
+func TestComputeHashIgnoresDiffPreview(t *testing.T) {
+	spec := v1alpha1.KubernetesApplySpec{YAML: testyaml.SanchoYAML, DiffPreview: true}
+	hash := MustComputeInputHash(t, spec, nil)
+	assert.Equal(t, hash, "LFGIqtR3XdaG-vuXhSxYq4epR7E=")
+}
+
+func TestComputeCmdHashDeps(t *testing.T) {
+	spec := v1alpha1.KubernetesApplySpec{
+		ApplyCmd: &v1alpha1.KubernetesApplyCmd{Args: []string{"terraform", "apply"}},
+	}
+	fw := &v1alpha1.FileWatch{
+		ObjectMeta: metav1.ObjectMeta{Name: "infra"},
+		Spec:       v1alpha1.FileWatchSpec{WatchedPaths: []string{"/infra"}},
+	}
+	hash := func() string {
+		hash, err := ComputeCmdInputHash(spec, nil, nil, []*v1alpha1.FileWatch{fw})
+		require.NoError(t, err)
+		return hash
+	}
+
+	_, err := ComputeCmdInputHash(spec, nil, nil, []*v1alpha1.FileWatch{fw})
+	assert.EqualError(t, err, "infra: not watching files yet")
+
+	fw.Status.MonitorStartTime = metav1.NewMicroTime(time.Unix(1, 0))
+	hashA := hash()
+	assert.NotEqual(t, MustComputeInputHash(t, spec, nil), hashA)
+	assert.Equal(t, hashA, hash())
+
+	fw.Status.LastEventTime = metav1.NewMicroTime(time.Unix(2, 0))
+	hashB := hash()
+	assert.NotEqual(t, hashA, hashB)
+
+	// A restarted watcher may have missed changes.
+	fw.Status.MonitorStartTime = metav1.NewMicroTime(time.Unix(3, 0))
+	assert.NotEqual(t, hashB, hash())
+
+	fw.Status.Error = "too many files"
+	_, err = ComputeCmdInputHash(spec, nil, nil, []*v1alpha1.FileWatch{fw})
+	assert.EqualError(t, err, "infra: too many files")
 }
@@ -0,0 +1,246 @@
+package kubernetesapply
+
+import (
+	"context"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/tilt-dev/tilt/internal/controllers/indexer"
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+var kdType = metav1.TypeMeta{
+	Kind:       "KubernetesDiscovery",
+	APIVersion: v1alpha1.SchemeGroupVersion.String(),
+}
+
+// How long to keep completed Jobs, as set by update_settings().
+//
+// Zero means that we never delete them.
+func (r *Reconciler) jobRetention() time.Duration {
+	state := r.st.RLockState()
+	defer r.st.RUnlockState()
+	return state.UpdateSettings.K8sJobRetention()
+}
+
+// Dev namespaces tend to accumulate completed Jobs (like database migrations).
+//
+// If the user has set a retention period, delete the Jobs that we applied
+// (or created from CronJobs) once they've succeeded, we've captured all
+// their logs, and the retention period has passed. Failed Jobs are left
+// alone, so that the user can inspect them.
+//
+// Returns how long to wait before the next Job can be deleted,
+// or zero if there's nothing to wait for.
+func (r *Reconciler) maybeCollectCompletedJobs(ctx context.Context, nn types.NamespacedName) (time.Duration, error) {
+	retention := r.jobRetention()
+	if retention == 0 {
+		return 0, nil
+	}
+
+	r.mu.Lock()
+	jobs := r.collectableJobs(nn)
+	r.mu.Unlock()
+	if len(jobs) == 0 {
+		return 0, nil
+	}
+
+	// Each KubernetesApply owns a KubernetesDiscovery of the same name,
+	// which tracks the Jobs' pods and owns their PodLogStreams.
+	var kd v1alpha1.KubernetesDiscovery
+	err := r.ctrlClient.Get(ctx, nn, &kd)
+	if err != nil {
+		return 0, client.IgnoreNotFound(err)
+	}
+
+	var streams v1alpha1.PodLogStreamList
+	err = indexer.ListOwnedBy(ctx, r.ctrlClient, &streams, nn, kdType)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	var toDelete []k8s.K8sEntity
+	var requeueAfter time.Duration
+	for _, job := range jobs {
+		finishedAt, ok := jobFinishedAt(job, kd.Status.Pods, streams.Items)
+		if !ok {
+			continue
+		}
+
+		wait := finishedAt.Add(retention).Sub(now)
+		if wait > 0 {
+			if requeueAfter == 0 || wait < requeueAfter {
+				requeueAfter = wait
+			}
+			continue
+		}
+		toDelete = append(toDelete, job)
+	}
+
+	if len(toDelete) == 0 {
+		return requeueAfter, nil
+	}
+
+	r.bestEffortDelete(ctx, nn, deleteSpec{entities: toDelete}, "cleaning up completed Jobs")
+	r.recordCollectedJobs(nn, toDelete)
+	return requeueAfter, nil
+}
+
+// Returns the Jobs that we created and haven't deleted yet.
+// Caller must hold the mutex.
+func (r *Reconciler) collectableJobs(nn types.NamespacedName) []k8s.K8sEntity {
+	result, ok := r.results[nn]
+	if !ok || result.Status.Error != "" {
+		return nil
+	}
+
+	var jobs []k8s.K8sEntity
+	for _, e := range result.AppliedObjects {
+		if isJob(e) && !result.CollectedJobs.Contains(e.UID()) {
+			jobs = append(jobs, e)
+		}
+	}
+	jobs = append(jobs, result.CreatedJobs...)
+	return jobs
+}
+
+// Record that we deleted the given Jobs.
+//
+// If we applied the Job, the resource stays complete,
+// even though its pods are going away.
+func (r *Reconciler) recordCollectedJobs(nn types.NamespacedName, jobs []k8s.K8sEntity) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := r.ensureResultExists(nn)
+	collected := k8s.NewUIDSet()
+	for _, job := range jobs {
+		collected.Add(job.UID())
+	}
+
+	var createdJobs []k8s.K8sEntity
+	for _, job := range result.CreatedJobs {
+		if !collected.Contains(job.UID()) {
+			createdJobs = append(createdJobs, job)
+		}
+	}
+	result.CreatedJobs = createdJobs
+
+	// Only remember the applied Jobs, so that this doesn't grow forever.
+	appliedJobs := k8s.NewUIDSet()
+	for _, e := range result.AppliedObjects {
+		uid := e.UID()
+		if isJob(e) && (collected.Contains(uid) || result.CollectedJobs.Contains(uid)) {
+			appliedJobs.Add(uid)
+		}
+	}
+	result.CollectedJobs = appliedJobs
+
+	if len(appliedJobs) == 0 ||
+		meta.IsStatusConditionTrue(result.Status.Conditions, v1alpha1.ApplyConditionJobComplete) {
+		return
+	}
+
+	update := result.Status.DeepCopy()
+	update.Conditions = append(update.Conditions, metav1.Condition{
+		Type:   v1alpha1.ApplyConditionJobComplete,
+		Status: metav1.ConditionTrue,
+	})
+	result.Status = *update
+}
+
+// Checks if the Job has succeeded and we've captured all the logs of its pods.
+//
+// Returns the time that the Job finished.
+func jobFinishedAt(job k8s.K8sEntity, pods []v1alpha1.Pod, streams []v1alpha1.PodLogStream) (time.Time, bool) {
+	completions := int32(1)
+	if j, ok := job.Obj.(*batchv1.Job); ok && j.Spec.Completions != nil {
+		completions = *j.Spec.Completions
+	}
+
+	var finishedAt time.Time
+	succeeded := int32(0)
+	creation := job.Meta().GetCreationTimestamp()
+	for _, pod := range pods {
+		owner := pod.Owner
+		// Match on the creation time, so that pods of an old Job
+		// don't count towards a re-created Job of the same name.
+		if owner == nil || owner.Kind != "Job" || owner.Name != job.Name() ||
+			pod.Namespace != job.Namespace().String() ||
+			!owner.CreationTimestamp.Equal(&creation) {
+			continue
+		}
+
+		switch v1.PodPhase(pod.Phase) {
+		case v1.PodSucceeded:
+			succeeded++
+		case v1.PodFailed:
+		default:
+			// The Job is still running.
+			return time.Time{}, false
+		}
+
+		if !podLogsCaptured(pod, streams) {
+			return time.Time{}, false
+		}
+
+		for _, c := range pod.Containers {
+			if c.State.Terminated != nil && c.State.Terminated.FinishedAt.After(finishedAt) {
+				finishedAt = c.State.Terminated.FinishedAt.Time
+			}
+		}
+	}
+
+	if succeeded < completions {
+		return time.Time{}, false
+	}
+	return finishedAt, true
+}
+
+func podLogsCaptured(pod v1alpha1.Pod, streams []v1alpha1.PodLogStream) bool {
+	for _, s := range streams {
+		if s.Spec.Pod != pod.Name || s.Spec.Namespace != pod.Namespace {
+			continue
+		}
+		if len(s.Status.ContainerStatuses) == 0 {
+			return false
+		}
+		for _, c := range s.Status.ContainerStatuses {
+			if !c.Terminated {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func isJob(e k8s.K8sEntity) bool {
+	return e.GVK() == batchv1.SchemeGroupVersion.WithKind("Job")
+}
+
+// PodLogStreams are owned by a KubernetesDiscovery, which is owned by the
+// KubernetesApply of the same name. Their status tells us when a Job's
+// logs have been captured.
+func (r *Reconciler) enqueueForPodLogStream(obj client.Object) []reconcile.Request {
+	if r.jobRetention() == 0 {
+		return nil
+	}
+
+	owner := metav1.GetControllerOf(obj)
+	if owner == nil || owner.Kind != kdType.Kind || owner.APIVersion != kdType.APIVersion {
+		return nil
+	}
+	return []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Namespace: obj.GetNamespace(), Name: owner.Name}},
+	}
+}
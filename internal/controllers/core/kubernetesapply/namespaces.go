@@ -0,0 +1,297 @@
+package kubernetesapply
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/tilt-dev/tilt/internal/controllers/apicmp"
+	"github.com/tilt-dev/tilt/internal/controllers/apis/uibutton"
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/store/kubernetesapplys"
+	"github.com/tilt-dev/tilt/internal/timecmp"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+// Where we remember the namespaces that the user approved, by project.
+//
+// Relative to the Tilt dev dir (~/.tilt-dev).
+const approvedNamespacesFile = "approved_namespaces.json"
+
+// Copy-pasted YAML sometimes hardcodes someone else's namespace.
+//
+// Before we apply YAML to a namespace that the project hasn't deployed to
+// before, we ask the user to approve it. These namespaces don't need approval:
+//
+//   - The cluster's default namespace, where objects without a namespace go.
+//   - Namespaces from allow_k8s_namespaces(), or that the project creates.
+//   - Namespaces that the user approved for this project before.
+//   - Namespaces that the project deployed these objects to before,
+//     so that upgrading Tilt doesn't block existing projects.
+//
+// Returns the namespaces that need approval, sorted.
+func (r *Reconciler) unapprovedNamespaces(ctx context.Context, nn types.NamespacedName, cluster *v1alpha1.Cluster, entities []k8s.K8sEntity) ([]string, error) {
+	var ka v1alpha1.KubernetesApply
+	err := r.ctrlClient.Get(ctx, nn, &ka)
+	if err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+
+	value, ok := ka.Annotations[v1alpha1.AnnotationAllowedNamespaces]
+	if !ok {
+		return nil, nil
+	}
+
+	allowed := sets.NewString(defaultNamespace(cluster))
+	if value != "" {
+		allowed.Insert(strings.Split(value, ",")...)
+	}
+	for _, e := range entities {
+		if k8s.IsNamespace(e) {
+			allowed.Insert(e.Name())
+		}
+	}
+
+	hardcoded := sets.NewString()
+	for _, e := range entities {
+		ns := e.Meta().GetNamespace()
+		if ns != "" && !allowed.Has(ns) {
+			hardcoded.Insert(ns)
+		}
+	}
+	if hardcoded.Len() == 0 {
+		return nil, nil
+	}
+
+	approvals, err := r.readNamespaceApprovals()
+	if err != nil {
+		return nil, err
+	}
+	project, err := r.projectPath(ctx, &ka)
+	if err != nil {
+		return nil, err
+	}
+	unapproved := hardcoded.Difference(sets.NewString(approvals[project]...))
+	if unapproved.Len() == 0 {
+		return nil, nil
+	}
+
+	deployed := r.previouslyDeployedNamespaces(ctx, entities, unapproved)
+	if len(deployed) > 0 {
+		err := r.approveNamespaces(project, deployed)
+		if err != nil {
+			return nil, err
+		}
+		unapproved.Delete(deployed...)
+	}
+	return unapproved.List(), nil
+}
+
+// Finds the namespaces where Tilt already applied some of these objects,
+// which means that a past apply to the namespace succeeded.
+//
+// A failed lookup means we can't vouch for the namespace, so we ask.
+func (r *Reconciler) previouslyDeployedNamespaces(ctx context.Context, entities []k8s.K8sEntity, namespaces sets.String) []string {
+	result := sets.NewString()
+	for _, e := range entities {
+		ns := e.Meta().GetNamespace()
+		if !namespaces.Has(ns) || result.Has(ns) {
+			continue
+		}
+
+		existing, err := r.k8sClient.GetMetaByReference(ctx, e.ToObjectReference())
+		if err != nil {
+			continue
+		}
+		if existing.GetLabels()[k8s.ManagedByLabel] == k8s.ManagedByValue {
+			result.Insert(ns)
+		}
+	}
+	return result.List()
+}
+
+// The namespace that objects without a namespace are applied to.
+func defaultNamespace(cluster *v1alpha1.Cluster) string {
+	if cluster != nil && cluster.Status.Connection != nil &&
+		cluster.Status.Connection.Kubernetes != nil &&
+		cluster.Status.Connection.Kubernetes.Namespace != "" {
+		return cluster.Status.Connection.Kubernetes.Namespace
+	}
+	return k8s.DefaultNamespace.String()
+}
+
+// Approvals are per-project, so we key them by the path
+// of the Tiltfile that created the KubernetesApply.
+func (r *Reconciler) projectPath(ctx context.Context, ka *v1alpha1.KubernetesApply) (string, error) {
+	owner := metav1.GetControllerOf(ka)
+	if owner == nil || owner.Kind != "Tiltfile" {
+		return "", nil
+	}
+
+	var tf v1alpha1.Tiltfile
+	err := r.ctrlClient.Get(ctx, types.NamespacedName{Name: owner.Name}, &tf)
+	if err != nil {
+		return "", client.IgnoreNotFound(err)
+	}
+	return tf.Spec.Path, nil
+}
+
+// Reads the approved namespaces, as a map from project to namespaces.
+func (r *Reconciler) readNamespaceApprovals() (map[string][]string, error) {
+	contents, err := r.dir.ReadFile(approvedNamespacesFile)
+	if os.IsNotExist(err) {
+		return map[string][]string{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("reading approved namespaces: %v", err)
+	}
+
+	approvals := map[string][]string{}
+	err = json.Unmarshal([]byte(contents), &approvals)
+	if err != nil {
+		return nil, fmt.Errorf("reading approved namespaces: %v", err)
+	}
+	return approvals, nil
+}
+
+func (r *Reconciler) approveNamespaces(project string, namespaces []string) error {
+	r.approvalsMu.Lock()
+	defer r.approvalsMu.Unlock()
+
+	approvals, err := r.readNamespaceApprovals()
+	if err != nil {
+		return err
+	}
+	approvals[project] = sets.NewString(approvals[project]...).Insert(namespaces...).List()
+
+	contents, err := json.MarshalIndent(approvals, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = r.dir.WriteFile(approvedNamespacesFile, string(contents))
+	if err != nil {
+		return fmt.Errorf("saving approved namespaces: %v", err)
+	}
+	return nil
+}
+
+// If the user clicked the resource's "Approve Namespaces" button,
+// remember the namespaces for this project, and deploy again.
+//
+// We approve the namespaces in the current spec, rather than the ones
+// that the button displayed, so that the button can't approve anything else.
+func (r *Reconciler) maybeApproveNamespaces(ctx context.Context, nn types.NamespacedName, ka *v1alpha1.KubernetesApply, cluster *v1alpha1.Cluster) error {
+	var button v1alpha1.UIButton
+	err := r.ctrlClient.Get(ctx, types.NamespacedName{Name: uibutton.ApproveNamespacesButtonName(nn.Name)}, &button)
+	if err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	lastClick := button.Status.LastClickedAt
+	r.mu.Lock()
+	result := r.ensureResultExists(nn)
+	isNewClick := timecmp.After(lastClick, result.LastApproveNamespacesClick)
+	if isNewClick {
+		result.LastApproveNamespacesClick = lastClick
+	}
+	r.mu.Unlock()
+
+	if !isNewClick || ka.Spec.YAML == "" {
+		return nil
+	}
+
+	entities, err := k8s.ParseYAMLFromString(ka.Spec.YAML)
+	if err != nil {
+		return fmt.Errorf("reading YAML: %v", err)
+	}
+	namespaces, err := r.unapprovedNamespaces(ctx, nn, cluster, entities)
+	if err != nil {
+		return err
+	}
+	if len(namespaces) == 0 {
+		return nil
+	}
+
+	project, err := r.projectPath(ctx, ka)
+	if err != nil {
+		return err
+	}
+	err = r.approveNamespaces(project, namespaces)
+	if err != nil {
+		return err
+	}
+	logger.Get(ctx).Infof("Approved deploying to namespaces: %s", strings.Join(namespaces, ", "))
+
+	if ka.Annotations[v1alpha1.AnnotationManagedBy] != "" {
+		// The buildcontrol engine deploys this resource,
+		// so ask it to build the resource again.
+		mn := ka.Annotations[v1alpha1.AnnotationManifest]
+		if mn == "" {
+			mn = nn.Name
+		}
+		r.st.Dispatch(kubernetesapplys.NewKubernetesApplyNamespacesApprovedAction(mn))
+		return nil
+	}
+
+	r.mu.Lock()
+	result = r.ensureResultExists(nn)
+	result.NamespacesApproved = true
+	r.mu.Unlock()
+	return nil
+}
+
+// Shows the "Approve Namespaces" button while the resource is waiting
+// for approval, and removes it once it's not.
+func (r *Reconciler) manageNamespaceApprovalButton(ctx context.Context, nn types.NamespacedName, ka *v1alpha1.KubernetesApply) error {
+	var desired *v1alpha1.UIButton
+	if ka != nil {
+		cond := meta.FindStatusCondition(ka.Status.Conditions, v1alpha1.ApplyConditionNamespaceApprovalRequired)
+		if cond != nil && cond.Status == metav1.ConditionTrue {
+			desired = uibutton.ApproveNamespacesButton(nn.Name, strings.Split(cond.Message, ","))
+		}
+	}
+
+	var existing v1alpha1.UIButton
+	err := r.ctrlClient.Get(ctx, types.NamespacedName{Name: uibutton.ApproveNamespacesButtonName(nn.Name)}, &existing)
+	isNotFound := apierrors.IsNotFound(err)
+	if err != nil && !isNotFound {
+		return err
+	}
+
+	if isNotFound {
+		if desired == nil {
+			return nil
+		}
+		err := r.ctrlClient.Create(ctx, desired)
+		if apierrors.IsAlreadyExists(err) {
+			return nil
+		}
+		return err
+	}
+
+	if desired == nil {
+		return client.IgnoreNotFound(r.ctrlClient.Delete(ctx, &existing))
+	}
+
+	if !apicmp.DeepEqual(existing.Spec, desired.Spec) {
+		existing.Spec = desired.Spec
+		return client.IgnoreNotFound(r.ctrlClient.Update(ctx, &existing))
+	}
+	return nil
+}
+
+func namespaceApprovalError(namespaces []string, resourceName string) error {
+	return fmt.Errorf("waiting for approval to deploy to namespaces that this project hasn't deployed to before: %s.\n"+
+		"If you meant to, click \"Approve Namespaces\", or run 'tilt approve-namespaces %s'. "+
+		"To allow them in the Tiltfile, use allow_k8s_namespaces()",
+		strings.Join(namespaces, ", "), resourceName)
+}
@@ -0,0 +1,52 @@
+package kubernetesapply
+
+import (
+	"fmt"
+
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+// Split the objects into the stages of the apply order.
+//
+// Each object goes in the stage of the first selector that matches it, and
+// objects that match no selector go in a final stage. Empty stages are
+// skipped. Each stage is then split by the objects' apply weights
+// (see k8s.AnnotationApplyWeight). Within each stage, objects are sorted by
+// kind, so that (e.g.) Namespaces and CRDs are created before the objects
+// that use them.
+func applyStages(order []v1alpha1.ObjectSelector, entities []k8s.K8sEntity) ([][]k8s.K8sEntity, error) {
+	selectors := make([]k8s.ObjectSelector, 0, len(order))
+	for i, spec := range order {
+		selector, err := k8s.ParseObjectSelector(spec)
+		if err != nil {
+			return nil, fmt.Errorf("applyOrder[%d]: %v", i, err)
+		}
+		selectors = append(selectors, selector)
+	}
+
+	stages := make([][]k8s.K8sEntity, len(selectors)+1)
+	for _, e := range entities {
+		i := len(selectors)
+		for j, selector := range selectors {
+			if selector.Matches(e) {
+				i = j
+				break
+			}
+		}
+		stages[i] = append(stages[i], e)
+	}
+
+	result := make([][]k8s.K8sEntity, 0, len(stages))
+	for _, stage := range stages {
+		if len(stage) == 0 {
+			continue
+		}
+		weighted, err := k8s.ApplyWeightStages(stage)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, weighted...)
+	}
+	return result, nil
+}
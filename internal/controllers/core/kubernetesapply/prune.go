@@ -0,0 +1,147 @@
+package kubernetesapply
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+// Records the prune owner of the KubernetesApply that applied an object,
+// so that we can find the object again after Tilt restarts.
+const pruneOwnerAnnotation = "tilt.dev/prune-owner"
+
+// Kinds that we look for when pruning, in addition to the kinds in the YAML.
+//
+// Like `kubectl apply --prune`, we only check common kinds, because listing
+// every kind in the cluster would be slow.
+var pruneKinds = []schema.GroupVersionKind{
+	{Version: "v1", Kind: "ConfigMap"},
+	{Version: "v1", Kind: "Pod"},
+	{Version: "v1", Kind: "Secret"},
+	{Version: "v1", Kind: "Service"},
+	{Version: "v1", Kind: "ServiceAccount"},
+	{Group: "apps", Version: "v1", Kind: "DaemonSet"},
+	{Group: "apps", Version: "v1", Kind: "Deployment"},
+	{Group: "apps", Version: "v1", Kind: "StatefulSet"},
+	{Group: "batch", Version: "v1", Kind: "CronJob"},
+	{Group: "batch", Version: "v1", Kind: "Job"},
+	{Group: "networking.k8s.io", Version: "v1", Kind: "Ingress"},
+}
+
+// Kinds that we never prune, because deleting them is likely
+// to be more destructive than the user wants.
+var neverPruneKinds = map[schema.GroupKind]bool{
+	{Kind: "Namespace"}:             true,
+	{Kind: "PersistentVolume"}:      true,
+	{Kind: "PersistentVolumeClaim"}: true,
+}
+
+// Marks the objects as applied by the given prune owner.
+func annotatePruneOwner(entities []k8s.K8sEntity, owner string) []k8s.K8sEntity {
+	result := make([]k8s.K8sEntity, 0, len(entities))
+	for _, e := range entities {
+		e = e.DeepCopy()
+		meta := e.Meta()
+		annotations := meta.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[pruneOwnerAnnotation] = owner
+		meta.SetAnnotations(annotations)
+		result = append(result, e)
+	}
+	return result
+}
+
+// Deletes objects that this KubernetesApply applied in the past
+// (even before Tilt restarted) but that are no longer in the YAML.
+//
+// Returns the objects that we deleted.
+func (r *Reconciler) pruneRemovedObjects(ctx context.Context, nn types.NamespacedName, owner string, deployed []k8s.K8sEntity) []k8s.K8sEntity {
+	kinds := append([]schema.GroupVersionKind{}, pruneKinds...)
+	seenKinds := map[schema.GroupVersionKind]bool{}
+	for _, gvk := range kinds {
+		seenKinds[gvk] = true
+	}
+
+	keep := k8s.NewUIDSet()
+	namespaces := sets.NewString()
+	for _, e := range deployed {
+		keep.Add(e.UID())
+		namespaces.Insert(e.Namespace().String())
+		if gvk := e.GVK(); !seenKinds[gvk] {
+			seenKinds[gvk] = true
+			kinds = append(kinds, gvk)
+		}
+	}
+
+	// Don't delete objects that moved to another resource.
+	r.mu.Lock()
+	for key, result := range r.results {
+		if key == nn {
+			continue
+		}
+		for _, e := range result.AppliedObjects {
+			keep.Add(e.UID())
+		}
+	}
+	r.mu.Unlock()
+
+	var toDelete []k8s.K8sEntity
+	for _, gvk := range kinds {
+		if neverPruneKinds[gvk.GroupKind()] {
+			continue
+		}
+
+		for _, ns := range namespaces.List() {
+			objs, err := r.k8sClient.ListMeta(ctx, gvk, k8s.Namespace(ns))
+			if err != nil {
+				// The cluster may not serve this kind.
+				logger.Get(ctx).Debugf("Listing %s for pruning: %v", gvk.Kind, err)
+				continue
+			}
+
+			for _, obj := range objs {
+				if obj.GetAnnotations()[pruneOwnerAnnotation] != owner ||
+					keep.Contains(obj.GetUID()) ||
+					len(obj.GetOwnerReferences()) > 0 ||
+					obj.GetDeletionTimestamp() != nil {
+					continue
+				}
+
+				// Cluster-scoped objects show up in every namespace.
+				keep.Add(obj.GetUID())
+
+				u := &unstructured.Unstructured{}
+				u.SetGroupVersionKind(gvk)
+				u.SetName(obj.GetName())
+				u.SetNamespace(obj.GetNamespace())
+				u.SetUID(obj.GetUID())
+				toDelete = append(toDelete, k8s.NewK8sEntity(u))
+			}
+		}
+	}
+
+	if len(toDelete) == 0 {
+		return nil
+	}
+
+	l := logger.Get(ctx)
+	l.Infof("Pruning objects removed from the YAML:")
+	for _, displayName := range k8s.UniqueNames(toDelete, 2) {
+		l.Infof("  → %s", displayName)
+	}
+
+	err := r.k8sClient.Delete(ctx, toDelete, false)
+	if err != nil {
+		l.Errorf("Error pruning objects: %v", err)
+		return nil
+	}
+	return toDelete
+}
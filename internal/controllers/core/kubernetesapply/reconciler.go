@@ -4,8 +4,11 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"strings"
 	"sync"
+	"time"
 
+	"github.com/jonboulle/clockwork"
 	"github.com/pkg/errors"
 	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
@@ -22,13 +25,18 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
+	"github.com/tilt-dev/wmclient/pkg/dirs"
+
 	"github.com/tilt-dev/tilt/internal/build"
 	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/internal/controllers/apicmp"
+	clusterapi "github.com/tilt-dev/tilt/internal/controllers/apis/cluster"
 	"github.com/tilt-dev/tilt/internal/controllers/apis/configmap"
 	"github.com/tilt-dev/tilt/internal/controllers/apis/imagemap"
 	"github.com/tilt-dev/tilt/internal/controllers/apis/trigger"
+	"github.com/tilt-dev/tilt/internal/controllers/apis/uibutton"
 	"github.com/tilt-dev/tilt/internal/controllers/indexer"
+	"github.com/tilt-dev/tilt/internal/docker"
 	"github.com/tilt-dev/tilt/internal/k8s"
 	"github.com/tilt-dev/tilt/internal/localexec"
 	"github.com/tilt-dev/tilt/internal/store"
@@ -47,19 +55,31 @@ type deleteSpec struct {
 
 	// waits for the entities to fully delete
 	wait bool
+
+	// hands GitOps-managed objects back to their GitOps tools
+	// instead of deleting them
+	resumeGitOps bool
 }
 
 type Reconciler struct {
 	st         store.RStore
 	dkc        build.DockerKubeConnection
+	dCli       docker.Client
 	k8sClient  k8s.Client
 	ctrlClient ctrlclient.Client
 	indexer    *indexer.Indexer
 	execer     localexec.Execer
 	requeuer   *indexer.Requeuer
+	dir        *dirs.TiltDevDir
+
+	// Tracks the object fields in RestartOn.
+	fieldTracker *trigger.ObjectFieldTracker
 
 	mu sync.Mutex
 
+	// Serializes writes to the approved namespaces file.
+	approvalsMu sync.Mutex
+
 	// Protected by the mutex.
 	results map[types.NamespacedName]*Result
 }
@@ -74,7 +94,11 @@ func (r *Reconciler) CreateBuilder(mgr ctrl.Manager) (*builder.Builder, error) {
 		Watches(&source.Kind{Type: &v1alpha1.ConfigMap{}},
 			handler.EnqueueRequestsFromMapFunc(r.indexer.Enqueue)).
 		Watches(&source.Kind{Type: &v1alpha1.Cluster{}},
-			handler.EnqueueRequestsFromMapFunc(r.indexer.Enqueue))
+			handler.EnqueueRequestsFromMapFunc(r.indexer.Enqueue)).
+		Watches(&source.Kind{Type: &v1alpha1.UIButton{}},
+			handler.EnqueueRequestsFromMapFunc(r.indexer.Enqueue)).
+		Watches(&source.Kind{Type: &v1alpha1.PodLogStream{}},
+			handler.EnqueueRequestsFromMapFunc(r.enqueueForPodLogStream))
 
 	trigger.SetupControllerRestartOn(b, r.indexer, func(obj ctrlclient.Object) *v1alpha1.RestartOnSpec {
 		return obj.(*v1alpha1.KubernetesApply).Spec.RestartOn
@@ -83,16 +107,20 @@ func (r *Reconciler) CreateBuilder(mgr ctrl.Manager) (*builder.Builder, error) {
 	return b, nil
 }
 
-func NewReconciler(ctrlClient ctrlclient.Client, k8sClient k8s.Client, scheme *runtime.Scheme, dkc build.DockerKubeConnection, st store.RStore, execer localexec.Execer) *Reconciler {
+func NewReconciler(ctrlClient ctrlclient.Client, k8sClient k8s.Client, scheme *runtime.Scheme, dkc build.DockerKubeConnection, dCli docker.Client, st store.RStore, execer localexec.Execer, dir *dirs.TiltDevDir) *Reconciler {
 	return &Reconciler{
 		ctrlClient: ctrlClient,
 		k8sClient:  k8sClient,
 		indexer:    indexer.NewIndexer(scheme, indexKubernetesApply),
 		execer:     execer,
 		dkc:        dkc,
+		dCli:       dCli,
 		st:         st,
 		results:    make(map[types.NamespacedName]*Result),
 		requeuer:   indexer.NewRequeuer(),
+		dir:        dir,
+
+		fieldTracker: trigger.NewObjectFieldTracker(clockwork.NewRealClock()),
 	}
 }
 
@@ -113,10 +141,16 @@ func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 			return ctrl.Result{}, err
 		}
 
+		err = r.manageNamespaceApprovalButton(ctx, nn, nil)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+
 		r.recordDelete(nn)
 		toDelete := r.garbageCollect(nn, true)
 		r.bestEffortDelete(ctx, nn, toDelete, "garbage collecting Kubernetes objects")
 		r.clearRecord(nn)
+		r.fieldTracker.Forget(nn)
 
 		r.st.Dispatch(kubernetesapplys.NewKubernetesApplyDeleteAction(request.NamespacedName.Name))
 		return result, nil
@@ -136,6 +170,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 
 	// Delete kubernetesapply if it's disabled
 	isDisabling := false
+	var jobGCRequeueAfter, rolloutRequeueAfter time.Duration
 	gcReason := "garbage collecting Kubernetes objects"
 	if disableStatus.State == v1alpha1.DisableStateDisabled {
 		gcReason = "deleting disabled Kubernetes objects"
@@ -155,10 +190,25 @@ func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 			return ctrl.Result{}, err
 		}
 
-		lastRestartEvent, _, _, err := trigger.LastRestartEvent(ctx, r.ctrlClient, ka.Spec.RestartOn)
+		lastRestartEvent, lastRestartButton, _, err := trigger.LastRestartEvent(ctx, r.ctrlClient, ka.Spec.RestartOn)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+
+		lastFieldChange, err := r.fieldTracker.LastChange(ctx, r.ctrlClient, nn, ka.Spec.RestartOn)
 		if err != nil {
 			return ctrl.Result{}, err
 		}
+		r.maybeQueueForFieldChange(nn, &ka, lastFieldChange)
+		fieldChanged := timecmp.After(lastFieldChange, lastRestartEvent)
+		if fieldChanged {
+			lastRestartEvent = lastFieldChange
+		}
+
+		err = r.maybeApproveNamespaces(ctx, nn, &ka, &cluster)
+		if err != nil {
+			logger.Get(ctx).Errorf("Approving namespaces: %v", err)
+		}
 
 		// Apply to the cluster if necessary.
 		//
@@ -166,9 +216,39 @@ func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 		// be a reason why we're not deploying, and we should update the
 		// Status field of KubernetesApply with that reason.
 		if r.shouldDeployOnReconcile(request.NamespacedName, &ka, &cluster, imageMaps, lastRestartEvent) {
-			_ = r.forceApplyHelper(ctx, nn, ka.Spec, &cluster, imageMaps)
+			// If the user clicked a restart button, or a watched field changed,
+			// they want the apply command to run, even if its inputs haven't changed.
+			triggered := (lastRestartButton != nil || fieldChanged) && r.restartedSinceLastApply(nn, lastRestartEvent)
+			_ = r.forceApplyHelper(ctx, nn, ka.Spec, &cluster, imageMaps, triggered)
 			gcReason = "garbage collecting removed Kubernetes objects"
 		}
+
+		r.maybeComputeDiff(ctx, nn, &ka, &cluster, imageMaps)
+
+		err = r.maybeRunCronJobs(ctx, nn)
+		if err != nil {
+			logger.Get(ctx).Errorf("Running CronJob: %v", err)
+		}
+
+		err = r.maybeRollback(ctx, nn, ka.Spec)
+		if err != nil {
+			logger.Get(ctx).Errorf("Rolling back: %v", err)
+		}
+
+		err = r.maybeResetVolumes(ctx, nn, ka.Spec)
+		if err != nil {
+			logger.Get(ctx).Errorf("Resetting volumes: %v", err)
+		}
+
+		jobGCRequeueAfter, err = r.maybeCollectCompletedJobs(ctx, nn)
+		if err != nil {
+			logger.Get(ctx).Errorf("Cleaning up completed Jobs: %v", err)
+		}
+
+		rolloutRequeueAfter, err = r.maybeCheckRollout(ctx, nn)
+		if err != nil {
+			logger.Get(ctx).Errorf("Checking rollout: %v", err)
+		}
 	}
 
 	toDelete := r.garbageCollect(nn, isDisabling)
@@ -179,7 +259,21 @@ func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 		return ctrl.Result{}, err
 	}
 
-	return r.manageOwnedKubernetesDiscovery(ctx, nn, newKA)
+	err = r.manageNamespaceApprovalButton(ctx, nn, newKA)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	result, err := r.manageOwnedKubernetesDiscovery(ctx, nn, newKA)
+	if err != nil {
+		return result, err
+	}
+	for _, requeueAfter := range []time.Duration{jobGCRequeueAfter, rolloutRequeueAfter} {
+		if requeueAfter > 0 && (result.RequeueAfter == 0 || requeueAfter < result.RequeueAfter) {
+			result.RequeueAfter = requeueAfter
+		}
+	}
+	return result, nil
 }
 
 // Determine if we should deploy the current YAML.
@@ -228,6 +322,11 @@ func (r *Reconciler) shouldDeployOnReconcile(
 		return true
 	}
 
+	if result.NamespacesApproved {
+		// The user approved the namespaces that the last apply was waiting on.
+		return true
+	}
+
 	if !apicmp.DeepEqual(ka.Spec, result.Spec) {
 		// The YAML to deploy changed.
 		return true
@@ -256,11 +355,43 @@ func (r *Reconciler) shouldDeployOnReconcile(
 	return false
 }
 
+// Objects managed by the buildcontrol engine don't apply on reconcile,
+// so when a watched object field changes, we queue a build instead.
+func (r *Reconciler) maybeQueueForFieldChange(nn types.NamespacedName, ka *v1alpha1.KubernetesApply, lastFieldChange metav1.MicroTime) {
+	if ka.Annotations[v1alpha1.AnnotationManagedBy] != "buildcontrol" {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := r.ensureResultExists(nn)
+	if !timecmp.After(lastFieldChange, result.LastFieldChange) {
+		return
+	}
+	result.LastFieldChange = lastFieldChange
+
+	r.st.Dispatch(store.AppendToTriggerQueueAction{
+		Name:   model.ManifestName(ka.Annotations[v1alpha1.AnnotationManifest]),
+		Reason: model.BuildReasonFlagTriggerExternal,
+	})
+}
+
+func (r *Reconciler) restartedSinceLastApply(nn types.NamespacedName, lastRestartEvent metav1.MicroTime) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result, ok := r.results[nn]
+	return !ok || timecmp.After(lastRestartEvent, result.Status.LastApplyTime)
+}
+
 // Inject the images into the YAML and apply it to the cluster, unconditionally.
 //
 // Does not update the API server, but does trigger a re-reconcile
 // so that the reconciliation loop will handle it.
 //
+// If triggered is true, the user asked for this apply (e.g., with a trigger button),
+// so we always run the apply command, even if its inputs haven't changed.
+//
 // We expose this as a public method as a hack! Currently, in Tilt, BuildController
 // handles dependencies between resources. The API server doesn't know about build
 // dependencies yet. So Tiltfile-owned resources are applied manually, rather than
@@ -270,8 +401,9 @@ func (r *Reconciler) ForceApply(
 	nn types.NamespacedName,
 	spec v1alpha1.KubernetesApplySpec,
 	cluster *v1alpha1.Cluster,
-	imageMaps map[types.NamespacedName]*v1alpha1.ImageMap) v1alpha1.KubernetesApplyStatus {
-	status := r.forceApplyHelper(ctx, nn, spec, cluster, imageMaps)
+	imageMaps map[types.NamespacedName]*v1alpha1.ImageMap,
+	triggered bool) v1alpha1.KubernetesApplyStatus {
+	status := r.forceApplyHelper(ctx, nn, spec, cluster, imageMaps, triggered)
 	r.requeuer.Add(nn)
 	return status
 }
@@ -284,6 +416,7 @@ func (r *Reconciler) forceApplyHelper(
 	spec v1alpha1.KubernetesApplySpec,
 	cluster *v1alpha1.Cluster,
 	imageMaps map[types.NamespacedName]*v1alpha1.ImageMap,
+	triggered bool,
 ) v1alpha1.KubernetesApplyStatus {
 
 	startTime := apis.NowMicro()
@@ -297,6 +430,12 @@ func (r *Reconciler) forceApplyHelper(
 		return r.recordApplyResult(nn, spec, cluster, imageMaps, status)
 	}
 
+	// If we already know that the cluster is unreachable (e.g., because the
+	// VPN dropped), say so, rather than waiting for the apply to time out.
+	if err := clusterapi.UnreachableError(cluster); err != nil {
+		return recordErrorStatus(err)
+	}
+
 	inputHash, err := ComputeInputHash(spec, imageMaps)
 	if err != nil {
 		return recordErrorStatus(err)
@@ -305,11 +444,21 @@ func (r *Reconciler) forceApplyHelper(
 	var deployed []k8s.K8sEntity
 	deployCtx := r.indentLogger(ctx)
 	if spec.YAML != "" {
-		deployed, err = r.runYAMLDeploy(deployCtx, spec, imageMaps)
+		deployed, err = r.runYAMLDeploy(deployCtx, nn, spec, cluster, imageMaps, &status)
 		if err != nil {
 			return recordErrorStatus(err)
 		}
 	} else {
+		cmdInputHash, ok := r.cmdInputHash(ctx, spec, cluster, imageMaps)
+		if ok {
+			inputHash = cmdInputHash
+			last, ok := r.lastCmdApply(nn, inputHash)
+			if ok && !triggered {
+				logger.Get(deployCtx).Infof("Skipping apply command: inputs unchanged since the last apply")
+				return r.recordSkippedApply(nn, spec, cluster, imageMaps, status, last)
+			}
+		}
+
 		deployed, err = r.runCmdDeploy(deployCtx, spec, cluster, imageMaps)
 		if err != nil {
 			return recordErrorStatus(err)
@@ -344,13 +493,38 @@ func (r *Reconciler) printAppliedReport(ctx context.Context, msg string, deploye
 	}
 }
 
-func (r *Reconciler) runYAMLDeploy(ctx context.Context, spec v1alpha1.KubernetesApplySpec, imageMaps map[types.NamespacedName]*v1alpha1.ImageMap) ([]k8s.K8sEntity, error) {
+func (r *Reconciler) runYAMLDeploy(ctx context.Context, nn types.NamespacedName, spec v1alpha1.KubernetesApplySpec, cluster *v1alpha1.Cluster, imageMaps map[types.NamespacedName]*v1alpha1.ImageMap, status *applyResult) ([]k8s.K8sEntity, error) {
 	// Create API objects.
 	newK8sEntities, err := r.createEntitiesToDeploy(ctx, imageMaps, spec)
 	if err != nil {
 		return newK8sEntities, err
 	}
 
+	unapproved, err := r.unapprovedNamespaces(ctx, nn, cluster, newK8sEntities)
+	if err != nil {
+		return nil, err
+	}
+	if len(unapproved) > 0 {
+		status.UnapprovedNamespaces = unapproved
+		return nil, namespaceApprovalError(unapproved, nn.Name)
+	}
+
+	if spec.PruneOwner != "" {
+		newK8sEntities = annotatePruneOwner(newK8sEntities, spec.PruneOwner)
+	}
+
+	// Save the YAML we're sending before the apply modifies the entities,
+	// so that we can re-apply it if the user rolls back a later deploy.
+	appliedYAML, err := k8s.SerializeSpecYAML(newK8sEntities)
+	if err != nil {
+		return nil, err
+	}
+
+	stages, err := applyStages(spec.ApplyOrder, newK8sEntities)
+	if err != nil {
+		return nil, err
+	}
+
 	logger.Get(ctx).Infof("Applying YAML to cluster")
 
 	timeout := spec.Timeout.Duration
@@ -358,16 +532,94 @@ func (r *Reconciler) runYAMLDeploy(ctx context.Context, spec v1alpha1.Kubernetes
 		timeout = v1alpha1.KubernetesApplyTimeoutDefault
 	}
 
-	deployed, err := r.k8sClient.Upsert(ctx, newK8sEntities, timeout)
-	if err != nil {
-		r.printAppliedReport(ctx, "Tried to apply objects to cluster:", newK8sEntities)
-		return nil, err
+	deployed := []k8s.K8sEntity{}
+	for i, stage := range stages {
+		if len(stages) > 1 {
+			logger.Get(ctx).Infof("Applying stage %d/%d (%d objects)", i+1, len(stages), len(stage))
+		}
+
+		stageDeployed, err := r.k8sClient.Upsert(ctx, stage, timeout)
+		if err != nil {
+			r.printAppliedReport(ctx, "Tried to apply objects to cluster:", stage)
+			return nil, err
+		}
+		deployed = append(deployed, stageDeployed...)
 	}
 	r.printAppliedReport(ctx, "Objects applied to cluster:", deployed)
 
+	mutations := r.webhookMutations(ctx, newK8sEntities, deployed)
+	if len(mutations) > 0 {
+		l := logger.Get(ctx)
+		l.Infof("Objects mutated by admission webhooks:")
+		for _, m := range mutations {
+			l.Infof("  → %s", m)
+		}
+	}
+
+	if spec.PruneOwner != "" {
+		status.Pruned = r.pruneRemovedObjects(ctx, nn, spec.PruneOwner, deployed)
+	}
+
+	if spec.GitOpsHandoff {
+		status.GitOpsHandoff = true
+		r.suspendGitOps(ctx, nn, deployed)
+	}
+
+	status.AppliedYAML = appliedYAML
+	status.WebhookMutations = mutations
 	return deployed, nil
 }
 
+// Describes the changes that mutating admission webhooks made to the objects we applied.
+//
+// Webhooks commonly inject sidecars or add annotations. We record these
+// separately, so that users can see why the objects in the cluster differ from
+// their YAML.
+func (r *Reconciler) webhookMutations(ctx context.Context, sent []k8s.K8sEntity, deployed []k8s.K8sEntity) []string {
+	sentByKey := make(map[string]k8s.K8sEntity, len(sent))
+	for _, e := range sent {
+		sentByKey[webhookMutationKey(e)] = e
+	}
+
+	var result []string
+	webhooksByGVK := make(map[schema.GroupVersionKind][]string)
+	displayNames := k8s.UniqueNames(deployed, 2)
+	for i, e := range deployed {
+		s, ok := sentByKey[webhookMutationKey(e)]
+		if !ok {
+			continue
+		}
+
+		fields := k8s.WebhookMutatedFields(s, e)
+		if len(fields) == 0 {
+			continue
+		}
+
+		gvk := e.GVK()
+		webhooks, ok := webhooksByGVK[gvk]
+		if !ok {
+			var err error
+			webhooks, err = r.k8sClient.MutatingWebhooks(ctx, gvk)
+			if err != nil {
+				logger.Get(ctx).Debugf("Looking up mutating webhooks for %s: %v", gvk.Kind, err)
+			}
+			webhooksByGVK[gvk] = webhooks
+		}
+
+		by := "an admission webhook"
+		if len(webhooks) > 0 {
+			by = fmt.Sprintf("webhook %s", strings.Join(webhooks, ", "))
+		}
+		result = append(result, fmt.Sprintf("%s mutated by %s (added %s)",
+			displayNames[i], by, strings.Join(fields, ", ")))
+	}
+	return result
+}
+
+func webhookMutationKey(e k8s.K8sEntity) string {
+	return fmt.Sprintf("%s/%s/%s", e.GVK().GroupKind(), e.Namespace(), e.Name())
+}
+
 func (r *Reconciler) maybeInjectKubeconfig(cmd *model.Cmd, cluster *v1alpha1.Cluster) {
 	if cluster == nil ||
 		cluster.Status.Connection == nil ||
@@ -460,7 +712,16 @@ func (r *Reconciler) createEntitiesToDeploy(ctx context.Context,
 	spec v1alpha1.KubernetesApplySpec) ([]k8s.K8sEntity, error) {
 	newK8sEntities := []k8s.K8sEntity{}
 
-	entities, err := k8s.ParseYAMLFromString(spec.YAML)
+	yaml := spec.YAML
+	if imagemap.HasVars(yaml) {
+		var err error
+		yaml, err = imagemap.ExpandVars(ctx, yaml, spec.ImageMaps, imageMaps, r.dCli)
+		if err != nil {
+			return nil, errors.Wrap(err, "expanding image variables")
+		}
+	}
+
+	entities, err := k8s.ParseYAMLFromString(yaml)
 	if err != nil {
 		return nil, err
 	}
@@ -474,6 +735,21 @@ func (r *Reconciler) createEntitiesToDeploy(ctx context.Context,
 	imageMapNames := spec.ImageMaps
 	injectedImageMaps := map[string]bool{}
 	for _, e := range entities {
+		// Stamp the resource's common labels and annotations first, so that
+		// they can't overwrite the labels that Tilt uses to track objects.
+		if len(spec.CommonLabels) > 0 {
+			e, err = k8s.InjectLabels(e, model.ToLabelPairs(spec.CommonLabels))
+			if err != nil {
+				return nil, errors.Wrap(err, "injecting common labels")
+			}
+		}
+		if len(spec.CommonAnnotations) > 0 {
+			e, err = k8s.InjectAnnotations(e, spec.CommonAnnotations)
+			if err != nil {
+				return nil, errors.Wrap(err, "injecting common annotations")
+			}
+		}
+
 		e, err = k8s.InjectLabels(e, []model.LabelPair{
 			k8s.TiltManagedByLabel(),
 		})
@@ -595,17 +871,88 @@ type applyResult struct {
 	LastApplyStartTime metav1.MicroTime
 	AppliedInputHash   string
 	Objects            []k8s.K8sEntity
+	WebhookMutations   []string
+
+	// Set if we skipped the apply command because its inputs didn't change.
+	Skipped bool
+
+	// Objects that we deleted because they were removed from the YAML.
+	Pruned []k8s.K8sEntity
+
+	// The YAML we sent to the cluster, with images injected.
+	// Only set for successful YAML deploys.
+	AppliedYAML string
+
+	// Namespaces that the YAML hardcodes, that we're waiting
+	// for the user to approve before we apply it.
+	UnapprovedNamespaces []string
+
+	// Set if we suspended GitOps reconciliation of the applied objects.
+	GitOpsHandoff bool
 }
 
 // conditionsFromApply extracts any conditions based on the result.
 //
-// Currently, this is only used as part of special handling for Jobs, which
-// might have already completed successfully in the past.
+// Currently, this is used as part of special handling for Jobs, which
+// might have already completed successfully in the past, to surface
+// changes made by mutating admission webhooks, and to record
+// apply commands that we skipped and objects that we pruned.
 func conditionsFromApply(result applyResult) []metav1.Condition {
-	if result.Error != "" || len(result.Objects) == 0 {
+	if len(result.UnapprovedNamespaces) > 0 {
+		return []metav1.Condition{{
+			Type:    v1alpha1.ApplyConditionNamespaceApprovalRequired,
+			Status:  metav1.ConditionTrue,
+			Reason:  "UnapprovedNamespaces",
+			Message: strings.Join(result.UnapprovedNamespaces, ","),
+		}}
+	}
+
+	if result.Error != "" {
 		return nil
 	}
 
+	var conditions []metav1.Condition
+	if result.Skipped {
+		conditions = append(conditions, metav1.Condition{
+			Type:    v1alpha1.ApplyConditionInputsUnchanged,
+			Status:  metav1.ConditionTrue,
+			Reason:  "InputsUnchanged",
+			Message: fmt.Sprintf("apply command skipped (input hash: %s)", result.AppliedInputHash),
+		})
+	}
+
+	if len(result.Pruned) > 0 {
+		conditions = append(conditions, metav1.Condition{
+			Type:    v1alpha1.ApplyConditionPruned,
+			Status:  metav1.ConditionTrue,
+			Reason:  "RemovedFromYAML",
+			Message: strings.Join(k8s.UniqueNames(result.Pruned, 2), ", "),
+		})
+	}
+
+	if len(result.Objects) == 0 {
+		return conditions
+	}
+
+	if jobCompleteFromApply(result) {
+		conditions = append(conditions, metav1.Condition{
+			Type:   v1alpha1.ApplyConditionJobComplete,
+			Status: metav1.ConditionTrue,
+		})
+	}
+
+	if len(result.WebhookMutations) > 0 {
+		conditions = append(conditions, metav1.Condition{
+			Type:    v1alpha1.ApplyConditionMutatedByWebhook,
+			Status:  metav1.ConditionTrue,
+			Reason:  "WebhookMutation",
+			Message: strings.Join(result.WebhookMutations, "; "),
+		})
+	}
+	return conditions
+}
+
+func jobCompleteFromApply(result applyResult) bool {
 	for _, e := range result.Objects {
 		job, ok := e.Obj.(*batchv1.Job)
 		if !ok {
@@ -613,16 +960,11 @@ func conditionsFromApply(result applyResult) []metav1.Condition {
 		}
 		for _, cond := range job.Status.Conditions {
 			if cond.Type == batchv1.JobComplete && cond.Status == v1.ConditionTrue {
-				return []metav1.Condition{
-					{
-						Type:   v1alpha1.ApplyConditionJobComplete,
-						Status: metav1.ConditionTrue,
-					},
-				}
+				return true
 			}
 		}
 	}
-	return nil
+	return false
 }
 
 // Create a result object if necessary. Caller must hold the mutex.
@@ -661,14 +1003,26 @@ func (r *Reconciler) recordApplyResult(
 	updatedStatus.LastApplyTime = applyResult.LastApplyTime
 	updatedStatus.AppliedInputHash = applyResult.AppliedInputHash
 	updatedStatus.Conditions = conditionsFromApply(applyResult)
+	if applyResult.Error == "" {
+		updatedStatus.Diff = nil
+	}
+	if len(spec.WaitFor) > 0 && applyResult.Error == "" {
+		updatedStatus.Conditions = append(updatedStatus.Conditions, rolloutWaitingCondition(applyResult.LastApplyTime))
+	}
 
 	result.Cluster = cluster
 	result.Spec = spec
 	result.Status = *updatedStatus
+	result.NamespacesApproved = false
+	if applyResult.GitOpsHandoff {
+		result.GitOpsHandoff = true
+	}
 	if spec.ApplyCmd != nil {
 		result.CmdApplied = true
 	}
 	result.SetAppliedObjects(newObjectRefSet(applyResult.Objects))
+	result.forgetDanglingObjects(applyResult.Pruned)
+	result.recordAppliedYAML(applyResult)
 
 	result.ImageMapSpecs = nil
 	result.ImageMapStatuses = nil
@@ -815,8 +1169,9 @@ func (r *Reconciler) garbageCollect(nn types.NamespacedName, isDeleting bool) de
 		result.clearApplyStatus()
 	}
 	return deleteSpec{
-		entities: toDelete,
-		cluster:  result.Cluster,
+		entities:     toDelete,
+		cluster:      result.Cluster,
+		resumeGitOps: result.GitOpsHandoff,
 	}
 }
 
@@ -835,7 +1190,12 @@ func (r *Reconciler) ForceDelete(ctx context.Context, nn types.NamespacedName,
 	cluster *v1alpha1.Cluster,
 	reason string) error {
 
-	toDelete := deleteSpec{wait: true, cluster: cluster}
+	r.mu.Lock()
+	result, ok := r.results[nn]
+	resumeGitOps := spec.GitOpsHandoff || (ok && result.GitOpsHandoff)
+	r.mu.Unlock()
+
+	toDelete := deleteSpec{wait: true, cluster: cluster, resumeGitOps: resumeGitOps}
 	if spec.YAML != "" {
 		entities, err := k8s.ParseYAMLFromString(spec.YAML)
 		if err != nil {
@@ -895,6 +1255,13 @@ func (r *Reconciler) bestEffortDelete(ctx context.Context, nn types.NamespacedNa
 	}
 
 	l := logger.Get(ctx)
+	if toDelete.resumeGitOps && len(toDelete.entities) != 0 {
+		toDelete.entities = r.resumeGitOps(ctx, nn, toDelete.entities)
+		if len(toDelete.entities) == 0 && toDelete.deleteCmd == nil {
+			return
+		}
+	}
+
 	l.Infof("Begin %s:", reason)
 
 	if len(toDelete.entities) != 0 {
@@ -905,9 +1272,15 @@ func (r *Reconciler) bestEffortDelete(ctx context.Context, nn types.NamespacedNa
 			l.Infof("→ %s", displayName)
 		}
 
-		err := r.k8sClient.Delete(ctx, toDelete.entities, toDelete.wait)
-		if err != nil {
-			l.Errorf("Error %s: %v", reason, err)
+		// Wait for each delete-weight stage to be gone before deleting the next,
+		// so that (e.g.) an operator outlives the objects with its finalizers.
+		stages := k8s.DeleteWeightStages(toDelete.entities)
+		for i, stage := range stages {
+			wait := toDelete.wait || i < len(stages)-1
+			err := r.k8sClient.Delete(ctx, stage, wait)
+			if err != nil {
+				l.Errorf("Error %s: %v", reason, err)
+			}
 		}
 	}
 
@@ -923,6 +1296,7 @@ func (r *Reconciler) bestEffortDelete(ctx context.Context, nn types.NamespacedNa
 
 var imGVK = v1alpha1.SchemeGroupVersion.WithKind("ImageMap")
 var clusterGVK = v1alpha1.SchemeGroupVersion.WithKind("Cluster")
+var uiButtonGVK = v1alpha1.SchemeGroupVersion.WithKind("UIButton")
 
 // indexKubernetesApply returns keys for all the objects we need to watch based on the spec.
 func indexKubernetesApply(obj client.Object) []indexer.Key {
@@ -941,6 +1315,20 @@ func indexKubernetesApply(obj client.Object) []indexer.Key {
 		})
 	}
 
+	result = append(result, indexer.Key{
+		Name: types.NamespacedName{Name: uibutton.CronJobRunButtonName(ka.Name)},
+		GVK:  uiButtonGVK,
+	}, indexer.Key{
+		Name: types.NamespacedName{Name: uibutton.RollbackButtonName(ka.Name)},
+		GVK:  uiButtonGVK,
+	}, indexer.Key{
+		Name: types.NamespacedName{Name: uibutton.ResetVolumesButtonName(ka.Name)},
+		GVK:  uiButtonGVK,
+	}, indexer.Key{
+		Name: types.NamespacedName{Name: uibutton.ApproveNamespacesButtonName(ka.Name)},
+		GVK:  uiButtonGVK,
+	})
+
 	if ka.Spec.DisableSource != nil {
 		cm := ka.Spec.DisableSource.ConfigMap
 		if cm != nil {
@@ -965,6 +1353,80 @@ type Result struct {
 	AppliedObjects  objectRefSet
 	DanglingObjects objectRefSet
 	Status          v1alpha1.KubernetesApplyStatus
+
+	// The last click of the "Run CronJob Now" button that we've handled.
+	LastCronJobRunClick metav1.MicroTime
+
+	// The last click of the "Roll Back" button that we've handled.
+	LastRollbackClick metav1.MicroTime
+
+	// The last click of the "Reset Volumes" button that we've handled.
+	LastResetVolumesClick metav1.MicroTime
+
+	// The last click of the "Approve Namespaces" button that we've handled.
+	LastApproveNamespacesClick metav1.MicroTime
+
+	// The last change to a RestartOn object field that we've queued a build for.
+	LastFieldChange metav1.MicroTime
+
+	// Set when the user approves the namespaces that the last apply
+	// was waiting on, until we apply again.
+	NamespacesApproved bool
+
+	// Set once we've suspended GitOps reconciliation of the applied objects,
+	// so that we resume it instead of deleting them.
+	GitOpsHandoff bool
+
+	// Jobs created from CronJobs by the "Run CronJob Now" button.
+	CreatedJobs []k8s.K8sEntity
+
+	// The applied Jobs that we've deleted after they completed.
+	CollectedJobs k8s.UIDSet
+}
+
+// Add the YAML of a successful deploy to the history, so that we can roll back.
+func (r *Result) recordAppliedYAML(applyResult applyResult) {
+	if applyResult.Error != "" {
+		return
+	}
+
+	if applyResult.AppliedYAML == "" {
+		// Custom apply commands can't be rolled back.
+		r.Status.History = nil
+		r.Status.CurrentRevision = 0
+		return
+	}
+
+	for _, rev := range r.Status.History {
+		if rev.YAML == applyResult.AppliedYAML {
+			// We've deployed this YAML before (e.g., re-deploying after a rollback),
+			// so point at that revision instead of adding a duplicate.
+			r.Status.CurrentRevision = rev.Revision
+			return
+		}
+	}
+
+	next := int32(1)
+	if len(r.Status.History) > 0 {
+		next = r.Status.History[0].Revision + 1
+	}
+	history := append([]v1alpha1.KubernetesApplyRevision{{
+		Revision:  next,
+		YAML:      applyResult.AppliedYAML,
+		ApplyTime: applyResult.LastApplyTime,
+	}}, r.Status.History...)
+	r.Status.History = trimHistory(history)
+	r.Status.CurrentRevision = next
+}
+
+// The revision that's currently deployed, and its index in the history.
+func (r *Result) currentRevision() (*v1alpha1.KubernetesApplyRevision, int) {
+	for i, rev := range r.Status.History {
+		if rev.Revision == r.Status.CurrentRevision {
+			return &r.Status.History[i], i
+		}
+	}
+	return nil, -1
 }
 
 // Set the status of applied objects to empty,
@@ -993,6 +1455,19 @@ func (r *Result) SetAppliedObjects(set objectRefSet) {
 	r.AppliedObjects = set
 }
 
+// Stop tracking dangling objects that we've already deleted.
+func (r *Result) forgetDanglingObjects(deleted []k8s.K8sEntity) {
+	uids := k8s.NewUIDSet()
+	for _, e := range deleted {
+		uids.Add(e.UID())
+	}
+	for k, v := range r.DanglingObjects {
+		if uids.Contains(v.UID()) {
+			delete(r.DanglingObjects, k)
+		}
+	}
+}
+
 type objectRef struct {
 	Name       string
 	Namespace  string
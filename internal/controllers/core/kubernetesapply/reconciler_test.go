@@ -8,26 +8,37 @@ import (
 	"time"
 
 	"github.com/davecgh/go-spew/spew"
+	dockertypes "github.com/docker/docker/api/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/tilt-dev/tilt/internal/build"
+	"github.com/tilt-dev/tilt/internal/controllers/apis/uibutton"
 	"github.com/tilt-dev/tilt/internal/controllers/fake"
 	"github.com/tilt-dev/tilt/internal/docker"
 	"github.com/tilt-dev/tilt/internal/dockerfile"
 	"github.com/tilt-dev/tilt/internal/k8s"
 	"github.com/tilt-dev/tilt/internal/k8s/testyaml"
 	"github.com/tilt-dev/tilt/internal/localexec"
+	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/internal/testutils/configmap"
 	"github.com/tilt-dev/tilt/internal/timecmp"
+	"github.com/tilt-dev/tilt/internal/yaml"
 	"github.com/tilt-dev/tilt/pkg/apis"
 	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model"
+	"github.com/tilt-dev/wmclient/pkg/dirs"
 )
 
 // Test constants
@@ -112,6 +123,65 @@ func TestBasicApplyYAML(t *testing.T) {
 	assert.Equal(f.T(), f.kClient.Yaml, "")
 }
 
+func TestForceApplyClusterUnreachable(t *testing.T) {
+	f := newFixture(t)
+	nn := types.NamespacedName{Name: "a"}
+	ka := v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "a",
+			Annotations: map[string]string{
+				v1alpha1.AnnotationManagedBy: "buildcontrol",
+			},
+		},
+		Spec: v1alpha1.KubernetesApplySpec{
+			YAML: testyaml.SanchoYAML,
+		},
+	}
+	f.Create(&ka)
+
+	cluster := &v1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Status: v1alpha1.ClusterStatus{
+			Conditions: []metav1.Condition{{
+				Type:    v1alpha1.ClusterConditionReachable,
+				Status:  metav1.ConditionFalse,
+				Reason:  v1alpha1.ClusterReasonUnreachable,
+				Message: "dial tcp 10.0.0.1:6443: i/o timeout",
+			}},
+		},
+	}
+	status := f.r.ForceApply(f.Context(), nn, ka.Spec, cluster, nil, false)
+	assert.Equal(t, `cluster "default" is unreachable: dial tcp 10.0.0.1:6443: i/o timeout`, status.Error)
+	assert.Equal(t, "", f.kClient.Yaml)
+}
+
+func TestApplyYAMLWithCommonLabelsAndAnnotations(t *testing.T) {
+	f := newFixture(t)
+	ka := v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "a",
+		},
+		Spec: v1alpha1.KubernetesApplySpec{
+			YAML:              testyaml.SanchoYAML,
+			CommonLabels:      map[string]string{"team": "payments"},
+			CommonAnnotations: map[string]string{"example.com/ticket": "PAY-123"},
+		},
+	}
+	f.Create(&ka)
+
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+
+	entities, err := k8s.ParseYAMLFromString(f.kClient.Yaml)
+	require.NoError(t, err)
+	require.Len(t, entities, 1)
+	d := entities[0].Obj.(*appsv1.Deployment)
+	assert.Equal(t, "payments", d.Labels["team"])
+	assert.Equal(t, "payments", d.Spec.Template.Labels["team"])
+	assert.Equal(t, "PAY-123", d.Annotations["example.com/ticket"])
+	assert.Equal(t, "PAY-123", d.Spec.Template.Annotations["example.com/ticket"])
+	assert.Equal(t, k8s.ManagedByValue, d.Labels[k8s.ManagedByLabel])
+}
+
 func TestBasicApplyCmd(t *testing.T) {
 	f := newFixture(t)
 
@@ -183,6 +253,88 @@ func TestApplyCmdWithImages(t *testing.T) {
 	}
 }
 
+func TestApplyYAMLWithImageVars(t *testing.T) {
+	f := newFixture(t)
+
+	buildTime := time.Date(2022, 3, 4, 5, 6, 7, 0, time.UTC)
+	f.Create(&v1alpha1.ImageMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "image-a",
+		},
+		Spec: v1alpha1.ImageMapSpec{
+			Selector: "image-a",
+		},
+		Status: v1alpha1.ImageMapStatus{
+			Image:            "localhost:5000/image-a:tilt-123",
+			ImageFromLocal:   "localhost:5000/image-a:tilt-123",
+			ImageFromCluster: "localhost:5000/image-a:tilt-123",
+			BuildStartTime:   &metav1.MicroTime{Time: buildTime},
+		},
+	})
+
+	digest := "sha256:" + strings.Repeat("a", 64)
+	f.dockerClient.Images["localhost:5000/image-a:tilt-123"] = dockertypes.ImageInspect{
+		ID:          "sha256:" + strings.Repeat("b", 64),
+		RepoDigests: []string{"localhost:5000/image-a@" + digest},
+	}
+
+	ka := v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "a",
+		},
+		Spec: v1alpha1.KubernetesApplySpec{
+			YAML: `apiVersion: v1
+kind: Pod
+metadata:
+  name: image-info
+spec:
+  containers:
+  - name: main
+    image: image-a
+    env:
+    - name: DIGEST
+      value: $(TILT_IMAGE_DIGEST:image-a)
+    - name: TAG
+      value: $(TILT_IMAGE_TAG:image-a)
+    - name: BUILD_TIME
+      value: $(TILT_IMAGE_BUILD_TIME:image-a)
+`,
+			ImageMaps: []string{"image-a"},
+		},
+	}
+	f.Create(&ka)
+
+	f.MustGet(types.NamespacedName{Name: "a"}, &ka)
+	assert.Equal(t, "", ka.Status.Error)
+	assert.Contains(t, f.kClient.Yaml, "value: "+digest)
+	assert.Contains(t, f.kClient.Yaml, "value: tilt-123")
+	assert.Contains(t, f.kClient.Yaml, "value: \"2022-03-04T05:06:07Z\"")
+}
+
+func TestApplyYAMLWithUnknownImageVar(t *testing.T) {
+	f := newFixture(t)
+
+	ka := v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "a",
+		},
+		Spec: v1alpha1.KubernetesApplySpec{
+			YAML: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: image-info
+data:
+  digest: $(TILT_IMAGE_DIGEST:image-b)
+`,
+		},
+	}
+	f.Create(&ka)
+
+	f.MustGet(types.NamespacedName{Name: "a"}, &ka)
+	assert.Contains(t, ka.Status.Error, `no image named "image-b" is deployed with this resource`)
+	assert.Equal(t, "", f.kClient.Yaml)
+}
+
 func TestApplyCmdWithKubeconfig(t *testing.T) {
 	f := newFixture(t)
 
@@ -347,8 +499,21 @@ func TestBasicApplyYAML_JobComplete(t *testing.T) {
 		"KubernetesApply status should reflect Job completion")
 }
 
-func TestGarbageCollectAllOnDelete_YAML(t *testing.T) {
+func TestBasicApplyYAML_MutatedByWebhook(t *testing.T) {
 	f := newFixture(t)
+
+	entities, err := k8s.ParseYAMLFromString(testyaml.SanchoYAML)
+	require.NoError(t, err)
+	dep := entities[0].Obj.(*appsv1.Deployment)
+	dep.SetUID(uuid.NewUUID())
+	dep.Annotations = map[string]string{"sidecar.istio.io/status": "injected"}
+	dep.Spec.Template.Spec.Containers = append(dep.Spec.Template.Spec.Containers,
+		v1.Container{Name: "istio-proxy", Image: "istio/proxyv2"})
+	f.kClient.UpsertResult = entities
+	f.kClient.MutatingWebhookNames = map[schema.GroupVersionKind][]string{
+		appsv1.SchemeGroupVersion.WithKind("Deployment"): {"sidecar-injector.istio.io"},
+	}
+
 	ka := v1alpha1.KubernetesApply{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: "a",
@@ -360,210 +525,760 @@ func TestGarbageCollectAllOnDelete_YAML(t *testing.T) {
 	f.Create(&ka)
 
 	f.MustReconcile(types.NamespacedName{Name: "a"})
-	assert.Contains(f.T(), f.kClient.Yaml, "name: sancho")
+	f.MustGet(types.NamespacedName{Name: "a"}, &ka)
 
-	f.Delete(&ka)
+	expected := []metav1.Condition{
+		{
+			Type:   v1alpha1.ApplyConditionMutatedByWebhook,
+			Status: metav1.ConditionTrue,
+			Reason: "WebhookMutation",
+			Message: "sancho:deployment mutated by webhook sidecar-injector.istio.io " +
+				"(added metadata.annotations[sidecar.istio.io/status], containers[istio-proxy])",
+		},
+	}
+	assert.Equal(t, expected, ka.Status.Conditions)
+	assert.Contains(t, f.Stdout(), "Objects mutated by admission webhooks:")
+
+	// Make sure that the mutation isn't treated as a reason to re-apply.
+	f.kClient.LastUpsertResult = nil
 	f.MustReconcile(types.NamespacedName{Name: "a"})
-	assert.Contains(f.T(), f.kClient.DeletedYaml, "name: sancho")
+	assert.Nil(t, f.kClient.LastUpsertResult)
+	f.MustGet(types.NamespacedName{Name: "a"}, &ka)
+	assert.Equal(t, expected, ka.Status.Conditions)
 }
 
-func TestGarbageCollectAllOnDelete_Cmd(t *testing.T) {
+func TestRunCronJobButton(t *testing.T) {
 	f := newFixture(t)
-
-	applyCmd, yamlOut := f.createApplyCmd("custom-apply-cmd", testyaml.SanchoYAML)
 	ka := v1alpha1.KubernetesApply{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: "a",
 		},
 		Spec: v1alpha1.KubernetesApplySpec{
-			ApplyCmd:  &applyCmd,
-			DeleteCmd: &v1alpha1.KubernetesApplyCmd{Args: []string{"custom-delete-cmd"}},
+			YAML: testyaml.CronJobYAML,
 		},
 	}
 	f.Create(&ka)
+	button := uibutton.CronJobRunButton("a")
+	f.Create(button)
 
-	f.MustGet(types.NamespacedName{Name: "a"}, &ka)
-	assert.Equal(f.T(), yamlOut, ka.Status.ResultYAML)
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+	assert.Contains(f.T(), f.kClient.Yaml, "kind: CronJob")
 
-	f.Delete(&ka)
-	assert.False(t, f.Get(types.NamespacedName{Name: "a"}, &ka), "Object was not deleted")
+	// Nothing happens until the button is clicked.
+	f.kClient.Yaml = ""
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+	assert.Equal(f.T(), "", f.kClient.Yaml)
 
-	calls := f.execer.Calls()
-	if assert.Len(t, calls, 2, "Expected 2 calls (1x apply + 1x delete)") {
-		assert.Equal(t, []string{"custom-delete-cmd"}, calls[1].Cmd.Argv)
-	}
+	f.MustGet(types.NamespacedName{Name: button.Name}, button)
+	button.Status.LastClickedAt = apis.NowMicro()
+	f.UpdateStatus(button)
+
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+	assert.Contains(f.T(), f.kClient.Yaml, "kind: Job")
+	assert.Contains(f.T(), f.kClient.Yaml, "name: hello-manual-")
+	assert.Contains(f.T(), f.kClient.Yaml, "cronjob.kubernetes.io/instantiate: manual")
+	assert.Contains(f.T(), f.Stdout(), "Jobs created from CronJobs:")
+
+	// Each click only runs the CronJob once.
+	f.kClient.Yaml = ""
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+	assert.Equal(f.T(), "", f.kClient.Yaml)
 }
 
-func TestGarbageCollectAllOnDisable(t *testing.T) {
+func TestRollbackButton(t *testing.T) {
 	f := newFixture(t)
-
-	applyCmd, yamlOut := f.createApplyCmd("custom-apply-cmd", testyaml.SanchoYAML)
 	ka := v1alpha1.KubernetesApply{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: "a",
 		},
 		Spec: v1alpha1.KubernetesApplySpec{
-			ApplyCmd:  &applyCmd,
-			DeleteCmd: &v1alpha1.KubernetesApplyCmd{Args: []string{"custom-delete-cmd"}},
-			DisableSource: &v1alpha1.DisableSource{
-				ConfigMap: &v1alpha1.ConfigMapDisableSource{
-					Name: "test-disable",
-					Key:  "isDisabled",
-				},
-			},
+			YAML: testyaml.SanchoYAML,
 		},
 	}
 	f.Create(&ka)
+	button := uibutton.RollbackButton("a")
+	f.Create(button)
+
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+	assert.Contains(f.T(), f.kClient.Yaml, "name: sancho")
 
-	f.setDisabled(ka.GetObjectMeta().Name, false)
 	f.MustGet(types.NamespacedName{Name: "a"}, &ka)
-	assert.Equal(f.T(), yamlOut, ka.Status.ResultYAML)
+	ka.Spec.YAML = fmt.Sprintf("%s\n---\n%s\n", testyaml.SanchoYAML, testyaml.PodDisruptionBudgetYAML)
+	f.Update(&ka)
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+	assert.Contains(f.T(), f.kClient.Yaml, "name: infra-kafka-zookeeper")
 
-	f.setDisabled(ka.GetObjectMeta().Name, true)
+	f.MustGet(types.NamespacedName{Name: button.Name}, button)
+	button.Status.LastClickedAt = apis.NowMicro()
+	f.UpdateStatus(button)
 
-	calls := f.execer.Calls()
-	if assert.Len(t, calls, 2, "Expected 2 calls (1x apply + 1x delete)") {
-		assert.Equal(t, []string{"custom-apply-cmd"}, calls[0].Cmd.Argv)
-		assert.Equal(t, []string{"custom-delete-cmd"}, calls[1].Cmd.Argv)
-	}
+	f.kClient.Yaml = ""
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+	assert.Contains(f.T(), f.kClient.Yaml, "name: sancho")
+	assert.NotContains(f.T(), f.kClient.Yaml, "name: infra-kafka-zookeeper")
+	assert.Contains(f.T(), f.kClient.DeletedYaml, "name: infra-kafka-zookeeper")
+	assert.Contains(f.T(), f.Stdout(), "Rolling back to the previous successful deploy")
 
-	f.setDisabled(ka.GetObjectMeta().Name, false)
-	calls = f.execer.Calls()
-	if assert.Len(t, calls, 3, "Expected 3 calls (2x apply + 1x delete)") {
-		assert.Equal(t, []string{"custom-apply-cmd"}, calls[2].Cmd.Argv)
-	}
+	f.MustGet(types.NamespacedName{Name: "a"}, &ka)
+	assert.NotContains(f.T(), ka.Status.ResultYAML, "name: infra-kafka-zookeeper")
 
-	// Confirm the k8s client never deletes resources directly.
-	assert.Equal(t, "", f.kClient.DeletedYaml)
+	// The rollback doesn't trigger a re-deploy of the current spec.
+	f.kClient.Yaml = ""
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+	assert.Equal(f.T(), "", f.kClient.Yaml)
+
+	// There's no deploy before the first one.
+	f.MustGet(types.NamespacedName{Name: button.Name}, button)
+	button.Status.LastClickedAt = apis.NowMicro()
+	f.UpdateStatus(button)
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+	assert.Equal(f.T(), "", f.kClient.Yaml)
+	assert.Contains(f.T(), f.Stdout(), "No previous successful deploy to roll back to.")
 }
 
-func TestGarbageCollectPartial(t *testing.T) {
+const teamBConfigMapYAML = `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: team-b
+data:
+  color: blue
+`
+
+func TestNamespaceApproval(t *testing.T) {
 	f := newFixture(t)
 	ka := v1alpha1.KubernetesApply{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: "a",
+			Annotations: map[string]string{
+				v1alpha1.AnnotationAllowedNamespaces: "",
+			},
 		},
 		Spec: v1alpha1.KubernetesApplySpec{
-			YAML: fmt.Sprintf("%s\n---\n%s\n", testyaml.SanchoYAML, testyaml.PodDisruptionBudgetYAML),
+			YAML: fmt.Sprintf("%s\n---\n%s", testyaml.SanchoYAML, teamBConfigMapYAML),
 		},
 	}
 	f.Create(&ka)
 
 	f.MustReconcile(types.NamespacedName{Name: "a"})
-	assert.Contains(f.T(), f.kClient.Yaml, "name: sancho")
-	assert.Contains(f.T(), f.kClient.Yaml, "name: infra-kafka-zookeeper")
+	assert.Equal(t, "", f.kClient.Yaml)
 
 	f.MustGet(types.NamespacedName{Name: "a"}, &ka)
-	ka.Spec.YAML = testyaml.SanchoYAML
-	f.Update(&ka)
-
+	assert.Contains(t, ka.Status.Error, "waiting for approval to deploy to namespaces that this project hasn't deployed to before: team-b")
+	cond := meta.FindStatusCondition(ka.Status.Conditions, v1alpha1.ApplyConditionNamespaceApprovalRequired)
+	require.NotNil(t, cond)
+	assert.Equal(t, "team-b", cond.Message)
+
+	var button v1alpha1.UIButton
+	f.MustGet(types.NamespacedName{Name: uibutton.ApproveNamespacesButtonName("a")}, &button)
+	assert.Equal(t, "Approve Namespaces (team-b)", button.Spec.Text)
+	assert.True(t, button.Spec.RequiresConfirmation)
+
+	button.Status.LastClickedAt = apis.NowMicro()
+	f.UpdateStatus(&button)
 	f.MustReconcile(types.NamespacedName{Name: "a"})
-	assert.Contains(f.T(), f.kClient.Yaml, "name: sancho")
-	assert.NotContains(f.T(), f.kClient.Yaml, "name: infra-kafka-zookeeper")
-	assert.Contains(f.T(), f.kClient.DeletedYaml, "name: infra-kafka-zookeeper")
+	assert.Contains(t, f.kClient.Yaml, "name: settings")
+	assert.Contains(t, f.Stdout(), "Approved deploying to namespaces: team-b")
+
+	f.MustGet(types.NamespacedName{Name: "a"}, &ka)
+	assert.Equal(t, "", ka.Status.Error)
+	assert.Nil(t, meta.FindStatusCondition(ka.Status.Conditions, v1alpha1.ApplyConditionNamespaceApprovalRequired))
+	assert.False(t, f.Get(types.NamespacedName{Name: button.Name}, &button))
+
+	// The approval is remembered, even after Tilt restarts.
+	approvals, err := f.r.readNamespaceApprovals()
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"": {"team-b"}}, approvals)
 }
 
-func TestGarbageCollectAfterErrorDuringApply(t *testing.T) {
+func TestNamespaceApprovalOnlyApprovesSpecNamespaces(t *testing.T) {
 	f := newFixture(t)
 	ka := v1alpha1.KubernetesApply{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: "a",
+			Annotations: map[string]string{
+				v1alpha1.AnnotationAllowedNamespaces: "",
+			},
 		},
 		Spec: v1alpha1.KubernetesApplySpec{
-			YAML: fmt.Sprintf("%s\n---\n%s\n", testyaml.SanchoYAML, testyaml.PodDisruptionBudgetYAML),
+			YAML: teamBConfigMapYAML,
 		},
 	}
 	f.Create(&ka)
-
 	f.MustReconcile(types.NamespacedName{Name: "a"})
-	assert.Contains(f.T(), f.kClient.Yaml, "name: sancho")
-	assert.Contains(f.T(), f.kClient.Yaml, "name: infra-kafka-zookeeper")
-
-	f.kClient.UpsertError = errors.New("oh no")
 
-	f.MustGet(types.NamespacedName{Name: "a"}, &ka)
-	ka.Spec.YAML = testyaml.SanchoYAML
-	f.Update(&ka)
+	// Whatever else the button says, a click only approves
+	// the namespaces that the resource needs.
+	var button v1alpha1.UIButton
+	f.MustGet(types.NamespacedName{Name: uibutton.ApproveNamespacesButtonName("a")}, &button)
+	button.Annotations = map[string]string{"tilt.dev/approve-namespaces": "kube-system"}
+	button.Spec.Text = "Approve Namespaces (kube-system)"
+	f.Update(&button)
+	button.Status.LastClickedAt = apis.NowMicro()
+	f.UpdateStatus(&button)
 
-	// because the apply (upsert) returned an error, no GC should have happened yet
 	f.MustReconcile(types.NamespacedName{Name: "a"})
-	if assert.Empty(t, f.kClient.DeletedYaml) {
-		assert.Contains(f.T(), f.kClient.Yaml, "name: sancho")
-		assert.Contains(f.T(), f.kClient.Yaml, "name: infra-kafka-zookeeper")
-	}
+	assert.Contains(t, f.kClient.Yaml, "name: settings")
 
-	assert.Contains(f.T(), f.Stdout(), "Tried to apply objects")
+	approvals, err := f.r.readNamespaceApprovals()
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"": {"team-b"}}, approvals)
 }
 
-func TestGarbageCollect_DeleteCmdNotInvokedOnChange(t *testing.T) {
+func TestNamespaceApprovalSeededFromPastDeploys(t *testing.T) {
 	f := newFixture(t)
 
-	applyCmd, yamlOut := f.createApplyCmd("custom-apply-1", testyaml.SanchoYAML)
+	// Tilt deployed this object before namespace approval existed.
+	entities, err := k8s.ParseYAMLFromString(teamBConfigMapYAML)
+	require.NoError(t, err)
+	existing, err := k8s.InjectLabels(entities[0], []model.LabelPair{k8s.TiltManagedByLabel()})
+	require.NoError(t, err)
+	existing.Meta().SetUID("settings-uid")
+	f.kClient.Inject(existing)
+
 	ka := v1alpha1.KubernetesApply{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: "a",
+			Annotations: map[string]string{
+				v1alpha1.AnnotationAllowedNamespaces: "",
+			},
 		},
 		Spec: v1alpha1.KubernetesApplySpec{
-			ApplyCmd:  &applyCmd,
-			DeleteCmd: &v1alpha1.KubernetesApplyCmd{Args: []string{"custom-delete-cmd"}},
+			YAML: teamBConfigMapYAML,
 		},
 	}
 	f.Create(&ka)
 
-	f.MustGet(types.NamespacedName{Name: "a"}, &ka)
-	assert.Equal(f.T(), yamlOut, ka.Status.ResultYAML)
-
-	applyCmd, yamlOut = f.createApplyCmd("custom-apply-2", testyaml.JobYAML)
-	ka.Spec.ApplyCmd = &applyCmd
-	f.Update(&ka)
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+	assert.Contains(t, f.kClient.Yaml, "name: settings")
+	assert.False(t, f.Get(types.NamespacedName{Name: uibutton.ApproveNamespacesButtonName("a")}, &v1alpha1.UIButton{}))
 
-	f.MustGet(types.NamespacedName{Name: "a"}, &ka)
-	assert.Equal(f.T(), yamlOut, ka.Status.ResultYAML)
+	approvals, err := f.r.readNamespaceApprovals()
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"": {"team-b"}}, approvals)
+}
 
-	calls := f.execer.Calls()
-	if assert.Len(t, calls, 2, "Expected 2x calls (both to apply)") {
-		for i := range calls {
-			assert.Equal(t, []string{fmt.Sprintf("custom-apply-%d", i+1)}, calls[i].Cmd.Argv)
-		}
+func TestNamespaceApprovalAllowed(t *testing.T) {
+	f := newFixture(t)
+	ka := v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "a",
+			Annotations: map[string]string{
+				v1alpha1.AnnotationAllowedNamespaces: "team-a,team-b",
+			},
+		},
+		Spec: v1alpha1.KubernetesApplySpec{
+			YAML: teamBConfigMapYAML,
+		},
 	}
+	f.Create(&ka)
 
-	if assert.NoError(t, f.kClient.DeleteError,
-		"Delete should not have been invoked (so no error should have occurred)") {
-		assert.Empty(t, f.kClient.DeletedYaml,
-			"Delete should not have been invoked (so no YAML should have been deleted)")
-	}
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+	assert.Contains(t, f.kClient.Yaml, "name: settings")
+	assert.False(t, f.Get(types.NamespacedName{Name: uibutton.ApproveNamespacesButtonName("a")}, &v1alpha1.UIButton{}))
 }
 
-func TestRestartOn(t *testing.T) {
+func TestNamespaceApprovalNotNeededForCreatedNamespace(t *testing.T) {
 	f := newFixture(t)
+	ka := v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "a",
+			Annotations: map[string]string{
+				v1alpha1.AnnotationAllowedNamespaces: "",
+			},
+		},
+		Spec: v1alpha1.KubernetesApplySpec{
+			YAML: fmt.Sprintf("apiVersion: v1\nkind: Namespace\nmetadata:\n  name: team-b\n---\n%s", teamBConfigMapYAML),
+		},
+	}
+	f.Create(&ka)
 
-	f.Create(&v1alpha1.FileWatch{
-		ObjectMeta: metav1.ObjectMeta{Name: "fw"},
-		Spec:       v1alpha1.FileWatchSpec{WatchedPaths: []string{"/fake/dir"}},
-	})
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+	assert.Contains(t, f.kClient.Yaml, "name: settings")
+}
 
+func TestRollbackHistory(t *testing.T) {
+	f := newFixture(t)
 	ka := v1alpha1.KubernetesApply{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: "a",
 		},
 		Spec: v1alpha1.KubernetesApplySpec{
 			YAML: testyaml.SanchoYAML,
-			RestartOn: &v1alpha1.RestartOnSpec{
-				FileWatches: []string{"fw"},
-			},
 		},
 	}
 	f.Create(&ka)
-
+	button := uibutton.RollbackButton("a")
+	f.Create(button)
 	f.MustReconcile(types.NamespacedName{Name: "a"})
-	assert.Contains(f.T(), f.kClient.Yaml, "name: sancho")
+
+	for _, yaml := range []string{testyaml.PodDisruptionBudgetYAML, testyaml.SecretYaml} {
+		f.MustGet(types.NamespacedName{Name: "a"}, &ka)
+		ka.Spec.YAML = fmt.Sprintf("%s\n---\n%s\n", testyaml.SanchoYAML, yaml)
+		f.Update(&ka)
+		f.MustReconcile(types.NamespacedName{Name: "a"})
+	}
 
 	f.MustGet(types.NamespacedName{Name: "a"}, &ka)
-	assert.Contains(f.T(), ka.Status.ResultYAML, "name: sancho")
-	assert.Contains(f.T(), ka.Status.ResultYAML, "uid:")
-	lastApply := ka.Status.LastApplyTime
+	require.Equal(t, 3, len(ka.Status.History))
+	assert.Equal(t, []int32{3, 2, 1}, historyRevisions(ka.Status.History))
+	assert.Equal(t, int32(3), ka.Status.CurrentRevision)
+	assert.Contains(t, ka.Status.History[0].YAML, "name: mysecret")
+
+	// Each click goes back one more deploy.
+	f.MustGet(types.NamespacedName{Name: button.Name}, button)
+	button.Status.LastClickedAt = apis.NowMicro()
+	f.UpdateStatus(button)
+	f.kClient.Yaml = ""
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+	assert.Contains(t, f.kClient.Yaml, "name: infra-kafka-zookeeper")
+	assert.NotContains(t, f.kClient.Yaml, "name: mysecret")
 
-	// Make sure that re-reconciling w/o changes doesn't re-apply the YAML
+	f.MustGet(types.NamespacedName{Name: button.Name}, button)
+	button.Status.LastClickedAt = apis.NowMicro()
+	f.UpdateStatus(button)
+	f.kClient.Yaml = ""
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+	assert.Contains(t, f.kClient.Yaml, "name: sancho")
+	assert.NotContains(t, f.kClient.Yaml, "name: infra-kafka-zookeeper")
+	assert.Contains(t, f.Stdout(), "Rolling back to the previous successful deploy (revision 1)")
+
+	f.MustGet(types.NamespacedName{Name: "a"}, &ka)
+	assert.Equal(t, int32(1), ka.Status.CurrentRevision)
+	assert.Equal(t, 3, len(ka.Status.History))
+
+	// Re-deploying a YAML from the history moves back to its revision,
+	// instead of adding a duplicate.
+	f.r.ForceApply(f.Context(), types.NamespacedName{Name: "a"}, ka.Spec, nil, nil, true)
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+	f.MustGet(types.NamespacedName{Name: "a"}, &ka)
+	assert.Equal(t, []int32{3, 2, 1}, historyRevisions(ka.Status.History))
+	assert.Equal(t, int32(3), ka.Status.CurrentRevision)
+
+	// A new deploy goes on top of the history.
+	ka.Spec.YAML = testyaml.SecretYaml
+	f.Update(&ka)
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+	f.MustGet(types.NamespacedName{Name: "a"}, &ka)
+	assert.Equal(t, []int32{4, 3, 2, 1}, historyRevisions(ka.Status.History))
+	assert.Equal(t, int32(4), ka.Status.CurrentRevision)
+}
+
+func TestTrimHistory(t *testing.T) {
+	var history []v1alpha1.KubernetesApplyRevision
+	for i := 20; i > 0; i-- {
+		history = append(history, v1alpha1.KubernetesApplyRevision{Revision: int32(i), YAML: "kind: Secret"})
+	}
+	assert.Equal(t, []int32{20, 19, 18, 17, 16, 15, 14, 13, 12, 11}, historyRevisions(trimHistory(history)))
+
+	big := strings.Repeat("x", applyHistoryMaxBytes/2+1)
+	history = []v1alpha1.KubernetesApplyRevision{
+		{Revision: 3, YAML: big},
+		{Revision: 2, YAML: big},
+		{Revision: 1, YAML: "kind: Secret"},
+	}
+	assert.Equal(t, []int32{3}, historyRevisions(trimHistory(history)))
+
+	// The newest revision is kept, even if it's too big.
+	history = []v1alpha1.KubernetesApplyRevision{
+		{Revision: 2, YAML: big + big},
+		{Revision: 1, YAML: "kind: Secret"},
+	}
+	assert.Equal(t, []int32{2}, historyRevisions(trimHistory(history)))
+}
+
+func historyRevisions(history []v1alpha1.KubernetesApplyRevision) []int32 {
+	result := []int32{}
+	for _, rev := range history {
+		result = append(result, rev.Revision)
+	}
+	return result
+}
+
+func TestResetVolumesButton(t *testing.T) {
+	f := newFixture(t)
+	ka := v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "a",
+		},
+		Spec: v1alpha1.KubernetesApplySpec{
+			YAML:         testyaml.DatabaseWithVolumesYAML,
+			ResetVolumes: []string{"pgdata", "uploads"},
+		},
+	}
+	f.Create(&ka)
+	button := uibutton.ResetVolumesButton("a")
+	f.Create(button)
+
+	claim := k8s.NewK8sEntity(&v1.PersistentVolumeClaim{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "PersistentVolumeClaim"},
+		ObjectMeta: metav1.ObjectMeta{Name: "pgdata-db-0", Namespace: "default", UID: "pgdata-db-0-uid"},
+	})
+	f.kClient.Inject(claim)
+
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+	assert.Contains(f.T(), f.kClient.Yaml, "name: db")
+
+	// Nothing happens until the button is clicked.
+	f.kClient.Yaml = ""
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+	assert.Equal(f.T(), "", f.kClient.DeletedYaml)
+
+	f.MustGet(types.NamespacedName{Name: button.Name}, button)
+	button.Status.LastClickedAt = apis.NowMicro()
+	f.UpdateStatus(button)
+
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+	assert.Contains(f.T(), f.kClient.DeletedYaml, "name: uploads")
+	assert.Contains(f.T(), f.kClient.DeletedYaml, "name: pgdata-db-0")
+	assert.NotContains(f.T(), f.kClient.DeletedYaml, "name: db\n")
+	assert.Contains(f.T(), f.kClient.Yaml, "replicas: 2")
+	assert.Contains(f.T(), f.Stdout(), "[1/3] Scaling down workloads")
+	assert.Contains(f.T(), f.Stdout(), "[2/3] Deleting volume claims")
+	assert.Contains(f.T(), f.Stdout(), "[3/3] Re-applying YAML")
+	assert.Contains(f.T(), f.Stdout(), "Volumes reset")
+
+	// Each click only resets the volumes once.
+	f.kClient.DeletedYaml = ""
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+	assert.Equal(f.T(), "", f.kClient.DeletedYaml)
+}
+
+func TestCollectCompletedJobs(t *testing.T) {
+	f := newFixture(t)
+	f.Store.WithState(func(state *store.EngineState) {
+		state.UpdateSettings = model.DefaultUpdateSettings().WithK8sJobRetention(time.Minute)
+	})
+
+	ka := v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "a",
+		},
+		Spec: v1alpha1.KubernetesApplySpec{
+			YAML: testyaml.JobYAML,
+		},
+	}
+	f.Create(&ka)
+	assert.Contains(f.T(), f.kClient.Yaml, "name: pi")
+
+	var kd v1alpha1.KubernetesDiscovery
+	f.MustGet(types.NamespacedName{Name: "a"}, &kd)
+	kd.Status.Pods = []v1alpha1.Pod{
+		{
+			Name:      "pi-abcde",
+			Namespace: "default",
+			Phase:     string(v1.PodSucceeded),
+			Owner:     &v1alpha1.PodOwner{Name: "pi", APIVersion: "batch/v1", Kind: "Job"},
+			Containers: []v1alpha1.Container{
+				{
+					Name: "pi",
+					State: v1alpha1.ContainerState{
+						Terminated: &v1alpha1.ContainerStateTerminated{
+							FinishedAt: metav1.NewTime(time.Now().Add(-2 * time.Minute)),
+						},
+					},
+				},
+			},
+		},
+	}
+	f.UpdateStatus(&kd)
+
+	// The Job isn't deleted until its logs have been captured.
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+	assert.Equal(f.T(), "", f.kClient.DeletedYaml)
+
+	pls := v1alpha1.PodLogStream{
+		ObjectMeta: metav1.ObjectMeta{Name: "a-default-pi-abcde"},
+		Spec:       v1alpha1.PodLogStreamSpec{Pod: "pi-abcde", Namespace: "default"},
+	}
+	require.NoError(t, controllerutil.SetControllerReference(&kd, &pls, v1alpha1.NewScheme()))
+	require.NoError(t, f.Client.Create(f.Context(), &pls))
+	pls.Status.ContainerStatuses = []v1alpha1.ContainerLogStreamStatus{{Name: "pi", Terminated: true}}
+	require.NoError(t, f.Client.Status().Update(f.Context(), &pls))
+
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+	assert.Contains(f.T(), f.kClient.DeletedYaml, "name: pi")
+	assert.Contains(f.T(), f.Stdout(), "cleaning up completed Jobs")
+
+	// The resource stays complete after its pods go away.
+	f.MustGet(types.NamespacedName{Name: "a"}, &ka)
+	assert.True(f.T(), meta.IsStatusConditionTrue(ka.Status.Conditions, v1alpha1.ApplyConditionJobComplete))
+
+	// Each Job is only deleted once.
+	f.kClient.DeletedYaml = ""
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+	assert.Equal(f.T(), "", f.kClient.DeletedYaml)
+}
+
+func TestCollectCompletedJobsWaitsForRetention(t *testing.T) {
+	f := newFixture(t)
+	f.Store.WithState(func(state *store.EngineState) {
+		state.UpdateSettings = model.DefaultUpdateSettings().WithK8sJobRetention(time.Hour)
+	})
+
+	ka := v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "a",
+		},
+		Spec: v1alpha1.KubernetesApplySpec{
+			YAML: testyaml.JobYAML,
+		},
+	}
+	f.Create(&ka)
+
+	var kd v1alpha1.KubernetesDiscovery
+	f.MustGet(types.NamespacedName{Name: "a"}, &kd)
+	kd.Status.Pods = []v1alpha1.Pod{
+		{
+			Name:      "pi-abcde",
+			Namespace: "default",
+			Phase:     string(v1.PodSucceeded),
+			Owner:     &v1alpha1.PodOwner{Name: "pi", APIVersion: "batch/v1", Kind: "Job"},
+			Containers: []v1alpha1.Container{
+				{
+					Name: "pi",
+					State: v1alpha1.ContainerState{
+						Terminated: &v1alpha1.ContainerStateTerminated{FinishedAt: metav1.Now()},
+					},
+				},
+			},
+		},
+	}
+	f.UpdateStatus(&kd)
+
+	pls := v1alpha1.PodLogStream{
+		ObjectMeta: metav1.ObjectMeta{Name: "a-default-pi-abcde"},
+		Spec:       v1alpha1.PodLogStreamSpec{Pod: "pi-abcde", Namespace: "default"},
+	}
+	require.NoError(t, controllerutil.SetControllerReference(&kd, &pls, v1alpha1.NewScheme()))
+	require.NoError(t, f.Client.Create(f.Context(), &pls))
+	pls.Status.ContainerStatuses = []v1alpha1.ContainerLogStreamStatus{{Name: "pi", Terminated: true}}
+	require.NoError(t, f.Client.Status().Update(f.Context(), &pls))
+
+	result := f.MustReconcile(types.NamespacedName{Name: "a"})
+	assert.Equal(f.T(), "", f.kClient.DeletedYaml)
+	assert.Greater(f.T(), result.RequeueAfter, 59*time.Minute)
+	assert.LessOrEqual(f.T(), result.RequeueAfter, time.Hour)
+}
+
+func TestGarbageCollectAllOnDelete_YAML(t *testing.T) {
+	f := newFixture(t)
+	ka := v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "a",
+		},
+		Spec: v1alpha1.KubernetesApplySpec{
+			YAML: testyaml.SanchoYAML,
+		},
+	}
+	f.Create(&ka)
+
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+	assert.Contains(f.T(), f.kClient.Yaml, "name: sancho")
+
+	f.Delete(&ka)
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+	assert.Contains(f.T(), f.kClient.DeletedYaml, "name: sancho")
+}
+
+func TestGarbageCollectAllOnDelete_Cmd(t *testing.T) {
+	f := newFixture(t)
+
+	applyCmd, yamlOut := f.createApplyCmd("custom-apply-cmd", testyaml.SanchoYAML)
+	ka := v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "a",
+		},
+		Spec: v1alpha1.KubernetesApplySpec{
+			ApplyCmd:  &applyCmd,
+			DeleteCmd: &v1alpha1.KubernetesApplyCmd{Args: []string{"custom-delete-cmd"}},
+		},
+	}
+	f.Create(&ka)
+
+	f.MustGet(types.NamespacedName{Name: "a"}, &ka)
+	assert.Equal(f.T(), yamlOut, ka.Status.ResultYAML)
+
+	f.Delete(&ka)
+	assert.False(t, f.Get(types.NamespacedName{Name: "a"}, &ka), "Object was not deleted")
+
+	calls := f.execer.Calls()
+	if assert.Len(t, calls, 2, "Expected 2 calls (1x apply + 1x delete)") {
+		assert.Equal(t, []string{"custom-delete-cmd"}, calls[1].Cmd.Argv)
+	}
+}
+
+func TestGarbageCollectAllOnDisable(t *testing.T) {
+	f := newFixture(t)
+
+	applyCmd, yamlOut := f.createApplyCmd("custom-apply-cmd", testyaml.SanchoYAML)
+	ka := v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "a",
+		},
+		Spec: v1alpha1.KubernetesApplySpec{
+			ApplyCmd:  &applyCmd,
+			DeleteCmd: &v1alpha1.KubernetesApplyCmd{Args: []string{"custom-delete-cmd"}},
+			DisableSource: &v1alpha1.DisableSource{
+				ConfigMap: &v1alpha1.ConfigMapDisableSource{
+					Name: "test-disable",
+					Key:  "isDisabled",
+				},
+			},
+		},
+	}
+	f.Create(&ka)
+
+	f.setDisabled(ka.GetObjectMeta().Name, false)
+	f.MustGet(types.NamespacedName{Name: "a"}, &ka)
+	assert.Equal(f.T(), yamlOut, ka.Status.ResultYAML)
+
+	f.setDisabled(ka.GetObjectMeta().Name, true)
+
+	calls := f.execer.Calls()
+	if assert.Len(t, calls, 2, "Expected 2 calls (1x apply + 1x delete)") {
+		assert.Equal(t, []string{"custom-apply-cmd"}, calls[0].Cmd.Argv)
+		assert.Equal(t, []string{"custom-delete-cmd"}, calls[1].Cmd.Argv)
+	}
+
+	f.setDisabled(ka.GetObjectMeta().Name, false)
+	calls = f.execer.Calls()
+	if assert.Len(t, calls, 3, "Expected 3 calls (2x apply + 1x delete)") {
+		assert.Equal(t, []string{"custom-apply-cmd"}, calls[2].Cmd.Argv)
+	}
+
+	// Confirm the k8s client never deletes resources directly.
+	assert.Equal(t, "", f.kClient.DeletedYaml)
+}
+
+func TestGarbageCollectPartial(t *testing.T) {
+	f := newFixture(t)
+	ka := v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "a",
+		},
+		Spec: v1alpha1.KubernetesApplySpec{
+			YAML: fmt.Sprintf("%s\n---\n%s\n", testyaml.SanchoYAML, testyaml.PodDisruptionBudgetYAML),
+		},
+	}
+	f.Create(&ka)
+
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+	assert.Contains(f.T(), f.kClient.Yaml, "name: sancho")
+	assert.Contains(f.T(), f.kClient.Yaml, "name: infra-kafka-zookeeper")
+
+	f.MustGet(types.NamespacedName{Name: "a"}, &ka)
+	ka.Spec.YAML = testyaml.SanchoYAML
+	f.Update(&ka)
+
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+	assert.Contains(f.T(), f.kClient.Yaml, "name: sancho")
+	assert.NotContains(f.T(), f.kClient.Yaml, "name: infra-kafka-zookeeper")
+	assert.Contains(f.T(), f.kClient.DeletedYaml, "name: infra-kafka-zookeeper")
+}
+
+func TestGarbageCollectAfterErrorDuringApply(t *testing.T) {
+	f := newFixture(t)
+	ka := v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "a",
+		},
+		Spec: v1alpha1.KubernetesApplySpec{
+			YAML: fmt.Sprintf("%s\n---\n%s\n", testyaml.SanchoYAML, testyaml.PodDisruptionBudgetYAML),
+		},
+	}
+	f.Create(&ka)
+
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+	assert.Contains(f.T(), f.kClient.Yaml, "name: sancho")
+	assert.Contains(f.T(), f.kClient.Yaml, "name: infra-kafka-zookeeper")
+
+	f.kClient.UpsertError = errors.New("oh no")
+
+	f.MustGet(types.NamespacedName{Name: "a"}, &ka)
+	ka.Spec.YAML = testyaml.SanchoYAML
+	f.Update(&ka)
+
+	// because the apply (upsert) returned an error, no GC should have happened yet
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+	if assert.Empty(t, f.kClient.DeletedYaml) {
+		assert.Contains(f.T(), f.kClient.Yaml, "name: sancho")
+		assert.Contains(f.T(), f.kClient.Yaml, "name: infra-kafka-zookeeper")
+	}
+
+	assert.Contains(f.T(), f.Stdout(), "Tried to apply objects")
+}
+
+func TestGarbageCollect_DeleteCmdNotInvokedOnChange(t *testing.T) {
+	f := newFixture(t)
+
+	applyCmd, yamlOut := f.createApplyCmd("custom-apply-1", testyaml.SanchoYAML)
+	ka := v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "a",
+		},
+		Spec: v1alpha1.KubernetesApplySpec{
+			ApplyCmd:  &applyCmd,
+			DeleteCmd: &v1alpha1.KubernetesApplyCmd{Args: []string{"custom-delete-cmd"}},
+		},
+	}
+	f.Create(&ka)
+
+	f.MustGet(types.NamespacedName{Name: "a"}, &ka)
+	assert.Equal(f.T(), yamlOut, ka.Status.ResultYAML)
+
+	applyCmd, yamlOut = f.createApplyCmd("custom-apply-2", testyaml.JobYAML)
+	ka.Spec.ApplyCmd = &applyCmd
+	f.Update(&ka)
+
+	f.MustGet(types.NamespacedName{Name: "a"}, &ka)
+	assert.Equal(f.T(), yamlOut, ka.Status.ResultYAML)
+
+	calls := f.execer.Calls()
+	if assert.Len(t, calls, 2, "Expected 2x calls (both to apply)") {
+		for i := range calls {
+			assert.Equal(t, []string{fmt.Sprintf("custom-apply-%d", i+1)}, calls[i].Cmd.Argv)
+		}
+	}
+
+	if assert.NoError(t, f.kClient.DeleteError,
+		"Delete should not have been invoked (so no error should have occurred)") {
+		assert.Empty(t, f.kClient.DeletedYaml,
+			"Delete should not have been invoked (so no YAML should have been deleted)")
+	}
+}
+
+func TestRestartOn(t *testing.T) {
+	f := newFixture(t)
+
+	f.Create(&v1alpha1.FileWatch{
+		ObjectMeta: metav1.ObjectMeta{Name: "fw"},
+		Spec:       v1alpha1.FileWatchSpec{WatchedPaths: []string{"/fake/dir"}},
+	})
+
+	ka := v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "a",
+		},
+		Spec: v1alpha1.KubernetesApplySpec{
+			YAML: testyaml.SanchoYAML,
+			RestartOn: &v1alpha1.RestartOnSpec{
+				FileWatches: []string{"fw"},
+			},
+		},
+	}
+	f.Create(&ka)
+
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+	assert.Contains(f.T(), f.kClient.Yaml, "name: sancho")
+
+	f.MustGet(types.NamespacedName{Name: "a"}, &ka)
+	assert.Contains(f.T(), ka.Status.ResultYAML, "name: sancho")
+	assert.Contains(f.T(), ka.Status.ResultYAML, "uid:")
+	lastApply := ka.Status.LastApplyTime
+
+	// Make sure that re-reconciling w/o changes doesn't re-apply the YAML
 	f.kClient.Yaml = ""
 	f.MustReconcile(types.NamespacedName{Name: "a"})
 	f.MustGet(types.NamespacedName{Name: "a"}, &ka)
@@ -581,83 +1296,428 @@ func TestRestartOn(t *testing.T) {
 	})
 	f.UpdateStatus(&fw)
 
-	f.kClient.Yaml = ""
+	f.kClient.Yaml = ""
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+	f.MustGet(types.NamespacedName{Name: "a"}, &ka)
+	assert.Contains(f.T(), f.kClient.Yaml, "name: sancho")
+	assert.Truef(t, ka.Status.LastApplyTime.After(lastApply.Time),
+		"Last apply time %s should have been after previous apply time %s",
+		ka.Status.LastApplyTime.Format(time.RFC3339Nano),
+		lastApply.Format(time.RFC3339Nano))
+	lastApply = ka.Status.LastApplyTime
+
+	// One last time - make sure that re-reconciling w/o changes doesn't re-apply the YAML
+	f.kClient.Yaml = ""
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+	f.MustGet(types.NamespacedName{Name: "a"}, &ka)
+	assert.Equal(f.T(), f.kClient.Yaml, "")
+	timecmp.AssertTimeEqual(f.T(), lastApply, ka.Status.LastApplyTime)
+}
+
+func TestRestartOnObjectField(t *testing.T) {
+	f := newFixture(t)
+
+	cm := &v1alpha1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "schema"},
+		Data:       map[string]string{"version": "1"},
+	}
+	f.Create(cm)
+
+	nn := types.NamespacedName{Name: "a"}
+	ka := v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{Name: "a"},
+		Spec: v1alpha1.KubernetesApplySpec{
+			YAML: testyaml.SanchoYAML,
+			RestartOn: &v1alpha1.RestartOnSpec{
+				ObjectFields: []v1alpha1.ObjectFieldSource{
+					{Kind: "ConfigMap", Name: "schema", FieldPath: "{.data.version}"},
+				},
+			},
+		},
+	}
+	f.Create(&ka)
+
+	f.MustReconcile(nn)
+	assert.Contains(f.T(), f.kClient.Yaml, "name: sancho")
+
+	// Re-reconciling w/o changes doesn't re-apply the YAML.
+	f.kClient.Yaml = ""
+	f.MustReconcile(nn)
+	assert.Equal(f.T(), "", f.kClient.Yaml)
+
+	cm.Data["version"] = "2"
+	f.Update(cm)
+	f.MustReconcile(nn)
+	assert.Contains(f.T(), f.kClient.Yaml, "name: sancho")
+
+	f.kClient.Yaml = ""
+	f.MustReconcile(nn)
+	assert.Equal(f.T(), "", f.kClient.Yaml)
+}
+
+func TestObjectFieldQueuesManagedObjects(t *testing.T) {
+	f := newFixture(t)
+
+	cm := &v1alpha1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "schema"},
+		Data:       map[string]string{"version": "1"},
+	}
+	f.Create(cm)
+
+	nn := types.NamespacedName{Name: "a"}
+	ka := v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "a",
+			Annotations: map[string]string{
+				v1alpha1.AnnotationManifest:  "a",
+				v1alpha1.AnnotationManagedBy: "buildcontrol",
+			},
+		},
+		Spec: v1alpha1.KubernetesApplySpec{
+			YAML: testyaml.SanchoYAML,
+			RestartOn: &v1alpha1.RestartOnSpec{
+				ObjectFields: []v1alpha1.ObjectFieldSource{
+					{Kind: "ConfigMap", Name: "schema", FieldPath: "{.data.version}"},
+				},
+			},
+		},
+	}
+	f.Create(&ka)
+
+	f.MustReconcile(nn)
+	assert.Empty(f.T(), f.queuedBuilds())
+
+	cm.Data["version"] = "2"
+	f.Update(cm)
+	f.MustReconcile(nn)
+	f.MustReconcile(nn)
+	assert.Equal(f.T(), []store.AppendToTriggerQueueAction{
+		{Name: "a", Reason: model.BuildReasonFlagTriggerExternal},
+	}, f.queuedBuilds())
+
+	// The engine deploys managed objects, not the reconciler.
+	assert.Empty(f.T(), f.kClient.Yaml)
+}
+
+func (f *fixture) queuedBuilds() []store.AppendToTriggerQueueAction {
+	var result []store.AppendToTriggerQueueAction
+	for _, a := range f.Actions() {
+		if a, ok := a.(store.AppendToTriggerQueueAction); ok {
+			result = append(result, a)
+		}
+	}
+	return result
+}
+
+func TestIgnoreManagedObjects(t *testing.T) {
+	f := newFixture(t)
+	ka := v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "a",
+			Annotations: map[string]string{
+				v1alpha1.AnnotationManagedBy: "buildcontrol",
+			},
+		},
+		Spec: v1alpha1.KubernetesApplySpec{
+			YAML: testyaml.SanchoYAML,
+		},
+	}
+	f.Create(&ka)
+
+	nn := types.NamespacedName{Name: "a"}
+	f.MustReconcile(nn)
+	assert.Empty(f.T(), f.kClient.Yaml)
+
+	// no apply should happen since the object is managed by the engine
+	f.MustGet(nn, &ka)
+	assert.Empty(f.T(), ka.Status.ResultYAML)
+	assert.Zero(f.T(), ka.Status.LastApplyTime)
+
+	result := f.r.ForceApply(f.Context(), nn, ka.Spec, nil, nil, false)
+	assert.Contains(f.T(), result.ResultYAML, "sancho")
+	assert.True(f.T(), !result.LastApplyTime.IsZero())
+	assert.True(f.T(), !result.LastApplyStartTime.IsZero())
+	assert.Equal(f.T(), result.Error, "")
+
+	// ForceApply must NOT update the apiserver.
+	f.MustGet(nn, &ka)
+	assert.Empty(f.T(), ka.Status.ResultYAML)
+	assert.Zero(f.T(), ka.Status.LastApplyTime)
+
+	f.MustReconcile(nn)
+	f.MustGet(nn, &ka)
+	assert.Equal(f.T(), result, ka.Status)
+}
+
+func TestForceDelete(t *testing.T) {
+	f := newFixture(t)
+	nn := types.NamespacedName{Name: "a"}
+	ka := v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "a",
+			Annotations: map[string]string{
+				v1alpha1.AnnotationManagedBy: "buildcontrol",
+			},
+		},
+		Spec: v1alpha1.KubernetesApplySpec{
+			YAML: testyaml.SanchoYAML,
+		},
+	}
+	f.Create(&ka)
+
+	err := f.r.ForceDelete(f.Context(), nn, ka.Spec, nil, "testing")
+	assert.Nil(f.T(), err)
+	assert.Contains(f.T(), f.kClient.DeletedYaml, "sancho")
+}
+
+func TestForceApplyCmdSkipsUnchangedInputs(t *testing.T) {
+	f := newFixture(t)
+
+	fw := v1alpha1.FileWatch{
+		ObjectMeta: metav1.ObjectMeta{Name: "a:apply"},
+		Spec: v1alpha1.FileWatchSpec{
+			WatchedPaths: []string{"/infra"},
+		},
+	}
+	f.Create(&fw)
+	fw.Status.MonitorStartTime = apis.NowMicro()
+	f.UpdateStatus(&fw)
+
+	nn := types.NamespacedName{Name: "a"}
+	applyCmd, yamlOut := f.createApplyCmd("custom-apply-cmd", testyaml.SanchoYAML)
+	ka := v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "a",
+			Annotations: map[string]string{
+				v1alpha1.AnnotationManagedBy: "buildcontrol",
+			},
+		},
+		Spec: v1alpha1.KubernetesApplySpec{
+			ApplyCmd:  &applyCmd,
+			DeleteCmd: &v1alpha1.KubernetesApplyCmd{Args: []string{"custom-delete-cmd"}},
+			RestartOn: &v1alpha1.RestartOnSpec{FileWatches: []string{"a:apply"}},
+		},
+	}
+	f.Create(&ka)
+
+	status := f.r.ForceApply(f.Context(), nn, ka.Spec, nil, nil, false)
+	require.Equal(t, "", status.Error)
+	require.Len(t, f.execer.Calls(), 1)
+	assert.Nil(t, meta.FindStatusCondition(status.Conditions, v1alpha1.ApplyConditionInputsUnchanged))
+	firstHash := status.AppliedInputHash
+
+	// Re-applying without any file changes doesn't re-run the command.
+	status = f.r.ForceApply(f.Context(), nn, ka.Spec, nil, nil, false)
+	require.Equal(t, "", status.Error)
+	assert.Len(t, f.execer.Calls(), 1)
+	assert.Equal(t, firstHash, status.AppliedInputHash)
+	assert.Equal(t, yamlOut, status.ResultYAML)
+	cond := meta.FindStatusCondition(status.Conditions, v1alpha1.ApplyConditionInputsUnchanged)
+	if assert.NotNil(t, cond) {
+		assert.Contains(t, cond.Message, firstHash)
+	}
+
+	// A trigger always re-runs the command.
+	status = f.r.ForceApply(f.Context(), nn, ka.Spec, nil, nil, true)
+	require.Equal(t, "", status.Error)
+	assert.Len(t, f.execer.Calls(), 2)
+	assert.Nil(t, meta.FindStatusCondition(status.Conditions, v1alpha1.ApplyConditionInputsUnchanged))
+
+	// So does a change to a dep.
+	fw.Status.LastEventTime = apis.NowMicro()
+	fw.Status.FileEvents = []v1alpha1.FileEvent{{Time: fw.Status.LastEventTime, SeenFiles: []string{"/infra/main.tf"}}}
+	f.UpdateStatus(&fw)
+	status = f.r.ForceApply(f.Context(), nn, ka.Spec, nil, nil, false)
+	require.Equal(t, "", status.Error)
+	assert.Len(t, f.execer.Calls(), 3)
+	assert.NotEqual(t, firstHash, status.AppliedInputHash)
+	assert.Nil(t, meta.FindStatusCondition(status.Conditions, v1alpha1.ApplyConditionInputsUnchanged))
+
+	// A force delete always re-runs the command.
+	require.NoError(t, f.r.ForceDelete(f.Context(), nn, ka.Spec, nil, "testing"))
+	status = f.r.ForceApply(f.Context(), nn, ka.Spec, nil, nil, false)
+	require.Equal(t, "", status.Error)
+	assert.Len(t, f.execer.Calls(), 5)
+}
+
+func TestRestartButtonRunsCmdWithUnchangedInputs(t *testing.T) {
+	f := newFixture(t)
+
+	fw := v1alpha1.FileWatch{
+		ObjectMeta: metav1.ObjectMeta{Name: "a:apply"},
+		Spec: v1alpha1.FileWatchSpec{
+			WatchedPaths: []string{"/infra"},
+		},
+	}
+	f.Create(&fw)
+	fw.Status.MonitorStartTime = apis.NowMicro()
+	f.UpdateStatus(&fw)
+
+	button := v1alpha1.UIButton{
+		ObjectMeta: metav1.ObjectMeta{Name: "a:restart"},
+		Spec: v1alpha1.UIButtonSpec{
+			Text:     "Restart",
+			Location: v1alpha1.UIComponentLocation{ComponentType: "resource", ComponentID: "a"},
+		},
+	}
+	f.Create(&button)
+
+	applyCmd, _ := f.createApplyCmd("custom-apply-cmd", testyaml.SanchoYAML)
+	ka := v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{Name: "a"},
+		Spec: v1alpha1.KubernetesApplySpec{
+			ApplyCmd:  &applyCmd,
+			DeleteCmd: &v1alpha1.KubernetesApplyCmd{Args: []string{"custom-delete-cmd"}},
+			RestartOn: &v1alpha1.RestartOnSpec{
+				FileWatches: []string{"a:apply"},
+				UIButtons:   []string{"a:restart"},
+			},
+		},
+	}
+	f.Create(&ka)
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+	require.Len(t, f.execer.Calls(), 1)
+
+	f.MustGet(types.NamespacedName{Name: button.Name}, &button)
+	button.Status.LastClickedAt = apis.NowMicro()
+	f.UpdateStatus(&button)
+
 	f.MustReconcile(types.NamespacedName{Name: "a"})
+	assert.Len(t, f.execer.Calls(), 2)
 	f.MustGet(types.NamespacedName{Name: "a"}, &ka)
-	assert.Contains(f.T(), f.kClient.Yaml, "name: sancho")
-	assert.Truef(t, ka.Status.LastApplyTime.After(lastApply.Time),
-		"Last apply time %s should have been after previous apply time %s",
-		ka.Status.LastApplyTime.Format(time.RFC3339Nano),
-		lastApply.Format(time.RFC3339Nano))
-	lastApply = ka.Status.LastApplyTime
+	assert.Nil(t, meta.FindStatusCondition(ka.Status.Conditions, v1alpha1.ApplyConditionInputsUnchanged))
 
-	// One last time - make sure that re-reconciling w/o changes doesn't re-apply the YAML
-	f.kClient.Yaml = ""
+	// Each click only runs the command once.
 	f.MustReconcile(types.NamespacedName{Name: "a"})
-	f.MustGet(types.NamespacedName{Name: "a"}, &ka)
-	assert.Equal(f.T(), f.kClient.Yaml, "")
-	timecmp.AssertTimeEqual(f.T(), lastApply, ka.Status.LastApplyTime)
+	assert.Len(t, f.execer.Calls(), 2)
 }
 
-func TestIgnoreManagedObjects(t *testing.T) {
+func TestPruneObjectsRemovedFromYAML(t *testing.T) {
 	f := newFixture(t)
+
+	deployment := func(name string, annotations map[string]string) k8s.K8sEntity {
+		return k8s.NewK8sEntity(&appsv1.Deployment{
+			TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   "default",
+				UID:         types.UID(name + "-uid"),
+				Annotations: annotations,
+			},
+		})
+	}
+
+	// Objects left over from a previous run of Tilt.
+	f.kClient.Inject(
+		deployment("removed", map[string]string{pruneOwnerAnnotation: "project1/a"}),
+		deployment("other-resource", map[string]string{pruneOwnerAnnotation: "project1/b"}),
+		deployment("other-project", map[string]string{pruneOwnerAnnotation: "project2/a"}),
+		deployment("unmanaged", nil))
+
 	ka := v1alpha1.KubernetesApply{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: "a",
-			Annotations: map[string]string{
-				v1alpha1.AnnotationManagedBy: "buildcontrol",
-			},
 		},
 		Spec: v1alpha1.KubernetesApplySpec{
-			YAML: testyaml.SanchoYAML,
+			YAML:       testyaml.SanchoYAML,
+			PruneOwner: "project1/a",
 		},
 	}
 	f.Create(&ka)
 
-	nn := types.NamespacedName{Name: "a"}
-	f.MustReconcile(nn)
-	assert.Empty(f.T(), f.kClient.Yaml)
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+	assert.Contains(t, f.kClient.Yaml, "tilt.dev/prune-owner: project1/a")
+	assert.Contains(t, f.kClient.DeletedYaml, "name: removed")
+	assert.NotContains(t, f.kClient.DeletedYaml, "name: other-resource")
+	assert.NotContains(t, f.kClient.DeletedYaml, "name: other-project")
+	assert.NotContains(t, f.kClient.DeletedYaml, "name: unmanaged")
+	assert.NotContains(t, f.kClient.DeletedYaml, "name: sancho")
 
-	// no apply should happen since the object is managed by the engine
-	f.MustGet(nn, &ka)
-	assert.Empty(f.T(), ka.Status.ResultYAML)
-	assert.Zero(f.T(), ka.Status.LastApplyTime)
+	f.MustGet(types.NamespacedName{Name: "a"}, &ka)
+	cond := meta.FindStatusCondition(ka.Status.Conditions, v1alpha1.ApplyConditionPruned)
+	if assert.NotNil(t, cond) {
+		assert.Equal(t, "removed:deployment", cond.Message)
+	}
+}
 
-	result := f.r.ForceApply(f.Context(), nn, ka.Spec, nil, nil)
-	assert.Contains(f.T(), result.ResultYAML, "sancho")
-	assert.True(f.T(), !result.LastApplyTime.IsZero())
-	assert.True(f.T(), !result.LastApplyStartTime.IsZero())
-	assert.Equal(f.T(), result.Error, "")
+func TestNoPruneWithoutOptIn(t *testing.T) {
+	f := newFixture(t)
 
-	// ForceApply must NOT update the apiserver.
-	f.MustGet(nn, &ka)
-	assert.Empty(f.T(), ka.Status.ResultYAML)
-	assert.Zero(f.T(), ka.Status.LastApplyTime)
+	f.kClient.Inject(k8s.NewK8sEntity(&appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "removed",
+			Namespace:   "default",
+			UID:         "removed-uid",
+			Annotations: map[string]string{pruneOwnerAnnotation: "a"},
+		},
+	}))
 
-	f.MustReconcile(nn)
-	f.MustGet(nn, &ka)
-	assert.Equal(f.T(), result, ka.Status)
+	ka := v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "a",
+		},
+		Spec: v1alpha1.KubernetesApplySpec{
+			YAML: testyaml.SanchoYAML,
+		},
+	}
+	f.Create(&ka)
+
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+	assert.NotContains(t, f.kClient.Yaml, pruneOwnerAnnotation)
+	assert.Equal(t, "", f.kClient.DeletedYaml)
 }
 
-func TestForceDelete(t *testing.T) {
+func TestGitOpsHandoff(t *testing.T) {
 	f := newFixture(t)
+
+	// The Deployment that Flux deployed.
+	fluxLabels := map[string]string{"kustomize.toolkit.fluxcd.io/name": "apps"}
+	f.kClient.Inject(k8s.NewK8sEntity(&appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "sancho",
+			Namespace: "default",
+			UID:       "sancho-uid",
+			Labels:    fluxLabels,
+		},
+	}))
+
 	nn := types.NamespacedName{Name: "a"}
 	ka := v1alpha1.KubernetesApply{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: "a",
-			Annotations: map[string]string{
-				v1alpha1.AnnotationManagedBy: "buildcontrol",
-			},
 		},
 		Spec: v1alpha1.KubernetesApplySpec{
-			YAML: testyaml.SanchoYAML,
+			YAML:          testyaml.SanchoYAML,
+			GitOpsHandoff: true,
 		},
 	}
 	f.Create(&ka)
 
-	err := f.r.ForceDelete(f.Context(), nn, ka.Spec, nil, "testing")
-	assert.Nil(f.T(), err)
-	assert.Contains(f.T(), f.kClient.DeletedYaml, "sancho")
+	f.MustReconcile(nn)
+	live := f.liveSancho()
+	assert.Equal(t, "disabled", live.Annotations()["kustomize.toolkit.fluxcd.io/reconcile"])
+	assert.Equal(t, "a", live.Annotations()[k8s.AnnotationGitOpsSuspendedBy])
+	assert.Contains(t, f.Stdout(), "Suspended GitOps reconciliation of Deployment sancho")
+
+	// On delete, Flux gets the Deployment back.
+	f.Delete(&ka)
+	f.MustReconcile(nn)
+	assert.NotContains(t, f.kClient.DeletedYaml, "name: sancho")
+	assert.Empty(t, f.liveSancho().Annotations())
+	assert.Contains(t, f.Stdout(), "Resumed GitOps reconciliation of Deployment sancho")
+}
+
+func (f *fixture) liveSancho() k8s.K8sEntity {
+	live, err := f.kClient.GetByReference(f.Context(), v1.ObjectReference{
+		APIVersion: "apps/v1",
+		Kind:       "Deployment",
+		Namespace:  "default",
+		Name:       "sancho",
+	})
+	require.NoError(f.T(), err)
+	return live
 }
 
 func TestForceDeleteWithCmd(t *testing.T) {
@@ -713,6 +1773,335 @@ func TestDisableByConfigmap(t *testing.T) {
 	f.setDisabled(ka.GetObjectMeta().Name, false)
 }
 
+func TestWaitForCondition(t *testing.T) {
+	f := newFixture(t)
+	nn := types.NamespacedName{Name: "a"}
+	ka := v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{Name: "a"},
+		Spec: v1alpha1.KubernetesApplySpec{
+			YAML: testyaml.SanchoYAML,
+			WaitFor: []v1alpha1.KubernetesApplyWaitFor{
+				{
+					ObjectSelector: v1alpha1.ObjectSelector{KindRegexp: "Deployment"},
+					Condition:      "Available",
+				},
+			},
+		},
+	}
+	f.Create(&ka)
+
+	result := f.MustReconcile(nn)
+	assert.Equal(t, rolloutCheckInterval, result.RequeueAfter)
+	f.MustGet(nn, &ka)
+	cond := meta.FindStatusCondition(ka.Status.Conditions, v1alpha1.ApplyConditionRolloutComplete)
+	if assert.NotNil(t, cond) {
+		assert.Equal(t, metav1.ConditionFalse, cond.Status)
+		assert.Equal(t, v1alpha1.ApplyReasonRolloutWaiting, cond.Reason)
+		assert.Contains(t, cond.Message, "deployment/sancho: condition=Available")
+	}
+
+	f.injectDeploymentStatus(appsv1.DeploymentStatus{
+		Conditions: []appsv1.DeploymentCondition{
+			{Type: appsv1.DeploymentAvailable, Status: v1.ConditionTrue},
+		},
+	})
+
+	result = f.MustReconcile(nn)
+	assert.Equal(t, time.Duration(0), result.RequeueAfter)
+	f.MustGet(nn, &ka)
+	assert.True(t, meta.IsStatusConditionTrue(ka.Status.Conditions, v1alpha1.ApplyConditionRolloutComplete))
+}
+
+func TestWaitForJSONPath(t *testing.T) {
+	f := newFixture(t)
+	nn := types.NamespacedName{Name: "a"}
+	ka := v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{Name: "a"},
+		Spec: v1alpha1.KubernetesApplySpec{
+			YAML: testyaml.SanchoYAML,
+			WaitFor: []v1alpha1.KubernetesApplyWaitFor{
+				{
+					ObjectSelector: v1alpha1.ObjectSelector{KindRegexp: "Deployment"},
+					JSONPath:       "{.status.readyReplicas}",
+					Value:          "1",
+				},
+			},
+		},
+	}
+	f.Create(&ka)
+
+	f.injectDeploymentStatus(appsv1.DeploymentStatus{ReadyReplicas: 0})
+	f.MustReconcile(nn)
+	f.MustGet(nn, &ka)
+	assert.False(t, meta.IsStatusConditionTrue(ka.Status.Conditions, v1alpha1.ApplyConditionRolloutComplete))
+
+	f.injectDeploymentStatus(appsv1.DeploymentStatus{ReadyReplicas: 1})
+	f.MustReconcile(nn)
+	f.MustGet(nn, &ka)
+	assert.True(t, meta.IsStatusConditionTrue(ka.Status.Conditions, v1alpha1.ApplyConditionRolloutComplete))
+}
+
+func TestWaitForTimeout(t *testing.T) {
+	f := newFixture(t)
+	nn := types.NamespacedName{Name: "a"}
+	ka := v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{Name: "a"},
+		Spec: v1alpha1.KubernetesApplySpec{
+			YAML: testyaml.SanchoYAML,
+			WaitFor: []v1alpha1.KubernetesApplyWaitFor{
+				{
+					ObjectSelector: v1alpha1.ObjectSelector{KindRegexp: "Deployment"},
+					Condition:      "Available",
+					Timeout:        metav1.Duration{Duration: time.Nanosecond},
+				},
+			},
+		},
+	}
+	f.Create(&ka)
+
+	result := f.MustReconcile(nn)
+	assert.Equal(t, time.Duration(0), result.RequeueAfter)
+	f.MustGet(nn, &ka)
+	cond := meta.FindStatusCondition(ka.Status.Conditions, v1alpha1.ApplyConditionRolloutComplete)
+	if assert.NotNil(t, cond) {
+		assert.Equal(t, metav1.ConditionFalse, cond.Status)
+		assert.Equal(t, v1alpha1.ApplyReasonRolloutTimeout, cond.Reason)
+		assert.Contains(t, cond.Message, "timed out after 1ns waiting for deployment/sancho")
+	}
+}
+
+func TestDiffPreview(t *testing.T) {
+	f := newFixture(t)
+	nn := types.NamespacedName{Name: "a"}
+
+	entities, err := k8s.ParseYAMLFromString(testyaml.SanchoYAML)
+	require.NoError(t, err)
+	live := entities[0].DeepCopy()
+	live.SetUID("sancho-uid")
+	live.Obj.(*appsv1.Deployment).Spec.Replicas = pointer.Int32(2)
+	f.kClient.Inject(live)
+
+	ka := v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "a",
+			Annotations: map[string]string{
+				v1alpha1.AnnotationManagedBy: "buildcontrol",
+			},
+		},
+		Spec: v1alpha1.KubernetesApplySpec{
+			YAML:        testyaml.SanchoYAML + "\n---\n" + testyaml.SecretYaml,
+			DiffPreview: true,
+		},
+	}
+	f.Create(&ka)
+
+	f.MustGet(nn, &ka)
+	require.NotNil(t, ka.Status.Diff)
+	assert.Equal(t, "", ka.Status.Diff.Error)
+	require.Len(t, ka.Status.Diff.Objects, 2)
+
+	deployment := ka.Status.Diff.Objects[0]
+	assert.Equal(t, "Deployment", deployment.Kind)
+	assert.Equal(t, "sancho", deployment.Name)
+	assert.Equal(t, v1alpha1.KubernetesApplyDiffActionUpdate, deployment.Action)
+	assert.Contains(t, deployment.Diff, "--- live/Deployment/sancho")
+	assert.Contains(t, deployment.Diff, "-  replicas: 2\n+  replicas: 1\n")
+
+	secret := ka.Status.Diff.Objects[1]
+	assert.Equal(t, "Secret", secret.Kind)
+	assert.Equal(t, v1alpha1.KubernetesApplyDiffActionCreate, secret.Action)
+	assert.Contains(t, secret.Diff, "+  name: mysecret\n")
+	assert.Contains(t, secret.Diff, "+  password: '***'\n")
+	assert.NotContains(t, secret.Diff, "MWYyZDFlMmU2N2Rm")
+
+	// Nothing was applied yet.
+	assert.Empty(t, f.kClient.Yaml)
+
+	_ = f.r.ForceApply(f.Context(), nn, ka.Spec, nil, nil, false)
+	f.MustReconcile(nn)
+	f.MustGet(nn, &ka)
+	assert.Nil(t, ka.Status.Diff)
+}
+
+func TestDiffPreviewMasksSecrets(t *testing.T) {
+	f := newFixture(t)
+	nn := types.NamespacedName{Name: "a"}
+
+	entities, err := k8s.ParseYAMLFromString(testyaml.SecretYaml)
+	require.NoError(t, err)
+	live := entities[0].DeepCopy()
+	live.SetUID("secret-uid")
+	live.Obj.(*v1.Secret).Data = map[string][]byte{
+		"username": []byte("admin"),
+		"password": []byte("old-password"),
+		"token":    []byte("old-token"),
+	}
+	f.kClient.Inject(live)
+
+	ka := v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "a",
+			Annotations: map[string]string{
+				v1alpha1.AnnotationManagedBy: "buildcontrol",
+			},
+		},
+		Spec: v1alpha1.KubernetesApplySpec{YAML: testyaml.SecretYaml, DiffPreview: true},
+	}
+	f.Create(&ka)
+
+	f.MustGet(nn, &ka)
+	require.NotNil(t, ka.Status.Diff)
+	require.Len(t, ka.Status.Diff.Objects, 1)
+
+	diff := ka.Status.Diff.Objects[0].Diff
+	assert.Contains(t, diff, "-  password: '*** (before)'\n")
+	assert.Contains(t, diff, "+  password: '*** (after)'\n")
+	assert.Contains(t, diff, "-  token: '***'\n")
+	assert.Contains(t, diff, "   username: '***'\n")
+	for _, value := range []string{"YWRtaW4=", "MWYyZDFlMmU2N2Rm", "b2xkLXBhc3N3b3Jk", "b2xkLXRva2Vu"} {
+		assert.NotContains(t, diff, value)
+	}
+}
+
+func TestDiffPreviewRequiresAnnotation(t *testing.T) {
+	f := newFixture(t)
+	nn := types.NamespacedName{Name: "a"}
+	ka := v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "a",
+			Annotations: map[string]string{
+				v1alpha1.AnnotationManagedBy: "buildcontrol",
+			},
+		},
+		Spec: v1alpha1.KubernetesApplySpec{
+			YAML: testyaml.SanchoYAML,
+		},
+	}
+	f.Create(&ka)
+
+	f.MustGet(nn, &ka)
+	assert.Nil(t, ka.Status.Diff)
+}
+
+func TestApplyInKindOrder(t *testing.T) {
+	f := newFixture(t)
+	nn := types.NamespacedName{Name: "a"}
+	ka := v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{Name: "a"},
+		Spec: v1alpha1.KubernetesApplySpec{
+			YAML: yaml.ConcatYAML(testyaml.SanchoYAML, testyaml.MyNamespaceYAML),
+		},
+	}
+	f.Create(&ka)
+
+	f.MustGet(nn, &ka)
+	require.Empty(t, ka.Status.Error)
+	assert.Less(t,
+		strings.Index(ka.Status.ResultYAML, "kind: Namespace"),
+		strings.Index(ka.Status.ResultYAML, "kind: Deployment"))
+	assert.NotContains(t, f.Stdout(), "Applying stage")
+}
+
+func TestApplyOrder(t *testing.T) {
+	f := newFixture(t)
+	nn := types.NamespacedName{Name: "a"}
+	ka := v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{Name: "a"},
+		Spec: v1alpha1.KubernetesApplySpec{
+			YAML: yaml.ConcatYAML(testyaml.MyNamespaceYAML, testyaml.JobYAML, testyaml.SanchoYAML),
+			ApplyOrder: []v1alpha1.ObjectSelector{
+				{KindRegexp: "^Deployment$"},
+				{KindRegexp: "^Job$"},
+			},
+		},
+	}
+	f.Create(&ka)
+
+	f.MustGet(nn, &ka)
+	require.Empty(t, ka.Status.Error)
+	deployment := strings.Index(ka.Status.ResultYAML, "kind: Deployment")
+	job := strings.Index(ka.Status.ResultYAML, "kind: Job")
+	namespace := strings.Index(ka.Status.ResultYAML, "kind: Namespace")
+	assert.Less(t, deployment, job)
+	assert.Less(t, job, namespace)
+
+	out := f.Stdout()
+	assert.Contains(t, out, "Applying stage 1/3 (1 objects)")
+	assert.Contains(t, out, "Applying stage 3/3 (1 objects)")
+
+	// Only the last stage is left in the fake client.
+	assert.Contains(t, f.kClient.Yaml, "kind: Namespace")
+	assert.NotContains(t, f.kClient.Yaml, "kind: Deployment")
+}
+
+func TestApplyWeight(t *testing.T) {
+	f := newFixture(t)
+	nn := types.NamespacedName{Name: "a"}
+	config := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+  annotations:
+    tilt.dev/apply-weight: "-1"
+`
+	ka := v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{Name: "a"},
+		Spec: v1alpha1.KubernetesApplySpec{
+			YAML: yaml.ConcatYAML(testyaml.SanchoYAML, config),
+		},
+	}
+	f.Create(&ka)
+
+	f.MustGet(nn, &ka)
+	require.Empty(t, ka.Status.Error)
+
+	out := f.Stdout()
+	assert.Contains(t, out, "Applying stage 1/2 (1 objects)")
+	assert.Contains(t, out, "Applying stage 2/2 (1 objects)")
+
+	// The ConfigMap is applied first, so only the Deployment is left in the fake client.
+	assert.Contains(t, f.kClient.Yaml, "kind: Deployment")
+	assert.NotContains(t, f.kClient.Yaml, "kind: ConfigMap")
+}
+
+func TestApplyWeightInvalid(t *testing.T) {
+	f := newFixture(t)
+	nn := types.NamespacedName{Name: "a"}
+	ka := v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{Name: "a"},
+		Spec: v1alpha1.KubernetesApplySpec{
+			YAML: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+  annotations:
+    tilt.dev/apply-weight: first
+`,
+		},
+	}
+	f.Create(&ka)
+
+	f.MustGet(nn, &ka)
+	assert.Contains(t, ka.Status.Error, "annotation tilt.dev/apply-weight must be an integer")
+}
+
+func TestApplyOrderInvalidSelector(t *testing.T) {
+	f := newFixture(t)
+	nn := types.NamespacedName{Name: "a"}
+	ka := v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{Name: "a"},
+		Spec: v1alpha1.KubernetesApplySpec{
+			YAML:       testyaml.SanchoYAML,
+			ApplyOrder: []v1alpha1.ObjectSelector{{KindRegexp: "("}},
+		},
+	}
+	f.Create(&ka)
+
+	f.MustGet(nn, &ka)
+	assert.Contains(t, ka.Status.Error, "applyOrder[0]: error parsing kind regexp")
+	assert.Equal(t, "", f.kClient.Yaml)
+}
+
 func (f *fixture) requireKaMatchesInApi(name string, matcher func(ka *v1alpha1.KubernetesApply) bool) *v1alpha1.KubernetesApply {
 	ka := v1alpha1.KubernetesApply{}
 
@@ -769,9 +2158,10 @@ func (f *fixture) setDisabled(name string, isDisabled bool) {
 
 type fixture struct {
 	*fake.ControllerFixture
-	r       *Reconciler
-	kClient *k8s.FakeK8sClient
-	execer  *localexec.FakeExecer
+	r            *Reconciler
+	kClient      *k8s.FakeK8sClient
+	dockerClient *docker.FakeClient
+	execer       *localexec.FakeExecer
 }
 
 func newFixture(t *testing.T) *fixture {
@@ -785,13 +2175,14 @@ func newFixture(t *testing.T) *fixture {
 
 	execer := localexec.NewFakeExecer(t)
 
-	db := build.NewDockerBuilder(dockerClient, dockerfile.Labels{})
-	r := NewReconciler(cfb.Client, kClient, v1alpha1.NewScheme(), db, cfb.Store, execer)
+	db := build.NewDockerBuilder(dockerClient, dockerfile.Labels{}, nil)
+	r := NewReconciler(cfb.Client, kClient, v1alpha1.NewScheme(), db, dockerClient, cfb.Store, execer, dirs.NewTiltDevDirAt(t.TempDir()))
 
 	f := &fixture{
 		ControllerFixture: cfb.Build(r),
 		r:                 r,
 		kClient:           kClient,
+		dockerClient:      dockerClient,
 		execer:            execer,
 	}
 	f.Create(&v1alpha1.Cluster{
@@ -810,6 +2201,15 @@ func newFixture(t *testing.T) *fixture {
 	return f
 }
 
+// Simulates the cluster updating the status of the applied Deployment.
+func (f *fixture) injectDeploymentStatus(status appsv1.DeploymentStatus) {
+	f.T().Helper()
+	require.Len(f.T(), f.kClient.LastUpsertResult, 1)
+	e := f.kClient.LastUpsertResult[0].DeepCopy()
+	e.Obj.(*appsv1.Deployment).Status = status
+	f.kClient.Inject(e)
+}
+
 // createApplyCmd creates a KubernetesApplyCmd that use the passed YAML to generate simulated stdout via the FakeExecer.
 func (f *fixture) createApplyCmd(name string, yaml string) (v1alpha1.KubernetesApplyCmd, string) {
 	f.T().Helper()
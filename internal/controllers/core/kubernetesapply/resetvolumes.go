@@ -0,0 +1,163 @@
+package kubernetesapply
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/tilt-dev/tilt/internal/controllers/apis/uibutton"
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/timecmp"
+	"github.com/tilt-dev/tilt/pkg/apis"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+// If the user clicked the resource's "Reset Volumes" button,
+// reset its volumes to a clean slate:
+//
+//  1. Scale down the workloads, so that nothing is using the volumes.
+//  2. Delete the volume claims that the spec allows us to delete.
+//  3. Re-apply the YAML from the last successful deploy, which scales the
+//     workloads back up and recreates the claims.
+func (r *Reconciler) maybeResetVolumes(ctx context.Context, nn types.NamespacedName, spec v1alpha1.KubernetesApplySpec) error {
+	var button v1alpha1.UIButton
+	err := r.ctrlClient.Get(ctx, types.NamespacedName{Name: uibutton.ResetVolumesButtonName(nn.Name)}, &button)
+	if err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	lastClick := button.Status.LastClickedAt
+	r.mu.Lock()
+	result := r.ensureResultExists(nn)
+
+	// Ignore clicks from before the current deploy,
+	// so that a stale click never deletes data.
+	isNewClick := timecmp.After(lastClick, result.LastResetVolumesClick) &&
+		timecmp.After(lastClick, result.Status.LastApplyStartTime)
+	if isNewClick {
+		result.LastResetVolumesClick = lastClick
+	}
+	appliedYAML := ""
+	if current, _ := result.currentRevision(); current != nil {
+		appliedYAML = current.YAML
+	}
+	resultYAML := result.Status.ResultYAML
+	r.mu.Unlock()
+
+	if !isNewClick {
+		return nil
+	}
+
+	l := logger.Get(ctx)
+	if appliedYAML == "" || resultYAML == "" {
+		l.Warnf("Resource hasn't been deployed yet. No volumes to reset.")
+		return nil
+	}
+
+	applied, err := k8s.ParseYAMLFromString(appliedYAML)
+	if err != nil {
+		return fmt.Errorf("reading applied YAML: %v", err)
+	}
+
+	// The deployed objects have their namespaces filled in,
+	// so we use them to find the claims.
+	deployed, err := k8s.ParseYAMLFromString(resultYAML)
+	if err != nil {
+		return fmt.Errorf("reading deployed objects: %v", err)
+	}
+
+	existing, err := r.listVolumeClaims(ctx, deployed)
+	if err != nil {
+		return fmt.Errorf("listing volume claims: %v", err)
+	}
+
+	claims, unmatched := k8s.VolumeClaimsToReset(deployed, spec.ResetVolumes, existing)
+	if len(unmatched) > 0 {
+		l.Warnf("No volume claims found for reset_volumes: %s", strings.Join(unmatched, ", "))
+	}
+	if len(claims) == 0 {
+		l.Warnf("No volume claims to reset.")
+		return nil
+	}
+
+	timeout := spec.Timeout.Duration
+	if timeout == 0 {
+		timeout = v1alpha1.KubernetesApplyTimeoutDefault
+	}
+
+	l.Infof("Resetting volumes")
+	startTime := apis.NowMicro()
+
+	var scaled []k8s.K8sEntity
+	for _, e := range applied {
+		s, ok := k8s.ScaleToZero(e)
+		if ok {
+			scaled = append(scaled, s)
+		}
+	}
+	if len(scaled) > 0 {
+		l.Infof("[1/3] Scaling down workloads")
+		_, err = r.k8sClient.Upsert(ctx, scaled, timeout)
+		if err != nil {
+			return fmt.Errorf("scaling down workloads: %v", err)
+		}
+	} else {
+		l.Infof("[1/3] No workloads to scale down")
+	}
+
+	l.Infof("[2/3] Deleting volume claims")
+	deleteErr := r.k8sClient.Delete(ctx, claims, true)
+
+	// Even if the delete failed, scale the workloads back up,
+	// so that we don't leave the resource down.
+	l.Infof("[3/3] Re-applying YAML")
+	reapplied, err := r.k8sClient.Upsert(ctx, applied, timeout)
+	if err != nil {
+		return fmt.Errorf("scaling workloads back up: %v", err)
+	}
+	r.printAppliedReport(ctx, "Objects applied to cluster:", reapplied)
+
+	for _, d := range reapplied {
+		d.Clean()
+	}
+	newResultYAML, err := k8s.SerializeSpecYAML(reapplied)
+	if err != nil {
+		return err
+	}
+	r.recordReapply(nn, applyResult{
+		ResultYAML:         newResultYAML,
+		LastApplyStartTime: startTime,
+		LastApplyTime:      apis.NowMicro(),
+		Objects:            reapplied,
+	})
+
+	if deleteErr != nil {
+		return fmt.Errorf("deleting volume claims: %v", deleteErr)
+	}
+	l.Infof("Volumes reset")
+	return nil
+}
+
+// Lists the existing volume claims in the namespaces of the deployed objects.
+func (r *Reconciler) listVolumeClaims(ctx context.Context, deployed []k8s.K8sEntity) ([]metav1.Object, error) {
+	namespaces := sets.NewString()
+	for _, e := range deployed {
+		namespaces.Insert(e.Namespace().String())
+	}
+
+	var result []metav1.Object
+	for _, ns := range namespaces.List() {
+		claims, err := r.k8sClient.ListMeta(ctx, k8s.PersistentVolumeClaimGVK, k8s.Namespace(ns))
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, claims...)
+	}
+	return result, nil
+}
@@ -0,0 +1,149 @@
+package kubernetesapply
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/tilt-dev/tilt/internal/controllers/apis/uibutton"
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/timecmp"
+	"github.com/tilt-dev/tilt/pkg/apis"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+// How many successful deploys to keep in the history.
+const applyHistoryLimit = 10
+
+// The most YAML to keep in the history, across all revisions.
+//
+// The history lives in the KubernetesApply status, so we don't want
+// big manifests to bloat every status update.
+const applyHistoryMaxBytes = 1 << 20
+
+// Drop the oldest revisions until the history fits in its limits.
+//
+// Always keeps the newest revision, even if it's bigger than the limit,
+// so that the next deploy can roll back to it.
+func trimHistory(history []v1alpha1.KubernetesApplyRevision) []v1alpha1.KubernetesApplyRevision {
+	if len(history) > applyHistoryLimit {
+		history = history[:applyHistoryLimit]
+	}
+
+	size := 0
+	for i, rev := range history {
+		size += len(rev.YAML)
+		if i > 0 && size > applyHistoryMaxBytes {
+			return history[:i]
+		}
+	}
+	return history
+}
+
+// If the user clicked the resource's "Roll Back" button,
+// re-apply the YAML from the successful deploy before the current one.
+//
+// Clicking "Roll Back" again goes back one more deploy, until we run out
+// of history. The objects that the bad deploy added are garbage collected,
+// like any other removed object.
+//
+// The spec itself doesn't change, so the next change to the resource's
+// inputs deploys the current YAML again.
+func (r *Reconciler) maybeRollback(ctx context.Context, nn types.NamespacedName, spec v1alpha1.KubernetesApplySpec) error {
+	var button v1alpha1.UIButton
+	err := r.ctrlClient.Get(ctx, types.NamespacedName{Name: uibutton.RollbackButtonName(nn.Name)}, &button)
+	if err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	lastClick := button.Status.LastClickedAt
+	r.mu.Lock()
+	result := r.ensureResultExists(nn)
+
+	// Ignore clicks from before the current deploy,
+	// so that a stale click never reverts a newer deploy.
+	isNewClick := timecmp.After(lastClick, result.LastRollbackClick) &&
+		timecmp.After(lastClick, result.Status.LastApplyStartTime)
+	if isNewClick {
+		result.LastRollbackClick = lastClick
+	}
+	var target *v1alpha1.KubernetesApplyRevision
+	_, i := result.currentRevision()
+	if i >= 0 && i+1 < len(result.Status.History) {
+		target = result.Status.History[i+1].DeepCopy()
+	}
+	r.mu.Unlock()
+
+	if !isNewClick {
+		return nil
+	}
+
+	l := logger.Get(ctx)
+	if target == nil {
+		l.Warnf("No previous successful deploy to roll back to.")
+		return nil
+	}
+
+	entities, err := k8s.ParseYAMLFromString(target.YAML)
+	if err != nil {
+		return fmt.Errorf("reading previous deploy: %v", err)
+	}
+
+	timeout := spec.Timeout.Duration
+	if timeout == 0 {
+		timeout = v1alpha1.KubernetesApplyTimeoutDefault
+	}
+
+	l.Infof("Rolling back to the previous successful deploy (revision %d)", target.Revision)
+	startTime := apis.NowMicro()
+	deployed, err := r.k8sClient.Upsert(ctx, entities, timeout)
+	if err != nil {
+		return err
+	}
+	r.printAppliedReport(ctx, "Objects rolled back:", deployed)
+
+	for _, d := range deployed {
+		d.Clean()
+	}
+	resultYAML, err := k8s.SerializeSpecYAML(deployed)
+	if err != nil {
+		return err
+	}
+
+	r.recordReapply(nn, applyResult{
+		ResultYAML:         resultYAML,
+		LastApplyStartTime: startTime,
+		LastApplyTime:      apis.NowMicro(),
+		Objects:            deployed,
+	})
+
+	r.mu.Lock()
+	result = r.ensureResultExists(nn)
+	result.Status.CurrentRevision = target.Revision
+	r.mu.Unlock()
+	return nil
+}
+
+// Record the results of re-applying YAML that we've applied before
+// (like a rollback or a volume reset) to the local Result map.
+//
+// Leaves the AppliedInputHash alone, so that we don't immediately
+// re-deploy the current spec.
+func (r *Reconciler) recordReapply(nn types.NamespacedName, applyResult applyResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := r.ensureResultExists(nn)
+	updatedStatus := result.Status.DeepCopy()
+	updatedStatus.ResultYAML = applyResult.ResultYAML
+	updatedStatus.Error = ""
+	updatedStatus.LastApplyStartTime = applyResult.LastApplyStartTime
+	updatedStatus.LastApplyTime = applyResult.LastApplyTime
+	updatedStatus.Conditions = conditionsFromApply(applyResult)
+	result.Status = *updatedStatus
+
+	result.SetAppliedObjects(newObjectRefSet(applyResult.Objects))
+}
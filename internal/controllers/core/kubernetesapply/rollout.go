@@ -0,0 +1,201 @@
+package kubernetesapply
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/jsonpath"
+
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+// How often to re-check a rollout that's still in progress.
+const rolloutCheckInterval = time.Second
+
+// The condition that marks a fresh apply as waiting on its rollout checks.
+//
+// The transition time is the apply time, so that the timeouts are
+// measured from the apply.
+func rolloutWaitingCondition(applyTime metav1.MicroTime) metav1.Condition {
+	return metav1.Condition{
+		Type:               v1alpha1.ApplyConditionRolloutComplete,
+		Status:             metav1.ConditionFalse,
+		Reason:             v1alpha1.ApplyReasonRolloutWaiting,
+		Message:            "Waiting for rollout",
+		LastTransitionTime: metav1.NewTime(applyTime.Time),
+	}
+}
+
+// If the spec has WaitFor checks, fetch the applied objects and
+// check if they've rolled out.
+//
+// Records the outcome in the RolloutComplete condition.
+//
+// Returns how long to wait before checking again,
+// or zero if there's nothing to wait for.
+func (r *Reconciler) maybeCheckRollout(ctx context.Context, nn types.NamespacedName) (time.Duration, error) {
+	r.mu.Lock()
+	result, ok := r.results[nn]
+	if !ok || len(result.Spec.WaitFor) == 0 ||
+		result.Status.Error != "" || result.Status.LastApplyTime.IsZero() {
+		r.mu.Unlock()
+		return 0, nil
+	}
+
+	cond := meta.FindStatusCondition(result.Status.Conditions, v1alpha1.ApplyConditionRolloutComplete)
+	if cond != nil && cond.Reason != v1alpha1.ApplyReasonRolloutWaiting {
+		// We already know how this apply turned out.
+		r.mu.Unlock()
+		return 0, nil
+	}
+
+	waitFor := result.Spec.WaitFor
+	applyTime := result.Status.LastApplyTime
+	objects := make([]k8s.K8sEntity, 0, len(result.AppliedObjects))
+	for _, e := range result.AppliedObjects {
+		objects = append(objects, e)
+	}
+	r.mu.Unlock()
+
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].Name() < objects[j].Name()
+	})
+
+	var pending []string
+	var timedOut []string
+	elapsed := time.Since(applyTime.Time)
+	for _, w := range waitFor {
+		selector, err := k8s.ParseObjectSelector(w.ObjectSelector)
+		if err != nil {
+			return 0, err
+		}
+
+		timeout := w.Timeout.Duration
+		if timeout == 0 {
+			timeout = v1alpha1.KubernetesApplyWaitForTimeoutDefault
+		}
+
+		for _, e := range objects {
+			if !selector.Matches(e) {
+				continue
+			}
+
+			done, err := r.checkRollout(ctx, e, w)
+			if done {
+				continue
+			}
+
+			desc := fmt.Sprintf("%s/%s: %s", strings.ToLower(e.GVK().Kind), e.Name(), waitForString(w))
+			if err != nil {
+				desc = fmt.Sprintf("%s (%v)", desc, err)
+			}
+			if elapsed >= timeout {
+				timedOut = append(timedOut, fmt.Sprintf("timed out after %s waiting for %s", timeout, desc))
+			} else {
+				pending = append(pending, fmt.Sprintf("waiting for %s", desc))
+			}
+		}
+	}
+
+	requeueAfter := time.Duration(0)
+	newCond := rolloutWaitingCondition(applyTime)
+	switch {
+	case len(timedOut) > 0:
+		newCond.Reason = v1alpha1.ApplyReasonRolloutTimeout
+		newCond.Message = strings.Join(timedOut, "; ")
+		logger.Get(ctx).Errorf("Rollout failed: %s", newCond.Message)
+	case len(pending) > 0:
+		newCond.Message = strings.Join(pending, "; ")
+		requeueAfter = rolloutCheckInterval
+	default:
+		newCond.Status = metav1.ConditionTrue
+		newCond.Reason = v1alpha1.ApplyReasonRolloutComplete
+		newCond.Message = ""
+		newCond.LastTransitionTime = metav1.Now()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result, ok = r.results[nn]
+	if !ok || !result.Status.LastApplyTime.Equal(&applyTime) {
+		// The objects were re-applied while we were checking them.
+		return 0, nil
+	}
+
+	update := result.Status.DeepCopy()
+	meta.SetStatusCondition(&update.Conditions, newCond)
+	result.Status = *update
+	return requeueAfter, nil
+}
+
+// Fetches the current state of the object, and checks if it passes.
+func (r *Reconciler) checkRollout(ctx context.Context, e k8s.K8sEntity, w v1alpha1.KubernetesApplyWaitFor) (bool, error) {
+	current, err := r.k8sClient.GetByReference(ctx, e.ToObjectReference())
+	if err != nil {
+		return false, err
+	}
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(current.Obj)
+	if err != nil {
+		return false, err
+	}
+
+	if w.Condition != "" {
+		conditions, _, err := unstructured.NestedSlice(content, "status", "conditions")
+		if err != nil {
+			return false, err
+		}
+		for _, c := range conditions {
+			c, ok := c.(map[string]interface{})
+			if ok && strings.EqualFold(fmt.Sprintf("%v", c["type"]), w.Condition) {
+				return fmt.Sprintf("%v", c["status"]) == string(metav1.ConditionTrue), nil
+			}
+		}
+		return false, nil
+	}
+
+	field := w.JSONPath
+	if !strings.HasPrefix(field, "{") {
+		field = fmt.Sprintf("{%s}", field)
+	}
+	jp := jsonpath.New("wait-for")
+	jp.AllowMissingKeys(true)
+	err = jp.Parse(field)
+	if err != nil {
+		return false, fmt.Errorf("invalid jsonpath %q: %v", w.JSONPath, err)
+	}
+
+	buf := bytes.NewBuffer(nil)
+	err = jp.Execute(buf, content)
+	if err != nil {
+		return false, err
+	}
+
+	value := buf.String()
+	if w.Value == "" {
+		return value != "", nil
+	}
+	return value == w.Value, nil
+}
+
+// Describes the check, in the syntax of `kubectl wait --for`.
+func waitForString(w v1alpha1.KubernetesApplyWaitFor) string {
+	if w.Condition != "" {
+		return fmt.Sprintf("condition=%s", w.Condition)
+	}
+	if w.Value == "" {
+		return fmt.Sprintf("jsonpath=%s", w.JSONPath)
+	}
+	return fmt.Sprintf("jsonpath=%s=%s", w.JSONPath, w.Value)
+}
@@ -0,0 +1,89 @@
+package kubernetesapply
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/pkg/apis"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+// Custom apply commands (like wrappers around pulumi or terraform) tend to be slow,
+// and re-running one when none of its deps have changed is wasted work.
+//
+// Computes the hash of all the inputs to the apply command.
+// Returns false if we can't fingerprint the inputs, in which case
+// we should always run the command.
+func (r *Reconciler) cmdInputHash(ctx context.Context,
+	spec v1alpha1.KubernetesApplySpec,
+	cluster *v1alpha1.Cluster,
+	imageMaps map[types.NamespacedName]*v1alpha1.ImageMap) (string, bool) {
+	if spec.RestartOn == nil || len(spec.RestartOn.FileWatches) == 0 {
+		// The command doesn't declare any deps.
+		return "", false
+	}
+
+	var fileWatches []*v1alpha1.FileWatch
+	for _, name := range spec.RestartOn.FileWatches {
+		var fw v1alpha1.FileWatch
+		err := r.ctrlClient.Get(ctx, types.NamespacedName{Name: name}, &fw)
+		if err != nil {
+			// If the FileWatch hasn't been created yet, we don't know the deps.
+			return "", false
+		}
+		fileWatches = append(fileWatches, &fw)
+	}
+
+	hash, err := ComputeCmdInputHash(spec, imageMaps, cluster, fileWatches)
+	if err != nil {
+		logger.Get(ctx).Debugf("Can't fingerprint apply command inputs: %v", err)
+		return "", false
+	}
+	return hash, true
+}
+
+// Returns the result of the last apply command if it succeeded
+// with the same inputs, and its objects are still deployed.
+func (r *Reconciler) lastCmdApply(nn types.NamespacedName, inputHash string) (applyResult, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result, ok := r.results[nn]
+	if !ok || !result.CmdApplied ||
+		result.Status.Error != "" ||
+		result.Status.AppliedInputHash != inputHash {
+		return applyResult{}, false
+	}
+
+	var objects []k8s.K8sEntity
+	for _, e := range result.AppliedObjects {
+		objects = append(objects, e)
+	}
+	return applyResult{
+		ResultYAML:       result.Status.ResultYAML,
+		AppliedInputHash: inputHash,
+		Objects:          objects,
+	}, true
+}
+
+// Record that we skipped the apply command.
+//
+// The objects from the last apply are still current,
+// so we carry them over to the new status.
+func (r *Reconciler) recordSkippedApply(
+	nn types.NamespacedName,
+	spec v1alpha1.KubernetesApplySpec,
+	cluster *v1alpha1.Cluster,
+	imageMaps map[types.NamespacedName]*v1alpha1.ImageMap,
+	status applyResult,
+	last applyResult) v1alpha1.KubernetesApplyStatus {
+	status.ResultYAML = last.ResultYAML
+	status.Objects = last.Objects
+	status.AppliedInputHash = last.AppliedInputHash
+	status.LastApplyTime = apis.NowMicro()
+	status.Skipped = true
+	return r.recordApplyResult(nn, spec, cluster, imageMaps, status)
+}
@@ -0,0 +1,169 @@
+package kubernetesdiscovery
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+// The most events to report for a single Pod.
+const maxEventsPerPod = 5
+
+// The reason that Kubernetes uses when a container runs out of memory.
+const oomKilledReason = "OOMKilled"
+
+// Kubernetes event reasons that explain why a Pod isn't running.
+//
+// Most warning events about Pods are noise (e.g., a flaky readiness
+// probe), so we only surface the ones that need the user to act.
+var podProblemEventReasons = map[string]bool{
+	"FailedScheduling":       true,
+	"Failed":                 true, // e.g., ErrImagePull
+	"BackOff":                true, // e.g., ImagePullBackOff, CrashLoopBackOff
+	"FailedMount":            true,
+	"FailedAttachVolume":     true,
+	"FailedCreatePodSandBox": true,
+	"Evicted":                true,
+}
+
+func isPodProblemEvent(event *v1.Event) bool {
+	return event.InvolvedObject.Kind == "Pod" &&
+		event.Type == v1.EventTypeWarning &&
+		podProblemEventReasons[event.Reason]
+}
+
+func (w *Reconciler) dispatchEventChangesLoop(ctx context.Context, nsKey nsKey, ch <-chan *v1.Event) {
+	for {
+		select {
+		case event, ok := <-ch:
+			if !ok {
+				return
+			}
+			if !isPodProblemEvent(event) {
+				continue
+			}
+			w.handleEventChange(nsKey, event)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (w *Reconciler) handleEventChange(nsKey nsKey, event *v1.Event) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	podKey := uidKey{cluster: nsKey.cluster, uid: event.InvolvedObject.UID}
+	events, ok := w.knownPodEvents[podKey]
+	if !ok {
+		events = make(map[types.UID]*v1.Event)
+		w.knownPodEvents[podKey] = events
+	}
+	events[event.UID] = event
+
+	// Events don't say which watchers matched the Pod, so trigger an
+	// update on every watcher for the Pod's cluster, which will return
+	// early if it didn't change.
+	for watcherID, watcher := range w.watchers {
+		if watcher.cluster != nsKey.cluster {
+			continue
+		}
+		w.requeuer.Add(types.NamespacedName(watcherID))
+	}
+}
+
+// podEvents returns the most recent problems with the Pod, oldest first.
+//
+// mu must be held by caller.
+func (w *Reconciler) podEvents(cluster clusterKey, pod *v1.Pod) []v1alpha1.KubernetesDiscoveryEvent {
+	var result []v1alpha1.KubernetesDiscoveryEvent
+	for _, event := range w.knownPodEvents[uidKey{cluster: cluster, uid: pod.UID}] {
+		count := event.Count
+		if count == 0 {
+			count = 1
+		}
+		result = append(result, v1alpha1.KubernetesDiscoveryEvent{
+			PodName:       pod.Name,
+			PodNamespace:  pod.Namespace,
+			ContainerName: containerNameFromFieldPath(event.InvolvedObject.FieldPath),
+			Reason:        event.Reason,
+			Message:       event.Message,
+			Count:         count,
+			LastTimestamp: eventTime(event),
+		})
+	}
+
+	result = append(result, oomKilledEvents(pod)...)
+
+	sort.SliceStable(result, func(i, j int) bool {
+		ti, tj := result[i].LastTimestamp, result[j].LastTimestamp
+		if !ti.Equal(&tj) {
+			return ti.Before(&tj)
+		}
+		if result[i].Reason != result[j].Reason {
+			return result[i].Reason < result[j].Reason
+		}
+		return result[i].Message < result[j].Message
+	})
+
+	if len(result) > maxEventsPerPod {
+		result = result[len(result)-maxEventsPerPod:]
+	}
+	return result
+}
+
+// Kubernetes doesn't emit an event when a container runs out of memory,
+// so we synthesize one from the container status.
+func oomKilledEvents(pod *v1.Pod) []v1alpha1.KubernetesDiscoveryEvent {
+	var result []v1alpha1.KubernetesDiscoveryEvent
+	statuses := append(append([]v1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, cs := range statuses {
+		terminated := cs.State.Terminated
+		if terminated == nil || terminated.Reason != oomKilledReason {
+			terminated = cs.LastTerminationState.Terminated
+		}
+		if terminated == nil || terminated.Reason != oomKilledReason {
+			continue
+		}
+
+		result = append(result, v1alpha1.KubernetesDiscoveryEvent{
+			PodName:       pod.Name,
+			PodNamespace:  pod.Namespace,
+			ContainerName: cs.Name,
+			Reason:        oomKilledReason,
+			Message: fmt.Sprintf("Container %s ran out of memory and was killed (exit code %d)",
+				cs.Name, terminated.ExitCode),
+			Count:         1,
+			LastTimestamp: terminated.FinishedAt,
+		})
+	}
+	return result
+}
+
+// Events about a container have a field path like "spec.containers{name}".
+func containerNameFromFieldPath(fieldPath string) string {
+	start := strings.Index(fieldPath, "{")
+	end := strings.LastIndex(fieldPath, "}")
+	if start == -1 || end < start {
+		return ""
+	}
+	return fieldPath[start+1 : end]
+}
+
+// Newer clients set the EventTime instead of the LastTimestamp.
+func eventTime(event *v1.Event) metav1.Time {
+	if !event.LastTimestamp.IsZero() {
+		return event.LastTimestamp
+	}
+	if !event.EventTime.IsZero() {
+		return metav1.NewTime(event.EventTime.Time)
+	}
+	return event.CreationTimestamp
+}
@@ -0,0 +1,191 @@
+package kubernetesdiscovery
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+func TestPodEvents(t *testing.T) {
+	f := newFixture(t)
+
+	pod := f.buildPod("pod-ns", "pod", nil, nil)
+	key := f.createExactMatchDiscovery(pod)
+
+	kCli := f.clients.MustK8sClient(clusterNN(f.mustGetDiscovery(key)))
+	kCli.UpsertPod(pod)
+	f.requireObservedPods(key, ancestorMap{pod.UID: pod.UID}, nil)
+
+	t1 := metav1.NewTime(time.Now().Truncate(time.Second))
+	t2 := metav1.NewTime(t1.Add(time.Second))
+	kCli.UpsertEvent(f.buildPodEvent(pod, "event1", v1.EventTypeWarning, "FailedScheduling",
+		"0/1 nodes are available: 1 Insufficient memory.", t1))
+	kCli.UpsertEvent(f.buildPodEvent(pod, "event2", v1.EventTypeNormal, "Scheduled",
+		"Successfully assigned pod-ns/pod to node", t2))
+	kCli.UpsertEvent(f.buildPodEvent(pod, "event3", v1.EventTypeWarning, "Unhealthy",
+		"Readiness probe failed", t2))
+
+	f.requireEvents(key, []v1alpha1.KubernetesDiscoveryEvent{
+		{
+			PodName:       "pod",
+			PodNamespace:  "pod-ns",
+			Reason:        "FailedScheduling",
+			Message:       "0/1 nodes are available: 1 Insufficient memory.",
+			Count:         1,
+			LastTimestamp: t1,
+		},
+	})
+
+	// Kubernetes updates the count of a repeated event.
+	backoff := f.buildPodEvent(pod, "event4", v1.EventTypeWarning, "BackOff",
+		`Back-off pulling image "my-image"`, t2)
+	backoff.InvolvedObject.FieldPath = "spec.containers{main}"
+	backoff.Count = 3
+	kCli.UpsertEvent(backoff)
+
+	f.requireEvents(key, []v1alpha1.KubernetesDiscoveryEvent{
+		{
+			PodName:       "pod",
+			PodNamespace:  "pod-ns",
+			Reason:        "FailedScheduling",
+			Message:       "0/1 nodes are available: 1 Insufficient memory.",
+			Count:         1,
+			LastTimestamp: t1,
+		},
+		{
+			PodName:       "pod",
+			PodNamespace:  "pod-ns",
+			ContainerName: "main",
+			Reason:        "BackOff",
+			Message:       `Back-off pulling image "my-image"`,
+			Count:         3,
+			LastTimestamp: t2,
+		},
+	})
+
+	// Events go away with the pod.
+	kCli.EmitPodDelete(pod)
+	f.requireObservedPods(key, nil, nil)
+	assert.Empty(t, f.mustGetDiscovery(key).Status.Events)
+}
+
+func TestPodEventsOOMKilled(t *testing.T) {
+	f := newFixture(t)
+
+	finishedAt := metav1.NewTime(time.Now().Truncate(time.Second))
+	pod := f.buildPod("pod-ns", "pod", nil, nil)
+	pod.Status.ContainerStatuses = []v1.ContainerStatus{
+		{
+			Name:         "main",
+			RestartCount: 1,
+			State:        v1.ContainerState{Running: &v1.ContainerStateRunning{}},
+			LastTerminationState: v1.ContainerState{
+				Terminated: &v1.ContainerStateTerminated{
+					Reason:     "OOMKilled",
+					ExitCode:   137,
+					FinishedAt: finishedAt,
+				},
+			},
+		},
+		{
+			Name:  "sidecar",
+			State: v1.ContainerState{Running: &v1.ContainerStateRunning{}},
+		},
+	}
+	key := f.createExactMatchDiscovery(pod)
+
+	kCli := f.clients.MustK8sClient(clusterNN(f.mustGetDiscovery(key)))
+	kCli.UpsertPod(pod)
+
+	f.requireEvents(key, []v1alpha1.KubernetesDiscoveryEvent{
+		{
+			PodName:       "pod",
+			PodNamespace:  "pod-ns",
+			ContainerName: "main",
+			Reason:        "OOMKilled",
+			Message:       "Container main ran out of memory and was killed (exit code 137)",
+			Count:         1,
+			LastTimestamp: finishedAt,
+		},
+	})
+}
+
+func TestContainerNameFromFieldPath(t *testing.T) {
+	assert.Equal(t, "main", containerNameFromFieldPath("spec.containers{main}"))
+	assert.Equal(t, "init", containerNameFromFieldPath("spec.initContainers{init}"))
+	assert.Equal(t, "", containerNameFromFieldPath(""))
+}
+
+func (f *fixture) createExactMatchDiscovery(pod *v1.Pod) types.NamespacedName {
+	f.t.Helper()
+	key := types.NamespacedName{Namespace: "some-ns", Name: "kd"}
+	kd := &v1alpha1.KubernetesDiscovery{
+		ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name},
+		Spec: v1alpha1.KubernetesDiscoverySpec{
+			Watches: []v1alpha1.KubernetesWatchRef{
+				{
+					UID:       string(pod.UID),
+					Namespace: pod.Namespace,
+					Name:      pod.Name,
+				},
+			},
+		},
+	}
+	f.Create(kd)
+	f.requireMonitorStarted(key)
+	return key
+}
+
+func (f *fixture) mustGetDiscovery(key types.NamespacedName) v1alpha1.KubernetesDiscovery {
+	f.t.Helper()
+	var kd v1alpha1.KubernetesDiscovery
+	f.MustGet(key, &kd)
+	return kd
+}
+
+func (f *fixture) buildPodEvent(pod *v1.Pod, name, eventType, reason, message string, ts metav1.Time) *v1.Event {
+	return &v1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			UID:       types.UID(name + "-uid"),
+			Name:      name,
+			Namespace: pod.Namespace,
+		},
+		InvolvedObject: v1.ObjectReference{
+			Kind:      "Pod",
+			Namespace: pod.Namespace,
+			Name:      pod.Name,
+			UID:       pod.UID,
+		},
+		Type:          eventType,
+		Reason:        reason,
+		Message:       message,
+		Count:         1,
+		LastTimestamp: ts,
+	}
+}
+
+func (f *fixture) requireEvents(key types.NamespacedName, expected []v1alpha1.KubernetesDiscoveryEvent) {
+	f.t.Helper()
+	var desc strings.Builder
+	f.requireState(key, func(kd *v1alpha1.KubernetesDiscovery) bool {
+		desc.Reset()
+		if kd == nil {
+			desc.WriteString("object does not exist in apiserver")
+			return false
+		}
+		if diff := cmp.Diff(expected, kd.Status.Events); diff != "" {
+			desc.WriteString("\n")
+			desc.WriteString(diff)
+			return false
+		}
+		return true
+	}, "Expected events were not observed for key[%s]: %s", key, &desc)
+}
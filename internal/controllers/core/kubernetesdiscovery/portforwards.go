@@ -179,6 +179,15 @@ func isBetterPortForwardPod(podA, podB *v1alpha1.Pod) bool {
 		return false
 	}
 
+	// A ready pod is better than a pod that isn't ready yet
+	// (e.g., because its containers are still starting or crashing).
+	readyA, readyB := isPodReady(podA), isPodReady(podB)
+	if readyA && !readyB {
+		return true
+	} else if readyB && !readyA {
+		return false
+	}
+
 	// Otherwise, a more recent pod is better.
 	if podA.CreatedAt.After(podB.CreatedAt.Time) {
 		return true
@@ -190,6 +199,15 @@ func isBetterPortForwardPod(podA, podB *v1alpha1.Pod) bool {
 	return podA.Name > podB.Name
 }
 
+func isPodReady(pod *v1alpha1.Pod) bool {
+	for _, c := range pod.Conditions {
+		if c.Type == string(v1.PodReady) {
+			return c.Status == string(v1.ConditionTrue)
+		}
+	}
+	return false
+}
+
 func warnDeprecatedImplicitForwards(ctx context.Context, kd *v1alpha1.KubernetesDiscovery, pf *v1alpha1.PortForward) {
 	if kd == nil || pf == nil {
 		return
@@ -2,6 +2,7 @@ package kubernetesdiscovery
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -227,3 +228,25 @@ func TestPortForwardNotForPending(t *testing.T) {
 
 	assert.True(t, f.Get(types.NamespacedName{Name: "kd-pod"}, &pf))
 }
+
+func TestPickBestPortForwardPodPrefersReady(t *testing.T) {
+	now := time.Now()
+	ready := []v1alpha1.PodCondition{{Type: string(v1.PodReady), Status: string(v1.ConditionTrue)}}
+	notReady := []v1alpha1.PodCondition{{Type: string(v1.PodReady), Status: string(v1.ConditionFalse)}}
+
+	kd := &v1alpha1.KubernetesDiscovery{
+		Status: v1alpha1.KubernetesDiscoveryStatus{
+			Pods: []v1alpha1.Pod{
+				{Name: "old-ready", Phase: string(v1.PodRunning), CreatedAt: metav1.NewTime(now.Add(-time.Minute)), Conditions: ready},
+				{Name: "new-not-ready", Phase: string(v1.PodRunning), CreatedAt: metav1.NewTime(now), Conditions: notReady},
+				{Name: "older-ready", Phase: string(v1.PodRunning), CreatedAt: metav1.NewTime(now.Add(-time.Hour)), Conditions: ready},
+			},
+		},
+	}
+	assert.Equal(t, "old-ready", pickBestPortForwardPod(kd).Name)
+
+	// A pod that's being deleted loses to a new pod, even if the new pod isn't ready.
+	kd.Status.Pods[0].Deleting = true
+	kd.Status.Pods[2].Deleting = true
+	assert.Equal(t, "new-not-ready", pickBestPortForwardPod(kd).Name)
+}
@@ -3,6 +3,8 @@ package kubernetesdiscovery
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -43,6 +45,16 @@ var (
 )
 
 type namespaceSet map[string]bool
+
+func (s namespaceSet) sorted() []string {
+	result := make([]string, 0, len(s))
+	for ns := range s {
+		result = append(result, ns)
+	}
+	sort.Strings(result)
+	return result
+}
+
 type watcherSet map[watcherID]bool
 
 // watcherID is to disambiguate between K8s object keys and tilt-apiserver KubernetesDiscovery object keys.
@@ -92,6 +104,12 @@ type Reconciler struct {
 	// knownPods is an index of all the known pods and associated Tilt-derived metadata, by UID.
 	knownPods             map[uidKey]*v1.Pod
 	knownPodOwnerCreation map[uidKey]metav1.Time
+
+	// knownPodEvents is an index of the problem events for each pod, by pod UID and event UID.
+	knownPodEvents map[uidKey]map[types.UID]*v1.Event
+
+	// How often to list pods in namespaces where we can't watch them.
+	podPollIntervals k8s.PodPollIntervals
 }
 
 func (w *Reconciler) CreateBuilder(mgr ctrl.Manager) (*builder.Builder, error) {
@@ -120,6 +138,8 @@ func NewReconciler(ctrlClient ctrlclient.Client, scheme *runtime.Scheme, clients
 		knownDescendentPodUIDs: make(map[uidKey]k8s.UIDSet),
 		knownPods:              make(map[uidKey]*v1.Pod),
 		knownPodOwnerCreation:  make(map[uidKey]metav1.Time),
+		knownPodEvents:         make(map[uidKey]map[types.UID]*v1.Event),
+		podPollIntervals:       k8s.DefaultPodPollIntervals,
 	}
 }
 
@@ -134,15 +154,32 @@ type watcher struct {
 	extraSelectors []labels.Selector
 	cluster        clusterKey
 	errorReason    string
+
+	// clusterUnreachable is whether the cluster was unreachable
+	// the last time we reconciled.
+	clusterUnreachable bool
+
+	// namespaceErrors are the namespaces that couldn't be watched,
+	// when other namespaces could.
+	namespaceErrors []v1alpha1.KubernetesDiscoveryNamespaceError
+
+	// pollingReason is set when pods are polled in some of the namespaces,
+	// because they can't be watched.
+	pollingReason string
 }
 
 // nsWatch tracks the watchers for the given namespace and allows the watch to be canceled.
 type nsWatch struct {
 	watchers map[watcherID]bool
 	cancel   context.CancelFunc
+
+	// Whether we list pods periodically, because we can't watch them.
+	polling bool
 }
 
 // Reconcile manages namespace watches for the modified KubernetesDiscovery object.
+func (w *Reconciler) CrashRecoverable() {}
+
 func (w *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
@@ -176,14 +213,30 @@ func (w *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 	// The apiserver is the source of truth, and will ensure the engine state is up to date.
 	w.st.Dispatch(kubernetesdiscoverys.NewKubernetesDiscoveryUpsertAction(kd))
 
-	cluster, err := w.getCluster(ctx, kd)
+	clusterObj, err := w.getCluster(ctx, kd)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
-	needsRefresh := w.clients.Refresh(kd, cluster)
-
-	if !hasExisting || needsRefresh || !apicmp.DeepEqual(existing.spec, kd.Spec) {
-		w.addOrReplace(ctx, key, kd, cluster)
+	needsRefresh := w.clients.Refresh(kd, clusterObj)
+	unreachableErr := cluster.UnreachableError(clusterObj)
+
+	// If we couldn't start discovery because the cluster was unreachable,
+	// start it once the cluster is back.
+	reconnected := existing.clusterUnreachable && unreachableErr == nil
+	if !hasExisting || needsRefresh || !apicmp.DeepEqual(existing.spec, kd.Spec) ||
+		(reconnected && existing.startTime.IsZero()) {
+		w.addOrReplace(ctx, key, kd, clusterObj, unreachableErr)
+	} else if existing.clusterUnreachable != (unreachableErr != nil) {
+		// The pod watches outlive a dropped connection, so tell the user
+		// once, rather than letting the watches time out over and over.
+		if unreachableErr != nil {
+			logger.Get(ctx).Warnf("kubernetesdiscovery %s: %v. Pod updates will resume when it reconnects.",
+				kd.Name, unreachableErr)
+		} else {
+			logger.Get(ctx).Infof("kubernetesdiscovery %s: cluster %q is reachable again", kd.Name, clusterObj.Name)
+		}
+		existing.clusterUnreachable = unreachableErr != nil
+		w.watchers[key] = existing
 	}
 
 	kd, err = w.maybeUpdateObjectStatus(ctx, kd, key)
@@ -228,7 +281,7 @@ func (w *Reconciler) getKubernetesDiscovery(ctx context.Context, key watcherID)
 	return &kd, nil
 }
 
-func (w *Reconciler) addOrReplace(ctx context.Context, watcherKey watcherID, kd *store.KubernetesDiscovery, cluster *v1alpha1.Cluster) {
+func (w *Reconciler) addOrReplace(ctx context.Context, watcherKey watcherID, kd *store.KubernetesDiscovery, cluster *v1alpha1.Cluster, unreachableErr error) {
 	if _, ok := w.watchers[watcherKey]; ok {
 		// if a watcher already exists, just tear it down and we'll set it up from scratch so that
 		// we don't have to diff a bunch of different pieces
@@ -255,23 +308,24 @@ func (w *Reconciler) addOrReplace(ctx context.Context, watcherKey watcherID, kd
 	}
 
 	newWatcher := watcher{
-		spec:           *kd.Spec.DeepCopy(),
-		extraSelectors: extraSelectors,
-		cluster:        newClusterKey(cluster),
+		spec:               *kd.Spec.DeepCopy(),
+		extraSelectors:     extraSelectors,
+		cluster:            newClusterKey(cluster),
+		clusterUnreachable: unreachableErr != nil,
 	}
 
 	kCli, err := w.clients.GetK8sClient(kd, cluster)
 	if err != nil {
 		newWatcher.errorReason = "ClusterUnavailable"
+	} else if unreachableErr != nil {
+		newWatcher.errorReason = unreachableErr.Error()
 	} else {
-		currentNamespaces, currentUIDs := namespacesAndUIDsFromSpec(kd.Spec.Watches)
-		for namespace := range currentNamespaces {
-			nsKey := newNsKey(cluster, namespace)
-			err := w.setupNamespaceWatch(ctx, nsKey, watcherKey, kCli)
-			if err != nil {
-				newWatcher.errorReason = err.Error()
-				break
-			}
+		_, currentUIDs := namespacesAndUIDsFromSpec(kd.Spec)
+		watched, nsErrors := w.setupNamespaceWatches(ctx, cluster, watcherKey, kCli, kd.Spec)
+		if watched == 0 && len(nsErrors) > 0 {
+			newWatcher.errorReason = nsErrors[0].Error
+		} else {
+			newWatcher.namespaceErrors = nsErrors
 		}
 
 		if newWatcher.errorReason == "" {
@@ -280,6 +334,7 @@ func (w *Reconciler) addOrReplace(ctx context.Context, watcherKey watcherID, kd
 			}
 
 			newWatcher.startTime = time.Now()
+			newWatcher.pollingReason = w.pollingReason(watcherKey)
 		}
 	}
 
@@ -294,7 +349,7 @@ func (w *Reconciler) addOrReplace(ctx context.Context, watcherKey watcherID, kd
 // the watches without needlessly removing + recreating the lower-level namespace watch.
 func (w *Reconciler) teardown(watcherKey watcherID) {
 	watcher := w.watchers[watcherKey]
-	namespaces, uids := namespacesAndUIDsFromSpec(watcher.spec.Watches)
+	namespaces, uids := namespacesAndUIDsFromSpec(watcher.spec)
 	for nsKey, nsWatch := range w.watchedNamespaces {
 		if namespaces[nsKey.namespace] {
 			delete(nsWatch.watchers, watcherKey)
@@ -324,6 +379,44 @@ func (w *Reconciler) cleanupAbandonedNamespaces() {
 	}
 }
 
+// setupNamespaceWatches watches all the namespaces that the spec looks for pods in.
+//
+// mu must be held by caller.
+//
+// Keeps going if a namespace can't be watched (e.g., because of missing RBAC
+// permissions), so that discovery works in the namespaces that can be.
+// Returns the number of namespaces watched, and an error for each namespace
+// that couldn't be.
+//
+// If the spec asks for all namespaces but they can't be watched,
+// falls back to the namespaces that the spec names.
+func (w *Reconciler) setupNamespaceWatches(ctx context.Context, cluster *v1alpha1.Cluster, watcherKey watcherID,
+	kCli k8s.Client, spec v1alpha1.KubernetesDiscoverySpec) (int, []v1alpha1.KubernetesDiscoveryNamespaceError) {
+	var nsErrors []v1alpha1.KubernetesDiscoveryNamespaceError
+	if spec.AllNamespaces {
+		err := w.setupNamespaceWatch(ctx, newNsKey(cluster, ""), watcherKey, kCli)
+		if err == nil {
+			return 1, nil
+		}
+		nsErrors = append(nsErrors, v1alpha1.KubernetesDiscoveryNamespaceError{Error: err.Error()})
+	}
+
+	namespaces, _ := namespacesAndUIDsFromSpec(spec)
+	watched := 0
+	for _, ns := range namespaces.sorted() {
+		if ns == "" {
+			continue
+		}
+		err := w.setupNamespaceWatch(ctx, newNsKey(cluster, ns), watcherKey, kCli)
+		if err != nil {
+			nsErrors = append(nsErrors, v1alpha1.KubernetesDiscoveryNamespaceError{Namespace: ns, Error: err.Error()})
+			continue
+		}
+		watched++
+	}
+	return watched, nsErrors
+}
+
 // setupNamespaceWatch creates a namespace watch if necessary and adds a key to the list of watchers for it.
 //
 // mu must be held by caller.
@@ -345,7 +438,19 @@ func (w *Reconciler) setupNamespaceWatch(ctx context.Context, nsKey nsKey, watch
 
 	ns := nsKey.namespace
 	ch, err := kCli.WatchPods(ctx, k8s.Namespace(ns))
+	polling := false
+	if err != nil && apierrors.IsForbidden(err) {
+		// Restricted clusters sometimes let us list pods, but not watch them.
+		// Polling is slower, but better than no pod status at all.
+		if _, listErr := kCli.ListPods(ctx, k8s.Namespace(ns)); listErr == nil {
+			polling = true
+			err = nil
+		}
+	}
 	if err != nil {
+		if ns == "" {
+			return errors.Wrap(err, "Error watching pods in all namespaces. Are you connected to kubernetes?\nTry running `kubectl get pods --all-namespaces`")
+		}
 		return errors.Wrapf(err, "Error watching pods. Are you connected to kubernetes?\nTry running `kubectl get pods -n %q`", ns)
 	}
 
@@ -353,12 +458,47 @@ func (w *Reconciler) setupNamespaceWatch(ctx context.Context, nsKey nsKey, watch
 	w.watchedNamespaces[nsKey] = nsWatch{
 		watchers: map[watcherID]bool{watcherKey: true},
 		cancel:   cancel,
+		polling:  polling,
+	}
+
+	if polling {
+		ch = k8s.PollPods(ctx, kCli, k8s.Namespace(ns), w.podPollIntervals)
 	}
 
 	go w.dispatchPodChangesLoop(ctx, nsKey, kCli.OwnerFetcher(), ch)
+
+	// Events are only used for diagnostics, so discovery should keep working
+	// if they can't be watched (e.g., because of missing RBAC permissions).
+	eventCh, err := kCli.WatchEvents(ctx, k8s.Namespace(ns))
+	if err == nil {
+		go w.dispatchEventChangesLoop(ctx, nsKey, eventCh)
+	}
 	return nil
 }
 
+// Explains which of the watcher's namespaces are polled for pods, if any.
+//
+// mu must be held by caller.
+func (w *Reconciler) pollingReason(watcherKey watcherID) string {
+	var namespaces []string
+	for nsKey, nsWatch := range w.watchedNamespaces {
+		if !nsWatch.polling || !nsWatch.watchers[watcherKey] {
+			continue
+		}
+		if nsKey.namespace == "" {
+			namespaces = append(namespaces, "all namespaces")
+		} else {
+			namespaces = append(namespaces, fmt.Sprintf("namespace %q", nsKey.namespace))
+		}
+	}
+	if len(namespaces) == 0 {
+		return ""
+	}
+	sort.Strings(namespaces)
+	return fmt.Sprintf("Not allowed to watch pods in %s. Polling for pod changes instead, so updates may be slower",
+		strings.Join(namespaces, ", "))
+}
+
 // setupUIDWatch registers a watcher to receive updates for any Pods transitively owned by this UID (or that exactly
 // match this UID).
 //
@@ -407,10 +547,36 @@ func (w *Reconciler) maybeUpdateObjectStatus(ctx context.Context, kd *v1alpha1.K
 		logger.Get(ctx).Errorf("kubernetesdiscovery %s: %s", update.Name, newError)
 	}
 
+	if reason := statusPollingReason(update.Status); reason != "" && reason != statusPollingReason(oldStatus) {
+		logger.Get(ctx).Warnf("kubernetesdiscovery %s: %s", update.Name, reason)
+	}
+
+	for _, nsErr := range update.Status.NamespaceErrors {
+		if !containsNamespaceError(oldStatus.NamespaceErrors, nsErr) {
+			logger.Get(ctx).Warnf("kubernetesdiscovery %s: skipping namespace: %s", update.Name, nsErr.Error)
+		}
+	}
+
 	w.restartDetector.Detect(w.st, oldStatus, update)
 	return update, nil
 }
 
+func containsNamespaceError(errs []v1alpha1.KubernetesDiscoveryNamespaceError, nsErr v1alpha1.KubernetesDiscoveryNamespaceError) bool {
+	for _, e := range errs {
+		if e == nsErr {
+			return true
+		}
+	}
+	return false
+}
+
+func statusPollingReason(status v1alpha1.KubernetesDiscoveryStatus) string {
+	if status.Running != nil {
+		return status.Running.PollingReason
+	}
+	return ""
+}
+
 func (w *Reconciler) statusError(status v1alpha1.KubernetesDiscoveryStatus) string {
 	if status.Waiting != nil {
 		return status.Waiting.Reason
@@ -432,6 +598,7 @@ func (w *Reconciler) buildStatus(ctx context.Context, watcher watcher) v1alpha1.
 
 	seenPodUIDs := k8s.NewUIDSet()
 	var pods []v1alpha1.Pod
+	var events []v1alpha1.KubernetesDiscoveryEvent
 	maybeTrackPod := func(pod *v1.Pod, ancestorUID types.UID) {
 		if pod == nil || seenPodUIDs.Contains(pod.UID) {
 			return
@@ -443,6 +610,7 @@ func (w *Reconciler) buildStatus(ctx context.Context, watcher watcher) v1alpha1.
 			podObj.Owner.CreationTimestamp = w.knownPodOwnerCreation[podKey]
 		}
 		pods = append(pods, podObj)
+		events = append(events, w.podEvents(watcher.cluster, pod)...)
 	}
 
 	for i := range watcher.spec.Watches {
@@ -481,8 +649,11 @@ func (w *Reconciler) buildStatus(ctx context.Context, watcher watcher) v1alpha1.
 		MonitorStartTime: startTime,
 		Pods:             pods,
 		Running: &v1alpha1.KubernetesDiscoveryStateRunning{
-			StartTime: startTime,
+			StartTime:     startTime,
+			PollingReason: watcher.pollingReason,
 		},
+		NamespaceErrors: append([]v1alpha1.KubernetesDiscoveryNamespaceError(nil), watcher.namespaceErrors...),
+		Events:          events,
 	}
 }
 
@@ -605,6 +776,7 @@ func (w *Reconciler) handlePodDelete(ctx context.Context, namespace k8s.Namespac
 		if pod.Namespace == namespace.String() && pod.Name == name {
 			delete(w.knownPods, podKey)
 			delete(w.knownPodOwnerCreation, podKey)
+			delete(w.knownPodEvents, podKey)
 			matchedPodKey = podKey
 			break
 		}
@@ -760,10 +932,22 @@ func (w *Reconciler) dispatchPodChangesLoop(ctx context.Context, nsKey nsKey, ow
 	}
 }
 
-func namespacesAndUIDsFromSpec(watches []v1alpha1.KubernetesWatchRef) (namespaceSet, k8s.UIDSet) {
+// Returns the namespaces to look for pods in, and the UIDs to match pods against.
+//
+// If the spec asks for all namespaces, the set includes the empty namespace,
+// in addition to the namespaces that the spec names.
+func namespacesAndUIDsFromSpec(spec v1alpha1.KubernetesDiscoverySpec) (namespaceSet, k8s.UIDSet) {
 	seenNamespaces := make(namespaceSet)
 	seenUIDs := k8s.NewUIDSet()
 
+	if spec.AllNamespaces {
+		seenNamespaces[""] = true
+	}
+	for _, ns := range spec.ExtraNamespaces {
+		seenNamespaces[ns] = true
+	}
+
+	watches := spec.Watches
 	for i := range watches {
 		seenNamespaces[watches[i].Namespace] = true
 		uid := types.UID(watches[i].UID)
@@ -13,6 +13,7 @@ import (
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -201,6 +202,181 @@ func TestPodDiscoveryLabelMatch(t *testing.T) {
 	f.requireObservedPods(key, ancestorMap{pod2.UID: "", pod4.UID: knownRS.UID}, nil)
 }
 
+func TestPodDiscoveryLabelMatchExtraNamespace(t *testing.T) {
+	f := newFixture(t)
+
+	ns := k8s.Namespace("ns")
+	operatorNS := k8s.Namespace("operator-ns")
+
+	key := types.NamespacedName{Namespace: "some-ns", Name: "kd"}
+	kd := &v1alpha1.KubernetesDiscovery{
+		ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name},
+		Spec: v1alpha1.KubernetesDiscoverySpec{
+			Watches: []v1alpha1.KubernetesWatchRef{
+				{Namespace: ns.String()},
+			},
+			ExtraSelectors: []metav1.LabelSelector{
+				*metav1.SetAsLabelSelector(labels.Set{"app": "db"}),
+			},
+			ExtraNamespaces: []string{operatorNS.String()},
+		},
+	}
+
+	f.Create(kd)
+	f.requireMonitorStarted(key)
+
+	pod1 := f.buildPod(ns, "pod1", labels.Set{"app": "db"}, nil)
+	pod2 := f.buildPod(operatorNS, "pod2", labels.Set{"app": "db"}, nil)
+	pod3 := f.buildPod("other-ns", "pod3", labels.Set{"app": "db"}, nil)
+	f.injectK8sObjects(*kd, pod1, pod2, pod3)
+
+	// pod3 matches on labels, but isn't in a watched namespace
+	f.requireObservedPods(key, ancestorMap{pod1.UID: "", pod2.UID: ""}, nil)
+}
+
+func TestPodDiscoveryLabelMatchAllNamespaces(t *testing.T) {
+	f := newFixture(t)
+
+	key := types.NamespacedName{Namespace: "some-ns", Name: "kd"}
+	kd := &v1alpha1.KubernetesDiscovery{
+		ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name},
+		Spec: v1alpha1.KubernetesDiscoverySpec{
+			ExtraSelectors: []metav1.LabelSelector{
+				*metav1.SetAsLabelSelector(labels.Set{"app": "db"}),
+			},
+			AllNamespaces: true,
+		},
+	}
+
+	f.Create(kd)
+	f.requireMonitorStarted(key)
+
+	pod1 := f.buildPod("ns1", "pod1", labels.Set{"app": "db"}, nil)
+	pod2 := f.buildPod("ns2", "pod2", labels.Set{"app": "db"}, nil)
+	pod3 := f.buildPod("ns2", "pod3", labels.Set{"app": "web"}, nil)
+	f.injectK8sObjects(*kd, pod1, pod2, pod3)
+
+	f.requireObservedPods(key, ancestorMap{pod1.UID: "", pod2.UID: ""}, nil)
+}
+
+func TestPodDiscoveryNamespaceError(t *testing.T) {
+	f := newFixture(t)
+
+	ns := k8s.Namespace("ns")
+	forbiddenNS := k8s.Namespace("forbidden-ns")
+
+	key := types.NamespacedName{Namespace: "some-ns", Name: "kd"}
+	kd := &v1alpha1.KubernetesDiscovery{
+		ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name},
+		Spec: v1alpha1.KubernetesDiscoverySpec{
+			Watches: []v1alpha1.KubernetesWatchRef{
+				{Namespace: ns.String()},
+			},
+			ExtraSelectors: []metav1.LabelSelector{
+				*metav1.SetAsLabelSelector(labels.Set{"app": "db"}),
+			},
+			ExtraNamespaces: []string{forbiddenNS.String()},
+			AllNamespaces:   true,
+		},
+	}
+
+	f.clients.EnsureK8sCluster(f.ctx, clusterNN(*kd))
+	f.clients.MustK8sClient(clusterNN(*kd)).PodWatchErrs = map[k8s.Namespace]error{
+		"":          errors.New("cannot list pods at the cluster scope"),
+		forbiddenNS: errors.New("cannot list pods in namespace forbidden-ns"),
+	}
+
+	f.Create(kd)
+	f.requireMonitorStarted(key)
+
+	pod1 := f.buildPod(ns, "pod1", labels.Set{"app": "db"}, nil)
+	f.injectK8sObjects(*kd, pod1)
+	f.requireObservedPods(key, ancestorMap{pod1.UID: ""}, nil)
+
+	f.MustGet(key, kd)
+	assert.Nil(t, kd.Status.Waiting)
+	assert.NotNil(t, kd.Status.Running)
+	if assert.Len(t, kd.Status.NamespaceErrors, 2) {
+		assert.Equal(t, "", kd.Status.NamespaceErrors[0].Namespace)
+		assert.Contains(t, kd.Status.NamespaceErrors[0].Error, "cannot list pods at the cluster scope")
+		assert.Equal(t, forbiddenNS.String(), kd.Status.NamespaceErrors[1].Namespace)
+		assert.Contains(t, kd.Status.NamespaceErrors[1].Error, "cannot list pods in namespace forbidden-ns")
+	}
+}
+
+func TestPodDiscoveryPollsWhenWatchForbidden(t *testing.T) {
+	f := newFixture(t)
+	f.r.podPollIntervals = k8s.PodPollIntervals{Min: 5 * time.Millisecond, Max: 20 * time.Millisecond}
+
+	ns := k8s.Namespace("ns")
+	restrictedNS := k8s.Namespace("restricted-ns")
+
+	key := types.NamespacedName{Namespace: "some-ns", Name: "kd"}
+	kd := &v1alpha1.KubernetesDiscovery{
+		ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name},
+		Spec: v1alpha1.KubernetesDiscoverySpec{
+			Watches: []v1alpha1.KubernetesWatchRef{
+				{Namespace: ns.String()},
+				{Namespace: restrictedNS.String()},
+			},
+			ExtraSelectors: []metav1.LabelSelector{
+				*metav1.SetAsLabelSelector(labels.Set{"app": "db"}),
+			},
+		},
+	}
+
+	f.clients.EnsureK8sCluster(f.ctx, clusterNN(*kd))
+	f.clients.MustK8sClient(clusterNN(*kd)).PodWatchErrs = map[k8s.Namespace]error{
+		restrictedNS: apierrors.NewForbidden(k8s.PodGVR.GroupResource(), "", errors.New("cannot watch pods")),
+	}
+
+	f.Create(kd)
+	f.requireMonitorStarted(key)
+
+	pod1 := f.buildPod(ns, "pod1", labels.Set{"app": "db"}, nil)
+	pod2 := f.buildPod(restrictedNS, "pod2", labels.Set{"app": "db"}, nil)
+	f.injectK8sObjects(*kd, pod1, pod2)
+	f.requireObservedPods(key, ancestorMap{pod1.UID: "", pod2.UID: ""}, nil)
+
+	f.MustGet(key, kd)
+	assert.Empty(t, kd.Status.NamespaceErrors)
+	require.NotNil(t, kd.Status.Running)
+	assert.Equal(t,
+		`Not allowed to watch pods in namespace "restricted-ns". Polling for pod changes instead, so updates may be slower`,
+		kd.Status.Running.PollingReason)
+
+	// Deleted pods are noticed too.
+	f.clients.MustK8sClient(clusterNN(*kd)).EmitPodDelete(pod2)
+	f.requireObservedPods(key, ancestorMap{pod1.UID: ""}, nil)
+}
+
+func TestPodDiscoveryWatchAndListForbidden(t *testing.T) {
+	f := newFixture(t)
+
+	ns := k8s.Namespace("ns")
+	key := types.NamespacedName{Namespace: "some-ns", Name: "kd"}
+	kd := &v1alpha1.KubernetesDiscovery{
+		ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name},
+		Spec: v1alpha1.KubernetesDiscoverySpec{
+			Watches: []v1alpha1.KubernetesWatchRef{{Namespace: ns.String()}},
+		},
+	}
+
+	forbidden := apierrors.NewForbidden(k8s.PodGVR.GroupResource(), "", errors.New("cannot list pods"))
+	f.clients.EnsureK8sCluster(f.ctx, clusterNN(*kd))
+	kCli := f.clients.MustK8sClient(clusterNN(*kd))
+	kCli.PodWatchErrs = map[k8s.Namespace]error{ns: forbidden}
+	kCli.PodListErrs = map[k8s.Namespace]error{ns: forbidden}
+
+	f.Create(kd)
+
+	require.Eventually(t, func() bool {
+		f.MustGet(key, kd)
+		return kd.Status.Waiting != nil
+	}, time.Second, 5*time.Millisecond)
+	assert.Contains(t, kd.Status.Waiting.Reason, "cannot list pods")
+}
+
 func TestPodDiscoveryDuplicates(t *testing.T) {
 	f := newFixture(t)
 
@@ -499,6 +675,50 @@ func TestKubernetesDiscoveryClusterError(t *testing.T) {
 	require.Nil(t, kd.Status.Running, "Running should not be populated")
 }
 
+func TestKubernetesDiscoveryClusterUnreachable(t *testing.T) {
+	f := newFixture(t)
+
+	pod := f.buildPod("pod-ns", "pod", nil, nil)
+
+	key := types.NamespacedName{Namespace: "some-ns", Name: "kd"}
+	kd := &v1alpha1.KubernetesDiscovery{
+		ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name},
+		Spec: v1alpha1.KubernetesDiscoverySpec{
+			Cluster: "my-cluster",
+			Watches: []v1alpha1.KubernetesWatchRef{
+				{
+					UID:       string(pod.UID),
+					Namespace: pod.Namespace,
+					Name:      pod.Name,
+				},
+			},
+		},
+	}
+
+	f.clients.EnsureK8sClusterUnreachable(f.ctx, clusterNN(*kd), errors.New("dial tcp 10.0.0.1:6443: i/o timeout"))
+	require.NoError(t, f.Client.Create(f.Context(), kd), "Could not create KubernetesDiscovery")
+	f.MustReconcile(key)
+	f.MustGet(key, kd)
+
+	require.NotNil(t, kd.Status.Waiting, "Waiting should be present")
+	require.Equal(t, `cluster "my-cluster" is unreachable: dial tcp 10.0.0.1:6443: i/o timeout`,
+		kd.Status.Waiting.Reason)
+	require.Zero(t, kd.Status.MonitorStartTime, "MonitorStartTime should not be populated")
+
+	// Once the cluster is back, discovery starts.
+	f.clients.EnsureK8sCluster(f.ctx, clusterNN(*kd))
+	f.MustReconcile(key)
+	f.requireMonitorStarted(key)
+
+	// If the cluster drops again, the watches stay up,
+	// and we tell the user once.
+	f.clients.EnsureK8sClusterUnreachable(f.ctx, clusterNN(*kd), errors.New("dial tcp 10.0.0.1:6443: i/o timeout"))
+	f.MustReconcile(key)
+	f.MustReconcile(key)
+	f.requireMonitorStarted(key)
+	assert.Equal(t, 1, strings.Count(f.Stdout(), "Pod updates will resume when it reconnects"))
+}
+
 func TestClusterChange(t *testing.T) {
 	f := newFixture(t)
 
@@ -3,6 +3,7 @@ package liveupdate
 import (
 	"github.com/tilt-dev/tilt/internal/build"
 	"github.com/tilt-dev/tilt/internal/store/liveupdates"
+	"github.com/tilt-dev/tilt/pkg/model"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -11,6 +12,10 @@ type Input struct {
 	// Derived from DockerResource
 	IsDC bool
 
+	// Derived from the DockerComposeService's tilt.restart-cmd label.
+	// Runs after every update, in place of restarting the container.
+	RestartCmd model.Cmd
+
 	// Derived from KubernetesResource + KubenetesSelector + DockerResource
 	Containers []liveupdates.Container
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"sync"
 	"time"
 
@@ -28,6 +29,7 @@ import (
 	"github.com/tilt-dev/tilt/internal/controllers/apis/configmap"
 	"github.com/tilt-dev/tilt/internal/controllers/apis/liveupdate"
 	"github.com/tilt-dev/tilt/internal/controllers/indexer"
+	"github.com/tilt-dev/tilt/internal/dockercompose"
 	"github.com/tilt-dev/tilt/internal/k8s"
 	"github.com/tilt-dev/tilt/internal/ospath"
 	"github.com/tilt-dev/tilt/internal/sliceutils"
@@ -66,6 +68,9 @@ type Reconciler struct {
 
 	monitors map[string]*monitor
 
+	// Records reconciles for debugging, if enabled.
+	recorder *recorder
+
 	// We need to be able to map trigger events to known resources while
 	// Reconcile() is running.
 	mu sync.Mutex
@@ -82,7 +87,7 @@ func NewReconciler(
 	kubeContext k8s.KubeContext,
 	client ctrlclient.Client,
 	scheme *runtime.Scheme) *Reconciler {
-	return &Reconciler{
+	r := &Reconciler{
 		DockerUpdater: dcu,
 		ExecUpdater:   ecu,
 		updateMode:    updateMode,
@@ -93,6 +98,16 @@ func NewReconciler(
 		startedTime:   apis.NowMicro(),
 		monitors:      make(map[string]*monitor),
 	}
+	if dir := os.Getenv(RecordDirEnvVar); dir != "" {
+		r.enableRecording(dir)
+	}
+	return r
+}
+
+// Record the inputs and outputs of every reconcile.
+func (r *Reconciler) enableRecording(dir string) {
+	r.recorder = newRecorder(dir)
+	r.client = recordingClient{Client: r.client, recorder: r.recorder}
 }
 
 // Create a reconciler baked by a fake ContainerUpdater and Client.
@@ -118,6 +133,9 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	r.recorder.start(req.Name, r.startedTime)
+	defer r.recorder.finish(ctx)
+
 	lu := &v1alpha1.LiveUpdate{}
 	err := r.client.Get(ctx, req.NamespacedName, lu)
 	r.indexer.OnReconcile(req.NamespacedName, lu)
@@ -776,6 +794,7 @@ func (r *Reconciler) maybeSync(ctx context.Context, lu *v1alpha1.LiveUpdate, mon
 			// Apply the change to the container.
 			oneUpdateStatus = r.applyInternal(ctx, lu.Spec, Input{
 				IsDC:               lu.Spec.Selector.DockerCompose != nil,
+				RestartCmd:         restartCmd(resource),
 				ChangedFiles:       plan.SyncPaths,
 				Containers:         []liveupdates.Container{c},
 				LastFileTimeSynced: newHighWaterMark,
@@ -888,6 +907,9 @@ func (r *Reconciler) applyInternal(
 	var result v1alpha1.LiveUpdateStatus
 	cu := r.containerUpdater(input)
 	l := logger.Get(ctx)
+	if configmap.LowBandwidth(ctx, r.client) {
+		ctx = containerupdate.WithCompression(ctx)
+	}
 	containers := input.Containers
 	names := liveupdates.ContainerDisplayNames(containers)
 	suffix := ""
@@ -898,6 +920,13 @@ func (r *Reconciler) applyInternal(
 	runSteps := liveupdate.RunSteps(spec)
 	changedFiles := input.ChangedFiles
 	hotReload := !liveupdate.ShouldRestart(spec)
+	if !input.RestartCmd.Empty() {
+		// The service knows how to reload itself, which is
+		// cheaper than restarting its container.
+		l.Infof("Will run restart command from %s label: %s", dockercompose.LabelRestartCmd, input.RestartCmd)
+		runSteps = append(runSteps, model.Run{Cmd: input.RestartCmd})
+		hotReload = true
+	}
 	boiledSteps, err := build.BoilRuns(runSteps, changedFiles)
 	if err != nil {
 		result.Failed = &v1alpha1.LiveUpdateStateFailed{
@@ -940,6 +969,7 @@ func (r *Reconciler) applyInternal(
 		err = cu.UpdateContainer(ctx, cInfo, archive,
 			build.PathMappingsToContainerPaths(toRemove), boiledSteps, hotReload)
 		_ = archive.Close()
+		r.recorder.recordUpdate(cInfo, toArchive, toRemove, boiledSteps, hotReload, err)
 
 		lastFileTimeSynced := input.LastFileTimeSynced
 		if lastFileTimeSynced.IsZero() {
@@ -781,6 +781,45 @@ func TestDockerComposeRestartPolicy(t *testing.T) {
 	}
 }
 
+func TestDockerComposeRestartCmdLabel(t *testing.T) {
+	f := newFixture(t)
+
+	p, _ := os.Getwd()
+	nowMicro := apis.NowMicro()
+	txtPath := filepath.Join(p, "a.txt")
+	txtChangeTime := metav1.MicroTime{Time: nowMicro.Add(time.Second)}
+
+	f.setupDockerComposeFrontend()
+
+	var dcs v1alpha1.DockerComposeService
+	f.MustGet(types.NamespacedName{Name: "frontend-service"}, &dcs)
+	dcs.Spec.Labels = map[string]string{dockercompose.LabelRestartCmd: "kill -HUP 1"}
+	f.Upsert(&dcs)
+
+	var lu v1alpha1.LiveUpdate
+	f.MustGet(types.NamespacedName{Name: "frontend-liveupdate"}, &lu)
+	lu.Spec.Restart = v1alpha1.LiveUpdateRestartStrategyAlways
+	lu.Spec.Execs = []v1alpha1.LiveUpdateExec{
+		{Args: model.ToUnixCmd("./foo.sh bar").Argv},
+	}
+	f.Upsert(&lu)
+
+	f.addFileEvent("frontend-fw", txtPath, txtChangeTime)
+	f.MustReconcile(types.NamespacedName{Name: "frontend-liveupdate"})
+
+	f.MustGet(types.NamespacedName{Name: "frontend-liveupdate"}, &lu)
+	assert.Nil(t, lu.Status.Failed)
+
+	// Make sure the restart command ran after the execs, instead of a restart.
+	if assert.Equal(t, 1, len(f.cu.Calls)) {
+		assert.True(t, f.cu.Calls[0].HotReload)
+		assert.Equal(t, []model.Cmd{
+			model.ToUnixCmd("./foo.sh bar"),
+			model.ToUnixCmd("kill -HUP 1"),
+		}, f.cu.Calls[0].Cmds)
+	}
+}
+
 func TestDockerComposeExecs(t *testing.T) {
 	f := newFixture(t)
 
@@ -0,0 +1,288 @@
+package liveupdate
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/tilt-dev/tilt/internal/build"
+	"github.com/tilt-dev/tilt/internal/store/liveupdates"
+	"github.com/tilt-dev/tilt/pkg/apis"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// Set this env var to a directory to record every LiveUpdate reconcile.
+//
+// Each LiveUpdate gets a file <dir>/<name>.jsonl, with one ReconcileRecord
+// per line. Replay the file with `tilt debug replay-liveupdate`.
+const RecordDirEnvVar = "TILT_LIVEUPDATE_RECORD_DIR"
+
+// Everything that one LiveUpdate reconcile read from the apiserver,
+// and what it did with it.
+//
+// Intended for reproducing sync bugs. The format does not make any
+// compatibility promises.
+type ReconcileRecord struct {
+	// The name of the LiveUpdate that was reconciled.
+	Name string `json:"name"`
+
+	// When the reconcile ran.
+	Time metav1.MicroTime `json:"time"`
+
+	// When the reconciler started. File events before this are ignored.
+	StartedTime metav1.MicroTime `json:"startedTime"`
+
+	// The inputs, as read from the apiserver.
+	LiveUpdate            *v1alpha1.LiveUpdate            `json:"liveUpdate,omitempty"`
+	FileWatches           []v1alpha1.FileWatch            `json:"fileWatches,omitempty"`
+	ImageMaps             []v1alpha1.ImageMap             `json:"imageMaps,omitempty"`
+	KubernetesApplys      []v1alpha1.KubernetesApply      `json:"kubernetesApplys,omitempty"`
+	KubernetesDiscoverys  []v1alpha1.KubernetesDiscovery  `json:"kubernetesDiscoverys,omitempty"`
+	DockerComposeServices []v1alpha1.DockerComposeService `json:"dockerComposeServices,omitempty"`
+	ConfigMaps            []v1alpha1.ConfigMap            `json:"configMaps,omitempty"`
+
+	// Inputs that were looked up, but didn't exist.
+	NotFound []RecordedRef `json:"notFound,omitempty"`
+
+	// The container updates that the reconcile ran.
+	Updates []RecordedUpdate `json:"updates,omitempty"`
+
+	// The LiveUpdate status after the reconcile.
+	Status v1alpha1.LiveUpdateStatus `json:"status"`
+}
+
+type RecordedRef struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+// One update of one container.
+type RecordedUpdate struct {
+	ContainerName string   `json:"containerName"`
+	ContainerID   string   `json:"containerID"`
+	PodName       string   `json:"podName,omitempty"`
+	Namespace     string   `json:"namespace,omitempty"`
+	Copied        []string `json:"copied,omitempty"`
+	Deleted       []string `json:"deleted,omitempty"`
+	Cmds          []string `json:"cmds,omitempty"`
+	HotReload     bool     `json:"hotReload,omitempty"`
+
+	// The error from the container updater, if any.
+	Error string `json:"error,omitempty"`
+
+	// Whether the error was from one of the user's run steps
+	// (rather than from the container runtime).
+	RunStepFailure bool `json:"runStepFailure,omitempty"`
+}
+
+// Reads the records written by a recorder.
+func ReadRecords(r io.Reader) ([]ReconcileRecord, error) {
+	var result []ReconcileRecord
+	decoder := json.NewDecoder(bufio.NewReader(r))
+	for decoder.More() {
+		var record ReconcileRecord
+		err := decoder.Decode(&record)
+		if err != nil {
+			return nil, fmt.Errorf("reading record %d: %v", len(result)+1, err)
+		}
+		result = append(result, record)
+	}
+	return result, nil
+}
+
+// Records the inputs and outputs of each reconcile.
+//
+// Only accessed while the reconciler holds its mutex.
+type recorder struct {
+	// If empty, records are kept in memory but not written.
+	dir string
+
+	current *ReconcileRecord
+	last    *ReconcileRecord
+}
+
+func newRecorder(dir string) *recorder {
+	return &recorder{dir: dir}
+}
+
+func (r *recorder) start(name string, startedTime metav1.MicroTime) {
+	if r == nil {
+		return
+	}
+	r.current = &ReconcileRecord{
+		Name:        name,
+		Time:        apis.NowMicro(),
+		StartedTime: startedTime,
+	}
+}
+
+func (r *recorder) recordObject(obj ctrlclient.Object) {
+	if r == nil || r.current == nil {
+		return
+	}
+
+	switch obj := obj.(type) {
+	case *v1alpha1.LiveUpdate:
+		r.current.LiveUpdate = obj.DeepCopy()
+		r.current.Status = *obj.Status.DeepCopy()
+	case *v1alpha1.FileWatch:
+		r.current.FileWatches = append(r.current.FileWatches, *obj.DeepCopy())
+	case *v1alpha1.ImageMap:
+		r.current.ImageMaps = append(r.current.ImageMaps, *obj.DeepCopy())
+	case *v1alpha1.KubernetesApply:
+		r.current.KubernetesApplys = append(r.current.KubernetesApplys, *obj.DeepCopy())
+	case *v1alpha1.KubernetesDiscovery:
+		r.current.KubernetesDiscoverys = append(r.current.KubernetesDiscoverys, *obj.DeepCopy())
+	case *v1alpha1.DockerComposeService:
+		r.current.DockerComposeServices = append(r.current.DockerComposeServices, *obj.DeepCopy())
+	case *v1alpha1.ConfigMap:
+		r.current.ConfigMaps = append(r.current.ConfigMaps, *obj.DeepCopy())
+	}
+}
+
+func (r *recorder) recordNotFound(obj ctrlclient.Object, name string) {
+	if r == nil || r.current == nil {
+		return
+	}
+
+	kind := ""
+	switch obj.(type) {
+	case *v1alpha1.LiveUpdate:
+		kind = "LiveUpdate"
+	case *v1alpha1.FileWatch:
+		kind = "FileWatch"
+	case *v1alpha1.ImageMap:
+		kind = "ImageMap"
+	case *v1alpha1.KubernetesApply:
+		kind = "KubernetesApply"
+	case *v1alpha1.KubernetesDiscovery:
+		kind = "KubernetesDiscovery"
+	case *v1alpha1.DockerComposeService:
+		kind = "DockerComposeService"
+	case *v1alpha1.ConfigMap:
+		kind = "ConfigMap"
+	default:
+		return
+	}
+	r.current.NotFound = append(r.current.NotFound, RecordedRef{Kind: kind, Name: name})
+}
+
+func (r *recorder) recordUpdate(c liveupdates.Container, toArchive, toRemove []build.PathMapping,
+	cmds []model.Cmd, hotReload bool, err error) {
+	if r == nil || r.current == nil {
+		return
+	}
+
+	update := RecordedUpdate{
+		ContainerName: c.ContainerName.String(),
+		ContainerID:   c.ContainerID.String(),
+		PodName:       c.PodID.String(),
+		Namespace:     string(c.Namespace),
+		Deleted:       build.PathMappingsToContainerPaths(toRemove),
+		HotReload:     hotReload,
+	}
+	for _, pm := range toArchive {
+		update.Copied = append(update.Copied, pm.ContainerPath)
+	}
+	for _, cmd := range cmds {
+		update.Cmds = append(update.Cmds, cmd.String())
+	}
+	if err != nil {
+		update.Error = err.Error()
+		update.RunStepFailure = build.IsRunStepFailure(err)
+	}
+	r.current.Updates = append(r.current.Updates, update)
+}
+
+func (r *recorder) recordStatus(obj ctrlclient.Object) {
+	if r == nil || r.current == nil {
+		return
+	}
+
+	lu, ok := obj.(*v1alpha1.LiveUpdate)
+	if ok && lu.Name == r.current.Name {
+		r.current.Status = *lu.Status.DeepCopy()
+	}
+}
+
+// Write out the record of the current reconcile.
+func (r *recorder) finish(ctx context.Context) {
+	if r == nil || r.current == nil {
+		return
+	}
+
+	record := r.current
+	r.current = nil
+	r.last = record
+
+	if r.dir == "" {
+		return
+	}
+
+	err := r.write(record)
+	if err != nil {
+		logger.Get(ctx).Debugf("Recording LiveUpdate %s: %v", record.Name, err)
+	}
+}
+
+func (r *recorder) write(record *ReconcileRecord) error {
+	err := os.MkdirAll(r.dir, 0755)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(filepath.Join(r.dir, fmt.Sprintf("%s.jsonl", record.Name)),
+		os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	return json.NewEncoder(f).Encode(record)
+}
+
+// A client that records every object the reconciler reads.
+type recordingClient struct {
+	ctrlclient.Client
+	recorder *recorder
+}
+
+func (c recordingClient) Get(ctx context.Context, key ctrlclient.ObjectKey, obj ctrlclient.Object) error {
+	err := c.Client.Get(ctx, key, obj)
+	if err == nil {
+		c.recorder.recordObject(obj)
+	} else if apierrors.IsNotFound(err) {
+		c.recorder.recordNotFound(obj, key.Name)
+	}
+	return err
+}
+
+func (c recordingClient) Status() ctrlclient.StatusWriter {
+	return recordingStatusWriter{StatusWriter: c.Client.Status(), recorder: c.recorder}
+}
+
+// Records the status that the reconciler writes back.
+type recordingStatusWriter struct {
+	ctrlclient.StatusWriter
+	recorder *recorder
+}
+
+func (w recordingStatusWriter) Update(ctx context.Context, obj ctrlclient.Object, opts ...ctrlclient.UpdateOption) error {
+	err := w.StatusWriter.Update(ctx, obj, opts...)
+	if err == nil {
+		w.recorder.recordStatus(obj)
+	}
+	return err
+}
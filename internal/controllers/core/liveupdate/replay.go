@@ -0,0 +1,223 @@
+package liveupdate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/tilt-dev/tilt/internal/build"
+	"github.com/tilt-dev/tilt/internal/containerupdate"
+	"github.com/tilt-dev/tilt/internal/controllers/apicmp"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+// The outcome of replaying one recorded reconcile.
+type ReplayResult struct {
+	// The container updates that the replay ran.
+	Updates []RecordedUpdate
+
+	// The LiveUpdate status after the replay.
+	Status v1alpha1.LiveUpdateStatus
+
+	// Whether the replay did the same thing as the recorded reconcile.
+	//
+	// Ignores failure transition times, which depend on the wall clock.
+	Matches bool
+}
+
+// Replays recorded reconciles against a fresh reconciler, in isolation.
+//
+// Before each reconcile, the recorded inputs are loaded into an in-memory
+// client. Container updates go to a fake updater, which returns the
+// recorded errors. Files to sync are read from the local filesystem,
+// like a real sync would.
+//
+// Logs from the reconciler are written to out.
+func Replay(ctx context.Context, records []ReconcileRecord, out io.Writer) ([]ReplayResult, error) {
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	client := fake.NewClientBuilder().WithScheme(v1alpha1.NewScheme()).Build()
+	cu := &containerupdate.FakeContainerUpdater{}
+	r := NewFakeReconciler(&replayStore{out: out}, cu, client)
+	r.startedTime = records[0].StartedTime
+	r.enableRecording("")
+
+	results := make([]ReplayResult, 0, len(records))
+	for i, record := range records {
+		err := loadRecord(ctx, client, record)
+		if err != nil {
+			return nil, fmt.Errorf("loading record %d: %v", i+1, err)
+		}
+
+		cu.UpdateErrs = nil
+		for _, u := range record.Updates {
+			cu.UpdateErrs = append(cu.UpdateErrs, u.err())
+		}
+
+		_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: record.Name}})
+		if err != nil {
+			return nil, fmt.Errorf("replaying record %d: %v", i+1, err)
+		}
+
+		replayed := r.recorder.last
+		results = append(results, ReplayResult{
+			Updates: replayed.Updates,
+			Status:  replayed.Status,
+			Matches: apicmp.DeepEqual(record.Updates, replayed.Updates) &&
+				apicmp.DeepEqual(normalizeStatus(record.Status), normalizeStatus(replayed.Status)),
+		})
+	}
+	return results, nil
+}
+
+// Re-create the error that the container updater returned.
+func (u RecordedUpdate) err() error {
+	if u.Error == "" {
+		return nil
+	}
+	err := errors.New(u.Error)
+	if u.RunStepFailure {
+		return build.NewRunStepFailure(err)
+	}
+	return err
+}
+
+func normalizeStatus(status v1alpha1.LiveUpdateStatus) v1alpha1.LiveUpdateStatus {
+	result := *status.DeepCopy()
+	if result.Failed != nil {
+		result.Failed.LastTransitionTime.Reset()
+	}
+	return result
+}
+
+// Make the client's objects match the inputs of the record.
+func loadRecord(ctx context.Context, client ctrlclient.Client, record ReconcileRecord) error {
+	var objs []ctrlclient.Object
+	for i := range record.FileWatches {
+		objs = append(objs, &record.FileWatches[i])
+	}
+	for i := range record.ImageMaps {
+		objs = append(objs, &record.ImageMaps[i])
+	}
+	for i := range record.KubernetesApplys {
+		objs = append(objs, &record.KubernetesApplys[i])
+	}
+	for i := range record.KubernetesDiscoverys {
+		objs = append(objs, &record.KubernetesDiscoverys[i])
+	}
+	for i := range record.DockerComposeServices {
+		objs = append(objs, &record.DockerComposeServices[i])
+	}
+	for i := range record.ConfigMaps {
+		objs = append(objs, &record.ConfigMaps[i])
+	}
+
+	for _, obj := range objs {
+		err := upsertRecordedObject(ctx, client, obj.DeepCopyObject().(ctrlclient.Object))
+		if err != nil {
+			return err
+		}
+	}
+
+	if record.LiveUpdate != nil {
+		// The reconciler owns the status, so keep the status from the
+		// previous replay rather than the recorded one.
+		lu := record.LiveUpdate.DeepCopy()
+		var existing v1alpha1.LiveUpdate
+		err := client.Get(ctx, types.NamespacedName{Name: lu.Name}, &existing)
+		if err == nil {
+			lu.Status = existing.Status
+		}
+		err = upsertRecordedObject(ctx, client, lu)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, ref := range record.NotFound {
+		obj, err := newObjectOfKind(ref.Kind)
+		if err != nil {
+			return err
+		}
+		obj.SetName(ref.Name)
+		err = client.Delete(ctx, obj)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func upsertRecordedObject(ctx context.Context, client ctrlclient.Client, obj ctrlclient.Object) error {
+	obj.SetResourceVersion("")
+
+	existing := obj.DeepCopyObject().(ctrlclient.Object)
+	err := client.Get(ctx, types.NamespacedName{Name: obj.GetName()}, existing)
+	if apierrors.IsNotFound(err) {
+		return client.Create(ctx, obj)
+	} else if err != nil {
+		return err
+	}
+
+	obj.SetResourceVersion(existing.GetResourceVersion())
+	return client.Update(ctx, obj)
+}
+
+func newObjectOfKind(kind string) (ctrlclient.Object, error) {
+	switch kind {
+	case "LiveUpdate":
+		return &v1alpha1.LiveUpdate{}, nil
+	case "FileWatch":
+		return &v1alpha1.FileWatch{}, nil
+	case "ImageMap":
+		return &v1alpha1.ImageMap{}, nil
+	case "KubernetesApply":
+		return &v1alpha1.KubernetesApply{}, nil
+	case "KubernetesDiscovery":
+		return &v1alpha1.KubernetesDiscovery{}, nil
+	case "DockerComposeService":
+		return &v1alpha1.DockerComposeService{}, nil
+	case "ConfigMap":
+		return &v1alpha1.ConfigMap{}, nil
+	}
+	return nil, fmt.Errorf("unknown kind %q", kind)
+}
+
+// A store that only prints logs.
+type replayStore struct {
+	out   io.Writer
+	mu    sync.RWMutex
+	state store.EngineState
+}
+
+var _ store.RStore = &replayStore{}
+
+func (s *replayStore) Dispatch(action store.Action) {
+	if action, ok := action.(store.LogAction); ok {
+		_, _ = s.out.Write(action.Message())
+	}
+}
+
+func (s *replayStore) RLockState() store.EngineState {
+	s.mu.RLock()
+	return s.state
+}
+
+func (s *replayStore) RUnlockState() {
+	s.mu.RUnlock()
+}
+
+func (s *replayStore) StateMutex() *sync.RWMutex {
+	return &s.mu
+}
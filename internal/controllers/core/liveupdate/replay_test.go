@@ -0,0 +1,71 @@
+package liveupdate
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/tilt-dev/tilt/internal/build"
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+	"github.com/tilt-dev/tilt/pkg/apis"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	f := newFixture(t)
+	tmpDir := tempdir.NewTempDirFixture(t)
+	f.r.enableRecording(tmpDir.Path())
+
+	p, _ := os.Getwd()
+	nowMicro := apis.NowMicro()
+	txtPath := filepath.Join(p, "a.txt")
+
+	f.setupFrontend()
+
+	f.addFileEvent("frontend-fw", txtPath, metav1.MicroTime{Time: nowMicro.Add(time.Second)})
+	f.MustReconcile(types.NamespacedName{Name: "frontend-liveupdate"})
+
+	f.cu.SetUpdateErr(build.NewRunStepFailure(errors.New("compilation failed")))
+	f.addFileEvent("frontend-fw", txtPath, metav1.MicroTime{Time: nowMicro.Add(2 * time.Second)})
+	f.MustReconcile(types.NamespacedName{Name: "frontend-liveupdate"})
+	require.Equal(t, 2, len(f.cu.Calls))
+
+	file, err := os.Open(filepath.Join(tmpDir.Path(), "frontend-liveupdate.jsonl"))
+	require.NoError(t, err)
+	defer func() {
+		_ = file.Close()
+	}()
+
+	records, err := ReadRecords(file)
+	require.NoError(t, err)
+	require.True(t, len(records) >= 3)
+
+	results, err := Replay(f.Context(), records, io.Discard)
+	require.NoError(t, err)
+	require.Equal(t, len(records), len(results))
+
+	var updates []RecordedUpdate
+	for i, result := range results {
+		assert.True(t, result.Matches, "record %d did not match", i+1)
+		updates = append(updates, result.Updates...)
+	}
+	if assert.Equal(t, 2, len(updates)) {
+		// a.txt doesn't exist, so it's deleted from the container.
+		assert.Equal(t, []string{"/app/a.txt"}, updates[0].Deleted)
+		assert.Equal(t, "", updates[0].Error)
+		assert.Equal(t, "compilation failed", updates[1].Error)
+		assert.True(t, updates[1].RunStepFailure)
+	}
+
+	last := results[len(results)-1].Status
+	if assert.Equal(t, 1, len(last.Containers)) {
+		assert.Equal(t, "compilation failed", last.Containers[0].LastExecError)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/tilt-dev/tilt/internal/store/k8sconv"
 	"github.com/tilt-dev/tilt/pkg/apis"
 	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model"
 )
 
 // Helper interface for live-updating different kinds of resources.
@@ -63,6 +64,16 @@ func (r *luK8sResource) visitSelectedContainers(
 	}
 }
 
+// The command that a resource runs to reload itself after a live update,
+// if it has one.
+func restartCmd(r luResource) model.Cmd {
+	dc, ok := r.(*luDCResource)
+	if !ok {
+		return model.Cmd{}
+	}
+	return model.ToUnixCmd(dc.res.Spec.Labels[dockercompose.LabelRestartCmd])
+}
+
 // We model the DockerCompose resource as a single-container pod with a
 // name equal to the container id.
 type luDCResource struct {
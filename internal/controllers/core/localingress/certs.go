@@ -0,0 +1,332 @@
+package localingress
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tilt-dev/tilt/internal/xdg"
+)
+
+// The CA lives in the state dir, so that users only need to trust it once,
+// e.g., ~/.local/state/tilt-dev/localingress/ca.crt
+const (
+	certsDir   = "localingress"
+	caCertFile = "ca.crt"
+	caKeyFile  = "ca.key"
+)
+
+const caValidity = 10 * 365 * 24 * time.Hour
+
+// Extra domains (comma-separated) that the CA may sign certificates for,
+// besides localhost and its subdomains, e.g., "test,myapp.internal".
+//
+// Only read when the CA is created, because the domains are baked into it.
+const domainsEnvVar = "TILT_LOCAL_INGRESS_DOMAINS"
+
+// Browsers reject leaf certificates that are valid for more than ~13 months.
+const leafValidity = 365 * 24 * time.Hour
+
+// Issues self-signed certificates for LocalIngress hostnames.
+//
+// All certificates are signed by a local CA, which is created on first use.
+// Users trust the CA, so it has name constraints: it can only sign
+// certificates for local domains, and never for public sites.
+type certStore struct {
+	base    xdg.Base
+	domains []string
+
+	mu     sync.Mutex
+	ca     *x509.Certificate
+	caKey  crypto.Signer
+	caPath string
+	leaves map[string]*tls.Certificate
+}
+
+func newCertStore(base xdg.Base, extraDomains []string) *certStore {
+	domains := []string{"localhost"}
+	for _, d := range extraDomains {
+		d = strings.ToLower(strings.Trim(strings.TrimSpace(d), "."))
+		if d != "" && d != "localhost" {
+			domains = append(domains, d)
+		}
+	}
+	return &certStore{
+		base:    base,
+		domains: domains,
+		leaves:  make(map[string]*tls.Certificate),
+	}
+}
+
+func domainsFromEnv() []string {
+	v := os.Getenv(domainsEnvVar)
+	if v == "" {
+		return nil
+	}
+	return strings.Split(v, ",")
+}
+
+// Returns the path to the CA certificate, creating the CA if necessary.
+func (s *certStore) CACertPath() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := s.loadCA()
+	if err != nil {
+		return "", err
+	}
+	return s.caPath, nil
+}
+
+// Returns an error if the CA can't sign certificates for the host.
+func (s *certStore) CheckHost(host string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := s.loadCA()
+	if err != nil {
+		return err
+	}
+	return s.checkHost(host)
+}
+
+// Must be called with the lock held, after loading the CA.
+func (s *certStore) checkHost(host string) error {
+	if permitsHost(s.ca.PermittedDNSDomains, host) {
+		return nil
+	}
+	return fmt.Errorf("the local certificate authority (%s) can only sign certificates for %s, not %s. "+
+		"To serve other domains, add them to %s, then delete the certificate authority "+
+		"so that Tilt creates a new one (and trust the new one)",
+		s.caPath, strings.Join(s.ca.PermittedDNSDomains, ", "), host, domainsEnvVar)
+}
+
+// Issues a certificate for the host.
+//
+// The caller must check that the host is one we serve.
+func (s *certStore) Certificate(host string) (*tls.Certificate, error) {
+	host = strings.ToLower(host)
+	if host == "" {
+		return nil, fmt.Errorf("missing server name (SNI)")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	leaf, ok := s.leaves[host]
+	if ok && time.Now().Before(leaf.Leaf.NotAfter) {
+		return leaf, nil
+	}
+
+	err := s.loadCA()
+	if err != nil {
+		return nil, err
+	}
+	err = s.checkHost(host)
+	if err != nil {
+		return nil, err
+	}
+
+	leaf, err = s.issue(host)
+	if err != nil {
+		return nil, fmt.Errorf("issuing certificate for %s: %v", host, err)
+	}
+	s.leaves[host] = leaf
+	return leaf, nil
+}
+
+// Must be called with the lock held.
+func (s *certStore) loadCA() error {
+	if s.ca != nil {
+		return nil
+	}
+
+	certPath, err := s.base.StateFile(filepath.Join(certsDir, caCertFile))
+	if err != nil {
+		return err
+	}
+	keyPath, err := s.base.StateFile(filepath.Join(certsDir, caKeyFile))
+	if err != nil {
+		return err
+	}
+
+	// Only create a CA if there isn't one. If the user has already trusted
+	// a CA that we can't use, they need to know, so that they can remove it.
+	_, certErr := os.Stat(certPath)
+	_, keyErr := os.Stat(keyPath)
+	if os.IsNotExist(certErr) && os.IsNotExist(keyErr) {
+		return s.createCA(certPath, keyPath)
+	}
+
+	ca, key, err := loadCA(certPath, keyPath)
+	if err != nil {
+		return fmt.Errorf("loading local certificate authority: %v. "+
+			"Delete %s and %s (and stop trusting the old one) so that Tilt creates a new one",
+			err, certPath, keyPath)
+	}
+	s.ca = ca
+	s.caKey = key
+	s.caPath = certPath
+	return nil
+}
+
+func loadCA(certPath, keyPath string) (*x509.Certificate, crypto.Signer, error) {
+	pair, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	signer, ok := pair.PrivateKey.(crypto.Signer)
+	if !ok {
+		return nil, nil, fmt.Errorf("unsupported private key type %T", pair.PrivateKey)
+	}
+	if !time.Now().Before(cert.NotAfter) {
+		return nil, nil, fmt.Errorf("expired on %s", cert.NotAfter.Format(time.RFC3339))
+	}
+	if !cert.IsCA || !cert.PermittedDNSDomainsCritical || len(cert.PermittedDNSDomains) == 0 {
+		return nil, nil, fmt.Errorf("%s isn't limited to local domains", certPath)
+	}
+	return cert, signer, nil
+}
+
+// Must be called with the lock held.
+func (s *certStore) createCA(certPath, keyPath string) error {
+	cert, key, err := createCA(s.domains)
+	if err != nil {
+		return fmt.Errorf("creating local certificate authority: %v", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return err
+	}
+	err = writePEM(keyPath, "EC PRIVATE KEY", keyDER, 0600)
+	if err != nil {
+		return err
+	}
+	err = writePEM(certPath, "CERTIFICATE", cert.Raw, 0644)
+	if err != nil {
+		return err
+	}
+
+	s.ca = cert
+	s.caKey = key
+	s.caPath = certPath
+	return nil
+}
+
+func (s *certStore) issue(host string) (*tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: newSerialNumber(),
+		Subject:      pkix.Name{CommonName: host},
+		DNSNames:     []string{host},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(leafValidity),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, s.ca, key.Public(), s.caKey)
+	if err != nil {
+		return nil, err
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Certificate{
+		Certificate: [][]byte{der, s.ca.Raw},
+		PrivateKey:  key,
+		Leaf:        leaf,
+	}, nil
+}
+
+func createCA(domains []string) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          newSerialNumber(),
+		Subject:               pkix.Name{CommonName: "Tilt Local Development CA", Organization: []string{"Tilt"}},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(caValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+
+		// Browsers reject certificates that this CA signs for any other domain.
+		PermittedDNSDomainsCritical: true,
+		PermittedDNSDomains:         domains,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		return nil, nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cert, key, nil
+}
+
+// Whether the host is one of the domains, or a subdomain of one,
+// following the rules for X.509 name constraints.
+func permitsHost(domains []string, host string) bool {
+	host = strings.ToLower(host)
+	for _, d := range domains {
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+func newSerialNumber() *big.Int {
+	limit := new(big.Int).Lsh(big.NewInt(1), 128)
+	n, err := rand.Int(rand.Reader, limit)
+	if err != nil {
+		return big.NewInt(time.Now().UnixNano())
+	}
+	return n
+}
+
+// Never replaces an existing file.
+func writePEM(path, blockType string, der []byte, perm os.FileMode) error {
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err == nil {
+		_, err = f.Write(data)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("writing %s: %v", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,291 @@
+package localingress
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/tilt-dev/tilt/internal/controllers/apicmp"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/internal/xdg"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+// Serves each LocalIngress over HTTPS, and proxies its requests
+// to the port forwards of Tilt resources.
+//
+// All the LocalIngresses on a port share one server, which routes
+// by hostname. We resolve the port forward on every request, so the
+// URL keeps working when a pod restarts and its forward moves.
+type Reconciler struct {
+	ctrlClient ctrlclient.Client
+	certs      *certStore
+
+	mu        sync.Mutex
+	ingresses map[types.NamespacedName]*v1alpha1.LocalIngress
+	servers   map[int32]*server
+
+	// Why we can't serve a LocalIngress (e.g., its port is in use).
+	errors map[types.NamespacedName]string
+}
+
+var _ reconcile.Reconciler = &Reconciler{}
+var _ store.TearDowner = &Reconciler{}
+
+func NewReconciler(ctrlClient ctrlclient.Client, base xdg.Base) *Reconciler {
+	return &Reconciler{
+		ctrlClient: ctrlClient,
+		certs:      newCertStore(base, domainsFromEnv()),
+		ingresses:  make(map[types.NamespacedName]*v1alpha1.LocalIngress),
+		servers:    make(map[int32]*server),
+		errors:     make(map[types.NamespacedName]string),
+	}
+}
+
+func (r *Reconciler) CreateBuilder(mgr ctrl.Manager) (*builder.Builder, error) {
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.LocalIngress{}).
+		Watches(&source.Kind{Type: &v1alpha1.PortForward{}},
+			handler.EnqueueRequestsFromMapFunc(r.enqueueForPortForward))
+
+	return b, nil
+}
+
+func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	nn := request.NamespacedName
+
+	var li v1alpha1.LocalIngress
+	err := r.ctrlClient.Get(ctx, nn, &li)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return ctrl.Result{}, err
+	}
+
+	if apierrors.IsNotFound(err) || !li.ObjectMeta.DeletionTimestamp.IsZero() {
+		delete(r.ingresses, nn)
+		r.syncServers(ctx)
+		return ctrl.Result{}, nil
+	}
+
+	r.ingresses[nn] = li.DeepCopy()
+	r.syncServers(ctx)
+
+	status := r.status(ctx, nn, &li)
+	if !apicmp.DeepEqual(li.Status, status) {
+		update := li.DeepCopy()
+		update.Status = status
+		err := r.ctrlClient.Status().Update(ctx, update)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	return ctrl.Result{}, nil
+}
+
+func (r *Reconciler) TearDown(_ context.Context) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for port, s := range r.servers {
+		s.Close()
+		delete(r.servers, port)
+	}
+}
+
+// Starts a server for every port in use, stops servers that
+// are no longer needed, and updates their routing tables.
+//
+// Must be called with the lock held.
+func (r *Reconciler) syncServers(ctx context.Context) {
+	// Sort by name, so that if two LocalIngresses claim the same
+	// host, the winner is stable.
+	names := make([]types.NamespacedName, 0, len(r.ingresses))
+	for nn := range r.ingresses {
+		names = append(names, nn)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return names[i].String() < names[j].String()
+	})
+
+	errors := make(map[types.NamespacedName]string)
+	routesByPort := make(map[int32]map[string][]v1alpha1.LocalIngressRoute)
+	owners := make(map[int32]map[string]string)
+	for _, nn := range names {
+		spec := r.ingresses[nn].Spec
+		port := listenPort(spec)
+		if routesByPort[port] == nil {
+			routesByPort[port] = make(map[string][]v1alpha1.LocalIngressRoute)
+			owners[port] = make(map[string]string)
+		}
+
+		owner, ok := owners[port][spec.Host]
+		if ok {
+			errors[nn] = fmt.Sprintf("host %s on port %d is already served by LocalIngress %s", spec.Host, port, owner)
+			continue
+		}
+		owners[port][spec.Host] = nn.Name
+		routesByPort[port][spec.Host] = spec.Routes
+	}
+
+	for port, s := range r.servers {
+		if _, ok := routesByPort[port]; !ok {
+			s.Close()
+			delete(r.servers, port)
+		}
+	}
+
+	for port, hosts := range routesByPort {
+		s, ok := r.servers[port]
+		if !ok {
+			var err error
+			s, err = startServer(ctx, port, r.certs, r.resolve)
+			if err != nil {
+				for _, nn := range names {
+					if listenPort(r.ingresses[nn].Spec) == port {
+						errors[nn] = err.Error()
+					}
+				}
+				continue
+			}
+			r.servers[port] = s
+		}
+		s.SetHosts(hosts)
+	}
+
+	r.errors = errors
+}
+
+// Must be called with the lock held.
+func (r *Reconciler) status(ctx context.Context, nn types.NamespacedName, li *v1alpha1.LocalIngress) v1alpha1.LocalIngressStatus {
+	status := v1alpha1.LocalIngressStatus{
+		URL:   fmt.Sprintf("https://%s:%d/", li.Spec.Host, listenPort(li.Spec)),
+		Error: r.errors[nn],
+	}
+
+	caPath, err := r.certs.CACertPath()
+	if err == nil {
+		err = r.certs.CheckHost(li.Spec.Host)
+	}
+	if err != nil && status.Error == "" {
+		status.Error = err.Error()
+	}
+	status.CACertPath = caPath
+
+	for _, route := range li.Spec.Routes {
+		rs := v1alpha1.LocalIngressRouteStatus{Path: routePath(route)}
+		target, err := r.resolve(ctx, route)
+		if err != nil {
+			rs.Error = err.Error()
+		} else {
+			rs.Target = target
+		}
+		status.Routes = append(status.Routes, rs)
+	}
+	return status
+}
+
+func (r *Reconciler) resolve(ctx context.Context, route v1alpha1.LocalIngressRoute) (string, error) {
+	if route.LocalPort != 0 {
+		return fmt.Sprintf("localhost:%d", route.LocalPort), nil
+	}
+
+	var list v1alpha1.PortForwardList
+	err := r.ctrlClient.List(ctx, &list)
+	if err != nil {
+		return "", err
+	}
+	return pickForward(list.Items, route)
+}
+
+// Finds the local address of the resource's port forward.
+//
+// If the resource has more than one PortForward (e.g., while a new pod
+// is rolling out), prefer the newest one that's ready.
+func pickForward(pfs []v1alpha1.PortForward, route v1alpha1.LocalIngressRoute) (string, error) {
+	var candidates []v1alpha1.PortForward
+	for _, pf := range pfs {
+		if pf.Annotations[v1alpha1.AnnotationManifest] == route.Resource && pf.DeletionTimestamp == nil {
+			candidates = append(candidates, pf)
+		}
+	}
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("resource %s has no port forwards", route.Resource)
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		ti, tj := candidates[i].CreationTimestamp, candidates[j].CreationTimestamp
+		if !ti.Equal(&tj) {
+			return tj.Before(&ti)
+		}
+		return candidates[i].Name < candidates[j].Name
+	})
+
+	for _, pf := range candidates {
+		for _, fs := range pf.Status.ForwardStatuses {
+			if route.ContainerPort != 0 && fs.ContainerPort != route.ContainerPort {
+				continue
+			}
+			if fs.LocalPort == 0 || fs.Error != "" {
+				continue
+			}
+			if fs.State != "" && fs.State != v1alpha1.ForwardStateReady {
+				continue
+			}
+			return fmt.Sprintf("localhost:%d", fs.LocalPort), nil
+		}
+	}
+
+	if route.ContainerPort != 0 {
+		return "", fmt.Errorf("resource %s has no ready port forward to container port %d", route.Resource, route.ContainerPort)
+	}
+	return "", fmt.Errorf("resource %s has no ready port forward", route.Resource)
+}
+
+// Re-resolves the routes of every LocalIngress that sends requests
+// to the port forward's resource.
+func (r *Reconciler) enqueueForPortForward(obj ctrlclient.Object) []reconcile.Request {
+	resource := obj.GetAnnotations()[v1alpha1.AnnotationManifest]
+	if resource == "" {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var result []reconcile.Request
+	for nn, li := range r.ingresses {
+		for _, route := range li.Spec.Routes {
+			if route.Resource == resource {
+				result = append(result, reconcile.Request{NamespacedName: nn})
+				break
+			}
+		}
+	}
+	return result
+}
+
+func listenPort(spec v1alpha1.LocalIngressSpec) int32 {
+	if spec.Port == 0 {
+		return v1alpha1.LocalIngressDefaultPort
+	}
+	return spec.Port
+}
+
+func routePath(route v1alpha1.LocalIngressRoute) string {
+	if route.Path == "" {
+		return "/"
+	}
+	return route.Path
+}
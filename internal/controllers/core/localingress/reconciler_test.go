@@ -0,0 +1,410 @@
+package localingress
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/tilt-dev/tilt/internal/controllers/fake"
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+	"github.com/tilt-dev/tilt/internal/xdg"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+func TestProxyToLocalPort(t *testing.T) {
+	f := newFixture(t)
+	backend := f.backend("hello from backend")
+
+	li := f.ingress("myapp.localhost", v1alpha1.LocalIngressRoute{Path: "/", LocalPort: backend})
+	f.Create(li)
+
+	status := f.status(li.Name)
+	assert.Equal(t, "", status.Error)
+	assert.Equal(t, fmt.Sprintf("https://myapp.localhost:%d/", f.port), status.URL)
+	assert.Equal(t, []v1alpha1.LocalIngressRouteStatus{
+		{Path: "/", Target: fmt.Sprintf("localhost:%d", backend)},
+	}, status.Routes)
+	assert.FileExists(t, status.CACertPath)
+
+	code, body := f.get(status.CACertPath, "myapp.localhost", "/index.html")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "hello from backend /index.html", body)
+}
+
+func TestProxyToPortForward(t *testing.T) {
+	f := newFixture(t)
+	backend := f.backend("hello from pod")
+
+	li := f.ingress("myapp.localhost", v1alpha1.LocalIngressRoute{Path: "/", Resource: "fe", ContainerPort: 8080})
+	f.Create(li)
+
+	status := f.status(li.Name)
+	assert.Equal(t, "resource fe has no port forwards", status.Routes[0].Error)
+
+	code, body := f.get(status.CACertPath, "myapp.localhost", "/")
+	assert.Equal(t, http.StatusBadGateway, code)
+	assert.Contains(t, body, "resource fe has no port forwards")
+
+	f.portForward("fe-pod-1", "fe", v1alpha1.ForwardStatus{
+		LocalPort:     backend,
+		ContainerPort: 8080,
+		State:         v1alpha1.ForwardStateReady,
+	})
+	f.MustReconcile(types.NamespacedName{Name: li.Name})
+
+	status = f.status(li.Name)
+	assert.Equal(t, fmt.Sprintf("localhost:%d", backend), status.Routes[0].Target)
+
+	code, body = f.get(status.CACertPath, "myapp.localhost", "/")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "hello from pod /", body)
+}
+
+func TestRouteByPath(t *testing.T) {
+	f := newFixture(t)
+	web := f.backend("web")
+	api := f.backend("api")
+
+	li := f.ingress("myapp.localhost",
+		v1alpha1.LocalIngressRoute{Path: "/", LocalPort: web},
+		v1alpha1.LocalIngressRoute{Path: "/api", LocalPort: api})
+	f.Create(li)
+	caPath := f.status(li.Name).CACertPath
+
+	_, body := f.get(caPath, "myapp.localhost", "/api/users")
+	assert.Equal(t, "api /api/users", body)
+
+	_, body = f.get(caPath, "myapp.localhost", "/apis")
+	assert.Equal(t, "web /apis", body)
+}
+
+func TestHostConflict(t *testing.T) {
+	f := newFixture(t)
+	backend := f.backend("hello")
+
+	a := f.ingress("myapp.localhost", v1alpha1.LocalIngressRoute{Path: "/", LocalPort: backend})
+	a.Name = "a"
+	f.Create(a)
+
+	b := f.ingress("myapp.localhost", v1alpha1.LocalIngressRoute{Path: "/", LocalPort: backend})
+	b.Name = "b"
+	f.Create(b)
+
+	assert.Equal(t, "", f.status("a").Error)
+	assert.Equal(t,
+		fmt.Sprintf("host myapp.localhost on port %d is already served by LocalIngress a", f.port),
+		f.status("b").Error)
+}
+
+func TestPortInUse(t *testing.T) {
+	f := newFixture(t)
+	l, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", f.port))
+	require.NoError(t, err)
+	defer func() { _ = l.Close() }()
+
+	li := f.ingress("myapp.localhost", v1alpha1.LocalIngressRoute{Path: "/", LocalPort: 8080})
+	f.Create(li)
+
+	assert.Contains(t, f.status(li.Name).Error, fmt.Sprintf("listening on port %d", f.port))
+}
+
+func TestDeleteStopsServer(t *testing.T) {
+	f := newFixture(t)
+	li := f.ingress("myapp.localhost", v1alpha1.LocalIngressRoute{Path: "/", LocalPort: 8080})
+	f.Create(li)
+	assert.Len(t, f.r.servers, 1)
+
+	f.Delete(li)
+	assert.Len(t, f.r.servers, 0)
+
+	// The port should be free again.
+	l, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", f.port))
+	require.NoError(t, err)
+	_ = l.Close()
+}
+
+func TestPickForwardPrefersNewestReady(t *testing.T) {
+	now := metav1.Now()
+	older := metav1.NewTime(now.Add(-60e9))
+	pfs := []v1alpha1.PortForward{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "fe-old",
+				CreationTimestamp: older,
+				Annotations:       map[string]string{v1alpha1.AnnotationManifest: "fe"},
+			},
+			Status: v1alpha1.PortForwardStatus{ForwardStatuses: []v1alpha1.ForwardStatus{
+				{LocalPort: 1000, ContainerPort: 80, State: v1alpha1.ForwardStateReady},
+			}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "fe-new",
+				CreationTimestamp: now,
+				Annotations:       map[string]string{v1alpha1.AnnotationManifest: "fe"},
+			},
+			Status: v1alpha1.PortForwardStatus{ForwardStatuses: []v1alpha1.ForwardStatus{
+				{LocalPort: 2000, ContainerPort: 80, State: v1alpha1.ForwardStateReady},
+				{LocalPort: 3000, ContainerPort: 9000, State: v1alpha1.ForwardStateReady},
+			}},
+		},
+	}
+
+	target, err := pickForward(pfs, v1alpha1.LocalIngressRoute{Resource: "fe"})
+	require.NoError(t, err)
+	assert.Equal(t, "localhost:2000", target)
+
+	target, err = pickForward(pfs, v1alpha1.LocalIngressRoute{Resource: "fe", ContainerPort: 9000})
+	require.NoError(t, err)
+	assert.Equal(t, "localhost:3000", target)
+
+	// If the new forward isn't connected yet, fall back to the old one.
+	pfs[1].Status.ForwardStatuses[0].State = v1alpha1.ForwardStateConnecting
+	target, err = pickForward(pfs, v1alpha1.LocalIngressRoute{Resource: "fe", ContainerPort: 80})
+	require.NoError(t, err)
+	assert.Equal(t, "localhost:1000", target)
+
+	_, err = pickForward(pfs, v1alpha1.LocalIngressRoute{Resource: "fe", ContainerPort: 443})
+	assert.EqualError(t, err, "resource fe has no ready port forward to container port 443")
+}
+
+func TestMatchRoute(t *testing.T) {
+	routes := []v1alpha1.LocalIngressRoute{
+		{Path: "/api/v2/", LocalPort: 3},
+		{Path: "/api", LocalPort: 2},
+		{Path: "/", LocalPort: 1},
+	}
+
+	for _, tc := range []struct {
+		path     string
+		expected int32
+	}{
+		{"/", 1},
+		{"/index.html", 1},
+		{"/api", 2},
+		{"/api/users", 2},
+		{"/apis", 1},
+		{"/api/v2", 3},
+		{"/api/v2/users", 3},
+	} {
+		t.Run(tc.path, func(t *testing.T) {
+			route, ok := matchRoute(routes, tc.path)
+			require.True(t, ok)
+			assert.Equal(t, tc.expected, route.LocalPort)
+		})
+	}
+
+	_, ok := matchRoute(routes[:1], "/other")
+	assert.False(t, ok)
+}
+
+func TestCAIsReused(t *testing.T) {
+	tmpf := tempdir.NewTempDirFixture(t)
+	base := xdg.FakeBase{Dir: tmpf.Path()}
+
+	path, err := newCertStore(base, nil).CACertPath()
+	require.NoError(t, err)
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	path2, err := newCertStore(base, nil).CACertPath()
+	require.NoError(t, err)
+	contents2, err := os.ReadFile(path2)
+	require.NoError(t, err)
+
+	assert.Equal(t, path, path2)
+	assert.Equal(t, string(contents), string(contents2))
+}
+
+func TestCAHasNameConstraints(t *testing.T) {
+	tmpf := tempdir.NewTempDirFixture(t)
+	certs := newCertStore(xdg.FakeBase{Dir: tmpf.Path()}, []string{" .Test ", "localhost"})
+
+	path, err := certs.CACertPath()
+	require.NoError(t, err)
+	assert.True(t, certs.ca.PermittedDNSDomainsCritical)
+	assert.Equal(t, []string{"localhost", "test"}, certs.ca.PermittedDNSDomains)
+
+	pool := x509.NewCertPool()
+	caPEM, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.True(t, pool.AppendCertsFromPEM(caPEM))
+
+	for _, host := range []string{"localhost", "myapp.localhost", "myapp.test"} {
+		leaf, err := certs.Certificate(host)
+		require.NoError(t, err, host)
+		_, err = leaf.Leaf.Verify(x509.VerifyOptions{DNSName: host, Roots: pool})
+		assert.NoError(t, err, host)
+	}
+
+	for _, host := range []string{"example.com", "localhost.example.com", "notlocalhost"} {
+		_, err := certs.Certificate(host)
+		assert.ErrorContains(t, err, "can only sign certificates for localhost, test", host)
+	}
+}
+
+func TestCAIsNotReplaced(t *testing.T) {
+	tmpf := tempdir.NewTempDirFixture(t)
+	base := xdg.FakeBase{Dir: tmpf.Path()}
+	certPath, err := base.StateFile("localingress/ca.crt")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(certPath, []byte("garbage"), 0644))
+
+	_, err = newCertStore(base, nil).CACertPath()
+	assert.ErrorContains(t, err, "loading local certificate authority")
+
+	contents, err := os.ReadFile(certPath)
+	require.NoError(t, err)
+	assert.Equal(t, "garbage", string(contents))
+}
+
+func TestOnlyServesCertificatesForRoutedHosts(t *testing.T) {
+	f := newFixture(t)
+	backend := f.backend("hello from backend")
+
+	li := f.ingress("myapp.localhost", v1alpha1.LocalIngressRoute{Path: "/", LocalPort: backend})
+	f.Create(li)
+	status := f.status(li.Name)
+
+	conn, err := tls.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", f.port), &tls.Config{
+		ServerName:         "other.localhost",
+		InsecureSkipVerify: true,
+	})
+	if err == nil {
+		_ = conn.Close()
+	}
+	assert.Error(t, err)
+
+	code, _ := f.get(status.CACertPath, "myapp.localhost", "/")
+	assert.Equal(t, http.StatusOK, code)
+}
+
+func TestHostOutsideCADomains(t *testing.T) {
+	f := newFixture(t)
+	backend := f.backend("hello from backend")
+
+	li := f.ingress("myapp.example.com", v1alpha1.LocalIngressRoute{Path: "/", LocalPort: backend})
+	f.Create(li)
+
+	status := f.status(li.Name)
+	assert.Contains(t, status.Error, "can only sign certificates for localhost, not myapp.example.com")
+}
+
+type fixture struct {
+	*fake.ControllerFixture
+	r    *Reconciler
+	port int32
+}
+
+func newFixture(t *testing.T) *fixture {
+	cfb := fake.NewControllerFixtureBuilder(t)
+	tmpf := tempdir.NewTempDirFixture(t)
+	base := xdg.FakeBase{Dir: tmpf.Path()}
+
+	r := NewReconciler(cfb.Client, base)
+	t.Cleanup(func() { r.TearDown(context.Background()) })
+
+	return &fixture{
+		ControllerFixture: cfb.Build(r),
+		r:                 r,
+		port:              freePort(t),
+	}
+}
+
+func (f *fixture) ingress(host string, routes ...v1alpha1.LocalIngressRoute) *v1alpha1.LocalIngress {
+	return &v1alpha1.LocalIngress{
+		ObjectMeta: metav1.ObjectMeta{Name: host},
+		Spec: v1alpha1.LocalIngressSpec{
+			Host:   host,
+			Port:   f.port,
+			Routes: routes,
+		},
+	}
+}
+
+func (f *fixture) portForward(name, resource string, fs v1alpha1.ForwardStatus) {
+	pf := &v1alpha1.PortForward{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Annotations: map[string]string{v1alpha1.AnnotationManifest: resource},
+		},
+		Spec: v1alpha1.PortForwardSpec{
+			PodName:   name,
+			Forwards:  []v1alpha1.Forward{{ContainerPort: fs.ContainerPort}},
+			Namespace: "default",
+		},
+	}
+	require.NoError(f.T(), f.Client.Create(f.Context(), pf))
+
+	pf.Status.ForwardStatuses = []v1alpha1.ForwardStatus{fs}
+	require.NoError(f.T(), f.Client.Status().Update(f.Context(), pf))
+}
+
+func (f *fixture) status(name string) v1alpha1.LocalIngressStatus {
+	var li v1alpha1.LocalIngress
+	f.MustGet(types.NamespacedName{Name: name}, &li)
+	return li.Status
+}
+
+// Starts an HTTP server that echoes the request path.
+func (f *fixture) backend(greeting string) int32 {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, "%s %s", greeting, r.URL.Path)
+	}))
+	f.T().Cleanup(s.Close)
+
+	_, port, err := net.SplitHostPort(s.Listener.Addr().String())
+	require.NoError(f.T(), err)
+	p, err := strconv.Atoi(port)
+	require.NoError(f.T(), err)
+	return int32(p)
+}
+
+// Sends a request for the hostname to the LocalIngress server,
+// trusting only the LocalIngress CA.
+func (f *fixture) get(caPath, host, path string) (int, string) {
+	f.T().Helper()
+	caPEM, err := os.ReadFile(caPath)
+	require.NoError(f.T(), err)
+	pool := x509.NewCertPool()
+	require.True(f.T(), pool.AppendCertsFromPEM(caPEM))
+
+	addr := fmt.Sprintf("127.0.0.1:%d", f.port)
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: pool},
+			DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, network, addr)
+			},
+		},
+	}
+	defer client.CloseIdleConnections()
+
+	resp, err := client.Get(fmt.Sprintf("https://%s:%d%s", host, f.port, path))
+	require.NoError(f.T(), err)
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(f.T(), err)
+	return resp.StatusCode, string(body)
+}
+
+func freePort(t *testing.T) int32 {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = l.Close() }()
+	return int32(l.Addr().(*net.TCPAddr).Port)
+}
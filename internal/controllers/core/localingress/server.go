@@ -0,0 +1,175 @@
+package localingress
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+// Finds the address (e.g., localhost:53212) that a route should send requests to.
+type resolveFunc func(ctx context.Context, route v1alpha1.LocalIngressRoute) (string, error)
+
+// Serves HTTPS for all the LocalIngresses on one port, routing by hostname.
+type server struct {
+	resolve   resolveFunc
+	listeners []net.Listener
+	srv       *http.Server
+
+	mu sync.RWMutex
+
+	// Routes for each hostname, sorted from the longest path to the shortest.
+	hosts map[string][]v1alpha1.LocalIngressRoute
+}
+
+// Starts serving on localhost.
+//
+// Browsers may resolve *.localhost to either 127.0.0.1 or ::1,
+// so we listen on both if we can.
+func startServer(ctx context.Context, port int32, certs *certStore, resolve resolveFunc) (*server, error) {
+	s := &server{
+		resolve: resolve,
+		hosts:   make(map[string][]v1alpha1.LocalIngressRoute),
+	}
+
+	ipv4, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	if err != nil {
+		return nil, fmt.Errorf("listening on port %d: %v", port, err)
+	}
+	s.listeners = []net.Listener{ipv4}
+
+	ipv6, err := net.Listen("tcp", fmt.Sprintf("[::1]:%d", port))
+	if err == nil {
+		s.listeners = append(s.listeners, ipv6)
+	}
+
+	tlsConfig := &tls.Config{
+		GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			// Only issue certificates for the hosts we serve, so that a client
+			// can't get one for any name it likes.
+			host := strings.ToLower(hello.ServerName)
+			if !s.hasHost(host) {
+				return nil, fmt.Errorf("no LocalIngress for host %q", host)
+			}
+			return certs.Certificate(host)
+		},
+		MinVersion: tls.VersionTLS12,
+	}
+	s.srv = &http.Server{
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+		ErrorLog:          log.New(logger.Get(ctx).Writer(logger.DebugLvl), "", 0),
+	}
+	for _, l := range s.listeners {
+		go func(l net.Listener) {
+			err := s.srv.Serve(tls.NewListener(l, tlsConfig))
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logger.Get(ctx).Debugf("LocalIngress server on port %d stopped: %v", port, err)
+			}
+		}(l)
+	}
+	return s, nil
+}
+
+// Closes the listeners directly as well as the server, because
+// the server doesn't know about a listener until Serve starts.
+func (s *server) Close() {
+	_ = s.srv.Close()
+	for _, l := range s.listeners {
+		_ = l.Close()
+	}
+}
+
+// Replaces the routing table.
+func (s *server) SetHosts(hosts map[string][]v1alpha1.LocalIngressRoute) {
+	sorted := make(map[string][]v1alpha1.LocalIngressRoute, len(hosts))
+	for host, routes := range hosts {
+		routes = append([]v1alpha1.LocalIngressRoute{}, routes...)
+		sort.SliceStable(routes, func(i, j int) bool {
+			return len(routes[i].Path) > len(routes[j].Path)
+		})
+		sorted[strings.ToLower(host)] = routes
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hosts = sorted
+}
+
+func (s *server) hasHost(host string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.hosts[host]
+	return ok
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	host := req.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(host)
+
+	s.mu.RLock()
+	routes, ok := s.hosts[host]
+	s.mu.RUnlock()
+	if !ok {
+		http.Error(w, fmt.Sprintf("No LocalIngress for host %s", host), http.StatusNotFound)
+		return
+	}
+
+	route, ok := matchRoute(routes, req.URL.Path)
+	if !ok {
+		http.Error(w, fmt.Sprintf("No route for %s%s", host, req.URL.Path), http.StatusNotFound)
+		return
+	}
+
+	target, err := s.resolve(req.Context(), route)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	proxy := httputil.NewSingleHostReverseProxy(&url.URL{Scheme: "http", Host: target})
+	director := proxy.Director
+	proxy.Director = func(r *http.Request) {
+		director(r)
+		r.Header.Set("X-Forwarded-Host", req.Host)
+		r.Header.Set("X-Forwarded-Proto", "https")
+	}
+	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		http.Error(w, fmt.Sprintf("Error proxying to %s: %v", target, err), http.StatusBadGateway)
+	}
+	proxy.ServeHTTP(w, req)
+}
+
+// Finds the route with the longest path prefix that matches.
+// Routes must be sorted from the longest path to the shortest.
+//
+// A prefix only matches whole path segments, so /api matches
+// /api and /api/users, but not /apis.
+func matchRoute(routes []v1alpha1.LocalIngressRoute, path string) (v1alpha1.LocalIngressRoute, bool) {
+	for _, r := range routes {
+		prefix := r.Path
+		if prefix == "" || prefix == "/" {
+			return r, true
+		}
+		prefix = strings.TrimSuffix(prefix, "/")
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return r, true
+		}
+	}
+	return v1alpha1.LocalIngressRoute{}, false
+}
@@ -0,0 +1,282 @@
+package nestedtilt
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	errorutil "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/tilt-dev/tilt/internal/controllers/apicmp"
+	"github.com/tilt-dev/tilt/internal/controllers/indexer"
+	"github.com/tilt-dev/tilt/pkg/apis"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// The name of the link from each mirrored resource to the child Tilt.
+const childLinkName = "nested tilt"
+
+func cmdName(nn types.NamespacedName) types.NamespacedName {
+	return types.NamespacedName{Name: apis.SanitizeName(fmt.Sprintf("nestedtilt:%s", nn.Name))}
+}
+
+// Reconcile the Cmd that runs the child Tilt.
+func (r *Reconciler) manageOwnedCmd(ctx context.Context, nn types.NamespacedName, owner *v1alpha1.NestedTilt) error {
+	var childList v1alpha1.CmdList
+	err := indexer.ListOwnedBy(ctx, r.ctrlClient, &childList, nn, apiType)
+	if err != nil {
+		return fmt.Errorf("failed to fetch managed Cmd objects for NestedTilt %s: %v", nn.Name, err)
+	}
+
+	child, err := r.toDesiredCmd(owner)
+	if err != nil {
+		return fmt.Errorf("creating cmd: %v", err)
+	}
+
+	// Delete all the Cmds that don't match this one.
+	errs := []error{}
+	foundDesired := false
+	for _, existingChild := range childList.Items {
+		matches := child != nil && existingChild.Name == child.Name
+		if matches {
+			foundDesired = true
+
+			if apicmp.DeepEqual(child.Spec, existingChild.Spec) {
+				continue
+			}
+
+			updated := existingChild.DeepCopy()
+			updated.Spec = child.Spec
+			err := r.ctrlClient.Update(ctx, updated)
+			if err != nil && !apierrors.IsNotFound(err) {
+				errs = append(errs, fmt.Errorf("updating cmd %s: %v", existingChild.Name, err))
+			}
+			continue
+		}
+
+		deletedChild := existingChild.DeepCopy()
+		err := r.ctrlClient.Delete(ctx, deletedChild)
+		if err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("deleting cmd %s: %v", existingChild.Name, err))
+		}
+	}
+
+	if !foundDesired && child != nil {
+		err := r.ctrlClient.Create(ctx, child)
+		if err != nil && !apierrors.IsAlreadyExists(err) {
+			errs = append(errs, fmt.Errorf("creating cmd %s: %v", child.Name, err))
+		}
+	}
+
+	return errorutil.NewAggregate(errs)
+}
+
+// Construct the desired Cmd. May be nil.
+func (r *Reconciler) toDesiredCmd(owner *v1alpha1.NestedTilt) (*v1alpha1.Cmd, error) {
+	if owner == nil {
+		return nil, nil
+	}
+
+	args := []string{
+		r.tiltPath, "up",
+		"--stream",
+		fmt.Sprintf("--port=%d", owner.Spec.Port),
+	}
+	args = append(args, tiltfileArgs(owner.Spec)...)
+
+	name := cmdName(types.NamespacedName{Name: owner.Name}).Name
+	child := &v1alpha1.Cmd{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Annotations: map[string]string{
+				v1alpha1.AnnotationManifest: owner.Name,
+				v1alpha1.AnnotationSpanID:   fmt.Sprintf("cmd:%s", name),
+			},
+		},
+		Spec: v1alpha1.CmdSpec{
+			Args: args,
+			Dir:  owner.Spec.Path,
+		},
+	}
+	err := controllerutil.SetControllerReference(owner, child, r.ctrlClient.Scheme())
+	if err != nil {
+		return nil, err
+	}
+	return child, nil
+}
+
+// The command that deletes everything the child Tilt deployed.
+//
+// Stopping the child's `tilt up` leaves its Kubernetes objects and
+// Compose services running, so we run the child's `tilt down` when
+// the NestedTilt is deleted, and when the parent runs `tilt down`.
+func DownCmd(tiltPath string, spec v1alpha1.NestedTiltSpec) model.Cmd {
+	return model.Cmd{
+		Argv: append([]string{tiltPath, "down"}, tiltfileArgs(spec)...),
+		Dir:  spec.Path,
+	}
+}
+
+func tiltfileArgs(spec v1alpha1.NestedTiltSpec) []string {
+	args := []string{"--file", filepath.Join(spec.Path, "Tiltfile")}
+	if len(spec.Args) > 0 {
+		args = append(args, "--")
+		args = append(args, spec.Args...)
+	}
+	return args
+}
+
+// Reconcile the UIResources that show the child Tilt in this Tilt's UI.
+//
+// If fetchFailed is set, we couldn't reach the child Tilt, so the mirrored
+// resources are left as they are.
+func (r *Reconciler) manageOwnedUIResources(ctx context.Context, nn types.NamespacedName,
+	owner *v1alpha1.NestedTilt, cmd *v1alpha1.Cmd, children []v1alpha1.UIResource, fetchFailed bool) error {
+	var existingList v1alpha1.UIResourceList
+	err := indexer.ListOwnedBy(ctx, r.ctrlClient, &existingList, nn, apiType)
+	if err != nil {
+		return fmt.Errorf("failed to fetch managed UIResource objects for NestedTilt %s: %v", nn.Name, err)
+	}
+
+	desired, err := r.toDesiredUIResources(owner, cmd, children)
+	if err != nil {
+		return fmt.Errorf("creating uiresources: %v", err)
+	}
+
+	errs := []error{}
+	for _, existing := range existingList.Items {
+		obj, ok := desired[existing.Name]
+		if !ok {
+			if owner != nil && fetchFailed && existing.Name != owner.Name {
+				continue
+			}
+
+			err := r.ctrlClient.Delete(ctx, existing.DeepCopy())
+			if err != nil && !apierrors.IsNotFound(err) {
+				errs = append(errs, fmt.Errorf("deleting uiresource %s: %v", existing.Name, err))
+			}
+			continue
+		}
+		delete(desired, existing.Name)
+
+		update := existing.DeepCopy()
+		if !apicmp.DeepEqual(existing.Labels, obj.Labels) || !apicmp.DeepEqual(existing.Annotations, obj.Annotations) {
+			update.Labels = obj.Labels
+			update.Annotations = obj.Annotations
+			err := r.ctrlClient.Update(ctx, update)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("updating uiresource %s: %v", existing.Name, err))
+				continue
+			}
+		}
+
+		if !apicmp.DeepEqual(existing.Status, obj.Status) {
+			update.Status = obj.Status
+			err := r.ctrlClient.Status().Update(ctx, update)
+			if err != nil && !apierrors.IsNotFound(err) {
+				errs = append(errs, fmt.Errorf("updating uiresource %s status: %v", existing.Name, err))
+			}
+		}
+	}
+
+	for _, obj := range desired {
+		status := obj.Status
+		err := r.ctrlClient.Create(ctx, obj)
+		if err != nil {
+			if !apierrors.IsAlreadyExists(err) {
+				errs = append(errs, fmt.Errorf("creating uiresource %s: %v", obj.Name, err))
+			}
+			continue
+		}
+
+		obj.Status = status
+		err = r.ctrlClient.Status().Update(ctx, obj)
+		if err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("updating uiresource %s status: %v", obj.Name, err))
+		}
+	}
+
+	return errorutil.NewAggregate(errs)
+}
+
+// Construct the desired UIResources, keyed by name.
+//
+// The NestedTilt gets a resource of its own, for the child Tilt's
+// logs and process status. Each resource of the child Tilt is mirrored
+// as "[nestedtilt-name]:[resource-name]".
+func (r *Reconciler) toDesiredUIResources(owner *v1alpha1.NestedTilt, cmd *v1alpha1.Cmd, children []v1alpha1.UIResource) (map[string]*v1alpha1.UIResource, error) {
+	result := make(map[string]*v1alpha1.UIResource)
+	if owner == nil {
+		return result, nil
+	}
+
+	// Group the child's resources with the NestedTilt in the UI.
+	label := apis.SanitizeLabel(owner.Name)
+	labels := map[string]string{label: label}
+	url := webURL(owner)
+
+	self := &v1alpha1.UIResource{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   owner.Name,
+			Labels: labels,
+			Annotations: map[string]string{
+				v1alpha1.AnnotationManifest: owner.Name,
+			},
+		},
+		Status: v1alpha1.UIResourceStatus{
+			EndpointLinks: []v1alpha1.UIResourceLink{{URL: url, Name: childLinkName}},
+			UpdateStatus:  v1alpha1.UpdateStatusNotApplicable,
+			RuntimeStatus: cmdRuntimeStatus(cmd),
+		},
+	}
+	if cmd.Status.Running != nil {
+		self.Status.LocalResourceInfo = &v1alpha1.UIResourceLocal{PID: int64(cmd.Status.Running.PID)}
+	}
+	result[self.Name] = self
+
+	for _, child := range children {
+		name := apis.SanitizeName(fmt.Sprintf("%s:%s", owner.Name, child.Name))
+		status := *child.Status.DeepCopy()
+
+		// The parent can't change anything in the child Tilt, so the resource
+		// is read-only here. Link to the child's UI for everything else.
+		status.DisableStatus.Sources = nil
+		status.Queued = false
+		status.EndpointLinks = append([]v1alpha1.UIResourceLink{{
+			URL:  fmt.Sprintf("%sr/%s/overview", url, child.Name),
+			Name: childLinkName,
+		}}, status.EndpointLinks...)
+
+		result[name] = &v1alpha1.UIResource{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: labels,
+			},
+			Status: status,
+		}
+	}
+
+	for _, obj := range result {
+		err := controllerutil.SetControllerReference(owner, obj, r.ctrlClient.Scheme())
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func cmdRuntimeStatus(cmd *v1alpha1.Cmd) v1alpha1.RuntimeStatus {
+	switch {
+	case cmd.Status.Terminated != nil:
+		return v1alpha1.RuntimeStatusError
+	case cmd.Status.Running != nil && cmd.Status.Ready:
+		return v1alpha1.RuntimeStatusOK
+	default:
+		return v1alpha1.RuntimeStatusPending
+	}
+}
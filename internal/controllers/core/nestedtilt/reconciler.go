@@ -0,0 +1,226 @@
+package nestedtilt
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/tilt-dev/tilt/internal/controllers/apicmp"
+	"github.com/tilt-dev/tilt/internal/localexec"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+	proto_webview "github.com/tilt-dev/tilt/pkg/webview"
+)
+
+var (
+	apiGVStr = v1alpha1.SchemeGroupVersion.String()
+	apiKind  = "NestedTilt"
+	apiType  = metav1.TypeMeta{Kind: apiKind, APIVersion: apiGVStr}
+)
+
+// How often to sync resources from a running child Tilt.
+const syncInterval = 2 * time.Second
+
+// Runs a child Tilt for each NestedTilt object, and mirrors the child's
+// resources into this Tilt's UI.
+//
+// The child runs as a Cmd owned by the NestedTilt, so its logs show up under
+// the NestedTilt's own resource. The child's resources are copied into
+// read-only UIResources that link back to the child's web UI.
+//
+// When the NestedTilt is deleted, the child's `tilt down` deletes
+// whatever the child deployed.
+type Reconciler struct {
+	ctrlClient ctrlclient.Client
+	httpClient *http.Client
+	execer     localexec.Execer
+	mu         sync.Mutex
+
+	// The tilt binary to run the child with.
+	tiltPath string
+
+	// The `tilt down` of each NestedTilt we've seen, so that we can
+	// still run it once the NestedTilt is gone.
+	downCmds map[types.NamespacedName]model.Cmd
+}
+
+var _ reconcile.Reconciler = &Reconciler{}
+
+func (r *Reconciler) CreateBuilder(mgr ctrl.Manager) (*builder.Builder, error) {
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.NestedTilt{}).
+		Owns(&v1alpha1.Cmd{})
+
+	return b, nil
+}
+
+func NewReconciler(ctrlClient ctrlclient.Client, execer localexec.Execer) *Reconciler {
+	return &Reconciler{
+		ctrlClient: ctrlClient,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+		execer:     execer,
+		tiltPath:   TiltPath(),
+		downCmds:   make(map[types.NamespacedName]model.Cmd),
+	}
+}
+
+// Run the child with the same version of Tilt as the parent.
+func TiltPath() string {
+	tiltPath, err := os.Executable()
+	if err != nil {
+		return "tilt"
+	}
+	return tiltPath
+}
+
+func (r *Reconciler) CrashRecoverable() {}
+
+func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	nn := request.NamespacedName
+
+	var nt v1alpha1.NestedTilt
+	err := r.ctrlClient.Get(ctx, nn, &nt)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return ctrl.Result{}, err
+	}
+
+	if apierrors.IsNotFound(err) || !nt.ObjectMeta.DeletionTimestamp.IsZero() {
+		err := r.manageOwnedCmd(ctx, nn, nil)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		err = r.manageOwnedUIResources(ctx, nn, nil, nil, nil, false)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		r.down(ctx, nn)
+		return ctrl.Result{}, nil
+	}
+
+	r.downCmds[nn] = DownCmd(r.tiltPath, nt.Spec)
+
+	err = r.manageOwnedCmd(ctx, nn, &nt)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	var cmd v1alpha1.Cmd
+	err = r.ctrlClient.Get(ctx, cmdName(nn), &cmd)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return ctrl.Result{}, err
+	}
+
+	status := v1alpha1.NestedTiltStatus{
+		WebURL: webURL(&nt),
+	}
+
+	// Only talk to the child once it's running. If it's not running,
+	// its resources are gone too.
+	result := ctrl.Result{}
+	var children []v1alpha1.UIResource
+	fetchFailed := false
+	if cmd.Status.Running != nil {
+		result.RequeueAfter = syncInterval
+
+		children, err = r.fetchChildResources(ctx, &nt)
+		if err != nil {
+			// Keep showing the resources from the last sync until
+			// the child comes back.
+			status.Error = err.Error()
+			status.Resources = nt.Status.Resources
+			fetchFailed = true
+		}
+	}
+
+	for _, child := range children {
+		status.Resources = append(status.Resources, child.Name)
+	}
+	sort.Strings(status.Resources)
+
+	err = r.manageOwnedUIResources(ctx, nn, &nt, &cmd, children, fetchFailed)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if !apicmp.DeepEqual(nt.Status, status) {
+		update := nt.DeepCopy()
+		update.Status = status
+		err := r.ctrlClient.Status().Update(ctx, update)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	return result, nil
+}
+
+// Deletes whatever the child Tilt deployed, once the NestedTilt is gone.
+func (r *Reconciler) down(ctx context.Context, nn types.NamespacedName) {
+	cmd, ok := r.downCmds[nn]
+	if !ok {
+		return
+	}
+	delete(r.downCmds, nn)
+
+	err := localexec.OneShotToLogger(ctx, r.execer, cmd)
+	if err != nil {
+		logger.Get(ctx).Errorf("Error running tilt down for nested tilt %s: %v", nn.Name, err)
+	}
+}
+
+// Reads the resources of the child Tilt from its web server.
+func (r *Reconciler) fetchChildResources(ctx context.Context, nt *v1alpha1.NestedTilt) ([]v1alpha1.UIResource, error) {
+	url := fmt.Sprintf("http://localhost:%d/api/view", nt.Spec.Port)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("reading resources from child tilt: %v", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("reading resources from child tilt: %s", resp.Status)
+	}
+
+	view := &proto_webview.View{}
+	err = (&runtime.JSONPb{}).NewDecoder(resp.Body).Decode(view)
+	if err != nil {
+		return nil, fmt.Errorf("decoding resources from child tilt: %v", err)
+	}
+
+	result := make([]v1alpha1.UIResource, 0, len(view.UiResources))
+	for _, r := range view.UiResources {
+		result = append(result, *r)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+func webURL(nt *v1alpha1.NestedTilt) string {
+	return fmt.Sprintf("http://localhost:%d/", nt.Spec.Port)
+}
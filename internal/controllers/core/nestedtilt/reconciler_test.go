@@ -0,0 +1,216 @@
+package nestedtilt
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/tilt-dev/tilt/internal/controllers/fake"
+	"github.com/tilt-dev/tilt/internal/localexec"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	proto_webview "github.com/tilt-dev/tilt/pkg/webview"
+)
+
+var ntName = types.NamespacedName{Name: "payments"}
+
+func TestCreatesCmd(t *testing.T) {
+	f := newFixture(t)
+	f.createNestedTilt(10360, "api")
+
+	var cmd v1alpha1.Cmd
+	f.MustGet(types.NamespacedName{Name: "nestedtilt:payments"}, &cmd)
+	assert.Equal(t, []string{
+		"tilt", "up", "--stream", "--port=10360",
+		"--file", "/src/payments/Tiltfile", "--", "api",
+	}, cmd.Spec.Args)
+	assert.Equal(t, "/src/payments", cmd.Spec.Dir)
+	assert.Equal(t, "payments", cmd.Annotations[v1alpha1.AnnotationManifest])
+
+	uir := f.uiResource("payments")
+	assert.Equal(t, v1alpha1.RuntimeStatusPending, uir.Status.RuntimeStatus)
+	assert.Equal(t, []v1alpha1.UIResourceLink{
+		{URL: "http://localhost:10360/", Name: "nested tilt"},
+	}, uir.Status.EndpointLinks)
+	assert.Equal(t, map[string]string{"payments": "payments"}, uir.Labels)
+}
+
+func TestMirrorsChildResources(t *testing.T) {
+	f := newFixture(t)
+	port := f.serveChild(childResource("(Tiltfile)"), childResource("api"))
+	f.createNestedTilt(port)
+	f.setCmdRunning()
+
+	result := f.MustReconcile(ntName)
+	assert.Equal(t, syncInterval, result.RequeueAfter)
+
+	uir := f.uiResource("payments")
+	assert.Equal(t, v1alpha1.RuntimeStatusOK, uir.Status.RuntimeStatus)
+	assert.Equal(t, int64(1234), uir.Status.LocalResourceInfo.PID)
+
+	api := f.uiResource("payments:api")
+	assert.Equal(t, v1alpha1.RuntimeStatusOK, api.Status.RuntimeStatus)
+	assert.Equal(t, map[string]string{"payments": "payments"}, api.Labels)
+	assert.Nil(t, api.Status.DisableStatus.Sources)
+	assert.Equal(t, []v1alpha1.UIResourceLink{
+		{URL: fmt.Sprintf("http://localhost:%d/r/api/overview", port), Name: "nested tilt"},
+		{URL: "http://localhost:8080/", Name: "api"},
+	}, api.Status.EndpointLinks)
+
+	nt := f.nestedTilt()
+	assert.Equal(t, []string{"(Tiltfile)", "api"}, nt.Status.Resources)
+	assert.Equal(t, "", nt.Status.Error)
+}
+
+func TestKeepsResourcesWhenChildUnreachable(t *testing.T) {
+	f := newFixture(t)
+	port := f.serveChild(childResource("api"))
+	f.createNestedTilt(port)
+	f.setCmdRunning()
+	f.MustReconcile(ntName)
+
+	f.server.Close()
+	f.MustReconcile(ntName)
+
+	nt := f.nestedTilt()
+	assert.Contains(t, nt.Status.Error, "reading resources from child tilt")
+	assert.Equal(t, []string{"api"}, nt.Status.Resources)
+	f.uiResource("payments:api")
+}
+
+func TestRemovesResourcesWhenChildExits(t *testing.T) {
+	f := newFixture(t)
+	port := f.serveChild(childResource("api"))
+	f.createNestedTilt(port)
+	f.setCmdRunning()
+	f.MustReconcile(ntName)
+
+	var cmd v1alpha1.Cmd
+	f.MustGet(types.NamespacedName{Name: "nestedtilt:payments"}, &cmd)
+	cmd.Status.Running = nil
+	cmd.Status.Terminated = &v1alpha1.CmdStateTerminated{ExitCode: 1}
+	f.UpdateStatus(&cmd)
+	f.MustReconcile(ntName)
+
+	var api v1alpha1.UIResource
+	assert.False(t, f.Get(types.NamespacedName{Name: "payments:api"}, &api))
+	assert.Equal(t, v1alpha1.RuntimeStatusError, f.uiResource("payments").Status.RuntimeStatus)
+	assert.Empty(t, f.nestedTilt().Status.Resources)
+}
+
+func TestDeleteCleansUp(t *testing.T) {
+	f := newFixture(t)
+	port := f.serveChild(childResource("api"))
+	f.createNestedTilt(port)
+	f.setCmdRunning()
+	f.MustReconcile(ntName)
+
+	nt := f.nestedTilt()
+	found, _ := f.Delete(&nt)
+	require.True(t, found)
+
+	var cmd v1alpha1.Cmd
+	assert.False(t, f.Get(types.NamespacedName{Name: "nestedtilt:payments"}, &cmd))
+	var uirs v1alpha1.UIResourceList
+	f.List(&uirs)
+	assert.Empty(t, uirs.Items)
+
+	calls := f.execer.Calls()
+	require.Len(t, calls, 1)
+	assert.Equal(t, []string{"tilt", "down", "--file", "/src/payments/Tiltfile"}, calls[0].Cmd.Argv)
+	assert.Equal(t, "/src/payments", calls[0].Cmd.Dir)
+
+	// Only tear down the child once.
+	f.MustReconcile(ntName)
+	assert.Len(t, f.execer.Calls(), 1)
+}
+
+type fixture struct {
+	*fake.ControllerFixture
+	t      *testing.T
+	server *httptest.Server
+	execer *localexec.FakeExecer
+}
+
+func newFixture(t *testing.T) *fixture {
+	cfb := fake.NewControllerFixtureBuilder(t)
+	execer := localexec.NewFakeExecer(t)
+	r := NewReconciler(cfb.Client, execer)
+	r.tiltPath = "tilt"
+	return &fixture{
+		ControllerFixture: cfb.Build(r),
+		t:                 t,
+		execer:            execer,
+	}
+}
+
+func childResource(name string) *v1alpha1.UIResource {
+	return &v1alpha1.UIResource{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: v1alpha1.UIResourceStatus{
+			RuntimeStatus: v1alpha1.RuntimeStatusOK,
+			EndpointLinks: []v1alpha1.UIResourceLink{{URL: "http://localhost:8080/", Name: name}},
+			DisableStatus: v1alpha1.DisableResourceStatus{
+				Sources: []v1alpha1.DisableSource{{ConfigMap: &v1alpha1.ConfigMapDisableSource{Name: name + "-disable", Key: "isDisabled"}}},
+			},
+		},
+	}
+}
+
+// Serves the view of a fake child Tilt. Returns its port.
+func (f *fixture) serveChild(resources ...*v1alpha1.UIResource) int32 {
+	f.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/api/view" {
+			http.NotFound(w, req)
+			return
+		}
+		view := &proto_webview.View{UiResources: resources}
+		_ = (&runtime.JSONPb{}).NewEncoder(w).Encode(view)
+	}))
+	f.t.Cleanup(f.server.Close)
+
+	u, err := url.Parse(f.server.URL)
+	require.NoError(f.t, err)
+	port, err := strconv.Atoi(u.Port())
+	require.NoError(f.t, err)
+	return int32(port)
+}
+
+func (f *fixture) createNestedTilt(port int32, args ...string) {
+	f.Create(&v1alpha1.NestedTilt{
+		ObjectMeta: metav1.ObjectMeta{Name: ntName.Name},
+		Spec: v1alpha1.NestedTiltSpec{
+			Path: "/src/payments",
+			Port: port,
+			Args: args,
+		},
+	})
+}
+
+func (f *fixture) setCmdRunning() {
+	var cmd v1alpha1.Cmd
+	f.MustGet(types.NamespacedName{Name: "nestedtilt:payments"}, &cmd)
+	cmd.Status.Running = &v1alpha1.CmdStateRunning{PID: 1234}
+	cmd.Status.Ready = true
+	f.UpdateStatus(&cmd)
+}
+
+func (f *fixture) nestedTilt() v1alpha1.NestedTilt {
+	var nt v1alpha1.NestedTilt
+	f.MustGet(ntName, &nt)
+	return nt
+}
+
+func (f *fixture) uiResource(name string) v1alpha1.UIResource {
+	var uir v1alpha1.UIResource
+	f.MustGet(types.NamespacedName{Name: name}, &uir)
+	return uir
+}
@@ -0,0 +1,7 @@
+package nestedtilt
+
+import "github.com/google/wire"
+
+var WireSet = wire.NewSet(
+	NewReconciler,
+)
@@ -26,6 +26,7 @@ import (
 
 	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/internal/controllers/apicmp"
+	"github.com/tilt-dev/tilt/internal/controllers/apis/configmap"
 	"github.com/tilt-dev/tilt/internal/engine/runtimelog"
 	"github.com/tilt-dev/tilt/internal/k8s"
 	"github.com/tilt-dev/tilt/internal/store"
@@ -38,6 +39,9 @@ var podLogReconnectGap = 2 * time.Second
 
 const maxDebounceDuration = time.Minute
 
+// In low-bandwidth mode, how far back to read logs when we start watching a container.
+const lowBandwidthBackfill = 10 * time.Second
+
 var clusterGVK = v1alpha1.SchemeGroupVersion.WithKind("Cluster")
 
 // Reconciles the PodLogStream API object.
@@ -189,6 +193,8 @@ func (c *Controller) addOrUpdateContainerWatches(ctx context.Context, streamName
 	containers = append(containers, runContainers...)
 	c.ensureStatusActive(streamName, containers)
 
+	lowBandwidth := configmap.LowBandwidth(ctx, c.client)
+
 	result := reconcile.Result{}
 	containerWatches := make(map[podLogKey]bool)
 	for i, co := range containers {
@@ -204,6 +210,10 @@ func (c *Controller) addOrUpdateContainerWatches(ctx context.Context, streamName
 		}
 
 		isInitContainer := i < len(initContainers)
+		if lowBandwidth && isInitContainer && isSucceeded(co) {
+			// Init containers that worked aren't worth the bandwidth.
+			continue
+		}
 
 		// We don't want to clutter the logs with a container name
 		// if it's unambiguous what container we're looking at.
@@ -224,6 +234,13 @@ func (c *Controller) addOrUpdateContainerWatches(ctx context.Context, streamName
 		if stream.Spec.SinceTime != nil {
 			startWatchTime = stream.Spec.SinceTime.Time
 		}
+		if lowBandwidth {
+			// Don't backfill much history over a slow link.
+			backfillStart := c.clock.Now().Add(-lowBandwidthBackfill)
+			if startWatchTime.Before(backfillStart) {
+				startWatchTime = backfillStart
+			}
+		}
 		debounce := time.Second
 
 		if isActive {
@@ -286,6 +303,10 @@ func (c *Controller) addOrUpdateContainerWatches(ctx context.Context, streamName
 	return result
 }
 
+func isSucceeded(co v1alpha1.Container) bool {
+	return co.State.Terminated != nil && co.State.Terminated.ExitCode == 0
+}
+
 // Delete all the streams generated by the named API object
 func (c *Controller) deleteStreams(streamName types.NamespacedName) {
 	for k, watch := range c.watches {
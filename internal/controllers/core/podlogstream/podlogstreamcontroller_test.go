@@ -12,6 +12,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/tilt-dev/tilt/internal/controllers/apicmp"
+	"github.com/tilt-dev/tilt/internal/controllers/apis/configmap"
 	"github.com/tilt-dev/tilt/internal/timecmp"
 	"github.com/tilt-dev/tilt/pkg/apis"
 
@@ -335,6 +336,27 @@ func TestInitContainerLogs(t *testing.T) {
 	f.AssertOutputContains("hello world!")
 }
 
+func TestLowBandwidthLogs(t *testing.T) {
+	f := newPLMFixture(t)
+	f.Create(configmap.BandwidthCreate(true))
+
+	cNameInit := container.Name("cNameInit")
+	cNameNormal := container.Name("cNameNormal")
+	pb := newPodBuilder(podID).
+		addTerminatedInitContainer(cNameInit, "cID-init").
+		addRunningContainer(cNameNormal, "cID-normal")
+	f.kClient.UpsertPod(pb.toPod())
+
+	f.kClient.SetLogsForPodContainer(podID, cNameInit, "init world!")
+	f.kClient.SetLogsForPodContainer(podID, cNameNormal, "hello world!")
+
+	f.Create(plsFromPod("server", pb, time.Time{}))
+
+	f.AssertOutputContains("hello world!")
+	f.AssertOutputDoesNotContain("init world!")
+	f.AssertLogStartTime(f.clock.Now().Add(-lowBandwidthBackfill))
+}
+
 func TestIgnoredContainerLogs(t *testing.T) {
 	f := newPLMFixture(t)
 
@@ -74,6 +74,8 @@ func (r *Reconciler) CreateBuilder(mgr ctrl.Manager) (*builder.Builder, error) {
 	return b, nil
 }
 
+func (r *Reconciler) CrashRecoverable() {}
+
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	err := r.reconcile(ctx, req.NamespacedName)
 	return ctrl.Result{}, err
@@ -137,6 +139,10 @@ func (r *Reconciler) reconcile(ctx context.Context, name types.NamespacedName) e
 	return r.maybeUpdateStatus(ctx, pf, r.activeForwards[name])
 }
 
+// How long a forward needs to stay up before we consider it healthy
+// and reset the backoff.
+const healthyForwardDuration = 10 * time.Second
+
 func (r *Reconciler) portForwardLoop(ctx context.Context, entry *portForwardEntry, forward Forward) {
 	originalBackoff := wait.Backoff{
 		Steps:    1000,
@@ -146,6 +152,7 @@ func (r *Reconciler) portForwardLoop(ctx context.Context, entry *portForwardEntr
 		Cap:      15 * time.Second,
 	}
 	currentBackoff := originalBackoff
+	retryCount := int32(0)
 
 	for {
 		start := time.Now()
@@ -158,13 +165,26 @@ func (r *Reconciler) portForwardLoop(ctx context.Context, entry *portForwardEntr
 			return
 		}
 
-		// If this failed in less than a second, then we should advance the backoff.
-		// Otherwise, reset the backoff.
-		if time.Since(start) < time.Second {
-			time.Sleep(currentBackoff.Step())
-		} else {
+		// If the forward was healthy for a while, reset the backoff, so that
+		// we reconnect quickly (e.g., when the container restarts).
+		// Otherwise, advance the backoff.
+		if time.Since(start) >= healthyForwardDuration {
 			currentBackoff = originalBackoff
 		}
+		delay := currentBackoff.Step()
+		retryCount++
+		entry.updateStatus(forward, func(status *ForwardStatus) {
+			status.State = v1alpha1.ForwardStateRetrying
+			status.RetryCount = retryCount
+			status.NextRetryTime = apis.NewMicroTime(time.Now().Add(delay))
+		})
+		r.requeuer.Add(entry.name)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
 	}
 }
 
@@ -187,19 +207,57 @@ func (r *Reconciler) onePortForward(ctx context.Context, entry *portForwardEntry
 			forward.LocalPort, forward.ContainerPort, err)
 	}
 
+	// Keep the error from the last attempt until we're connected,
+	// so that it's visible while we're retrying.
+	entry.updateStatus(forward, func(status *ForwardStatus) {
+		status.LocalPort = forward.LocalPort
+		status.ContainerPort = forward.ContainerPort
+		status.State = v1alpha1.ForwardStateConnecting
+		status.NextRetryTime = metav1.MicroTime{}
+	})
+
+	// If we already know that the cluster is unreachable (e.g., because the
+	// VPN dropped), say so once, rather than logging a timeout on every retry.
+	if err := r.clusterUnreachableError(ctx, entry); err != nil {
+		if entry.lastError(forward) != err.Error() {
+			logger.Get(ctx).Infof("Waiting to port-forward %s (%d -> %d): %v",
+				entry.meta.Annotations[v1alpha1.AnnotationManifest],
+				forward.LocalPort, forward.ContainerPort, err)
+		}
+		entry.updateStatus(forward, func(status *ForwardStatus) {
+			status.Addresses = nil
+			status.StartedAt = metav1.MicroTime{}
+			status.Error = err.Error()
+		})
+		r.requeuer.Add(entry.name)
+		return
+	}
+
+	podName, containerPort, err := resolveTarget(ctx, entry.client, entry.spec, forward, entry.lastPodName(forward))
+	if err != nil {
+		logError(err)
+		entry.updateStatus(forward, func(status *ForwardStatus) {
+			status.Addresses = nil
+			status.StartedAt = metav1.MicroTime{}
+			status.Error = err.Error()
+		})
+		r.requeuer.Add(entry.name)
+		return
+	}
+
 	pf, err := entry.client.CreatePortForwarder(
 		ctx,
 		k8s.Namespace(entry.spec.Namespace),
-		k8s.PodID(entry.spec.PodName),
+		k8s.PodID(podName),
 		int(forward.LocalPort),
-		int(forward.ContainerPort),
+		int(containerPort),
 		forward.Host)
 	if err != nil {
 		logError(err)
-		entry.setStatus(forward, ForwardStatus{
-			LocalPort:     forward.LocalPort,
-			ContainerPort: forward.ContainerPort,
-			Error:         err.Error(),
+		entry.updateStatus(forward, func(status *ForwardStatus) {
+			status.Addresses = nil
+			status.StartedAt = metav1.MicroTime{}
+			status.Error = err.Error()
 		})
 		r.requeuer.Add(entry.name)
 		return
@@ -222,11 +280,13 @@ func (r *Reconciler) onePortForward(ctx context.Context, entry *portForwardEntry
 			// forward initialization errored at start before ready
 			return
 		case <-readyCh:
-			entry.setStatus(forward, ForwardStatus{
-				LocalPort:     int32(pf.LocalPort()),
-				ContainerPort: forward.ContainerPort,
-				Addresses:     pf.Addresses(),
-				StartedAt:     apis.NowMicro(),
+			entry.updateStatus(forward, func(status *ForwardStatus) {
+				status.LocalPort = int32(pf.LocalPort())
+				status.Addresses = pf.Addresses()
+				status.StartedAt = apis.NowMicro()
+				status.Error = ""
+				status.State = v1alpha1.ForwardStateReady
+				status.PodName = podName
 			})
 			r.requeuer.Add(entry.name)
 		}
@@ -236,17 +296,29 @@ func (r *Reconciler) onePortForward(ctx context.Context, entry *portForwardEntry
 	close(doneCh)
 	if err != nil {
 		logError(err)
-		entry.setStatus(forward, ForwardStatus{
-			LocalPort:     int32(pf.LocalPort()),
-			ContainerPort: forward.ContainerPort,
-			Addresses:     pf.Addresses(),
-			Error:         err.Error(),
+		entry.updateStatus(forward, func(status *ForwardStatus) {
+			status.LocalPort = int32(pf.LocalPort())
+			status.Addresses = pf.Addresses()
+			status.StartedAt = metav1.MicroTime{}
+			status.Error = err.Error()
 		})
 		r.requeuer.Add(entry.name)
 		return
 	}
 }
 
+// Returns an error if the cluster that the forward runs against
+// is unreachable, or nil otherwise.
+func (r *Reconciler) clusterUnreachableError(ctx context.Context, entry *portForwardEntry) error {
+	var clusterObj v1alpha1.Cluster
+	nn := types.NamespacedName{Namespace: entry.meta.Namespace, Name: entry.spec.Cluster}
+	if err := r.ctrlClient.Get(ctx, nn, &clusterObj); err != nil {
+		// If we can't read the cluster, let the forward find out for itself.
+		return nil
+	}
+	return cluster.UnreachableError(&clusterObj)
+}
+
 func (r *Reconciler) TearDown(_ context.Context) {
 	for name := range r.activeForwards {
 		r.stop(name)
@@ -287,12 +359,28 @@ func newEntry(ctx context.Context, pf *PortForward, cli k8s.Client) *portForward
 	}
 }
 
-func (e *portForwardEntry) setStatus(spec Forward, status ForwardStatus) {
+func (e *portForwardEntry) updateStatus(spec Forward, update func(status *ForwardStatus)) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
+	status := e.status[spec]
+	update(&status)
 	e.status[spec] = status
 }
 
+// The pod that the forward was last connected to, if any.
+func (e *portForwardEntry) lastPodName(spec Forward) string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.status[spec].PodName
+}
+
+// The error from the last attempt to connect the forward, if any.
+func (e *portForwardEntry) lastError(spec Forward) string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.status[spec].Error
+}
+
 func (e *portForwardEntry) statuses() []ForwardStatus {
 	e.mu.Lock()
 	defer e.mu.Unlock()
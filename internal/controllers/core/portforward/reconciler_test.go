@@ -12,8 +12,10 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	ctrl "sigs.k8s.io/controller-runtime"
 
 	"github.com/tilt-dev/tilt/internal/controllers/apis/cluster"
@@ -260,6 +262,135 @@ func TestPortForwardRuntimeFailure(t *testing.T) {
 	f.requirePortForwardError(pfFooName, 8000, 8080, errMsg)
 }
 
+func TestPortForwardReconnect(t *testing.T) {
+	f := newPFRFixture(t)
+
+	pf := f.makeSimplePF(pfFooName, 8000, 8080)
+	f.Create(pf)
+	f.requirePortForwardStarted(pfFooName, 8000, 8080)
+
+	kCli := f.clients.MustK8sClient(clusterNN(pf))
+	require.Equal(t, 1, len(kCli.PortForwardCalls()))
+	kCli.LastForwarder().TriggerFailure(errors.New("lost connection to pod"))
+
+	// The forward reconnects on its own, and counts the retry.
+	f.requirePortForwardStatus(pfFooName, 8000, 8080, func(status ForwardStatus) (bool, string) {
+		if status.State != v1alpha1.ForwardStateReady || status.RetryCount != 1 {
+			return false, fmt.Sprintf("status has state=%s / retryCount=%d", status.State, status.RetryCount)
+		}
+		return true, ""
+	})
+	assert.Equal(t, 2, len(kCli.PortForwardCalls()))
+}
+
+func TestPortForwardRetryStatus(t *testing.T) {
+	f := newPFRFixture(t)
+
+	pf := f.makeSimplePF(pfFooName, k8s.MagicTestExplodingPort, 8080)
+	f.Create(pf)
+
+	f.requirePortForwardStatus(pfFooName, k8s.MagicTestExplodingPort, 8080, func(status ForwardStatus) (bool, string) {
+		if status.State != v1alpha1.ForwardStateRetrying || status.RetryCount < 2 || status.NextRetryTime.IsZero() {
+			return false, fmt.Sprintf("status has state=%s / retryCount=%d / nextRetryTime=%s",
+				status.State, status.RetryCount, status.NextRetryTime.String())
+		}
+		if !strings.Contains(status.Error, "fake error starting port forwarding") {
+			return false, fmt.Sprintf("error %q does not contain the forward error", status.Error)
+		}
+		return true, ""
+	})
+}
+
+func TestPortForwardClusterUnreachable(t *testing.T) {
+	f := newPFRFixture(t)
+
+	pf := f.makeSimplePF(pfFooName, 8000, 8080)
+	defaulted := pf.DeepCopy()
+	defaulted.Default()
+	clusterKey := clusterNN(defaulted)
+	f.clients.EnsureK8sClusterUnreachable(f.Context(), clusterKey, errors.New("dial tcp 10.0.0.1:6443: i/o timeout"))
+	f.ControllerFixture.Create(pf)
+
+	f.requirePortForwardError(pfFooName, 8000, 8080,
+		`cluster "default" is unreachable: dial tcp 10.0.0.1:6443: i/o timeout`)
+	kCli := f.clients.MustK8sClient(clusterKey)
+	assert.Equal(t, 0, kCli.CreatePortForwardCallCount())
+
+	// Once the cluster is back, the forward connects on its own.
+	f.clients.EnsureK8sCluster(f.Context(), clusterKey)
+	f.requirePortForwardStarted(pfFooName, 8000, 8080)
+}
+
+func TestPortForwardService(t *testing.T) {
+	f := newPFRFixture(t)
+
+	pf := f.makeSimplePF(pfFooName, 8000, 80)
+	pf.Spec.PodName = ""
+	pf.Spec.ServiceName = "svc"
+	pf.Spec.Namespace = "default"
+	pf.Default()
+	f.ensureCluster(pf)
+	kCli := f.clients.MustK8sClient(clusterNN(pf))
+
+	kCli.UpsertService(&v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "svc", Namespace: "default"},
+		Spec: v1.ServiceSpec{
+			Selector: map[string]string{"app": "foo"},
+			Ports:    []v1.ServicePort{{Port: 80, TargetPort: intstr.FromString("http")}},
+		},
+	})
+	kCli.UpsertPod(f.makeReadyPod("pod-a", time.Now().Add(-time.Minute)))
+
+	f.Create(pf)
+	f.requirePortForwardStarted(pfFooName, 8000, 80)
+	assert.Equal(t, "pod-a", kCli.LastForwardPortPodID().String())
+	assert.Equal(t, 8080, kCli.LastForwardPortRemotePort())
+	f.requirePortForwardPod(pfFooName, 8000, 80, "pod-a")
+
+	// A new pod comes up, but we keep using the current one.
+	kCli.UpsertPod(f.makeReadyPod("pod-b", time.Now()))
+	kCli.LastForwarder().TriggerFailure(errors.New("lost connection to pod"))
+	f.requirePortForwardStatus(pfFooName, 8000, 80, func(status ForwardStatus) (bool, string) {
+		if status.RetryCount != 1 || status.State != v1alpha1.ForwardStateReady {
+			return false, fmt.Sprintf("status has state=%s / retryCount=%d", status.State, status.RetryCount)
+		}
+		return true, ""
+	})
+	assert.Equal(t, "pod-a", kCli.LastForwardPortPodID().String())
+
+	// The current pod goes away, so we fail over to the other one.
+	kCli.EmitPodDelete(f.makeReadyPod("pod-a", time.Now()))
+	kCli.LastForwarder().TriggerFailure(errors.New("lost connection to pod"))
+	f.requirePortForwardPod(pfFooName, 8000, 80, "pod-b")
+	assert.Equal(t, 8080, kCli.LastForwardPortRemotePort())
+}
+
+func TestPortForwardPodSelectorNoReadyPods(t *testing.T) {
+	f := newPFRFixture(t)
+
+	pf := f.makeSimplePF(pfFooName, 8000, 8080)
+	pf.Spec.PodName = ""
+	pf.Spec.PodSelector = map[string]string{"app": "foo"}
+	pf.Spec.Namespace = "default"
+	pf.Default()
+	f.ensureCluster(pf)
+	kCli := f.clients.MustK8sClient(clusterNN(pf))
+
+	pod := f.makeReadyPod("pod-a", time.Now())
+	pod.Status.Conditions[0].Status = v1.ConditionFalse
+	kCli.UpsertPod(pod)
+
+	f.Create(pf)
+	f.requirePortForwardError(pfFooName, 8000, 8080, "no ready pods match selector app=foo")
+	assert.Equal(t, 0, kCli.CreatePortForwardCallCount())
+
+	// Once the pod is ready, the forward connects on its own.
+	pod.Status.Conditions[0].Status = v1.ConditionTrue
+	kCli.UpsertPod(pod)
+	f.requirePortForwardPod(pfFooName, 8000, 8080, "pod-a")
+	assert.Equal(t, 8080, kCli.LastForwardPortRemotePort())
+}
+
 func TestPortForwardPartialSuccess(t *testing.T) {
 	f := newPFRFixture(t)
 
@@ -418,6 +549,16 @@ func (f *pfrFixture) requirePortForwardStarted(name string, localPort int32, con
 	})
 }
 
+func (f *pfrFixture) requirePortForwardPod(name string, localPort, containerPort int32, podName string) {
+	f.t.Helper()
+	f.requirePortForwardStatus(name, localPort, containerPort, func(status ForwardStatus) (bool, string) {
+		if status.State != v1alpha1.ForwardStateReady || status.PodName != podName {
+			return false, fmt.Sprintf("status has state=%s / podName=%q / error=%q", status.State, status.PodName, status.Error)
+		}
+		return true, ""
+	})
+}
+
 func (f *pfrFixture) requirePortForwardDeleted(name string) {
 	f.t.Helper()
 	f.requireState(name, func(pf *PortForward) bool {
@@ -482,6 +623,27 @@ func (f *pfrFixture) makeSimplePFMultipleForwards(name string, forwards []Forwar
 	return f.makePF(name, model.ManifestName(fmt.Sprintf("manifest-%s", name)), k8s.PodID(fmt.Sprintf("pod-%s", name)), "", forwards)
 }
 
+// A ready pod that matches the selector "app=foo", with a port named "http".
+func (f *pfrFixture) makeReadyPod(name string, created time.Time) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "default",
+			Labels:            map[string]string{"app": "foo"},
+			CreationTimestamp: metav1.NewTime(created),
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{Name: "main", Ports: []v1.ContainerPort{{Name: "http", ContainerPort: 8080}}},
+			},
+		},
+		Status: v1.PodStatus{
+			Phase:      v1.PodRunning,
+			Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionTrue}},
+		},
+	}
+}
+
 func (f *pfrFixture) makeForward(localPort, containerPort int32, host string) Forward {
 	return Forward{
 		LocalPort:     localPort,
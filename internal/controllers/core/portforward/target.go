@@ -0,0 +1,134 @@
+package portforward
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+// Resolves the pod and container port to forward to.
+//
+// When forwarding to a Service or PodSelector, picks a ready pod. Prefers
+// the pod that we were last connected to, so that reconnecting after a
+// blip doesn't bounce between pods.
+func resolveTarget(ctx context.Context, cli k8s.Client, spec v1alpha1.PortForwardSpec, forward Forward, lastPod string) (string, int32, error) {
+	if spec.PodName != "" {
+		return spec.PodName, forward.ContainerPort, nil
+	}
+
+	var svc *v1.Service
+	selector := labels.SelectorFromSet(spec.PodSelector)
+	if spec.ServiceName != "" {
+		var err error
+		svc, err = cli.ServiceFromInformerCache(ctx, types.NamespacedName{Namespace: spec.Namespace, Name: spec.ServiceName})
+		if err != nil {
+			return "", 0, fmt.Errorf("looking up service %s: %v", spec.ServiceName, err)
+		}
+		if len(svc.Spec.Selector) == 0 {
+			return "", 0, fmt.Errorf("service %s has no pod selector", spec.ServiceName)
+		}
+		selector = labels.SelectorFromSet(svc.Spec.Selector)
+	}
+
+	pods, err := cli.PodsFromInformerCache(ctx, k8s.Namespace(spec.Namespace), selector)
+	if err != nil {
+		return "", 0, fmt.Errorf("looking up pods: %v", err)
+	}
+
+	pod := pickPod(pods, lastPod)
+	if pod == nil {
+		if svc != nil {
+			return "", 0, fmt.Errorf("no ready pods for service %s", spec.ServiceName)
+		}
+		return "", 0, fmt.Errorf("no ready pods match selector %s", selector)
+	}
+
+	if svc == nil {
+		return pod.Name, forward.ContainerPort, nil
+	}
+
+	port, err := serviceTargetPort(svc, pod, forward.ContainerPort)
+	if err != nil {
+		return "", 0, err
+	}
+	return pod.Name, port, nil
+}
+
+// Picks the pod to forward to, or nil if none are ready.
+//
+// If the last pod is still ready, keep using it. Otherwise,
+// use the newest ready pod, which is the least likely to be on
+// its way out during a rollout.
+func pickPod(pods []*v1.Pod, lastPod string) *v1.Pod {
+	var ready []*v1.Pod
+	for _, pod := range pods {
+		if pod.DeletionTimestamp != nil || !isPodReady(pod) {
+			continue
+		}
+		if pod.Name == lastPod {
+			return pod
+		}
+		ready = append(ready, pod)
+	}
+
+	if len(ready) == 0 {
+		return nil
+	}
+
+	sort.Slice(ready, func(i, j int) bool {
+		ti, tj := ready[i].CreationTimestamp, ready[j].CreationTimestamp
+		if !ti.Equal(&tj) {
+			return tj.Before(&ti)
+		}
+		return ready[i].Name < ready[j].Name
+	})
+	return ready[0]
+}
+
+func isPodReady(pod *v1.Pod) bool {
+	if pod.Status.Phase != v1.PodRunning {
+		return false
+	}
+	for _, c := range pod.Status.Conditions {
+		if c.Type == v1.PodReady {
+			return c.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// Maps a port on the Service to the port on the pod, like
+// `kubectl port-forward svc/NAME` does.
+func serviceTargetPort(svc *v1.Service, pod *v1.Pod, port int32) (int32, error) {
+	for _, sp := range svc.Spec.Ports {
+		if sp.Port != port {
+			continue
+		}
+
+		target := sp.TargetPort
+		switch {
+		case target.Type == intstr.String && target.StrVal != "":
+			for _, c := range pod.Spec.Containers {
+				for _, cp := range c.Ports {
+					if cp.Name == target.StrVal {
+						return cp.ContainerPort, nil
+					}
+				}
+			}
+			return 0, fmt.Errorf("pod %s has no port named %q (from service %s)", pod.Name, target.StrVal, svc.Name)
+		case target.IntVal != 0:
+			return target.IntVal, nil
+		default:
+			return sp.Port, nil
+		}
+	}
+	return 0, fmt.Errorf("service %s has no port %d", svc.Name, port)
+}
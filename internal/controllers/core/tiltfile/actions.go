@@ -5,6 +5,7 @@ import (
 
 	"github.com/tilt-dev/wmclient/pkg/analytics"
 
+	"github.com/tilt-dev/tilt/internal/feature"
 	"github.com/tilt-dev/tilt/pkg/model"
 	"github.com/tilt-dev/tilt/pkg/model/logstore"
 )
@@ -31,10 +32,14 @@ type ConfigsReloadedAction struct {
 	Err                  error
 	Warnings             []string
 	Features             map[string]bool
+	FeatureSources       map[string]feature.Source
 	TeamID               string
 	TelemetrySettings    model.TelemetrySettings
 	Secrets              model.SecretSet
 	DockerPruneSettings  model.DockerPruneSettings
+	SnapshotSettings     model.SnapshotSettings
+	IdleSettings         model.IdleSettings
+	BandwidthSettings    model.BandwidthSettings
 	AnalyticsTiltfileOpt analytics.Opt
 	VersionSettings      model.VersionSettings
 	UpdateSettings       model.UpdateSettings
@@ -2,8 +2,10 @@ package tiltfile
 
 import (
 	"context"
+	"crypto/sha256"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
@@ -22,6 +24,7 @@ import (
 	"github.com/tilt-dev/tilt/internal/controllers/apiset"
 	"github.com/tilt-dev/tilt/internal/controllers/indexer"
 	"github.com/tilt-dev/tilt/internal/feature"
+	"github.com/tilt-dev/tilt/internal/k8s"
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/internal/tiltfile"
 	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
@@ -73,6 +76,14 @@ func updateOwnedObjects(
 				return err
 			}
 
+			// Record the prune owner on the target itself, because the
+			// engine applies the target's spec, not the KubernetesApply's.
+			if m.IsK8s() && m.K8sTarget().Prune {
+				kTarget := m.K8sTarget()
+				kTarget.PruneOwner = pruneOwner(tf, m.Name.String())
+				m = m.WithDeployTarget(kTarget)
+			}
+
 			tlr.Manifests[i] = m.WithDisableSource(disableSources[m.Name])
 		}
 	}
@@ -182,6 +193,8 @@ var typesWithTiltfileBuiltins = []apiset.Object{
 	&v1alpha1.UIButton{},
 	&v1alpha1.ConfigMap{},
 	&v1alpha1.KubernetesDiscovery{},
+	&v1alpha1.NestedTilt{},
+	&v1alpha1.LocalIngress{},
 }
 
 var typesToReconcile = append([]apiset.Object{
@@ -237,13 +250,14 @@ func toAPIObjects(
 			result.AddSetForType(obj, tlr.ObjectSet.GetSetForType(obj))
 		}
 
-		result.AddSetForType(&v1alpha1.KubernetesApply{}, toKubernetesApplyObjects(tlr, disableSources))
+		result.AddSetForType(&v1alpha1.KubernetesApply{}, toKubernetesApplyObjects(tf, tlr, mode, disableSources))
 		result.AddSetForType(&v1alpha1.DockerComposeService{}, toDockerComposeServiceObjects(tlr, disableSources))
 		result.AddSetForType(&v1alpha1.ConfigMap{}, toDisableConfigMaps(disableSources, tlr.EnabledManifests))
 		result.AddSetForType(&v1alpha1.Cmd{}, toCmdObjects(tlr, disableSources))
 		result.AddSetForType(&v1alpha1.ToggleButton{}, toToggleButtons(disableSources))
 		result.AddSetForType(&v1alpha1.Cluster{}, toClusterObjects(nn, tlr, defaultK8sConnection))
-		result.AddSetForType(&v1alpha1.UIButton{}, toCancelButtons(tlr))
+		result.AddSetForType(&v1alpha1.UIButton{}, toUIButtons(tlr))
+		result.AddSetForType(&v1alpha1.LocalIngress{}, toLocalIngressObjects(tlr))
 	}
 
 	result.AddSetForType(&v1alpha1.UIResource{}, toUIResourceObjects(tf, tlr, disableSources))
@@ -378,17 +392,105 @@ func toToggleButtons(disableSources disableSourceMap) apiset.TypedObjectSet {
 	return result
 }
 
-func toCancelButtons(tlr *tiltfile.TiltfileLoadResult) apiset.TypedObjectSet {
+func toUIButtons(tlr *tiltfile.TiltfileLoadResult) apiset.TypedObjectSet {
 	result := apiset.TypedObjectSet{}
 	for _, m := range tlr.Manifests {
 		button := uibutton.StopBuildButton(m.Name.String())
 		result[button.Name] = button
+
+		if hasCronJob(m) {
+			button := uibutton.CronJobRunButton(m.Name.String())
+			result[button.Name] = button
+		}
+
+		// Only YAML deploys can be rolled back. Tilt doesn't know
+		// how to undo a custom apply_cmd.
+		if m.IsK8s() && m.K8sTarget().YAML != "" {
+			button := uibutton.RollbackButton(m.Name.String())
+			result[button.Name] = button
+		}
+
+		if m.IsK8s() && len(m.K8sTarget().ResetVolumes) > 0 {
+			button := uibutton.ResetVolumesButton(m.Name.String())
+			result[button.Name] = button
+		}
 	}
 	return result
 }
 
+func hasCronJob(m model.Manifest) bool {
+	if !m.IsK8s() {
+		return false
+	}
+	entities, err := k8s.ParseYAMLFromString(m.K8sTarget().YAML)
+	if err != nil {
+		return false
+	}
+	for _, e := range entities {
+		if k8s.IsCronJob(e) {
+			return true
+		}
+	}
+	return false
+}
+
+// Creates a LocalIngress for each hostname in k8s_resource(hostnames=...).
+//
+// Resources that share a hostname share a LocalIngress, routed by path.
+// A LocalIngress declared with v1alpha1.local_ingress() takes precedence.
+func toLocalIngressObjects(tlr *tiltfile.TiltfileLoadResult) apiset.TypedObjectSet {
+	declared := tlr.ObjectSet.GetSetForType(&v1alpha1.LocalIngress{})
+	result := apiset.TypedObjectSet{}
+	for _, m := range tlr.Manifests {
+		if !m.IsK8s() {
+			continue
+		}
+
+		for _, h := range m.K8sTarget().Hostnames {
+			if _, ok := declared[h.Host]; ok {
+				continue
+			}
+
+			route := v1alpha1.LocalIngressRoute{Path: h.Path, Resource: m.Name.String()}
+			existing, ok := result[h.Host]
+			if ok {
+				li := existing.(*v1alpha1.LocalIngress)
+				li.Spec.Routes = append(li.Spec.Routes, route)
+				continue
+			}
+
+			result[h.Host] = &v1alpha1.LocalIngress{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: h.Host,
+					Annotations: map[string]string{
+						v1alpha1.AnnotationManifest: m.Name.String(),
+					},
+				},
+				Spec: v1alpha1.LocalIngressSpec{
+					Host:   h.Host,
+					Routes: []v1alpha1.LocalIngressRoute{route},
+				},
+			}
+		}
+	}
+	return result
+}
+
+// Identifies the objects that a resource applies, for pruning.
+//
+// Other projects may have resources with the same name in the same cluster,
+// so we qualify the name with a hash of the Tiltfile path.
+func pruneOwner(tf *v1alpha1.Tiltfile, name string) string {
+	path := ""
+	if tf != nil {
+		path = tf.Spec.Path
+	}
+	hash := sha256.Sum256([]byte(path))
+	return fmt.Sprintf("%x/%s", hash[:8], name)
+}
+
 // Pulls out all the KubernetesApply objects generated by the Tiltfile.
-func toKubernetesApplyObjects(tlr *tiltfile.TiltfileLoadResult, disableSources disableSourceMap) apiset.TypedObjectSet {
+func toKubernetesApplyObjects(tf *v1alpha1.Tiltfile, tlr *tiltfile.TiltfileLoadResult, mode store.EngineMode, disableSources disableSourceMap) apiset.TypedObjectSet {
 	result := apiset.TypedObjectSet{}
 	for _, m := range tlr.Manifests {
 		if !m.IsK8s() {
@@ -408,6 +510,15 @@ func toKubernetesApplyObjects(tlr *tiltfile.TiltfileLoadResult, disableSources d
 			},
 			Spec: kTarget.KubernetesApplySpec,
 		}
+		if kTarget.YAML != "" && !m.TriggerMode.AutoOnChange() {
+			// With manual triggers, users review the changes before applying them.
+			ka.Spec.DiffPreview = true
+		}
+		if kTarget.YAML != "" && mode != store.EngineModeCI {
+			// Ask before deploying to namespaces that the project hasn't
+			// deployed to before. In CI, there's no one to ask.
+			ka.Annotations[v1alpha1.AnnotationAllowedNamespaces] = strings.Join(kTarget.AllowedNamespaces, ",")
+		}
 		ka.Spec.DisableSource = disableSources[m.Name]
 		result[name] = ka
 	}
@@ -542,6 +653,12 @@ func toCmdImageObjects(tlr *tiltfile.TiltfileLoadResult, disableSources disableS
 				},
 				Spec: iTarget.CustomBuildInfo().CmdImageSpec,
 			}
+			if iTarget.IsOCIArtifactBuild() {
+				ci.Spec = iTarget.OCIArtifactBuildInfo().CmdImageSpec
+			}
+			if iTarget.IsKoBuild() {
+				ci.Spec = iTarget.KoBuildInfo().CmdImageSpec
+			}
 
 			// TODO(nick): Add DisableSource to image builds.
 			// di.Spec.DisableSource = disableSources[m.Name]
@@ -611,10 +728,17 @@ func toClusterObjects(nn types.NamespacedName, tlr *tiltfile.TiltfileLoadResult,
 					Kubernetes: defaultK8sConnection.DeepCopy(),
 				},
 				DefaultRegistry: tlr.DefaultRegistry,
+				BuildKit:        tlr.BuildKit,
 			},
 		}
 	}
 
+	for _, c := range tlr.K8sClusters {
+		obj := c.DeepCopy()
+		obj.Annotations = annotations
+		result[obj.Name] = obj
+	}
+
 	if tlr.HasOrchestrator(model.OrchestratorDC) {
 		name := v1alpha1.ClusterNameDocker
 		result[name] = &v1alpha1.Cluster{
@@ -624,7 +748,9 @@ func toClusterObjects(nn types.NamespacedName, tlr *tiltfile.TiltfileLoadResult,
 			},
 			Spec: v1alpha1.ClusterSpec{
 				Connection: &v1alpha1.ClusterConnection{
-					Docker: &v1alpha1.DockerClusterConnection{},
+					Docker: &v1alpha1.DockerClusterConnection{
+						Host: tlr.ContainerEngine.Host,
+					},
 				},
 			},
 		}
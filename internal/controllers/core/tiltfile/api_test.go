@@ -13,6 +13,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/tilt-dev/tilt/internal/controllers/apis/uibutton"
 	"github.com/tilt-dev/tilt/internal/controllers/fake"
 	"github.com/tilt-dev/tilt/internal/k8s/testyaml"
 	"github.com/tilt-dev/tilt/internal/store"
@@ -109,6 +110,82 @@ func TestAPIUpdate(t *testing.T) {
 	assert.Contains(t, ka.Spec.YAML, "sidecar")
 }
 
+func TestAPIDiffPreviewForManualTrigger(t *testing.T) {
+	f := newAPIFixture(t)
+	fe := manifestbuilder.New(f, "fe").WithK8sYAML(testyaml.SanchoYAML).Build()
+	be := manifestbuilder.New(f, "be").WithK8sYAML(testyaml.SecretYaml).
+		WithTriggerMode(model.TriggerModeManualWithAutoInit).Build()
+	nn := types.NamespacedName{Name: "tiltfile"}
+	tf := &v1alpha1.Tiltfile{ObjectMeta: metav1.ObjectMeta{Name: "tiltfile"}}
+	err := f.updateOwnedObjects(nn, tf,
+		&tiltfile.TiltfileLoadResult{Manifests: []model.Manifest{fe, be}})
+	assert.NoError(t, err)
+
+	var ka v1alpha1.KubernetesApply
+	assert.NoError(t, f.Get(types.NamespacedName{Name: "fe"}, &ka))
+	assert.False(t, ka.Spec.DiffPreview)
+	assert.NoError(t, f.Get(types.NamespacedName{Name: "be"}, &ka))
+	assert.True(t, ka.Spec.DiffPreview)
+}
+
+func TestAPIPruneOwner(t *testing.T) {
+	f := newAPIFixture(t)
+	fe := manifestbuilder.New(f, "fe").WithK8sYAML(testyaml.SanchoYAML).Build()
+	kTarget := fe.K8sTarget()
+	kTarget.Prune = true
+	fe = fe.WithDeployTarget(kTarget)
+	be := manifestbuilder.New(f, "be").WithK8sYAML(testyaml.SecretYaml).Build()
+	nn := types.NamespacedName{Name: "tiltfile"}
+	tf := &v1alpha1.Tiltfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "tiltfile"},
+		Spec:       v1alpha1.TiltfileSpec{Path: "/project1/Tiltfile"},
+	}
+	tlr := &tiltfile.TiltfileLoadResult{Manifests: []model.Manifest{fe, be}}
+	err := f.updateOwnedObjects(nn, tf, tlr)
+	assert.NoError(t, err)
+
+	var ka v1alpha1.KubernetesApply
+	assert.NoError(t, f.Get(types.NamespacedName{Name: "fe"}, &ka))
+	assert.Regexp(t, "^[0-9a-f]{16}/fe$", ka.Spec.PruneOwner)
+	assert.NoError(t, f.Get(types.NamespacedName{Name: "be"}, &ka))
+	assert.Equal(t, "", ka.Spec.PruneOwner)
+
+	// The engine applies the manifests, so they need the owner too.
+	assert.Equal(t, ka.Spec.PruneOwner, tlr.Manifests[1].K8sTarget().PruneOwner)
+	assert.NoError(t, f.Get(types.NamespacedName{Name: "fe"}, &ka))
+	assert.Equal(t, ka.Spec.PruneOwner, tlr.Manifests[0].K8sTarget().PruneOwner)
+
+	// Resources with the same name in other projects have different owners.
+	otherTf := &v1alpha1.Tiltfile{Spec: v1alpha1.TiltfileSpec{Path: "/project2/Tiltfile"}}
+	assert.NotEqual(t, pruneOwner(tf, "fe"), pruneOwner(otherTf, "fe"))
+}
+
+func TestAPIAllowedNamespaces(t *testing.T) {
+	f := newAPIFixture(t)
+	fe := manifestbuilder.New(f, "fe").WithK8sYAML(testyaml.SanchoYAML).Build()
+	kTarget := fe.K8sTarget()
+	kTarget.AllowedNamespaces = []string{"team-a", "team-b"}
+	fe = fe.WithDeployTarget(kTarget)
+	nn := types.NamespacedName{Name: "tiltfile"}
+	tf := &v1alpha1.Tiltfile{ObjectMeta: metav1.ObjectMeta{Name: "tiltfile"}}
+	err := f.updateOwnedObjects(nn, tf,
+		&tiltfile.TiltfileLoadResult{Manifests: []model.Manifest{fe}})
+	assert.NoError(t, err)
+
+	var ka v1alpha1.KubernetesApply
+	assert.NoError(t, f.Get(types.NamespacedName{Name: "fe"}, &ka))
+	assert.Equal(t, "team-a,team-b", ka.Annotations[v1alpha1.AnnotationAllowedNamespaces])
+
+	// In CI, there's no one to approve new namespaces.
+	err = updateOwnedObjects(f.ctx, f.c, nn, tf,
+		&tiltfile.TiltfileLoadResult{Manifests: []model.Manifest{fe}}, false, store.EngineModeCI,
+		&v1alpha1.KubernetesClusterConnection{})
+	assert.NoError(t, err)
+	assert.NoError(t, f.Get(types.NamespacedName{Name: "fe"}, &ka))
+	_, ok := ka.Annotations[v1alpha1.AnnotationAllowedNamespaces]
+	assert.False(t, ok)
+}
+
 func TestImageMapCreate(t *testing.T) {
 	f := newAPIFixture(t)
 	fe := manifestbuilder.New(f, "fe").
@@ -162,6 +239,95 @@ func TestCmdImageCreate(t *testing.T) {
 	assert.Contains(t, ci.Spec.Ref, SanchoRef.String())
 }
 
+func TestCronJobRunButton(t *testing.T) {
+	f := newAPIFixture(t)
+	cron := manifestbuilder.New(f, "cron").WithK8sYAML(testyaml.CronJobYAML).Build()
+	fe := manifestbuilder.New(f, "fe").WithK8sYAML(testyaml.SanchoYAML).Build()
+	nn := types.NamespacedName{Name: "tiltfile"}
+	tf := &v1alpha1.Tiltfile{ObjectMeta: metav1.ObjectMeta{Name: "tiltfile"}}
+	err := f.updateOwnedObjects(nn, tf,
+		&tiltfile.TiltfileLoadResult{Manifests: []model.Manifest{cron, fe}})
+	assert.NoError(t, err)
+
+	var button v1alpha1.UIButton
+	assert.NoError(t, f.Get(types.NamespacedName{Name: uibutton.CronJobRunButtonName("cron")}, &button))
+	assert.Equal(t, "cron", button.Spec.Location.ComponentID)
+
+	err = f.Get(types.NamespacedName{Name: uibutton.CronJobRunButtonName("fe")}, &button)
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestRollbackButton(t *testing.T) {
+	f := newAPIFixture(t)
+	fe := manifestbuilder.New(f, "fe").WithK8sYAML(testyaml.SanchoYAML).Build()
+	be := manifestbuilder.New(f, "be").WithLocalResource("echo hi", nil).Build()
+	nn := types.NamespacedName{Name: "tiltfile"}
+	tf := &v1alpha1.Tiltfile{ObjectMeta: metav1.ObjectMeta{Name: "tiltfile"}}
+	err := f.updateOwnedObjects(nn, tf,
+		&tiltfile.TiltfileLoadResult{Manifests: []model.Manifest{fe, be}})
+	assert.NoError(t, err)
+
+	var button v1alpha1.UIButton
+	assert.NoError(t, f.Get(types.NamespacedName{Name: uibutton.RollbackButtonName("fe")}, &button))
+	assert.Equal(t, "fe", button.Spec.Location.ComponentID)
+	assert.True(t, button.Spec.RequiresConfirmation)
+
+	err = f.Get(types.NamespacedName{Name: uibutton.RollbackButtonName("be")}, &button)
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestResetVolumesButton(t *testing.T) {
+	f := newAPIFixture(t)
+	db := manifestbuilder.New(f, "db").WithK8sYAML(testyaml.DatabaseWithVolumesYAML).Build()
+	kTarget := db.K8sTarget()
+	kTarget.ResetVolumes = []string{"pgdata", "uploads"}
+	db = db.WithDeployTarget(kTarget)
+	fe := manifestbuilder.New(f, "fe").WithK8sYAML(testyaml.SanchoYAML).Build()
+	nn := types.NamespacedName{Name: "tiltfile"}
+	tf := &v1alpha1.Tiltfile{ObjectMeta: metav1.ObjectMeta{Name: "tiltfile"}}
+	err := f.updateOwnedObjects(nn, tf,
+		&tiltfile.TiltfileLoadResult{Manifests: []model.Manifest{db, fe}})
+	assert.NoError(t, err)
+
+	var button v1alpha1.UIButton
+	assert.NoError(t, f.Get(types.NamespacedName{Name: uibutton.ResetVolumesButtonName("db")}, &button))
+	assert.True(t, button.Spec.RequiresConfirmation)
+
+	var ka v1alpha1.KubernetesApply
+	assert.NoError(t, f.Get(types.NamespacedName{Name: "db"}, &ka))
+	assert.Equal(t, []string{"pgdata", "uploads"}, ka.Spec.ResetVolumes)
+
+	err = f.Get(types.NamespacedName{Name: uibutton.ResetVolumesButtonName("fe")}, &button)
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestLocalIngressFromHostnames(t *testing.T) {
+	f := newAPIFixture(t)
+	fe := manifestbuilder.New(f, "fe").WithK8sYAML(testyaml.SanchoYAML).Build()
+	kTarget := fe.K8sTarget()
+	kTarget.Hostnames = []model.Hostname{{Host: "shop.localhost", Path: "/"}}
+	fe = fe.WithDeployTarget(kTarget)
+
+	api := manifestbuilder.New(f, "api").WithK8sYAML(testyaml.SanchoYAML).Build()
+	kTarget = api.K8sTarget()
+	kTarget.Hostnames = []model.Hostname{{Host: "shop.localhost", Path: "/api"}}
+	api = api.WithDeployTarget(kTarget)
+
+	nn := types.NamespacedName{Name: "tiltfile"}
+	tf := &v1alpha1.Tiltfile{ObjectMeta: metav1.ObjectMeta{Name: "tiltfile"}}
+	err := f.updateOwnedObjects(nn, tf,
+		&tiltfile.TiltfileLoadResult{Manifests: []model.Manifest{fe, api}})
+	assert.NoError(t, err)
+
+	var li v1alpha1.LocalIngress
+	assert.NoError(t, f.Get(types.NamespacedName{Name: "shop.localhost"}, &li))
+	assert.Equal(t, "fe", li.Annotations[v1alpha1.AnnotationManifest])
+	assert.Equal(t, []v1alpha1.LocalIngressRoute{
+		{Path: "/", Resource: "fe"},
+		{Path: "/api", Resource: "api"},
+	}, li.Spec.Routes)
+}
+
 func TestTwoManifestsShareImage(t *testing.T) {
 	f := newAPIFixture(t)
 	target := model.MustNewImageTarget(SanchoRef).
@@ -279,6 +445,88 @@ func TestCreateClusterDefaultRegistry(t *testing.T) {
 	require.Equal(t, "fake-repo", cluster.Spec.DefaultRegistry.SingleName, "Default registry single name")
 }
 
+func TestCreateClusterBuildKit(t *testing.T) {
+	f := newAPIFixture(t)
+	fe := manifestbuilder.New(f, "fe").
+		WithImageTarget(NewSanchoDockerBuildImageTarget(f)).
+		WithK8sYAML(testyaml.SanchoYAML).
+		Build()
+	tf := &v1alpha1.Tiltfile{
+		ObjectMeta: metav1.ObjectMeta{Name: model.MainTiltfileManifestName.String()},
+	}
+	nn := apis.Key(tf)
+	tlr := &tiltfile.TiltfileLoadResult{
+		Manifests: []model.Manifest{fe},
+		BuildKit:  &v1alpha1.BuildKitConnection{Address: "kube-pod://buildkitd-0"},
+	}
+	err := f.updateOwnedObjects(nn, tf, tlr)
+	assert.NoError(t, err)
+
+	var cluster v1alpha1.Cluster
+	require.NoError(t, f.Get(types.NamespacedName{Name: "default"}, &cluster))
+	require.NotNil(t, cluster.Spec.BuildKit, ".Spec.BuildKit was nil")
+	require.Equal(t, "kube-pod://buildkitd-0", cluster.Spec.BuildKit.Address)
+}
+
+func TestCreateDeclaredK8sClusters(t *testing.T) {
+	f := newAPIFixture(t)
+	fe := manifestbuilder.New(f, "fe").
+		WithK8sYAML(testyaml.SanchoYAML).
+		Build()
+	tf := &v1alpha1.Tiltfile{
+		ObjectMeta: metav1.ObjectMeta{Name: model.MainTiltfileManifestName.String()},
+	}
+	nn := apis.Key(tf)
+	edge := &v1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "edge"},
+		Spec: v1alpha1.ClusterSpec{
+			Connection: &v1alpha1.ClusterConnection{
+				Kubernetes: &v1alpha1.KubernetesClusterConnection{Context: "kind-edge"},
+			},
+		},
+	}
+	tlr := &tiltfile.TiltfileLoadResult{
+		Manifests:   []model.Manifest{fe},
+		K8sClusters: []*v1alpha1.Cluster{edge},
+	}
+	err := f.updateOwnedObjects(nn, tf, tlr)
+	assert.NoError(t, err)
+
+	var cluster v1alpha1.Cluster
+	require.NoError(t, f.Get(types.NamespacedName{Name: "default"}, &cluster))
+	require.NoError(t, f.Get(types.NamespacedName{Name: "edge"}, &cluster))
+	assert.Equal(t, "kind-edge", cluster.Spec.Connection.Kubernetes.Context)
+
+	// Clusters that the Tiltfile no longer declares get deleted.
+	tlr.K8sClusters = nil
+	err = f.updateOwnedObjects(nn, tf, tlr)
+	assert.NoError(t, err)
+	assert.True(t, apierrors.IsNotFound(f.Get(types.NamespacedName{Name: "edge"}, &cluster)))
+}
+
+func TestCreateClusterContainerEngine(t *testing.T) {
+	f := newAPIFixture(t)
+	fe := manifestbuilder.New(f, "fe").WithDockerCompose().Build()
+	tf := &v1alpha1.Tiltfile{
+		ObjectMeta: metav1.ObjectMeta{Name: model.MainTiltfileManifestName.String()},
+	}
+	nn := apis.Key(tf)
+	tlr := &tiltfile.TiltfileLoadResult{
+		Manifests: []model.Manifest{fe},
+		ContainerEngine: model.ContainerEngineSettings{
+			Engine: model.ContainerEnginePodman,
+			Host:   "unix:///run/podman/podman.sock",
+		},
+	}
+	err := f.updateOwnedObjects(nn, tf, tlr)
+	assert.NoError(t, err)
+
+	var cluster v1alpha1.Cluster
+	require.NoError(t, f.Get(types.NamespacedName{Name: v1alpha1.ClusterNameDocker}, &cluster))
+	require.NotNil(t, cluster.Spec.Connection.Docker, ".Spec.Connection.Docker was nil")
+	require.Equal(t, "unix:///run/podman/podman.sock", cluster.Spec.Connection.Docker.Host)
+}
+
 // Ensure that we emit disable-related objects/field appropriately
 func TestDisableObjects(t *testing.T) {
 	f := newAPIFixture(t)
@@ -6,6 +6,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/jonboulle/clockwork"
 	"github.com/pkg/errors"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -48,6 +49,7 @@ type Reconciler struct {
 	indexer              *indexer.Indexer
 	requeuer             *indexer.Requeuer
 	engineMode           store.EngineMode
+	fieldTracker         *trigger.ObjectFieldTracker
 	loadCount            int // used to differentiate spans
 
 	runs map[types.NamespacedName]*runStatus
@@ -97,6 +99,7 @@ func NewReconciler(
 		runs:                 make(map[types.NamespacedName]*runStatus),
 		requeuer:             indexer.NewRequeuer(),
 		engineMode:           engineMode,
+		fieldTracker:         trigger.NewObjectFieldTracker(clockwork.NewRealClock()),
 		k8sContextOverride:   k8sContextOverride,
 		k8sNamespaceOverride: k8sNamespaceOverride,
 	}
@@ -117,6 +120,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 
 	if apierrors.IsNotFound(err) || !tf.ObjectMeta.DeletionTimestamp.IsZero() {
 		r.deleteExistingRun(nn)
+		r.fieldTracker.Forget(nn)
 
 		// Delete owned objects
 		err := updateOwnedObjects(ctx, r.ctrlClient, nn, nil, nil, false, r.engineMode, r.defaultK8sConnection())
@@ -162,6 +166,13 @@ func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 		if err != nil {
 			return ctrl.Result{}, err
 		}
+		lastFieldChange, err := r.fieldTracker.LastChange(ctx, r.ctrlClient, nn, tf.Spec.RestartOn)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if timecmp.After(lastFieldChange, lastRestartEventTime) {
+			lastRestartEventTime = lastFieldChange
+		}
 		queue, err := configmap.TriggerQueue(ctx, r.ctrlClient)
 		if err != nil {
 			return ctrl.Result{}, err
@@ -326,6 +337,11 @@ func (r *Reconciler) run(ctx context.Context, nn types.NamespacedName, tf *v1alp
 		r.dockerClient.SetOrchestrator(model.OrchestratorDC)
 	}
 
+	// Only the main Tiltfile picks the container engine.
+	if tf.Name == model.MainTiltfileManifestName.String() {
+		r.dockerClient.SetContainerEngine(tlr.ContainerEngine)
+	}
+
 	if requiresDocker(tlr) {
 		dockerErr := r.dockerClient.CheckConnected()
 		if tlr.Error == nil && dockerErr != nil {
@@ -377,11 +393,15 @@ func (r *Reconciler) handleLoaded(
 		FinishTime:            time.Now(),
 		Err:                   tlr.Error,
 		Features:              tlr.FeatureFlags,
+		FeatureSources:        tlr.FeatureSources,
 		TeamID:                tlr.TeamID,
 		TelemetrySettings:     tlr.TelemetrySettings,
 		Secrets:               tlr.Secrets,
 		AnalyticsTiltfileOpt:  tlr.AnalyticsOpt,
 		DockerPruneSettings:   tlr.DockerPruneSettings,
+		SnapshotSettings:      tlr.SnapshotSettings,
+		IdleSettings:          tlr.IdleSettings,
+		BandwidthSettings:     tlr.BandwidthSettings,
 		CheckpointAtExecStart: entry.CheckpointAtExecStart,
 		VersionSettings:       tlr.VersionSettings,
 		UpdateSettings:        tlr.UpdateSettings,
@@ -459,7 +479,7 @@ func requiresDocker(tlr tiltfile.TiltfileLoadResult) bool {
 
 	for _, m := range tlr.Manifests {
 		for _, iTarget := range m.ImageTargets {
-			if iTarget.IsDockerBuild() {
+			if iTarget.IsDockerBuild() || iTarget.IsKoBuild() {
 				return true
 			}
 		}
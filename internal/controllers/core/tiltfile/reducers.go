@@ -3,6 +3,7 @@ package tiltfile
 import (
 	"context"
 
+	"github.com/tilt-dev/tilt/internal/feature"
 	"github.com/tilt-dev/tilt/internal/sliceutils"
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/pkg/logger"
@@ -128,10 +129,15 @@ func HandleConfigsReloaded(
 			// Enable any new features in the partial state.
 			if len(state.Features) == 0 {
 				state.Features = event.Features
+				state.FeatureSources = event.FeatureSources
 			} else {
+				if state.FeatureSources == nil {
+					state.FeatureSources = make(map[string]feature.Source)
+				}
 				for feature, val := range event.Features {
 					if val {
 						state.Features[feature] = val
+						state.FeatureSources[feature] = event.FeatureSources[feature]
 					}
 				}
 			}
@@ -189,10 +195,14 @@ func HandleConfigsReloaded(
 	// Global state that's only configurable from the main manifest.
 	if isMainTiltfile {
 		state.Features = event.Features
+		state.FeatureSources = event.FeatureSources
 		state.TelemetrySettings = event.TelemetrySettings
 		state.VersionSettings = event.VersionSettings
 		state.AnalyticsTiltfileOpt = event.AnalyticsTiltfileOpt
 		state.UpdateSettings = event.UpdateSettings
 		state.DockerPruneSettings = event.DockerPruneSettings
+		state.SnapshotSettings = event.SnapshotSettings
+		state.IdleSettings = event.IdleSettings
+		state.BandwidthSettings = event.BandwidthSettings
 	}
 }
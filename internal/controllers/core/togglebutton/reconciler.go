@@ -70,6 +70,8 @@ func indexToggleButton(obj client.Object) []indexer.Key {
 	return result
 }
 
+func (r *Reconciler) CrashRecoverable() {}
+
 func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
 	nn := request.NamespacedName
 
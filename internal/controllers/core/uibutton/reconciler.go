@@ -32,6 +32,8 @@ func NewReconciler(client ctrlclient.Client, wsList *server.WebsocketList, store
 	}
 }
 
+func (r *Reconciler) CrashRecoverable() {}
+
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	button := &v1alpha1.UIButton{}
 	err := r.client.Get(ctx, req.NamespacedName, button)
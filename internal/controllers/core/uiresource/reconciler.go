@@ -38,6 +38,8 @@ func NewReconciler(client ctrlclient.Client, wsList *server.WebsocketList, store
 	}
 }
 
+func (r *Reconciler) CrashRecoverable() {}
+
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	resource := &v1alpha1.UIResource{}
 	err := r.client.Get(ctx, req.NamespacedName, resource)
@@ -35,6 +35,8 @@ func NewReconciler(client ctrlclient.Client, wsList *server.WebsocketList) *Reco
 	}
 }
 
+func (r *Reconciler) CrashRecoverable() {}
+
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	session := &v1alpha1.UISession{}
 	err := r.client.Get(ctx, req.NamespacedName, session)
@@ -18,6 +18,8 @@ import (
 	"github.com/tilt-dev/tilt/internal/controllers/core/kubernetesapply"
 	"github.com/tilt-dev/tilt/internal/controllers/core/kubernetesdiscovery"
 	"github.com/tilt-dev/tilt/internal/controllers/core/liveupdate"
+	"github.com/tilt-dev/tilt/internal/controllers/core/localingress"
+	"github.com/tilt-dev/tilt/internal/controllers/core/nestedtilt"
 	"github.com/tilt-dev/tilt/internal/controllers/core/podlogstream"
 	"github.com/tilt-dev/tilt/internal/controllers/core/portforward"
 	"github.com/tilt-dev/tilt/internal/controllers/core/tiltfile"
@@ -61,6 +63,8 @@ func ProvideControllers(
 	dcr *dockercomposeservice.Reconciler,
 	imr *imagemap.Reconciler,
 	dclsr *dockercomposelogstream.Reconciler,
+	ntr *nestedtilt.Reconciler,
+	lir *localingress.Reconciler,
 ) []Controller {
 	return []Controller{
 		fileWatch,
@@ -84,6 +88,8 @@ func ProvideControllers(
 		dcr,
 		imr,
 		dclsr,
+		ntr,
+		lir,
 	}
 }
 
@@ -113,4 +119,6 @@ var WireSet = wire.NewSet(
 	dockercomposeservice.WireSet,
 	imagemap.WireSet,
 	dockercomposelogstream.WireSet,
+	nestedtilt.WireSet,
+	localingress.WireSet,
 )
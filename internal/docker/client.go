@@ -20,6 +20,9 @@ import (
 	"github.com/docker/docker/api/types"
 	mobycontainer "github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/volume"
 	"github.com/docker/docker/client"
 	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/docker/docker/registry"
@@ -85,6 +88,10 @@ type Client interface {
 	// relevant for the switchClient which has clients for both types.
 	ForOrchestrator(orc model.Orchestrator) Client
 
+	// Set the container engine that the project asked for. This is only relevant
+	// to switchClient, which replaces its clients when the engine has its own host.
+	SetContainerEngine(settings model.ContainerEngineSettings)
+
 	ContainerInspect(ctx context.Context, contianerID string) (types.ContainerJSON, error)
 	ContainerList(ctx context.Context, options types.ContainerListOptions) ([]types.Container, error)
 	ContainerRestartNoWait(ctx context.Context, containerID string) error
@@ -101,11 +108,16 @@ type Client interface {
 	ImageTag(ctx context.Context, source, target string) error
 	ImageInspectWithRaw(ctx context.Context, imageID string) (types.ImageInspect, []byte, error)
 	ImageList(ctx context.Context, options types.ImageListOptions) ([]types.ImageSummary, error)
+	ImageHistory(ctx context.Context, imageID string) ([]image.HistoryResponseItem, error)
 	ImageRemove(ctx context.Context, imageID string, options types.ImageRemoveOptions) ([]types.ImageDeleteResponseItem, error)
 
 	NewVersionError(APIrequired, feature string) error
 	BuildCachePrune(ctx context.Context, opts types.BuildCachePruneOptions) (*types.BuildCachePruneReport, error)
 	ContainersPrune(ctx context.Context, pruneFilters filters.Args) (types.ContainersPruneReport, error)
+
+	VolumeList(ctx context.Context, filter filters.Args) (volume.VolumeListOKBody, error)
+	VolumeRemove(ctx context.Context, volumeID string, force bool) error
+	DiskUsage(ctx context.Context) (types.DiskUsage, error)
 }
 
 // Add-on interface for a client that manages multiple clients transparently.
@@ -224,6 +236,11 @@ func SupportsBuildkit(v types.Version, env Env) bool {
 		return false
 	}
 
+	if IsPodman(v) {
+		// Podman's Docker-compatible API can't run BuildKit sessions.
+		return false
+	}
+
 	version, err := semver.ParseTolerant(v.APIVersion)
 	if err != nil {
 		// If the server version doesn't parse, disable buildkit
@@ -361,8 +378,9 @@ func (c *Cli) initAuthConfigs(ctx context.Context) {
 	})
 }
 
-func (c *Cli) CheckConnected() error                  { return nil }
-func (c *Cli) SetOrchestrator(orc model.Orchestrator) {}
+func (c *Cli) CheckConnected() error                                     { return nil }
+func (c *Cli) SetOrchestrator(orc model.Orchestrator)                    {}
+func (c *Cli) SetContainerEngine(settings model.ContainerEngineSettings) {}
 func (c *Cli) ForOrchestrator(orc model.Orchestrator) Client {
 	return c
 }
@@ -534,6 +552,7 @@ func (c *Cli) ImageBuild(ctx context.Context, buildContext io.Reader, options Bu
 	opts.NetworkMode = options.Network
 	opts.CacheFrom = options.CacheFrom
 	opts.PullParent = options.PullParent
+	opts.NoCache = options.NoCache
 	opts.Platform = options.Platform
 
 	if len(options.SyncedDirs) > 0 {
@@ -644,6 +663,32 @@ func (c *Cli) ExecInContainer(ctx context.Context, cID container.ID, cmd model.C
 	}
 }
 
+// Labels the volumes that Docker creates for these mounts, so that
+// the Docker pruner knows that we created them.
+//
+// Docker only applies the labels when it creates a volume, so volumes that
+// already exist keep their labels.
+func withBuiltByTiltVolumeLabels(mounts []mount.Mount) []mount.Mount {
+	result := make([]mount.Mount, 0, len(mounts))
+	for _, m := range mounts {
+		if m.Type == mount.TypeVolume {
+			opts := mount.VolumeOptions{}
+			if m.VolumeOptions != nil {
+				opts = *m.VolumeOptions
+			}
+			labels := make(map[string]string, len(opts.Labels)+1)
+			for k, v := range opts.Labels {
+				labels[k] = v
+			}
+			labels[BuiltByLabel] = BuiltByValue
+			opts.Labels = labels
+			m.VolumeOptions = &opts
+		}
+		result = append(result, m)
+	}
+	return result
+}
+
 func (c *Cli) Run(ctx context.Context, opts RunConfig) (RunResult, error) {
 	if opts.Pull {
 		namedRef, ok := opts.Image.(reference.Named)
@@ -664,7 +709,7 @@ func (c *Cli) Run(ctx context.Context, opts RunConfig) (RunResult, error) {
 	}
 
 	hc := &mobycontainer.HostConfig{
-		Mounts: opts.Mounts,
+		Mounts: withBuiltByTiltVolumeLabels(opts.Mounts),
 	}
 
 	createResp, err := c.Client.ContainerCreate(ctx,
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/mount"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/tilt-dev/clusterid"
@@ -32,6 +33,7 @@ func TestSupportsBuildkit(t *testing.T) {
 		{types.Version{APIVersion: "1.40", Experimental: false}, Env{}, true},
 		{types.Version{APIVersion: "garbage", Experimental: false}, Env{}, false},
 		{types.Version{APIVersion: "1.39", Experimental: true}, Env{IsOldMinikube: true}, false},
+		{types.Version{APIVersion: "1.41", Components: []types.ComponentVersion{{Name: "Podman Engine"}}}, Env{}, false},
 	}
 
 	for i, c := range cases {
@@ -353,6 +355,10 @@ func TestProvideClusterProduct(t *testing.T) {
 
 	for i, c := range cases {
 		t.Run(fmt.Sprintf("Case%d", i), func(t *testing.T) {
+			// Make sure we don't pick up sockets from the machine running the tests.
+			t.Setenv("HOME", t.TempDir())
+			t.Setenv("XDG_RUNTIME_DIR", "")
+
 			origEnv := map[string]string{}
 			for _, k := range envVars {
 				origEnv[k] = os.Getenv(k)
@@ -380,3 +386,24 @@ func TestProvideClusterProduct(t *testing.T) {
 		})
 	}
 }
+
+func TestBuiltByTiltVolumeLabels(t *testing.T) {
+	mounts := []mount.Mount{
+		{Type: mount.TypeBind, Source: "/src", Target: "/app"},
+		{Type: mount.TypeVolume, Source: "data", Target: "/data"},
+		{
+			Type:          mount.TypeVolume,
+			Source:        "cache",
+			Target:        "/cache",
+			VolumeOptions: &mount.VolumeOptions{Labels: map[string]string{"purpose": "cache"}},
+		},
+	}
+
+	result := withBuiltByTiltVolumeLabels(mounts)
+	assert.Nil(t, result[0].VolumeOptions)
+	assert.Equal(t, BuiltByTiltLabel, result[1].VolumeOptions.Labels)
+	assert.Equal(t, map[string]string{"purpose": "cache", BuiltByLabel: BuiltByValue}, result[2].VolumeOptions.Labels)
+
+	// The caller's mounts are unchanged.
+	assert.Equal(t, map[string]string{"purpose": "cache"}, mounts[2].VolumeOptions.Labels)
+}
@@ -147,6 +147,7 @@ func ProvideLocalEnv(
 	if err != nil {
 		result.Error = err
 	}
+	result = useDetectedDaemonHost(creator, product, kubeContext, result)
 
 	// if the ClusterEnv host is the same, use it to infer some properties
 	if Env(clusterEnv).DaemonHost() == result.DaemonHost() {
@@ -252,6 +253,7 @@ func ProvideClusterEnv(
 		if err != nil {
 			env.Error = err
 		}
+		env = useDetectedDaemonHost(creator, product, kubeContext, env)
 	}
 
 	// some local Docker-based solutions expose their socket so we can build
@@ -324,28 +326,39 @@ func willBuildToKubeContext(product clusterid.Product, kubeContext k8s.KubeConte
 		return isDefaultHost(env)
 	case clusterid.ProductRancherDesktop:
 		// N.B. Rancher Desktop creates a Docker socket at /var/run/docker.sock
-		// (the same as Docker Desktop)
-		return isDefaultHost(env)
+		// (the same as Docker Desktop) when it has admin privileges,
+		// and at ~/.rd/docker.sock when it doesn't.
+		if isDefaultHost(env) {
+			return true
+		}
+		if _, host, ok := strings.Cut(env.DaemonHost(), "unix://"); ok {
+			return strings.HasSuffix(host, string(filepath.Separator)+filepath.Join(".rd", "docker.sock"))
+		}
 	case clusterid.ProductColima:
 		if _, host, ok := strings.Cut(env.DaemonHost(), "unix://"); ok {
-			// Socket is stored in a directory named `.colima[-$profile]`
+			// Socket is stored in a directory named after the Colima profile.
 			// For example:
+			// 	colima default profile -> ~/.colima/default/docker.sock
+			// 	colima "test" profile -> ~/.colima/test/docker.sock
+			//
+			// Older versions of Colima used:
 			// 	colima default profile -> ~/.colima/docker.sock
 			// 	colima "test" profile -> ~/.colima-test/docker.sock
 			//
-			// NOTE: ~ is used for legibility here; in practice, the paths MUST
-			// be fully qualified!
-			//
-			// We look for the existence of the `/` in the path after the dir
-			// to prevent mismatching Colima profiles: e.g. a KubeContext of
-			// `colima-test` and `DOCKER_HOST=unix://~/.colima/docker.sock`
+			// We match on the profile to prevent mismatching Colima profiles:
+			// e.g. a KubeContext of `colima-test` and
+			// `DOCKER_HOST=unix://~/.colima/default/docker.sock`
 			// should NOT be considered as building to the context, as these
 			// are two distinct Colima VMs/profiles. (This would almost always
 			// be indicative of user error, but we respect the Docker + K8s
 			// configs as provided to Tilt as-is. Providing a warning upon
 			// detecting a likely misconfiguration here is probably a good idea
 			// in the future, however!)
-			return strings.Contains(host, string(kubeContext)+string(filepath.Separator))
+			for _, sock := range colimaSockets(string(filepath.Separator), colimaProfile(kubeContext)) {
+				if strings.HasSuffix(host, sock) {
+					return true
+				}
+			}
 		}
 	}
 	return false
@@ -7,6 +7,8 @@ import (
 	"github.com/docker/distribution/reference"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/volume"
 
 	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/pkg/model"
@@ -26,6 +28,8 @@ func newExplodingClient(err error) explodingClient {
 
 func (c explodingClient) SetOrchestrator(orc model.Orchestrator) {
 }
+func (c explodingClient) SetContainerEngine(settings model.ContainerEngineSettings) {
+}
 func (c explodingClient) ForOrchestrator(orc model.Orchestrator) Client {
 	return c
 }
@@ -74,6 +78,9 @@ func (c explodingClient) ImageInspectWithRaw(ctx context.Context, imageID string
 func (c explodingClient) ImageList(ctx context.Context, options types.ImageListOptions) ([]types.ImageSummary, error) {
 	return nil, c.err
 }
+func (c explodingClient) ImageHistory(ctx context.Context, imageID string) ([]image.HistoryResponseItem, error) {
+	return nil, c.err
+}
 func (c explodingClient) ImageRemove(ctx context.Context, imageID string, options types.ImageRemoveOptions) ([]types.ImageDeleteResponseItem, error) {
 	return nil, c.err
 }
@@ -86,5 +93,14 @@ func (c explodingClient) BuildCachePrune(ctx context.Context, opts types.BuildCa
 func (c explodingClient) ContainersPrune(ctx context.Context, pruneFilters filters.Args) (types.ContainersPruneReport, error) {
 	return types.ContainersPruneReport{}, c.err
 }
+func (c explodingClient) VolumeList(ctx context.Context, filter filters.Args) (volume.VolumeListOKBody, error) {
+	return volume.VolumeListOKBody{}, c.err
+}
+func (c explodingClient) VolumeRemove(ctx context.Context, volumeID string, force bool) error {
+	return c.err
+}
+func (c explodingClient) DiskUsage(ctx context.Context) (types.DiskUsage, error) {
+	return types.DiskUsage{}, c.err
+}
 
 var _ Client = &explodingClient{}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/docker/go-units"
@@ -15,6 +16,8 @@ import (
 	"github.com/docker/distribution/reference"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/volume"
 
 	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/pkg/model"
@@ -117,6 +120,9 @@ type FakeClient struct {
 	// Images returned by ImageInspect.
 	Images map[string]types.ImageInspect
 
+	// Layers returned by ImageHistory.
+	ImageHistories map[string][]image.HistoryResponseItem
+
 	// Containers returned by ContainerInspect
 	Containers map[string]types.ContainerState
 
@@ -125,6 +131,8 @@ type FakeClient struct {
 	ImageAlwaysExists bool
 
 	Orchestrator      model.Orchestrator
+	FakeServerVersion *types.Version
+	ContainerEngine   model.ContainerEngineSettings
 	CheckConnectedErr error
 
 	ThrowNewVersionError   bool
@@ -134,6 +142,11 @@ type FakeClient struct {
 	ContainersPruneErr     error
 	ContainersPruneFilters filters.Args
 	ContainersPruned       []string
+
+	// Volumes returned by VolumeList, keyed by name.
+	Volumes          map[string]*types.Volume
+	VolumeListFilter filters.Args
+	RemovedVolumes   []string
 }
 
 var _ Client = &FakeClient{}
@@ -155,6 +168,9 @@ func (c *FakeClient) SetOrchestrator(orc model.Orchestrator) {
 func (c *FakeClient) ForOrchestrator(orc model.Orchestrator) Client {
 	return c
 }
+func (c *FakeClient) SetContainerEngine(settings model.ContainerEngineSettings) {
+	c.ContainerEngine = settings
+}
 func (c *FakeClient) CheckConnected() error {
 	return c.CheckConnectedErr
 }
@@ -165,6 +181,9 @@ func (c *FakeClient) BuilderVersion() types.BuilderVersion {
 	return types.BuilderV1
 }
 func (c *FakeClient) ServerVersion() types.Version {
+	if c.FakeServerVersion != nil {
+		return *c.FakeServerVersion
+	}
 	return types.Version{
 		Arch:    "amd64",
 		Version: "20.10.11",
@@ -306,6 +325,14 @@ func (c *FakeClient) ImageInspectWithRaw(ctx context.Context, imageID string) (t
 	return types.ImageInspect{}, nil, newNotFoundErrorf("fakeClient.Images key: %s", imageID)
 }
 
+func (c *FakeClient) ImageHistory(ctx context.Context, imageID string) ([]image.HistoryResponseItem, error) {
+	result, ok := c.ImageHistories[imageID]
+	if ok {
+		return result, nil
+	}
+	return nil, newNotFoundErrorf("fakeClient.ImageHistories key: %s", imageID)
+}
+
 func (c *FakeClient) ImageList(ctx context.Context, options types.ImageListOptions) ([]types.ImageSummary, error) {
 	c.ImageListOpts = append(c.ImageListOpts, options)
 	summaries := make([]types.ImageSummary, c.ImageListCount)
@@ -372,6 +399,52 @@ func (c *FakeClient) ContainersPrune(ctx context.Context, pruneFilters filters.A
 	return report, nil
 }
 
+func (c *FakeClient) VolumeList(ctx context.Context, filter filters.Args) (volume.VolumeListOKBody, error) {
+	c.VolumeListFilter = filter.Clone()
+	result := volume.VolumeListOKBody{}
+	for _, v := range c.Volumes {
+		if filter.ExactMatch("dangling", "true") && v.UsageData != nil && v.UsageData.RefCount > 0 {
+			continue
+		}
+		if filter.Contains("label") {
+			matches := true
+			for _, l := range filter.Get("label") {
+				parts := strings.SplitN(l, "=", 2)
+				val, ok := v.Labels[parts[0]]
+				if !ok || (len(parts) == 2 && val != parts[1]) {
+					matches = false
+				}
+			}
+			if !matches {
+				continue
+			}
+		}
+		result.Volumes = append(result.Volumes, v)
+	}
+	sort.Slice(result.Volumes, func(i, j int) bool {
+		return result.Volumes[i].Name < result.Volumes[j].Name
+	})
+	return result, nil
+}
+
+func (c *FakeClient) VolumeRemove(ctx context.Context, volumeID string, force bool) error {
+	if _, ok := c.Volumes[volumeID]; !ok {
+		return newNotFoundErrorf("fakeClient.Volumes key: %s", volumeID)
+	}
+	delete(c.Volumes, volumeID)
+	c.RemovedVolumes = append(c.RemovedVolumes, volumeID)
+	sort.Strings(c.RemovedVolumes)
+	return nil
+}
+
+func (c *FakeClient) DiskUsage(ctx context.Context) (types.DiskUsage, error) {
+	result := types.DiskUsage{}
+	for _, v := range c.Volumes {
+		result.Volumes = append(result.Volumes, v)
+	}
+	return result, nil
+}
+
 var _ Client = &FakeClient{}
 
 type fakeDockerResponse struct {
@@ -17,6 +17,7 @@ type BuildOptions struct {
 	Network            string
 	CacheFrom          []string
 	PullParent         bool
+	NoCache            bool
 	Platform           string
 	ExtraTags          []string
 	ForceLegacyBuilder bool
@@ -0,0 +1,110 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+)
+
+// Podman serves a Docker-compatible API, so we build and run containers
+// on it with the same client we use for Docker.
+//
+// The gaps in that API (BuildKit sessions, the build cache) are handled
+// where we call them, with IsPodman().
+
+// Where rootful Podman puts its API socket on Linux.
+const rootfulPodmanSocket = "/run/podman/podman.sock"
+
+// Runs the podman CLI. Replaced in tests.
+var podmanOutput = func(ctx context.Context, args ...string) (string, error) {
+	out, err := exec.CommandContext(ctx, "podman", args...).Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// Finds the address of the Podman API socket.
+//
+// Checks CONTAINER_HOST (Podman's equivalent of DOCKER_HOST) and the
+// well-known socket paths first, then asks podman itself.
+func DetectPodmanHost(ctx context.Context) (string, error) {
+	host := os.Getenv("CONTAINER_HOST")
+	if host != "" {
+		return host, nil
+	}
+
+	candidates := []string{}
+	home, err := os.UserHomeDir()
+	if err == nil {
+		candidates = append(candidates, podmanSockets(home)...)
+	}
+	candidates = append(candidates, rootfulPodmanSocket)
+	for _, sock := range candidates {
+		if socketExists(sock) {
+			return fmt.Sprintf("unix://%s", sock), nil
+		}
+	}
+
+	// On macOS and Windows, the socket of the running machine.
+	out, err := podmanOutput(ctx, "machine", "inspect", "--format", "{{.ConnectionInfo.PodmanSocket.Path}}")
+	if err == nil {
+		for _, line := range strings.Split(out, "\n") {
+			path := strings.TrimSpace(line)
+			if path != "" && path != "<no value>" {
+				return podmanSocketHost(path), nil
+			}
+		}
+	}
+
+	// On Linux, the socket that systemd activates.
+	out, err = podmanOutput(ctx, "info", "--format", "{{.Host.RemoteSocket.Path}}")
+	if err == nil && out != "" && socketExists(strings.TrimPrefix(out, "unix://")) {
+		return podmanSocketHost(out), nil
+	}
+
+	return "", fmt.Errorf("couldn't find the Podman API socket. " +
+		"Start it with `podman machine start` (macOS and Windows) or " +
+		"`systemctl --user start podman.socket` (Linux), or set CONTAINER_HOST")
+}
+
+// Podman reports socket paths without a scheme.
+func podmanSocketHost(path string) string {
+	if strings.Contains(path, "://") {
+		return path
+	}
+	if runtime.GOOS == "windows" && strings.HasPrefix(path, `\\.\pipe\`) {
+		return "npipe://" + filepath.ToSlash(path)
+	}
+	return "unix://" + path
+}
+
+// Creates a client for the engine at the given host.
+//
+// The host is also added to the env, so that subprocesses (like
+// a custom_build script) talk to the same engine.
+func newHostClient(ctx context.Context, creator ClientCreator, host string) Client {
+	d, err := creator.FromEnvMap(map[string]string{"DOCKER_HOST": host})
+	return NewDockerClient(ctx, Env{
+		Client:  d,
+		Environ: []string{fmt.Sprintf("DOCKER_HOST=%s", host)},
+		Error:   err,
+	})
+}
+
+// Podman serves the Docker API, but can't run BuildKit sessions,
+// and doesn't have a separate build cache.
+func IsPodman(v types.Version) bool {
+	for _, c := range v.Components {
+		if strings.HasPrefix(c.Name, "Podman") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func withPodmanOutput(t *testing.T, fn func(args ...string) (string, error)) {
+	orig := podmanOutput
+	podmanOutput = func(ctx context.Context, args ...string) (string, error) {
+		return fn(args...)
+	}
+	t.Cleanup(func() {
+		podmanOutput = orig
+	})
+}
+
+func noPodmanCLI(args ...string) (string, error) {
+	return "", fmt.Errorf("exec: \"podman\": executable file not found in $PATH")
+}
+
+func TestDetectPodmanHostFromEnv(t *testing.T) {
+	newSocketFixture(t)
+	t.Setenv("CONTAINER_HOST", "ssh://core@localhost:53000/run/user/501/podman/podman.sock")
+	withPodmanOutput(t, noPodmanCLI)
+
+	host, err := DetectPodmanHost(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "ssh://core@localhost:53000/run/user/501/podman/podman.sock", host)
+}
+
+func TestDetectPodmanHostMachineSocket(t *testing.T) {
+	f := newSocketFixture(t)
+	t.Setenv("CONTAINER_HOST", "")
+	withPodmanOutput(t, noPodmanCLI)
+	expected := f.listen(".local/share/containers/podman/machine/podman.sock")
+
+	host, err := DetectPodmanHost(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, expected, host)
+}
+
+func TestDetectPodmanHostFromCLI(t *testing.T) {
+	newSocketFixture(t)
+	t.Setenv("CONTAINER_HOST", "")
+	withPodmanOutput(t, func(args ...string) (string, error) {
+		if args[0] == "machine" {
+			return "/Users/tilt/.local/share/containers/podman/machine/qemu/podman.sock\n", nil
+		}
+		return noPodmanCLI(args...)
+	})
+
+	host, err := DetectPodmanHost(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "unix:///Users/tilt/.local/share/containers/podman/machine/qemu/podman.sock", host)
+}
+
+func TestDetectPodmanHostNotFound(t *testing.T) {
+	newSocketFixture(t)
+	t.Setenv("CONTAINER_HOST", "")
+	withPodmanOutput(t, noPodmanCLI)
+
+	_, err := DetectPodmanHost(context.Background())
+	if err == nil {
+		t.Skip("this machine runs rootful podman")
+	}
+	assert.Contains(t, err.Error(), "couldn't find the Podman API socket")
+}
+
+func TestSwitchCliContainerEngine(t *testing.T) {
+	local := NewFakeClient()
+	podman := NewFakeClient()
+	hosts := []string{}
+	cli := ProvideSwitchCli(context.Background(), ClusterClient(local), LocalClient(local)).(*switchCli)
+	cli.newEngineCli = func(host string) Client {
+		hosts = append(hosts, host)
+		return podman
+	}
+
+	_, _ = cli.ImageList(context.Background(), types.ImageListOptions{})
+	assert.Len(t, local.ImageListOpts, 1)
+
+	settings := model.ContainerEngineSettings{
+		Engine: model.ContainerEnginePodman,
+		Host:   "unix:///run/podman/podman.sock",
+	}
+	cli.SetContainerEngine(settings)
+	cli.SetContainerEngine(settings)
+	_, _ = cli.ImageList(context.Background(), types.ImageListOptions{})
+	assert.Len(t, local.ImageListOpts, 1)
+	assert.Len(t, podman.ImageListOpts, 1)
+	assert.Equal(t, []string{"unix:///run/podman/podman.sock"}, hosts)
+
+	cli.SetContainerEngine(model.ContainerEngineSettings{})
+	_, _ = cli.ImageList(context.Background(), types.ImageListOptions{})
+	assert.Len(t, local.ImageListOpts, 2)
+}
@@ -0,0 +1,153 @@
+package docker
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/tilt-dev/clusterid"
+
+	"github.com/tilt-dev/tilt/internal/k8s"
+)
+
+// Docker Desktop alternatives (Colima, Rancher Desktop, Podman machine)
+// usually don't create /var/run/docker.sock unless you give them admin
+// privileges. Instead, they put the socket somewhere under your home dir,
+// and expect you to point DOCKER_HOST (or a Docker CLI context) at it.
+//
+// If the user hasn't configured a Docker host, and the default socket
+// doesn't exist, we look for these sockets ourselves.
+
+var defaultDaemonSocket = "/var/run/docker.sock"
+
+// The Colima profile that a kube context belongs to.
+//
+// Colima names the kube context `colima` for the default profile,
+// and `colima-$profile` for other profiles.
+func colimaProfile(kubeContext k8s.KubeContext) string {
+	profile := strings.TrimPrefix(string(kubeContext), "colima-")
+	if profile == string(kubeContext) {
+		return "default"
+	}
+	return profile
+}
+
+// Sockets where Colima puts the Docker daemon for a profile.
+//
+// Colima v0.4+ uses ~/.colima/$profile/docker.sock.
+// Older versions used ~/.colima/docker.sock for the default profile,
+// and ~/.colima-$profile/docker.sock for others.
+func colimaSockets(home string, profile string) []string {
+	result := []string{filepath.Join(home, ".colima", profile, "docker.sock")}
+	if profile == "default" {
+		result = append(result, filepath.Join(home, ".colima", "docker.sock"))
+	} else {
+		result = append(result, filepath.Join(home, ".colima-"+profile, "docker.sock"))
+	}
+	return result
+}
+
+// Without admin privileges, Rancher Desktop puts its socket in ~/.rd.
+func rancherDesktopSockets(home string) []string {
+	return []string{filepath.Join(home, ".rd", "docker.sock")}
+}
+
+// Podman machine forwards its API socket to the host. The Podman API
+// is compatible with the Docker API.
+func podmanSockets(home string) []string {
+	machineDir := filepath.Join(home, ".local", "share", "containers", "podman", "machine")
+	result := []string{
+		filepath.Join(machineDir, "podman.sock"),
+		filepath.Join(machineDir, "qemu", "podman.sock"),
+		filepath.Join(machineDir, "podman-machine-default", "podman.sock"),
+	}
+
+	// Rootless Podman on Linux, with the podman.socket systemd unit enabled.
+	runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
+	if runtimeDir != "" {
+		result = append(result, filepath.Join(runtimeDir, "podman", "podman.sock"))
+	}
+	return result
+}
+
+// Returns the sockets to try, in order of preference.
+//
+// If the kube context belongs to one of these runtimes, we prefer its
+// socket, so that images we build show up in the cluster.
+func candidateDaemonSockets(home string, product clusterid.Product, kubeContext k8s.KubeContext) []string {
+	result := []string{}
+	switch product {
+	case clusterid.ProductColima:
+		result = append(result, colimaSockets(home, colimaProfile(kubeContext))...)
+	case clusterid.ProductRancherDesktop:
+		result = append(result, rancherDesktopSockets(home)...)
+	}
+
+	result = append(result, colimaSockets(home, "default")...)
+	result = append(result, rancherDesktopSockets(home)...)
+	result = append(result, podmanSockets(home)...)
+	return result
+}
+
+// Looks for a Docker-compatible socket when the default one doesn't exist.
+//
+// Returns the empty string if the user has configured the host, or we
+// can't find one.
+func detectDaemonHost(product clusterid.Product, kubeContext k8s.KubeContext, e Env) string {
+	if runtime.GOOS == "windows" {
+		return ""
+	}
+
+	if os.Getenv("DOCKER_HOST") != "" || !isDefaultHost(e) {
+		return ""
+	}
+
+	if socketExists(defaultDaemonSocket) {
+		return ""
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+
+	// Docker Desktop for Linux
+	if socketExists(filepath.Join(home, ".docker", "desktop", "docker.sock")) {
+		return ""
+	}
+
+	for _, sock := range candidateDaemonSockets(home, product, kubeContext) {
+		if socketExists(sock) {
+			return fmt.Sprintf("unix://%s", sock)
+		}
+	}
+	return ""
+}
+
+func socketExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode()&os.ModeSocket != 0
+}
+
+// Replaces the env's client with one that talks to the detected socket.
+//
+// The host is also added to the env, so that subprocesses (like
+// `docker compose` or a custom_build script) talk to the same daemon.
+func useDetectedDaemonHost(creator ClientCreator, product clusterid.Product, kubeContext k8s.KubeContext, e Env) Env {
+	host := detectDaemonHost(product, kubeContext, e)
+	if host == "" {
+		return e
+	}
+
+	d, err := creator.FromEnvMap(map[string]string{"DOCKER_HOST": host})
+	if err != nil {
+		return e
+	}
+
+	e.Client = d
+	e.Error = nil
+	e.Environ = append(e.Environ, fmt.Sprintf("DOCKER_HOST=%s", host))
+	return e
+}
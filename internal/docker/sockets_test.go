@@ -0,0 +1,131 @@
+package docker
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/clusterid"
+
+	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/k8s"
+)
+
+type socketFixture struct {
+	t    *testing.T
+	home string
+}
+
+func newSocketFixture(t *testing.T) *socketFixture {
+	// Unix socket paths have a short max length, so don't use t.TempDir().
+	home, err := os.MkdirTemp("", "home")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = os.RemoveAll(home)
+	})
+
+	t.Setenv("HOME", home)
+	t.Setenv("DOCKER_HOST", "")
+	t.Setenv("XDG_RUNTIME_DIR", "")
+
+	orig := defaultDaemonSocket
+	defaultDaemonSocket = filepath.Join(home, "docker.sock")
+	t.Cleanup(func() {
+		defaultDaemonSocket = orig
+	})
+
+	return &socketFixture{t: t, home: home}
+}
+
+func (f *socketFixture) listen(relPath string) string {
+	p := filepath.Join(f.home, relPath)
+	require.NoError(f.t, os.MkdirAll(filepath.Dir(p), os.FileMode(0755)))
+	l, err := net.Listen("unix", p)
+	require.NoError(f.t, err)
+	f.t.Cleanup(func() {
+		_ = l.Close()
+	})
+	return "unix://" + p
+}
+
+func (f *socketFixture) provide(product clusterid.Product, kubeContext k8s.KubeContext) (ClusterEnv, LocalEnv) {
+	cluster := ProvideClusterEnv(context.Background(), fakeClientCreator{}, kubeContext, product, container.RuntimeDocker, k8s.FakeMinikube{})
+	local := ProvideLocalEnv(context.Background(), fakeClientCreator{}, kubeContext, product, cluster)
+	return cluster, local
+}
+
+func TestDetectColimaSocket(t *testing.T) {
+	f := newSocketFixture(t)
+	host := f.listen(".colima/default/docker.sock")
+
+	cluster, local := f.provide(clusterid.ProductColima, "colima")
+	assert.Equal(t, host, Env(cluster).DaemonHost())
+	assert.Equal(t, []string{"DOCKER_HOST=" + host}, cluster.Environ)
+	assert.True(t, Env(cluster).WillBuildToKubeContext("colima"))
+	assert.Equal(t, host, Env(local).DaemonHost())
+	assert.True(t, Env(local).WillBuildToKubeContext("colima"))
+}
+
+func TestDetectColimaProfileSocket(t *testing.T) {
+	f := newSocketFixture(t)
+	f.listen(".colima/default/docker.sock")
+	host := f.listen(".colima/test/docker.sock")
+
+	cluster, _ := f.provide(clusterid.ProductColima, "colima-test")
+	assert.Equal(t, host, Env(cluster).DaemonHost())
+	assert.True(t, Env(cluster).WillBuildToKubeContext("colima-test"))
+}
+
+func TestDetectRancherDesktopSocket(t *testing.T) {
+	f := newSocketFixture(t)
+	host := f.listen(".rd/docker.sock")
+
+	cluster, local := f.provide(clusterid.ProductRancherDesktop, "rancher-desktop")
+	assert.Equal(t, host, Env(cluster).DaemonHost())
+	assert.True(t, Env(cluster).WillBuildToKubeContext("rancher-desktop"))
+	assert.Equal(t, host, Env(local).DaemonHost())
+}
+
+func TestDetectPodmanMachineSocket(t *testing.T) {
+	f := newSocketFixture(t)
+	host := f.listen(".local/share/containers/podman/machine/qemu/podman.sock")
+
+	cluster, local := f.provide(clusterid.ProductKIND, "kind-kind")
+	assert.Equal(t, host, Env(cluster).DaemonHost())
+	assert.False(t, Env(cluster).WillBuildToKubeContext("kind-kind"))
+	assert.Equal(t, host, Env(local).DaemonHost())
+}
+
+func TestDefaultSocketWins(t *testing.T) {
+	f := newSocketFixture(t)
+	f.listen("docker.sock")
+	f.listen(".colima/default/docker.sock")
+
+	cluster, _ := f.provide(clusterid.ProductColima, "colima")
+	assert.Equal(t, "unix:///var/run/docker.sock", Env(cluster).DaemonHost())
+	assert.Empty(t, cluster.Environ)
+}
+
+func TestDockerHostWins(t *testing.T) {
+	f := newSocketFixture(t)
+	f.listen(".colima/default/docker.sock")
+	t.Setenv("DOCKER_HOST", "tcp://localhost:2375")
+
+	cluster, _ := f.provide(clusterid.ProductColima, "colima")
+	assert.Equal(t, "tcp://localhost:2375", Env(cluster).DaemonHost())
+}
+
+func TestColimaProfileMismatch(t *testing.T) {
+	env := Env{Client: hostClient{Host: "unix:///Users/tilt/.colima/default/docker.sock"}}
+	assert.True(t, willBuildToKubeContext(clusterid.ProductColima, "colima", env))
+	assert.False(t, willBuildToKubeContext(clusterid.ProductColima, "colima-test", env))
+
+	env = Env{Client: hostClient{Host: "unix:///Users/tilt/.colima-test/docker.sock"}}
+	assert.True(t, willBuildToKubeContext(clusterid.ProductColima, "colima-test", env))
+	assert.False(t, willBuildToKubeContext(clusterid.ProductColima, "colima", env))
+}
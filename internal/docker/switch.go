@@ -8,6 +8,8 @@ import (
 	"github.com/docker/distribution/reference"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/volume"
 
 	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
@@ -22,16 +24,25 @@ type switchCli struct {
 	clusterCli ClusterClient
 	orc        model.Orchestrator
 	mu         sync.Mutex
+
+	// When the project picks a container engine with its own host (like Podman),
+	// all calls go to that engine instead.
+	engine       model.ContainerEngineSettings
+	engineCli    Client
+	newEngineCli func(host string) Client
 }
 
 var _ Client = &switchCli{}
 var _ CompositeClient = &switchCli{}
 
-func ProvideSwitchCli(clusterCli ClusterClient, localCli LocalClient) CompositeClient {
+func ProvideSwitchCli(ctx context.Context, clusterCli ClusterClient, localCli LocalClient) CompositeClient {
 	return &switchCli{
 		localCli:   localCli,
 		clusterCli: clusterCli,
 		orc:        model.OrchestratorK8s,
+		newEngineCli: func(host string) Client {
+			return newHostClient(ctx, RealClientCreator{}, host)
+		},
 	}
 }
 
@@ -45,6 +56,9 @@ func WithOrchestrator(ctx context.Context, orc model.Orchestrator) context.Conte
 func (c *switchCli) client(ctx context.Context) Client {
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	if c.engineCli != nil {
+		return c.engineCli
+	}
 	orc, ok := ctx.Value(orcKey).(model.Orchestrator)
 	if ok {
 		return c.ForOrchestrator(orc)
@@ -57,6 +71,24 @@ func (c *switchCli) SetOrchestrator(orc model.Orchestrator) {
 	defer c.mu.Unlock()
 	c.orc = orc
 }
+
+// Connects to the engine's host, if it has one.
+//
+// The client is only replaced when the settings change, so reloading
+// the Tiltfile doesn't reconnect.
+func (c *switchCli) SetContainerEngine(settings model.ContainerEngineSettings) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if settings == c.engine {
+		return
+	}
+	c.engine = settings
+	c.engineCli = nil
+	if settings.Host != "" {
+		c.engineCli = c.newEngineCli(settings.Host)
+	}
+}
+
 func (c *switchCli) ForOrchestrator(orc model.Orchestrator) Client {
 	if orc == model.OrchestratorK8s {
 		return c.clusterCli
@@ -106,6 +138,9 @@ func (c *switchCli) ImageTag(ctx context.Context, source, target string) error {
 func (c *switchCli) ImageInspectWithRaw(ctx context.Context, imageID string) (types.ImageInspect, []byte, error) {
 	return c.client(ctx).ImageInspectWithRaw(ctx, imageID)
 }
+func (c *switchCli) ImageHistory(ctx context.Context, imageID string) ([]image.HistoryResponseItem, error) {
+	return c.client(ctx).ImageHistory(ctx, imageID)
+}
 func (c *switchCli) ImageList(ctx context.Context, options types.ImageListOptions) ([]types.ImageSummary, error) {
 	return c.client(ctx).ImageList(ctx, options)
 }
@@ -121,6 +156,15 @@ func (c *switchCli) BuildCachePrune(ctx context.Context, opts types.BuildCachePr
 func (c *switchCli) ContainersPrune(ctx context.Context, pruneFilters filters.Args) (types.ContainersPruneReport, error) {
 	return c.client(ctx).ContainersPrune(ctx, pruneFilters)
 }
+func (c *switchCli) VolumeList(ctx context.Context, filter filters.Args) (volume.VolumeListOKBody, error) {
+	return c.client(ctx).VolumeList(ctx, filter)
+}
+func (c *switchCli) VolumeRemove(ctx context.Context, volumeID string, force bool) error {
+	return c.client(ctx).VolumeRemove(ctx, volumeID, force)
+}
+func (c *switchCli) DiskUsage(ctx context.Context) (types.DiskUsage, error) {
+	return c.client(ctx).DiskUsage(ctx)
+}
 
 // CompositeClient
 func (c *switchCli) DefaultLocalClient() Client {
@@ -0,0 +1,71 @@
+package dockercompose
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+)
+
+// BuildKit redraws its progress in place when it thinks it's talking to a terminal,
+// which turns into garbage in a resource's log pane. Ask for line-by-line progress
+// instead, unless the user has picked a progress mode themselves.
+const buildkitProgressEnvVar = "BUILDKIT_PROGRESS"
+
+func buildEnv(environ []string) []string {
+	for _, kv := range environ {
+		if strings.HasPrefix(kv, buildkitProgressEnvVar+"=") {
+			return environ
+		}
+	}
+	return append(environ, buildkitProgressEnvVar+"=plain")
+}
+
+// Compose writes build progress to stderr and its own status messages to stdout.
+// Both usually end up in the same log span, where a partial line from one stream
+// would get glued to the next write from the other. A lineWriter only passes on
+// whole lines, and shares a lock with its sibling so they take turns.
+type lineWriter struct {
+	mu  *sync.Mutex
+	out io.Writer
+	buf []byte
+}
+
+// Wraps stdout and stderr so that their lines can't be spliced together.
+// Call the returned func after the command exits to write out any
+// trailing partial lines.
+func newLineWriters(stdout, stderr io.Writer) (io.Writer, io.Writer, func()) {
+	mu := &sync.Mutex{}
+	o := &lineWriter{mu: mu, out: stdout}
+	e := &lineWriter{mu: mu, out: stderr}
+	return o, e, func() {
+		o.flush()
+		e.flush()
+	}
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	i := bytes.LastIndexByte(w.buf, '\n')
+	if i == -1 {
+		return len(p), nil
+	}
+
+	w.write(w.buf[:i+1])
+	w.buf = append([]byte{}, w.buf[i+1:]...)
+	return len(p), nil
+}
+
+func (w *lineWriter) flush() {
+	if len(w.buf) == 0 {
+		return
+	}
+	w.write(w.buf)
+	w.buf = nil
+}
+
+func (w *lineWriter) write(p []byte) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, _ = w.out.Write(p)
+}
@@ -0,0 +1,28 @@
+package dockercompose
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildEnv(t *testing.T) {
+	assert.Equal(t, []string{"A=1", "BUILDKIT_PROGRESS=plain"}, buildEnv([]string{"A=1"}))
+	assert.Equal(t, []string{"BUILDKIT_PROGRESS=tty"}, buildEnv([]string{"BUILDKIT_PROGRESS=tty"}))
+}
+
+func TestLineWritersDontSpliceStreams(t *testing.T) {
+	out := &bytes.Buffer{}
+	stdout, stderr, flush := newLineWriters(out, out)
+
+	_, _ = stdout.Write([]byte("Building "))
+	_, _ = stderr.Write([]byte("#1 [internal] load build definition\n#2 "))
+	_, _ = stdout.Write([]byte("fe\n"))
+	_, _ = stderr.Write([]byte("DONE 0.1s"))
+	flush()
+
+	assert.Equal(t,
+		"#1 [internal] load build definition\nBuilding fe\n#2 DONE 0.1s",
+		out.String())
+}
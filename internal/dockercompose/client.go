@@ -41,34 +41,41 @@ var dcProjectOptions = []compose.ProjectOptionsFn{
 }
 
 type DockerComposeClient interface {
-	Up(ctx context.Context, spec v1alpha1.DockerComposeServiceSpec, shouldBuild bool, stdout, stderr io.Writer) error
-	Down(ctx context.Context, spec v1alpha1.DockerComposeProject, stdout, stderr io.Writer) error
+	Up(ctx context.Context, spec v1alpha1.DockerComposeServiceSpec, shouldBuild, recreate bool, stdout, stderr io.Writer) error
+	Down(ctx context.Context, spec v1alpha1.DockerComposeProject, deleteVolumes bool, stdout, stderr io.Writer) error
 	Rm(ctx context.Context, specs []v1alpha1.DockerComposeServiceSpec, stdout, stderr io.Writer) error
 	StreamLogs(ctx context.Context, spec v1alpha1.DockerComposeServiceSpec) io.ReadCloser
 	StreamEvents(ctx context.Context, spec v1alpha1.DockerComposeProject) (<-chan string, error)
 	Project(ctx context.Context, spec v1alpha1.DockerComposeProject) (*types.Project, error)
 	ContainerID(ctx context.Context, spec v1alpha1.DockerComposeServiceSpec) (container.ID, error)
-	Version(ctx context.Context) (canonicalVersion string, build string, err error)
+	Version(ctx context.Context, provider string) (ProviderVersion, error)
 }
 
 type cmdDCClient struct {
-	env     docker.Env
-	mu      *sync.Mutex
-	initCmd *sync.Once
-
-	composeCmd []string
-	version    string
-	build      string
-	err        error
+	env docker.Env
+
+	// Serializes commands that remove containers.
+	mu *sync.Mutex
+
+	// Projects whose networks and volumes we've created, by version.
+	setupMu *sync.Mutex
+	setups  map[string]*projectSetup
+
+	// Probed providers, by name.
+	providersMu *sync.Mutex
+	providers   map[string]*provider
 }
 
 // TODO(dmiller): we might want to make this take a path to the docker-compose config so we don't
 // have to keep passing it in.
 func NewDockerComposeClient(lenv docker.LocalEnv) DockerComposeClient {
 	return &cmdDCClient{
-		env:     docker.Env(lenv),
-		mu:      &sync.Mutex{},
-		initCmd: &sync.Once{},
+		env:         docker.Env(lenv),
+		mu:          &sync.Mutex{},
+		setupMu:     &sync.Mutex{},
+		setups:      make(map[string]*projectSetup),
+		providersMu: &sync.Mutex{},
+		providers:   make(map[string]*provider),
 	}
 }
 
@@ -87,6 +94,10 @@ func (c *cmdDCClient) projectArgs(p v1alpha1.DockerComposeProject) []string {
 		result = append(result, "--env-file", p.EnvFile)
 	}
 
+	for _, profile := range p.Profiles {
+		result = append(result, "--profile", profile)
+	}
+
 	if p.YAML != "" {
 		result = append(result, "-f", "-")
 	}
@@ -98,70 +109,77 @@ func (c *cmdDCClient) projectArgs(p v1alpha1.DockerComposeProject) []string {
 	return result
 }
 
-func (c *cmdDCClient) Up(ctx context.Context, spec v1alpha1.DockerComposeServiceSpec, shouldBuild bool, stdout, stderr io.Writer) error {
+func (c *cmdDCClient) Up(ctx context.Context, spec v1alpha1.DockerComposeServiceSpec, shouldBuild, recreate bool, stdout, stderr io.Writer) error {
+	provider := c.provider(spec.Project.Provider)
 	genArgs := c.projectArgs(spec.Project)
 	// TODO(milas): this causes docker-compose to output a truly excessive amount of logging; it might
 	// 	make sense to hide it behind a special environment variable instead or something
-	if logger.Get(ctx).Level().ShouldDisplay(logger.VerboseLvl) {
+	if provider.version.supportsVerbose() && logger.Get(ctx).Level().ShouldDisplay(logger.VerboseLvl) {
 		genArgs = append(genArgs, "--verbose")
 	}
 
 	if shouldBuild {
 		var buildArgs = append([]string{}, genArgs...)
 		buildArgs = append(buildArgs, "build", spec.Service)
-		cmd := c.dcCommand(ctx, buildArgs)
+		cmd := c.dcCommand(ctx, spec.Project, buildArgs)
+		cmd.Env = buildEnv(cmd.Env)
 		cmd.Stdin = strings.NewReader(spec.Project.YAML)
-		cmd.Stdout = stdout
-		cmd.Stderr = stderr
+		var flush func()
+		cmd.Stdout, cmd.Stderr, flush = newLineWriters(stdout, stderr)
 		err := cmd.Run()
+		flush()
 		if err != nil {
 			return FormatError(cmd, nil, err)
 		}
 	}
 
-	// docker-compose up is not thread-safe, because network operations are non-atomic. See:
-	// https://github.com/tilt-dev/tilt/issues/2817
-	//
-	// docker-compose build can run in parallel fine, so we only want the mutex on the 'up' call.
-	//
-	// TODO(nick): It might make sense to use a CondVar so that we can log a message
-	// when we're waiting on another build...
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	// docker-compose build can run in parallel fine, so we only wait
+	// for the project's setup on the 'up' call.
+	unlock := c.lockProjectSetup(ctx, spec.Project)
 	runArgs := append([]string{}, genArgs...)
 	runArgs = append(runArgs, "up", "--no-deps")
 	// Omit --no-build for now to get v2 working.
 	// https://github.com/docker/compose/issues/8785
-	if semver.Major(c.version) != "v2" {
+	if provider.version.needsNoBuild() {
 		runArgs = append(runArgs, "--no-build")
 	}
+	if recreate {
+		runArgs = append(runArgs, "--force-recreate")
+	}
 	runArgs = append(runArgs, "-d", spec.Service)
-	cmd := c.dcCommand(ctx, runArgs)
+	cmd := c.dcCommand(ctx, spec.Project, runArgs)
 	cmd.Stdin = strings.NewReader(spec.Project.YAML)
 	cmd.Stdout = stdout
 	cmd.Stderr = stderr
 
-	return FormatError(cmd, nil, cmd.Run())
+	err := cmd.Run()
+	unlock(err == nil)
+	return FormatError(cmd, nil, err)
 }
 
-func (c *cmdDCClient) Down(ctx context.Context, p v1alpha1.DockerComposeProject, stdout, stderr io.Writer) error {
+func (c *cmdDCClient) Down(ctx context.Context, p v1alpha1.DockerComposeProject, deleteVolumes bool, stdout, stderr io.Writer) error {
 	// To be safe, we try not to run two docker-compose downs in parallel,
 	// because we know docker-compose up is not thread-safe.
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	args := c.projectArgs(p)
-	if logger.Get(ctx).Level().ShouldDisplay(logger.VerboseLvl) {
+	if c.provider(p.Provider).version.supportsVerbose() && logger.Get(ctx).Level().ShouldDisplay(logger.VerboseLvl) {
 		args = append(args, "--verbose")
 	}
 
 	args = append(args, "down")
-	cmd := c.dcCommand(ctx, args)
+	if deleteVolumes {
+		args = append(args, "--volumes")
+	}
+	cmd := c.dcCommand(ctx, p, args)
 	cmd.Stdin = strings.NewReader(p.YAML)
 	cmd.Stdout = stdout
 	cmd.Stderr = stderr
 
 	err := cmd.Run()
+	// Down removes the project's networks, so the next `up` needs to create them again.
+	c.resetProjectSetups()
 	if err != nil {
 		return FormatError(cmd, nil, err)
 	}
@@ -181,7 +199,7 @@ func (c *cmdDCClient) Rm(ctx context.Context, specs []v1alpha1.DockerComposeServ
 
 	p := specs[0].Project
 	args := c.projectArgs(p)
-	if logger.Get(ctx).Level().ShouldDisplay(logger.VerboseLvl) {
+	if c.provider(p.Provider).version.supportsVerbose() && logger.Get(ctx).Level().ShouldDisplay(logger.VerboseLvl) {
 		args = append(args, "--verbose")
 	}
 
@@ -197,7 +215,7 @@ func (c *cmdDCClient) Rm(ctx context.Context, specs []v1alpha1.DockerComposeServ
 	// timeout.
 	args = append(args, []string{"rm", "--stop", "--force"}...)
 	args = append(args, serviceNames...)
-	cmd := c.dcCommand(ctx, args)
+	cmd := c.dcCommand(ctx, p, args)
 	cmd.Stdin = strings.NewReader(p.YAML)
 	cmd.Stdout = stdout
 	cmd.Stderr = stderr
@@ -222,7 +240,7 @@ func (c *cmdDCClient) StreamLogs(ctx context.Context, spec v1alpha1.DockerCompos
 	// 		 under some conditions - adding a final \n after stdout is closed would probably be sufficient given the
 	// 		 current pattern of how Compose colorizes stuff, but it's really not worth the headache to find out
 	args = append(args, "logs", "--no-color", "--no-log-prefix", "--timestamps", "--follow", spec.Service)
-	cmd := c.dcCommand(ctx, args)
+	cmd := c.dcCommand(ctx, spec.Project, args)
 	cmd.Stdin = strings.NewReader(spec.Project.YAML)
 	cmd.Stdout = w
 
@@ -231,8 +249,8 @@ func (c *cmdDCClient) StreamLogs(ctx context.Context, spec v1alpha1.DockerCompos
 
 	go func() {
 		if cmdErr := cmd.Run(); cmdErr != nil {
-			_ = w.CloseWithError(fmt.Errorf("cmd `docker-compose %s` exited with error: \"%v\" (stderr: %s)",
-				strings.Join(args, " "), cmdErr, errBuf.String()))
+			_ = w.CloseWithError(fmt.Errorf("cmd `%s` exited with error: \"%v\" (stderr: %s)",
+				strings.Join(cmd.Args, " "), cmdErr, errBuf.String()))
 		} else {
 			_ = w.Close()
 		}
@@ -243,9 +261,14 @@ func (c *cmdDCClient) StreamLogs(ctx context.Context, spec v1alpha1.DockerCompos
 func (c *cmdDCClient) StreamEvents(ctx context.Context, p v1alpha1.DockerComposeProject) (<-chan string, error) {
 	ch := make(chan string)
 
+	version := c.provider(p.Provider).version
+	if !version.supportsEvents() {
+		return ch, fmt.Errorf("%s does not support `events`", version.Impl)
+	}
+
 	args := c.projectArgs(p)
 	args = append(args, "events", "--json")
-	cmd := c.dcCommand(ctx, args)
+	cmd := c.dcCommand(ctx, p, args)
 	cmd.Stdin = strings.NewReader(p.YAML)
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
@@ -299,9 +322,38 @@ func (c *cmdDCClient) Project(ctx context.Context, spec v1alpha1.DockerComposePr
 		}
 	}
 
+	if len(spec.Profiles) > 0 {
+		err = ApplyProfiles(proj, spec.Profiles)
+		if err != nil {
+			return nil, err
+		}
+	}
 	return proj, nil
 }
 
+// ApplyProfiles removes services that aren't in an active profile,
+// following docker-compose: services without profiles are always active.
+//
+// The client only calls this when the project has profiles, so that
+// Tiltfiles written before profile support still see all their services.
+func ApplyProfiles(proj *types.Project, profiles []string) error {
+	proj.ApplyProfiles(profiles)
+
+	disabled := make(map[string]bool, len(proj.DisabledServices))
+	for _, svc := range proj.DisabledServices {
+		disabled[svc.Name] = true
+	}
+	for _, svc := range proj.Services {
+		for _, dep := range svc.GetDependencies() {
+			if disabled[dep] {
+				return fmt.Errorf("service %q depends on %q, which is not in an active profile (active profiles: %q)",
+					svc.Name, dep, profiles)
+			}
+		}
+	}
+	return nil
+}
+
 func (c *cmdDCClient) ContainerID(ctx context.Context, spec v1alpha1.DockerComposeServiceSpec) (container.ID, error) {
 	id, err := c.dcOutput(ctx, spec.Project, "ps", "-q", spec.Service)
 	if err != nil {
@@ -311,14 +363,15 @@ func (c *cmdDCClient) ContainerID(ctx context.Context, spec v1alpha1.DockerCompo
 	return container.ID(id), nil
 }
 
-// Version returns the parsed output of `docker compose version`, the canonical version and build (if present).
+// Version returns the parsed output of `<provider> version`: the compose implementation,
+// its canonical version, and its build (if present).
 //
 // NOTE: The version subcommand was added in Docker Compose v1.4.0 (released 2015-08-04), so this won't work for
 //
 //	truly ancient versions, but handles both v1 and v2.
-func (c *cmdDCClient) Version(ctx context.Context) (string, string, error) {
-	c.initDcCommand()
-	return c.version, c.build, c.err
+func (c *cmdDCClient) Version(ctx context.Context, provider string) (ProviderVersion, error) {
+	p := c.provider(provider)
+	return p.version, p.err
 }
 
 func composeProjectOptions(modelProj v1alpha1.DockerComposeProject) (*compose.ProjectOptions, error) {
@@ -351,17 +404,24 @@ func (c *cmdDCClient) loadProjectCLI(ctx context.Context, proj v1alpha1.DockerCo
 		return nil, err
 	}
 
+	configFile := types.ConfigFile{Content: []byte(resolvedYAML)}
+
+	// The loader can't parse secrets and configs that come from environment
+	// variables, so move them out of its way.
+	config, err := loader.ParseYAML(configFile.Content)
+	if err == nil && moveEnvironmentSources(config) {
+		configFile.Config = config
+	}
+
 	// docker-compose is very inconsistent about whether it fully resolves paths or not via CLI, both between
 	// v1 and v2 as well as even different releases within v2, so set the workdir and force the loader to resolve
 	// any relative paths
 	return loader.Load(types.ConfigDetails{
-		WorkingDir: proj.ProjectPath,
-		ConfigFiles: []types.ConfigFile{
-			{
-				Content: []byte(resolvedYAML),
-			},
-		},
+		WorkingDir:  proj.ProjectPath,
+		ConfigFiles: []types.ConfigFile{configFile},
 		// no environment specified because the CLI call will already have resolved all variables
+		// (but the loader writes the project name into it, so it can't be nil)
+		Environment: map[string]string{},
 	}, dcLoaderOption(proj.Name))
 }
 
@@ -378,52 +438,25 @@ func dcLoaderOption(name string) func(opts *loader.Options) {
 	}
 }
 
-func dcExecutableVersion(environ []string) ([]string, string, string, error) {
-	execVersion := func(names []string) (string, string, error) {
-		args := append(names, "version")
-		cmd := exec.Command(args[0], args[1:]...)
-		cmd.Env = append(os.Environ(), environ...)
-		stdout, err := cmd.Output()
-		if err != nil {
-			return "", "", FormatError(cmd, stdout, err)
-		}
-		ver, build, err := parseComposeVersionOutput(stdout)
-		return ver, build, err
-	}
-
-	var cmd []string
-	if cmdstr := os.Getenv("TILT_DOCKER_COMPOSE_CMD"); cmdstr != "" {
-		cmd = []string{cmdstr}
-		ver, build, err := execVersion(cmd)
-		return cmd, ver, build, err
-	}
+// Probes the provider the first time it's used.
+func (c *cmdDCClient) provider(name string) *provider {
+	c.providersMu.Lock()
+	defer c.providersMu.Unlock()
 
-	cmd = []string{"docker", "compose"}
-	ver, build, err := execVersion(cmd)
-	if err != nil {
-		cmd = []string{"docker-compose"}
-		ver, build, err = execVersion(cmd)
+	p, ok := c.providers[name]
+	if !ok {
+		p = probeProvider(name, c.env.AsEnviron())
+		c.providers[name] = p
 	}
-
-	return cmd, ver, build, err
-}
-
-func (c *cmdDCClient) initDcCommand() {
-	c.initCmd.Do(func() {
-		cmd, version, build, err := dcExecutableVersion(c.env.AsEnviron())
-		c.composeCmd = cmd
-		c.version = version
-		c.build = build
-		c.err = err
-	})
+	return p
 }
 
-func (c *cmdDCClient) dcCommand(ctx context.Context, args []string) *exec.Cmd {
-	c.initDcCommand()
-	composeCmd := c.composeCmd[0]
-	composeArgs := c.composeCmd[1:]
+func (c *cmdDCClient) dcCommand(ctx context.Context, p v1alpha1.DockerComposeProject, args []string) *exec.Cmd {
+	providerCmd := c.provider(p.Provider).cmd
+	composeCmd := providerCmd[0]
+	composeArgs := providerCmd[1:]
 	if len(composeArgs) > 0 {
-		args = append(composeArgs, args...)
+		args = append(append([]string{}, composeArgs...), args...)
 	}
 	cmd := exec.CommandContext(ctx, composeCmd, args...)
 	cmd.Env = append(os.Environ(), c.env.AsEnviron()...)
@@ -434,7 +467,7 @@ func (c *cmdDCClient) dcOutput(ctx context.Context, p v1alpha1.DockerComposeProj
 
 	tempArgs := c.projectArgs(p)
 	args = append(tempArgs, args...)
-	cmd := c.dcCommand(ctx, args)
+	cmd := c.dcCommand(ctx, p, args)
 	cmd.Stdin = strings.NewReader(p.YAML)
 
 	output, err := cmd.Output()
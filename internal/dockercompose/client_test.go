@@ -136,6 +136,51 @@ func TestLoadEnvFile(t *testing.T) {
 	require.Equal(t, types.ShellCommand{"foo"}, proj.Services[0].Command)
 }
 
+func TestApplyProfiles(t *testing.T) {
+	newProject := func() *types.Project {
+		return &types.Project{
+			Services: types.Services{
+				{Name: "app"},
+				{Name: "debugger", Profiles: []string{"debug"}},
+				{Name: "grafana", Profiles: []string{"metrics"}},
+			},
+		}
+	}
+
+	proj := newProject()
+	require.NoError(t, ApplyProfiles(proj, nil))
+	assert.Equal(t, []string{"app"}, proj.ServiceNames())
+
+	proj = newProject()
+	require.NoError(t, ApplyProfiles(proj, []string{"debug"}))
+	assert.Equal(t, []string{"app", "debugger"}, proj.ServiceNames())
+
+	proj = newProject()
+	require.NoError(t, ApplyProfiles(proj, []string{"*"}))
+	assert.Equal(t, []string{"app", "debugger", "grafana"}, proj.ServiceNames())
+}
+
+func TestApplyProfilesDisabledDependency(t *testing.T) {
+	proj := &types.Project{
+		Services: types.Services{
+			{Name: "app", DependsOn: types.DependsOnConfig{"grafana": {Condition: "service_started"}}},
+			{Name: "grafana", Profiles: []string{"metrics"}},
+		},
+	}
+	err := ApplyProfiles(proj, []string{"debug"})
+	require.EqualError(t, err,
+		`service "app" depends on "grafana", which is not in an active profile (active profiles: ["debug"])`)
+}
+
+func TestProjectArgsProfiles(t *testing.T) {
+	c := &cmdDCClient{}
+	args := c.projectArgs(v1alpha1.DockerComposeProject{
+		Name:     "proj",
+		Profiles: []string{"debug", "metrics"},
+	})
+	assert.Equal(t, []string{"--project-name", "proj", "--profile", "debug", "--profile", "metrics"}, args)
+}
+
 type dcFixture struct {
 	t      testing.TB
 	ctx    context.Context
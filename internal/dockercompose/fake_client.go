@@ -33,6 +33,9 @@ type FakeDCClient struct {
 	ConfigOutput      string
 	VersionOutput     string
 
+	// Written to the Up call's stdout when it builds an image.
+	BuildOutput string
+
 	upCalls   []UpCall
 	downCalls []DownCall
 	rmCalls   []RmCall
@@ -48,11 +51,13 @@ var _ DockerComposeClient = &FakeDCClient{}
 type UpCall struct {
 	Spec        v1alpha1.DockerComposeServiceSpec
 	ShouldBuild bool
+	Recreate    bool
 }
 
 // Represents a single call to Down
 type DownCall struct {
-	Proj v1alpha1.DockerComposeProject
+	Proj          v1alpha1.DockerComposeProject
+	DeleteVolumes bool
 }
 
 type RmCall struct {
@@ -69,19 +74,22 @@ func NewFakeDockerComposeClient(t *testing.T, ctx context.Context) *FakeDCClient
 }
 
 func (c *FakeDCClient) Up(ctx context.Context, spec v1alpha1.DockerComposeServiceSpec,
-	shouldBuild bool, stdout, stderr io.Writer) error {
+	shouldBuild, recreate bool, stdout, stderr io.Writer) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.upCalls = append(c.upCalls, UpCall{spec, shouldBuild})
+	c.upCalls = append(c.upCalls, UpCall{spec, shouldBuild, recreate})
+	if shouldBuild && c.BuildOutput != "" {
+		_, _ = stdout.Write([]byte(c.BuildOutput))
+	}
 	return nil
 }
 
-func (c *FakeDCClient) Down(ctx context.Context, proj v1alpha1.DockerComposeProject, stdout, stderr io.Writer) error {
+func (c *FakeDCClient) Down(ctx context.Context, proj v1alpha1.DockerComposeProject, deleteVolumes bool, stdout, stderr io.Writer) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.downCalls = append(c.downCalls, DownCall{proj})
+	c.downCalls = append(c.downCalls, DownCall{proj, deleteVolumes})
 	if c.DownError != nil {
 		err := c.DownError
 		c.DownError = nil
@@ -203,19 +211,29 @@ func (c *FakeDCClient) Project(_ context.Context, m v1alpha1.DockerComposeProjec
 		},
 		Environment: opts.Environment,
 	}, dcLoaderOption(projectName))
-	return p, err
+	if err != nil {
+		return nil, err
+	}
+
+	if len(m.Profiles) > 0 {
+		err = ApplyProfiles(p, m.Profiles)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
 }
 
 func (c *FakeDCClient) ContainerID(ctx context.Context, spec v1alpha1.DockerComposeServiceSpec) (container.ID, error) {
 	return c.ContainerIdOutput, nil
 }
 
-func (c *FakeDCClient) Version(_ context.Context) (string, string, error) {
+func (c *FakeDCClient) Version(_ context.Context, _ string) (ProviderVersion, error) {
 	if c.VersionOutput != "" {
-		return c.VersionOutput, "tilt-fake", nil
+		return ProviderVersion{Impl: ImplDockerCompose, Version: c.VersionOutput, Build: "tilt-fake"}, nil
 	}
 	// default to a "known good" version that won't produce warnings
-	return "v1.29.2", "tilt-fake", nil
+	return ProviderVersion{Impl: ImplDockerCompose, Version: "v1.29.2", Build: "tilt-fake"}, nil
 }
 
 func (c *FakeDCClient) UpCalls() []UpCall {
@@ -0,0 +1,139 @@
+package dockercompose
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/compose-spec/compose-go/types"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+// Newer versions of Docker Compose can read a secret or config from an
+// environment variable:
+//
+//	secrets:
+//	  token:
+//	    environment: API_TOKEN
+//
+// Our compose-go loader doesn't know about `environment` yet and rejects it,
+// so we move it to an extension field before loading.
+const environmentExtension = "x-tilt-environment"
+
+// Moves `environment` sources of secrets and configs to an extension field.
+//
+// Returns whether there were any.
+func moveEnvironmentSources(config map[string]interface{}) bool {
+	moved := false
+	for _, block := range []string{"secrets", "configs"} {
+		objs, ok := config[block].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, v := range objs {
+			obj, ok := v.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			env, ok := obj["environment"]
+			if !ok {
+				continue
+			}
+			delete(obj, "environment")
+			obj[environmentExtension] = env
+			moved = true
+		}
+	}
+	return moved
+}
+
+// The secrets and configs that a service uses, with hashes of their contents.
+//
+// External secrets and configs live outside the project, so we skip them.
+func ServiceFileObjects(proj *types.Project, svc types.ServiceConfig) (secrets, configs []v1alpha1.DockerComposeFileObject) {
+	for _, ref := range svc.Secrets {
+		obj, ok := proj.Secrets[ref.Source]
+		if !ok || obj.External.External {
+			continue
+		}
+		secrets = append(secrets, fileObject(proj, ref.Source, types.FileObjectConfig(obj)))
+	}
+	for _, ref := range svc.Configs {
+		obj, ok := proj.Configs[ref.Source]
+		if !ok || obj.External.External {
+			continue
+		}
+		configs = append(configs, fileObject(proj, ref.Source, types.FileObjectConfig(obj)))
+	}
+	sortFileObjects(secrets)
+	sortFileObjects(configs)
+	return secrets, configs
+}
+
+func fileObject(proj *types.Project, name string, config types.FileObjectConfig) v1alpha1.DockerComposeFileObject {
+	result := v1alpha1.DockerComposeFileObject{Name: name}
+
+	// Check the environment first: the loader resolves every `file`
+	// against the project directory, even an empty one.
+	env, ok := config.Extensions[environmentExtension].(string)
+	if ok {
+		result.Environment = env
+		val, ok := proj.Environment[env]
+		if !ok {
+			val = os.Getenv(env)
+		}
+		result.ContentHash = hashContents([]byte(val))
+		return result
+	}
+
+	if config.File != "" {
+		result.File = config.File
+		if !filepath.IsAbs(result.File) {
+			result.File = filepath.Join(proj.WorkingDir, result.File)
+		}
+		// If we can't read the file, Docker Compose will report it
+		// when it brings up the service.
+		contents, err := os.ReadFile(result.File)
+		if err == nil {
+			result.ContentHash = hashContents(contents)
+		}
+	}
+	return result
+}
+
+func hashContents(contents []byte) string {
+	sum := sha256.Sum256(contents)
+	return hex.EncodeToString(sum[:])
+}
+
+func sortFileObjects(objs []v1alpha1.DockerComposeFileObject) {
+	sort.Slice(objs, func(i, j int) bool {
+		return objs[i].Name < objs[j].Name
+	})
+}
+
+// Whether the contents of a secret or config that the service used before
+// have changed.
+//
+// Docker Compose only recreates a container when its configuration changes,
+// so it wouldn't pick up the new contents on its own.
+func FileObjectsChanged(old, new v1alpha1.DockerComposeServiceSpec) bool {
+	return fileObjectsChanged(old.Secrets, new.Secrets) || fileObjectsChanged(old.Configs, new.Configs)
+}
+
+func fileObjectsChanged(old, new []v1alpha1.DockerComposeFileObject) bool {
+	oldHashes := make(map[string]string, len(old))
+	for _, obj := range old {
+		oldHashes[obj.Name] = obj.ContentHash
+	}
+	for _, obj := range new {
+		oldHash, ok := oldHashes[obj.Name]
+		if ok && oldHash != obj.ContentHash {
+			return true
+		}
+	}
+	return false
+}
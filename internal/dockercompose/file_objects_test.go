@@ -0,0 +1,117 @@
+package dockercompose
+
+import (
+	"testing"
+
+	"github.com/compose-spec/compose-go/loader"
+	"github.com/compose-spec/compose-go/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+func TestServiceFileObjects(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.WriteFile("token.txt", "hunter2")
+	f.WriteFile("nginx.conf", "server {}")
+	t.Setenv("API_KEY", "abc123")
+
+	proj := loadFileObjectsProject(t, f, `
+services:
+  app:
+    image: app
+    secrets: [token, api_key, vault]
+    configs: [nginx]
+  db:
+    image: postgres
+secrets:
+  token:
+    file: ./token.txt
+  api_key:
+    environment: API_KEY
+  vault:
+    external: true
+configs:
+  nginx:
+    file: ./nginx.conf
+`)
+
+	app, err := proj.GetService("app")
+	require.NoError(t, err)
+	secrets, configs := ServiceFileObjects(proj, app)
+	assert.Equal(t, []v1alpha1.DockerComposeFileObject{
+		{Name: "api_key", Environment: "API_KEY", ContentHash: hashContents([]byte("abc123"))},
+		{Name: "token", File: f.JoinPath("token.txt"), ContentHash: hashContents([]byte("hunter2"))},
+	}, secrets)
+	assert.Equal(t, []v1alpha1.DockerComposeFileObject{
+		{Name: "nginx", File: f.JoinPath("nginx.conf"), ContentHash: hashContents([]byte("server {}"))},
+	}, configs)
+
+	db, err := proj.GetService("db")
+	require.NoError(t, err)
+	secrets, configs = ServiceFileObjects(proj, db)
+	assert.Empty(t, secrets)
+	assert.Empty(t, configs)
+}
+
+func TestServiceFileObjectsMissingFile(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	proj := loadFileObjectsProject(t, f, `
+services:
+  app:
+    image: app
+    secrets: [token]
+secrets:
+  token:
+    file: ./token.txt
+`)
+
+	app, err := proj.GetService("app")
+	require.NoError(t, err)
+	secrets, _ := ServiceFileObjects(proj, app)
+	assert.Equal(t, []v1alpha1.DockerComposeFileObject{
+		{Name: "token", File: f.JoinPath("token.txt")},
+	}, secrets)
+}
+
+func TestFileObjectsChanged(t *testing.T) {
+	spec := func(secrets ...v1alpha1.DockerComposeFileObject) v1alpha1.DockerComposeServiceSpec {
+		return v1alpha1.DockerComposeServiceSpec{Service: "app", Secrets: secrets}
+	}
+	token := v1alpha1.DockerComposeFileObject{Name: "token", File: "token.txt", ContentHash: "a"}
+	newToken := v1alpha1.DockerComposeFileObject{Name: "token", File: "token.txt", ContentHash: "b"}
+	key := v1alpha1.DockerComposeFileObject{Name: "key", Environment: "KEY", ContentHash: "c"}
+
+	assert.False(t, FileObjectsChanged(spec(token), spec(token)))
+	assert.True(t, FileObjectsChanged(spec(token), spec(newToken)))
+
+	// Adding or removing a secret changes the service config,
+	// which Docker Compose already knows to recreate for.
+	assert.False(t, FileObjectsChanged(spec(token), spec(token, key)))
+	assert.False(t, FileObjectsChanged(spec(token, key), spec(key)))
+
+	config := v1alpha1.DockerComposeServiceSpec{Service: "app", Configs: []v1alpha1.DockerComposeFileObject{token}}
+	newConfig := v1alpha1.DockerComposeServiceSpec{Service: "app", Configs: []v1alpha1.DockerComposeFileObject{newToken}}
+	assert.True(t, FileObjectsChanged(config, newConfig))
+}
+
+// Loads a project the way loadProjectCLI does, minus the CLI.
+func loadFileObjectsProject(t *testing.T, f *tempdir.TempDirFixture, yaml string) *types.Project {
+	t.Helper()
+	configFile := types.ConfigFile{Content: []byte(yaml)}
+	config, err := loader.ParseYAML(configFile.Content)
+	require.NoError(t, err)
+	if moveEnvironmentSources(config) {
+		configFile.Config = config
+	}
+
+	proj, err := loader.Load(types.ConfigDetails{
+		WorkingDir:  f.Path(),
+		ConfigFiles: []types.ConfigFile{configFile},
+		Environment: map[string]string{},
+	}, dcLoaderOption("test"))
+	require.NoError(t, err)
+	return proj
+}
@@ -0,0 +1,171 @@
+package dockercompose
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/compose-spec/compose-go/loader"
+	"github.com/compose-spec/compose-go/template"
+)
+
+// ReferencedFiles lists the files that a compose project reads besides
+// its config files: env_files, the files that services extend, and
+// included compose fragments (recursively).
+//
+// Changes to any of these files change the project, so Tilt needs to
+// watch them. This reads the raw YAML rather than the loaded project,
+// because the loader resolves extends and includes away. It's best-effort:
+// files that can't be read or parsed are skipped, and the loader will
+// report the error.
+func ReferencedFiles(projectPath string, configPaths []string) []string {
+	w := &fileWalker{seen: make(map[string]bool)}
+	for _, p := range configPaths {
+		w.seen[absPath(projectPath, p)] = true
+	}
+	for _, p := range configPaths {
+		w.walk(absPath(projectPath, p), projectPath)
+	}
+
+	sort.Strings(w.result)
+	return w.result
+}
+
+type fileWalker struct {
+	seen   map[string]bool
+	result []string
+}
+
+func (w *fileWalker) add(path string) bool {
+	if w.seen[path] {
+		return false
+	}
+	w.seen[path] = true
+	w.result = append(w.result, path)
+	return true
+}
+
+// Reads the compose file at path. Relative paths in the file are
+// resolved against workingDir.
+func (w *fileWalker) walk(path string, workingDir string) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	config, err := loader.ParseYAML(contents)
+	if err != nil {
+		return
+	}
+
+	for _, inc := range asList(config["include"]) {
+		var paths []string
+		var envFiles []string
+		projectDir := ""
+		switch inc := inc.(type) {
+		case string:
+			paths = []string{inc}
+		case map[string]interface{}:
+			paths = stringList(inc["path"])
+			envFiles = stringList(inc["env_file"])
+			projectDir, _ = inc["project_directory"].(string)
+		}
+
+		for _, p := range envFiles {
+			w.addPath(workingDir, p)
+		}
+		for _, p := range paths {
+			incPath, ok := w.addPath(workingDir, p)
+			if !ok {
+				continue
+			}
+			incDir := filepath.Dir(incPath)
+			if projectDir != "" {
+				incDir = absPath(workingDir, interpolate(projectDir))
+			}
+			w.walk(incPath, incDir)
+		}
+	}
+
+	services, _ := config["services"].(map[string]interface{})
+	for _, svc := range services {
+		svc, ok := svc.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		for _, envFile := range asList(svc["env_file"]) {
+			switch envFile := envFile.(type) {
+			case string:
+				w.addPath(workingDir, envFile)
+			case map[string]interface{}:
+				p, _ := envFile["path"].(string)
+				w.addPath(workingDir, p)
+			}
+		}
+
+		extends, _ := svc["extends"].(map[string]interface{})
+		file, _ := extends["file"].(string)
+		extPath, ok := w.addPath(workingDir, file)
+		if ok {
+			w.walk(extPath, filepath.Dir(extPath))
+		}
+	}
+}
+
+// Adds a path from a compose file to the result. Returns the absolute
+// path, and whether it's new.
+func (w *fileWalker) addPath(workingDir string, p string) (string, bool) {
+	p = interpolate(p)
+	if p == "" {
+		return "", false
+	}
+	p = absPath(workingDir, p)
+	return p, w.add(p)
+}
+
+// Compose files can use environment variables in paths. If a variable
+// can't be substituted, use the path as-is; it's only used for watching.
+func interpolate(p string) string {
+	result, err := template.Substitute(p, os.LookupEnv)
+	if err != nil {
+		return p
+	}
+	return result
+}
+
+func absPath(workingDir string, p string) string {
+	if strings.HasPrefix(p, "~") {
+		home, err := os.UserHomeDir()
+		if err == nil {
+			return filepath.Join(home, p[1:])
+		}
+	}
+	if filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(workingDir, p)
+}
+
+// Compose accepts either a single value or a list in many places.
+func asList(v interface{}) []interface{} {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		return v
+	default:
+		return []interface{}{v}
+	}
+}
+
+func stringList(v interface{}) []string {
+	var result []string
+	for _, item := range asList(v) {
+		s, ok := item.(string)
+		if ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
@@ -0,0 +1,114 @@
+package dockercompose
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+)
+
+func TestReferencedFiles(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.WriteFile("docker-compose.yml", `
+include:
+  - lib/db.yml
+  - path: [lib/cache.yml]
+    env_file: cache.env
+services:
+  app:
+    image: app
+    env_file: app.env
+    extends:
+      file: base/common.yml
+      service: common
+`)
+	f.WriteFile("base/common.yml", `
+services:
+  common:
+    env_file:
+      - common.env
+      - path: optional.env
+        required: false
+`)
+	f.WriteFile("lib/db.yml", `
+services:
+  db:
+    image: postgres
+    env_file: db.env
+`)
+	f.WriteFile("lib/cache.yml", `
+services:
+  cache:
+    image: redis
+`)
+
+	files := ReferencedFiles(f.Path(), []string{f.JoinPath("docker-compose.yml")})
+	assert.Equal(t, []string{
+		f.JoinPath("app.env"),
+		f.JoinPath("base", "common.env"),
+		f.JoinPath("base", "common.yml"),
+		f.JoinPath("base", "optional.env"),
+		f.JoinPath("cache.env"),
+		f.JoinPath("lib", "cache.yml"),
+		f.JoinPath("lib", "db.env"),
+		f.JoinPath("lib", "db.yml"),
+	}, files)
+}
+
+func TestReferencedFilesRelativeToProject(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.WriteFile("docker-compose.yml", `
+services:
+  app:
+    image: app
+`)
+	// Like the compose CLI, paths in override files are relative
+	// to the project directory, not to the override file.
+	f.WriteFile("overrides/dev.yml", `
+services:
+  app:
+    env_file: dev.env
+`)
+
+	files := ReferencedFiles(f.Path(), []string{
+		f.JoinPath("docker-compose.yml"),
+		f.JoinPath("overrides", "dev.yml"),
+	})
+	assert.Equal(t, []string{f.JoinPath("dev.env")}, files)
+}
+
+func TestReferencedFilesCycle(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.WriteFile("docker-compose.yml", `
+services:
+  app:
+    extends:
+      file: base.yml
+      service: base
+`)
+	f.WriteFile("base.yml", `
+services:
+  base:
+    extends:
+      file: docker-compose.yml
+      service: app
+`)
+
+	files := ReferencedFiles(f.Path(), []string{f.JoinPath("docker-compose.yml")})
+	assert.Equal(t, []string{f.JoinPath("base.yml")}, files)
+}
+
+func TestReferencedFilesInterpolation(t *testing.T) {
+	t.Setenv("TILT_TEST_ENV_NAME", "staging")
+
+	f := tempdir.NewTempDirFixture(t)
+	f.WriteFile("docker-compose.yml", `
+services:
+  app:
+    env_file: ${TILT_TEST_ENV_NAME}.env
+`)
+
+	files := ReferencedFiles(f.Path(), []string{f.JoinPath("docker-compose.yml")})
+	assert.Equal(t, []string{f.JoinPath("staging.env")}, files)
+}
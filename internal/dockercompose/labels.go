@@ -0,0 +1,6 @@
+package dockercompose
+
+// A label on a service in the Docker Compose file, with a shell command
+// that reloads the service. After a live update, Tilt runs it in the
+// container instead of restarting the container.
+const LabelRestartCmd = "tilt.restart-cmd"
@@ -0,0 +1,77 @@
+package dockercompose
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+// docker-compose up is not thread-safe, because creating a project's networks
+// and volumes is non-atomic. See:
+// https://github.com/tilt-dev/tilt/issues/2817
+//
+// But once they exist, `up` for different services can run in parallel.
+// So each version of a project gets its own lock, which `up` holds until
+// the first service has come up (and created the networks and volumes).
+type projectSetup struct {
+	mu   sync.Mutex
+	done bool
+}
+
+// Waits until it's safe to bring up a service in the project.
+//
+// The caller must call the returned func when `up` finishes, with whether
+// it succeeded.
+func (c *cmdDCClient) lockProjectSetup(ctx context.Context, p v1alpha1.DockerComposeProject) func(succeeded bool) {
+	key := projectSetupKey(p)
+	c.setupMu.Lock()
+	setup, ok := c.setups[key]
+	if !ok {
+		setup = &projectSetup{}
+		c.setups[key] = setup
+	}
+	c.setupMu.Unlock()
+
+	if !setup.mu.TryLock() {
+		logger.Get(ctx).Infof("Waiting for another service to create the networks and volumes of project %s", p.Name)
+		setup.mu.Lock()
+	}
+
+	if setup.done {
+		setup.mu.Unlock()
+		return func(bool) {}
+	}
+
+	return func(succeeded bool) {
+		if succeeded {
+			setup.done = true
+		}
+		setup.mu.Unlock()
+	}
+}
+
+// Forgets which projects have been set up, e.g., after
+// `down` removes their networks.
+func (c *cmdDCClient) resetProjectSetups() {
+	c.setupMu.Lock()
+	defer c.setupMu.Unlock()
+	c.setups = make(map[string]*projectSetup)
+}
+
+// Networks and volumes can change with the project, so a new version
+// of the project needs to be set up again.
+func projectSetupKey(p v1alpha1.DockerComposeProject) string {
+	data, err := json.Marshal(p)
+	if err != nil {
+		// Should never happen, but if it does, fall back to the name,
+		// which just means we may not serialize after a config change.
+		return p.Name
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
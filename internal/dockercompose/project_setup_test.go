@@ -0,0 +1,86 @@
+package dockercompose
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tilt-dev/tilt/internal/docker"
+	"github.com/tilt-dev/tilt/internal/testutils"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+func TestProjectSetupSerializesFirstUp(t *testing.T) {
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	c := NewDockerComposeClient(docker.LocalEnv{}).(*cmdDCClient)
+	p := v1alpha1.DockerComposeProject{Name: "proj", YAML: "services: {}"}
+
+	unlockFirst := c.lockProjectSetup(ctx, p)
+	second := make(chan func(bool))
+	go func() {
+		second <- c.lockProjectSetup(ctx, p)
+	}()
+
+	select {
+	case <-second:
+		t.Fatal("second up should wait for the project to be set up")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	unlockFirst(true)
+	unlockSecond := <-second
+	unlockSecond(true)
+
+	// Once the project is set up, ups don't wait on each other.
+	unlockA := c.lockProjectSetup(ctx, p)
+	unlockB := c.lockProjectSetup(ctx, p)
+	unlockA(true)
+	unlockB(true)
+}
+
+func TestProjectSetupRetriesAfterFailure(t *testing.T) {
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	c := NewDockerComposeClient(docker.LocalEnv{}).(*cmdDCClient)
+	p := v1alpha1.DockerComposeProject{Name: "proj", YAML: "services: {}"}
+
+	c.lockProjectSetup(ctx, p)(false)
+
+	unlock := c.lockProjectSetup(ctx, p)
+	assertProjectSetupLocked(t, c, p)
+	unlock(true)
+}
+
+func TestProjectSetupPerVersion(t *testing.T) {
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	c := NewDockerComposeClient(docker.LocalEnv{}).(*cmdDCClient)
+	p := v1alpha1.DockerComposeProject{Name: "proj", YAML: "services: {}"}
+	c.lockProjectSetup(ctx, p)(true)
+
+	// A new network needs to be created again.
+	p2 := p
+	p2.YAML = "services: {}\nnetworks: {backend: {}}"
+	unlock := c.lockProjectSetup(ctx, p2)
+	assertProjectSetupLocked(t, c, p2)
+	unlock(true)
+
+	// So does everything after a down.
+	c.resetProjectSetups()
+	unlock = c.lockProjectSetup(ctx, p)
+	assertProjectSetupLocked(t, c, p)
+	unlock(true)
+}
+
+func assertProjectSetupLocked(t *testing.T, c *cmdDCClient, p v1alpha1.DockerComposeProject) {
+	t.Helper()
+	c.setupMu.Lock()
+	setup := c.setups[projectSetupKey(p)]
+	c.setupMu.Unlock()
+	if assert.NotNil(t, setup) {
+		locked := !setup.mu.TryLock()
+		if !locked {
+			setup.mu.Unlock()
+		}
+		assert.True(t, locked, "expected the up to hold the project setup lock")
+	}
+}
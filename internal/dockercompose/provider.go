@@ -0,0 +1,169 @@
+package dockercompose
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+
+	"golang.org/x/mod/semver"
+)
+
+// Compose providers that Tilt knows how to run. Any other provider name
+// is treated as the path to a compose binary.
+const (
+	// `docker compose`, falling back to `docker-compose`.
+	ProviderDocker = "docker"
+	// The standalone `docker-compose` binary.
+	ProviderDockerCompose = "docker-compose"
+	// `podman compose`, falling back to `podman-compose`.
+	ProviderPodman = "podman"
+	// `nerdctl compose`.
+	ProviderNerdctl = "nerdctl"
+)
+
+// Picks the compose provider for projects that don't specify one.
+const ProviderEnvVar = "TILT_DOCKER_COMPOSE_PROVIDER"
+
+// Overrides the compose binary for projects that don't specify a provider.
+// Predates ProviderEnvVar, which takes precedence.
+const CmdEnvVar = "TILT_DOCKER_COMPOSE_CMD"
+
+// The compose implementation that answered a provider's version probe.
+//
+// `podman compose` runs docker-compose or podman-compose under the hood,
+// so this isn't always the same as the provider.
+type Impl string
+
+const (
+	ImplDockerCompose Impl = "docker-compose"
+	ImplPodmanCompose Impl = "podman-compose"
+	ImplNerdctl       Impl = "nerdctl"
+)
+
+type ProviderVersion struct {
+	Impl Impl
+
+	// The canonical semver, e.g., v2.17.2.
+	Version string
+
+	// The build, if the provider reports one.
+	Build string
+}
+
+// Only docker-compose v1 builds images on `up` unless told not to.
+//
+// If we couldn't determine the version, assume docker-compose v1,
+// like Tilt always has.
+func (v ProviderVersion) needsNoBuild() bool {
+	switch v.Impl {
+	case ImplPodmanCompose:
+		return true
+	case ImplNerdctl:
+		return false
+	default:
+		return semver.Major(v.Version) != "v2"
+	}
+}
+
+// podman-compose and nerdctl don't implement `events` or `--verbose`.
+func (v ProviderVersion) supportsEvents() bool {
+	return v.Impl != ImplPodmanCompose && v.Impl != ImplNerdctl
+}
+
+func (v ProviderVersion) supportsVerbose() bool {
+	return v.Impl != ImplPodmanCompose && v.Impl != ImplNerdctl
+}
+
+// The command that runs a compose provider, and what it can do.
+type provider struct {
+	cmd     []string
+	version ProviderVersion
+	err     error
+}
+
+// The commands to try for a provider, in order.
+func providerCmds(name string) [][]string {
+	if name == "" {
+		name = os.Getenv(ProviderEnvVar)
+	}
+	if name == "" {
+		if cmd := os.Getenv(CmdEnvVar); cmd != "" {
+			return [][]string{{cmd}}
+		}
+		name = ProviderDocker
+	}
+
+	switch name {
+	case ProviderDocker:
+		return [][]string{{"docker", "compose"}, {"docker-compose"}}
+	case ProviderDockerCompose:
+		return [][]string{{"docker-compose"}}
+	case ProviderPodman:
+		return [][]string{{"podman", "compose"}, {"podman-compose"}}
+	case ProviderNerdctl:
+		return [][]string{{"nerdctl", "compose"}}
+	default:
+		return [][]string{{name}}
+	}
+}
+
+// Finds the first command for the provider that answers `version`.
+//
+// If none of them do, falls back to the last one, so that commands
+// fail with a useful error.
+func probeProvider(name string, environ []string) *provider {
+	var result provider
+	for _, cmd := range providerCmds(name) {
+		result = provider{cmd: cmd}
+		result.version, result.err = execVersion(cmd, environ)
+		if result.err == nil {
+			break
+		}
+	}
+	return &result
+}
+
+func execVersion(names []string, environ []string) (ProviderVersion, error) {
+	args := append(append([]string{}, names...), "version")
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Env = append(os.Environ(), environ...)
+	stdout, err := cmd.Output()
+	if err != nil {
+		return ProviderVersion{}, FormatError(cmd, stdout, err)
+	}
+	return parseProviderVersionOutput(stdout)
+}
+
+var podmanComposeVersionRegex = regexp.MustCompile(`(?mi)^podman-compose version:? v?([^\s,]+)`)
+var nerdctlVersionRegex = regexp.MustCompile(`(?mi)^nerdctl(?: compose)? version:? v?([^\s,]+)`)
+
+// Parses the output of `version` for each compose implementation we know.
+func parseProviderVersionOutput(stdout []byte) (ProviderVersion, error) {
+	stdout = bytes.TrimSpace(stdout)
+	if versionRegex.Match(stdout) {
+		version, build, err := parseComposeVersionOutput(stdout)
+		return ProviderVersion{Impl: ImplDockerCompose, Version: version, Build: build}, err
+	}
+
+	impls := []struct {
+		impl  Impl
+		regex *regexp.Regexp
+	}{
+		{ImplPodmanCompose, podmanComposeVersionRegex},
+		{ImplNerdctl, nerdctlVersionRegex},
+	}
+	for _, i := range impls {
+		m := i.regex.FindSubmatch(stdout)
+		if len(m) < 2 {
+			continue
+		}
+		version := semver.Canonical("v" + string(m[1]))
+		if version == "" {
+			return ProviderVersion{}, fmt.Errorf("invalid %s version: %q", i.impl, string(m[1]))
+		}
+		return ProviderVersion{Impl: i.impl, Version: version}, nil
+	}
+	return ProviderVersion{}, fmt.Errorf("could not parse version from output: %q", string(stdout))
+}
@@ -0,0 +1,95 @@
+package dockercompose
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseProviderVersionOutput(t *testing.T) {
+	type tc struct {
+		name     string
+		output   string
+		expected ProviderVersion
+	}
+	tcs := []tc{
+		{
+			name:     "docker compose",
+			output:   "Docker Compose version v2.17.2\n",
+			expected: ProviderVersion{Impl: ImplDockerCompose, Version: "v2.17.2"},
+		},
+		{
+			name: "podman-compose",
+			output: `podman-compose version: 1.0.6
+['podman', '--version', '']
+using podman version: 4.3.1
+podman-compose version 1.0.6
+podman --version
+podman version 4.3.1
+exit code: 0
+`,
+			expected: ProviderVersion{Impl: ImplPodmanCompose, Version: "v1.0.6"},
+		},
+		{
+			name: "podman compose with docker-compose",
+			output: `>>>> Executing external compose provider "/usr/local/bin/docker-compose". Please refer to the documentation for details. <<<<
+
+Docker Compose version v2.24.5
+`,
+			expected: ProviderVersion{Impl: ImplDockerCompose, Version: "v2.24.5"},
+		},
+		{
+			name:     "nerdctl",
+			output:   "nerdctl Compose version v1.7.6\n",
+			expected: ProviderVersion{Impl: ImplNerdctl, Version: "v1.7.6"},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := parseProviderVersionOutput([]byte(tc.output))
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestParseProviderVersionOutputUnknown(t *testing.T) {
+	_, err := parseProviderVersionOutput([]byte("my-compose 1.0\n"))
+	assert.Error(t, err)
+}
+
+func TestProviderCmds(t *testing.T) {
+	t.Setenv(ProviderEnvVar, "")
+	t.Setenv(CmdEnvVar, "")
+
+	assert.Equal(t, [][]string{{"docker", "compose"}, {"docker-compose"}}, providerCmds(""))
+	assert.Equal(t, [][]string{{"podman", "compose"}, {"podman-compose"}}, providerCmds(ProviderPodman))
+	assert.Equal(t, [][]string{{"nerdctl", "compose"}}, providerCmds(ProviderNerdctl))
+	assert.Equal(t, [][]string{{"/opt/bin/compose"}}, providerCmds("/opt/bin/compose"))
+
+	t.Setenv(CmdEnvVar, "/opt/bin/compose")
+	assert.Equal(t, [][]string{{"/opt/bin/compose"}}, providerCmds(""))
+
+	// The provider env var takes precedence over the legacy command env var,
+	// and a Tiltfile provider takes precedence over both.
+	t.Setenv(ProviderEnvVar, ProviderNerdctl)
+	assert.Equal(t, [][]string{{"nerdctl", "compose"}}, providerCmds(""))
+	assert.Equal(t, [][]string{{"docker-compose"}}, providerCmds(ProviderDockerCompose))
+}
+
+func TestProviderCapabilities(t *testing.T) {
+	v1 := ProviderVersion{Impl: ImplDockerCompose, Version: "v1.29.2"}
+	v2 := ProviderVersion{Impl: ImplDockerCompose, Version: "v2.17.2"}
+	podman := ProviderVersion{Impl: ImplPodmanCompose, Version: "v1.0.6"}
+	nerdctl := ProviderVersion{Impl: ImplNerdctl, Version: "v1.7.6"}
+
+	assert.True(t, v1.needsNoBuild())
+	assert.False(t, v2.needsNoBuild())
+	assert.True(t, podman.needsNoBuild())
+	assert.False(t, nerdctl.needsNoBuild())
+
+	assert.True(t, v2.supportsEvents())
+	assert.False(t, podman.supportsEvents())
+	assert.False(t, nerdctl.supportsEvents())
+}
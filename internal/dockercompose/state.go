@@ -4,9 +4,11 @@ import (
 	"fmt"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/network"
 	"github.com/docker/go-connections/nat"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
@@ -25,6 +27,12 @@ const ContainerStatusRemoving = "removing"
 const ContainerStatusExited = "exited"
 const ContainerStatusDead = "dead"
 
+// Health strings taken from:
+// https://godoc.org/github.com/docker/docker/api/types#Health
+const ContainerHealthStarting = "starting"
+const ContainerHealthHealthy = "healthy"
+const ContainerHealthUnhealthy = "unhealthy"
+
 // Helper functions for dealing with ContainerState.
 const ZeroTime = "0001-01-01T00:00:00Z"
 
@@ -43,6 +51,16 @@ func (s State) RuntimeStatus() v1alpha1.RuntimeStatus {
 	if s.ContainerState.Error != "" || s.ContainerState.ExitCode != 0 {
 		return v1alpha1.RuntimeStatusError
 	}
+
+	// A container with a healthcheck isn't ready until it passes,
+	// matching what `docker compose up --wait` waits for.
+	if s.ContainerState.Running && s.ContainerState.Health == ContainerHealthUnhealthy {
+		return v1alpha1.RuntimeStatusError
+	}
+	if s.ContainerState.Running && s.ContainerState.Health == ContainerHealthStarting {
+		return v1alpha1.RuntimeStatusPending
+	}
+
 	if s.ContainerState.Running ||
 		s.ContainerState.Status == ContainerStatusRunning ||
 		s.ContainerState.Status == ContainerStatusExited {
@@ -65,6 +83,12 @@ func (s State) RuntimeStatusError() error {
 	if s.ContainerState.ExitCode != 0 {
 		return fmt.Errorf("Container %s exited with %d", s.ContainerID, s.ContainerState.ExitCode)
 	}
+	if s.ContainerState.Health == ContainerHealthUnhealthy {
+		if s.ContainerState.HealthOutput != "" {
+			return fmt.Errorf("Container %s is unhealthy: %s", s.ContainerID, s.ContainerState.HealthOutput)
+		}
+		return fmt.Errorf("Container %s is unhealthy", s.ContainerID)
+	}
 	return fmt.Errorf("Container %s error status: %s", s.ContainerID, s.ContainerState.Status)
 }
 
@@ -101,6 +125,25 @@ func (s State) HasEverBeenReadyOrSucceeded() bool {
 	return !s.LastReadyTime.IsZero()
 }
 
+// Whether the container has reached a `depends_on` condition,
+// so that services that depend on it can start.
+func (s State) SatisfiesCondition(condition string) bool {
+	cState := s.ContainerState
+	switch condition {
+	case v1alpha1.DockerComposeConditionServiceHealthy:
+		if cState.Health == "" {
+			// Docker Compose rejects service_healthy on a service without
+			// a healthcheck. Fall back to readiness rather than wait forever.
+			return s.HasEverBeenReadyOrSucceeded()
+		}
+		return cState.Running && cState.Health == ContainerHealthHealthy
+	case v1alpha1.DockerComposeConditionServiceCompletedSuccessfully:
+		return cState.Status == ContainerStatusExited && cState.ExitCode == 0 && cState.Error == ""
+	default:
+		return cState.Running || s.HasEverBeenReadyOrSucceeded()
+	}
+}
+
 // Convert ContainerState into an apiserver-compatible state model.
 func ToContainerState(state *types.ContainerState) *v1alpha1.DockerContainerState {
 	if state == nil {
@@ -122,48 +165,104 @@ func ToContainerState(state *types.ContainerState) *v1alpha1.DockerContainerStat
 		}
 	}
 
+	health := ""
+	healthOutput := ""
+	if state.Health != nil && state.Health.Status != types.NoHealthcheck {
+		health = state.Health.Status
+
+		// Docker keeps the last few probes, oldest first.
+		if n := len(state.Health.Log); n > 0 && state.Health.Log[n-1] != nil {
+			healthOutput = strings.TrimSpace(state.Health.Log[n-1].Output)
+		}
+	}
+
 	return &v1alpha1.DockerContainerState{
-		Status:     state.Status,
-		Running:    state.Running,
-		Error:      state.Error,
-		ExitCode:   int32(state.ExitCode),
-		StartedAt:  metav1.NewMicroTime(startedAt),
-		FinishedAt: metav1.NewMicroTime(finishedAt),
+		Status:       state.Status,
+		Running:      state.Running,
+		Error:        state.Error,
+		ExitCode:     int32(state.ExitCode),
+		StartedAt:    metav1.NewMicroTime(startedAt),
+		FinishedAt:   metav1.NewMicroTime(finishedAt),
+		Health:       health,
+		HealthOutput: healthOutput,
 	}
 }
 
 // Convert a full into an apiserver-compatible status model.
-func ToServiceStatus(id container.ID, name string, state *types.ContainerState, ports nat.PortMap) v1alpha1.DockerComposeServiceStatus {
+//
+// netSettings may be nil if we couldn't inspect the container.
+func ToServiceStatus(id container.ID, name string, state *types.ContainerState, netSettings *types.NetworkSettings) v1alpha1.DockerComposeServiceStatus {
 	status := v1alpha1.DockerComposeServiceStatus{}
 	status.ContainerID = string(id)
 	status.ContainerName = name
 	status.ContainerState = ToContainerState(state)
+	if netSettings == nil {
+		return status
+	}
+
+	status.PortBindings = toPortBindings(netSettings.Ports)
+	status.Networks = toNetworkAttachments(id, netSettings.Networks)
+	return status
+}
 
+func toPortBindings(ports nat.PortMap) []v1alpha1.DockerPortBinding {
+	var result []v1alpha1.DockerPortBinding
 	for containerPort, bindings := range ports {
 		for _, binding := range bindings {
 			p, err := strconv.Atoi(binding.HostPort)
 			if err != nil || p == 0 {
 				continue
 			}
-			status.PortBindings = append(status.PortBindings, v1alpha1.DockerPortBinding{
+			result = append(result, v1alpha1.DockerPortBinding{
 				ContainerPort: int32(containerPort.Int()),
 				HostIP:        binding.HostIP,
 				HostPort:      int32(p),
+				Protocol:      containerPort.Proto(),
 			})
 		}
 	}
 
 	// `ports` is a map, so make sure the ports come out in a deterministic order.
-	sort.Slice(status.PortBindings, func(i, j int) bool {
-		pi := status.PortBindings[i]
-		pj := status.PortBindings[j]
-		if pi.HostPort < pj.HostPort {
-			return true
+	sort.Slice(result, func(i, j int) bool {
+		pi := result[i]
+		pj := result[j]
+		if pi.HostPort != pj.HostPort {
+			return pi.HostPort < pj.HostPort
 		}
-		if pi.HostPort > pj.HostPort {
-			return false
+		if pi.HostIP != pj.HostIP {
+			return pi.HostIP < pj.HostIP
 		}
-		return pi.HostIP < pj.HostIP
+		return pi.Protocol < pj.Protocol
 	})
-	return status
+	return result
+}
+
+func toNetworkAttachments(id container.ID, networks map[string]*network.EndpointSettings) []v1alpha1.DockerNetworkAttachment {
+	var result []v1alpha1.DockerNetworkAttachment
+	for name, settings := range networks {
+		if settings == nil {
+			continue
+		}
+
+		var aliases []string
+		for _, alias := range settings.Aliases {
+			// Older versions of Docker add the short container ID as an alias,
+			// which isn't a name anyone would use.
+			if alias == "" || strings.HasPrefix(string(id), alias) {
+				continue
+			}
+			aliases = append(aliases, alias)
+		}
+		sort.Strings(aliases)
+
+		result = append(result, v1alpha1.DockerNetworkAttachment{
+			Name:      name,
+			Aliases:   aliases,
+			IPAddress: settings.IPAddress,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
 }
@@ -0,0 +1,124 @@
+package dockercompose
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/go-connections/nat"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+func TestRuntimeStatusWithHealthcheck(t *testing.T) {
+	for _, tc := range []struct {
+		health   string
+		expected v1alpha1.RuntimeStatus
+	}{
+		{"", v1alpha1.RuntimeStatusOK},
+		{ContainerHealthStarting, v1alpha1.RuntimeStatusPending},
+		{ContainerHealthHealthy, v1alpha1.RuntimeStatusOK},
+		{ContainerHealthUnhealthy, v1alpha1.RuntimeStatusError},
+	} {
+		t.Run(tc.health, func(t *testing.T) {
+			s := State{}.WithContainerState(v1alpha1.DockerContainerState{
+				Status:  ContainerStatusRunning,
+				Running: true,
+				Health:  tc.health,
+			})
+			assert.Equal(t, tc.expected, s.RuntimeStatus())
+			assert.Equal(t, tc.expected == v1alpha1.RuntimeStatusOK, s.HasEverBeenReadyOrSucceeded())
+		})
+	}
+}
+
+func TestSatisfiesCondition(t *testing.T) {
+	starting := State{}.WithContainerState(v1alpha1.DockerContainerState{
+		Status: ContainerStatusRunning, Running: true, Health: ContainerHealthStarting,
+	})
+	assert.True(t, starting.SatisfiesCondition(v1alpha1.DockerComposeConditionServiceStarted))
+	assert.False(t, starting.SatisfiesCondition(v1alpha1.DockerComposeConditionServiceHealthy))
+	assert.False(t, starting.SatisfiesCondition(v1alpha1.DockerComposeConditionServiceCompletedSuccessfully))
+
+	healthy := starting.WithContainerState(v1alpha1.DockerContainerState{
+		Status: ContainerStatusRunning, Running: true, Health: ContainerHealthHealthy,
+	})
+	assert.True(t, healthy.SatisfiesCondition(v1alpha1.DockerComposeConditionServiceHealthy))
+
+	noHealthcheck := State{}.WithContainerState(v1alpha1.DockerContainerState{
+		Status: ContainerStatusRunning, Running: true,
+	})
+	assert.True(t, noHealthcheck.SatisfiesCondition(v1alpha1.DockerComposeConditionServiceHealthy))
+
+	succeeded := State{}.WithContainerState(v1alpha1.DockerContainerState{Status: ContainerStatusExited})
+	assert.True(t, succeeded.SatisfiesCondition(v1alpha1.DockerComposeConditionServiceCompletedSuccessfully))
+
+	failed := State{}.WithContainerState(v1alpha1.DockerContainerState{Status: ContainerStatusExited, ExitCode: 1})
+	assert.False(t, failed.SatisfiesCondition(v1alpha1.DockerComposeConditionServiceCompletedSuccessfully))
+}
+
+func TestToContainerStateHealth(t *testing.T) {
+	state := ToContainerState(&types.ContainerState{
+		Status:  ContainerStatusRunning,
+		Running: true,
+		Health:  &types.Health{Status: types.Healthy},
+	})
+	assert.Equal(t, ContainerHealthHealthy, state.Health)
+
+	state = ToContainerState(&types.ContainerState{
+		Status:  ContainerStatusRunning,
+		Running: true,
+		Health:  &types.Health{Status: types.NoHealthcheck},
+	})
+	assert.Equal(t, "", state.Health)
+}
+
+func TestToContainerStateHealthOutput(t *testing.T) {
+	state := ToContainerState(&types.ContainerState{
+		Status:  ContainerStatusRunning,
+		Running: true,
+		Health: &types.Health{
+			Status: types.Unhealthy,
+			Log: []*types.HealthcheckResult{
+				{ExitCode: 0, Output: "ok\n"},
+				{ExitCode: 1, Output: "connection refused\n"},
+			},
+		},
+	})
+	assert.Equal(t, ContainerHealthUnhealthy, state.Health)
+	assert.Equal(t, "connection refused", state.HealthOutput)
+
+	s := State{ContainerID: "cid"}.WithContainerState(*state)
+	assert.Equal(t, v1alpha1.RuntimeStatusError, s.RuntimeStatus())
+	assert.EqualError(t, s.RuntimeStatusError(), "Container cid is unhealthy: connection refused")
+}
+
+func TestToServiceStatusNetwork(t *testing.T) {
+	status := ToServiceStatus("0123456789abcdef", "proj-web-1", &types.ContainerState{Running: true}, &types.NetworkSettings{
+		NetworkSettingsBase: types.NetworkSettingsBase{
+			Ports: nat.PortMap{
+				"80/tcp": []nat.PortBinding{
+					{HostIP: "::", HostPort: "8080"},
+					{HostIP: "0.0.0.0", HostPort: "8080"},
+				},
+				"53/udp":   []nat.PortBinding{{HostIP: "0.0.0.0", HostPort: "5353"}},
+				"9000/tcp": nil,
+			},
+		},
+		Networks: map[string]*network.EndpointSettings{
+			"proj_default": {Aliases: []string{"web", "proj-web-1", "0123456789ab"}, IPAddress: "172.18.0.2"},
+			"proj_backend": {Aliases: []string{"api"}, IPAddress: "172.19.0.3"},
+		},
+	})
+
+	assert.Equal(t, []v1alpha1.DockerPortBinding{
+		{ContainerPort: 53, HostIP: "0.0.0.0", HostPort: 5353, Protocol: "udp"},
+		{ContainerPort: 80, HostIP: "0.0.0.0", HostPort: 8080, Protocol: "tcp"},
+		{ContainerPort: 80, HostIP: "::", HostPort: 8080, Protocol: "tcp"},
+	}, status.PortBindings)
+	assert.Equal(t, []v1alpha1.DockerNetworkAttachment{
+		{Name: "proj_backend", Aliases: []string{"api"}, IPAddress: "172.19.0.3"},
+		{Name: "proj_default", Aliases: []string{"proj-web-1", "web"}, IPAddress: "172.18.0.2"},
+	}, status.Networks)
+}
@@ -0,0 +1,217 @@
+package dockerfile
+
+import (
+	"path"
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+	"github.com/pkg/errors"
+)
+
+// A starting point for an image's live_update, guessed from its Dockerfile.
+//
+// This is a heuristic. It doesn't know what the image's build steps do,
+// so users should always review it.
+type LiveUpdateSuggestion struct {
+	Syncs []LiveUpdateSync
+	Runs  []LiveUpdateRun
+}
+
+type LiveUpdateSync struct {
+	// Relative to the build context.
+	LocalPath     string
+	ContainerPath string
+}
+
+type LiveUpdateRun struct {
+	Command string
+
+	// The dependency files that should re-run the command, relative to the build context.
+	Triggers []string
+}
+
+// Files that tell a package manager what to install.
+//
+// A Dockerfile that copies one of these and then runs a command is
+// almost always installing dependencies, which is what a live_update run()
+// step with a trigger is for.
+var dependencyFiles = map[string]bool{
+	"package.json":        true,
+	"package-lock.json":   true,
+	"yarn.lock":           true,
+	"pnpm-lock.yaml":      true,
+	"requirements.txt":    true,
+	"Pipfile":             true,
+	"Pipfile.lock":        true,
+	"pyproject.toml":      true,
+	"poetry.lock":         true,
+	"Gemfile":             true,
+	"Gemfile.lock":        true,
+	"go.mod":              true,
+	"go.sum":              true,
+	"Cargo.toml":          true,
+	"Cargo.lock":          true,
+	"composer.json":       true,
+	"composer.lock":       true,
+	"mix.exs":             true,
+	"mix.lock":            true,
+	"pom.xml":             true,
+	"build.gradle":        true,
+	"build.gradle.kts":    true,
+	"settings.gradle":     true,
+	"settings.gradle.kts": true,
+}
+
+func isDependencyFile(p string) bool {
+	return dependencyFiles[path.Base(p)]
+}
+
+// Proposes live_update steps for the final stage of a Dockerfile.
+//
+// Files copied from the build context become syncs. A RUN after copying
+// dependency files becomes a run() step triggered by those files.
+//
+// workDir is the container's working directory, for Dockerfiles that
+// inherit it from their base image. If empty, we assume "/".
+func SuggestLiveUpdate(df Dockerfile, workDir string) (LiveUpdateSuggestion, error) {
+	result, err := parser.Parse(newReader(df))
+	if err != nil {
+		return LiveUpdateSuggestion{}, errors.Wrap(err, "dockerfile.SuggestLiveUpdate")
+	}
+
+	stages, _, err := instructions.Parse(result.AST)
+	if err != nil {
+		return LiveUpdateSuggestion{}, errors.Wrap(err, "dockerfile.SuggestLiveUpdate")
+	}
+	if len(stages) == 0 {
+		return LiveUpdateSuggestion{}, nil
+	}
+
+	cwd := workDir
+	if cwd == "" {
+		cwd = "/"
+	}
+
+	// Earlier stages only matter through what the final stage copies from them,
+	// and those files aren't in the build context, so we can't sync them.
+	stage := stages[len(stages)-1]
+	var syncs []LiveUpdateSync
+	var runs []LiveUpdateRun
+	var runDirs []string
+	var deps []string
+	for _, cmd := range stage.Commands {
+		switch cmd := cmd.(type) {
+		case *instructions.WorkdirCommand:
+			cwd = resolveContainerPath(cwd, cmd.Path)
+		case *instructions.CopyCommand:
+			if cmd.From != "" {
+				continue
+			}
+			newSyncs, newDeps := copySyncs(cmd.SourcesAndDest, cwd)
+			syncs = append(syncs, newSyncs...)
+			deps = append(deps, newDeps...)
+		case *instructions.AddCommand:
+			newSyncs, newDeps := copySyncs(cmd.SourcesAndDest, cwd)
+			syncs = append(syncs, newSyncs...)
+			deps = append(deps, newDeps...)
+		case *instructions.RunCommand:
+			if len(deps) == 0 {
+				continue
+			}
+			runs = append(runs, LiveUpdateRun{Command: strings.Join(cmd.CmdLine, " "), Triggers: deps})
+			runDirs = append(runDirs, cwd)
+			deps = nil
+		}
+	}
+
+	suggestion := LiveUpdateSuggestion{Syncs: dedupeSyncs(syncs)}
+	for i, run := range runs {
+		// Live update runs commands in the container's working directory,
+		// which is wherever the Dockerfile left it.
+		if runDirs[i] != cwd {
+			run.Command = "cd " + runDirs[i] + " && " + run.Command
+		}
+		suggestion.Runs = append(suggestion.Runs, run)
+	}
+	return suggestion, nil
+}
+
+// Converts a COPY or ADD from the build context into syncs, and reports
+// which of its sources are dependency files.
+func copySyncs(sd instructions.SourcesAndDest, cwd string) ([]LiveUpdateSync, []string) {
+	sources := sd.Sources()
+	dest := resolveContainerPath(cwd, sd.Dest())
+
+	// Like Docker, treat the destination as a directory if it says so,
+	// or if there's more than one thing to copy into it.
+	destIsDir := strings.HasSuffix(sd.Dest(), "/") || path.Base(sd.Dest()) == "." || len(sources) > 1
+
+	var syncs []LiveUpdateSync
+	var deps []string
+	for _, src := range sources {
+		if isURL(src) || strings.ContainsAny(src, "*?[") {
+			continue
+		}
+
+		local := path.Clean(strings.TrimPrefix(src, "/"))
+		containerPath := dest
+		if destIsDir && local != "." {
+			containerPath = path.Join(dest, path.Base(local))
+		}
+		syncs = append(syncs, LiveUpdateSync{LocalPath: local, ContainerPath: containerPath})
+		if isDependencyFile(local) {
+			deps = append(deps, local)
+		}
+	}
+	return syncs, deps
+}
+
+// Drops syncs that a broader sync already covers, e.g., a COPY of
+// package.json that's followed by a COPY of the whole directory.
+func dedupeSyncs(syncs []LiveUpdateSync) []LiveUpdateSync {
+	var result []LiveUpdateSync
+	for i, s := range syncs {
+		covered := false
+		for j, other := range syncs {
+			if i == j {
+				continue
+			}
+			if s == other {
+				covered = j < i
+			} else {
+				covered = syncCovers(other, s)
+			}
+			if covered {
+				break
+			}
+		}
+		if !covered {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+func syncCovers(parent, child LiveUpdateSync) bool {
+	var rel string
+	if parent.LocalPath == "." {
+		rel = child.LocalPath
+	} else if strings.HasPrefix(child.LocalPath, parent.LocalPath+"/") {
+		rel = strings.TrimPrefix(child.LocalPath, parent.LocalPath+"/")
+	} else {
+		return false
+	}
+	return rel != "." && path.Join(parent.ContainerPath, rel) == child.ContainerPath
+}
+
+func resolveContainerPath(cwd, p string) string {
+	if path.IsAbs(p) {
+		return path.Clean(p)
+	}
+	return path.Join(cwd, p)
+}
+
+func isURL(src string) bool {
+	return strings.HasPrefix(src, "http://") || strings.HasPrefix(src, "https://")
+}
@@ -0,0 +1,71 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuggestLiveUpdateNode(t *testing.T) {
+	df := Dockerfile(`
+FROM node:18
+WORKDIR /app
+COPY package.json yarn.lock ./
+RUN yarn install
+COPY . .
+CMD ["node", "index.js"]
+`)
+	s, err := SuggestLiveUpdate(df, "")
+	require.NoError(t, err)
+	assert.Equal(t, []LiveUpdateSync{{LocalPath: ".", ContainerPath: "/app"}}, s.Syncs)
+	assert.Equal(t, []LiveUpdateRun{
+		{Command: "yarn install", Triggers: []string{"package.json", "yarn.lock"}},
+	}, s.Runs)
+}
+
+func TestSuggestLiveUpdateFinalStageOnly(t *testing.T) {
+	df := Dockerfile(`
+FROM golang:1.20 AS builder
+COPY . /src
+RUN go build -o /out/app ./cmd/app
+
+FROM alpine
+COPY --from=builder /out/app /usr/bin/app
+COPY config/app.yaml /etc/app/
+`)
+	s, err := SuggestLiveUpdate(df, "")
+	require.NoError(t, err)
+	assert.Equal(t, []LiveUpdateSync{{LocalPath: "config/app.yaml", ContainerPath: "/etc/app/app.yaml"}}, s.Syncs)
+	assert.Empty(t, s.Runs)
+}
+
+func TestSuggestLiveUpdateRunInOtherDir(t *testing.T) {
+	df := Dockerfile(`
+FROM python:3.11
+COPY requirements.txt /deps/
+WORKDIR /deps
+RUN pip install -r requirements.txt
+WORKDIR /app
+COPY src src
+`)
+	s, err := SuggestLiveUpdate(df, "")
+	require.NoError(t, err)
+	assert.Equal(t, []LiveUpdateSync{
+		{LocalPath: "requirements.txt", ContainerPath: "/deps/requirements.txt"},
+		{LocalPath: "src", ContainerPath: "/app/src"},
+	}, s.Syncs)
+	assert.Equal(t, []LiveUpdateRun{
+		{Command: "cd /deps && pip install -r requirements.txt", Triggers: []string{"requirements.txt"}},
+	}, s.Runs)
+}
+
+func TestSuggestLiveUpdateInheritedWorkDir(t *testing.T) {
+	df := Dockerfile(`
+FROM my-base
+COPY app.py .
+`)
+	s, err := SuggestLiveUpdate(df, "/srv")
+	require.NoError(t, err)
+	assert.Equal(t, []LiveUpdateSync{{LocalPath: "app.py", ContainerPath: "/srv/app.py"}}, s.Syncs)
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/internal/token"
 	"github.com/tilt-dev/tilt/pkg/model"
+	"github.com/tilt-dev/tilt/pkg/model/logstore"
 	"github.com/tilt-dev/wmclient/pkg/analytics"
 )
 
@@ -30,6 +31,8 @@ type InitAction struct {
 	CloudAddress string
 	Token        token.Token
 	TerminalMode store.TerminalMode
+
+	LogPrefixFormat logstore.PrefixFormat
 }
 
 func (InitAction) Action() {}
@@ -0,0 +1,105 @@
+package bandwidth
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/tilt-dev/tilt/internal/controllers/apis/configmap"
+	"github.com/tilt-dev/tilt/internal/controllers/apis/uibutton"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// Manages low-bandwidth mode, for developers working against remote
+// clusters over slow links.
+//
+// The mode lives in a ConfigMap, so that it can be toggled at runtime from
+// the UI. Reconcilers read the ConfigMap directly and decide for themselves
+// what to cut back on (log backfill, polling, image pulls, live update
+// payload size).
+//
+// bandwidth_settings(low=...) in the Tiltfile sets the mode when Tilt starts.
+// After that, we only apply the Tiltfile setting when it changes, so that
+// reloading the Tiltfile doesn't undo a toggle from the UI.
+type Controller struct {
+	client ctrlclient.Client
+
+	initialized bool
+
+	// The Tiltfile setting that we last applied.
+	applied model.BandwidthSettings
+
+	// The mode that we last told the user about.
+	low bool
+}
+
+var _ store.Subscriber = &Controller{}
+
+func NewController(client ctrlclient.Client) *Controller {
+	return &Controller{
+		client: client,
+	}
+}
+
+func (c *Controller) OnChange(ctx context.Context, st store.RStore, summary store.ChangeSummary) error {
+	if summary.IsLogOnly() {
+		return nil
+	}
+
+	state := st.RLockState()
+	// The API server isn't ready until the Tiltfile object exists.
+	_, ready := state.Tiltfiles[model.MainTiltfileManifestName.String()]
+	settings := state.BandwidthSettings
+	low := configmap.IsLowBandwidth(state.ConfigMaps[configmap.BandwidthName])
+	st.RUnlockState()
+
+	if !ready {
+		return nil
+	}
+
+	if !c.initialized {
+		err := c.initialize(ctx)
+		if err != nil {
+			st.Dispatch(store.NewErrorAction(fmt.Errorf("failed to initialize bandwidth controller: %v", err)))
+			return nil
+		}
+		c.initialized = true
+	}
+
+	if settings != c.applied {
+		err := configmap.SetLowBandwidth(ctx, c.client, settings.Low)
+		if err != nil {
+			logger.Get(ctx).Errorf("Setting low-bandwidth mode: %v", err)
+			return nil
+		}
+		c.applied = settings
+	}
+
+	if low != c.low {
+		c.low = low
+		if low {
+			logger.Get(ctx).Infof("Low-bandwidth mode on: skipping log backfill, polling the cluster less often, " +
+				"skipping base image pulls, and compressing live updates")
+		} else {
+			logger.Get(ctx).Infof("Low-bandwidth mode off")
+		}
+	}
+	return nil
+}
+
+func (c *Controller) initialize(ctx context.Context) error {
+	err := c.client.Create(ctx, configmap.BandwidthCreate(false))
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+
+	err = c.client.Create(ctx, uibutton.BandwidthToggleButton())
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package bandwidth
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/tilt-dev/tilt/internal/controllers/apis/configmap"
+	"github.com/tilt-dev/tilt/internal/controllers/apis/uibutton"
+	"github.com/tilt-dev/tilt/internal/controllers/fake"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestWaitsForTiltfile(t *testing.T) {
+	f := newFixture(t)
+	f.onChange()
+	assert.False(t, f.hasConfigMap())
+
+	f.addTiltfile()
+	f.onChange()
+	assert.True(t, f.hasConfigMap())
+	assert.False(t, f.isLow())
+
+	var tb v1alpha1.ToggleButton
+	err := f.client.Get(f.ctx, types.NamespacedName{Name: uibutton.BandwidthToggleButtonName}, &tb)
+	require.NoError(t, err)
+	assert.Equal(t, configmap.BandwidthName, tb.Spec.StateSource.ConfigMap.Name)
+}
+
+func TestTiltfileSetting(t *testing.T) {
+	f := newFixture(t)
+	f.addTiltfile()
+	f.setSettings(model.BandwidthSettings{Low: true})
+	f.onChange()
+	assert.True(t, f.isLow())
+
+	f.syncConfigMap()
+	f.onChange()
+	assert.Contains(t, f.out.String(), "Low-bandwidth mode on")
+
+	f.setSettings(model.BandwidthSettings{})
+	f.onChange()
+	assert.False(t, f.isLow())
+
+	f.syncConfigMap()
+	f.onChange()
+	assert.Contains(t, f.out.String(), "Low-bandwidth mode off")
+}
+
+func TestToggleSurvivesTiltfileReload(t *testing.T) {
+	f := newFixture(t)
+	f.addTiltfile()
+	f.setSettings(model.BandwidthSettings{Low: true})
+	f.onChange()
+	assert.True(t, f.isLow())
+
+	// Someone switches low-bandwidth mode off in the UI.
+	require.NoError(t, configmap.SetLowBandwidth(f.ctx, f.client, false))
+	f.syncConfigMap()
+
+	// The Tiltfile reloads with the same setting.
+	f.setSettings(model.BandwidthSettings{Low: true})
+	f.onChange()
+	assert.False(t, f.isLow())
+}
+
+type fixture struct {
+	t      *testing.T
+	ctx    context.Context
+	out    *bytes.Buffer
+	client ctrlclient.Client
+	st     *store.TestingStore
+	c      *Controller
+}
+
+func newFixture(t *testing.T) *fixture {
+	out := &bytes.Buffer{}
+	ctx := logger.WithLogger(context.Background(), logger.NewTestLogger(out))
+	client := fake.NewFakeTiltClient()
+	st := store.NewTestingStore()
+
+	return &fixture{
+		t:      t,
+		ctx:    ctx,
+		out:    out,
+		client: client,
+		st:     st,
+		c:      NewController(client),
+	}
+}
+
+func (f *fixture) addTiltfile() {
+	state := f.st.LockMutableStateForTesting()
+	state.Tiltfiles[model.MainTiltfileManifestName.String()] = &v1alpha1.Tiltfile{
+		ObjectMeta: metav1.ObjectMeta{Name: model.MainTiltfileManifestName.String()},
+	}
+	f.st.UnlockMutableState()
+}
+
+func (f *fixture) setSettings(settings model.BandwidthSettings) {
+	state := f.st.LockMutableStateForTesting()
+	state.BandwidthSettings = settings
+	f.st.UnlockMutableState()
+}
+
+// Simulates the ConfigMap reconciler copying the ConfigMap into the engine state.
+func (f *fixture) syncConfigMap() {
+	var cm v1alpha1.ConfigMap
+	err := f.client.Get(f.ctx, types.NamespacedName{Name: configmap.BandwidthName}, &cm)
+	require.NoError(f.t, err)
+
+	state := f.st.LockMutableStateForTesting()
+	state.ConfigMaps[configmap.BandwidthName] = &cm
+	f.st.UnlockMutableState()
+}
+
+func (f *fixture) onChange() {
+	err := f.c.OnChange(f.ctx, f.st, store.ChangeSummary{})
+	require.NoError(f.t, err)
+}
+
+func (f *fixture) hasConfigMap() bool {
+	var cm v1alpha1.ConfigMap
+	err := f.client.Get(f.ctx, types.NamespacedName{Name: configmap.BandwidthName}, &cm)
+	return err == nil
+}
+
+func (f *fixture) isLow() bool {
+	return configmap.LowBandwidth(f.ctx, f.client)
+}
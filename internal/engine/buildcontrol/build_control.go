@@ -7,6 +7,7 @@ import (
 
 	"github.com/tilt-dev/tilt/internal/build"
 	"github.com/tilt-dev/tilt/internal/controllers/apis/liveupdate"
+	"github.com/tilt-dev/tilt/internal/dockercompose"
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/internal/store/k8sconv"
 	"github.com/tilt-dev/tilt/internal/store/liveupdates"
@@ -131,10 +132,31 @@ func waitingOnDependencies(state store.EngineState, mt *store.ManifestTarget) []
 		return nil
 	}
 
+	conditions := dockerComposeConditions(mt.Manifest)
+
 	var waitingOn []model.TargetID
 	for _, mn := range mt.Manifest.ResourceDependencies {
 		ms, ok := state.ManifestState(mn)
-		if !ok || ms == nil || ms.RuntimeState == nil || !ms.RuntimeState.HasEverBeenReadyOrSucceeded() {
+		if !ok || ms == nil || ms.RuntimeState == nil {
+			waitingOn = append(waitingOn, mn.TargetID())
+			continue
+		}
+
+		// Each backend decides when its resources are ready, so a Kubernetes
+		// resource can wait on a Docker Compose service, and vice versa.
+		//
+		// A Docker Compose `depends_on` can wait for a condition
+		// other than readiness (e.g., for a one-shot migration to finish).
+		dcState, isDC := ms.RuntimeState.(dockercompose.State)
+		condition, hasCondition := conditions[mn]
+		if isDC && hasCondition {
+			if !dcState.SatisfiesCondition(condition) {
+				waitingOn = append(waitingOn, mn.TargetID())
+			}
+			continue
+		}
+
+		if !ms.RuntimeState.HasEverBeenReadyOrSucceeded() {
 			waitingOn = append(waitingOn, mn.TargetID())
 		}
 	}
@@ -142,6 +164,21 @@ func waitingOnDependencies(state store.EngineState, mt *store.ManifestTarget) []
 	return waitingOn
 }
 
+// The `depends_on` conditions of a Docker Compose service, by dependency.
+//
+// Compose services are named after their service, so the
+// service name is also the manifest name.
+func dockerComposeConditions(m model.Manifest) map[model.ManifestName]string {
+	if !m.IsDC() {
+		return nil
+	}
+	result := make(map[model.ManifestName]string)
+	for _, dep := range m.DockerComposeTarget().Spec.DependsOn {
+		result[model.ManifestName(dep.Service)] = dep.Condition
+	}
+	return result
+}
+
 // Check to see if this is an ImageTarget where the built image
 // can be potentially reused.
 //
@@ -249,12 +286,15 @@ func targetsByCluster(mts []*store.ManifestTarget) map[string][]*store.ManifestT
 // We use the cluster to detect what architecture we're building for.
 // Until the cluster connection has been established, we block any
 // image builds.
+//
+// A Tiltfile with both Kubernetes and Docker Compose resources has a cluster
+// for each, so each cluster only holds its own targets.
 func HoldTargetsWaitingOnCluster(state store.EngineState, mts []*store.ManifestTarget, holds HoldSet) {
 	for clusterName, targets := range targetsByCluster(mts) {
 		cluster, ok := state.Clusters[clusterName]
 		isClusterOK := ok && cluster.Status.Error == "" && cluster.Status.Arch != ""
 		if isClusterOK {
-			return
+			continue
 		}
 
 		gvk := v1alpha1.SchemeGroupVersion.WithKind("Cluster")
@@ -15,6 +15,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 
 	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/dockercompose"
 	"github.com/tilt-dev/tilt/internal/k8s"
 	"github.com/tilt-dev/tilt/internal/k8s/testyaml"
 	"github.com/tilt-dev/tilt/internal/store"
@@ -117,6 +118,124 @@ func TestK8sDependsOnLocal(t *testing.T) {
 	_ = k8s2
 }
 
+func TestDockerComposeDependsOnConditions(t *testing.T) {
+	f := newTestFixture(t)
+	f.st.Clusters[v1alpha1.ClusterNameDocker] = &v1alpha1.Cluster{
+		Status: v1alpha1.ClusterStatus{Arch: "amd64"},
+	}
+
+	app := f.upsertDCManifest("app",
+		v1alpha1.DockerComposeServiceDependency{Service: "db", Condition: v1alpha1.DockerComposeConditionServiceHealthy},
+		v1alpha1.DockerComposeServiceDependency{Service: "migrate", Condition: v1alpha1.DockerComposeConditionServiceCompletedSuccessfully})
+	db := f.upsertDCManifest("db")
+	migrate := f.upsertDCManifest("migrate")
+	for _, mt := range []*store.ManifestTarget{db, migrate} {
+		mt.State.AddCompletedBuild(model.BuildRecord{StartTime: time.Now(), FinishTime: time.Now()})
+	}
+
+	// Both containers are running, but the db is still starting
+	// and the migration hasn't finished.
+	db.State.RuntimeState = dockercompose.State{}.WithContainerState(v1alpha1.DockerContainerState{
+		Status: dockercompose.ContainerStatusRunning, Running: true, Health: dockercompose.ContainerHealthStarting,
+	})
+	migrate.State.RuntimeState = dockercompose.State{}.WithContainerState(v1alpha1.DockerContainerState{
+		Status: dockercompose.ContainerStatusRunning, Running: true,
+	})
+	f.assertNoTargetNextToBuild()
+	f.assertHold("app", store.HoldReasonWaitingForDep,
+		model.ManifestName("db").TargetID(), model.ManifestName("migrate").TargetID())
+
+	db.State.RuntimeState = db.State.DCRuntimeState().WithContainerState(v1alpha1.DockerContainerState{
+		Status: dockercompose.ContainerStatusRunning, Running: true, Health: dockercompose.ContainerHealthHealthy,
+	})
+	f.assertHold("app", store.HoldReasonWaitingForDep, model.ManifestName("migrate").TargetID())
+
+	migrate.State.RuntimeState = migrate.State.DCRuntimeState().WithContainerState(v1alpha1.DockerContainerState{
+		Status: dockercompose.ContainerStatusExited,
+	})
+	f.assertNextTargetToBuild("app")
+
+	_ = app
+}
+
+func TestK8sDependsOnDockerCompose(t *testing.T) {
+	f := newTestFixture(t)
+	f.st.Clusters[v1alpha1.ClusterNameDocker] = &v1alpha1.Cluster{
+		Status: v1alpha1.ClusterStatus{Arch: "amd64"},
+	}
+
+	k8s1 := f.upsertK8sManifest("k8s1", withResourceDeps("db"))
+	db := f.upsertDCManifest("db")
+
+	f.assertNextTargetToBuild("db")
+	f.assertHold("k8s1", store.HoldReasonWaitingForDep, model.ManifestName("db").TargetID())
+
+	// The container is up, but its healthcheck hasn't passed yet.
+	db.State.AddCompletedBuild(model.BuildRecord{StartTime: time.Now(), FinishTime: time.Now()})
+	db.State.RuntimeState = dockercompose.State{}.WithContainerState(v1alpha1.DockerContainerState{
+		Status: dockercompose.ContainerStatusRunning, Running: true, Health: dockercompose.ContainerHealthStarting,
+	})
+	f.assertNoTargetNextToBuild()
+	f.assertHold("k8s1", store.HoldReasonWaitingForDep, model.ManifestName("db").TargetID())
+
+	db.State.RuntimeState = db.State.DCRuntimeState().WithContainerState(v1alpha1.DockerContainerState{
+		Status: dockercompose.ContainerStatusRunning, Running: true, Health: dockercompose.ContainerHealthHealthy,
+	})
+	f.assertNextTargetToBuild("k8s1")
+
+	_ = k8s1
+}
+
+func TestDockerComposeDependsOnK8s(t *testing.T) {
+	f := newTestFixture(t)
+	f.st.Clusters[v1alpha1.ClusterNameDocker] = &v1alpha1.Cluster{
+		Status: v1alpha1.ClusterStatus{Arch: "amd64"},
+	}
+
+	app := f.upsertManifest(manifestbuilder.New(f, "app").
+		WithDockerCompose().
+		WithResourceDeps("k8s1").
+		Build())
+	k8s1 := f.upsertK8sManifest("k8s1")
+
+	f.assertNextTargetToBuild("k8s1")
+	f.assertHold("app", store.HoldReasonWaitingForDep, model.ManifestName("k8s1").TargetID())
+
+	// Deployed, but no pod is ready yet.
+	k8s1.State.AddCompletedBuild(model.BuildRecord{StartTime: time.Now(), FinishTime: time.Now()})
+	k8s1.State.RuntimeState = store.K8sRuntimeState{HasEverDeployedSuccessfully: true}
+	f.assertNoTargetNextToBuild()
+
+	k8s1.State.RuntimeState = store.K8sRuntimeState{
+		HasEverDeployedSuccessfully: true,
+		LastReadyOrSucceededTime:    time.Now(),
+	}
+	f.assertNextTargetToBuild("app")
+
+	_ = app
+}
+
+func TestHoldsTargetsOnTheirOwnCluster(t *testing.T) {
+	f := newTestFixture(t)
+
+	// The Kubernetes cluster is connected, but Docker isn't yet.
+	f.st.Clusters[v1alpha1.ClusterNameDocker] = &v1alpha1.Cluster{}
+
+	_ = f.upsertManifest(manifestbuilder.New(f, "app").WithDockerCompose().Build())
+	_ = f.upsertK8sManifest("k8s1")
+
+	// Check a few times, so that we don't depend on map order.
+	for i := 0; i < 10; i++ {
+		f.assertNextTargetToBuild("k8s1")
+		f.assertHoldOnRefs("app", store.HoldReasonCluster, v1alpha1.UIResourceStateWaitingOnRef{
+			Group:      "tilt.dev",
+			APIVersion: "v1alpha1",
+			Kind:       "Cluster",
+			Name:       v1alpha1.ClusterNameDocker,
+		})
+	}
+}
+
 func TestLocalDependsOnNonWorkloadK8s(t *testing.T) {
 	f := newTestFixture(t)
 
@@ -750,6 +869,17 @@ func (f *testFixture) upsertLocalManifest(name model.ManifestName, opts ...manif
 	return f.upsertManifest(b.WithLocalResource(fmt.Sprintf("exec-%s", name), nil).Build())
 }
 
+func (f *testFixture) upsertDCManifest(name model.ManifestName, deps ...v1alpha1.DockerComposeServiceDependency) *store.ManifestTarget {
+	var names []string
+	for _, dep := range deps {
+		names = append(names, dep.Service)
+	}
+	m := manifestbuilder.New(f, name).WithDockerCompose().WithResourceDeps(names...).Build()
+	dc := m.DockerComposeTarget()
+	dc.Spec.DependsOn = deps
+	return f.upsertManifest(m.WithDeployTarget(dc))
+}
+
 type manifestOption func(manifestbuilder.ManifestBuilder) manifestbuilder.ManifestBuilder
 
 func withResourceDeps(deps ...string) manifestOption {
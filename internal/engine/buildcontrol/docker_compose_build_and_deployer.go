@@ -160,6 +160,7 @@ func (bd *DockerComposeBuildAndDeployer) BuildAndDeploy(ctx context.Context, st
 		imageMapSet[nn] = im.DeepCopy()
 	}
 
+	imageCtx, endImageBuilds := StartImageBuild(ctx)
 	err = q.RunBuilds(func(target model.TargetSpec, depResults []store.ImageBuildResult) (store.ImageBuildResult, error) {
 		iTarget, ok := target.(model.ImageTarget)
 		if !ok {
@@ -167,9 +168,15 @@ func (bd *DockerComposeBuildAndDeployer) BuildAndDeploy(ctx context.Context, st
 		}
 
 		cluster := currentState[target.ID()].ClusterOrEmpty()
-		return bd.build(ctx, iTarget, cluster, imageMapSet, ps)
+		iTarget = withNoCache(iTarget, currentState[target.ID()])
+		return bd.build(imageCtx, iTarget, cluster, imageMapSet, ps)
 	})
 
+	// Don't deploy anything if a newer change superseded the image builds.
+	if endErr := endImageBuilds(); err == nil {
+		err = endErr
+	}
+
 	newResults := q.NewResults().ToBuildResultSet()
 	if err != nil {
 		return newResults, err
@@ -206,7 +213,7 @@ func (bd *DockerComposeBuildAndDeployer) build(
 	switch iTarget.BuildDetails.(type) {
 	case model.DockerBuild:
 		return bd.dr.ForceApply(ctx, iTarget, cluster, imageMaps, ps)
-	case model.CustomBuild:
+	case model.CustomBuild, model.KoBuild:
 		return bd.cr.ForceApply(ctx, iTarget, cluster, imageMaps, ps)
 	}
 	return store.ImageBuildResult{}, fmt.Errorf("invalid image spec")
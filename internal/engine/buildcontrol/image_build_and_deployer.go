@@ -121,6 +121,7 @@ func (ibd *ImageBuildAndDeployer) BuildAndDeploy(ctx context.Context, st store.R
 		imageMapSet[nn] = im.DeepCopy()
 	}
 
+	imageCtx, endImageBuilds := StartImageBuild(ctx)
 	err = q.RunBuilds(func(target model.TargetSpec, depResults []store.ImageBuildResult) (store.ImageBuildResult, error) {
 		iTarget, ok := target.(model.ImageTarget)
 		if !ok {
@@ -128,9 +129,15 @@ func (ibd *ImageBuildAndDeployer) BuildAndDeploy(ctx context.Context, st store.R
 		}
 
 		cluster := stateSet[target.ID()].ClusterOrEmpty()
-		return ibd.build(ctx, iTarget, cluster, imageMapSet, ps)
+		iTarget = withNoCache(iTarget, stateSet[target.ID()])
+		return ibd.build(imageCtx, iTarget, cluster, imageMapSet, ps)
 	})
 
+	// Don't deploy anything if a newer change superseded the image builds.
+	if endErr := endImageBuilds(); err == nil {
+		err = endErr
+	}
+
 	newResults := q.NewResults().ToBuildResultSet()
 	if err != nil {
 		return newResults, WrapDontFallBackError(err)
@@ -138,7 +145,7 @@ func (ibd *ImageBuildAndDeployer) BuildAndDeploy(ctx context.Context, st store.R
 
 	// (If we pass an empty list of refs here (as we will do if only deploying
 	// yaml), we just don't inject any image refs into the yaml, nbd.
-	k8sResult, err := ibd.deploy(ctx, st, ps, kTarget.ID(), kTarget.KubernetesApplySpec, kCluster, imageMapSet)
+	k8sResult, err := ibd.deploy(ctx, st, ps, kTarget.ID(), kTarget.KubernetesApplySpec, kCluster, imageMapSet, stateSet.FullBuildTriggered())
 	if err != nil {
 		return newResults, WrapDontFallBackError(err)
 	}
@@ -155,12 +162,22 @@ func (ibd *ImageBuildAndDeployer) build(
 	switch iTarget.BuildDetails.(type) {
 	case model.DockerBuild:
 		return ibd.dr.ForceApply(ctx, iTarget, cluster, imageMaps, ps)
-	case model.CustomBuild:
+	case model.CustomBuild, model.OCIArtifactBuild, model.KoBuild:
 		return ibd.cr.ForceApply(ctx, iTarget, cluster, imageMaps, ps)
 	}
 	return store.ImageBuildResult{}, fmt.Errorf("invalid image spec")
 }
 
+// If the user triggered a build without the cache, pass that on to the Docker build.
+func withNoCache(iTarget model.ImageTarget, state store.BuildState) model.ImageTarget {
+	db, ok := iTarget.BuildDetails.(model.DockerBuild)
+	if !ok || !state.NoCache {
+		return iTarget
+	}
+	db.NoCache = true
+	return iTarget.WithBuildDetails(db)
+}
+
 // Returns: the entities deployed and the namespace of the pod with the given image name/tag.
 func (ibd *ImageBuildAndDeployer) deploy(
 	ctx context.Context,
@@ -169,12 +186,13 @@ func (ibd *ImageBuildAndDeployer) deploy(
 	kTargetID model.TargetID,
 	spec v1alpha1.KubernetesApplySpec,
 	cluster *v1alpha1.Cluster,
-	imageMaps map[types.NamespacedName]*v1alpha1.ImageMap) (store.K8sBuildResult, error) {
+	imageMaps map[types.NamespacedName]*v1alpha1.ImageMap,
+	triggered bool) (store.K8sBuildResult, error) {
 	ps.StartPipelineStep(ctx, "Deploying")
 	defer ps.EndPipelineStep(ctx)
 
 	kTargetNN := types.NamespacedName{Name: kTargetID.Name.String()}
-	status := ibd.r.ForceApply(ctx, kTargetNN, spec, cluster, imageMaps)
+	status := ibd.r.ForceApply(ctx, kTargetNN, spec, cluster, imageMaps, triggered)
 	if status.Error != "" {
 		return store.K8sBuildResult{}, fmt.Errorf("%s", status.Error)
 	}
@@ -54,7 +54,7 @@ func TestDeployTwinImages(t *testing.T) {
 	}
 
 	id := manifest.ImageTargetAt(0).ID()
-	expectedImage := "gcr.io/some-project-162817/sancho:tilt-11cd0b38bc3ceb95"
+	expectedImage := "gcr.io/some-project-162817/sancho:tilt-11cd0b38bc3ceb95@" + docker.ExamplePushSHA1
 	image := store.ClusterImageRefFromBuildResult(result[id])
 	assert.Equal(t, expectedImage, image)
 	assert.Equalf(t, 2, strings.Count(f.k8s.Yaml, expectedImage),
@@ -77,6 +77,27 @@ func TestForceUpdate(t *testing.T) {
 	assert.Equal(t, 1, strings.Count(f.k8s.DeletedYaml, "Deployment"))
 }
 
+func TestForceUpdateNoCache(t *testing.T) {
+	f := newIBDFixture(t, clusterid.ProductGKE)
+
+	m := NewSanchoDockerBuildManifest(f)
+
+	iTargetID1 := m.ImageTargets[0].ID()
+	stateSet := store.BuildStateSet{
+		iTargetID1: store.BuildState{FullBuildTriggered: true, NoCache: true},
+	}
+	_, err := f.BuildAndDeploy(BuildTargets(m), stateSet)
+	require.NoError(t, err)
+	assert.True(t, f.docker.BuildOptions.NoCache)
+
+	stateSet = store.BuildStateSet{
+		iTargetID1: store.BuildState{FullBuildTriggered: true},
+	}
+	_, err = f.BuildAndDeploy(BuildTargets(m), stateSet)
+	require.NoError(t, err)
+	assert.False(t, f.docker.BuildOptions.NoCache)
+}
+
 func TestForceUpdateDoesNotDeleteNamespace(t *testing.T) {
 	f := newIBDFixture(t, clusterid.ProductGKE)
 
@@ -461,6 +482,37 @@ func TestDockerPushIfKINDAndClusterRef(t *testing.T) {
 	assert.NotContains(t, yaml, refs.LocalRef().String(), "LocalRef was NOT injected into applied YAML")
 }
 
+func TestDockerPushPinsDigest(t *testing.T) {
+	f := newIBDFixture(t, clusterid.ProductGKE)
+
+	manifest := NewSanchoDockerBuildManifest(f)
+	iTarg := manifest.ImageTargetAt(0)
+	result, err := f.BuildAndDeploy(BuildTargets(manifest), store.BuildStateSet{})
+	require.NoError(t, err)
+
+	status := result[iTarg.ID()].(store.ImageBuildResult).ImageMapStatus
+	assert.Equal(t, docker.ExamplePushSHA1, status.Digest)
+	assert.Equal(t, "gcr.io", status.Registry)
+	assert.Equal(t, "gcr.io/some-project-162817/sancho:tilt-11cd0b38bc3ceb95", status.ImageFromLocal,
+		"local ref isn't pinned")
+	assert.Contains(t, f.k8s.Yaml, "image: gcr.io/some-project-162817/sancho:tilt-11cd0b38bc3ceb95@"+docker.ExamplePushSHA1)
+}
+
+func TestDockerPushWithoutDigest(t *testing.T) {
+	f := newIBDFixture(t, clusterid.ProductGKE)
+	f.docker.PushOutput = `{"status":"The push refers to repository [gcr.io/some-project-162817/sancho]"}`
+
+	manifest := NewSanchoDockerBuildManifest(f)
+	iTarg := manifest.ImageTargetAt(0)
+	result, err := f.BuildAndDeploy(BuildTargets(manifest), store.BuildStateSet{})
+	require.NoError(t, err)
+
+	status := result[iTarg.ID()].(store.ImageBuildResult).ImageMapStatus
+	assert.Equal(t, "", status.Digest)
+	assert.Equal(t, "", status.Registry)
+	assert.Equal(t, "gcr.io/some-project-162817/sancho:tilt-11cd0b38bc3ceb95", status.ImageFromCluster)
+}
+
 func TestCustomBuildDisablePush(t *testing.T) {
 	f := newIBDFixture(t, clusterid.ProductKIND)
 	sha := digest.Digest("sha256:11cd0eb38bc3ceb958ffb2f9bd70be3fb317ce7d255c8a4c3f4af30e298aa1aab")
@@ -585,10 +637,10 @@ func TestDeployInjectImageEnvVar(t *testing.T) {
 
 	c := d.Spec.Template.Spec.Containers[0]
 	// container image always gets injected
-	assert.Equal(t, "gcr.io/some-project-162817/sancho:tilt-11cd0b38bc3ceb95", c.Image)
+	assert.Equal(t, "gcr.io/some-project-162817/sancho:tilt-11cd0b38bc3ceb95@"+docker.ExamplePushSHA1, c.Image)
 	expectedEnv := []corev1.EnvVar{
 		// sancho2 gets injected here because it sets match_in_env_vars in docker_build
-		{Name: "foo", Value: "gcr.io/some-project-162817/sancho2:tilt-11cd0b38bc3ceb95"},
+		{Name: "foo", Value: "gcr.io/some-project-162817/sancho2:tilt-11cd0b38bc3ceb95@" + docker.ExamplePushSHA1},
 		// sancho does not because it doesn't
 		{Name: "bar", Value: "gcr.io/some-project-162817/sancho"},
 	}
@@ -625,7 +677,7 @@ func TestDeployInjectsOverrideCommand(t *testing.T) {
 	c := d.Spec.Template.Spec.Containers[0]
 
 	// Make sure container ref injection worked as expected
-	assert.Equal(t, "gcr.io/some-project-162817/sancho:tilt-11cd0b38bc3ceb95", c.Image)
+	assert.Equal(t, "gcr.io/some-project-162817/sancho:tilt-11cd0b38bc3ceb95@"+docker.ExamplePushSHA1, c.Image)
 
 	assert.Equal(t, cmd.Argv, c.Command)
 	assert.Empty(t, c.Args)
@@ -0,0 +1,93 @@
+package buildcontrol
+
+import (
+	"context"
+	"sync"
+)
+
+// Tracks whether a build is building images.
+//
+// When a newer file change supersedes a build, we can only cancel it
+// while it's building images, because nothing has changed on the cluster yet.
+// Canceling a deploy or a live update midway could leave the resource
+// half-updated.
+//
+// Image builds run with their own child context, so that superseding
+// a build only cancels its image builds, never the rest of the build.
+type ImageBuildPhase struct {
+	mu         sync.Mutex
+	nextID     int
+	cancels    map[int]context.CancelFunc
+	superseded bool
+}
+
+func NewImageBuildPhase() *ImageBuildPhase {
+	return &ImageBuildPhase{
+		cancels: make(map[int]context.CancelFunc),
+	}
+}
+
+// Cancels the image builds in progress.
+//
+// Returns false without canceling anything if the build isn't building images.
+func (p *ImageBuildPhase) Supersede() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.superseded {
+		return true
+	}
+	if len(p.cancels) == 0 {
+		return false
+	}
+	p.superseded = true
+	for _, cancel := range p.cancels {
+		cancel()
+	}
+	return true
+}
+
+func (p *ImageBuildPhase) start(ctx context.Context) (context.Context, func() error) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.superseded {
+		cancel()
+		return ctx, ctx.Err
+	}
+
+	id := p.nextID
+	p.nextID++
+	p.cancels[id] = cancel
+
+	var once sync.Once
+	var err error
+	return ctx, func() error {
+		once.Do(func() {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			delete(p.cancels, id)
+			err = ctx.Err()
+		})
+		return err
+	}
+}
+
+type imageBuildPhaseKey struct{}
+
+func WithImageBuildPhase(ctx context.Context, p *ImageBuildPhase) context.Context {
+	return context.WithValue(ctx, imageBuildPhaseKey{}, p)
+}
+
+// Marks the build in the context as building images, until the returned func is called.
+//
+// Image builds should use the returned context, which is canceled if a newer
+// change supersedes the build. The returned func reports whether that happened,
+// so callers should check it before deploying anything.
+func StartImageBuild(ctx context.Context) (context.Context, func() error) {
+	p, ok := ctx.Value(imageBuildPhaseKey{}).(*ImageBuildPhase)
+	if !ok {
+		return ctx, func() error { return nil }
+	}
+	return p.start(ctx)
+}
@@ -0,0 +1,32 @@
+package buildcontrol
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSupersedeCancelsOnlyImageBuilds(t *testing.T) {
+	phase := NewImageBuildPhase()
+	ctx := WithImageBuildPhase(context.Background(), phase)
+
+	imageCtx, endImageBuild := StartImageBuild(ctx)
+	require.True(t, phase.Supersede())
+
+	assert.Equal(t, context.Canceled, imageCtx.Err())
+	assert.NoError(t, ctx.Err())
+	assert.Equal(t, context.Canceled, endImageBuild())
+}
+
+func TestSupersedeAfterImageBuilds(t *testing.T) {
+	phase := NewImageBuildPhase()
+	ctx := WithImageBuildPhase(context.Background(), phase)
+
+	imageCtx, endImageBuild := StartImageBuild(ctx)
+	require.NoError(t, endImageBuild())
+
+	assert.False(t, phase.Supersede())
+	assert.NoError(t, imageCtx.Err())
+}
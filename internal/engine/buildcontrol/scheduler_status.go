@@ -0,0 +1,172 @@
+package buildcontrol
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// A snapshot of the build scheduler's decisions.
+//
+// Intended for diagnosing "why isn't my resource building?".
+// Doesn't make any compatibility promises.
+type SchedulerStatus struct {
+	// How many builds can run at once, and how many more can start now.
+	MaxParallelUpdates  int
+	AvailableBuildSlots int
+
+	// The resources that are building now.
+	Building []SchedulerBuilding
+
+	// The resource the scheduler will build next, if any.
+	Next model.ManifestName `json:",omitempty"`
+
+	// The resources that have changes waiting to be built, in manifest order.
+	Pending []SchedulerPending
+}
+
+type SchedulerBuilding struct {
+	Name      model.ManifestName
+	StartTime time.Time
+	Reason    string
+}
+
+type SchedulerPending struct {
+	Name model.ManifestName
+
+	// Why the resource needs a build.
+	Reason string
+
+	// The time of the earliest change that hasn't been built yet.
+	// Zero if there are no changes (e.g., the resource has never been built).
+	PendingSince time.Time
+
+	// The hold that's stopping the resource from building, if any.
+	Hold   store.HoldReason `json:",omitempty"`
+	HoldOn []string         `json:",omitempty"`
+
+	// A human-readable explanation of why the resource isn't building yet.
+	Explanation string
+}
+
+// Explains the scheduler's decisions for the current state.
+//
+// Uses the same algorithm as NextTargetToBuild.
+func NewSchedulerStatus(state store.EngineState) SchedulerStatus {
+	next, holds := NextTargetToBuild(state)
+	result := SchedulerStatus{
+		MaxParallelUpdates:  state.UpdateSettings.MaxParallelUpdates(),
+		AvailableBuildSlots: state.AvailableBuildSlots(),
+		Building:            []SchedulerBuilding{},
+		Pending:             []SchedulerPending{},
+	}
+	if next != nil {
+		result.Next = next.Manifest.Name
+	}
+
+	needsBuild := make(map[model.ManifestName]bool)
+	for _, mt := range FindTargetsNeedingAnyBuild(state) {
+		needsBuild[mt.Manifest.Name] = true
+	}
+
+	for _, mt := range state.Targets() {
+		mn := mt.Manifest.Name
+		ms := mt.State
+		if ms.IsBuilding() {
+			build := ms.EarliestCurrentBuild()
+			result.Building = append(result.Building, SchedulerBuilding{
+				Name:      mn,
+				StartTime: build.StartTime,
+				Reason:    build.Reason.String(),
+			})
+			continue
+		}
+
+		if ms.DisableState == v1alpha1.DisableStateDisabled {
+			continue
+		}
+
+		hasPendingChanges, pendingSince := ms.HasPendingChanges()
+		neverBuilt := !ms.StartedFirstBuild()
+		if !needsBuild[mn] && !hasPendingChanges && !neverBuilt {
+			continue
+		}
+
+		pending := SchedulerPending{
+			Name:   mn,
+			Reason: mt.NextBuildReason().String(),
+		}
+		if hasPendingChanges {
+			pending.PendingSince = pendingSince
+		}
+
+		hold := holds[mn]
+		switch {
+		case hold.Reason != store.HoldReasonNone:
+			pending.Hold = hold.Reason
+			for _, id := range hold.HoldOn {
+				pending.HoldOn = append(pending.HoldOn, id.String())
+			}
+			for _, ref := range hold.OnRefs {
+				pending.HoldOn = append(pending.HoldOn, fmt.Sprintf("%s:%s", strings.ToLower(ref.Kind), ref.Name))
+			}
+			pending.Explanation = holdExplanation(hold)
+		case !needsBuild[mn]:
+			pending.Explanation = "Waiting for a manual trigger"
+		case result.AvailableBuildSlots < 1:
+			pending.Explanation = fmt.Sprintf("Waiting for a free build slot (%d of %d in use)",
+				len(state.CurrentBuildSet), result.MaxParallelUpdates)
+		case mn == result.Next:
+			pending.Explanation = "Building next"
+		case result.Next != "":
+			pending.Explanation = fmt.Sprintf("Queued behind %s", result.Next)
+		default:
+			pending.Explanation = "Waiting for the scheduler"
+		}
+		result.Pending = append(result.Pending, pending)
+	}
+	return result
+}
+
+func holdExplanation(hold store.Hold) string {
+	on := make([]string, 0, len(hold.HoldOn)+len(hold.OnRefs))
+	for _, id := range hold.HoldOn {
+		on = append(on, id.Name.String())
+	}
+	for _, ref := range hold.OnRefs {
+		on = append(on, ref.Name)
+	}
+	onList := strings.Join(on, ", ")
+
+	switch hold.Reason {
+	case store.HoldReasonTiltfileReload:
+		return "Waiting for the Tiltfile to finish loading"
+	case store.HoldReasonWaitingForUnparallelizableTarget:
+		return fmt.Sprintf("Waiting for %s, which can't run in parallel with other builds", onList)
+	case store.HoldReasonIsUnparallelizableTarget:
+		return "Can't run in parallel with other builds. Waiting for them to finish"
+	case store.HoldReasonWaitingForUncategorized:
+		return "Waiting for uncategorized YAML to deploy"
+	case store.HoldReasonBuildingComponent:
+		if onList == "" {
+			return "Waiting for a build that shares an image to finish"
+		}
+		return fmt.Sprintf("Waiting for a build of a shared image (%s) to finish", onList)
+	case store.HoldReasonWaitingForDep:
+		return fmt.Sprintf("Waiting for resource dependencies: %s", onList)
+	case store.HoldReasonWaitingForDeploy:
+		return "Waiting for the deployed container to start, so that it can live update"
+	case store.HoldReasonReconciling:
+		if onList == "" {
+			return "Waiting for the live update reconciler to handle the change"
+		}
+		return fmt.Sprintf("Waiting for the live update reconciler to handle the change (%s)", onList)
+	case store.HoldReasonCluster:
+		return fmt.Sprintf("Waiting for the connection to cluster %s", onList)
+	}
+	return string(hold.Reason)
+}
@@ -0,0 +1,67 @@
+package buildcontrol
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/internal/testutils/manifestbuilder"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestSchedulerStatus(t *testing.T) {
+	f := newTestFixture(t)
+
+	f.upsertK8sManifest("k8s1", withResourceDeps("local1"))
+	f.upsertK8sManifest("k8s2")
+	f.upsertLocalManifest("local1")
+	f.upsertLocalManifest("local2", func(m manifestbuilder.ManifestBuilder) manifestbuilder.ManifestBuilder {
+		return m.WithTriggerMode(model.TriggerModeManualWithAutoInit)
+	})
+	f.upsertLocalManifest("manual", func(m manifestbuilder.ManifestBuilder) manifestbuilder.ManifestBuilder {
+		return m.WithTriggerMode(model.TriggerModeManual)
+	})
+
+	status := NewSchedulerStatus(*f.st)
+	assert.Equal(t, model.ManifestName("local1"), status.Next)
+	assert.Empty(t, status.Building)
+
+	pending := make(map[model.ManifestName]SchedulerPending)
+	for _, p := range status.Pending {
+		pending[p.Name] = p
+	}
+	require.Len(t, pending, 5)
+
+	assert.Equal(t, "Building next", pending["local1"].Explanation)
+	assert.Equal(t, "Queued behind local1", pending["k8s2"].Explanation)
+	assert.Equal(t, "Queued behind local1", pending["local2"].Explanation)
+	assert.Equal(t, "Waiting for a manual trigger", pending["manual"].Explanation)
+	assert.Equal(t, store.HoldReasonWaitingForDep, pending["k8s1"].Hold)
+	assert.Equal(t, []string{"manifest:local1"}, pending["k8s1"].HoldOn)
+	assert.Equal(t, "Waiting for resource dependencies: local1", pending["k8s1"].Explanation)
+}
+
+func TestSchedulerStatusBuilding(t *testing.T) {
+	f := newTestFixture(t)
+
+	local1 := f.upsertLocalManifest("local1")
+	f.upsertK8sManifest("k8s1")
+
+	start := time.Now()
+	local1.State.CurrentBuilds["buildcontrol"] = model.BuildRecord{
+		StartTime: start,
+		Reason:    model.BuildReasonFlagInit,
+	}
+	f.st.CurrentBuildSet["local1"] = true
+
+	status := NewSchedulerStatus(*f.st)
+	assert.Equal(t, model.ManifestName(""), status.Next)
+	assert.Equal(t, []SchedulerBuilding{{Name: "local1", StartTime: start, Reason: "Initial Build"}}, status.Building)
+	require.Len(t, status.Pending, 1)
+	assert.Equal(t, store.HoldReasonWaitingForUnparallelizableTarget, status.Pending[0].Hold)
+	assert.Equal(t, "Waiting for local1, which can't run in parallel with other builds", status.Pending[0].Explanation)
+	assert.Equal(t, status.MaxParallelUpdates-1, status.AvailableBuildSlots)
+}
@@ -39,6 +39,7 @@ var BaseWireSet = wire.NewSet(
 
 	v1alpha1.NewScheme,
 	k8s.ProvideMinikubeClient,
+	build.NewBuildCache,
 	build.NewDockerBuilder,
 	build.NewCustomBuilder,
 	wire.Bind(new(build.DockerKubeConnection), new(*build.DockerBuilder)),
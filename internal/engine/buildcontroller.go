@@ -31,6 +31,11 @@ type BuildController struct {
 	// CancelFuncs for in-progress builds
 	mu           sync.Mutex
 	stopBuildFns map[model.ManifestName]context.CancelFunc
+
+	// Whether in-progress builds are building images, and the file changes
+	// that superseded them
+	imageBuildPhases map[model.ManifestName]*buildcontrol.ImageBuildPhase
+	supersededBy     map[model.ManifestName]string
 }
 
 type buildEntry struct {
@@ -48,8 +53,10 @@ func (e buildEntry) BuildReason() model.BuildReason { return e.buildReason }
 
 func NewBuildController(b buildcontrol.BuildAndDeployer) *BuildController {
 	return &BuildController{
-		b:            b,
-		stopBuildFns: make(map[model.ManifestName]context.CancelFunc),
+		b:                b,
+		stopBuildFns:     make(map[model.ManifestName]context.CancelFunc),
+		imageBuildPhases: make(map[model.ManifestName]*buildcontrol.ImageBuildPhase),
+		supersededBy:     make(map[model.ManifestName]string),
 	}
 }
 
@@ -138,7 +145,9 @@ func (c *BuildController) OnChange(ctx context.Context, st store.RStore, summary
 		})
 
 		result, err := c.buildAndDeploy(ctx, st, entry)
-		if ctx.Err() == context.Canceled {
+		if file, ok := c.supersedingFile(entry.name); ok && err != nil {
+			err = buildcontrols.SupersededError{File: file}
+		} else if ctx.Err() == context.Canceled {
 			err = errors.New("build canceled")
 		}
 		st.Dispatch(buildcontrols.NewBuildCompleteAction(entry.name, BuildControlSource, entry.spanID, result, err))
@@ -161,6 +170,9 @@ func (c *BuildController) buildAndDeploy(ctx context.Context, st store.RStore, e
 // cancel any in-progress builds associated with canceled builds and disabled UIResources
 // when builds are fully represented by api objects, cancellation should probably
 // be tied to those rather than the UIResource
+//
+// also cancels image builds that a newer file change has superseded, so that
+// we don't finish a stale build before starting the next one
 func (c *BuildController) cleanUpCanceledBuilds(st store.RStore) {
 	state := st.RLockState()
 	defer st.RUnlockState()
@@ -177,18 +189,85 @@ func (c *BuildController) cleanUpCanceledBuilds(st store.RStore) {
 		}
 		if disabled || canceled {
 			c.cleanupBuildContext(ms.Name)
+			continue
+		}
+
+		mt, ok := state.ManifestTargets[ms.Name]
+		if !ok {
+			continue
+		}
+		if file, ok := supersedingFileChange(mt); ok {
+			c.supersedeBuild(ms.Name, file)
 		}
 	}
 }
 
+// Returns the earliest change to one of the manifest's images since
+// its current build started.
+func supersedingFileChange(mt *store.ManifestTarget) (string, bool) {
+	if !mt.Manifest.TriggerMode.AutoOnChange() {
+		return "", false
+	}
+
+	build, ok := mt.State.CurrentBuilds[BuildControlSource]
+	if !ok {
+		return "", false
+	}
+
+	file := ""
+	var earliest time.Time
+	for _, iTarget := range mt.Manifest.ImageTargets {
+		status, ok := mt.State.BuildStatuses[iTarget.ID()]
+		if !ok {
+			continue
+		}
+		for f, t := range status.PendingFileChanges {
+			if !t.After(build.StartTime) {
+				continue
+			}
+			if file == "" || t.Before(earliest) || (t.Equal(earliest) && f < file) {
+				file = f
+				earliest = t
+			}
+		}
+	}
+	return file, file != ""
+}
+
+// Cancels the build's image builds, if it's building images.
+func (c *BuildController) supersedeBuild(mn model.ManifestName, file string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.supersededBy[mn]; ok {
+		return
+	}
+	phase, ok := c.imageBuildPhases[mn]
+	if !ok || !phase.Supersede() {
+		return
+	}
+	c.supersededBy[mn] = file
+}
+
+func (c *BuildController) supersedingFile(mn model.ManifestName) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	file, ok := c.supersededBy[mn]
+	return file, ok
+}
+
 func (c *BuildController) buildContext(ctx context.Context, entry buildEntry, st store.RStore) context.Context {
 	// Send the logs to both the EngineState and the normal log stream.
 	ctx = store.WithManifestLogHandler(ctx, st, entry.name, entry.spanID)
 
+	phase := buildcontrol.NewImageBuildPhase()
+	ctx = buildcontrol.WithImageBuildPhase(ctx, phase)
+
 	ctx, cancel := context.WithCancel(ctx)
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	c.stopBuildFns[entry.name] = cancel
+	c.imageBuildPhases[entry.name] = phase
 	return ctx
 }
 
@@ -199,6 +278,8 @@ func (c *BuildController) cleanupBuildContext(mn model.ManifestName) {
 		cancel()
 		delete(c.stopBuildFns, mn)
 	}
+	delete(c.imageBuildPhases, mn)
+	delete(c.supersededBy, mn)
 }
 
 func SpanIDForBuildLog(buildCount int) logstore.SpanID {
@@ -235,8 +316,9 @@ func buildStateSet(ctx context.Context, manifest model.Manifest,
 
 	isFullBuildTrigger := reason.HasTrigger() && !buildcontrol.IsLiveUpdateEligibleTrigger(manifest, reason)
 	if isFullBuildTrigger {
+		noCache := reason.Has(model.BuildReasonFlagNoCache)
 		for k, v := range result {
-			result[k] = v.WithFullBuildTriggered(true)
+			result[k] = v.WithFullBuildTriggered(true).WithNoCache(noCache)
 		}
 	}
 
@@ -15,7 +15,6 @@ import (
 
 	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/internal/controllers/apis/uibutton"
-	"github.com/tilt-dev/tilt/internal/hud/server"
 	"github.com/tilt-dev/tilt/internal/k8s"
 	"github.com/tilt-dev/tilt/internal/k8s/testyaml"
 	"github.com/tilt-dev/tilt/internal/store"
@@ -72,7 +71,7 @@ func TestBuildControllerManualTriggerBuildReasonInit(t *testing.T) {
 
 			// make sure there's a first build
 			if !manifest.TriggerMode.AutoInitial() {
-				f.store.Dispatch(server.AppendToTriggerQueueAction{Name: mName})
+				f.store.Dispatch(store.AppendToTriggerQueueAction{Name: mName})
 			}
 
 			f.nextCallComplete()
@@ -169,7 +168,7 @@ func TestBuildControllerImageBuildTrigger(t *testing.T) {
 				f.assertNoCall("even tho there are pending changes, manual manifest shouldn't build w/o explicit trigger")
 			}
 
-			f.store.Dispatch(server.AppendToTriggerQueueAction{Name: mName})
+			f.store.Dispatch(store.AppendToTriggerQueueAction{Name: mName})
 			call := f.nextCallComplete()
 			state := call.oneImageState()
 			assert.Equal(t, expectedFiles, state.FilesChanged())
@@ -187,6 +186,36 @@ func TestBuildControllerImageBuildTrigger(t *testing.T) {
 	}
 }
 
+func TestBuildControllerNoCacheTrigger(t *testing.T) {
+	f := newTestFixture(t)
+	mName := model.ManifestName("foobar")
+
+	manifest := f.simpleManifestWithTriggerMode(mName, model.TriggerModeManual)
+	f.Start([]model.Manifest{manifest})
+
+	// Even with pending changes, a no-cache trigger skips live update.
+	f.fsWatcher.Events <- watch.NewFileEvent(f.JoinPath("main.go"))
+	f.WaitUntil("pending change appears", func(st store.EngineState) bool {
+		return len(st.BuildStatus(manifest.ImageTargetAt(0).ID()).PendingFileChanges) >= 1
+	})
+
+	f.store.Dispatch(store.AppendToTriggerQueueAction{
+		Name:   mName,
+		Reason: model.BuildReasonFlagTriggerCLI.With(model.BuildReasonFlagNoCache),
+	})
+	call := f.nextCallComplete()
+	state := call.oneImageState()
+	assert.True(t, state.FullBuildTriggered)
+	assert.True(t, state.NoCache)
+
+	// The next trigger uses the cache again.
+	f.store.Dispatch(store.AppendToTriggerQueueAction{Name: mName, Reason: model.BuildReasonFlagTriggerCLI})
+	call = f.nextCallComplete()
+	state = call.oneImageState()
+	assert.True(t, state.FullBuildTriggered)
+	assert.False(t, state.NoCache)
+}
+
 func TestBuildQueueOrdering(t *testing.T) {
 	f := newTestFixture(t)
 
@@ -222,11 +251,11 @@ func TestBuildQueueOrdering(t *testing.T) {
 	})
 	f.assertNoCall("even tho there are pending changes, manual manifest shouldn't build w/o explicit trigger")
 
-	f.store.Dispatch(server.AppendToTriggerQueueAction{Name: "manifest1"})
-	f.store.Dispatch(server.AppendToTriggerQueueAction{Name: "manifest2"})
+	f.store.Dispatch(store.AppendToTriggerQueueAction{Name: "manifest1"})
+	f.store.Dispatch(store.AppendToTriggerQueueAction{Name: "manifest2"})
 	time.Sleep(10 * time.Millisecond)
-	f.store.Dispatch(server.AppendToTriggerQueueAction{Name: "manifest3"})
-	f.store.Dispatch(server.AppendToTriggerQueueAction{Name: "manifest4"})
+	f.store.Dispatch(store.AppendToTriggerQueueAction{Name: "manifest3"})
+	f.store.Dispatch(store.AppendToTriggerQueueAction{Name: "manifest4"})
 
 	for i := range manifests {
 		expName := fmt.Sprintf("manifest%d", i+1)
@@ -278,13 +307,13 @@ func TestBuildQueueAndAutobuildOrdering(t *testing.T) {
 	})
 	f.assertNoCall("even tho there are pending changes, manual manifest shouldn't build w/o explicit trigger")
 
-	f.store.Dispatch(server.AppendToTriggerQueueAction{Name: "manifest1"})
-	f.store.Dispatch(server.AppendToTriggerQueueAction{Name: "manifest2"})
+	f.store.Dispatch(store.AppendToTriggerQueueAction{Name: "manifest1"})
+	f.store.Dispatch(store.AppendToTriggerQueueAction{Name: "manifest2"})
 	// make our one auto-trigger manifest build - should be evaluated LAST, after
 	// all the manual manifests waiting in the queue
 	f.fsWatcher.Events <- watch.NewFileEvent(f.JoinPath("dirAuto/main.go"))
-	f.store.Dispatch(server.AppendToTriggerQueueAction{Name: "manifest3"})
-	f.store.Dispatch(server.AppendToTriggerQueueAction{Name: "manifest4"})
+	f.store.Dispatch(store.AppendToTriggerQueueAction{Name: "manifest3"})
+	f.store.Dispatch(store.AppendToTriggerQueueAction{Name: "manifest4"})
 
 	for i := range manifests {
 		call := f.nextCall()
@@ -904,7 +933,7 @@ func TestManifestsWithCommonAncestorAndTrigger(t *testing.T) {
 	call = f.nextCall("m2 build1")
 	assert.Equal(t, m2.K8sTarget(), call.k8s())
 
-	f.store.Dispatch(server.AppendToTriggerQueueAction{Name: m1.Name})
+	f.store.Dispatch(store.AppendToTriggerQueueAction{Name: m1.Name})
 	f.waitForCompletedBuildCount(3)
 
 	// Make sure that only one build was triggered.
@@ -1008,6 +1037,74 @@ local_resource('local', 'sleep 10000')
 	require.NoError(t, err)
 }
 
+func TestNewerChangeSupersedesImageBuild(t *testing.T) {
+	f := newTestFixture(t)
+	f.b.completeBuildsManually = true
+	f.b.buildImagesUntilCompleted = true
+
+	manifest := f.simpleManifestWithTriggerMode("fe", model.TriggerModeAuto)
+	f.Start([]model.Manifest{manifest})
+	f.completeBuildForManifest(manifest)
+	f.nextCall("initial build")
+	f.waitForCompletedBuildCount(1)
+
+	f.fsWatcher.Events <- watch.NewFileEvent(f.JoinPath("main.go"))
+	f.waitUntilManifestBuilding("fe")
+	f.fsWatcher.Events <- watch.NewFileEvent(f.JoinPath("other.go"))
+
+	call := f.nextCall("superseded build")
+	assert.Equal(t, []string{f.JoinPath("main.go")}, call.oneImageState().FilesChanged())
+	f.waitForCompletedBuildCount(2)
+
+	f.completeBuildForManifest(manifest)
+	call = f.nextCall("build with both changes")
+	assert.Equal(t, []string{f.JoinPath("main.go"), f.JoinPath("other.go")}, call.oneImageState().FilesChanged())
+	f.waitForCompletedBuildCount(3)
+
+	f.withManifestState("fe", func(ms store.ManifestState) {
+		require.NoError(t, ms.BuildHistory[0].Error)
+		require.EqualError(t, ms.BuildHistory[1].Error,
+			fmt.Sprintf("build canceled: superseded by a newer change to %s", f.JoinPath("other.go")))
+	})
+
+	err := f.Stop()
+	require.NoError(t, err)
+}
+
+func TestNewerChangeDoesNotCancelDeploy(t *testing.T) {
+	f := newTestFixture(t)
+	f.b.completeBuildsManually = true
+
+	manifest := f.simpleManifestWithTriggerMode("fe", model.TriggerModeAuto)
+	f.Start([]model.Manifest{manifest})
+	f.completeBuildForManifest(manifest)
+	f.nextCall("initial build")
+	f.waitForCompletedBuildCount(1)
+
+	f.fsWatcher.Events <- watch.NewFileEvent(f.JoinPath("main.go"))
+	f.waitUntilManifestBuilding("fe")
+	f.fsWatcher.Events <- watch.NewFileEvent(f.JoinPath("other.go"))
+	f.WaitUntil("pending change appears", func(st store.EngineState) bool {
+		return len(st.BuildStatus(manifest.ImageTargetAt(0).ID()).PendingFileChanges) >= 2
+	})
+	f.assertNoCall("build should not be canceled outside of image builds")
+
+	f.completeBuildForManifest(manifest)
+	f.nextCall("build with first change")
+	f.waitForCompletedBuildCount(2)
+
+	f.withManifestState("fe", func(ms store.ManifestState) {
+		require.NoError(t, ms.LastBuild().Error)
+	})
+
+	f.completeBuildForManifest(manifest)
+	call := f.nextCall("build with second change")
+	assert.Equal(t, []string{f.JoinPath("other.go")}, call.oneImageState().FilesChanged())
+
+	err := f.Stop()
+	require.NoError(t, err)
+}
+
 func TestBuildControllerK8sFileDependencies(t *testing.T) {
 	f := newTestFixture(t)
 
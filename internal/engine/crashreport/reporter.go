@@ -0,0 +1,151 @@
+package crashreport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/internal/xdg"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// Crash reports go in the state dir,
+// e.g., ~/.local/state/tilt-dev/crashes
+const crashesDir = "crashes"
+
+// Matches things that look like credentials (e.g., "token=abc123" or
+// "password: hunter2") in panic messages, which might include
+// arbitrary user data.
+var credentialRE = regexp.MustCompile(`(?i)\b((?:api[_-]?)?(?:token|password|passwd|secret|key)s?)(\s*[=:]\s*)("[^"]*"|\S+)`)
+
+// A crash bundle that the user can attach to a bug report.
+type Bundle struct {
+	Component     string    `json:"component"`
+	Message       string    `json:"message"`
+	Time          time.Time `json:"time"`
+	Count         int       `json:"count"`
+	Stack         string    `json:"stack"`
+	RecentActions []string  `json:"recentActions"`
+	Versions      Versions  `json:"versions"`
+}
+
+type Versions struct {
+	Tilt      string `json:"tilt"`
+	CommitSHA string `json:"commitSHA,omitempty"`
+	Go        string `json:"go"`
+	OS        string `json:"os"`
+	Arch      string `json:"arch"`
+}
+
+// Writes a crash bundle for each crash that Tilt recovered from.
+type Reporter struct {
+	base xdg.Base
+
+	// Crashes that we've already written, by component and time.
+	written map[crashKey]bool
+}
+
+type crashKey struct {
+	component string
+	time      time.Time
+}
+
+var _ store.Subscriber = &Reporter{}
+
+func NewReporter(base xdg.Base) *Reporter {
+	return &Reporter{
+		base:    base,
+		written: make(map[crashKey]bool),
+	}
+}
+
+func (r *Reporter) OnChange(ctx context.Context, st store.RStore, summary store.ChangeSummary) error {
+	if summary.IsLogOnly() {
+		return nil
+	}
+
+	var bundles []Bundle
+	state := st.RLockState()
+	for _, c := range state.Crashes {
+		key := crashKey{component: c.Component, time: c.Time}
+		if r.written[key] {
+			continue
+		}
+		r.written[key] = true
+		bundles = append(bundles, NewBundle(c, state.TiltBuildInfo, state.Secrets))
+	}
+	st.RUnlockState()
+
+	for _, b := range bundles {
+		path, err := r.write(b)
+		if err != nil {
+			logger.Get(ctx).Infof("Error writing crash report: %v", err)
+			continue
+		}
+		logger.Get(ctx).Infof("Crash report for %s written to %s", b.Component, path)
+	}
+	return nil
+}
+
+func (r *Reporter) write(b Bundle) (string, error) {
+	contents, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	name := fmt.Sprintf("crash-%s-%s.json",
+		b.Time.UTC().Format("20060102-150405.000"), sanitizeFileName(b.Component))
+	path, err := r.base.StateFile(filepath.Join(crashesDir, name))
+	if err != nil {
+		return "", err
+	}
+
+	err = os.WriteFile(path, contents, 0600)
+	if err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// Builds a crash bundle, redacting secrets.
+func NewBundle(c store.Crash, build model.TiltBuild, secrets model.SecretSet) Bundle {
+	return Bundle{
+		Component:     c.Component,
+		Message:       redact(c.Message, secrets),
+		Time:          c.Time,
+		Count:         c.Count,
+		Stack:         redact(c.Stack, secrets),
+		RecentActions: append([]string{}, c.RecentActions...),
+		Versions: Versions{
+			Tilt:      build.Version,
+			CommitSHA: build.CommitSHA,
+			Go:        runtime.Version(),
+			OS:        runtime.GOOS,
+			Arch:      runtime.GOARCH,
+		},
+	}
+}
+
+func redact(text string, secrets model.SecretSet) string {
+	text = credentialRE.ReplaceAllString(text, "$1$2[redacted]")
+	return string(secrets.Scrub([]byte(text)))
+}
+
+// e.g., "engine/uiresource.Subscriber" -> "engine-uiresource.Subscriber"
+func sanitizeFileName(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '_':
+			return r
+		}
+		return '-'
+	}, s)
+}
@@ -0,0 +1,103 @@
+package crashreport
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/internal/testutils"
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+	"github.com/tilt-dev/tilt/internal/xdg"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestWriteReport(t *testing.T) {
+	f := newFixture(t)
+
+	f.st.WithState(func(state *store.EngineState) {
+		state.TiltBuildInfo = model.TiltBuild{Version: "0.30.0", CommitSHA: "abc123"}
+		state.Secrets.AddSecret("my-secret", "password", []byte("hunter22"))
+		state.Crashes = []store.Crash{
+			{
+				Component:     "engine/uiresource.Subscriber",
+				Message:       "bad password hunter22",
+				Stack:         "goroutine 1 [running]:\nmain.go:10 token=abcdef",
+				Time:          time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC),
+				Count:         1,
+				RecentActions: []string{"store.ErrorAction"},
+			},
+		}
+	})
+
+	f.onChange()
+	paths := f.reports()
+	require.Len(t, paths, 1)
+	assert.Equal(t, "crash-20220102-030405.000-engine-uiresource.Subscriber.json", filepath.Base(paths[0]))
+
+	contents, err := os.ReadFile(paths[0])
+	require.NoError(t, err)
+
+	var b Bundle
+	require.NoError(t, json.Unmarshal(contents, &b))
+	assert.Equal(t, "engine/uiresource.Subscriber", b.Component)
+	assert.Equal(t, "bad password [redacted secret my-secret:password]", b.Message)
+	assert.Equal(t, "goroutine 1 [running]:\nmain.go:10 token=[redacted]", b.Stack)
+	assert.Equal(t, []string{"store.ErrorAction"}, b.RecentActions)
+	assert.Equal(t, "0.30.0", b.Versions.Tilt)
+	assert.Equal(t, "abc123", b.Versions.CommitSHA)
+	assert.NotEmpty(t, b.Versions.Go)
+	assert.NotContains(t, string(contents), "hunter22")
+	assert.NotContains(t, string(contents), "abcdef")
+
+	// Repeated crashes don't write a new report.
+	f.st.WithState(func(state *store.EngineState) {
+		state.Crashes[0].Count++
+	})
+	f.onChange()
+	assert.Len(t, f.reports(), 1)
+}
+
+func TestRedact(t *testing.T) {
+	secrets := model.SecretSet{}
+	assert.Equal(t, "api_key: [redacted] user=bob", redact("api_key: xyz user=bob", secrets))
+	assert.Equal(t, `PASSWORD=[redacted] ok`, redact(`PASSWORD="a b c" ok`, secrets))
+	assert.Equal(t, "nothing to see", redact("nothing to see", secrets))
+}
+
+type fixture struct {
+	*tempdir.TempDirFixture
+	ctx context.Context
+	st  *store.TestingStore
+	r   *Reporter
+}
+
+func newFixture(t *testing.T) *fixture {
+	f := tempdir.NewTempDirFixture(t)
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	return &fixture{
+		TempDirFixture: f,
+		ctx:            ctx,
+		st:             store.NewTestingStore(),
+		r:              NewReporter(xdg.FakeBase{Dir: f.Path()}),
+	}
+}
+
+func (f *fixture) onChange() {
+	f.T().Helper()
+	err := f.r.OnChange(f.ctx, f.st, store.LegacyChangeSummary())
+	require.NoError(f.T(), err)
+}
+
+func (f *fixture) reports() []string {
+	f.T().Helper()
+	paths, err := filepath.Glob(filepath.Join(f.Path(), "*", crashesDir, "*.json"))
+	require.NoError(f.T(), err)
+	return paths
+}
@@ -11,6 +11,8 @@ import (
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/filters"
+	ktypes "k8s.io/apimachinery/pkg/types"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/tilt-dev/tilt/internal/container"
 
@@ -21,24 +23,58 @@ import (
 	"github.com/tilt-dev/tilt/internal/docker"
 	"github.com/tilt-dev/tilt/internal/sliceutils"
 	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/apis"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
 	"github.com/tilt-dev/tilt/pkg/logger"
 )
 
+// Looks up the Docker client of a Cluster with a Docker connection.
+type DockerClientProvider interface {
+	GetComposeDockerClient(key ktypes.NamespacedName) (docker.Client, error)
+}
+
 type DockerPruner struct {
-	dCli docker.Client
+	dCli       docker.Client
+	ctrlClient ctrlclient.Client
+	clients    DockerClientProvider
 
 	disabledForTesting bool
 	disabledOnSetup    bool
 
+	// When we last pruned each Cluster, by name.
+	schedules map[string]pruneSchedule
+}
+
+type pruneSchedule struct {
 	lastPruneBuildCount int
 	lastPruneTime       time.Time
 }
 
+// A Cluster whose container runtime we prune.
+type pruneCluster struct {
+	name string
+
+	// Whether the Cluster has its own Docker connection (like the one that
+	// Docker Compose deploys to). Otherwise, it's a Kubernetes cluster, and
+	// we prune the daemon that Tilt builds its images on.
+	docker bool
+}
+
 var _ store.Subscriber = &DockerPruner{}
 var _ store.SetUpper = &DockerPruner{}
 
-func NewDockerPruner(dCli docker.Client) *DockerPruner {
-	return &DockerPruner{dCli: dCli}
+// ctrlClient is used to record each prune on its Cluster.
+// If nil, prunes aren't recorded.
+//
+// clients looks up the Docker connection of each Cluster.
+// If nil, we use dCli for every Cluster.
+func NewDockerPruner(dCli docker.Client, ctrlClient ctrlclient.Client, clients DockerClientProvider) *DockerPruner {
+	return &DockerPruner{
+		dCli:       dCli,
+		ctrlClient: ctrlClient,
+		clients:    clients,
+		schedules:  make(map[string]pruneSchedule),
+	}
 }
 
 func (dp *DockerPruner) DisabledForTesting(disabled bool) {
@@ -66,52 +102,43 @@ func (dp *DockerPruner) SetUp(ctx context.Context, _ store.RStore) error {
 // OnChange determines if any Tilt-built Docker images should be pruned based on settings and invokes the pruning
 // process if necessary.
 //
+// Each Cluster is pruned on its own schedule, with its own settings.
+//
 // Care should be taken when modifying this method to not introduce expensive operations unless necessary, as this
 // is invoked for EVERY store action change batch. Because of this, the store (un)locking is done somewhat manually,
 // so care must be taken to avoid locking issues.
 func (dp *DockerPruner) OnChange(ctx context.Context, st store.RStore, summary store.ChangeSummary) error {
-	if dp.disabledForTesting || dp.disabledOnSetup || summary.IsLogOnly() {
+	if dp.disabledForTesting || summary.IsLogOnly() {
 		return nil
 	}
 
 	state := st.RLockState()
 	settings := state.DockerPruneSettings
 	// Exit early if possible if any of the following is true:
-	// 	* Pruning is disabled entirely
 	// 	* Engine is currently building something
 	// 	* There are NO `docker_build`s in the Tiltfile
 	// 	* Something is queued for building
-	if !settings.Enabled || len(state.CurrentBuildSet) > 0 || !state.HasDockerBuild() || buildcontrol.NextManifestNameToBuild(state) != "" {
+	if len(state.CurrentBuildSet) > 0 || !state.HasDockerBuild() || buildcontrol.NextManifestNameToBuild(state) != "" {
 		st.RUnlockState()
 		return nil
 	}
 
-	// Prune as soon after startup as we can (waiting until we've built SOMETHING)
 	curBuildCount := state.CompletedBuildCount
-	shouldPrune := dp.lastPruneTime.IsZero() && curBuildCount > 0
-	// "Prune every X builds" takes precedence over "prune every Y hours"
-	if settings.NumBuilds != 0 {
-		buildsSince := curBuildCount - dp.lastPruneBuildCount
-		if buildsSince >= settings.NumBuilds {
-			shouldPrune = true
-		}
-	} else {
-		interval := settings.Interval
-		if interval == 0 {
-			interval = model.DockerPruneDefaultInterval
-		}
-		if time.Since(dp.lastPruneTime) >= interval {
-			shouldPrune = true
+	var due []pruneCluster
+	for _, c := range pruneClusters(state.Clusters) {
+		if dp.shouldPrune(c.name, settings.ForCluster(c.name), curBuildCount) {
+			due = append(due, c)
 		}
 	}
 
-	if shouldPrune {
+	if len(due) > 0 {
 		// N.B. Only determine the ref selectors if we're actually going to prune - OnChange is called for every batch
 		// 	of store events and this is a comparatively expensive operation (lots of regex), but 99% of the time this
 		// 	is called, no pruning is going to happen, so avoid burning CPU cycles unnecessarily
 		imgSelectors := model.LocalRefSelectorsForManifests(state.Manifests(), state.Clusters)
+		composeProjects := ComposeProjectNames(state.Manifests())
 		st.RUnlockState()
-		dp.PruneAndRecordState(ctx, settings.MaxAge, settings.KeepRecent, imgSelectors, curBuildCount)
+		dp.pruneAndRecordState(ctx, due, settings, imgSelectors, composeProjects, curBuildCount)
 		return nil
 	}
 
@@ -119,22 +146,133 @@ func (dp *DockerPruner) OnChange(ctx context.Context, st store.RStore, summary s
 	return nil
 }
 
-func (dp *DockerPruner) PruneAndRecordState(ctx context.Context, maxAge time.Duration, keepRecent int, imgSelectors []container.RefSelector, curBuildCount int) {
-	dp.Prune(ctx, maxAge, keepRecent, imgSelectors)
-	dp.lastPruneTime = time.Now()
-	dp.lastPruneBuildCount = curBuildCount
+// The Clusters with a container runtime, sorted by name.
+//
+// Before the Tiltfile has created any Clusters, we prune the daemon
+// that Tilt builds on, as if it belonged to the default Cluster.
+func pruneClusters(clusters map[string]*v1alpha1.Cluster) []pruneCluster {
+	var result []pruneCluster
+	for name, c := range clusters {
+		if c.Spec.Connection == nil {
+			continue
+		}
+		if c.Spec.Connection.Docker != nil {
+			result = append(result, pruneCluster{name: name, docker: true})
+		} else if c.Spec.Connection.Kubernetes != nil {
+			result = append(result, pruneCluster{name: name})
+		}
+	}
+	if len(clusters) == 0 {
+		result = append(result, pruneCluster{name: v1alpha1.ClusterNameDefault})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].name < result[j].name
+	})
+	return result
+}
+
+func (dp *DockerPruner) shouldPrune(cluster string, settings model.DockerPruneSettings, curBuildCount int) bool {
+	if !settings.Enabled {
+		return false
+	}
+
+	// Prune as soon after startup as we can (waiting until we've built SOMETHING)
+	schedule := dp.schedules[cluster]
+	if schedule.lastPruneTime.IsZero() && curBuildCount > 0 {
+		return true
+	}
+
+	// "Prune every X builds" takes precedence over "prune every Y hours"
+	if settings.NumBuilds != 0 {
+		buildsSince := curBuildCount - schedule.lastPruneBuildCount
+		return buildsSince >= settings.NumBuilds
+	}
+
+	interval := settings.Interval
+	if interval == 0 {
+		interval = model.DockerPruneDefaultInterval
+	}
+	return time.Since(schedule.lastPruneTime) >= interval
+}
+
+// Prunes the container runtime of each Cluster, and records the result on the Cluster.
+//
+// Clusters can share a daemon (like Docker Desktop's Kubernetes and Docker Compose),
+// so we only prune each daemon once.
+func (dp *DockerPruner) pruneAndRecordState(ctx context.Context, clusters []pruneCluster, settings model.DockerPruneSettings, imgSelectors []container.RefSelector, composeProjects []string, curBuildCount int) {
+	runsByHost := make(map[string]v1alpha1.DockerPruneRun)
+	for _, c := range clusters {
+		dCli, err := dp.clientFor(c)
+		if err != nil {
+			logger.Get(ctx).Debugf("[Docker Prune] skipping cluster %s: %v", c.name, err)
+			continue
+		}
+
+		host := dCli.Env().DaemonHost()
+		run, ok := runsByHost[host]
+		if !ok || host == "" {
+			run = dp.withClient(dCli).pruneWithSettings(ctx, settings.ForCluster(c.name), imgSelectors, composeProjects)
+			runsByHost[host] = run
+		} else {
+			logger.Get(ctx).Debugf("[Docker Prune] cluster %s shares a Docker daemon with a cluster we just pruned", c.name)
+		}
+		dp.recordRun(ctx, c.name, run)
+
+		dp.schedules[c.name] = pruneSchedule{
+			lastPruneBuildCount: curBuildCount,
+			lastPruneTime:       time.Now(),
+		}
+	}
 }
 
-func (dp *DockerPruner) Prune(ctx context.Context, maxAge time.Duration, keepRecent int, imgSelectors []container.RefSelector) {
-	// For future: dispatch event with output/errors to be recorded
-	//   in engineState.TiltSystemState on store (analogous to TiltfileState)
-	err := dp.prune(ctx, maxAge, keepRecent, imgSelectors)
+// The Docker client for the Cluster's container runtime.
+func (dp *DockerPruner) clientFor(c pruneCluster) (docker.Client, error) {
+	if !c.docker || dp.clients == nil {
+		if dp.disabledOnSetup {
+			return nil, fmt.Errorf("docker is not responding")
+		}
+		if c.docker {
+			return dp.dCli.ForOrchestrator(model.OrchestratorDC), nil
+		}
+		return dp.dCli.ForOrchestrator(model.OrchestratorK8s), nil
+	}
+
+	dCli, err := dp.clients.GetComposeDockerClient(ktypes.NamespacedName{Name: c.name})
+	if err != nil {
+		return nil, err
+	}
+	err = dCli.CheckConnected()
+	if err != nil {
+		return nil, err
+	}
+	return dCli, nil
+}
+
+// A pruner for another Docker daemon. Prune and PruneVolumes only need the client.
+func (dp *DockerPruner) withClient(dCli docker.Client) *DockerPruner {
+	return &DockerPruner{dCli: dCli}
+}
+
+func (dp *DockerPruner) pruneWithSettings(ctx context.Context, settings model.DockerPruneSettings, imgSelectors []container.RefSelector, composeProjects []string) v1alpha1.DockerPruneRun {
+	run := v1alpha1.DockerPruneRun{StartTime: apis.NowMicro()}
+	dp.Prune(ctx, settings.MaxAge, KeepPolicyFromSettings(settings), imgSelectors, &run)
+	if settings.PruneVolumes {
+		dp.PruneVolumes(ctx, settings.VolumeMaxAge, composeProjects, &run)
+	}
+	run.FinishTime = apis.NowMicro()
+	return run
+}
+
+// Prune adds what it removed (and any error) to run.
+func (dp *DockerPruner) Prune(ctx context.Context, maxAge time.Duration, keep KeepPolicy, imgSelectors []container.RefSelector, run *v1alpha1.DockerPruneRun) {
+	err := dp.prune(ctx, maxAge, keep, imgSelectors, run)
 	if err != nil {
 		logger.Get(ctx).Infof("[Docker Prune] error running docker prune: %v", err)
+		addRunError(run, err)
 	}
 }
 
-func (dp *DockerPruner) prune(ctx context.Context, maxAge time.Duration, keepRecent int, imgSelectors []container.RefSelector) error {
+func (dp *DockerPruner) prune(ctx context.Context, maxAge time.Duration, keep KeepPolicy, imgSelectors []container.RefSelector, run *v1alpha1.DockerPruneRun) error {
 	l := logger.Get(ctx)
 	if err := dp.sufficientVersionError(); err != nil {
 		l.Debugf("[Docker Prune] skipping Docker prune, Docker API version too low:\t%v", err)
@@ -152,15 +290,25 @@ func (dp *DockerPruner) prune(ctx context.Context, maxAge time.Duration, keepRec
 		return err
 	}
 	prettyPrintContainersPruneReport(containerReport, l)
+	run.ContainersDeleted += int32(len(containerReport.ContainersDeleted))
+	run.SpaceReclaimed += int64(containerReport.SpaceReclaimed)
 
 	// PRUNE IMAGES
-	imageReport, err := dp.deleteOldImages(ctx, maxAge, keepRecent, imgSelectors)
+	imageReport, err := dp.deleteOldImages(ctx, maxAge, keep, imgSelectors)
 	if err != nil {
 		return err
 	}
 	prettyPrintImagesPruneReport(imageReport, l)
+	run.ImagesDeleted += int32(len(imageReport.ImagesDeleted))
+	run.SpaceReclaimed += int64(imageReport.SpaceReclaimed)
 
 	// PRUNE BUILD CACHE
+	if docker.IsPodman(dp.dCli.ServerVersion()) {
+		// Podman's build cache is made of images, so we already pruned it.
+		l.Debugf("[Docker Prune] skipping build cache prune, Podman doesn't have a separate build cache")
+		return nil
+	}
+
 	opts := types.BuildCachePruneOptions{Filters: f}
 	cacheReport, err := dp.dCli.BuildCachePrune(ctx, opts)
 	if err != nil {
@@ -170,11 +318,133 @@ func (dp *DockerPruner) prune(ctx context.Context, maxAge time.Duration, keepRec
 		l.Debugf("[Docker Prune] skipping build cache prune, Docker API version too low:\t%s", err)
 	} else {
 		prettyPrintCachePruneReport(cacheReport, l)
+		run.CachesDeleted += int32(len(cacheReport.CachesDeleted))
+		run.SpaceReclaimed += int64(cacheReport.SpaceReclaimed)
+	}
+
+	return nil
+}
+
+// PruneVolumes removes volumes that Tilt created, that no container is using,
+// and that are at least maxAge old.
+//
+// Most volumes come from the Docker Compose projects that Tilt runs, which label
+// them with their project name. Tilt labels the volumes that it creates itself.
+//
+// Unlike the other objects we prune, volumes hold state that the user may care
+// about, so this only runs if they ask for it.
+func (dp *DockerPruner) PruneVolumes(ctx context.Context, maxAge time.Duration, composeProjects []string, run *v1alpha1.DockerPruneRun) {
+	err := dp.pruneVolumes(ctx, maxAge, composeProjects, run)
+	if err != nil {
+		logger.Get(ctx).Infof("[Docker Prune] error pruning volumes: %v", err)
+		addRunError(run, err)
+	}
+}
+
+func (dp *DockerPruner) pruneVolumes(ctx context.Context, maxAge time.Duration, composeProjects []string, run *v1alpha1.DockerPruneRun) error {
+	l := logger.Get(ctx)
+	if err := dp.sufficientVersionError(); err != nil {
+		l.Debugf("[Docker Prune] skipping volume prune, Docker API version too low:\t%v", err)
+		return nil
 	}
 
+	// The volume API doesn't support the "until" filter, so we check ages ourselves.
+	//
+	// Label filters are ANDed together, so we check the labels ourselves, too.
+	list, err := dp.dCli.VolumeList(ctx, filters.NewArgs(
+		filters.Arg("dangling", "true"),
+	))
+	if err != nil {
+		return err
+	}
+
+	var toDelete []*types.Volume
+	for _, v := range list.Volumes {
+		if !isTiltVolume(v, composeProjects) {
+			continue
+		}
+
+		createdAt, err := time.Parse(time.RFC3339, v.CreatedAt)
+		if err != nil {
+			l.Debugf("[Docker Prune] error parsing creation time of volume '%s': %v", v.Name, err)
+			continue
+		}
+		if time.Since(createdAt) >= maxAge {
+			toDelete = append(toDelete, v)
+		}
+	}
+
+	var report types.VolumesPruneReport
+	if len(toDelete) > 0 {
+		sizes := dp.volumeSizes(ctx)
+		for _, v := range toDelete {
+			err := dp.dCli.VolumeRemove(ctx, v.Name, false)
+			if err != nil {
+				// A container may have started using the volume since we listed it.
+				l.Debugf("[Docker Prune] error removing volume '%s': %v", v.Name, err)
+				continue
+			}
+			report.VolumesDeleted = append(report.VolumesDeleted, v.Name)
+			report.SpaceReclaimed += sizes[v.Name]
+		}
+	}
+	prettyPrintVolumesPruneReport(report, l)
+	run.VolumesDeleted += int32(len(report.VolumesDeleted))
+	run.SpaceReclaimed += int64(report.SpaceReclaimed)
 	return nil
 }
 
+// The label that Docker Compose puts on the volumes, networks, and containers it creates.
+const composeProjectLabel = "com.docker.compose.project"
+
+func isTiltVolume(v *types.Volume, composeProjects []string) bool {
+	if v.Labels[docker.BuiltByLabel] == docker.BuiltByValue {
+		return true
+	}
+	project := v.Labels[composeProjectLabel]
+	if project == "" {
+		return false
+	}
+	for _, p := range composeProjects {
+		if p == project {
+			return true
+		}
+	}
+	return false
+}
+
+// The names of the Docker Compose projects in the given manifests, sorted.
+func ComposeProjectNames(manifests []model.Manifest) []string {
+	var result []string
+	for _, m := range manifests {
+		if !m.IsDC() {
+			continue
+		}
+		name := m.DockerComposeTarget().Spec.Project.Name
+		if name != "" {
+			result = append(result, name)
+		}
+	}
+	return sliceutils.DedupedAndSorted(result)
+}
+
+// Docker only reports volume sizes in its disk usage summary, which is
+// relatively expensive to compute, so only call this if there's something to prune.
+func (dp *DockerPruner) volumeSizes(ctx context.Context) map[string]uint64 {
+	result := make(map[string]uint64)
+	du, err := dp.dCli.DiskUsage(ctx)
+	if err != nil {
+		logger.Get(ctx).Debugf("[Docker Prune] error getting volume sizes: %v", err)
+		return result
+	}
+	for _, v := range du.Volumes {
+		if v.UsageData != nil && v.UsageData.Size > 0 {
+			result[v.Name] = uint64(v.UsageData.Size)
+		}
+	}
+	return result
+}
+
 func (dp *DockerPruner) inspectImages(ctx context.Context, imgs []types.ImageSummary) []types.ImageInspect {
 	result := []types.ImageInspect{}
 	for _, imgSummary := range imgs {
@@ -216,7 +486,7 @@ func (dp *DockerPruner) filterImageInspectsByMaxAge(ctx context.Context, inspect
 
 // Return all image objects that aren't in the N
 // most recently used for each tag.
-func (dp *DockerPruner) filterOutMostRecentInspects(ctx context.Context, inspects []types.ImageInspect, keepRecent int, selectors []container.RefSelector) []types.ImageInspect {
+func (dp *DockerPruner) filterOutMostRecentInspects(ctx context.Context, inspects []types.ImageInspect, keep KeepPolicy, selectors []container.RefSelector) []types.ImageInspect {
 	// First, sort the images in order from most recent to least recent.
 	recentFirst := append([]types.ImageInspect{}, inspects...)
 	sort.SliceStable(recentFirst, func(i, j int) bool {
@@ -244,7 +514,8 @@ func (dp *DockerPruner) filterOutMostRecentInspects(ctx context.Context, inspect
 
 	// Finally, keep the N most recent for each tag.
 	idsToKeep := make(map[string]bool)
-	for _, list := range imgsBySelector {
+	for sel, list := range imgsBySelector {
+		keepRecent := keep.recentFor(sel)
 		for i := 0; i < keepRecent && i < len(list); i++ {
 			idsToKeep[list[i].ID] = true
 		}
@@ -259,7 +530,29 @@ func (dp *DockerPruner) filterOutMostRecentInspects(ctx context.Context, inspect
 	return result
 }
 
-func (dp *DockerPruner) deleteOldImages(ctx context.Context, maxAge time.Duration, keepRecent int, selectors []container.RefSelector) (types.ImagesPruneReport, error) {
+// Return all image objects that don't match a ref the user asked us to keep.
+func (dp *DockerPruner) filterOutKeptRefs(ctx context.Context, inspects []types.ImageInspect, keep KeepPolicy) []types.ImageInspect {
+	keepSelectors := keep.refSelectors(ctx)
+	if len(keepSelectors) == 0 {
+		return inspects
+	}
+
+	result := []types.ImageInspect{}
+	for _, inspect := range inspects {
+		namedRefs, err := container.ParseNamedMulti(inspect.RepoTags)
+		if err != nil {
+			logger.Get(ctx).Debugf("[Docker Prune] error parsing repo tags for '%s': %v", inspect.ID, err)
+			continue
+		}
+		if container.AnyMatch(namedRefs, keepSelectors) {
+			continue
+		}
+		result = append(result, inspect)
+	}
+	return result
+}
+
+func (dp *DockerPruner) deleteOldImages(ctx context.Context, maxAge time.Duration, keep KeepPolicy, selectors []container.RefSelector) (types.ImagesPruneReport, error) {
 	opts := types.ImageListOptions{
 		Filters: filters.NewArgs(
 			filters.Arg("label", docker.BuiltByTiltLabelStr),
@@ -272,7 +565,8 @@ func (dp *DockerPruner) deleteOldImages(ctx context.Context, maxAge time.Duratio
 
 	inspects := dp.inspectImages(ctx, imgs)
 	inspects = dp.filterImageInspectsByMaxAge(ctx, inspects, maxAge, selectors)
-	toDelete := dp.filterOutMostRecentInspects(ctx, inspects, keepRecent, selectors)
+	toDelete := dp.filterOutMostRecentInspects(ctx, inspects, keep, selectors)
+	toDelete = dp.filterOutKeptRefs(ctx, toDelete, keep)
 
 	rmOpts := types.ImageRemoveOptions{PruneChildren: true}
 	var responseItems []types.ImageDeleteResponseItem
@@ -349,6 +643,18 @@ func prettyPrintContainersPruneReport(report types.ContainersPruneReport, l logg
 	}
 }
 
+func prettyPrintVolumesPruneReport(report types.VolumesPruneReport, l logger.Logger) {
+	if len(report.VolumesDeleted) == 0 && !l.Level().ShouldDisplay(logger.VerboseLvl) {
+		return
+	}
+
+	l.Infof("[Docker Prune] removed %d volumes, reclaimed %s",
+		len(report.VolumesDeleted), humanSize(report.SpaceReclaimed))
+	if len(report.VolumesDeleted) > 0 {
+		l.Debugf("%s", sliceutils.BulletedIndentedStringList(report.VolumesDeleted))
+	}
+}
+
 func humanSize(bytes uint64) string {
 	return units.HumanSize(float64(bytes))
 }
@@ -13,8 +13,12 @@ import (
 	"github.com/docker/go-units"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ktypes "k8s.io/apimachinery/pkg/types"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/controllers/fake"
 	"github.com/tilt-dev/tilt/internal/docker"
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/internal/testutils"
@@ -28,7 +32,7 @@ var (
 	numImages        = 3
 	maxAge           = 11 * time.Hour
 	refSel           = container.MustParseSelector("some-ref")
-	keep0            = 0
+	keep0            = KeepPolicy{}
 )
 
 var buildHistory = []model.BuildRecord{
@@ -41,7 +45,7 @@ func twoHrsAgo() time.Time {
 
 func TestPruneFilters(t *testing.T) {
 	f, imgSelectors := newFixture(t).withPruneOutput(cachesPruned, containersPruned, numImages)
-	err := f.dp.prune(f.ctx, maxAge, keep0, imgSelectors)
+	err := f.dp.prune(f.ctx, maxAge, keep0, imgSelectors, &v1alpha1.DockerPruneRun{})
 	require.NoError(t, err)
 
 	expectedFilters := filters.NewArgs(
@@ -61,7 +65,7 @@ func TestPruneFilters(t *testing.T) {
 
 func TestPruneOutput(t *testing.T) {
 	f, imgSelectors := newFixture(t).withPruneOutput(cachesPruned, containersPruned, numImages)
-	err := f.dp.prune(f.ctx, maxAge, keep0, imgSelectors)
+	err := f.dp.prune(f.ctx, maxAge, keep0, imgSelectors, &v1alpha1.DockerPruneRun{})
 	require.NoError(t, err)
 
 	logs := f.logs.String()
@@ -76,7 +80,7 @@ func TestPruneOutput(t *testing.T) {
 func TestPruneVersionTooLow(t *testing.T) {
 	f, imgSelectors := newFixture(t).withPruneOutput(cachesPruned, containersPruned, numImages)
 	f.dCli.ThrowNewVersionError = true
-	err := f.dp.prune(f.ctx, maxAge, keep0, imgSelectors)
+	err := f.dp.prune(f.ctx, maxAge, keep0, imgSelectors, &v1alpha1.DockerPruneRun{})
 	require.NoError(t, err) // should log failure but not throw error
 
 	logs := f.logs.String()
@@ -92,7 +96,7 @@ func TestPruneVersionTooLow(t *testing.T) {
 func TestPruneSkipCachePruneIfVersionTooLow(t *testing.T) {
 	f, imgSelectors := newFixture(t).withPruneOutput(cachesPruned, containersPruned, numImages)
 	f.dCli.BuildCachePruneErr = f.dCli.VersionError("1.2.3", "build prune")
-	err := f.dp.prune(f.ctx, maxAge, keep0, imgSelectors)
+	err := f.dp.prune(f.ctx, maxAge, keep0, imgSelectors, &v1alpha1.DockerPruneRun{})
 	require.NoError(t, err) // should log failure but not throw error
 
 	logs := f.logs.String()
@@ -104,10 +108,26 @@ func TestPruneSkipCachePruneIfVersionTooLow(t *testing.T) {
 	assert.NotEmpty(t, f.dCli.RemovedImageIDs)
 }
 
+func TestPruneSkipCachePruneOnPodman(t *testing.T) {
+	f, imgSelectors := newFixture(t).withPruneOutput(cachesPruned, containersPruned, numImages)
+	f.dCli.FakeServerVersion = &types.Version{
+		Components: []types.ComponentVersion{{Name: "Podman Engine", Version: "4.5.0"}},
+	}
+	err := f.dp.prune(f.ctx, maxAge, keep0, imgSelectors, &v1alpha1.DockerPruneRun{})
+	require.NoError(t, err)
+
+	logs := f.logs.String()
+	assert.Contains(t, logs, "skipping build cache prune")
+
+	assert.Empty(t, f.dCli.BuildCachePruneOpts)
+	assert.NotEmpty(t, f.dCli.ContainersPruneFilters)
+	assert.NotEmpty(t, f.dCli.RemovedImageIDs)
+}
+
 func TestPruneReturnsCachePruneError(t *testing.T) {
 	f, imgSelectors := newFixture(t).withPruneOutput(cachesPruned, containersPruned, numImages)
 	f.dCli.BuildCachePruneErr = fmt.Errorf("this is a real error, NOT an API version error")
-	err := f.dp.prune(f.ctx, maxAge, keep0, imgSelectors)
+	err := f.dp.prune(f.ctx, maxAge, keep0, imgSelectors, &v1alpha1.DockerPruneRun{})
 	require.NotNil(t, err) // For all errors besides API version error, expect them to return
 	assert.Contains(t, err.Error(), "this is a real error")
 
@@ -154,12 +174,12 @@ func TestKeepRecentImages(t *testing.T) {
 		container.NameSelector(ref3),
 	}
 
-	keep4 := 4
+	keep4 := KeepPolicy{Recent: 4}
 	report, err := f.dp.deleteOldImages(f.ctx, maxAge, keep4, selectors)
 	require.NoError(t, err)
 	assert.Len(t, report.ImagesDeleted, 0)
 
-	keep2 := 2
+	keep2 := KeepPolicy{Recent: 2}
 	report, err = f.dp.deleteOldImages(f.ctx, maxAge, keep2, selectors)
 	require.NoError(t, err)
 	assert.Len(t, report.ImagesDeleted, 1)
@@ -185,12 +205,12 @@ func TestKeepRecentImagesMultipleTags(t *testing.T) {
 		container.NameSelector(refB2),
 	}
 
-	keep4 := 4
+	keep4 := KeepPolicy{Recent: 4}
 	report, err := f.dp.deleteOldImages(f.ctx, maxAge, keep4, selectors)
 	require.NoError(t, err)
 	assert.Len(t, report.ImagesDeleted, 0)
 
-	keep1 := 1
+	keep1 := KeepPolicy{Recent: 1}
 	report, err = f.dp.deleteOldImages(f.ctx, maxAge, keep1, selectors)
 	require.NoError(t, err)
 	assert.Len(t, report.ImagesDeleted, 3)
@@ -200,6 +220,58 @@ func TestKeepRecentImagesMultipleTags(t *testing.T) {
 	assert.Equal(t, expectedDeleted, f.dCli.RemovedImageIDs)
 }
 
+func TestKeepRecentByImage(t *testing.T) {
+	f := newFixture(t)
+	maxAge := time.Minute
+	_, refA1 := f.withImageInspect(0, 10, time.Hour)
+	_, refA2 := f.withImageInspect(0, 100, 2*time.Hour)
+	idB1, refB1 := f.withImageInspect(1, 10, 3*time.Hour)
+	idB2, refB2 := f.withImageInspect(1, 100, 4*time.Hour)
+	selectors := []container.RefSelector{
+		container.NameSelector(refA1),
+		container.NameSelector(refA2),
+		container.NameSelector(refB1),
+		container.NameSelector(refB2),
+	}
+
+	keep := KeepPolicy{RecentByImage: map[string]int{"tag-0": 2}}
+	report, err := f.dp.deleteOldImages(f.ctx, maxAge, keep, selectors)
+	require.NoError(t, err)
+	assert.Len(t, report.ImagesDeleted, 2)
+
+	// keeps both builds of tag-0, and no builds of tag-1
+	expectedDeleted := []string{idB1, idB2}
+	assert.Equal(t, expectedDeleted, f.dCli.RemovedImageIDs)
+}
+
+func TestKeepRefs(t *testing.T) {
+	f := newFixture(t)
+	maxAge := time.Minute
+	_, refA := f.withImageInspect(0, 10, time.Hour)
+	idB, refB := f.withImageInspect(1, 100, 2*time.Hour)
+	selectors := []container.RefSelector{
+		container.NameSelector(refA),
+		container.NameSelector(refB),
+	}
+
+	keep := KeepPolicy{Refs: []string{"tag-0"}}
+	report, err := f.dp.deleteOldImages(f.ctx, maxAge, keep, selectors)
+	require.NoError(t, err)
+	assert.Len(t, report.ImagesDeleted, 1)
+	assert.Equal(t, []string{idB}, f.dCli.RemovedImageIDs)
+}
+
+func TestKeepRefsWithTag(t *testing.T) {
+	f := newFixture(t)
+	maxAge := time.Minute
+	_, ref := f.withImageInspect(0, 10, time.Hour)
+
+	keep := KeepPolicy{Refs: []string{"tag-0:some-other-tag"}}
+	report, err := f.dp.deleteOldImages(f.ctx, maxAge, keep, []container.RefSelector{container.NameSelector(ref)})
+	require.NoError(t, err)
+	assert.Len(t, report.ImagesDeleted, 1, "a ref with a tag only keeps images with that tag")
+}
+
 func TestDeleteOldImagesDontRemoveImageWithMultipleTags(t *testing.T) {
 	f := newFixture(t)
 	maxAge := 3 * time.Hour
@@ -217,13 +289,207 @@ func TestDeleteOldImagesDontRemoveImageWithMultipleTags(t *testing.T) {
 	assert.Contains(t, f.logs.String(), "`docker image remove --force` required to remove an image with multiple tags")
 }
 
+func TestPruneVolumes(t *testing.T) {
+	f := newFixture(t)
+	f.withVolume("old", docker.BuiltByTiltLabel, 48*time.Hour, 2*units.MB)
+	f.withVolume("new", docker.BuiltByTiltLabel, time.Hour, units.MB)
+	f.withVolume("not-ours", nil, 48*time.Hour, units.MB)
+
+	// Docker Compose labels volumes with their project, not with anything from Tilt.
+	f.withVolume("myproject_db", composeVolumeLabels("myproject", "db"), 48*time.Hour, 3*units.MB)
+	f.withVolume("otherproject_db", composeVolumeLabels("otherproject", "db"), 48*time.Hour, units.MB)
+	f.withVolume("myproject_cache", composeVolumeLabels("myproject", "cache"), 48*time.Hour, units.MB)
+	f.dCli.Volumes["myproject_cache"].UsageData.RefCount = 1
+
+	err := f.dp.pruneVolumes(f.ctx, 24*time.Hour, []string{"myproject"}, &v1alpha1.DockerPruneRun{})
+	require.NoError(t, err)
+
+	expectedFilters := filters.NewArgs(
+		filters.Arg("dangling", "true"),
+	)
+	assert.Equal(t, expectedFilters, f.dCli.VolumeListFilter)
+	assert.Equal(t, []string{"myproject_db", "old"}, f.dCli.RemovedVolumes)
+
+	logs := f.logs.String()
+	assert.Contains(t, logs, "[Docker Prune] removed 2 volumes, reclaimed 5MB")
+	assert.Contains(t, logs, "- old")
+	assert.Contains(t, logs, "- myproject_db")
+}
+
+func TestComposeProjectNames(t *testing.T) {
+	dc := func(name, project string) model.Manifest {
+		return model.Manifest{Name: model.ManifestName(name)}.WithDeployTarget(model.DockerComposeTarget{
+			Name: model.TargetName(name),
+			Spec: v1alpha1.DockerComposeServiceSpec{
+				Service: name,
+				Project: v1alpha1.DockerComposeProject{Name: project},
+			},
+		})
+	}
+	manifests := []model.Manifest{
+		dc("web", "myproject"),
+		dc("db", "myproject"),
+		dc("other", "otherproject"),
+		{Name: "k8s"},
+	}
+	assert.Equal(t, []string{"myproject", "otherproject"}, ComposeProjectNames(manifests))
+}
+
+func TestDockerPrunerVolumesFromSettings(t *testing.T) {
+	f := newFixture(t)
+	f.withDockerManifestAlreadyBuilt()
+	f.withBuildCount(5)
+	f.withVolume("old", docker.BuiltByTiltLabel, 48*time.Hour, units.MB)
+	f.withDockerPruneSettings(true, time.Hour, 10, 0)
+	_ = f.dp.OnChange(f.ctx, f.st, store.LegacyChangeSummary())
+	f.assertPrune()
+	assert.Empty(t, f.dCli.RemovedVolumes)
+
+	f = newFixture(t)
+	f.withDockerManifestAlreadyBuilt()
+	f.withBuildCount(5)
+	f.withVolume("old", docker.BuiltByTiltLabel, 48*time.Hour, units.MB)
+	f.withDockerPruneSettings(true, time.Hour, 10, 0)
+	state := f.st.LockMutableStateForTesting()
+	state.DockerPruneSettings.PruneVolumes = true
+	state.DockerPruneSettings.VolumeMaxAge = 24 * time.Hour
+	f.st.UnlockMutableState()
+	_ = f.dp.OnChange(f.ctx, f.st, store.LegacyChangeSummary())
+	f.assertPrune()
+	assert.Equal(t, []string{"old"}, f.dCli.RemovedVolumes)
+}
+
+func TestDockerPrunerRecordsRunOnCluster(t *testing.T) {
+	f := newFixture(t)
+	f.withDefaultCluster()
+	f.withDockerManifestAlreadyBuilt()
+	f.withBuildCount(5)
+	f.withPruneOutput(cachesPruned, containersPruned, numImages)
+	f.withDockerPruneSettings(true, time.Hour, 10, 0)
+	_ = f.dp.OnChange(f.ctx, f.st, store.LegacyChangeSummary())
+	f.assertPrune()
+
+	runs := f.pruneRuns()
+	require.Len(t, runs, 1)
+	assert.Equal(t, int32(len(containersPruned)), runs[0].ContainersDeleted)
+	assert.Equal(t, int32(len(cachesPruned)), runs[0].CachesDeleted)
+	assert.Greater(t, runs[0].SpaceReclaimed, int64(0))
+	assert.Empty(t, runs[0].Error)
+	assert.False(t, runs[0].StartTime.IsZero())
+	assert.False(t, runs[0].FinishTime.Before(&runs[0].StartTime))
+}
+
+func TestDockerPrunerRecordsErrorOnCluster(t *testing.T) {
+	f := newFixture(t)
+	f.withDefaultCluster()
+	f.dCli.ContainersPruneErr = fmt.Errorf("oh no")
+	f.dp.pruneAndRecordState(f.ctx, []pruneCluster{{name: v1alpha1.ClusterNameDefault}}, model.DockerPruneSettings{MaxAge: time.Hour}, nil, nil, 1)
+
+	runs := f.pruneRuns()
+	require.Len(t, runs, 1)
+	assert.Equal(t, "oh no", runs[0].Error)
+}
+
+func TestDockerPrunerHistoryLimit(t *testing.T) {
+	f := newFixture(t)
+	f.withDefaultCluster()
+	for i := 0; i < historyLimit+2; i++ {
+		f.dp.pruneAndRecordState(f.ctx, []pruneCluster{{name: v1alpha1.ClusterNameDefault}}, model.DockerPruneSettings{MaxAge: time.Hour}, nil, nil, i)
+	}
+	assert.Len(t, f.pruneRuns(), historyLimit)
+}
+
+func TestDockerPrunerPrunesEachCluster(t *testing.T) {
+	f := newFixture(t)
+	f.withCluster(v1alpha1.ClusterNameDefault, false)
+	remote := f.withDockerCluster("remote", "tcp://remote:2376")
+	f.withDockerManifestAlreadyBuilt()
+	f.withBuildCount(5)
+	f.withDockerPruneSettings(true, time.Hour, 10, 0)
+	remote.ContainersPruned = containersPruned
+
+	_ = f.dp.OnChange(f.ctx, f.st, store.LegacyChangeSummary())
+
+	f.assertPrune()
+	assert.Positive(t, remote.ContainersPruneFilters.Len())
+	require.Len(t, f.clusterPruneRuns("remote"), 1)
+	assert.Equal(t, int32(len(containersPruned)), f.clusterPruneRuns("remote")[0].ContainersDeleted)
+	require.Len(t, f.pruneRuns(), 1)
+	assert.Equal(t, int32(0), f.pruneRuns()[0].ContainersDeleted)
+}
+
+func TestDockerPrunerPerClusterSettings(t *testing.T) {
+	f := newFixture(t)
+	f.withCluster(v1alpha1.ClusterNameDefault, false)
+	remote := f.withDockerCluster("remote", "tcp://remote:2376")
+	f.withDockerManifestAlreadyBuilt()
+	f.withBuildCount(5)
+	f.withDockerPruneSettings(true, time.Hour, 10, 0)
+	state := f.st.LockMutableStateForTesting()
+	state.DockerPruneSettings.ByCluster = map[string]model.DockerPruneSettings{
+		"remote": {Enabled: true, MaxAge: 3 * time.Hour, NumBuilds: 2},
+	}
+	f.st.UnlockMutableState()
+
+	_ = f.dp.OnChange(f.ctx, f.st, store.LegacyChangeSummary())
+	f.assertPrune()
+	assert.Equal(t, []string{"3h0m0s"}, remote.ContainersPruneFilters.Get("until"))
+
+	// The remote cluster is due again after 2 builds, but the default cluster isn't.
+	f.dCli.ContainersPruneFilters = filters.Args{}
+	remote.ContainersPruneFilters = filters.Args{}
+	f.withBuildCount(7)
+	_ = f.dp.OnChange(f.ctx, f.st, store.LegacyChangeSummary())
+	f.assertNoPrune()
+	assert.Positive(t, remote.ContainersPruneFilters.Len())
+	assert.Len(t, f.clusterPruneRuns("remote"), 2)
+	assert.Len(t, f.pruneRuns(), 1)
+}
+
+func TestDockerPrunerSharedDaemon(t *testing.T) {
+	f := newFixture(t)
+	f.dCli.FakeEnv = docker.Env{Client: fakeDaemon("unix:///var/run/docker.sock")}
+	f.withCluster(v1alpha1.ClusterNameDefault, false)
+	f.withCluster(v1alpha1.ClusterNameDocker, true)
+	f.clients.clients[v1alpha1.ClusterNameDocker] = f.dCli
+	f.withDockerManifestAlreadyBuilt()
+	f.withBuildCount(5)
+	f.withPruneOutput(cachesPruned, containersPruned, numImages)
+	f.withDockerPruneSettings(true, time.Hour, 10, 0)
+
+	_ = f.dp.OnChange(f.ctx, f.st, store.LegacyChangeSummary())
+
+	f.assertPrune()
+	assert.Contains(t, f.logs.String(), "cluster docker shares a Docker daemon with a cluster we just pruned")
+	assert.Equal(t, f.pruneRuns(), f.clusterPruneRuns(v1alpha1.ClusterNameDocker))
+}
+
+func TestDockerPrunerSkipsDisconnectedCluster(t *testing.T) {
+	f := newFixture(t)
+	f.withCluster(v1alpha1.ClusterNameDefault, false)
+	remote := f.withDockerCluster("remote", "tcp://remote:2376")
+	remote.CheckConnectedErr = fmt.Errorf("connection refused")
+	f.withDockerManifestAlreadyBuilt()
+	f.withBuildCount(5)
+	f.withDockerPruneSettings(true, time.Hour, 10, 0)
+
+	_ = f.dp.OnChange(f.ctx, f.st, store.LegacyChangeSummary())
+
+	f.assertPrune()
+	assert.Equal(t, 0, remote.ContainersPruneFilters.Len())
+	assert.Empty(t, f.clusterPruneRuns("remote"))
+
+	// We try again on the next change.
+	_, ok := f.dp.schedules["remote"]
+	assert.False(t, ok)
+}
+
 func TestDockerPrunerSinceNBuilds(t *testing.T) {
 	f := newFixture(t)
 	f.withDockerManifestAlreadyBuilt()
 	f.withBuildCount(11)
 	f.withDockerPruneSettings(true, 0, 5, 0)
-	f.dp.lastPruneBuildCount = 5
-	f.dp.lastPruneTime = twoHrsAgo()
+	f.withLastPrune(5, twoHrsAgo())
 
 	_ = f.dp.OnChange(f.ctx, f.st, store.LegacyChangeSummary())
 
@@ -235,8 +501,7 @@ func TestDockerPrunerNotEnoughBuilds(t *testing.T) {
 	f.withDockerManifestAlreadyBuilt()
 	f.withBuildCount(11)
 	f.withDockerPruneSettings(true, 0, 10, 0)
-	f.dp.lastPruneBuildCount = 5
-	f.dp.lastPruneTime = twoHrsAgo()
+	f.withLastPrune(5, twoHrsAgo())
 
 	_ = f.dp.OnChange(f.ctx, f.st, store.LegacyChangeSummary())
 
@@ -247,7 +512,7 @@ func TestDockerPrunerSinceInterval(t *testing.T) {
 	f := newFixture(t)
 	f.withDockerManifestAlreadyBuilt()
 	f.withDockerPruneSettings(true, 0, 0, 30*time.Minute)
-	f.dp.lastPruneTime = twoHrsAgo()
+	f.withLastPrune(0, twoHrsAgo())
 
 	_ = f.dp.OnChange(f.ctx, f.st, store.LegacyChangeSummary())
 
@@ -258,7 +523,7 @@ func TestDockerPrunerSinceDefaultInterval(t *testing.T) {
 	f := newFixture(t)
 	f.withDockerManifestAlreadyBuilt()
 	f.withDockerPruneSettings(true, 0, 0, 0)
-	f.dp.lastPruneTime = time.Now().Add(-1 * (model.DockerPruneDefaultInterval + time.Minute))
+	f.withLastPrune(0, time.Now().Add(-1*(model.DockerPruneDefaultInterval+time.Minute)))
 
 	_ = f.dp.OnChange(f.ctx, f.st, store.LegacyChangeSummary())
 
@@ -269,7 +534,7 @@ func TestDockerPrunerNotEnoughTimeElapsed(t *testing.T) {
 	f := newFixture(t)
 	f.withDockerManifestAlreadyBuilt()
 	f.withDockerPruneSettings(true, 0, 0, 3*time.Hour)
-	f.dp.lastPruneTime = twoHrsAgo()
+	f.withLastPrune(0, twoHrsAgo())
 
 	_ = f.dp.OnChange(f.ctx, f.st, store.LegacyChangeSummary())
 
@@ -280,7 +545,7 @@ func TestDockerPrunerSinceDefaultIntervalNotEnoughTime(t *testing.T) {
 	f := newFixture(t)
 	f.withDockerManifestAlreadyBuilt()
 	f.withDockerPruneSettings(true, 0, 0, 0)
-	f.dp.lastPruneTime = time.Now().Add(-1 * model.DockerPruneDefaultInterval).Add(20 * time.Minute)
+	f.withLastPrune(0, time.Now().Add(-1*model.DockerPruneDefaultInterval).Add(20*time.Minute))
 
 	_ = f.dp.OnChange(f.ctx, f.st, store.LegacyChangeSummary())
 
@@ -335,7 +600,7 @@ func TestDockerPrunerCurrentlyBuilding(t *testing.T) {
 	f.withDockerManifestAlreadyBuilt()
 	f.withCurrentlyBuilding("idk something")
 	f.withDockerPruneSettings(true, 0, 0, time.Hour)
-	f.dp.lastPruneTime = twoHrsAgo()
+	f.withLastPrune(0, twoHrsAgo())
 
 	_ = f.dp.OnChange(f.ctx, f.st, store.LegacyChangeSummary())
 
@@ -346,7 +611,7 @@ func TestDockerPrunerPendingBuild(t *testing.T) {
 	f := newFixture(t)
 	f.withDockerManifestUnbuilt() // manifest not yet built will be pending, so we should not prune
 	f.withDockerPruneSettings(true, 0, 0, time.Hour)
-	f.dp.lastPruneTime = twoHrsAgo()
+	f.withLastPrune(0, twoHrsAgo())
 
 	_ = f.dp.OnChange(f.ctx, f.st, store.LegacyChangeSummary())
 
@@ -376,6 +641,9 @@ type dockerPruneFixture struct {
 
 	dCli *docker.FakeClient
 	dp   *DockerPruner
+	tc   ctrlclient.Client
+
+	clients *fakeDockerClients
 }
 
 func newFixture(t *testing.T) *dockerPruneFixture {
@@ -384,7 +652,9 @@ func newFixture(t *testing.T) *dockerPruneFixture {
 	st := store.NewTestingStore()
 
 	dCli := docker.NewFakeClient()
-	dp := NewDockerPruner(dCli)
+	tc := fake.NewFakeTiltClient()
+	clients := &fakeDockerClients{clients: make(map[string]docker.Client)}
+	dp := NewDockerPruner(dCli, tc, clients)
 
 	return &dockerPruneFixture{
 		t:    t,
@@ -393,9 +663,70 @@ func newFixture(t *testing.T) *dockerPruneFixture {
 		st:   st,
 		dCli: dCli,
 		dp:   dp,
+		tc:   tc,
+
+		clients: clients,
+	}
+}
+
+func (dpf *dockerPruneFixture) withDefaultCluster() {
+	err := dpf.tc.Create(dpf.ctx, &v1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: v1alpha1.ClusterNameDefault},
+	})
+	require.NoError(dpf.t, err)
+}
+
+// Adds a Cluster to the engine state and the API.
+func (dpf *dockerPruneFixture) withCluster(name string, isDocker bool) {
+	cluster := &v1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: v1alpha1.ClusterSpec{
+			Connection: &v1alpha1.ClusterConnection{},
+		},
+	}
+	if isDocker {
+		cluster.Spec.Connection.Docker = &v1alpha1.DockerClusterConnection{}
+	} else {
+		cluster.Spec.Connection.Kubernetes = &v1alpha1.KubernetesClusterConnection{}
+	}
+	err := dpf.tc.Create(dpf.ctx, cluster.DeepCopy())
+	require.NoError(dpf.t, err)
+
+	state := dpf.st.LockMutableStateForTesting()
+	state.Clusters[name] = cluster
+	dpf.st.UnlockMutableState()
+}
+
+// Adds a Cluster with its own Docker daemon.
+func (dpf *dockerPruneFixture) withDockerCluster(name, host string) *docker.FakeClient {
+	dpf.withCluster(name, true)
+	dCli := docker.NewFakeClient()
+	dCli.FakeEnv = docker.Env{Client: fakeDaemon(host)}
+	dpf.clients.clients[name] = dCli
+	return dCli
+}
+
+func (dpf *dockerPruneFixture) withLastPrune(buildCount int, t time.Time) {
+	dpf.dp.schedules[v1alpha1.ClusterNameDefault] = pruneSchedule{
+		lastPruneBuildCount: buildCount,
+		lastPruneTime:       t,
 	}
 }
 
+func (dpf *dockerPruneFixture) pruneRuns() []v1alpha1.DockerPruneRun {
+	return dpf.clusterPruneRuns(v1alpha1.ClusterNameDefault)
+}
+
+func (dpf *dockerPruneFixture) clusterPruneRuns(name string) []v1alpha1.DockerPruneRun {
+	var cluster v1alpha1.Cluster
+	err := dpf.tc.Get(dpf.ctx, ktypes.NamespacedName{Name: name}, &cluster)
+	require.NoError(dpf.t, err)
+	if cluster.Status.DockerPrune == nil {
+		return nil
+	}
+	return cluster.Status.DockerPrune.Runs
+}
+
 func (dpf *dockerPruneFixture) withPruneOutput(caches, containers []string, numImages int) (*dockerPruneFixture, []container.RefSelector) {
 	dpf.dCli.BuildCachesPruned = caches
 	dpf.dCli.ContainersPruned = containers
@@ -441,6 +772,26 @@ func (dpf *dockerPruneFixture) withDockerManifest(alreadyBuilt bool) {
 	dpf.withManifestTarget(store.NewManifestTarget(m), alreadyBuilt)
 }
 
+func (dpf *dockerPruneFixture) withVolume(name string, labels map[string]string, age time.Duration, size int) {
+	if dpf.dCli.Volumes == nil {
+		dpf.dCli.Volumes = make(map[string]*types.Volume)
+	}
+	dpf.dCli.Volumes[name] = &types.Volume{
+		Name:      name,
+		Labels:    labels,
+		CreatedAt: time.Now().Add(-age).Format(time.RFC3339),
+		UsageData: &types.VolumeUsageData{Size: int64(size), RefCount: 0},
+	}
+}
+
+func composeVolumeLabels(project string, volume string) map[string]string {
+	return map[string]string{
+		"com.docker.compose.project": project,
+		"com.docker.compose.version": "2.17.2",
+		"com.docker.compose.volume":  volume,
+	}
+}
+
 func (dpf *dockerPruneFixture) withK8sOnlyManifest() {
 	m := model.Manifest{Name: "i'm-k8s-only"}.WithDeployTarget(model.K8sTarget{})
 	dpf.withManifestTarget(store.NewManifestTarget(m), true)
@@ -492,8 +843,8 @@ func (dpf *dockerPruneFixture) assertPrune() {
 		dpf.t.Errorf("expected Prune() to be called, but it was not")
 		dpf.t.FailNow()
 	}
-	if time.Since(dpf.dp.lastPruneTime) > time.Second {
-		dpf.t.Errorf("Prune() was called, but dp.lastPruneTime was not updated/" +
+	if time.Since(dpf.dp.schedules[v1alpha1.ClusterNameDefault].lastPruneTime) > time.Second {
+		dpf.t.Errorf("Prune() was called, but the last prune time was not updated/" +
 			"not updated recently")
 		dpf.t.FailNow()
 	}
@@ -505,3 +856,19 @@ func (dpf *dockerPruneFixture) assertNoPrune() {
 		dpf.t.FailNow()
 	}
 }
+
+type fakeDockerClients struct {
+	clients map[string]docker.Client
+}
+
+func (c *fakeDockerClients) GetComposeDockerClient(key ktypes.NamespacedName) (docker.Client, error) {
+	dCli, ok := c.clients[key.Name]
+	if !ok {
+		return nil, fmt.Errorf("no connection to cluster %s", key.Name)
+	}
+	return dCli, nil
+}
+
+type fakeDaemon string
+
+func (d fakeDaemon) DaemonHost() string { return string(d) }
@@ -0,0 +1,67 @@
+package dockerprune
+
+import (
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+// The number of prune runs we keep on the Cluster.
+const historyLimit = 10
+
+// The cluster reconciler also updates the Cluster status, so we may
+// need a few tries to get our update in.
+const recordAttempts = 3
+
+// Records a prune run on the Cluster, so that it shows up
+// in the API and the web UI.
+func (dp *DockerPruner) recordRun(ctx context.Context, cluster string, run v1alpha1.DockerPruneRun) {
+	if dp.ctrlClient == nil {
+		return
+	}
+
+	var err error
+	for i := 0; i < recordAttempts; i++ {
+		err = dp.appendRun(ctx, cluster, run)
+		if !apierrors.IsConflict(err) {
+			break
+		}
+	}
+	if err != nil {
+		logger.Get(ctx).Debugf("[Docker Prune] error recording prune: %v", err)
+	}
+}
+
+func (dp *DockerPruner) appendRun(ctx context.Context, name string, run v1alpha1.DockerPruneRun) error {
+	var cluster v1alpha1.Cluster
+	err := dp.ctrlClient.Get(ctx, types.NamespacedName{Name: name}, &cluster)
+	if err != nil {
+		return err
+	}
+
+	update := cluster.DeepCopy()
+	update.Status.DockerPrune = appendRun(update.Status.DockerPrune, run)
+	return dp.ctrlClient.Status().Update(ctx, update)
+}
+
+func appendRun(status *v1alpha1.DockerPruneStatus, run v1alpha1.DockerPruneRun) *v1alpha1.DockerPruneStatus {
+	if status == nil {
+		status = &v1alpha1.DockerPruneStatus{}
+	}
+	status.Runs = append(status.Runs, run)
+	if len(status.Runs) > historyLimit {
+		status.Runs = status.Runs[len(status.Runs)-historyLimit:]
+	}
+	return status
+}
+
+func addRunError(run *v1alpha1.DockerPruneRun, err error) {
+	if run.Error != "" {
+		run.Error += "; "
+	}
+	run.Error += err.Error()
+}
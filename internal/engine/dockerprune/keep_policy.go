@@ -0,0 +1,58 @@
+package dockerprune
+
+import (
+	"context"
+
+	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// Which images to keep, even if they're old enough to prune.
+type KeepPolicy struct {
+	// Keep the N most recent builds of each image.
+	Recent int
+
+	// Overrides Recent for specific images, keyed by image name.
+	RecentByImage map[string]int
+
+	// Never prune images that match these refs. A ref without a tag
+	// matches every tag of that image.
+	Refs []string
+}
+
+func KeepPolicyFromSettings(settings model.DockerPruneSettings) KeepPolicy {
+	return KeepPolicy{
+		Recent:        settings.KeepRecent,
+		RecentByImage: settings.KeepRecentByImage,
+		Refs:          settings.KeepRefs,
+	}
+}
+
+// The number of recent builds to keep of images matching the given selector.
+func (p KeepPolicy) recentFor(sel container.RefSelector) int {
+	for name, n := range p.RecentByImage {
+		ref, err := container.ParseNamed(name)
+		if err != nil {
+			continue
+		}
+		if ref.Name() == sel.RefName() {
+			return n
+		}
+	}
+	return p.Recent
+}
+
+func (p KeepPolicy) refSelectors(ctx context.Context) []container.RefSelector {
+	var result []container.RefSelector
+	for _, r := range p.Refs {
+		ref, err := container.ParseNamed(r)
+		if err != nil {
+			// The Tiltfile validates these, so this should never happen.
+			logger.Get(ctx).Debugf("[Docker Prune] error parsing ref to keep '%s': %v", r, err)
+			continue
+		}
+		result = append(result, container.NewRefSelector(ref))
+	}
+	return result
+}
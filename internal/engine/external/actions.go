@@ -0,0 +1,20 @@
+package external
+
+import (
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// Dispatched when the health check of an external resource
+// starts passing or starts failing.
+type HealthAction struct {
+	ManifestName model.ManifestName
+	Healthy      bool
+
+	// Why the health check is failing.
+	Message string
+
+	// The log span for the health check output.
+	SpanID model.LogSpanID
+}
+
+func (HealthAction) Action() {}
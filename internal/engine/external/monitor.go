@@ -0,0 +1,149 @@
+package external
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/tilt-dev/probe/pkg/prober"
+	"k8s.io/apimachinery/pkg/api/equality"
+
+	"github.com/tilt-dev/tilt/internal/controllers/core/cmd"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// Polls the health of external resources, i.e., services that
+// Tilt doesn't run, declared with external_resource().
+//
+// Health checks start once the resource's (no-op) update completes, like
+// a serve_cmd would, and stop when the resource is disabled or removed.
+type HealthMonitor struct {
+	prober cmd.ProberManager
+
+	mu     sync.Mutex
+	checks map[model.ManifestName]*healthCheck
+}
+
+type healthCheck struct {
+	spec   *v1alpha1.Probe
+	cancel context.CancelFunc
+}
+
+var _ store.Subscriber = &HealthMonitor{}
+var _ store.TearDowner = &HealthMonitor{}
+
+func NewHealthMonitor(prober cmd.ProberManager) *HealthMonitor {
+	return &HealthMonitor{
+		prober: prober,
+		checks: make(map[model.ManifestName]*healthCheck),
+	}
+}
+
+func SpanIDForHealthCheck(mn model.ManifestName) model.LogSpanID {
+	return model.LogSpanID(fmt.Sprintf("external:%s", mn))
+}
+
+func (m *HealthMonitor) CrashRecoverable() {}
+
+func (m *HealthMonitor) OnChange(ctx context.Context, st store.RStore, summary store.ChangeSummary) error {
+	if summary.IsLogOnly() {
+		return nil
+	}
+
+	desired := desiredChecks(st)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for name, check := range m.checks {
+		spec, ok := desired[name]
+		if !ok || !equality.Semantic.DeepEqual(spec, check.spec) {
+			check.cancel()
+			delete(m.checks, name)
+		}
+	}
+
+	for name, spec := range desired {
+		if _, ok := m.checks[name]; ok {
+			continue
+		}
+		m.start(ctx, st, name, spec)
+	}
+	return nil
+}
+
+func (m *HealthMonitor) TearDown(ctx context.Context) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for name, check := range m.checks {
+		check.cancel()
+		delete(m.checks, name)
+	}
+}
+
+// The health checks that should be running, by resource.
+func desiredChecks(st store.RStore) map[model.ManifestName]*v1alpha1.Probe {
+	state := st.RLockState()
+	defer st.RUnlockState()
+
+	result := make(map[model.ManifestName]*v1alpha1.Probe)
+	for _, mt := range state.Targets() {
+		if !mt.Manifest.IsLocal() {
+			continue
+		}
+		lt := mt.Manifest.LocalTarget()
+		if !lt.IsExternal() || lt.ReadinessProbe == nil {
+			continue
+		}
+		if mt.State.DisableState == v1alpha1.DisableStateDisabled {
+			continue
+		}
+
+		// Wait for the resource to be triggered, so that health checks
+		// respect auto_init=False.
+		us := mt.UpdateStatus()
+		if us != v1alpha1.UpdateStatusOK && us != v1alpha1.UpdateStatusNotApplicable {
+			continue
+		}
+		result[mt.Manifest.Name] = lt.ReadinessProbe
+	}
+	return result
+}
+
+// Must hold the lock.
+func (m *HealthMonitor) start(ctx context.Context, st store.RStore, name model.ManifestName, spec *v1alpha1.Probe) {
+	spanID := SpanIDForHealthCheck(name)
+	ctx, cancel := context.WithCancel(store.WithManifestLogHandler(ctx, st, name, spanID))
+	m.checks[name] = &healthCheck{spec: spec, cancel: cancel}
+
+	worker, err := cmd.ProbeWorkerFromSpec(m.prober, spec,
+		func(result prober.Result, statusChanged bool, output string, err error) {
+			if ctx.Err() != nil || !statusChanged {
+				return
+			}
+
+			healthy := result == prober.Success || result == prober.Warning
+			message := strings.TrimSpace(output)
+			if err != nil {
+				message = err.Error()
+			}
+			if healthy {
+				logger.Get(ctx).Infof("[health check: %s] %s", result, message)
+				message = ""
+			} else {
+				logger.Get(ctx).Warnf("[health check: %s] %s", result, message)
+			}
+			st.Dispatch(HealthAction{ManifestName: name, Healthy: healthy, Message: message, SpanID: spanID})
+		})
+	if err != nil {
+		logger.Get(ctx).Errorf("Invalid health check: %v", err)
+		st.Dispatch(HealthAction{ManifestName: name, Healthy: false, Message: err.Error(), SpanID: spanID})
+		return
+	}
+
+	go worker.Run(ctx)
+}
@@ -0,0 +1,244 @@
+package external
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tilt-dev/probe/pkg/prober"
+
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/internal/testutils"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestHealthCheckFailsThenPasses(t *testing.T) {
+	f := newFixture(t)
+	f.addExternal("api", true)
+
+	f.prober.setResult(prober.Failure, "connection refused")
+	f.onChange()
+
+	action := f.nextHealthAction()
+	assert.False(t, action.Healthy)
+	assert.Equal(t, "connection refused", action.Message)
+	assert.Equal(t, "payments.example.com", f.prober.lastURL().Hostname())
+
+	f.reduce(action)
+	f.withManifestTarget("api", func(mt *store.ManifestTarget) {
+		assert.Equal(t, v1alpha1.RuntimeStatusError, mt.RuntimeStatus())
+		assert.EqualError(t, mt.State.RuntimeState.RuntimeStatusError(), "Health check failed: connection refused")
+		assert.False(t, mt.State.RuntimeState.HasEverBeenReadyOrSucceeded())
+	})
+
+	f.prober.setResult(prober.Success, "200 OK")
+	action = f.nextHealthAction()
+	assert.True(t, action.Healthy)
+	assert.Equal(t, "", action.Message)
+
+	f.reduce(action)
+	f.withManifestTarget("api", func(mt *store.ManifestTarget) {
+		assert.Equal(t, v1alpha1.RuntimeStatusOK, mt.RuntimeStatus())
+		assert.NoError(t, mt.State.RuntimeState.RuntimeStatusError())
+		assert.True(t, mt.State.RuntimeState.HasEverBeenReadyOrSucceeded())
+	})
+}
+
+func TestHealthCheckWaitsForUpdate(t *testing.T) {
+	f := newFixture(t)
+	f.addExternal("api", false)
+
+	f.onChange()
+	assert.Empty(t, f.monitor.checks)
+
+	f.st.WithManifestState("api", func(ms *store.ManifestState) {
+		ms.AddCompletedBuild(model.BuildRecord{StartTime: time.Now(), FinishTime: time.Now()})
+	})
+	f.onChange()
+	assert.Len(t, f.monitor.checks, 1)
+}
+
+func TestHealthCheckStopsWhenDisabled(t *testing.T) {
+	f := newFixture(t)
+	f.addExternal("api", true)
+
+	f.onChange()
+	require.Len(t, f.monitor.checks, 1)
+
+	f.st.WithManifestState("api", func(ms *store.ManifestState) {
+		ms.DisableState = v1alpha1.DisableStateDisabled
+	})
+	f.onChange()
+	assert.Empty(t, f.monitor.checks)
+}
+
+func TestHealthCheckRestartsWhenSpecChanges(t *testing.T) {
+	f := newFixture(t)
+	f.addExternal("api", true)
+
+	f.onChange()
+	require.Len(t, f.monitor.checks, 1)
+	old := f.monitor.checks["api"]
+
+	f.st.WithState(func(state *store.EngineState) {
+		mt := state.ManifestTargets["api"]
+		lt := mt.Manifest.LocalTarget()
+		lt.ReadinessProbe = &v1alpha1.Probe{
+			Handler: v1alpha1.Handler{
+				TCPSocket: &v1alpha1.TCPSocketAction{Host: "payments.example.com", Port: 443},
+			},
+		}
+		mt.Manifest = mt.Manifest.WithDeployTarget(lt)
+	})
+	f.onChange()
+	require.Len(t, f.monitor.checks, 1)
+	assert.NotSame(t, old, f.monitor.checks["api"])
+}
+
+func TestHandleHealthActionIgnoresOtherResources(t *testing.T) {
+	f := newFixture(t)
+	f.st.WithState(func(state *store.EngineState) {
+		lt := model.NewLocalTarget("server", model.Cmd{}, model.ToHostCmd("./server"), nil)
+		state.UpsertManifestTarget(store.NewManifestTarget(model.Manifest{Name: "server"}.WithDeployTarget(lt)))
+	})
+
+	f.reduce(HealthAction{ManifestName: "server", Healthy: true})
+	f.reduce(HealthAction{ManifestName: "missing", Healthy: true})
+	f.withManifestTarget("server", func(mt *store.ManifestTarget) {
+		assert.False(t, mt.State.RuntimeState.HasEverBeenReadyOrSucceeded())
+	})
+}
+
+type fixture struct {
+	t       *testing.T
+	ctx     context.Context
+	st      *store.TestingStore
+	prober  *fakeProber
+	monitor *HealthMonitor
+	seen    int
+}
+
+func newFixture(t *testing.T) *fixture {
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	ctx, cancel := context.WithCancel(ctx)
+	t.Cleanup(cancel)
+
+	p := &fakeProber{result: prober.Success}
+	f := &fixture{
+		t:       t,
+		ctx:     ctx,
+		st:      store.NewTestingStore(),
+		prober:  p,
+		monitor: NewHealthMonitor(p),
+	}
+	t.Cleanup(func() { f.monitor.TearDown(ctx) })
+	return f
+}
+
+func (f *fixture) addExternal(name model.ManifestName, updated bool) {
+	lt := model.NewLocalTarget(model.TargetName(name), model.Cmd{}, model.Cmd{}, nil).
+		WithExternalURL("https://payments.example.com/healthz").
+		WithReadinessProbe(&v1alpha1.Probe{
+			PeriodSeconds: 1,
+			Handler: v1alpha1.Handler{
+				HTTPGet: &v1alpha1.HTTPGetAction{
+					Scheme: v1alpha1.URISchemeHTTPS,
+					Host:   "payments.example.com",
+					Port:   443,
+					Path:   "/healthz",
+				},
+			},
+		})
+	m := model.Manifest{Name: name, TriggerMode: model.TriggerModeAuto}.WithDeployTarget(lt)
+
+	f.st.WithState(func(state *store.EngineState) {
+		mt := store.NewManifestTarget(m)
+		if updated {
+			mt.State.AddCompletedBuild(model.BuildRecord{StartTime: time.Now(), FinishTime: time.Now()})
+		}
+		state.UpsertManifestTarget(mt)
+	})
+}
+
+func (f *fixture) onChange() {
+	err := f.monitor.OnChange(f.ctx, f.st, store.ChangeSummary{})
+	require.NoError(f.t, err)
+}
+
+// Waits for the next HealthAction that we haven't seen yet.
+func (f *fixture) nextHealthAction() HealthAction {
+	var result HealthAction
+	require.Eventually(f.t, func() bool {
+		actions := f.st.Actions()
+		for ; f.seen < len(actions); f.seen++ {
+			if action, ok := actions[f.seen].(HealthAction); ok {
+				result = action
+				f.seen++
+				return true
+			}
+		}
+		return false
+	}, 5*time.Second, 10*time.Millisecond)
+	return result
+}
+
+func (f *fixture) reduce(action HealthAction) {
+	f.st.WithState(func(state *store.EngineState) {
+		HandleHealthAction(state, action)
+	})
+}
+
+func (f *fixture) withManifestTarget(name model.ManifestName, fn func(mt *store.ManifestTarget)) {
+	state := f.st.RLockState()
+	defer f.st.RUnlockState()
+	mt, ok := state.ManifestTargets[name]
+	require.True(f.t, ok)
+	fn(mt)
+}
+
+type fakeProber struct {
+	mu     sync.Mutex
+	result prober.Result
+	output string
+	url    *url.URL
+}
+
+func (p *fakeProber) setResult(result prober.Result, output string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.result = result
+	p.output = output
+}
+
+func (p *fakeProber) lastURL() *url.URL {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.url
+}
+
+func (p *fakeProber) probe(_ context.Context) (prober.Result, string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.result, p.output, nil
+}
+
+func (p *fakeProber) HTTPGet(u *url.URL, _ http.Header) prober.ProberFunc {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.url = u
+	return p.probe
+}
+
+func (p *fakeProber) TCPSocket(_ string, _ int) prober.ProberFunc {
+	return p.probe
+}
+
+func (p *fakeProber) Exec(_ string, _ ...string) prober.ProberFunc {
+	return p.probe
+}
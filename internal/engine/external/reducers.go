@@ -0,0 +1,34 @@
+package external
+
+import (
+	"time"
+
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+// Update the runtime state of the external resource to match its health.
+//
+// Resources that depend on it wait until it's been healthy at least once.
+func HandleHealthAction(state *store.EngineState, action HealthAction) {
+	mt, ok := state.ManifestTargets[action.ManifestName]
+	if !ok || !mt.Manifest.IsLocal() || !mt.Manifest.LocalTarget().IsExternal() {
+		return
+	}
+
+	ms := mt.State
+	lrs := ms.LocalRuntimeState()
+	lrs.SpanID = action.SpanID
+	if action.Healthy {
+		lrs.Status = v1alpha1.RuntimeStatusOK
+		lrs.HealthCheckError = ""
+		if !lrs.Ready {
+			lrs.LastReadyOrSucceededTime = time.Now()
+		}
+	} else {
+		lrs.Status = v1alpha1.RuntimeStatusError
+		lrs.HealthCheckError = action.Message
+	}
+	lrs.Ready = action.Healthy
+	ms.RuntimeState = lrs
+}
@@ -0,0 +1,36 @@
+package idle
+
+import (
+	"time"
+
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// Dispatched right before Tilt disables resources for being idle,
+// so that the reducer can save their build results.
+type ShutdownAction struct {
+	ShutDownAt time.Time
+	Resources  []model.ManifestName
+}
+
+func (ShutdownAction) Action() {}
+
+// Dispatched right before Tilt re-enables the resources that it disabled.
+type ResumeAction struct {
+	StartTime time.Time
+
+	// Files that changed while the resources were disabled, by target.
+	// File watches are disabled along with their resources, so we need
+	// to look for these ourselves.
+	ChangedFiles map[model.TargetID][]string
+}
+
+func (ResumeAction) Action() {}
+
+// Dispatched when every resource that we resumed has deployed.
+type ResumeDoneAction struct {
+	StartTime  time.Time
+	FinishTime time.Time
+}
+
+func (ResumeDoneAction) Action() {}
@@ -0,0 +1,67 @@
+package idle
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tilt-dev/tilt/internal/ignore"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// Finds the files that changed since the given time under each FileWatch.
+//
+// File watches are disabled along with their resources, so this is the
+// only way to know whether a resource's last build is still good. Like
+// FilesChangedSet, this is liberal: a directory whose entries changed
+// counts, because that's the only trace a deleted file leaves.
+func changedFiles(fws []*v1alpha1.FileWatch, since time.Time) map[model.TargetID][]string {
+	result := make(map[model.TargetID][]string)
+	for _, fw := range fws {
+		id, ok := fileWatchTargetID(fw)
+		if !ok {
+			continue
+		}
+
+		matcher := ignore.CreateFileChangeFilter(fw.Spec.Ignores)
+		for _, root := range fw.Spec.WatchedPaths {
+			_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+				if err != nil {
+					// Deleted files show up in their parent's mtime.
+					return nil
+				}
+
+				if d.IsDir() {
+					skip, _ := matcher.MatchesEntireDir(path)
+					if skip {
+						return filepath.SkipDir
+					}
+				} else {
+					ignored, _ := matcher.Matches(path)
+					if ignored {
+						return nil
+					}
+				}
+
+				info, err := d.Info()
+				if err == nil && info.ModTime().After(since) {
+					result[id] = append(result[id], path)
+				}
+				return nil
+			})
+		}
+		sort.Strings(result[id])
+	}
+	return result
+}
+
+func fileWatchTargetID(fw *v1alpha1.FileWatch) (model.TargetID, bool) {
+	parts := strings.SplitN(fw.Annotations[v1alpha1.AnnotationTargetID], ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return model.TargetID{}, false
+	}
+	return model.TargetID{Type: model.TargetType(parts[0]), Name: model.TargetName(parts[1])}, true
+}
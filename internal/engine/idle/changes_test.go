@@ -0,0 +1,52 @@
+package idle
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestChangedFiles(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.WriteFile("src/main.go", "package main")
+	f.WriteFile("src/util.go", "package main")
+	f.WriteFile("src/build/out.bin", "")
+
+	shutDownAt := time.Now()
+	old := shutDownAt.Add(-time.Hour)
+	for _, p := range []string{"src", "src/main.go", "src/util.go", "src/build", "src/build/out.bin"} {
+		require.NoError(t, os.Chtimes(f.JoinPath(p), old, old))
+	}
+
+	fw := &v1alpha1.FileWatch{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "image:fe",
+			Annotations: map[string]string{v1alpha1.AnnotationTargetID: "image:fe"},
+		},
+		Spec: v1alpha1.FileWatchSpec{
+			WatchedPaths: []string{f.JoinPath("src")},
+			Ignores: []v1alpha1.IgnoreDef{
+				{BasePath: f.JoinPath("src", "build")},
+			},
+		},
+	}
+	id := model.TargetID{Type: model.TargetTypeImage, Name: "fe"}
+
+	changed := changedFiles([]*v1alpha1.FileWatch{fw}, shutDownAt)
+	assert.Empty(t, changed[id])
+
+	newer := shutDownAt.Add(time.Minute)
+	require.NoError(t, os.Chtimes(f.JoinPath("src", "util.go"), newer, newer))
+	require.NoError(t, os.Chtimes(f.JoinPath("src", "build", "out.bin"), newer, newer))
+
+	changed = changedFiles([]*v1alpha1.FileWatch{fw}, shutDownAt)
+	assert.Equal(t, []string{f.JoinPath("src", "util.go")}, changed[id])
+}
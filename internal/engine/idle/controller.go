@@ -0,0 +1,383 @@
+package idle
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/tilt-dev/tilt/internal/controllers/apis/uibutton"
+	"github.com/tilt-dev/tilt/internal/hud"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// How often to check whether Tilt is idle.
+const pollInterval = 10 * time.Second
+
+// After disabling resources, how long to give the reconcilers
+// to delete their workloads before exiting.
+const exitGracePeriod = 30 * time.Second
+
+// If some resources never report that they're disabled,
+// exit anyway after this long.
+const exitTimeout = 5 * time.Minute
+
+// Shuts down dev environments that nobody is using, so that they don't
+// hold on to shared cluster capacity overnight.
+//
+// Opt-in with idle_settings(timeout=...). Tilt is idle when nobody has
+// changed a file, triggered a resource, or clicked in the UI. When it's
+// been idle for the timeout, we disable every resource, which deletes its
+// workloads, and optionally exit.
+//
+// If Tilt stays up, the Resume button brings the resources back. We save
+// their last builds, so resuming redeploys the same images instead of
+// rebuilding them, unless their files changed in the meantime.
+type Controller struct {
+	client ctrlclient.Client
+	clock  clockwork.Clock
+
+	// The last activity that we've warned about and shut down for,
+	// so that we only do each once per idle period.
+	warnedFor   time.Time
+	shutDownFor time.Time
+
+	// When we disabled all resources, if we still need to exit.
+	exitPendingSince time.Time
+
+	// The shutdown whose resources we've seen disabled, the shutdown that
+	// we've resumed from, and the resume that we've reported as done, so
+	// that we only do each once. Only used by OnChange.
+	sawDisabledFor time.Time
+	resumedFor     time.Time
+	doneFor        time.Time
+
+	// How many resources we've reported as resumed.
+	resumedCount int
+}
+
+var _ store.Subscriber = &Controller{}
+var _ store.SetUpper = &Controller{}
+
+func NewController(client ctrlclient.Client, clock clockwork.Clock) *Controller {
+	return &Controller{
+		client: client,
+		clock:  clock,
+	}
+}
+
+func (c *Controller) SetUp(ctx context.Context, st store.RStore) error {
+	go func() {
+		for {
+			select {
+			case <-c.clock.After(pollInterval):
+				c.poll(ctx, st)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+func (c *Controller) OnChange(ctx context.Context, st store.RStore, _ store.ChangeSummary) error {
+	state := st.RLockState()
+	idle := state.Idle
+	disabled := anyDisabled(state, idle.Resources)
+	if idle.IsShutDown() && disabled {
+		c.sawDisabledFor = idle.ShutDownAt
+	}
+
+	// Resume when someone clicks the button, or when someone re-enables
+	// the resources another way (e.g., `tilt enable --all`).
+	shouldResume := idle.IsShutDown() && !c.resumedFor.Equal(idle.ShutDownAt) &&
+		(resumeClicked(state) || (c.sawDisabledFor.Equal(idle.ShutDownAt) && !disabled))
+	var fws []*v1alpha1.FileWatch
+	var sources []v1alpha1.ConfigMapDisableSource
+	if shouldResume {
+		fws = snapshotFileWatches(state)
+		_, sources = disableSources(state, func(uir *v1alpha1.UIResource) bool {
+			return containsName(idle.Resources, model.ManifestName(uir.Name))
+		})
+	}
+	resumed := len(state.IdleResumedResources())
+	st.RUnlockState()
+
+	if shouldResume {
+		c.resumedFor = idle.ShutDownAt
+		c.resumedCount = 0
+		c.resume(ctx, st, idle.ShutDownAt, fws, sources)
+		return nil
+	}
+
+	if !idle.IsResuming() || c.doneFor.Equal(idle.ResumeStartTime) {
+		return nil
+	}
+
+	total := len(idle.Resuming)
+	if resumed > c.resumedCount && resumed < total {
+		logger.Get(ctx).Infof("Resumed %d/%d resources", resumed, total)
+	}
+	c.resumedCount = resumed
+
+	if resumed == total {
+		c.doneFor = idle.ResumeStartTime
+		st.Dispatch(ResumeDoneAction{StartTime: idle.ResumeStartTime, FinishTime: c.clock.Now()})
+	}
+	return nil
+}
+
+// Brings back the resources that we disabled.
+//
+// Dispatches ResumeAction before re-enabling anything, so that the saved
+// build results are in place before the resources start building.
+func (c *Controller) resume(ctx context.Context, st store.RStore, shutDownAt time.Time,
+	fws []*v1alpha1.FileWatch, sources []v1alpha1.ConfigMapDisableSource) {
+	st.Dispatch(ResumeAction{
+		StartTime:    c.clock.Now(),
+		ChangedFiles: changedFiles(fws, shutDownAt),
+	})
+
+	l := logger.Get(ctx)
+	err := c.setDisabled(ctx, sources, false)
+	if err != nil {
+		l.Errorf("Resuming idle resources: %v", err)
+	}
+
+	err = c.client.Delete(ctx, uibutton.IdleResumeButton())
+	if err != nil && !apierrors.IsNotFound(err) {
+		l.Debugf("Deleting %s button: %v", uibutton.IdleResumeButtonName, err)
+	}
+}
+
+func (c *Controller) poll(ctx context.Context, st store.RStore) {
+	state := st.RLockState()
+	settings := state.IdleSettings
+	lastActivity := LastActivity(state)
+	names, sources := disableSources(state, func(uir *v1alpha1.UIResource) bool {
+		return uir.Status.DisableStatus.State != v1alpha1.DisableStateDisabled
+	})
+	allDisabled := allResourcesDisabled(state)
+	st.RUnlockState()
+
+	if !settings.Enabled() {
+		c.exitPendingSince = time.Time{}
+		return
+	}
+
+	now := c.clock.Now()
+	idleFor := now.Sub(lastActivity)
+	l := logger.Get(ctx)
+
+	if !c.exitPendingSince.IsZero() {
+		if !c.shutDownFor.Equal(lastActivity) {
+			l.Infof("Tilt is no longer idle; not exiting")
+			c.exitPendingSince = time.Time{}
+		} else if settings.Exit && shouldExit(now.Sub(c.exitPendingSince), allDisabled) {
+			l.Infof("Exiting Tilt because it was idle for %s", settings.Timeout)
+			c.exitPendingSince = time.Time{}
+			st.Dispatch(hud.NewExitAction(nil))
+			return
+		}
+	}
+
+	if idleFor >= settings.Timeout {
+		if c.shutDownFor.Equal(lastActivity) {
+			return
+		}
+		c.shutDownFor = lastActivity
+
+		if settings.Exit {
+			l.Warnf("Tilt has been idle for %s. Disabling all resources to free up cluster capacity.", settings.Timeout)
+		} else {
+			l.Warnf("Tilt has been idle for %s. Disabling all resources to free up cluster capacity. "+
+				"Click Resume in the Tilt UI to bring them back.", settings.Timeout)
+		}
+
+		st.Dispatch(ShutdownAction{ShutDownAt: now, Resources: names})
+		err := c.setDisabled(ctx, sources, true)
+		if err != nil {
+			l.Errorf("Disabling idle resources: %v", err)
+			return
+		}
+		if settings.Exit {
+			c.exitPendingSince = now
+			return
+		}
+
+		err = c.client.Create(ctx, uibutton.IdleResumeButton())
+		if err != nil && !apierrors.IsAlreadyExists(err) {
+			l.Errorf("Creating %s button: %v", uibutton.IdleResumeButtonName, err)
+		}
+		return
+	}
+
+	if settings.WarnBefore > 0 && idleFor >= settings.Timeout-settings.WarnBefore && !c.warnedFor.Equal(lastActivity) {
+		c.warnedFor = lastActivity
+		action := "disable all resources"
+		if settings.Exit {
+			action = "disable all resources and exit"
+		}
+		l.Warnf("Tilt has been idle for %s. In %s, Tilt will %s, unless you change a file, "+
+			"trigger a resource, or click in the Tilt UI.",
+			idleFor.Round(time.Minute), (settings.Timeout - idleFor).Round(time.Minute), action)
+	}
+}
+
+// Wait for the reconcilers to delete disabled workloads before exiting.
+func shouldExit(sinceDisable time.Duration, allDisabled bool) bool {
+	if sinceDisable >= exitTimeout {
+		return true
+	}
+	return allDisabled && sinceDisable >= exitGracePeriod
+}
+
+// Disable or enable resources the same way as `tilt disable --all`.
+func (c *Controller) setDisabled(ctx context.Context, sources []v1alpha1.ConfigMapDisableSource, disabled bool) error {
+	for _, source := range sources {
+		cm := &v1alpha1.ConfigMap{}
+		cm.Name = source.Name
+		_, err := controllerutil.CreateOrUpdate(ctx, c.client, cm, func() error {
+			if cm.Data == nil {
+				cm.Data = make(map[string]string)
+			}
+			cm.Data[source.Key] = strconv.FormatBool(disabled)
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("updating %s: %v", source.Name, err)
+		}
+	}
+	return nil
+}
+
+// The ConfigMaps that disable each matching resource.
+func disableSources(state store.EngineState, include func(uir *v1alpha1.UIResource) bool) ([]model.ManifestName, []v1alpha1.ConfigMapDisableSource) {
+	var names []model.ManifestName
+	var sources []v1alpha1.ConfigMapDisableSource
+	for _, uir := range state.UIResources {
+		if !include(uir) {
+			continue
+		}
+		found := false
+		for _, source := range uir.Status.DisableStatus.Sources {
+			if source.ConfigMap != nil {
+				sources = append(sources, *source.ConfigMap)
+				found = true
+			}
+		}
+		if found {
+			names = append(names, model.ManifestName(uir.Name))
+		}
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names, sources
+}
+
+func anyDisabled(state store.EngineState, names []model.ManifestName) bool {
+	for _, mn := range names {
+		uir, ok := state.UIResources[mn.String()]
+		if ok && uir.Status.DisableStatus.State == v1alpha1.DisableStateDisabled {
+			return true
+		}
+	}
+	return false
+}
+
+func resumeClicked(state store.EngineState) bool {
+	b, ok := state.UIButtons[uibutton.IdleResumeButtonName]
+	return ok && b.Status.LastClickedAt.Time.After(state.Idle.ShutDownAt)
+}
+
+// The FileWatches of the resources whose builds we saved.
+func snapshotFileWatches(state store.EngineState) []*v1alpha1.FileWatch {
+	var result []*v1alpha1.FileWatch
+	for _, fw := range state.FileWatches {
+		id, ok := fileWatchTargetID(fw)
+		if !ok {
+			continue
+		}
+		for _, mn := range state.ManifestNamesForTargetID(id) {
+			if _, ok := state.Idle.Snapshots[mn]; ok {
+				result = append(result, fw.DeepCopy())
+				break
+			}
+		}
+	}
+	return result
+}
+
+func containsName(names []model.ManifestName, name model.ManifestName) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func allResourcesDisabled(state store.EngineState) bool {
+	for _, uir := range state.UIResources {
+		ds := uir.Status.DisableStatus
+		if len(ds.Sources) > 0 && ds.State != v1alpha1.DisableStateDisabled {
+			return false
+		}
+	}
+	return true
+}
+
+// LastActivity returns the last time that someone used Tilt: when Tilt
+// started, a file changed, a build was triggered, or a button was clicked.
+//
+// Builds that Tilt starts on its own (e.g., the first build of each
+// resource) don't count, so that Tilt can't keep itself awake.
+func LastActivity(state store.EngineState) time.Time {
+	last := state.TiltStartTime
+	update := func(t time.Time) {
+		if t.After(last) {
+			last = t
+		}
+	}
+
+	for _, fw := range state.FileWatches {
+		update(fw.Status.LastEventTime.Time)
+	}
+	for _, b := range state.UIButtons {
+		update(b.Status.LastClickedAt.Time)
+	}
+
+	updateFromBuilds := func(ms *store.ManifestState) {
+		for _, b := range ms.CurrentBuilds {
+			if isUserActivity(b.Reason) {
+				update(b.StartTime)
+			}
+		}
+		for _, b := range ms.BuildHistory {
+			if isUserActivity(b.Reason) {
+				update(b.StartTime)
+			}
+		}
+	}
+	for _, mt := range state.ManifestTargets {
+		updateFromBuilds(mt.State)
+	}
+	for _, ms := range state.TiltfileStates {
+		updateFromBuilds(ms)
+	}
+	return last
+}
+
+func isUserActivity(reason model.BuildReason) bool {
+	return reason.HasTrigger() ||
+		reason.Has(model.BuildReasonFlagChangedFiles) ||
+		reason.Has(model.BuildReasonFlagTiltfileArgs)
+}
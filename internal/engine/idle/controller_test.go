@@ -0,0 +1,329 @@
+package idle
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/tilt-dev/tilt/internal/controllers/apis/uibutton"
+	"github.com/tilt-dev/tilt/internal/controllers/fake"
+	"github.com/tilt-dev/tilt/internal/hud"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestDisabledByDefault(t *testing.T) {
+	f := newFixture(t)
+	f.clock.Advance(48 * time.Hour)
+	f.poll()
+	assert.Empty(t, f.out.String())
+	assert.False(t, f.isDisabled())
+}
+
+func TestWarnThenDisable(t *testing.T) {
+	f := newFixture(t)
+	f.setSettings(model.IdleSettings{Timeout: 8 * time.Hour, WarnBefore: 30 * time.Minute})
+
+	f.clock.Advance(7 * time.Hour)
+	f.poll()
+	assert.Empty(t, f.out.String())
+
+	f.clock.Advance(40 * time.Minute)
+	f.poll()
+	assert.Contains(t, f.out.String(), "Tilt has been idle for 7h40m0s. In 20m0s, Tilt will disable all resources")
+	assert.False(t, f.isDisabled())
+
+	// Only warn once.
+	f.out.Reset()
+	f.clock.Advance(10 * time.Minute)
+	f.poll()
+	assert.Empty(t, f.out.String())
+
+	f.clock.Advance(10 * time.Minute)
+	f.poll()
+	assert.Contains(t, f.out.String(), "Disabling all resources")
+	assert.True(t, f.isDisabled())
+	store.AssertNoActionOfType(t, reflect.TypeOf(hud.ExitAction{}), f.st.Actions)
+}
+
+func TestActivityResetsTimer(t *testing.T) {
+	f := newFixture(t)
+	f.setSettings(model.IdleSettings{Timeout: time.Hour})
+
+	f.clock.Advance(50 * time.Minute)
+	f.fileChanged()
+
+	f.clock.Advance(50 * time.Minute)
+	f.poll()
+	assert.False(t, f.isDisabled())
+
+	f.clock.Advance(10 * time.Minute)
+	f.poll()
+	assert.True(t, f.isDisabled())
+}
+
+func TestExitAfterDisable(t *testing.T) {
+	f := newFixture(t)
+	f.setSettings(model.IdleSettings{Timeout: time.Hour, Exit: true})
+
+	f.clock.Advance(time.Hour)
+	f.poll()
+	assert.True(t, f.isDisabled())
+
+	// Give the reconcilers time to delete workloads.
+	f.poll()
+	store.AssertNoActionOfType(t, reflect.TypeOf(hud.ExitAction{}), f.st.Actions)
+
+	f.markResourceDisabled()
+	f.clock.Advance(exitGracePeriod)
+	f.poll()
+	a := store.WaitForAction(t, reflect.TypeOf(hud.ExitAction{}), f.st.Actions)
+	assert.Nil(t, a.(hud.ExitAction).Err)
+}
+
+func TestActivityCancelsExit(t *testing.T) {
+	f := newFixture(t)
+	f.setSettings(model.IdleSettings{Timeout: time.Hour, Exit: true})
+
+	f.clock.Advance(time.Hour)
+	f.poll()
+	assert.True(t, f.isDisabled())
+
+	f.buttonClicked()
+	f.clock.Advance(exitTimeout)
+	f.poll()
+	assert.Contains(t, f.out.String(), "no longer idle")
+	store.AssertNoActionOfType(t, reflect.TypeOf(hud.ExitAction{}), f.st.Actions)
+}
+
+func TestShutdownCreatesResumeButton(t *testing.T) {
+	f := newFixture(t)
+	f.setSettings(model.IdleSettings{Timeout: time.Hour})
+
+	f.clock.Advance(time.Hour)
+	f.poll()
+	assert.True(t, f.isDisabled())
+
+	a := store.WaitForAction(t, reflect.TypeOf(ShutdownAction{}), f.st.Actions)
+	assert.Equal(t, []model.ManifestName{"fe"}, a.(ShutdownAction).Resources)
+	assert.True(t, f.hasResumeButton())
+}
+
+func TestResumeButton(t *testing.T) {
+	f := newFixture(t)
+	f.setSettings(model.IdleSettings{Timeout: time.Hour})
+
+	f.clock.Advance(time.Hour)
+	f.poll()
+	f.shutDown()
+
+	// Nothing happens until someone clicks Resume.
+	f.onChange()
+	store.AssertNoActionOfType(t, reflect.TypeOf(ResumeAction{}), f.st.Actions)
+	assert.True(t, f.isDisabled())
+
+	f.clock.Advance(time.Minute)
+	f.resumeClicked()
+	f.onChange()
+
+	a := store.WaitForAction(t, reflect.TypeOf(ResumeAction{}), f.st.Actions)
+	assert.Equal(t, f.clock.Now(), a.(ResumeAction).StartTime)
+	assert.False(t, f.isDisabled())
+	assert.False(t, f.hasResumeButton())
+}
+
+func TestResumeAfterManualEnable(t *testing.T) {
+	f := newFixture(t)
+	f.setSettings(model.IdleSettings{Timeout: time.Hour})
+
+	f.clock.Advance(time.Hour)
+	f.poll()
+	f.shutDown()
+	f.onChange()
+
+	// e.g., `tilt enable --all`
+	f.markResourceEnabled()
+	f.onChange()
+
+	store.WaitForAction(t, reflect.TypeOf(ResumeAction{}), f.st.Actions)
+	assert.False(t, f.hasResumeButton())
+}
+
+func TestResumeDone(t *testing.T) {
+	f := newFixture(t)
+	start := f.clock.Now()
+
+	state := f.st.LockMutableStateForTesting()
+	state.Idle = store.IdleState{ResumeStartTime: start, Resuming: []model.ManifestName{"fe"}}
+	mt := store.NewManifestTarget(model.Manifest{Name: "fe"}.WithDeployTarget(model.LocalTarget{}))
+	mt.State.DisableState = v1alpha1.DisableStateEnabled
+	state.UpsertManifestTarget(mt)
+	f.st.UnlockMutableState()
+
+	f.onChange()
+	store.AssertNoActionOfType(t, reflect.TypeOf(ResumeDoneAction{}), f.st.Actions)
+
+	state = f.st.LockMutableStateForTesting()
+	state.ManifestTargets["fe"].State.AddCompletedBuild(model.BuildRecord{
+		StartTime:  start.Add(time.Second),
+		FinishTime: start.Add(2 * time.Second),
+	})
+	f.st.UnlockMutableState()
+
+	f.clock.Advance(time.Minute)
+	f.onChange()
+	a := store.WaitForAction(t, reflect.TypeOf(ResumeDoneAction{}), f.st.Actions)
+	assert.Equal(t, ResumeDoneAction{StartTime: start, FinishTime: f.clock.Now()}, a)
+}
+
+func TestLastActivityIgnoresAutomaticBuilds(t *testing.T) {
+	start := time.Now()
+	state := store.NewState()
+	state.TiltStartTime = start
+
+	m := model.Manifest{Name: "fe"}
+	mt := store.NewManifestTarget(m)
+	mt.State.AddCompletedBuild(model.BuildRecord{
+		StartTime: start.Add(time.Hour),
+		Reason:    model.BuildReasonFlagInit,
+	})
+	state.UpsertManifestTarget(mt)
+	assert.Equal(t, start, LastActivity(*state))
+
+	mt.State.AddCompletedBuild(model.BuildRecord{
+		StartTime: start.Add(2 * time.Hour),
+		Reason:    model.BuildReasonFlagTriggerWeb,
+	})
+	assert.Equal(t, start.Add(2*time.Hour), LastActivity(*state))
+}
+
+type fixture struct {
+	t      *testing.T
+	ctx    context.Context
+	out    *bytes.Buffer
+	clock  clockwork.FakeClock
+	client ctrlclient.Client
+	st     *store.TestingStore
+	c      *Controller
+}
+
+func newFixture(t *testing.T) *fixture {
+	out := &bytes.Buffer{}
+	ctx := logger.WithLogger(context.Background(), logger.NewTestLogger(out))
+	clock := clockwork.NewFakeClock()
+	client := fake.NewFakeTiltClient()
+
+	st := store.NewTestingStore()
+	state := st.LockMutableStateForTesting()
+	state.TiltStartTime = clock.Now()
+	state.UIResources = map[string]*v1alpha1.UIResource{
+		"fe": {
+			ObjectMeta: metav1.ObjectMeta{Name: "fe"},
+			Status: v1alpha1.UIResourceStatus{
+				DisableStatus: v1alpha1.DisableResourceStatus{
+					State: v1alpha1.DisableStateEnabled,
+					Sources: []v1alpha1.DisableSource{
+						{ConfigMap: &v1alpha1.ConfigMapDisableSource{Name: "fe-disable", Key: "isDisabled"}},
+					},
+				},
+			},
+		},
+	}
+	st.UnlockMutableState()
+
+	return &fixture{
+		t:      t,
+		ctx:    ctx,
+		out:    out,
+		clock:  clock,
+		client: client,
+		st:     st,
+		c:      NewController(client, clock),
+	}
+}
+
+func (f *fixture) setSettings(settings model.IdleSettings) {
+	state := f.st.LockMutableStateForTesting()
+	state.IdleSettings = settings
+	f.st.UnlockMutableState()
+}
+
+func (f *fixture) fileChanged() {
+	state := f.st.LockMutableStateForTesting()
+	state.FileWatches = map[string]*v1alpha1.FileWatch{
+		"fe": {Status: v1alpha1.FileWatchStatus{LastEventTime: metav1.NewMicroTime(f.clock.Now())}},
+	}
+	f.st.UnlockMutableState()
+}
+
+func (f *fixture) buttonClicked() {
+	state := f.st.LockMutableStateForTesting()
+	state.UIButtons = map[string]*v1alpha1.UIButton{
+		"fe-enable": {Status: v1alpha1.UIButtonStatus{LastClickedAt: metav1.NewMicroTime(f.clock.Now())}},
+	}
+	f.st.UnlockMutableState()
+}
+
+func (f *fixture) markResourceDisabled() {
+	state := f.st.LockMutableStateForTesting()
+	state.UIResources["fe"].Status.DisableStatus.State = v1alpha1.DisableStateDisabled
+	f.st.UnlockMutableState()
+}
+
+func (f *fixture) markResourceEnabled() {
+	state := f.st.LockMutableStateForTesting()
+	state.UIResources["fe"].Status.DisableStatus.State = v1alpha1.DisableStateEnabled
+	f.st.UnlockMutableState()
+}
+
+// Simulates the reducers handling the shutdown: the resource is disabled
+// and the state remembers it.
+func (f *fixture) shutDown() {
+	state := f.st.LockMutableStateForTesting()
+	state.Idle = store.IdleState{ShutDownAt: f.clock.Now(), Resources: []model.ManifestName{"fe"}}
+	state.UIResources["fe"].Status.DisableStatus.State = v1alpha1.DisableStateDisabled
+	f.st.UnlockMutableState()
+}
+
+func (f *fixture) resumeClicked() {
+	state := f.st.LockMutableStateForTesting()
+	state.UIButtons = map[string]*v1alpha1.UIButton{
+		uibutton.IdleResumeButtonName: {Status: v1alpha1.UIButtonStatus{LastClickedAt: metav1.NewMicroTime(f.clock.Now())}},
+	}
+	f.st.UnlockMutableState()
+}
+
+func (f *fixture) onChange() {
+	err := f.c.OnChange(f.ctx, f.st, store.ChangeSummary{})
+	require.NoError(f.t, err)
+}
+
+func (f *fixture) hasResumeButton() bool {
+	var b v1alpha1.UIButton
+	err := f.client.Get(f.ctx, types.NamespacedName{Name: uibutton.IdleResumeButtonName}, &b)
+	return err == nil
+}
+
+func (f *fixture) poll() {
+	f.c.poll(f.ctx, f.st)
+}
+
+func (f *fixture) isDisabled() bool {
+	var cm v1alpha1.ConfigMap
+	err := f.client.Get(f.ctx, types.NamespacedName{Name: "fe-disable"}, &cm)
+	if err != nil {
+		return false
+	}
+	return cm.Data["isDisabled"] == "true"
+}
@@ -72,6 +72,8 @@ func (m *PodMonitor) diff(st store.RStore) []podStatus {
 	return updates
 }
 
+func (m *PodMonitor) CrashRecoverable() {}
+
 func (m *PodMonitor) OnChange(ctx context.Context, st store.RStore, _ store.ChangeSummary) error {
 	updates := m.diff(st)
 	for _, update := range updates {
@@ -60,8 +60,12 @@ func (ks *watcherKnownState) createTaskList(state store.EngineState) watcherTask
 			continue
 		}
 
-		// TODO(milas): read the Cluster object name from the spec once available
-		clusterNN := types.NamespacedName{Name: v1alpha1.ClusterNameDefault}
+		clusterNN := types.NamespacedName{Name: mt.Manifest.ClusterName()}
+		cfgNS := ks.cfgNS
+		if clusterNN.Name != v1alpha1.ClusterNameDefault {
+			// The namespace of the kubeconfig only applies to the default cluster.
+			cfgNS = clusterNamespaceFromStatus(state.Clusters[clusterNN.Name])
+		}
 
 		name := mt.Manifest.Name
 
@@ -71,7 +75,7 @@ func (ks *watcherKnownState) createTaskList(state store.EngineState) watcherTask
 			for _, ref := range applyFilter.DeployedRefs {
 				namespace := k8s.Namespace(ref.Namespace)
 				if namespace == "" {
-					namespace = ks.cfgNS
+					namespace = cfgNS
 				}
 				if namespace == "" {
 					namespace = k8s.DefaultNamespace
@@ -169,3 +173,11 @@ func (w watcherClientKey) GetName() string {
 func (w watcherClientKey) GetNamespace() string {
 	return "tilt-engine"
 }
+
+// The default namespace of a cluster, as resolved by the Cluster reconciler.
+func clusterNamespaceFromStatus(cluster *v1alpha1.Cluster) k8s.Namespace {
+	if cluster == nil || cluster.Status.Connection == nil || cluster.Status.Connection.Kubernetes == nil {
+		return ""
+	}
+	return k8s.Namespace(cluster.Status.Connection.Kubernetes.Namespace)
+}
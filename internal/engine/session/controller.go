@@ -50,6 +50,8 @@ func NewController(cli ctrlclient.Client, engineMode store.EngineMode) *Controll
 	}
 }
 
+func (c *Controller) CrashRecoverable() {}
+
 func (c *Controller) OnChange(ctx context.Context, st store.RStore, summary store.ChangeSummary) error {
 	if summary.IsLogOnly() {
 		return nil
@@ -217,6 +217,9 @@ func genericRuntimeTarget(mt *store.ManifestTarget, holds buildcontrol.HoldSet)
 func runtimeTarget(mt *store.ManifestTarget, holds buildcontrol.HoldSet) *session.Target {
 	if mt.Manifest.IsK8s() {
 		return k8sRuntimeTarget(mt)
+	} else if mt.Manifest.IsLocal() && mt.Manifest.LocalTarget().IsExternal() {
+		// external resources have a health check, but no process
+		return genericRuntimeTarget(mt, holds)
 	} else if mt.Manifest.IsLocal() {
 		return localServeTarget(mt, holds)
 	} else {
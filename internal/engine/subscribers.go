@@ -4,8 +4,12 @@ import (
 	"github.com/tilt-dev/tilt/internal/cloud"
 	"github.com/tilt-dev/tilt/internal/controllers"
 	"github.com/tilt-dev/tilt/internal/engine/analytics"
+	"github.com/tilt-dev/tilt/internal/engine/bandwidth"
 	"github.com/tilt-dev/tilt/internal/engine/configs"
+	"github.com/tilt-dev/tilt/internal/engine/crashreport"
 	"github.com/tilt-dev/tilt/internal/engine/dockerprune"
+	"github.com/tilt-dev/tilt/internal/engine/external"
+	"github.com/tilt-dev/tilt/internal/engine/idle"
 	"github.com/tilt-dev/tilt/internal/engine/k8srollout"
 	"github.com/tilt-dev/tilt/internal/engine/k8swatch"
 	"github.com/tilt-dev/tilt/internal/engine/local"
@@ -14,6 +18,7 @@ import (
 	"github.com/tilt-dev/tilt/internal/engine/telemetry"
 	"github.com/tilt-dev/tilt/internal/engine/uiresource"
 	"github.com/tilt-dev/tilt/internal/engine/uisession"
+	"github.com/tilt-dev/tilt/internal/engine/versioncheck"
 	"github.com/tilt-dev/tilt/internal/hud"
 	"github.com/tilt-dev/tilt/internal/hud/prompt"
 	"github.com/tilt-dev/tilt/internal/hud/server"
@@ -64,6 +69,11 @@ func ProvideSubscribers(
 	sc *session.Controller,
 	uss *uisession.Subscriber,
 	urs *uiresource.Subscriber,
+	cr *crashreport.Reporter,
+	vc *versioncheck.Controller,
+	ic *idle.Controller,
+	bwc *bandwidth.Controller,
+	ehm *external.HealthMonitor,
 ) []store.Subscriber {
 	apiSubscribers := ProvideSubscribersAPIOnly(hudsc, tscm, cb, ts)
 
@@ -86,6 +96,11 @@ func ProvideSubscribers(
 		sc,
 		uss,
 		urs,
+		cr,
+		vc,
+		ic,
+		bwc,
+		ehm,
 	}
 	return append(apiSubscribers, legacySubscribers...)
 }
@@ -33,6 +33,8 @@ func (s *Subscriber) currentResources(store store.RStore, disableSources map[str
 	return webview.ToUIResourceList(state, disableSources)
 }
 
+func (s *Subscriber) CrashRecoverable() {}
+
 func (s *Subscriber) OnChange(ctx context.Context, st store.RStore, summary store.ChangeSummary) error {
 	if summary.IsLogOnly() {
 		return nil
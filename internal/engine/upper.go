@@ -12,9 +12,12 @@ import (
 	tiltanalytics "github.com/tilt-dev/tilt/internal/analytics"
 	"github.com/tilt-dev/tilt/internal/controllers/core/filewatch"
 	ctrltiltfile "github.com/tilt-dev/tilt/internal/controllers/core/tiltfile"
+	"github.com/tilt-dev/tilt/internal/engine/external"
+	"github.com/tilt-dev/tilt/internal/engine/idle"
 	"github.com/tilt-dev/tilt/internal/engine/k8swatch"
 	"github.com/tilt-dev/tilt/internal/engine/local"
 	"github.com/tilt-dev/tilt/internal/engine/session"
+	"github.com/tilt-dev/tilt/internal/engine/versioncheck"
 	"github.com/tilt-dev/tilt/internal/hud"
 	"github.com/tilt-dev/tilt/internal/hud/prompt"
 	"github.com/tilt-dev/tilt/internal/hud/server"
@@ -35,8 +38,10 @@ import (
 	"github.com/tilt-dev/tilt/internal/store/uibuttons"
 	"github.com/tilt-dev/tilt/internal/store/uiresources"
 	"github.com/tilt-dev/tilt/internal/token"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
 	"github.com/tilt-dev/tilt/pkg/logger"
 	"github.com/tilt-dev/tilt/pkg/model"
+	"github.com/tilt-dev/tilt/pkg/model/logstore"
 	"github.com/tilt-dev/wmclient/pkg/analytics"
 )
 
@@ -74,6 +79,7 @@ func (u Upper) Start(
 	b model.TiltBuild,
 	fileName string,
 	initTerminalMode store.TerminalMode,
+	logPrefixFormat logstore.PrefixFormat,
 	analyticsUserOpt analytics.Opt,
 	token token.Token,
 	cloudAddress string,
@@ -98,6 +104,7 @@ func (u Upper) Start(
 		Token:            token,
 		CloudAddress:     cloudAddress,
 		TerminalMode:     initTerminalMode,
+		LogPrefixFormat:  logPrefixFormat,
 	})
 }
 
@@ -146,7 +153,7 @@ func upperReducerFn(ctx context.Context, state *store.EngineState, action store.
 		ctrltiltfile.HandleConfigsReloadStarted(ctx, state, action)
 	case ctrltiltfile.ConfigsReloadedAction:
 		ctrltiltfile.HandleConfigsReloaded(ctx, state, action)
-	case server.AppendToTriggerQueueAction:
+	case store.AppendToTriggerQueueAction:
 		state.AppendToTriggerQueue(action.Name, action.Reason)
 	case hud.DumpEngineStateAction:
 		handleDumpEngineStateAction(ctx, state)
@@ -156,8 +163,18 @@ func upperReducerFn(ctx context.Context, state *store.EngineState, action store.
 		handleAnalyticsNudgeSurfacedAction(ctx, state)
 	case store.TiltCloudStatusReceivedAction:
 		handleTiltCloudStatusReceivedAction(state, action)
+	case versioncheck.VersionCheckAction:
+		handleVersionCheckAction(state, action)
+	case idle.ShutdownAction:
+		handleIdleShutdownAction(state, action)
+	case idle.ResumeAction:
+		handleIdleResumeAction(state, action)
+	case idle.ResumeDoneAction:
+		handleIdleResumeDoneAction(state, action)
 	case store.PanicAction:
 		handlePanicAction(state, action)
+	case store.CrashAction:
+		handleCrashAction(state, action)
 	case store.LogAction:
 		handleLogAction(state, action)
 	case session.SessionUpdateStatusAction:
@@ -166,12 +183,16 @@ func upperReducerFn(ctx context.Context, state *store.EngineState, action store.
 		handleSwitchTerminalModeAction(state, action)
 	case server.OverrideTriggerModeAction:
 		handleOverrideTriggerModeAction(ctx, state, action)
+	case server.SetLogPrefixFormatAction:
+		state.LogPrefixFormat = action.Format
 	case local.CmdCreateAction:
 		local.HandleCmdCreateAction(state, action)
 	case local.CmdUpdateStatusAction:
 		local.HandleCmdUpdateStatusAction(state, action)
 	case local.CmdDeleteAction:
 		local.HandleCmdDeleteAction(state, action)
+	case external.HealthAction:
+		external.HandleHealthAction(state, action)
 	case tiltfiles.TiltfileUpsertAction:
 		tiltfiles.HandleTiltfileUpsertAction(state, action)
 	case tiltfiles.TiltfileDeleteAction:
@@ -196,6 +217,8 @@ func upperReducerFn(ctx context.Context, state *store.EngineState, action store.
 		kubernetesapplys.HandleKubernetesApplyUpsertAction(state, action)
 	case kubernetesapplys.KubernetesApplyDeleteAction:
 		kubernetesapplys.HandleKubernetesApplyDeleteAction(state, action)
+	case kubernetesapplys.KubernetesApplyNamespacesApprovedAction:
+		kubernetesapplys.HandleKubernetesApplyNamespacesApprovedAction(state, action)
 	case kubernetesdiscoverys.KubernetesDiscoveryUpsertAction:
 		kubernetesdiscoverys.HandleKubernetesDiscoveryUpsertAction(state, action)
 	case kubernetesdiscoverys.KubernetesDiscoveryDeleteAction:
@@ -288,6 +311,7 @@ func handleInitAction(ctx context.Context, engineState *store.EngineState, actio
 	engineState.CloudAddress = action.CloudAddress
 	engineState.Token = action.Token
 	engineState.TerminalMode = action.TerminalMode
+	engineState.LogPrefixFormat = action.LogPrefixFormat
 }
 
 func handleHudExitAction(state *store.EngineState, action hud.ExitAction) {
@@ -302,6 +326,18 @@ func handlePanicAction(state *store.EngineState, action store.PanicAction) {
 	state.PanicExited = action.Err
 }
 
+func handleCrashAction(state *store.EngineState, action store.CrashAction) {
+	if !state.RecordCrash(action) {
+		return
+	}
+
+	msg := fmt.Sprintf("Internal error in %s: %s\n"+
+		"Tilt recovered and will keep running, but some things may not work until you restart. "+
+		"Please file an issue at https://github.com/tilt-dev/tilt/issues\n",
+		action.Component, action.Message)
+	state.LogStore.Append(store.NewGlobalLogAction(logger.ErrorLvl, []byte(msg)), state.Secrets)
+}
+
 func handleAnalyticsUserOptAction(state *store.EngineState, action store.AnalyticsUserOptAction) {
 	state.AnalyticsUserOpt = action.Opt
 }
@@ -322,6 +358,118 @@ func handleTiltCloudStatusReceivedAction(state *store.EngineState, action store.
 	state.SuggestedTiltVersion = action.SuggestedTiltVersion
 }
 
+// Warn about version skew in the log, but only once per warning,
+// so that the daily re-check doesn't repeat itself.
+func handleVersionCheckAction(state *store.EngineState, action versioncheck.VersionCheckAction) {
+	seen := make(map[string]bool, len(state.VersionCheck.Warnings))
+	for _, w := range state.VersionCheck.Warnings {
+		seen[w] = true
+	}
+	for _, w := range action.Status.Warnings {
+		if seen[w] {
+			continue
+		}
+		state.LogStore.Append(store.NewGlobalLogAction(logger.WarnLvl, []byte(w+"\n")), state.Secrets)
+	}
+	state.VersionCheck = action.Status
+}
+
+// Save the last good build of each resource that we're about to disable,
+// because disabling a resource throws away its build history.
+func handleIdleShutdownAction(state *store.EngineState, action idle.ShutdownAction) {
+	snapshots := make(map[model.ManifestName]store.IdleSnapshot)
+	for _, mn := range action.Resources {
+		mt, ok := state.ManifestTargets[mn]
+		if !ok {
+			continue
+		}
+		ms := mt.State
+		lastBuild := ms.LastBuild()
+		if ms.IsBuilding() || lastBuild.Empty() || lastBuild.Error != nil {
+			continue
+		}
+
+		results := make(map[model.TargetID]store.BuildResult, len(ms.BuildStatuses))
+		clean := true
+		for id, status := range ms.BuildStatuses {
+			if len(status.PendingFileChanges) > 0 || len(status.PendingDependencyChanges) > 0 {
+				clean = false
+				break
+			}
+			if status.LastResult != nil {
+				results[id] = status.LastResult
+			}
+		}
+		if !clean || len(results) == 0 {
+			continue
+		}
+		snapshots[mn] = store.IdleSnapshot{Manifest: mt.Manifest, Results: results}
+	}
+
+	state.Idle = store.IdleState{
+		ShutDownAt: action.ShutDownAt,
+		Resources:  action.Resources,
+		Snapshots:  snapshots,
+	}
+}
+
+// Restore the saved build results, so that the resources redeploy
+// their last images instead of rebuilding them.
+//
+// If the resource changed while it was disabled, we can't trust the old
+// results, so it gets a full build like any newly enabled resource.
+func handleIdleResumeAction(state *store.EngineState, action idle.ResumeAction) {
+	fast := 0
+	for mn, snapshot := range state.Idle.Snapshots {
+		mt, ok := state.ManifestTargets[mn]
+		if !ok || model.ChangesInvalidateBuild(snapshot.Manifest, mt.Manifest) {
+			continue
+		}
+
+		// If someone already re-enabled the resource, it's already building.
+		if mt.State.DisableState != v1alpha1.DisableStateDisabled {
+			continue
+		}
+
+		changed := false
+		for id := range snapshot.Results {
+			if len(action.ChangedFiles[id]) > 0 {
+				changed = true
+				break
+			}
+		}
+		if changed {
+			continue
+		}
+
+		for id, result := range snapshot.Results {
+			mt.State.MutableBuildStatus(id).LastResult = result
+		}
+		fast++
+	}
+
+	msg := fmt.Sprintf("Resuming %d resources that were disabled while Tilt was idle", len(state.Idle.Resources))
+	if fast > 0 {
+		msg += fmt.Sprintf(" (%d from their last build)", fast)
+	}
+	state.LogStore.Append(store.NewGlobalLogAction(logger.InfoLvl, []byte(msg+"\n")), state.Secrets)
+
+	state.Idle = store.IdleState{
+		ResumeStartTime: action.StartTime,
+		Resuming:        state.Idle.Resources,
+	}
+}
+
+func handleIdleResumeDoneAction(state *store.EngineState, action idle.ResumeDoneAction) {
+	if !state.Idle.ResumeStartTime.Equal(action.StartTime) {
+		return
+	}
+	msg := fmt.Sprintf("Resumed %d resources in %s\n",
+		len(state.Idle.Resuming), action.FinishTime.Sub(action.StartTime).Round(time.Second))
+	state.LogStore.Append(store.NewGlobalLogAction(logger.InfoLvl, []byte(msg)), state.Secrets)
+	state.Idle = store.IdleState{}
+}
+
 func handleOverrideTriggerModeAction(ctx context.Context, state *store.EngineState,
 	action server.OverrideTriggerModeAction) {
 	// TODO(maia): in this implementation, overrides do NOT persist across Tiltfile loads
@@ -52,6 +52,8 @@ import (
 	"github.com/tilt-dev/tilt/internal/controllers/core/kubernetesapply"
 	"github.com/tilt-dev/tilt/internal/controllers/core/kubernetesdiscovery"
 	"github.com/tilt-dev/tilt/internal/controllers/core/liveupdate"
+	"github.com/tilt-dev/tilt/internal/controllers/core/localingress"
+	"github.com/tilt-dev/tilt/internal/controllers/core/nestedtilt"
 	"github.com/tilt-dev/tilt/internal/controllers/core/podlogstream"
 	apiportforward "github.com/tilt-dev/tilt/internal/controllers/core/portforward"
 	ctrltiltfile "github.com/tilt-dev/tilt/internal/controllers/core/tiltfile"
@@ -62,9 +64,13 @@ import (
 	"github.com/tilt-dev/tilt/internal/docker"
 	"github.com/tilt-dev/tilt/internal/dockercompose"
 	engineanalytics "github.com/tilt-dev/tilt/internal/engine/analytics"
+	"github.com/tilt-dev/tilt/internal/engine/bandwidth"
 	"github.com/tilt-dev/tilt/internal/engine/buildcontrol"
 	"github.com/tilt-dev/tilt/internal/engine/configs"
+	"github.com/tilt-dev/tilt/internal/engine/crashreport"
 	"github.com/tilt-dev/tilt/internal/engine/dockerprune"
+	"github.com/tilt-dev/tilt/internal/engine/external"
+	"github.com/tilt-dev/tilt/internal/engine/idle"
 	"github.com/tilt-dev/tilt/internal/engine/k8srollout"
 	"github.com/tilt-dev/tilt/internal/engine/k8swatch"
 	"github.com/tilt-dev/tilt/internal/engine/local"
@@ -73,6 +79,7 @@ import (
 	"github.com/tilt-dev/tilt/internal/engine/telemetry"
 	"github.com/tilt-dev/tilt/internal/engine/uiresource"
 	"github.com/tilt-dev/tilt/internal/engine/uisession"
+	"github.com/tilt-dev/tilt/internal/engine/versioncheck"
 	"github.com/tilt-dev/tilt/internal/feature"
 	"github.com/tilt-dev/tilt/internal/hud"
 	"github.com/tilt-dev/tilt/internal/hud/prompt"
@@ -110,6 +117,7 @@ import (
 	"github.com/tilt-dev/tilt/pkg/model"
 	"github.com/tilt-dev/tilt/pkg/model/logstore"
 	"github.com/tilt-dev/wmclient/pkg/analytics"
+	"github.com/tilt-dev/wmclient/pkg/dirs"
 )
 
 var originalWD string
@@ -218,7 +226,11 @@ type fakeBuildAndDeployer struct {
 	calls chan buildAndDeployCall
 
 	completeBuildsManually bool
-	buildCompletionChans   sync.Map // map[string]buildCompletionChannel; close channel at buildCompletionChans[k(targs)] to
+
+	// Set this to simulate builds that spend their whole time building images.
+	buildImagesUntilCompleted bool
+
+	buildCompletionChans sync.Map // map[string]buildCompletionChannel; close channel at buildCompletionChans[k(targs)] to
 	// complete the build started for targs (where k(targs) generates a unique string key for the set of targets)
 
 	buildCount int
@@ -284,11 +296,23 @@ func (b *fakeBuildAndDeployer) nextImageBuildResult(ctx context.Context, iTarget
 func (b *fakeBuildAndDeployer) BuildAndDeploy(ctx context.Context, st store.RStore, specs []model.TargetSpec, state store.BuildStateSet) (brs store.BuildResultSet, err error) {
 	b.t.Helper()
 
-	b.mu.Lock()
-	b.buildCount++
 	buildKey := stringifyTargetIDs(specs)
 	b.registerBuild(buildKey)
 
+	// Wait for the build to complete while building images, so that
+	// a newer change can supersede it before anything is deployed.
+	var imageErr error
+	if b.buildImagesUntilCompleted {
+		imageCtx, endImageBuild := buildcontrol.StartImageBuild(ctx)
+		imageErr = b.waitUntilBuildCompleted(imageCtx, buildKey)
+		if endErr := endImageBuild(); imageErr == nil {
+			imageErr = endErr
+		}
+	}
+
+	b.mu.Lock()
+	b.buildCount++
+
 	if !b.completeBuildsManually {
 		// i.e. we should complete builds automatically: mark the build for completion now,
 		// so we return immediately at the end of BuildAndDeploy.
@@ -314,9 +338,11 @@ func (b *fakeBuildAndDeployer) BuildAndDeploy(ctx context.Context, st store.RSto
 		b.mu.Unlock()
 
 		// block until we know we're supposed to resolve this build
-		err2 := b.waitUntilBuildCompleted(ctx, buildKey)
-		if err == nil {
-			err = err2
+		if !b.buildImagesUntilCompleted {
+			err2 := b.waitUntilBuildCompleted(ctx, buildKey)
+			if err == nil {
+				err = err2
+			}
 		}
 
 		// don't update b.calls until the end, to ensure appropriate actions have been dispatched first
@@ -334,6 +360,9 @@ func (b *fakeBuildAndDeployer) BuildAndDeploy(ctx context.Context, st store.RSto
 	if err != nil {
 		return nil, err
 	}
+	if imageErr != nil {
+		return nil, imageErr
+	}
 
 	iTargets := model.ExtractImageTargets(specs)
 	fakeImageExistsCheck := func(ctx context.Context, iTarget model.ImageTarget, namedTagged reference.NamedTagged) (bool, error) {
@@ -426,10 +455,13 @@ func (b *fakeBuildAndDeployer) updateKubernetesApplyStatus(ctx context.Context,
 	}
 
 	var cluster v1alpha1.Cluster
-	require.NoError(b.t, b.ctrlClient.Get(ctx, types.NamespacedName{Name: clusterName}, &cluster))
+	err := b.ctrlClient.Get(ctx, types.NamespacedName{Name: clusterName}, &cluster)
+	if err != nil {
+		return err
+	}
 
 	nn := types.NamespacedName{Name: kTarg.ID().Name.String()}
-	status := b.kaReconciler.ForceApply(ctx, nn, kTarg.KubernetesApplySpec, &cluster, imageMapSet)
+	status := b.kaReconciler.ForceApply(ctx, nn, kTarg.KubernetesApplySpec, &cluster, imageMapSet, false)
 
 	// We want our fake stub to only propagate apiserver problems.
 	_ = status
@@ -1990,6 +2022,90 @@ func TestHudExitWithError(t *testing.T) {
 	_ = f.WaitForNoExit()
 }
 
+func TestCrashKeepsRunning(t *testing.T) {
+	f := newTestFixture(t)
+	f.Start([]model.Manifest{})
+
+	f.store.Dispatch(store.CrashAction{Component: "fake.Reconciler", Message: "oh no", Time: time.Now()})
+	f.store.Dispatch(store.CrashAction{Component: "fake.Reconciler", Message: "oh no", Time: time.Now()})
+	f.WaitUntil("crash recorded", func(state store.EngineState) bool {
+		return len(state.Crashes) == 1 && state.Crashes[0].Count == 2
+	})
+
+	f.withState(func(state store.EngineState) {
+		assert.Nil(t, state.PanicExited)
+		assert.Nil(t, state.FatalError)
+		assert.Equal(t, 1, strings.Count(state.LogStore.String(), "Internal error in fake.Reconciler: oh no"))
+	})
+}
+
+func TestIdleResumeRestoresLastBuild(t *testing.T) {
+	state := store.NewState()
+	m := manifestbuilder.New(tempdir.NewTempDirFixture(t), "fe").WithK8sYAML(SanchoYAML).Build()
+	mt := store.NewManifestTarget(m)
+	state.UpsertManifestTarget(mt)
+
+	id := m.K8sTarget().ID()
+	result := store.NewK8sDeployResult(id, nil)
+	mt.State.AddCompletedBuild(model.BuildRecord{StartTime: time.Now(), FinishTime: time.Now()})
+	mt.State.MutableBuildStatus(id).LastResult = result
+
+	shutDownAt := time.Now()
+	handleIdleShutdownAction(state, idle.ShutdownAction{ShutDownAt: shutDownAt, Resources: []model.ManifestName{"fe"}})
+	require.Contains(t, state.Idle.Snapshots, model.ManifestName("fe"))
+
+	// Disabling a resource throws away its build history.
+	mt.State.DisableState = v1alpha1.DisableStateDisabled
+	mt.State.BuildHistory = nil
+	mt.State.BuildStatuses = make(map[model.TargetID]*store.BuildStatus)
+
+	handleIdleResumeAction(state, idle.ResumeAction{StartTime: time.Now()})
+	assert.Equal(t, result, mt.State.BuildStatus(id).LastResult)
+	assert.Equal(t, []model.ManifestName{"fe"}, state.Idle.Resuming)
+	assert.Contains(t, state.LogStore.String(), "Resuming 1 resources that were disabled while Tilt was idle (1 from their last build)")
+}
+
+func TestIdleResumeRebuildsChangedResources(t *testing.T) {
+	state := store.NewState()
+	m := manifestbuilder.New(tempdir.NewTempDirFixture(t), "fe").WithK8sYAML(SanchoYAML).Build()
+	mt := store.NewManifestTarget(m)
+	state.UpsertManifestTarget(mt)
+
+	id := m.K8sTarget().ID()
+	mt.State.AddCompletedBuild(model.BuildRecord{StartTime: time.Now(), FinishTime: time.Now()})
+	mt.State.MutableBuildStatus(id).LastResult = store.NewK8sDeployResult(id, nil)
+
+	handleIdleShutdownAction(state, idle.ShutdownAction{ShutDownAt: time.Now(), Resources: []model.ManifestName{"fe"}})
+	mt.State.DisableState = v1alpha1.DisableStateDisabled
+	mt.State.BuildStatuses = make(map[model.TargetID]*store.BuildStatus)
+
+	handleIdleResumeAction(state, idle.ResumeAction{
+		StartTime:    time.Now(),
+		ChangedFiles: map[model.TargetID][]string{id: {"/src/main.go"}},
+	})
+	assert.Nil(t, mt.State.BuildStatus(id).LastResult)
+	assert.NotContains(t, state.LogStore.String(), "from their last build")
+}
+
+func TestVersionCheckWarnsOnce(t *testing.T) {
+	f := newTestFixture(t)
+	f.Start([]model.Manifest{})
+
+	status := store.VersionCheckStatus{
+		LatestVersion: "0.31.0",
+		Warnings:      []string{"Tilt v0.31.0 is available"},
+	}
+	f.store.Dispatch(versioncheck.VersionCheckAction{Status: status})
+	f.store.Dispatch(versioncheck.VersionCheckAction{Status: status})
+	f.WaitUntil("version check recorded", func(state store.EngineState) bool {
+		return state.VersionCheck.LatestVersion == "0.31.0"
+	})
+
+	f.withState(func(state store.EngineState) {
+		assert.Equal(t, 1, strings.Count(state.LogStore.String(), "Tilt v0.31.0 is available"))
+	})
+}
+
 func TestNewConfigsAreWatchedAfterFailure(t *testing.T) {
 	f := newTestFixture(t)
 	f.useRealTiltfileLoader()
@@ -2223,7 +2339,7 @@ func TestEmptyTiltfile(t *testing.T) {
 	closeCh := make(chan error)
 	go func() {
 		err := f.upper.Start(f.ctx, []string{}, model.TiltBuild{},
-			f.JoinPath("Tiltfile"), store.TerminalModeHUD,
+			f.JoinPath("Tiltfile"), store.TerminalModeHUD, logstore.PrefixFormat{},
 			analytics.OptIn, token.Token("unit test token"),
 			"nonexistent.example.com")
 		closeCh <- err
@@ -2258,7 +2374,7 @@ func TestUpperStart(t *testing.T) {
 	f.WriteFile("Tiltfile", "")
 	go func() {
 		err := f.upper.Start(f.ctx, []string{"foo", "bar"}, model.TiltBuild{},
-			f.JoinPath("Tiltfile"), store.TerminalModeHUD,
+			f.JoinPath("Tiltfile"), store.TerminalModeHUD, logstore.PrefixFormat{},
 			analytics.OptIn, tok, cloudAddress)
 		closeCh <- err
 	}()
@@ -3044,7 +3160,7 @@ func TestHandleTiltfileTriggerQueue(t *testing.T) {
 		assert.Equal(t, model.BuildReasonNone, st.MainTiltfileState().TriggerReason,
 			"initial state should not have Tiltfile trigger reason")
 	})
-	action := server.AppendToTriggerQueueAction{Name: model.MainTiltfileManifestName, Reason: 123}
+	action := store.AppendToTriggerQueueAction{Name: model.MainTiltfileManifestName, Reason: 123}
 	f.store.Dispatch(action)
 
 	f.WaitUntil("Tiltfile trigger processed", func(st store.EngineState) bool {
@@ -3199,7 +3315,7 @@ func TestDisabledResourceRemovedFromTriggerQueue(t *testing.T) {
 
 	f.bc.DisableForTesting()
 
-	f.store.Dispatch(server.AppendToTriggerQueueAction{Name: m.Name, Reason: model.BuildReasonFlagTriggerCLI})
+	f.store.Dispatch(store.AppendToTriggerQueueAction{Name: m.Name, Reason: model.BuildReasonFlagTriggerCLI})
 
 	f.WaitUntil("in trigger queue", func(state store.EngineState) bool {
 		return state.ManifestInTriggerQueue(m.Name)
@@ -3336,7 +3452,7 @@ func newTestFixture(t *testing.T, options ...fixtureOptions) *testFixture {
 		tiltextension.NewFakeExtRepoReconciler(f.Path()),
 		tiltextension.NewFakeExtReconciler(f.Path()))
 	realTFL := tiltfile.ProvideTiltfileLoader(ta, k8sContextPlugin, versionPlugin, configPlugin, extPlugin,
-		fakeDcc, "localhost", execer, feature.MainDefaults, env)
+		fakeDcc, kClient, "localhost", execer, feature.MainDefaults, env)
 	tfl := tiltfile.NewFakeTiltfileLoader()
 	cc := configs.NewConfigsController(cdc)
 	tqs := configs.NewTriggerQueueSubscriber(cdc)
@@ -3362,7 +3478,7 @@ func newTestFixture(t *testing.T, options ...fixtureOptions) *testFixture {
 	sessionController := session.NewController(cdc, engineMode)
 	ts := hud.NewTerminalStream(hud.NewIncrementalPrinter(log), st)
 	tp := prompt.NewTerminalPrompt(ta, prompt.TTYOpen, openurl.BrowserOpen,
-		log, "localhost", model.WebURL{})
+		log, "localhost", model.WebURL{}, "")
 	h := hud.NewFakeHud()
 
 	uncached := controllers.UncachedObjects{}
@@ -3380,7 +3496,7 @@ func newTestFixture(t *testing.T, options ...fixtureOptions) *testFixture {
 
 	wsl := server.NewWebsocketList()
 
-	kar := kubernetesapply.NewReconciler(cdc, kClient, sch, docker.Env{}, st, execer)
+	kar := kubernetesapply.NewReconciler(cdc, kClient, sch, docker.Env{}, dockerClient, st, execer, dirs.NewTiltDevDirAt(f.JoinPath(".tilt-dev")))
 	dcds := dockercomposeservice.NewDisableSubscriber(ctx, fakeDcc, clock)
 	dcr := dockercomposeservice.NewReconciler(cdc, fakeDcc, dockerClient, st, sch, dcds)
 
@@ -3393,7 +3509,7 @@ func newTestFixture(t *testing.T, options ...fixtureOptions) *testFixture {
 
 	cu := &containerupdate.FakeContainerUpdater{}
 	lur := liveupdate.NewFakeReconciler(st, cu, cdc)
-	dockerBuilder := build.NewDockerBuilder(dockerClient, nil)
+	dockerBuilder := build.NewDockerBuilder(dockerClient, nil, nil)
 	customBuilder := build.NewCustomBuilder(dockerClient, clock)
 	kp := build.NewKINDLoader()
 	ib := build.NewImageBuilder(dockerBuilder, customBuilder, kp)
@@ -3427,9 +3543,11 @@ func newTestFixture(t *testing.T, options ...fixtureOptions) *testFixture {
 		dcr,
 		imagemap.NewReconciler(cdc, st),
 		dclsr,
+		nestedtilt.NewReconciler(cdc, execer),
+		localingress.NewReconciler(cdc, base),
 	))
 
-	dp := dockerprune.NewDockerPruner(dockerClient)
+	dp := dockerprune.NewDockerPruner(dockerClient, cdc, nil)
 	dp.DisabledForTesting(true)
 
 	b := newFakeBuildAndDeployer(t, kClient, fakeDcc, cdc, kar, dcr)
@@ -3474,7 +3592,7 @@ func newTestFixture(t *testing.T, options ...fixtureOptions) *testFixture {
 	uss := uisession.NewSubscriber(cdc)
 	urs := uiresource.NewSubscriber(cdc)
 
-	subs := ProvideSubscribers(hudsc, tscm, cb, h, ts, tp, sw, bc, cc, tqs, dclm, ar, au, ewm, tcum, dp, tc, lsc, podm, sessionController, uss, urs)
+	subs := ProvideSubscribers(hudsc, tscm, cb, h, ts, tp, sw, bc, cc, tqs, dclm, ar, au, ewm, tcum, dp, tc, lsc, podm, sessionController, uss, urs, crashreport.NewReporter(base), versioncheck.NewController(httptest.NewFakeClientEmptyJSON(), clock), idle.NewController(cdc, clock), bandwidth.NewController(cdc), external.NewHealthMonitor(fpm))
 	ret.upper, err = NewUpper(ctx, st, subs)
 	require.NoError(t, err)
 
@@ -0,0 +1,11 @@
+package versioncheck
+
+import (
+	"github.com/tilt-dev/tilt/internal/store"
+)
+
+type VersionCheckAction struct {
+	Status store.VersionCheckStatus
+}
+
+func (VersionCheckAction) Action() {}
@@ -0,0 +1,103 @@
+package versioncheck
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+
+	"github.com/tilt-dev/tilt/internal/cloud"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+// How often to look for a new release.
+const checkPeriod = 24 * time.Hour
+
+// How long to wait before trying again after an error.
+const retryAfterError = time.Hour
+
+// How long to wait for GitHub before giving up.
+const checkTimeout = 10 * time.Second
+
+// Compares the running Tilt against the latest release, and against
+// the tilt binary that editor integrations use.
+//
+// Opt-in with version_settings(check_latest_release=True), because
+// it makes requests to GitHub.
+type Controller struct {
+	client            cloud.HttpClient
+	clock             clockwork.Clock
+	pathBinaryVersion func(ctx context.Context) (string, string, error)
+
+	mu        sync.Mutex
+	checking  bool
+	nextCheck time.Time
+}
+
+func NewController(client cloud.HttpClient, clock clockwork.Clock) *Controller {
+	return &Controller{
+		client:            client,
+		clock:             clock,
+		pathBinaryVersion: pathBinaryVersion,
+	}
+}
+
+var _ store.Subscriber = &Controller{}
+
+func (c *Controller) OnChange(ctx context.Context, st store.RStore, summary store.ChangeSummary) error {
+	if summary.IsLogOnly() {
+		return nil
+	}
+
+	state := st.RLockState()
+	enabled := state.VersionSettings.CheckLatestRelease
+	running := state.TiltBuildInfo.Version
+	st.RUnlockState()
+
+	if !enabled || running == "" {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.checking || c.clock.Now().Before(c.nextCheck) {
+		return nil
+	}
+	c.checking = true
+
+	go c.check(ctx, st, running)
+	return nil
+}
+
+func (c *Controller) check(ctx context.Context, st store.RStore, running string) {
+	nextCheck := c.clock.Now().Add(checkPeriod)
+	defer func() {
+		c.mu.Lock()
+		c.checking = false
+		c.nextCheck = nextCheck
+		c.mu.Unlock()
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	release, warnings, err := check(ctx, c.client, running, c.pathBinaryVersion)
+	if err != nil {
+		logger.Get(ctx).Debugf("Error checking for Tilt updates: %v", err)
+		nextCheck = c.clock.Now().Add(retryAfterError)
+		return
+	}
+
+	st.Dispatch(VersionCheckAction{
+		Status: store.VersionCheckStatus{
+			LatestVersion:    release.Version,
+			ReleaseURL:       release.URL,
+			UpgradeAvailable: IsOutdated(running, release.Version),
+			Highlights:       release.Highlights,
+			Warnings:         warnings,
+			CheckedAt:        c.clock.Now(),
+		},
+	})
+}
@@ -0,0 +1,113 @@
+package versioncheck
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/internal/testutils"
+	"github.com/tilt-dev/tilt/internal/testutils/httptest"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestDisabledByDefault(t *testing.T) {
+	f := newFixture(t)
+	f.onChange()
+	assert.Empty(t, f.client.Requests())
+}
+
+func TestCheckLatestRelease(t *testing.T) {
+	f := newFixture(t)
+	f.enable()
+	f.onChange()
+
+	status := f.waitForStatus()
+	assert.Equal(t, "0.31.0", status.LatestVersion)
+	assert.True(t, status.UpgradeAvailable)
+	assert.Equal(t, []string{"abc123 Faster builds", "def456 Better logs"}, status.Highlights)
+	require.Len(t, status.Warnings, 2)
+	assert.Contains(t, status.Warnings[0], "Tilt v0.31.0 is available")
+	assert.Contains(t, status.Warnings[1], "/opt/bin/tilt")
+
+	// Don't check again until the period is up.
+	f.onChange()
+	assert.Len(t, f.client.Requests(), 1)
+
+	f.clock.Advance(checkPeriod)
+	f.onChange()
+	f.waitForStatus()
+	assert.Len(t, f.client.Requests(), 2)
+}
+
+type fixture struct {
+	t      *testing.T
+	ctx    context.Context
+	clock  clockwork.FakeClock
+	client *httptest.FakeClient
+	st     *store.TestingStore
+	c      *Controller
+}
+
+func newFixture(t *testing.T) *fixture {
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	clock := clockwork.NewFakeClock()
+	client := httptest.NewFakeClient()
+	client.SetResponse(releaseJSON)
+
+	st := store.NewTestingStore()
+	state := st.LockMutableStateForTesting()
+	state.TiltBuildInfo = model.TiltBuild{Version: "0.30.7"}
+	st.UnlockMutableState()
+
+	c := NewController(client, clock)
+	c.pathBinaryVersion = func(ctx context.Context) (string, string, error) {
+		return "/opt/bin/tilt", "0.29.0", nil
+	}
+
+	return &fixture{
+		t:      t,
+		ctx:    ctx,
+		clock:  clock,
+		client: client,
+		st:     st,
+		c:      c,
+	}
+}
+
+func (f *fixture) enable() {
+	state := f.st.LockMutableStateForTesting()
+	state.VersionSettings.CheckLatestRelease = true
+	f.st.UnlockMutableState()
+}
+
+func (f *fixture) onChange() {
+	err := f.c.OnChange(f.ctx, f.st, store.LegacyChangeSummary())
+	require.NoError(f.t, err)
+}
+
+func (f *fixture) waitForStatus() store.VersionCheckStatus {
+	var status store.VersionCheckStatus
+	require.Eventually(f.t, func() bool {
+		for _, a := range f.st.Actions() {
+			if action, ok := a.(VersionCheckAction); ok {
+				status = action.Status
+				f.st.ClearActions()
+				return true
+			}
+		}
+		return false
+	}, time.Second, 10*time.Millisecond)
+
+	// Wait for the check to finish.
+	require.Eventually(f.t, func() bool {
+		f.c.mu.Lock()
+		defer f.c.mu.Unlock()
+		return !f.c.checking
+	}, time.Second, 10*time.Millisecond)
+	return status
+}
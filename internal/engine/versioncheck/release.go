@@ -0,0 +1,189 @@
+package versioncheck
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/blang/semver"
+
+	"github.com/tilt-dev/tilt/internal/cloud"
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+const latestReleaseURL = "https://api.github.com/repos/tilt-dev/tilt/releases/latest"
+
+// The most changelog lines to show.
+const maxHighlights = 5
+
+// A published release of Tilt.
+type Release struct {
+	// e.g., "0.31.0"
+	Version string
+
+	// A link to the full release notes.
+	URL string
+
+	// The first few items from the changelog.
+	Highlights []string
+}
+
+type gitHubRelease struct {
+	TagName string `json:"tag_name"`
+	HTMLURL string `json:"html_url"`
+	Body    string `json:"body"`
+}
+
+// Looks up the latest Tilt release on GitHub.
+func FetchLatestRelease(ctx context.Context, client cloud.HttpClient) (Release, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", latestReleaseURL, nil)
+	if err != nil {
+		return Release{}, err
+	}
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return Release{}, fmt.Errorf("fetching latest release: %v", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return Release{}, fmt.Errorf("fetching latest release: status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var r gitHubRelease
+	err = json.NewDecoder(resp.Body).Decode(&r)
+	if err != nil {
+		return Release{}, fmt.Errorf("decoding latest release: %v", err)
+	}
+
+	return Release{
+		Version:    strings.TrimPrefix(r.TagName, "v"),
+		URL:        r.HTMLURL,
+		Highlights: parseHighlights(r.Body),
+	}, nil
+}
+
+// Release notes are markdown. The changelog is a bulleted list.
+func parseHighlights(body string) []string {
+	var result []string
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "* ") && !strings.HasPrefix(line, "- ") {
+			continue
+		}
+		result = append(result, strings.TrimSpace(line[2:]))
+		if len(result) == maxHighlights {
+			break
+		}
+	}
+	return result
+}
+
+// Returns true if the latest version is newer than the running version.
+//
+// Versions that we can't parse (e.g., custom builds) are never out of date.
+func IsOutdated(running, latest string) bool {
+	rv, err := semver.ParseTolerant(running)
+	if err != nil {
+		return false
+	}
+	lv, err := semver.ParseTolerant(latest)
+	if err != nil {
+		return false
+	}
+	return rv.LT(lv)
+}
+
+// Editor integrations (like the VS Code Tiltfile extension) run
+// `tilt lsp` with the tilt binary on the PATH, which may not be
+// the binary that's running now.
+//
+// Returns the path and version of that binary, or an empty path
+// if it's the running binary or there isn't one.
+func pathBinaryVersion(ctx context.Context) (string, string, error) {
+	path, err := exec.LookPath("tilt")
+	if err != nil {
+		return "", "", nil
+	}
+
+	self, err := os.Executable()
+	if err == nil && isSameFile(self, path) {
+		return "", "", nil
+	}
+
+	out, err := exec.CommandContext(ctx, path, "version").Output()
+	if err != nil {
+		return "", "", fmt.Errorf("running %s version: %v", path, err)
+	}
+	return path, parseBuildStamp(string(out)), nil
+}
+
+func isSameFile(a, b string) bool {
+	a, errA := filepath.EvalSymlinks(a)
+	b, errB := filepath.EvalSymlinks(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	return errA == nil && errB == nil && os.SameFile(infoA, infoB)
+}
+
+// Parses the version from the output of `tilt version`,
+// e.g., "v0.30.7, built 2022-08-01" -> "0.30.7"
+func parseBuildStamp(stamp string) string {
+	version := strings.TrimSpace(stamp)
+	if i := strings.Index(version, ","); i != -1 {
+		version = version[:i]
+	}
+	version = strings.TrimSuffix(version, "-dev")
+	return strings.TrimPrefix(version, "v")
+}
+
+// Fetches the latest release, and explains how the running Tilt differs
+// from it and from the tilt binary on the PATH.
+func Check(ctx context.Context, client cloud.HttpClient, running string) (Release, []string, error) {
+	return check(ctx, client, running, pathBinaryVersion)
+}
+
+func check(ctx context.Context, client cloud.HttpClient, running string,
+	pathBinaryVersion func(ctx context.Context) (string, string, error)) (Release, []string, error) {
+	release, err := FetchLatestRelease(ctx, client)
+	if err != nil {
+		return Release{}, nil, err
+	}
+
+	pathBinary, pathVersion, err := pathBinaryVersion(ctx)
+	if err != nil {
+		logger.Get(ctx).Debugf("Error checking tilt version on PATH: %v", err)
+	}
+	return release, skewWarnings(running, release, pathBinary, pathVersion), nil
+}
+
+// Explains how the running Tilt differs from the latest release
+// and from the tilt binary that editors use.
+func skewWarnings(running string, latest Release, pathBinary, pathVersion string) []string {
+	var result []string
+	if latest.Version != "" && IsOutdated(running, latest.Version) {
+		result = append(result, fmt.Sprintf("Tilt v%s is available (you have v%s). Release notes: %s",
+			latest.Version, running, latest.URL))
+	}
+	if pathBinary != "" && pathVersion != "" && pathVersion != running {
+		result = append(result, fmt.Sprintf(
+			"The tilt binary on your PATH (%s) is v%s, but you're running v%s. "+
+				"Editor integrations that use `tilt lsp` may not match this Tiltfile API.",
+			pathBinary, pathVersion, running))
+	}
+	return result
+}
@@ -0,0 +1,66 @@
+package versioncheck
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/testutils/httptest"
+)
+
+const releaseJSON = `{
+  "tag_name": "v0.31.0",
+  "html_url": "https://github.com/tilt-dev/tilt/releases/tag/v0.31.0",
+  "body": "## Changelog\n\n* abc123 Faster builds\n- def456 Better logs\n\nThanks!"
+}`
+
+func TestFetchLatestRelease(t *testing.T) {
+	client := httptest.NewFakeClient()
+	client.SetResponse(releaseJSON)
+
+	r, err := FetchLatestRelease(context.Background(), client)
+	require.NoError(t, err)
+	assert.Equal(t, "0.31.0", r.Version)
+	assert.Equal(t, "https://github.com/tilt-dev/tilt/releases/tag/v0.31.0", r.URL)
+	assert.Equal(t, []string{"abc123 Faster builds", "def456 Better logs"}, r.Highlights)
+}
+
+func TestFetchLatestReleaseError(t *testing.T) {
+	client := httptest.NewFakeClient()
+	_, err := FetchLatestRelease(context.Background(), client)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "status 500")
+}
+
+func TestParseHighlightsLimit(t *testing.T) {
+	body := "* 1\n* 2\n* 3\n* 4\n* 5\n* 6\n"
+	assert.Equal(t, []string{"1", "2", "3", "4", "5"}, parseHighlights(body))
+}
+
+func TestIsOutdated(t *testing.T) {
+	assert.True(t, IsOutdated("0.30.7", "0.31.0"))
+	assert.True(t, IsOutdated("v0.30.7", "0.30.8"))
+	assert.False(t, IsOutdated("0.31.0", "0.31.0"))
+	assert.False(t, IsOutdated("0.31.1", "0.31.0"))
+	assert.False(t, IsOutdated("custom", "0.31.0"))
+	assert.False(t, IsOutdated("0.30.7", ""))
+}
+
+func TestParseBuildStamp(t *testing.T) {
+	assert.Equal(t, "0.30.7", parseBuildStamp("v0.30.7, built 2022-08-01\n"))
+	assert.Equal(t, "0.31.0", parseBuildStamp("v0.31.0-dev, built 2022-08-10"))
+}
+
+func TestSkewWarnings(t *testing.T) {
+	latest := Release{Version: "0.31.0", URL: "https://example.com/v0.31.0"}
+
+	assert.Empty(t, skewWarnings("0.31.0", latest, "", ""))
+	assert.Empty(t, skewWarnings("0.31.0", latest, "/usr/local/bin/tilt", "0.31.0"))
+
+	warnings := skewWarnings("0.30.7", latest, "/usr/local/bin/tilt", "0.29.0")
+	require.Len(t, warnings, 2)
+	assert.Contains(t, warnings[0], "Tilt v0.31.0 is available (you have v0.30.7)")
+	assert.Contains(t, warnings[1], "The tilt binary on your PATH (/usr/local/bin/tilt) is v0.29.0")
+}
@@ -2,6 +2,7 @@ package feature
 
 import (
 	"fmt"
+	"hash/fnv"
 )
 
 // The status of a feature flag
@@ -30,10 +31,30 @@ const BulkDisableResources = "bulk_disable_resources"
 const ClusterRefresh = "cluster_refresh"
 const OfflineSnapshotCreation = "offline_snapshot_creation"
 
-// The Value a flag can have. Status should never be changed.
+// Where a flag's value came from.
+type Source string
+
+const (
+	// The flag has its default value.
+	SourceDefault Source = "default"
+	// The flag is disabled by default, but this project is in its rollout.
+	SourceRollout Source = "rollout"
+	// The Tiltfile set the flag.
+	SourceTiltfile Source = "tiltfile"
+)
+
+// The Value a flag can have. Status and Rollout should never be changed.
 type Value struct {
 	Enabled bool
 	Status  Status
+
+	// While an Active flag is disabled by default, the percentage of projects
+	// (0-100) that get it enabled anyway. Raise it over a few releases to roll
+	// out a feature gradually, then flip Enabled when it's ready for everyone.
+	Rollout int
+
+	// Empty means SourceDefault.
+	Source Source
 }
 
 // Defaults is the initial values for a FeatureSet.
@@ -124,10 +145,41 @@ func (s FeatureSet) Set(name string, enabled bool) error {
 	}
 
 	v.Enabled = enabled
+	v.Source = SourceTiltfile
 	s[name] = v
 	return nil
 }
 
+// Roll enables the flags whose rollout includes the given project.
+//
+// A project is identified by its main Tiltfile path, so a project gets the
+// same flags every time Tilt runs it, and keeps them as the rollout grows.
+func (s FeatureSet) Roll(project string) {
+	for name, v := range s {
+		if v.Status != Active || v.Enabled || v.Rollout <= 0 {
+			continue
+		}
+		if v.Source != "" && v.Source != SourceDefault {
+			continue
+		}
+		if rolloutBucket(name, project) < v.Rollout {
+			v.Enabled = true
+			v.Source = SourceRollout
+			s[name] = v
+		}
+	}
+}
+
+// Puts a project in one of 100 buckets, independently for each flag,
+// so that the same projects don't get every new feature first.
+func rolloutBucket(name, project string) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(project))
+	return int(h.Sum32() % 100)
+}
+
 // Get gets whether a feature is enabled.
 func (s FeatureSet) Get(name string) bool {
 	v, ok := s[name]
@@ -145,3 +197,16 @@ func (s FeatureSet) ToEnabled() map[string]bool {
 	}
 	return r
 }
+
+// ToSources returns where each value in the FeatureSet came from
+func (s FeatureSet) ToSources() map[string]Source {
+	r := make(map[string]Source)
+	for k, v := range s {
+		source := v.Source
+		if source == "" {
+			source = SourceDefault
+		}
+		r[k] = source
+	}
+	return r
+}
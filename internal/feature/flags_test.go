@@ -1,6 +1,7 @@
 package feature
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -53,3 +54,63 @@ func TestToEnabled(t *testing.T) {
 
 	assert.Equal(t, map[string]bool{"foo": true}, m.ToEnabled())
 }
+
+func TestSetRecordsSource(t *testing.T) {
+	m := FeatureSet{"foo": Value{Enabled: false}}
+	assert.Equal(t, map[string]Source{"foo": SourceDefault}, m.ToSources())
+
+	err := m.Set("foo", true)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Source{"foo": SourceTiltfile}, m.ToSources())
+}
+
+func TestRoll(t *testing.T) {
+	m := FeatureSet{
+		"everyone":  Value{Rollout: 100},
+		"no-one":    Value{Rollout: 0},
+		"obsolete":  Value{Rollout: 100, Status: Obsolete},
+		"tiltfile":  Value{Rollout: 100},
+		"on-anyway": Value{Enabled: true, Rollout: 100},
+	}
+	require.NoError(t, m.Set("tiltfile", false))
+
+	m.Roll("/src/Tiltfile")
+
+	assert.Equal(t, map[string]bool{
+		"everyone":  true,
+		"no-one":    false,
+		"obsolete":  false,
+		"tiltfile":  false,
+		"on-anyway": true,
+	}, m.ToEnabled())
+	assert.Equal(t, map[string]Source{
+		"everyone":  SourceRollout,
+		"no-one":    SourceDefault,
+		"obsolete":  SourceDefault,
+		"tiltfile":  SourceTiltfile,
+		"on-anyway": SourceDefault,
+	}, m.ToSources())
+}
+
+func TestRollIsStableAndGradual(t *testing.T) {
+	enabled := 0
+	for i := 0; i < 1000; i++ {
+		project := fmt.Sprintf("/src/project-%d/Tiltfile", i)
+
+		half := FeatureSet{"foo": Value{Rollout: 50}}
+		half.Roll(project)
+
+		again := FeatureSet{"foo": Value{Rollout: 50}}
+		again.Roll(project)
+		assert.Equal(t, half.Get("foo"), again.Get("foo"))
+
+		// Projects in a rollout stay in it as it grows.
+		more := FeatureSet{"foo": Value{Rollout: 75}}
+		more.Roll(project)
+		if half.Get("foo") {
+			enabled++
+			assert.True(t, more.Get("foo"))
+		}
+	}
+	assert.InDelta(t, 500, enabled, 75)
+}
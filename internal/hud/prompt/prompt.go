@@ -35,6 +35,7 @@ type TerminalPrompt struct {
 	stdout    hud.Stdout
 	host      model.WebHost
 	url       model.WebURL
+	token     model.WebAuthToken
 
 	printed bool
 	term    TerminalInput
@@ -48,7 +49,7 @@ type TerminalPrompt struct {
 
 func NewTerminalPrompt(a *analytics.TiltAnalytics, openInput OpenInput,
 	openURL openurl.OpenURL, stdout hud.Stdout,
-	host model.WebHost, url model.WebURL) *TerminalPrompt {
+	host model.WebHost, url model.WebURL, token model.WebAuthToken) *TerminalPrompt {
 
 	return &TerminalPrompt{
 		a:         a,
@@ -57,6 +58,7 @@ func NewTerminalPrompt(a *analytics.TiltAnalytics, openInput OpenInput,
 		stdout:    stdout,
 		host:      host,
 		url:       url,
+		token:     token,
 	}
 }
 
@@ -206,7 +208,7 @@ func (p *TerminalPrompt) OnChange(ctx context.Context, st store.RStore, _ store.
 				case ' ':
 					p.a.Incr("ui.prompt.browser", map[string]string{})
 					_, _ = fmt.Fprintf(p.stdout, "Opening browser: %s\n", p.url.String())
-					err := p.openURL(p.url.String(), p.stdout)
+					err := p.openURL(p.url.WithAuthToken(p.token).String(), p.stdout)
 					if err != nil {
 						_, _ = fmt.Fprintf(p.stdout, "Error: %v\n", err)
 					}
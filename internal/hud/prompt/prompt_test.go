@@ -91,7 +91,7 @@ func newFixture(t *testing.T) *fixture {
 
 	url, _ := url.Parse(FakeURL)
 
-	prompt := NewTerminalPrompt(ta, openInput, b.OpenURL, out, "localhost", model.WebURL(*url))
+	prompt := NewTerminalPrompt(ta, openInput, b.OpenURL, out, "localhost", model.WebURL(*url), "")
 	ret := &fixture{
 		ctx:    ctx,
 		cancel: cancel,
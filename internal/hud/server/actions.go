@@ -2,15 +2,9 @@ package server
 
 import (
 	"github.com/tilt-dev/tilt/pkg/model"
+	"github.com/tilt-dev/tilt/pkg/model/logstore"
 )
 
-type AppendToTriggerQueueAction struct {
-	Name   model.ManifestName
-	Reason model.BuildReason
-}
-
-func (AppendToTriggerQueueAction) Action() {}
-
 // TODO: a way to clear an override
 type OverrideTriggerModeAction struct {
 	ManifestNames []model.ManifestName
@@ -18,3 +12,9 @@ type OverrideTriggerModeAction struct {
 }
 
 func (OverrideTriggerModeAction) Action() {}
+
+type SetLogPrefixFormatAction struct {
+	Format logstore.PrefixFormat
+}
+
+func (SetLogPrefixFormatAction) Action() {}
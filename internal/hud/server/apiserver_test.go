@@ -89,6 +89,16 @@ func TestAPIServerDynamicClient(t *testing.T) {
 				},
 			},
 		},
+		"LocalIngress": map[string]interface{}{
+			"host": "myapp.localhost",
+			"routes": []interface{}{
+				map[string]interface{}{"path": "/", "localPort": 8080},
+			},
+		},
+		"NestedTilt": map[string]interface{}{
+			"path": "/home/user/project/Tiltfile",
+			"port": 10351,
+		},
 		"ToggleButton": map[string]interface{}{
 			"stateSource": map[string]interface{}{
 				"configMap": map[string]interface{}{
@@ -0,0 +1,141 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// The cookie that authenticates the web UI, once the user has
+// opened it with the token.
+const WebAuthCookieName = "Tilt-Web-Auth"
+
+// Requires the web auth token on everything the web server serves, so that
+// Tilt can be exposed beyond localhost (e.g., on 0.0.0.0, or through a tunnel).
+//
+// Clients send the token as a bearer token:
+//
+//	Authorization: Bearer <token>
+//
+// Browsers can't add headers to websockets, so the web UI logs in with the
+// token in the URL instead, and we remember it in a cookie.
+//
+// Only the health checks don't need the token, so that probes keep working.
+func (s *HeadsUpServer) authMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if s.authToken == "" || isHealthCheck(req.URL.Path) {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		if token := req.URL.Query().Get(model.WebAuthTokenParam); token != "" {
+			s.login(w, req, token)
+			return
+		}
+
+		if !s.isAuthenticated(req) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="tilt"`)
+			http.Error(w, "Unauthorized. Send the Tilt web token as a bearer token, or open the web UI with ?token=<token>",
+				http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// The paths that don't need the web auth token.
+var healthCheckPaths = map[string]bool{
+	"/healthz": true,
+	"/readyz":  true,
+}
+
+func isHealthCheck(path string) bool {
+	return healthCheckPaths[path]
+}
+
+// Requires the web auth token on gRPC calls, as metadata:
+//
+//	authorization: Bearer <token>
+func (s *HeadsUpServer) grpcAuthUnaryInterceptor(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := s.checkGRPCAuth(ctx); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (s *HeadsUpServer) grpcAuthStreamInterceptor(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := s.checkGRPCAuth(ss.Context()); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+func (s *HeadsUpServer) checkGRPCAuth(ctx context.Context) error {
+	if s.authToken == "" {
+		return nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, auth := range md.Get("authorization") {
+		if token, ok := strings.CutPrefix(auth, "Bearer "); ok && s.tokenMatches(token) {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, "send the Tilt web token as authorization: Bearer <token>")
+}
+
+func (s *HeadsUpServer) isAuthenticated(req *http.Request) bool {
+	auth := req.Header.Get("Authorization")
+	if token, ok := strings.CutPrefix(auth, "Bearer "); ok {
+		return s.tokenMatches(token)
+	}
+	cookie, err := req.Cookie(WebAuthCookieName)
+	if err == nil {
+		return s.tokenMatches(cookie.Value)
+	}
+	return false
+}
+
+func (s *HeadsUpServer) tokenMatches(token string) bool {
+	return subtle.ConstantTimeCompare([]byte(token), []byte(s.authToken)) == 1
+}
+
+// Checks the token in the URL, and if it matches, sets the auth cookie and
+// redirects to the same URL without the token, so that it doesn't stay
+// in the browser's history.
+func (s *HeadsUpServer) login(w http.ResponseWriter, req *http.Request, token string) {
+	if !s.tokenMatches(token) {
+		http.Error(w, "Unauthorized. Invalid Tilt web token", http.StatusUnauthorized)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     WebAuthCookieName,
+		Value:    string(s.authToken),
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   req.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+	})
+
+	u := *req.URL
+	query := u.Query()
+	query.Del(model.WebAuthTokenParam)
+	u.RawQuery = query.Encode()
+	http.Redirect(w, req, u.RequestURI(), http.StatusFound)
+}
+
+// Adds the web auth token to a request to the Tilt web server, if there is one.
+func AddWebAuth(header http.Header, token model.WebAuthToken) {
+	if token != "" {
+		header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	}
+}
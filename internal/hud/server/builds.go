@@ -0,0 +1,151 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/model"
+	"github.com/tilt-dev/tilt/pkg/model/logstore"
+)
+
+// A single build of a resource, addressable by a stable URL:
+//
+//	/api/resources/{name}/builds/{buildID}
+//
+// The build ID is derived from the build's log span, so it stays the same
+// for as long as Tilt remembers the build.
+type buildPayload struct {
+	Resource   string     `json:"resource"`
+	BuildID    string     `json:"buildID"`
+	SpanID     string     `json:"spanID"`
+	StartTime  time.Time  `json:"startTime"`
+	FinishTime *time.Time `json:"finishTime,omitempty"`
+	Error      string     `json:"error,omitempty"`
+
+	// The range of log checkpoints [from, to) that contains this build's logs.
+	// Absent if the logs have been truncated.
+	LogRange *logRangePayload `json:"logRange,omitempty"`
+
+	// Only populated when requesting a single build.
+	Log string `json:"log,omitempty"`
+}
+
+type logRangePayload struct {
+	FromCheckpoint int `json:"fromCheckpoint"`
+	ToCheckpoint   int `json:"toCheckpoint"`
+}
+
+// Builds with log spans of the form "build:42" get the ID "42".
+func buildIDForSpanID(spanID model.LogSpanID) string {
+	s := string(spanID)
+	return s[strings.LastIndex(s, ":")+1:]
+}
+
+func newBuildPayload(mn model.ManifestName, b model.BuildRecord, ls *logstore.LogStore) buildPayload {
+	p := buildPayload{
+		Resource:  mn.String(),
+		BuildID:   buildIDForSpanID(b.SpanID),
+		SpanID:    string(b.SpanID),
+		StartTime: b.StartTime,
+	}
+	if !b.FinishTime.IsZero() {
+		finishTime := b.FinishTime
+		p.FinishTime = &finishTime
+	}
+	if b.Error != nil {
+		p.Error = b.Error.Error()
+	}
+	if from, to, ok := ls.SpanRange(b.SpanID); ok {
+		p.LogRange = &logRangePayload{FromCheckpoint: int(from), ToCheckpoint: int(to)}
+	}
+	return p
+}
+
+// All builds of a manifest that Tilt still remembers, most recent first.
+func buildsForManifest(ms *store.ManifestState) []model.BuildRecord {
+	result := []model.BuildRecord{}
+	for _, b := range ms.CurrentBuilds {
+		if b.SpanID != "" {
+			result = append(result, b)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].StartTime.After(result[j].StartTime)
+	})
+
+	for _, b := range ms.BuildHistory {
+		if b.SpanID != "" {
+			result = append(result, b)
+		}
+	}
+	return result
+}
+
+// Lists the builds of a resource.
+func (s *HeadsUpServer) HandleListBuilds(w http.ResponseWriter, req *http.Request) {
+	mn := model.ManifestName(mux.Vars(req)["name"])
+
+	state := s.store.RLockState()
+	ms, ok := state.ManifestState(mn)
+	if !ok {
+		s.store.RUnlockState()
+		http.Error(w, fmt.Sprintf("resource %q does not exist", mn), http.StatusNotFound)
+		return
+	}
+
+	result := []buildPayload{}
+	for _, b := range buildsForManifest(ms) {
+		result = append(result, newBuildPayload(mn, b, state.LogStore))
+	}
+	s.store.RUnlockState()
+
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(result)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("error rendering builds: %v", err), http.StatusInternalServerError)
+	}
+}
+
+// Serves a single build of a resource, with its logs.
+func (s *HeadsUpServer) HandleGetBuild(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	mn := model.ManifestName(vars["name"])
+	buildID := vars["buildID"]
+
+	state := s.store.RLockState()
+	ms, ok := state.ManifestState(mn)
+	if !ok {
+		s.store.RUnlockState()
+		http.Error(w, fmt.Sprintf("resource %q does not exist", mn), http.StatusNotFound)
+		return
+	}
+
+	var result *buildPayload
+	for _, b := range buildsForManifest(ms) {
+		if buildIDForSpanID(b.SpanID) == buildID {
+			p := newBuildPayload(mn, b, state.LogStore)
+			p.Log = state.LogStore.SpanLog(b.SpanID)
+			result = &p
+			break
+		}
+	}
+	s.store.RUnlockState()
+
+	if result == nil {
+		http.Error(w, fmt.Sprintf("build %q of resource %q does not exist", buildID, mn), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(result)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("error rendering build: %v", err), http.StatusInternalServerError)
+	}
+}
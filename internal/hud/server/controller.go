@@ -10,6 +10,8 @@ import (
 	"regexp"
 
 	"github.com/gorilla/mux"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 	genericapiserver "k8s.io/apiserver/pkg/server"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -89,6 +91,7 @@ func (s *HeadsUpServerController) TearDown(ctx context.Context) {
 }
 
 func (s *HeadsUpServerController) OnChange(ctx context.Context, st store.RStore, _ store.ChangeSummary) error {
+	s.hudServer.ObserveBuilds(st)
 	return nil
 }
 
@@ -156,16 +159,11 @@ func (s *HeadsUpServerController) setUpHelper(ctx context.Context, st store.RSto
 		return fmt.Errorf("failed to create apiserver proxy: %v", err)
 	}
 
-	webRouter := mux.NewRouter()
-	webRouter.PathPrefix("/debug").Handler(http.DefaultServeMux) // for /debug/pprof
-	// the path prefix here must be kept in sync with the prefix configured in the proxy handler
-	// (it needs to know what to strip before forwarding the request)
-	webRouter.PathPrefix(apiServerProxyPrefix).Handler(proxyHandler)
-	webRouter.PathPrefix("/").Handler(s.hudServer.Router())
+	webHandler := NewWebHandler(s.hudServer, proxyHandler)
 
 	s.webServer = &http.Server{
 		Addr:    s.webListener.Addr().String(),
-		Handler: webRouter,
+		Handler: h2c.NewHandler(grpcOrHTTPHandler(NewGRPCServer(s.hudServer), webHandler), &http2.Server{}),
 
 		// blackhole any server errors
 		ErrorLog: log.New(io.Discard, "", 0),
@@ -255,6 +253,19 @@ func (s *HeadsUpServerController) removeFromAPIServerConfig() error {
 	return clientcmd.ModifyConfig(s.configAccess, *newConfig, true)
 }
 
+// Serves the web UI, its API, pprof, and the apiserver proxy on the web listener.
+//
+// All of them require the web auth token, if there is one.
+func NewWebHandler(hud *HeadsUpServer, proxyHandler http.Handler) http.Handler {
+	webRouter := mux.NewRouter()
+	webRouter.PathPrefix("/debug").Handler(http.DefaultServeMux) // for /debug/pprof
+	// the path prefix here must be kept in sync with the prefix configured in the proxy handler
+	// (it needs to know what to strip before forwarding the request)
+	webRouter.PathPrefix(apiServerProxyPrefix).Handler(proxyHandler)
+	webRouter.PathPrefix("/").Handler(hud.Router())
+	return hud.authMiddleware(webRouter)
+}
+
 func newAPIServerProxyHandler(config *rest.Config) (http.Handler, error) {
 	// all requests to the proxy handler are same origin from the HUD server, so there is
 	// no CORS policy in place because we explicitly want to reject all other origin requests
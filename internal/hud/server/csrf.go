@@ -0,0 +1,41 @@
+package server
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+
+	"github.com/tilt-dev/tilt/internal/hud/server/gorilla"
+)
+
+// Checks that a request that changes state on the developer's machine
+// came from the web UI or the CLI, and not from some other web page
+// they happen to have open.
+//
+// Browsers let any page send a "simple" POST (e.g., a text/plain body) to
+// localhost without asking first. A JSON content type forces the browser to
+// send a CORS preflight, which we never approve. Browsers that send the request
+// anyway also send Origin and Sec-Fetch-Site, which must match our host.
+//
+// The CLI doesn't send Origin or Sec-Fetch-Site, so it only needs the
+// content type.
+func checkSameOriginJSON(w http.ResponseWriter, req *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		http.Error(w, fmt.Sprintf("Content-Type must be application/json, got %q", req.Header.Get("Content-Type")),
+			http.StatusUnsupportedMediaType)
+		return false
+	}
+
+	site := req.Header.Get("Sec-Fetch-Site")
+	if site != "" && site != "same-origin" && site != "none" {
+		http.Error(w, fmt.Sprintf("Forbidden: cross-site request (Sec-Fetch-Site: %s)", site), http.StatusForbidden)
+		return false
+	}
+
+	if !gorilla.CheckSameOrigin(req) {
+		http.Error(w, fmt.Sprintf("Forbidden: request from origin %q", req.Header.Get("Origin")), http.StatusForbidden)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+type ResourceDisableResponse struct {
+	Resource string `json:"resource"`
+	Disabled bool   `json:"disabled"`
+}
+
+// Disables a resource, like the web UI's disable button:
+//
+//	POST /api/resources/{name}/disable
+func (s *HeadsUpServer) HandleDisableResource(w http.ResponseWriter, req *http.Request) {
+	s.setResourceDisabled(w, req, true)
+}
+
+// Enables a resource that was disabled:
+//
+//	POST /api/resources/{name}/enable
+func (s *HeadsUpServer) HandleEnableResource(w http.ResponseWriter, req *http.Request) {
+	s.setResourceDisabled(w, req, false)
+}
+
+// Flips the ConfigMaps that the resource's DisableSource points to.
+// The controllers take it from there, the same as when the web UI
+// or `tilt disable` does it.
+//
+// Disabling a resource tears down its workloads, so only accept
+// requests from the web UI or the CLI.
+func (s *HeadsUpServer) setResourceDisabled(w http.ResponseWriter, req *http.Request, disabled bool) {
+	if !checkSameOriginJSON(w, req) {
+		return
+	}
+
+	ctx := req.Context()
+	name := mux.Vars(req)["name"]
+
+	var uir v1alpha1.UIResource
+	err := s.ctrlClient.Get(ctx, types.NamespacedName{Name: name}, &uir)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			http.Error(w, fmt.Sprintf("resource %q does not exist", name), http.StatusNotFound)
+			return
+		}
+		http.Error(w, fmt.Sprintf("error reading resource %q: %v", name, err), http.StatusInternalServerError)
+		return
+	}
+
+	sources := uir.Status.DisableStatus.Sources
+	if len(sources) == 0 {
+		http.Error(w, fmt.Sprintf("resource %q cannot be enabled or disabled", name), http.StatusBadRequest)
+		return
+	}
+
+	for _, source := range sources {
+		if source.ConfigMap == nil {
+			http.Error(w, fmt.Sprintf("internal error: resource %q's DisableSource does not have a ConfigMap", name),
+				http.StatusInternalServerError)
+			return
+		}
+		cm := &v1alpha1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: source.ConfigMap.Name}}
+		_, err := controllerutil.CreateOrUpdate(ctx, s.ctrlClient, cm, func() error {
+			if cm.Data == nil {
+				cm.Data = make(map[string]string)
+			}
+			cm.Data[source.ConfigMap.Key] = strconv.FormatBool(disabled)
+			return nil
+		})
+		if err != nil {
+			http.Error(w, fmt.Sprintf("error updating ConfigMap %q: %v", source.ConfigMap.Name, err),
+				http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(ResourceDisableResponse{Resource: name, Disabled: disabled})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("error rendering response: %v", err), http.StatusInternalServerError)
+	}
+}
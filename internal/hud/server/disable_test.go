@@ -0,0 +1,91 @@
+package server_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/tilt-dev/tilt/internal/testutils/uiresourcebuilder"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestDisableAndEnableResource(t *testing.T) {
+	f := newTestFixture(t)
+
+	source := v1alpha1.DisableSource{
+		ConfigMap: &v1alpha1.ConfigMapDisableSource{Name: "fe-disable", Key: "isDisabled"},
+	}
+	require.NoError(t, f.ctrlClient.Create(f.ctx, uiresourcebuilder.New("fe").WithDisableSource(source).Build()))
+
+	status, body := f.post("/api/resources/fe/disable")
+	require.Equal(t, http.StatusOK, status, body)
+	assert.JSONEq(t, `{"resource":"fe","disabled":true}`, body)
+	assert.Equal(t, "true", f.configMapValue("fe-disable", "isDisabled"))
+
+	status, body = f.post("/api/resources/fe/enable")
+	require.Equal(t, http.StatusOK, status, body)
+	assert.JSONEq(t, `{"resource":"fe","disabled":false}`, body)
+	assert.Equal(t, "false", f.configMapValue("fe-disable", "isDisabled"))
+}
+
+func TestDisableResourceErrors(t *testing.T) {
+	f := newTestFixture(t)
+
+	require.NoError(t, f.ctrlClient.Create(f.ctx, uiresourcebuilder.New(model.MainTiltfileManifestName.String()).Build()))
+
+	status, body := f.post("/api/resources/fe/disable")
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.Contains(t, body, `resource "fe" does not exist`)
+
+	status, body = f.post("/api/resources/(Tiltfile)/enable")
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Contains(t, body, `resource "(Tiltfile)" cannot be enabled or disabled`)
+}
+
+func TestDisableResourceRejectsCrossSiteRequests(t *testing.T) {
+	f := newTestFixture(t)
+
+	source := v1alpha1.DisableSource{
+		ConfigMap: &v1alpha1.ConfigMapDisableSource{Name: "fe-disable", Key: "isDisabled"},
+	}
+	require.NoError(t, f.ctrlClient.Create(f.ctx, uiresourcebuilder.New("fe").WithDisableSource(source).Build()))
+
+	// A no-cors fetch() from another page.
+	req := httptest.NewRequest(http.MethodPost, "/api/resources/fe/disable", nil)
+	req.Header.Set("Content-Type", "text/plain")
+	req.Header.Set("Sec-Fetch-Site", "cross-site")
+	rr := httptest.NewRecorder()
+	f.serv.Router().ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusUnsupportedMediaType, rr.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/resources/fe/disable", nil)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Origin", "https://evil.example.com")
+	rr = httptest.NewRecorder()
+	f.serv.Router().ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	var cm v1alpha1.ConfigMap
+	err := f.ctrlClient.Get(f.ctx, types.NamespacedName{Name: "fe-disable"}, &cm)
+	assert.True(t, apierrors.IsNotFound(err), "ConfigMap should not exist, got: %v", err)
+}
+
+func (f *serverFixture) post(path string) (int, string) {
+	req := httptest.NewRequest(http.MethodPost, path, nil)
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	f.serv.Router().ServeHTTP(rr, req)
+	return rr.Code, rr.Body.String()
+}
+
+func (f *serverFixture) configMapValue(name, key string) string {
+	var cm v1alpha1.ConfigMap
+	require.NoError(f.t, f.ctrlClient.Get(f.ctx, types.NamespacedName{Name: name}, &cm))
+	return cm.Data[key]
+}
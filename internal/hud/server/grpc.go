@@ -0,0 +1,85 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+
+	"github.com/tilt-dev/tilt/internal/hud/webview"
+	"github.com/tilt-dev/tilt/internal/snapshots"
+	proto_webview "github.com/tilt-dev/tilt/pkg/webview"
+)
+
+// Serves the webview protocol over gRPC, so that non-Go clients can
+// generate typed clients from pkg/webview/view.proto instead of
+// reverse-engineering the JSON endpoints.
+//
+// The server has reflection enabled, so tools like grpcurl work without
+// a copy of the proto files:
+//
+//	grpcurl -plaintext localhost:10350 list
+//	grpcurl -plaintext localhost:10350 webview.ViewService/GetView
+type viewService struct {
+	proto_webview.UnimplementedViewServiceServer
+
+	hud *HeadsUpServer
+}
+
+var _ proto_webview.ViewServiceServer = &viewService{}
+
+func (v *viewService) GetView(ctx context.Context, _ *proto_webview.GetViewRequest) (*proto_webview.View, error) {
+	view, err := webview.CompleteView(ctx, v.hud.ctrlClient, v.hud.store)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "converting view to proto: %v", err)
+	}
+	return view, nil
+}
+
+// Uploads the snapshot to the storage configured with snapshot_settings()
+// or TILT_SNAPSHOT_STORAGE_URL.
+func (v *viewService) UploadSnapshot(ctx context.Context, snapshot *proto_webview.Snapshot) (*proto_webview.UploadSnapshotResponse, error) {
+	storage, err := v.hud.snapshotStorage()
+	if err == snapshots.ErrStorageNotConfigured {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	} else if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "uploading snapshot: %v", err)
+	}
+
+	data, err := snapshots.Render(snapshot, snapshots.FormatJSON)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "uploading snapshot: %v", err)
+	}
+
+	link, err := uploadSnapshot(ctx, storage, data, snapshots.FormatJSON)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "uploading snapshot: %v", err)
+	}
+	return &proto_webview.UploadSnapshotResponse{Url: link}, nil
+}
+
+func NewGRPCServer(hud *HeadsUpServer) *grpc.Server {
+	s := grpc.NewServer(
+		grpc.UnaryInterceptor(hud.grpcAuthUnaryInterceptor),
+		grpc.StreamInterceptor(hud.grpcAuthStreamInterceptor))
+	proto_webview.RegisterViewServiceServer(s, &viewService{hud: hud})
+	reflection.Register(s)
+	return s
+}
+
+// Sends gRPC requests to the gRPC server, and everything else to the HTTP handler.
+//
+// gRPC needs HTTP/2, so the web listener must accept cleartext HTTP/2 (h2c).
+func grpcOrHTTPHandler(grpcServer *grpc.Server, httpHandler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor == 2 && strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+			grpcServer.ServeHTTP(w, r)
+			return
+		}
+		httpHandler.ServeHTTP(w, r)
+	})
+}
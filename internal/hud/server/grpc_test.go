@@ -0,0 +1,111 @@
+package server_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/tilt-dev/tilt/internal/hud/server"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	proto_webview "github.com/tilt-dev/tilt/pkg/webview"
+)
+
+func TestGRPCGetView(t *testing.T) {
+	f := newTestFixture(t)
+	err := f.ctrlClient.Create(f.ctx, &v1alpha1.UIResource{
+		ObjectMeta: metav1.ObjectMeta{Name: "frontend"},
+	})
+	require.NoError(t, err)
+	conn := f.dialGRPC()
+
+	view, err := proto_webview.NewViewServiceClient(conn).GetView(f.ctx, &proto_webview.GetViewRequest{})
+	require.NoError(t, err)
+	require.Len(t, view.UiResources, 1)
+	assert.Equal(t, "frontend", view.UiResources[0].Name)
+}
+
+func TestGRPCUploadSnapshotNotConfigured(t *testing.T) {
+	t.Setenv("TILT_SNAPSHOT_STORAGE_URL", "")
+	f := newTestFixture(t)
+	conn := f.dialGRPC()
+
+	_, err := proto_webview.NewViewServiceClient(conn).UploadSnapshot(f.ctx, &proto_webview.Snapshot{})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func TestGRPCReflection(t *testing.T) {
+	f := newTestFixture(t)
+	conn := f.dialGRPC()
+
+	stream, err := rpb.NewServerReflectionClient(conn).ServerReflectionInfo(f.ctx)
+	require.NoError(t, err)
+	err = stream.Send(&rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_ListServices{ListServices: "*"},
+	})
+	require.NoError(t, err)
+
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+
+	var names []string
+	for _, s := range resp.GetListServicesResponse().GetService() {
+		names = append(names, s.Name)
+	}
+	assert.Contains(t, names, "webview.ViewService")
+}
+
+func TestGRPCWebAuth(t *testing.T) {
+	f := newTestFixtureWithAuthToken(t, "secret")
+	conn := f.dialGRPC()
+	client := proto_webview.NewViewServiceClient(conn)
+
+	_, err := client.GetView(f.ctx, &proto_webview.GetViewRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = client.UploadSnapshot(f.ctx, &proto_webview.Snapshot{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx := metadata.AppendToOutgoingContext(f.ctx, "authorization", "Bearer wrong")
+	_, err = client.GetView(ctx, &proto_webview.GetViewRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx = metadata.AppendToOutgoingContext(f.ctx, "authorization", "Bearer secret")
+	_, err = client.GetView(ctx, &proto_webview.GetViewRequest{})
+	assert.NoError(t, err)
+
+	// Reflection streams need the token too.
+	stream, err := rpb.NewServerReflectionClient(conn).ServerReflectionInfo(f.ctx)
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func (f *serverFixture) dialGRPC() *grpc.ClientConn {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(f.t, err)
+
+	s := server.NewGRPCServer(f.serv)
+	go func() {
+		_ = s.Serve(l)
+	}()
+	f.t.Cleanup(s.Stop)
+
+	ctx, cancel := context.WithCancel(f.ctx)
+	f.t.Cleanup(cancel)
+	conn, err := grpc.DialContext(ctx, l.Addr().String(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(f.t, err)
+	f.t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
@@ -0,0 +1,146 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+// How long to wait for the apiserver before reporting it as down.
+const healthAPIServerTimeout = 2 * time.Second
+
+const (
+	healthStatusOK          = "ok"
+	healthStatusDegraded    = "degraded"
+	healthStatusUnavailable = "unavailable"
+)
+
+// The health of the Tilt process, for wrapper tooling that
+// monitors Tilt (e.g., devcontainer orchestrators).
+type healthPayload struct {
+	// One of "ok", "degraded", or "unavailable".
+	Status string `json:"status"`
+
+	// The subsystems that were checked, in a stable order.
+	Checks []healthCheck `json:"checks"`
+
+	// The number of web UI tabs connected.
+	Websockets int `json:"websockets"`
+}
+
+type healthCheck struct {
+	Name string `json:"name"`
+	OK   bool   `json:"ok"`
+
+	// Why the subsystem is degraded. Empty if it's ok.
+	Reason string `json:"reason,omitempty"`
+}
+
+// Liveness check.
+//
+// Responds 200 as long as the process is able to serve requests,
+// even if some subsystems are degraded. Responds 503 only if
+// the apiserver is down, because Tilt can't do anything without it.
+func (s *HeadsUpServer) HandleHealthz(w http.ResponseWriter, req *http.Request) {
+	payload := s.health(req.Context())
+	code := http.StatusOK
+	if payload.Status == healthStatusUnavailable {
+		code = http.StatusServiceUnavailable
+	}
+	writeHealth(w, code, payload)
+}
+
+// Readiness check.
+//
+// Responds 503 if any subsystem is degraded (e.g., the cluster
+// connection is down), so that tooling can wait for Tilt to be usable.
+func (s *HeadsUpServer) HandleReadyz(w http.ResponseWriter, req *http.Request) {
+	payload := s.health(req.Context())
+	code := http.StatusOK
+	if payload.Status != healthStatusOK {
+		code = http.StatusServiceUnavailable
+	}
+	writeHealth(w, code, payload)
+}
+
+func writeHealth(w http.ResponseWriter, code int, payload healthPayload) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(payload)
+}
+
+func (s *HeadsUpServer) health(ctx context.Context) healthPayload {
+	apiServer := s.apiServerHealth(ctx)
+	checks := []healthCheck{apiServer}
+	checks = append(checks, s.clusterHealth()...)
+
+	status := healthStatusOK
+	if !apiServer.OK {
+		status = healthStatusUnavailable
+	} else {
+		for _, c := range checks {
+			if !c.OK {
+				status = healthStatusDegraded
+				break
+			}
+		}
+	}
+
+	return healthPayload{
+		Status:     status,
+		Checks:     checks,
+		Websockets: s.wsList.Len(),
+	}
+}
+
+func (s *HeadsUpServer) apiServerHealth(ctx context.Context) healthCheck {
+	ctx, cancel := context.WithTimeout(ctx, healthAPIServerTimeout)
+	defer cancel()
+
+	var sessions v1alpha1.SessionList
+	err := s.ctrlClient.List(ctx, &sessions)
+	if err != nil {
+		return healthCheck{Name: "apiserver", Reason: fmt.Sprintf("listing sessions: %v", err)}
+	}
+	return healthCheck{Name: "apiserver", OK: true}
+}
+
+// Checks the connection to each cluster that the Tiltfile uses.
+//
+// Kubernetes clusters are named "cluster:NAME". The Docker daemon
+// is named "docker".
+func (s *HeadsUpServer) clusterHealth() []healthCheck {
+	state := s.store.RLockState()
+	defer s.store.RUnlockState()
+
+	names := make([]string, 0, len(state.Clusters))
+	for name := range state.Clusters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := make([]healthCheck, 0, len(names))
+	for _, name := range names {
+		cluster := state.Clusters[name]
+		check := healthCheck{Name: fmt.Sprintf("cluster:%s", name)}
+		if cluster.Spec.Connection != nil && cluster.Spec.Connection.Docker != nil {
+			check.Name = "docker"
+		}
+
+		switch {
+		case cluster.Status.Error != "":
+			check.Reason = cluster.Status.Error
+		case cluster.Status.ConnectedAt == nil:
+			check.Reason = "not connected yet"
+		default:
+			check.OK = true
+		}
+		result = append(result, check)
+	}
+	return result
+}
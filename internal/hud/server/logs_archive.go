@@ -0,0 +1,200 @@
+package server
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/model"
+	"github.com/tilt-dev/tilt/pkg/model/logstore"
+)
+
+const (
+	logArchiveFormatZip = "zip"
+	logArchiveFormatTar = "tar"
+)
+
+// The log file of one resource in a log archive.
+type logArchiveFile struct {
+	name     string
+	contents []byte
+}
+
+// Serves the logs of the selected resources as an archive with one file per resource,
+// so that users can attach complete logs to a ticket:
+//
+//	/api/logs/archive?resources=fe,be&since=30m&format=tar
+//
+// Query parameters, all optional:
+//
+//   - resources: comma-separated resource names. Defaults to all resources.
+//   - since: only include logs since this time, either an RFC3339 timestamp
+//     or a duration ago, like "30m". Defaults to all the logs that Tilt still has.
+//   - format: "zip" (the default) or "tar", for a gzipped tarball.
+func (s *HeadsUpServer) HandleLogArchive(w http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+
+	format := query.Get("format")
+	if format == "" {
+		format = logArchiveFormatZip
+	}
+	if format != logArchiveFormatZip && format != logArchiveFormatTar {
+		http.Error(w, fmt.Sprintf("invalid format %q. Must be one of: %s, %s",
+			format, logArchiveFormatZip, logArchiveFormatTar), http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	since, err := parseLogArchiveSince(query.Get("since"), now)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	names := resourceNamesParam(query)
+	state := s.store.RLockState()
+	if len(names) == 0 {
+		names = allResourceNames(state)
+	}
+	files := []logArchiveFile{}
+	for _, mn := range names {
+		if _, ok := state.ManifestState(mn); !ok {
+			s.store.RUnlockState()
+			http.Error(w, fmt.Sprintf("resource %q does not exist", mn), http.StatusNotFound)
+			return
+		}
+		files = append(files, logArchiveFile{
+			name:     logArchiveFileName(mn),
+			contents: logArchiveContents(state.LogStore.ManifestLinesSince(mn, since)),
+		})
+	}
+	s.store.RUnlockState()
+
+	filename := fmt.Sprintf("tilt-logs-%s", now.Format("20060102-150405"))
+	if format == logArchiveFormatZip {
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+".zip"))
+		err = writeLogZip(w, files, now)
+	} else {
+		w.Header().Set("Content-Type", "application/gzip")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+".tar.gz"))
+		err = writeLogTar(w, files, now)
+	}
+	if err != nil {
+		// We've already started writing the archive, so we can't change the status code.
+		log.Printf("Error writing log archive: %v", err)
+	}
+}
+
+func parseLogArchiveSince(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return time.Time{}, fmt.Errorf("invalid since %q. Must be an RFC3339 timestamp or a duration, like 30m", value)
+	}
+	return now.Add(-d), nil
+}
+
+// Parses the comma-separated "resources" query parameter.
+func resourceNamesParam(query url.Values) []model.ManifestName {
+	var names []model.ManifestName
+	for _, value := range query["resources"] {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimSpace(name)
+			if name != "" {
+				names = append(names, model.ManifestName(name))
+			}
+		}
+	}
+	return names
+}
+
+// The Tiltfiles and all the resources, in the order that the UI shows them.
+func allResourceNames(state store.EngineState) []model.ManifestName {
+	result := []model.ManifestName{}
+	for _, ms := range state.GetTiltfileStates() {
+		result = append(result, ms.Name)
+	}
+	for _, ms := range state.ManifestStates() {
+		result = append(result, ms.Name)
+	}
+	return result
+}
+
+var logArchiveFileNameReplacer = strings.NewReplacer("/", "_", "\\", "_")
+
+func logArchiveFileName(mn model.ManifestName) string {
+	return logArchiveFileNameReplacer.Replace(mn.String()) + ".log"
+}
+
+// Prefixes each line with its timestamp, so that logs from
+// different resources can be lined up.
+func logArchiveContents(lines []logstore.LogLine) []byte {
+	sb := strings.Builder{}
+	for _, line := range lines {
+		sb.WriteString(line.Time.Format(time.RFC3339Nano))
+		sb.WriteString(" ")
+		sb.WriteString(line.Text)
+		if !strings.HasSuffix(line.Text, "\n") {
+			sb.WriteString("\n")
+		}
+	}
+	return []byte(sb.String())
+}
+
+func writeLogZip(w io.Writer, files []logArchiveFile, modTime time.Time) error {
+	zw := zip.NewWriter(w)
+	for _, f := range files {
+		fw, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     f.name,
+			Method:   zip.Deflate,
+			Modified: modTime,
+		})
+		if err != nil {
+			return err
+		}
+		_, err = fw.Write(f.contents)
+		if err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func writeLogTar(w io.Writer, files []logArchiveFile, modTime time.Time) error {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	for _, f := range files {
+		err := tw.WriteHeader(&tar.Header{
+			Name:    f.name,
+			Mode:    0644,
+			Size:    int64(len(f.contents)),
+			ModTime: modTime,
+		})
+		if err != nil {
+			return err
+		}
+		_, err = tw.Write(f.contents)
+		if err != nil {
+			return err
+		}
+	}
+	err := tw.Close()
+	if err != nil {
+		return err
+	}
+	return gw.Close()
+}
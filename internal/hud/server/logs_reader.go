@@ -3,6 +3,8 @@ package server
 import (
 	"context"
 	"io"
+	"net/http"
+	"regexp"
 
 	"github.com/golang/protobuf/jsonpb"
 	"github.com/gorilla/websocket"
@@ -31,8 +33,9 @@ type WebsocketReader struct {
 	handler      ViewHandler
 }
 
-func newWebsocketReaderForLogs(conn WebsocketConn, persistent bool, resources []string, p *hud.IncrementalPrinter) *WebsocketReader {
+func newWebsocketReaderForLogs(conn WebsocketConn, persistent bool, resources []string, grep *regexp.Regexp, p *hud.IncrementalPrinter) *WebsocketReader {
 	ls := NewLogStreamer(resources, p)
+	ls.grep = grep
 	return newWebsocketReader(conn, persistent, ls)
 }
 
@@ -60,6 +63,7 @@ type LogStreamer struct {
 	// This value should only be used to compare to other server values, NOT client checkpoints.
 	serverWatermark int32
 	resources       model.ManifestNameSet // if present, resource(s) to stream logs for
+	grep            *regexp.Regexp        // if present, only print lines that match
 	printer         *hud.IncrementalPrinter
 }
 
@@ -97,28 +101,59 @@ func (ls *LogStreamer) Handle(v *proto_webview.View) error {
 		ls.logstore.Append(webview.LogSegmentToEvent(seg, v.LogList.Spans), model.SecretSet{})
 	}
 
-	ls.printer.Print(ls.logstore.ContinuingLinesWithOptions(ls.checkpoint, logstore.LineOptions{
+	opts := logstore.LineOptions{
 		ManifestNames:  ls.resources,
 		SuppressPrefix: suppressPrefix,
-	}))
+	}
+	lines := ls.logstore.ContinuingLinesWithOptions(ls.checkpoint, opts)
+	if ls.grep != nil {
+		lines = ls.grepLines(lines, opts)
+	}
+	ls.printer.Print(lines)
 
 	ls.checkpoint = ls.logstore.Checkpoint()
 	ls.serverWatermark = v.LogList.ToCheckpoint
 
 	return nil
 }
-func StreamLogs(ctx context.Context, follow bool, url model.WebURL, resources []string, printer *hud.IncrementalPrinter) error {
+
+// Filters out the lines that don't match the grep pattern.
+//
+// We match the lines without the resource name prefix,
+// so that a pattern like "fe" doesn't match every line of resource "fe".
+func (ls *LogStreamer) grepLines(lines []logstore.LogLine, opts logstore.LineOptions) []logstore.LogLine {
+	unprefixed := lines
+	if !opts.SuppressPrefix {
+		opts.SuppressPrefix = true
+		unprefixed = ls.logstore.ContinuingLinesWithOptions(ls.checkpoint, opts)
+		if len(unprefixed) != len(lines) {
+			unprefixed = lines
+		}
+	}
+
+	result := []logstore.LogLine{}
+	for i, line := range lines {
+		if ls.grep.MatchString(logstore.SearchableText(unprefixed[i].Text)) {
+			result = append(result, line)
+		}
+	}
+	return result
+}
+
+func StreamLogs(ctx context.Context, follow bool, url model.WebURL, token model.WebAuthToken, resources []string, grep *regexp.Regexp, printer *hud.IncrementalPrinter) error {
 	url.Scheme = "ws"
 	url.Path = "/ws/view"
 	logger.Get(ctx).Debugf("connecting to %s", url.String())
 
-	conn, _, err := websocket.DefaultDialer.Dial(url.String(), nil)
+	header := http.Header{}
+	AddWebAuth(header, token)
+	conn, _, err := websocket.DefaultDialer.Dial(url.String(), header)
 	if err != nil {
 		return errors.Wrapf(err, "dialing websocket %s", url.String())
 	}
 	defer conn.Close()
 
-	wsr := newWebsocketReaderForLogs(conn, follow, resources, printer)
+	wsr := newWebsocketReaderForLogs(conn, follow, resources, grep, printer)
 	return wsr.Listen(ctx)
 }
 
@@ -3,6 +3,7 @@ package server
 import (
 	"bytes"
 	"fmt"
+	"regexp"
 	"strings"
 	"testing"
 
@@ -131,6 +132,18 @@ func TestLogStreamerCheckpointHandlingWithFiltering(t *testing.T) {
 	f.assertExpectedLogLines(expected)
 }
 
+func TestLogStreamerGrep(t *testing.T) {
+	f := newLogStreamerFixture(t)
+	f.ls.grep = regexp.MustCompile("a$|foo")
+	manifestNames := []string{"foo", "", "foo", "bar"}
+	view := f.newViewWithLogsForManifests(alphabet[:4], manifestNames, 0)
+	f.handle(view)
+
+	// Matches the text of the line, not the resource name prefix.
+	expected := f.expectedLinesWithPrefixes([]string{"alpha", "delta"}, []string{"foo", "bar"})
+	f.assertExpectedLogLines(expected)
+}
+
 type logStreamerFixture struct {
 	t          *testing.T
 	fakeStdout *bytes.Buffer
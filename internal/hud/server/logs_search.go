@@ -0,0 +1,238 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/tilt-dev/tilt/internal/hud"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+	"github.com/tilt-dev/tilt/pkg/model/logstore"
+)
+
+const (
+	logSearchDefaultLimit = 100
+	logSearchMaxLimit     = 1000
+	logSearchMaxContext   = 20
+)
+
+type LogSearchMatch struct {
+	Resource string    `json:"resource"`
+	SpanID   string    `json:"spanId"`
+	Time     time.Time `json:"time"`
+	Text     string    `json:"text"`
+	Before   []string  `json:"before,omitempty"`
+	After    []string  `json:"after,omitempty"`
+}
+
+type LogSearchResponse struct {
+	Matches []LogSearchMatch `json:"matches"`
+
+	// The number of matches across all pages.
+	Total int `json:"total"`
+
+	// The offset of the next page, or 0 if this is the last page.
+	NextOffset int `json:"nextOffset,omitempty"`
+}
+
+// Searches the logs with a regular expression:
+//
+//	/api/logs/search?q=error&context=3&resources=fe,be
+//
+// Query parameters:
+//
+//   - q: the regular expression to search for. Required.
+//   - context: how many lines of the same resource to include before and
+//     after each match. Defaults to 0.
+//   - resources: comma-separated resource names. Defaults to all resources.
+//   - span: only search the logs of this span.
+//   - limit, offset: the page of matches to return, oldest first.
+//     The limit defaults to 100.
+func (s *HeadsUpServer) HandleLogSearch(w http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+
+	q := query.Get("q")
+	if q == "" {
+		http.Error(w, "missing query parameter q", http.StatusBadRequest)
+		return
+	}
+	pattern, err := regexp.Compile(q)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid q %q: %v", q, err), http.StatusBadRequest)
+		return
+	}
+
+	contextLines, err := intParam(query.Get("context"), 0, logSearchMaxContext)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid context: %v", err), http.StatusBadRequest)
+		return
+	}
+	limit, err := intParam(query.Get("limit"), logSearchDefaultLimit, logSearchMaxLimit)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid limit: %v", err), http.StatusBadRequest)
+		return
+	}
+	offset, err := intParam(query.Get("offset"), 0, -1)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid offset: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	opts := logstore.SearchOptions{
+		Pattern: pattern,
+		SpanID:  logstore.SpanID(query.Get("span")),
+		Context: contextLines,
+	}
+
+	state := s.store.RLockState()
+	names := resourceNamesParam(query)
+	if len(names) > 0 {
+		opts.ManifestNames = make(model.ManifestNameSet, len(names))
+	}
+	for _, mn := range names {
+		if _, ok := state.ManifestState(mn); !ok {
+			s.store.RUnlockState()
+			http.Error(w, fmt.Sprintf("resource %q does not exist", mn), http.StatusNotFound)
+			return
+		}
+		opts.ManifestNames[mn] = true
+	}
+	matches := state.LogStore.Search(opts)
+	s.store.RUnlockState()
+
+	response := LogSearchResponse{
+		Matches: []LogSearchMatch{},
+		Total:   len(matches),
+	}
+	if offset < len(matches) {
+		end := offset + limit
+		if end < len(matches) {
+			response.NextOffset = end
+		} else {
+			end = len(matches)
+		}
+		for _, m := range matches[offset:end] {
+			response.Matches = append(response.Matches, LogSearchMatch{
+				Resource: m.ManifestName.String(),
+				SpanID:   string(m.SpanID),
+				Time:     m.Time,
+				Text:     m.Text,
+				Before:   m.Before,
+				After:    m.After,
+			})
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("error rendering search results: %v", err), http.StatusInternalServerError)
+	}
+}
+
+// Parses a non-negative integer query parameter.
+//
+// If max is non-negative, the value can't be greater than max.
+func intParam(value string, defaultValue int, max int) (int, error) {
+	if value == "" {
+		return defaultValue, nil
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil || i < 0 {
+		return 0, fmt.Errorf("%q must be a non-negative integer", value)
+	}
+	if max >= 0 && i > max {
+		return 0, fmt.Errorf("%q must be at most %d", value, max)
+	}
+	return i, nil
+}
+
+// Prints the matches of a log search on a running Tilt instance, page by page,
+// in the style of grep.
+func SearchLogs(ctx context.Context, u model.WebURL, token model.WebAuthToken, grep string, contextLines int, resources []string, printer *hud.IncrementalPrinter) error {
+	query := url.Values{}
+	query.Set("q", grep)
+	query.Set("context", strconv.Itoa(contextLines))
+	query.Set("limit", strconv.Itoa(logSearchMaxLimit))
+	if len(resources) > 0 {
+		query.Set("resources", strings.Join(resources, ","))
+	}
+
+	// If searching only one resource, don't need resource name prefix
+	showPrefix := len(resources) != 1
+	printed := 0
+	offset := 0
+	for {
+		query.Set("offset", strconv.Itoa(offset))
+		response, err := fetchLogSearchPage(ctx, u, token, query)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range response.Matches {
+			if contextLines > 0 && printed > 0 {
+				printer.Print([]logstore.LogLine{{Text: "--\n"}})
+			}
+			lines := []logstore.LogLine{}
+			addLines := func(texts ...string) {
+				for _, text := range texts {
+					if showPrefix {
+						text = logstore.SourcePrefix(model.ManifestName(m.Resource)) + text
+					}
+					lines = append(lines, logstore.LogLine{Text: text + "\n", SpanID: logstore.SpanID(m.SpanID), Time: m.Time})
+				}
+			}
+			addLines(m.Before...)
+			addLines(m.Text)
+			addLines(m.After...)
+			printer.Print(lines)
+			printed++
+		}
+
+		if response.NextOffset == 0 {
+			return nil
+		}
+		offset = response.NextOffset
+	}
+}
+
+func fetchLogSearchPage(ctx context.Context, u model.WebURL, token model.WebAuthToken, query url.Values) (LogSearchResponse, error) {
+	u.Path = "/api/logs/search"
+	u.RawQuery = query.Encode()
+	logger.Get(ctx).Debugf("searching logs at %s", u.String())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return LogSearchResponse{}, err
+	}
+	AddWebAuth(req.Header, token)
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return LogSearchResponse{}, errors.Wrapf(err, "searching logs at %s", u.String())
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(res.Body)
+		return LogSearchResponse{}, fmt.Errorf("searching logs: %s", strings.TrimSpace(string(body)))
+	}
+
+	var response LogSearchResponse
+	err = json.NewDecoder(res.Body).Decode(&response)
+	if err != nil {
+		return LogSearchResponse{}, errors.Wrap(err, "reading log search results")
+	}
+	return response, nil
+}
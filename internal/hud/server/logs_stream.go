@@ -0,0 +1,174 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+	"github.com/tilt-dev/tilt/pkg/model/logstore"
+)
+
+// How often the log stream checks for new logs.
+var logStreamPollInterval = 200 * time.Millisecond
+
+var logStreamLevels = map[string]logger.Level{
+	"debug":   logger.DebugLvl,
+	"verbose": logger.VerboseLvl,
+	"info":    logger.InfoLvl,
+	"warn":    logger.WarnLvl,
+	"error":   logger.ErrorLvl,
+}
+
+// One event of the log stream.
+type LogStreamEvent struct {
+	Resource string    `json:"resource"`
+	SpanID   string    `json:"spanId"`
+	Level    string    `json:"level"`
+	Time     time.Time `json:"time"`
+
+	// Part of the log, as it was written. It may be part of a line,
+	// and may contain colors.
+	Text string `json:"text"`
+}
+
+// Streams the logs as server-sent events, so that tools can tail the logs
+// without speaking the websocket protocol of the web UI:
+//
+//	curl -N 'localhost:10350/api/logs/stream?resources=fe&level=warn'
+//
+// Each event is a "log" event with a LogStreamEvent as JSON data. The last event
+// of each batch has the checkpoint to resume from as its ID, so clients that
+// reconnect with Last-Event-ID only get the logs they missed.
+//
+// Query parameters, all optional:
+//
+//   - resources (or resource): comma-separated resource names. Defaults to all logs.
+//   - span: only stream the logs of this span.
+//   - level: only stream logs at this level or above. One of debug, verbose,
+//     info, warn or error. Defaults to all logs.
+//   - checkpoint: the checkpoint to start from. Defaults to 0, the oldest logs
+//     that Tilt still has. The Last-Event-ID header takes precedence.
+func (s *HeadsUpServer) HandleLogStream(w http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+
+	opts := logstore.SegmentOptions{
+		SpanID: logstore.SpanID(query.Get("span")),
+	}
+	if level := query.Get("level"); level != "" {
+		lvl, ok := logStreamLevels[strings.ToLower(level)]
+		if !ok {
+			http.Error(w, fmt.Sprintf("invalid level %q. Must be one of: debug, verbose, info, warn, error", level),
+				http.StatusBadRequest)
+			return
+		}
+		opts.MinLevel = lvl
+	}
+
+	checkpointParam := query.Get("checkpoint")
+	if lastEventID := req.Header.Get("Last-Event-ID"); lastEventID != "" {
+		checkpointParam = lastEventID
+	}
+	start, err := intParam(checkpointParam, 0, -1)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid checkpoint: %v", err), http.StatusBadRequest)
+		return
+	}
+	checkpoint := logstore.Checkpoint(start)
+
+	names := resourceNamesParam(query)
+	for _, value := range query["resource"] {
+		if name := strings.TrimSpace(value); name != "" {
+			names = append(names, model.ManifestName(name))
+		}
+	}
+	state := s.store.RLockState()
+	if len(names) > 0 {
+		opts.ManifestNames = make(model.ManifestNameSet, len(names))
+	}
+	for _, mn := range names {
+		if _, ok := state.ManifestState(mn); !ok {
+			s.store.RUnlockState()
+			http.Error(w, fmt.Sprintf("resource %q does not exist", mn), http.StatusNotFound)
+			return
+		}
+		opts.ManifestNames[mn] = true
+	}
+	s.store.RUnlockState()
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ticker := time.NewTicker(logStreamPollInterval)
+	defer ticker.Stop()
+	for {
+		state := s.store.RLockState()
+		segments, next := state.LogStore.SegmentsSince(checkpoint, opts)
+		s.store.RUnlockState()
+		checkpoint = next
+
+		if len(segments) > 0 {
+			err := writeLogStreamEvents(w, segments, checkpoint)
+			if err != nil {
+				// The client went away.
+				return
+			}
+			flusher.Flush()
+		}
+
+		select {
+		case <-req.Context().Done():
+			return
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func writeLogStreamEvents(w http.ResponseWriter, segments []logstore.Segment, checkpoint logstore.Checkpoint) error {
+	var sb strings.Builder
+	for i, seg := range segments {
+		data, err := json.Marshal(LogStreamEvent{
+			Resource: seg.ManifestName.String(),
+			SpanID:   string(seg.SpanID),
+			Level:    logStreamLevelName(seg.Level),
+			Time:     seg.Time,
+			Text:     seg.Text,
+		})
+		if err != nil {
+			return err
+		}
+		sb.WriteString("event: log\n")
+		if i == len(segments)-1 {
+			sb.WriteString("id: " + strconv.Itoa(int(checkpoint)) + "\n")
+		}
+		sb.WriteString("data: ")
+		sb.Write(data)
+		sb.WriteString("\n\n")
+	}
+	_, err := w.Write([]byte(sb.String()))
+	return err
+}
+
+func logStreamLevelName(level logger.Level) string {
+	for name, lvl := range logStreamLevels {
+		if lvl == level {
+			return name
+		}
+	}
+	return "info"
+}
@@ -0,0 +1,42 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/tilt-dev/tilt/internal/msgcat"
+)
+
+type messagesPayload struct {
+	Locale   string         `json:"locale"`
+	Messages []msgcat.Entry `json:"messages"`
+}
+
+// Serves the catalog of user-facing status messages, so that clients can
+// render (or translate) the MessageCode and MessageArgs on API objects.
+//
+// The locale comes from the ?locale= param, or else the Accept-Language header.
+// Messages that aren't translated fall back to English.
+func (s *HeadsUpServer) HandleMessages(w http.ResponseWriter, req *http.Request) {
+	locale := req.URL.Query().Get("locale")
+	if locale == "" {
+		locale = preferredLocale(req.Header.Get("Accept-Language"))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(messagesPayload{
+		Locale:   locale,
+		Messages: msgcat.Entries(locale),
+	})
+}
+
+// e.g., "fr-CH, fr;q=0.9, en;q=0.8" -> "fr-CH"
+func preferredLocale(acceptLanguage string) string {
+	first := strings.Split(acceptLanguage, ",")[0]
+	first = strings.TrimSpace(strings.Split(first, ";")[0])
+	if first == "" || first == "*" {
+		return msgcat.DefaultLocale
+	}
+	return first
+}
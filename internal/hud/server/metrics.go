@@ -0,0 +1,121 @@
+package server
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+const (
+	buildResultSuccess = "success"
+	buildResultError   = "error"
+)
+
+// Prometheus metrics for Tilt's own health, so that platform teams
+// can monitor many developer Tilt instances at once.
+//
+// Reconciler timings, Kubernetes API request counts (by status code),
+// and Go runtime stats come from the controller-runtime registry,
+// which we serve alongside our own.
+type serverMetrics struct {
+	registry *prometheus.Registry
+
+	buildDuration      *prometheus.HistogramVec
+	liveUpdateDuration *prometheus.HistogramVec
+
+	mu sync.Mutex
+
+	// The span of the last build we observed for each resource,
+	// so that each build is only counted once.
+	lastObserved map[model.ManifestName]model.LogSpanID
+}
+
+func newServerMetrics(wsList *WebsocketList) *serverMetrics {
+	m := &serverMetrics{
+		registry: prometheus.NewRegistry(),
+		buildDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "tilt_build_duration_seconds",
+			Help:    "How long each completed build took, by resource and result.",
+			Buckets: prometheus.ExponentialBuckets(0.25, 2, 12),
+		}, []string{"resource", "result"}),
+		liveUpdateDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "tilt_live_update_duration_seconds",
+			Help:    "How long each completed live update took, by resource.",
+			Buckets: prometheus.ExponentialBuckets(0.05, 2, 12),
+		}, []string{"resource"}),
+		lastObserved: make(map[model.ManifestName]model.LogSpanID),
+	}
+	m.registry.MustRegister(
+		m.buildDuration,
+		m.liveUpdateDuration,
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "tilt_websocket_clients",
+			Help: "The number of web UI clients connected over websockets.",
+		}, func() float64 {
+			return float64(wsList.Len())
+		}),
+	)
+	return m
+}
+
+// Records any builds that finished since the last time we looked.
+func (m *serverMetrics) observeBuilds(st store.RStore) {
+	state := st.RLockState()
+	defer st.RUnlockState()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	seen := make(map[model.ManifestName]bool, len(state.ManifestTargets))
+	for _, mt := range state.ManifestTargets {
+		name := mt.Manifest.Name
+		seen[name] = true
+
+		ms := mt.State
+		if ms == nil {
+			continue
+		}
+		build := ms.LastBuild()
+		if build.Empty() || build.FinishTime.IsZero() || build.SpanID == m.lastObserved[name] {
+			continue
+		}
+		m.lastObserved[name] = build.SpanID
+
+		result := buildResultSuccess
+		if build.Error != nil {
+			result = buildResultError
+		}
+		duration := build.Duration().Seconds()
+		m.buildDuration.WithLabelValues(name.String(), result).Observe(duration)
+		if build.HasBuildType(model.BuildTypeLiveUpdate) {
+			m.liveUpdateDuration.WithLabelValues(name.String()).Observe(duration)
+		}
+	}
+
+	for name := range m.lastObserved {
+		if !seen[name] {
+			delete(m.lastObserved, name)
+		}
+	}
+}
+
+func (m *serverMetrics) handler() http.Handler {
+	gatherers := prometheus.Gatherers{m.registry, ctrlmetrics.Registry}
+	return promhttp.HandlerFor(gatherers, promhttp.HandlerOpts{})
+}
+
+// Records builds that finished since the last store change.
+//
+// Called by the HeadsUpServerController on every store change.
+func (s *HeadsUpServer) ObserveBuilds(st store.RStore) {
+	if s.metrics == nil {
+		return
+	}
+	s.metrics.observeBuilds(st)
+}
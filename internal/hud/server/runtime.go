@@ -0,0 +1,350 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// LabelRuntimeObject marks the API objects that were created at runtime
+// through /api/runtime, rather than by the Tiltfile.
+//
+// The Tiltfile doesn't own them, so they survive Tiltfile reloads,
+// and last until they're deleted or Tilt exits.
+const LabelRuntimeObject = "tilt.dev/runtime-object"
+
+// The kinds of objects that can be created at runtime, by their path
+// under /api/runtime.
+type runtimeKind struct {
+	newObject func() ctrlclient.Object
+	newList   func() ctrlclient.ObjectList
+}
+
+var runtimeKinds = map[string]runtimeKind{
+	"portforwards": {
+		newObject: func() ctrlclient.Object { return &v1alpha1.PortForward{} },
+		newList:   func() ctrlclient.ObjectList { return &v1alpha1.PortForwardList{} },
+	},
+	"tasks": {
+		newObject: func() ctrlclient.Object { return &v1alpha1.Cmd{} },
+		newList:   func() ctrlclient.ObjectList { return &v1alpha1.CmdList{} },
+	},
+	"filewatches": {
+		newObject: func() ctrlclient.Object { return &v1alpha1.FileWatch{} },
+		newList:   func() ctrlclient.ObjectList { return &v1alpha1.FileWatchList{} },
+	},
+}
+
+// Forwards a local port to the pods of a Kubernetes resource.
+type runtimePortForwardPayload struct {
+	Resource string `json:"resource"`
+
+	// Exactly one of ServiceName or PodSelector is required, so that the
+	// forward follows the resource's pods when they restart.
+	ServiceName string            `json:"serviceName,omitempty"`
+	PodSelector map[string]string `json:"podSelector,omitempty"`
+	Namespace   string            `json:"namespace"`
+
+	LocalPort     int32  `json:"localPort,omitempty"`
+	ContainerPort int32  `json:"containerPort"`
+	Host          string `json:"host,omitempty"`
+}
+
+// Runs a command once, with its logs in the resource's log pane.
+type runtimeTaskPayload struct {
+	Resource string   `json:"resource"`
+	Args     []string `json:"args"`
+
+	// Defaults to the directory of the main Tiltfile.
+	// Relative paths are relative to that directory.
+	Dir string   `json:"dir,omitempty"`
+	Env []string `json:"env,omitempty"`
+}
+
+// Updates the resource when any of the paths change.
+type runtimeFileWatchPayload struct {
+	Resource string `json:"resource"`
+
+	// Relative paths are relative to the directory of the main Tiltfile.
+	Paths []string `json:"paths"`
+}
+
+type runtimeObjectPayload struct {
+	Kind     string `json:"kind"`
+	Name     string `json:"name"`
+	Resource string `json:"resource"`
+}
+
+type runtimeObjectsPayload struct {
+	Objects []runtimeObjectPayload `json:"objects"`
+}
+
+// Lists the objects created at runtime:
+//
+//	GET /api/runtime
+func (s *HeadsUpServer) HandleListRuntimeObjects(w http.ResponseWriter, req *http.Request) {
+	kinds := make([]string, 0, len(runtimeKinds))
+	for kind := range runtimeKinds {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	response := runtimeObjectsPayload{Objects: []runtimeObjectPayload{}}
+	for _, kind := range kinds {
+		list := runtimeKinds[kind].newList()
+		err := s.ctrlClient.List(req.Context(), list, ctrlclient.HasLabels{LabelRuntimeObject})
+		if err != nil {
+			http.Error(w, fmt.Sprintf("error listing %s: %v", kind, err), http.StatusInternalServerError)
+			return
+		}
+
+		var objects []runtimeObjectPayload
+		_ = meta.EachListItem(list, func(item runtime.Object) error {
+			obj := item.(ctrlclient.Object)
+			objects = append(objects, runtimeObjectPayload{
+				Kind:     kind,
+				Name:     obj.GetName(),
+				Resource: obj.GetAnnotations()[v1alpha1.AnnotationManifest],
+			})
+			return nil
+		})
+		sort.Slice(objects, func(i, j int) bool { return objects[i].Name < objects[j].Name })
+		response.Objects = append(response.Objects, objects...)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// Creates a port forward at runtime:
+//
+//	POST /api/runtime/portforwards
+func (s *HeadsUpServer) HandleCreateRuntimePortForward(w http.ResponseWriter, req *http.Request) {
+	var payload runtimePortForwardPayload
+	if !decodeRuntimePayload(w, req, &payload) {
+		return
+	}
+
+	mn := model.ManifestName(payload.Resource)
+	state := s.store.RLockState()
+	mt, ok := state.ManifestTargets[mn]
+	var cluster string
+	if ok {
+		cluster = mt.Manifest.K8sTarget().Cluster
+	}
+	isK8s := ok && mt.Manifest.IsK8s()
+	s.store.RUnlockState()
+
+	if !ok {
+		http.Error(w, fmt.Sprintf("resource %q does not exist", mn), http.StatusNotFound)
+		return
+	}
+	if !isK8s {
+		http.Error(w, fmt.Sprintf("resource %q is not a Kubernetes resource", mn), http.StatusBadRequest)
+		return
+	}
+	if payload.ServiceName == "" && len(payload.PodSelector) == 0 {
+		http.Error(w, "one of serviceName or podSelector is required", http.StatusBadRequest)
+		return
+	}
+
+	pf := &v1alpha1.PortForward{
+		ObjectMeta: s.runtimeObjectMeta(mn),
+		Spec: v1alpha1.PortForwardSpec{
+			ServiceName: payload.ServiceName,
+			PodSelector: payload.PodSelector,
+			Namespace:   payload.Namespace,
+			Cluster:     cluster,
+			Forwards: []v1alpha1.Forward{{
+				LocalPort:     payload.LocalPort,
+				ContainerPort: payload.ContainerPort,
+				Host:          payload.Host,
+			}},
+		},
+	}
+	errs := pf.Validate(req.Context())
+	if payload.ContainerPort <= 0 {
+		errs = append(errs, field.Required(field.NewPath("containerPort"), "must be a port number"))
+	}
+	s.createRuntimeObject(w, req, pf, errs)
+}
+
+// Runs a one-off task at runtime:
+//
+//	POST /api/runtime/tasks
+func (s *HeadsUpServer) HandleCreateRuntimeTask(w http.ResponseWriter, req *http.Request) {
+	var payload runtimeTaskPayload
+	if !decodeRuntimePayload(w, req, &payload) {
+		return
+	}
+
+	mn := model.ManifestName(payload.Resource)
+	state := s.store.RLockState()
+	_, ok := state.ManifestState(mn)
+	tiltfileDir := filepath.Dir(state.MainTiltfilePath())
+	s.store.RUnlockState()
+
+	if !ok {
+		http.Error(w, fmt.Sprintf("resource %q does not exist", mn), http.StatusNotFound)
+		return
+	}
+
+	cmd := &v1alpha1.Cmd{
+		ObjectMeta: s.runtimeObjectMeta(mn),
+		Spec: v1alpha1.CmdSpec{
+			Args: payload.Args,
+			Dir:  absRuntimePath(tiltfileDir, payload.Dir),
+			Env:  payload.Env,
+		},
+	}
+	errs := cmd.Validate(req.Context())
+	if len(payload.Args) == 0 {
+		errs = append(errs, field.Required(field.NewPath("args"), "cannot be an empty list"))
+	}
+	s.createRuntimeObject(w, req, cmd, errs)
+}
+
+// Watches extra paths for a resource at runtime:
+//
+//	POST /api/runtime/filewatches
+func (s *HeadsUpServer) HandleCreateRuntimeFileWatch(w http.ResponseWriter, req *http.Request) {
+	var payload runtimeFileWatchPayload
+	if !decodeRuntimePayload(w, req, &payload) {
+		return
+	}
+
+	mn := model.ManifestName(payload.Resource)
+	state := s.store.RLockState()
+	mt, ok := state.ManifestTargets[mn]
+	var targetID model.TargetID
+	if ok && mt.Manifest.DeployTarget != nil {
+		targetID = mt.Manifest.DeployTarget.ID()
+	}
+	tiltfileDir := filepath.Dir(state.MainTiltfilePath())
+	s.store.RUnlockState()
+
+	if !ok {
+		http.Error(w, fmt.Sprintf("resource %q does not exist", mn), http.StatusNotFound)
+		return
+	}
+	if targetID.Empty() {
+		http.Error(w, fmt.Sprintf("resource %q has nothing to update", mn), http.StatusBadRequest)
+		return
+	}
+
+	paths := make([]string, 0, len(payload.Paths))
+	for _, p := range payload.Paths {
+		paths = append(paths, absRuntimePath(tiltfileDir, p))
+	}
+
+	fw := &v1alpha1.FileWatch{
+		ObjectMeta: s.runtimeObjectMeta(mn),
+		Spec:       v1alpha1.FileWatchSpec{WatchedPaths: paths},
+	}
+	fw.Annotations[v1alpha1.AnnotationTargetID] = targetID.String()
+	s.createRuntimeObject(w, req, fw, fw.Validate(req.Context()))
+}
+
+// Deletes an object created at runtime:
+//
+//	DELETE /api/runtime/{kind}/{name}
+//
+// Only deletes objects created through /api/runtime, never objects
+// from the Tiltfile.
+func (s *HeadsUpServer) HandleDeleteRuntimeObject(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	kind, ok := runtimeKinds[vars["kind"]]
+	if !ok {
+		http.Error(w, fmt.Sprintf("unknown kind %q", vars["kind"]), http.StatusNotFound)
+		return
+	}
+
+	obj := kind.newObject()
+	err := s.ctrlClient.Get(req.Context(), types.NamespacedName{Name: vars["name"]}, obj)
+	if err == nil && obj.GetLabels()[LabelRuntimeObject] == "" {
+		err = apierrors.NewNotFound(v1alpha1.Resource(vars["kind"]), vars["name"])
+	}
+	if err == nil {
+		err = s.ctrlClient.Delete(req.Context(), obj)
+	}
+	if err != nil {
+		code := http.StatusInternalServerError
+		if apierrors.IsNotFound(err) {
+			code = http.StatusNotFound
+		}
+		http.Error(w, fmt.Sprintf("error deleting %s %q: %v", vars["kind"], vars["name"], err), code)
+		return
+	}
+}
+
+// Objects created at runtime can run commands on the developer's machine,
+// so only accept them from the web UI or the CLI.
+func decodeRuntimePayload(w http.ResponseWriter, req *http.Request, payload interface{}) bool {
+	if !checkSameOriginJSON(w, req) {
+		return false
+	}
+
+	decoder := json.NewDecoder(req.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(payload)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("error parsing JSON payload: %v", err), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// Every object gets its own name, and shows its logs under the resource.
+func (s *HeadsUpServer) runtimeObjectMeta(mn model.ManifestName) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:        fmt.Sprintf("%s:runtime-%s", mn, uuid.New().String()[:8]),
+		Labels:      map[string]string{LabelRuntimeObject: "true"},
+		Annotations: map[string]string{v1alpha1.AnnotationManifest: mn.String()},
+	}
+}
+
+func (s *HeadsUpServer) createRuntimeObject(w http.ResponseWriter, req *http.Request, obj ctrlclient.Object, errs field.ErrorList) {
+	if len(errs) > 0 {
+		http.Error(w, fmt.Sprintf("invalid request: %v", errs.ToAggregate()), http.StatusUnprocessableEntity)
+		return
+	}
+
+	err := s.ctrlClient.Create(req.Context(), obj)
+	if err != nil {
+		code := http.StatusInternalServerError
+		if apierrors.IsInvalid(err) {
+			code = http.StatusUnprocessableEntity
+		}
+		http.Error(w, fmt.Sprintf("error creating %s: %v", obj.GetName(), err), code)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(obj)
+}
+
+func absRuntimePath(dir string, path string) string {
+	if path == "" {
+		return dir
+	}
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
@@ -0,0 +1,191 @@
+package server_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/tilt-dev/tilt/internal/hud/server"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestRuntimePortForward(t *testing.T) {
+	f := newRuntimeFixture(t)
+
+	status, body := f.request(http.MethodPost, "/api/runtime/portforwards",
+		`{"resource":"fe","serviceName":"fe","namespace":"default","localPort":8080,"containerPort":80}`)
+	require.Equal(t, http.StatusCreated, status, body)
+
+	var pf v1alpha1.PortForward
+	require.NoError(t, json.Unmarshal([]byte(body), &pf))
+	assert.True(t, strings.HasPrefix(pf.Name, "fe:runtime-"), pf.Name)
+	assert.Equal(t, "fe", pf.Annotations[v1alpha1.AnnotationManifest])
+	assert.Equal(t, "true", pf.Labels[server.LabelRuntimeObject])
+	assert.Equal(t, "fe", pf.Spec.ServiceName)
+	assert.Equal(t, []v1alpha1.Forward{{LocalPort: 8080, ContainerPort: 80}}, pf.Spec.Forwards)
+
+	var stored v1alpha1.PortForward
+	require.NoError(t, f.ctrlClient.Get(f.ctx, types.NamespacedName{Name: pf.Name}, &stored))
+	assert.Equal(t, pf.Spec, stored.Spec)
+}
+
+func TestRuntimePortForwardInvalid(t *testing.T) {
+	f := newRuntimeFixture(t)
+
+	status, body := f.request(http.MethodPost, "/api/runtime/portforwards",
+		`{"resource":"fe","serviceName":"fe","containerPort":80}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, status)
+	assert.Contains(t, body, "Namespace is required")
+
+	status, body = f.request(http.MethodPost, "/api/runtime/portforwards",
+		`{"resource":"fe","namespace":"default","containerPort":80}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Contains(t, body, "one of serviceName or podSelector is required")
+
+	status, body = f.request(http.MethodPost, "/api/runtime/portforwards",
+		`{"resource":"local","serviceName":"fe","namespace":"default","containerPort":80}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Contains(t, body, `resource "local" is not a Kubernetes resource`)
+
+	status, body = f.request(http.MethodPost, "/api/runtime/portforwards",
+		`{"resource":"nope","serviceName":"fe","namespace":"default","containerPort":80}`)
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.Contains(t, body, `resource "nope" does not exist`)
+
+	status, body = f.request(http.MethodPost, "/api/runtime/portforwards", `{"resource":"fe","port":80}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Contains(t, body, "error parsing JSON")
+}
+
+func TestRuntimeTask(t *testing.T) {
+	f := newRuntimeFixture(t)
+
+	status, body := f.request(http.MethodPost, "/api/runtime/tasks",
+		`{"resource":"local","args":["make","seed"],"dir":"scripts"}`)
+	require.Equal(t, http.StatusCreated, status, body)
+
+	var cmd v1alpha1.Cmd
+	require.NoError(t, json.Unmarshal([]byte(body), &cmd))
+	assert.Equal(t, []string{"make", "seed"}, cmd.Spec.Args)
+	assert.Equal(t, "/src/scripts", cmd.Spec.Dir)
+	assert.Equal(t, "local", cmd.Annotations[v1alpha1.AnnotationManifest])
+
+	status, body = f.request(http.MethodPost, "/api/runtime/tasks", `{"resource":"local","args":[]}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, status)
+	assert.Contains(t, body, "args: Required value")
+}
+
+func TestRuntimeFileWatch(t *testing.T) {
+	f := newRuntimeFixture(t)
+
+	status, body := f.request(http.MethodPost, "/api/runtime/filewatches",
+		`{"resource":"local","paths":["config", "/etc/app.conf"]}`)
+	require.Equal(t, http.StatusCreated, status, body)
+
+	var fw v1alpha1.FileWatch
+	require.NoError(t, json.Unmarshal([]byte(body), &fw))
+	assert.Equal(t, []string{"/src/config", "/etc/app.conf"}, fw.Spec.WatchedPaths)
+	assert.Equal(t, "local:local", fw.Annotations[v1alpha1.AnnotationTargetID])
+
+	status, body = f.request(http.MethodPost, "/api/runtime/filewatches", `{"resource":"local","paths":[]}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, status)
+	assert.Contains(t, body, "cannot be an empty list")
+}
+
+func TestRuntimeListAndDelete(t *testing.T) {
+	f := newRuntimeFixture(t)
+
+	// Objects from the Tiltfile aren't runtime objects.
+	require.NoError(t, f.ctrlClient.Create(f.ctx, &v1alpha1.Cmd{
+		ObjectMeta: metav1.ObjectMeta{Name: "local:serve"},
+		Spec:       v1alpha1.CmdSpec{Args: []string{"serve"}},
+	}))
+
+	status, body := f.request(http.MethodPost, "/api/runtime/tasks", `{"resource":"local","args":["make"]}`)
+	require.Equal(t, http.StatusCreated, status, body)
+	var cmd v1alpha1.Cmd
+	require.NoError(t, json.Unmarshal([]byte(body), &cmd))
+
+	status, body = f.request(http.MethodGet, "/api/runtime", "")
+	require.Equal(t, http.StatusOK, status, body)
+	assert.JSONEq(t, `{"objects":[{"kind":"tasks","name":"`+cmd.Name+`","resource":"local"}]}`, body)
+
+	status, body = f.request(http.MethodDelete, "/api/runtime/tasks/local:serve", "")
+	assert.Equal(t, http.StatusNotFound, status, body)
+
+	status, body = f.request(http.MethodDelete, "/api/runtime/tasks/"+cmd.Name, "")
+	require.Equal(t, http.StatusOK, status, body)
+
+	status, body = f.request(http.MethodGet, "/api/runtime", "")
+	require.Equal(t, http.StatusOK, status, body)
+	assert.JSONEq(t, `{"objects":[]}`, body)
+}
+
+func TestRuntimeRejectsCrossSiteRequests(t *testing.T) {
+	f := newRuntimeFixture(t)
+	body := `{"resource":"local","args":["make"]}`
+
+	// A form or a fetch() without a preflight.
+	status, _ := f.requestWithHeader(http.MethodPost, "/api/runtime/tasks", body,
+		http.Header{"Content-Type": {"text/plain"}})
+	assert.Equal(t, http.StatusUnsupportedMediaType, status)
+
+	status, _ = f.requestWithHeader(http.MethodPost, "/api/runtime/tasks", body,
+		http.Header{"Content-Type": {"application/json"}, "Origin": {"https://evil.example.com"}})
+	assert.Equal(t, http.StatusForbidden, status)
+
+	status, _ = f.requestWithHeader(http.MethodPost, "/api/runtime/filewatches", `{"resource":"local","paths":["src"]}`,
+		http.Header{"Content-Type": {"application/json"}, "Sec-Fetch-Site": {"cross-site"}})
+	assert.Equal(t, http.StatusForbidden, status)
+
+	// The web UI.
+	status, body = f.requestWithHeader(http.MethodPost, "/api/runtime/tasks", body,
+		http.Header{
+			"Content-Type":   {"application/json; charset=utf-8"},
+			"Origin":         {"http://example.com"},
+			"Sec-Fetch-Site": {"same-origin"},
+		})
+	assert.Equal(t, http.StatusCreated, status, body)
+}
+
+type runtimeFixture struct {
+	*serverFixture
+}
+
+func newRuntimeFixture(t *testing.T) *runtimeFixture {
+	f := newTestFixture(t)
+
+	state := f.st.LockMutableStateForTesting()
+	state.Tiltfiles[model.MainTiltfileManifestName.String()] = &v1alpha1.Tiltfile{
+		ObjectMeta: metav1.ObjectMeta{Name: model.MainTiltfileManifestName.String()},
+		Spec:       v1alpha1.TiltfileSpec{Path: "/src/Tiltfile"},
+	}
+	state.UpsertManifestTarget(store.NewManifestTarget(model.Manifest{Name: "fe"}.
+		WithDeployTarget(model.K8sTarget{Name: "fe"})))
+	state.UpsertManifestTarget(store.NewManifestTarget(model.Manifest{Name: "local"}.
+		WithDeployTarget(model.NewLocalTarget("local", model.Cmd{}, model.ToHostCmd("serve"), nil))))
+	f.st.UnlockMutableState()
+
+	return &runtimeFixture{serverFixture: f}
+}
+
+func (f *runtimeFixture) request(method, path, body string) (int, string) {
+	return f.requestWithHeader(method, path, body, http.Header{"Content-Type": {"application/json"}})
+}
+
+func (f *runtimeFixture) requestWithHeader(method, path, body string, header http.Header) (int, string) {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header = header
+	rr := httptest.NewRecorder()
+	f.serv.Router().ServeHTTP(rr, req)
+	return rr.Code, rr.Body.String()
+}
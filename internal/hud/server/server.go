@@ -7,6 +7,7 @@ import (
 	"log"
 	"net/http"
 	_ "net/http/pprof"
+	"time"
 
 	"google.golang.org/protobuf/types/known/timestamppb"
 
@@ -21,11 +22,14 @@ import (
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 
 	tiltanalytics "github.com/tilt-dev/tilt/internal/analytics"
+	"github.com/tilt-dev/tilt/internal/engine/buildcontrol"
 	"github.com/tilt-dev/tilt/internal/hud/webview"
+	"github.com/tilt-dev/tilt/internal/snapshots"
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/internal/store/tiltfiles"
 	"github.com/tilt-dev/tilt/pkg/assets"
 	"github.com/tilt-dev/tilt/pkg/model"
+	"github.com/tilt-dev/tilt/pkg/model/logstore"
 	proto_webview "github.com/tilt-dev/tilt/pkg/webview"
 	"github.com/tilt-dev/wmclient/pkg/analytics"
 )
@@ -47,9 +51,42 @@ type analyticsOptPayload struct {
 	Opt string `json:"opt"`
 }
 
-type triggerPayload struct {
+type TriggerPayload struct {
 	ManifestNames []string          `json:"manifest_names"`
 	BuildReason   model.BuildReason `json:"build_reason"`
+
+	// Also trigger every resource with any of these labels.
+	Labels []string `json:"labels,omitempty"`
+
+	// Skip the build cache for this build.
+	NoCache bool `json:"no_cache"`
+}
+
+type TriggerStatus string
+
+const (
+	TriggerStatusQueued   TriggerStatus = "queued"
+	TriggerStatusDisabled TriggerStatus = "disabled"
+	TriggerStatusNotFound TriggerStatus = "not_found"
+)
+
+type TriggerResult struct {
+	Name   string        `json:"name"`
+	Status TriggerStatus `json:"status"`
+}
+
+func (r TriggerResult) Error() string {
+	switch r.Status {
+	case TriggerStatusDisabled:
+		return fmt.Sprintf("resource %q is currently disabled", r.Name)
+	case TriggerStatusNotFound:
+		return fmt.Sprintf("resource %q does not exist", r.Name)
+	}
+	return ""
+}
+
+type TriggerResponse struct {
+	Results []TriggerResult `json:"results"`
 }
 
 type overrideTriggerModePayload struct {
@@ -57,6 +94,10 @@ type overrideTriggerModePayload struct {
 	TriggerMode   int      `json:"trigger_mode"`
 }
 
+type snapshotUploadPayload struct {
+	URL string `json:"url"`
+}
+
 type HeadsUpServer struct {
 	ctx        context.Context
 	store      *store.Store
@@ -64,6 +105,8 @@ type HeadsUpServer struct {
 	a          *tiltanalytics.TiltAnalytics
 	wsList     *WebsocketList
 	ctrlClient ctrlclient.Client
+	authToken  model.WebAuthToken
+	metrics    *serverMetrics
 }
 
 func ProvideHeadsUpServer(
@@ -72,7 +115,8 @@ func ProvideHeadsUpServer(
 	assetServer assets.Server,
 	analytics *tiltanalytics.TiltAnalytics,
 	wsList *WebsocketList,
-	ctrlClient ctrlclient.Client) (*HeadsUpServer, error) {
+	ctrlClient ctrlclient.Client,
+	authToken model.WebAuthToken) (*HeadsUpServer, error) {
 	r := mux.NewRouter().UseEncodedPath()
 	s := &HeadsUpServer{
 		ctx:        ctx,
@@ -81,19 +125,46 @@ func ProvideHeadsUpServer(
 		a:          analytics,
 		wsList:     wsList,
 		ctrlClient: ctrlClient,
+		authToken:  authToken,
+		metrics:    newServerMetrics(wsList),
 	}
-
 	r.HandleFunc("/api/view", s.ViewJSON)
 	r.HandleFunc("/api/dump/engine", s.DumpEngineJSON)
+	r.HandleFunc("/api/dump/scheduler", s.DumpSchedulerJSON)
 	r.HandleFunc("/api/analytics", s.HandleAnalytics)
 	r.HandleFunc("/api/analytics_opt", s.HandleAnalyticsOpt)
 	r.HandleFunc("/api/trigger", s.HandleTrigger)
+	r.HandleFunc("/api/triggers/{name}", s.HandleExternalTrigger).Methods("POST")
 	r.HandleFunc("/api/override/trigger_mode", s.HandleOverrideTriggerMode)
+	r.HandleFunc("/api/snapshot/upload", s.HandleSnapshotUpload).Methods("POST")
+	r.HandleFunc("/api/snapshot/save", s.HandleSnapshotSave).Methods("POST")
+	r.HandleFunc("/api/snapshot/export", s.HandleSnapshotExport).Methods("GET")
 	// this endpoint is only used for testing snapshots in development
 	r.HandleFunc("/api/snapshot/{snapshot_id}", s.SnapshotJSON)
 	r.HandleFunc("/api/websocket_token", s.WebsocketToken)
 	r.HandleFunc("/ws/view", s.ViewWebsocket)
+	r.HandleFunc("/api/resources/{name}/builds", s.HandleListBuilds).Methods("GET")
+	r.HandleFunc("/api/resources/{name}/builds/{buildID}", s.HandleGetBuild).Methods("GET")
+	r.HandleFunc("/api/resources/{name}/disable", s.HandleDisableResource).Methods("POST")
+	r.HandleFunc("/api/resources/{name}/enable", s.HandleEnableResource).Methods("POST")
+	r.HandleFunc("/api/trigger_history", s.HandleTriggerHistory).Methods("GET")
+	r.HandleFunc("/api/websockets", s.HandleWebsocketStats).Methods("GET")
+	r.HandleFunc("/api/websockets/{id}/disconnect", s.HandleWebsocketDisconnect).Methods("POST")
 	r.HandleFunc("/api/set_tiltfile_args", s.HandleSetTiltfileArgs).Methods("POST")
+	r.HandleFunc("/api/settings/log_prefix", s.HandleGetLogPrefixFormat).Methods("GET")
+	r.HandleFunc("/api/settings/log_prefix", s.HandleSetLogPrefixFormat).Methods("POST")
+	r.HandleFunc("/api/messages", s.HandleMessages).Methods("GET")
+	r.HandleFunc("/api/logs/archive", s.HandleLogArchive).Methods("GET")
+	r.HandleFunc("/api/logs/search", s.HandleLogSearch).Methods("GET")
+	r.HandleFunc("/api/logs/stream", s.HandleLogStream).Methods("GET")
+	r.HandleFunc("/api/runtime", s.HandleListRuntimeObjects).Methods("GET")
+	r.HandleFunc("/api/runtime/portforwards", s.HandleCreateRuntimePortForward).Methods("POST")
+	r.HandleFunc("/api/runtime/tasks", s.HandleCreateRuntimeTask).Methods("POST")
+	r.HandleFunc("/api/runtime/filewatches", s.HandleCreateRuntimeFileWatch).Methods("POST")
+	r.HandleFunc("/api/runtime/{kind}/{name}", s.HandleDeleteRuntimeObject).Methods("DELETE")
+	r.HandleFunc("/healthz", s.HandleHealthz).Methods("GET")
+	r.HandleFunc("/readyz", s.HandleReadyz).Methods("GET")
+	r.Handle("/metrics", s.metrics.handler()).Methods("GET")
 
 	r.PathPrefix("/").Handler(s.cookieWrapper(assetServer))
 
@@ -138,27 +209,56 @@ func (s *HeadsUpServer) ViewJSON(w http.ResponseWriter, req *http.Request) {
 }
 
 // Dump the JSON engine over http. Only intended for 'tilt dump engine'.
+// The engine state, plus the build scheduler's decisions about that state.
+type engineDump struct {
+	*store.EngineState
+	Scheduler buildcontrol.SchedulerStatus
+}
+
 func (s *HeadsUpServer) DumpEngineJSON(w http.ResponseWriter, req *http.Request) {
 	state := s.store.RLockState()
 	defer s.store.RUnlockState()
 
 	encoder := store.CreateEngineStateEncoder(w)
-	err := encoder.Encode(state)
+	err := encoder.Encode(engineDump{
+		EngineState: &state,
+		Scheduler:   buildcontrol.NewSchedulerStatus(state),
+	})
 	if err != nil {
 		log.Printf("Error encoding: %v", err)
 	}
 }
 
-func (s *HeadsUpServer) SnapshotJSON(w http.ResponseWriter, req *http.Request) {
-	view, err := webview.CompleteView(req.Context(), s.ctrlClient, s.store)
+func (s *HeadsUpServer) DumpSchedulerJSON(w http.ResponseWriter, req *http.Request) {
+	state := s.store.RLockState()
+	scheduler := buildcontrol.NewSchedulerStatus(state)
+	s.store.RUnlockState()
+
+	w.Header().Set("Content-Type", "application/json")
+	encoder := store.CreateEngineStateEncoder(w)
+	err := encoder.Encode(scheduler)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Error converting view to proto: %v", err), http.StatusInternalServerError)
-		return
+		log.Printf("Error encoding: %v", err)
+	}
+}
+
+func (s *HeadsUpServer) completeSnapshot(ctx context.Context) (*proto_webview.Snapshot, error) {
+	view, err := webview.CompleteView(ctx, s.ctrlClient, s.store)
+	if err != nil {
+		return nil, err
 	}
 
-	snapshot := &proto_webview.Snapshot{
+	return &proto_webview.Snapshot{
 		View:      view,
 		CreatedAt: timestamppb.Now(),
+	}, nil
+}
+
+func (s *HeadsUpServer) SnapshotJSON(w http.ResponseWriter, req *http.Request) {
+	snapshot, err := s.completeSnapshot(req.Context())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error converting view to proto: %v", err), http.StatusInternalServerError)
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -169,6 +269,53 @@ func (s *HeadsUpServer) SnapshotJSON(w http.ResponseWriter, req *http.Request) {
 	}
 }
 
+// Uploads a snapshot to the storage configured with snapshot_settings()
+// or TILT_SNAPSHOT_STORAGE_URL, and responds with a link to download it.
+func (s *HeadsUpServer) HandleSnapshotUpload(w http.ResponseWriter, req *http.Request) {
+	storage, err := s.snapshotStorage()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error uploading snapshot: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	snapshot, err := s.completeSnapshot(req.Context())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error converting view to proto: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	data, err := snapshots.Render(snapshot, snapshots.FormatJSON)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error rendering snapshot: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	link, err := uploadSnapshot(req.Context(), storage, data, snapshots.FormatJSON)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error uploading snapshot: %v", err), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(snapshotUploadPayload{URL: link})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error rendering response: %v", err), http.StatusInternalServerError)
+	}
+}
+
+func (s *HeadsUpServer) snapshotStorage() (snapshots.Storage, error) {
+	state := s.store.RLockState()
+	settings := state.SnapshotSettings
+	s.store.RUnlockState()
+
+	return snapshots.NewStorage(settings)
+}
+
+func uploadSnapshot(ctx context.Context, storage snapshots.Storage, data []byte, format snapshots.Format) (string, error) {
+	name := fmt.Sprintf("tilt-snapshot-%s-%s%s", time.Now().UTC().Format("20060102-150405"), uuid.New(), format.Ext())
+	return storage.Upload(ctx, name, data)
+}
+
 func (s *HeadsUpServer) HandleAnalyticsOpt(w http.ResponseWriter, req *http.Request) {
 	if req.Method != http.MethodPost {
 		http.Error(w, "must be POST request", http.StatusBadRequest)
@@ -248,7 +395,7 @@ func (s *HeadsUpServer) HandleTrigger(w http.ResponseWriter, req *http.Request)
 		return
 	}
 
-	var payload triggerPayload
+	var payload TriggerPayload
 
 	decoder := json.NewDecoder(req.Body)
 	err := decoder.Decode(&payload)
@@ -257,22 +404,107 @@ func (s *HeadsUpServer) HandleTrigger(w http.ResponseWriter, req *http.Request)
 		return
 	}
 
-	if len(payload.ManifestNames) != 1 {
-		http.Error(w, fmt.Sprintf("/api/trigger currently supports exactly one manifest name, got %d", len(payload.ManifestNames)), http.StatusBadRequest)
+	if len(payload.ManifestNames) == 0 && len(payload.Labels) == 0 {
+		http.Error(w, "/api/trigger needs at least one manifest name or label", http.StatusBadRequest)
 		return
 	}
 
-	mn := model.ManifestName(payload.ManifestNames[0])
+	reason := payload.BuildReason
+	if payload.NoCache {
+		reason = reason.With(model.BuildReasonFlagNoCache)
+	}
 
 	state := s.store.RLockState()
-	defer s.store.RUnlockState()
-	ms, ok := state.ManifestState(mn)
-	if !ok {
-		http.Error(w, fmt.Sprintf("resource %q does not exist", mn), http.StatusNotFound)
-	} else if ms != nil && ms.DisableState == v1alpha1.DisableStateDisabled {
-		_, _ = fmt.Fprintf(w, "resource %q is currently disabled", mn)
-	} else {
-		s.store.Dispatch(AppendToTriggerQueueAction{Name: mn, Reason: payload.BuildReason})
+	names := triggerManifestNames(state, payload)
+	response := TriggerResponse{Results: []TriggerResult{}}
+	var toTrigger []model.ManifestName
+	for _, mn := range names {
+		ms, ok := state.ManifestState(mn)
+		status := TriggerStatusQueued
+		if !ok {
+			status = TriggerStatusNotFound
+		} else if ms != nil && ms.DisableState == v1alpha1.DisableStateDisabled {
+			status = TriggerStatusDisabled
+		} else {
+			toTrigger = append(toTrigger, mn)
+		}
+		response.Results = append(response.Results, TriggerResult{Name: mn.String(), Status: status})
+	}
+	s.store.RUnlockState()
+
+	for _, mn := range toTrigger {
+		s.store.Dispatch(store.AppendToTriggerQueueAction{Name: mn, Reason: reason})
+	}
+
+	// Only a 404 if none of the requested resources exist, so that
+	// scripts can tell a typo from a partial success.
+	code := http.StatusNotFound
+	for _, r := range response.Results {
+		if r.Status != TriggerStatusNotFound {
+			code = http.StatusOK
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// The resources to trigger: the named resources in order, then the resources
+// with any of the labels. Each resource appears once.
+func triggerManifestNames(state store.EngineState, payload TriggerPayload) []model.ManifestName {
+	seen := make(map[model.ManifestName]bool)
+	var result []model.ManifestName
+	add := func(mn model.ManifestName) {
+		if !seen[mn] {
+			seen[mn] = true
+			result = append(result, mn)
+		}
+	}
+
+	for _, name := range payload.ManifestNames {
+		add(model.ManifestName(name))
+	}
+	for _, mt := range state.Targets() {
+		for _, label := range payload.Labels {
+			if _, ok := mt.Manifest.Labels[label]; ok {
+				add(mt.Manifest.Name)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// Fires a trigger declared with external_trigger() in the Tiltfile,
+// updating every resource that uses it.
+//
+// Doesn't read the request body, so that it can be used as a webhook.
+func (s *HeadsUpServer) HandleExternalTrigger(w http.ResponseWriter, req *http.Request) {
+	name := mux.Vars(req)["name"]
+
+	found := false
+	var toTrigger []model.ManifestName
+	state := s.store.RLockState()
+	for _, mt := range state.Targets() {
+		if !mt.Manifest.HasExternalTrigger(name) {
+			continue
+		}
+		found = true
+		if mt.State.DisableState == v1alpha1.DisableStateDisabled {
+			continue
+		}
+		toTrigger = append(toTrigger, mt.Manifest.Name)
+	}
+	s.store.RUnlockState()
+
+	if !found {
+		http.Error(w, fmt.Sprintf("no resources use trigger %q", name), http.StatusNotFound)
+		return
+	}
+
+	for _, mn := range toTrigger {
+		s.store.Dispatch(store.AppendToTriggerQueueAction{Name: mn, Reason: model.BuildReasonFlagTriggerExternal})
 	}
 }
 
@@ -309,11 +541,73 @@ func (s *HeadsUpServer) HandleOverrideTriggerMode(w http.ResponseWriter, req *ht
 	})
 }
 
+// Serves the format of log prefixes in the terminal.
+func (s *HeadsUpServer) HandleGetLogPrefixFormat(w http.ResponseWriter, req *http.Request) {
+	state := s.store.RLockState()
+	format := state.LogPrefixFormat
+	s.store.RUnlockState()
+
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(format)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("error rendering log prefix format: %v", err), http.StatusInternalServerError)
+	}
+}
+
+// Changes the format of log prefixes in the terminal, without restarting Tilt.
+//
+// Applies to logs printed after the change. Fields left empty use the defaults.
+func (s *HeadsUpServer) HandleSetLogPrefixFormat(w http.ResponseWriter, req *http.Request) {
+	if !checkSameOriginJSON(w, req) {
+		return
+	}
+
+	var format logstore.PrefixFormat
+
+	decoder := json.NewDecoder(req.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&format)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("error parsing JSON payload: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	err = format.Validate()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.store.Dispatch(SetLogPrefixFormatAction{Format: format})
+}
+
 func (s *HeadsUpServer) WebsocketToken(w http.ResponseWriter, req *http.Request) {
 	w.Header().Set("Content-Type", "text/plain")
 	_, _ = w.Write([]byte(websocketCSRFToken.String()))
 }
 
+// Lists per-client stats for all connected websockets.
+func (s *HeadsUpServer) HandleWebsocketStats(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(s.wsList.Stats())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("error rendering websocket stats: %v", err), http.StatusInternalServerError)
+	}
+}
+
+// Forcibly disconnects a websocket client. The web UI will
+// reconnect on its own, so this is mostly useful for kicking a stalled tab.
+func (s *HeadsUpServer) HandleWebsocketDisconnect(w http.ResponseWriter, req *http.Request) {
+	if !checkSameOriginJSON(w, req) {
+		return
+	}
+
+	id := mux.Vars(req)["id"]
+	if !s.wsList.Disconnect(id, "disconnected by admin request") {
+		http.Error(w, fmt.Sprintf("websocket %q does not exist", id), http.StatusNotFound)
+	}
+}
+
 func checkManifestsExist(st store.RStore, mNames []string) error {
 	state := st.RLockState()
 	defer st.RUnlockState()
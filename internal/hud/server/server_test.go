@@ -1,12 +1,19 @@
 package server_test
 
 import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
 	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
@@ -22,12 +29,16 @@ import (
 	"github.com/tilt-dev/tilt/internal/controllers/fake"
 	"github.com/tilt-dev/tilt/internal/hud/server"
 	"github.com/tilt-dev/tilt/internal/hud/view"
+	"github.com/tilt-dev/tilt/internal/msgcat"
 	"github.com/tilt-dev/tilt/internal/sliceutils"
+	"github.com/tilt-dev/tilt/internal/snapshots"
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/internal/testutils"
 	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
 	"github.com/tilt-dev/tilt/pkg/assets"
+	"github.com/tilt-dev/tilt/pkg/logger"
 	"github.com/tilt-dev/tilt/pkg/model"
+	"github.com/tilt-dev/tilt/pkg/model/logstore"
 	"github.com/tilt-dev/wmclient/pkg/analytics"
 )
 
@@ -127,17 +138,75 @@ func TestHandleTriggerNoManifestWithName(t *testing.T) {
 	status, respBody := f.makeReq("/api/trigger", f.serv.HandleTrigger, http.MethodPost, payload)
 
 	require.Equal(t, http.StatusNotFound, status, "handler returned wrong status code")
-	require.Equal(t, respBody, "resource \"foo\" does not exist\n")
+	require.Equal(t, `{"results":[{"name":"foo","status":"not_found"}]}`+"\n", respBody)
 }
 
-func TestHandleTriggerTooManyManifestNames(t *testing.T) {
+func TestHandleTriggerNoManifestNames(t *testing.T) {
 	f := newTestFixture(t)
 
-	payload := `{"manifest_names":["foo", "bar"]}`
+	payload := `{"manifest_names":[]}`
 	status, respBody := f.makeReq("/api/trigger", f.serv.HandleTrigger, http.MethodPost, payload)
 
 	require.Equal(t, http.StatusBadRequest, status, "handler returned wrong status code")
-	require.Contains(t, respBody, "currently supports exactly one manifest name, got 2")
+	require.Contains(t, respBody, "needs at least one manifest name or label")
+}
+
+func TestHandleTriggerMultipleManifests(t *testing.T) {
+	f := newTestFixture(t)
+
+	f.withDummyManifests("foo", "bar", "baz")
+	state := f.st.LockMutableStateForTesting()
+	state.ManifestTargets["baz"].State.DisableState = v1alpha1.DisableStateDisabled
+	f.st.UnlockMutableState()
+
+	payload := `{"manifest_names":["foo", "bar", "baz", "qux", "foo"]}`
+	status, respBody := f.makeReq("/api/trigger", f.serv.HandleTrigger, http.MethodPost, payload)
+	require.Equal(t, http.StatusOK, status, "handler returned wrong status code")
+
+	var resp server.TriggerResponse
+	require.NoError(t, json.Unmarshal([]byte(respBody), &resp))
+	assert.Equal(t, []server.TriggerResult{
+		{Name: "foo", Status: server.TriggerStatusQueued},
+		{Name: "bar", Status: server.TriggerStatusQueued},
+		{Name: "baz", Status: server.TriggerStatusDisabled},
+		{Name: "qux", Status: server.TriggerStatusNotFound},
+	}, resp.Results)
+
+	require.Eventually(t, func() bool {
+		var triggered []model.ManifestName
+		for _, a := range f.getActions() {
+			if action, ok := a.(store.AppendToTriggerQueueAction); ok {
+				triggered = append(triggered, action.Name)
+			}
+		}
+		return reflect.DeepEqual([]model.ManifestName{"foo", "bar"}, triggered)
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestHandleTriggerLabels(t *testing.T) {
+	f := newTestFixture(t)
+
+	state := f.st.LockMutableStateForTesting()
+	for _, m := range []model.Manifest{
+		model.Manifest{Name: "fe"}.WithLabels(map[string]string{"frontend": "frontend"}),
+		model.Manifest{Name: "be"}.WithLabels(map[string]string{"backend": "backend"}),
+		model.Manifest{Name: "db"}.WithLabels(map[string]string{"backend": "backend", "storage": "storage"}),
+	} {
+		state.UpsertManifestTarget(store.NewManifestTarget(m))
+	}
+	f.st.UnlockMutableState()
+
+	payload := `{"manifest_names":["fe"], "labels":["backend", "storage"]}`
+	status, respBody := f.makeReq("/api/trigger", f.serv.HandleTrigger, http.MethodPost, payload)
+	require.Equal(t, http.StatusOK, status, "handler returned wrong status code")
+
+	var resp server.TriggerResponse
+	require.NoError(t, json.Unmarshal([]byte(respBody), &resp))
+	assert.Equal(t, []server.TriggerResult{
+		{Name: "fe", Status: server.TriggerStatusQueued},
+		{Name: "be", Status: server.TriggerStatusQueued},
+		{Name: "db", Status: server.TriggerStatusQueued},
+	}, resp.Results)
 }
 
 func TestHandleTriggerNonPost(t *testing.T) {
@@ -164,16 +233,16 @@ func TestHandleTriggerTiltfileOK(t *testing.T) {
 
 	payload := fmt.Sprintf(`{"manifest_names":["%s"], "build_reason": %d}`, model.MainTiltfileManifestName, model.BuildReasonFlagTriggerWeb)
 	status, resp := f.makeReq("/api/trigger", f.serv.HandleTrigger, http.MethodPost, payload)
-	assert.Equal(t, "", resp)
+	assert.Equal(t, fmt.Sprintf(`{"results":[{"name":"%s","status":"queued"}]}`+"\n", model.MainTiltfileManifestName), resp)
 	assert.Equal(t, http.StatusOK, status)
 
-	a := store.WaitForAction(t, reflect.TypeOf(server.AppendToTriggerQueueAction{}), f.getActions)
-	action, ok := a.(server.AppendToTriggerQueueAction)
+	a := store.WaitForAction(t, reflect.TypeOf(store.AppendToTriggerQueueAction{}), f.getActions)
+	action, ok := a.(store.AppendToTriggerQueueAction)
 	if !ok {
 		t.Fatalf("Action was not of type 'AppendToTriggreQueueAction': %+v", action)
 	}
 
-	expected := server.AppendToTriggerQueueAction{
+	expected := store.AppendToTriggerQueueAction{
 		Name:   model.MainTiltfileManifestName,
 		Reason: model.BuildReasonFlagTriggerWeb,
 	}
@@ -191,7 +260,7 @@ func TestHandleTriggerResourceDisabled(t *testing.T) {
 	status, body := f.makeReq("/api/trigger", f.serv.HandleTrigger, http.MethodPost, payload)
 
 	require.Equal(t, http.StatusOK, status, "handler returned wrong status code")
-	require.Equal(t, "resource \"foo\" is currently disabled", body)
+	require.Equal(t, `{"results":[{"name":"foo","status":"disabled"}]}`+"\n", body)
 }
 
 func TestHandleTriggerNonTiltfileManifest(t *testing.T) {
@@ -209,17 +278,96 @@ func TestHandleTriggerNonTiltfileManifest(t *testing.T) {
 
 	payload := fmt.Sprintf(`{"manifest_names":["%s"]}`, mt.Manifest.Name)
 	status, resp := f.makeReq("/api/trigger", f.serv.HandleTrigger, http.MethodPost, payload)
-	assert.Equal(t, "", resp)
+	assert.Equal(t, `{"results":[{"name":"foobar","status":"queued"}]}`+"\n", resp)
 	assert.Equal(t, http.StatusOK, status)
 
-	a := store.WaitForAction(t, reflect.TypeOf(server.AppendToTriggerQueueAction{}), f.getActions)
-	action, ok := a.(server.AppendToTriggerQueueAction)
+	a := store.WaitForAction(t, reflect.TypeOf(store.AppendToTriggerQueueAction{}), f.getActions)
+	action, ok := a.(store.AppendToTriggerQueueAction)
 	if !ok {
 		t.Fatalf("Action was not of type 'AppendToTriggerQueueAction': %+v", action)
 	}
 	assert.Equal(t, "foobar", action.Name.String())
 }
 
+func TestHandleExternalTrigger(t *testing.T) {
+	f := newTestFixture(t)
+
+	hook := []model.ExternalTrigger{{Name: "schema"}}
+	state := f.st.LockMutableStateForTesting()
+	state.UpsertManifestTarget(store.NewManifestTarget(model.Manifest{Name: "api"}.WithExternalTriggers(hook)))
+	state.UpsertManifestTarget(store.NewManifestTarget(model.Manifest{Name: "web"}))
+	state.UpsertManifestTarget(store.NewManifestTarget(model.Manifest{Name: "worker"}.WithExternalTriggers(hook)))
+	state.ManifestTargets["worker"].State.DisableState = v1alpha1.DisableStateDisabled
+	f.st.UnlockMutableState()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/triggers/schema", nil)
+	rr := httptest.NewRecorder()
+	f.serv.Router().ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	a := store.WaitForAction(t, reflect.TypeOf(store.AppendToTriggerQueueAction{}), f.getActions)
+	assert.Equal(t, store.AppendToTriggerQueueAction{
+		Name:   "api",
+		Reason: model.BuildReasonFlagTriggerExternal,
+	}, a)
+
+	var triggered []model.ManifestName
+	for _, a := range f.getActions() {
+		if a, ok := a.(store.AppendToTriggerQueueAction); ok {
+			triggered = append(triggered, a.Name)
+		}
+	}
+	assert.Equal(t, []model.ManifestName{"api"}, triggered)
+}
+
+func TestHandleExternalTriggerUnknown(t *testing.T) {
+	f := newTestFixture(t)
+	f.withDummyManifests("api")
+
+	req := httptest.NewRequest(http.MethodPost, "/api/triggers/schema", nil)
+	rr := httptest.NewRecorder()
+	f.serv.Router().ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusNotFound, rr.Code)
+	require.Equal(t, "no resources use trigger \"schema\"\n", rr.Body.String())
+}
+
+func TestHandleSetLogPrefixFormat(t *testing.T) {
+	f := newTestFixture(t)
+
+	payload := `{"width": 20, "truncation": "middle", "theme": "dark", "timestamps": "runtime"}`
+	status, resp := f.makeReq("/api/settings/log_prefix", f.serv.HandleSetLogPrefixFormat, http.MethodPost, payload)
+	require.Equal(t, http.StatusOK, status, resp)
+
+	a := store.WaitForAction(t, reflect.TypeOf(server.SetLogPrefixFormatAction{}), f.getActions)
+	assert.Equal(t, logstore.PrefixFormat{
+		Width:      20,
+		Truncation: logstore.PrefixTruncateMiddle,
+		Theme:      logstore.PrefixThemeDark,
+		Timestamps: logstore.TimestampsRuntime,
+	}, a.(server.SetLogPrefixFormatAction).Format)
+}
+
+func TestHandleSetLogPrefixFormatInvalid(t *testing.T) {
+	f := newTestFixture(t)
+
+	status, resp := f.makeReq("/api/settings/log_prefix", f.serv.HandleSetLogPrefixFormat, http.MethodPost, `{"theme": "neon"}`)
+	require.Equal(t, http.StatusBadRequest, status)
+	assert.Contains(t, resp, `invalid theme "neon"`)
+	store.AssertNoActionOfType(t, reflect.TypeOf(server.SetLogPrefixFormatAction{}), f.getActions)
+}
+
+func TestHandleGetLogPrefixFormat(t *testing.T) {
+	f := newTestFixture(t)
+	state := f.st.LockMutableStateForTesting()
+	state.LogPrefixFormat = logstore.PrefixFormat{Width: 20, Timestamps: logstore.TimestampsAll}
+	f.st.UnlockMutableState()
+
+	status, resp := f.makeReq("/api/settings/log_prefix", f.serv.HandleGetLogPrefixFormat, http.MethodGet, "")
+	require.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, `{"width": 20, "timestamps": "all"}`, resp)
+}
+
 func TestHandleOverrideTriggerModeReturnsErrorForBadManifest(t *testing.T) {
 	f := newTestFixture(t).withDummyManifests("foo", "baz")
 
@@ -312,6 +460,623 @@ func TestSetTiltfileArgs(t *testing.T) {
 	)
 }
 
+func TestHandleGetBuild(t *testing.T) {
+	f := newTestFixture(t).withDummyManifests("fe")
+
+	state := f.st.LockMutableStateForTesting()
+	state.LogStore.Append(store.NewLogAction("fe", "build:1", logger.InfoLvl, nil, []byte("build 1 output\n")), nil)
+	state.LogStore.Append(store.NewLogAction("fe", "build:2", logger.InfoLvl, nil, []byte("build 2 output\n")), nil)
+	ms := state.ManifestTargets["fe"].State
+	ms.AddCompletedBuild(model.BuildRecord{
+		StartTime:  time.Unix(1, 0),
+		FinishTime: time.Unix(2, 0),
+		SpanID:     "build:1",
+	})
+	ms.AddCompletedBuild(model.BuildRecord{
+		StartTime:  time.Unix(3, 0),
+		FinishTime: time.Unix(4, 0),
+		SpanID:     "build:2",
+		Error:      fmt.Errorf("oh no"),
+	})
+	f.st.UnlockMutableState()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/resources/fe/builds/2", nil)
+	rr := httptest.NewRecorder()
+	f.serv.Router().ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var build map[string]interface{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &build))
+	assert.Equal(t, "2", build["buildID"])
+	assert.Equal(t, "oh no", build["error"])
+	assert.Equal(t, "build 2 output\n", build["log"])
+	assert.Equal(t, map[string]interface{}{"fromCheckpoint": 1.0, "toCheckpoint": 2.0}, build["logRange"])
+
+	req = httptest.NewRequest(http.MethodGet, "/api/resources/fe/builds", nil)
+	rr = httptest.NewRecorder()
+	f.serv.Router().ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var builds []map[string]interface{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &builds))
+	require.Len(t, builds, 2)
+	assert.Equal(t, "2", builds[0]["buildID"])
+	assert.Equal(t, "1", builds[1]["buildID"])
+	assert.Nil(t, builds[1]["log"])
+}
+
+func TestHandleTriggerHistory(t *testing.T) {
+	f := newTestFixture(t).withDummyManifests("fe", "be")
+
+	state := f.st.LockMutableStateForTesting()
+	fe := state.ManifestTargets["fe"].State
+	fe.AddTrigger(model.TriggerRecord{
+		Time:   time.Unix(1, 0).UTC(),
+		Reason: model.BuildReasonFlagInit,
+		SpanID: "build:1",
+	})
+	fe.AddTrigger(model.TriggerRecord{
+		Time:   time.Unix(3, 0).UTC(),
+		Reason: model.BuildReasonFlagChangedFiles,
+		Files:  []string{"/src/main.go"},
+		SpanID: "build:3",
+	})
+	be := state.ManifestTargets["be"].State
+	be.AddTrigger(model.TriggerRecord{
+		Time:   time.Unix(2, 0).UTC(),
+		Reason: model.BuildReasonFlagTriggerExternal,
+		SpanID: "build:2",
+	})
+	f.st.UnlockMutableState()
+
+	history := func(query string) []server.TriggerHistoryEntry {
+		req := httptest.NewRequest(http.MethodGet, "/api/trigger_history?"+query, nil)
+		rr := httptest.NewRecorder()
+		f.serv.Router().ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		var response server.TriggerHistoryResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		return response.Triggers
+	}
+
+	triggers := history("")
+	require.Len(t, triggers, 3)
+	assert.Equal(t, server.TriggerHistoryEntry{
+		Resource: "fe",
+		Time:     time.Unix(3, 0).UTC(),
+		Kind:     server.TriggerKindFileChange,
+		Reason:   "Changed Files",
+		Files:    []string{"/src/main.go"},
+		BuildID:  "3",
+		SpanID:   "build:3",
+	}, triggers[0])
+	assert.Equal(t, server.TriggerKindAPI, triggers[1].Kind)
+	assert.Equal(t, "be", triggers[1].Resource)
+	assert.Equal(t, server.TriggerKindInitial, triggers[2].Kind)
+
+	triggers = history("resources=be")
+	require.Len(t, triggers, 1)
+	assert.Equal(t, "2", triggers[0].BuildID)
+
+	triggers = history("limit=1")
+	require.Len(t, triggers, 1)
+	assert.Equal(t, "fe", triggers[0].Resource)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/trigger_history?resources=db", nil)
+	rr := httptest.NewRecorder()
+	f.serv.Router().ServeHTTP(rr, req)
+	require.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Contains(t, rr.Body.String(), `resource "db" does not exist`)
+}
+
+func TestHandleGetBuildNotFound(t *testing.T) {
+	f := newTestFixture(t).withDummyManifests("fe")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/resources/fe/builds/5", nil)
+	rr := httptest.NewRecorder()
+	f.serv.Router().ServeHTTP(rr, req)
+	require.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Contains(t, rr.Body.String(), `build "5" of resource "fe" does not exist`)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/resources/be/builds/5", nil)
+	rr = httptest.NewRecorder()
+	f.serv.Router().ServeHTTP(rr, req)
+	require.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Contains(t, rr.Body.String(), `resource "be" does not exist`)
+}
+
+func TestHandleLogArchive(t *testing.T) {
+	f := newTestFixture(t).withDummyManifests("fe", "be")
+
+	state := f.st.LockMutableStateForTesting()
+	state.LogStore.Append(store.NewLogAction("fe", "build:1", logger.InfoLvl, nil, []byte("fe output\n")), nil)
+	state.LogStore.Append(store.NewLogAction("be", "build:2", logger.InfoLvl, nil, []byte("be output\n")), nil)
+	f.st.UnlockMutableState()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/logs/archive", nil)
+	rr := httptest.NewRecorder()
+	f.serv.Router().ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/zip", rr.Header().Get("Content-Type"))
+
+	zr, err := zip.NewReader(bytes.NewReader(rr.Body.Bytes()), int64(rr.Body.Len()))
+	require.NoError(t, err)
+	files := map[string]string{}
+	for _, file := range zr.File {
+		r, err := file.Open()
+		require.NoError(t, err)
+		contents, err := io.ReadAll(r)
+		require.NoError(t, err)
+		files[file.Name] = string(contents)
+	}
+	assert.Len(t, files, 3)
+	assert.Equal(t, "", files["(Tiltfile).log"])
+	assert.Regexp(t, `^\S+ fe output\n$`, files["fe.log"])
+	assert.Regexp(t, `^\S+ be output\n$`, files["be.log"])
+
+	req = httptest.NewRequest(http.MethodGet, "/api/logs/archive?resources=be&format=tar", nil)
+	rr = httptest.NewRecorder()
+	f.serv.Router().ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/gzip", rr.Header().Get("Content-Type"))
+
+	gr, err := gzip.NewReader(rr.Body)
+	require.NoError(t, err)
+	tr := tar.NewReader(gr)
+	header, err := tr.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "be.log", header.Name)
+	contents, err := io.ReadAll(tr)
+	require.NoError(t, err)
+	assert.Contains(t, string(contents), "be output")
+	_, err = tr.Next()
+	assert.Equal(t, io.EOF, err)
+
+	// Logs from the future.
+	since := time.Now().Add(time.Hour).Format(time.RFC3339)
+	req = httptest.NewRequest(http.MethodGet, "/api/logs/archive?resources=fe&since="+since, nil)
+	rr = httptest.NewRecorder()
+	f.serv.Router().ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	zr, err = zip.NewReader(bytes.NewReader(rr.Body.Bytes()), int64(rr.Body.Len()))
+	require.NoError(t, err)
+	require.Len(t, zr.File, 1)
+	assert.Equal(t, uint64(0), zr.File[0].UncompressedSize64)
+}
+
+func TestHandleLogArchiveErrors(t *testing.T) {
+	f := newTestFixture(t).withDummyManifests("fe")
+
+	for _, tc := range []struct {
+		query string
+		code  int
+		msg   string
+	}{
+		{"resources=fe,db", http.StatusNotFound, `resource "db" does not exist`},
+		{"format=rar", http.StatusBadRequest, `invalid format "rar"`},
+		{"since=yesterday", http.StatusBadRequest, `invalid since "yesterday"`},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/logs/archive?"+tc.query, nil)
+			rr := httptest.NewRecorder()
+			f.serv.Router().ServeHTTP(rr, req)
+			require.Equal(t, tc.code, rr.Code)
+			assert.Contains(t, rr.Body.String(), tc.msg)
+		})
+	}
+}
+
+func TestHandleLogSearch(t *testing.T) {
+	f := newTestFixture(t).withDummyManifests("fe", "be")
+
+	state := f.st.LockMutableStateForTesting()
+	state.LogStore.Append(store.NewLogAction("fe", "build:1", logger.InfoLvl, nil, []byte("starting\nerror: 1\nerror: 2\n")), nil)
+	state.LogStore.Append(store.NewLogAction("be", "build:2", logger.InfoLvl, nil, []byte("error: 3\n")), nil)
+	f.st.UnlockMutableState()
+
+	search := func(query string) server.LogSearchResponse {
+		req := httptest.NewRequest(http.MethodGet, "/api/logs/search?"+query, nil)
+		rr := httptest.NewRecorder()
+		f.serv.Router().ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		var response server.LogSearchResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		return response
+	}
+
+	response := search("q=error:+%5Cd&context=1&limit=2")
+	assert.Equal(t, 3, response.Total)
+	assert.Equal(t, 2, response.NextOffset)
+	require.Len(t, response.Matches, 2)
+	assert.Equal(t, "fe", response.Matches[0].Resource)
+	assert.Equal(t, "build:1", response.Matches[0].SpanID)
+	assert.Equal(t, "error: 1", response.Matches[0].Text)
+	assert.Equal(t, []string{"starting"}, response.Matches[0].Before)
+	assert.Equal(t, []string{"error: 2"}, response.Matches[0].After)
+
+	response = search("q=error:+%5Cd&limit=2&offset=2")
+	assert.Equal(t, 0, response.NextOffset)
+	require.Len(t, response.Matches, 1)
+	assert.Equal(t, "be", response.Matches[0].Resource)
+
+	response = search("q=error&resources=be")
+	assert.Equal(t, 1, response.Total)
+
+	response = search("q=error&span=build:1")
+	assert.Equal(t, 2, response.Total)
+
+	response = search("q=nothing")
+	assert.Equal(t, 0, response.Total)
+	assert.Equal(t, []server.LogSearchMatch{}, response.Matches)
+}
+
+func TestHandleLogSearchErrors(t *testing.T) {
+	f := newTestFixture(t).withDummyManifests("fe")
+
+	for _, tc := range []struct {
+		query string
+		code  int
+		msg   string
+	}{
+		{"", http.StatusBadRequest, "missing query parameter q"},
+		{"q=(", http.StatusBadRequest, `invalid q "("`},
+		{"q=x&context=-1", http.StatusBadRequest, "invalid context"},
+		{"q=x&limit=5000", http.StatusBadRequest, "must be at most 1000"},
+		{"q=x&resources=db", http.StatusNotFound, `resource "db" does not exist`},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/logs/search?"+tc.query, nil)
+			rr := httptest.NewRecorder()
+			f.serv.Router().ServeHTTP(rr, req)
+			require.Equal(t, tc.code, rr.Code)
+			assert.Contains(t, rr.Body.String(), tc.msg)
+		})
+	}
+}
+
+func TestHandleLogStream(t *testing.T) {
+	f := newTestFixture(t).withDummyManifests("fe", "be")
+
+	state := f.st.LockMutableStateForTesting()
+	state.LogStore.Append(store.NewLogAction("fe", "build:1", logger.InfoLvl, nil, []byte("starting\n")), nil)
+	state.LogStore.Append(store.NewLogAction("fe", "build:1", logger.WarnLvl, nil, []byte("slow build\n")), nil)
+	state.LogStore.Append(store.NewLogAction("be", "build:2", logger.WarnLvl, nil, []byte("low disk\n")), nil)
+	f.st.UnlockMutableState()
+
+	ts := httptest.NewServer(f.serv.Router())
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"/api/logs/stream?resource=fe&level=warn", nil)
+	require.NoError(t, err)
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer func() {
+		_ = res.Body.Close()
+	}()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(res.Body)
+	nextEvent := func() (string, server.LogStreamEvent) {
+		id := ""
+		var event server.LogStreamEvent
+		for {
+			line, err := reader.ReadString('\n')
+			require.NoError(t, err)
+			line = strings.TrimSuffix(line, "\n")
+			if line == "" {
+				return id, event
+			}
+			if v, ok := strings.CutPrefix(line, "id: "); ok {
+				id = v
+			}
+			if v, ok := strings.CutPrefix(line, "data: "); ok {
+				require.NoError(t, json.Unmarshal([]byte(v), &event))
+			}
+		}
+	}
+
+	id, event := nextEvent()
+	assert.Equal(t, "3", id)
+	assert.Equal(t, "fe", event.Resource)
+	assert.Equal(t, "build:1", event.SpanID)
+	assert.Equal(t, "warn", event.Level)
+	assert.Equal(t, "slow build\n", event.Text)
+
+	// New logs are streamed as they come in.
+	state = f.st.LockMutableStateForTesting()
+	state.LogStore.Append(store.NewLogAction("be", "build:2", logger.ErrorLvl, nil, []byte("unrelated\n")), nil)
+	state.LogStore.Append(store.NewLogAction("fe", "build:1", logger.ErrorLvl, nil, []byte("boom\n")), nil)
+	f.st.UnlockMutableState()
+
+	id, event = nextEvent()
+	assert.Equal(t, "5", id)
+	assert.Equal(t, "error", event.Level)
+	assert.Equal(t, "boom\n", event.Text)
+}
+
+func TestHandleLogStreamErrors(t *testing.T) {
+	f := newTestFixture(t).withDummyManifests("fe")
+
+	for _, tc := range []struct {
+		query string
+		code  int
+		msg   string
+	}{
+		{"level=loud", http.StatusBadRequest, `invalid level "loud"`},
+		{"checkpoint=-1", http.StatusBadRequest, "invalid checkpoint"},
+		{"resources=db", http.StatusNotFound, `resource "db" does not exist`},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/logs/stream?"+tc.query, nil)
+			rr := httptest.NewRecorder()
+			f.serv.Router().ServeHTTP(rr, req)
+			require.Equal(t, tc.code, rr.Code)
+			assert.Contains(t, rr.Body.String(), tc.msg)
+		})
+	}
+}
+
+func TestHandleWebsocketStats(t *testing.T) {
+	f := newTestFixture(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/websockets", nil)
+	rr := httptest.NewRecorder()
+	f.serv.Router().ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "[]\n", rr.Body.String())
+}
+
+func TestHandleWebsocketDisconnectNotFound(t *testing.T) {
+	f := newTestFixture(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/websockets/5/disconnect", nil)
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	f.serv.Router().ServeHTTP(rr, req)
+	require.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Contains(t, rr.Body.String(), `websocket "5" does not exist`)
+}
+
+func TestDumpScheduler(t *testing.T) {
+	f := newTestFixture(t)
+
+	mt := store.NewManifestTarget(model.Manifest{Name: "web"})
+	mt.State.DisableState = v1alpha1.DisableStateEnabled
+	state := f.st.LockMutableStateForTesting()
+	state.UpsertManifestTarget(mt)
+	f.st.UnlockMutableState()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/dump/scheduler", nil)
+	rr := httptest.NewRecorder()
+	f.serv.Router().ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var scheduler map[string]interface{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &scheduler))
+	assert.Equal(t, "web", scheduler["Next"])
+
+	req = httptest.NewRequest(http.MethodGet, "/api/dump/engine", nil)
+	rr = httptest.NewRecorder()
+	f.serv.Router().ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var engine map[string]interface{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &engine))
+	assert.Contains(t, engine, "ManifestTargets")
+	assert.Equal(t, scheduler, engine["Scheduler"])
+}
+
+func TestHealthz(t *testing.T) {
+	f := newTestFixture(t)
+
+	state := f.st.LockMutableStateForTesting()
+	state.Clusters[v1alpha1.ClusterNameDefault] = &v1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: v1alpha1.ClusterNameDefault},
+		Spec: v1alpha1.ClusterSpec{
+			Connection: &v1alpha1.ClusterConnection{Kubernetes: &v1alpha1.KubernetesClusterConnection{}},
+		},
+		Status: v1alpha1.ClusterStatus{Error: "connection refused"},
+	}
+	state.Clusters[v1alpha1.ClusterNameDocker] = &v1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: v1alpha1.ClusterNameDocker},
+		Spec: v1alpha1.ClusterSpec{
+			Connection: &v1alpha1.ClusterConnection{Docker: &v1alpha1.DockerClusterConnection{}},
+		},
+		Status: v1alpha1.ClusterStatus{ConnectedAt: &metav1.MicroTime{Time: time.Now()}},
+	}
+	f.st.UnlockMutableState()
+
+	expected := `{"status":"degraded","checks":[` +
+		`{"name":"apiserver","ok":true},` +
+		`{"name":"cluster:default","ok":false,"reason":"connection refused"},` +
+		`{"name":"docker","ok":true}` +
+		`],"websockets":0}` + "\n"
+
+	// A degraded cluster doesn't fail the liveness check, but does fail the readiness check.
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	rr := httptest.NewRecorder()
+	f.serv.Router().ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, expected, rr.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	rr = httptest.NewRecorder()
+	f.serv.Router().ServeHTTP(rr, req)
+	require.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Equal(t, expected, rr.Body.String())
+}
+
+func TestReadyz(t *testing.T) {
+	f := newTestFixture(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	rr := httptest.NewRecorder()
+	f.serv.Router().ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `{"status":"ok","checks":[{"name":"apiserver","ok":true}],"websockets":0}`+"\n", rr.Body.String())
+}
+
+func TestWebAuthRequiresToken(t *testing.T) {
+	f := newTestFixtureWithAuthToken(t, "secret")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/view", nil)
+	rr := httptest.NewRecorder()
+	f.webHandler().ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	assert.Equal(t, `Bearer realm="tilt"`, rr.Header().Get("WWW-Authenticate"))
+
+	req = httptest.NewRequest(http.MethodGet, "/ws/view", nil)
+	rr = httptest.NewRecorder()
+	f.webHandler().ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/view", nil)
+	req.Header.Set("Authorization", "Bearer wrong")
+	rr = httptest.NewRecorder()
+	f.webHandler().ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	// Everything else on the web server needs the token too.
+	for _, path := range []string{"/proxy/apis/tilt.dev/v1alpha1/cmds", "/debug/pprof/", "/metrics", "/r/fe/overview"} {
+		req = httptest.NewRequest(http.MethodGet, path, nil)
+		rr = httptest.NewRecorder()
+		f.webHandler().ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusUnauthorized, rr.Code, path)
+	}
+
+	// Health checks stay open, so that probes don't need the token.
+	for _, path := range []string{"/healthz", "/readyz"} {
+		req = httptest.NewRequest(http.MethodGet, path, nil)
+		rr = httptest.NewRecorder()
+		f.webHandler().ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code, path)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	f := newTestFixture(t).withDummyManifests("fe", "be")
+
+	start := time.Unix(100, 0)
+	state := f.st.LockMutableStateForTesting()
+	state.ManifestTargets["fe"].State.AddCompletedBuild(model.BuildRecord{
+		StartTime:  start,
+		FinishTime: start.Add(3 * time.Second),
+		BuildTypes: []model.BuildType{model.BuildTypeImage, model.BuildTypeK8s},
+		SpanID:     "build:1",
+	})
+	state.ManifestTargets["be"].State.AddCompletedBuild(model.BuildRecord{
+		StartTime:  start,
+		FinishTime: start.Add(time.Second),
+		BuildTypes: []model.BuildType{model.BuildTypeLiveUpdate},
+		Error:      fmt.Errorf("sync failed"),
+		SpanID:     "build:2",
+	})
+	f.st.UnlockMutableState()
+
+	// Observing the same builds twice only counts them once.
+	f.serv.ObserveBuilds(f.st)
+	f.serv.ObserveBuilds(f.st)
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	rr := httptest.NewRecorder()
+	f.serv.Router().ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	body := rr.Body.String()
+	assert.Contains(t, body, `tilt_build_duration_seconds_count{resource="fe",result="success"} 1`)
+	assert.Contains(t, body, `tilt_build_duration_seconds_sum{resource="fe",result="success"} 3`)
+	assert.Contains(t, body, `tilt_build_duration_seconds_count{resource="be",result="error"} 1`)
+	assert.Contains(t, body, `tilt_live_update_duration_seconds_count{resource="be"} 1`)
+	assert.NotContains(t, body, `tilt_live_update_duration_seconds_count{resource="fe"}`)
+	assert.Contains(t, body, "tilt_websocket_clients 0")
+}
+
+func TestWebAuthBearerToken(t *testing.T) {
+	f := newTestFixtureWithAuthToken(t, "secret")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/messages", nil)
+	server.AddWebAuth(req.Header, "secret")
+	rr := httptest.NewRecorder()
+	f.webHandler().ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestWebAuthLogin(t *testing.T) {
+	f := newTestFixtureWithAuthToken(t, "secret")
+
+	req := httptest.NewRequest(http.MethodGet, "/r/fe/overview?token=wrong", nil)
+	rr := httptest.NewRecorder()
+	f.webHandler().ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/r/fe/overview?term=x&token=secret", nil)
+	rr = httptest.NewRecorder()
+	f.webHandler().ServeHTTP(rr, req)
+	require.Equal(t, http.StatusFound, rr.Code)
+	assert.Equal(t, "/r/fe/overview?term=x", rr.Header().Get("Location"))
+
+	cookies := rr.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, server.WebAuthCookieName, cookies[0].Name)
+	assert.True(t, cookies[0].HttpOnly)
+
+	// The cookie authenticates the API (and the websocket) from then on.
+	req = httptest.NewRequest(http.MethodGet, "/api/messages", nil)
+	req.AddCookie(cookies[0])
+	rr = httptest.NewRecorder()
+	f.webHandler().ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestMessages(t *testing.T) {
+	f := newTestFixture(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/messages", nil)
+	req.Header.Set("Accept-Language", "en-US,en;q=0.9")
+	rr := httptest.NewRecorder()
+	f.serv.Router().ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var payload struct {
+		Locale   string
+		Messages []msgcat.Entry
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &payload))
+	assert.Equal(t, "en-US", payload.Locale)
+
+	var found bool
+	for _, m := range payload.Messages {
+		if m.Code == msgcat.WaitingForDependency {
+			found = true
+			assert.Equal(t, "Waiting on {on}", m.Text)
+		}
+	}
+	assert.True(t, found, "WaitingForDependency not found in %v", payload.Messages)
+}
+
+// Puts the main Tiltfile in a temp dir, and returns the dir.
+func (f *serverFixture) withTiltfileDir() string {
+	dir := f.t.TempDir()
+	state := f.st.LockMutableStateForTesting()
+	state.Tiltfiles[model.MainTiltfileManifestName.String()] = &v1alpha1.Tiltfile{
+		ObjectMeta: metav1.ObjectMeta{Name: model.MainTiltfileManifestName.String()},
+		Spec:       v1alpha1.TiltfileSpec{Path: filepath.Join(dir, "Tiltfile")},
+	}
+	f.st.UnlockMutableState()
+	return dir
+}
+
+// The whole web server, with a proxy that always succeeds.
+func (f *serverFixture) webHandler() http.Handler {
+	proxy := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	return server.NewWebHandler(f.serv, proxy)
+}
+
 type serverFixture struct {
 	t            *testing.T
 	ctx          context.Context
@@ -325,6 +1090,10 @@ type serverFixture struct {
 }
 
 func newTestFixture(t *testing.T) *serverFixture {
+	return newTestFixtureWithAuthToken(t, "")
+}
+
+func newTestFixtureWithAuthToken(t *testing.T, token model.WebAuthToken) *serverFixture {
 	st, getActions := store.NewStoreWithFakeReducer()
 	go func() {
 		err := st.Loop(context.Background())
@@ -341,7 +1110,7 @@ func newTestFixture(t *testing.T) *serverFixture {
 
 	ctx := context.Background()
 
-	serv, err := server.ProvideHeadsUpServer(ctx, st, assets.NewFakeServer(), ta, wsl, ctrlClient)
+	serv, err := server.ProvideHeadsUpServer(ctx, st, assets.NewFakeServer(), ta, wsl, ctrlClient, token)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -411,3 +1180,152 @@ func (f *serverFixture) assertIncrement(name string, count int) {
 
 	assert.Equalf(f.t, count, runningCount, "Expected the total count to be %d, got %d", count, runningCount)
 }
+
+func TestHandleSnapshotUploadNotConfigured(t *testing.T) {
+	t.Setenv("TILT_SNAPSHOT_STORAGE_URL", "")
+	f := newTestFixture(t)
+
+	status, body := f.makeReq("/api/snapshot/upload", f.serv.HandleSnapshotUpload, http.MethodPost, "")
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Contains(t, body, "snapshot storage is not configured")
+}
+
+func TestHandleSnapshotUpload(t *testing.T) {
+	var uploaded []byte
+	bucket := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		uploaded, _ = io.ReadAll(req.Body)
+	}))
+	defer bucket.Close()
+
+	t.Setenv("TILT_SNAPSHOT_STORAGE_ENDPOINT", bucket.URL)
+	t.Setenv("AWS_ACCESS_KEY_ID", "id")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+
+	f := newTestFixture(t)
+	state := f.st.LockMutableStateForTesting()
+	state.SnapshotSettings = model.SnapshotSettings{StorageURL: "s3://my-bucket/snapshots"}
+	f.st.UnlockMutableState()
+
+	status, body := f.makeReq("/api/snapshot/upload", f.serv.HandleSnapshotUpload, http.MethodPost, "")
+	require.Equal(t, http.StatusOK, status, body)
+
+	var payload struct {
+		URL string `json:"url"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(body), &payload))
+	assert.True(t, strings.HasPrefix(payload.URL, bucket.URL+"/my-bucket/snapshots/tilt-snapshot-"), payload.URL)
+	assert.Contains(t, payload.URL, "X-Amz-Signature=")
+	assert.Contains(t, string(uploaded), `"view"`)
+}
+
+func TestHandleSnapshotSave(t *testing.T) {
+	f := newTestFixture(t).withDummyManifests("fe")
+	dir := f.withTiltfileDir()
+
+	path := filepath.Join(dir, "snapshot.html")
+	body, _ := json.Marshal(server.SnapshotSavePayload{Name: "snapshot.html"})
+	status, resp := f.makeReq("/api/snapshot/save", f.serv.HandleSnapshotSave, http.MethodPost, string(body))
+	require.Equal(t, http.StatusOK, status, resp)
+
+	var response server.SnapshotSaveResponse
+	require.NoError(t, json.Unmarshal([]byte(resp), &response))
+	assert.Equal(t, server.SnapshotSaveResponse{Path: path, Format: "html"}, response)
+
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(contents), "<title>Tilt Snapshot</title>")
+	assert.Contains(t, string(snapshots.ExtractJSON(contents)), `"view"`)
+
+	// An explicit format overrides the extension.
+	path = filepath.Join(dir, "snapshot.json")
+	body, _ = json.Marshal(server.SnapshotSavePayload{Name: "snapshot.json", Format: "html"})
+	status, resp = f.makeReq("/api/snapshot/save", f.serv.HandleSnapshotSave, http.MethodPost, string(body))
+	require.Equal(t, http.StatusOK, status, resp)
+
+	contents, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(contents), "<title>Tilt Snapshot</title>")
+}
+
+func TestHandleSnapshotSaveOverwrite(t *testing.T) {
+	f := newTestFixture(t)
+	dir := f.withTiltfileDir()
+	path := filepath.Join(dir, "snapshot.json")
+	require.NoError(t, os.WriteFile(path, []byte("old"), 0644))
+
+	body, _ := json.Marshal(server.SnapshotSavePayload{Name: "snapshot.json"})
+	status, resp := f.makeReq("/api/snapshot/save", f.serv.HandleSnapshotSave, http.MethodPost, string(body))
+	assert.Equal(t, http.StatusConflict, status)
+	assert.Contains(t, resp, "already exists")
+	contents, _ := os.ReadFile(path)
+	assert.Equal(t, "old", string(contents))
+
+	body, _ = json.Marshal(server.SnapshotSavePayload{Name: "snapshot.json", Overwrite: true})
+	status, resp = f.makeReq("/api/snapshot/save", f.serv.HandleSnapshotSave, http.MethodPost, string(body))
+	require.Equal(t, http.StatusOK, status, resp)
+	contents, _ = os.ReadFile(path)
+	assert.Contains(t, string(contents), `"view"`)
+}
+
+func TestHandleSnapshotSaveErrors(t *testing.T) {
+	f := newTestFixture(t)
+	dir := f.withTiltfileDir()
+
+	for _, name := range []string{"", "/home/me/.bashrc", "../snapshot.json", "sub/snapshot.json", ".bashrc", "Tiltfile", "snapshot.sh"} {
+		body, _ := json.Marshal(server.SnapshotSavePayload{Name: name})
+		status, resp := f.makeReq("/api/snapshot/save", f.serv.HandleSnapshotSave, http.MethodPost, string(body))
+		assert.Equal(t, http.StatusBadRequest, status, name)
+		assert.Contains(t, resp, "snapshot name must", name)
+	}
+
+	body, _ := json.Marshal(server.SnapshotSavePayload{Name: "snapshot.json", Format: "pdf"})
+	status, resp := f.makeReq("/api/snapshot/save", f.serv.HandleSnapshotSave, http.MethodPost, string(body))
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Contains(t, resp, "unknown snapshot format")
+
+	t.Setenv("TILT_SNAPSHOT_STORAGE_URL", "")
+	body, _ = json.Marshal(server.SnapshotSavePayload{Name: "snapshot.json", Upload: true})
+	status, resp = f.makeReq("/api/snapshot/save", f.serv.HandleSnapshotSave, http.MethodPost, string(body))
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Contains(t, resp, "snapshot storage is not configured")
+	assert.NoFileExists(t, filepath.Join(dir, "snapshot.json"))
+
+	// Other web pages can't save snapshots.
+	req := httptest.NewRequest(http.MethodPost, "/api/snapshot/save", strings.NewReader(`{"name":"snapshot.json"}`))
+	req.Header.Set("Content-Type", "text/plain")
+	rr := httptest.NewRecorder()
+	f.serv.Router().ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusUnsupportedMediaType, rr.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/snapshot/save", strings.NewReader(`{"name":"snapshot.json"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Origin", "https://evil.example.com")
+	rr = httptest.NewRecorder()
+	f.serv.Router().ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	assert.NoFileExists(t, filepath.Join(dir, "snapshot.json"))
+}
+
+func TestHandleSnapshotExport(t *testing.T) {
+	f := newTestFixture(t).withDummyManifests("fe")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/snapshot/export?format=html", nil)
+	rr := httptest.NewRecorder()
+	f.serv.Router().ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Equal(t, "text/html; charset=utf-8", rr.Header().Get("Content-Type"))
+	assert.Regexp(t, `^attachment; filename="tilt-snapshot-\d{8}-\d{6}\.html"$`, rr.Header().Get("Content-Disposition"))
+	assert.Contains(t, rr.Body.String(), "<title>Tilt Snapshot</title>")
+
+	req = httptest.NewRequest(http.MethodGet, "/api/snapshot/export", nil)
+	rr = httptest.NewRecorder()
+	f.serv.Router().ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	assert.Contains(t, rr.Body.String(), `"view"`)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/snapshot/export?format=pdf", nil)
+	rr = httptest.NewRecorder()
+	f.serv.Router().ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
@@ -0,0 +1,188 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tilt-dev/tilt/internal/snapshots"
+)
+
+type SnapshotSavePayload struct {
+	// A file name, like "snapshot.html". Tilt saves it in the directory
+	// of the main Tiltfile.
+	Name string `json:"name"`
+
+	// "json" or "html". Defaults to the format that matches
+	// the file extension.
+	Format string `json:"format,omitempty"`
+
+	// Replace the file if it already exists.
+	Overwrite bool `json:"overwrite,omitempty"`
+
+	// Also upload the snapshot to the storage configured with
+	// snapshot_settings() or TILT_SNAPSHOT_STORAGE_URL.
+	Upload bool `json:"upload,omitempty"`
+}
+
+type SnapshotSaveResponse struct {
+	Path   string `json:"path"`
+	Format string `json:"format"`
+
+	// A link to the uploaded snapshot, if it was uploaded.
+	URL string `json:"url,omitempty"`
+}
+
+// Renders the full view (including logs) into a snapshot
+// and writes it to disk, without going through the web UI.
+//
+// Anyone who can reach the server can call this, so it only writes
+// snapshot files (.json or .html) to the Tiltfile's directory, and never
+// replaces an existing file unless asked to.
+func (s *HeadsUpServer) HandleSnapshotSave(w http.ResponseWriter, req *http.Request) {
+	if !checkSameOriginJSON(w, req) {
+		return
+	}
+
+	var payload SnapshotSavePayload
+	err := json.NewDecoder(req.Body).Decode(&payload)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("error parsing JSON payload: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	err = validateSnapshotName(payload.Name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	format := snapshots.FormatFromPath(payload.Name)
+	if payload.Format != "" {
+		format, err = snapshots.ParseFormat(payload.Format)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	state := s.store.RLockState()
+	path := filepath.Join(filepath.Dir(state.MainTiltfilePath()), payload.Name)
+	s.store.RUnlockState()
+
+	// Check the storage before doing any work, so that a misconfigured
+	// upload doesn't leave a half-finished save behind.
+	var storage snapshots.Storage
+	if payload.Upload {
+		storage, err = s.snapshotStorage()
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error uploading snapshot: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	snapshot, err := s.completeSnapshot(req.Context())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error converting view to proto: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	data, err := snapshots.Render(snapshot, format)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error rendering snapshot: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	err = writeSnapshotFile(path, data, payload.Overwrite)
+	if os.IsExist(err) {
+		http.Error(w, fmt.Sprintf("%s already exists. Pass overwrite to replace it", path), http.StatusConflict)
+		return
+	} else if err != nil {
+		http.Error(w, fmt.Sprintf("Error writing snapshot: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	response := SnapshotSaveResponse{Path: path, Format: string(format)}
+	if storage != nil {
+		response.URL, err = uploadSnapshot(req.Context(), storage, data, format)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Snapshot saved to %s, but uploading it failed: %v", path, err),
+				http.StatusBadGateway)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error rendering response: %v", err), http.StatusInternalServerError)
+	}
+}
+
+// Snapshot names must be plain file names with a snapshot extension,
+// so that saving a snapshot can't replace a config file (like .bashrc
+// or the Tiltfile) or write outside the Tiltfile's directory.
+func validateSnapshotName(name string) error {
+	if name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("snapshot name must be a file name, like snapshot.html: %q", name)
+	}
+	ext := strings.ToLower(filepath.Ext(name))
+	if ext != snapshots.FormatJSON.Ext() && ext != snapshots.FormatHTML.Ext() {
+		return fmt.Errorf("snapshot name must end in %s or %s: %q",
+			snapshots.FormatJSON.Ext(), snapshots.FormatHTML.Ext(), name)
+	}
+	return nil
+}
+
+func writeSnapshotFile(path string, data []byte, overwrite bool) error {
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if overwrite {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	f, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Downloads a self-contained snapshot.
+//
+// Query params:
+//   - format: "json" (default) or "html"
+func (s *HeadsUpServer) HandleSnapshotExport(w http.ResponseWriter, req *http.Request) {
+	format := snapshots.FormatJSON
+	if f := req.URL.Query().Get("format"); f != "" {
+		var err error
+		format, err = snapshots.ParseFormat(f)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	snapshot, err := s.completeSnapshot(req.Context())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error converting view to proto: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	data, err := snapshots.Render(snapshot, format)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error rendering snapshot: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	filename := fmt.Sprintf("tilt-snapshot-%s%s", time.Now().UTC().Format("20060102-150405"), format.Ext())
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	_, _ = w.Write(data)
+}
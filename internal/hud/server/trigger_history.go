@@ -0,0 +1,118 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// What kind of event triggered a build.
+type TriggerKind string
+
+const (
+	TriggerKindInitial    TriggerKind = "initial"
+	TriggerKindFileChange TriggerKind = "file-change"
+	TriggerKindConfig     TriggerKind = "config"
+	TriggerKindDependency TriggerKind = "dependency"
+	TriggerKindManual     TriggerKind = "manual"
+	TriggerKindAPI        TriggerKind = "api"
+	TriggerKindOther      TriggerKind = "other"
+)
+
+func triggerKindForReason(r model.BuildReason) TriggerKind {
+	switch {
+	case r.Has(model.BuildReasonFlagTriggerExternal):
+		return TriggerKindAPI
+	case r.HasTrigger():
+		return TriggerKindManual
+	case r.Has(model.BuildReasonFlagInit):
+		return TriggerKindInitial
+	case r.Has(model.BuildReasonFlagChangedFiles):
+		return TriggerKindFileChange
+	case r.Has(model.BuildReasonFlagChangedDeps):
+		return TriggerKindDependency
+	case r.Has(model.BuildReasonFlagConfig), r.Has(model.BuildReasonFlagTiltfileArgs):
+		return TriggerKindConfig
+	}
+	return TriggerKindOther
+}
+
+// A trigger of a resource, and the build it started.
+type TriggerHistoryEntry struct {
+	Resource string      `json:"resource"`
+	Time     time.Time   `json:"time"`
+	Kind     TriggerKind `json:"kind"`
+	Reason   string      `json:"reason"`
+	Files    []string    `json:"files,omitempty"`
+	BuildID  string      `json:"buildID,omitempty"`
+	SpanID   string      `json:"spanID,omitempty"`
+}
+
+type TriggerHistoryResponse struct {
+	Triggers []TriggerHistoryEntry `json:"triggers"`
+}
+
+// Lists why resources were rebuilt, most recent first, to answer
+// "what keeps rebuilding this thing?":
+//
+//	/api/trigger_history?resources=fe,be
+//
+// Query parameters, all optional:
+//
+//   - resources: comma-separated resource names. Defaults to all resources.
+//   - limit: the maximum number of triggers to return.
+func (s *HeadsUpServer) HandleTriggerHistory(w http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+	limit, err := intParam(query.Get("limit"), 0, -1)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid limit: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	state := s.store.RLockState()
+	names := resourceNamesParam(query)
+	if len(names) == 0 {
+		names = allResourceNames(state)
+	}
+	response := TriggerHistoryResponse{Triggers: []TriggerHistoryEntry{}}
+	for _, mn := range names {
+		ms, ok := state.ManifestState(mn)
+		if !ok {
+			s.store.RUnlockState()
+			http.Error(w, fmt.Sprintf("resource %q does not exist", mn), http.StatusNotFound)
+			return
+		}
+		for _, t := range ms.TriggerHistory {
+			entry := TriggerHistoryEntry{
+				Resource: mn.String(),
+				Time:     t.Time,
+				Kind:     triggerKindForReason(t.Reason),
+				Reason:   t.Reason.String(),
+				Files:    t.Files,
+				SpanID:   string(t.SpanID),
+			}
+			if t.SpanID != "" {
+				entry.BuildID = buildIDForSpanID(t.SpanID)
+			}
+			response.Triggers = append(response.Triggers, entry)
+		}
+	}
+	s.store.RUnlockState()
+
+	sort.SliceStable(response.Triggers, func(i, j int) bool {
+		return response.Triggers[i].Time.After(response.Triggers[j].Time)
+	})
+	if limit > 0 && len(response.Triggers) > limit {
+		response.Triggers = response.Triggers[:limit]
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("error rendering trigger history: %v", err), http.StatusInternalServerError)
+	}
+}
@@ -73,6 +73,33 @@ type WebsocketSubscriber struct {
 
 	tiltStartTime    *timestamppb.Timestamp
 	clientCheckpoint logstore.Checkpoint
+
+	// Assigned by the WebsocketList.
+	id       string
+	throttle WebsocketThrottle
+
+	remoteAddr           string
+	connectedAt          time.Time
+	messagesSent         int
+	bytesSent            int64
+	lastSendDuration     time.Duration
+	slowSends            int
+	consecutiveSlowSends int
+	disconnectReason     string
+}
+
+// A snapshot of a websocket client's traffic, for diagnosing slow clients.
+type WebsocketStats struct {
+	ID                   string    `json:"id"`
+	RemoteAddr           string    `json:"remoteAddr,omitempty"`
+	ConnectedAt          time.Time `json:"connectedAt"`
+	MessagesSent         int       `json:"messagesSent"`
+	BytesSent            int64     `json:"bytesSent"`
+	LastSendDurationMs   int64     `json:"lastSendDurationMs"`
+	SlowSends            int       `json:"slowSends"`
+	ConsecutiveSlowSends int       `json:"consecutiveSlowSends"`
+	Slow                 bool      `json:"slow"`
+	DisconnectReason     string    `json:"disconnectReason,omitempty"`
 }
 
 type WebsocketConn interface {
@@ -83,6 +110,12 @@ type WebsocketConn interface {
 
 var _ WebsocketConn = &websocket.Conn{}
 
+// Implemented by *websocket.Conn. Not part of WebsocketConn
+// so that fake connections don't need to implement it.
+type writeDeadliner interface {
+	SetWriteDeadline(t time.Time) error
+}
+
 func NewWebsocketSubscriber(ctx context.Context, ctrlClient ctrlclient.Client, st store.RStore, conn WebsocketConn) *WebsocketSubscriber {
 	return &WebsocketSubscriber{
 		ctx:              ctx,
@@ -93,9 +126,52 @@ func NewWebsocketSubscriber(ctx context.Context, ctrlClient ctrlclient.Client, s
 		dirtyUIButtons:   make(map[string]*v1alpha1.UIButton),
 		dirtyUIResources: make(map[string]*v1alpha1.UIResource),
 		dirtyClusters:    make(map[string]*v1alpha1.Cluster),
+		throttle:         DefaultWebsocketThrottle(),
+		connectedAt:      time.Now(),
 	}
 }
 
+func (ws *WebsocketSubscriber) setIDAndThrottle(id string, throttle WebsocketThrottle) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	ws.id = id
+	ws.throttle = throttle
+}
+
+func (ws *WebsocketSubscriber) ID() string {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	return ws.id
+}
+
+func (ws *WebsocketSubscriber) Stats() WebsocketStats {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	return WebsocketStats{
+		ID:                   ws.id,
+		RemoteAddr:           ws.remoteAddr,
+		ConnectedAt:          ws.connectedAt,
+		MessagesSent:         ws.messagesSent,
+		BytesSent:            ws.bytesSent,
+		LastSendDurationMs:   ws.lastSendDuration.Milliseconds(),
+		SlowSends:            ws.slowSends,
+		ConsecutiveSlowSends: ws.consecutiveSlowSends,
+		Slow:                 ws.consecutiveSlowSends > 0,
+		DisconnectReason:     ws.disconnectReason,
+	}
+}
+
+// Closes the connection. The Stream loop will notice
+// the closed connection and shut down.
+func (ws *WebsocketSubscriber) Disconnect(reason string) {
+	ws.mu.Lock()
+	if ws.disconnectReason == "" {
+		ws.disconnectReason = reason
+	}
+	ws.mu.Unlock()
+	_ = ws.conn.Close()
+}
+
 func (ws *WebsocketSubscriber) TearDown(ctx context.Context) {
 	_ = ws.conn.Close()
 }
@@ -132,7 +208,11 @@ func (ws *WebsocketSubscriber) Stream(ctx context.Context) {
 		ws.onSessionUpdateSent(ctx, view.UiSession)
 	}
 
-	debouncer := time.NewTimer(200 * time.Millisecond)
+	ws.mu.Lock()
+	minSendInterval := ws.throttle.MinSendInterval
+	ws.mu.Unlock()
+
+	debouncer := time.NewTimer(minSendInterval)
 	defer func() {
 		if !debouncer.Stop() {
 			<-debouncer.C
@@ -159,7 +239,7 @@ func (ws *WebsocketSubscriber) Stream(ctx context.Context) {
 		case <-debouncer.C:
 		case <-ctx.Done():
 		}
-		debouncer.Reset(200 * time.Millisecond)
+		debouncer.Reset(minSendInterval)
 	}
 }
 
@@ -327,23 +407,81 @@ func (ws *WebsocketSubscriber) sendView(ctx context.Context, view *proto_webview
 		ws.tiltStartTime = view.TiltStartTime
 	}
 
+	ws.mu.Lock()
+	throttle := ws.throttle
+	ws.mu.Unlock()
+
+	if d, ok := ws.conn.(writeDeadliner); ok && throttle.WriteTimeout > 0 {
+		_ = d.SetWriteDeadline(time.Now().Add(throttle.WriteTimeout))
+	}
+
+	start := time.Now()
+	n, err := ws.writeView(ctx, view)
+	ws.recordSend(ctx, throttle, time.Since(start), n, err)
+}
+
+// Encodes the view onto the websocket. Returns the number of bytes written.
+func (ws *WebsocketSubscriber) writeView(ctx context.Context, view *proto_webview.View) (int64, error) {
 	jsEncoder := &runtime.JSONPb{}
 	w, err := ws.conn.NextWriter(websocket.TextMessage)
 	if err != nil {
 		logger.Get(ctx).Verbosef("getting writer: %v", err)
-		return
+		return 0, err
 	}
-	defer func() {
-		err := w.Close()
-		if err != nil {
-			logger.Get(ctx).Verbosef("error closing websocket writer: %v", err)
-		}
-	}()
 
-	err = jsEncoder.NewEncoder(w).Encode(view)
+	cw := &countingWriter{w: w}
+	err = jsEncoder.NewEncoder(cw).Encode(view)
 	if err != nil {
 		logger.Get(ctx).Verbosef("sending webview data: %v", err)
 	}
+
+	closeErr := w.Close()
+	if closeErr != nil {
+		logger.Get(ctx).Verbosef("error closing websocket writer: %v", closeErr)
+		if err == nil {
+			err = closeErr
+		}
+	}
+	return cw.n, err
+}
+
+// Updates the client stats after a send, and disconnects
+// the client if it's been slow for too long.
+func (ws *WebsocketSubscriber) recordSend(ctx context.Context, throttle WebsocketThrottle, dur time.Duration, n int64, err error) {
+	ws.mu.Lock()
+	ws.lastSendDuration = dur
+	if err == nil {
+		ws.messagesSent++
+		ws.bytesSent += n
+	}
+
+	slow := err != nil || (throttle.SlowSendThreshold > 0 && dur > throttle.SlowSendThreshold)
+	if slow {
+		ws.slowSends++
+		ws.consecutiveSlowSends++
+	} else {
+		ws.consecutiveSlowSends = 0
+	}
+	consecutiveSlowSends := ws.consecutiveSlowSends
+	id := ws.id
+	ws.mu.Unlock()
+
+	if throttle.MaxConsecutiveSlowSends > 0 && consecutiveSlowSends >= throttle.MaxConsecutiveSlowSends {
+		reason := fmt.Sprintf("slow client: %d consecutive slow sends", consecutiveSlowSends)
+		logger.Get(ctx).Debugf("Disconnecting websocket %s: %s", id, reason)
+		ws.Disconnect(reason)
+	}
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
 }
 
 func (s *HeadsUpServer) ViewWebsocket(w http.ResponseWriter, req *http.Request) {
@@ -354,6 +492,7 @@ func (s *HeadsUpServer) ViewWebsocket(w http.ResponseWriter, req *http.Request)
 	}
 
 	ws := NewWebsocketSubscriber(s.ctx, s.ctrlClient, s.store, conn)
+	ws.remoteAddr = req.RemoteAddr
 	s.wsList.Add(ws)
 	_ = s.store.AddSubscriber(s.ctx, ws)
 
@@ -186,6 +186,69 @@ func TestMergeUpdates(t *testing.T) {
 	assert.Len(t, view4.Clusters, 1, "Cluster updates")
 }
 
+func TestSlowClientDisconnect(t *testing.T) {
+	f := newWSFixture(t)
+	l := NewWebsocketListWithThrottle(WebsocketThrottle{
+		MinSendInterval:         time.Millisecond,
+		SlowSendThreshold:       time.Second,
+		MaxConsecutiveSlowSends: 2,
+	})
+	l.Add(f.ws)
+	throttle := f.ws.throttle
+
+	f.ws.recordSend(f.ctx, throttle, 2*time.Second, 10, nil)
+	stats := f.ws.Stats()
+	assert.Equal(t, "1", stats.ID)
+	assert.True(t, stats.Slow)
+	assert.Equal(t, 1, stats.SlowSends)
+	assert.False(t, f.conn.closed)
+
+	// A fast send resets the consecutive count.
+	f.ws.recordSend(f.ctx, throttle, time.Millisecond, 10, nil)
+	stats = f.ws.Stats()
+	assert.False(t, stats.Slow)
+	assert.Equal(t, 2, stats.MessagesSent)
+	assert.Equal(t, int64(20), stats.BytesSent)
+
+	f.ws.recordSend(f.ctx, throttle, 2*time.Second, 10, nil)
+	assert.False(t, f.conn.closed)
+	f.ws.recordSend(f.ctx, throttle, 0, 0, fmt.Errorf("write timeout"))
+	assert.True(t, f.conn.closed)
+
+	stats = f.ws.Stats()
+	assert.Equal(t, 3, stats.SlowSends)
+	assert.Equal(t, 3, stats.MessagesSent)
+	assert.Contains(t, stats.DisconnectReason, "2 consecutive slow sends")
+}
+
+func TestWebsocketListDisconnect(t *testing.T) {
+	f := newWSFixture(t)
+	l := NewWebsocketListWithThrottle(DefaultWebsocketThrottle())
+	l.Add(f.ws)
+
+	assert.False(t, l.Disconnect("2", "test"))
+	assert.False(t, f.conn.closed)
+
+	assert.True(t, l.Disconnect("1", "test"))
+	assert.True(t, f.conn.closed)
+	assert.Equal(t, "test", l.Stats()[0].DisconnectReason)
+}
+
+func TestWebsocketThrottleFromEnv(t *testing.T) {
+	t.Setenv("TILT_WEBSOCKET_MIN_SEND_INTERVAL", "1s")
+	t.Setenv("TILT_WEBSOCKET_MAX_SLOW_SENDS", "0")
+	throttle, err := WebsocketThrottleFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, time.Second, throttle.MinSendInterval)
+	assert.Equal(t, 0, throttle.MaxConsecutiveSlowSends)
+	assert.Equal(t, DefaultWebsocketThrottle().SlowSendThreshold, throttle.SlowSendThreshold)
+
+	t.Setenv("TILT_WEBSOCKET_WRITE_TIMEOUT", "forever")
+	_, err = WebsocketThrottleFromEnv()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid TILT_WEBSOCKET_WRITE_TIMEOUT")
+}
+
 type wsFixture struct {
 	ws   *WebsocketSubscriber
 	ctx  context.Context
@@ -1,21 +1,111 @@
 package server
 
 import (
+	"fmt"
+	"os"
+	"strconv"
 	"sync"
+	"time"
 )
 
+// WebsocketThrottle controls how often we push updates to each websocket
+// client, and when we decide that a client is too slow to keep around.
+type WebsocketThrottle struct {
+	// The minimum time between two sends to the same client.
+	// Updates that arrive in between are merged into the next send.
+	MinSendInterval time.Duration
+
+	// A send that takes longer than this marks the client as slow.
+	SlowSendThreshold time.Duration
+
+	// The deadline for a single send. A client that can't accept
+	// a message within this window is disconnected.
+	//
+	// Zero means no deadline.
+	WriteTimeout time.Duration
+
+	// After this many consecutive slow sends, we disconnect the client.
+	//
+	// Zero means we never disconnect a client for being slow.
+	MaxConsecutiveSlowSends int
+}
+
+func DefaultWebsocketThrottle() WebsocketThrottle {
+	return WebsocketThrottle{
+		MinSendInterval:         200 * time.Millisecond,
+		SlowSendThreshold:       2 * time.Second,
+		WriteTimeout:            30 * time.Second,
+		MaxConsecutiveSlowSends: 5,
+	}
+}
+
+// Reads overrides for the default throttle from the environment.
+//
+// TILT_WEBSOCKET_MIN_SEND_INTERVAL, TILT_WEBSOCKET_SLOW_SEND_THRESHOLD, and
+// TILT_WEBSOCKET_WRITE_TIMEOUT are durations (e.g., "500ms").
+// TILT_WEBSOCKET_MAX_SLOW_SENDS is an integer.
+func WebsocketThrottleFromEnv() (WebsocketThrottle, error) {
+	t := DefaultWebsocketThrottle()
+	durations := []struct {
+		env string
+		val *time.Duration
+	}{
+		{"TILT_WEBSOCKET_MIN_SEND_INTERVAL", &t.MinSendInterval},
+		{"TILT_WEBSOCKET_SLOW_SEND_THRESHOLD", &t.SlowSendThreshold},
+		{"TILT_WEBSOCKET_WRITE_TIMEOUT", &t.WriteTimeout},
+	}
+	for _, d := range durations {
+		s := os.Getenv(d.env)
+		if s == "" {
+			continue
+		}
+		v, err := time.ParseDuration(s)
+		if err != nil || v < 0 {
+			return DefaultWebsocketThrottle(), fmt.Errorf("invalid %s: %q", d.env, s)
+		}
+		*d.val = v
+	}
+
+	if s := os.Getenv("TILT_WEBSOCKET_MAX_SLOW_SENDS"); s != "" {
+		v, err := strconv.Atoi(s)
+		if err != nil || v < 0 {
+			return DefaultWebsocketThrottle(), fmt.Errorf("invalid TILT_WEBSOCKET_MAX_SLOW_SENDS: %q", s)
+		}
+		t.MaxConsecutiveSlowSends = v
+	}
+	return t, nil
+}
+
 type WebsocketList struct {
-	items []*WebsocketSubscriber
-	mu    sync.RWMutex
+	items    []*WebsocketSubscriber
+	mu       sync.RWMutex
+	throttle WebsocketThrottle
+	nextID   int
 }
 
 func NewWebsocketList() *WebsocketList {
-	return &WebsocketList{}
+	throttle, err := WebsocketThrottleFromEnv()
+	if err != nil {
+		// Fall back to the defaults. The env variables are a debugging
+		// tool, not something we want to fail startup over.
+		throttle = DefaultWebsocketThrottle()
+	}
+	return NewWebsocketListWithThrottle(throttle)
+}
+
+func NewWebsocketListWithThrottle(throttle WebsocketThrottle) *WebsocketList {
+	return &WebsocketList{throttle: throttle}
 }
 
+// Adds the websocket to the list.
+//
+// Assigns the websocket a unique ID and the list's throttle config,
+// so this must be called before the websocket starts streaming.
 func (l *WebsocketList) Add(w *WebsocketSubscriber) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
+	l.nextID++
+	w.setIDAndThrottle(strconv.Itoa(l.nextID), l.throttle)
 	l.items = append(l.items, w)
 }
 
@@ -45,3 +135,33 @@ func (l *WebsocketList) ForEach(f func(w *WebsocketSubscriber)) {
 		f(item)
 	}
 }
+
+// Stats for all connected websockets, in the order they connected.
+func (l *WebsocketList) Stats() []WebsocketStats {
+	result := []WebsocketStats{}
+	l.ForEach(func(w *WebsocketSubscriber) {
+		result = append(result, w.Stats())
+	})
+	return result
+}
+
+// The number of connected websockets.
+func (l *WebsocketList) Len() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return len(l.items)
+}
+
+// Disconnects the websocket with the given ID.
+//
+// Returns false if no such websocket exists.
+func (l *WebsocketList) Disconnect(id string, reason string) bool {
+	found := false
+	l.ForEach(func(w *WebsocketSubscriber) {
+		if w.ID() == id {
+			w.Disconnect(reason)
+			found = true
+		}
+	})
+	return found
+}
@@ -47,7 +47,9 @@ func (h *TerminalStream) OnChange(ctx context.Context, st store.RStore, _ store.
 	}
 
 	state := st.RLockState()
-	lines := state.LogStore.ContinuingLines(h.ProcessedLogs)
+	lines := state.LogStore.ContinuingLinesWithOptions(h.ProcessedLogs, logstore.LineOptions{
+		PrefixFormat: state.LogPrefixFormat,
+	})
 	checkpoint := state.LogStore.Checkpoint()
 	st.RUnlockState()
 
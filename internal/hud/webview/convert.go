@@ -3,8 +3,10 @@ package webview
 import (
 	"context"
 	"fmt"
+	"math"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"google.golang.org/protobuf/types/known/timestamppb"
@@ -17,6 +19,7 @@ import (
 	"github.com/tilt-dev/tilt/internal/controllers/apis/uiresource"
 	"github.com/tilt-dev/tilt/internal/engine/buildcontrol"
 	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/msgcat"
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/internal/store/k8sconv"
 	"github.com/tilt-dev/tilt/pkg/apis"
@@ -160,11 +163,18 @@ func ToUISession(s store.EngineState) *v1alpha1.UISession {
 		Date:      s.TiltBuildInfo.Date,
 	}
 	status.SuggestedTiltVersion = s.SuggestedTiltVersion
+	if status.SuggestedTiltVersion == "" && s.VersionCheck.UpgradeAvailable {
+		status.SuggestedTiltVersion = s.VersionCheck.LatestVersion
+		status.ReleaseHighlights = s.VersionCheck.Highlights
+	}
+	status.VersionWarnings = s.VersionCheck.Warnings
+	status.Idle = toUISessionIdleStatus(s)
 	status.FeatureFlags = []v1alpha1.UIFeatureFlag{}
 	for k, v := range s.Features {
 		status.FeatureFlags = append(status.FeatureFlags, v1alpha1.UIFeatureFlag{
-			Name:  k,
-			Value: v,
+			Name:   k,
+			Value:  v,
+			Source: string(s.FeatureSources[k]),
 		})
 	}
 	sort.Slice(status.FeatureFlags, func(i, j int) bool {
@@ -182,6 +192,15 @@ func ToUISession(s store.EngineState) *v1alpha1.UISession {
 
 	status.TiltfileKey = s.MainTiltfilePath()
 
+	for _, c := range s.Crashes {
+		status.Crashes = append(status.Crashes, v1alpha1.UISessionCrash{
+			Component: c.Component,
+			Message:   string(s.Secrets.Scrub([]byte(c.Message))),
+			Time:      metav1.NewTime(c.Time),
+			Count:     int32(c.Count),
+		})
+	}
+
 	return ret
 }
 
@@ -259,9 +278,15 @@ func toUIResource(mt *store.ManifestTarget, s store.EngineState, disableSources
 	endpoints := store.ManifestTargetEndpoints(mt)
 
 	bh := ToBuildsTerminated(ms.BuildHistory, s.LogStore)
+	if len(bh) > 0 {
+		populateBuildReport(&bh[0], mt, s)
+	}
 	lastDeploy := metav1.NewMicroTime(ms.LastSuccessfulDeployTime)
 	currentBuild := ms.EarliestCurrentBuild()
 	cb := ToBuildRunning(currentBuild)
+	if cb != nil {
+		populateBuildProgress(cb, mt, s)
+	}
 
 	specs, err := ToAPITargetSpecs(mt.Manifest.TargetSpecs())
 	if err != nil {
@@ -300,6 +325,12 @@ func toUIResource(mt *store.ManifestTarget, s store.EngineState, disableSources
 	}
 
 	populateResourceInfoView(mt, r)
+	if r.Status.K8sResourceInfo != nil {
+		ka, ok := s.KubernetesApplys[mn.String()]
+		if ok {
+			r.Status.K8sResourceInfo.PendingDiff = pendingDiff(ka.Status.Diff)
+		}
+	}
 
 	r.Status.Conditions = []v1alpha1.UIResourceCondition{
 		UIResourceUpToDateCondition(r.Status),
@@ -308,6 +339,88 @@ func toUIResource(mt *store.ManifestTarget, s store.EngineState, disableSources
 	return r, nil
 }
 
+// Summarizes the image build stages of a running build, so that the UI
+// can show a progress bar.
+//
+// Counts the images that are building now, and the images that finished
+// building during this build.
+func populateBuildProgress(cb *v1alpha1.UIBuildRunning, mt *store.ManifestTarget, s store.EngineState) {
+	var completed, total int32
+	done := 0.0
+	for _, iTarget := range mt.Manifest.ImageTargets {
+		di, ok := s.DockerImages[iTarget.DockerImageName]
+		if !ok {
+			continue
+		}
+		building := di.Status.Building != nil
+		builtThisBuild := di.Status.Completed != nil && !di.Status.Completed.StartedAt.Before(&cb.StartTime)
+		if !building && !builtThisBuild {
+			continue
+		}
+
+		for _, stage := range di.Status.StageStatuses {
+			total++
+			if stage.FinishedAt != nil {
+				completed++
+				done++
+				continue
+			}
+			if stage.StartedAt == nil {
+				continue
+			}
+
+			cb.CurrentStage = stage.Name
+			if stage.Total > 0 {
+				done += math.Min(1, float64(stage.Current)/float64(stage.Total))
+			}
+		}
+	}
+
+	if total == 0 {
+		return
+	}
+	cb.CompletedStages = completed
+	cb.TotalStages = total
+	cb.Progress = int32(100 * done / float64(total))
+}
+
+// Summarizes the image build stages of the most recent build, so that the UI
+// can show why it was slow.
+//
+// DockerImage only keeps the stages of the latest image build, so we can't
+// summarize older builds.
+func populateBuildReport(bt *v1alpha1.UIBuildTerminated, mt *store.ManifestTarget, s store.EngineState) {
+	var slowest time.Duration
+	for _, iTarget := range mt.Manifest.ImageTargets {
+		di, ok := s.DockerImages[iTarget.DockerImageName]
+		if !ok || di.Status.Completed == nil {
+			continue
+		}
+		completed := di.Status.Completed
+		if completed.StartedAt.Before(&bt.StartTime) || bt.FinishTime.Before(&completed.FinishedAt) {
+			// The image was built by a different build.
+			continue
+		}
+
+		for _, stage := range di.Status.StageStatuses {
+			bt.TotalStages++
+			if stage.Cached {
+				bt.CachedStages++
+				continue
+			}
+			if stage.StartedAt == nil || stage.FinishedAt == nil {
+				continue
+			}
+			d := stage.FinishedAt.Sub(stage.StartedAt.Time)
+			if d > slowest {
+				slowest = d
+				bt.SlowestStage = stage.Name
+				bt.SlowestStageMillis = d.Milliseconds()
+			}
+		}
+	}
+}
+
 // The "Ready" condition is a cross-resource status report that's synthesized
 // from the more type-specific fields of UIResource.
 func UIResourceReadyCondition(r v1alpha1.UIResourceStatus) v1alpha1.UIResourceCondition {
@@ -335,16 +448,22 @@ func UIResourceReadyCondition(r v1alpha1.UIResourceStatus) v1alpha1.UIResourceCo
 	c.Status = metav1.ConditionFalse
 	if r.DisableStatus.State == v1alpha1.DisableStateDisabled {
 		c.Reason = "Disabled"
+		setConditionMessage(&c, msgcat.ResourceDisabled, nil)
 	} else if r.RuntimeStatus == v1alpha1.RuntimeStatusError {
 		c.Reason = "RuntimeError"
+		setConditionMessage(&c, msgcat.ResourceRuntimeError, nil)
 	} else if r.UpdateStatus == v1alpha1.UpdateStatusError {
 		c.Reason = "UpdateError"
+		setConditionMessage(&c, msgcat.ResourceUpdateError, updateErrorArgs(r))
 	} else if r.UpdateStatus == v1alpha1.UpdateStatusOK && r.RuntimeStatus == v1alpha1.RuntimeStatusPending {
 		c.Reason = "RuntimePending"
+		setConditionMessage(&c, msgcat.ResourceRuntimePending, nil)
 	} else if r.UpdateStatus == v1alpha1.UpdateStatusPending {
 		c.Reason = "UpdatePending"
+		setConditionMessage(&c, msgcat.ResourceUpdatePending, nil)
 	} else {
 		c.Reason = "Unknown"
+		setConditionMessage(&c, msgcat.ResourceUnknown, nil)
 	}
 	return c
 }
@@ -366,16 +485,33 @@ func UIResourceUpToDateCondition(r v1alpha1.UIResourceStatus) v1alpha1.UIResourc
 	c.Status = metav1.ConditionFalse
 	if r.DisableStatus.State == v1alpha1.DisableStateDisabled {
 		c.Reason = "Disabled"
+		setConditionMessage(&c, msgcat.ResourceDisabled, nil)
 	} else if r.UpdateStatus == v1alpha1.UpdateStatusError {
 		c.Reason = "UpdateError"
+		setConditionMessage(&c, msgcat.ResourceUpdateError, updateErrorArgs(r))
 	} else if r.UpdateStatus == v1alpha1.UpdateStatusPending {
 		c.Reason = "UpdatePending"
+		setConditionMessage(&c, msgcat.ResourceUpdatePending, nil)
 	} else {
 		c.Reason = "Unknown"
+		setConditionMessage(&c, msgcat.ResourceUnknown, nil)
 	}
 	return c
 }
 
+func setConditionMessage(c *v1alpha1.UIResourceCondition, code msgcat.Code, args map[string]string) {
+	c.Message = msgcat.Message(code, args)
+	c.MessageCode = string(code)
+	c.MessageArgs = args
+}
+
+func updateErrorArgs(r v1alpha1.UIResourceStatus) map[string]string {
+	if len(r.BuildHistory) == 0 || r.BuildHistory[0].Error == "" {
+		return nil
+	}
+	return map[string]string{msgcat.ArgError: r.BuildHistory[0].Error}
+}
+
 // TODO(nick): We should build this from the Tiltfile in the apiserver,
 // not the Tiltfile state in EngineState.
 func TiltfileResource(name model.ManifestName, ms *store.ManifestState, logStore *logstore.LogStore) *v1alpha1.UIResource {
@@ -448,6 +584,22 @@ func populateResourceInfoView(mt *store.ManifestTarget, r *v1alpha1.UIResource)
 	}
 }
 
+// Combine the diffs of all the objects into a single unified diff.
+func pendingDiff(diff *v1alpha1.KubernetesApplyDiff) string {
+	if diff == nil {
+		return ""
+	}
+	if diff.Error != "" {
+		return fmt.Sprintf("# Error computing diff: %s\n", diff.Error)
+	}
+
+	var sb strings.Builder
+	for _, o := range diff.Objects {
+		sb.WriteString(o.Diff)
+	}
+	return sb.String()
+}
+
 func LogSegmentToEvent(seg *proto_webview.LogSegment, spans map[string]*proto_webview.LogSpan) store.LogAction {
 	span, ok := spans[seg.SpanId]
 	if !ok {
@@ -469,13 +621,18 @@ func holdToWaiting(hold store.Hold) *v1alpha1.UIResourceStateWaiting {
 	}
 	waiting := &v1alpha1.UIResourceStateWaiting{
 		Reason: string(hold.Reason),
+		On:     holdOnRefs(hold),
 	}
+	setWaitingMessage(waiting, hold.Reason)
+	return waiting
+}
 
+func holdOnRefs(hold store.Hold) []v1alpha1.UIResourceStateWaitingOnRef {
 	if hold.OnRefs != nil {
-		waiting.On = hold.OnRefs
-		return waiting
+		return hold.OnRefs
 	}
 
+	var refs []v1alpha1.UIResourceStateWaitingOnRef
 	for _, targetID := range hold.HoldOn {
 		var gvk schema.GroupVersionKind
 		switch targetID.Type {
@@ -487,8 +644,8 @@ func holdToWaiting(hold store.Hold) *v1alpha1.UIResourceStateWaiting {
 			continue
 		}
 
-		waiting.On = append(
-			waiting.On, v1alpha1.UIResourceStateWaitingOnRef{
+		refs = append(
+			refs, v1alpha1.UIResourceStateWaitingOnRef{
 				Group:      gvk.Group,
 				APIVersion: gvk.Version,
 				Kind:       gvk.Kind,
@@ -496,7 +653,38 @@ func holdToWaiting(hold store.Hold) *v1alpha1.UIResourceStateWaiting {
 			},
 		)
 	}
-	return waiting
+	return refs
+}
+
+var holdReasonCodes = map[store.HoldReason]msgcat.Code{
+	store.HoldReasonTiltfileReload:                   msgcat.WaitingForTiltfileReload,
+	store.HoldReasonWaitingForUnparallelizableTarget: msgcat.WaitingForLocal,
+	store.HoldReasonIsUnparallelizableTarget:         msgcat.WaitingForParallelLocal,
+	store.HoldReasonWaitingForUncategorized:          msgcat.WaitingForUncategorized,
+	store.HoldReasonBuildingComponent:                msgcat.WaitingForComponent,
+	store.HoldReasonWaitingForDep:                    msgcat.WaitingForDependency,
+	store.HoldReasonWaitingForDeploy:                 msgcat.WaitingForDeploy,
+	store.HoldReasonCluster:                          msgcat.WaitingForCluster,
+}
+
+func setWaitingMessage(waiting *v1alpha1.UIResourceStateWaiting, reason store.HoldReason) {
+	code, ok := holdReasonCodes[reason]
+	if !ok {
+		return
+	}
+
+	var args map[string]string
+	if len(waiting.On) > 0 {
+		names := make([]string, 0, len(waiting.On))
+		for _, ref := range waiting.On {
+			names = append(names, ref.Name)
+		}
+		args = map[string]string{msgcat.ArgOn: strings.Join(names, ", ")}
+	}
+
+	waiting.Message = msgcat.Message(code, args)
+	waiting.MessageCode = string(code)
+	waiting.MessageArgs = args
 }
 
 func manifestType(m model.Manifest) string {
@@ -524,3 +712,25 @@ func isExtensionTiltfile(tf *v1alpha1.Tiltfile) bool {
 	}
 	return false
 }
+
+func toUISessionIdleStatus(s store.EngineState) *v1alpha1.UISessionIdleStatus {
+	if !s.Idle.IsShutDown() && !s.Idle.IsResuming() {
+		return nil
+	}
+
+	status := &v1alpha1.UISessionIdleStatus{
+		ShutDownAt:      metav1.NewTime(s.Idle.ShutDownAt),
+		ResumeStartTime: metav1.NewTime(s.Idle.ResumeStartTime),
+	}
+	resources := s.Idle.Resources
+	if s.Idle.IsResuming() {
+		resources = s.Idle.Resuming
+		for _, mn := range s.IdleResumedResources() {
+			status.ResumedResources = append(status.ResumedResources, mn.String())
+		}
+	}
+	for _, mn := range resources {
+		status.Resources = append(status.Resources, mn.String())
+	}
+	return status
+}
@@ -9,14 +9,17 @@ import (
 	"k8s.io/apimachinery/pkg/util/uuid"
 
 	"github.com/tilt-dev/tilt/internal/store/k8sconv"
+	"github.com/tilt-dev/tilt/pkg/apis"
 	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	ctrltiltfile "github.com/tilt-dev/tilt/internal/controllers/core/tiltfile"
+	"github.com/tilt-dev/tilt/internal/feature"
 	"github.com/tilt-dev/tilt/internal/k8s"
 	"github.com/tilt-dev/tilt/internal/k8s/testyaml"
+	"github.com/tilt-dev/tilt/internal/msgcat"
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
 	"github.com/tilt-dev/tilt/internal/timecmp"
@@ -179,6 +182,25 @@ func TestStateToViewK8sTargetsIncludeDisplayNames(t *testing.T) {
 	assert.Equal(t, []string{"foo:namespace", "foo:secret"}, r.K8sResourceInfo.DisplayNames)
 }
 
+func TestStateToViewK8sPendingDiff(t *testing.T) {
+	m := model.Manifest{Name: "foo"}.WithDeployTarget(model.K8sTarget{})
+	state := newState([]model.Manifest{m})
+	state.KubernetesApplys["foo"] = &v1alpha1.KubernetesApply{
+		Status: v1alpha1.KubernetesApplyStatus{
+			Diff: &v1alpha1.KubernetesApplyDiff{
+				Objects: []v1alpha1.KubernetesApplyObjectDiff{
+					{Kind: "Secret", Name: "a", Action: "create", Diff: "+a\n"},
+					{Kind: "Secret", Name: "b", Action: "update", Diff: "-b\n+c\n"},
+				},
+			},
+		},
+	}
+
+	v := completeProtoView(t, *state)
+	r, _ := findResource(m.Name, v)
+	assert.Equal(t, "+a\n-b\n+c\n", r.K8sResourceInfo.PendingDiff)
+}
+
 func TestStateToViewTiltfileLog(t *testing.T) {
 	es := newState([]model.Manifest{})
 	spanID := ctrltiltfile.SpanIDForLoadCount("(Tiltfile)", 1)
@@ -229,11 +251,16 @@ func TestTriggerMode(t *testing.T) {
 
 func TestFeatureFlags(t *testing.T) {
 	state := newState(nil)
-	state.Features = map[string]bool{"foo_feature": true}
+	state.Features = map[string]bool{"foo_feature": true, "bar_feature": true}
+	state.FeatureSources = map[string]feature.Source{
+		"foo_feature": feature.SourceTiltfile,
+		"bar_feature": feature.SourceRollout,
+	}
 
 	v := completeProtoView(t, *state)
 	assert.Equal(t, v.UiSession.Status.FeatureFlags, []v1alpha1.UIFeatureFlag{
-		v1alpha1.UIFeatureFlag{Name: "foo_feature", Value: true},
+		v1alpha1.UIFeatureFlag{Name: "bar_feature", Value: true, Source: "rollout"},
+		v1alpha1.UIFeatureFlag{Name: "foo_feature", Value: true, Source: "tiltfile"},
 	})
 }
 
@@ -258,6 +285,7 @@ func TestReadinessCheckFailing(t *testing.T) {
 	require.True(t, ok)
 	require.Equal(t, v1alpha1.RuntimeStatusPending, rv.RuntimeStatus)
 	require.Equal(t, "False", string(readyCondition(rv).Status))
+	require.Equal(t, string(msgcat.ResourceUpdatePending), readyCondition(rv).MessageCode)
 }
 
 func TestRuntimeErrorAndDisabled(t *testing.T) {
@@ -294,10 +322,28 @@ func TestRuntimeErrorAndDisabled(t *testing.T) {
 	uc := upToDateCondition(rv)
 	require.Equal(t, "False", string(uc.Status))
 	require.Equal(t, "Disabled", uc.Reason)
+	require.Equal(t, string(msgcat.ResourceDisabled), uc.MessageCode)
+	require.Equal(t, "Resource is disabled", uc.Message)
 	require.Equal(t, v1alpha1.RuntimeStatusNone, rv.RuntimeStatus)
 	require.Equal(t, v1alpha1.UpdateStatusNone, rv.UpdateStatus)
 }
 
+func TestHoldToWaitingMessage(t *testing.T) {
+	waiting := holdToWaiting(store.Hold{
+		Reason: store.HoldReasonWaitingForDep,
+		HoldOn: []model.TargetID{
+			{Type: model.TargetTypeK8s, Name: "ignored"},
+			{Type: model.TargetTypeManifest, Name: "db"},
+			{Type: model.TargetTypeManifest, Name: "cache"},
+		},
+	})
+	require.NotNil(t, waiting)
+	assert.Equal(t, "waiting-for-dep", waiting.Reason)
+	assert.Equal(t, string(msgcat.WaitingForDependency), waiting.MessageCode)
+	assert.Equal(t, map[string]string{msgcat.ArgOn: "db, cache"}, waiting.MessageArgs)
+	assert.Equal(t, "Waiting on db, cache", waiting.Message)
+}
+
 func TestLocalResource(t *testing.T) {
 	cmd := model.Cmd{
 		Argv: []string{"make", "test"},
@@ -366,6 +412,85 @@ func TestBuildHistory(t *testing.T) {
 	}
 }
 
+func TestBuildProgress(t *testing.T) {
+	iTarget := model.ImageTarget{DockerImageName: "foo-image"}.WithBuildDetails(model.DockerBuild{})
+	m := model.Manifest{Name: "foo"}.WithImageTarget(iTarget).WithDeployTarget(model.K8sTarget{})
+	state := newState([]model.Manifest{m})
+
+	startTime := time.Now().Add(-time.Minute)
+	state.ManifestTargets[m.Name].State.CurrentBuilds["buildcontrol"] = model.BuildRecord{
+		StartTime: startTime,
+		SpanID:    "build:1",
+	}
+
+	stageStart := apis.NewMicroTime(startTime.Add(time.Second))
+	stageEnd := apis.NewMicroTime(startTime.Add(2 * time.Second))
+	state.DockerImages["foo-image"] = &v1alpha1.DockerImage{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo-image"},
+		Status: v1alpha1.DockerImageStatus{
+			Building: &v1alpha1.DockerImageStateBuilding{StartedAt: stageStart},
+			StageStatuses: []v1alpha1.DockerImageStageStatus{
+				{Name: "[1/4] FROM alpine", StartedAt: &stageStart, FinishedAt: &stageEnd},
+				{Name: "[2/4] COPY . .", StartedAt: &stageStart, Current: 50, Total: 100},
+				{Name: "[3/4] RUN make"},
+				{Name: "[4/4] RUN make install"},
+			},
+		},
+	}
+
+	v := completeProtoView(t, *state)
+	rs, ok := findResource(m.Name, v)
+	require.True(t, ok)
+	require.NotNil(t, rs.CurrentBuild)
+	assert.Equal(t, "[2/4] COPY . .", rs.CurrentBuild.CurrentStage)
+	assert.Equal(t, int32(1), rs.CurrentBuild.CompletedStages)
+	assert.Equal(t, int32(4), rs.CurrentBuild.TotalStages)
+	assert.Equal(t, int32(37), rs.CurrentBuild.Progress)
+}
+
+func TestBuildReport(t *testing.T) {
+	iTarget := model.ImageTarget{DockerImageName: "foo-image"}.WithBuildDetails(model.DockerBuild{})
+	m := model.Manifest{Name: "foo"}.WithImageTarget(iTarget).WithDeployTarget(model.K8sTarget{})
+	state := newState([]model.Manifest{m})
+
+	startTime := time.Now().Add(-time.Minute)
+	at := func(d time.Duration) *metav1.MicroTime {
+		t := apis.NewMicroTime(startTime.Add(d))
+		return &t
+	}
+	state.ManifestTargets[m.Name].State.BuildHistory = []model.BuildRecord{
+		{StartTime: startTime, FinishTime: startTime.Add(20 * time.Second), SpanID: "build:2"},
+		{StartTime: startTime.Add(-time.Hour), FinishTime: startTime.Add(-time.Hour + time.Second), SpanID: "build:1"},
+	}
+	state.DockerImages["foo-image"] = &v1alpha1.DockerImage{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo-image"},
+		Status: v1alpha1.DockerImageStatus{
+			Completed: &v1alpha1.DockerImageStateCompleted{
+				StartedAt:  *at(time.Second),
+				FinishedAt: *at(15 * time.Second),
+			},
+			StageStatuses: []v1alpha1.DockerImageStageStatus{
+				{Name: "[1/3] FROM alpine", Cached: true, StartedAt: at(time.Second), FinishedAt: at(time.Second)},
+				{Name: "[2/3] RUN make", StartedAt: at(time.Second), FinishedAt: at(13 * time.Second)},
+				{Name: "[3/3] COPY . .", StartedAt: at(13 * time.Second), FinishedAt: at(15 * time.Second)},
+			},
+		},
+	}
+
+	v := completeProtoView(t, *state)
+	rs, ok := findResource(m.Name, v)
+	require.True(t, ok)
+	require.Len(t, rs.BuildHistory, 2)
+	assert.Equal(t, int32(3), rs.BuildHistory[0].TotalStages)
+	assert.Equal(t, int32(1), rs.BuildHistory[0].CachedStages)
+	assert.Equal(t, "[2/3] RUN make", rs.BuildHistory[0].SlowestStage)
+	assert.Equal(t, int64(12000), rs.BuildHistory[0].SlowestStageMillis)
+
+	// We don't know the stages of older builds.
+	assert.Equal(t, int32(0), rs.BuildHistory[1].TotalStages)
+	assert.Equal(t, "", rs.BuildHistory[1].SlowestStage)
+}
+
 func TestSpecs(t *testing.T) {
 	luSpec := v1alpha1.LiveUpdateSpec{
 		BasePath: ".",
@@ -0,0 +1,32 @@
+package k8s
+
+import (
+	"reflect"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// InjectAnnotations adds the given annotations to every object in the entity,
+// including pod templates, replacing existing annotations with the same key.
+func InjectAnnotations(entity K8sEntity, annotations map[string]string) (K8sEntity, error) {
+	entity = entity.DeepCopy()
+
+	// Don't modify persistent volume claims, to match InjectLabels.
+	pvc := reflect.TypeOf(v1.PersistentVolumeClaim{})
+	metas, err := extractObjectMetas(&entity, func(v reflect.Value) bool {
+		return v.Type() != pvc
+	})
+	if err != nil {
+		return K8sEntity{}, err
+	}
+
+	for _, meta := range metas {
+		if meta.Annotations == nil {
+			meta.Annotations = make(map[string]string, len(annotations))
+		}
+		for k, v := range annotations {
+			meta.Annotations[k] = v
+		}
+	}
+	return entity, nil
+}
@@ -0,0 +1,50 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/tilt-dev/tilt/internal/k8s/testyaml"
+)
+
+func TestInjectAnnotationsDeployment(t *testing.T) {
+	entity := parseOneEntity(t, testyaml.SanchoYAML)
+	newEntity, err := InjectAnnotations(entity, map[string]string{"team": "payments"})
+	require.NoError(t, err)
+
+	d, ok := newEntity.Obj.(*appsv1.Deployment)
+	require.True(t, ok)
+	assert.Equal(t, "payments", d.Annotations["team"])
+	assert.Equal(t, "payments", d.Spec.Template.Annotations["team"])
+
+	// The original entity is unchanged.
+	assert.Empty(t, entity.Obj.(*appsv1.Deployment).Annotations)
+}
+
+func TestInjectAnnotationsOverridesExisting(t *testing.T) {
+	entity := parseOneEntity(t, testyaml.LonelyPodYAML)
+	entity.Obj.(*v1.Pod).Annotations = map[string]string{"team": "old", "ticket": "T-1"}
+
+	newEntity, err := InjectAnnotations(entity, map[string]string{"team": "payments"})
+	require.NoError(t, err)
+
+	p, ok := newEntity.Obj.(*v1.Pod)
+	require.True(t, ok)
+	assert.Equal(t, map[string]string{"team": "payments", "ticket": "T-1"}, p.Annotations)
+}
+
+func TestInjectAnnotationsSkipsPVC(t *testing.T) {
+	entities, err := ParseYAMLFromString(testyaml.DatabaseWithVolumesYAML)
+	require.NoError(t, err)
+
+	newEntity, err := InjectAnnotations(entities[0], map[string]string{"team": "payments"})
+	require.NoError(t, err)
+
+	pvc, ok := newEntity.Obj.(*v1.PersistentVolumeClaim)
+	require.True(t, ok)
+	assert.Empty(t, pvc.Annotations)
+}
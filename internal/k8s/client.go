@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/url"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -20,6 +21,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/validation"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/version"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
@@ -104,6 +106,12 @@ type Client interface {
 	// than they were passed in) and with UUIDs from the Kube API
 	Upsert(ctx context.Context, entities []K8sEntity, timeout time.Duration) ([]K8sEntity, error)
 
+	// Previews the result of Upsert with a server-side dry run, without
+	// changing anything in the cluster.
+	//
+	// Returns the entities as the server would store them.
+	DryRunUpsert(ctx context.Context, entities []K8sEntity, timeout time.Duration) ([]K8sEntity, error)
+
 	// Delete all given entities, optionally waiting for them to be fully deleted.
 	//
 	// Currently ignores any "not found" errors, because that seems like the correct
@@ -111,8 +119,23 @@ type Client interface {
 	Delete(ctx context.Context, entities []K8sEntity, wait bool) error
 
 	GetMetaByReference(ctx context.Context, ref v1.ObjectReference) (metav1.Object, error)
+
+	// Gets the full object, including its status.
+	GetByReference(ctx context.Context, ref v1.ObjectReference) (K8sEntity, error)
+
+	// Sets annotations on an object, without changing the rest of it.
+	//
+	// A nil value removes the annotation.
+	PatchAnnotations(ctx context.Context, ref v1.ObjectReference, annotations map[string]*string) error
+
 	ListMeta(ctx context.Context, gvk schema.GroupVersionKind, ns Namespace) ([]metav1.Object, error)
 
+	// Lists the pods in a namespace from the apiserver, rather than an informer cache.
+	// An empty namespace lists the pods in all namespaces.
+	//
+	// Used to poll for pods when we don't have permission to watch them.
+	ListPods(ctx context.Context, ns Namespace) ([]*v1.Pod, error)
+
 	// Streams the container logs
 	ContainerLogs(ctx context.Context, podID PodID, cName container.Name, n Namespace, startTime time.Time) (io.ReadCloser, error)
 
@@ -134,11 +157,23 @@ type Client interface {
 	// Returns version information about the apiserver, or an error if we're not connected.
 	CheckConnected(ctx context.Context) (*version.Info, error)
 
+	// Returns the API versions that the apiserver serves (e.g., "v1", "apps/v1"),
+	// in the same format as `kubectl api-versions`.
+	APIVersions(ctx context.Context) ([]string, error)
+
+	// Returns the CPU architectures of the cluster's nodes (e.g., "amd64", "arm64"),
+	// sorted and without duplicates.
+	NodeArchitectures(ctx context.Context) ([]string, error)
+
 	OwnerFetcher() OwnerFetcher
 
 	ClusterHealth(ctx context.Context, verbose bool) (ClusterHealth, error)
 
 	APIConfig() *api.Config
+
+	// Returns the names of the mutating admission webhooks that may
+	// change objects of the given kind when we apply them.
+	MutatingWebhooks(ctx context.Context, gvk schema.GroupVersionKind) ([]string, error)
 }
 
 type RESTMapper interface {
@@ -168,6 +203,7 @@ type K8sClient struct {
 	clientLoader      clientcmd.ClientConfig
 	resourceClient    ResourceClient
 	ownerFetcher      OwnerFetcher
+	establishing      *establishingKinds
 }
 
 var _ Client = &K8sClient{}
@@ -248,6 +284,7 @@ func ProvideK8sClient(
 		metadata:          meta,
 		apiConfig:         apiConfig,
 		clientLoader:      clientLoader,
+		establishing:      newEstablishingKinds(),
 	}
 	c.resourceClient = newResourceClient(c)
 	c.ownerFetcher = NewOwnerFetcher(globalCtx, c)
@@ -317,6 +354,93 @@ func (k *K8sClient) ToDiscoveryClient() (discovery.CachedDiscoveryInterface, err
 	return k.discovery, nil
 }
 
+func (k *K8sClient) APIVersions(ctx context.Context) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	discoClient, err := k.ToDiscoveryClient()
+	if err != nil {
+		return nil, err
+	}
+
+	// Query the server directly rather than through the discovery cache,
+	// so that we see CRDs installed since the last load.
+	restClient := discoClient.RESTClient()
+	if restClient == nil {
+		return apiVersionsFromGroups(k.clientset.Discovery().ServerGroups())
+	}
+
+	// Like ServerGroups(), tolerate servers that don't serve the legacy core group.
+	var legacy metav1.APIVersions
+	body, err := restClient.Get().AbsPath("/api").Do(ctx).Raw()
+	if err != nil && !apierrors.IsNotFound(err) && !apierrors.IsForbidden(err) {
+		return nil, err
+	}
+	if err == nil {
+		err = json.Unmarshal(body, &legacy)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse the server API versions: %v", err)
+		}
+	}
+
+	groups := &metav1.APIGroupList{}
+	body, err = restClient.Get().AbsPath("/apis").Do(ctx).Raw()
+	if err != nil && !apierrors.IsNotFound(err) && !apierrors.IsForbidden(err) {
+		return nil, err
+	}
+	if err == nil {
+		err = json.Unmarshal(body, groups)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse the server API groups: %v", err)
+		}
+	}
+
+	for _, v := range legacy.Versions {
+		groups.Groups = append(groups.Groups, metav1.APIGroup{
+			Versions: []metav1.GroupVersionForDiscovery{{GroupVersion: v, Version: v}},
+		})
+	}
+	return apiVersionsFromGroups(groups, nil)
+}
+
+func apiVersionsFromGroups(groups *metav1.APIGroupList, err error) ([]string, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	var result []string
+	for _, g := range groups.Groups {
+		for _, v := range g.Versions {
+			result = append(result, v.GroupVersion)
+		}
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+func (k *K8sClient) NodeArchitectures(ctx context.Context) ([]string, error) {
+	nodes, err := k.core.Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var result []string
+	for _, n := range nodes.Items {
+		arch := n.Status.NodeInfo.Architecture
+		if arch == "" {
+			arch = n.Labels[v1.LabelArchStable]
+		}
+		if arch == "" || seen[arch] {
+			continue
+		}
+		seen[arch] = true
+		result = append(result, arch)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
 // Loosely adapted from ctlptl.
 func (k *K8sClient) CheckConnected(ctx context.Context) (*version.Info, error) {
 	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
@@ -363,12 +487,41 @@ func (k *K8sClient) Upsert(ctx context.Context, entities []K8sEntity, timeout ti
 			}
 			return nil, err
 		}
+		k.establishing.add(newEntity)
 		result = append(result, newEntity...)
 	}
 
 	return result, nil
 }
 
+func (k *K8sClient) DryRunUpsert(ctx context.Context, entities []K8sEntity, timeout time.Duration) ([]K8sEntity, error) {
+	result := make([]K8sEntity, 0, len(entities))
+	for _, e := range entities {
+		innerCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		resources, err := k.prepareUpdateList(innerCtx, e)
+		if err != nil {
+			return nil, errors.Wrap(err, "kubernetes dry run")
+		}
+
+		applied, err := k.resourceClient.DryRunApply(resources)
+		if err != nil {
+			if ctx.Err() == context.DeadlineExceeded {
+				return nil, timeoutError(timeout)
+			}
+			return nil, errors.Wrapf(err, "kubernetes dry run of %s", e.Name())
+		}
+
+		newEntities, err := k.helmResultToEntities(applied)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, newEntities...)
+	}
+	return result, nil
+}
+
 func (k *K8sClient) OwnerFetcher() OwnerFetcher {
 	return k.ownerFetcher
 }
@@ -441,7 +594,7 @@ func (k *K8sClient) deleteAndCreateEntity(ctx context.Context, entity K8sEntity)
 
 // Make sure the type exists and create a ResourceList to help update it.
 func (k *K8sClient) prepareUpdateList(ctx context.Context, e K8sEntity) (kube.ResourceList, error) {
-	_, err := k.forceDiscovery(ctx, e.GVK())
+	_, err := k.discoverKind(ctx, e.GVK())
 	if err != nil {
 		return nil, err
 	}
@@ -709,6 +862,18 @@ func (k *K8sClient) ListMeta(ctx context.Context, gvk schema.GroupVersionKind, n
 	return result, nil
 }
 
+func (k *K8sClient) ListPods(ctx context.Context, ns Namespace) ([]*v1.Pod, error) {
+	list, err := k.core.Pods(ns.String()).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*v1.Pod, len(list.Items))
+	for i := range list.Items {
+		result[i] = &list.Items[i]
+	}
+	return result, nil
+}
+
 func (k *K8sClient) GetMetaByReference(ctx context.Context, ref v1.ObjectReference) (metav1.Object, error) {
 	gvk := ReferenceGVK(ref)
 	mapping, err := k.forceDiscovery(ctx, gvk)
@@ -735,6 +900,52 @@ func (k *K8sClient) GetMetaByReference(ctx context.Context, ref v1.ObjectReferen
 	return &meta, nil
 }
 
+func (k *K8sClient) GetByReference(ctx context.Context, ref v1.ObjectReference) (K8sEntity, error) {
+	gvk := ReferenceGVK(ref)
+	mapping, err := k.forceDiscovery(ctx, gvk)
+	if err != nil {
+		return K8sEntity{}, err
+	}
+
+	gvr := mapping.Resource
+	obj, err := k.dynamic.Resource(gvr).Namespace(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return K8sEntity{}, err
+	}
+	if ref.UID != "" && obj.GetUID() != ref.UID {
+		return K8sEntity{}, apierrors.NewNotFound(v1.Resource(gvr.Resource), ref.Name)
+	}
+	return NewK8sEntity(obj), nil
+}
+
+func (k *K8sClient) PatchAnnotations(ctx context.Context, ref v1.ObjectReference, annotations map[string]*string) error {
+	gvk := ReferenceGVK(ref)
+	mapping, err := k.forceDiscovery(ctx, gvk)
+	if err != nil {
+		return err
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": annotations,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	namespace := ref.Namespace
+	if mapping.Scope.Name() == meta.RESTScopeNameRoot {
+		namespace = ""
+	}
+	_, err = k.metadata.Resource(mapping.Resource).Namespace(namespace).Patch(
+		ctx, ref.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "annotating %s %s", ref.Kind, ref.Name)
+	}
+	return nil
+}
+
 func (k *K8sClient) ClusterHealth(ctx context.Context, verbose bool) (ClusterHealth, error) {
 	isLive, livezResp, err := k.apiServerHealthCheck(ctx, "/livez", verbose)
 	if err != nil {
@@ -183,6 +183,50 @@ func TestGetGroup(t *testing.T) {
 	}
 }
 
+func TestNodeArchitectures(t *testing.T) {
+	f := newClientTestFixture(t)
+	nodes := []*v1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "a"}, Status: v1.NodeStatus{NodeInfo: v1.NodeSystemInfo{Architecture: "arm64"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "b"}, Status: v1.NodeStatus{NodeInfo: v1.NodeSystemInfo{Architecture: "amd64"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "c"}, Status: v1.NodeStatus{NodeInfo: v1.NodeSystemInfo{Architecture: "arm64"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "d", Labels: map[string]string{v1.LabelArchStable: "s390x"}}},
+	}
+	for _, n := range nodes {
+		require.NoError(t, f.tracker.Add(n))
+	}
+
+	archs, err := f.client.NodeArchitectures(f.ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"amd64", "arm64", "s390x"}, archs)
+}
+
+func TestAPIVersions(t *testing.T) {
+	f := newClientTestFixture(t)
+	f.restClient.NegotiatedSerializer = scheme.Codecs.WithoutConversion()
+	f.restClient.Client = restfake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+		_, hasDeadline := req.Context().Deadline()
+		assert.True(t, hasDeadline, "discovery request should be bounded by a timeout")
+
+		var body string
+		switch req.URL.Path {
+		case "/api":
+			body = `{"versions": ["v1"]}`
+		case "/apis":
+			body = `{"groups": [
+  {"name": "apps", "versions": [{"groupVersion": "apps/v1", "version": "v1"}]},
+  {"name": "tilt.dev", "versions": [{"groupVersion": "tilt.dev/v1alpha1", "version": "v1alpha1"}]}
+]}`
+		default:
+			return &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(strings.NewReader(""))}, nil
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body))}, nil
+	})
+
+	versions, err := f.client.APIVersions(f.ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"apps/v1", "tilt.dev/v1alpha1", "v1"}, versions)
+}
+
 func TestServerHealth(t *testing.T) {
 	// NOTE: the health endpoint contract only specifies that 200 is healthy
 	// 	and any other status code indicates not-healthy; in practice, apiserver
@@ -279,6 +323,9 @@ func (c *fakeResourceClient) Apply(target kube.ResourceList) (*kube.Result, erro
 	c.updates = append(c.updates, target...)
 	return &kube.Result{Updated: target}, nil
 }
+func (c *fakeResourceClient) DryRunApply(target kube.ResourceList) (*kube.Result, error) {
+	return &kube.Result{Updated: target}, nil
+}
 func (c *fakeResourceClient) Delete(l kube.ResourceList) (*kube.Result, []error) {
 	c.deletes = append(c.deletes, l...)
 	return &kube.Result{Deleted: l}, nil
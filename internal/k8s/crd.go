@@ -0,0 +1,111 @@
+package k8s
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// How often to check if the apiserver is serving a newly-defined kind.
+const crdEstablishInterval = 250 * time.Millisecond
+
+// Returns true if the entity is a CustomResourceDefinition.
+func IsCRD(e K8sEntity) bool {
+	gvk := e.GVK()
+	return gvk.Group == "apiextensions.k8s.io" && gvk.Kind == "CustomResourceDefinition"
+}
+
+// Returns the kind that a CustomResourceDefinition defines.
+func crdDefinedKind(e K8sEntity) (schema.GroupKind, bool) {
+	if !IsCRD(e) {
+		return schema.GroupKind{}, false
+	}
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(e.Obj)
+	if err != nil {
+		return schema.GroupKind{}, false
+	}
+	group, _, _ := unstructured.NestedString(content, "spec", "group")
+	kind, _, _ := unstructured.NestedString(content, "spec", "names", "kind")
+	if kind == "" {
+		return schema.GroupKind{}, false
+	}
+	return schema.GroupKind{Group: group, Kind: kind}, true
+}
+
+// The kinds defined by CustomResourceDefinitions that we've applied,
+// but haven't seen the apiserver serve yet.
+//
+// When a YAML contains both a CRD and objects of that kind, the objects
+// can't be applied until the apiserver has established the CRD.
+type establishingKinds struct {
+	mu    sync.Mutex
+	kinds map[schema.GroupKind]bool
+}
+
+func newEstablishingKinds() *establishingKinds {
+	return &establishingKinds{kinds: make(map[schema.GroupKind]bool)}
+}
+
+func (k *establishingKinds) add(entities []K8sEntity) {
+	if k == nil {
+		return
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	for _, e := range entities {
+		gk, ok := crdDefinedKind(e)
+		if ok {
+			k.kinds[gk] = true
+		}
+	}
+}
+
+func (k *establishingKinds) contains(gk schema.GroupKind) bool {
+	if k == nil {
+		return false
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.kinds[gk]
+}
+
+func (k *establishingKinds) remove(gk schema.GroupKind) {
+	if k == nil {
+		return
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	delete(k.kinds, gk)
+}
+
+// Like forceDiscovery, but if the kind is defined by a CRD that we
+// just applied, waits for the apiserver to establish it.
+func (k *K8sClient) discoverKind(ctx context.Context, gvk schema.GroupVersionKind) (*meta.RESTMapping, error) {
+	gk := gvk.GroupKind()
+	for {
+		rm, err := k.forceDiscovery(ctx, gvk)
+		if err == nil {
+			k.establishing.remove(gk)
+			return rm, nil
+		}
+		if !meta.IsNoMatchError(errors.Cause(err)) || !k.establishing.contains(gk) {
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, errors.Wrapf(err, "waiting for CustomResourceDefinition of %s to be established", gvk.Kind)
+		case <-time.After(crdEstablishInterval):
+		}
+	}
+}
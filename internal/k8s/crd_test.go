@@ -0,0 +1,77 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/tilt-dev/tilt/internal/k8s/testyaml"
+)
+
+func TestCRDDefinedKind(t *testing.T) {
+	entities := MustParseYAMLFromString(t, testyaml.CRDYAML)
+	require.Len(t, entities, 2)
+
+	gk, ok := crdDefinedKind(entities[0])
+	assert.True(t, ok)
+	assert.Equal(t, schema.GroupKind{Group: "example.martin-helmich.de", Kind: "Project"}, gk)
+
+	_, ok = crdDefinedKind(entities[1])
+	assert.False(t, ok)
+}
+
+func TestUpsertWaitsForCRDToBeEstablished(t *testing.T) {
+	f := newClientTestFixture(t)
+	drm := &establishingRESTMapper{
+		gk:         schema.GroupKind{Group: "example.martin-helmich.de", Kind: "Project"},
+		resetsLeft: 2,
+	}
+	f.client.drm = drm
+	f.client.establishing = newEstablishingKinds()
+
+	_, err := f.k8sUpsert(f.ctx, MustParseYAMLFromString(t, testyaml.CRDYAML))
+	require.NoError(t, err)
+	assert.Equal(t, 2, len(f.resourceClient.updates))
+	assert.Equal(t, 0, drm.resetsLeft)
+	assert.False(t, f.client.establishing.contains(drm.gk))
+}
+
+func TestUpsertUnknownKindFailsFast(t *testing.T) {
+	f := newClientTestFixture(t)
+	drm := &establishingRESTMapper{
+		gk:         schema.GroupKind{Group: "example.martin-helmich.de", Kind: "Project"},
+		resetsLeft: 100,
+	}
+	f.client.drm = drm
+	f.client.establishing = newEstablishingKinds()
+
+	// Without the CRD, there's nothing to wait for.
+	entities := MustParseYAMLFromString(t, testyaml.CRDYAML)
+	_, err := f.k8sUpsert(f.ctx, entities[1:])
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `no matches for kind "Project"`)
+	}
+	assert.Equal(t, 99, drm.resetsLeft)
+}
+
+// A REST mapper that only discovers a kind after it's been reset a few times,
+// like an apiserver that's still establishing a CRD.
+type establishingRESTMapper struct {
+	fakeRESTMapper
+	gk         schema.GroupKind
+	resetsLeft int
+}
+
+func (m *establishingRESTMapper) RESTMapping(gk schema.GroupKind, versions ...string) (*meta.RESTMapping, error) {
+	if gk == m.gk && m.resetsLeft > 0 {
+		return nil, &meta.NoKindMatchError{GroupKind: gk, SearchedVersions: versions}
+	}
+	return m.fakeRESTMapper.RESTMapping(gk, versions...)
+}
+
+func (m *establishingRESTMapper) Reset() {
+	m.resetsLeft--
+}
@@ -0,0 +1,83 @@
+package k8s
+
+import (
+	"fmt"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// The annotation that kubectl adds to Jobs that it creates
+// with `kubectl create job --from=cronjob/NAME`.
+const AnnotationCronJobInstantiate = "cronjob.kubernetes.io/instantiate"
+
+// Job names are used as pod label values, so they have the same length limit.
+const maxJobNameLength = 63
+
+func IsCronJob(e K8sEntity) bool {
+	switch e.Obj.(type) {
+	case *batchv1.CronJob, *batchv1beta1.CronJob:
+		return true
+	}
+	return false
+}
+
+// Creates a Job from a CronJob's job template, the same way that
+// `kubectl create job --from=cronjob/NAME` does.
+//
+// The CronJob owns the Job. This means that Tilt finds the Job's pods
+// through the CronJob, and Kubernetes deletes the Job with the CronJob.
+func JobFromCronJob(e K8sEntity, now time.Time) (K8sEntity, error) {
+	var template batchv1.JobTemplateSpec
+	switch cj := e.Obj.(type) {
+	case *batchv1.CronJob:
+		template = *cj.Spec.JobTemplate.DeepCopy()
+	case *batchv1beta1.CronJob:
+		template = batchv1.JobTemplateSpec{
+			ObjectMeta: *cj.Spec.JobTemplate.ObjectMeta.DeepCopy(),
+			Spec:       *cj.Spec.JobTemplate.Spec.DeepCopy(),
+		}
+	default:
+		return K8sEntity{}, fmt.Errorf("%s is not a CronJob", e.Name())
+	}
+
+	annotations := make(map[string]string, len(template.Annotations)+1)
+	for k, v := range template.Annotations {
+		annotations[k] = v
+	}
+	annotations[AnnotationCronJobInstantiate] = "manual"
+
+	suffix := fmt.Sprintf("-manual-%d", now.Unix())
+	prefix := e.Name()
+	if len(prefix)+len(suffix) > maxJobNameLength {
+		prefix = prefix[:maxJobNameLength-len(suffix)]
+	}
+
+	gvk := e.GVK()
+	isController := true
+	job := &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: batchv1.SchemeGroupVersion.String(),
+			Kind:       "Job",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        prefix + suffix,
+			Namespace:   e.Meta().GetNamespace(),
+			Labels:      template.Labels,
+			Annotations: annotations,
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: gvk.GroupVersion().String(),
+					Kind:       gvk.Kind,
+					Name:       e.Name(),
+					UID:        e.UID(),
+					Controller: &isController,
+				},
+			},
+		},
+		Spec: template.Spec,
+	}
+	return NewK8sEntity(job), nil
+}
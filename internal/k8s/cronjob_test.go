@@ -0,0 +1,55 @@
+package k8s
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+
+	"github.com/tilt-dev/tilt/internal/k8s/testyaml"
+)
+
+func TestJobFromCronJob(t *testing.T) {
+	cronJob := mustParseYAML(t, testyaml.CronJobYAML)[0]
+	cronJob.SetUID("cronjob-uid")
+	require.True(t, IsCronJob(cronJob))
+
+	entity, err := JobFromCronJob(cronJob, time.Unix(1600000000, 0))
+	require.NoError(t, err)
+
+	job := entity.Obj.(*batchv1.Job)
+	assert.Equal(t, "hello-manual-1600000000", job.Name)
+	assert.Equal(t, "default", job.Namespace)
+	assert.Equal(t, map[string]string{"app": "hello"}, job.Labels)
+	assert.Equal(t, "manual", job.Annotations[AnnotationCronJobInstantiate])
+	assert.Equal(t, "busybox", job.Spec.Template.Spec.Containers[0].Image)
+
+	require.Len(t, job.OwnerReferences, 1)
+	owner := job.OwnerReferences[0]
+	assert.Equal(t, "batch/v1", owner.APIVersion)
+	assert.Equal(t, "CronJob", owner.Kind)
+	assert.Equal(t, "hello", owner.Name)
+	assert.Equal(t, "cronjob-uid", string(owner.UID))
+	assert.True(t, *owner.Controller)
+}
+
+func TestJobFromCronJobLongName(t *testing.T) {
+	cronJob := mustParseYAML(t, testyaml.CronJobYAML)[0]
+	cronJob.Meta().SetName(strings.Repeat("a", 60))
+
+	entity, err := JobFromCronJob(cronJob, time.Unix(1600000000, 0))
+	require.NoError(t, err)
+	assert.Len(t, entity.Name(), maxJobNameLength)
+	assert.True(t, strings.HasSuffix(entity.Name(), "-manual-1600000000"))
+}
+
+func TestJobFromCronJobNotACronJob(t *testing.T) {
+	job := mustParseYAML(t, testyaml.JobYAML)[0]
+	assert.False(t, IsCronJob(job))
+
+	_, err := JobFromCronJob(job, time.Now())
+	assert.Error(t, err)
+}
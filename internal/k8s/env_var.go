@@ -0,0 +1,39 @@
+package k8s
+
+import (
+	v1 "k8s.io/api/core/v1"
+)
+
+// Iterate through the containers of a k8s entity and
+// set the given environment variables on each of them.
+//
+// If a container already has a variable with the same name,
+// its value is replaced (including any valueFrom).
+func InjectEnv(entity K8sEntity, env []v1.EnvVar) (K8sEntity, error) {
+	if len(env) == 0 {
+		return entity, nil
+	}
+
+	entity = entity.DeepCopy()
+	containers, err := extractContainers(&entity)
+	if err != nil {
+		return K8sEntity{}, err
+	}
+
+	for _, c := range containers {
+		for _, e := range env {
+			c.Env = setEnvVar(c.Env, e)
+		}
+	}
+	return entity, nil
+}
+
+func setEnvVar(env []v1.EnvVar, e v1.EnvVar) []v1.EnvVar {
+	for i := range env {
+		if env[i].Name == e.Name {
+			env[i] = e
+			return env
+		}
+	}
+	return append(env, e)
+}
@@ -0,0 +1,46 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/tilt-dev/tilt/internal/k8s/testyaml"
+)
+
+func TestInjectEnv(t *testing.T) {
+	entity := mustParseYAML(t, testyaml.SanchoSidecarYAML)[0]
+	newEntity, err := InjectEnv(entity, []v1.EnvVar{{Name: "FEATURE_X", Value: "true"}})
+	require.NoError(t, err)
+
+	containers, err := extractContainers(&newEntity)
+	require.NoError(t, err)
+	require.Len(t, containers, 2)
+	assert.Equal(t, []string{"token", "FEATURE_X"}, envVarNames(containers[0].Env))
+	assert.Equal(t, []v1.EnvVar{{Name: "FEATURE_X", Value: "true"}}, containers[1].Env)
+
+	// The original entity isn't modified.
+	containers, err = extractContainers(&entity)
+	require.NoError(t, err)
+	assert.Empty(t, containers[1].Env)
+}
+
+func TestInjectEnvReplacesExisting(t *testing.T) {
+	entity := mustParseYAML(t, testyaml.SanchoSidecarYAML)[0]
+	newEntity, err := InjectEnv(entity, []v1.EnvVar{{Name: "token", Value: "fake-token"}})
+	require.NoError(t, err)
+
+	containers, err := extractContainers(&newEntity)
+	require.NoError(t, err)
+	assert.Equal(t, []v1.EnvVar{{Name: "token", Value: "fake-token"}}, containers[0].Env)
+}
+
+func envVarNames(env []v1.EnvVar) []string {
+	result := make([]string, 0, len(env))
+	for _, e := range env {
+		result = append(result, e.Name)
+	}
+	return result
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/pkg/errors"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/version"
@@ -29,6 +30,10 @@ func (ec *explodingClient) Upsert(ctx context.Context, entities []K8sEntity, tim
 	return nil, errors.Wrap(ec.err, "could not set up kubernetes client")
 }
 
+func (ec *explodingClient) DryRunUpsert(ctx context.Context, entities []K8sEntity, timeout time.Duration) ([]K8sEntity, error) {
+	return nil, errors.Wrap(ec.err, "could not set up kubernetes client")
+}
+
 func (ec *explodingClient) Delete(ctx context.Context, entities []K8sEntity, wait bool) error {
 	return errors.Wrap(ec.err, "could not set up kubernetes client")
 }
@@ -37,6 +42,14 @@ func (ec *explodingClient) GetMetaByReference(ctx context.Context, ref v1.Object
 	return nil, errors.Wrap(ec.err, "could not set up kubernetes client")
 }
 
+func (ec *explodingClient) GetByReference(ctx context.Context, ref v1.ObjectReference) (K8sEntity, error) {
+	return K8sEntity{}, errors.Wrap(ec.err, "could not set up kubernetes client")
+}
+
+func (ec *explodingClient) PatchAnnotations(ctx context.Context, ref v1.ObjectReference, annotations map[string]*string) error {
+	return errors.Wrap(ec.err, "could not set up kubernetes client")
+}
+
 func (ec *explodingClient) ListMeta(ctx context.Context, gvk schema.GroupVersionKind, ns Namespace) ([]metav1.Object, error) {
 	return nil, errors.Wrap(ec.err, "could not set up kubernetes client")
 }
@@ -61,10 +74,22 @@ func (ec *explodingClient) WatchPods(ctx context.Context, ns Namespace) (<-chan
 	return nil, errors.Wrap(ec.err, "could not set up kubernetes client")
 }
 
+func (ec *explodingClient) ListPods(ctx context.Context, ns Namespace) ([]*v1.Pod, error) {
+	return nil, errors.Wrap(ec.err, "could not set up kubernetes client")
+}
+
 func (ec *explodingClient) PodFromInformerCache(ctx context.Context, nn types.NamespacedName) (*v1.Pod, error) {
 	return nil, errors.Wrap(ec.err, "could not set up kubernetes client")
 }
 
+func (ec *explodingClient) PodsFromInformerCache(ctx context.Context, ns Namespace, selector labels.Selector) ([]*v1.Pod, error) {
+	return nil, errors.Wrap(ec.err, "could not set up kubernetes client")
+}
+
+func (ec *explodingClient) ServiceFromInformerCache(ctx context.Context, nn types.NamespacedName) (*v1.Service, error) {
+	return nil, errors.Wrap(ec.err, "could not set up kubernetes client")
+}
+
 func (ec *explodingClient) WatchServices(ctx context.Context, ns Namespace) (<-chan *v1.Service, error) {
 	return nil, errors.Wrap(ec.err, "could not set up kubernetes client")
 }
@@ -105,6 +130,18 @@ func (ec *explodingClient) ClusterHealth(_ context.Context, _ bool) (ClusterHeal
 	return ClusterHealth{}, errors.Wrap(ec.err, "could not set up kubernetes client")
 }
 
+func (ec *explodingClient) APIVersions(_ context.Context) ([]string, error) {
+	return nil, errors.Wrap(ec.err, "could not set up kubernetes client")
+}
+
+func (ec *explodingClient) NodeArchitectures(_ context.Context) ([]string, error) {
+	return nil, errors.Wrap(ec.err, "could not set up kubernetes client")
+}
+
+func (ec *explodingClient) MutatingWebhooks(_ context.Context, _ schema.GroupVersionKind) ([]string, error) {
+	return nil, errors.Wrap(ec.err, "could not set up kubernetes client")
+}
+
 func (ec *explodingClient) APIConfig() *api.Config {
 	return &api.Config{}
 }
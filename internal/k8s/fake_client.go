@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 	"testing"
@@ -16,6 +17,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/version"
@@ -73,6 +75,13 @@ type FakeK8sClient struct {
 
 	EventsWatchErr error
 
+	// Errors to return when watching pods in a namespace,
+	// e.g., to simulate missing RBAC permissions.
+	PodWatchErrs map[Namespace]error
+
+	// Errors to return when listing pods in a namespace.
+	PodListErrs map[Namespace]error
+
 	UpsertError      error
 	UpsertResult     []K8sEntity
 	LastUpsertResult []K8sEntity
@@ -101,6 +110,13 @@ type FakeK8sClient struct {
 	ClusterHealthStatus *ClusterHealth
 	ClusterHealthError  error
 	FakeAPIConfig       *api.Config
+
+	MutatingWebhookNames map[schema.GroupVersionKind][]string
+
+	FakeAPIVersions       []string
+	FakeNodeArchitectures []string
+	NodeArchitecturesErr  error
+	FakeVersion           *version.Info
 }
 
 var _ Client = &FakeK8sClient{}
@@ -125,12 +141,22 @@ type fakePodWatch struct {
 	ch     chan ObjectUpdate
 }
 
+// An empty namespace watches all namespaces.
+func (w fakePodWatch) matches(ns string) bool {
+	return w.ns == "" || w.ns == Namespace(ns)
+}
+
 type fakeEventWatch struct {
 	cancel func()
 	ns     Namespace
 	ch     chan *v1.Event
 }
 
+// An empty namespace watches all namespaces.
+func (w fakeEventWatch) matches(ns string) bool {
+	return w.ns == "" || w.ns == Namespace(ns)
+}
+
 func (c *FakeK8sClient) UpsertService(s *v1.Service) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -153,7 +179,7 @@ func (c *FakeK8sClient) UpsertPod(pod *v1.Pod) {
 	pod = pod.DeepCopy()
 	c.pods[types.NamespacedName{Name: pod.Name, Namespace: pod.Namespace}] = pod
 	for _, w := range c.podWatches {
-		if w.ns != Namespace(pod.Namespace) {
+		if !w.matches(pod.Namespace) {
 			continue
 		}
 
@@ -168,7 +194,7 @@ func (c *FakeK8sClient) UpsertEvent(event *v1.Event) {
 	event = event.DeepCopy()
 	c.events[types.NamespacedName{Name: event.Name, Namespace: event.Namespace}] = event
 	for _, w := range c.eventWatches {
-		if w.ns != Namespace(event.Namespace) {
+		if !w.matches(event.Namespace) {
 			continue
 		}
 
@@ -190,6 +216,36 @@ func (c *FakeK8sClient) PodFromInformerCache(ctx context.Context, nn types.Names
 	return pod, nil
 }
 
+func (c *FakeK8sClient) PodsFromInformerCache(ctx context.Context, ns Namespace, selector labels.Selector) ([]*v1.Pod, error) {
+	if ns == "" {
+		return nil, fmt.Errorf("missing namespace from pod request")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var result []*v1.Pod
+	for _, pod := range c.pods {
+		if Namespace(pod.Namespace) == ns && selector.Matches(labels.Set(pod.Labels)) {
+			result = append(result, pod)
+		}
+	}
+	return result, nil
+}
+
+func (c *FakeK8sClient) ServiceFromInformerCache(ctx context.Context, nn types.NamespacedName) (*v1.Service, error) {
+	if nn.Namespace == "" {
+		return nil, fmt.Errorf("missing namespace from service request")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	svc, ok := c.services[nn]
+	if !ok {
+		return nil, apierrors.NewNotFound(ServiceGVR.GroupResource(), nn.Name)
+	}
+	return svc, nil
+}
+
 func (c *FakeK8sClient) WatchServices(ctx context.Context, ns Namespace) (<-chan *v1.Service, error) {
 	if ns == "" {
 		return nil, fmt.Errorf("missing namespace from watch request")
@@ -233,10 +289,6 @@ func (c *FakeK8sClient) WatchServices(ctx context.Context, ns Namespace) (<-chan
 }
 
 func (c *FakeK8sClient) WatchEvents(ctx context.Context, ns Namespace) (<-chan *v1.Event, error) {
-	if ns == "" {
-		return nil, fmt.Errorf("missing namespace from watch request")
-	}
-
 	if c.EventsWatchErr != nil {
 		err := c.EventsWatchErr
 		c.EventsWatchErr = nil
@@ -247,10 +299,11 @@ func (c *FakeK8sClient) WatchEvents(ctx context.Context, ns Namespace) (<-chan *
 
 	c.mu.Lock()
 	ch := make(chan *v1.Event, 20)
-	c.eventWatches = append(c.eventWatches, fakeEventWatch{cancel, ns, ch})
+	watch := fakeEventWatch{cancel, ns, ch}
+	c.eventWatches = append(c.eventWatches, watch)
 	toEmit := []*v1.Event{}
 	for _, event := range c.events {
-		if Namespace(event.Namespace) == ns {
+		if watch.matches(event.Namespace) {
 			toEmit = append(toEmit, event)
 		}
 	}
@@ -293,7 +346,7 @@ func (c *FakeK8sClient) EmitPodDelete(p *v1.Pod) {
 
 	delete(c.pods, types.NamespacedName{Name: p.Name, Namespace: p.Namespace})
 	for _, w := range c.podWatches {
-		if w.ns != Namespace(p.Namespace) {
+		if !w.matches(p.Namespace) {
 			continue
 		}
 
@@ -301,19 +354,40 @@ func (c *FakeK8sClient) EmitPodDelete(p *v1.Pod) {
 	}
 }
 
-func (c *FakeK8sClient) WatchPods(ctx context.Context, ns Namespace) (<-chan ObjectUpdate, error) {
-	if ns == "" {
-		return nil, fmt.Errorf("missing namespace from watch request")
+func (c *FakeK8sClient) ListPods(ctx context.Context, ns Namespace) ([]*v1.Pod, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.PodListErrs[ns]; err != nil {
+		return nil, err
 	}
 
+	result := []*v1.Pod{}
+	for _, pod := range c.pods {
+		if ns == "" || Namespace(pod.Namespace) == ns {
+			result = append(result, pod.DeepCopy())
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Namespace+"/"+result[i].Name < result[j].Namespace+"/"+result[j].Name
+	})
+	return result, nil
+}
+
+func (c *FakeK8sClient) WatchPods(ctx context.Context, ns Namespace) (<-chan ObjectUpdate, error) {
 	ctx, cancel := context.WithCancel(ctx)
 
 	c.mu.Lock()
+	if err := c.PodWatchErrs[ns]; err != nil {
+		c.mu.Unlock()
+		cancel()
+		return nil, err
+	}
 	ch := make(chan ObjectUpdate, 20)
-	c.podWatches = append(c.podWatches, fakePodWatch{cancel, ns, ch})
+	watch := fakePodWatch{cancel, ns, ch}
+	c.podWatches = append(c.podWatches, watch)
 	toEmit := []*v1.Pod{}
 	for _, pod := range c.pods {
-		if Namespace(pod.Namespace) == ns {
+		if watch.matches(pod.Namespace) {
 			toEmit = append(toEmit, pod)
 		}
 	}
@@ -344,8 +418,16 @@ func (c *FakeK8sClient) WatchPods(ctx context.Context, ns Namespace) (<-chan Obj
 }
 
 func NewFakeK8sClient(t testing.TB) *FakeK8sClient {
+	ctx, cancel := context.WithCancel(logger.WithLogger(context.Background(), logger.NewTestLogger(os.Stdout)))
+	t.Cleanup(cancel)
+	cli := newFakeK8sClient(ctx)
+	cli.t = t
+	t.Cleanup(cli.tearDown)
+	return cli
+}
+
+func newFakeK8sClient(ctx context.Context) *FakeK8sClient {
 	cli := &FakeK8sClient{
-		t:                        t,
 		PodLogsByPodAndContainer: make(map[PodAndCName]ReaderCloser),
 		pods:                     make(map[types.NamespacedName]*v1.Pod),
 		services:                 make(map[types.NamespacedName]*v1.Service),
@@ -365,9 +447,6 @@ func NewFakeK8sClient(t testing.TB) *FakeK8sClient {
 			},
 		},
 	}
-	ctx, cancel := context.WithCancel(logger.WithLogger(context.Background(), logger.NewTestLogger(os.Stdout)))
-	t.Cleanup(cancel)
-	t.Cleanup(cli.tearDown)
 	cli.ownerFetcher = NewOwnerFetcher(ctx, cli)
 	return cli
 }
@@ -427,6 +506,21 @@ func (c *FakeK8sClient) Upsert(_ context.Context, entities []K8sEntity, timeout
 	return result, nil
 }
 
+func (c *FakeK8sClient) DryRunUpsert(_ context.Context, entities []K8sEntity, timeout time.Duration) ([]K8sEntity, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.UpsertError != nil {
+		return nil, c.UpsertError
+	}
+
+	result := make([]K8sEntity, 0, len(entities))
+	for _, e := range entities {
+		result = append(result, e.DeepCopy())
+	}
+	return result, nil
+}
+
 func (c *FakeK8sClient) Delete(_ context.Context, entities []K8sEntity, wait bool) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -457,6 +551,12 @@ func (c *FakeK8sClient) Inject(entities ...K8sEntity) {
 		if entity.UID() == "" {
 			c.t.Fatalf("Entity with name[%s] at index[%d] had no UID", entity.Name(), i)
 		}
+	}
+	c.injectLocked(entities...)
+}
+
+func (c *FakeK8sClient) injectLocked(entities ...K8sEntity) {
+	for _, entity := range entities {
 		c.entities[entity.UID()] = entity
 		c.currentVersions[entity.Name()] = entity.UID()
 	}
@@ -468,6 +568,15 @@ func (c *FakeK8sClient) GetMetaByReference(ctx context.Context, ref v1.ObjectRef
 
 	c.getByReferenceCallCount++
 	resp, ok := c.entities[ref.UID]
+	if !ok && ref.UID == "" {
+		// Look up by name, like the real client.
+		for _, e := range c.entities {
+			if e.GVK().Kind == ref.Kind && e.Name() == ref.Name && e.Meta().GetNamespace() == ref.Namespace {
+				resp, ok = e, true
+				break
+			}
+		}
+	}
 	if !ok {
 		logger.Get(ctx).Infof("FakeK8sClient.GetMetaByReference: resource not found: %s", ref.Name)
 		return nil, apierrors.NewNotFound(v1.Resource(ref.Kind), ref.Name)
@@ -475,6 +584,58 @@ func (c *FakeK8sClient) GetMetaByReference(ctx context.Context, ref v1.ObjectRef
 	return resp.Meta(), nil
 }
 
+func (c *FakeK8sClient) GetByReference(ctx context.Context, ref v1.ObjectReference) (K8sEntity, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.getByReferenceCallCount++
+	resp, ok := c.entities[ref.UID]
+	if !ok && ref.UID == "" {
+		// Look up by name, like the real client.
+		for _, e := range c.entities {
+			if e.GVK().Kind == ref.Kind && e.Name() == ref.Name && e.Meta().GetNamespace() == ref.Namespace {
+				resp, ok = e, true
+				break
+			}
+		}
+	}
+	if !ok {
+		logger.Get(ctx).Infof("FakeK8sClient.GetByReference: resource not found: %s", ref.Name)
+		return K8sEntity{}, apierrors.NewNotFound(v1.Resource(ref.Kind), ref.Name)
+	}
+	return resp.DeepCopy(), nil
+}
+
+func (c *FakeK8sClient) PatchAnnotations(ctx context.Context, ref v1.ObjectReference, annotations map[string]*string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, uid := range c.currentVersions {
+		e := c.entities[uid]
+		if e.GVK().Kind != ref.Kind || e.Name() != ref.Name || e.Meta().GetNamespace() != ref.Namespace {
+			continue
+		}
+
+		e = e.DeepCopy()
+		meta := e.Meta()
+		newAnnotations := map[string]string{}
+		for k, v := range meta.GetAnnotations() {
+			newAnnotations[k] = v
+		}
+		for k, v := range annotations {
+			if v == nil {
+				delete(newAnnotations, k)
+			} else {
+				newAnnotations[k] = *v
+			}
+		}
+		meta.SetAnnotations(newAnnotations)
+		c.injectLocked(e)
+		return nil
+	}
+	return apierrors.NewNotFound(v1.Resource(ref.Kind), ref.Name)
+}
+
 func (c *FakeK8sClient) ListMeta(_ context.Context, gvk schema.GroupVersionKind, ns Namespace) ([]metav1.Object, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -538,6 +699,27 @@ func (c *FakeK8sClient) APIConfig() *api.Config {
 	return c.FakeAPIConfig
 }
 
+func (c *FakeK8sClient) APIVersions(_ context.Context) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.FakeAPIVersions...), nil
+}
+
+func (c *FakeK8sClient) NodeArchitectures(_ context.Context) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.NodeArchitecturesErr != nil {
+		return nil, c.NodeArchitecturesErr
+	}
+	return append([]string(nil), c.FakeNodeArchitectures...), nil
+}
+
+func (c *FakeK8sClient) MutatingWebhooks(_ context.Context, gvk schema.GroupVersionKind) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.MutatingWebhookNames[gvk], nil
+}
+
 func (c *FakeK8sClient) ClusterHealth(_ context.Context, _ bool) (ClusterHealth, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -670,6 +852,9 @@ func (c *FakeK8sClient) Exec(ctx context.Context, podID PodID, cName container.N
 }
 
 func (c *FakeK8sClient) CheckConnected(ctx context.Context) (*version.Info, error) {
+	if c.FakeVersion != nil {
+		return c.FakeVersion, nil
+	}
 	return &version.Info{}, nil
 }
 
@@ -0,0 +1,238 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+// AnnotationGitOpsSuspendedBy marks an object whose GitOps reconciliation
+// Tilt suspended. The value is the name of the resource that suspended it.
+const AnnotationGitOpsSuspendedBy = "tilt.dev/gitops-suspended-by"
+
+// AnnotationGitOpsSuspendedFrom records the value that the GitOps tool's
+// annotation had before Tilt suspended the object, if any, so that we
+// can restore it.
+const AnnotationGitOpsSuspendedFrom = "tilt.dev/gitops-suspended-from"
+
+// Argo CD tracks the objects of an Application with an annotation of the form
+// <app>:<group>/<kind>:<namespace>/<name>, where <app> is <namespace>_<name>
+// for Applications outside of Argo CD's own namespace.
+const argoTrackingIDAnnotation = "argocd.argoproj.io/tracking-id"
+
+// The namespace of Applications whose tracking IDs don't have one.
+const argoCDNamespace = "argocd"
+
+// Labels that Flux adds to the objects that it manages, and the annotation
+// that tells the controller to leave each object alone.
+var fluxSuspensions = []struct {
+	ownerLabel string
+	annotation string
+	value      string
+}{
+	{"kustomize.toolkit.fluxcd.io/name", "kustomize.toolkit.fluxcd.io/reconcile", "disabled"},
+	{"helm.toolkit.fluxcd.io/name", "helm.toolkit.fluxcd.io/driftDetection", "disabled"},
+}
+
+// How to stop a GitOps tool from reverting Tilt's changes to an object.
+type gitOpsSuspension struct {
+	// The object to annotate: either the object itself (for Flux), or
+	// the Application that manages it (for Argo CD).
+	target     v1.ObjectReference
+	annotation string
+	value      string
+}
+
+// Returns how to suspend the GitOps tool that manages the given live object,
+// or false if no GitOps tool manages it.
+func gitOpsSuspensionFor(obj K8sEntity) (gitOpsSuspension, bool) {
+	labels := obj.Labels()
+	for _, flux := range fluxSuspensions {
+		if labels[flux.ownerLabel] != "" {
+			return gitOpsSuspension{
+				target:     objectRefWithoutUID(obj),
+				annotation: flux.annotation,
+				value:      flux.value,
+			}, true
+		}
+	}
+
+	trackingID := obj.Annotations()[argoTrackingIDAnnotation]
+	if trackingID != "" {
+		app := strings.SplitN(trackingID, ":", 2)[0]
+		namespace := argoCDNamespace
+		if i := strings.Index(app, "_"); i != -1 {
+			namespace, app = app[:i], app[i+1:]
+		}
+		if app != "" {
+			return gitOpsSuspension{
+				target: v1.ObjectReference{
+					APIVersion: "argoproj.io/v1alpha1",
+					Kind:       "Application",
+					Namespace:  namespace,
+					Name:       app,
+				},
+				annotation: "argocd.argoproj.io/skip-reconcile",
+				value:      "true",
+			}, true
+		}
+	}
+	return gitOpsSuspension{}, false
+}
+
+func objectRefWithoutUID(e K8sEntity) v1.ObjectReference {
+	ref := e.ToObjectReference()
+	ref.UID = ""
+	return ref
+}
+
+// The namespace of the client's kubeconfig context, where objects
+// without a namespace go.
+func clientNamespace(kCli Client) Namespace {
+	config := kCli.APIConfig()
+	if config == nil {
+		return DefaultNamespace
+	}
+	kubeContext, ok := config.Contexts[config.CurrentContext]
+	if !ok || kubeContext.Namespace == "" {
+		return DefaultNamespace
+	}
+	return Namespace(kubeContext.Namespace)
+}
+
+// Looks up the live objects. Skips objects that don't exist.
+func liveObjects(ctx context.Context, kCli Client, entities []K8sEntity) ([]K8sEntity, error) {
+	defaultNamespace := clientNamespace(kCli)
+	result := make([]K8sEntity, 0, len(entities))
+	for _, e := range entities {
+		ref := objectRefWithoutUID(e)
+		if ref.Namespace == "" {
+			ref.Namespace = defaultNamespace.String()
+		}
+		live, err := kCli.GetByReference(ctx, ref)
+		if err != nil {
+			if apierrors.IsNotFound(err) || isMissingKindError(err) {
+				continue
+			}
+			return nil, fmt.Errorf("looking up %s %s: %v", ref.Kind, ref.Name, err)
+		}
+		result = append(result, live)
+	}
+	return result, nil
+}
+
+// SuspendGitOps hands the given objects over from the GitOps tools
+// that manage them (Flux or Argo CD) to Tilt, so that the tools don't
+// revert Tilt's changes.
+//
+// Annotates each object (or, for Argo CD, its Application) the way the
+// tool expects, and records the old annotation so that ResumeGitOps can
+// restore it. Leaves objects alone that are already suspended.
+func SuspendGitOps(ctx context.Context, kCli Client, entities []K8sEntity, owner string) error {
+	live, err := liveObjects(ctx, kCli, entities)
+	if err != nil {
+		return err
+	}
+
+	seen := map[v1.ObjectReference]bool{}
+	for _, obj := range live {
+		s, ok := gitOpsSuspensionFor(obj)
+		if !ok || seen[s.target] {
+			continue
+		}
+		seen[s.target] = true
+
+		target, err := kCli.GetByReference(ctx, s.target)
+		if err != nil {
+			if apierrors.IsNotFound(err) || isMissingKindError(err) {
+				continue
+			}
+			return fmt.Errorf("looking up %s %s: %v", s.target.Kind, s.target.Name, err)
+		}
+
+		annotations := target.Annotations()
+		if annotations[AnnotationGitOpsSuspendedBy] != "" || annotations[s.annotation] == s.value {
+			continue
+		}
+
+		patch := map[string]*string{
+			s.annotation:                &s.value,
+			AnnotationGitOpsSuspendedBy: &owner,
+		}
+		if old, ok := annotations[s.annotation]; ok {
+			patch[AnnotationGitOpsSuspendedFrom] = &old
+		}
+		err = kCli.PatchAnnotations(ctx, s.target, patch)
+		if err != nil {
+			return err
+		}
+		logger.Get(ctx).Infof("Suspended GitOps reconciliation of %s %s while Tilt manages it", s.target.Kind, s.target.Name)
+	}
+	return nil
+}
+
+// ResumeGitOps hands the given objects back to the GitOps tools that
+// manage them, restoring the annotations that SuspendGitOps changed.
+//
+// If owner is not empty, only resumes objects that owner suspended.
+//
+// Returns the objects that no GitOps tool manages. Tilt can delete those,
+// while the GitOps tools revert the others to their declared state.
+func ResumeGitOps(ctx context.Context, kCli Client, entities []K8sEntity, owner string) ([]K8sEntity, error) {
+	unmanaged := []K8sEntity{}
+	resumed := map[v1.ObjectReference]bool{}
+	for _, e := range entities {
+		live, err := liveObjects(ctx, kCli, []K8sEntity{e})
+		if err != nil {
+			return nil, err
+		}
+		if len(live) == 0 {
+			unmanaged = append(unmanaged, e)
+			continue
+		}
+
+		s, ok := gitOpsSuspensionFor(live[0])
+		if !ok {
+			unmanaged = append(unmanaged, e)
+			continue
+		}
+		if resumed[s.target] {
+			continue
+		}
+		resumed[s.target] = true
+
+		target, err := kCli.GetByReference(ctx, s.target)
+		if err != nil {
+			if apierrors.IsNotFound(err) || isMissingKindError(err) {
+				continue
+			}
+			return nil, fmt.Errorf("looking up %s %s: %v", s.target.Kind, s.target.Name, err)
+		}
+
+		annotations := target.Annotations()
+		suspendedBy, ok := annotations[AnnotationGitOpsSuspendedBy]
+		if !ok || (owner != "" && suspendedBy != owner) {
+			continue
+		}
+
+		patch := map[string]*string{
+			s.annotation:                  nil,
+			AnnotationGitOpsSuspendedBy:   nil,
+			AnnotationGitOpsSuspendedFrom: nil,
+		}
+		if old, ok := annotations[AnnotationGitOpsSuspendedFrom]; ok {
+			patch[s.annotation] = &old
+		}
+		err = kCli.PatchAnnotations(ctx, s.target, patch)
+		if err != nil {
+			return nil, err
+		}
+		logger.Get(ctx).Infof("Resumed GitOps reconciliation of %s %s", s.target.Kind, s.target.Name)
+	}
+	return unmanaged, nil
+}
@@ -0,0 +1,121 @@
+package k8s
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+func gitOpsObject(kind, namespace, name string, labels, annotations map[string]string) K8sEntity {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion("v1")
+	if kind == "Application" {
+		u.SetAPIVersion("argoproj.io/v1alpha1")
+	}
+	u.SetKind(kind)
+	u.SetNamespace(namespace)
+	u.SetName(name)
+	u.SetUID(types.UID(kind + "-" + name))
+	u.SetLabels(labels)
+	u.SetAnnotations(annotations)
+	return NewK8sEntity(u)
+}
+
+func liveAnnotations(t *testing.T, kCli *FakeK8sClient, kind, namespace, name string) map[string]string {
+	obj := gitOpsObject(kind, namespace, name, nil, nil)
+	live, err := kCli.GetByReference(context.Background(), v1.ObjectReference{
+		APIVersion: obj.ToObjectReference().APIVersion,
+		Kind:       kind,
+		Namespace:  namespace,
+		Name:       name,
+	})
+	require.NoError(t, err)
+	return live.Annotations()
+}
+
+func TestGitOpsFluxHandoff(t *testing.T) {
+	kCli := NewFakeK8sClient(t)
+	ctx := logger.WithLogger(context.Background(), logger.NewTestLogger(os.Stdout))
+	obj := gitOpsObject("ConfigMap", "default", "web",
+		map[string]string{"kustomize.toolkit.fluxcd.io/name": "apps"},
+		map[string]string{"kustomize.toolkit.fluxcd.io/reconcile": "enabled"})
+	plain := gitOpsObject("ConfigMap", "default", "plain", nil, nil)
+	kCli.Inject(obj, plain)
+
+	err := SuspendGitOps(ctx, kCli, []K8sEntity{obj, plain}, "web")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"kustomize.toolkit.fluxcd.io/reconcile": "disabled",
+		AnnotationGitOpsSuspendedBy:             "web",
+		AnnotationGitOpsSuspendedFrom:           "enabled",
+	}, liveAnnotations(t, kCli, "ConfigMap", "default", "web"))
+	assert.Empty(t, liveAnnotations(t, kCli, "ConfigMap", "default", "plain"))
+
+	// Another resource can't resume it.
+	unmanaged, err := ResumeGitOps(ctx, kCli, []K8sEntity{obj, plain}, "other")
+	require.NoError(t, err)
+	assert.Equal(t, []K8sEntity{plain}, unmanaged)
+	assert.Equal(t, "disabled", liveAnnotations(t, kCli, "ConfigMap", "default", "web")["kustomize.toolkit.fluxcd.io/reconcile"])
+
+	unmanaged, err = ResumeGitOps(ctx, kCli, []K8sEntity{obj, plain}, "web")
+	require.NoError(t, err)
+	assert.Equal(t, []K8sEntity{plain}, unmanaged)
+	assert.Equal(t, map[string]string{
+		"kustomize.toolkit.fluxcd.io/reconcile": "enabled",
+	}, liveAnnotations(t, kCli, "ConfigMap", "default", "web"))
+}
+
+func TestGitOpsArgoHandoff(t *testing.T) {
+	kCli := NewFakeK8sClient(t)
+	ctx := logger.WithLogger(context.Background(), logger.NewTestLogger(os.Stdout))
+	tracking := map[string]string{argoTrackingIDAnnotation: "team_shop:/ConfigMap:default/web"}
+	obj1 := gitOpsObject("ConfigMap", "default", "web", nil, tracking)
+	obj2 := gitOpsObject("Service", "default", "web", nil, map[string]string{
+		argoTrackingIDAnnotation: "team_shop:/Service:default/web",
+	})
+	app := gitOpsObject("Application", "team", "shop", nil, nil)
+	kCli.Inject(obj1, obj2, app)
+
+	err := SuspendGitOps(ctx, kCli, []K8sEntity{obj1, obj2}, "web")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"argocd.argoproj.io/skip-reconcile": "true",
+		AnnotationGitOpsSuspendedBy:         "web",
+	}, liveAnnotations(t, kCli, "Application", "team", "shop"))
+
+	// The objects themselves are untouched.
+	assert.Equal(t, tracking, liveAnnotations(t, kCli, "ConfigMap", "default", "web"))
+
+	unmanaged, err := ResumeGitOps(ctx, kCli, []K8sEntity{obj1, obj2}, "")
+	require.NoError(t, err)
+	assert.Empty(t, unmanaged)
+	assert.Empty(t, liveAnnotations(t, kCli, "Application", "team", "shop"))
+}
+
+func TestGitOpsDoesNotTakeOverUserSuspension(t *testing.T) {
+	kCli := NewFakeK8sClient(t)
+	ctx := logger.WithLogger(context.Background(), logger.NewTestLogger(os.Stdout))
+	obj := gitOpsObject("ConfigMap", "default", "web",
+		map[string]string{"helm.toolkit.fluxcd.io/name": "web"},
+		map[string]string{"helm.toolkit.fluxcd.io/driftDetection": "disabled"})
+	kCli.Inject(obj)
+
+	err := SuspendGitOps(ctx, kCli, []K8sEntity{obj}, "web")
+	require.NoError(t, err)
+
+	// We didn't suspend it, so we don't resume it.
+	unmanaged, err := ResumeGitOps(ctx, kCli, []K8sEntity{obj}, "web")
+	require.NoError(t, err)
+	assert.Empty(t, unmanaged)
+	assert.Equal(t, map[string]string{
+		"helm.toolkit.fluxcd.io/driftDetection": "disabled",
+	}, liveAnnotations(t, kCli, "ConfigMap", "default", "web"))
+}
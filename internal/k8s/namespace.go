@@ -0,0 +1,7 @@
+package k8s
+
+// Returns true if the entity is a Namespace.
+func IsNamespace(e K8sEntity) bool {
+	gvk := e.GVK()
+	return gvk.Group == "" && gvk.Kind == "Namespace"
+}
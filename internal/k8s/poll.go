@@ -0,0 +1,104 @@
+package k8s
+
+import (
+	"context"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// How often to list pods when we can't watch them.
+//
+// Polling starts at the minimum interval, and backs off to the maximum
+// while nothing changes, so that idle resources don't hammer the apiserver.
+// Any change resets it to the minimum, so that pods that are starting up
+// (e.g., after a deploy or a live update) get fresh status quickly.
+type PodPollIntervals struct {
+	Min time.Duration
+	Max time.Duration
+}
+
+var DefaultPodPollIntervals = PodPollIntervals{
+	Min: 2 * time.Second,
+	Max: 30 * time.Second,
+}
+
+type PodLister interface {
+	ListPods(ctx context.Context, ns Namespace) ([]*v1.Pod, error)
+}
+
+// Lists pods periodically, and emits updates for the pods that were added,
+// changed, or deleted since the last list, like WatchPods does.
+//
+// The channel is closed when the context is done.
+func PollPods(ctx context.Context, lister PodLister, ns Namespace, intervals PodPollIntervals) <-chan ObjectUpdate {
+	ch := make(chan ObjectUpdate)
+	go func() {
+		defer close(ch)
+
+		send := func(update ObjectUpdate) bool {
+			select {
+			case ch <- update:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		seen := make(map[types.UID]*v1.Pod)
+		interval := intervals.Min
+		for {
+			pods, err := lister.ListPods(ctx, ns)
+			if err == nil {
+				changed := false
+				current := make(map[types.UID]*v1.Pod, len(pods))
+				for _, pod := range pods {
+					current[pod.UID] = pod
+					old, ok := seen[pod.UID]
+					if ok && old.ResourceVersion == pod.ResourceVersion {
+						continue
+					}
+					changed = true
+					if !send(ObjectUpdate{obj: FixContainerStatusImagesNoMutation(pod)}) {
+						return
+					}
+				}
+				for uid, pod := range seen {
+					if _, ok := current[uid]; ok {
+						continue
+					}
+					changed = true
+					if !send(ObjectUpdate{obj: pod, isDelete: true}) {
+						return
+					}
+				}
+				seen = current
+
+				if changed {
+					interval = intervals.Min
+				} else {
+					interval = nextPodPollInterval(interval, intervals)
+				}
+			} else {
+				// Don't hammer an apiserver that's failing.
+				interval = nextPodPollInterval(interval, intervals)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(interval):
+			}
+		}
+	}()
+	return ch
+}
+
+func nextPodPollInterval(current time.Duration, intervals PodPollIntervals) time.Duration {
+	next := current * 2
+	if next > intervals.Max {
+		return intervals.Max
+	}
+	return next
+}
@@ -0,0 +1,110 @@
+package k8s
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+)
+
+type fakePodLister struct {
+	mu    sync.Mutex
+	pods  []*v1.Pod
+	err   error
+	calls []time.Time
+}
+
+func (l *fakePodLister) ListPods(ctx context.Context, ns Namespace) ([]*v1.Pod, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.calls = append(l.calls, time.Now())
+	return append([]*v1.Pod{}, l.pods...), l.err
+}
+
+func (l *fakePodLister) setPods(pods ...*v1.Pod) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.pods = pods
+}
+
+func (l *fakePodLister) callCount() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.calls)
+}
+
+func TestPollPods(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pod1 := fakePod("pod1", "image1")
+	pod1.UID = "uid1"
+	pod2 := fakePod("pod2", "image2")
+	pod2.UID = "uid2"
+	lister := &fakePodLister{pods: []*v1.Pod{pod1, pod2}}
+
+	ch := PollPods(ctx, lister, "default", PodPollIntervals{Min: time.Millisecond, Max: 5 * time.Millisecond})
+	assertNextPod(t, ch, "pod1")
+	assertNextPod(t, ch, "pod2")
+
+	// Only changed pods are sent again.
+	pod2Updated := pod2.DeepCopy()
+	pod2Updated.ResourceVersion = "updated"
+	lister.setPods(pod1, pod2Updated)
+	assertNextPod(t, ch, "pod2")
+
+	lister.setPods(pod2Updated)
+	update := nextUpdate(t, ch)
+	_, name, ok := update.AsDeletedKey()
+	require.True(t, ok)
+	assert.Equal(t, "pod1", name)
+
+	cancel()
+	require.Eventually(t, func() bool {
+		_, ok := <-ch
+		return !ok
+	}, time.Second, time.Millisecond)
+}
+
+func TestPollPodsBacksOff(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	lister := &fakePodLister{}
+	_ = PollPods(ctx, lister, "default", PodPollIntervals{Min: time.Millisecond, Max: 50 * time.Millisecond})
+
+	// 1ms, 2ms, 4ms, ..., then every 50ms.
+	time.Sleep(200 * time.Millisecond)
+	count := lister.callCount()
+	assert.Greater(t, count, 3)
+	assert.Less(t, count, 20)
+}
+
+func TestNextPodPollInterval(t *testing.T) {
+	intervals := PodPollIntervals{Min: 2 * time.Second, Max: 30 * time.Second}
+	assert.Equal(t, 4*time.Second, nextPodPollInterval(2*time.Second, intervals))
+	assert.Equal(t, 30*time.Second, nextPodPollInterval(16*time.Second, intervals))
+	assert.Equal(t, 30*time.Second, nextPodPollInterval(30*time.Second, intervals))
+}
+
+func nextUpdate(t *testing.T, ch <-chan ObjectUpdate) ObjectUpdate {
+	t.Helper()
+	select {
+	case update := <-ch:
+		return update
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for pod update")
+	}
+	return ObjectUpdate{}
+}
+
+func assertNextPod(t *testing.T, ch <-chan ObjectUpdate, name string) {
+	t.Helper()
+	pod, ok := nextUpdate(t, ch).AsPod()
+	require.True(t, ok)
+	assert.Equal(t, name, pod.Name)
+}
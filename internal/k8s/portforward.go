@@ -39,17 +39,14 @@ type PortForwarder interface {
 	ReadyCh() <-chan struct{}
 
 	// Listens on the configured port and forward all traffic to the container.
-	// Returns when the port-forwarder sees an unrecoverable error or
-	// when the context passed at creation is canceled.
-	ForwardPorts() error
-
-	// TODO(nick): If the port forwarder has any problems connecting to the pod,
-	// it just logs those as debug logs. I'm not sure that logs are the right API
-	// for this -- there are lots of cases (e.g., where you're deliberately
-	// restarting the pod) where it's ok if it drops the connection.
+	// Returns when the port-forwarder sees an unrecoverable error (including
+	// a lost connection to the pod), or returns nil when the context passed
+	// at creation is canceled.
 	//
-	// I suspect what we actually need is a healthcheck/status field for the
-	// portforwarder that's exposed as part of the engine.
+	// Problems with individual connections are only logged as debug logs.
+	// The PortForward reconciler reports the health of the forward in
+	// its status.
+	ForwardPorts() error
 }
 
 type portForwarder struct {
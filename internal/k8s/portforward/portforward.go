@@ -234,7 +234,10 @@ func (pf *PortForwarder) forward() error {
 
 	case <-pf.ctx.Done():
 	case <-pf.streamConn.CloseChan():
-		logger.Get(pf.ctx).Debugf("lost connection to pod")
+		if pf.ctx.Err() == nil {
+			// Return an error, so that the caller knows to reconnect.
+			return fmt.Errorf("lost connection to pod")
+		}
 	}
 
 	return nil
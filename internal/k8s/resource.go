@@ -17,6 +17,7 @@ import (
 // We've adapted Helm's kubernetes client for our needs
 type ResourceClient interface {
 	Apply(target kube.ResourceList) (*kube.Result, error)
+	DryRunApply(target kube.ResourceList) (*kube.Result, error)
 	CreateOrReplace(target kube.ResourceList) (*kube.Result, error)
 	Delete(existing kube.ResourceList) (*kube.Result, []error)
 	Create(l kube.ResourceList) (*kube.Result, error)
@@ -31,6 +32,18 @@ type resourceClient struct {
 // Helm's update function doesn't really work for us,
 // so we use the kubectl apply code directly.
 func (c *resourceClient) Apply(target kube.ResourceList) (*kube.Result, error) {
+	return c.apply(target, cmdutil.DryRunNone)
+}
+
+// Runs the same apply on the server without persisting it, like
+// `kubectl apply --dry-run=server`.
+//
+// The objects in the target are updated with what the server would store.
+func (c *resourceClient) DryRunApply(target kube.ResourceList) (*kube.Result, error) {
+	return c.apply(target, cmdutil.DryRunServer)
+}
+
+func (c *resourceClient) apply(target kube.ResourceList, dryRun cmdutil.DryRunStrategy) (*kube.Result, error) {
 	f := c.factory
 	iostreams := genericclioptions.IOStreams{
 		In:     strings.NewReader(""),
@@ -87,6 +100,8 @@ func (c *resourceClient) Apply(target kube.ResourceList) (*kube.Result, error) {
 		Mapper:           mapper,
 		DynamicClient:    dynamicClient,
 		OpenAPISchema:    openAPISchema,
+		DryRunStrategy:   dryRun,
+		DryRunVerifier:   resource.NewDryRunVerifier(dynamicClient, f.OpenAPIGetter()),
 
 		IOStreams: flags.IOStreams,
 
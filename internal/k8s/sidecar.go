@@ -0,0 +1,14 @@
+package k8s
+
+// The names of the containers that service meshes inject into pods.
+//
+// These proxies aren't part of the app, so Tilt doesn't block readiness
+// on them by default, and never picks them for live update by image.
+var serviceMeshSidecarNames = map[string]bool{
+	"istio-proxy":   true,
+	"linkerd-proxy": true,
+}
+
+func IsServiceMeshSidecar(containerName string) bool {
+	return serviceMeshSidecarNames[containerName]
+}
@@ -0,0 +1,206 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// The kubeconfig context of the simulated cluster.
+const SimulatedKubeContext = KubeContext("tilt-fake-cluster")
+
+// SimulatedClient is an in-memory cluster, for running Tilt end-to-end
+// without a real cluster (e.g., `tilt ci --fake-cluster`).
+//
+// Applied objects are stored in memory. Every workload immediately gets a
+// single pod that's running and ready (or, for Jobs, that has succeeded),
+// so that Tilt sees the resource come up. Re-applying a workload replaces
+// its pod, like a rollout would.
+//
+// The cluster pretends to be a local dev cluster that can see images on the
+// local container runtime, so images are never pushed.
+type SimulatedClient struct {
+	*FakeK8sClient
+}
+
+var _ Client = &SimulatedClient{}
+
+func NewSimulatedClient(ctx context.Context) *SimulatedClient {
+	cli := newFakeK8sClient(ctx)
+	cli.FakeAPIConfig = &api.Config{
+		CurrentContext: string(SimulatedKubeContext),
+		Contexts: map[string]*api.Context{
+			string(SimulatedKubeContext): &api.Context{
+				// Cluster names determine the product, so make sure
+				// this is treated as a local dev cluster.
+				Cluster:   "docker-desktop",
+				Namespace: string(DefaultNamespace),
+			},
+		},
+		Clusters: map[string]*api.Cluster{
+			"docker-desktop": &api.Cluster{Server: "https://fake-cluster.tilt.dev"},
+		},
+	}
+
+	go func() {
+		<-ctx.Done()
+		cli.tearDown()
+	}()
+	return &SimulatedClient{FakeK8sClient: cli}
+}
+
+func (c *SimulatedClient) Upsert(ctx context.Context, entities []K8sEntity, timeout time.Duration) ([]K8sEntity, error) {
+	namespaced := make([]K8sEntity, 0, len(entities))
+	for _, e := range entities {
+		if e.Meta().GetNamespace() == "" {
+			e = e.WithNamespace(string(DefaultNamespace))
+		}
+		namespaced = append(namespaced, e)
+	}
+
+	result, err := c.FakeK8sClient.Upsert(ctx, namespaced, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.injectLocked(result...)
+	c.mu.Unlock()
+
+	for _, e := range result {
+		pod, err := simulatedPod(e)
+		if err != nil {
+			return nil, err
+		}
+		if pod != nil {
+			c.deleteOwnedPods(e)
+			c.UpsertPod(pod)
+		}
+	}
+	return result, nil
+}
+
+// Delete the pods that earlier applies of the workload created.
+func (c *SimulatedClient) deleteOwnedPods(e K8sEntity) {
+	kind := e.GVK().Kind
+	c.mu.Lock()
+	var owned []*v1.Pod
+	for _, pod := range c.pods {
+		if pod.Namespace != e.Meta().GetNamespace() {
+			continue
+		}
+		owner := metav1.GetControllerOf(pod)
+		if owner != nil && owner.Kind == kind && owner.Name == e.Name() {
+			owned = append(owned, pod)
+		}
+	}
+	c.mu.Unlock()
+
+	for _, pod := range owned {
+		c.EmitPodDelete(pod)
+	}
+}
+
+// Create the pod that the cluster would run for a workload.
+//
+// Returns nil if the object doesn't run a pod.
+func simulatedPod(e K8sEntity) (*v1.Pod, error) {
+	now := metav1.Now()
+	if pod, ok := e.Obj.(*v1.Pod); ok {
+		pod = pod.DeepCopy()
+		pod.CreationTimestamp = now
+		pod.Status = simulatedPodStatus(pod.Spec, v1.PodRunning, now)
+		return pod, nil
+	}
+
+	if IsCronJob(e) {
+		// CronJobs don't run until they're scheduled.
+		return nil, nil
+	}
+
+	templates, err := ExtractPodTemplateSpec(e.Obj)
+	if err != nil {
+		return nil, err
+	}
+	if len(templates) == 0 {
+		return nil, nil
+	}
+
+	phase := v1.PodRunning
+	if e.GVK().Kind == "Job" {
+		phase = v1.PodSucceeded
+	}
+
+	template := templates[0]
+	isController := true
+	apiVersion, kind := e.GVK().ToAPIVersionAndKind()
+	uid := types.UID(uuid.New().String())
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              fmt.Sprintf("%s-%s", e.Name(), uid[:8]),
+			Namespace:         e.Meta().GetNamespace(),
+			UID:               uid,
+			Labels:            template.Labels,
+			Annotations:       template.Annotations,
+			CreationTimestamp: now,
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: apiVersion,
+					Kind:       kind,
+					Name:       e.Name(),
+					UID:        e.UID(),
+					Controller: &isController,
+				},
+			},
+		},
+		Spec:   *template.Spec.DeepCopy(),
+		Status: simulatedPodStatus(template.Spec, phase, now),
+	}, nil
+}
+
+func simulatedPodStatus(spec v1.PodSpec, phase v1.PodPhase, now metav1.Time) v1.PodStatus {
+	isRunning := phase == v1.PodRunning
+	ready := v1.ConditionFalse
+	if isRunning {
+		ready = v1.ConditionTrue
+	}
+
+	status := v1.PodStatus{
+		Phase:     phase,
+		StartTime: &now,
+		Conditions: []v1.PodCondition{
+			{Type: v1.PodScheduled, Status: v1.ConditionTrue, LastTransitionTime: now},
+			{Type: v1.PodInitialized, Status: v1.ConditionTrue, LastTransitionTime: now},
+			{Type: v1.ContainersReady, Status: ready, LastTransitionTime: now},
+			{Type: v1.PodReady, Status: ready, LastTransitionTime: now},
+		},
+	}
+	for _, c := range spec.Containers {
+		cs := v1.ContainerStatus{
+			Name:    c.Name,
+			Image:   c.Image,
+			ImageID: fmt.Sprintf("docker-pullable://%s", c.Image),
+			Ready:   isRunning,
+		}
+		if isRunning {
+			started := true
+			cs.Started = &started
+			cs.State.Running = &v1.ContainerStateRunning{StartedAt: now}
+		} else {
+			cs.State.Terminated = &v1.ContainerStateTerminated{
+				ExitCode:   0,
+				Reason:     "Completed",
+				StartedAt:  now,
+				FinishedAt: now,
+			}
+		}
+		status.ContainerStatuses = append(status.ContainerStatuses, cs)
+	}
+	return status
+}
@@ -0,0 +1,116 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/tilt-dev/tilt/internal/k8s/testyaml"
+)
+
+func TestSimulatedClientDeploymentRunsPod(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cli := NewSimulatedClient(ctx)
+	ch, err := cli.WatchPods(ctx, DefaultNamespace)
+	require.NoError(t, err)
+
+	entities, err := ParseYAMLFromString(testyaml.SanchoYAML)
+	require.NoError(t, err)
+	result, err := cli.Upsert(ctx, entities, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+
+	pod := nextSimulatedPod(t, ch)
+	assert.Equal(t, v1.PodRunning, pod.Status.Phase)
+	assert.Equal(t, "default", pod.Namespace)
+	require.Len(t, pod.OwnerReferences, 1)
+	assert.Equal(t, result[0].UID(), pod.OwnerReferences[0].UID)
+	require.Len(t, pod.Status.ContainerStatuses, 1)
+	assert.True(t, pod.Status.ContainerStatuses[0].Ready)
+
+	// The applied object can be fetched back, so that Tilt can
+	// follow the ownership chain from the pod.
+	deployment, err := cli.GetByReference(ctx, result[0].ToObjectReference())
+	require.NoError(t, err)
+	assert.Equal(t, "sancho", deployment.Name())
+}
+
+func TestSimulatedClientReplacesPodOnUpsert(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cli := NewSimulatedClient(ctx)
+	ch, err := cli.WatchPods(ctx, DefaultNamespace)
+	require.NoError(t, err)
+
+	entities, err := ParseYAMLFromString(testyaml.SanchoYAML)
+	require.NoError(t, err)
+	_, err = cli.Upsert(ctx, entities, time.Minute)
+	require.NoError(t, err)
+	first := nextSimulatedPod(t, ch)
+
+	_, err = cli.Upsert(ctx, entities, time.Minute)
+	require.NoError(t, err)
+
+	select {
+	case update := <-ch:
+		_, name, ok := update.AsDeletedKey()
+		require.True(t, ok)
+		assert.Equal(t, first.Name, name)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for pod delete")
+	}
+	second := nextSimulatedPod(t, ch)
+	assert.NotEqual(t, first.Name, second.Name)
+
+	pods, err := cli.ListPods(ctx, DefaultNamespace)
+	require.NoError(t, err)
+	require.Len(t, pods, 1)
+	assert.Equal(t, second.Name, pods[0].Name)
+}
+
+func TestSimulatedClientJobSucceeds(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cli := NewSimulatedClient(ctx)
+	ch, err := cli.WatchPods(ctx, DefaultNamespace)
+	require.NoError(t, err)
+
+	entities, err := ParseYAMLFromString(testyaml.JobYAML)
+	require.NoError(t, err)
+	_, err = cli.Upsert(ctx, entities, time.Minute)
+	require.NoError(t, err)
+
+	pod := nextSimulatedPod(t, ch)
+	assert.Equal(t, v1.PodSucceeded, pod.Status.Phase)
+	require.Len(t, pod.Status.ContainerStatuses, 1)
+	assert.Equal(t, "Completed", pod.Status.ContainerStatuses[0].State.Terminated.Reason)
+}
+
+func TestSimulatedClientIsLocalCluster(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cli := NewSimulatedClient(ctx)
+	assert.Equal(t, string(SimulatedKubeContext), cli.APIConfig().CurrentContext)
+	assert.True(t, ClusterProductFromAPIConfig(cli.APIConfig()).IsDevCluster())
+}
+
+func nextSimulatedPod(t *testing.T, ch <-chan ObjectUpdate) *v1.Pod {
+	select {
+	case update := <-ch:
+		pod, ok := update.AsPod()
+		require.True(t, ok)
+		return pod
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for pod")
+		return nil
+	}
+}
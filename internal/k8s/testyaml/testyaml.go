@@ -513,6 +513,79 @@ spec:
   backoffLimit: 4
 `
 
+const CronJobYAML = `
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: hello
+  namespace: default
+spec:
+  schedule: "*/5 * * * *"
+  jobTemplate:
+    metadata:
+      labels:
+        app: hello
+    spec:
+      template:
+        spec:
+          containers:
+          - name: hello
+            image: busybox
+            command: ["echo", "hello"]
+          restartPolicy: OnFailure
+`
+
+// A database with a volumeClaimTemplate, and a standalone claim.
+const DatabaseWithVolumesYAML = `
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: uploads
+  namespace: default
+spec:
+  accessModes: ["ReadWriteOnce"]
+  resources:
+    requests:
+      storage: 1Gi
+---
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: db
+  namespace: default
+spec:
+  replicas: 2
+  serviceName: db
+  selector:
+    matchLabels:
+      app: db
+  template:
+    metadata:
+      labels:
+        app: db
+    spec:
+      containers:
+      - name: postgres
+        image: postgres
+        volumeMounts:
+        - name: pgdata
+          mountPath: /var/lib/postgresql/data
+        - name: uploads
+          mountPath: /uploads
+      volumes:
+      - name: uploads
+        persistentVolumeClaim:
+          claimName: uploads
+  volumeClaimTemplates:
+  - metadata:
+      name: pgdata
+    spec:
+      accessModes: ["ReadWriteOnce"]
+      resources:
+        requests:
+          storage: 1Gi
+`
+
 const PodYAML = `apiVersion: v1
 kind: Pod
 metadata:
@@ -0,0 +1,94 @@
+package k8s
+
+import (
+	"fmt"
+	"regexp"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var PersistentVolumeClaimGVK = v1.SchemeGroupVersion.WithKind("PersistentVolumeClaim")
+
+// Returns a copy of the workload with its replicas set to zero,
+// or false if the entity isn't a workload that we know how to scale.
+func ScaleToZero(entity K8sEntity) (K8sEntity, bool) {
+	entity = entity.DeepCopy()
+	zero := int32(0)
+	switch obj := entity.Obj.(type) {
+	case *appsv1.Deployment:
+		obj.Spec.Replicas = &zero
+	case *appsv1.StatefulSet:
+		obj.Spec.Replicas = &zero
+	case *appsv1.ReplicaSet:
+		obj.Spec.Replicas = &zero
+	default:
+		return K8sEntity{}, false
+	}
+	return entity, true
+}
+
+// Finds the PersistentVolumeClaims that a volume reset should delete.
+//
+// Each allowed name matches either:
+//  1. A PersistentVolumeClaim in the YAML with that name, or
+//  2. A volumeClaimTemplate of a StatefulSet in the YAML. The StatefulSet
+//     controller creates these claims, so we match them against the existing
+//     claims in the cluster, which are named <template>-<statefulset>-<ordinal>.
+//
+// Returns the claims to delete, and the allowed names that didn't match anything.
+func VolumeClaimsToReset(entities []K8sEntity, allowed []string, existing []metav1.Object) ([]K8sEntity, []string) {
+	isAllowed := make(map[string]bool, len(allowed))
+	for _, name := range allowed {
+		isAllowed[name] = true
+	}
+
+	var result []K8sEntity
+	matched := make(map[string]bool, len(allowed))
+	for _, e := range entities {
+		switch obj := e.Obj.(type) {
+		case *v1.PersistentVolumeClaim:
+			if isAllowed[obj.Name] {
+				matched[obj.Name] = true
+				result = append(result, e)
+			}
+		case *appsv1.StatefulSet:
+			for _, tmpl := range obj.Spec.VolumeClaimTemplates {
+				if !isAllowed[tmpl.Name] {
+					continue
+				}
+				matched[tmpl.Name] = true
+
+				re := regexp.MustCompile(fmt.Sprintf("^%s-%s-[0-9]+$",
+					regexp.QuoteMeta(tmpl.Name), regexp.QuoteMeta(obj.Name)))
+				for _, m := range existing {
+					if m.GetNamespace() == e.Namespace().String() && re.MatchString(m.GetName()) {
+						result = append(result, newVolumeClaimEntity(m.GetName(), m.GetNamespace()))
+					}
+				}
+			}
+		}
+	}
+
+	var unmatched []string
+	for _, name := range allowed {
+		if !matched[name] {
+			unmatched = append(unmatched, name)
+		}
+	}
+	return result, unmatched
+}
+
+func newVolumeClaimEntity(name, namespace string) K8sEntity {
+	return NewK8sEntity(&v1.PersistentVolumeClaim{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: PersistentVolumeClaimGVK.GroupVersion().String(),
+			Kind:       PersistentVolumeClaimGVK.Kind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+	})
+}
@@ -0,0 +1,56 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/tilt-dev/tilt/internal/k8s/testyaml"
+)
+
+func TestScaleToZero(t *testing.T) {
+	entities := mustParseYAML(t, testyaml.DatabaseWithVolumesYAML)
+
+	_, ok := ScaleToZero(entities[0])
+	assert.False(t, ok, "claims can't be scaled")
+
+	scaled, ok := ScaleToZero(entities[1])
+	require.True(t, ok)
+	assert.Equal(t, int32(0), *scaled.Obj.(*appsv1.StatefulSet).Spec.Replicas)
+	assert.Equal(t, int32(2), *entities[1].Obj.(*appsv1.StatefulSet).Spec.Replicas)
+}
+
+func TestVolumeClaimsToReset(t *testing.T) {
+	entities := mustParseYAML(t, testyaml.DatabaseWithVolumesYAML)
+	existing := []metav1.Object{
+		&metav1.ObjectMeta{Name: "pgdata-db-0", Namespace: "default"},
+		&metav1.ObjectMeta{Name: "pgdata-db-1", Namespace: "default"},
+		&metav1.ObjectMeta{Name: "pgdata-db-backup-0", Namespace: "default"},
+		&metav1.ObjectMeta{Name: "pgdata-db-0", Namespace: "other"},
+	}
+
+	claims, unmatched := VolumeClaimsToReset(entities, []string{"pgdata", "uploads", "cache"}, existing)
+	var names []string
+	for _, c := range claims {
+		assert.Equal(t, PersistentVolumeClaimGVK, c.GVK())
+		assert.Equal(t, "default", c.Namespace().String())
+		names = append(names, c.Name())
+	}
+	assert.Equal(t, []string{"uploads", "pgdata-db-0", "pgdata-db-1"}, names)
+	assert.Equal(t, []string{"cache"}, unmatched)
+}
+
+func TestVolumeClaimsToResetOnlyAllowed(t *testing.T) {
+	entities := mustParseYAML(t, testyaml.DatabaseWithVolumesYAML)
+	existing := []metav1.Object{
+		&metav1.ObjectMeta{Name: "pgdata-db-0", Namespace: "default"},
+	}
+
+	claims, unmatched := VolumeClaimsToReset(entities, []string{"uploads"}, existing)
+	require.Len(t, claims, 1)
+	assert.Equal(t, "uploads", claims[0].Name())
+	assert.Empty(t, unmatched)
+}
@@ -13,6 +13,7 @@ import (
 	apiErrors "k8s.io/apimachinery/pkg/api/errors"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
@@ -28,7 +29,8 @@ import (
 )
 
 type InformerSet interface {
-	// For all watchers, a namespace must be specified.
+	// For all watchers, a namespace must be specified, except for WatchPods
+	// and WatchEvents, where an empty namespace watches all namespaces.
 	WatchPods(ctx context.Context, ns Namespace) (<-chan ObjectUpdate, error)
 
 	WatchServices(ctx context.Context, ns Namespace) (<-chan *v1.Service, error)
@@ -41,6 +43,21 @@ type InformerSet interface {
 	//
 	// The pod should be treated as immutable (since it's a pointer to a shared cache reference).
 	PodFromInformerCache(ctx context.Context, nn types.NamespacedName) (*v1.Pod, error)
+
+	// List the pods that match a label selector from the informer cache.
+	//
+	// If no informer has started, start one now on the given ctx,
+	// and wait for it to sync.
+	//
+	// The pods should be treated as immutable (since they're pointers to shared cache references).
+	PodsFromInformerCache(ctx context.Context, ns Namespace, selector labels.Selector) ([]*v1.Pod, error)
+
+	// Fetch a service from the informer cache.
+	//
+	// If no informer has started, start one now on the given ctx.
+	//
+	// The service should be treated as immutable (since it's a pointer to a shared cache reference).
+	ServiceFromInformerCache(ctx context.Context, nn types.NamespacedName) (*v1.Service, error)
 }
 
 type informerSet struct {
@@ -125,24 +142,39 @@ func (r ObjectUpdate) AsDeletedKey() (Namespace, string, bool) {
 	return Namespace(ns), name, true
 }
 
+// A StatusError with a more readable message.
+//
+// It unwraps to the original error, so that callers can still check
+// its reason (e.g., with apierrors.IsForbidden).
+type unpackedStatusError struct {
+	*apiErrors.StatusError
+}
+
+func (e unpackedStatusError) Error() string {
+	status := e.ErrStatus
+	return fmt.Sprintf("%s, Reason: %s, Code: %d", status.Message, status.Reason, status.Code)
+}
+
+func (e unpackedStatusError) Unwrap() error {
+	return e.StatusError
+}
+
 func maybeUnpackStatusError(err error) error {
 	statusErr, isStatusErr := err.(*apiErrors.StatusError)
 	if !isStatusErr {
 		return err
 	}
-	status := statusErr.ErrStatus
-	return fmt.Errorf("%s, Reason: %s, Code: %d", status.Message, status.Reason, status.Code)
+	return unpackedStatusError{statusErr}
 }
 
 // Make a new informer, and start it.
+//
+// An empty namespace watches all namespaces. Callers should check
+// that this is what they meant.
 func (s *informerSet) makeInformer(
 	ctx context.Context,
 	ns Namespace,
 	gvr schema.GroupVersionResource) (cache.SharedInformer, error) {
-	if ns == "" {
-		return nil, fmt.Errorf("missing namespace from watch request")
-	}
-
 	key := fmt.Sprintf("%s/%s", ns, gvr)
 	result, err, _ := s.singleflight.Do(key, func() (interface{}, error) {
 		s.mu.Lock()
@@ -240,6 +272,10 @@ func (s *informerSet) WatchEvents(ctx context.Context, ns Namespace) (<-chan *v1
 //
 // The pod should be treated as immutable (since it's a pointer to a shared cache reference).
 func (s *informerSet) PodFromInformerCache(ctx context.Context, nn types.NamespacedName) (*v1.Pod, error) {
+	if nn.Namespace == "" {
+		return nil, fmt.Errorf("missing namespace from pod lookup")
+	}
+
 	gvr := PodGVR
 	informer, err := s.makeInformer(ctx, Namespace(nn.Namespace), gvr)
 	if err != nil {
@@ -257,6 +293,54 @@ func (s *informerSet) PodFromInformerCache(ctx context.Context, nn types.Namespa
 	return pod.(*v1.Pod), nil
 }
 
+func (s *informerSet) PodsFromInformerCache(ctx context.Context, ns Namespace, selector labels.Selector) ([]*v1.Pod, error) {
+	if ns == "" {
+		return nil, fmt.Errorf("missing namespace from pod lookup")
+	}
+
+	informer, err := s.makeInformer(ctx, ns, PodGVR)
+	if err != nil {
+		return nil, errors.Wrap(err, "PodsFromInformer")
+	}
+	if !cache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+		return nil, fmt.Errorf("PodsFromInformer: timed out waiting for pods to sync")
+	}
+
+	var result []*v1.Pod
+	for _, obj := range informer.GetStore().List() {
+		pod, ok := obj.(*v1.Pod)
+		if ok && selector.Matches(labels.Set(pod.Labels)) {
+			result = append(result, pod)
+		}
+	}
+	return result, nil
+}
+
+func (s *informerSet) ServiceFromInformerCache(ctx context.Context, nn types.NamespacedName) (*v1.Service, error) {
+	if nn.Namespace == "" {
+		return nil, fmt.Errorf("missing namespace from service lookup")
+	}
+
+	gvr := ServiceGVR
+	informer, err := s.makeInformer(ctx, Namespace(nn.Namespace), gvr)
+	if err != nil {
+		return nil, errors.Wrap(err, "ServiceFromInformer")
+	}
+	if !cache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+		return nil, fmt.Errorf("ServiceFromInformer: timed out waiting for services to sync")
+	}
+	svc, exists, err := informer.GetStore().Get(&v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: nn.Name, Namespace: nn.Namespace},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, apierrors.NewNotFound(gvr.GroupResource(), nn.Name)
+	}
+	return svc.(*v1.Service), nil
+}
+
 func (s *informerSet) WatchPods(ctx context.Context, ns Namespace) (<-chan ObjectUpdate, error) {
 	gvr := PodGVR
 	informer, err := s.makeInformer(ctx, ns, gvr)
@@ -300,6 +384,10 @@ func (s *informerSet) WatchPods(ctx context.Context, ns Namespace) (<-chan Objec
 }
 
 func (s *informerSet) WatchServices(ctx context.Context, ns Namespace) (<-chan *v1.Service, error) {
+	if ns == "" {
+		return nil, fmt.Errorf("missing namespace from watch request")
+	}
+
 	gvr := ServiceGVR
 	informer, err := s.makeInformer(ctx, ns, gvr)
 	if err != nil {
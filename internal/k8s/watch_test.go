@@ -167,6 +167,7 @@ func TestK8sClient_WatchPodsError(t *testing.T) {
 	_, err := tf.kCli.WatchPods(tf.ctx, "default")
 	if assert.Error(t, err) {
 		assert.Contains(t, err.Error(), "Forbidden")
+		assert.True(t, apierrors.IsForbidden(err))
 	}
 }
 
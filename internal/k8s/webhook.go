@@ -0,0 +1,160 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Returns the names of the mutating admission webhooks that intercept
+// creates or updates of the given kind.
+//
+// Objects don't record which webhook changed them, so this is a best guess.
+// We don't evaluate namespace or object selectors.
+func (k *K8sClient) MutatingWebhooks(ctx context.Context, gvk schema.GroupVersionKind) ([]string, error) {
+	rm, err := k.drm.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	configs, err := k.clientset.AdmissionregistrationV1().MutatingWebhookConfigurations().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return mutatingWebhooksFor(configs.Items, rm.Resource), nil
+}
+
+func mutatingWebhooksFor(configs []admissionregistrationv1.MutatingWebhookConfiguration, gvr schema.GroupVersionResource) []string {
+	var result []string
+	for _, config := range configs {
+		for _, webhook := range config.Webhooks {
+			for _, rule := range webhook.Rules {
+				if webhookRuleMatches(rule, gvr) {
+					result = append(result, webhook.Name)
+					break
+				}
+			}
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+func webhookRuleMatches(rule admissionregistrationv1.RuleWithOperations, gvr schema.GroupVersionResource) bool {
+	matchesOp := false
+	for _, op := range rule.Operations {
+		if op == admissionregistrationv1.OperationAll ||
+			op == admissionregistrationv1.Create ||
+			op == admissionregistrationv1.Update {
+			matchesOp = true
+			break
+		}
+	}
+	return matchesOp &&
+		webhookRuleContains(rule.APIGroups, gvr.Group) &&
+		webhookRuleContains(rule.APIVersions, gvr.Version) &&
+		webhookRuleContains(rule.Resources, gvr.Resource)
+}
+
+func webhookRuleContains(values []string, v string) bool {
+	for _, value := range values {
+		// Subresource rules (like "pods/status") never match the main resource,
+		// except for the catch-all "*/*".
+		if value == "*" || value == "*/*" || value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Compares an object Tilt sent to the apiserver with the object that the
+// apiserver returned, and describes the fields that an admission webhook added.
+//
+// We only look for changes that webhooks typically make: added labels,
+// annotations, containers, and volumes. Fields that the apiserver
+// fills in with defaults aren't reported.
+func WebhookMutatedFields(sent, applied K8sEntity) []string {
+	var result []string
+	result = append(result, addedKeys("metadata.labels", sent.Labels(), applied.Labels())...)
+	result = append(result, addedKeys("metadata.annotations", sent.Annotations(), applied.Annotations())...)
+
+	sentPods, err := ExtractPods(sent.Obj)
+	if err != nil {
+		return result
+	}
+	appliedPods, err := ExtractPods(applied.Obj)
+	if err != nil || len(sentPods) != len(appliedPods) {
+		return result
+	}
+
+	for i := range sentPods {
+		result = append(result, addedNames("containers",
+			containerNames(sentPods[i].Containers), containerNames(appliedPods[i].Containers))...)
+		result = append(result, addedNames("initContainers",
+			containerNames(sentPods[i].InitContainers), containerNames(appliedPods[i].InitContainers))...)
+		result = append(result, addedNames("volumes",
+			volumeNames(sentPods[i].Volumes), volumeNames(appliedPods[i].Volumes))...)
+	}
+	return result
+}
+
+func addedKeys(field string, sent, applied map[string]string) []string {
+	var result []string
+	for key := range applied {
+		_, ok := sent[key]
+		if ok || isBookkeepingKey(key) {
+			continue
+		}
+		result = append(result, fmt.Sprintf("%s[%s]", field, key))
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Kubernetes itself sets labels and annotations on some objects
+// (like the last applied configuration). These aren't webhook mutations.
+func isBookkeepingKey(key string) bool {
+	prefix, _, ok := strings.Cut(key, "/")
+	if !ok {
+		return false
+	}
+	return prefix == "kubernetes.io" || strings.HasSuffix(prefix, ".kubernetes.io") ||
+		prefix == "k8s.io" || strings.HasSuffix(prefix, ".k8s.io")
+}
+
+func addedNames(field string, sent, applied []string) []string {
+	sentSet := make(map[string]bool, len(sent))
+	for _, name := range sent {
+		sentSet[name] = true
+	}
+
+	var result []string
+	for _, name := range applied {
+		if !sentSet[name] {
+			result = append(result, fmt.Sprintf("%s[%s]", field, name))
+		}
+	}
+	return result
+}
+
+func containerNames(containers []v1.Container) []string {
+	result := make([]string, 0, len(containers))
+	for _, c := range containers {
+		result = append(result, c.Name)
+	}
+	return result
+}
+
+func volumeNames(volumes []v1.Volume) []string {
+	result := make([]string, 0, len(volumes))
+	for _, v := range volumes {
+		result = append(result, v.Name)
+	}
+	return result
+}
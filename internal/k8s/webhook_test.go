@@ -0,0 +1,87 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/tilt-dev/tilt/internal/k8s/testyaml"
+)
+
+func TestWebhookMutatedFields(t *testing.T) {
+	sent := mustParseYAML(t, testyaml.SanchoYAML)[0]
+	applied := sent.DeepCopy()
+
+	dep := applied.Obj.(*appsv1.Deployment)
+	dep.Annotations = map[string]string{
+		"deployment.kubernetes.io/revision": "1",
+		"sidecar.istio.io/status":           "{}",
+	}
+	dep.Spec.Template.Spec.InitContainers = append(dep.Spec.Template.Spec.InitContainers,
+		v1.Container{Name: "istio-init"})
+	dep.Spec.Template.Spec.Containers = append(dep.Spec.Template.Spec.Containers,
+		v1.Container{Name: "istio-proxy"})
+	dep.Spec.Template.Spec.Volumes = append(dep.Spec.Template.Spec.Volumes,
+		v1.Volume{Name: "istio-envoy"})
+
+	// Defaulted fields aren't webhook mutations.
+	dep.Spec.Template.Spec.Containers[0].TerminationMessagePath = "/dev/termination-log"
+
+	assert.Equal(t, []string{
+		"metadata.annotations[sidecar.istio.io/status]",
+		"containers[istio-proxy]",
+		"initContainers[istio-init]",
+		"volumes[istio-envoy]",
+	}, WebhookMutatedFields(sent, applied))
+}
+
+func TestWebhookMutatedFieldsUnchanged(t *testing.T) {
+	sent := mustParseYAML(t, testyaml.SanchoYAML)[0]
+	assert.Empty(t, WebhookMutatedFields(sent, sent.DeepCopy()))
+}
+
+func TestMutatingWebhooksFor(t *testing.T) {
+	rule := func(groups, resources []string, ops ...admissionregistrationv1.OperationType) admissionregistrationv1.RuleWithOperations {
+		return admissionregistrationv1.RuleWithOperations{
+			Operations: ops,
+			Rule: admissionregistrationv1.Rule{
+				APIGroups:   groups,
+				APIVersions: []string{"*"},
+				Resources:   resources,
+			},
+		}
+	}
+	configs := []admissionregistrationv1.MutatingWebhookConfiguration{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "istio"},
+			Webhooks: []admissionregistrationv1.MutatingWebhook{
+				{Name: "sidecar-injector.istio.io", Rules: []admissionregistrationv1.RuleWithOperations{
+					rule([]string{""}, []string{"pods"}, admissionregistrationv1.Create),
+				}},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "policy"},
+			Webhooks: []admissionregistrationv1.MutatingWebhook{
+				{Name: "defaults.policy.io", Rules: []admissionregistrationv1.RuleWithOperations{
+					rule([]string{"*"}, []string{"*"}, admissionregistrationv1.OperationAll),
+				}},
+				{Name: "status.policy.io", Rules: []admissionregistrationv1.RuleWithOperations{
+					rule([]string{"apps"}, []string{"deployments/status"}, admissionregistrationv1.Update),
+				}},
+				{Name: "cleanup.policy.io", Rules: []admissionregistrationv1.RuleWithOperations{
+					rule([]string{"apps"}, []string{"deployments"}, admissionregistrationv1.Delete),
+				}},
+			},
+		},
+	}
+
+	assert.Equal(t, []string{"defaults.policy.io"},
+		mutatingWebhooksFor(configs, appsv1.SchemeGroupVersion.WithResource("deployments")))
+	assert.Equal(t, []string{"defaults.policy.io", "sidecar-injector.istio.io"},
+		mutatingWebhooksFor(configs, v1.SchemeGroupVersion.WithResource("pods")))
+}
@@ -0,0 +1,96 @@
+package k8s
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AnnotationApplyWeight orders the objects of one apply, like Helm's hook weights.
+//
+// Objects with a lower weight are applied first. Each weight is applied
+// as its own stage, after the stages with lower weights have been applied.
+// Objects without the annotation have weight 0.
+const AnnotationApplyWeight = "tilt.dev/apply-weight"
+
+// AnnotationDeleteWeight orders the deletion of objects (e.g., on `tilt down`).
+//
+// Objects with a lower weight are deleted first, and Tilt waits for them to be
+// gone before deleting objects with higher weights. Give objects that other
+// objects' finalizers depend on (e.g., an operator) a high weight so that
+// they're deleted last. Objects without the annotation have weight 0.
+const AnnotationDeleteWeight = "tilt.dev/delete-weight"
+
+// The weight of an object in the given annotation.
+func weight(e K8sEntity, annotation string) (int, error) {
+	value, ok := e.Annotations()[annotation]
+	if !ok {
+		return 0, nil
+	}
+	w, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("%s: annotation %s must be an integer, got %q",
+			e.Name(), annotation, value)
+	}
+	return w, nil
+}
+
+// Groups the entities by weight, lowest weight first.
+//
+// Keeps the order of the entities within each group.
+func groupByWeight(entities []K8sEntity, weights []int) [][]K8sEntity {
+	groups := make(map[int][]K8sEntity)
+	var keys []int
+	for i, e := range entities {
+		w := weights[i]
+		if _, ok := groups[w]; !ok {
+			keys = append(keys, w)
+		}
+		groups[w] = append(groups[w], e)
+	}
+	sort.Ints(keys)
+
+	result := make([][]K8sEntity, 0, len(keys))
+	for _, w := range keys {
+		result = append(result, groups[w])
+	}
+	return result
+}
+
+// ApplyWeightStages splits the entities into stages by their apply weight,
+// lowest weight first.
+//
+// Within each stage, entities are sorted by kind, so that (e.g.) Namespaces
+// and CRDs are created before the objects that use them.
+func ApplyWeightStages(entities []K8sEntity) ([][]K8sEntity, error) {
+	weights := make([]int, len(entities))
+	for i, e := range entities {
+		w, err := weight(e, AnnotationApplyWeight)
+		if err != nil {
+			return nil, err
+		}
+		weights[i] = w
+	}
+
+	stages := groupByWeight(entities, weights)
+	for i, stage := range stages {
+		stages[i] = SortedEntities(stage)
+	}
+	return stages, nil
+}
+
+// DeleteWeightStages splits the entities into stages by their delete weight,
+// lowest weight first.
+//
+// Keeps the order of the entities within each stage, so callers should sort
+// them in delete order first (see ReverseSortedEntities).
+//
+// Deletes are best-effort, so an invalid weight counts as 0.
+func DeleteWeightStages(entities []K8sEntity) [][]K8sEntity {
+	weights := make([]int, len(entities))
+	for i, e := range entities {
+		weights[i], _ = weight(e, AnnotationDeleteWeight)
+	}
+	return groupByWeight(entities, weights)
+}
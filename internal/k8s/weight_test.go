@@ -0,0 +1,69 @@
+package k8s
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func weightedConfigMap(t *testing.T, name string, annotation string, weight string) K8sEntity {
+	yaml := fmt.Sprintf(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: %s
+`, name)
+	if weight != "" {
+		yaml += fmt.Sprintf("  annotations:\n    %s: %q\n", annotation, weight)
+	}
+	return parseOneEntity(t, yaml)
+}
+
+func stageNames(stages [][]K8sEntity) [][]string {
+	result := [][]string{}
+	for _, stage := range stages {
+		names := []string{}
+		for _, e := range stage {
+			names = append(names, e.Name())
+		}
+		result = append(result, names)
+	}
+	return result
+}
+
+func TestApplyWeightStages(t *testing.T) {
+	entities := []K8sEntity{
+		weightedConfigMap(t, "late", AnnotationApplyWeight, "10"),
+		weightedConfigMap(t, "default", AnnotationApplyWeight, ""),
+		weightedConfigMap(t, "early", AnnotationApplyWeight, "-5"),
+		weightedConfigMap(t, "zero", AnnotationApplyWeight, "0"),
+		parseOneEntity(t, `apiVersion: v1
+kind: Namespace
+metadata:
+  name: ns
+`),
+	}
+
+	stages, err := ApplyWeightStages(entities)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"early"}, {"ns", "default", "zero"}, {"late"}}, stageNames(stages))
+}
+
+func TestApplyWeightStagesInvalid(t *testing.T) {
+	_, err := ApplyWeightStages([]K8sEntity{weightedConfigMap(t, "cm", AnnotationApplyWeight, "first")})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `annotation tilt.dev/apply-weight must be an integer, got "first"`)
+}
+
+func TestDeleteWeightStages(t *testing.T) {
+	entities := []K8sEntity{
+		weightedConfigMap(t, "operator", AnnotationDeleteWeight, "100"),
+		weightedConfigMap(t, "b", AnnotationDeleteWeight, ""),
+		weightedConfigMap(t, "invalid", AnnotationDeleteWeight, "last"),
+		weightedConfigMap(t, "a", AnnotationDeleteWeight, ""),
+	}
+
+	stages := DeleteWeightStages(entities)
+	assert.Equal(t, [][]string{{"b", "invalid", "a"}, {"operator"}}, stageNames(stages))
+}
@@ -0,0 +1,256 @@
+// Package msgcat is a catalog of the user-facing status messages that Tilt
+// reports in API objects (e.g., why a resource is pending or failing).
+//
+// Each message has a stable code, so that the web UI and other clients can
+// match on the code instead of the text, and translate the text if they like.
+// Message text is a template with {name} placeholders for arguments.
+package msgcat
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+type Code string
+
+// Reasons for the Ready and UpToDate conditions on a UIResource.
+const (
+	ResourceDisabled       Code = "ResourceDisabled"
+	ResourceUpdateError    Code = "ResourceUpdateError"
+	ResourceRuntimeError   Code = "ResourceRuntimeError"
+	ResourceUpdatePending  Code = "ResourceUpdatePending"
+	ResourceRuntimePending Code = "ResourceRuntimePending"
+	ResourceUnknown        Code = "ResourceUnknown"
+)
+
+// Reasons that a UIResource is waiting to update.
+const (
+	WaitingForTiltfileReload Code = "WaitingForTiltfileReload"
+	WaitingForLocal          Code = "WaitingForLocal"
+	WaitingForParallelLocal  Code = "WaitingForParallelLocal"
+	WaitingForUncategorized  Code = "WaitingForUncategorized"
+	WaitingForComponent      Code = "WaitingForComponent"
+	WaitingForDependency     Code = "WaitingForDependency"
+	WaitingForDeploy         Code = "WaitingForDeploy"
+	WaitingForCluster        Code = "WaitingForCluster"
+)
+
+// Arguments that messages may use.
+const (
+	// A comma-separated list of the objects that a resource is waiting on.
+	ArgOn = "on"
+
+	// An error message.
+	ArgError = "error"
+)
+
+const DefaultLocale = "en"
+
+type Entry struct {
+	Code Code `json:"code"`
+
+	// The message template, e.g., "Waiting on {on}".
+	Text string `json:"text"`
+
+	// Optional advice on how to resolve the problem.
+	Hint string `json:"hint,omitempty"`
+}
+
+// A set of messages for a single locale.
+type Catalog map[Code]Entry
+
+var english = newCatalog(
+	Entry{
+		Code: ResourceDisabled,
+		Text: "Resource is disabled",
+		Hint: "Enable it from the web UI, or with `tilt enable`.",
+	},
+	Entry{
+		Code: ResourceUpdateError,
+		Text: "Update failed: {error}",
+		Hint: "Check the resource's logs for details. Tilt will try again when a file changes, or you can trigger an update.",
+	},
+	Entry{
+		Code: ResourceRuntimeError,
+		Text: "Runtime error",
+		Hint: "The resource updated, but isn't running correctly. Check the resource's logs for details.",
+	},
+	Entry{
+		Code: ResourceUpdatePending,
+		Text: "Waiting to update",
+	},
+	Entry{
+		Code: ResourceRuntimePending,
+		Text: "Updated, waiting for the runtime to become ready",
+	},
+	Entry{
+		Code: ResourceUnknown,
+		Text: "Status unknown",
+	},
+	Entry{
+		Code: WaitingForTiltfileReload,
+		Text: "Waiting for the Tiltfile to reload",
+	},
+	Entry{
+		Code: WaitingForLocal,
+		Text: "Waiting on {on}, which can't update in parallel with other resources",
+		Hint: "Set allow_parallel=True on local_resource() to let it update in parallel.",
+	},
+	Entry{
+		Code: WaitingForParallelLocal,
+		Text: "Waiting for other updates to finish, because this resource can't update in parallel",
+		Hint: "Set allow_parallel=True on local_resource() to let it update in parallel.",
+	},
+	Entry{
+		Code: WaitingForUncategorized,
+		Text: "Waiting for uncategorized objects to deploy",
+		Hint: "Uncategorized objects (e.g., CRDs and namespaces) deploy first, so that other resources can use them.",
+	},
+	Entry{
+		Code: WaitingForComponent,
+		Text: "Waiting on {on} to build",
+	},
+	Entry{
+		Code: WaitingForDependency,
+		Text: "Waiting on {on}",
+		Hint: "This resource declares resource_deps on {on}, which must become ready first.",
+	},
+	Entry{
+		Code: WaitingForDeploy,
+		Text: "Waiting on {on} to deploy",
+	},
+	Entry{
+		Code: WaitingForCluster,
+		Text: "Waiting for cluster connection: {on}",
+		Hint: "Check that your cluster is running, and that your kubeconfig points at it.",
+	},
+)
+
+var mu sync.RWMutex
+var catalogs = map[string]Catalog{DefaultLocale: english}
+
+func newCatalog(entries ...Entry) Catalog {
+	c := make(Catalog, len(entries))
+	for _, e := range entries {
+		c[e.Code] = e
+	}
+	return c
+}
+
+// Registers translations for a locale (e.g., "fr").
+//
+// Translations don't need to cover every code. Any missing codes fall back
+// to the default locale.
+func Register(locale string, entries ...Entry) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	c, ok := catalogs[locale]
+	if !ok {
+		c = make(Catalog)
+		catalogs[locale] = c
+	}
+	for _, e := range entries {
+		c[e.Code] = e
+	}
+}
+
+// Looks up the entry for a code, falling back to the default locale.
+func Lookup(locale string, code Code) (Entry, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if e, ok := catalogFor(locale)[code]; ok {
+		return e, true
+	}
+	e, ok := catalogs[DefaultLocale][code]
+	return e, ok
+}
+
+// Returns all the entries for a locale, sorted by code, with missing
+// translations filled in from the default locale.
+func Entries(locale string) []Entry {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	translations := catalogFor(locale)
+	result := make([]Entry, 0, len(catalogs[DefaultLocale]))
+	for code, e := range catalogs[DefaultLocale] {
+		if translated, ok := translations[code]; ok {
+			e = translated
+		}
+		result = append(result, e)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Code < result[j].Code
+	})
+	return result
+}
+
+// Finds the catalog for a locale, falling back from a regional
+// locale to its language (e.g., "fr-CH" -> "fr").
+//
+// Must be called with the lock held.
+func catalogFor(locale string) Catalog {
+	if c, ok := catalogs[locale]; ok {
+		return c
+	}
+	if i := strings.IndexAny(locale, "-_"); i != -1 {
+		return catalogs[locale[:i]]
+	}
+	return nil
+}
+
+// Renders the message for a code in the given locale.
+//
+// Unknown codes render as the code itself. Missing arguments are dropped,
+// along with any ": " separator before them, so that "Update failed: {error}"
+// renders as "Update failed" if there's no error.
+func Format(locale string, code Code, args map[string]string) string {
+	e, ok := Lookup(locale, code)
+	if !ok {
+		return string(code)
+	}
+	return render(e.Text, args)
+}
+
+// Renders the hint for a code in the given locale, or "" if it has none.
+func FormatHint(locale string, code Code, args map[string]string) string {
+	e, ok := Lookup(locale, code)
+	if !ok {
+		return ""
+	}
+	return render(e.Hint, args)
+}
+
+// Renders the message for a code in the default locale.
+func Message(code Code, args map[string]string) string {
+	return Format(DefaultLocale, code, args)
+}
+
+func render(text string, args map[string]string) string {
+	var b strings.Builder
+	for {
+		start := strings.Index(text, "{")
+		if start == -1 {
+			break
+		}
+		end := strings.Index(text[start:], "}")
+		if end == -1 {
+			break
+		}
+		end += start
+
+		prefix := text[:start]
+		value := args[text[start+1:end]]
+		if value == "" {
+			prefix = strings.TrimSuffix(prefix, ": ")
+		}
+		b.WriteString(prefix)
+		b.WriteString(value)
+		text = text[end+1:]
+	}
+	b.WriteString(text)
+	return b.String()
+}
@@ -0,0 +1,48 @@
+package msgcat
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessage(t *testing.T) {
+	assert.Equal(t, "Waiting on frontend, backend",
+		Message(WaitingForDependency, map[string]string{ArgOn: "frontend, backend"}))
+	assert.Equal(t, "Update failed: exit status 1",
+		Message(ResourceUpdateError, map[string]string{ArgError: "exit status 1"}))
+}
+
+func TestMessageMissingArg(t *testing.T) {
+	assert.Equal(t, "Update failed", Message(ResourceUpdateError, nil))
+}
+
+func TestMessageUnknownCode(t *testing.T) {
+	assert.Equal(t, "NotACode", Message(Code("NotACode"), nil))
+}
+
+func TestFormatHint(t *testing.T) {
+	assert.Equal(t, "This resource declares resource_deps on db, which must become ready first.",
+		FormatHint(DefaultLocale, WaitingForDependency, map[string]string{ArgOn: "db"}))
+	assert.Equal(t, "", FormatHint(DefaultLocale, ResourceUnknown, nil))
+}
+
+func TestTranslation(t *testing.T) {
+	Register("xx", Entry{Code: WaitingForDependency, Text: "En attente de {on}"})
+
+	args := map[string]string{ArgOn: "db"}
+	assert.Equal(t, "En attente de db", Format("xx", WaitingForDependency, args))
+	assert.Equal(t, "En attente de db", Format("xx-YY", WaitingForDependency, args))
+
+	// Falls back to the default locale.
+	assert.Equal(t, "Resource is disabled", Format("xx", ResourceDisabled, nil))
+	assert.Equal(t, "Waiting on db", Format("zz", WaitingForDependency, args))
+
+	entries := Entries("xx")
+	assert.Len(t, entries, len(english))
+	for _, e := range entries {
+		if e.Code == WaitingForDependency {
+			assert.Equal(t, "En attente de {on}", e.Text)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package snapshots
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// How long the URL we upload to stays valid.
+const uploadURLExpiry = 15 * time.Minute
+
+type credentials struct {
+	accessKeyID     string
+	secretAccessKey string
+	sessionToken    string
+}
+
+// Uploads snapshots to an S3-compatible bucket.
+//
+// GCS supports the same API with HMAC keys, so we use this for both.
+//
+// Rather than pull in a cloud SDK, we sign requests with AWS Signature Version 4
+// query parameters (a "presigned URL"). That way, the upload and the
+// shareable download link use the same code.
+type bucketStorage struct {
+	endpoint   *url.URL
+	pathStyle  bool
+	bucket     string
+	prefix     string
+	region     string
+	creds      credentials
+	linkExpiry time.Duration
+
+	client *http.Client
+	now    func() time.Time
+}
+
+var _ Storage = &bucketStorage{}
+
+func (s *bucketStorage) Upload(ctx context.Context, name string, snapshot []byte) (string, error) {
+	key := path.Join(s.prefix, name)
+	now := s.currentTime()
+
+	uploadURL := s.presign(http.MethodPut, key, now, uploadURLExpiry)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, uploadURL, bytes.NewReader(snapshot))
+	if err != nil {
+		return "", fmt.Errorf("uploading snapshot: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := s.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("uploading snapshot: %v", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return "", fmt.Errorf("uploading snapshot to bucket %s: %s: %s",
+			s.bucket, resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	return s.presign(http.MethodGet, key, now, s.linkExpiry), nil
+}
+
+func (s *bucketStorage) currentTime() time.Time {
+	if s.now != nil {
+		return s.now()
+	}
+	return time.Now()
+}
+
+// The host and path of an object.
+func (s *bucketStorage) objectLocation(key string) (host string, p string) {
+	basePath := strings.TrimSuffix(s.endpoint.Path, "/")
+	if s.pathStyle {
+		return s.endpoint.Host, basePath + "/" + s.bucket + "/" + key
+	}
+	return s.bucket + "." + s.endpoint.Host, basePath + "/" + key
+}
+
+// Creates a URL that lets anyone perform the given method on the object
+// until it expires.
+//
+// See:
+// https://docs.aws.amazon.com/AmazonS3/latest/API/sigv4-query-string-auth.html
+func (s *bucketStorage) presign(method, key string, t time.Time, expiry time.Duration) string {
+	t = t.UTC()
+	amzDate := t.Format("20060102T150405Z")
+	date := t.Format("20060102")
+	scope := strings.Join([]string{date, s.region, "s3", "aws4_request"}, "/")
+
+	host, p := s.objectLocation(key)
+
+	query := map[string]string{
+		"X-Amz-Algorithm":     "AWS4-HMAC-SHA256",
+		"X-Amz-Credential":    s.creds.accessKeyID + "/" + scope,
+		"X-Amz-Date":          amzDate,
+		"X-Amz-Expires":       strconv.Itoa(int(expiry.Seconds())),
+		"X-Amz-SignedHeaders": "host",
+	}
+	if s.creds.sessionToken != "" {
+		query["X-Amz-Security-Token"] = s.creds.sessionToken
+	}
+	canonicalQuery := canonicalQueryString(query)
+
+	canonicalRequest := strings.Join([]string{
+		method,
+		uriEncode(p, false),
+		canonicalQuery,
+		"host:" + host + "\n",
+		"host",
+		"UNSIGNED-PAYLOAD",
+	}, "\n")
+
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hex.EncodeToString(requestHash[:]),
+	}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+s.creds.secretAccessKey), date)
+	signingKey = hmacSHA256(signingKey, s.region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	return fmt.Sprintf("%s://%s%s?%s&X-Amz-Signature=%s",
+		s.endpoint.Scheme, host, uriEncode(p, false), canonicalQuery, signature)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	_, _ = h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func canonicalQueryString(query map[string]string) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, uriEncode(k, true)+"="+uriEncode(query[k], true))
+	}
+	return strings.Join(parts, "&")
+}
+
+// URI-encodes every byte except the unreserved characters, as SigV4 requires.
+// Slashes are only encoded in query parameters.
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,198 @@
+package snapshots
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+
+	proto_webview "github.com/tilt-dev/tilt/pkg/webview"
+)
+
+// The file format of an exported snapshot.
+type Format string
+
+const (
+	// The snapshot as JSON, viewable with `tilt snapshot view`.
+	FormatJSON Format = "json"
+
+	// A single HTML page that shows the resources and logs without
+	// a network connection. The JSON snapshot is embedded in the page,
+	// so it can also be opened with `tilt snapshot view`.
+	FormatHTML Format = "html"
+)
+
+func ParseFormat(s string) (Format, error) {
+	switch Format(strings.ToLower(s)) {
+	case FormatJSON:
+		return FormatJSON, nil
+	case FormatHTML:
+		return FormatHTML, nil
+	}
+	return "", fmt.Errorf("unknown snapshot format %q: must be one of json, html", s)
+}
+
+// Guesses the format from a file extension, defaulting to JSON.
+func FormatFromPath(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".html", ".htm":
+		return FormatHTML
+	}
+	return FormatJSON
+}
+
+func (f Format) Ext() string {
+	return "." + string(f)
+}
+
+func (f Format) ContentType() string {
+	if f == FormatHTML {
+		return "text/html; charset=utf-8"
+	}
+	return "application/json"
+}
+
+// Renders a complete snapshot in the given format.
+func Render(snapshot *proto_webview.Snapshot, format Format) ([]byte, error) {
+	var buf bytes.Buffer
+	m := jsonpb.Marshaler{Indent: "  "}
+	err := m.Marshal(&buf, snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("rendering snapshot: %v", err)
+	}
+
+	if format != FormatHTML {
+		return buf.Bytes(), nil
+	}
+	return renderHTML(snapshot, buf.Bytes())
+}
+
+const snapshotScriptID = "tilt-snapshot"
+
+var snapshotScriptRe = regexp.MustCompile(
+	`(?s)<script type="application/json" id="` + snapshotScriptID + `">(.*?)</script>`)
+
+// Returns the JSON snapshot embedded in an HTML export.
+//
+// Snapshots that aren't HTML are returned unchanged.
+func ExtractJSON(raw []byte) []byte {
+	match := snapshotScriptRe.FindSubmatch(raw)
+	if match == nil {
+		return raw
+	}
+	return match[1]
+}
+
+type htmlResource struct {
+	Name          string
+	UpdateStatus  string
+	RuntimeStatus string
+	Endpoints     []string
+}
+
+type htmlLogLine struct {
+	Resource string
+	Text     string
+	Level    string
+}
+
+type htmlSnapshot struct {
+	CreatedAt   string
+	TiltVersion string
+	Resources   []htmlResource
+	Logs        []htmlLogLine
+	JSON        template.JS
+}
+
+func renderHTML(snapshot *proto_webview.Snapshot, js []byte) ([]byte, error) {
+	data := htmlSnapshot{
+		// JSON can only contain '<' inside strings, where the escape
+		// is equivalent. This keeps a log line like "</script>" from
+		// ending the embedded snapshot early.
+		JSON: template.JS(bytes.ReplaceAll(js, []byte("<"), []byte(`\u003c`))),
+	}
+	if snapshot.CreatedAt != nil {
+		data.CreatedAt = snapshot.CreatedAt.AsTime().UTC().Format(time.RFC1123)
+	}
+
+	view := snapshot.View
+	if view == nil {
+		view = &proto_webview.View{}
+	}
+	if session := view.UiSession; session != nil {
+		data.TiltVersion = session.Status.RunningTiltBuild.Version
+	}
+
+	for _, r := range view.UiResources {
+		res := htmlResource{
+			Name:          r.Name,
+			UpdateStatus:  string(r.Status.UpdateStatus),
+			RuntimeStatus: string(r.Status.RuntimeStatus),
+		}
+		for _, link := range r.Status.EndpointLinks {
+			res.Endpoints = append(res.Endpoints, link.URL)
+		}
+		data.Resources = append(data.Resources, res)
+	}
+
+	if logList := view.LogList; logList != nil {
+		for _, seg := range logList.Segments {
+			resource := ""
+			if span, ok := logList.Spans[seg.SpanId]; ok {
+				resource = span.ManifestName
+			}
+			data.Logs = append(data.Logs, htmlLogLine{
+				Resource: resource,
+				Text:     seg.Text,
+				Level:    strings.ToLower(seg.Level.String()),
+			})
+		}
+	}
+
+	var buf bytes.Buffer
+	err := htmlTemplate.Execute(&buf, data)
+	if err != nil {
+		return nil, fmt.Errorf("rendering snapshot: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+var htmlTemplate = template.Must(template.New("snapshot").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Tilt Snapshot</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #20ba31; background: #001b20; }
+h1, h2 { color: #fff; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { text-align: left; padding: 0.25em 1em 0.25em 0; color: #eee; }
+pre { white-space: pre-wrap; color: #eee; }
+.resource { color: #03c7d3; }
+.warn { color: #fcb41e; }
+.error { color: #f6685c; }
+</style>
+</head>
+<body>
+<h1>Tilt Snapshot</h1>
+<p>{{if .CreatedAt}}Created {{.CreatedAt}}{{end}}{{if .TiltVersion}} with Tilt v{{.TiltVersion}}{{end}}</p>
+<h2>Resources</h2>
+<table>
+<tr><th>Resource</th><th>Update</th><th>Runtime</th><th>Endpoints</th></tr>
+{{- range .Resources}}
+<tr><td>{{.Name}}</td><td>{{.UpdateStatus}}</td><td>{{.RuntimeStatus}}</td><td>{{range .Endpoints}}{{.}} {{end}}</td></tr>
+{{- end}}
+</table>
+<h2>Logs</h2>
+<pre>
+{{- range .Logs}}<span class="{{.Level}}">{{if .Resource}}<span class="resource">{{.Resource}} │ </span>{{end}}{{.Text}}</span>{{end -}}
+</pre>
+<script type="application/json" id="tilt-snapshot">{{.JSON}}</script>
+</body>
+</html>
+`))
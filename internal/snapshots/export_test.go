@@ -0,0 +1,95 @@
+package snapshots
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	proto_webview "github.com/tilt-dev/tilt/pkg/webview"
+)
+
+func testSnapshot() *proto_webview.Snapshot {
+	return &proto_webview.Snapshot{
+		CreatedAt: timestamppb.New(time.Unix(1600000000, 0)),
+		View: &proto_webview.View{
+			UiResources: []*v1alpha1.UIResource{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "frontend"},
+					Status: v1alpha1.UIResourceStatus{
+						UpdateStatus:  v1alpha1.UpdateStatusOK,
+						RuntimeStatus: v1alpha1.RuntimeStatusOK,
+						EndpointLinks: []v1alpha1.UIResourceLink{{URL: "http://localhost:3000"}},
+					},
+				},
+			},
+			LogList: &proto_webview.LogList{
+				Spans: map[string]*proto_webview.LogSpan{
+					"build:1": {ManifestName: "frontend"},
+				},
+				Segments: []*proto_webview.LogSegment{
+					{SpanId: "build:1", Text: "Building <frontend>\n", Level: proto_webview.LogLevel_INFO},
+					{SpanId: "build:1", Text: "oops </script>\n", Level: proto_webview.LogLevel_ERROR},
+				},
+			},
+		},
+	}
+}
+
+func TestFormatFromPath(t *testing.T) {
+	assert.Equal(t, FormatJSON, FormatFromPath("snapshot.json"))
+	assert.Equal(t, FormatJSON, FormatFromPath("snapshot"))
+	assert.Equal(t, FormatHTML, FormatFromPath("/tmp/snapshot.HTML"))
+	assert.Equal(t, FormatHTML, FormatFromPath("snapshot.htm"))
+}
+
+func TestParseFormat(t *testing.T) {
+	f, err := ParseFormat("HTML")
+	require.NoError(t, err)
+	assert.Equal(t, FormatHTML, f)
+
+	_, err = ParseFormat("pdf")
+	assert.EqualError(t, err, `unknown snapshot format "pdf": must be one of json, html`)
+}
+
+func TestRenderJSON(t *testing.T) {
+	data, err := Render(testSnapshot(), FormatJSON)
+	require.NoError(t, err)
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Contains(t, decoded, "view")
+	assert.Contains(t, decoded, "createdAt")
+
+	// JSON snapshots pass through untouched.
+	assert.Equal(t, data, ExtractJSON(data))
+}
+
+func TestRenderHTML(t *testing.T) {
+	data, err := Render(testSnapshot(), FormatHTML)
+	require.NoError(t, err)
+
+	html := string(data)
+	assert.Contains(t, html, "<td>frontend</td>")
+	assert.Contains(t, html, "http://localhost:3000")
+	assert.Contains(t, html, "Building &lt;frontend&gt;")
+	assert.Contains(t, html, `<span class="error">`)
+
+	// The log line with a closing script tag must not
+	// end the embedded snapshot early.
+	assert.Equal(t, 1, strings.Count(html, "</script>"))
+
+	expected, err := Render(testSnapshot(), FormatJSON)
+	require.NoError(t, err)
+
+	var expectedJSON, embeddedJSON interface{}
+	require.NoError(t, json.Unmarshal(expected, &expectedJSON))
+	require.NoError(t, json.Unmarshal(ExtractJSON(data), &embeddedJSON))
+	assert.Equal(t, expectedJSON, embeddedJSON)
+}
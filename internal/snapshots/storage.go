@@ -0,0 +1,112 @@
+package snapshots
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// Storage uploads snapshots somewhere they can be shared.
+type Storage interface {
+	// Uploads the snapshot under the given name.
+	//
+	// Returns a URL that anyone with the link can use to download the snapshot
+	// until the link expires.
+	Upload(ctx context.Context, name string, snapshot []byte) (string, error)
+}
+
+var ErrStorageNotConfigured = errors.New("snapshot storage is not configured. " +
+	"Set snapshot_settings(storage_url=...) in your Tiltfile or the TILT_SNAPSHOT_STORAGE_URL environment variable")
+
+// Creates a Storage for the storage URL in the settings,
+// falling back to the TILT_SNAPSHOT_STORAGE_URL environment variable.
+//
+// Credentials always come from the environment:
+//
+//   - s3:// uses AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN, and AWS_REGION.
+//   - gs:// uses the HMAC keys in TILT_SNAPSHOT_GCS_HMAC_ACCESS_ID and TILT_SNAPSHOT_GCS_HMAC_SECRET.
+//
+// TILT_SNAPSHOT_STORAGE_ENDPOINT overrides the endpoint, for S3-compatible
+// stores like MinIO.
+func NewStorage(settings model.SnapshotSettings) (Storage, error) {
+	return newStorage(settings, os.Getenv)
+}
+
+func newStorage(settings model.SnapshotSettings, getenv func(string) string) (Storage, error) {
+	storageURL := settings.StorageURL
+	if storageURL == "" {
+		storageURL = getenv("TILT_SNAPSHOT_STORAGE_URL")
+	}
+	if storageURL == "" {
+		return nil, ErrStorageNotConfigured
+	}
+
+	u, err := url.Parse(storageURL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing snapshot storage URL %q: %v", storageURL, err)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("snapshot storage URL %q has no bucket", storageURL)
+	}
+
+	linkExpiry := settings.LinkExpiry
+	if linkExpiry <= 0 || linkExpiry > model.SnapshotDefaultLinkExpiry {
+		linkExpiry = model.SnapshotDefaultLinkExpiry
+	}
+
+	s := &bucketStorage{
+		bucket:     u.Host,
+		prefix:     strings.Trim(u.Path, "/"),
+		linkExpiry: linkExpiry,
+	}
+
+	switch u.Scheme {
+	case "s3":
+		s.creds = credentials{
+			accessKeyID:     getenv("AWS_ACCESS_KEY_ID"),
+			secretAccessKey: getenv("AWS_SECRET_ACCESS_KEY"),
+			sessionToken:    getenv("AWS_SESSION_TOKEN"),
+		}
+		s.region = getenv("AWS_REGION")
+		if s.region == "" {
+			s.region = getenv("AWS_DEFAULT_REGION")
+		}
+		if s.region == "" {
+			s.region = "us-east-1"
+		}
+		s.endpoint = &url.URL{Scheme: "https", Host: fmt.Sprintf("s3.%s.amazonaws.com", s.region)}
+
+		// Bucket names with dots don't match the wildcard TLS cert.
+		s.pathStyle = strings.Contains(s.bucket, ".")
+	case "gs":
+		s.creds = credentials{
+			accessKeyID:     getenv("TILT_SNAPSHOT_GCS_HMAC_ACCESS_ID"),
+			secretAccessKey: getenv("TILT_SNAPSHOT_GCS_HMAC_SECRET"),
+		}
+		s.region = "auto"
+		s.endpoint = &url.URL{Scheme: "https", Host: "storage.googleapis.com"}
+		s.pathStyle = true
+	default:
+		return nil, fmt.Errorf("unsupported snapshot storage URL %q: must start with s3:// or gs://", storageURL)
+	}
+
+	if s.creds.accessKeyID == "" || s.creds.secretAccessKey == "" {
+		return nil, fmt.Errorf("missing credentials for snapshot storage %q", storageURL)
+	}
+
+	if endpoint := getenv("TILT_SNAPSHOT_STORAGE_ENDPOINT"); endpoint != "" {
+		e, err := url.Parse(endpoint)
+		if err != nil || e.Host == "" {
+			return nil, fmt.Errorf("invalid TILT_SNAPSHOT_STORAGE_ENDPOINT: %q", endpoint)
+		}
+		s.endpoint = e
+		s.pathStyle = true
+	}
+
+	return s, nil
+}
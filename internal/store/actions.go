@@ -23,6 +23,15 @@ func NewErrorAction(err error) ErrorAction {
 	return ErrorAction{Error: err}
 }
 
+// AppendToTriggerQueueAction asks the engine to queue a build of the
+// given manifest, e.g., from a UI button, a webhook, or a watched object field.
+type AppendToTriggerQueueAction struct {
+	Name   model.ManifestName
+	Reason model.BuildReason
+}
+
+func (AppendToTriggerQueueAction) Action() {}
+
 type LogAction struct {
 	mn        model.ManifestName
 	spanID    logstore.SpanID
@@ -197,6 +197,10 @@ type BuildState struct {
 	// live_update, and force an image build (even if there are no changed files)
 	FullBuildTriggered bool
 
+	// The user triggered this build with the no-cache option, so image builds
+	// should skip the build cache. Always accompanies FullBuildTriggered.
+	NoCache bool
+
 	// The default cluster.
 	Cluster *v1alpha1.Cluster
 }
@@ -229,6 +233,11 @@ func (b BuildState) WithFullBuildTriggered(isImageBuildTrigger bool) BuildState
 	return b
 }
 
+func (b BuildState) WithNoCache(noCache bool) BuildState {
+	b.NoCache = noCache
+	return b
+}
+
 func (b BuildState) LastLocalImageAsString() string {
 	return LocalImageRefFromBuildResult(b.LastResult)
 }
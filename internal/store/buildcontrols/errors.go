@@ -2,6 +2,7 @@ package buildcontrols
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/pkg/errors"
 )
@@ -12,3 +13,18 @@ func IsFatalError(err error) bool {
 	cause := errors.Cause(err)
 	return cause == context.Canceled
 }
+
+// Indicates that a build was canceled because a newer file change
+// will rebuild it.
+type SupersededError struct {
+	File string
+}
+
+func (e SupersededError) Error() string {
+	return fmt.Sprintf("build canceled: superseded by a newer change to %s", e.File)
+}
+
+func IsSupersededError(err error) bool {
+	_, ok := errors.Cause(err).(SupersededError)
+	return ok
+}
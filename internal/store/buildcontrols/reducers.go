@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/pkg/errors"
+
 	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/internal/dockercompose"
 	"github.com/tilt-dev/tilt/internal/k8s"
@@ -40,6 +42,19 @@ func HandleBuildStarted(ctx context.Context, state *store.EngineState, action Bu
 		Reason:    action.Reason,
 		SpanID:    action.SpanID,
 	}
+
+	// If the Tiltfile changed the manifest, the config files are what triggered it.
+	triggerFiles := bs.Edits
+	if len(triggerFiles) == 0 && action.Reason.Has(model.BuildReasonFlagConfig) {
+		triggerFiles = ms.ConfigFilesThatCausedChange
+	}
+	ms.AddTrigger(model.TriggerRecord{
+		Time:   action.StartTime,
+		Reason: action.Reason,
+		Files:  triggerFiles,
+		SpanID: action.SpanID,
+	})
+
 	ms.ConfigFilesThatCausedChange = []string{}
 	ms.CurrentBuilds[action.Source] = bs
 
@@ -80,8 +95,12 @@ func handleBuildResults(engineState *store.EngineState,
 	}
 
 	// Remove pending file changes that were consumed by this build.
-	for _, status := range ms.BuildStatuses {
-		status.ClearPendingChangesBefore(br.StartTime)
+	//
+	// A superseded build didn't consume them, so the next build needs them too.
+	if !IsSupersededError(br.Error) {
+		for _, status := range ms.BuildStatuses {
+			status.ClearPendingChangesBefore(br.StartTime)
+		}
 	}
 
 	if isBuildSuccess {
@@ -177,7 +196,13 @@ func HandleBuildCompleted(ctx context.Context, engineState *store.EngineState, c
 	}
 
 	err := cb.Error
-	if err != nil {
+	if superseded, ok := errors.Cause(err).(SupersededError); ok {
+		s := fmt.Sprintf("Build Canceled: superseded by a newer change to %s", superseded.File)
+
+		engineState.LogStore.Append(
+			store.NewLogAction(mt.Manifest.Name, cb.SpanID, logger.InfoLvl, nil, []byte(s)),
+			engineState.Secrets)
+	} else if err != nil {
 		s := fmt.Sprintf("Build Failed: %v", err)
 
 		engineState.LogStore.Append(
@@ -255,7 +280,13 @@ func HandleBuildCompleted(ctx context.Context, engineState *store.EngineState, c
 				// only update the succeeded time if there's no readiness probe
 				lrs.LastReadyOrSucceededTime = time.Now()
 			}
-			if lt.ServeCmd.Empty() {
+			if lt.IsExternal() {
+				// external resources get their runtime status from their
+				// health check; without one, they're always healthy
+				if lt.ReadinessProbe == nil {
+					lrs.Status = v1alpha1.RuntimeStatusOK
+				}
+			} else if lt.ServeCmd.Empty() {
 				// local resources without a serve command are jobs that run and
 				// terminate; so there's no real runtime status
 				lrs.Status = v1alpha1.RuntimeStatusNotApplicable
@@ -0,0 +1,129 @@
+package store
+
+import (
+	"fmt"
+	"runtime/debug"
+	"time"
+)
+
+// The most crashes to keep in the EngineState.
+const maxCrashes = 10
+
+// The most recent actions to keep for crash reports.
+const maxRecentActions = 20
+
+// A panic in a controller or subscriber that Tilt recovered from.
+//
+// Tilt keeps running, but the component that crashed may not
+// work correctly, so we surface the crash to the user.
+type Crash struct {
+	// The controller or subscriber that panicked,
+	// e.g., "engine/uiresource.Subscriber"
+	Component string
+
+	// The value passed to panic().
+	Message string
+
+	Stack string
+
+	// When the component first crashed with this message.
+	Time time.Time
+
+	// When the component most recently crashed with this message,
+	// and how many times it's crashed.
+	LastTime time.Time
+	Count    int
+
+	// The types of the actions processed just before the first crash.
+	RecentActions []string
+}
+
+type CrashAction struct {
+	Component string
+	Message   string
+	Stack     string
+	Time      time.Time
+}
+
+func (CrashAction) Action() {}
+
+// Implemented by controllers and subscribers that can keep running
+// after a panic, because they release every lock they hold with defer.
+//
+// Tilt recovers from panics in these components, reports the crash, and
+// retries them with backoff. A panic anywhere else stops Tilt, because
+// it may have left the store (or the component) locked.
+type CrashRecoverable interface {
+	CrashRecoverable()
+}
+
+func IsCrashRecoverable(component interface{}) bool {
+	_, ok := component.(CrashRecoverable)
+	return ok
+}
+
+// Recovers from a panic in a controller or subscriber and reports the crash.
+//
+// Must be called with defer. Sets err so that the caller
+// retries with backoff, like any other error.
+//
+// Unlike SafeGo, this doesn't stop the process. Only use it
+// in components that are CrashRecoverable.
+func RecoverCrash(st RStore, component string, err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	st.Dispatch(CrashAction{
+		Component: component,
+		Message:   fmt.Sprintf("%v", r),
+		Stack:     string(debug.Stack()),
+		Time:      time.Now(),
+	})
+	*err = fmt.Errorf("PANIC: %v", r)
+}
+
+// Records a crash in the EngineState.
+//
+// Repeated crashes with the same message are merged, so that a component
+// that panics on every retry doesn't flood the UI.
+//
+// Returns true if this is a new crash.
+func (s *EngineState) RecordCrash(action CrashAction) bool {
+	for i, c := range s.Crashes {
+		if c.Component == action.Component && c.Message == action.Message {
+			s.Crashes[i].Count++
+			s.Crashes[i].LastTime = action.Time
+			return false
+		}
+	}
+
+	s.Crashes = append(s.Crashes, Crash{
+		Component:     action.Component,
+		Message:       action.Message,
+		Stack:         action.Stack,
+		Time:          action.Time,
+		LastTime:      action.Time,
+		Count:         1,
+		RecentActions: append([]string{}, s.RecentActions...),
+	})
+	if len(s.Crashes) > maxCrashes {
+		s.Crashes = s.Crashes[len(s.Crashes)-maxCrashes:]
+	}
+	return true
+}
+
+// Remembers the type of an action for crash reports.
+//
+// We only keep the type, so that the reports don't leak anything sensitive.
+// Log actions are too noisy to be useful, so we skip them.
+func (s *EngineState) recordRecentAction(action Action) {
+	if _, ok := action.(LogAction); ok {
+		return
+	}
+	s.RecentActions = append(s.RecentActions, fmt.Sprintf("%T", action))
+	if len(s.RecentActions) > maxRecentActions {
+		s.RecentActions = s.RecentActions[len(s.RecentActions)-maxRecentActions:]
+	}
+}
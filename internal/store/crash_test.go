@@ -0,0 +1,44 @@
+package store
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+func TestRecordCrash(t *testing.T) {
+	state := NewState()
+	state.recordRecentAction(CompletedBuildAction{})
+	state.recordRecentAction(NewGlobalLogAction(logger.InfoLvl, []byte("hi")))
+
+	t1 := time.Now()
+	t2 := t1.Add(time.Second)
+	assert.True(t, state.RecordCrash(CrashAction{Component: "c1", Message: "oh no", Time: t1}))
+	assert.False(t, state.RecordCrash(CrashAction{Component: "c1", Message: "oh no", Time: t2}))
+	assert.True(t, state.RecordCrash(CrashAction{Component: "c2", Message: "oh no", Time: t2}))
+
+	require.Len(t, state.Crashes, 2)
+	assert.Equal(t, 2, state.Crashes[0].Count)
+	assert.Equal(t, t1, state.Crashes[0].Time)
+	assert.Equal(t, t2, state.Crashes[0].LastTime)
+	assert.Equal(t, []string{"store.CompletedBuildAction"}, state.Crashes[0].RecentActions)
+}
+
+func TestRecordCrashLimit(t *testing.T) {
+	state := NewState()
+	for i := 0; i < maxRecentActions+5; i++ {
+		state.recordRecentAction(CompletedBuildAction{})
+	}
+	for i := 0; i < maxCrashes+5; i++ {
+		state.RecordCrash(CrashAction{Component: fmt.Sprintf("c%d", i), Message: "oh no"})
+	}
+
+	require.Len(t, state.Crashes, maxCrashes)
+	assert.Equal(t, "c5", state.Crashes[0].Component)
+	assert.Len(t, state.RecentActions, maxRecentActions)
+}
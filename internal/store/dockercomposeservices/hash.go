@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"hash"
 
+	"github.com/compose-spec/compose-go/types"
 	jsoniter "github.com/json-iterator/go"
 	"github.com/pkg/errors"
 
@@ -33,6 +34,19 @@ func MustHashProject(p v1alpha1.DockerComposeProject) string {
 	return w.done()
 }
 
+// Compute the hash of a service's resolved configuration.
+//
+// Two versions of a compose project can have different hashes, but the same
+// hash for any service that the edit didn't touch.
+func HashServiceConfig(svc types.ServiceConfig) (string, error) {
+	w := newHashWriter()
+	err := w.append(svc)
+	if err != nil {
+		return "", err
+	}
+	return w.done(), nil
+}
+
 type hashWriter struct {
 	h hash.Hash
 }
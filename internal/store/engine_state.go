@@ -2,13 +2,16 @@ package store
 
 import (
 	"fmt"
+	"net"
 	"sort"
+	"strconv"
 	"time"
 
 	"github.com/tilt-dev/wmclient/pkg/analytics"
 
 	tiltanalytics "github.com/tilt-dev/tilt/internal/analytics"
 	"github.com/tilt-dev/tilt/internal/dockercompose"
+	"github.com/tilt-dev/tilt/internal/feature"
 	"github.com/tilt-dev/tilt/internal/k8s"
 	"github.com/tilt-dev/tilt/internal/store/k8sconv"
 	"github.com/tilt-dev/tilt/internal/timecmp"
@@ -34,6 +37,9 @@ type EngineState struct {
 
 	TerminalMode TerminalMode
 
+	// How to format log prefixes when streaming logs to the terminal.
+	LogPrefixFormat logstore.PrefixFormat
+
 	// For synchronizing BuildController -- wait until engine records all builds started
 	// so far before starting another build
 	BuildControllerStartCount int
@@ -55,6 +61,12 @@ type EngineState struct {
 	// We recovered from a panic(). We need to clean up the RTY and print the error.
 	PanicExited error
 
+	// Panics in controllers and subscribers that we recovered from.
+	Crashes []Crash
+
+	// The types of the most recent actions, for crash reports.
+	RecentActions []string
+
 	// Normal process termination. Either Tilt completed all its work,
 	// or it determined that it was unable to complete the work it was assigned.
 	//
@@ -81,6 +93,7 @@ type EngineState struct {
 
 	SuggestedTiltVersion string
 	VersionSettings      model.VersionSettings
+	VersionCheck         VersionCheckStatus
 
 	// Analytics Info
 	AnalyticsEnvOpt        analytics.Opt
@@ -90,6 +103,9 @@ type EngineState struct {
 
 	Features map[string]bool
 
+	// Where each feature flag's value came from.
+	FeatureSources map[string]feature.Source
+
 	Secrets model.SecretSet
 
 	CloudAddress string
@@ -98,6 +114,13 @@ type EngineState struct {
 
 	DockerPruneSettings model.DockerPruneSettings
 
+	IdleSettings model.IdleSettings
+	Idle         IdleState
+
+	BandwidthSettings model.BandwidthSettings
+
+	SnapshotSettings model.SnapshotSettings
+
 	TelemetrySettings model.TelemetrySettings
 
 	UserConfigState model.UserConfigState
@@ -330,8 +353,8 @@ func (e *EngineState) AppendToTriggerQueue(mn model.ManifestName, reason model.B
 		return
 	}
 
-	if reason == 0 {
-		reason = model.BuildReasonFlagTriggerUnknown
+	if !reason.HasTrigger() {
+		reason = reason.With(model.BuildReasonFlagTriggerUnknown)
 	}
 
 	ms.TriggerReason = ms.TriggerReason.With(reason)
@@ -382,7 +405,7 @@ func (e *EngineState) MainConfigPaths() []string {
 func (e *EngineState) HasDockerBuild() bool {
 	for _, m := range e.Manifests() {
 		for _, targ := range m.ImageTargets {
-			if targ.IsDockerBuild() {
+			if targ.IsDockerBuild() || targ.IsKoBuild() {
 				return true
 			}
 		}
@@ -409,6 +432,100 @@ func (e *EngineState) InitialBuildsCompleted() bool {
 	return true
 }
 
+// Resources that Tilt disabled because nobody was using it, and what we
+// need to bring them back without rebuilding.
+type IdleState struct {
+	// When Tilt disabled resources for being idle. Zero if it hasn't, or
+	// if they've been resumed since.
+	ShutDownAt time.Time
+
+	// The resources that Tilt disabled.
+	Resources []model.ManifestName
+
+	// The last good build of each resource, by resource name.
+	Snapshots map[model.ManifestName]IdleSnapshot
+
+	// While resuming, when we started and which resources we're waiting on.
+	ResumeStartTime time.Time
+	Resuming        []model.ManifestName
+}
+
+func (s IdleState) IsShutDown() bool {
+	return !s.ShutDownAt.IsZero()
+}
+
+func (s IdleState) IsResuming() bool {
+	return !s.ResumeStartTime.IsZero()
+}
+
+// The resources that have come back since Tilt started resuming them.
+//
+// A resource is back when it has deployed and its runtime is no longer
+// pending. Resources that failed count too, so that one broken resource
+// doesn't leave us resuming forever.
+func (e EngineState) IdleResumedResources() []model.ManifestName {
+	var result []model.ManifestName
+	for _, mn := range e.Idle.Resuming {
+		mt, ok := e.ManifestTargets[mn]
+		if !ok {
+			// The resource was removed from the Tiltfile.
+			result = append(result, mn)
+			continue
+		}
+
+		ms := mt.State
+		if ms.DisableState != v1alpha1.DisableStateEnabled {
+			continue
+		}
+
+		if !mt.Manifest.TriggerMode.AutoInitial() {
+			// Manual resources don't deploy until someone triggers them.
+			result = append(result, mn)
+			continue
+		}
+
+		lastBuild := ms.LastBuild()
+		if ms.IsBuilding() || lastBuild.Empty() || lastBuild.StartTime.Before(e.Idle.ResumeStartTime) {
+			continue
+		}
+
+		if lastBuild.Error == nil {
+			rs := mt.RuntimeStatus()
+			if rs == v1alpha1.RuntimeStatusPending || rs == v1alpha1.RuntimeStatusUnknown {
+				continue
+			}
+		}
+		result = append(result, mn)
+	}
+	return result
+}
+
+// The state of a resource when Tilt disabled it for being idle.
+type IdleSnapshot struct {
+	// If the manifest changes before we resume, we can't re-use its results.
+	Manifest model.Manifest
+
+	Results map[model.TargetID]BuildResult
+}
+
+// The result of comparing the running Tilt against the latest release.
+type VersionCheckStatus struct {
+	// e.g., "0.31.0"
+	LatestVersion string
+	ReleaseURL    string
+
+	// True if the latest release is newer than the running Tilt.
+	UpgradeAvailable bool
+
+	// The first few items from the latest release's changelog.
+	Highlights []string
+
+	// Human-readable explanations of version skew (e.g., that Tilt is out of date).
+	Warnings []string
+
+	CheckedAt time.Time
+}
+
 // TODO(nick): This will eventually implement TargetStatus
 type BuildStatus struct {
 	// Stores the times of all the pending changes,
@@ -483,6 +600,9 @@ type ManifestState struct {
 	// The last `BuildHistoryLimit` builds. The most recent build is first in the slice.
 	BuildHistory []model.BuildRecord
 
+	// The last `TriggerHistoryLimit` triggers. The most recent trigger is first in the slice.
+	TriggerHistory []model.TriggerRecord
+
 	// If this manifest was changed, which config files led to the most recent change in manifest definition
 	ConfigFilesThatCausedChange []string
 
@@ -498,6 +618,7 @@ func NewState() *EngineState {
 	ret.ManifestTargets = make(map[model.ManifestName]*ManifestTarget)
 	ret.Secrets = model.SecretSet{}
 	ret.DockerPruneSettings = model.DefaultDockerPruneSettings()
+	ret.SnapshotSettings = model.DefaultSnapshotSettings()
 	ret.VersionSettings = model.VersionSettings{
 		CheckUpdates: true,
 	}
@@ -637,6 +758,13 @@ func (ms *ManifestState) AddCompletedBuild(bs model.BuildRecord) {
 	}
 }
 
+func (ms *ManifestState) AddTrigger(t model.TriggerRecord) {
+	ms.TriggerHistory = append([]model.TriggerRecord{t}, ms.TriggerHistory...)
+	if len(ms.TriggerHistory) > model.TriggerHistoryLimit {
+		ms.TriggerHistory = ms.TriggerHistory[:model.TriggerHistoryLimit]
+	}
+}
+
 func (ms *ManifestState) StartedFirstBuild() bool {
 	return ms.IsBuilding() || len(ms.BuildHistory) > 0
 }
@@ -869,6 +997,11 @@ func ManifestTargetEndpoints(mt *ManifestTarget) (endpoints []model.Link) {
 		}
 
 		for _, binding := range mt.State.DCRuntimeState().Ports {
+			// Browsers can't talk to UDP ports.
+			if binding.Protocol == "udp" {
+				continue
+			}
+
 			// Docker usually contains multiple bindings for each port - one for ipv4 (0.0.0.0)
 			// and one for ipv6 (::1).
 			p := binding.HostPort
@@ -876,7 +1009,7 @@ func ManifestTargetEndpoints(mt *ManifestTarget) (endpoints []model.Link) {
 				continue
 			}
 			hostPorts[p] = true
-			endpoints = append(endpoints, model.MustNewLink(fmt.Sprintf("http://localhost:%d/", p), ""))
+			endpoints = append(endpoints, model.MustNewLink(fmt.Sprintf("http://%s/", net.JoinHostPort(dcLinkHost(binding.HostIP), strconv.Itoa(int(p)))), ""))
 		}
 
 		endpoints = append(endpoints, mt.Manifest.DockerComposeTarget().Links...)
@@ -885,4 +1018,16 @@ func ManifestTargetEndpoints(mt *ManifestTarget) (endpoints []model.Link) {
 	return endpoints
 }
 
+// The host to link to for a port that Docker binds to ip.
+//
+// Ports bound to every interface, or to the loopback interface,
+// are reachable on localhost.
+func dcLinkHost(ip string) string {
+	parsed := net.ParseIP(ip)
+	if parsed == nil || parsed.IsUnspecified() || parsed.IsLoopback() {
+		return "localhost"
+	}
+	return ip
+}
+
 const MainTiltfileManifestName = model.MainTiltfileManifestName
@@ -77,6 +77,15 @@ func TestNextBuildReason(t *testing.T) {
 		mt.NextBuildReason().String())
 }
 
+func TestTriggerHistoryLimit(t *testing.T) {
+	state := &ManifestState{}
+	for i := 0; i < model.TriggerHistoryLimit+5; i++ {
+		state.AddTrigger(model.TriggerRecord{Time: time.Unix(int64(i), 0)})
+	}
+	require.Len(t, state.TriggerHistory, model.TriggerHistoryLimit)
+	assert.Equal(t, time.Unix(int64(model.TriggerHistoryLimit+4), 0), state.TriggerHistory[0].Time)
+}
+
 func TestManifestTargetEndpoints(t *testing.T) {
 	cases := []endpointsCase{
 		{
@@ -186,6 +195,33 @@ func TestManifestTargetEndpoints(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "docker compose ports on specific interfaces",
+			expected: []model.Link{
+				model.MustNewLink("http://localhost:8000/", ""),
+				model.MustNewLink("http://192.168.1.5:9000/", ""),
+			},
+			dcPortBindings: []v1alpha1.DockerPortBinding{
+				{
+					ContainerPort: 8080,
+					HostIP:        "127.0.0.1",
+					HostPort:      8000,
+					Protocol:      "tcp",
+				},
+				{
+					ContainerPort: 9090,
+					HostIP:        "192.168.1.5",
+					HostPort:      9000,
+					Protocol:      "tcp",
+				},
+				{
+					ContainerPort: 53,
+					HostIP:        "0.0.0.0",
+					HostPort:      5353,
+					Protocol:      "udp",
+				},
+			},
+		},
 		{
 			name: "load balancers",
 			expected: []model.Link{
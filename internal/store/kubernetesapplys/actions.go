@@ -21,3 +21,15 @@ func NewKubernetesApplyDeleteAction(n string) KubernetesApplyDeleteAction {
 }
 
 func (KubernetesApplyDeleteAction) Action() {}
+
+// Dispatched when the user approves the namespaces that
+// a resource's apply was waiting on, so that we deploy it again.
+type KubernetesApplyNamespacesApprovedAction struct {
+	ManifestName string
+}
+
+func NewKubernetesApplyNamespacesApprovedAction(mn string) KubernetesApplyNamespacesApprovedAction {
+	return KubernetesApplyNamespacesApprovedAction{ManifestName: mn}
+}
+
+func (KubernetesApplyNamespacesApprovedAction) Action() {}
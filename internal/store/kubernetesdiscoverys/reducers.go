@@ -5,6 +5,7 @@ import (
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/tilt-dev/tilt/internal/controllers/apicmp"
 	"github.com/tilt-dev/tilt/internal/store"
@@ -91,7 +92,7 @@ func RefreshKubernetesResource(state *store.EngineState, name string) {
 			krs.FilteredPods = r.FilteredPods
 			krs.Conditions = r.ApplyStatus.Conditions
 
-			if isReadyOrSucceeded(r, krs.PodReadinessMode) {
+			if isReadyOrSucceeded(r, krs.PodReadinessMode, krs.WaitForSidecars) {
 				// NOTE(nick): It doesn't seem right to update this timestamp everytime
 				// we get a new event, but it's what the old code did.
 				krs.LastReadyOrSucceededTime = time.Now()
@@ -102,7 +103,14 @@ func RefreshKubernetesResource(state *store.EngineState, name string) {
 	}
 }
 
-func isReadyOrSucceeded(r *k8sconv.KubernetesResource, podReadinessMode model.PodReadinessMode) bool {
+func isReadyOrSucceeded(r *k8sconv.KubernetesResource, podReadinessMode model.PodReadinessMode, waitForSidecars bool) bool {
+	// 0. If the apply has rollout checks (e.g., waiting for a Deployment to be
+	//    Available), they need to pass first.
+	if cond := meta.FindStatusCondition(r.ApplyStatus.Conditions, v1alpha1.ApplyConditionRolloutComplete); cond != nil &&
+		cond.Status != metav1.ConditionTrue {
+		return false
+	}
+
 	// 1. Apply operation indicated that it was for a Job that already completed,
 	// 	  so we can consider it successful without inspecting Pods, which avoids
 	//    issues in the case that the Job's Pod was GC'd.
@@ -124,7 +132,7 @@ func isReadyOrSucceeded(r *k8sconv.KubernetesResource, podReadinessMode model.Po
 			// for jobs, we don't care about whether it's ready, only whether it's succeeded
 			podReady = pod.Phase == string(v1.PodSucceeded)
 		} else {
-			podReady = len(pod.Containers) != 0 && store.AllPodContainersReady(pod)
+			podReady = len(pod.Containers) != 0 && store.PodContainersReady(pod, waitForSidecars)
 		}
 		if !podReady {
 			return false
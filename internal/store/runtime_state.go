@@ -47,6 +47,9 @@ type LocalRuntimeState struct {
 	SpanID                   model.LogSpanID
 	LastReadyOrSucceededTime time.Time
 	Ready                    bool
+
+	// Why the health check of an external resource is failing.
+	HealthCheckError string
 }
 
 var _ RuntimeState = LocalRuntimeState{}
@@ -66,6 +69,9 @@ func (l LocalRuntimeState) RuntimeStatusError() error {
 	if status != v1alpha1.RuntimeStatusError {
 		return nil
 	}
+	if l.HealthCheckError != "" {
+		return fmt.Errorf("Health check failed: %s", l.HealthCheckError)
+	}
 	return fmt.Errorf("Process %d exited with non-zero status", l.PID)
 }
 
@@ -91,6 +97,9 @@ type K8sRuntimeState struct {
 	UpdateStartTime map[k8s.PodID]time.Time
 
 	PodReadinessMode model.PodReadinessMode
+
+	// Whether service mesh sidecars count towards pod readiness.
+	WaitForSidecars bool
 }
 
 func (K8sRuntimeState) RuntimeState() {}
@@ -107,6 +116,7 @@ func NewK8sRuntimeStateWithPods(m model.Manifest, pods ...v1alpha1.Pod) K8sRunti
 func NewK8sRuntimeState(m model.Manifest) K8sRuntimeState {
 	return K8sRuntimeState{
 		PodReadinessMode: m.PodReadinessMode(),
+		WaitForSidecars:  m.WaitForSidecars(),
 		LBs:              make(map[k8s.ServiceName]*url.URL),
 		UpdateStartTime:  make(map[k8s.PodID]time.Time),
 	}
@@ -117,15 +127,32 @@ func (s K8sRuntimeState) RuntimeStatusError() error {
 	if status != v1alpha1.RuntimeStatusError {
 		return nil
 	}
+	if cond := s.rolloutCondition(); cond != nil && cond.Reason == v1alpha1.ApplyReasonRolloutTimeout {
+		return fmt.Errorf("Rollout failed: %s", cond.Message)
+	}
 	pod := s.MostRecentPod()
 	return fmt.Errorf("Pod %s in error state: %s", pod.Name, pod.Status)
 }
 
+// The outcome of the rollout checks on the applied objects, if any.
+func (s K8sRuntimeState) rolloutCondition() *metav1.Condition {
+	return meta.FindStatusCondition(s.Conditions, v1alpha1.ApplyConditionRolloutComplete)
+}
+
 func (s K8sRuntimeState) RuntimeStatus() v1alpha1.RuntimeStatus {
 	if !s.HasEverDeployedSuccessfully {
 		return v1alpha1.RuntimeStatusPending
 	}
 
+	// If the apply has rollout checks, they need to pass
+	// before we look at the pods.
+	if cond := s.rolloutCondition(); cond != nil && cond.Status != metav1.ConditionTrue {
+		if cond.Reason == v1alpha1.ApplyReasonRolloutTimeout {
+			return v1alpha1.RuntimeStatusError
+		}
+		return v1alpha1.RuntimeStatusPending
+	}
+
 	if s.PodReadinessMode == model.PodReadinessIgnore {
 		return v1alpha1.RuntimeStatusOK
 	}
@@ -140,7 +167,7 @@ func (s K8sRuntimeState) RuntimeStatus() v1alpha1.RuntimeStatus {
 	pod := s.MostRecentPod()
 	switch v1.PodPhase(pod.Phase) {
 	case v1.PodRunning:
-		if AllPodContainersReady(pod) && s.PodReadinessMode != model.PodReadinessSucceeded {
+		if PodContainersReady(pod, s.WaitForSidecars) && s.PodReadinessMode != model.PodReadinessSucceeded {
 			return v1alpha1.RuntimeStatusOK
 		}
 		return v1alpha1.RuntimeStatusPending
@@ -165,8 +192,9 @@ func (s K8sRuntimeState) HasEverBeenReadyOrSucceeded() bool {
 	if !s.HasEverDeployedSuccessfully {
 		return false
 	}
-	if s.PodReadinessMode == model.PodReadinessIgnore {
-		return true
+	if s.PodReadinessMode == model.PodReadinessIgnore && s.LastReadyOrSucceededTime.IsZero() {
+		cond := s.rolloutCondition()
+		return cond == nil || cond.Status == metav1.ConditionTrue
 	}
 	return !s.LastReadyOrSucceededTime.IsZero()
 }
@@ -248,6 +276,31 @@ func AllPodContainersReady(p v1alpha1.Pod) bool {
 	return true
 }
 
+// Like AllPodContainersReady, but skips service mesh sidecars
+// unless waitForSidecars is set.
+//
+// If the pod only has sidecars, we wait on them anyway.
+func PodContainersReady(p v1alpha1.Pod, waitForSidecars bool) bool {
+	if waitForSidecars {
+		return AllPodContainersReady(p)
+	}
+
+	hasAppContainer := false
+	for _, c := range p.Containers {
+		if k8s.IsServiceMeshSidecar(c.Name) {
+			continue
+		}
+		hasAppContainer = true
+		if !c.Ready {
+			return false
+		}
+	}
+	if !hasAppContainer {
+		return AllPodContainersReady(p)
+	}
+	return true
+}
+
 func AllPodContainerRestarts(p v1alpha1.Pod) int32 {
 	result := int32(0)
 	for _, c := range p.Containers {
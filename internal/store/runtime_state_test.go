@@ -0,0 +1,73 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestK8sRuntimeStatusIgnoresSidecars(t *testing.T) {
+	m := model.Manifest{Name: "k8s"}.WithDeployTarget(model.NewK8sTargetForTesting(""))
+	pod := v1alpha1.Pod{
+		Name:  "pod",
+		Phase: string(v1.PodRunning),
+		Containers: []v1alpha1.Container{
+			{Name: "app", Ready: true},
+			{Name: "istio-proxy", Ready: false},
+		},
+	}
+
+	state := NewK8sRuntimeStateWithPods(m, pod)
+	assert.Equal(t, v1alpha1.RuntimeStatusOK, state.RuntimeStatus())
+
+	state.WaitForSidecars = true
+	assert.Equal(t, v1alpha1.RuntimeStatusPending, state.RuntimeStatus())
+}
+
+func TestK8sRuntimeStatusWaitsForRollout(t *testing.T) {
+	m := model.Manifest{Name: "k8s"}.WithDeployTarget(model.NewK8sTargetForTesting(""))
+	pod := v1alpha1.Pod{
+		Name:       "pod",
+		Phase:      string(v1.PodRunning),
+		Containers: []v1alpha1.Container{{Name: "app", Ready: true}},
+	}
+
+	state := NewK8sRuntimeStateWithPods(m, pod)
+	state.Conditions = []metav1.Condition{{
+		Type:   v1alpha1.ApplyConditionRolloutComplete,
+		Status: metav1.ConditionFalse,
+		Reason: v1alpha1.ApplyReasonRolloutWaiting,
+	}}
+	assert.Equal(t, v1alpha1.RuntimeStatusPending, state.RuntimeStatus())
+
+	state.PodReadinessMode = model.PodReadinessIgnore
+	assert.False(t, state.HasEverBeenReadyOrSucceeded())
+
+	state.Conditions[0].Reason = v1alpha1.ApplyReasonRolloutTimeout
+	state.Conditions[0].Message = "timed out after 5m0s waiting for deployment/app: condition=Available"
+	assert.Equal(t, v1alpha1.RuntimeStatusError, state.RuntimeStatus())
+	assert.EqualError(t, state.RuntimeStatusError(),
+		"Rollout failed: timed out after 5m0s waiting for deployment/app: condition=Available")
+
+	state.Conditions[0].Status = metav1.ConditionTrue
+	state.Conditions[0].Reason = v1alpha1.ApplyReasonRolloutComplete
+	assert.Equal(t, v1alpha1.RuntimeStatusOK, state.RuntimeStatus())
+	assert.True(t, state.HasEverBeenReadyOrSucceeded())
+}
+
+func TestPodContainersReadySidecarOnly(t *testing.T) {
+	pod := v1alpha1.Pod{
+		Containers: []v1alpha1.Container{
+			{Name: "linkerd-proxy", Ready: false},
+		},
+	}
+	assert.False(t, PodContainersReady(pod, false))
+
+	pod.Containers[0].Ready = true
+	assert.True(t, PodContainersReady(pod, false))
+}
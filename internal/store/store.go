@@ -169,6 +169,7 @@ func (s *Store) Loop(ctx context.Context) error {
 					oldState = s.cheapCopyState()
 				}
 
+				s.state.recordRecentAction(action)
 				s.reduce(ctx, s.state, action)
 
 				if summarizer, ok := action.(Summarizer); ok {
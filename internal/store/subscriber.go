@@ -194,7 +194,7 @@ func (e *subscriberEntry) notify(ctx context.Context, store *Store) {
 	defer e.activeMu.Unlock()
 
 	activeChange := e.movePendingToActive()
-	err := e.subscriber.OnChange(ctx, store, *activeChange)
+	err := e.onChange(ctx, store, *activeChange)
 	if err == nil {
 		// Success! Finish immediately.
 		return
@@ -227,6 +227,16 @@ func (e *subscriberEntry) notify(ctx context.Context, store *Store) {
 	}
 }
 
+// A CrashRecoverable subscriber that panics is retried with backoff, like
+// any other error, so that one broken subscriber doesn't take down the whole
+// process.
+func (e *subscriberEntry) onChange(ctx context.Context, store *Store, summary ChangeSummary) (err error) {
+	if IsCrashRecoverable(e.subscriber) {
+		defer RecoverCrash(store, subscriberName(e.subscriber), &err)
+	}
+	return e.subscriber.OnChange(ctx, store, summary)
+}
+
 func (e *subscriberEntry) maybeSetUp(ctx context.Context, st RStore) error {
 	s, ok := e.subscriber.(SetUpper)
 	if ok {
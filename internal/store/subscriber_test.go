@@ -264,3 +264,62 @@ func TestSubscriberName(t *testing.T) {
 	require.Equal(t, "store.subscriberWithPointerReceiver", subscriberName(&subscriberWithPointerReceiver{}))
 	require.Equal(t, "store.subscriberWithNonPointerReceiver", subscriberName(subscriberWithNonPointerReceiver{}))
 }
+
+type panickingSubscriber struct {
+	calls chan struct{}
+}
+
+func (s *panickingSubscriber) CrashRecoverable() {}
+
+func (s *panickingSubscriber) OnChange(ctx context.Context, st RStore, summary ChangeSummary) error {
+	s.calls <- struct{}{}
+	panic("oh no")
+}
+
+func TestSubscriberPanic(t *testing.T) {
+	bs := newBlockingSleeper()
+	st, _ := NewStoreWithFakeReducer()
+	st.sleeper = bs
+
+	ctx := newCtx()
+	s := &panickingSubscriber{calls: make(chan struct{})}
+	require.NoError(t, st.AddSubscriber(ctx, s))
+
+	st.NotifySubscribers(ctx, ChangeSummary{Legacy: true})
+	<-s.calls
+
+	// The panic is reported as a crash, then retried like an error.
+	actions := <-st.actionCh
+	require.Len(t, actions, 1)
+	crash := actions[0].(CrashAction)
+	assert.Equal(t, "store.panickingSubscriber", crash.Component)
+	assert.Equal(t, "oh no", crash.Message)
+	assert.Contains(t, crash.Stack, "panickingSubscriber")
+
+	assert.Equal(t, time.Second, <-bs.SleepDur)
+	<-s.calls
+}
+
+// Panics while holding the state lock.
+type lockingPanickingSubscriber struct{}
+
+func (s lockingPanickingSubscriber) OnChange(ctx context.Context, st RStore, summary ChangeSummary) error {
+	_ = st.RLockState()
+	panic("oh no")
+}
+
+func TestSubscriberPanicNotRecoverable(t *testing.T) {
+	st, _ := NewStoreWithFakeReducer()
+
+	ctx := newCtx()
+	require.NoError(t, st.AddSubscriber(ctx, lockingPanickingSubscriber{}))
+	st.NotifySubscribers(ctx, ChangeSummary{Legacy: true})
+
+	// Recovering would leave the state locked, and deadlock the next reducer,
+	// so the panic stops Tilt instead.
+	actions := <-st.actionCh
+	require.Len(t, actions, 1)
+	panicAction, ok := actions[0].(PanicAction)
+	require.True(t, ok, "expected a PanicAction, got %T", actions[0])
+	assert.Contains(t, panicAction.Err.Error(), "PANIC: oh no")
+}
@@ -190,7 +190,7 @@ def docker_build(ref: str,
                  target: str = "",
                  ssh: Union[str, List[str]] = "",
                  network: str = "",
-                 secret: Union[str, List[str]] = "",
+                 secret: Union[str, List[str], Dict[str, Union[str, Blob]]] = "",
                  extra_tag: Union[str, List[str]] = "",
                  container_args: List[str] = None,
                  cache_from: Union[str, List[str]] = [],
@@ -232,9 +232,9 @@ def docker_build(ref: str,
     only: set of file paths that should be considered for the build. All other changes will not trigger a build and will not be included in images. Inverse of ignore parameter. Only accepts real paths, not file globs. Patterns will be evaluated relative to the ``context`` parameter.
     entrypoint: command to run when this container starts. Takes precedence over the container's ``CMD`` or ``ENTRYPOINT``, and over a `container command specified in k8s YAML <https://kubernetes.io/docs/tasks/inject-data-application/define-command-argument-container/>`_. If specified as a string, will be evaluated in a shell context (e.g. ``entrypoint="foo.sh bar"`` will be executed in the container as ``/bin/sh -c 'foo.sh bar'``); if specifed as a list, will be passed to the operating system as program name and args.
     target: Specify a build stage in the Dockerfile. Equivalent to the ``docker build --target`` flag.
-    ssh: Include SSH secrets in your build. Use ssh='default' to clone private repositories inside a Dockerfile. Uses the syntax in the `docker build --ssh flag <https://docs.docker.com/develop/develop-images/build_enhancements/#using-ssh-to-access-private-data-in-builds>`_.
+    ssh: Include SSH secrets in your build. Use ssh='default' to clone private repositories inside a Dockerfile. Uses the syntax in the `docker build --ssh flag <https://docs.docker.com/develop/develop-images/build_enhancements/#using-ssh-to-access-private-data-in-builds>`_. Key paths are relative to the Tiltfile, and may start with ``~``. Tilt checks that the keys exist (or, for ssh='default', that ``SSH_AUTH_SOCK`` is set) when the Tiltfile loads.
     network: Set the networking mode for RUN instructions. Equivalent to the ``docker build --network`` flag.
-    secret: Include secrets in your build in a way that won't show up in the image. Uses the same syntax as the `docker build --secret flag <https://docs.docker.com/develop/develop-images/build_enhancements/#new-docker-build-secret-information>`_.
+    secret: Include secrets in your build in a way that won't show up in the image. Uses the same syntax as the `docker build --secret flag <https://docs.docker.com/develop/develop-images/build_enhancements/#new-docker-build-secret-information>`_. Alternatively, a dict from secret id to a file path or the secret's contents (a :class:`Blob`, e.g., from :meth:`local`), like ``secret={'npmrc': '~/.npmrc', 'token': local('cat token', quiet=True, echo_off=True)}``. Tilt writes contents to a file that only you can read, and scrubs them from the logs. Tilt checks that each secret's file or env var exists when the Tiltfile loads.
     extra_tag: Tag an image with one or more extra references after each build. Useful when running Tilt in a CI pipeline, where you want each image to be tagged with the pipeline ID so you can find it later. Uses the same syntax as the ``docker build --tag`` flag.
     container_args: args to run when this container starts. Takes precedence over a `container args specified in k8s YAML <https://kubernetes.io/docs/tasks/inject-data-application/define-command-argument-container/>`_.
     cache_from: Cache image builds from a remote registry. Uses the same syntax as `docker build --cache-from flag <https://docs.docker.com/engine/reference/commandline/build/#specifying-external-cache-sources>`_.
@@ -243,7 +243,7 @@ def docker_build(ref: str,
   """
   pass
 
-def docker_compose(configPaths: Union[str, Blob, List[Union[str, Blob]]], env_file: str = None, project_name: str = "") -> None:
+def docker_compose(configPaths: Union[str, Blob, List[Union[str, Blob]]], env_file: str = None, project_name: str = "", profiles: Union[str, List[str]] = None, provider: str = "") -> None:
   """Run containers with Docker Compose.
 
   Tilt will read your Docker Compose YAML and separate out the services.
@@ -253,7 +253,40 @@ def docker_compose(configPaths: Union[str, Blob, List[Union[str, Blob]]], env_fi
 
   You can set up Docker Compose with a path to a file, a Blob containing Compose YAML, or a list of paths and/or Blobs.
 
-  Tilt will watch your Docker Compose YAML and reload if it changes.
+  Tilt will watch your Docker Compose YAML and reload if it changes. This includes
+  the files it refers to: ``env_file`` entries, ``extends`` files, ``include`` fragments,
+  and the ``file`` of each secret and config.
+
+  Docker Compose doesn't recreate a container when a secret or config changes, only when
+  the service's config does. So Tilt tracks the contents of each secret and config that a
+  service uses (from a ``file`` or an ``environment`` variable), and recreates only the
+  services that use one that changed.
+
+  Services can refer to the images they use with the same placeholders as
+  :meth:`k8s_yaml`, like ``$$(TILT_IMAGE_DIGEST:my-image)``. The extra ``$``
+  stops Docker Compose from treating the placeholder as an environment variable.
+
+  Tilt starts services in the order of their ``depends_on``, like ``docker compose up``.
+  A service waits for each dependency to reach its ``condition``: ``service_started``,
+  ``service_healthy``, or ``service_completed_successfully``. A service with a
+  ``healthcheck`` isn't ready until the healthcheck passes.
+  Services that don't depend on each other start in parallel, up to
+  ``max_parallel_updates`` (see :meth:`update_settings`).
+
+  Tilt shows a link in the UI for each TCP port that a service publishes. Ports bound to a
+  specific host IP link to that IP. To see a service's port bindings and the aliases it has on
+  each Docker network, run ``tilt get dockercomposeservice <name> -o yaml``.
+
+  A service can tell :meth:`live_update` how to reload itself with a ``tilt.restart-cmd`` label.
+  After each live update, Tilt runs the label's shell command in the container, after any
+  :meth:`run` steps, instead of restarting the container.
+
+  .. code-block:: yaml
+
+    services:
+      web:
+        labels:
+          tilt.restart-cmd: kill -HUP 1
 
   For more info, see `the guide to Tilt with Docker Compose <docker_compose.html>`_.
 
@@ -275,10 +308,33 @@ def docker_compose(configPaths: Union[str, Blob, List[Union[str, Blob]]], env_fi
     services = {'app': {'environment': {'DEBUG': 'true'}}}
     docker_compose(['docker-compose.yml', encode_yaml({'services': services})])
 
+    # Two separate Docker Compose projects
+    docker_compose('./frontend/docker-compose.yml', project_name='frontend')
+    docker_compose('./backend/docker-compose.yml', project_name='backend')
+
+    # Switch profiles at runtime with `tilt args -- --profiles=debug`
+    config.define_string_list('profiles')
+    cfg = config.parse()
+    docker_compose('./docker-compose.yml', profiles=cfg.get('profiles', []))
+
+  Calls without a ``project_name`` are merged into a single project. To run more than one
+  project, give each one a different ``project_name``. If two projects have a service with
+  the same name, the service from the later project is named ``<project_name>-<service>`` in Tilt.
+
   Args:
     configPaths: Path(s) and/or Blob(s) to Docker Compose yaml files or content.
     env_file: Path to env file to use; defaults to ``.env`` in current directory.
     project_name: The Docker Compose project name. If unspecified, the main Tiltfile's directory name is used.
+    profiles: `Compose profiles <https://docs.docker.com/compose/profiles/>`_ to activate. Only services
+      without profiles and services in an active profile become resources. Use ``'*'`` to activate
+      all profiles. If unspecified, every service becomes a resource, regardless of its profiles.
+      When the active profiles change, Tilt adds and removes resources to match.
+    provider: The compose implementation to run: ``'docker'`` (``docker compose``, falling back to
+      ``docker-compose``), ``'docker-compose'``, ``'podman'`` (``podman compose``, falling back to
+      ``podman-compose``), ``'nerdctl'`` (``nerdctl compose``), or a path to a compose binary. If unspecified,
+      Tilt uses the ``TILT_DOCKER_COMPOSE_PROVIDER`` environment variable, or ``'docker'``.
+      Tilt still inspects containers with the Docker API, so point ``DOCKER_HOST`` at your
+      provider's socket (e.g., ``unix:///run/user/1000/podman/podman.sock``).
   """
 
 
@@ -293,6 +349,20 @@ def k8s_yaml(yaml: Union[str, List[str], Blob], allow_duplicates: bool = False)
 
   Any YAML files are watched (See ``watch_file``).
 
+  A path may also be a directory, which loads every ``.yaml`` and ``.yml`` file
+  inside it and its subdirectories, or a glob pattern like ``deploy/**/*.yaml``. Matching files
+  are loaded in alphabetical order. Tilt watches the directory, so adding a
+  matching file later reloads the Tiltfile.
+
+  The YAML can refer to the images that its resource deploys with placeholders,
+  which Tilt fills in each time it applies the YAML:
+
+  - ``$(TILT_IMAGE_DIGEST:my-image)``: the image digest
+  - ``$(TILT_IMAGE_TAG:my-image)``: the tag that Tilt gave the image
+  - ``$(TILT_IMAGE_BUILD_TIME:my-image)``: when the build started, in RFC 3339 format
+
+  where ``my-image`` is the name passed to ``docker_build`` or ``custom_build``.
+
   Examples:
 
   .. code-block:: python
@@ -303,12 +373,18 @@ def k8s_yaml(yaml: Union[str, List[str], Blob], allow_duplicates: bool = False)
     # list of paths
     k8s_yaml(['foo.yaml', 'bar.yaml'])
 
+    # all YAML files in a directory
+    k8s_yaml('deploy')
+
+    # glob pattern
+    k8s_yaml('deploy/**/*.yaml')
+
     # Blob, i.e. `local` output (in this case, script output)
     templated_yaml = local('./template_yaml.sh')
     k8s_yaml(templated_yaml)
 
   Args:
-    yaml: Path(s) to YAML, or YAML as a ``Blob``.
+    yaml: Path(s) to YAML (files, directories, or glob patterns), or YAML as a ``Blob``.
     allow_duplicates: If you try to register the same Kubernetes
       resource twice, this function will assume this is a mistake and emit an error.
       Set allow_duplicates=True to allow duplicates. There are some Helm charts
@@ -341,6 +417,11 @@ def k8s_custom_deploy(name: str,
   output the YAML of the objects it applied to the Kubernetes cluster to stdout.
   Tilt will track workload status and stream pod logs based on this result.
 
+  Tilt fingerprints the contents of the files in ``deps`` (along with the command,
+  its images, and the cluster). If none of them changed since the last successful
+  apply, Tilt skips the ``apply_cmd`` and keeps the objects from the last run.
+  Triggering the resource from the UI or with ``tilt trigger`` always re-runs it.
+
   The ``delete_cmd`` is run on ``tilt down`` so that the tool can clean up any
   objects it created in the cluster as well as any state of its own.
 
@@ -437,7 +518,8 @@ def dc_resource(name: str,
     name: The name of the resource in the docker-compose yaml.
     trigger_mode: one of ``TRIGGER_MODE_AUTO`` or ``TRIGGER_MODE_MANUAL``. For more info, see the
       `Manual Update Control docs <manual_update_control.html>`_.
-    resource_deps: a list of resources on which this resource depends.
+    resource_deps: a list of resources on which this resource depends. These can be
+      Kubernetes or local resources, too: the service won't start until they're ready.
       See the `Resource Dependencies docs <resource_dependencies.html>`_.
     links: one or more links to be associated with this resource in the UI. For more info, see
       `Accessing Resource Endpoints <accessing_resource_endpoints.html#arbitrary-links>`_.
@@ -448,6 +530,62 @@ def dc_resource(name: str,
 
   pass
 
+class DCProject:
+  """A Docker Compose project, as Docker Compose resolved it: with variables interpolated,
+  ``extends`` and ``include`` merged, and ``profiles`` applied.
+
+  Attributes:
+    name (str): The project name
+    project_path (str): The project's working directory
+    config_paths (List[str]): The Compose files of the project
+    profiles (List[str]): The profiles that the Tiltfile selected with ``docker_compose(profiles=...)``
+    services (Dict[str, DCService]): The services of the project, by service name. See `DCService`.
+  """
+  pass
+
+class DCService:
+  """A service of a Docker Compose project.
+
+  Attributes:
+    name (str): The name of the service in the Compose file
+    resource_name (str): The name of the Tilt resource for the service. Differs from ``name`` if another project has a service with the same name.
+    image (str): The image that the service runs (e.g., ``"redis:7"``). For services with a ``build`` but no ``image``, the image that Docker Compose names after the project and the service.
+    build (Optional[DCBuild]): How Docker Compose builds the image, or ``None``. Has ``context``, ``dockerfile``, ``target``, and ``args`` attributes.
+    ports (List[DCPort]): The published ports. Each has ``target`` (int), ``published`` (str), ``protocol``, and ``host_ip`` attributes.
+    volumes (List[DCVolume]): The volumes. Each has ``type``, ``source``, ``target``, and ``read_only`` attributes. The ``source`` of a bind mount is an absolute path.
+    profiles (List[str]): The profiles that the service belongs to
+    depends_on (List[str]): The services that the service depends on
+    environment (Dict[str, Optional[str]]): The environment variables. Variables without a value are ``None``.
+    labels (Dict[str, str]): The container labels
+  """
+  pass
+
+def dc_project(project_name: str = "") -> DCProject:
+  """Returns the Docker Compose project that :meth:`docker_compose` loaded.
+
+  Use this to configure resources from the Compose model, instead of parsing
+  the Compose files yourself. The project is read-only: to change a service, call
+  :meth:`dc_resource`, :meth:`docker_build`, or :meth:`docker_compose` with an override file.
+
+  Fails if :meth:`docker_compose` hasn't loaded the project yet.
+
+  Example ::
+
+    docker_compose('docker-compose.yml')
+    project = dc_project()
+    for svc in project.services.values():
+      if svc.build:
+        docker_build(svc.image, svc.build.context,
+                     live_update=[sync(v.source, v.target) for v in svc.volumes if v.type == 'bind'])
+      if 'backend' in svc.profiles:
+        dc_resource(svc.resource_name, labels=['backend'])
+
+  Args:
+    project_name: The project to return, if you loaded more than one with ``docker_compose(project_name=...)``.
+      Defaults to the project that ``docker_compose`` loaded without a ``project_name``.
+  """
+  pass
+
 def k8s_resource(workload: str = "", new_name: str = "",
                  port_forwards: Union[str, int, PortForward, List[Union[str, int, PortForward]]] = [],
                  extra_pod_selectors: Union[Dict[str, str], List[Dict[str, str]]] = [],
@@ -457,7 +595,16 @@ def k8s_resource(workload: str = "", new_name: str = "",
                  pod_readiness: str = "",
                  links: Union[str, Link, List[Union[str, Link]]]=[],
                  labels: Union[str, List[str]] = [],
-                 discovery_strategy: str = "") -> None:
+                 discovery_strategy: str = "",
+                 wait_for_sidecars: bool = False,
+                 env: Dict[str, str] = {},
+                 reset_volumes: Union[str, List[str]] = [],
+                 prune: bool = False,
+                 gitops_handoff: bool = False,
+                 hostnames: Union[str, List[str]] = [],
+                 common_labels: Dict[str, str] = {},
+                 common_annotations: Dict[str, str] = {},
+                 cluster: str = "") -> None:
   """
 
   Configures or creates the specified Kubernetes resource.
@@ -537,7 +684,9 @@ def k8s_resource(workload: str = "", new_name: str = "",
       Tilt's usual mechanisms).
     trigger_mode: One of ``TRIGGER_MODE_AUTO`` or ``TRIGGER_MODE_MANUAL``. For more info, see the
       `Manual Update Control docs <manual_update_control.html>`_.
-    resource_deps: A list of resources on which this resource depends.
+    resource_deps: A list of resources on which this resource depends. These can be
+      Docker Compose services, too: a service is ready once its container is running
+      and passes its healthcheck, if it has one.
       See the `Resource Dependencies docs <resource_dependencies.html>`_.
     objects: A list of Kubernetes objects to be added to this resource, specified via
       Tilt's `Kubernetes Object Selector <tiltfile_concepts.html#kubernetes-object-selectors>`_
@@ -556,6 +705,80 @@ def k8s_resource(workload: str = "", new_name: str = "",
       `Accessing Resource Endpoints <accessing_resource_endpoints.html#arbitrary-links>`_.
     labels: used to group resources in the Web UI, (e.g. you want all frontend services displayed together, while test and backend services are displayed seperately). A label must start and end with an alphanumeric character, can include ``_``, ``-``, and ``.``, and must be 63 characters or less. For an example, see `Resource Grouping <tiltfile_concepts.html#resource-groups>`_.
     discovery_strategy: Possible values: '', 'default', 'selectors-only'. When '' or 'default', Tilt both uses `extra_pod_selectors` and traces k8s owner references to identify this resource's pods. When 'selectors-only', Tilt uses only `extra_pod_selectors`.
+    wait_for_sidecars: By default, service mesh sidecars (``istio-proxy`` and ``linkerd-proxy``) don't
+      block pod readiness. Set this to True if your app needs the mesh before it can serve.
+      Regardless of this setting, Tilt never picks sidecars for live update by matching images.
+    env: Environment variables to set on every container in this resource's workloads,
+      e.g., ``env={'FEATURE_X': 'true'}``. Overrides any variable with the same name in the YAML,
+      so you can toggle feature flags without editing it. If you call ``k8s_resource`` more
+      than once, the variables are merged. Not supported for resources created with
+      :meth:`k8s_custom_deploy`.
+    reset_volumes: Names of PersistentVolumeClaims that Tilt may delete to reset this resource
+      to a clean slate (e.g., to wipe a database). A name can also be a ``volumeClaimTemplate``
+      of a StatefulSet, to match all the claims created from the template. Adds a "Reset Volumes"
+      button to the resource in the web UI. The button scales the resource's workloads down,
+      deletes and recreates the claims, and scales the workloads back up. Claims that aren't
+      listed here are never deleted.
+    prune: If True, Tilt deletes objects from the cluster when you remove them from
+      this resource's YAML, even if they were applied before Tilt restarted. Tilt finds them
+      by an annotation it adds when applying, which names this project and resource, so only
+      objects this resource applied with ``prune=True`` are deleted. Tilt never prunes Namespaces, PersistentVolumes, or PersistentVolumeClaims.
+      Not supported for resources created with :meth:`k8s_custom_deploy`.
+    gitops_handoff: If True, Tilt takes over this resource's objects from the GitOps tool
+      that manages them in the cluster, so that you can develop a service in a GitOps-managed
+      cluster. After each deploy, Tilt suspends reconciliation of objects that Flux manages
+      (with the ``kustomize.toolkit.fluxcd.io/reconcile: disabled`` or
+      ``helm.toolkit.fluxcd.io/driftDetection: disabled`` annotation) and of the Argo CD
+      Applications that manage the others (with the ``argocd.argoproj.io/skip-reconcile``
+      annotation). On ``tilt down``, Tilt restores the annotations instead of deleting those
+      objects, and the GitOps tool reverts them to their declared state. Argo CD objects are
+      found by their ``argocd.argoproj.io/tracking-id`` annotation. Not supported for
+      resources created with :meth:`k8s_custom_deploy`.
+    hostnames: Friendly local hostnames that proxy to this resource's first port forward over
+      HTTPS, e.g., ``hostnames='shop.localhost'`` serves ``https://shop.localhost:10443/``.
+      Add a path to share a hostname between resources, e.g., ``'shop.localhost/api'``. The URL
+      stays the same when pods restart. Certificates are signed by a local CA that Tilt creates;
+      trust it to avoid browser warnings. Requires ``port_forwards``. See
+      :meth:`v1alpha1.local_ingress` for more control.
+    common_labels: Labels to add to every object applied for this resource, including pod
+      templates, e.g., ``common_labels={'team': 'payments'}``. Useful for cluster cost and
+      ownership tooling. Overrides labels with the same key in the YAML. Keys with the
+      ``tilt.dev/`` prefix are reserved. Not supported for resources created with
+      :meth:`k8s_custom_deploy`.
+    common_annotations: Annotations to add to every object applied for this resource, including
+      pod templates, e.g., ``common_annotations={'example.com/ticket': 'PAY-123'}``. Same rules
+      as ``common_labels``.
+    cluster: The name of a cluster declared with :meth:`k8s_cluster` to deploy this resource to.
+      Defaults to the cluster of the current kubeconfig context.
+  """
+  pass
+
+def k8s_cluster(name: str, context: str, namespace: str = "", default_registry: str = "") -> None:
+  """Declares an additional Kubernetes cluster that resources can deploy to.
+
+  By default, Tilt deploys all Kubernetes resources to the cluster of the current
+  kubeconfig context. Declare more clusters to deploy some resources elsewhere,
+  then assign resources to them with ``k8s_resource(cluster=...)``. For example:
+
+  .. code-block:: python
+
+    k8s_cluster('edge', context='kind-edge')
+    k8s_yaml('edge-proxy.yaml')
+    k8s_resource('edge-proxy', cluster='edge')
+
+  Tilt connects to each cluster separately, and watches its pods, logs, and events.
+  Images are pushed to the registry of the cluster that the resource deploys to.
+  An image can only be deployed to one cluster.
+
+  ``allow_k8s_contexts`` doesn't apply to these clusters, because you name their context
+  explicitly.
+
+  Args:
+    name: The name of the cluster, used in ``k8s_resource(cluster=...)``. Can't be ``default`` or ``docker``.
+    context: The kubeconfig context to connect to the cluster with.
+    namespace: The namespace for objects without a namespace. Defaults to the namespace of the context.
+    default_registry: The host of the registry to push images to, if the cluster doesn't advertise
+      a local registry. Images are renamed to use it, like with :meth:`default_registry`.
   """
   pass
 
@@ -681,6 +904,38 @@ def local(command: Union[str, List[str]],
   """
   pass
 
+class LocalProcess:
+  """A command started by :meth:`local_async`."""
+
+  def wait(self) -> Blob:
+    """Waits for the command to finish and returns its stdout as a ``Blob``.
+
+    Fails the Tiltfile if the command failed."""
+    pass
+
+def local_async(command: Union[str, List[str]],
+                quiet: bool = False,
+                command_bat: Union[str, List[str]] = "",
+                echo_off: bool = False,
+                env: Dict[str, str] = {},
+                dir: str = "",
+                stdin: Union[str, Blob, None] = None) -> LocalProcess:
+  """Starts a command on the *host* machine without waiting for it to finish.
+
+  Takes the same arguments as :meth:`local`. Use it to run slow setup commands in parallel.
+
+  Example ::
+
+    deps = local_async('make deps')
+    certs = local_async('./gen-certs.sh')
+    deps.wait()
+    print(certs.wait())
+
+  If a command fails and you never call ``wait()``, the Tiltfile fails after it finishes executing.
+  If the Tiltfile fails, Tilt kills any commands that are still running.
+  """
+  pass
+
 def read_file(file_path: str, default: str = None) -> Blob:
   """
   Reads file and returns its contents.
@@ -745,7 +1000,7 @@ def listdir(directory: str, recursive: bool = False) -> List[str]:
   """
   pass
 
-def k8s_kind(kind: str, api_version: str=None, *, image_json_path: Union[str, List[str]]=[], image_object_json_path: Dict=None, pod_readiness: str=""):
+def k8s_kind(kind: str, api_version: str=None, *, image_json_path: Union[str, List[str]]=[], image_object_json_path: Dict=None, pod_readiness: str="", wait_for: str="", wait_timeout: str="5m"):
   """Tells Tilt about a k8s kind.
 
   For CRDs that use images built by Tilt: call this with `image_json_path` or
@@ -765,6 +1020,12 @@ def k8s_kind(kind: str, api_version: str=None, *, image_json_path: Union[str, Li
   Custom Resource
   <https://github.com/tilt-dev/tilt/blob/master/integration/crd/Tiltfile#L8>`_.
 
+  To hold off dependent resources until objects of a kind have fully rolled out,
+  pass `wait_for` ::
+
+    k8s_kind('Deployment', wait_for='condition=Available')
+    k8s_kind('Database', wait_for='jsonpath={.status.phase}=Ready', wait_timeout='10m')
+
   Args:
     kind: Case-insensitive regexp specifying he value of the `kind` field in the k8s object definition (e.g., `"Deployment"`)
     api_version: Case-insensitive regexp specifying the apiVersion for `kind`, (e.g., "apps/v1")
@@ -778,6 +1039,12 @@ def k8s_kind(kind: str, api_version: str=None, *, image_json_path: Union[str, Li
       can start building). By default, Tilt will wait for pods to be ready if it
       thinks a resource has pods. This can be overridden on a resource-by-resource basis
       by the `k8s_resource` function.
+    wait_for: A rollout check, in the syntax of `kubectl wait --for`. Either
+      `condition=[type]`, to wait for a status condition to be True, or
+      `jsonpath={path}=[value]`, to wait for a field to have a value. Until every
+      object of this kind in a resource passes the check, the resource is pending.
+    wait_timeout: How long to wait for the check to pass after each deploy, e.g., `'10m'`.
+      If it doesn't pass in time, the resource is in an error state. Defaults to 5m.
 
   """
   pass
@@ -910,6 +1177,63 @@ def default_registry(host: str, host_from_cluster: str = None, single_name: str
   """
   pass
 
+def buildkit_builder(address: str, ca_cert: str = "", cert: str = "", key: str = "", server_name: str = "") -> None:
+  """Builds the images for ``docker_build`` on a remote BuildKit daemon, instead of the local Docker daemon.
+
+  Tilt sends the daemon the build context with BuildKit's file sync protocol, so each build only
+  transfers the files that changed. The build output streams into the resource's logs, like a local build.
+
+  BuildKit pushes each image to the registry as part of the build, so you'll usually want a
+  ``default_registry`` that both the daemon and your cluster can reach. Images are tagged with the
+  build time, and deployed by digest.
+
+  Only supported for Kubernetes. ``custom_build`` images are still built by their own commands.
+
+  Examples:
+
+  .. code-block:: python
+
+    # A buildkitd Deployment in the cluster, reached through `kubectl exec`,
+    # like the kubernetes driver of `docker buildx`.
+    buildkit_builder('kube-pod://buildkitd-0?namespace=buildkit')
+
+    # A shared builder that requires mutual TLS.
+    buildkit_builder('tcp://buildkit.example.com:1234',
+                     ca_cert='certs/ca.pem', cert='certs/cert.pem', key='certs/key.pem')
+
+  Args:
+    address: where to find the daemon. ``tcp://host:port`` and ``unix:///path/to/buildkitd.sock`` connect directly.
+      ``kube-pod://pod-name`` runs ``buildctl dial-stdio`` in the pod, and accepts ``namespace``, ``container``,
+      and ``context`` query parameters. The context defaults to the cluster's.
+    ca_cert: path to the CA certificate that signed the daemon's certificate. If set, Tilt connects over TLS.
+    cert: path to the client certificate, for daemons that require mutual TLS.
+    key: path to the client key, for daemons that require mutual TLS.
+    server_name: the name to verify the daemon's certificate against. Defaults to the host in ``address``.
+  """
+  pass
+
+def container_engine(engine: str, host: str = "") -> None:
+  """Picks the container engine that builds images and runs Docker Compose services for this project.
+
+  By default, Tilt uses the Docker daemon from your environment (``DOCKER_HOST``, or your Docker CLI context).
+  If your team can't install Docker Desktop, you can build and run on Podman instead:
+
+  .. code-block:: python
+
+    container_engine('podman')
+
+  Tilt talks to Podman through its Docker-compatible API socket, and works around the gaps in that API:
+  images are built without BuildKit, ``docker_compose`` projects run with ``podman compose``
+  unless they set their own ``provider``, and Docker Prune skips the build cache, which Podman stores as images.
+
+  Args:
+    engine: ``'docker'`` or ``'podman'``.
+    host: the address of the engine's API socket, like ``unix:///run/podman/podman.sock``. For Podman,
+      defaults to ``CONTAINER_HOST``, then the socket of your Podman machine or the ``podman.socket``
+      systemd unit. For Docker, defaults to the Docker host from your environment.
+  """
+  pass
+
 def custom_build(
     ref: str,
     command: Union[str, List[str]],
@@ -962,6 +1286,8 @@ def custom_build(
     outputs_image_ref_to: Specifies a file path. When set, the custom build command must write a content-based
       tagged image ref to this file. Tilt will read that file after the cmd runs to get the image ref,
       and inject that image ref into the YAML. For more on content-based tags, see <custom_build.html#why-tilt-uses-immutable-tags>_
+      The ref may also pin a digest (e.g., ``gcr.io/foo/bar@sha256:...``), as written by builders like ko
+      or rules_oci. Digest refs usually go with ``skips_local_docker=True``.
     command_bat: If non-empty and on Windows, takes precedence over ``command``. Ignored on other platforms.
       If a string, executed as a Windows batch command executed with ``cmd /S /C``; if a list, will be passed to
       the operating system as program name and args.
@@ -977,6 +1303,94 @@ def custom_build(
   pass
 
 
+def oci_artifact(
+    ref: str,
+    files: List[str],
+    command: Union[str, List[str]] = "",
+    deps: List[str] = [],
+    artifact_type: str = "",
+    media_type: str = "",
+    command_bat: Union[str, List[str]] = "",
+    match_in_env_vars: bool = False,
+    ignore: Union[str, List[str]] = []) -> None:
+  """Package files as an OCI artifact and push it to the registry.
+
+  OCI artifacts are things like WASM modules or Helm charts that live in a
+  container registry but aren't runnable images. Tilt pushes the artifact
+  with a content-based tag, and injects that tag into any resources that
+  reference ``ref``, so they redeploy when the artifact changes.
+
+  Example ::
+
+    oci_artifact(
+      'gcr.io/my-project/filter',
+      ['build/filter.wasm'],
+      command='make build/filter.wasm',
+      deps=['src'],
+      artifact_type='application/vnd.wasm.config.v1+json',
+    )
+
+  Tilt pushes directly to the registry, using the credentials in your Docker
+  config. It does not use your local Docker image store.
+
+  Args:
+    ref: name for this artifact (e.g. 'myregistry/myproj/filter'). Resources that reference this name get the tagged ref injected, like any other image.
+    files: the files to package. Each file becomes one layer of the artifact, annotated with its file name.
+    command: an optional command that produces ``files``. If a string, executed with ``sh -c`` on macOS/Linux,
+      or ``cmd /S /C`` on Windows; if a list, will be passed to the operating system as program name and args.
+    deps: a list of files or directories that Tilt watches to rebuild the artifact. Defaults to ``files``. Required if ``command`` is set.
+    artifact_type: the media type of the artifact config, which tools use to identify the kind of artifact. Defaults to ``application/vnd.unknown.config.v1+json``.
+    media_type: the media type of each layer. Defaults to ``application/vnd.oci.image.layer.v1.tar``.
+    command_bat: If non-empty and on Windows, takes precedence over ``command``. Ignored on other platforms.
+    match_in_env_vars: specifies that k8s objects can reference this artifact in their environment variables.
+    ignore: set of file patterns in ``deps`` that will not trigger builds. Follows the `dockerignore syntax <https://docs.docker.com/engine/reference/builder/#dockerignore-file>`_.
+  """
+  pass
+
+
+def ko_build(
+    ref: str,
+    importpath: str,
+    deps: List[str] = [],
+    base_image: str = "",
+    build_flags: Union[str, List[str]] = [],
+    env: Dict[str, str] = {},
+    platform: str = "",
+    live_update: List[LiveUpdateStep] = [],
+    match_in_env_vars: bool = False,
+    ignore: Union[str, List[str]] = []) -> None:
+  """Build a Go service the way `ko <https://ko.build/>`_ does, without a Dockerfile.
+
+  Tilt compiles the binary on your machine with ``go build``, then layers it
+  onto a minimal base image at ``/ko-app/<name>``. If the main package has a
+  ``kodata`` directory, it's copied into the image at ``/var/run/ko``.
+
+  Example ::
+
+    ko_build(
+      'gcr.io/my-project/frontend',
+      './cmd/frontend',
+      deps=['./cmd', './pkg'],
+    )
+
+  The image is built and pushed (or loaded into your cluster) like any
+  image built by :meth:`docker_build`, so you don't need to install ko.
+
+  Args:
+    ref: name for this image (e.g. 'myproj/frontend' or 'myregistry/myproj/frontend'). Resources that reference this name get the built image injected.
+    importpath: the Go package to build, relative to the Tiltfile (e.g., ``./cmd/frontend``) or a full import path in the current module.
+    deps: a list of files or directories that Tilt watches to rebuild the image. Defaults to the directory of the Tiltfile.
+    base_image: the image to put the binary on. Defaults to ``cgr.dev/chainguard/static``. If this image is also built by Tilt, it's built first.
+    build_flags: extra flags to pass to ``go build`` (e.g., ``['-tags=netgo', '-ldflags=-s -w']``).
+    env: extra environment variables to set when running ``go build``. The binary is always built with ``CGO_ENABLED=0``.
+    platform: the platform to build for (e.g., ``linux/arm64``). Defaults to the platform of your cluster.
+    live_update: set of steps for updating a running container (see `Live Update documentation <live_update_reference.html>`_). Since the binary is compiled on your machine, ``sync`` it to ``/ko-app`` after a ``local_resource`` rebuilds it.
+    match_in_env_vars: specifies that k8s objects can reference this image in their environment variables, and Tilt will handle those variables the same as it usually handles a k8s container spec's ``image`` s.
+    ignore: set of file patterns in ``deps`` that will not trigger builds. Follows the `dockerignore syntax <https://docs.docker.com/engine/reference/builder/#dockerignore-file>`_.
+  """
+  pass
+
+
 class K8sObjectID:
   """
   Attributes:
@@ -1022,7 +1436,57 @@ def k8s_context() -> str:
   """
   pass
 
-def allow_k8s_contexts(contexts: Union[str, List[str]]) -> None:
+class K8sClusterInfo:
+  """
+  Attributes:
+    server_version (str): The apiserver's version (e.g., `"v1.25.3"`)
+    api_versions (List[str]): The API versions that the cluster serves, in the same format as `kubectl api-versions` (e.g., `"apps/v1"`, `"monitoring.coreos.com/v1"`)
+    architectures (List[str]): The CPU architectures of the cluster's nodes (e.g., `["amd64", "arm64"]`). Empty if you don't have permission to list nodes.
+    is_local (bool): Whether the cluster is a known local development cluster (like Kind, Minikube, or Docker Desktop)
+    product (str): The kind of cluster Tilt detected (e.g., `"kind"`, `"minikube"`, `"gke"`, or `"unknown"`)
+    context (str): The name of the Kubernetes context
+    local_registry (Optional[K8sLocalRegistry]): The local registry that the cluster advertises, or `None`. See `K8sLocalRegistry`.
+  """
+  pass
+
+class K8sLocalRegistry:
+  """A local registry that the cluster advertises, so that tools can push images that the cluster can pull.
+
+  Tilt discovers the registry from the `local-registry-hosting ConfigMap <https://github.com/kubernetes/enhancements/tree/master/keps/sig-cluster-lifecycle/generic/1755-communicating-a-local-registry>`_
+  that Kind, k3d, and minikube set up, and pushes images there even if you don't call `default_registry`.
+
+  Attributes:
+    host (str): The host that tools outside the cluster push to (e.g., `"localhost:5000"`)
+    host_from_cluster_network (str): The host that containers in the cluster use to reach the registry. May be empty.
+    host_from_container_runtime (str): The host that the cluster's container runtime pulls from. May be empty.
+    help (str): A URL with instructions for setting up the registry. May be empty.
+  """
+  pass
+
+def k8s_cluster_info() -> K8sClusterInfo:
+  """Returns information about the Kubernetes cluster Tilt is connecting to.
+
+  Use this to adapt your Tiltfile to the cluster, like skipping objects whose CRDs aren't installed.
+
+  Tilt queries the cluster once per Tiltfile load. Fails if Tilt can't connect to the cluster.
+
+  Example ::
+
+    if 'monitoring.coreos.com/v1' in k8s_cluster_info().api_versions:
+      k8s_yaml('service-monitor.yaml')
+
+    if k8s_cluster_info().architectures == ['arm64']:
+      docker_build('app', '.', platform='linux/arm64')
+
+    reg = k8s_cluster_info().local_registry
+    if reg:
+      local('./push-charts.sh %s' % reg.host)
+  """
+  pass
+
+def allow_k8s_contexts(contexts: Union[str, List[str]] = [],
+                       patterns: Union[str, List[str]] = [],
+                       allowlist_file: str = "") -> None:
   """Specifies that Tilt is allowed to run against the specified k8s context names.
 
   To help reduce the chances you accidentally use Tilt to deploy to your
@@ -1044,12 +1508,25 @@ def allow_k8s_contexts(contexts: Union[str, List[str]]) -> None:
     allow_k8s_contexts(k8s_context())
     local('./validate-dev-cluster.sh')
 
+  Platform teams can manage the list centrally with an allowlist file, instead of
+  editing every Tiltfile. The file is YAML with a list of context names and a list of patterns::
+
+    contexts:
+    - my-staging-cluster
+    patterns:
+    - dev-.*
+
+  The file is watched, so editing it re-runs the Tiltfile. If it doesn't exist, it allows nothing.
+
   For more on which cluster context is right for you, see `Choosing a Local Dev Cluster <choosing_clusters.html>`_.
 
   Args:
     contexts: a string or list of strings, specifying one or more k8s context
         names that Tilt is allowed to run in. This list is in addition to
         the default of all known-local clusters.
+    patterns: a string or list of strings, specifying regular expressions for k8s context
+        names that Tilt is allowed to run in. A pattern must match the whole context name.
+    allowlist_file: path to a YAML file with more ``contexts`` and ``patterns`` to allow.
 
   Example ::
 
@@ -1062,6 +1539,38 @@ def allow_k8s_contexts(contexts: Union[str, List[str]]) -> None:
 
     allow_k8s_contexts(k8s_context()) # disable check
 
+    allow_k8s_contexts(patterns='gke_my-project-name_dev-.*')
+
+    allow_k8s_contexts(allowlist_file='/etc/tilt/allowed-contexts.yaml')
+
+  """
+  pass
+
+def allow_k8s_namespaces(namespaces: Union[str, List[str]]) -> None:
+  """Specifies namespaces that your YAML may deploy to without asking first.
+
+  YAML copied from another project sometimes hardcodes that project's namespace.
+  So before Tilt applies YAML to a namespace that your project hasn't deployed to
+  before, it waits for you to approve it, with the resource's "Approve Namespaces"
+  button or ``tilt approve-namespaces RESOURCE_NAME``. Tilt remembers approvals
+  for each project.
+
+  These namespaces never need approval:
+
+  - The default namespace of your kubeconfig context.
+  - Namespaces that your YAML creates.
+  - Namespaces passed to ``allow_k8s_namespaces``.
+
+  ``tilt ci`` doesn't ask.
+
+  Args:
+    namespaces: a string or list of strings, specifying namespaces
+        that your YAML may deploy to.
+
+  Example ::
+
+    allow_k8s_namespaces(['monitoring', 'cert-manager'])
+
   """
   pass
 
@@ -1083,6 +1592,30 @@ def enable_feature(feature_name: str) -> None:
   """
   pass
 
+def features(enable: List[str] = [], disable: List[str] = []) -> Dict[str, bool]:
+  """Enables and disables several feature flags at once, and returns the value of every
+  feature flag that Tilt still uses.
+
+  Like :meth:`enable_feature`, this is for early access to new behaviors before they're
+  on by default. Tilt rolls some features out gradually: a feature might be on for some
+  projects before it's on for everyone. Use ``disable`` to opt out of a feature while it's
+  rolling out.
+
+  The Tilt UI's session status reports each flag, and whether its value came from the
+  Tiltfile, a rollout, or the default.
+
+  .. code-block:: python
+
+    flags = features(disable=['cluster_refresh'])
+    if flags['snapshots']:
+      print('Snapshots are on')
+
+  Args:
+    enable: names of the features to enable
+    disable: names of the features to disable
+  """
+  pass
+
 def local_resource(name: str,
                    cmd: Union[str, List[str]],
                    deps: Union[str, List[str]] = None,
@@ -1140,13 +1673,108 @@ def local_resource(name: str,
     links: one or more links to be associated with this resource in the Web UI (e.g. perhaps you have a "reset database" workflow and want to attach a link to the database web console). Provide one or more strings (the URLs to link to) or :class:`~api.Link` objects.
     env: Environment variables to pass to the executed ``cmd``. Values specified here will override any variables passed to the Tilt parent process.
     serve_env: Environment variables to pass to the executed ``serve_cmd``. Values specified here will override any variables passed to the Tilt parent process.
-    readiness_probe: Optional readiness probe to use for determining ``serve_cmd`` health state. Fore more info, see the :meth:`probe` function.
+    readiness_probe: Optional readiness probe to use for determining ``serve_cmd`` health state. The resource is only ready while the probe passes, and becomes unready whenever ``serve_cmd`` exits or restarts. For more info, see the :meth:`probe` function.
     dir: Working directory for ``cmd``. Defaults to the Tiltfile directory.
     serve_dir: Working directory for ``serve_cmd``. Defaults to the Tiltfile directory.
     labels: used to group resources in the Web UI, (e.g. you want all frontend services displayed together, while test and backend services are displayed seperately). A label must start and end with an alphanumeric character, can include ``_``, ``-``, and ``.``, and must be 63 characters or less. For an example, see `Resource Grouping <tiltfile_concepts.html#resource-groups>`_.
   """
   pass
 
+def external_resource(name: str,
+                      url: str,
+                      health_check: Probe = None,
+                      resource_deps: List[str] = [],
+                      links: Union[str, Link, List[Union[str, Link]]] = [],
+                      labels: List[str] = [],
+                      auto_init: bool = True) -> None:
+  """Declares a service that Tilt doesn't deploy, like a shared staging API or a SaaS dependency,
+  so that the Web UI and ``resource_deps`` reflect the full topology of your dev environment.
+
+  Tilt polls the health of the service. Resources that list it in ``resource_deps`` wait until
+  it has been healthy at least once, just like they would for a ``local_resource`` with a
+  ``readiness_probe``. Example ::
+
+    external_resource('payments-api', 'https://payments.staging.example.com/healthz')
+    k8s_resource('checkout', resource_deps=['payments-api'])
+
+  By default, Tilt checks ``http://`` and ``https://`` URLs with a GET request, and other URLs
+  with a port (like ``postgres://db.internal:5432``) by opening a TCP connection. Other URLs
+  are always considered healthy.
+
+  Args:
+    name: the name of the resource.
+    url: the URL of the service. Shown as a link in the Web UI.
+    health_check: Optional probe to use instead of the default health check. For more info, see the :meth:`probe` function.
+    resource_deps: a list of resources on which this resource depends.
+      See the `Resource Dependencies docs <resource_dependencies.html>`_.
+    links: additional links to show in the Web UI.
+    labels: used to group resources in the Web UI.
+    auto_init: whether Tilt starts checking the health of the service on ``tilt up``.
+  """
+  pass
+
+def group(name: str, resources: Union[str, List[str]]) -> None:
+  """Puts resources in a nested group, for Tiltfiles with too many resources for flat labels.
+
+  Group names are paths like ``team/service/component``. In the Web UI, each group is shown
+  as a collapsible section, with its nested groups as collapsible sections inside it.
+
+  Groups can also be used to choose which resources to run. Passing a group name where Tilt
+  expects a resource name (e.g., ``tilt up payments``, or to :meth:`config.set_enabled_resources`)
+  selects every resource in that group and the groups nested under it.
+
+  Each resource can only be in one group. It's an error to put a resource that doesn't exist in a group.
+
+  Example ::
+
+    group('payments/api', ['payments-api', 'payments-db'])
+    group('payments/jobs', 'payments-worker')
+
+  Args:
+    name: the group path. Each segment must start and end with an alphanumeric character and can include ``_`` and ``-``. The whole path must be 63 characters or less.
+    resources: the name of a resource, or a list of resource names, to put in the group.
+  """
+  pass
+
+def external_trigger(name: str, resources: Union[str, List[str]], watch_object: str = "", watch_field: str = "") -> None:
+  """Lets systems outside of Tilt trigger resources to update, like a schema registry
+  that publishes a new version.
+
+  Firing the trigger queues a full update of every resource that uses it, just like clicking
+  the trigger button in the Web UI.
+
+  Other systems can fire the trigger with a POST request to ``/api/triggers/<name>`` on the Tilt
+  server. The request body is ignored, so the URL can be used directly as a webhook. Example ::
+
+    external_trigger('schema', ['api', 'worker'])
+
+  and then ::
+
+    curl -X POST http://localhost:10350/api/triggers/schema
+
+  A trigger can also watch a field of an object in the Tilt API, and fire whenever
+  the field changes. Example ::
+
+    external_trigger('schema', ['api', 'worker'],
+                     watch_object='configmap/schema-version',
+                     watch_field='{.data.version}')
+
+  and then ::
+
+    tilt patch configmap schema-version -p '{"data": {"version": "2"}}'
+
+  Creating or deleting the watched object counts as a change. Disabled resources are not triggered.
+  Watching an object is supported for Kubernetes resources and for ``local_resource`` with a ``cmd``,
+  where it's added to the resource's ``restartOn.objectFields``.
+
+  Args:
+    name: the name of the trigger, used in the URL. Must be a valid Kubernetes object name.
+    resources: the name of a resource, or a list of resource names, to update when the trigger fires.
+    watch_object: (optional) the Tilt API object to watch, in ``kind/name`` format.
+    watch_field: (optional) a `JSONPath <https://kubernetes.io/docs/reference/kubectl/jsonpath/>`_ expression selecting the field of ``watch_object`` to watch. Required if ``watch_object`` is set.
+  """
+  pass
+
 def disable_snapshots() -> None:
     """Disables Tilt's `snapshots <snapshots.html>`_ feature, hiding it from the UI.
 
@@ -1159,7 +1787,10 @@ def disable_snapshots() -> None:
     """
 
 def docker_prune_settings(disable: bool=False, max_age_mins: int=360,
-                          num_builds: int=0, interval_hrs: int=1, keep_recent: int=2) -> None:
+                          num_builds: int=0, interval_hrs: int=1, keep_recent: int=2,
+                          prune_volumes: bool=False, volume_max_age_mins: int=1440,
+                          keep_refs: List[str]=[], keep_recent_by_image: Dict[str, int]={},
+                          cluster: str="") -> None:
   """
   Configures Tilt's Docker Pruner, which runs occasionally in the background and prunes Docker images associated
   with your current project.
@@ -1170,8 +1801,28 @@ def docker_prune_settings(disable: bool=False, max_age_mins: int=360,
   The pruner will prune:
     - stopped containers built by Tilt that are at least ``max_age_mins`` mins old
     - images built by Tilt and associated with this Tilt run that are at least ``max_age_mins`` mins old,
-      and not in the ``keep_recent`` most recent builds for that image name
+      and not in the ``keep_recent`` most recent builds for that image name, and not in ``keep_refs``
     - dangling build caches that are at least ``max_age_mins`` mins old
+    - if ``prune_volumes`` is set, volumes of your Docker Compose projects (and volumes that Tilt
+      created itself) that no container uses and that are at least ``volume_max_age_mins`` mins old
+
+  With ``prune_volumes``, ``tilt down`` also removes the volumes of your Docker Compose projects.
+
+  Tilt prunes the container runtime of each cluster it connects to: the Docker daemon that
+  Tilt builds on for your Kubernetes cluster, and the daemon of each Docker Compose cluster.
+  Each cluster is pruned on its own schedule. Clusters that share a daemon (like Docker Desktop's
+  Kubernetes and Docker Compose) are pruned together.
+
+  To change the settings of one cluster, call ``docker_prune_settings`` with its ``cluster`` name
+  (see ``tilt get clusters``). Its settings start from the defaults, not from your other calls.
+
+  .. code-block:: python
+
+    docker_prune_settings(max_age_mins=60)
+    docker_prune_settings(cluster='docker', num_builds=5)
+
+  Tilt records the last few prunes on the status of each Cluster. You can see them
+  in the cluster status dialog in the web UI, or with ``tilt get cluster default -o yaml``.
 
   Args:
     disable: if true, disable the Docker Pruner
@@ -1179,6 +1830,64 @@ def docker_prune_settings(disable: bool=False, max_age_mins: int=360,
     num_builds: number of Docker builds after which to run a prune. (If unset, the pruner instead runs every ``interval_hrs`` hours)
     interval_hrs: run a Docker Prune every ``interval_hrs`` hours (unless ``num_builds`` is set, in which case use the "prune every X builds" logic). Defaults to 1 hour
     keep_recent: when pruning, retain at least the ``keep_recent`` most recent images for each image name. Defaults to 2
+    keep_refs: images that the pruner should never remove, e.g., a base image that you share with other projects. A ref without a tag (``'my-base'``) keeps every tag of that image; a ref with a tag (``'my-base:stable'``) keeps only that tag
+    keep_recent_by_image: overrides ``keep_recent`` for specific image names, e.g., ``{'my-base': 5}``
+    prune_volumes: if true, also prune unused volumes of your Docker Compose projects, and remove Docker Compose project volumes on ``tilt down``. Defaults to false, because volumes often hold data you want to keep
+    volume_max_age_mins: maximum age, in minutes, of unused volumes to retain. Defaults to 1440 mins., i.e. 24 hours
+    cluster: if set, these settings only apply to the cluster with this name
+  """
+  pass
+
+def idle_settings(timeout: str, warn_before: str = "15m", exit: bool = False) -> None:
+  """
+  Shuts down dev environments that nobody is using, so that a forgotten ``tilt up``
+  doesn't hold on to shared cluster capacity overnight.
+
+  Tilt is idle when nobody has changed a watched file, triggered a resource, changed
+  the Tiltfile args, or clicked a button in the Tilt UI. After ``timeout``, Tilt disables
+  every resource, which deletes its workloads (like ``tilt disable --all``).
+
+  To bring them back, click Resume in the Tilt UI. Tilt redeploys the images from each
+  resource's last build, so you don't have to wait for a rebuild, unless its files
+  changed while it was disabled. Re-enabling resources another way (e.g., with
+  ``tilt enable --all``) rebuilds them.
+
+  Tilt logs a warning ``warn_before`` the shutdown, so you have a chance to keep working.
+
+  .. code-block:: python
+
+    # Disable all resources after 8 hours without activity, and exit Tilt.
+    idle_settings(timeout='8h', exit=True)
+
+  Args:
+    timeout: How long Tilt can be idle before disabling all resources, e.g., ``'8h'``.
+    warn_before: How long before disabling resources to log a warning. Defaults to 15 minutes.
+    exit: If true, Tilt exits after it disables all resources.
+  """
+  pass
+
+def bandwidth_settings(low: bool) -> None:
+  """
+  Configures Tilt for remote clusters on a slow or metered connection.
+
+  In low-bandwidth mode, Tilt:
+
+  - Only backfills the last few seconds of logs when it starts watching a container,
+    and skips logs from init containers that succeeded.
+  - Checks on the cluster's health less often.
+  - Skips base image pulls, even for ``docker_build(pull=True)``.
+  - Gzips the files that live update copies into containers. This needs ``gzip``
+    support in the container's ``tar``.
+
+  You can also switch low-bandwidth mode on and off from the Tilt UI while Tilt is
+  running. Reloading the Tiltfile only overrides the UI if ``low`` changes.
+
+  .. code-block:: python
+
+    bandwidth_settings(low=True)
+
+  Args:
+    low: If true, Tilt starts in low-bandwidth mode.
   """
   pass
 
@@ -1196,7 +1905,7 @@ def analytics_settings(enable: bool) -> None:
   """
   pass
 
-def version_settings(check_updates: bool = True, constraint: str = "") -> None:
+def version_settings(check_updates: bool = True, constraint: str = "", check_latest_release: bool = False) -> None:
   """Controls Tilt's behavior with regard to its own version.
 
   Args:
@@ -1211,6 +1920,10 @@ def version_settings(check_updates: bool = True, constraint: str = "") -> None:
                 - `>=0.13.2` - at least 0.13.2
 
                 See more at the `constraint syntax documentation <https://github.com/blang/semver#ranges>`_.
+    check_latest_release: If true, Tilt will check GitHub once a day for the latest release,
+                          and warn if the running version is out of date or doesn't match
+                          the ``tilt`` binary on your PATH (which editor integrations use
+                          for ``tilt lsp``). Off by default.
   """
 
 def struct(**kwargs) -> Any:
@@ -1225,6 +1938,27 @@ def struct(**kwargs) -> Any:
   """
 
 
+def extension_settings(lockfile: str = "") -> None:
+  """Configures how Tilt loads `Tilt Extensions <extensions.html>`_.
+
+  Example ::
+
+    extension_settings(lockfile='tilt_extensions.lock')
+    v1alpha1.extension_repo(name='my-repo', url='https://github.com/my-org/tilt-lib', ref='v1.2.0')
+    v1alpha1.extension(name='my-lib', repo_name='my-repo', repo_path='my-lib')
+    load('ext://my-lib', 'my_func')
+
+  Must be called before any extensions are loaded.
+
+  Args:
+    lockfile: Path to a lockfile that pins each extension repo to a commit.
+      The first time Tilt loads a repo, it records the commit the repo resolved to.
+      After that, Tilt always loads that commit, and only fetches the repo again
+      when the lockfile entry (or the repo's ``ref``) changes. Check this file into
+      version control so that your whole team loads the same extension code.
+"""
+
+
 def secret_settings(disable_scrub: bool = False) -> None:
   """Configures Tilt's handling of Kubernetes Secrets. By default, Tilt scrubs
   the text of any Secrets from the logs; e.g. if Tilt applies a Secret with contents
@@ -1236,9 +1970,33 @@ def secret_settings(disable_scrub: bool = False) -> None:
 """
 
 
+def snapshot_settings(storage_url: str = "", link_expiry_hrs: int = 168) -> None:
+  """Configures where Tilt uploads snapshots, so that you can share a link to
+  a snapshot with your team without going through Tilt Cloud.
+
+  Tilt uploads the snapshot to your own bucket and returns a signed link that
+  anyone can use to download it until the link expires. View a downloaded
+  snapshot with ``tilt snapshot view``.
+
+  Credentials always come from the environment. For S3, Tilt reads
+  ``AWS_ACCESS_KEY_ID``, ``AWS_SECRET_ACCESS_KEY``, ``AWS_SESSION_TOKEN``,
+  and ``AWS_REGION``. For GCS, Tilt uses HMAC keys from
+  ``TILT_SNAPSHOT_GCS_HMAC_ACCESS_ID`` and ``TILT_SNAPSHOT_GCS_HMAC_SECRET``.
+  To use an S3-compatible store like MinIO, set ``TILT_SNAPSHOT_STORAGE_ENDPOINT``.
+
+  If you don't call ``snapshot_settings``, Tilt uses the
+  ``TILT_SNAPSHOT_STORAGE_URL`` environment variable.
+
+  Args:
+    storage_url: where to upload snapshots, e.g. ``s3://my-bucket/snapshots`` or ``gs://my-bucket/snapshots``.
+    link_expiry_hrs: how long the link to an uploaded snapshot stays valid, between 1 and 168 hours (7 days).
+"""
+
+
 def update_settings(
     max_parallel_updates: int=3,
     k8s_upsert_timeout_secs: int=30,
+    k8s_job_retention_secs: int=0,
     suppress_unused_image_warnings: Union[str, List[str]]=None) -> None:
   """Configures Tilt's updates to your resources. (An update is any execution of or
   change to a resource. Examples of updates include: doing a docker build + deploy to
@@ -1248,6 +2006,9 @@ def update_settings(
   Args:
     max_parallel_updates: maximum number of updates Tilt will execute in parallel. Default is 3. Must be a positive integer.
     k8s_upsert_timeout_secs: timeout (in seconds) for Kubernetes upserts (i.e. ``create``/``apply`` calls). Minimum value is 1.
+    k8s_job_retention_secs: how long (in seconds) to keep Jobs that Tilt created after they succeed.
+      Tilt deletes a Job (and its pods) once the Job has succeeded, Tilt has captured all its logs, and
+      this much time has passed. Failed Jobs are never deleted. Default is 0, which keeps completed Jobs forever.
     suppress_unused_image_warnings: suppresses warnings about images that aren't deployed.
       Accepts a list of image names, or '*' to suppress warnings for all images.
 """
@@ -67,6 +67,13 @@ class KubernetesApplyCmd:
 
 
 
+class KubernetesApplyWaitFor:
+  """A rollout check on the applied objects.
+"""
+  pass
+
+
+
 class KubernetesDiscoveryTemplateSpec:
   """
 """
@@ -110,6 +117,14 @@ class LabelSelectorRequirement:
 
 
 
+class LocalIngressRoute:
+  """LocalIngressRoute sends requests under a path prefix to a resource's
+  port forward, or to a local port.
+"""
+  pass
+
+
+
 class ObjectSelector:
   """Selector for any Kubernetes-style API.
 """
@@ -367,6 +382,8 @@ def kubernetes_apply(
   disable_source: Optional[DisableSource] = None,
   cmd: Optional[KubernetesApplyCmd] = None,
   restart_on: Optional[RestartOnSpec] = None,
+  wait_for: List[KubernetesApplyWaitFor] = None,
+  apply_order: List[ObjectSelector] = None,
 ):
   """
   KubernetesApply specifies a blob of YAML to apply, and a set of ImageMaps
@@ -451,6 +468,22 @@ def kubernetes_apply(
       
     restart_on: RestartOn determines external triggers that will result in an apply.
       
+    wait_for: WaitFor determines rollout checks on the applied objects.
+      
+      The apply isn't considered done until every object matched by a check
+      passes it (e.g., a Deployment is Available or a Job is Complete).
+      While waiting, the RolloutComplete condition is False.
+      
+    apply_order: ApplyOrder splits the objects into stages that are applied one after another.
+      
+      Each object is applied in the stage of the first selector that matches it.
+      Objects that don't match any selector are applied in a final stage.
+      Within a stage, objects are applied in kind order (e.g., Namespaces and
+      CustomResourceDefinitions first).
+      
+      Objects of a kind defined by a CustomResourceDefinition in the same apply
+      wait for the CustomResourceDefinition to be established.
+      
 """
   pass
 def kubernetes_discovery(
@@ -461,6 +494,8 @@ def kubernetes_discovery(
   extra_selectors: List[LabelSelector] = None,
   port_forward_template_spec: Optional[PortForwardTemplateSpec] = None,
   pod_log_stream_template_spec: Optional[PodLogStreamTemplateSpec] = None,
+  extra_namespaces: List[str] = None,
+  all_namespaces: bool = False,
 ):
   """
   KubernetesDiscovery
@@ -494,6 +529,80 @@ def kubernetes_discovery(
       If no template is specified, the controller will stream all
       pod logs available from the apiserver.
       
+    extra_namespaces: Extra namespaces to look in for Pods that match the ExtraSelectors.
+      
+      Pods are always discovered in the namespaces of the Watches. Use this
+      when an operator creates Pods in a different namespace than the objects
+      it manages.
+      
+    all_namespaces: Look in all namespaces for Pods that match the ExtraSelectors.
+      
+      Requires permission to list and watch Pods across the cluster.
+      
+"""
+  pass
+def local_ingress(
+  name: str,
+  labels: Dict[str, str] = None,
+  annotations: Dict[str, str] = None,
+  host: str = "",
+  port: int = 0,
+  routes: List[LocalIngressRoute] = None,
+):
+  """
+  LocalIngress serves a friendly local hostname (e.g., myapp.localhost)
+  over HTTPS, and proxies requests to Tilt-managed port forwards.
+  
+  The URL stays the same when pods restart or port forwards
+  move to a different local port.
+
+  Args:
+    name: The name in the Object metadata.
+    labels: A set of key/value pairs in the Object metadata for grouping objects.
+    annotations: A set of key/value pairs in the Object metadata for attaching data to objects.
+    host: The hostname to serve, e.g., myapp.localhost
+      
+      Hostnames under .localhost resolve to the local machine without
+      any DNS setup. Other hostnames need an entry in /etc/hosts, and
+      their domain in TILT_LOCAL_INGRESS_DOMAINS, because the local CA
+      only signs certificates for the domains it was created with.
+    port: The local port to serve HTTPS on. Defaults to 10443.
+      
+      LocalIngresses on the same port share a server, and are
+      routed by hostname.
+      
+    routes: Where to send requests, by path prefix. The longest matching prefix wins.
+"""
+  pass
+def nested_tilt(
+  name: str,
+  labels: Dict[str, str] = None,
+  annotations: Dict[str, str] = None,
+  path: str = "",
+  port: int = 0,
+  args: List[str] = None,
+):
+  """
+  NestedTilt runs a child Tilt instance for another Tiltfile,
+  and mirrors its resources into this Tilt's UI.
+  
+  Useful for composing several independently-owned Tilt setups
+  without merging their Tiltfiles.
+
+  Args:
+    name: The name in the Object metadata.
+    labels: A set of key/value pairs in the Object metadata for grouping objects.
+    annotations: A set of key/value pairs in the Object metadata for attaching data to objects.
+    path: The directory that contains the child Tiltfile.
+      
+      The child Tilt runs with this directory as its working directory.
+    port: The port for the child Tilt's web UI and API.
+      
+      Must be different from the port of every other running Tilt instance.
+    args: Arguments to the child Tiltfile.
+      
+      By default, a list of arguments indicates the list of resources
+      in the child Tiltfile that should be enabled.
 """
   pass
 def ui_button(
@@ -693,6 +802,40 @@ def kubernetes_apply_cmd(
 """
   pass
 
+def kubernetes_apply_wait_for(
+  object_selector: ObjectSelector = None,
+  condition: str = "",
+  json_path: str = "",
+  value: str = "",
+  timeout: str = "",
+) -> KubernetesApplyWaitFor:
+  """
+  A rollout check on the applied objects.
+  
+  Modeled after `kubectl wait --for`.
+
+  Args:
+    object_selector: Selects which applied objects to check.
+    condition: The type of a status condition that must be True,
+      e.g., "Available" for a Deployment or "Complete" for a Job.
+      
+      Exactly one of Condition OR JSONPath MUST be provided.
+      
+    json_path: A JSONPath into the object, e.g., "{.status.phase}".
+      
+      Useful for CRDs that don't report their status as conditions.
+      
+    value: The value the field at JSONPath must have.
+      
+      If empty, the field must be present and non-empty.
+      
+    timeout: How long to wait after the apply for the check to pass.
+      
+      If not specified, defaults to 5m.
+      
+"""
+  pass
+
 def kubernetes_discovery_template_spec(
   extra_selectors: List[LabelSelector] = None,
 ) -> KubernetesDiscoveryTemplateSpec:
@@ -799,6 +942,34 @@ def label_selector_requirement(
 """
   pass
 
+def local_ingress_route(
+  path: str = "",
+  resource: str = "",
+  container_port: int = 0,
+  local_port: int = 0,
+) -> LocalIngressRoute:
+  """
+  LocalIngressRoute sends requests under a path prefix to a resource's
+  port forward, or to a local port.
+
+  Args:
+    path: The path prefix to match. Defaults to "/".
+      
+    resource: The resource whose port forward should receive requests.
+      
+      Exactly one of Resource or LocalPort is required.
+      
+    container_port: The container port of the resource's port forward. If not specified,
+      uses the resource's first port forward.
+      
+    local_port: A port on localhost that should receive requests (e.g., a server
+      started by a local_resource).
+      
+      Exactly one of Resource or LocalPort is required.
+      
+"""
+  pass
+
 def object_selector(
   api_version_regexp: str = "",
   kind_regexp: str = "",
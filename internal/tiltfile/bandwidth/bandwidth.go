@@ -0,0 +1,54 @@
+package bandwidth
+
+import (
+	"go.starlark.net/starlark"
+
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// Implements functions for working against remote clusters over slow links.
+type Plugin struct{}
+
+func NewPlugin() Plugin {
+	return Plugin{}
+}
+
+func (e Plugin) NewState() interface{} {
+	return model.BandwidthSettings{}
+}
+
+func (e Plugin) OnStart(env *starkit.Environment) error {
+	return env.AddBuiltin("bandwidth_settings", e.bandwidthSettings)
+}
+
+func (e Plugin) bandwidthSettings(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var low bool
+	if err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs,
+		"low", &low); err != nil {
+		return nil, err
+	}
+
+	err := starkit.SetState(thread, func(settings model.BandwidthSettings) (model.BandwidthSettings, error) {
+		settings.Low = low
+		return settings, nil
+	})
+
+	return starlark.None, err
+}
+
+var _ starkit.StatefulPlugin = Plugin{}
+
+func MustState(model starkit.Model) model.BandwidthSettings {
+	state, err := GetState(model)
+	if err != nil {
+		panic(err)
+	}
+	return state
+}
+
+func GetState(m starkit.Model) (model.BandwidthSettings, error) {
+	var state model.BandwidthSettings
+	err := m.Load(&state)
+	return state, err
+}
@@ -0,0 +1,42 @@
+package bandwidth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+)
+
+func TestBandwidthSettings(t *testing.T) {
+	f := NewFixture(t)
+	f.File("Tiltfile", `
+bandwidth_settings(low=True)
+`)
+	result, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	assert.True(t, MustState(result).Low)
+}
+
+func TestBandwidthSettingsDefault(t *testing.T) {
+	f := NewFixture(t)
+	f.File("Tiltfile", ``)
+	result, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	assert.False(t, MustState(result).Low)
+}
+
+func TestBandwidthSettingsBadArg(t *testing.T) {
+	f := NewFixture(t)
+	f.File("Tiltfile", `
+bandwidth_settings(low='yes')
+`)
+	_, err := f.ExecFile("Tiltfile")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "low")
+}
+
+func NewFixture(tb testing.TB) *starkit.Fixture {
+	return starkit.NewFixture(tb, NewPlugin())
+}
@@ -69,7 +69,7 @@ func (idx *buildIndex) findBuilderByImageMapName(im string) *dockerImage {
 // Many things can consume image builds:
 // - k8s yaml
 // - docker-compose yaml
-// - the from command in other images
+// (The from command in other images is handled by findBuilderForBaseImage.)
 // Check to see if we have a build target for that image,
 // and mark that build target as consumed by an larger target.
 func (idx *buildIndex) findBuilderForConsumedImage(ref reference.Named) *dockerImage {
@@ -79,19 +79,66 @@ func (idx *buildIndex) findBuilderForConsumedImage(ref reference.Named) *dockerI
 		idx.consumedImageNames = append(idx.consumedImageNames, name)
 	}
 
+	image := idx.findBuilder(ref)
+	if image != nil {
+		image.matched = true
+	}
+	return image
+}
+
+// Like findBuilderForConsumedImage, but for an image that another image
+// builds on. The base image is only used if the image that builds on it is.
+//
+// Base images aren't deployed, so they're not good suggestions
+// for unmatched images, and we don't record them as consumed.
+func (idx *buildIndex) findBuilderForBaseImage(ref reference.Named, dependent *dockerImage) *dockerImage {
+	image := idx.findBuilder(ref)
+	if image != nil {
+		image.dependents = append(image.dependents, dependent)
+	}
+	return image
+}
+
+func (idx *buildIndex) findBuilder(ref reference.Named) *dockerImage {
 	for _, image := range idx.images {
 		if image.configurationRef.Matches(ref) {
-			image.matched = true
 			return image
 		}
 	}
 	return nil
 }
 
+// Finds the images that no deploy target uses, either directly or
+// through an image that builds on them.
 func (idx *buildIndex) unmatchedImages() []*dockerImage {
+	used := make(map[*dockerImage]bool, len(idx.images))
+	for _, image := range idx.images {
+		if image.matched {
+			used[image] = true
+		}
+	}
+
+	// Propagate use from each image to the images it builds on,
+	// until nothing changes.
+	for changed := true; changed; {
+		changed = false
+		for _, image := range idx.images {
+			if used[image] {
+				continue
+			}
+			for _, dependent := range image.dependents {
+				if used[dependent] {
+					used[image] = true
+					changed = true
+					break
+				}
+			}
+		}
+	}
+
 	unmatchedImages := make([]*dockerImage, 0)
 	for _, image := range idx.images {
-		if !image.matched {
+		if !used[image] {
 			unmatchedImages = append(unmatchedImages, image)
 		}
 	}
@@ -115,10 +162,23 @@ func (idx *buildIndex) unmatchedImageWarning(image *dockerImage, configType stri
 		matchLines = append(matchLines, fmt.Sprintf("    - %s\n", match))
 	}
 
-	return fmt.Errorf("Image not used in any %s config:\n    ✕ %v\n%sSkipping this image build\n"+
+	dependentLines := ""
+	if len(image.dependents) > 0 {
+		var names []string
+		for _, dependent := range image.dependents {
+			names = append(names, container.FamiliarString(dependent.configurationRef))
+		}
+		dependentLines = fmt.Sprintf("Only used to build other unused images: %s\n", strings.Join(names, ", "))
+	}
+
+	return fmt.Errorf("Image not used in any %s config:\n    ✕ %v\n%s%sSkipping this image build\n"+
+		"If it's left over from a refactor, remove its %s(). Otherwise, use the image name in a %s config to deploy it.\n"+
 		"If this is deliberate, suppress this warning with: update_settings(suppress_unused_image_warnings=[%q])",
 		configType,
 		container.FamiliarString(image.configurationRef),
+		dependentLines,
 		strings.Join(matchLines, ""),
+		image.builtinName(),
+		configType,
 		container.FamiliarString(image.configurationRef))
 }
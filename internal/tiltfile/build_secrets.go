@@ -0,0 +1,332 @@
+package tiltfile
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.starlark.net/starlark"
+
+	"github.com/tilt-dev/tilt/internal/tiltfile/io"
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+	"github.com/tilt-dev/tilt/internal/tiltfile/value"
+	"github.com/tilt-dev/tilt/internal/xdg"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// BuildKit reads build secrets from files or env vars.
+//
+// When a secret's contents come from the Tiltfile (e.g., from local()),
+// we write them to a file that only the current user can read. The files
+// are named after their contents, so that reloading the Tiltfile doesn't
+// change the image spec and trigger a rebuild.
+//
+// Each Tiltfile gets its own directory, and files that the last successful
+// load didn't use are deleted.
+
+// The files live in the user's state dir (e.g., ~/.local/state/tilt-dev/build-secrets),
+// rather than the shared temp dir, where another user could create the directory first.
+//
+// Replaced in tests.
+var buildSecretsRoot = func() (string, error) {
+	return xdg.NewTiltDevBase().StateFile("build-secrets")
+}
+
+// The name we scrub build secret contents under.
+const buildSecretName = "build-secret"
+
+type buildSecretFiles struct {
+	dir      string
+	dirErr   error
+	written  map[string]bool
+	contents model.SecretSet
+}
+
+func newBuildSecretFiles(tiltfilePath string) *buildSecretFiles {
+	f := &buildSecretFiles{
+		written:  make(map[string]bool),
+		contents: model.SecretSet{},
+	}
+	root, err := buildSecretsRoot()
+	if err != nil {
+		f.dirErr = err
+		return f
+	}
+	h := sha256.Sum256([]byte(tiltfilePath))
+	f.dir = filepath.Join(root, hex.EncodeToString(h[:8]))
+	return f
+}
+
+func (f *buildSecretFiles) write(id string, contents []byte) (string, error) {
+	if f.dirErr != nil {
+		return "", f.dirErr
+	}
+	err := os.MkdirAll(f.dir, 0700)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	_, _ = h.Write([]byte(id))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write(contents)
+	path := filepath.Join(f.dir, hex.EncodeToString(h.Sum(nil)[:16]))
+	f.written[path] = true
+	f.contents.AddSecret(buildSecretName, id, contents)
+
+	existing, err := os.ReadFile(path)
+	if err == nil && bytes.Equal(existing, contents) {
+		return path, nil
+	}
+
+	tmp, err := os.CreateTemp(f.dir, ".secret-")
+	if err != nil {
+		return "", err
+	}
+	_, err = tmp.Write(contents)
+	closeErr := tmp.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return "", err
+	}
+	return path, nil
+}
+
+// Deletes the files that this load didn't write.
+func (f *buildSecretFiles) prune() error {
+	if f.dir == "" {
+		return nil
+	}
+	entries, err := os.ReadDir(f.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	for _, e := range entries {
+		path := filepath.Join(f.dir, e.Name())
+		if f.written[path] {
+			continue
+		}
+		err := os.Remove(path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	if len(f.written) == 0 {
+		_ = os.Remove(f.dir)
+	}
+	return nil
+}
+
+// Parses the secret argument of docker_build().
+//
+// Accepts the `docker build --secret` syntax, or a dict from secret id to
+// either a file path or the secret's contents (a Blob, e.g., from local()).
+//
+// Returns the secrets in `--secret` syntax, with absolute paths.
+func (s *tiltfileState) parseBuildSecrets(t *starlark.Thread, v starlark.Value) ([]string, error) {
+	if v == nil || v == starlark.None {
+		return nil, nil
+	}
+
+	specs := []string{}
+	if d, ok := v.(*starlark.Dict); ok {
+		for _, item := range d.Items() {
+			id, ok := starlark.AsString(item[0])
+			if !ok || id == "" {
+				return nil, fmt.Errorf("secret: keys must be non-empty strings, got %s", item[0].String())
+			}
+
+			switch source := item[1].(type) {
+			case io.Blob:
+				path, err := s.buildSecrets.write(id, []byte(source.String()))
+				if err != nil {
+					return nil, errors.Wrapf(err, "secret %q: writing contents", id)
+				}
+				specs = append(specs, formatBuildSecret([][2]string{{"id", id}, {"src", path}}))
+			case starlark.String:
+				specs = append(specs, formatBuildSecret([][2]string{{"id", id}, {"src", string(source)}}))
+			default:
+				return nil, fmt.Errorf("secret %q: expected a file path or a blob, got %s", id, item[1].Type())
+			}
+		}
+	} else {
+		var list value.StringOrStringList
+		err := list.Unpack(v)
+		if err != nil {
+			return nil, errors.Wrap(err, "secret")
+		}
+		specs = list.Values
+	}
+
+	result := make([]string, 0, len(specs))
+	for _, spec := range specs {
+		normalized, err := normalizeBuildSecret(t, spec)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, normalized)
+	}
+	return result, nil
+}
+
+// Checks that the secret's source exists now, rather than failing
+// in the middle of the build.
+func normalizeBuildSecret(t *starlark.Thread, spec string) (string, error) {
+	fields, err := csv.NewReader(strings.NewReader(spec)).Read()
+	if err != nil {
+		return "", errors.Wrapf(err, "secret %q: invalid syntax", spec)
+	}
+
+	var id, typ, src, env string
+	for _, field := range fields {
+		parts := strings.SplitN(field, "=", 2)
+		if len(parts) != 2 {
+			return "", fmt.Errorf("secret %q: field %q must be a key=value pair", spec, field)
+		}
+		switch strings.ToLower(parts[0]) {
+		case "id":
+			id = parts[1]
+		case "type":
+			typ = parts[1]
+		case "src", "source":
+			src = parts[1]
+		case "env":
+			env = parts[1]
+		default:
+			return "", fmt.Errorf("secret %q: unexpected key %q", spec, parts[0])
+		}
+	}
+
+	if typ != "" && typ != "file" && typ != "env" {
+		return "", fmt.Errorf("secret %q: unsupported type %q. Valid types: file, env", spec, typ)
+	}
+	if id == "" && src == "" && env == "" {
+		return "", fmt.Errorf("secret %q: must have an id", spec)
+	}
+
+	// Follow the same defaults as `docker build --secret`.
+	if typ == "" && src == "" && env == "" {
+		// BuildKit looks for an env var named after the id first, then for
+		// a file. Prefer a file next to the Tiltfile over one relative to
+		// wherever Tilt was started. If there's neither, BuildKit resolves
+		// the id when it builds, like it always has.
+		if _, ok := os.LookupEnv(id); ok {
+			return formatBuildSecret([][2]string{{"id", id}}), nil
+		}
+		path := absSecretPath(t, id)
+		if info, err := os.Stat(path); err != nil || info.IsDir() {
+			return formatBuildSecret([][2]string{{"id", id}}), nil
+		}
+		return formatBuildSecret([][2]string{{"id", id}, {"src", path}}), nil
+	}
+	if typ == "env" && env == "" {
+		env = src
+		src = ""
+		if env == "" {
+			env = id
+		}
+	}
+	if env != "" {
+		if id == "" {
+			id = env
+		}
+		if _, ok := os.LookupEnv(env); !ok {
+			return "", fmt.Errorf("secret %q: environment variable %s is not set", id, env)
+		}
+		return formatBuildSecret([][2]string{{"id", id}, {"env", env}}), nil
+	}
+
+	if src == "" {
+		src = id
+	}
+	if id == "" {
+		id = filepath.Base(src)
+	}
+	src = absSecretPath(t, src)
+	info, err := os.Stat(src)
+	if err != nil {
+		return "", fmt.Errorf("secret %q: %v", id, err)
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("secret %q: %s is a directory", id, src)
+	}
+	return formatBuildSecret([][2]string{{"id", id}, {"src", src}}), nil
+}
+
+// Parses the ssh argument of docker_build(), in `docker build --ssh` syntax.
+//
+// Returns the configs with absolute key paths.
+func parseBuildSSH(t *starlark.Thread, specs []string) ([]string, error) {
+	result := make([]string, 0, len(specs))
+	for _, spec := range specs {
+		parts := strings.SplitN(spec, "=", 2)
+		id := parts[0]
+		if id == "" {
+			return nil, fmt.Errorf("ssh %q: must have an id, like 'default'", spec)
+		}
+
+		if len(parts) == 1 {
+			if os.Getenv("SSH_AUTH_SOCK") == "" {
+				return nil, fmt.Errorf("ssh %q: SSH_AUTH_SOCK is not set. "+
+					"Start an ssh-agent, or pass the path to a key, like ssh='default=~/.ssh/id_ed25519'", spec)
+			}
+			result = append(result, id)
+			continue
+		}
+
+		paths := []string{}
+		for _, p := range strings.Split(parts[1], ",") {
+			if p == "" {
+				return nil, fmt.Errorf("ssh %q: empty path", spec)
+			}
+			p = absSecretPath(t, p)
+			_, err := os.Stat(p)
+			if err != nil {
+				return nil, fmt.Errorf("ssh %q: %v", spec, err)
+			}
+			paths = append(paths, p)
+		}
+		result = append(result, fmt.Sprintf("%s=%s", id, strings.Join(paths, ",")))
+	}
+	return result, nil
+}
+
+// Resolves paths relative to the Tiltfile, and expands ~.
+func absSecretPath(t *starlark.Thread, p string) string {
+	if p == "~" || strings.HasPrefix(p, "~/") {
+		home, err := os.UserHomeDir()
+		if err == nil {
+			p = filepath.Join(home, strings.TrimPrefix(p, "~"))
+		}
+	}
+	return starkit.AbsPath(t, p)
+}
+
+func formatBuildSecret(fields [][2]string) string {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	record := make([]string, 0, len(fields))
+	for _, f := range fields {
+		record = append(record, fmt.Sprintf("%s=%s", f[0], f[1]))
+	}
+	_ = w.Write(record)
+	w.Flush()
+	return strings.TrimSuffix(buf.String(), "\n")
+}
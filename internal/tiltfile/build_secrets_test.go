@@ -0,0 +1,185 @@
+package tiltfile
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func newBuildSecretsFixture(t *testing.T) *fixture {
+	root := t.TempDir()
+	orig := buildSecretsRoot
+	buildSecretsRoot = func() (string, error) { return root, nil }
+	t.Cleanup(func() {
+		buildSecretsRoot = orig
+	})
+
+	f := newFixture(t)
+	f.setupFoo()
+	return f
+}
+
+func (f *fixture) buildSecrets() []string {
+	f.t.Helper()
+	m := f.assertNextManifest("foo")
+	return m.ImageTargets[0].BuildDetails.(model.DockerBuild).Secrets
+}
+
+func TestBuildSecretEnv(t *testing.T) {
+	f := newBuildSecretsFixture(t)
+	t.Setenv("NPM_TOKEN", "hunter2")
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+docker_build("gcr.io/foo", "foo", secret=['id=npm,env=NPM_TOKEN', 'type=env,id=NPM_TOKEN'])
+`)
+
+	f.load()
+	assert.Equal(t, []string{"id=npm,env=NPM_TOKEN", "id=NPM_TOKEN,env=NPM_TOKEN"}, f.buildSecrets())
+}
+
+func TestBuildSecretEnvNotSet(t *testing.T) {
+	f := newBuildSecretsFixture(t)
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+docker_build("gcr.io/foo", "foo", secret='id=npm,env=TILT_TEST_UNSET_TOKEN')
+`)
+
+	f.loadErrString(`secret "npm": environment variable TILT_TEST_UNSET_TOKEN is not set`)
+}
+
+func TestBuildSecretIDPrefersEnv(t *testing.T) {
+	f := newBuildSecretsFixture(t)
+	t.Setenv("NPM_TOKEN", "hunter2")
+	f.file("NPM_TOKEN", "from-file")
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+docker_build("gcr.io/foo", "foo", secret='id=NPM_TOKEN')
+`)
+
+	f.load()
+	assert.Equal(t, []string{"id=NPM_TOKEN"}, f.buildSecrets())
+}
+
+func TestBuildSecretIDFileNextToTiltfile(t *testing.T) {
+	f := newBuildSecretsFixture(t)
+	f.file("npmrc", "from-file")
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+docker_build("gcr.io/foo", "foo", secret='id=npmrc')
+`)
+
+	f.load()
+	assert.Equal(t, []string{"id=npmrc,src=" + f.JoinPath("npmrc")}, f.buildSecrets())
+}
+
+func TestBuildSecretMissingFile(t *testing.T) {
+	f := newBuildSecretsFixture(t)
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+docker_build("gcr.io/foo", "foo", secret='id=npmrc,src=.npmrc')
+`)
+
+	f.loadErrString(`secret "npmrc"`, "no such file or directory")
+}
+
+func TestBuildSecretInvalidType(t *testing.T) {
+	f := newBuildSecretsFixture(t)
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+docker_build("gcr.io/foo", "foo", secret='id=npmrc,type=vault')
+`)
+
+	f.loadErrString(`unsupported type "vault"`)
+}
+
+func TestBuildSecretDict(t *testing.T) {
+	f := newBuildSecretsFixture(t)
+	f.file(".npmrc", "//registry.npmjs.org/:_authToken=abc")
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+docker_build("gcr.io/foo", "foo", secret={
+  'npmrc': '.npmrc',
+  'token': local('echo hunter2', quiet=True),
+})
+`)
+
+	f.load()
+	secrets := f.buildSecrets()
+	require.Len(t, secrets, 2)
+	assert.Equal(t, "id=npmrc,src="+f.JoinPath(".npmrc"), secrets[0])
+
+	path := f.buildSecretPath(secrets[1], "token")
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2\n", string(contents))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	if os.Getenv("OS") != "Windows_NT" {
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	}
+
+	assert.Equal(t, "[redacted secret build-secret:token]",
+		string(f.loadResult.Secrets.Scrub([]byte("hunter2\n"))))
+}
+
+func TestBuildSecretFilesArePruned(t *testing.T) {
+	f := newBuildSecretsFixture(t)
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+docker_build("gcr.io/foo", "foo", secret={'token': local('echo v1', quiet=True)})
+`)
+
+	f.load()
+	v1 := f.buildSecretPath(f.buildSecrets()[0], "token")
+
+	// Reloading with the same contents keeps the same file, so the image doesn't rebuild.
+	f.load()
+	assert.Equal(t, v1, f.buildSecretPath(f.buildSecrets()[0], "token"))
+
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+docker_build("gcr.io/foo", "foo", secret={'token': local('echo v2', quiet=True)})
+`)
+	f.load()
+	v2 := f.buildSecretPath(f.buildSecrets()[0], "token")
+	assert.NotEqual(t, v1, v2)
+	assert.NoFileExists(t, v1)
+	assert.FileExists(t, v2)
+}
+
+func TestBuildSSHKeyPath(t *testing.T) {
+	f := newBuildSecretsFixture(t)
+	f.file("keys/id_ed25519", "private key")
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+docker_build("gcr.io/foo", "foo", ssh='github=keys/id_ed25519')
+`)
+
+	f.load()
+	m := f.assertNextManifest("foo")
+	assert.Equal(t, []string{"github=" + f.JoinPath("keys", "id_ed25519")},
+		m.ImageTargets[0].BuildDetails.(model.DockerBuild).SSHAgentConfigs)
+}
+
+func TestBuildSSHNoAgent(t *testing.T) {
+	f := newBuildSecretsFixture(t)
+	t.Setenv("SSH_AUTH_SOCK", "")
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+docker_build("gcr.io/foo", "foo", ssh='default')
+`)
+
+	f.loadErrString("SSH_AUTH_SOCK is not set")
+}
+
+func (f *fixture) buildSecretPath(spec string, id string) string {
+	f.t.Helper()
+	prefix := "id=" + id + ",src="
+	require.Contains(f.t, spec, prefix)
+	return spec[len(prefix):]
+}
@@ -115,12 +115,23 @@ func match(manifests []model.Manifest, requestedManifests []model.ManifestName)
 	var unknownNames []string
 
 	for _, m := range requestedManifests {
-		if _, ok := manifestsByName[m]; !ok {
-			unknownNames = append(unknownNames, string(m))
+		if _, ok := manifestsByName[m]; ok {
+			addManifestAndDeps(manifestsToRun, manifestsByName, m)
 			continue
 		}
 
-		addManifestAndDeps(manifestsToRun, manifestsByName, m)
+		// If it's not a resource name, maybe it's a group, which selects
+		// every resource in that group and the groups nested under it.
+		inGroup := false
+		for _, candidate := range manifests {
+			if model.GroupContains(string(m), candidate.GroupPath()) {
+				inGroup = true
+				addManifestAndDeps(manifestsToRun, manifestsByName, candidate.Name)
+			}
+		}
+		if !inGroup {
+			unknownNames = append(unknownNames, string(m))
+		}
 	}
 
 	var result []model.ManifestName
@@ -14,6 +14,7 @@ import (
 	"go.starlark.net/starlark"
 
 	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/docker"
 	"github.com/tilt-dev/tilt/internal/dockerfile"
 	"github.com/tilt-dev/tilt/internal/ospath"
 	"github.com/tilt-dev/tilt/internal/sliceutils"
@@ -63,8 +64,22 @@ type dockerImage struct {
 	// Whether this has been matched up yet to a deploy resource.
 	matched bool
 
+	// Images that build on this one.
+	dependents []*dockerImage
+
 	imageMapDeps []string
 
+	// Only applicable to ko_build
+	koImportPath string
+	koBaseImage  string
+	koBuildFlags []string
+	koEnv        []string
+
+	// Only applicable to oci_artifact
+	ociFiles        []string
+	ociArtifactType string
+	ociMediaType    string
+
 	// Only applicable to custom_build
 	disablePush       bool
 	skipsLocalDocker  bool
@@ -94,12 +109,28 @@ const (
 	DockerBuild
 	CustomBuild
 	DockerComposeBuild
+	OCIArtifactBuild
+	KoBuild
 )
 
 func (d *dockerImage) Type() dockerImageBuildType {
 	return d.buildType
 }
 
+// The Tiltfile function that declares this kind of image.
+func (d *dockerImage) builtinName() string {
+	switch d.buildType {
+	case CustomBuild:
+		return customBuildN
+	case OCIArtifactBuild:
+		return ociArtifactN
+	case KoBuild:
+		return koBuildN
+	default:
+		return dockerBuildN
+	}
+}
+
 func (s *tiltfileState) dockerBuild(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 	var dockerRef, targetStage string
 	contextVal := value.NewLocalPathUnpacker(thread)
@@ -109,10 +140,11 @@ func (s *tiltfileState) dockerBuild(thread *starlark.Thread, fn *starlark.Builti
 		liveUpdateVal,
 		ignoreVal,
 		onlyVal,
-		entrypoint starlark.Value
+		entrypoint,
+		secretVal starlark.Value
 	var buildArgs value.StringStringMap
 	var network, platform value.Stringable
-	var ssh, secret, extraTags, cacheFrom value.StringOrStringList
+	var ssh, extraTags, cacheFrom value.StringOrStringList
 	var matchInEnvVars, pullParent bool
 	var overrideArgsVal starlark.Sequence
 	if err := s.unpackArgs(fn.Name(), args, kwargs,
@@ -130,7 +162,7 @@ func (s *tiltfileState) dockerBuild(thread *starlark.Thread, fn *starlark.Builti
 		"container_args?", &overrideArgsVal,
 		"target?", &targetStage,
 		"ssh?", &ssh,
-		"secret?", &secret,
+		"secret?", &secretVal,
 		"network?", &network,
 		"extra_tag?", &extraTags,
 		"cache_from?", &cacheFrom,
@@ -145,6 +177,16 @@ func (s *tiltfileState) dockerBuild(thread *starlark.Thread, fn *starlark.Builti
 		return nil, fmt.Errorf("Argument 1 (ref): can't parse %q: %v", dockerRef, err)
 	}
 
+	secrets, err := s.parseBuildSecrets(thread, secretVal)
+	if err != nil {
+		return nil, err
+	}
+
+	sshSpecs, err := parseBuildSSH(thread, ssh.Values)
+	if err != nil {
+		return nil, err
+	}
+
 	context := contextVal.Value
 	dockerfilePath := filepath.Join(context, "Dockerfile")
 	var dockerfileContents string
@@ -241,8 +283,8 @@ func (s *tiltfileState) dockerBuild(thread *starlark.Thread, fn *starlark.Builti
 		dbBuildArgs:      buildArgsList,
 		liveUpdate:       liveUpdate,
 		matchInEnvVars:   matchInEnvVars,
-		sshSpecs:         ssh.Values,
-		secretSpecs:      secret.Values,
+		sshSpecs:         sshSpecs,
+		secretSpecs:      secrets,
 		ignores:          ignores,
 		onlys:            onlys,
 		entrypoint:       entrypointCmd,
@@ -419,6 +461,173 @@ func (b *customBuild) AttrNames() []string {
 	return []string{}
 }
 
+func (s *tiltfileState) ociArtifact(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var artifactRef, artifactType, mediaType string
+	var commandVal, commandBat, ignoreVal starlark.Value
+	files := value.NewLocalPathListUnpacker(thread)
+	deps := value.NewLocalPathListUnpacker(thread)
+	var matchInEnvVars bool
+
+	err := s.unpackArgs(fn.Name(), args, kwargs,
+		"ref", &artifactRef,
+		"files", &files,
+		"command?", &commandVal,
+		"deps?", &deps,
+		"artifact_type?", &artifactType,
+		"media_type?", &mediaType,
+		"command_bat?", &commandBat,
+		"match_in_env_vars?", &matchInEnvVars,
+		"ignore?", &ignoreVal,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	ref, err := container.ParseNamed(artifactRef)
+	if err != nil {
+		return nil, fmt.Errorf("Argument 1 (ref): can't parse %q: %v", artifactRef, err)
+	}
+
+	if len(files.Value) == 0 {
+		return nil, fmt.Errorf("Argument 2 (files) can't be empty")
+	}
+
+	ignores, err := parseValuesToStrings(ignoreVal, "ignore")
+	if err != nil {
+		return nil, err
+	}
+
+	command, err := value.ValueGroupToCmdHelper(thread, commandVal, commandBat, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Argument 3 (command): %v", err)
+	}
+
+	// If the files are checked in, they're the only things to watch.
+	// If a command generates them, watching the outputs would re-trigger
+	// the build on every run, so the inputs must be declared.
+	depPaths := deps.Value
+	if len(depPaths) == 0 {
+		if !command.Empty() {
+			return nil, fmt.Errorf("%s: deps must be specified when command is specified", fn.Name())
+		}
+		depPaths = files.Value
+	}
+
+	img := &dockerImage{
+		buildType:        OCIArtifactBuild,
+		workDir:          starkit.AbsWorkingDir(thread),
+		configurationRef: container.NewRefSelector(ref),
+		customCommand:    command,
+		customDeps:       depPaths,
+		ociFiles:         files.Value,
+		ociArtifactType:  artifactType,
+		ociMediaType:     mediaType,
+		matchInEnvVars:   matchInEnvVars,
+		ignores:          ignores,
+		tiltfilePath:     starkit.CurrentExecPath(thread),
+	}
+
+	err = s.buildIndex.addImage(img)
+	if err != nil {
+		return nil, err
+	}
+
+	return starlark.None, nil
+}
+
+func (s *tiltfileState) koBuild(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var imageRef, importPath, baseImage string
+	var liveUpdateVal, ignoreVal starlark.Value
+	deps := value.NewLocalPathListUnpacker(thread)
+	var buildFlags value.StringOrStringList
+	var env value.StringStringMap
+	var platform value.Stringable
+	var matchInEnvVars bool
+
+	err := s.unpackArgs(fn.Name(), args, kwargs,
+		"ref", &imageRef,
+		"importpath", &importPath,
+		"deps?", &deps,
+		"base_image?", &baseImage,
+		"build_flags?", &buildFlags,
+		"env?", &env,
+		"platform?", &platform,
+		"live_update?", &liveUpdateVal,
+		"match_in_env_vars?", &matchInEnvVars,
+		"ignore?", &ignoreVal,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	ref, err := container.ParseNamed(imageRef)
+	if err != nil {
+		return nil, fmt.Errorf("Argument 1 (ref): can't parse %q: %v", imageRef, err)
+	}
+
+	if importPath == "" {
+		return nil, fmt.Errorf("Argument 2 (importpath) can't be empty")
+	}
+
+	if baseImage == "" {
+		baseImage = model.KoDefaultBaseImage
+	}
+	_, err = container.ParseNamed(baseImage)
+	if err != nil {
+		return nil, fmt.Errorf("Argument base_image=%q not a valid image reference: %v", baseImage, err)
+	}
+
+	liveUpdate, err := s.liveUpdateFromSteps(thread, liveUpdateVal)
+	if err != nil {
+		return nil, errors.Wrap(err, "live_update")
+	}
+
+	ignores, err := parseValuesToStrings(ignoreVal, "ignore")
+	if err != nil {
+		return nil, err
+	}
+
+	envList := []string{}
+	for k, v := range env.AsMap() {
+		envList = append(envList, fmt.Sprintf("%s=%s", k, v))
+	}
+	sort.Strings(envList)
+
+	// By default, watch the whole Go module the Tiltfile lives in.
+	workDir := starkit.AbsWorkingDir(thread)
+	depPaths := deps.Value
+	if len(depPaths) == 0 {
+		depPaths = []string{workDir}
+	}
+
+	img := &dockerImage{
+		buildType:        KoBuild,
+		workDir:          workDir,
+		configurationRef: container.NewRefSelector(ref),
+		customDeps:       depPaths,
+		koImportPath:     importPath,
+		koBaseImage:      baseImage,
+		koBuildFlags:     buildFlags.Values,
+		koEnv:            envList,
+		platform:         platform.Value,
+		liveUpdate:       liveUpdate,
+		matchInEnvVars:   matchInEnvVars,
+		ignores:          ignores,
+		tiltfilePath:     starkit.CurrentExecPath(thread),
+
+		// So that a base image built by the Tiltfile is
+		// built first, and injected into the build.
+		dbDockerfile: dockerfile.Dockerfile(fmt.Sprintf("FROM %s", baseImage)),
+	}
+
+	err = s.buildIndex.addImage(img)
+	if err != nil {
+		return nil, err
+	}
+
+	return starlark.None, nil
+}
+
 func parseValuesToStrings(value starlark.Value, param string) ([]string, error) {
 
 	tempIgnores := starlarkValueOrSequenceToSlice(value)
@@ -508,6 +717,84 @@ func (s *tiltfileState) defaultRegistry(t *starlark.Thread, fn *starlark.Builtin
 	return starlark.None, nil
 }
 
+func (s *tiltfileState) buildkitBuilder(t *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if s.buildKit != nil {
+		return starlark.None, errors.New("buildkit builder already defined")
+	}
+
+	var address, serverName string
+	caCert := value.NewLocalPathUnpacker(t)
+	cert := value.NewLocalPathUnpacker(t)
+	key := value.NewLocalPathUnpacker(t)
+	if err := s.unpackArgs(fn.Name(), args, kwargs,
+		"address", &address,
+		"ca_cert?", &caCert,
+		"cert?", &cert,
+		"key?", &key,
+		"server_name?", &serverName); err != nil {
+		return nil, err
+	}
+
+	conn := &v1alpha1.BuildKitConnection{
+		Address:    address,
+		ServerName: serverName,
+		CACert:     caCert.Value,
+		Cert:       cert.Value,
+		Key:        key.Value,
+	}
+
+	ctx, err := starkit.ContextFromThread(t)
+	if err != nil {
+		return starlark.None, err
+	}
+
+	if err := conn.Validate(ctx); err != nil {
+		return starlark.None, errors.Wrapf(err.ToAggregate(), "validating buildkit_builder")
+	}
+
+	s.buildKit = conn
+
+	return starlark.None, nil
+}
+
+func (s *tiltfileState) containerEngine(t *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if s.engineSettings.Engine != "" {
+		return starlark.None, errors.New("container engine already set")
+	}
+
+	var engine, host string
+	if err := s.unpackArgs(fn.Name(), args, kwargs,
+		"engine", &engine,
+		"host?", &host); err != nil {
+		return nil, err
+	}
+
+	switch model.ContainerEngine(engine) {
+	case model.ContainerEngineDocker:
+	case model.ContainerEnginePodman:
+		if host == "" {
+			ctx, err := starkit.ContextFromThread(t)
+			if err != nil {
+				return starlark.None, err
+			}
+			host, err = docker.DetectPodmanHost(ctx)
+			if err != nil {
+				return starlark.None, errors.Wrap(err, fn.Name())
+			}
+		}
+	default:
+		return starlark.None, fmt.Errorf("%s: unknown engine %q. Valid engines: %v",
+			fn.Name(), engine, model.ContainerEngines)
+	}
+
+	s.engineSettings = model.ContainerEngineSettings{
+		Engine: model.ContainerEngine(engine),
+		Host:   host,
+	}
+
+	return starlark.None, nil
+}
+
 func (s *tiltfileState) dockerignoresFromPathsAndContextFilters(source string, paths []string, ignorePatterns []string, onlys []string, dbDockerfilePath string) ([]model.Dockerignore, error) {
 	var result []model.Dockerignore
 	dupeSet := map[string]bool{}
@@ -601,6 +888,12 @@ func (s *tiltfileState) dockerignoresForImage(image *dockerImage) ([]model.Docke
 	case DockerComposeBuild:
 		paths = append(paths, image.dbBuildPath)
 		source = fmt.Sprintf("docker_compose(%q)", ref)
+	case OCIArtifactBuild:
+		paths = append(paths, image.customDeps...)
+		source = fmt.Sprintf("oci_artifact(%q)", ref)
+	case KoBuild:
+		paths = append(paths, image.customDeps...)
+		source = fmt.Sprintf("ko_build(%q)", ref)
 	}
 	return s.dockerignoresFromPathsAndContextFilters(
 		source,
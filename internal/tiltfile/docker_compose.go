@@ -6,7 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -32,26 +32,36 @@ import (
 	"github.com/tilt-dev/tilt/pkg/model"
 )
 
-// dcResourceSet represents a single docker-compose config file and all its associated services
+// dcResourceSet represents a single docker-compose project and all its associated services
 type dcResourceSet struct {
 	Project v1alpha1.DockerComposeProject
 
+	// Whether the user named the project with project_name.
+	// All docker_compose() calls without a project_name add to the same project.
+	explicitName bool
+
+	// Whether the user selected profiles with profiles=.
+	// Otherwise, every service becomes a resource, regardless of its profiles.
+	explicitProfiles bool
+
 	configPaths  []string
 	services     []*dcService
 	tiltfilePath string
 }
 
-func (dc dcResourceSet) Empty() bool { return reflect.DeepEqual(dc, dcResourceSet{}) }
-
 func (s *tiltfileState) dockerCompose(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 	var configPaths starlark.Value
 	var projectName string
+	var profiles starlark.Value
+	var provider string
 	envFile := value.NewLocalPathUnpacker(thread)
 
 	err := s.unpackArgs(fn.Name(), args, kwargs,
 		"configPaths", &configPaths,
 		"env_file?", &envFile,
 		"project_name?", &projectName,
+		"profiles?", &profiles,
+		"provider?", &provider,
 	)
 	if err != nil {
 		return nil, err
@@ -63,17 +73,29 @@ func (s *tiltfileState) dockerCompose(thread *starlark.Thread, fn *starlark.Buil
 		return nil, fmt.Errorf("Nothing to compose")
 	}
 
-	dc := s.dc
-
 	currentTiltfilePath := starkit.CurrentExecPath(thread)
+	explicitName := projectName != ""
+	dc := s.findDCResourceSet(projectName)
+	if projectName == "" {
+		if dc != nil {
+			projectName = dc.Project.Name
+		} else {
+			projectName = model.NormalizeName(filepath.Base(filepath.Dir(currentTiltfilePath)))
+			dc = s.findDCResourceSet(projectName)
+		}
+	}
+
+	isNewProject := dc == nil
+	if isNewProject {
+		dc = &dcResourceSet{explicitName: explicitName}
+	}
+
 	if dc.tiltfilePath != "" && dc.tiltfilePath != currentTiltfilePath {
 		return starlark.None, fmt.Errorf("Cannot load docker-compose files from two different Tiltfiles.\n"+
 			"docker-compose must have a single working directory:\n"+
-			"(%s, %s)", dc.tiltfilePath, currentTiltfilePath)
-	}
-
-	if projectName == "" {
-		projectName = model.NormalizeName(filepath.Base(filepath.Dir(currentTiltfilePath)))
+			"(%s, %s)\n"+
+			"To run a separate docker-compose project, give each one a project_name.",
+			dc.tiltfilePath, currentTiltfilePath)
 	}
 
 	project := v1alpha1.DockerComposeProject{
@@ -81,6 +103,28 @@ func (s *tiltfileState) dockerCompose(thread *starlark.Thread, fn *starlark.Buil
 		ProjectPath: dc.Project.ProjectPath,
 		Name:        projectName,
 		EnvFile:     envFile.Value,
+		Profiles:    dc.Project.Profiles,
+		Provider:    dc.Project.Provider,
+	}
+
+	if provider != "" {
+		// A relative path to a compose binary is relative to the Tiltfile,
+		// but bare names like "podman" are providers or binaries on the PATH.
+		if strings.ContainsRune(provider, '/') || strings.ContainsRune(provider, filepath.Separator) {
+			provider = starkit.AbsPath(thread, provider)
+		}
+		project.Provider = provider
+	}
+
+	explicitProfiles := dc.explicitProfiles
+	if profiles != nil && profiles != starlark.None {
+		var profilesVal value.StringOrStringList
+		err = profilesVal.Unpack(profiles)
+		if err != nil {
+			return nil, fmt.Errorf("%s: for parameter \"profiles\": %v", fn.Name(), err)
+		}
+		project.Profiles = profilesVal.Values
+		explicitProfiles = true
 	}
 
 	if project.EnvFile != "" {
@@ -139,20 +183,42 @@ func (s *tiltfileState) dockerCompose(thread *starlark.Thread, fn *starlark.Buil
 		project.ProjectPath = filepath.Dir(currentTiltfilePath)
 	}
 
-	services, err := parseDCConfig(s.ctx, s.dcCli, project)
+	// Watch env_files, extends targets, and included files before loading,
+	// so that fixing a broken one reloads the project.
+	for _, f := range dockercompose.ReferencedFiles(project.ProjectPath, project.ConfigPaths) {
+		err = io.RecordReadPath(thread, io.WatchFileOnly, f)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	services, err := parseDCConfig(s.ctx, s.dcCli, project, explicitProfiles)
 	if err != nil {
 		return nil, err
 	}
 
+	// Re-parsing a project replaces all of its services.
+	for _, svc := range dc.services {
+		delete(s.dcByName, svc.Name)
+	}
+
 	for _, svc := range services {
-		previousSvc := s.dcByName[svc.Name]
-		if previousSvc != nil {
-			delete(s.dcByName, svc.Name)
+		// Services in different projects can have the same name,
+		// but resource names must be unique. Qualify the later one
+		// with its project name.
+		if s.dcByName[svc.Name] != nil {
+			svc.Name = fmt.Sprintf("%s-%s", projectName, svc.Name)
 		}
+
 		err := s.checkResourceConflict(svc.Name)
 		if err != nil {
 			return nil, err
 		}
+		if s.dcByName[svc.Name] != nil {
+			return nil, fmt.Errorf("docker_compose: service %q in project %q conflicts with an existing resource named %q",
+				svc.ServiceConfig.Name, projectName, svc.Name)
+		}
+
 		svc.Options = s.dcResOptions[svc.Name]
 		for _, f := range svc.ServiceConfig.EnvFile {
 			if !filepath.IsAbs(f) {
@@ -163,19 +229,52 @@ func (s *tiltfileState) dockerCompose(thread *starlark.Thread, fn *starlark.Buil
 				return nil, err
 			}
 		}
+		for _, f := range svc.fileObjectPaths() {
+			err = io.RecordReadPath(thread, io.WatchFileOnly, f)
+			if err != nil {
+				return nil, err
+			}
+		}
 		s.dcByName[svc.Name] = svc
 	}
 
-	s.dc = dcResourceSet{
-		Project:      project,
-		configPaths:  project.ConfigPaths,
-		services:     services,
-		tiltfilePath: currentTiltfilePath,
+	dc.Project = project
+	dc.explicitProfiles = explicitProfiles
+	dc.configPaths = project.ConfigPaths
+	dc.services = services
+	dc.tiltfilePath = currentTiltfilePath
+	if isNewProject {
+		s.dc = append(s.dc, dc)
 	}
 
 	return starlark.None, nil
 }
 
+// Finds the docker-compose project that a docker_compose() call adds to.
+//
+// Calls without a project name add to the first project
+// that was also loaded without a name.
+func (s *tiltfileState) findDCResourceSet(projectName string) *dcResourceSet {
+	for _, dc := range s.dc {
+		if projectName == "" && !dc.explicitName {
+			return dc
+		}
+		if projectName != "" && dc.Project.Name == projectName {
+			return dc
+		}
+	}
+	return nil
+}
+
+// All the docker-compose services, across all projects.
+func (s *tiltfileState) dcServices() []*dcService {
+	var result []*dcService
+	for _, dc := range s.dc {
+		result = append(result, dc.services...)
+	}
+	return result
+}
+
 // DCResource allows you to adjust specific settings on a DC resource that we assume
 // to be defined in a `docker_compose.yml`
 func (s *tiltfileState) dcResource(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
@@ -259,8 +358,9 @@ func (s *tiltfileState) dcResource(thread *starlark.Thread, fn *starlark.Builtin
 }
 
 func (s *tiltfileState) getDCService(name string) (*dcService, error) {
-	allNames := make([]string, len(s.dc.services))
-	for i, svc := range s.dc.services {
+	services := s.dcServices()
+	allNames := make([]string, len(services))
+	for i, svc := range services {
 		if svc.Name == name {
 			return svc, nil
 		}
@@ -272,6 +372,8 @@ func (s *tiltfileState) getDCService(name string) (*dcService, error) {
 
 // A docker-compose service, according to Tilt.
 type dcService struct {
+	// The name of the Tilt resource. Usually the same as the service name,
+	// unless another docker-compose project has a service with the same name.
 	Name string
 
 	// these are the host machine paths that DC will sync from the local volume into the container
@@ -290,9 +392,26 @@ type dcService struct {
 	ImageMapDeps   []string
 	PublishedPorts []int
 
+	// The secrets and configs the service uses, with hashes of their contents.
+	Secrets []v1alpha1.DockerComposeFileObject
+	Configs []v1alpha1.DockerComposeFileObject
+
 	Options *dcResourceOptions
 }
 
+// The files behind the service's secrets and configs.
+func (svc dcService) fileObjectPaths() []string {
+	var result []string
+	for _, objs := range [][]v1alpha1.DockerComposeFileObject{svc.Secrets, svc.Configs} {
+		for _, obj := range objs {
+			if obj.File != "" {
+				result = append(result, obj.File)
+			}
+		}
+	}
+	return result
+}
+
 // Options set via dc_resource
 type dcResourceOptions struct {
 	imageRefFromUser reference.Named
@@ -364,18 +483,29 @@ func dockerComposeConfigToService(projectName string, svcConfig types.ServiceCon
 	return svc, nil
 }
 
-func parseDCConfig(ctx context.Context, dcc dockercompose.DockerComposeClient, spec v1alpha1.DockerComposeProject) ([]*dcService, error) {
+func parseDCConfig(ctx context.Context, dcc dockercompose.DockerComposeClient, spec v1alpha1.DockerComposeProject, explicitProfiles bool) ([]*dcService, error) {
 	proj, err := dcc.Project(ctx, spec)
 	if err != nil {
 		return nil, err
 	}
 
+	// The client keeps every service when no profiles are active.
+	// But if the user explicitly asked for no profiles, follow
+	// docker-compose and only keep services without profiles.
+	if explicitProfiles && len(spec.Profiles) == 0 {
+		err = dockercompose.ApplyProfiles(proj, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	var services []*dcService
 	err = proj.WithServices(proj.ServiceNames(), func(svcConfig types.ServiceConfig) error {
 		svc, err := dockerComposeConfigToService(proj.Name, svcConfig)
 		if err != nil {
 			return errors.Wrapf(err, "getting service %s", svcConfig.Name)
 		}
+		svc.Secrets, svc.Configs = dockercompose.ServiceFileObjects(proj, svcConfig)
 		services = append(services, &svc)
 		return nil
 	})
@@ -386,17 +516,29 @@ func parseDCConfig(ctx context.Context, dcc dockercompose.DockerComposeClient, s
 	return services, nil
 }
 
-func (s *tiltfileState) dcServiceToManifest(service *dcService, dcSet dcResourceSet, iTargets []model.ImageTarget) (model.Manifest, error) {
+func (s *tiltfileState) dcServiceToManifest(service *dcService, dcSet *dcResourceSet, iTargets []model.ImageTarget) (model.Manifest, error) {
 	options := service.Options
 	if options == nil {
 		options = newDcResourceOptions()
 	}
 
+	dependsOn := dependsOnFromConfig(service.ServiceConfig.DependsOn)
+	var labels map[string]string
+	if len(service.ServiceConfig.Labels) > 0 {
+		labels = make(map[string]string, len(service.ServiceConfig.Labels))
+		for k, v := range service.ServiceConfig.Labels {
+			labels[k] = v
+		}
+	}
 	dcInfo := model.DockerComposeTarget{
 		Name: model.TargetName(service.Name),
 		Spec: v1alpha1.DockerComposeServiceSpec{
-			Service: service.Name,
-			Project: dcSet.Project,
+			Service:   service.ServiceConfig.Name,
+			Project:   dcSet.Project,
+			DependsOn: dependsOn,
+			Labels:    labels,
+			Secrets:   service.Secrets,
+			Configs:   service.Configs,
 		},
 		ServiceYAML: string(service.ServiceYAML),
 		Links:       options.Links,
@@ -412,9 +554,17 @@ func (s *tiltfileState) dcServiceToManifest(service *dcService, dcSet dcResource
 		return model.Manifest{}, err
 	}
 
+	// Services in `depends_on` are also resource dependencies, so that
+	// Tilt starts them in the same order as `docker compose up`.
 	var mds []model.ManifestName
+	for _, dep := range dependsOn {
+		mds = append(mds, model.ManifestName(dep.Service))
+	}
 	for _, md := range options.resourceDeps {
-		mds = append(mds, model.ManifestName(md))
+		mn := model.ManifestName(md)
+		if !manifestNamesContain(mds, mn) {
+			mds = append(mds, mn)
+		}
 	}
 
 	for i, iTarget := range iTargets {
@@ -435,3 +585,31 @@ func (s *tiltfileState) dcServiceToManifest(service *dcService, dcSet dcResource
 
 	return m, nil
 }
+
+// Converts `depends_on` in the compose file to the API model, sorted by service.
+func dependsOnFromConfig(config types.DependsOnConfig) []v1alpha1.DockerComposeServiceDependency {
+	var result []v1alpha1.DockerComposeServiceDependency
+	for service, dep := range config {
+		condition := dep.Condition
+		if condition == "" {
+			condition = v1alpha1.DockerComposeConditionServiceStarted
+		}
+		result = append(result, v1alpha1.DockerComposeServiceDependency{
+			Service:   service,
+			Condition: condition,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Service < result[j].Service
+	})
+	return result
+}
+
+func manifestNamesContain(names []model.ManifestName, name model.ManifestName) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,140 @@
+package tiltfile
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/compose-spec/compose-go/types"
+	"github.com/docker/distribution/reference"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+
+	"github.com/tilt-dev/tilt/internal/tiltfile/value"
+)
+
+// Returns the compose project that docker_compose() loaded, as Compose resolved it
+// (with env vars interpolated, extends and includes merged, and profiles applied),
+// so that Tiltfiles can generate resources from the services.
+func (s *tiltfileState) dcProject(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var projectName string
+	if err := s.unpackArgs(fn.Name(), args, kwargs,
+		"project_name?", &projectName,
+	); err != nil {
+		return nil, err
+	}
+
+	dc := s.findDCResourceSet(projectName)
+	if dc == nil {
+		if projectName == "" {
+			return nil, fmt.Errorf("%s: no project loaded. Call docker_compose() first", fn.Name())
+		}
+		return nil, fmt.Errorf("%s: no project named %q. Call docker_compose(project_name=%q) first", fn.Name(), projectName, projectName)
+	}
+
+	services := starlark.NewDict(len(dc.services))
+	for _, svc := range dc.services {
+		err := services.SetKey(starlark.String(svc.ServiceConfig.Name), dcServiceToStarlark(svc))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	project := starlarkstruct.FromStringDict(starlark.String("dc_project"), starlark.StringDict{
+		"name":         starlark.String(dc.Project.Name),
+		"project_path": starlark.String(dc.Project.ProjectPath),
+		"config_paths": value.StringSliceToList(dc.Project.ConfigPaths),
+		"profiles":     value.StringSliceToList(dc.Project.Profiles),
+		"services":     services,
+	})
+
+	// The project is a snapshot. Changing it doesn't change the resources.
+	project.Freeze()
+	return project, nil
+}
+
+func dcServiceToStarlark(svc *dcService) starlark.Value {
+	config := svc.ServiceConfig
+
+	var build starlark.Value = starlark.None
+	if config.Build != nil {
+		build = starlarkstruct.FromStringDict(starlark.String("dc_build"), starlark.StringDict{
+			"context":    starlark.String(config.Build.Context),
+			"dockerfile": starlark.String(config.Build.Dockerfile),
+			"target":     starlark.String(config.Build.Target),
+			"args":       mappingToStarlark(config.Build.Args),
+		})
+	}
+
+	ports := []starlark.Value{}
+	for _, p := range config.Ports {
+		ports = append(ports, starlarkstruct.FromStringDict(starlark.String("dc_port"), starlark.StringDict{
+			"target":    starlark.MakeUint(uint(p.Target)),
+			"published": starlark.String(p.Published),
+			"protocol":  starlark.String(p.Protocol),
+			"host_ip":   starlark.String(p.HostIP),
+		}))
+	}
+
+	volumes := []starlark.Value{}
+	for _, v := range config.Volumes {
+		volumes = append(volumes, starlarkstruct.FromStringDict(starlark.String("dc_volume"), starlark.StringDict{
+			"type":      starlark.String(v.Type),
+			"source":    starlark.String(v.Source),
+			"target":    starlark.String(v.Target),
+			"read_only": starlark.Bool(v.ReadOnly),
+		}))
+	}
+
+	dependsOn := make([]string, 0, len(config.DependsOn))
+	for name := range config.DependsOn {
+		dependsOn = append(dependsOn, name)
+	}
+	sort.Strings(dependsOn)
+
+	return starlarkstruct.FromStringDict(starlark.String("dc_service"), starlark.StringDict{
+		"name":          starlark.String(config.Name),
+		"resource_name": starlark.String(svc.Name),
+		"image":         starlark.String(reference.FamiliarString(svc.imageRefFromConfig)),
+		"build":         build,
+		"ports":         starlark.NewList(ports),
+		"volumes":       starlark.NewList(volumes),
+		"profiles":      value.StringSliceToList(config.Profiles),
+		"depends_on":    value.StringSliceToList(dependsOn),
+		"environment":   mappingToStarlark(config.Environment),
+		"labels":        stringMapToStarlark(config.Labels),
+	})
+}
+
+// Converts a Compose mapping to a dict. Variables without a value
+// (like `environment: [DEBUG]`) map to None.
+func mappingToStarlark(m types.MappingWithEquals) *starlark.Dict {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	result := starlark.NewDict(len(m))
+	for _, k := range keys {
+		var v starlark.Value = starlark.None
+		if m[k] != nil {
+			v = starlark.String(*m[k])
+		}
+		_ = result.SetKey(starlark.String(k), v)
+	}
+	return result
+}
+
+func stringMapToStarlark(m map[string]string) *starlark.Dict {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	result := starlark.NewDict(len(m))
+	for _, k := range keys {
+		_ = result.SetKey(starlark.String(k), starlark.String(m[k]))
+	}
+	return result
+}
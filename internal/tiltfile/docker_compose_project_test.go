@@ -0,0 +1,88 @@
+package tiltfile
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestDCProject(t *testing.T) {
+	f := newFixture(t)
+
+	f.dockerfile(filepath.Join("web", "Dockerfile"))
+	f.file("docker-compose.yml", `services:
+  web:
+    build:
+      context: ./web
+      target: dev
+      args:
+        VERSION: "1"
+    ports:
+      - "8080:80"
+    volumes:
+      - ./web/src:/app/src
+    environment:
+      MODE: dev
+      TOKEN:
+    labels:
+      team: frontend
+    depends_on:
+      - redis
+  redis:
+    image: redis:7
+    profiles: ["cache"]
+`)
+	f.file("Tiltfile", `
+docker_compose('docker-compose.yml', project_name='shop')
+p = dc_project('shop')
+if p.name != 'shop':
+  fail('bad name: %s' % p.name)
+if sorted(p.services.keys()) != ['redis', 'web']:
+  fail('bad services: %s' % p.services.keys())
+
+redis = p.services['redis']
+if redis.image != 'redis:7' or redis.build != None:
+  fail('bad redis image: %s' % redis.image)
+if redis.profiles != ['cache']:
+  fail('bad redis profiles: %s' % redis.profiles)
+
+web = p.services['web']
+if web.build.target != 'dev' or web.build.args != {'VERSION': '1'}:
+  fail('bad web build: %s' % web.build)
+if not web.build.context.endswith('web'):
+  fail('bad web build context: %s' % web.build.context)
+if [(x.target, x.published) for x in web.ports] != [(80, '8080')]:
+  fail('bad web ports: %s' % web.ports)
+if [v.target for v in web.volumes] != ['/app/src'] or web.volumes[0].type != 'bind':
+  fail('bad web volumes: %s' % web.volumes)
+if web.environment != {'MODE': 'dev', 'TOKEN': None}:
+  fail('bad web environment: %s' % web.environment)
+if web.labels != {'team': 'frontend'} or web.depends_on != ['redis']:
+  fail('bad web labels or deps: %s %s' % (web.labels, web.depends_on))
+
+for svc in p.services.values():
+  dc_resource(svc.resource_name, labels=svc.profiles or ['app'])
+`)
+
+	f.load()
+	f.assertNextManifest("redis", resourceLabels("cache"))
+	f.assertNextManifest("web", resourceLabels("app"))
+	f.assertNoMoreManifests()
+}
+
+func TestDCProjectNotLoaded(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", "dc_project()")
+	f.loadErrString("dc_project: no project loaded. Call docker_compose() first")
+}
+
+func TestDCProjectIsReadOnly(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("docker-compose.yml", simpleConfig)
+	f.file("Tiltfile", `
+docker_compose('docker-compose.yml')
+dc_project().services['foo'].environment['X'] = 'y'
+`)
+	f.loadErrString("cannot insert into frozen hash table")
+}
@@ -157,7 +157,7 @@ func (f dcFixture) parse(configOutput string) []*dcService {
 
 	f.dcCli.ConfigOutput = configOutput
 
-	services, err := parseDCConfig(f.ctx, f.dcCli, v1alpha1.DockerComposeProject{ConfigPaths: []string{"doesn't-matter.yml"}})
+	services, err := parseDCConfig(f.ctx, f.dcCli, v1alpha1.DockerComposeProject{ConfigPaths: []string{"doesn't-matter.yml"}}, false)
 	if err != nil {
 		f.t.Fatalf("dcFixture.Parse: %v", err)
 	}
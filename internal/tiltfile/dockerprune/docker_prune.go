@@ -6,9 +6,11 @@ import (
 
 	"go.starlark.net/starlark"
 
+	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/pkg/model"
 
 	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+	"github.com/tilt-dev/tilt/internal/tiltfile/value"
 )
 
 // Implements functions for dealing with Docker Prune settings.
@@ -21,10 +23,11 @@ func NewPlugin() Plugin {
 
 func (e Plugin) NewState() interface{} {
 	return model.DockerPruneSettings{
-		Enabled:    true,
-		MaxAge:     model.DockerPruneDefaultMaxAge,
-		Interval:   model.DockerPruneDefaultInterval,
-		KeepRecent: model.DockerPruneDefaultKeepRecent,
+		Enabled:      true,
+		MaxAge:       model.DockerPruneDefaultMaxAge,
+		Interval:     model.DockerPruneDefaultInterval,
+		KeepRecent:   model.DockerPruneDefaultKeepRecent,
+		VolumeMaxAge: model.DockerPruneDefaultVolumeMaxAge,
 	}
 }
 
@@ -33,15 +36,23 @@ func (e Plugin) OnStart(env *starkit.Environment) error {
 }
 
 func (e Plugin) dockerPruneSettings(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
-	var disable bool
+	var disable, pruneVolumes bool
 	var keepRecent starlark.Value
-	var intervalHrs, numBuilds, maxAgeMins int
+	var keepRecentByImage *starlark.Dict
+	var keepRefs value.ImageList
+	var intervalHrs, numBuilds, maxAgeMins, volumeMaxAgeMins int
+	var cluster string
 	if err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs,
 		"disable?", &disable,
 		"max_age_mins?", &maxAgeMins,
 		"num_builds?", &numBuilds,
 		"interval_hrs?", &intervalHrs,
-		"keep_recent?", &keepRecent); err != nil {
+		"keep_recent?", &keepRecent,
+		"keep_recent_by_image?", &keepRecentByImage,
+		"keep_refs?", &keepRefs,
+		"prune_volumes?", &pruneVolumes,
+		"volume_max_age_mins?", &volumeMaxAgeMins,
+		"cluster?", &cluster); err != nil {
 		return nil, err
 	}
 
@@ -50,7 +61,12 @@ func (e Plugin) dockerPruneSettings(thread *starlark.Thread, fn *starlark.Builti
 			"only one of `num_builds` and `interval_hrs`")
 	}
 
-	err := starkit.SetState(thread, func(settings model.DockerPruneSettings) (model.DockerPruneSettings, error) {
+	recentByImage, err := unpackKeepRecentByImage(fn.Name(), keepRecentByImage)
+	if err != nil {
+		return nil, err
+	}
+
+	apply := func(settings model.DockerPruneSettings) (model.DockerPruneSettings, error) {
 		settings.Enabled = !disable
 		if maxAgeMins != 0 {
 			settings.MaxAge = time.Duration(maxAgeMins) * time.Minute
@@ -66,12 +82,74 @@ func (e Plugin) dockerPruneSettings(thread *starlark.Thread, fn *starlark.Builti
 			}
 			settings.KeepRecent = recent
 		}
+		settings.KeepRecentByImage = recentByImage
+		settings.KeepRefs = nil
+		for _, ref := range keepRefs {
+			settings.KeepRefs = append(settings.KeepRefs, ref.String())
+		}
+		settings.PruneVolumes = pruneVolumes
+		if volumeMaxAgeMins != 0 {
+			settings.VolumeMaxAge = time.Duration(volumeMaxAgeMins) * time.Minute
+		}
+		return settings, nil
+	}
+
+	err = starkit.SetState(thread, func(settings model.DockerPruneSettings) (model.DockerPruneSettings, error) {
+		if cluster == "" {
+			return apply(settings)
+		}
+
+		// Settings for a cluster start from the defaults, not from the settings
+		// for every other cluster.
+		clusterSettings, ok := settings.ByCluster[cluster]
+		if !ok {
+			clusterSettings = e.NewState().(model.DockerPruneSettings)
+		}
+		clusterSettings, err := apply(clusterSettings)
+		if err != nil {
+			return settings, err
+		}
+
+		byCluster := make(map[string]model.DockerPruneSettings, len(settings.ByCluster)+1)
+		for k, v := range settings.ByCluster {
+			byCluster[k] = v
+		}
+		byCluster[cluster] = clusterSettings
+		settings.ByCluster = byCluster
 		return settings, nil
 	})
 
 	return starlark.None, err
 }
 
+// Unpacks a dict of image name => number of recent builds to keep.
+func unpackKeepRecentByImage(fnName string, d *starlark.Dict) (map[string]int, error) {
+	if d == nil || d.Len() == 0 {
+		return nil, nil
+	}
+
+	result := make(map[string]int, d.Len())
+	for _, item := range d.Items() {
+		key, ok := value.AsString(item[0])
+		if !ok {
+			return nil, fmt.Errorf("%s: keep_recent_by_image: keys must be image names, but got %s", fnName, item[0].Type())
+		}
+		ref, err := container.ParseNamed(key)
+		if err != nil {
+			return nil, fmt.Errorf("%s: keep_recent_by_image: %q must be a valid image reference: %v", fnName, key, err)
+		}
+		recent, err := starlark.AsInt32(item[1])
+		if err != nil {
+			return nil, fmt.Errorf("%s: keep_recent_by_image[%q]: %v", fnName, key, err)
+		}
+		if recent < 0 {
+			return nil, fmt.Errorf("%s: keep_recent_by_image[%q]: must be non-negative, got %d", fnName, key, recent)
+		}
+		result[ref.Name()] = recent
+	}
+	return result, nil
+}
+
 var _ starkit.StatefulPlugin = Plugin{}
 
 func MustState(model starkit.Model) model.DockerPruneSettings {
@@ -45,6 +45,73 @@ docker_prune_settings(keep_recent=5)
 	assert.Equal(t, model.DockerPruneDefaultKeepRecent, MustState(result).KeepRecent)
 }
 
+func TestDockerPruneVolumes(t *testing.T) {
+	f := NewFixture(t)
+	f.File("Tiltfile", `
+docker_prune_settings(prune_volumes=True, volume_max_age_mins=30)
+`)
+	result, err := f.ExecFile("Tiltfile")
+	assert.NoError(t, err)
+	assert.True(t, MustState(result).PruneVolumes)
+	assert.Equal(t, 30*time.Minute, MustState(result).VolumeMaxAge)
+
+	f.File("Tiltfile.empty", `
+`)
+	result, err = f.ExecFile("Tiltfile.empty")
+	assert.NoError(t, err)
+	assert.False(t, MustState(result).PruneVolumes)
+	assert.Equal(t, model.DockerPruneDefaultVolumeMaxAge, MustState(result).VolumeMaxAge)
+}
+
 func NewFixture(tb testing.TB) *starkit.Fixture {
 	return starkit.NewFixture(tb, NewPlugin())
 }
+
+func TestDockerPruneKeepRefs(t *testing.T) {
+	f := NewFixture(t)
+	f.File("Tiltfile", `
+docker_prune_settings(keep_refs=['my-base', 'gcr.io/foo/bar:stable'], keep_recent_by_image={'my-base': 5})
+`)
+	result, err := f.ExecFile("Tiltfile")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"docker.io/library/my-base", "gcr.io/foo/bar:stable"}, MustState(result).KeepRefs)
+	assert.Equal(t, map[string]int{"docker.io/library/my-base": 5}, MustState(result).KeepRecentByImage)
+}
+
+func TestDockerPruneKeepRefsInvalid(t *testing.T) {
+	f := NewFixture(t)
+	f.File("Tiltfile", `
+docker_prune_settings(keep_recent_by_image={'my-base': -1})
+`)
+	_, err := f.ExecFile("Tiltfile")
+	assert.ErrorContains(t, err, "must be non-negative")
+
+	f.File("Tiltfile.badref", `
+docker_prune_settings(keep_refs=['Not A Ref'])
+`)
+	_, err = f.ExecFile("Tiltfile.badref")
+	assert.ErrorContains(t, err, "must be a valid image reference")
+}
+
+func TestDockerPruneCluster(t *testing.T) {
+	f := NewFixture(t)
+	f.File("Tiltfile", `
+docker_prune_settings(max_age_mins=30)
+docker_prune_settings(cluster='docker', num_builds=3)
+docker_prune_settings(cluster='remote', disable=True)
+`)
+	result, err := f.ExecFile("Tiltfile")
+	assert.NoError(t, err)
+
+	settings := MustState(result)
+	assert.Equal(t, 30*time.Minute, settings.MaxAge)
+	assert.Equal(t, 0, settings.NumBuilds)
+
+	docker := settings.ForCluster("docker")
+	assert.True(t, docker.Enabled)
+	assert.Equal(t, 3, docker.NumBuilds)
+	assert.Equal(t, model.DockerPruneDefaultMaxAge, docker.MaxAge)
+
+	assert.False(t, settings.ForCluster("remote").Enabled)
+	assert.Equal(t, 30*time.Minute, settings.ForCluster("default").MaxAge)
+}
@@ -0,0 +1,130 @@
+package tiltfile
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.starlark.net/starlark"
+
+	"github.com/tilt-dev/tilt/internal/tiltfile/links"
+	"github.com/tilt-dev/tilt/internal/tiltfile/probe"
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+	"github.com/tilt-dev/tilt/internal/tiltfile/value"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// Declares a service that Tilt doesn't deploy (e.g., a shared staging API
+// or a SaaS dependency), so that other resources can depend on it.
+//
+// We model it as a local resource with no commands. Instead of running a
+// serve_cmd, Tilt polls the health check, which defaults to a GET of the URL.
+func (s *tiltfileState) externalResource(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var name value.Name
+	var rawURL string
+	var healthCheck probe.Probe
+	var resourceDepsVal starlark.Sequence
+	var links links.LinkList
+	var labels value.LabelSet
+	autoInit := true
+
+	if err := s.unpackArgs(fn.Name(), args, kwargs,
+		"name", &name,
+		"url", &rawURL,
+		"health_check?", &healthCheck,
+		"resource_deps?", &resourceDepsVal,
+		"links?", &links,
+		"labels?", &labels,
+		"auto_init?", &autoInit,
+	); err != nil {
+		return nil, err
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("%s: url must be absolute, like https://staging.example.com, got %q", fn.Name(), rawURL)
+	}
+
+	resourceDeps, err := value.SequenceToStringSlice(resourceDepsVal)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: resource_deps", fn.Name())
+	}
+
+	probeSpec := healthCheck.Spec()
+	if probeSpec == nil {
+		probeSpec = defaultHealthCheck(u)
+	}
+
+	res := &localResource{
+		name:           string(name),
+		threadDir:      filepath.Dir(starkit.CurrentExecPath(thread)),
+		triggerMode:    TriggerModeUnset,
+		autoInit:       autoInit,
+		resourceDeps:   resourceDeps,
+		links:          append([]model.Link{{URL: u}}, links.Links...),
+		labels:         labels.Values,
+		readinessProbe: probeSpec,
+		externalURL:    u.String(),
+	}
+
+	err = s.checkResourceConflict(res.name)
+	if err != nil {
+		return nil, err
+	}
+	s.localResources = append(s.localResources, res)
+	s.localByName[res.name] = res
+
+	return starlark.None, nil
+}
+
+// HTTP(S) URLs get a GET of the URL, and other URLs with a port
+// (e.g., postgres://db.internal:5432) get a TCP check of the port.
+//
+// Returns nil if we don't know how to check the URL, in which case
+// the resource is always healthy.
+func defaultHealthCheck(u *url.URL) *v1alpha1.Probe {
+	port, _ := strconv.Atoi(u.Port())
+
+	var scheme v1alpha1.URIScheme
+	switch strings.ToLower(u.Scheme) {
+	case "http":
+		scheme = v1alpha1.URISchemeHTTP
+		if port == 0 {
+			port = 80
+		}
+	case "https":
+		scheme = v1alpha1.URISchemeHTTPS
+		if port == 0 {
+			port = 443
+		}
+	}
+
+	if scheme != "" {
+		return &v1alpha1.Probe{
+			Handler: v1alpha1.Handler{
+				HTTPGet: &v1alpha1.HTTPGetAction{
+					Scheme: scheme,
+					Host:   u.Hostname(),
+					Port:   int32(port),
+					Path:   u.RequestURI(),
+				},
+			},
+		}
+	}
+
+	if port == 0 {
+		return nil
+	}
+	return &v1alpha1.Probe{
+		Handler: v1alpha1.Handler{
+			TCPSocket: &v1alpha1.TCPSocketAction{
+				Host: u.Hostname(),
+				Port: int32(port),
+			},
+		},
+	}
+}
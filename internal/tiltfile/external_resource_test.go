@@ -0,0 +1,112 @@
+package tiltfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestExternalResourceHTTP(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+external_resource("payments", "https://payments.example.com/healthz?deep=1",
+                  links=[link("https://payments.example.com/docs", "docs")])
+local_resource("checkout", "echo hi", resource_deps=["payments"])
+`)
+
+	f.load()
+
+	m := f.assertNextManifest("payments")
+	lt := m.LocalTarget()
+	assert.True(t, lt.IsExternal())
+	assert.Equal(t, "https://payments.example.com/healthz?deep=1", lt.ExternalURL)
+	assert.Nil(t, lt.UpdateCmdSpec)
+	assert.True(t, lt.ServeCmd.Empty())
+	assert.Equal(t, &v1alpha1.Probe{
+		Handler: v1alpha1.Handler{
+			HTTPGet: &v1alpha1.HTTPGetAction{
+				Scheme: v1alpha1.URISchemeHTTPS,
+				Host:   "payments.example.com",
+				Port:   443,
+				Path:   "/healthz?deep=1",
+			},
+		},
+	}, lt.ReadinessProbe)
+
+	require.Len(t, lt.Links, 2)
+	assert.Equal(t, "https://payments.example.com/healthz?deep=1", lt.Links[0].URLString())
+	assert.Equal(t, "docs", lt.Links[1].Name)
+
+	checkout := f.assertNextManifest("checkout")
+	assert.Equal(t, []model.ManifestName{"payments"}, checkout.ResourceDependencies)
+}
+
+func TestExternalResourceTCP(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+external_resource("db", "postgres://db.internal:5432/app")
+external_resource("queue", "amqp://queue.internal")
+`)
+
+	f.load()
+
+	m := f.assertNextManifest("db")
+	assert.Equal(t, &v1alpha1.Probe{
+		Handler: v1alpha1.Handler{
+			TCPSocket: &v1alpha1.TCPSocketAction{Host: "db.internal", Port: 5432},
+		},
+	}, m.LocalTarget().ReadinessProbe)
+
+	// Without a port, we don't know how to check it.
+	m = f.assertNextManifest("queue")
+	assert.True(t, m.LocalTarget().IsExternal())
+	assert.Nil(t, m.LocalTarget().ReadinessProbe)
+}
+
+func TestExternalResourceCustomHealthCheck(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+external_resource("api", "https://api.example.com",
+                  health_check=probe(period_secs=30, exec=exec_action(["./check-api.sh"])),
+                  labels=["shared"], auto_init=False)
+`)
+
+	f.load()
+
+	m := f.assertNextManifest("api")
+	probe := m.LocalTarget().ReadinessProbe
+	require.NotNil(t, probe)
+	assert.Equal(t, int32(30), probe.PeriodSeconds)
+	assert.Equal(t, []string{"./check-api.sh"}, probe.Exec.Command)
+	assert.Nil(t, probe.HTTPGet)
+	assert.Equal(t, map[string]string{"shared": "shared"}, m.Labels)
+	assert.False(t, m.TriggerMode.AutoInitial())
+}
+
+func TestExternalResourceInvalidURL(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+external_resource("api", "api.example.com")
+`)
+
+	f.loadErrString(`external_resource: url must be absolute, like https://staging.example.com, got "api.example.com"`)
+}
+
+func TestExternalResourceConflict(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+local_resource("api", "echo hi")
+external_resource("api", "https://api.example.com")
+`)
+
+	f.loadErrString(`local_resource named "api" already exists`)
+}
@@ -1,9 +1,13 @@
 package tiltfile
 
 import (
+	"fmt"
+	"sort"
+
 	"go.starlark.net/starlark"
 
 	"github.com/tilt-dev/tilt/internal/feature"
+	"github.com/tilt-dev/tilt/internal/tiltfile/value"
 )
 
 func (s *tiltfileState) enableFeature(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
@@ -13,12 +17,9 @@ func (s *tiltfileState) enableFeature(thread *starlark.Thread, fn *starlark.Buil
 		return nil, err
 	}
 
-	err = s.features.Set(flag, true)
+	err = s.setFeature(flag, true)
 	if err != nil {
-		if _, ok := err.(feature.ObsoleteError); !ok {
-			return nil, err
-		}
-		s.logger.Warnf("%s", err.Error())
+		return nil, err
 	}
 
 	return starlark.None, nil
@@ -31,15 +32,65 @@ func (s *tiltfileState) disableFeature(thread *starlark.Thread, fn *starlark.Bui
 		return nil, err
 	}
 
-	err = s.features.Set(flag, false)
+	err = s.setFeature(flag, false)
 	if err != nil {
-		if _, ok := err.(feature.ObsoleteError); !ok {
+		return nil, err
+	}
+
+	return starlark.None, nil
+}
+
+// Enables and disables several flags at once, and returns the value of
+// every flag that's still in use, so that a Tiltfile can check them.
+func (s *tiltfileState) featuresFn(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var enable, disable value.StringList
+	err := s.unpackArgs(fn.Name(), args, kwargs,
+		"enable?", &enable,
+		"disable?", &disable)
+	if err != nil {
+		return nil, err
+	}
+
+	disabled := make(map[string]bool, len(disable))
+	for _, flag := range disable {
+		disabled[flag] = true
+	}
+	for _, flag := range enable {
+		if disabled[flag] {
+			return nil, fmt.Errorf("%s: feature flag %q is in both enable and disable", fn.Name(), flag)
+		}
+	}
+
+	for _, flag := range enable {
+		err = s.setFeature(flag, true)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, flag := range disable {
+		err = s.setFeature(flag, false)
+		if err != nil {
 			return nil, err
 		}
-		s.logger.Warnf("%s", err.Error())
 	}
 
-	return starlark.None, nil
+	var names []string
+	for name, v := range s.features {
+		if v.Status == feature.Obsolete {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := starlark.NewDict(len(names))
+	for _, name := range names {
+		err = result.SetKey(starlark.String(name), starlark.Bool(s.features[name].Enabled))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
 }
 
 func (s *tiltfileState) disableSnapshots(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
@@ -48,13 +99,22 @@ func (s *tiltfileState) disableSnapshots(thread *starlark.Thread, fn *starlark.B
 		return nil, err
 	}
 
-	err = s.features.Set(feature.Snapshots, false)
+	err = s.setFeature(feature.Snapshots, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return starlark.None, nil
+}
+
+// Sets a feature flag, warning (rather than failing) if it's obsolete.
+func (s *tiltfileState) setFeature(flag string, enabled bool) error {
+	err := s.features.Set(flag, enabled)
 	if err != nil {
 		if _, ok := err.(feature.ObsoleteError); !ok {
-			return nil, err
+			return err
 		}
 		s.logger.Warnf("%s", err.Error())
 	}
-
-	return starlark.None, nil
+	return nil
 }
@@ -2,6 +2,7 @@ package tiltfile
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -37,6 +38,21 @@ type execCommandOptions struct {
 }
 
 func (s *tiltfileState) local(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	cmd, execOptions, err := s.unpackLocalArgs(thread, fn, args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := s.execLocalCmd(thread, cmd, execOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return tiltfile_io.NewBlob(out, fmt.Sprintf("local: %s", cmd)), nil
+}
+
+// Unpacks the arguments shared by local() and local_async().
+func (s *tiltfileState) unpackLocalArgs(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (model.Cmd, execCommandOptions, error) {
 	var commandValue, commandBatValue, commandDirValue starlark.Value
 	var commandEnv value.StringStringMap
 	var stdin value.Stringable
@@ -52,37 +68,38 @@ func (s *tiltfileState) local(thread *starlark.Thread, fn *starlark.Builtin, arg
 		"stdin?", &stdin,
 	)
 	if err != nil {
-		return nil, err
+		return model.Cmd{}, execCommandOptions{}, err
 	}
 
 	cmd, err := value.ValueGroupToCmdHelper(thread, commandValue, commandBatValue, commandDirValue, commandEnv)
 	if err != nil {
-		return nil, err
+		return model.Cmd{}, execCommandOptions{}, err
 	}
 
 	execOptions := execCommandOptions{
 		logOutput:        !quiet,
 		logCommand:       !echoOff,
-		logCommandPrefix: "local:",
+		logCommandPrefix: fmt.Sprintf("%s:", fn.Name()),
 	}
 	if stdin.IsSet {
 		s := stdin.Value
 		execOptions.stdin = &s
 	}
-	out, err := s.execLocalCmd(thread, cmd, execOptions)
-	if err != nil {
-		return nil, err
-	}
-
-	return tiltfile_io.NewBlob(out, fmt.Sprintf("local: %s", cmd)), nil
+	return cmd, execOptions, nil
 }
 
 func (s *tiltfileState) execLocalCmd(t *starlark.Thread, cmd model.Cmd, options execCommandOptions) (string, error) {
-	var stdoutBuf, stderrBuf bytes.Buffer
 	ctx, err := starkit.ContextFromThread(t)
 	if err != nil {
 		return "", err
 	}
+	return s.execLocalCmdWithContext(ctx, cmd, options)
+}
+
+// Like execLocalCmd, but doesn't need a starlark thread,
+// so it's safe to call from another goroutine.
+func (s *tiltfileState) execLocalCmdWithContext(ctx context.Context, cmd model.Cmd, options execCommandOptions) (string, error) {
+	var stdoutBuf, stderrBuf bytes.Buffer
 
 	if options.logCommand {
 		prefix := options.logCommandPrefix
@@ -0,0 +1,136 @@
+package groups
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.starlark.net/starlark"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/tilt-dev/tilt/internal/sliceutils"
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+	"github.com/tilt-dev/tilt/internal/tiltfile/value"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+type State struct {
+	// Maps each resource name to the path of the group it's in.
+	ByResource map[string]string
+}
+
+func (s State) ApplyTo(manifests []model.Manifest) ([]model.Manifest, error) {
+	found := make(map[string]bool, len(manifests))
+	for i, m := range manifests {
+		path, ok := s.ByResource[m.Name.String()]
+		if !ok {
+			continue
+		}
+		found[m.Name.String()] = true
+
+		labels := make(map[string]string, len(m.Labels)+1)
+		for k, v := range m.Labels {
+			labels[k] = v
+		}
+		labels[model.ManifestGroupLabel] = model.GroupPathToLabelValue(path)
+		manifests[i] = m.WithLabels(labels)
+	}
+
+	var missing []string
+	for name := range s.ByResource {
+		if !found[name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("group: no resource found named %s",
+			sliceutils.QuotedStringList(missing))
+	}
+	return manifests, nil
+}
+
+// Implements the group() builtin, for organizing resources into
+// nested groups like team/service/component.
+type Plugin struct{}
+
+func NewPlugin() Plugin {
+	return Plugin{}
+}
+
+func (e Plugin) NewState() interface{} {
+	return State{ByResource: make(map[string]string)}
+}
+
+func (e Plugin) OnStart(env *starkit.Environment) error {
+	return env.AddBuiltin("group", e.group)
+}
+
+func (e Plugin) group(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var path string
+	var resources value.StringOrStringList
+	if err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs,
+		"name", &path,
+		"resources", &resources); err != nil {
+		return nil, err
+	}
+
+	err := validatePath(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", fn.Name(), err)
+	}
+
+	err = starkit.SetState(thread, func(state State) (State, error) {
+		byResource := make(map[string]string, len(state.ByResource)+len(resources.Values))
+		for k, v := range state.ByResource {
+			byResource[k] = v
+		}
+		for _, r := range resources.Values {
+			existing, ok := byResource[r]
+			if ok && existing != path {
+				return state, fmt.Errorf("%s: resource %q is already in group %q", fn.Name(), r, existing)
+			}
+			byResource[r] = path
+		}
+		state.ByResource = byResource
+		return state, nil
+	})
+	return starlark.None, err
+}
+
+func validatePath(path string) error {
+	if path == "" {
+		return fmt.Errorf("group name must not be empty")
+	}
+
+	for _, segment := range strings.Split(path, model.GroupPathSeparator) {
+		if segment == "" {
+			return fmt.Errorf("invalid group name %q: empty path segment", path)
+		}
+		if strings.Contains(segment, ".") {
+			return fmt.Errorf("invalid group name %q: segments cannot contain '.'", path)
+		}
+	}
+
+	errs := validation.IsValidLabelValue(model.GroupPathToLabelValue(path))
+	if len(errs) != 0 {
+		return fmt.Errorf("invalid group name %q: %s", path, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+var _ starkit.StatefulPlugin = Plugin{}
+
+func MustState(model starkit.Model) State {
+	state, err := GetState(model)
+	if err != nil {
+		panic(err)
+	}
+	return state
+}
+
+func GetState(m starkit.Model) (State, error) {
+	var state State
+	err := m.Load(&state)
+	return state, err
+}
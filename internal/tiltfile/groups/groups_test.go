@@ -0,0 +1,50 @@
+package groups
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+)
+
+func TestGroup(t *testing.T) {
+	f := NewFixture(t)
+	f.File("Tiltfile", `
+group('payments/api', ['api', 'db'])
+group('payments/jobs', 'worker')
+group('payments/api', 'api')
+`)
+	result, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"api":    "payments/api",
+		"db":     "payments/api",
+		"worker": "payments/jobs",
+	}, MustState(result).ByResource)
+}
+
+func TestGroupInvalidName(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		expected string
+	}{
+		{"", "group name must not be empty"},
+		{"payments//api", "empty path segment"},
+		{"payments/api.v2", "segments cannot contain '.'"},
+		{"payments/api!", "a valid label must be"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f := NewFixture(t)
+			f.File("Tiltfile", "group('"+tc.name+"', ['api'])")
+			_, err := f.ExecFile("Tiltfile")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
+
+func NewFixture(tb testing.TB) *starkit.Fixture {
+	return starkit.NewFixture(tb, NewPlugin())
+}
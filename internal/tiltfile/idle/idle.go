@@ -0,0 +1,74 @@
+package idle
+
+import (
+	"fmt"
+
+	"go.starlark.net/starlark"
+
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+	"github.com/tilt-dev/tilt/internal/tiltfile/value"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// Implements functions for shutting down idle dev environments.
+type Plugin struct{}
+
+func NewPlugin() Plugin {
+	return Plugin{}
+}
+
+func (e Plugin) NewState() interface{} {
+	return model.IdleSettings{
+		WarnBefore: model.IdleDefaultWarnBefore,
+	}
+}
+
+func (e Plugin) OnStart(env *starkit.Environment) error {
+	return env.AddBuiltin("idle_settings", e.idleSettings)
+}
+
+func (e Plugin) idleSettings(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var timeout value.Duration
+	var warnBefore value.Duration
+	var exit bool
+	if err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs,
+		"timeout", &timeout,
+		"warn_before?", &warnBefore,
+		"exit?", &exit); err != nil {
+		return nil, err
+	}
+
+	if timeout.AsDuration() < 0 || warnBefore.AsDuration() < 0 {
+		return nil, fmt.Errorf("%s: durations must not be negative", fn.Name())
+	}
+
+	err := starkit.SetState(thread, func(settings model.IdleSettings) (model.IdleSettings, error) {
+		settings.Timeout = timeout.AsDuration()
+		if !warnBefore.IsZero() {
+			settings.WarnBefore = warnBefore.AsDuration()
+		}
+		if settings.WarnBefore > settings.Timeout {
+			settings.WarnBefore = settings.Timeout
+		}
+		settings.Exit = exit
+		return settings, nil
+	})
+
+	return starlark.None, err
+}
+
+var _ starkit.StatefulPlugin = Plugin{}
+
+func MustState(model starkit.Model) model.IdleSettings {
+	state, err := GetState(model)
+	if err != nil {
+		panic(err)
+	}
+	return state
+}
+
+func GetState(m starkit.Model) (model.IdleSettings, error) {
+	var state model.IdleSettings
+	err := m.Load(&state)
+	return state, err
+}
@@ -0,0 +1,68 @@
+package idle
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestIdleSettings(t *testing.T) {
+	f := NewFixture(t)
+	f.File("Tiltfile", `
+idle_settings(timeout='8h', warn_before='30m', exit=True)
+`)
+	result, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	assert.Equal(t, model.IdleSettings{
+		Timeout:    8 * time.Hour,
+		WarnBefore: 30 * time.Minute,
+		Exit:       true,
+	}, MustState(result))
+}
+
+func TestIdleSettingsDefault(t *testing.T) {
+	f := NewFixture(t)
+	f.File("Tiltfile", `
+idle_settings(timeout='2h')
+`)
+	result, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	assert.Equal(t, model.IdleSettings{
+		Timeout:    2 * time.Hour,
+		WarnBefore: model.IdleDefaultWarnBefore,
+	}, MustState(result))
+
+	f.File("Tiltfile.empty", ``)
+	result, err = f.ExecFile("Tiltfile.empty")
+	require.NoError(t, err)
+	assert.False(t, MustState(result).Enabled())
+}
+
+func TestIdleSettingsWarnBeforeLongerThanTimeout(t *testing.T) {
+	f := NewFixture(t)
+	f.File("Tiltfile", `
+idle_settings(timeout='5m')
+`)
+	result, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Minute, MustState(result).WarnBefore)
+}
+
+func TestIdleSettingsBadDuration(t *testing.T) {
+	f := NewFixture(t)
+	f.File("Tiltfile", `
+idle_settings(timeout='eight hours')
+`)
+	_, err := f.ExecFile("Tiltfile")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid duration")
+}
+
+func NewFixture(tb testing.TB) *starkit.Fixture {
+	return starkit.NewFixture(tb, NewPlugin())
+}
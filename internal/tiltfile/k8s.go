@@ -2,17 +2,25 @@ package tiltfile
 
 import (
 	"fmt"
+	"io/fs"
 	"net/url"
+	"os"
+	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/pkg/fileutils"
 	"github.com/pkg/errors"
 	"go.starlark.net/starlark"
 	"go.starlark.net/syntax"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	"github.com/tilt-dev/tilt/internal/tiltfile/links"
 
@@ -54,6 +62,29 @@ type k8sResource struct {
 	extraPodSelectors []labels.Set
 
 	podReadinessMode model.PodReadinessMode
+	waitForSidecars  bool
+
+	// env vars to set on every container in the resource's workloads
+	env map[string]string
+
+	// volume claims that the user can reset from the UI
+	resetVolumes []string
+
+	// delete objects from the cluster when they're removed from the YAML
+	prune bool
+
+	// take over objects that a GitOps tool manages, and hand them back on down
+	gitOpsHandoff bool
+
+	// friendly local hostnames that proxy to the port forwards
+	hostnames []model.Hostname
+
+	// the cluster declared with k8s_cluster() to deploy to, if not the default cluster
+	cluster string
+
+	// labels and annotations to stamp on every applied object
+	commonLabels      map[string]string
+	commonAnnotations map[string]string
 
 	discoveryStrategy v1alpha1.KubernetesDiscoveryStrategy
 
@@ -87,9 +118,18 @@ type k8sResourceOptions struct {
 	objects           []string
 	manuallyGrouped   bool
 	podReadinessMode  model.PodReadinessMode
+	waitForSidecars   value.Optional[starlark.Bool]
 	discoveryStrategy v1alpha1.KubernetesDiscoveryStrategy
 	links             []model.Link
 	labels            map[string]string
+	env               map[string]string
+	resetVolumes      []string
+	prune             value.Optional[starlark.Bool]
+	gitOpsHandoff     value.Optional[starlark.Bool]
+	hostnames         []model.Hostname
+	cluster           string
+	commonLabels      map[string]string
+	commonAnnotations map[string]string
 }
 
 // Count image injection for analytics.
@@ -182,6 +222,10 @@ func (s *tiltfileState) k8sYaml(thread *starlark.Thread, fn *starlark.Builtin, a
 
 func (s *tiltfileState) extractSecrets() model.SecretSet {
 	result := model.SecretSet{}
+	if s.secretSettings.ScrubSecrets {
+		result.AddAll(s.buildSecrets.contents)
+	}
+
 	for _, e := range s.k8sUnresourced {
 		secrets := s.maybeExtractSecrets(e)
 		result.AddAll(secrets)
@@ -305,6 +349,15 @@ func (s *tiltfileState) k8sResource(thread *starlark.Thread, fn *starlark.Builti
 	var autoInit = value.Optional[starlark.Bool]{Value: true}
 	var labels value.LabelSet
 	var discoveryStrategy tiltfile_k8s.DiscoveryStrategy
+	var waitForSidecars value.Optional[starlark.Bool]
+	var env value.StringStringMap
+	var resetVolumesVal value.StringOrStringList
+	var prune value.Optional[starlark.Bool]
+	var gitOpsHandoff value.Optional[starlark.Bool]
+	var hostnamesVal value.StringOrStringList
+	var commonLabels value.StringStringMap
+	var commonAnnotations value.StringStringMap
+	var cluster string
 
 	if err := s.unpackArgs(fn.Name(), args, kwargs,
 		"workload?", &workload,
@@ -319,6 +372,15 @@ func (s *tiltfileState) k8sResource(thread *starlark.Thread, fn *starlark.Builti
 		"links?", &links,
 		"labels?", &labels,
 		"discovery_strategy?", &discoveryStrategy,
+		"wait_for_sidecars?", &waitForSidecars,
+		"env?", &env,
+		"reset_volumes?", &resetVolumesVal,
+		"prune?", &prune,
+		"gitops_handoff?", &gitOpsHandoff,
+		"hostnames?", &hostnamesVal,
+		"common_labels?", &commonLabels,
+		"common_annotations?", &commonAnnotations,
+		"cluster?", &cluster,
 	); err != nil {
 		return nil, err
 	}
@@ -359,6 +421,16 @@ func (s *tiltfileState) k8sResource(thread *starlark.Thread, fn *starlark.Builti
 		return nil, fmt.Errorf("k8s_resource doesn't specify a workload or any objects. All non-workload resources must specify 1 or more objects")
 	}
 
+	hostnames, err := parseHostnames(hostnamesVal.Values)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s %q: hostnames", fn.Name(), resourceName)
+	}
+
+	err = validateCommonMetadata(commonLabels, commonAnnotations)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s %q", fn.Name(), resourceName)
+	}
+
 	labelMap := make(map[string]string)
 	for k, v := range labels.Values {
 		labelMap[k] = v
@@ -376,14 +448,47 @@ func (s *tiltfileState) k8sResource(thread *starlark.Thread, fn *starlark.Builti
 		objects:           objects,
 		manuallyGrouped:   manuallyGrouped,
 		podReadinessMode:  podReadinessMode.Value,
+		waitForSidecars:   waitForSidecars,
 		links:             links.Links,
 		labels:            labelMap,
 		discoveryStrategy: v1alpha1.KubernetesDiscoveryStrategy(discoveryStrategy),
+		env:               env,
+		resetVolumes:      resetVolumesVal.Values,
+		prune:             prune,
+		gitOpsHandoff:     gitOpsHandoff,
+		hostnames:         hostnames,
+		commonLabels:      commonLabels,
+		commonAnnotations: commonAnnotations,
+		cluster:           cluster,
 	})
 
 	return starlark.None, nil
 }
 
+// Parses hostnames of the form "myapp.localhost" or "myapp.localhost/api".
+func parseHostnames(values []string) ([]model.Hostname, error) {
+	var result []model.Hostname
+	for _, v := range values {
+		host, path := strings.ToLower(v), "/"
+		if i := strings.Index(host, "/"); i != -1 {
+			host, path = host[:i], v[i:]
+		}
+		if errs := validation.IsDNS1123Subdomain(host); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid hostname %q: %s", v, strings.Join(errs, "; "))
+		}
+		result = append(result, model.Hostname{Host: host, Path: path})
+	}
+	return result, nil
+}
+
+// Checks that common labels and annotations are valid, so that
+// mistakes show up when the Tiltfile loads rather than on apply.
+func validateCommonMetadata(commonLabels, commonAnnotations map[string]string) error {
+	errs := v1alpha1.ValidateCommonLabels(commonLabels, field.NewPath("common_labels"))
+	errs = append(errs, v1alpha1.ValidateCommonAnnotations(commonAnnotations, field.NewPath("common_annotations"))...)
+	return errs.ToAggregate()
+}
+
 func labelSetFromStarlarkDict(d *starlark.Dict) (labels.Set, error) {
 	ret := make(labels.Set)
 
@@ -494,12 +599,16 @@ func (s *tiltfileState) k8sKind(thread *starlark.Thread, fn *starlark.Builtin, a
 	var jpLocators tiltfile_k8s.JSONPathImageLocatorListSpec
 	var jpObjectLocator tiltfile_k8s.JSONPathImageObjectLocatorSpec
 	var podReadiness tiltfile_k8s.PodReadinessMode
+	var waitFor tiltfile_k8s.WaitFor
+	var waitTimeout value.Duration
 	if err := s.unpackArgs(fn.Name(), args, kwargs,
 		"kind", &kind,
 		"image_json_path?", &jpLocators,
 		"api_version?", &apiVersion,
 		"image_object?", &jpObjectLocator,
 		"pod_readiness?", &podReadiness,
+		"wait_for?", &waitFor,
+		"wait_timeout?", &waitTimeout,
 	); err != nil {
 		return nil, err
 	}
@@ -513,6 +622,10 @@ func (s *tiltfileState) k8sKind(thread *starlark.Thread, fn *starlark.Builtin, a
 		return nil, fmt.Errorf("Cannot specify both image_json_path and image_object")
 	}
 
+	if waitFor.IsEmpty() && !waitTimeout.IsZero() {
+		return nil, fmt.Errorf("Cannot specify wait_timeout without wait_for")
+	}
+
 	kindInfo, ok := s.k8sKinds[k]
 	if !ok {
 		kindInfo = &tiltfile_k8s.KindInfo{}
@@ -538,6 +651,13 @@ func (s *tiltfileState) k8sKind(thread *starlark.Thread, fn *starlark.Builtin, a
 		kindInfo.PodReadinessMode = podReadiness.Value
 	}
 
+	if !waitFor.IsEmpty() {
+		w := *waitFor.Value
+		w.ObjectSelector = k.ToSpec()
+		w.Timeout = metav1.Duration{Duration: waitTimeout.AsDuration()}
+		kindInfo.WaitFor = &w
+	}
+
 	return starlark.None, nil
 }
 
@@ -698,6 +818,29 @@ func (s *tiltfileState) yamlEntitiesFromSkylarkValue(thread *starlark.Thread, v
 		if err != nil {
 			return nil, err
 		}
+
+		// An empty path resolves to the Tiltfile directory; keep treating
+		// it as a (missing) file rather than loading the whole directory.
+		var yamlPaths []string
+		ok := false
+		if str, isStr := value.AsString(v); !isStr || str != "" {
+			yamlPaths, ok, err = s.expandYAMLPath(thread, yamlPath)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if ok {
+			var ret []k8s.K8sEntity
+			for _, p := range yamlPaths {
+				entities, err := s.yamlEntitiesFromSkylarkValue(thread, starlark.String(p))
+				if err != nil {
+					return nil, err
+				}
+				ret = append(ret, entities...)
+			}
+			return ret, nil
+		}
+
 		bs, err := io.ReadFile(thread, yamlPath)
 		if err != nil {
 			return nil, errors.Wrap(err, "error reading yaml file")
@@ -715,6 +858,89 @@ func (s *tiltfileState) yamlEntitiesFromSkylarkValue(thread *starlark.Thread, v
 	}
 }
 
+// k8s_yaml accepts a directory or a glob pattern (e.g., 'deploy/**/*.yaml')
+// in place of a single file.
+//
+// Returns the YAML files that the path expands to, sorted so that entities load
+// in a stable order, and ok=false if the path should be read as a single file.
+//
+// We watch the directory (or the part of the pattern before the first wildcard)
+// recursively, so that the Tiltfile reloads when a matching file is added.
+func (s *tiltfileState) expandYAMLPath(thread *starlark.Thread, p string) (paths []string, ok bool, err error) {
+	info, statErr := os.Stat(p)
+	isDir := statErr == nil && info.IsDir()
+	isPattern := os.IsNotExist(statErr) && strings.ContainsAny(p, "*?[")
+	if !isDir && !isPattern {
+		return nil, false, nil
+	}
+
+	base := p
+	var matcher *fileutils.PatternMatcher
+	if isPattern {
+		base = globBase(p)
+		matcher, err = fileutils.NewPatternMatcher([]string{p})
+		if err != nil {
+			return nil, false, fmt.Errorf("invalid pattern %q: %v", p, err)
+		}
+	}
+
+	err = io.RecordReadPath(thread, io.WatchRecursive, base)
+	if err != nil {
+		return nil, false, err
+	}
+
+	err = filepath.WalkDir(base, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == base {
+				return nil
+			}
+			return err
+		}
+
+		if d.IsDir() {
+			// Like `kubectl apply -R -f dir`, a directory includes the files in its
+			// subdirectories, to match the recursive watch.
+			return nil
+		}
+
+		if isDir {
+			ext := filepath.Ext(path)
+			if ext == ".yaml" || ext == ".yml" {
+				paths = append(paths, path)
+			}
+			return nil
+		}
+
+		match, err := matcher.Matches(path)
+		if err != nil {
+			return err
+		}
+		if match {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("listing YAML files in %s: %v", p, err)
+	}
+
+	if len(paths) == 0 {
+		s.logger.Warnf("k8s_yaml: no YAML files found at %s", p)
+	}
+
+	sort.Strings(paths)
+	return paths, true, nil
+}
+
+// The directory part of a glob pattern, up to the first wildcard.
+func globBase(pattern string) string {
+	dir := filepath.Dir(pattern)
+	for strings.ContainsAny(dir, "*?[") {
+		dir = filepath.Dir(dir)
+	}
+	return dir
+}
+
 func convertPortForwards(val starlark.Value) ([]model.PortForward, error) {
 	if val == nil {
 		return nil, nil
@@ -2,12 +2,16 @@ package k8s
 
 import (
 	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
 	"github.com/tilt-dev/tilt/pkg/model"
 )
 
 type KindInfo struct {
 	ImageLocators    []k8s.ImageLocator
 	PodReadinessMode model.PodReadinessMode
+
+	// A rollout check for objects of this kind, if any.
+	WaitFor *v1alpha1.KubernetesApplyWaitFor
 }
 
 func InitialKinds() map[k8s.ObjectSelector]*KindInfo {
@@ -0,0 +1,74 @@
+package k8s
+
+import (
+	"fmt"
+	"strings"
+
+	"go.starlark.net/starlark"
+
+	"github.com/tilt-dev/tilt/internal/tiltfile/value"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+// Deserializing rollout checks from starlark values.
+//
+// Uses the syntax of `kubectl wait --for`, e.g.,
+// "condition=Available" or "jsonpath={.status.phase}=Ready".
+type WaitFor struct {
+	Value *v1alpha1.KubernetesApplyWaitFor
+}
+
+func (w *WaitFor) IsEmpty() bool {
+	return w.Value == nil
+}
+
+func (w *WaitFor) Unpack(v starlark.Value) error {
+	s, ok := value.AsString(v)
+	if !ok {
+		return fmt.Errorf("Must be a string. Got: %s", v.Type())
+	}
+
+	result, err := ParseWaitFor(s)
+	if err != nil {
+		return err
+	}
+	w.Value = &result
+	return nil
+}
+
+func ParseWaitFor(s string) (v1alpha1.KubernetesApplyWaitFor, error) {
+	if cond := strings.TrimPrefix(s, "condition="); cond != s {
+		if cond == "" {
+			return v1alpha1.KubernetesApplyWaitFor{}, fmt.Errorf("Invalid value %q: missing condition", s)
+		}
+		return v1alpha1.KubernetesApplyWaitFor{Condition: cond}, nil
+	}
+
+	if expr := strings.TrimPrefix(s, "jsonpath="); expr != s {
+		// The path may contain '=' inside the braces (e.g., in a filter),
+		// so look for the value after the closing brace.
+		path, val := expr, ""
+		if strings.HasPrefix(expr, "{") {
+			end := strings.LastIndex(expr, "}")
+			if end == -1 {
+				return v1alpha1.KubernetesApplyWaitFor{}, fmt.Errorf("Invalid value %q: unterminated jsonpath", s)
+			}
+			path = expr[:end+1]
+			rest := expr[end+1:]
+			if rest != "" && !strings.HasPrefix(rest, "=") {
+				return v1alpha1.KubernetesApplyWaitFor{}, fmt.Errorf("Invalid value %q: expected jsonpath={path}=value", s)
+			}
+			val = strings.TrimPrefix(rest, "=")
+		} else if i := strings.Index(expr, "="); i != -1 {
+			path, val = expr[:i], expr[i+1:]
+		}
+
+		if path == "" || path == "{}" {
+			return v1alpha1.KubernetesApplyWaitFor{}, fmt.Errorf("Invalid value %q: missing jsonpath", s)
+		}
+		return v1alpha1.KubernetesApplyWaitFor{JSONPath: path, Value: val}, nil
+	}
+
+	return v1alpha1.KubernetesApplyWaitFor{}, fmt.Errorf(
+		"Invalid value %q. Expected condition=[type] or jsonpath={path}=[value]", s)
+}
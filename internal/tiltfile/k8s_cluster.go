@@ -0,0 +1,75 @@
+package tiltfile
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.starlark.net/starlark"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+// Declares an additional Kubernetes cluster that k8s_resource(cluster=...) can deploy to.
+func (s *tiltfileState) k8sCluster(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var name, kubeContext, namespace, registryHost string
+	if err := s.unpackArgs(fn.Name(), args, kwargs,
+		"name", &name,
+		"context", &kubeContext,
+		"namespace?", &namespace,
+		"default_registry?", &registryHost,
+	); err != nil {
+		return nil, err
+	}
+
+	if name == v1alpha1.ClusterNameDefault || name == v1alpha1.ClusterNameDocker {
+		return nil, fmt.Errorf("%s: cluster name %q is reserved", fn.Name(), name)
+	}
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return nil, fmt.Errorf("%s: invalid cluster name %q: %s", fn.Name(), name, strings.Join(errs, ", "))
+	}
+	if s.k8sClusterNamed(name) != nil {
+		return nil, fmt.Errorf("%s: cluster %q already declared", fn.Name(), name)
+	}
+	if kubeContext == "" {
+		return nil, fmt.Errorf("%s(%q): context must not be empty", fn.Name(), name)
+	}
+
+	var reg *v1alpha1.RegistryHosting
+	if registryHost != "" {
+		reg = &v1alpha1.RegistryHosting{Host: registryHost}
+		ctx, err := starkit.ContextFromThread(thread)
+		if err != nil {
+			return nil, err
+		}
+		if err := reg.Validate(ctx); err != nil {
+			return nil, errors.Wrapf(err.ToAggregate(), "%s(%q): validating default_registry", fn.Name(), name)
+		}
+	}
+
+	s.k8sClusters = append(s.k8sClusters, &v1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: v1alpha1.ClusterSpec{
+			Connection: &v1alpha1.ClusterConnection{
+				Kubernetes: &v1alpha1.KubernetesClusterConnection{
+					Context:   kubeContext,
+					Namespace: namespace,
+				},
+			},
+			DefaultRegistry: reg,
+		},
+	})
+	return starlark.None, nil
+}
+
+func (s *tiltfileState) k8sClusterNamed(name string) *v1alpha1.Cluster {
+	for _, c := range s.k8sClusters {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package tiltfile
+
+import (
+	"fmt"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/tiltfile/k8scontext"
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+	"github.com/tilt-dev/tilt/internal/tiltfile/value"
+)
+
+// Returns metadata about the cluster that Tilt deploys to,
+// so that Tiltfiles can branch on the server version, the node
+// architectures, or on which APIs (like CRDs) are installed.
+//
+// Queries the cluster at most once per Tiltfile load.
+func (s *tiltfileState) k8sClusterInfoFn(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := s.unpackArgs(fn.Name(), args, kwargs); err != nil {
+		return nil, err
+	}
+
+	if s.k8sClusterInfo != nil {
+		return s.k8sClusterInfo, nil
+	}
+
+	model, err := starkit.ModelFromThread(thread)
+	if err != nil {
+		return nil, err
+	}
+	k8sContextState, err := k8scontext.GetState(model)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.k8sClient == nil {
+		return nil, fmt.Errorf("%s: no Kubernetes cluster configured", fn.Name())
+	}
+
+	ctx, err := starkit.ContextFromThread(thread)
+	if err != nil {
+		return nil, err
+	}
+
+	serverVersion, err := s.k8sClient.CheckConnected(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: connecting to cluster %q: %v", fn.Name(), k8sContextState.KubeContext(), err)
+	}
+
+	apiVersions, err := s.k8sClient.APIVersions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: reading API versions from cluster %q: %v", fn.Name(), k8sContextState.KubeContext(), err)
+	}
+
+	// Users whose role can't list nodes still get the rest of the info.
+	architectures, err := s.k8sClient.NodeArchitectures(ctx)
+	if err != nil && !apierrors.IsForbidden(err) {
+		return nil, fmt.Errorf("%s: reading nodes from cluster %q: %v", fn.Name(), k8sContextState.KubeContext(), err)
+	}
+
+	// The registry that the cluster advertises, usually with the
+	// local-registry-hosting ConfigMap. Tilt pushes images there
+	// even if the Tiltfile doesn't set a default_registry().
+	var localRegistry starlark.Value = starlark.None
+	if reg := s.k8sClient.LocalRegistry(ctx); !container.IsEmptyRegistry(reg) {
+		localRegistry = starlarkstruct.FromStringDict(starlark.String("local_registry"), starlark.StringDict{
+			"host":                        starlark.String(reg.Host),
+			"host_from_cluster_network":   starlark.String(reg.HostFromClusterNetwork),
+			"host_from_container_runtime": starlark.String(reg.HostFromContainerRuntime),
+			"help":                        starlark.String(reg.Help),
+		})
+	}
+
+	env := k8sContextState.Env()
+	info := starlarkstruct.FromStringDict(starlark.String("k8s_cluster_info"), starlark.StringDict{
+		"context":        starlark.String(k8sContextState.KubeContext()),
+		"product":        starlark.String(env),
+		"is_local":       starlark.Bool(env.IsDevCluster()),
+		"server_version": starlark.String(serverVersion.GitVersion),
+		"api_versions":   value.StringSliceToList(apiVersions),
+		"architectures":  value.StringSliceToList(architectures),
+		"local_registry": localRegistry,
+	})
+
+	// Every call returns the same value, so don't let one caller modify it.
+	info.Freeze()
+	s.k8sClusterInfo = info
+	return info, nil
+}
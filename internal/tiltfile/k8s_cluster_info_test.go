@@ -0,0 +1,120 @@
+package tiltfile
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/tilt-dev/clusterid"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+func TestK8sClusterInfo(t *testing.T) {
+	f := newFixture(t)
+
+	f.setupFoo()
+	f.k8sClient.FakeVersion = &version.Info{GitVersion: "v1.25.3"}
+	f.k8sClient.FakeAPIVersions = []string{"apps/v1", "v1"}
+	f.k8sClient.FakeNodeArchitectures = []string{"amd64", "arm64"}
+
+	f.file("Tiltfile", `
+info = k8s_cluster_info()
+if info.server_version != 'v1.25.3':
+  fail('bad server version: %s' % info.server_version)
+if not info.is_local:
+  fail('expected local cluster')
+if info.product != 'docker-desktop' or info.context != 'fake-context':
+  fail('bad cluster: %s %s' % (info.product, info.context))
+if 'monitoring.coreos.com/v1' in info.api_versions:
+  fail('unexpected CRD')
+if 'apps/v1' not in k8s_cluster_info().api_versions:
+  fail('missing apps/v1')
+if info.architectures != ['amd64', 'arm64']:
+  fail('bad architectures: %s' % info.architectures)
+k8s_yaml('foo.yaml')
+docker_build('gcr.io/foo', 'foo')
+`)
+
+	f.load()
+	f.assertNextManifest("foo", deployment("foo"))
+}
+
+func TestK8sClusterInfoLocalRegistry(t *testing.T) {
+	f := newFixture(t)
+	f.k8sClient.Registry = &v1alpha1.RegistryHosting{
+		Host:                   "localhost:5000",
+		HostFromClusterNetwork: "registry:5000",
+	}
+
+	f.file("Tiltfile", `
+reg = k8s_cluster_info().local_registry
+if reg.host != 'localhost:5000':
+  fail('bad host: %s' % reg.host)
+if reg.host_from_cluster_network != 'registry:5000':
+  fail('bad host from cluster network: %s' % reg.host_from_cluster_network)
+if reg.host_from_container_runtime != '':
+  fail('bad host from container runtime: %s' % reg.host_from_container_runtime)
+`)
+
+	f.load()
+}
+
+func TestK8sClusterInfoNoLocalRegistry(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+if k8s_cluster_info().local_registry != None:
+  fail('expected no local registry')
+`)
+
+	f.load()
+}
+
+func TestK8sClusterInfoRemote(t *testing.T) {
+	f := newFixture(t)
+	f.k8sEnv = clusterid.ProductGKE
+
+	f.file("Tiltfile", `
+if k8s_cluster_info().is_local:
+  fail('expected remote cluster')
+`)
+
+	f.load()
+}
+
+func TestK8sClusterInfoIsImmutable(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+k8s_cluster_info().api_versions.append('foo/v1')
+`)
+
+	f.loadErrString("frozen list")
+}
+
+func TestK8sClusterInfoNodesForbidden(t *testing.T) {
+	f := newFixture(t)
+	f.k8sClient.NodeArchitecturesErr = apierrors.NewForbidden(
+		schema.GroupResource{Resource: "nodes"}, "", fmt.Errorf("cannot list nodes"))
+
+	f.file("Tiltfile", `
+if k8s_cluster_info().architectures != []:
+  fail('expected no architectures')
+`)
+
+	f.load()
+}
+
+func TestK8sClusterInfoNodesError(t *testing.T) {
+	f := newFixture(t)
+	f.k8sClient.NodeArchitecturesErr = fmt.Errorf("connection refused")
+
+	f.file("Tiltfile", `
+k8s_cluster_info()
+`)
+
+	f.loadErrString("k8s_cluster_info: reading nodes", "connection refused")
+}
@@ -2,11 +2,15 @@ package k8scontext
 
 import (
 	"fmt"
+	"os"
+	"regexp"
 
 	"go.starlark.net/starlark"
+	"sigs.k8s.io/yaml"
 
 	"github.com/tilt-dev/clusterid"
 	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/tiltfile/io"
 	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
 	"github.com/tilt-dev/tilt/internal/tiltfile/value"
 	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
@@ -41,6 +45,11 @@ func (e Plugin) OnStart(env *starkit.Environment) error {
 	if err != nil {
 		return err
 	}
+
+	err = env.AddBuiltin("allow_k8s_namespaces", e.allowK8sNamespaces)
+	if err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -50,8 +59,12 @@ func (e Plugin) k8sContext(thread *starlark.Thread, fn *starlark.Builtin, args s
 
 func (e Plugin) allowK8sContexts(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 	var contexts starlark.Value
+	var patterns value.StringOrStringList
+	allowlistFile := value.NewLocalPathUnpacker(thread)
 	if err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs,
-		"contexts", &contexts,
+		"contexts?", &contexts,
+		"patterns?", &patterns,
+		"allowlist_file?", &allowlistFile,
 	); err != nil {
 		return nil, err
 	}
@@ -67,33 +80,140 @@ func (e Plugin) allowK8sContexts(thread *starlark.Thread, fn *starlark.Builtin,
 		}
 	}
 
-	err := starkit.SetState(thread, func(existing State) State {
+	newPatterns, err := compilePatterns(patterns.Values)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", fn.Name(), err)
+	}
+
+	if allowlistFile.Value != "" {
+		fileContexts, filePatterns, err := readAllowlistFile(thread, allowlistFile.Value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", fn.Name(), err)
+		}
+		newContexts = append(newContexts, fileContexts...)
+		newPatterns = append(newPatterns, filePatterns...)
+	}
+
+	err = starkit.SetState(thread, func(existing State) State {
 		return State{
-			context: existing.context,
-			env:     existing.env,
-			allowed: append(newContexts, existing.allowed...),
+			context:           existing.context,
+			env:               existing.env,
+			allowed:           append(newContexts, existing.allowed...),
+			allowedPatterns:   append(newPatterns, existing.allowedPatterns...),
+			allowedNamespaces: existing.allowedNamespaces,
 		}
 	})
 
 	return starlark.None, err
 }
 
+func (e Plugin) allowK8sNamespaces(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var namespaces value.StringOrStringList
+	if err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs,
+		"namespaces", &namespaces,
+	); err != nil {
+		return nil, err
+	}
+
+	for _, ns := range namespaces.Values {
+		if ns == "" {
+			return nil, fmt.Errorf("%s: namespaces must not be empty", fn.Name())
+		}
+	}
+
+	err := starkit.SetState(thread, func(existing State) State {
+		existing.allowedNamespaces = append(append([]string{}, existing.allowedNamespaces...), namespaces.Values...)
+		return existing
+	})
+
+	return starlark.None, err
+}
+
+// The allowlist file lets a platform team manage allowed contexts in one place,
+// instead of editing every Tiltfile. It's YAML with the same fields as
+// the allow_k8s_contexts() arguments:
+//
+//	contexts:
+//	- prod-readonly
+//	patterns:
+//	- dev-.*
+//
+// A missing file allows nothing. The file is watched, so
+// creating or editing it reloads the Tiltfile.
+type allowlist struct {
+	Contexts []string `json:"contexts"`
+	Patterns []string `json:"patterns"`
+}
+
+func readAllowlistFile(thread *starlark.Thread, path string) ([]k8s.KubeContext, []*regexp.Regexp, error) {
+	contents, err := io.ReadFile(thread, path)
+	if os.IsNotExist(err) {
+		return nil, nil, nil
+	} else if err != nil {
+		return nil, nil, fmt.Errorf("reading allowlist file: %v", err)
+	}
+
+	var list allowlist
+	err = yaml.UnmarshalStrict(contents, &list)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parsing allowlist file %s: %v", path, err)
+	}
+
+	contexts := make([]k8s.KubeContext, 0, len(list.Contexts))
+	for _, c := range list.Contexts {
+		contexts = append(contexts, k8s.KubeContext(c))
+	}
+
+	patterns, err := compilePatterns(list.Patterns)
+	if err != nil {
+		return nil, nil, fmt.Errorf("allowlist file %s: %v", path, err)
+	}
+	return contexts, patterns, nil
+}
+
+// Patterns must match the whole context name.
+func compilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	result := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		re, err := regexp.Compile(fmt.Sprintf("^(?:%s)$", p))
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %v", p, err)
+		}
+		result = append(result, re)
+	}
+	return result, nil
+}
+
 var _ starkit.StatefulPlugin = &Plugin{}
 
 type State struct {
 	context k8s.KubeContext
 	env     clusterid.Product
 	allowed []k8s.KubeContext
+
+	allowedPatterns []*regexp.Regexp
+
+	allowedNamespaces []string
 }
 
 func (s State) KubeContext() k8s.KubeContext {
 	return s.context
 }
 
+func (s State) Env() clusterid.Product {
+	return s.env
+}
+
+// The namespaces that the Tiltfile may deploy to without asking
+// the user first, from allow_k8s_namespaces().
+func (s State) AllowedNamespaces() []string {
+	return s.allowedNamespaces
+}
+
 // Returns whether we're allowed to deploy to this kubecontext.
 //
-// Checks against a manually specified list and a baked-in list
-// with known dev cluster names.
+// Checks against a manually specified list (of names or patterns)
+// and a baked-in list with known dev cluster names.
 //
 // Currently, only the tiltfile executor knows about "allowed" kubecontexts.
 //
@@ -120,6 +240,12 @@ func (s State) IsAllowed(tf *v1alpha1.Tiltfile) bool {
 		}
 	}
 
+	for _, re := range s.allowedPatterns {
+		if re.MatchString(string(s.context)) {
+			return true
+		}
+	}
+
 	return false
 }
 
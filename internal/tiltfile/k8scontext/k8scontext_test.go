@@ -4,9 +4,11 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/tilt-dev/clusterid"
 	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/tiltfile/io"
 	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
 )
 
@@ -38,6 +40,102 @@ func TestForbidK8sContext(t *testing.T) {
 	assert.True(t, MustState(model).IsAllowed(f.Tiltfile()))
 }
 
+func TestAllowK8sContextPatterns(t *testing.T) {
+	f := NewFixture(t, "gke-dev-alice", clusterid.ProductGKE)
+	f.File("Tiltfile", `
+allow_k8s_contexts(patterns=['gke-dev-.*'])
+`)
+	model, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	assert.True(t, MustState(model).IsAllowed(f.Tiltfile()))
+
+	// Patterns must match the whole context name.
+	f.File("Tiltfile", `
+allow_k8s_contexts(patterns='dev')
+`)
+	model, err = f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	assert.False(t, MustState(model).IsAllowed(f.Tiltfile()))
+}
+
+func TestAllowK8sContextBadPattern(t *testing.T) {
+	f := NewFixture(t, "gke-blorg", clusterid.ProductGKE)
+	f.File("Tiltfile", `
+allow_k8s_contexts(patterns='gke-(')
+`)
+	_, err := f.ExecFile("Tiltfile")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid pattern "gke-("`)
+}
+
+func TestAllowK8sContextAllowlistFile(t *testing.T) {
+	f := NewFixture(t, "gke-staging", clusterid.ProductGKE)
+	f.UseRealFS()
+	f.File("allowlist.yaml", `
+contexts:
+- gke-blorg
+patterns:
+- gke-stag.*
+`)
+	f.File("Tiltfile", `
+allow_k8s_contexts(allowlist_file='allowlist.yaml')
+`)
+	model, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	assert.Equal(t, []k8s.KubeContext{"gke-blorg"}, MustState(model).allowed)
+	assert.True(t, MustState(model).IsAllowed(f.Tiltfile()))
+	assert.Contains(t, io.MustState(model).Paths, f.JoinPath("allowlist.yaml"))
+}
+
+func TestAllowK8sContextAllowlistFileMissing(t *testing.T) {
+	f := NewFixture(t, "gke-blorg", clusterid.ProductGKE)
+	f.UseRealFS()
+	f.File("Tiltfile", `
+allow_k8s_contexts(allowlist_file='allowlist.yaml')
+`)
+	model, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	assert.False(t, MustState(model).IsAllowed(f.Tiltfile()))
+
+	// Watch the missing file, so that creating it reloads the Tiltfile.
+	assert.Contains(t, io.MustState(model).Paths, f.JoinPath("allowlist.yaml"))
+}
+
+func TestAllowK8sContextAllowlistFileInvalid(t *testing.T) {
+	f := NewFixture(t, "gke-blorg", clusterid.ProductGKE)
+	f.UseRealFS()
+	f.File("allowlist.yaml", `
+context: gke-blorg
+`)
+	f.File("Tiltfile", `
+allow_k8s_contexts(allowlist_file='allowlist.yaml')
+`)
+	_, err := f.ExecFile("Tiltfile")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "parsing allowlist file")
+}
+
+func TestAllowK8sNamespaces(t *testing.T) {
+	f := NewFixture(t, "gke-blorg", clusterid.ProductGKE)
+	f.File("Tiltfile", `
+allow_k8s_namespaces('monitoring')
+allow_k8s_namespaces(['team-a', 'team-b'])
+`)
+	model, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"monitoring", "team-a", "team-b"}, MustState(model).AllowedNamespaces())
+}
+
+func TestAllowK8sNamespacesEmpty(t *testing.T) {
+	f := NewFixture(t, "gke-blorg", clusterid.ProductGKE)
+	f.File("Tiltfile", `
+allow_k8s_namespaces('')
+`)
+	_, err := f.ExecFile("Tiltfile")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "namespaces must not be empty")
+}
+
 func NewFixture(tb testing.TB, ctx k8s.KubeContext, env clusterid.Product) *starkit.Fixture {
-	return starkit.NewFixture(tb, NewPlugin(ctx, env))
+	return starkit.NewFixture(tb, NewPlugin(ctx, env), io.NewPlugin())
 }
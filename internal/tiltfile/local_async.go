@@ -0,0 +1,130 @@
+package tiltfile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.starlark.net/starlark"
+
+	tiltfile_io "github.com/tilt-dev/tilt/internal/tiltfile/io"
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// A command started by local_async(), running in the background
+// while the rest of the Tiltfile executes.
+type localProcess struct {
+	cmd    model.Cmd
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	// Only safe to read after done is closed.
+	out string
+	err error
+
+	// Whether the Tiltfile has called wait(). Only accessed from the Tiltfile's thread.
+	waited bool
+}
+
+var _ starlark.HasAttrs = &localProcess{}
+
+func (p *localProcess) String() string {
+	return fmt.Sprintf("<local_process %q>", p.cmd.String())
+}
+
+func (p *localProcess) Type() string {
+	return "local_process"
+}
+
+func (p *localProcess) Freeze() {}
+
+func (p *localProcess) Truth() starlark.Bool {
+	return true
+}
+
+func (p *localProcess) Hash() (uint32, error) {
+	return 0, errors.New("unhashable type: local_process")
+}
+
+func (p *localProcess) Attr(name string) (starlark.Value, error) {
+	switch name {
+	case "wait":
+		return starlark.NewBuiltin(name, p.wait), nil
+	default:
+		return nil, nil
+	}
+}
+
+func (p *localProcess) AttrNames() []string {
+	return []string{"wait"}
+}
+
+// Blocks until the command exits, and returns its stdout like local() does.
+func (p *localProcess) wait(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+
+	<-p.done
+	p.waited = true
+	if p.err != nil {
+		return nil, p.err
+	}
+	return tiltfile_io.NewBlob(p.out, fmt.Sprintf("local_async: %s", p.cmd)), nil
+}
+
+// Starts a command in the background, so that independent commands
+// (like codegen and vendoring) can run in parallel.
+func (s *tiltfileState) localAsync(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	cmd, execOptions, err := s.unpackLocalArgs(thread, fn, args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, err := starkit.ContextFromThread(thread)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+
+	p := &localProcess{
+		cmd:    cmd,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	go func() {
+		defer close(p.done)
+		p.out, p.err = s.execLocalCmdWithContext(ctx, cmd, execOptions)
+	}()
+
+	s.localProcesses = append(s.localProcesses, p)
+	return p, nil
+}
+
+// Waits for all the commands started by local_async() to exit.
+//
+// If the Tiltfile failed, we kill any commands that are still running.
+// Otherwise, a failed command that the Tiltfile never waited on
+// fails the Tiltfile, just like a failed local() would.
+func (s *tiltfileState) waitForLocalProcesses(tiltfileFailed bool) error {
+	var result error
+	for _, p := range s.localProcesses {
+		if tiltfileFailed {
+			p.cancel()
+		}
+		<-p.done
+		p.cancel()
+
+		if !p.waited && p.err != nil && result == nil {
+			result = p.err
+		}
+	}
+	s.localProcesses = nil
+
+	if tiltfileFailed {
+		return nil
+	}
+	return result
+}
@@ -0,0 +1,81 @@
+package tiltfile
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalAsync(t *testing.T) {
+	f := newFixture(t)
+
+	f.setupFoo()
+
+	f.file("Tiltfile", `
+p = local_async('cat foo.yaml', command_bat='type foo.yaml')
+k8s_yaml(p.wait())
+docker_build('gcr.io/foo', 'foo')
+`)
+
+	f.load()
+
+	f.assertNextManifest("foo",
+		db(image("gcr.io/foo")),
+		deployment("foo"))
+	assert.Contains(t, f.out.String(), "local_async: ")
+	assert.Contains(t, f.out.String(), " → kind: Deployment")
+}
+
+func TestLocalAsyncRunsInParallel(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a unix shell loop")
+	}
+
+	f := newFixture(t)
+
+	// The first command waits for the second command to create a file,
+	// so the load only succeeds if they run at the same time.
+	f.file("Tiltfile", `
+first = local_async('i=0; while [ ! -f ready ] && [ $i -lt 500 ]; do sleep 0.01; i=$((i+1)); done; test -f ready && echo first')
+second = local_async('touch ready && echo second')
+out = str(first.wait()) + str(second.wait())
+if out != 'first\nsecond\n':
+  fail('unexpected output: %r' % out)
+`)
+
+	f.load()
+}
+
+func TestLocalAsyncWaitFailure(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+p = local_async('exit 1')
+p.wait()
+`)
+
+	f.loadErrString(`command "exit 1" failed`)
+}
+
+func TestLocalAsyncFailureWithoutWait(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+local_async('exit 1')
+`)
+
+	f.loadErrString(`command "exit 1" failed`)
+}
+
+func TestLocalAsyncWaitTwice(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+p = local_async('echo hi')
+if str(p.wait()) != str(p.wait()):
+  fail('expected the same output')
+`)
+
+	f.load()
+}
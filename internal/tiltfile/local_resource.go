@@ -35,6 +35,9 @@ type localResource struct {
 	labels        map[string]string
 
 	readinessProbe *v1alpha1.Probe
+
+	// Set for resources declared with external_resource().
+	externalURL string
 }
 
 func (s *tiltfileState) localResource(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
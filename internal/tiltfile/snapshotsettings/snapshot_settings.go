@@ -0,0 +1,76 @@
+package snapshotsettings
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"go.starlark.net/starlark"
+
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// Implements functions for configuring where snapshots are uploaded.
+type Plugin struct {
+}
+
+func NewPlugin() Plugin {
+	return Plugin{}
+}
+
+func (e Plugin) NewState() interface{} {
+	return model.DefaultSnapshotSettings()
+}
+
+func (e Plugin) OnStart(env *starkit.Environment) error {
+	return env.AddBuiltin("snapshot_settings", e.snapshotSettings)
+}
+
+func (e Plugin) snapshotSettings(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var storageURL string
+	linkExpiryHrs := int(model.SnapshotDefaultLinkExpiry.Hours())
+	if err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs,
+		"storage_url?", &storageURL,
+		"link_expiry_hrs?", &linkExpiryHrs); err != nil {
+		return nil, err
+	}
+
+	if storageURL != "" {
+		u, err := url.Parse(storageURL)
+		if err != nil || (u.Scheme != "s3" && u.Scheme != "gs") || u.Host == "" {
+			return nil, fmt.Errorf("%s: storage_url must look like s3://bucket/prefix or gs://bucket/prefix, got %q",
+				fn.Name(), storageURL)
+		}
+	}
+
+	linkExpiry := time.Duration(linkExpiryHrs) * time.Hour
+	if linkExpiry < time.Hour || linkExpiry > model.SnapshotDefaultLinkExpiry {
+		return nil, fmt.Errorf("%s: link_expiry_hrs must be between 1 and %d, got %d",
+			fn.Name(), int(model.SnapshotDefaultLinkExpiry.Hours()), linkExpiryHrs)
+	}
+
+	err := starkit.SetState(thread, func(settings model.SnapshotSettings) (model.SnapshotSettings, error) {
+		settings.StorageURL = storageURL
+		settings.LinkExpiry = linkExpiry
+		return settings, nil
+	})
+
+	return starlark.None, err
+}
+
+var _ starkit.StatefulPlugin = Plugin{}
+
+func MustState(model starkit.Model) model.SnapshotSettings {
+	state, err := GetState(model)
+	if err != nil {
+		panic(err)
+	}
+	return state
+}
+
+func GetState(m starkit.Model) (model.SnapshotSettings, error) {
+	var state model.SnapshotSettings
+	err := m.Load(&state)
+	return state, err
+}
@@ -0,0 +1,71 @@
+package snapshotsettings
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestSnapshotSettings(t *testing.T) {
+	f := NewFixture(t)
+	f.File("Tiltfile", `
+snapshot_settings(storage_url='s3://my-bucket/snapshots', link_expiry_hrs=2)
+`)
+	result, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	assert.Equal(t, "s3://my-bucket/snapshots", MustState(result).StorageURL)
+	assert.Equal(t, 2*time.Hour, MustState(result).LinkExpiry)
+
+	f.File("Tiltfile.empty", `
+`)
+	result, err = f.ExecFile("Tiltfile.empty")
+	require.NoError(t, err)
+	assert.Equal(t, "", MustState(result).StorageURL)
+	assert.Equal(t, model.SnapshotDefaultLinkExpiry, MustState(result).LinkExpiry)
+}
+
+func TestSnapshotSettingsBadURL(t *testing.T) {
+	f := NewFixture(t)
+	f.File("Tiltfile", `
+snapshot_settings(storage_url='https://my-bucket/snapshots')
+`)
+	_, err := f.ExecFile("Tiltfile")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "storage_url must look like s3://bucket/prefix")
+}
+
+func TestSnapshotSettingsBadExpiry(t *testing.T) {
+	f := NewFixture(t)
+	f.File("Tiltfile", `
+snapshot_settings(storage_url='gs://my-bucket', link_expiry_hrs=1000)
+`)
+	_, err := f.ExecFile("Tiltfile")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "link_expiry_hrs must be between 1 and 168, got 1000")
+
+	f.File("Tiltfile.zero", `
+snapshot_settings(storage_url='gs://my-bucket', link_expiry_hrs=0)
+`)
+	_, err = f.ExecFile("Tiltfile.zero")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "link_expiry_hrs must be between 1 and 168, got 0")
+}
+
+func TestSnapshotSettingsDefaultExpiry(t *testing.T) {
+	f := NewFixture(t)
+	f.File("Tiltfile", `
+snapshot_settings(storage_url='gs://my-bucket')
+`)
+	result, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	assert.Equal(t, model.SnapshotDefaultLinkExpiry, MustState(result).LinkExpiry)
+}
+
+func NewFixture(tb testing.TB) *starkit.Fixture {
+	return starkit.NewFixture(tb, NewPlugin())
+}
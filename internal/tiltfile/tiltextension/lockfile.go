@@ -0,0 +1,99 @@
+package tiltextension
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// A lockfile records the commit that each extension repo resolved to.
+//
+// When checked into version control, everyone on a team loads the same
+// extension code, and Tilt only re-fetches a repo when its entry in the
+// lockfile changes.
+type Lockfile struct {
+	Repos []LockedRepo `json:"repos"`
+}
+
+type LockedRepo struct {
+	// The name of the ExtensionRepo.
+	Name string `json:"name"`
+
+	// The URL of the repo.
+	URL string `json:"url"`
+
+	// The ref requested in the Tiltfile, if any. If the requested
+	// ref changes, we resolve the repo again.
+	Ref string `json:"ref,omitempty"`
+
+	// The commit that the repo resolved to.
+	Commit string `json:"commit"`
+}
+
+func readLockfile(path string) (Lockfile, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Lockfile{}, nil
+		}
+		return Lockfile{}, fmt.Errorf("reading extension lockfile: %v", err)
+	}
+
+	var lock Lockfile
+	if len(contents) == 0 {
+		return lock, nil
+	}
+	err = json.Unmarshal(contents, &lock)
+	if err != nil {
+		return Lockfile{}, fmt.Errorf("parsing extension lockfile %s: %v", path, err)
+	}
+	return lock, nil
+}
+
+func writeLockfile(path string, lock Lockfile) error {
+	contents, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return fmt.Errorf("writing extension lockfile: %v", err)
+	}
+	contents = append(contents, '\n')
+	err = os.WriteFile(path, contents, 0644)
+	if err != nil {
+		return fmt.Errorf("writing extension lockfile: %v", err)
+	}
+	return nil
+}
+
+func (l Lockfile) find(name string) (LockedRepo, bool) {
+	for _, r := range l.Repos {
+		if r.Name == name {
+			return r, true
+		}
+	}
+	return LockedRepo{}, false
+}
+
+// Returns the commit that the repo should be pinned to,
+// or "" if the lockfile has no usable entry for it.
+func (l Lockfile) pinnedCommit(name, url, ref string) string {
+	locked, ok := l.find(name)
+	if !ok || locked.URL != url || locked.Ref != ref {
+		return ""
+	}
+	return locked.Commit
+}
+
+// Returns a copy of the lockfile with the given repo entry
+// added or replaced, sorted by name.
+func (l Lockfile) with(entry LockedRepo) Lockfile {
+	repos := []LockedRepo{entry}
+	for _, r := range l.Repos {
+		if r.Name != entry.Name {
+			repos = append(repos, r)
+		}
+	}
+	sort.Slice(repos, func(i, j int) bool {
+		return repos[i].Name < repos[j].Name
+	})
+	return Lockfile{Repos: repos}
+}
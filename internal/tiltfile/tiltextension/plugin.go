@@ -16,8 +16,10 @@ import (
 	"github.com/tilt-dev/tilt/internal/controllers/apiset"
 	"github.com/tilt-dev/tilt/internal/controllers/core/extension"
 	"github.com/tilt-dev/tilt/internal/controllers/core/extensionrepo"
+	"github.com/tilt-dev/tilt/internal/tiltfile/io"
 	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
 	tiltfilev1alpha1 "github.com/tilt-dev/tilt/internal/tiltfile/v1alpha1"
+	"github.com/tilt-dev/tilt/internal/tiltfile/value"
 	"github.com/tilt-dev/tilt/pkg/apis"
 	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
 	"github.com/tilt-dev/tilt/pkg/logger"
@@ -47,17 +49,56 @@ func NewFakePlugin(repoReconciler ExtRepoReconciler, extReconciler ExtReconciler
 
 type State struct {
 	ExtsLoaded map[string]bool
+
+	// The path to the lockfile that pins extension repos to commits.
+	// Empty if the Tiltfile didn't ask for a lockfile.
+	LockfilePath string
+	Lockfile     Lockfile
+
+	// The ref that the Tiltfile asked for on each repo, before we pinned it.
+	RequestedRefs map[string]string
 }
 
 func (e Plugin) NewState() interface{} {
 	return State{
-		ExtsLoaded: make(map[string]bool),
+		ExtsLoaded:    make(map[string]bool),
+		RequestedRefs: make(map[string]string),
 	}
 }
 
 func (e *Plugin) OnStart(env *starkit.Environment) error {
 	env.AddLoadInterceptor(e)
-	return nil
+	return env.AddBuiltin("extension_settings", e.extensionSettings)
+}
+
+func (e *Plugin) extensionSettings(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	lockfile := value.NewLocalPathUnpacker(thread)
+	if err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs,
+		"lockfile?", &lockfile); err != nil {
+		return nil, err
+	}
+
+	path := lockfile.Value
+	if path == "" {
+		return starlark.None, nil
+	}
+
+	err := io.RecordReadPath(thread, io.WatchFileOnly, path)
+	if err != nil {
+		return nil, err
+	}
+
+	lock, err := readLockfile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	err = starkit.SetState(thread, func(existing State) State {
+		existing.LockfilePath = path
+		existing.Lockfile = lock
+		return existing
+	})
+	return starlark.None, err
 }
 
 func (e *Plugin) recordExtensionLoaded(ctx context.Context, t *starlark.Thread, moduleName string) {
@@ -101,6 +142,11 @@ func (e *Plugin) LocalPath(t *starlark.Thread, arg string) (localPath string, er
 
 	ext := e.ensureExtension(t, objSet, moduleName)
 	repo := e.ensureRepo(t, objSet, ext.Spec.RepoName)
+	requestedRef, err := e.pinRepo(t, repo)
+	if err != nil {
+		return "", err
+	}
+
 	repoStatus := e.repoReconciler.ForceApply(ctx, repo)
 	if repoStatus.Error != "" {
 		return "", fmt.Errorf("loading extension repo %s: %s", repo.Name, repoStatus.Error)
@@ -109,6 +155,11 @@ func (e *Plugin) LocalPath(t *starlark.Thread, arg string) (localPath string, er
 		return "", fmt.Errorf("extension repo not resolved: %s", repo.Name)
 	}
 
+	err = e.lockRepo(t, repo, requestedRef, repoStatus)
+	if err != nil {
+		return "", err
+	}
+
 	repoResolved := repo.DeepCopy()
 	repoResolved.Status = repoStatus
 	extStatus := e.extReconciler.ForceApply(ext, repoResolved)
@@ -179,6 +230,68 @@ func (e *Plugin) ensureRepo(t *starlark.Thread, objSet apiset.ObjectSet, repoNam
 	return defaultRepo
 }
 
+// If the Tiltfile uses a lockfile, and the lockfile has an entry for this repo,
+// pins the repo to the locked commit.
+//
+// Returns the ref that the Tiltfile originally asked for.
+func (e *Plugin) pinRepo(t *starlark.Thread, repo *v1alpha1.ExtensionRepo) (string, error) {
+	requestedRef := repo.Spec.Ref
+	err := starkit.SetState(t, func(existing State) State {
+		if existing.LockfilePath == "" {
+			return existing
+		}
+
+		// If we've already pinned this repo during this execution,
+		// the spec ref is the locked commit, not the requested ref.
+		ref, ok := existing.RequestedRefs[repo.Name]
+		if ok {
+			requestedRef = ref
+		} else {
+			existing.RequestedRefs[repo.Name] = requestedRef
+		}
+
+		commit := existing.Lockfile.pinnedCommit(repo.Name, repo.Spec.URL, requestedRef)
+		if commit != "" {
+			repo.Spec.Ref = commit
+		}
+		return existing
+	})
+	return requestedRef, err
+}
+
+// If the Tiltfile uses a lockfile, records the commit that the repo resolved to.
+func (e *Plugin) lockRepo(t *starlark.Thread, repo *v1alpha1.ExtensionRepo, requestedRef string, status v1alpha1.ExtensionRepoStatus) error {
+	if status.CheckoutRef == "" {
+		// file:// repos aren't versioned.
+		return nil
+	}
+
+	return starkit.SetState(t, func(existing State) (State, error) {
+		if existing.LockfilePath == "" {
+			return existing, nil
+		}
+
+		entry := LockedRepo{
+			Name:   repo.Name,
+			URL:    repo.Spec.URL,
+			Ref:    requestedRef,
+			Commit: status.CheckoutRef,
+		}
+		old, ok := existing.Lockfile.find(repo.Name)
+		if ok && old == entry {
+			return existing, nil
+		}
+
+		lock := existing.Lockfile.with(entry)
+		err := writeLockfile(existing.LockfilePath, lock)
+		if err != nil {
+			return existing, err
+		}
+		existing.Lockfile = lock
+		return existing, nil
+	})
+}
+
 var _ starkit.LoadInterceptor = (*Plugin)(nil)
 var _ starkit.StatefulPlugin = (*Plugin)(nil)
 
@@ -8,9 +8,11 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
 	"github.com/tilt-dev/tilt/internal/tiltfile/include"
+	"github.com/tilt-dev/tilt/internal/tiltfile/io"
 	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
 	tiltfilev1alpha1 "github.com/tilt-dev/tilt/internal/tiltfile/v1alpha1"
 )
@@ -191,6 +193,80 @@ include('Tiltfile.b')
 	f.assertLoadRecorded(res, "my-extension")
 }
 
+func TestLockfileRecordsCommit(t *testing.T) {
+	f := newExtensionFixture(t)
+	f.extrr.CheckoutRef = "abc123"
+
+	f.tiltfile(`
+extension_settings(lockfile='tilt_extensions.lock')
+load("ext://fetchable", "printFoo")
+printFoo()
+`)
+	f.writeModuleLocally("fetchable", libText)
+
+	f.assertExecOutput("foo")
+	assert.Equal(t, "", f.extrr.AppliedRefs["default"])
+
+	lock, err := readLockfile(f.skf.JoinPath("tilt_extensions.lock"))
+	require.NoError(t, err)
+	assert.Equal(t, []LockedRepo{{
+		Name:   "default",
+		URL:    "https://github.com/tilt-dev/tilt-extensions",
+		Commit: "abc123",
+	}}, lock.Repos)
+}
+
+func TestLockfilePinsCommit(t *testing.T) {
+	f := newExtensionFixture(t)
+	f.extrr.CheckoutRef = "def456"
+
+	f.tiltfile(`
+extension_settings(lockfile='tilt_extensions.lock')
+load("ext://fetchable", "printFoo")
+printFoo()
+`)
+	f.writeModuleLocally("fetchable", libText)
+	f.skf.File("tilt_extensions.lock", `{"repos": [{
+  "name": "default",
+  "url": "https://github.com/tilt-dev/tilt-extensions",
+  "commit": "abc123"
+}]}`)
+
+	res := f.assertExecOutput("foo")
+	assert.Equal(t, "abc123", f.extrr.AppliedRefs["default"])
+	assert.Equal(t, "abc123", MustState(res).Lockfile.Repos[0].Commit)
+}
+
+func TestLockfileIgnoredWhenRefChanges(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip()
+	}
+
+	f := newExtensionFixture(t)
+
+	f.tiltfile(`
+extension_settings(lockfile='tilt_extensions.lock')
+v1alpha1.extension_repo(name='default', url='https://github.com/tilt-dev/tilt-extensions', ref='v2')
+load("ext://fetchable", "printFoo")
+printFoo()
+`)
+	f.writeModuleLocally("fetchable", libText)
+	f.skf.File("tilt_extensions.lock", `{"repos": [{
+  "name": "default",
+  "url": "https://github.com/tilt-dev/tilt-extensions",
+  "ref": "v1",
+  "commit": "abc123"
+}]}`)
+
+	f.assertExecOutput("foo")
+	assert.Equal(t, "v2", f.extrr.AppliedRefs["default"])
+
+	lock, err := readLockfile(f.skf.JoinPath("tilt_extensions.lock"))
+	require.NoError(t, err)
+	assert.Equal(t, "v2", lock.Repos[0].Ref)
+	assert.Equal(t, "v2", lock.Repos[0].Commit)
+}
+
 type extensionFixture struct {
 	t     *testing.T
 	skf   *starkit.Fixture
@@ -208,7 +284,7 @@ func newExtensionFixture(t *testing.T) *extensionFixture {
 		extrr,
 		extr,
 	)
-	skf := starkit.NewFixture(t, ext, include.IncludeFn{}, tiltfilev1alpha1.NewPlugin())
+	skf := starkit.NewFixture(t, ext, include.IncludeFn{}, tiltfilev1alpha1.NewPlugin(), io.NewPlugin())
 	skf.UseRealFS()
 
 	return &extensionFixture{
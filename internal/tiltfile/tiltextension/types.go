@@ -20,18 +20,31 @@ type ExtReconciler interface {
 type FakeExtRepoReconciler struct {
 	path  string
 	Error string
+
+	// If set, the fake resolves repos with no ref to this commit.
+	CheckoutRef string
+
+	// The refs that each repo was applied with.
+	AppliedRefs map[string]string
 }
 
 func NewFakeExtRepoReconciler(path string) *FakeExtRepoReconciler {
-	return &FakeExtRepoReconciler{path: path}
+	return &FakeExtRepoReconciler{path: path, AppliedRefs: make(map[string]string)}
 }
 
 func (r *FakeExtRepoReconciler) ForceApply(ctx context.Context, repo *v1alpha1.ExtensionRepo) v1alpha1.ExtensionRepoStatus {
 	if r.Error != "" {
 		return v1alpha1.ExtensionRepoStatus{Error: r.Error}
 	}
+	r.AppliedRefs[repo.Name] = repo.Spec.Ref
+
+	checkoutRef := repo.Spec.Ref
+	if checkoutRef == "" {
+		checkoutRef = r.CheckoutRef
+	}
 	return v1alpha1.ExtensionRepoStatus{
-		Path: filepath.Join(r.path, filepath.Base(repo.Spec.URL)),
+		Path:        filepath.Join(r.path, filepath.Base(repo.Spec.URL)),
+		CheckoutRef: checkoutRef,
 	}
 }
 
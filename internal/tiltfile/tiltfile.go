@@ -20,12 +20,15 @@ import (
 	"github.com/tilt-dev/tilt/internal/ospath"
 	"github.com/tilt-dev/tilt/internal/sliceutils"
 	tiltfileanalytics "github.com/tilt-dev/tilt/internal/tiltfile/analytics"
+	"github.com/tilt-dev/tilt/internal/tiltfile/bandwidth"
 	"github.com/tilt-dev/tilt/internal/tiltfile/config"
 	"github.com/tilt-dev/tilt/internal/tiltfile/dockerprune"
 	"github.com/tilt-dev/tilt/internal/tiltfile/hasher"
+	"github.com/tilt-dev/tilt/internal/tiltfile/idle"
 	"github.com/tilt-dev/tilt/internal/tiltfile/io"
 	"github.com/tilt-dev/tilt/internal/tiltfile/k8scontext"
 	"github.com/tilt-dev/tilt/internal/tiltfile/secretsettings"
+	"github.com/tilt-dev/tilt/internal/tiltfile/snapshotsettings"
 	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
 	"github.com/tilt-dev/tilt/internal/tiltfile/telemetry"
 	"github.com/tilt-dev/tilt/internal/tiltfile/tiltextension"
@@ -47,16 +50,23 @@ type TiltfileLoadResult struct {
 	Tiltignore          model.Dockerignore
 	ConfigFiles         []string
 	FeatureFlags        map[string]bool
+	FeatureSources      map[string]feature.Source
 	TeamID              string
 	TelemetrySettings   model.TelemetrySettings
 	Secrets             model.SecretSet
 	Error               error
 	DockerPruneSettings model.DockerPruneSettings
+	SnapshotSettings    model.SnapshotSettings
+	IdleSettings        model.IdleSettings
+	BandwidthSettings   model.BandwidthSettings
 	AnalyticsOpt        wmanalytics.Opt
 	VersionSettings     model.VersionSettings
 	UpdateSettings      model.UpdateSettings
 	WatchSettings       model.WatchSettings
 	DefaultRegistry     *corev1alpha1.RegistryHosting
+	BuildKit            *corev1alpha1.BuildKitConnection
+	K8sClusters         []*corev1alpha1.Cluster
+	ContainerEngine     model.ContainerEngineSettings
 	ObjectSet           apiset.ObjectSet
 	Hashes              hasher.Hashes
 
@@ -100,6 +110,7 @@ func ProvideTiltfileLoader(
 	configPlugin *config.Plugin,
 	extensionPlugin *tiltextension.Plugin,
 	dcCli dockercompose.DockerComposeClient,
+	k8sClient k8s.Client,
 	webHost model.WebHost,
 	execer localexec.Execer,
 	fDefaults feature.Defaults,
@@ -111,6 +122,7 @@ func ProvideTiltfileLoader(
 		configPlugin:     configPlugin,
 		extensionPlugin:  extensionPlugin,
 		dcCli:            dcCli,
+		k8sClient:        k8sClient,
 		webHost:          webHost,
 		execer:           execer,
 		fDefaults:        fDefaults,
@@ -121,6 +133,7 @@ func ProvideTiltfileLoader(
 type tiltfileLoader struct {
 	analytics *analytics.TiltAnalytics
 	dcCli     dockercompose.DockerComposeClient
+	k8sClient k8s.Client
 	webHost   model.WebHost
 	execer    localexec.Execer
 
@@ -168,13 +181,27 @@ func (tfl tiltfileLoader) Load(ctx context.Context, tf *corev1alpha1.Tiltfile, p
 
 	tlr.Tiltignore = tiltignore
 
-	s := newTiltfileState(ctx, tfl.dcCli, tfl.webHost, tfl.execer, tfl.k8sContextPlugin, tfl.versionPlugin,
-		tfl.configPlugin, tfl.extensionPlugin, feature.FromDefaults(tfl.fDefaults))
+	features := feature.FromDefaults(tfl.fDefaults)
+	features.Roll(absFilename)
+	s := newTiltfileState(ctx, tfl.dcCli, tfl.k8sClient, tfl.webHost, tfl.execer, tfl.k8sContextPlugin, tfl.versionPlugin,
+		tfl.configPlugin, tfl.extensionPlugin, features)
 
+	s.buildSecrets = newBuildSecretFiles(absFilename)
 	manifests, result, err := s.loadManifests(tf)
+	if err == nil {
+		// Only clean up after a successful load, because a failed load
+		// keeps the images from the last one.
+		pruneErr := s.buildSecrets.prune()
+		if pruneErr != nil {
+			s.logger.Warnf("Cleaning up build secrets: %v", pruneErr)
+		}
+	}
 
 	tlr.BuiltinCalls = result.BuiltinCalls
 	tlr.DefaultRegistry = s.defaultReg
+	tlr.BuildKit = s.buildKit
+	tlr.K8sClusters = s.k8sClusters
+	tlr.ContainerEngine = s.engineSettings
 
 	// All data models are loaded with GetState. We ignore the error if the state
 	// isn't properly loaded. This is necessary for handling partial Tiltfile
@@ -196,11 +223,21 @@ func (tfl tiltfileLoader) Load(ctx context.Context, tf *corev1alpha1.Tiltfile, p
 	dps, _ := dockerprune.GetState(result)
 	tlr.DockerPruneSettings = dps
 
+	snapshotSettings, _ := snapshotsettings.GetState(result)
+	tlr.SnapshotSettings = snapshotSettings
+
+	idleSettings, _ := idle.GetState(result)
+	tlr.IdleSettings = idleSettings
+
+	bandwidthSettings, _ := bandwidth.GetState(result)
+	tlr.BandwidthSettings = bandwidthSettings
+
 	aSettings, _ := tiltfileanalytics.GetState(result)
 	tlr.AnalyticsOpt = aSettings.Opt
 
 	tlr.Secrets = s.extractSecrets()
 	tlr.FeatureFlags = s.features.ToEnabled()
+	tlr.FeatureSources = s.features.ToSources()
 	tlr.Error = err
 	tlr.Manifests = manifests
 	tlr.TeamID = s.teamID
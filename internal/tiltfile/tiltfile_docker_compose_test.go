@@ -12,6 +12,7 @@ import (
 	"github.com/tilt-dev/tilt/internal/controllers/apis/liveupdate"
 	ctrltiltfile "github.com/tilt-dev/tilt/internal/controllers/apis/tiltfile"
 	"github.com/tilt-dev/tilt/internal/dockercompose"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
 	"github.com/tilt-dev/tilt/pkg/model"
 )
 
@@ -51,6 +52,15 @@ services:
       - foo
 `
 
+// Like barServiceConfig, but doesn't depend on services in other files.
+const standaloneBarServiceConfig = `version: '3'
+services:
+  bar:
+    image: bar-image
+    expose:
+      - "3000"
+`
+
 const twoServiceConfig = `version: '3'
 services:
   foo:
@@ -171,6 +181,92 @@ func TestDockerComposeServiceEnvFile(t *testing.T) {
 	f.assertConfigFiles(expectedConfFiles...)
 }
 
+func TestDockerComposeSecretsAndConfigs(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("docker-compose.yml", `services:
+  bar:
+    image: bar-image
+    secrets: [token]
+    configs: [settings]
+  baz:
+    image: baz-image
+secrets:
+  token:
+    file: ./token.txt
+configs:
+  settings:
+    file: ./settings.json
+`)
+	f.file("token.txt", "hunter2")
+	f.file("settings.json", "{}")
+	f.file("Tiltfile", "docker_compose('docker-compose.yml')")
+
+	f.load()
+	bar := f.assertDcManifest("bar").DockerComposeTarget().Spec
+	require.Len(t, bar.Secrets, 1)
+	assert.Equal(t, "token", bar.Secrets[0].Name)
+	assert.Equal(t, f.JoinPath("token.txt"), bar.Secrets[0].File)
+	assert.NotEmpty(t, bar.Secrets[0].ContentHash)
+	require.Len(t, bar.Configs, 1)
+	assert.Equal(t, f.JoinPath("settings.json"), bar.Configs[0].File)
+
+	baz := f.assertDcManifest("baz").DockerComposeTarget().Spec
+	assert.Empty(t, baz.Secrets)
+	assert.Empty(t, baz.Configs)
+
+	f.assertConfigFiles("Tiltfile", ".tiltignore", "docker-compose.yml", "token.txt", "settings.json")
+}
+
+func TestDockerComposeExtendsFile(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("docker-compose.yml", `services:
+  bar:
+    extends:
+      file: common/base.yml
+      service: base
+`)
+	f.file("common/base.yml", `services:
+  base:
+    image: bar-image
+    env_file: base.env
+`)
+	f.file("common/base.env", "BAR_PORT=4000\n")
+	f.file("Tiltfile", "docker_compose('docker-compose.yml')")
+
+	f.load()
+	f.assertDcManifest("bar")
+
+	expectedConfFiles := []string{
+		"Tiltfile",
+		".tiltignore",
+		"docker-compose.yml",
+		"common/base.yml",
+		"common/base.env",
+		// compose-go resolves the env_files of extended services
+		// relative to the project, so we watch this too.
+		"base.env",
+	}
+	f.assertConfigFiles(expectedConfFiles...)
+}
+
+func TestDockerComposeWatchesReferencedFilesOnFailure(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("docker-compose.yml", `services:
+  bar:
+    image: bar-image
+    env_file: bar.env
+`)
+	f.file("Tiltfile", "docker_compose('docker-compose.yml')")
+
+	// The env_file doesn't exist yet, so the project fails to load,
+	// but creating it should reload the Tiltfile.
+	f.loadErrString("Error in docker_compose")
+	f.assertConfigFiles("Tiltfile", ".tiltignore", "docker-compose.yml", "bar.env")
+}
+
 func TestDockerComposeProjectName(t *testing.T) {
 	f := newFixture(t)
 
@@ -407,6 +503,101 @@ docker_compose('docker-compose2.yml')`
 	assert.Equal(t, 2, len(f.loadResult.Manifests))
 }
 
+func TestMultipleDockerComposeProjects(t *testing.T) {
+	f := newFixture(t)
+
+	f.dockerfile(filepath.Join("foo", "Dockerfile"))
+	f.file("docker-compose1.yml", simpleConfig)
+	f.file("docker-compose2.yml", standaloneBarServiceConfig)
+
+	f.file("Tiltfile", `
+docker_compose('docker-compose1.yml', project_name='one')
+docker_compose('docker-compose2.yml', project_name='two')
+`)
+
+	f.load()
+
+	foo := f.assertDcManifest("foo")
+	assert.Equal(t, "one", foo.DockerComposeTarget().Spec.Project.Name)
+	assert.Equal(t, []string{f.JoinPath("docker-compose1.yml")}, foo.DockerComposeTarget().Spec.Project.ConfigPaths)
+
+	bar := f.assertDcManifest("bar")
+	assert.Equal(t, "two", bar.DockerComposeTarget().Spec.Project.Name)
+	assert.Equal(t, []string{f.JoinPath("docker-compose2.yml")}, bar.DockerComposeTarget().Spec.Project.ConfigPaths)
+}
+
+func TestMultipleDockerComposeProjectsSameServiceName(t *testing.T) {
+	f := newFixture(t)
+
+	config := `version: '3'
+services:
+  redis:
+    image: redis
+`
+	f.file("docker-compose1.yml", config)
+	f.file("docker-compose2.yml", config)
+
+	f.file("Tiltfile", `
+docker_compose('docker-compose1.yml', project_name='one')
+docker_compose('docker-compose2.yml', project_name='two')
+dc_resource('two-redis', labels=['cache'])
+`)
+
+	f.load()
+
+	one := f.assertDcManifest("redis")
+	assert.Equal(t, "one", one.DockerComposeTarget().Spec.Project.Name)
+	assert.Equal(t, "redis", one.DockerComposeTarget().Spec.Service)
+
+	two := f.assertDcManifest("two-redis")
+	assert.Equal(t, "two", two.DockerComposeTarget().Spec.Project.Name)
+	assert.Equal(t, "redis", two.DockerComposeTarget().Spec.Service)
+	assert.Equal(t, map[string]string{"cache": "cache"}, two.Labels)
+}
+
+func TestMultipleDockerComposeProjectsDifferentDirs(t *testing.T) {
+	f := newFixture(t)
+
+	f.dockerfile(filepath.Join("foo", "Dockerfile"))
+	f.file("docker-compose1.yml", simpleConfig)
+
+	f.file(filepath.Join("subdir", "Tiltfile"), `docker_compose('docker-compose2.yml', project_name='sub')`)
+	f.file(filepath.Join("subdir", "docker-compose2.yml"), standaloneBarServiceConfig)
+
+	f.file("Tiltfile", `
+include('./subdir/Tiltfile')
+docker_compose('docker-compose1.yml')`)
+
+	f.load()
+
+	bar := f.assertDcManifest("bar")
+	assert.Equal(t, "sub", bar.DockerComposeTarget().Spec.Project.Name)
+	assert.Equal(t, f.JoinPath("subdir"), bar.DockerComposeTarget().Spec.Project.ProjectPath)
+
+	foo := f.assertDcManifest("foo")
+	assert.Equal(t, f.Path(), foo.DockerComposeTarget().Spec.Project.ProjectPath)
+}
+
+func TestMultipleDockerComposeSameProjectName(t *testing.T) {
+	f := newFixture(t)
+
+	f.dockerfile(filepath.Join("foo", "Dockerfile"))
+	f.file("docker-compose1.yml", simpleConfig)
+	f.file("docker-compose2.yml", barServiceConfig)
+
+	f.file("Tiltfile", `
+docker_compose('docker-compose1.yml', project_name='one')
+docker_compose('docker-compose2.yml', project_name='one')
+`)
+
+	f.load()
+
+	foo := f.assertDcManifest("foo")
+	bar := f.assertDcManifest("bar")
+	assert.Equal(t, foo.DockerComposeTarget().Spec.Project, bar.DockerComposeTarget().Spec.Project)
+	assert.Len(t, foo.DockerComposeTarget().Spec.Project.ConfigPaths, 2)
+}
+
 func TestDockerComposeAndK8sSupported(t *testing.T) {
 	f := newFixture(t)
 
@@ -657,6 +848,75 @@ docker_compose('docker-compose.yml')
 	assert.Equal(t, bar.DockerComposeTarget().Spec.Project.ConfigPaths, []string{configPath})
 }
 
+func TestDockerComposeDependsOnConditions(t *testing.T) {
+	f := newFixture(t)
+
+	config := `version: '3'
+services:
+  db:
+    image: postgres
+    healthcheck:
+      test: ["CMD", "pg_isready"]
+  migrate:
+    image: migrate
+    depends_on:
+      db:
+        condition: service_healthy
+  app:
+    image: app
+    depends_on:
+      migrate:
+        condition: service_completed_successfully
+      db:
+        condition: service_started
+`
+	f.file("docker-compose.yml", config)
+	f.file("Tiltfile", `
+docker_compose('docker-compose.yml')
+dc_resource('app', resource_deps=['migrate'])
+`)
+
+	f.load()
+
+	f.assertNextManifest("db")
+	migrate := f.assertNextManifest("migrate")
+	assert.Equal(t, []model.ManifestName{"db"}, migrate.ResourceDependencies)
+	assert.Equal(t, []v1alpha1.DockerComposeServiceDependency{
+		{Service: "db", Condition: v1alpha1.DockerComposeConditionServiceHealthy},
+	}, migrate.DockerComposeTarget().Spec.DependsOn)
+
+	app := f.assertNextManifest("app")
+	assert.Equal(t, []model.ManifestName{"db", "migrate"}, app.ResourceDependencies)
+	assert.Equal(t, []v1alpha1.DockerComposeServiceDependency{
+		{Service: "db", Condition: v1alpha1.DockerComposeConditionServiceStarted},
+		{Service: "migrate", Condition: v1alpha1.DockerComposeConditionServiceCompletedSuccessfully},
+	}, app.DockerComposeTarget().Spec.DependsOn)
+}
+
+func TestDockerComposeServiceLabels(t *testing.T) {
+	f := newFixture(t)
+
+	config := `services:
+  app:
+    image: app
+    labels:
+      tilt.restart-cmd: kill -HUP 1
+  db:
+    image: db
+`
+	f.file("docker-compose.yml", config)
+	f.file("Tiltfile", `docker_compose('docker-compose.yml')`)
+
+	f.load()
+
+	app := f.assertNextManifest("app")
+	assert.Equal(t, map[string]string{dockercompose.LabelRestartCmd: "kill -HUP 1"},
+		app.DockerComposeTarget().Spec.Labels)
+
+	db := f.assertNextManifest("db")
+	assert.Empty(t, db.DockerComposeTarget().Spec.Labels)
+}
+
 func TestDCImageRefSuggestion(t *testing.T) {
 	f := newFixture(t)
 
@@ -675,6 +935,7 @@ docker_compose('docker-compose.yml')
 Did you mean…
     - gcr.io/foo
 Skipping this image build
+If it's left over from a refactor, remove its docker_build(). Otherwise, use the image name in a Docker Compose config to deploy it.
 If this is deliberate, suppress this warning with: update_settings(suppress_unused_image_warnings=["gcr.typo.io/foo"])`)
 }
 
@@ -756,6 +1017,19 @@ default_registry('bar.com')
 	f.loadErrString("default_registry is not supported with docker compose")
 }
 
+func TestBuildKitBuilderWithDockerCompose(t *testing.T) {
+	f := newFixture(t)
+
+	f.dockerfile(filepath.Join("foo", "Dockerfile"))
+	f.file("docker-compose.yml", simpleConfig)
+	f.file("Tiltfile", `
+docker_compose('docker-compose.yml')
+buildkit_builder('tcp://buildkitd:1234')
+`)
+
+	f.loadErrString("buildkit_builder is not supported with docker compose")
+}
+
 func TestDockerComposeLabels(t *testing.T) {
 	f := newFixture(t)
 
@@ -1012,3 +1286,134 @@ type dcPublishedPortsHelper struct {
 func dcPublishedPorts(ports ...int) dcPublishedPortsHelper {
 	return dcPublishedPortsHelper{ports: ports}
 }
+
+const dcProfilesConfig = `services:
+  app:
+    image: app
+  debugger:
+    image: debugger
+    profiles: ["debug"]
+  grafana:
+    image: grafana
+    profiles: ["metrics"]
+`
+
+func TestDockerComposeProfilesDefault(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("docker-compose.yml", dcProfilesConfig)
+	f.file("Tiltfile", "docker_compose('docker-compose.yml')")
+
+	f.load()
+
+	// Without profiles=, every service is a resource.
+	f.assertNextManifest("app")
+	f.assertNextManifest("debugger")
+	f.assertNextManifest("grafana")
+	f.assertNoMoreManifests()
+}
+
+func TestDockerComposeProfiles(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("docker-compose.yml", dcProfilesConfig)
+	f.file("Tiltfile", "docker_compose('docker-compose.yml', profiles='debug')")
+
+	f.load()
+
+	app := f.assertNextManifest("app")
+	assert.Equal(t, []string{"debug"}, app.DockerComposeTarget().Spec.Project.Profiles)
+	f.assertNextManifest("debugger")
+	f.assertNoMoreManifests()
+}
+
+func TestDockerComposeNoProfiles(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("docker-compose.yml", dcProfilesConfig)
+	f.file("Tiltfile", "docker_compose('docker-compose.yml', profiles=[])")
+
+	f.load()
+
+	f.assertNextManifest("app")
+	f.assertNoMoreManifests()
+}
+
+func TestDockerComposeProfilesFromArgs(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("docker-compose.yml", dcProfilesConfig)
+	f.file("Tiltfile", `
+config.define_string_list('profiles')
+cfg = config.parse()
+docker_compose('docker-compose.yml', profiles=cfg.get('profiles', []))
+`)
+
+	f.load()
+	f.assertNextManifest("app")
+	f.assertNoMoreManifests()
+
+	f.load("--profiles", "debug", "--profiles", "metrics")
+	f.assertNextManifest("app")
+	f.assertNextManifest("debugger")
+	f.assertNextManifest("grafana")
+	f.assertNoMoreManifests()
+}
+
+func TestDockerComposeProfilesDisabledDependency(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("docker-compose.yml", `services:
+  app:
+    image: app
+    depends_on: [grafana]
+  grafana:
+    image: grafana
+    profiles: ["metrics"]
+`)
+	f.file("Tiltfile", "docker_compose('docker-compose.yml', profiles='debug')")
+
+	f.loadErrString(`service "app" depends on "grafana", which is not in an active profile`)
+}
+
+func TestDockerComposeProvider(t *testing.T) {
+	f := newFixture(t)
+
+	f.dockerfile(filepath.Join("foo", "Dockerfile"))
+	f.file("docker-compose.yml", simpleConfig)
+	f.file("Tiltfile", "docker_compose('docker-compose.yml', provider='podman')")
+
+	f.load()
+
+	m := f.assertNextManifest("foo")
+	assert.Equal(t, "podman", m.DockerComposeTarget().Spec.Project.Provider)
+}
+
+func TestDockerComposeProviderFromContainerEngine(t *testing.T) {
+	f := newFixture(t)
+
+	f.dockerfile(filepath.Join("foo", "Dockerfile"))
+	f.file("docker-compose.yml", simpleConfig)
+	f.file("Tiltfile", `
+docker_compose('docker-compose.yml')
+container_engine('podman', host='unix:///run/podman/podman.sock')
+`)
+
+	f.load()
+
+	m := f.assertNextManifest("foo")
+	assert.Equal(t, "podman", m.DockerComposeTarget().Spec.Project.Provider)
+}
+
+func TestDockerComposeProviderPath(t *testing.T) {
+	f := newFixture(t)
+
+	f.dockerfile(filepath.Join("foo", "Dockerfile"))
+	f.file("docker-compose.yml", simpleConfig)
+	f.file("Tiltfile", "docker_compose('docker-compose.yml', provider='./bin/compose')")
+
+	f.load()
+
+	m := f.assertNextManifest("foo")
+	assert.Equal(t, f.JoinPath("bin", "compose"), m.DockerComposeTarget().Spec.Project.Provider)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -12,7 +13,9 @@ import (
 	"go.starlark.net/starlark"
 	"go.starlark.net/syntax"
 	"golang.org/x/mod/semver"
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 
 	"github.com/tilt-dev/tilt/internal/controllers/apis/cmdimage"
 	"github.com/tilt-dev/tilt/internal/controllers/apis/dockerimage"
@@ -34,10 +37,13 @@ import (
 	"github.com/tilt-dev/tilt/internal/ospath"
 	"github.com/tilt-dev/tilt/internal/sliceutils"
 	"github.com/tilt-dev/tilt/internal/tiltfile/analytics"
+	"github.com/tilt-dev/tilt/internal/tiltfile/bandwidth"
 	"github.com/tilt-dev/tilt/internal/tiltfile/config"
 	"github.com/tilt-dev/tilt/internal/tiltfile/dockerprune"
 	"github.com/tilt-dev/tilt/internal/tiltfile/encoding"
 	"github.com/tilt-dev/tilt/internal/tiltfile/git"
+	"github.com/tilt-dev/tilt/internal/tiltfile/groups"
+	"github.com/tilt-dev/tilt/internal/tiltfile/idle"
 	"github.com/tilt-dev/tilt/internal/tiltfile/include"
 	"github.com/tilt-dev/tilt/internal/tiltfile/io"
 	tiltfile_k8s "github.com/tilt-dev/tilt/internal/tiltfile/k8s"
@@ -47,9 +53,11 @@ import (
 	"github.com/tilt-dev/tilt/internal/tiltfile/os"
 	"github.com/tilt-dev/tilt/internal/tiltfile/secretsettings"
 	"github.com/tilt-dev/tilt/internal/tiltfile/shlex"
+	"github.com/tilt-dev/tilt/internal/tiltfile/snapshotsettings"
 	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
 	"github.com/tilt-dev/tilt/internal/tiltfile/starlarkstruct"
 	"github.com/tilt-dev/tilt/internal/tiltfile/telemetry"
+	"github.com/tilt-dev/tilt/internal/tiltfile/triggers"
 	"github.com/tilt-dev/tilt/internal/tiltfile/updatesettings"
 	tfv1alpha1 "github.com/tilt-dev/tilt/internal/tiltfile/v1alpha1"
 	"github.com/tilt-dev/tilt/internal/tiltfile/version"
@@ -70,7 +78,7 @@ var unmatchedImageAllUnresourcedWarning = "No Kubernetes configs with images fou
 var pkgInitTime = time.Now()
 
 type resourceSet struct {
-	dc  dcResourceSet // currently only support one d-c.yml
+	dc  []*dcResourceSet
 	k8s []*k8sResource
 }
 
@@ -78,6 +86,7 @@ type tiltfileState struct {
 	// set at creation
 	ctx              context.Context
 	dcCli            dockercompose.DockerComposeClient
+	k8sClient        k8s.Client
 	webHost          model.WebHost
 	execer           localexec.Execer
 	k8sContextPlugin k8scontext.Plugin
@@ -101,7 +110,7 @@ type tiltfileState struct {
 	k8sByName      map[string]*k8sResource
 	k8sUnresourced []k8s.K8sEntity
 
-	dc           dcResourceSet // currently only support one d-c.yml
+	dc           []*dcResourceSet
 	dcByName     map[string]*dcService
 	dcResOptions map[string]*dcResourceOptions
 
@@ -111,9 +120,25 @@ type tiltfileState struct {
 
 	// ensure that any images are pushed to/pulled from this registry, rewriting names if needed
 	defaultReg *v1alpha1.RegistryHosting
+	buildKit   *v1alpha1.BuildKitConnection
+
+	// set by container_engine()
+	engineSettings model.ContainerEngineSettings
+
+	// files holding docker_build() secrets from the Tiltfile
+	buildSecrets *buildSecretFiles
 
 	k8sKinds map[k8s.ObjectSelector]*tiltfile_k8s.KindInfo
 
+	// memoized result of k8s_cluster_info(), so that we only query the cluster once per load
+	k8sClusterInfo starlark.Value
+
+	// additional clusters declared with k8s_cluster(), in order
+	k8sClusters []*v1alpha1.Cluster
+
+	// commands started by local_async(), which we wait on at the end of the load
+	localProcesses []*localProcess
+
 	workloadToResourceFunction workloadToResourceFunction
 
 	// for assembly
@@ -158,6 +183,7 @@ type tiltfileState struct {
 func newTiltfileState(
 	ctx context.Context,
 	dcCli dockercompose.DockerComposeClient,
+	k8sClient k8s.Client,
 	webHost model.WebHost,
 	execer localexec.Execer,
 	k8sContextPlugin k8scontext.Plugin,
@@ -168,6 +194,7 @@ func newTiltfileState(
 	return &tiltfileState{
 		ctx:                       ctx,
 		dcCli:                     dcCli,
+		k8sClient:                 k8sClient,
 		webHost:                   webHost,
 		execer:                    execer,
 		k8sContextPlugin:          k8sContextPlugin,
@@ -218,6 +245,8 @@ func (s *tiltfileState) loadManifests(tf *v1alpha1.Tiltfile) ([]model.Manifest,
 		io.NewPlugin(),
 		s.k8sContextPlugin,
 		dockerprune.NewPlugin(),
+		idle.NewPlugin(),
+		bandwidth.NewPlugin(),
 		analytics.NewPlugin(),
 		s.versionPlugin,
 		s.configPlugin,
@@ -226,6 +255,9 @@ func (s *tiltfileState) loadManifests(tf *v1alpha1.Tiltfile) ([]model.Manifest,
 		metrics.NewPlugin(),
 		updatesettings.NewPlugin(),
 		secretsettings.NewPlugin(),
+		snapshotsettings.NewPlugin(),
+		groups.NewPlugin(),
+		triggers.NewPlugin(),
 		encoding.NewPlugin(),
 		shlex.NewPlugin(),
 		watch.NewPlugin(),
@@ -237,9 +269,13 @@ func (s *tiltfileState) loadManifests(tf *v1alpha1.Tiltfile) ([]model.Manifest,
 		tfv1alpha1.NewPlugin(),
 		hasher.NewPlugin(),
 	)
+	asyncErr := s.waitForLocalProcesses(err != nil)
 	if err != nil {
 		return nil, result, starkit.UnpackBacktrace(err)
 	}
+	if asyncErr != nil {
+		return nil, result, asyncErr
+	}
 
 	resources, unresourced, err := s.assemble()
 	if err != nil {
@@ -251,8 +287,7 @@ func (s *tiltfileState) loadManifests(tf *v1alpha1.Tiltfile) ([]model.Manifest,
 		return nil, result, err
 	}
 
-	err = s.assertAllImagesMatched(us)
-	if err != nil {
+	for _, err := range s.unmatchedImageWarnings(us) {
 		s.logger.Warnf("%s", err.Error())
 	}
 
@@ -262,8 +297,9 @@ func (s *tiltfileState) loadManifests(tf *v1alpha1.Tiltfile) ([]model.Manifest,
 		return nil, result, err
 	}
 
+	allowedNamespaces := allowedK8sNamespaces(resources.k8s, unresourced, k8sContextState)
 	if len(resources.k8s) > 0 || len(unresourced) > 0 {
-		ms, err := s.translateK8s(resources.k8s, us)
+		ms, err := s.translateK8s(resources.k8s, us, allowedNamespaces)
 		if err != nil {
 			return nil, result, err
 		}
@@ -279,16 +315,29 @@ to your Tiltfile. Otherwise, switch k8s contexts and restart Tilt.`, kubeContext
 		}
 	}
 
-	if !resources.dc.Empty() {
-		if err := s.validateDockerComposeVersion(); err != nil {
-			return nil, result, err
+	if len(resources.dc) > 0 {
+		providers := make(map[string]bool)
+		for _, dc := range resources.dc {
+			// Projects that don't pick a compose provider run on the project's engine.
+			if dc.Project.Provider == "" && s.engineSettings.IsPodman() {
+				dc.Project.Provider = dockercompose.ProviderPodman
+			}
+			if providers[dc.Project.Provider] {
+				continue
+			}
+			providers[dc.Project.Provider] = true
+			if err := s.validateDockerComposeVersion(dc.Project.Provider); err != nil {
+				return nil, result, err
+			}
 		}
 
-		ms, err := s.translateDC(resources.dc)
-		if err != nil {
-			return nil, result, err
+		for _, dc := range resources.dc {
+			ms, err := s.translateDC(dc)
+			if err != nil {
+				return nil, result, err
+			}
+			manifests = append(manifests, ms...)
 		}
-		manifests = append(manifests, ms...)
 	}
 
 	err = s.validateLiveUpdatesForManifests(manifests)
@@ -318,6 +367,7 @@ to your Tiltfile. Otherwise, switch k8s contexts and restart Tilt.`, kubeContext
 		if err != nil {
 			return nil, starkit.Model{}, err
 		}
+		kt.AllowedNamespaces = allowedNamespaces
 
 		yamlManifest := model.Manifest{Name: mn}.WithDeployTarget(kt)
 		manifests = append(manifests, yamlManifest)
@@ -328,6 +378,16 @@ to your Tiltfile. Otherwise, switch k8s contexts and restart Tilt.`, kubeContext
 		return nil, starkit.Model{}, err
 	}
 
+	manifests, err = groups.MustState(result).ApplyTo(manifests)
+	if err != nil {
+		return nil, result, err
+	}
+
+	manifests, err = triggers.MustState(result).ApplyTo(manifests)
+	if err != nil {
+		return nil, result, err
+	}
+
 	for i := range manifests {
 		// ensure all manifests have a label indicating they're owned
 		// by the Tiltfile - some reconcilers have special handling
@@ -354,11 +414,16 @@ const (
 	// build functions
 	dockerBuildN     = "docker_build"
 	customBuildN     = "custom_build"
+	ociArtifactN     = "oci_artifact"
+	koBuildN         = "ko_build"
 	defaultRegistryN = "default_registry"
+	buildkitBuilderN = "buildkit_builder"
+	containerEngineN = "container_engine"
 
 	// docker compose functions
 	dockerComposeN = "docker_compose"
 	dcResourceN    = "dc_resource"
+	dcProjectN     = "dc_project"
 
 	// k8s functions
 	k8sYamlN                    = "k8s_yaml"
@@ -369,15 +434,19 @@ const (
 	k8sImageJSONPathN           = "k8s_image_json_path"
 	workloadToResourceFunctionN = "workload_to_resource_function"
 	k8sCustomDeployN            = "k8s_custom_deploy"
+	k8sClusterInfoN             = "k8s_cluster_info"
+	k8sClusterN                 = "k8s_cluster"
 
 	// local resource functions
-	localResourceN = "local_resource"
-	testN          = "test" // a deprecated fork of local resource
+	localResourceN    = "local_resource"
+	testN             = "test" // a deprecated fork of local resource
+	externalResourceN = "external_resource"
 
 	// file functions
-	localN     = "local"
-	kustomizeN = "kustomize"
-	helmN      = "helm"
+	localN      = "local"
+	localAsyncN = "local_async"
+	kustomizeN  = "kustomize"
+	helmN       = "helm"
 
 	// live update functions
 	fallBackOnN       = "fall_back_on"
@@ -393,6 +462,7 @@ const (
 	// feature flags
 	enableFeatureN  = "enable_feature"
 	disableFeatureN = "disable_feature"
+	featuresN       = "features"
 
 	disableSnapshotsN = "disable_snapshots"
 
@@ -542,17 +612,26 @@ func (s *tiltfileState) OnStart(e *starkit.Environment) error {
 		builtin starkit.Function
 	}{
 		{localN, s.potentiallyK8sUnsafeBuiltin(s.local)},
+		{localAsyncN, s.potentiallyK8sUnsafeBuiltin(s.localAsync)},
 		{dockerBuildN, s.dockerBuild},
 		{customBuildN, s.customBuild},
+		{ociArtifactN, s.ociArtifact},
+		{koBuildN, s.koBuild},
 		{defaultRegistryN, s.defaultRegistry},
+		{buildkitBuilderN, s.buildkitBuilder},
+		{containerEngineN, s.containerEngine},
 		{dockerComposeN, s.dockerCompose},
 		{dcResourceN, s.dcResource},
+		{dcProjectN, s.dcProject},
 		{k8sYamlN, s.k8sYaml},
 		{filterYamlN, s.filterYaml},
 		{k8sResourceN, s.k8sResource},
 		{k8sCustomDeployN, s.k8sCustomDeploy},
+		{k8sClusterInfoN, s.k8sClusterInfoFn},
+		{k8sClusterN, s.k8sCluster},
 		{localResourceN, s.localResource},
 		{testN, s.localResource},
+		{externalResourceN, s.externalResource},
 		{portForwardN, s.portForward},
 		{k8sKindN, s.k8sKind},
 		{k8sImageJSONPathN, s.k8sImageJsonPath},
@@ -566,6 +645,7 @@ func (s *tiltfileState) OnStart(e *starkit.Environment) error {
 		{restartContainerN, s.liveUpdateRestartContainer},
 		{enableFeatureN, s.enableFeature},
 		{disableFeatureN, s.disableFeature},
+		{featuresN, s.featuresFn},
 		{disableSnapshotsN, s.disableSnapshots},
 		{setTeamN, s.setTeam},
 	} {
@@ -613,9 +693,9 @@ func (s *tiltfileState) assemble() (resourceSet, []k8s.K8sEntity, error) {
 	}, s.k8sUnresourced, nil
 }
 
-// Emit an error if there are unmatches images.
+// Returns a warning for each unmatched image.
 //
-// There are 4 mistakes people commonly make if they
+// There are 5 mistakes people commonly make if they
 // have unmatched images:
 //  1. They didn't include any Kubernetes or Docker Compose configs at all.
 //  2. They included Kubernetes configs, but they're custom resources
@@ -623,31 +703,38 @@ func (s *tiltfileState) assemble() (resourceSet, []k8s.K8sEntity, error) {
 //  3. They typo'd the image name, and need help finding the right name.
 //  4. The tooling they're using to generating the k8s resources
 //     isn't generating what they expect.
+//  5. They refactored their deploy configs, and the image (or the
+//     image that builds on it) isn't deployed anymore.
 //
-// This function intends to help with cases (1)-(3).
+// This function intends to help with cases (1)-(3) and (5).
 // Long-term, we want to have better tooling to help with (4),
 // like being able to see k8s resources as they move thru
 // the build system.
-func (s *tiltfileState) assertAllImagesMatched(us model.UpdateSettings) error {
+func (s *tiltfileState) unmatchedImageWarnings(us model.UpdateSettings) []error {
 	unmatchedImages := s.buildIndex.unmatchedImages()
 	unmatchedImages = filterUnmatchedImages(us, unmatchedImages)
 	if len(unmatchedImages) == 0 {
 		return nil
 	}
 
-	if len(s.dc.services) == 0 && len(s.k8s) == 0 && len(s.k8sUnresourced) == 0 {
-		return fmt.Errorf(unmatchedImageNoConfigsWarning)
+	if len(s.dcServices()) == 0 && len(s.k8s) == 0 && len(s.k8sUnresourced) == 0 {
+		return []error{fmt.Errorf(unmatchedImageNoConfigsWarning)}
 	}
 
 	if len(s.k8s) == 0 && len(s.k8sUnresourced) != 0 {
-		return fmt.Errorf(unmatchedImageAllUnresourcedWarning)
+		return []error{fmt.Errorf(unmatchedImageAllUnresourcedWarning)}
 	}
 
 	configType := "Kubernetes"
-	if len(s.dc.services) > 0 {
+	if len(s.dcServices()) > 0 {
 		configType = "Docker Compose"
 	}
-	return s.buildIndex.unmatchedImageWarning(unmatchedImages[0], configType)
+
+	var result []error
+	for _, image := range unmatchedImages {
+		result = append(result, s.buildIndex.unmatchedImageWarning(image, configType))
+	}
+	return result
 }
 
 func (s *tiltfileState) assembleImages() error {
@@ -658,7 +745,7 @@ func (s *tiltfileState) assembleImages() error {
 				return err
 			}
 			for _, depImage := range depImages {
-				depBuilder := s.buildIndex.findBuilderForConsumedImage(depImage)
+				depBuilder := s.buildIndex.findBuilderForBaseImage(depImage, imageBuilder)
 				if depBuilder == nil {
 					// Images in the Dockerfile that don't have docker_build
 					// instructions are OK. We'll pull them as prebuilt images.
@@ -670,7 +757,7 @@ func (s *tiltfileState) assembleImages() error {
 		}
 
 		for _, depImage := range imageBuilder.customImgDeps {
-			depBuilder := s.buildIndex.findBuilderForConsumedImage(depImage)
+			depBuilder := s.buildIndex.findBuilderForBaseImage(depImage, imageBuilder)
 			if depBuilder == nil {
 				// If the user specifically said to depend on this image, there
 				// must be a build instruction for it.
@@ -685,11 +772,15 @@ func (s *tiltfileState) assembleImages() error {
 }
 
 func (s *tiltfileState) assembleDC() error {
-	if len(s.dc.services) > 0 && !container.IsEmptyRegistry(s.defaultReg) {
+	services := s.dcServices()
+	if len(services) > 0 && !container.IsEmptyRegistry(s.defaultReg) {
 		return errors.New("default_registry is not supported with docker compose")
 	}
+	if len(services) > 0 && s.buildKit != nil {
+		return errors.New("buildkit_builder is not supported with docker compose")
+	}
 
-	for _, svc := range s.dc.services {
+	for _, svc := range services {
 		builder := s.buildIndex.findBuilderForConsumedImage(svc.ImageRef())
 		if builder != nil {
 			// there's a Tilt-managed builder (e.g. docker_build or custom_build) for this image reference, so use that
@@ -737,7 +828,7 @@ func (s *tiltfileState) maybeAddDockerComposeImageBuilder(svc *dcService) error
 		&dockerImage{
 			buildType:                     DockerComposeBuild,
 			configurationRef:              container.NewRefSelector(imageRef),
-			dockerComposeService:          svc.Name,
+			dockerComposeService:          svc.ServiceConfig.Name,
 			dockerComposeLocalVolumePaths: svc.MountedLocalDirs,
 			dbBuildPath:                   buildContext,
 			dbDockerfilePath:              dfPath,
@@ -790,6 +881,9 @@ func (s *tiltfileState) assembleK8s() error {
 			if opts.podReadinessMode != model.PodReadinessNone {
 				r.podReadinessMode = opts.podReadinessMode
 			}
+			if opts.waitForSidecars.IsSet {
+				r.waitForSidecars = bool(opts.waitForSidecars.Value)
+			}
 			if opts.discoveryStrategy != "" {
 				r.discoveryStrategy = opts.discoveryStrategy
 			}
@@ -805,6 +899,35 @@ func (s *tiltfileState) assembleK8s() error {
 			for k, v := range opts.labels {
 				r.labels[k] = v
 			}
+			if len(opts.env) > 0 && r.env == nil {
+				r.env = make(map[string]string, len(opts.env))
+			}
+			for k, v := range opts.env {
+				r.env[k] = v
+			}
+			if len(opts.commonLabels) > 0 && r.commonLabels == nil {
+				r.commonLabels = make(map[string]string, len(opts.commonLabels))
+			}
+			for k, v := range opts.commonLabels {
+				r.commonLabels[k] = v
+			}
+			if len(opts.commonAnnotations) > 0 && r.commonAnnotations == nil {
+				r.commonAnnotations = make(map[string]string, len(opts.commonAnnotations))
+			}
+			for k, v := range opts.commonAnnotations {
+				r.commonAnnotations[k] = v
+			}
+			r.resetVolumes = sliceutils.AppendWithoutDupes(r.resetVolumes, opts.resetVolumes...)
+			r.hostnames = append(r.hostnames, opts.hostnames...)
+			if opts.prune.IsSet {
+				r.prune = bool(opts.prune.Value)
+			}
+			if opts.gitOpsHandoff.IsSet {
+				r.gitOpsHandoff = bool(opts.gitOpsHandoff.Value)
+			}
+			if opts.cluster != "" {
+				r.cluster = opts.cluster
+			}
 			if opts.newName != "" && opts.newName != r.name {
 				err := s.checkResourceConflict(opts.newName)
 				if err != nil {
@@ -1066,6 +1189,33 @@ func (s *tiltfileState) k8sResourceForName(name string) (*k8sResource, error) {
 // and it's complicated a bit by the fact that there are both normal CRDs where the image shows up in the same place each time, and more meta CRDs (like HelmRelease) where it might appear in different places
 //
 // feels like we're still doing this very ad-hoc rather than holistically
+// The rollout checks from k8s_kind() that apply to any of the entities.
+//
+// Sorted, so that the spec (and whether it needs to be re-applied)
+// doesn't depend on map order.
+func (s *tiltfileState) k8sWaitForList(entities []k8s.K8sEntity) []v1alpha1.KubernetesApplyWaitFor {
+	var result []v1alpha1.KubernetesApplyWaitFor
+	for sel, info := range s.k8sKinds {
+		if info.WaitFor == nil {
+			continue
+		}
+		for _, e := range entities {
+			if sel.Matches(e) {
+				result = append(result, *info.WaitFor)
+				break
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		si, sj := result[i].ObjectSelector, result[j].ObjectSelector
+		if si.KindRegexp != sj.KindRegexp {
+			return si.KindRegexp < sj.KindRegexp
+		}
+		return si.APIVersionRegexp < sj.APIVersionRegexp
+	})
+	return result
+}
+
 func (s *tiltfileState) inferPodReadinessMode(r *k8sResource) model.PodReadinessMode {
 	// The mode set directly on the resource has highest priority.
 	if r.podReadinessMode != model.PodReadinessNone {
@@ -1103,8 +1253,30 @@ func (s *tiltfileState) inferPodReadinessMode(r *k8sResource) model.PodReadiness
 	return model.PodReadinessWait
 }
 
-func (s *tiltfileState) translateK8s(resources []*k8sResource, updateSettings model.UpdateSettings) ([]model.Manifest, error) {
+// The namespaces that the project's YAML may hardcode without asking the user:
+// the ones from allow_k8s_namespaces(), and the ones that the project creates.
+func allowedK8sNamespaces(resources []*k8sResource, unresourced []k8s.K8sEntity, k8sContextState k8scontext.State) []string {
+	allowed := sets.NewString(k8sContextState.AllowedNamespaces()...)
+	entities := append([]k8s.K8sEntity{}, unresourced...)
+	for _, r := range resources {
+		entities = append(entities, r.entities...)
+	}
+	for _, e := range entities {
+		if k8s.IsNamespace(e) {
+			allowed.Insert(e.Name())
+		}
+	}
+	if allowed.Len() == 0 {
+		return nil
+	}
+	return allowed.List()
+}
+
+func (s *tiltfileState) translateK8s(resources []*k8sResource, updateSettings model.UpdateSettings, allowedNamespaces []string) ([]model.Manifest, error) {
 	var result []model.Manifest
+
+	// Each image is built for one cluster, so it can only be deployed to one cluster.
+	imageClusters := make(map[model.TargetID]string)
 	for _, r := range resources {
 		mn := model.ManifestName(r.name)
 		tm, err := starlarkTriggerModeToModel(s.triggerModeForResource(r.triggerMode), r.autoInit)
@@ -1143,6 +1315,17 @@ func (s *tiltfileState) translateK8s(resources []*k8sResource, updateSettings mo
 		if err != nil {
 			return nil, errors.Wrapf(err, "creating K8s deploy target for %s", r.name)
 		}
+		k8sTarget.AllowedNamespaces = allowedNamespaces
+
+		for _, iTarget := range iTargets {
+			cluster, ok := imageClusters[iTarget.ID()]
+			if ok && cluster != k8sTarget.Cluster {
+				return nil, fmt.Errorf("image %q is deployed to clusters %q and %q. "+
+					"An image can only be deployed to one cluster",
+					iTarget.ImageMapSpec.Selector, cluster, k8sTarget.Cluster)
+			}
+			imageClusters[iTarget.ID()] = k8sTarget.Cluster
+		}
 
 		m = m.WithDeployTarget(k8sTarget)
 		result = append(result, m)
@@ -1164,9 +1347,18 @@ func (s *tiltfileState) k8sDeployTarget(targetName model.TargetName, r *k8sResou
 		}
 	}
 
+	cluster := v1alpha1.ClusterNameDefault
+	if r.cluster != "" {
+		if s.k8sClusterNamed(r.cluster) == nil {
+			return model.K8sTarget{}, fmt.Errorf("k8s_resource(%q): cluster %q isn't declared. Declare it with %s()",
+				r.name, r.cluster, k8sClusterN)
+		}
+		cluster = r.cluster
+	}
+
 	sinceTime := apis.NewTime(pkgInitTime)
 	applySpec := v1alpha1.KubernetesApplySpec{
-		Cluster:                         v1alpha1.ClusterNameDefault,
+		Cluster:                         cluster,
 		Timeout:                         metav1.Duration{Duration: updateSettings.K8sUpsertTimeout()},
 		PortForwardTemplateSpec:         k8s.PortForwardTemplateSpec(s.defaultedPortForwards(r.portForwards)),
 		DiscoveryStrategy:               r.discoveryStrategy,
@@ -1183,6 +1375,21 @@ func (s *tiltfileState) k8sDeployTarget(targetName model.TargetName, r *k8sResou
 	var deps []string
 	var ignores []v1alpha1.IgnoreDef
 	if r.customDeploy != nil {
+		if len(r.env) > 0 {
+			return model.K8sTarget{}, fmt.Errorf("k8s_resource(%q): env is not supported with k8s_custom_deploy", r.name)
+		}
+		if len(r.resetVolumes) > 0 {
+			return model.K8sTarget{}, fmt.Errorf("k8s_resource(%q): reset_volumes is not supported with k8s_custom_deploy", r.name)
+		}
+		if r.prune {
+			return model.K8sTarget{}, fmt.Errorf("k8s_resource(%q): prune is not supported with k8s_custom_deploy", r.name)
+		}
+		if r.gitOpsHandoff {
+			return model.K8sTarget{}, fmt.Errorf("k8s_resource(%q): gitops_handoff is not supported with k8s_custom_deploy", r.name)
+		}
+		if len(r.commonLabels) > 0 || len(r.commonAnnotations) > 0 {
+			return model.K8sTarget{}, fmt.Errorf("k8s_resource(%q): common_labels and common_annotations are not supported with k8s_custom_deploy", r.name)
+		}
 		deps = r.customDeploy.deps
 		ignores = append(ignores, model.DockerignoresToIgnores(r.customDeploy.ignores)...)
 		applySpec.ApplyCmd = toKubernetesApplyCmd(r.customDeploy.applyCmd)
@@ -1191,26 +1398,50 @@ func (s *tiltfileState) k8sDeployTarget(targetName model.TargetName, r *k8sResou
 			FileWatches: []string{apis.SanitizeName(fmt.Sprintf("%s:apply", targetName.String()))},
 		}
 	} else {
-		entities := k8s.SortedEntities(r.entities)
-		var err error
+		entities, err := injectEnv(k8s.SortedEntities(r.entities), r.env)
+		if err != nil {
+			return model.K8sTarget{}, err
+		}
 		applySpec.YAML, err = k8s.SerializeSpecYAML(entities)
 		if err != nil {
 			return model.K8sTarget{}, err
 		}
+		applySpec.CommonLabels = r.commonLabels
+		applySpec.CommonAnnotations = r.commonAnnotations
+		applySpec.ResetVolumes = r.resetVolumes
+		applySpec.GitOpsHandoff = r.gitOpsHandoff
 
 		for _, locator := range s.k8sImageLocatorsList() {
 			if k8s.LocatorMatchesOne(locator, entities) {
 				applySpec.ImageLocators = append(applySpec.ImageLocators, locator.ToSpec())
 			}
 		}
+
+		applySpec.WaitFor = s.k8sWaitForList(entities)
 	}
 
 	ignores = append(ignores, repoIgnoresForPaths(deps)...)
 
-	t, err := k8s.NewTarget(targetName, applySpec, s.inferPodReadinessMode(r), r.links)
+	if len(r.hostnames) > 0 && len(r.portForwards) == 0 {
+		return model.K8sTarget{}, fmt.Errorf("k8s_resource(%q): hostnames require port_forwards to route to", r.name)
+	}
+
+	targetLinks := append([]model.Link{}, r.links...)
+	for _, h := range r.hostnames {
+		link, err := model.NewLink(h.URL(), "")
+		if err != nil {
+			return model.K8sTarget{}, err
+		}
+		targetLinks = append(targetLinks, link)
+	}
+
+	t, err := k8s.NewTarget(targetName, applySpec, s.inferPodReadinessMode(r), targetLinks)
 	if err != nil {
 		return model.K8sTarget{}, err
 	}
+	t.WaitForSidecars = r.waitForSidecars
+	t.Prune = r.prune
+	t.Hostnames = r.hostnames
 
 	t = t.WithImageDependencies(model.FilterLiveUpdateOnly(r.imageMapDeps, imageTargets)).
 		WithRefInjectCounts(r.imageRefInjectCounts()).
@@ -1220,6 +1451,37 @@ func (s *tiltfileState) k8sDeployTarget(targetName model.TargetName, r *k8sResou
 	return t, nil
 }
 
+// Set the env vars from k8s_resource(env=...) on every container.
+//
+// Variables are sorted by name, so that the YAML (and whether
+// it needs to be re-applied) doesn't depend on map order.
+func injectEnv(entities []k8s.K8sEntity, env map[string]string) ([]k8s.K8sEntity, error) {
+	if len(env) == 0 {
+		return entities, nil
+	}
+
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	envVars := make([]v1.EnvVar, 0, len(names))
+	for _, name := range names {
+		envVars = append(envVars, v1.EnvVar{Name: name, Value: env[name]})
+	}
+
+	result := make([]k8s.K8sEntity, 0, len(entities))
+	for _, e := range entities {
+		e, err := k8s.InjectEnv(e, envVars)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, e)
+	}
+	return result, nil
+}
+
 // Fill in default values in port-forwarding.
 //
 // In Kubernetes, "defaulted" is used as a verb to say "if a YAML value of a specification
@@ -1327,12 +1589,16 @@ func (s *tiltfileState) validateLiveUpdate(iTarget model.ImageTarget, g model.Ta
 	return nil
 }
 
-func (s *tiltfileState) validateDockerComposeVersion() error {
+func (s *tiltfileState) validateDockerComposeVersion(provider string) error {
 	const minimumDockerComposeVersion = "v1.28.3"
 
-	dcVersion, _, err := s.dcCli.Version(s.ctx)
+	v, err := s.dcCli.Version(s.ctx, provider)
+	dcVersion := v.Version
 	if err != nil {
 		logger.Get(s.ctx).Debugf("Failed to determine Docker Compose version: %v", err)
+	} else if v.Impl != dockercompose.ImplDockerCompose {
+		// We only know the minimum versions for Docker Compose itself.
+		logger.Get(s.ctx).Debugf("Using %s %s for Docker Compose", v.Impl, dcVersion)
 	} else if semver.Compare(dcVersion, minimumDockerComposeVersion) == -1 {
 		return fmt.Errorf(
 			"Tilt requires Docker Compose %s+ (you have %s). Please upgrade and re-launch Tilt.",
@@ -1468,6 +1734,37 @@ func (s *tiltfileState) imgTargetsForDepsHelper(mn model.ManifestName, imageMapD
 				Deps:         image.customDeps,
 			}
 			iTarget = iTarget.WithBuildDetails(r)
+		case OCIArtifactBuild:
+			iTarget.CmdImageName = cmdimage.GetName(mn, iTarget.ID())
+
+			r := model.OCIArtifactBuild{
+				CmdImageSpec: v1alpha1.CmdImageSpec{
+					Args:       image.customCommand.Argv,
+					Dir:        image.workDir,
+					OutputMode: v1alpha1.CmdImageOutputRemote,
+				},
+				Files:          image.ociFiles,
+				ArtifactType:   image.ociArtifactType,
+				LayerMediaType: image.ociMediaType,
+				Deps:           image.customDeps,
+			}
+			iTarget = iTarget.WithBuildDetails(r)
+		case KoBuild:
+			iTarget.CmdImageName = cmdimage.GetName(mn, iTarget.ID())
+
+			r := model.KoBuild{
+				CmdImageSpec: v1alpha1.CmdImageSpec{
+					Dir:        image.workDir,
+					OutputMode: v1alpha1.CmdImageOutputLocalDocker,
+				},
+				ImportPath: image.koImportPath,
+				BaseImage:  image.koBaseImage,
+				BuildFlags: image.koBuildFlags,
+				Env:        image.koEnv,
+				Platform:   image.platform,
+				Deps:       image.customDeps,
+			}
+			iTarget = iTarget.WithBuildDetails(r)
 		case DockerComposeBuild:
 			bd := model.DockerComposeBuild{
 				Service: image.dockerComposeService,
@@ -1494,7 +1791,7 @@ func (s *tiltfileState) imgTargetsForDepsHelper(mn model.ManifestName, imageMapD
 	return iTargets, nil
 }
 
-func (s *tiltfileState) translateDC(dc dcResourceSet) ([]model.Manifest, error) {
+func (s *tiltfileState) translateDC(dc *dcResourceSet) ([]model.Manifest, error) {
 	var result []model.Manifest
 
 	for _, svc := range dc.services {
@@ -1592,7 +1889,8 @@ func (s *tiltfileState) translateLocal() ([]model.Manifest, error) {
 		lt := model.NewLocalTarget(model.TargetName(r.name), r.updateCmd, r.serveCmd, r.deps).
 			WithAllowParallel(r.allowParallel || r.updateCmd.Empty()).
 			WithLinks(r.links).
-			WithReadinessProbe(r.readinessProbe)
+			WithReadinessProbe(r.readinessProbe).
+			WithExternalURL(r.externalURL)
 		lt.FileWatchIgnores = ignores
 
 		var mds []model.ManifestName
@@ -10,6 +10,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -628,6 +629,7 @@ docker_build("gcr.io/foo", "foo", target='stage')
 
 func TestDockerBuildSSH(t *testing.T) {
 	f := newFixture(t)
+	t.Setenv("SSH_AUTH_SOCK", "/tmp/ssh-agent.sock")
 
 	f.setupFoo()
 	f.file("Tiltfile", `
@@ -1250,6 +1252,65 @@ docker_build('gcr.io/d', 'd')
 	f.assertNextManifest("d", db(image("gcr.io/d")), deployment("d"))
 }
 
+func TestYamlDirectory(t *testing.T) {
+	f := newFixture(t)
+
+	f.setupExpand()
+	f.yaml("deploy/b.yaml", deployment("b", image("gcr.io/b")))
+	f.yaml("deploy/a.yml", deployment("a", image("gcr.io/a")))
+	f.yaml("deploy/nested/c.yaml", deployment("c", image("gcr.io/c")))
+	f.file("deploy/README.md", "not yaml")
+	f.file("Tiltfile", `
+k8s_yaml('deploy')
+docker_build('gcr.io/a', 'a')
+docker_build('gcr.io/b', 'b')
+docker_build('gcr.io/c', 'c')
+`)
+	f.load()
+	f.assertNextManifest("a", db(image("gcr.io/a")), deployment("a"))
+	f.assertNextManifest("b", db(image("gcr.io/b")), deployment("b"))
+	f.assertNextManifest("c", db(image("gcr.io/c")), deployment("c"))
+	f.assertNoMoreManifests()
+	f.assertConfigFiles("Tiltfile", ".tiltignore", "deploy", "deploy/a.yml", "deploy/b.yaml", "deploy/nested/c.yaml",
+		"a/Dockerfile", "a/.dockerignore", "b/Dockerfile", "b/.dockerignore", "c/Dockerfile", "c/.dockerignore")
+}
+
+func TestYamlGlob(t *testing.T) {
+	f := newFixture(t)
+
+	f.setupExpand()
+	f.yaml("deploy/d.yaml", deployment("d", image("gcr.io/d")))
+	f.yaml("deploy/nested/c.yaml", deployment("c", image("gcr.io/c")))
+	f.yaml("deploy/b.yaml", deployment("b", image("gcr.io/b")))
+	f.yaml("deploy/a.json", deployment("a", image("gcr.io/a")))
+	f.file("Tiltfile", `
+k8s_yaml('deploy/**/*.yaml')
+docker_build('gcr.io/b', 'b')
+docker_build('gcr.io/c', 'c')
+docker_build('gcr.io/d', 'd')
+`)
+	f.load()
+	f.assertNextManifest("b", db(image("gcr.io/b")), deployment("b"))
+	f.assertNextManifest("d", db(image("gcr.io/d")), deployment("d"))
+	f.assertNextManifest("c", db(image("gcr.io/c")), deployment("c"))
+	f.assertNoMoreManifests()
+	assert.Contains(t, f.loadResult.ConfigFiles, f.JoinPath("deploy"))
+}
+
+func TestYamlGlobNoMatches(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+k8s_yaml('deploy/*.yaml')
+`)
+	f.loadAllowWarnings()
+	f.assertNoMoreManifests()
+	f.assertWarnings(fmt.Sprintf("k8s_yaml: no YAML files found at %s", f.JoinPath("deploy", "*.yaml")))
+
+	// Watch the directory, so that we reload when it appears.
+	assert.Contains(t, f.loadResult.ConfigFiles, f.JoinPath("deploy"))
+}
+
 func TestLoadOneManifest(t *testing.T) {
 	f := newFixture(t)
 
@@ -1889,6 +1950,333 @@ k8s_resource(new_name='config', objects=['config'], pod_readiness='w')
 	f.loadErrString("Invalid value. Allowed: {ignore, wait}. Got: w")
 }
 
+func TestWaitForSidecars(t *testing.T) {
+	f := newFixture(t)
+
+	f.yaml("foo.yaml", deployment("foo", image("gcr.io/foo:stable")))
+	f.yaml("bar.yaml", deployment("bar", image("gcr.io/bar:stable")))
+	f.file("Tiltfile", `
+k8s_yaml(['foo.yaml', 'bar.yaml'])
+k8s_resource('foo', wait_for_sidecars=True)
+`)
+
+	f.load()
+	foo := f.assertNextManifest("foo", deployment("foo"))
+	assert.True(t, foo.WaitForSidecars())
+	bar := f.assertNextManifest("bar", deployment("bar"))
+	assert.False(t, bar.WaitForSidecars())
+}
+
+func TestK8sResourceEnv(t *testing.T) {
+	f := newFixture(t)
+
+	f.yaml("foo.yaml", deployment("foo", image("gcr.io/foo:stable")))
+	f.yaml("bar.yaml", deployment("bar", image("gcr.io/bar:stable")))
+	f.file("Tiltfile", `
+k8s_yaml(['foo.yaml', 'bar.yaml'])
+k8s_resource('foo', env={'FEATURE_X': 'true', 'DEBUG': '1'})
+k8s_resource('foo', env={'DEBUG': '2'})
+`)
+
+	f.load()
+	foo := f.assertNextManifest("foo", deployment("foo"))
+	entities, err := k8s.ParseYAMLFromString(foo.K8sTarget().YAML)
+	require.NoError(t, err)
+	pods, err := k8s.ExtractPods(entities[0].Obj)
+	require.NoError(t, err)
+	assert.Equal(t, []v1.EnvVar{
+		{Name: "DEBUG", Value: "2"},
+		{Name: "FEATURE_X", Value: "true"},
+	}, pods[0].Containers[0].Env)
+
+	bar := f.assertNextManifest("bar", deployment("bar"))
+	assert.NotContains(t, bar.K8sTarget().YAML, "FEATURE_X")
+}
+
+func TestK8sResourceEnvCustomDeploy(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+k8s_custom_deploy('foo', 'apply', 'delete', deps=['foo'])
+k8s_resource('foo', env={'FEATURE_X': 'true'})
+`)
+
+	f.loadErrString(`k8s_resource("foo"): env is not supported with k8s_custom_deploy`)
+}
+
+func TestK8sResourceResetVolumes(t *testing.T) {
+	f := newFixture(t)
+
+	f.yaml("foo.yaml", deployment("foo", image("gcr.io/foo:stable")))
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+k8s_resource('foo', reset_volumes='pgdata')
+k8s_resource('foo', reset_volumes=['pgdata', 'uploads'])
+`)
+
+	f.load()
+	foo := f.assertNextManifest("foo", deployment("foo"))
+	assert.Equal(t, []string{"pgdata", "uploads"}, foo.K8sTarget().ResetVolumes)
+}
+
+func TestK8sResourceCommonLabelsAndAnnotations(t *testing.T) {
+	f := newFixture(t)
+
+	f.yaml("foo.yaml", deployment("foo", image("gcr.io/foo:stable")))
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+k8s_resource('foo', common_labels={'team': 'payments'}, common_annotations={'example.com/ticket': 'PAY-1'})
+k8s_resource('foo', common_annotations={'example.com/ticket': 'PAY-2', 'example.com/owner': 'nick'})
+`)
+
+	f.load()
+	foo := f.assertNextManifest("foo", deployment("foo"))
+	spec := foo.K8sTarget().KubernetesApplySpec
+	assert.Equal(t, map[string]string{"team": "payments"}, spec.CommonLabels)
+	assert.Equal(t, map[string]string{
+		"example.com/ticket": "PAY-2",
+		"example.com/owner":  "nick",
+	}, spec.CommonAnnotations)
+}
+
+func TestK8sResourceCommonLabelsInvalid(t *testing.T) {
+	f := newFixture(t)
+
+	f.yaml("foo.yaml", deployment("foo", image("gcr.io/foo:stable")))
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+k8s_resource('foo', common_labels={'team': 'not a valid value'})
+`)
+
+	f.loadErrString(`common_labels: Invalid value: "not a valid value"`)
+}
+
+func TestK8sResourceCommonAnnotationsTiltPrefix(t *testing.T) {
+	f := newFixture(t)
+
+	f.yaml("foo.yaml", deployment("foo", image("gcr.io/foo:stable")))
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+k8s_resource('foo', common_annotations={'tilt.dev/resource': 'bar'})
+`)
+
+	f.loadErrString("the tilt.dev/ prefix is reserved")
+}
+
+func TestK8sResourceCommonLabelsCustomDeploy(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+k8s_custom_deploy('foo', apply_cmd='true', delete_cmd='true', deps=[])
+k8s_resource('foo', common_labels={'team': 'payments'})
+`)
+
+	f.loadErrString(`k8s_resource("foo"): common_labels and common_annotations are not supported with k8s_custom_deploy`)
+}
+
+func TestK8sResourceHostnames(t *testing.T) {
+	f := newFixture(t)
+
+	f.yaml("foo.yaml", deployment("foo", image("gcr.io/foo:stable")))
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+k8s_resource('foo', port_forwards=8000, hostnames=['Foo.localhost', 'shop.localhost/foo'])
+`)
+
+	f.load()
+	foo := f.assertNextManifest("foo", deployment("foo"))
+	assert.Equal(t, []model.Hostname{
+		{Host: "foo.localhost", Path: "/"},
+		{Host: "shop.localhost", Path: "/foo"},
+	}, foo.K8sTarget().Hostnames)
+
+	var urls []string
+	for _, link := range foo.K8sTarget().Links {
+		urls = append(urls, link.URLString())
+	}
+	assert.Equal(t, []string{
+		"https://foo.localhost:10443/",
+		"https://shop.localhost:10443/foo",
+	}, urls)
+}
+
+func TestK8sResourceHostnamesRequirePortForwards(t *testing.T) {
+	f := newFixture(t)
+
+	f.yaml("foo.yaml", deployment("foo", image("gcr.io/foo:stable")))
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+k8s_resource('foo', hostnames='foo.localhost')
+`)
+
+	f.loadErrString(`k8s_resource("foo"): hostnames require port_forwards to route to`)
+}
+
+func TestK8sResourceInvalidHostname(t *testing.T) {
+	f := newFixture(t)
+
+	f.yaml("foo.yaml", deployment("foo", image("gcr.io/foo:stable")))
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+k8s_resource('foo', port_forwards=8000, hostnames='foo_bar.localhost')
+`)
+
+	f.loadErrString(`invalid hostname "foo_bar.localhost"`)
+}
+
+func TestK8sResourcePrune(t *testing.T) {
+	f := newFixture(t)
+
+	f.yaml("foo.yaml", deployment("foo", image("gcr.io/foo:stable")))
+	f.yaml("bar.yaml", deployment("bar", image("gcr.io/bar:stable")))
+	f.file("Tiltfile", `
+k8s_yaml(['foo.yaml', 'bar.yaml'])
+k8s_resource('foo', prune=True)
+`)
+
+	f.load()
+	foo := f.assertNextManifest("foo", deployment("foo"))
+	assert.True(t, foo.K8sTarget().Prune)
+	bar := f.assertNextManifest("bar", deployment("bar"))
+	assert.False(t, bar.K8sTarget().Prune)
+}
+
+func TestK8sResourcePruneCustomDeploy(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+k8s_custom_deploy('foo', 'apply', 'delete', deps=['foo'])
+k8s_resource('foo', prune=True)
+`)
+
+	f.loadErrString(`k8s_resource("foo"): prune is not supported with k8s_custom_deploy`)
+}
+
+func TestK8sResourceGitOpsHandoff(t *testing.T) {
+	f := newFixture(t)
+
+	f.yaml("foo.yaml", deployment("foo", image("gcr.io/foo:stable")))
+	f.yaml("bar.yaml", deployment("bar", image("gcr.io/bar:stable")))
+	f.file("Tiltfile", `
+k8s_yaml(['foo.yaml', 'bar.yaml'])
+k8s_resource('foo', gitops_handoff=True)
+`)
+
+	f.load()
+	foo := f.assertNextManifest("foo", deployment("foo"))
+	assert.True(t, foo.K8sTarget().GitOpsHandoff)
+	bar := f.assertNextManifest("bar", deployment("bar"))
+	assert.False(t, bar.K8sTarget().GitOpsHandoff)
+}
+
+func TestK8sResourceGitOpsHandoffCustomDeploy(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+k8s_custom_deploy('foo', 'apply', 'delete', deps=['foo'])
+k8s_resource('foo', gitops_handoff=True)
+`)
+
+	f.loadErrString(`k8s_resource("foo"): gitops_handoff is not supported with k8s_custom_deploy`)
+}
+
+func TestK8sCluster(t *testing.T) {
+	f := newFixture(t)
+
+	f.setupFoo()
+	f.yaml("foo.yaml", deployment("foo", image("gcr.io/foo")))
+	f.yaml("bar.yaml", deployment("bar", image("gcr.io/bar:stable")))
+	f.file("Tiltfile", `
+k8s_cluster('edge', context='kind-edge', namespace='proxy', default_registry='localhost:5005')
+docker_build('gcr.io/foo', 'foo')
+k8s_yaml(['foo.yaml', 'bar.yaml'])
+k8s_resource('foo', cluster='edge')
+`)
+
+	f.load()
+
+	foo := f.assertNextManifest("foo", deployment("foo"))
+	assert.Equal(t, "edge", foo.K8sTarget().Cluster)
+	assert.Equal(t, "edge", foo.ClusterName())
+	assert.Equal(t, "edge", foo.ImageTargets[0].BuildDetails.(model.DockerBuild).Cluster)
+
+	bar := f.assertNextManifest("bar", deployment("bar"))
+	assert.Equal(t, v1alpha1.ClusterNameDefault, bar.K8sTarget().Cluster)
+
+	require.Len(t, f.loadResult.K8sClusters, 1)
+	edge := f.loadResult.K8sClusters[0]
+	assert.Equal(t, "edge", edge.Name)
+	assert.Equal(t, &v1alpha1.KubernetesClusterConnection{Context: "kind-edge", Namespace: "proxy"},
+		edge.Spec.Connection.Kubernetes)
+	assert.Equal(t, "localhost:5005", edge.Spec.DefaultRegistry.Host)
+}
+
+func TestK8sClusterNotDeclared(t *testing.T) {
+	f := newFixture(t)
+
+	f.yaml("foo.yaml", deployment("foo", image("gcr.io/foo:stable")))
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+k8s_resource('foo', cluster='edge')
+`)
+
+	f.loadErrString(`k8s_resource("foo"): cluster "edge" isn't declared. Declare it with k8s_cluster()`)
+}
+
+func TestK8sClusterInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		tiltfile string
+		err      string
+	}{
+		{"reserved", `k8s_cluster('default', context='kind-kind')`, `cluster name "default" is reserved`},
+		{"invalid name", `k8s_cluster('Edge_1', context='kind-kind')`, `invalid cluster name "Edge_1"`},
+		{"no context", `k8s_cluster('edge', context='')`, `context must not be empty`},
+		{"duplicate", "k8s_cluster('edge', context='a')\nk8s_cluster('edge', context='b')", `cluster "edge" already declared`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newFixture(t)
+			f.file("Tiltfile", tc.tiltfile)
+			f.loadErrString(tc.err)
+		})
+	}
+}
+
+func TestK8sClusterImageInTwoClusters(t *testing.T) {
+	f := newFixture(t)
+
+	f.setupFoo()
+	f.yaml("foo.yaml", deployment("foo", image("gcr.io/foo")))
+	f.yaml("bar.yaml", deployment("bar", image("gcr.io/foo")))
+	f.file("Tiltfile", `
+k8s_cluster('edge', context='kind-edge')
+docker_build('gcr.io/foo', 'foo')
+k8s_yaml(['foo.yaml', 'bar.yaml'])
+k8s_resource('bar', cluster='edge')
+`)
+
+	f.loadErrString(`image "gcr.io/foo" is deployed to clusters "default" and "edge"`)
+}
+
+func TestAllowK8sNamespaces(t *testing.T) {
+	f := newFixture(t)
+
+	f.yaml("ns.yaml", namespace("team-a"))
+	f.yaml("foo.yaml", deployment("foo", image("gcr.io/foo:stable"), namespace("team-b")))
+	f.file("Tiltfile", `
+k8s_yaml(['ns.yaml', 'foo.yaml'])
+allow_k8s_namespaces('monitoring')
+`)
+
+	f.load()
+
+	// Namespaces that the project creates don't need approval.
+	foo := f.assertNextManifest("foo", deployment("foo"))
+	assert.Equal(t, []string{"monitoring", "team-a"}, foo.K8sTarget().AllowedNamespaces)
+	unresourced := f.assertNextManifestUnresourced("team-a")
+	assert.Equal(t, []string{"monitoring", "team-a"}, unresourced.K8sTarget().AllowedNamespaces)
+}
+
 func TestDockerBuildMatchingTag(t *testing.T) {
 	f := newFixture(t)
 
@@ -1963,6 +2351,54 @@ k8s_yaml('foo.yaml')
 	f.loadAssertWarnings(w)
 }
 
+func TestDockerBuildMultipleUnusedImages(t *testing.T) {
+	f := newFixture(t)
+
+	f.gitInit("")
+	f.file("Dockerfile", "FROM golang:1.10")
+	f.yaml("foo.yaml", deployment("foo", image("gcr.io/foo")))
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', '.')
+docker_build('gcr.io/bar', '.')
+docker_build('gcr.io/baz', '.')
+k8s_yaml('foo.yaml')
+`)
+
+	f.loadAssertWarnings(
+		unusedImageWarning("gcr.io/bar", []string{"gcr.io/foo"}, "Kubernetes"),
+		unusedImageWarning("gcr.io/baz", []string{"gcr.io/foo"}, "Kubernetes"))
+}
+
+func TestDockerBuildUnusedBaseImage(t *testing.T) {
+	f := newFixture(t)
+
+	f.gitInit("")
+	f.file("base/Dockerfile", "FROM golang:1.10")
+	f.file("foo/Dockerfile", "FROM gcr.io/base")
+	f.file("bar/Dockerfile", "FROM gcr.io/base")
+	f.yaml("foo.yaml", deployment("foo", image("gcr.io/foo")))
+	f.file("Tiltfile", `
+docker_build('gcr.io/base', 'base')
+docker_build('gcr.io/foo', 'foo')
+k8s_yaml('foo.yaml')
+`)
+
+	// The base image is used by an image that's deployed.
+	f.load()
+
+	f.file("Tiltfile", `
+docker_build('gcr.io/base', 'base')
+docker_build('gcr.io/bar', 'bar')
+k8s_yaml('foo.yaml')
+`)
+
+	// Now the only image that uses the base image isn't deployed.
+	f.loadAssertWarnings(
+		strings.Replace(unusedImageWarning("gcr.io/base", []string{"gcr.io/foo"}, "Kubernetes"),
+			"\nDid you mean", "\nOnly used to build other unused images: gcr.io/bar\nDid you mean", 1),
+		unusedImageWarning("gcr.io/bar", []string{"gcr.io/foo"}, "Kubernetes"))
+}
+
 func TestFail(t *testing.T) {
 	f := newFixture(t)
 
@@ -2411,56 +2847,182 @@ custom_build(
 	assert.False(t, m.ImageTargets[0].CustomBuildInfo().SkipsPush())
 }
 
-func TestCustomBuildDisablePush(t *testing.T) {
+func TestCustomBuildDisablePush(t *testing.T) {
+	f := newFixture(t)
+
+	tiltfile := `k8s_yaml('foo.yaml')
+hfb = custom_build(
+  'gcr.io/foo',
+  'docker build -t $TAG foo',
+	['foo'],
+	disable_push=True,
+)`
+
+	f.setupFoo()
+	f.file("Tiltfile", tiltfile)
+
+	f.load("foo")
+	f.assertNumManifests(1)
+	f.assertConfigFiles("Tiltfile", ".tiltignore", "foo.yaml", "foo/.dockerignore")
+	f.assertNextManifest("foo",
+		cb(
+			image("gcr.io/foo"),
+			deps(f.JoinPath("foo")),
+			cmd("docker build -t $TAG foo", f.Path()),
+			disablePush(true),
+		),
+		deployment("foo"))
+}
+
+func TestCustomBuildSkipsLocalDocker(t *testing.T) {
+	f := newFixture(t)
+
+	tiltfile := `
+k8s_yaml('foo.yaml')
+custom_build(
+  'gcr.io/foo',
+  'buildah bud -t $TAG foo && buildah push $TAG $TAG',
+	['foo'],
+	skips_local_docker=True,
+)`
+
+	f.setupFoo()
+	f.file("Tiltfile", tiltfile)
+
+	f.load("foo")
+	m := f.assertNextManifest("foo",
+		cb(
+			image("gcr.io/foo"),
+		),
+		deployment("foo"))
+	assert.Equal(t, v1alpha1.CmdImageOutputRemote, m.ImageTargets[0].CustomBuildInfo().OutputMode)
+	assert.True(t, m.ImageTargets[0].CustomBuildInfo().SkipsPush())
+}
+
+func TestOCIArtifact(t *testing.T) {
+	f := newFixture(t)
+
+	f.setupFoo()
+	f.file("plugin.wasm", "wasm")
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+oci_artifact('gcr.io/foo', ['plugin.wasm'], artifact_type='application/vnd.wasm.config.v1+json')
+`)
+
+	f.load("foo")
+	m := f.assertNextManifest("foo", deployment("foo"))
+	iTarget := m.ImageTargets[0]
+	require.True(t, iTarget.IsOCIArtifactBuild())
+
+	info := iTarget.OCIArtifactBuildInfo()
+	assert.Equal(t, []string{f.JoinPath("plugin.wasm")}, info.Files)
+	assert.Equal(t, []string{f.JoinPath("plugin.wasm")}, info.Deps)
+	assert.Equal(t, "application/vnd.wasm.config.v1+json", info.ArtifactType)
+	assert.Equal(t, v1alpha1.CmdImageOutputRemote, info.OutputMode)
+	assert.Empty(t, info.Args)
+	assert.NotEmpty(t, iTarget.CmdImageName)
+	assert.Equal(t, []string{f.JoinPath("plugin.wasm")}, iTarget.LocalPaths())
+}
+
+func TestOCIArtifactCommand(t *testing.T) {
+	f := newFixture(t)
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+oci_artifact('gcr.io/foo', ['out/plugin.wasm'], command='make plugin', deps=['src'])
+`)
+
+	f.load("foo")
+	m := f.assertNextManifest("foo", deployment("foo"))
+	info := m.ImageTargets[0].OCIArtifactBuildInfo()
+	assert.Equal(t, model.ToHostCmd("make plugin").Argv, info.Args)
+	assert.Equal(t, f.Path(), info.Dir)
+	assert.Equal(t, []string{f.JoinPath("src")}, info.Deps)
+	assert.Equal(t, []string{f.JoinPath("out", "plugin.wasm")}, info.Files)
+}
+
+func TestOCIArtifactCommandWithoutDeps(t *testing.T) {
+	f := newFixture(t)
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+oci_artifact('gcr.io/foo', ['plugin.wasm'], command='make plugin')
+`)
+
+	f.loadErrString("oci_artifact: deps must be specified when command is specified")
+}
+
+func TestOCIArtifactNoFiles(t *testing.T) {
+	f := newFixture(t)
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+oci_artifact('gcr.io/foo', [])
+`)
+
+	f.loadErrString("Argument 2 (files) can't be empty")
+}
+
+func TestKoBuild(t *testing.T) {
 	f := newFixture(t)
 
-	tiltfile := `k8s_yaml('foo.yaml')
-hfb = custom_build(
-  'gcr.io/foo',
-  'docker build -t $TAG foo',
-	['foo'],
-	disable_push=True,
-)`
-
 	f.setupFoo()
-	f.file("Tiltfile", tiltfile)
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+ko_build('gcr.io/foo', './cmd/foo', build_flags=['-tags=netgo'], env={'GOFLAGS': '-mod=vendor'}, platform='linux/arm64')
+`)
 
 	f.load("foo")
-	f.assertNumManifests(1)
-	f.assertConfigFiles("Tiltfile", ".tiltignore", "foo.yaml", "foo/.dockerignore")
-	f.assertNextManifest("foo",
-		cb(
-			image("gcr.io/foo"),
-			deps(f.JoinPath("foo")),
-			cmd("docker build -t $TAG foo", f.Path()),
-			disablePush(true),
-		),
-		deployment("foo"))
+	m := f.assertNextManifest("foo", deployment("foo"))
+	iTarget := m.ImageTargets[0]
+	require.True(t, iTarget.IsKoBuild())
+
+	info := iTarget.KoBuildInfo()
+	assert.Equal(t, "./cmd/foo", info.ImportPath)
+	assert.Equal(t, model.KoDefaultBaseImage, info.BaseImage)
+	assert.Equal(t, []string{"-tags=netgo"}, info.BuildFlags)
+	assert.Equal(t, []string{"GOFLAGS=-mod=vendor"}, info.Env)
+	assert.Equal(t, "linux/arm64", info.Platform)
+	assert.Equal(t, f.Path(), info.Dir)
+	assert.Equal(t, v1alpha1.CmdImageOutputLocalDocker, info.OutputMode)
+	assert.NotEmpty(t, iTarget.CmdImageName)
+	assert.Equal(t, []string{f.Path()}, iTarget.LocalPaths())
 }
 
-func TestCustomBuildSkipsLocalDocker(t *testing.T) {
+func TestKoBuildBaseImageDependency(t *testing.T) {
 	f := newFixture(t)
 
-	tiltfile := `
+	f.setupFoo()
+	f.file("Dockerfile.base", "FROM alpine")
+	f.file("Tiltfile", `
 k8s_yaml('foo.yaml')
-custom_build(
-  'gcr.io/foo',
-  'buildah bud -t $TAG foo && buildah push $TAG $TAG',
-	['foo'],
-	skips_local_docker=True,
-)`
+docker_build('gcr.io/foo-base', '.', dockerfile='Dockerfile.base')
+ko_build('gcr.io/foo', './cmd/foo', deps=['./cmd'], base_image='gcr.io/foo-base')
+`)
+
+	f.load("foo")
+	m := f.assertNextManifest("foo", deployment("foo"))
+	require.Len(t, m.ImageTargets, 2)
+	assert.Equal(t, "gcr.io/foo-base", m.ImageTargets[0].ImageMapSpec.Selector)
+	ko := m.ImageTargets[1]
+	assert.Equal(t, "gcr.io/foo-base", ko.KoBuildInfo().BaseImage)
+	assert.Equal(t, []string{f.JoinPath("cmd")}, ko.LocalPaths())
+	assert.Equal(t, []string{m.ImageTargets[0].ImageMapName()}, ko.ImageMapDeps())
+}
+
+func TestKoBuildNoImportPath(t *testing.T) {
+	f := newFixture(t)
 
 	f.setupFoo()
-	f.file("Tiltfile", tiltfile)
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+ko_build('gcr.io/foo', '')
+`)
 
-	f.load("foo")
-	m := f.assertNextManifest("foo",
-		cb(
-			image("gcr.io/foo"),
-		),
-		deployment("foo"))
-	assert.Equal(t, v1alpha1.CmdImageOutputRemote, m.ImageTargets[0].CustomBuildInfo().OutputMode)
-	assert.True(t, m.ImageTargets[0].CustomBuildInfo().SkipsPush())
+	f.loadErrString("Argument 2 (importpath) can't be empty")
 }
 
 func TestImageObjectJSONPath(t *testing.T) {
@@ -2528,6 +3090,40 @@ docker_build('tilt.dev/frontend', '.')
 		m.ImageTargets[0].ImageMapSpec.Selector)
 }
 
+func TestK8sKindWaitFor(t *testing.T) {
+	f := newFixture(t)
+	f.setupFoo()
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+docker_build('gcr.io/foo', 'foo')
+k8s_kind('Deployment', wait_for='condition=Available', wait_timeout='10m')
+k8s_kind('UselessMachine', wait_for='jsonpath={.status.phase}=Ready')
+`)
+
+	f.load()
+	m := f.assertNextManifest("foo")
+	assert.Equal(t, []v1alpha1.KubernetesApplyWaitFor{
+		{
+			ObjectSelector: v1alpha1.ObjectSelector{KindRegexp: "(?i)Deployment"},
+			Condition:      "Available",
+			Timeout:        metav1.Duration{Duration: 10 * time.Minute},
+		},
+	}, m.K8sTarget().KubernetesApplySpec.WaitFor)
+}
+
+func TestK8sKindWaitForInvalid(t *testing.T) {
+	f := newFixture(t)
+	f.file("Tiltfile", `
+k8s_kind('Deployment', wait_for='available')
+`)
+	f.loadErrString("Expected condition=[type] or jsonpath={path}=[value]")
+
+	f.file("Tiltfile", `
+k8s_kind('Deployment', wait_timeout='10m')
+`)
+	f.loadErrString("Cannot specify wait_timeout without wait_for")
+}
+
 func TestExtraImageLocationOneImage(t *testing.T) {
 	f := newFixture(t)
 	f.setupCRD()
@@ -2950,6 +3546,75 @@ docker_build('gcr.io/foo', 'foo')
 		deployment("foo"))
 }
 
+func TestBuildKitBuilder(t *testing.T) {
+	f := newFixture(t)
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+buildkit_builder('tcp://buildkitd:1234', ca_cert='certs/ca.pem', cert='certs/cert.pem', key='certs/key.pem')
+k8s_yaml('foo.yaml')
+docker_build('gcr.io/foo', 'foo')
+`)
+
+	f.load()
+
+	assert.Equal(t, &v1alpha1.BuildKitConnection{
+		Address: "tcp://buildkitd:1234",
+		CACert:  f.JoinPath("certs", "ca.pem"),
+		Cert:    f.JoinPath("certs", "cert.pem"),
+		Key:     f.JoinPath("certs", "key.pem"),
+	}, f.loadResult.BuildKit)
+}
+
+func TestBuildKitBuilderInvalid(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+buildkit_builder('ssh://buildkitd')
+`)
+
+	f.loadErrString("validating buildkit_builder", `Unsupported value: "ssh"`)
+}
+
+func TestContainerEngine(t *testing.T) {
+	f := newFixture(t)
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+container_engine('podman', host='unix:///run/podman/podman.sock')
+k8s_yaml('foo.yaml')
+docker_build('gcr.io/foo', 'foo')
+`)
+
+	f.load()
+
+	assert.Equal(t, model.ContainerEngineSettings{
+		Engine: model.ContainerEnginePodman,
+		Host:   "unix:///run/podman/podman.sock",
+	}, f.loadResult.ContainerEngine)
+}
+
+func TestContainerEngineInvalid(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+container_engine('rkt')
+`)
+
+	f.loadErrString(`container_engine: unknown engine "rkt". Valid engines: [docker podman]`)
+}
+
+func TestTwoBuildKitBuilders(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+buildkit_builder('tcp://a:1234')
+buildkit_builder('tcp://b:1234')
+`)
+
+	f.loadErrString("buildkit builder already defined")
+}
+
 func TestDefaultRegistryAtEndOfTiltfile(t *testing.T) {
 	f := newFixture(t)
 
@@ -3928,6 +4593,52 @@ func TestDisableObsoleteFeature(t *testing.T) {
 	f.loadAssertWarnings("Obsolete feature flag: obsoleteflag")
 }
 
+func TestFeatures(t *testing.T) {
+	f := newFixture(t)
+	f.features["testflag_disabled"] = feature.Value{Enabled: false}
+	f.features["testflag_enabled"] = feature.Value{Enabled: true}
+	f.features["obsoleteflag"] = feature.Value{Status: feature.Obsolete, Enabled: true}
+	f.setupFoo()
+
+	f.file("Tiltfile", `
+flags = features(enable=['testflag_disabled'], disable=['testflag_enabled'])
+if not flags['testflag_disabled'] or flags['testflag_enabled']:
+  fail('unexpected flags: %s' % flags)
+if 'obsoleteflag' in flags:
+  fail('obsolete flags should be omitted: %s' % flags)
+`)
+	f.load()
+
+	f.assertFeature("testflag_disabled", true)
+	f.assertFeature("testflag_enabled", false)
+	assert.Equal(t, feature.SourceTiltfile, f.loadResult.FeatureSources["testflag_disabled"])
+}
+
+func TestFeaturesEnableAndDisable(t *testing.T) {
+	f := newFixture(t)
+	f.features["testflag"] = feature.Value{Enabled: false}
+	f.setupFoo()
+
+	f.file("Tiltfile", `features(enable=['testflag'], disable=['testflag'])`)
+
+	f.loadErrString(`features: feature flag "testflag" is in both enable and disable`)
+}
+
+func TestFeatureRollout(t *testing.T) {
+	f := newFixture(t)
+	f.features["testflag_everyone"] = feature.Value{Enabled: false, Rollout: 100}
+	f.features["testflag_optout"] = feature.Value{Enabled: false, Rollout: 100}
+	f.setupFoo()
+
+	f.file("Tiltfile", `disable_feature('testflag_optout')`)
+	f.load()
+
+	f.assertFeature("testflag_everyone", true)
+	f.assertFeature("testflag_optout", false)
+	assert.Equal(t, feature.SourceRollout, f.loadResult.FeatureSources["testflag_everyone"])
+	assert.Equal(t, feature.SourceTiltfile, f.loadResult.FeatureSources["testflag_optout"])
+}
+
 func TestDisableSnapshots(t *testing.T) {
 	f := newFixture(t)
 	f.setupFoo()
@@ -4911,6 +5622,45 @@ func TestK8sUpsertTimeout(t *testing.T) {
 	}
 }
 
+func TestK8sJobRetention(t *testing.T) {
+	for _, tc := range []struct {
+		name                string
+		tiltfile            string
+		expectErrorContains string
+		expectedRetention   time.Duration
+	}{
+		{
+			name:              "default value if func not called",
+			tiltfile:          "print('hello world')",
+			expectedRetention: 0,
+		},
+		{
+			name:              "set job retention",
+			tiltfile:          "update_settings(k8s_job_retention_secs=600)",
+			expectedRetention: 10 * time.Minute,
+		},
+		{
+			name:                "must be non-negative",
+			tiltfile:            "update_settings(k8s_job_retention_secs=-1)",
+			expectErrorContains: "k8s job retention must be >= 0",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newFixture(t)
+
+			f.file("Tiltfile", tc.tiltfile)
+
+			if tc.expectErrorContains != "" {
+				f.loadErrString(tc.expectErrorContains)
+				return
+			}
+
+			f.load()
+			assert.Equal(t, tc.expectedRetention, f.loadResult.UpdateSettings.K8sJobRetention())
+		})
+	}
+}
+
 func TestUpdateSettingsCalledTwice(t *testing.T) {
 	f := newFixture(t)
 
@@ -5705,6 +6455,158 @@ local_resource("test2", cmd="echo hi2", labels=["bar", "baz"])
 	f.assertNextManifest("test2", resourceLabels("bar", "baz"))
 }
 
+func TestGroup(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+local_resource("api", cmd="echo hi", labels="backend")
+local_resource("db", cmd="echo hi")
+local_resource("web", cmd="echo hi")
+group("payments/api", ["api", "db"])
+`)
+
+	f.load()
+	f.assertNumManifests(3)
+
+	api := f.assertNextManifest("api")
+	assert.Equal(t, map[string]string{
+		"backend":                "backend",
+		model.ManifestGroupLabel: "payments.api",
+	}, api.Labels)
+	assert.Equal(t, "payments/api", api.GroupPath())
+	assert.Equal(t, "payments/api", f.assertNextManifest("db").GroupPath())
+	assert.Equal(t, "", f.assertNextManifest("web").GroupPath())
+}
+
+func TestGroupSelectedByArgs(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+local_resource("api", cmd="echo hi")
+local_resource("db", cmd="echo hi")
+local_resource("worker", cmd="echo hi")
+local_resource("web", cmd="echo hi")
+group("payments/api", ["api", "db"])
+group("payments/jobs", "worker")
+`)
+
+	f.load("payments")
+	require.Equal(t, []model.ManifestName{"api", "db", "worker"}, f.loadResult.EnabledManifests)
+
+	f.load("payments/jobs")
+	require.Equal(t, []model.ManifestName{"worker"}, f.loadResult.EnabledManifests)
+
+	f.loadArgsErrString([]string{"pay"}, `You specified some resources that could not be found: "pay"`)
+}
+
+func TestGroupSelectedWithOnlyFlag(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+config.define_string_list("only")
+cfg = config.parse()
+config.set_enabled_resources(cfg.get("only", []))
+
+local_resource("api", cmd="echo hi")
+local_resource("db", cmd="echo hi")
+local_resource("worker", cmd="echo hi")
+group("payments/api", ["api", "db"])
+group("payments/jobs", "worker")
+`)
+
+	f.load("--only", "payments/api")
+	require.Equal(t, []model.ManifestName{"api", "db"}, f.loadResult.EnabledManifests)
+}
+
+func TestGroupUnknownResource(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+local_resource("api", cmd="echo hi")
+group("payments", ["api", "db"])
+`)
+
+	f.loadErrString(`group: no resource found named "db"`)
+}
+
+func TestGroupConflict(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+local_resource("api", cmd="echo hi")
+group("payments", "api")
+group("billing", "api")
+`)
+
+	f.loadErrString(`group: resource "api" is already in group "payments"`)
+}
+
+func TestExternalTrigger(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+local_resource("api", cmd="echo hi")
+local_resource("web", cmd="echo hi")
+external_trigger("schema", "api", watch_object="configmap/schema", watch_field="{.data.version}")
+`)
+
+	f.load()
+	f.assertNumManifests(2)
+
+	api := f.assertNextManifest("api")
+	assert.Equal(t, []model.ExternalTrigger{
+		{Name: "schema", WatchObject: "configmap/schema", WatchField: "{.data.version}"},
+	}, api.ExternalTriggers)
+	assert.Equal(t, &v1alpha1.RestartOnSpec{
+		ObjectFields: []v1alpha1.ObjectFieldSource{
+			{Kind: "ConfigMap", Name: "schema", FieldPath: "{.data.version}"},
+		},
+	}, api.LocalTarget().UpdateCmdSpec.RestartOn)
+
+	web := f.assertNextManifest("web")
+	assert.Empty(t, web.ExternalTriggers)
+	assert.Nil(t, web.LocalTarget().UpdateCmdSpec.RestartOn)
+}
+
+func TestExternalTriggerWatchK8s(t *testing.T) {
+	f := newFixture(t)
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+external_trigger("schema", "foo", watch_object="configmaps/schema", watch_field="{.data.version}")
+`)
+
+	f.load()
+	assert.Equal(t, &v1alpha1.RestartOnSpec{
+		ObjectFields: []v1alpha1.ObjectFieldSource{
+			{Kind: "ConfigMap", Name: "schema", FieldPath: "{.data.version}"},
+		},
+	}, f.assertNextManifest("foo").K8sTarget().RestartOn)
+}
+
+func TestExternalTriggerWatchUnsupported(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+local_resource("api", serve_cmd="echo hi")
+external_trigger("schema", "api", watch_object="configmap/schema", watch_field="{.data.version}")
+`)
+
+	f.loadErrString(`external_trigger: resource "api": watch_object is only supported for k8s resources and local resources with a cmd`)
+}
+
+func TestExternalTriggerUnknownResource(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+local_resource("api", cmd="echo hi")
+external_trigger("schema", ["api", "db"])
+`)
+
+	f.loadErrString(`external_trigger: no resource found named "db"`)
+}
+
 // https://github.com/tilt-dev/tilt/issues/5467
 func TestLoadErrorWithArgs(t *testing.T) {
 	f := newFixture(t)
@@ -5752,6 +6654,7 @@ type fixture struct {
 	*tempdir.TempDirFixture
 	k8sContext k8s.KubeContext
 	k8sEnv     clusterid.Product
+	k8sClient  *k8s.FakeK8sClient
 	webHost    model.WebHost
 
 	ta *tiltanalytics.TiltAnalytics
@@ -5774,7 +6677,7 @@ func (f *fixture) newTiltfileLoader() TiltfileLoader {
 	extrr := tiltextension.NewFakeExtRepoReconciler(f.Path())
 	extPlugin := tiltextension.NewFakePlugin(extrr, extr)
 	return ProvideTiltfileLoader(f.ta, k8sContextPlugin, versionPlugin, configPlugin,
-		extPlugin, dcc, f.webHost, execer, f.features, f.k8sEnv)
+		extPlugin, dcc, f.k8sClient, f.webHost, execer, f.features, f.k8sEnv)
 }
 
 func newFixture(t *testing.T) *fixture {
@@ -5798,11 +6701,15 @@ func newFixture(t *testing.T) *fixture {
 		ta:             ta,
 		k8sContext:     "fake-context",
 		k8sEnv:         clusterid.ProductDockerDesktop,
+		k8sClient:      k8s.NewFakeK8sClient(t),
 		features:       features,
 	}
 
-	// Collect the warnings
+	// Collect the warnings. Commands started by local_async() log from other goroutines.
+	var mu sync.Mutex
 	l := logger.NewFuncLogger(false, logger.DebugLvl, func(level logger.Level, fields logger.Fields, msg []byte) error {
+		mu.Lock()
+		defer mu.Unlock()
 		if level == logger.WarnLvl {
 			r.warnings = append(r.warnings, string(msg))
 		}
@@ -5956,6 +6863,7 @@ func unusedImageWarning(unusedImage string, suggestedImages []string, configType
 		}
 	}
 	ret += "\nSkipping this image build"
+	ret += fmt.Sprintf("\nIf it's left over from a refactor, remove its docker_build(). Otherwise, use the image name in a %s config to deploy it.", configType)
 	ret += fmt.Sprintf("\nIf this is deliberate, suppress this warning with: update_settings(suppress_unused_image_warnings=[%q])", unusedImage)
 	return ret
 }
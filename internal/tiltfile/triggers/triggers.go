@@ -0,0 +1,192 @@
+package triggers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.starlark.net/starlark"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/tilt-dev/tilt/internal/controllers/apis/trigger"
+	"github.com/tilt-dev/tilt/internal/sliceutils"
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+	"github.com/tilt-dev/tilt/internal/tiltfile/value"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+type State struct {
+	// Maps each resource name to the external triggers that update it.
+	ByResource map[string][]model.ExternalTrigger
+}
+
+func (s State) ApplyTo(manifests []model.Manifest) ([]model.Manifest, error) {
+	found := make(map[string]bool, len(manifests))
+	for i, m := range manifests {
+		triggers, ok := s.ByResource[m.Name.String()]
+		if !ok {
+			continue
+		}
+
+		found[m.Name.String()] = true
+		m, err := withObjectFields(m.WithExternalTriggers(triggers), triggers)
+		if err != nil {
+			return nil, err
+		}
+		manifests[i] = m
+	}
+
+	var missing []string
+	for name := range s.ByResource {
+		if !found[name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("external_trigger: no resource found named %s",
+			sliceutils.QuotedStringList(missing))
+	}
+	return manifests, nil
+}
+
+// Watch triggers are expressed as RestartOn object fields
+// on the API object that updates the resource.
+func withObjectFields(m model.Manifest, triggers []model.ExternalTrigger) (model.Manifest, error) {
+	var fields []v1alpha1.ObjectFieldSource
+	for _, t := range triggers {
+		if !t.IsWatch() {
+			continue
+		}
+
+		// Validated by external_trigger().
+		parts := strings.SplitN(t.WatchObject, "/", 2)
+		_, kind, err := trigger.ParseWatchKind(parts[0])
+		if err != nil {
+			return model.Manifest{}, fmt.Errorf("external_trigger(%q): %v", t.Name, err)
+		}
+		fields = append(fields, v1alpha1.ObjectFieldSource{
+			Kind:      kind,
+			Name:      parts[1],
+			FieldPath: t.WatchField,
+		})
+	}
+	if len(fields) == 0 {
+		return m, nil
+	}
+
+	if m.IsK8s() {
+		kTarget := m.K8sTarget()
+		kTarget.KubernetesApplySpec.RestartOn = appendObjectFields(kTarget.KubernetesApplySpec.RestartOn, fields)
+		return m.WithDeployTarget(kTarget), nil
+	}
+
+	if m.IsLocal() && m.LocalTarget().UpdateCmdSpec != nil {
+		localTarget := m.LocalTarget()
+		cmdSpec := localTarget.UpdateCmdSpec.DeepCopy()
+		cmdSpec.RestartOn = appendObjectFields(cmdSpec.RestartOn, fields)
+		localTarget.UpdateCmdSpec = cmdSpec
+		return m.WithDeployTarget(localTarget), nil
+	}
+
+	return model.Manifest{}, fmt.Errorf(
+		"external_trigger: resource %q: watch_object is only supported for k8s resources and local resources with a cmd",
+		m.Name)
+}
+
+func appendObjectFields(restartOn *v1alpha1.RestartOnSpec, fields []v1alpha1.ObjectFieldSource) *v1alpha1.RestartOnSpec {
+	result := &v1alpha1.RestartOnSpec{}
+	if restartOn != nil {
+		result = restartOn.DeepCopy()
+	}
+	result.ObjectFields = append(result.ObjectFields, fields...)
+	return result
+}
+
+// Implements the external_trigger() builtin, for updating resources
+// when something outside of Tilt changes.
+type Plugin struct{}
+
+func NewPlugin() Plugin {
+	return Plugin{}
+}
+
+func (e Plugin) NewState() interface{} {
+	return State{ByResource: make(map[string][]model.ExternalTrigger)}
+}
+
+func (e Plugin) OnStart(env *starkit.Environment) error {
+	return env.AddBuiltin("external_trigger", e.externalTrigger)
+}
+
+func (e Plugin) externalTrigger(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var name, watchObject, watchField string
+	var resources value.StringOrStringList
+	if err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs,
+		"name", &name,
+		"resources", &resources,
+		"watch_object?", &watchObject,
+		"watch_field?", &watchField); err != nil {
+		return nil, err
+	}
+
+	errs := validation.IsDNS1123Subdomain(name)
+	if len(errs) != 0 {
+		return nil, fmt.Errorf("%s: invalid name %q: %s", fn.Name(), name, strings.Join(errs, ", "))
+	}
+
+	if (watchObject == "") != (watchField == "") {
+		return nil, fmt.Errorf("%s: watch_object and watch_field must be specified together", fn.Name())
+	}
+	if watchObject != "" {
+		_, _, err := trigger.ParseWatchObject(watchObject)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", fn.Name(), err)
+		}
+		_, err = trigger.ParseWatchField(watchField)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", fn.Name(), err)
+		}
+	}
+
+	t := model.ExternalTrigger{
+		Name:        name,
+		WatchObject: watchObject,
+		WatchField:  watchField,
+	}
+
+	err := starkit.SetState(thread, func(state State) (State, error) {
+		byResource := make(map[string][]model.ExternalTrigger, len(state.ByResource)+len(resources.Values))
+		for k, v := range state.ByResource {
+			byResource[k] = v
+		}
+		for _, r := range resources.Values {
+			for _, existing := range byResource[r] {
+				if existing.Name == name {
+					return state, fmt.Errorf("%s: resource %q already has a trigger named %q", fn.Name(), r, name)
+				}
+			}
+			byResource[r] = append(append([]model.ExternalTrigger{}, byResource[r]...), t)
+		}
+		state.ByResource = byResource
+		return state, nil
+	})
+	return starlark.None, err
+}
+
+var _ starkit.StatefulPlugin = Plugin{}
+
+func MustState(model starkit.Model) State {
+	state, err := GetState(model)
+	if err != nil {
+		panic(err)
+	}
+	return state
+}
+
+func GetState(m starkit.Model) (State, error) {
+	var state State
+	err := m.Load(&state)
+	return state, err
+}
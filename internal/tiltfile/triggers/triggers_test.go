@@ -0,0 +1,56 @@
+package triggers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestExternalTrigger(t *testing.T) {
+	f := NewFixture(t)
+	f.File("Tiltfile", `
+external_trigger('schema', ['api', 'worker'], watch_object='configmap/schema', watch_field='{.data.version}')
+external_trigger('deploy-hook', 'api')
+`)
+
+	result, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+
+	schema := model.ExternalTrigger{Name: "schema", WatchObject: "configmap/schema", WatchField: "{.data.version}"}
+	hook := model.ExternalTrigger{Name: "deploy-hook"}
+	assert.Equal(t, map[string][]model.ExternalTrigger{
+		"api":    {schema, hook},
+		"worker": {schema},
+	}, MustState(result).ByResource)
+}
+
+func TestExternalTriggerInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		args     string
+		expected string
+	}{
+		{"bad name", "'Not_Valid', 'api'", `invalid name "Not_Valid"`},
+		{"object without field", "'hook', 'api', watch_object='configmap/schema'", "must be specified together"},
+		{"unknown kind", "'hook', 'api', watch_object='widget/schema', watch_field='.data'", `unknown kind "widget"`},
+		{"bad object", "'hook', 'api', watch_object='schema', watch_field='.data'", "expected kind/name"},
+		{"bad field", "'hook', 'api', watch_object='configmap/schema', watch_field='{.data['", "invalid field"},
+		{"duplicate", "'hook', 'api')\nexternal_trigger('hook', 'api'", `already has a trigger named "hook"`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f := NewFixture(t)
+			f.File("Tiltfile", "external_trigger("+tc.args+")")
+			_, err := f.ExecFile("Tiltfile")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
+
+func NewFixture(tb testing.TB) *starkit.Fixture {
+	return starkit.NewFixture(tb, NewPlugin())
+}
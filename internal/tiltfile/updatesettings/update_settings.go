@@ -29,11 +29,12 @@ func (e Plugin) OnStart(env *starkit.Environment) error {
 }
 
 func (e *Plugin) updateSettings(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
-	var maxParallelUpdates, k8sUpsertTimeoutSecs starlark.Value
+	var maxParallelUpdates, k8sUpsertTimeoutSecs, k8sJobRetentionSecs starlark.Value
 	var unusedImageWarnings value.StringOrStringList
 	if err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs,
 		"max_parallel_updates?", &maxParallelUpdates,
 		"k8s_upsert_timeout_secs?", &k8sUpsertTimeoutSecs,
+		"k8s_job_retention_secs?", &k8sJobRetentionSecs,
 		"suppress_unused_image_warnings?", &unusedImageWarnings); err != nil {
 		return nil, err
 	}
@@ -56,6 +57,14 @@ func (e *Plugin) updateSettings(thread *starlark.Thread, fn *starlark.Builtin, a
 			k8sUpsertTimeoutSecs)
 	}
 
+	kjrs, kjrsPassed, err := valueToInt(k8sJobRetentionSecs)
+	if err != nil {
+		return nil, errors.Wrap(err, "update_settings: for parameter \"k8s_job_retention_secs\"")
+	}
+	if kjrsPassed && kjrs < 0 {
+		return nil, fmt.Errorf("k8s job retention must be >= 0 (got: %ds)", kjrs)
+	}
+
 	err = starkit.SetState(thread, func(settings model.UpdateSettings) model.UpdateSettings {
 		if mpuPassed {
 			settings = settings.WithMaxParallelUpdates(mpu)
@@ -63,6 +72,9 @@ func (e *Plugin) updateSettings(thread *starlark.Thread, fn *starlark.Builtin, a
 		if kutsPassed {
 			settings = settings.WithK8sUpsertTimeout(time.Duration(kuts) * time.Second)
 		}
+		if kjrsPassed {
+			settings = settings.WithK8sJobRetention(time.Duration(kjrs) * time.Second)
+		}
 		settings.SuppressUnusedImageWarnings = append(settings.SuppressUnusedImageWarnings, unusedImageWarnings.Values...)
 		return settings
 	})
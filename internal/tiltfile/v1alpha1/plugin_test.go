@@ -308,6 +308,79 @@ data:
 	})
 }
 
+func TestNestedTilt(t *testing.T) {
+	f := newFixture(t)
+
+	f.File("Tiltfile", `
+v1alpha1.nested_tilt(name='payments', path='./payments', port=10360, args=['api'])
+`)
+	result, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+
+	set := MustState(result)
+
+	obj := set.GetSetForType(&v1alpha1.NestedTilt{})["payments"].(*v1alpha1.NestedTilt)
+	require.NotNil(t, obj)
+	require.Equal(t, v1alpha1.NestedTiltSpec{
+		Path: f.JoinPath("payments"),
+		Port: 10360,
+		Args: []string{"api"},
+	}, obj.Spec)
+}
+
+func TestNestedTiltValidation(t *testing.T) {
+	f := newFixture(t)
+
+	f.File("Tiltfile", `
+v1alpha1.nested_tilt(name='payments', path='./payments')
+`)
+	_, err := f.ExecFile("Tiltfile")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "must be between 1 and 65535")
+}
+
+func TestLocalIngress(t *testing.T) {
+	f := newFixture(t)
+
+	f.File("Tiltfile", `
+v1alpha1.local_ingress(
+  name='shop',
+  host='shop.localhost',
+  routes=[
+    v1alpha1.local_ingress_route(resource='frontend', container_port=3000),
+    v1alpha1.local_ingress_route(path='/api', local_port=8080),
+  ])
+`)
+	result, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+
+	set := MustState(result)
+
+	obj := set.GetSetForType(&v1alpha1.LocalIngress{})["shop"].(*v1alpha1.LocalIngress)
+	require.NotNil(t, obj)
+	require.Equal(t, v1alpha1.LocalIngressSpec{
+		Host: "shop.localhost",
+		Routes: []v1alpha1.LocalIngressRoute{
+			{Resource: "frontend", ContainerPort: 3000},
+			{Path: "/api", LocalPort: 8080},
+		},
+	}, obj.Spec)
+}
+
+func TestLocalIngressValidation(t *testing.T) {
+	f := newFixture(t)
+
+	f.File("Tiltfile", `
+v1alpha1.local_ingress(
+  name='shop',
+  host='shop.localhost',
+  routes=[v1alpha1.local_ingress_route(resource='frontend', local_port=8080)])
+`)
+	_, err := f.ExecFile("Tiltfile")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Exactly one of Resource or LocalPort is required")
+}
+
 func newFixture(tb testing.TB) *starkit.Fixture {
 	return starkit.NewFixture(tb, NewPlugin())
 }
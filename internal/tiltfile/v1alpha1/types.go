@@ -46,6 +46,14 @@ func (p Plugin) registerSymbols(env *starkit.Environment) error {
 	if err != nil {
 		return err
 	}
+	err = env.AddBuiltin("v1alpha1.local_ingress", p.localIngress)
+	if err != nil {
+		return err
+	}
+	err = env.AddBuiltin("v1alpha1.nested_tilt", p.nestedTilt)
+	if err != nil {
+		return err
+	}
 	err = env.AddBuiltin("v1alpha1.ui_button", p.uiButton)
 	if err != nil {
 		return err
@@ -86,6 +94,10 @@ func (p Plugin) registerSymbols(env *starkit.Environment) error {
 	if err != nil {
 		return err
 	}
+	err = env.AddBuiltin("v1alpha1.kubernetes_apply_wait_for", p.kubernetesApplyWaitFor)
+	if err != nil {
+		return err
+	}
 	err = env.AddBuiltin("v1alpha1.kubernetes_discovery_template_spec", p.kubernetesDiscoveryTemplateSpec)
 	if err != nil {
 		return err
@@ -110,6 +122,10 @@ func (p Plugin) registerSymbols(env *starkit.Environment) error {
 	if err != nil {
 		return err
 	}
+	err = env.AddBuiltin("v1alpha1.local_ingress_route", p.localIngressRoute)
+	if err != nil {
+		return err
+	}
 	err = env.AddBuiltin("v1alpha1.object_selector", p.objectSelector)
 	if err != nil {
 		return err
@@ -345,6 +361,8 @@ func (p Plugin) kubernetesApply(t *starlark.Thread, fn *starlark.Builtin, args s
 	var applyCmd KubernetesApplyCmd = KubernetesApplyCmd{t: t}
 	var restartOn RestartOnSpec = RestartOnSpec{t: t}
 	var deleteCmd KubernetesApplyCmd = KubernetesApplyCmd{t: t}
+	var waitFor KubernetesApplyWaitForList = KubernetesApplyWaitForList{t: t}
+	var applyOrder ObjectSelectorList = ObjectSelectorList{t: t}
 	var labels value.StringStringMap
 	var annotations value.StringStringMap
 	err = starkit.UnpackArgs(t, fn.Name(), args, kwargs,
@@ -364,6 +382,8 @@ func (p Plugin) kubernetesApply(t *starlark.Thread, fn *starlark.Builtin, args s
 		"restart_on?", &restartOn,
 		"delete_cmd?", &deleteCmd,
 		"cluster?", &obj.Spec.Cluster,
+		"wait_for?", &waitFor,
+		"apply_order?", &applyOrder,
 	)
 	if err != nil {
 		return nil, err
@@ -394,6 +414,8 @@ func (p Plugin) kubernetesApply(t *starlark.Thread, fn *starlark.Builtin, args s
 	if deleteCmd.isUnpacked {
 		obj.Spec.DeleteCmd = (*v1alpha1.KubernetesApplyCmd)(&deleteCmd.Value)
 	}
+	obj.Spec.WaitFor = waitFor.Value
+	obj.Spec.ApplyOrder = applyOrder.Value
 	obj.ObjectMeta.Labels = labels
 	obj.ObjectMeta.Annotations = annotations
 	return p.register(t, obj)
@@ -409,6 +431,7 @@ func (p Plugin) kubernetesDiscovery(t *starlark.Thread, fn *starlark.Builtin, ar
 	var extraSelectors LabelSelectorList = LabelSelectorList{t: t}
 	var portForwardTemplateSpec PortForwardTemplateSpec = PortForwardTemplateSpec{t: t}
 	var podLogStreamTemplateSpec PodLogStreamTemplateSpec = PodLogStreamTemplateSpec{t: t}
+	var extraNamespaces value.StringList
 	var labels value.StringStringMap
 	var annotations value.StringStringMap
 	err = starkit.UnpackArgs(t, fn.Name(), args, kwargs,
@@ -420,6 +443,8 @@ func (p Plugin) kubernetesDiscovery(t *starlark.Thread, fn *starlark.Builtin, ar
 		"port_forward_template_spec?", &portForwardTemplateSpec,
 		"pod_log_stream_template_spec?", &podLogStreamTemplateSpec,
 		"cluster?", &obj.Spec.Cluster,
+		"extra_namespaces?", &extraNamespaces,
+		"all_namespaces?", &obj.Spec.AllNamespaces,
 	)
 	if err != nil {
 		return nil, err
@@ -427,6 +452,7 @@ func (p Plugin) kubernetesDiscovery(t *starlark.Thread, fn *starlark.Builtin, ar
 
 	obj.Spec.Watches = watches.Value
 	obj.Spec.ExtraSelectors = extraSelectors.Value
+	obj.Spec.ExtraNamespaces = extraNamespaces
 	if portForwardTemplateSpec.isUnpacked {
 		obj.Spec.PortForwardTemplateSpec = (*v1alpha1.PortForwardTemplateSpec)(&portForwardTemplateSpec.Value)
 	}
@@ -438,6 +464,71 @@ func (p Plugin) kubernetesDiscovery(t *starlark.Thread, fn *starlark.Builtin, ar
 	return p.register(t, obj)
 }
 
+func (p Plugin) localIngress(t *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var err error
+	obj := &v1alpha1.LocalIngress{
+		ObjectMeta: metav1.ObjectMeta{},
+		Spec:       v1alpha1.LocalIngressSpec{},
+	}
+	var port int
+	var routes LocalIngressRouteList = LocalIngressRouteList{t: t}
+	var labels value.StringStringMap
+	var annotations value.StringStringMap
+	err = starkit.UnpackArgs(t, fn.Name(), args, kwargs,
+		"name", &obj.ObjectMeta.Name,
+		"labels?", &labels,
+		"annotations?", &annotations,
+		"host?", &obj.Spec.Host,
+		"port?", &port,
+		"routes?", &routes,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	obj.Spec.Port = int32(port)
+	obj.Spec.Routes = routes.Value
+	obj.ObjectMeta.Labels = labels
+	obj.ObjectMeta.Annotations = annotations
+	return p.register(t, obj)
+}
+
+func (p Plugin) nestedTilt(t *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var err error
+	obj := &v1alpha1.NestedTilt{
+		ObjectMeta: metav1.ObjectMeta{},
+		Spec:       v1alpha1.NestedTiltSpec{},
+	}
+	var path value.LocalPath = value.NewLocalPathUnpacker(t)
+	err = path.Unpack(starlark.String(""))
+	if err != nil {
+		return nil, err
+	}
+
+	var port int
+	var specArgs value.StringList
+	var labels value.StringStringMap
+	var annotations value.StringStringMap
+	err = starkit.UnpackArgs(t, fn.Name(), args, kwargs,
+		"name", &obj.ObjectMeta.Name,
+		"labels?", &labels,
+		"annotations?", &annotations,
+		"path?", &path,
+		"port?", &port,
+		"args?", &specArgs,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	obj.Spec.Path = path.Value
+	obj.Spec.Port = int32(port)
+	obj.Spec.Args = specArgs
+	obj.ObjectMeta.Labels = labels
+	obj.ObjectMeta.Annotations = annotations
+	return p.register(t, obj)
+}
+
 func (p Plugin) uiButton(t *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 	var err error
 	obj := &v1alpha1.UIButton{
@@ -1675,6 +1766,176 @@ func (o *KubernetesApplyCmdList) Unpack(v starlark.Value) error {
 	return nil
 }
 
+type KubernetesApplyWaitFor struct {
+	*starlark.Dict
+	Value      v1alpha1.KubernetesApplyWaitFor
+	isUnpacked bool
+	t          *starlark.Thread // instantiation thread for computing abspath
+}
+
+func (p Plugin) kubernetesApplyWaitFor(t *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var objectSelector starlark.Value
+	var condition starlark.Value
+	var jsonPath starlark.Value
+	var value starlark.Value
+	var timeout starlark.Value
+	err := starkit.UnpackArgs(t, fn.Name(), args, kwargs,
+		"object_selector?", &objectSelector,
+		"condition?", &condition,
+		"json_path?", &jsonPath,
+		"value?", &value,
+		"timeout?", &timeout,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	dict := starlark.NewDict(5)
+
+	if objectSelector != nil {
+		err := dict.SetKey(starlark.String("object_selector"), objectSelector)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if condition != nil {
+		err := dict.SetKey(starlark.String("condition"), condition)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if jsonPath != nil {
+		err := dict.SetKey(starlark.String("json_path"), jsonPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if value != nil {
+		err := dict.SetKey(starlark.String("value"), value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if timeout != nil {
+		err := dict.SetKey(starlark.String("timeout"), timeout)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var obj *KubernetesApplyWaitFor = &KubernetesApplyWaitFor{t: t}
+	err = obj.Unpack(dict)
+	if err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+func (o *KubernetesApplyWaitFor) Unpack(v starlark.Value) error {
+	obj := v1alpha1.KubernetesApplyWaitFor{}
+
+	starlarkObj, ok := v.(*KubernetesApplyWaitFor)
+	if ok {
+		*o = *starlarkObj
+		return nil
+	}
+
+	mapObj, ok := v.(*starlark.Dict)
+	if !ok {
+		return fmt.Errorf("expected dict, actual: %v", v.Type())
+	}
+
+	for _, item := range mapObj.Items() {
+		keyV, val := item[0], item[1]
+		key, ok := starlark.AsString(keyV)
+		if !ok {
+			return fmt.Errorf("key must be string. Got: %s", keyV.Type())
+		}
+
+		if key == "object_selector" {
+			v := ObjectSelector{t: o.t}
+			err := v.Unpack(val)
+			if err != nil {
+				return fmt.Errorf("unpacking %s: %v", key, err)
+			}
+			obj.ObjectSelector = v.Value
+			continue
+		}
+		if key == "condition" {
+			v, ok := starlark.AsString(val)
+			if !ok {
+				return fmt.Errorf("Expected string, actual: %s", val.Type())
+			}
+			obj.Condition = string(v)
+			continue
+		}
+		if key == "json_path" {
+			v, ok := starlark.AsString(val)
+			if !ok {
+				return fmt.Errorf("Expected string, actual: %s", val.Type())
+			}
+			obj.JSONPath = string(v)
+			continue
+		}
+		if key == "value" {
+			v, ok := starlark.AsString(val)
+			if !ok {
+				return fmt.Errorf("Expected string, actual: %s", val.Type())
+			}
+			obj.Value = string(v)
+			continue
+		}
+		if key == "timeout" {
+			var v value.Duration
+			err := v.Unpack(val)
+			if err != nil {
+				return fmt.Errorf("unpacking %s: %v", key, err)
+			}
+			obj.Timeout = metav1.Duration{Duration: time.Duration(v)}
+			continue
+		}
+		return fmt.Errorf("Unexpected attribute name: %s", key)
+	}
+
+	mapObj.Freeze()
+	o.Dict = mapObj
+	o.Value = obj
+	o.isUnpacked = true
+
+	return nil
+}
+
+type KubernetesApplyWaitForList struct {
+	*starlark.List
+	Value []v1alpha1.KubernetesApplyWaitFor
+	t     *starlark.Thread
+}
+
+func (o *KubernetesApplyWaitForList) Unpack(v starlark.Value) error {
+	items := []v1alpha1.KubernetesApplyWaitFor{}
+
+	listObj, ok := v.(*starlark.List)
+	if !ok {
+		return fmt.Errorf("expected list, actual: %v", v.Type())
+	}
+
+	for i := 0; i < listObj.Len(); i++ {
+		v := listObj.Index(i)
+
+		item := KubernetesApplyWaitFor{t: o.t}
+		err := item.Unpack(v)
+		if err != nil {
+			return fmt.Errorf("at index %d: %v", i, err)
+		}
+		items = append(items, v1alpha1.KubernetesApplyWaitFor(item.Value))
+	}
+
+	listObj.Freeze()
+	o.List = listObj
+	o.Value = items
+
+	return nil
+}
+
 type KubernetesDiscoveryTemplateSpec struct {
 	*starlark.Dict
 	Value      v1alpha1.KubernetesDiscoveryTemplateSpec
@@ -2433,6 +2694,158 @@ func (o *LabelSelectorRequirementList) Unpack(v starlark.Value) error {
 	return nil
 }
 
+type LocalIngressRoute struct {
+	*starlark.Dict
+	Value      v1alpha1.LocalIngressRoute
+	isUnpacked bool
+	t          *starlark.Thread // instantiation thread for computing abspath
+}
+
+func (p Plugin) localIngressRoute(t *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var path starlark.Value
+	var resource starlark.Value
+	var containerPort starlark.Value
+	var localPort starlark.Value
+	err := starkit.UnpackArgs(t, fn.Name(), args, kwargs,
+		"path?", &path,
+		"resource?", &resource,
+		"container_port?", &containerPort,
+		"local_port?", &localPort,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	dict := starlark.NewDict(4)
+
+	if path != nil {
+		err := dict.SetKey(starlark.String("path"), path)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if resource != nil {
+		err := dict.SetKey(starlark.String("resource"), resource)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if containerPort != nil {
+		err := dict.SetKey(starlark.String("container_port"), containerPort)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if localPort != nil {
+		err := dict.SetKey(starlark.String("local_port"), localPort)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var obj *LocalIngressRoute = &LocalIngressRoute{t: t}
+	err = obj.Unpack(dict)
+	if err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+func (o *LocalIngressRoute) Unpack(v starlark.Value) error {
+	obj := v1alpha1.LocalIngressRoute{}
+
+	starlarkObj, ok := v.(*LocalIngressRoute)
+	if ok {
+		*o = *starlarkObj
+		return nil
+	}
+
+	mapObj, ok := v.(*starlark.Dict)
+	if !ok {
+		return fmt.Errorf("expected dict, actual: %v", v.Type())
+	}
+
+	for _, item := range mapObj.Items() {
+		keyV, val := item[0], item[1]
+		key, ok := starlark.AsString(keyV)
+		if !ok {
+			return fmt.Errorf("key must be string. Got: %s", keyV.Type())
+		}
+
+		if key == "path" {
+			v, ok := starlark.AsString(val)
+			if !ok {
+				return fmt.Errorf("Expected string, actual: %s", val.Type())
+			}
+			obj.Path = string(v)
+			continue
+		}
+		if key == "resource" {
+			v, ok := starlark.AsString(val)
+			if !ok {
+				return fmt.Errorf("Expected string, actual: %s", val.Type())
+			}
+			obj.Resource = string(v)
+			continue
+		}
+		if key == "container_port" {
+			v, err := starlark.AsInt32(val)
+			if err != nil {
+				return fmt.Errorf("Expected int, got: %v", err)
+			}
+			obj.ContainerPort = int32(v)
+			continue
+		}
+		if key == "local_port" {
+			v, err := starlark.AsInt32(val)
+			if err != nil {
+				return fmt.Errorf("Expected int, got: %v", err)
+			}
+			obj.LocalPort = int32(v)
+			continue
+		}
+		return fmt.Errorf("Unexpected attribute name: %s", key)
+	}
+
+	mapObj.Freeze()
+	o.Dict = mapObj
+	o.Value = obj
+	o.isUnpacked = true
+
+	return nil
+}
+
+type LocalIngressRouteList struct {
+	*starlark.List
+	Value []v1alpha1.LocalIngressRoute
+	t     *starlark.Thread
+}
+
+func (o *LocalIngressRouteList) Unpack(v starlark.Value) error {
+	items := []v1alpha1.LocalIngressRoute{}
+
+	listObj, ok := v.(*starlark.List)
+	if !ok {
+		return fmt.Errorf("expected list, actual: %v", v.Type())
+	}
+
+	for i := 0; i < listObj.Len(); i++ {
+		v := listObj.Index(i)
+
+		item := LocalIngressRoute{t: o.t}
+		err := item.Unpack(v)
+		if err != nil {
+			return fmt.Errorf("at index %d: %v", i, err)
+		}
+		items = append(items, v1alpha1.LocalIngressRoute(item.Value))
+	}
+
+	listObj.Freeze()
+	o.List = listObj
+	o.Value = items
+
+	return nil
+}
+
 type ObjectSelector struct {
 	*starlark.Dict
 	Value      v1alpha1.ObjectSelector
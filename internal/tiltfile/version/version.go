@@ -38,6 +38,7 @@ func (e Plugin) setVersionSettings(thread *starlark.Thread, fn *starlark.Builtin
 		if err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs,
 			"check_updates?", &settings.CheckUpdates,
 			"constraint?", &constraint,
+			"check_latest_release?", &settings.CheckLatestRelease,
 		); err != nil {
 			return settings, err
 		}
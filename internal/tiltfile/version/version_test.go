@@ -39,6 +39,17 @@ version_settings(check_updates=True)
 	require.True(t, MustState(result).CheckUpdates)
 }
 
+func TestCheckLatestRelease(t *testing.T) {
+	f := NewFixture(t)
+	f.File("Tiltfile", `
+version_settings(check_latest_release=True)
+`)
+	result, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	require.True(t, MustState(result).CheckLatestRelease)
+	require.True(t, MustState(result).CheckUpdates)
+}
+
 func TestVersionConstraints(t *testing.T) {
 	for _, tc := range []struct {
 		constraint string
@@ -18,6 +18,7 @@ package v1alpha1
 
 import (
 	"context"
+	"net/url"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -75,6 +76,17 @@ type ClusterSpec struct {
 	//
 	// +optional
 	DefaultRegistry *RegistryHosting `json:"defaultRegistry,omitempty" protobuf:"bytes,2,opt,name=defaultRegistry"`
+
+	// BuildKit determines where images for this Cluster should be built.
+	//
+	// If specified, images are built on a remote BuildKit daemon instead of
+	// the local Docker daemon, and BuildKit pushes them to the registry
+	// as part of the build.
+	//
+	// Only supported for Kubernetes clusters.
+	//
+	// +optional
+	BuildKit *BuildKitConnection `json:"buildKit,omitempty" protobuf:"bytes,3,opt,name=buildKit"`
 }
 
 // Connection spec for an existing cluster.
@@ -110,6 +122,47 @@ type DockerClusterConnection struct {
 	Host string `json:"host,omitempty" protobuf:"bytes,1,opt,name=host"`
 }
 
+// Connection spec for a remote BuildKit daemon.
+type BuildKitConnection struct {
+	// The address of the BuildKit daemon.
+	//
+	// tcp://host:port and unix:///path/to/buildkitd.sock connect directly.
+	//
+	// kube-pod://pod-name connects to a buildkitd pod through `kubectl exec`,
+	// like the kubernetes driver of `docker buildx`. The namespace, container,
+	// and kubeconfig context can be set as query parameters
+	// (e.g., kube-pod://buildkitd-0?namespace=buildkit). The context defaults
+	// to the Cluster's context.
+	Address string `json:"address" protobuf:"bytes,1,opt,name=address"`
+
+	// The name to verify the daemon's TLS certificate against.
+	//
+	// If not specified, will use the host in the address.
+	//
+	// +optional
+	ServerName string `json:"serverName,omitempty" protobuf:"bytes,2,opt,name=serverName"`
+
+	// Path to the CA certificate that signed the daemon's certificate.
+	//
+	// If specified, connects over TLS.
+	//
+	// +optional
+	CACert string `json:"caCert,omitempty" protobuf:"bytes,3,opt,name=caCert"`
+
+	// Path to the client certificate, for daemons that require mutual TLS.
+	//
+	// +optional
+	Cert string `json:"cert,omitempty" protobuf:"bytes,4,opt,name=cert"`
+
+	// Path to the client key, for daemons that require mutual TLS.
+	//
+	// +optional
+	Key string `json:"key,omitempty" protobuf:"bytes,5,opt,name=key"`
+}
+
+// The address schemes we know how to connect to BuildKit over.
+var buildKitSchemes = []string{"tcp", "unix", "kube-pod"}
+
 var _ resource.Object = &Cluster{}
 var _ resourcestrategy.Validater = &Cluster{}
 
@@ -151,9 +204,49 @@ func (in *Cluster) Validate(ctx context.Context) field.ErrorList {
 		errors = append(errors,
 			in.Spec.DefaultRegistry.validateAsSubfield(ctx, field.NewPath(".spec.defaultRegistry"))...)
 	}
+	if in.Spec.BuildKit != nil {
+		path := field.NewPath(".spec.buildKit")
+		if in.Spec.Connection != nil && in.Spec.Connection.Docker != nil {
+			errors = append(errors, field.Forbidden(path, "remote BuildKit is only supported for Kubernetes clusters"))
+		}
+		errors = append(errors, in.Spec.BuildKit.validateAsSubfield(ctx, path)...)
+	}
+	return errors
+}
+
+func (in *BuildKitConnection) Validate(ctx context.Context) field.ErrorList {
+	return in.validateAsSubfield(ctx, nil)
+}
+
+func (in *BuildKitConnection) validateAsSubfield(_ context.Context, path *field.Path) field.ErrorList {
+	var errors field.ErrorList
+	u, err := url.Parse(in.Address)
+	if in.Address == "" {
+		errors = append(errors, field.Required(path.Child("address"), "address is required"))
+	} else if err != nil {
+		errors = append(errors, field.Invalid(path.Child("address"), in.Address, err.Error()))
+	} else if !isBuildKitScheme(u.Scheme) {
+		errors = append(errors, field.NotSupported(path.Child("address"), u.Scheme, buildKitSchemes))
+	}
+
+	if (in.Cert == "") != (in.Key == "") {
+		errors = append(errors, field.Invalid(path.Child("cert"), in.Cert, "cert and key must be specified together"))
+	}
+	if in.Cert != "" && in.CACert == "" {
+		errors = append(errors, field.Required(path.Child("caCert"), "mutual TLS requires a CA certificate"))
+	}
 	return errors
 }
 
+func isBuildKitScheme(scheme string) bool {
+	for _, s := range buildKitSchemes {
+		if s == scheme {
+			return true
+		}
+	}
+	return false
+}
+
 var _ resource.ObjectList = &ClusterList{}
 
 func (in *ClusterList) GetListMeta() *metav1.ListMeta {
@@ -203,6 +296,91 @@ type ClusterStatus struct {
 	//
 	// +optional
 	Version string `json:"version,omitempty" protobuf:"bytes,6,opt,name=version"`
+
+	// The most recent runs of the Docker pruner against this cluster's
+	// container runtime.
+	//
+	// +optional
+	DockerPrune *DockerPruneStatus `json:"dockerPrune,omitempty" protobuf:"bytes,7,opt,name=dockerPrune"`
+
+	// Conditions describe the health of the connection, based on
+	// Tilt's periodic checks against the cluster.
+	//
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty" protobuf:"bytes,8,rep,name=conditions"`
+}
+
+const (
+	// ClusterConditionReachable means that the most recent health check
+	// got a response from the cluster.
+	//
+	// When it's False (e.g., because a VPN dropped), objects that
+	// depend on the cluster report that it's unreachable, rather than
+	// timing out on their own.
+	ClusterConditionReachable string = "Reachable"
+
+	// ClusterConditionResponsive means that the cluster answered the most
+	// recent health check quickly.
+	//
+	// When it's False, the reason is SlowResponse, and the message says
+	// how long the cluster took to answer.
+	ClusterConditionResponsive string = "Responsive"
+)
+
+const (
+	ClusterReasonReachable    = "Reachable"
+	ClusterReasonUnreachable  = "Unreachable"
+	ClusterReasonResponsive   = "Responsive"
+	ClusterReasonSlowResponse = "SlowResponse"
+)
+
+// DockerPruneStatus records what Tilt's Docker pruner has cleaned up.
+type DockerPruneStatus struct {
+	// Recent prune runs, oldest first.
+	//
+	// Tilt only keeps the last few runs.
+	//
+	// +optional
+	Runs []DockerPruneRun `json:"runs,omitempty" protobuf:"bytes,1,rep,name=runs"`
+}
+
+// DockerPruneRun is the result of one run of the Docker pruner.
+type DockerPruneRun struct {
+	// When the pruner started.
+	StartTime metav1.MicroTime `json:"startTime,omitempty" protobuf:"bytes,1,opt,name=startTime"`
+
+	// When the pruner finished.
+	FinishTime metav1.MicroTime `json:"finishTime,omitempty" protobuf:"bytes,2,opt,name=finishTime"`
+
+	// The number of stopped containers removed.
+	//
+	// +optional
+	ContainersDeleted int32 `json:"containersDeleted,omitempty" protobuf:"varint,3,opt,name=containersDeleted"`
+
+	// The number of images removed.
+	//
+	// +optional
+	ImagesDeleted int32 `json:"imagesDeleted,omitempty" protobuf:"varint,4,opt,name=imagesDeleted"`
+
+	// The number of build cache entries removed.
+	//
+	// +optional
+	CachesDeleted int32 `json:"cachesDeleted,omitempty" protobuf:"varint,5,opt,name=cachesDeleted"`
+
+	// The number of volumes removed.
+	//
+	// +optional
+	VolumesDeleted int32 `json:"volumesDeleted,omitempty" protobuf:"varint,6,opt,name=volumesDeleted"`
+
+	// The disk space reclaimed, in bytes.
+	//
+	// +optional
+	SpaceReclaimed int64 `json:"spaceReclaimed,omitempty" protobuf:"varint,7,opt,name=spaceReclaimed"`
+
+	// An error that stopped the pruner before it finished.
+	//
+	// +optional
+	Error string `json:"error,omitempty" protobuf:"bytes,8,opt,name=error"`
 }
 
 // Cluster implements ObjectWithStatusSubResource interface.
@@ -0,0 +1,61 @@
+package v1alpha1_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+func TestCluster_Validate_BuildKit(t *testing.T) {
+	var cases = []struct {
+		name          string
+		conn          v1alpha1.BuildKitConnection
+		expectedError string
+	}{
+		{"tcp", v1alpha1.BuildKitConnection{Address: "tcp://buildkitd:1234"}, ""},
+		{"kube-pod", v1alpha1.BuildKitConnection{Address: "kube-pod://buildkitd-0?namespace=buildkit"}, ""},
+		{"mtls", v1alpha1.BuildKitConnection{Address: "tcp://buildkitd:1234", CACert: "ca.pem", Cert: "cert.pem", Key: "key.pem"}, ""},
+		{"no address", v1alpha1.BuildKitConnection{}, ".spec.buildKit.address: Required value: address is required"},
+		{"bad scheme", v1alpha1.BuildKitConnection{Address: "ssh://buildkitd"},
+			`.spec.buildKit.address: Unsupported value: "ssh": supported values: "tcp", "unix", "kube-pod"`},
+		{"cert without key", v1alpha1.BuildKitConnection{Address: "tcp://buildkitd:1234", CACert: "ca.pem", Cert: "cert.pem"},
+			`.spec.buildKit.cert: Invalid value: "cert.pem": cert and key must be specified together`},
+		{"cert without ca", v1alpha1.BuildKitConnection{Address: "tcp://buildkitd:1234", Cert: "cert.pem", Key: "key.pem"},
+			".spec.buildKit.caCert: Required value: mutual TLS requires a CA certificate"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			conn := tc.conn
+			cluster := &v1alpha1.Cluster{
+				Spec: v1alpha1.ClusterSpec{
+					Connection: &v1alpha1.ClusterConnection{Kubernetes: &v1alpha1.KubernetesClusterConnection{}},
+					BuildKit:   &conn,
+				},
+			}
+			errs := cluster.Validate(context.Background())
+			if tc.expectedError == "" {
+				assert.Empty(t, errs)
+			} else if assert.Len(t, errs, 1) {
+				require.EqualError(t, errs[0], tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestCluster_Validate_BuildKitOnDocker(t *testing.T) {
+	cluster := &v1alpha1.Cluster{
+		Spec: v1alpha1.ClusterSpec{
+			Connection: &v1alpha1.ClusterConnection{Docker: &v1alpha1.DockerClusterConnection{}},
+			BuildKit:   &v1alpha1.BuildKitConnection{Address: "tcp://buildkitd:1234"},
+		},
+	}
+	errs := cluster.Validate(context.Background())
+	if assert.Len(t, errs, 1) {
+		require.EqualError(t, errs[0], ".spec.buildKit: Forbidden: remote BuildKit is only supported for Kubernetes clusters")
+	}
+}
@@ -70,6 +70,89 @@ type DockerComposeServiceSpec struct {
 	//
 	// +optional
 	DisableSource *DisableSource `json:"disableSource,omitempty" protobuf:"bytes,4,opt,name=disableSource"`
+
+	// Other services in the project that must reach a condition
+	// before this service starts.
+	//
+	// Mirrors `depends_on` in the Docker Compose file.
+	//
+	// +optional
+	DependsOn []DockerComposeServiceDependency `json:"dependsOn,omitempty" protobuf:"bytes,5,rep,name=dependsOn"`
+
+	// Labels on the service in the Docker Compose file.
+	//
+	// Tilt reads lifecycle hooks from these, like `tilt.restart-cmd`,
+	// the command that live update runs instead of restarting the container.
+	//
+	// +optional
+	Labels map[string]string `json:"labels,omitempty" protobuf:"bytes,6,rep,name=labels"`
+
+	// Secrets that the service uses, from the top-level `secrets:`
+	// block of the Docker Compose file.
+	//
+	// Docker Compose doesn't recreate a container when a secret's contents
+	// change, so Tilt tracks a hash of them, and recreates the service when
+	// the hash changes.
+	//
+	// +optional
+	Secrets []DockerComposeFileObject `json:"secrets,omitempty" protobuf:"bytes,7,rep,name=secrets"`
+
+	// Configs that the service uses, from the top-level `configs:`
+	// block of the Docker Compose file.
+	//
+	// Tracked the same way as Secrets.
+	//
+	// +optional
+	Configs []DockerComposeFileObject `json:"configs,omitempty" protobuf:"bytes,8,rep,name=configs"`
+}
+
+// A secret or config in a Docker Compose project.
+type DockerComposeFileObject struct {
+	// The name of the secret or config in the Docker Compose file.
+	Name string `json:"name" protobuf:"bytes,1,opt,name=name"`
+
+	// The file that holds the contents.
+	//
+	// +optional
+	File string `json:"file,omitempty" protobuf:"bytes,2,opt,name=file"`
+
+	// The environment variable that holds the contents.
+	//
+	// +optional
+	Environment string `json:"environment,omitempty" protobuf:"bytes,3,opt,name=environment"`
+
+	// A hash of the contents, so that the spec changes when they do.
+	//
+	// +optional
+	ContentHash string `json:"contentHash,omitempty" protobuf:"bytes,4,opt,name=contentHash"`
+}
+
+// Conditions that a Docker Compose dependency can wait for.
+//
+// Matches the conditions of `depends_on` in the Docker Compose file.
+const (
+	// The dependency's container has started.
+	DockerComposeConditionServiceStarted = "service_started"
+
+	// The dependency's container passes its healthcheck.
+	DockerComposeConditionServiceHealthy = "service_healthy"
+
+	// The dependency's container has exited with code 0.
+	DockerComposeConditionServiceCompletedSuccessfully = "service_completed_successfully"
+)
+
+// A dependency on another service in the same Docker Compose project.
+type DockerComposeServiceDependency struct {
+	// The name of the service.
+	Service string `json:"service" protobuf:"bytes,1,opt,name=service"`
+
+	// The condition the service must reach. One of service_started,
+	// service_healthy, or service_completed_successfully.
+	//
+	// Defaults to service_started.
+	//
+	// +optional
+	Condition string `json:"condition,omitempty" protobuf:"bytes,2,opt,name=condition"`
 }
 
 var _ resource.Object = &DockerComposeService{}
@@ -113,8 +196,26 @@ func (in *DockerComposeService) IsStorageVersion() bool {
 }
 
 func (in *DockerComposeService) Validate(ctx context.Context) field.ErrorList {
-	// TODO(user): Modify it, adding your API validation here.
-	return nil
+	var fieldErrors field.ErrorList
+	for i, dep := range in.Spec.DependsOn {
+		p := field.NewPath("spec.dependsOn").Index(i)
+		if dep.Service == "" {
+			fieldErrors = append(fieldErrors, field.Required(p.Child("service"), "A service is required"))
+		}
+		switch dep.Condition {
+		case "",
+			DockerComposeConditionServiceStarted,
+			DockerComposeConditionServiceHealthy,
+			DockerComposeConditionServiceCompletedSuccessfully:
+		default:
+			fieldErrors = append(fieldErrors, field.NotSupported(p.Child("condition"), dep.Condition, []string{
+				DockerComposeConditionServiceStarted,
+				DockerComposeConditionServiceHealthy,
+				DockerComposeConditionServiceCompletedSuccessfully,
+			}))
+		}
+	}
+	return fieldErrors
 }
 
 var _ resource.ObjectList = &DockerComposeServiceList{}
@@ -162,6 +263,25 @@ type DockerComposeServiceStatus struct {
 	//
 	// +optional
 	LastApplyFinishTime metav1.MicroTime `json:"lastApplyFinishTime,omitempty" protobuf:"bytes,7,opt,name=lastApplyFinishTime"`
+
+	// A hash of the service's resolved configuration when we last brought it up.
+	//
+	// Edits to a compose project only recreate the services whose
+	// configuration hash changed.
+	//
+	// +optional
+	ConfigHash string `json:"configHash,omitempty" protobuf:"bytes,9,opt,name=configHash"`
+
+	// Why we last brought up this service, e.g., because it was never
+	// deployed, or because its configuration changed.
+	//
+	// +optional
+	LastApplyReason string `json:"lastApplyReason,omitempty" protobuf:"bytes,10,opt,name=lastApplyReason"`
+
+	// The Docker networks that the container is attached to.
+	//
+	// +optional
+	Networks []DockerNetworkAttachment `json:"networks,omitempty" protobuf:"bytes,11,rep,name=networks"`
 }
 
 // DockerComposeService implements ObjectWithStatusSubResource interface.
@@ -211,6 +331,28 @@ type DockerComposeProject struct {
 
 	// Path to an env file to use. Passed to docker-compose as `--env-file FILE`.
 	EnvFile string `json:"envFile,omitempty" protobuf:"bytes,5,opt,name=envFile"`
+
+	// Profiles to activate. Passed to docker-compose as `--profile NAME`.
+	//
+	// If omitted, every service in the project is included, regardless of
+	// its profiles. If set, only services without profiles and services
+	// in an active profile are included. Use "*" to activate all profiles.
+	//
+	// +optional
+	Profiles []string `json:"profiles,omitempty" protobuf:"bytes,6,rep,name=profiles"`
+
+	// The compose provider to run.
+	//
+	// One of "docker" (`docker compose`, falling back to `docker-compose`),
+	// "docker-compose", "podman" (`podman compose`, falling back to
+	// `podman-compose`), "nerdctl" (`nerdctl compose`), or the path to a
+	// compose binary.
+	//
+	// If omitted, Tilt uses $TILT_DOCKER_COMPOSE_PROVIDER if set, then
+	// $TILT_DOCKER_COMPOSE_CMD if set, and "docker" otherwise.
+	//
+	// +optional
+	Provider string `json:"provider,omitempty" protobuf:"bytes,7,opt,name=provider"`
 }
 
 // State of a standalone container in Docker.
@@ -242,6 +384,16 @@ type DockerContainerState struct {
 	// When the container process finished.
 	// +optional
 	FinishedAt metav1.MicroTime `json:"finishedAt,omitempty" protobuf:"bytes,6,opt,name=finishedAt"`
+
+	// The result of the container's healthcheck.
+	// Can be one of "starting", "healthy", or "unhealthy".
+	// Empty if the container has no healthcheck.
+	// +optional
+	Health string `json:"health,omitempty" protobuf:"bytes,7,opt,name=health"`
+
+	// Output from the most recent run of the container's healthcheck.
+	// +optional
+	HealthOutput string `json:"healthOutput,omitempty" protobuf:"bytes,8,opt,name=healthOutput"`
 }
 
 // How docker binds container ports to the host network
@@ -254,4 +406,26 @@ type DockerPortBinding struct {
 
 	// The IP on the host machine where Docker is binding the network.
 	HostIP string `json:"hostIP,omitempty" protobuf:"bytes,3,opt,name=hostIP"`
+
+	// The protocol of the port, "tcp" or "udp".
+	//
+	// +optional
+	Protocol string `json:"protocol,omitempty" protobuf:"bytes,4,opt,name=protocol"`
+}
+
+// How a container is attached to a Docker network.
+type DockerNetworkAttachment struct {
+	// The name of the network.
+	Name string `json:"name" protobuf:"bytes,1,opt,name=name"`
+
+	// The names that other containers on the network can use to reach
+	// this one, like the name of the Docker Compose service.
+	//
+	// +optional
+	Aliases []string `json:"aliases,omitempty" protobuf:"bytes,2,rep,name=aliases"`
+
+	// The IP address of the container on the network.
+	//
+	// +optional
+	IPAddress string `json:"ipAddress,omitempty" protobuf:"bytes,3,opt,name=ipAddress"`
 }
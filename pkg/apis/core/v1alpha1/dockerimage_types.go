@@ -18,6 +18,9 @@ package v1alpha1
 
 import (
 	"context"
+	"encoding/csv"
+	"fmt"
+	"strings"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -173,6 +176,17 @@ type DockerImageSpec struct {
 	//
 	// +optional
 	ClusterNeeds ClusterImageNeeds `json:"clusterNeeds,omitempty" protobuf:"bytes,15,opt,name=clusterNeeds,casttype=ClusterImageNeeds"`
+
+	// Build without using the cache, and don't re-use an image
+	// previously built from the same inputs.
+	//
+	// Equivalent to `--no-cache` in the Docker CLI.
+	//
+	// Tilt sets this on a single build when you trigger a resource
+	// with `tilt trigger --no-cache`.
+	//
+	// +optional
+	NoCache bool `json:"noCache,omitempty" protobuf:"varint,17,opt,name=noCache"`
 }
 
 var _ resource.Object = &DockerImage{}
@@ -211,7 +225,52 @@ func (in *DockerImage) IsStorageVersion() bool {
 }
 
 func (in *DockerImage) Validate(ctx context.Context) field.ErrorList {
-	// TODO(user): Modify it, adding your API validation here.
+	var errors field.ErrorList
+	for i, spec := range in.Spec.Secrets {
+		err := validateDockerBuildSecret(spec)
+		if err != nil {
+			errors = append(errors, field.Invalid(field.NewPath(".spec.secrets").Index(i), spec, err.Error()))
+		}
+	}
+	for i, spec := range in.Spec.SSHAgentConfigs {
+		if strings.SplitN(spec, "=", 2)[0] == "" {
+			errors = append(errors, field.Invalid(field.NewPath(".spec.sshAgentConfigs").Index(i), spec,
+				"must have an id, like 'default'"))
+		}
+	}
+	return errors
+}
+
+// Checks the syntax of a `docker build --secret` spec.
+//
+// Doesn't check that the source exists, because the builder may
+// not be on the same machine as the API server.
+func validateDockerBuildSecret(spec string) error {
+	fields, err := csv.NewReader(strings.NewReader(spec)).Read()
+	if err != nil {
+		return err
+	}
+
+	hasID := false
+	for _, f := range fields {
+		parts := strings.SplitN(f, "=", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("field %q must be a key=value pair", f)
+		}
+		switch strings.ToLower(parts[0]) {
+		case "id", "src", "source", "env":
+			hasID = hasID || parts[1] != ""
+		case "type":
+			if parts[1] != "file" && parts[1] != "env" {
+				return fmt.Errorf("unsupported type %q", parts[1])
+			}
+		default:
+			return fmt.Errorf("unexpected key %q", parts[0])
+		}
+	}
+	if !hasID {
+		return fmt.Errorf("must have an id")
+	}
 	return nil
 }
 
@@ -249,6 +308,24 @@ type DockerImageStatus struct {
 	// Status information about each individual build stage
 	// of the most recent image build.
 	StageStatuses []DockerImageStageStatus `json:"stageStatuses,omitempty" protobuf:"bytes,5,rep,name=stageStatuses"`
+
+	// The layers of the most recent successfully built image,
+	// from newest to oldest, like `docker history`.
+	//
+	// +optional
+	Layers []DockerImageLayer `json:"layers,omitempty" protobuf:"bytes,6,rep,name=layers"`
+
+	// The total size of the most recent successfully built image, in bytes.
+	//
+	// +optional
+	ImageSizeBytes int64 `json:"imageSizeBytes,omitempty" protobuf:"varint,7,opt,name=imageSizeBytes"`
+
+	// How much the image size changed from the previous successful build, in bytes.
+	//
+	// Zero on the first build.
+	//
+	// +optional
+	ImageSizeDeltaBytes int64 `json:"imageSizeDeltaBytes,omitempty" protobuf:"varint,8,opt,name=imageSizeDeltaBytes"`
 }
 
 // DockerImage implements ObjectWithStatusSubResource interface.
@@ -300,6 +377,38 @@ type DockerImageStateCompleted struct {
 	FinishedAt metav1.MicroTime `json:"finishedAt,omitempty" protobuf:"bytes,4,opt,name=finishedAt"`
 }
 
+// DockerImageLayer describes one layer of a built image.
+type DockerImageLayer struct {
+	// The ID of the layer's image, if the image is still around.
+	//
+	// Layers from a base image, or built with BuildKit, usually don't have one.
+	//
+	// +optional
+	ID string `json:"id,omitempty" protobuf:"bytes,1,opt,name=id"`
+
+	// The instruction that created the layer, e.g., `RUN npm install`.
+	//
+	// +optional
+	CreatedBy string `json:"createdBy,omitempty" protobuf:"bytes,2,opt,name=createdBy"`
+
+	// The size of the layer, in bytes.
+	//
+	// Instructions that only change the image config, like ENV or CMD,
+	// make empty layers.
+	//
+	// +optional
+	SizeBytes int64 `json:"sizeBytes,omitempty" protobuf:"varint,3,opt,name=sizeBytes"`
+
+	// Whether the build reused the layer, from the build cache or a base image,
+	// rather than building it again.
+	//
+	// Docker keeps the creation time of reused layers, so a layer that
+	// was created before the build started was reused.
+	//
+	// +optional
+	Cached bool `json:"cached,omitempty" protobuf:"varint,4,opt,name=cached"`
+}
+
 // DockerImageStageStatus gives detailed report of each stage
 // of the most recent image build.
 //
@@ -328,4 +437,13 @@ type DockerImageStageStatus struct {
 	// Error message if the stage failed. If empty, the stage succeeded.
 	// +optional
 	Error string `json:"error,omitempty" protobuf:"bytes,5,opt,name=error"`
+
+	// How many bytes of work the stage has done, for stages that
+	// report progress, like pulling a base image or sending the build context.
+	// +optional
+	Current int64 `json:"current,omitempty" protobuf:"varint,8,opt,name=current"`
+
+	// How many bytes of work the stage has in total. Zero if unknown.
+	// +optional
+	Total int64 `json:"total,omitempty" protobuf:"varint,9,opt,name=total"`
 }
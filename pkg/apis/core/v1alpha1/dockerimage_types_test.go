@@ -0,0 +1,52 @@
+package v1alpha1_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+func TestDockerImage_Validate_Secrets(t *testing.T) {
+	var cases = []struct {
+		name          string
+		secret        string
+		expectedError string
+	}{
+		{"id", "id=npmrc", ""},
+		{"src", "id=npmrc,src=/home/tilt/.npmrc", ""},
+		{"env", "id=token,env=NPM_TOKEN", ""},
+		{"type env", "type=env,id=NPM_TOKEN", ""},
+		{"no id", "type=file", `.spec.secrets[0]: Invalid value: "type=file": must have an id`},
+		{"bad type", "id=npmrc,type=vault", `.spec.secrets[0]: Invalid value: "id=npmrc,type=vault": unsupported type "vault"`},
+		{"bad key", "id=npmrc,path=.npmrc", `.spec.secrets[0]: Invalid value: "id=npmrc,path=.npmrc": unexpected key "path"`},
+		{"bad field", "npmrc", `.spec.secrets[0]: Invalid value: "npmrc": field "npmrc" must be a key=value pair`},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			image := &v1alpha1.DockerImage{
+				Spec: v1alpha1.DockerImageSpec{Secrets: []string{tc.secret}},
+			}
+			errs := image.Validate(context.Background())
+			if tc.expectedError == "" {
+				assert.Empty(t, errs)
+			} else if assert.Len(t, errs, 1) {
+				require.EqualError(t, errs[0], tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestDockerImage_Validate_SSH(t *testing.T) {
+	image := &v1alpha1.DockerImage{
+		Spec: v1alpha1.DockerImageSpec{SSHAgentConfigs: []string{"default", "github=/home/tilt/.ssh/id_ed25519", "=/key"}},
+	}
+	errs := image.Validate(context.Background())
+	if assert.Len(t, errs, 1) {
+		require.EqualError(t, errs[0], `.spec.sshAgentConfigs[2]: Invalid value: "=/key": must have an id, like 'default'`)
+	}
+}
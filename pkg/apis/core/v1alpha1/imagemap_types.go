@@ -214,6 +214,27 @@ type ImageMapStatus struct {
 	// may not be included in the image.
 	BuildStartTime *metav1.MicroTime `json:"buildStartTime,omitempty" protobuf:"bytes,2,opt,name=buildStartTime"`
 
+	// The digest of the image manifest that Tilt pushed to the registry,
+	// e.g., sha256:0123...
+	//
+	// When set, the image references above are pinned to this digest, so that
+	// the cluster runs exactly the image we built, even if someone else pushes
+	// the same tag to a shared registry.
+	//
+	// Empty if Tilt didn't push the image, or the registry didn't report a digest.
+	//
+	// +optional
+	Digest string `json:"digest,omitempty" protobuf:"bytes,5,opt,name=digest"`
+
+	// The host of the registry that Tilt pushed the image to,
+	// as seen from the local network.
+	//
+	// Together with the tag, tells tools that clean up old tags
+	// where Tilt's images live.
+	//
+	// +optional
+	Registry string `json:"registry,omitempty" protobuf:"bytes,6,opt,name=registry"`
+
 	// TODO(nick): I'm not totally sure how we should model registries in this system.
 	//
 	// We need to be able to support an image existing at multiple URLs in
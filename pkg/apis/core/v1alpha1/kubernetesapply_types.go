@@ -18,9 +18,13 @@ package v1alpha1
 
 import (
 	"context"
+	"sort"
+	"strings"
 	"time"
 
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/validation/field"
@@ -181,6 +185,92 @@ type KubernetesApplySpec struct {
 	//
 	// +optional
 	Cluster string `json:"cluster" protobuf:"bytes,13,opt,name=cluster"`
+
+	// WaitFor determines rollout checks on the applied objects.
+	//
+	// The apply isn't considered done until every object matched by a check
+	// passes it (e.g., a Deployment is Available or a Job is Complete).
+	// While waiting, the RolloutComplete condition is False.
+	//
+	// +optional
+	WaitFor []KubernetesApplyWaitFor `json:"waitFor,omitempty" protobuf:"bytes,14,rep,name=waitFor"`
+
+	// ApplyOrder splits the objects into stages that are applied one after another.
+	//
+	// Each object is applied in the stage of the first selector that matches it.
+	// Objects that don't match any selector are applied in a final stage.
+	// Within a stage, objects are applied in kind order (e.g., Namespaces and
+	// CustomResourceDefinitions first).
+	//
+	// Objects of a kind defined by a CustomResourceDefinition in the same apply
+	// wait for the CustomResourceDefinition to be established.
+	//
+	// +optional
+	ApplyOrder []ObjectSelector `json:"applyOrder,omitempty" protobuf:"bytes,15,rep,name=applyOrder"`
+
+	// Labels to add to every object in the YAML, including pod templates.
+	//
+	// Useful for attributing dev workloads to a team or owner
+	// (e.g., for cost tracking). Overrides labels with the same key in the YAML.
+	//
+	// Only supported with YAML, not ApplyCmd.
+	//
+	// +optional
+	CommonLabels map[string]string `json:"commonLabels,omitempty" protobuf:"bytes,16,rep,name=commonLabels"`
+
+	// Annotations to add to every object in the YAML, including pod templates.
+	//
+	// Overrides annotations with the same key in the YAML.
+	//
+	// Only supported with YAML, not ApplyCmd.
+	//
+	// +optional
+	CommonAnnotations map[string]string `json:"commonAnnotations,omitempty" protobuf:"bytes,17,rep,name=commonAnnotations"`
+
+	// Opts in to pruning: deleting objects that were removed from the YAML.
+	//
+	// When set, Tilt records this owner on each object it applies, and deletes
+	// objects with this owner that are no longer in the YAML, even if they were
+	// applied before Tilt restarted. The owner should be unique to the project
+	// and resource, so that projects sharing a cluster don't prune each other's
+	// objects.
+	//
+	// Only supported with YAML, not ApplyCmd.
+	//
+	// +optional
+	PruneOwner string `json:"pruneOwner,omitempty" protobuf:"bytes,18,opt,name=pruneOwner"`
+
+	// Volume claims that the Reset Volumes button may delete.
+	//
+	// Each entry is either the name of a PersistentVolumeClaim in the YAML, or
+	// the name of a volumeClaimTemplate on a StatefulSet in the YAML.
+	//
+	// Only supported with YAML, not ApplyCmd.
+	//
+	// +optional
+	ResetVolumes []string `json:"resetVolumes,omitempty" protobuf:"bytes,19,rep,name=resetVolumes"`
+
+	// Opts in to taking over objects that a GitOps tool (Flux or Argo CD) manages.
+	//
+	// After each apply, Tilt suspends reconciliation of the applied objects that
+	// the tool manages, so that it doesn't revert Tilt's changes. When the objects
+	// are deleted, Tilt resumes reconciliation instead, so that the tool restores
+	// the objects to their declared state.
+	//
+	// Only supported with YAML, not ApplyCmd.
+	//
+	// +optional
+	GitOpsHandoff bool `json:"gitOpsHandoff,omitempty" protobuf:"varint,20,opt,name=gitOpsHandoff"`
+
+	// Opts in to diff previews.
+	//
+	// When set, Tilt compares the YAML against the cluster each time the inputs
+	// change, and stores what the next apply would change in the status.
+	//
+	// Only supported with YAML, not ApplyCmd.
+	//
+	// +optional
+	DiffPreview bool `json:"diffPreview,omitempty" protobuf:"varint,21,opt,name=diffPreview"`
 }
 
 var _ resource.Object = &KubernetesApply{}
@@ -261,6 +351,65 @@ func (in *KubernetesApply) Validate(ctx context.Context) field.ErrorList {
 			"must specify exactly ONE of .spec.yaml or .spec.applyCmd"))
 	}
 
+	for i, w := range in.Spec.WaitFor {
+		fieldErrors = append(fieldErrors, w.validateAsSubfield(field.NewPath("spec.waitFor").Index(i))...)
+	}
+
+	labelsPath := field.NewPath("spec.commonLabels")
+	fieldErrors = append(fieldErrors, ValidateCommonLabels(in.Spec.CommonLabels, labelsPath)...)
+	fieldErrors = append(fieldErrors, ValidateCommonAnnotations(in.Spec.CommonAnnotations, field.NewPath("spec.commonAnnotations"))...)
+	if in.Spec.ApplyCmd != nil && (len(in.Spec.CommonLabels) > 0 || len(in.Spec.CommonAnnotations) > 0) {
+		fieldErrors = append(fieldErrors, field.Forbidden(labelsPath,
+			"common labels and annotations are not supported with .spec.applyCmd"))
+	}
+	if in.Spec.ApplyCmd != nil && in.Spec.PruneOwner != "" {
+		fieldErrors = append(fieldErrors, field.Forbidden(field.NewPath("spec.pruneOwner"),
+			"pruning is not supported with .spec.applyCmd"))
+	}
+	if in.Spec.ApplyCmd != nil && len(in.Spec.ResetVolumes) > 0 {
+		fieldErrors = append(fieldErrors, field.Forbidden(field.NewPath("spec.resetVolumes"),
+			"resetting volumes is not supported with .spec.applyCmd"))
+	}
+	if in.Spec.ApplyCmd != nil && in.Spec.GitOpsHandoff {
+		fieldErrors = append(fieldErrors, field.Forbidden(field.NewPath("spec.gitOpsHandoff"),
+			"GitOps handoff is not supported with .spec.applyCmd"))
+	}
+	if in.Spec.ApplyCmd != nil && in.Spec.DiffPreview {
+		fieldErrors = append(fieldErrors, field.Forbidden(field.NewPath("spec.diffPreview"),
+			"diff previews are not supported with .spec.applyCmd"))
+	}
+
+	return fieldErrors
+}
+
+// ValidateCommonLabels checks that labels are valid and don't
+// overwrite the labels that Tilt uses to track objects.
+func ValidateCommonLabels(labels map[string]string, fldPath *field.Path) field.ErrorList {
+	fieldErrors := metav1validation.ValidateLabels(labels, fldPath)
+	return append(fieldErrors, validateNotTiltKeys(labels, fldPath)...)
+}
+
+// ValidateCommonAnnotations checks that annotations are valid and don't
+// overwrite the annotations that Tilt uses to track objects.
+func ValidateCommonAnnotations(annotations map[string]string, fldPath *field.Path) field.ErrorList {
+	fieldErrors := apivalidation.ValidateAnnotations(annotations, fldPath)
+	return append(fieldErrors, validateNotTiltKeys(annotations, fldPath)...)
+}
+
+// Tilt uses the tilt.dev/ prefix to track the objects it applies,
+// so users can't overwrite those keys.
+func validateNotTiltKeys(m map[string]string, fldPath *field.Path) field.ErrorList {
+	var fieldErrors field.ErrorList
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if strings.HasPrefix(k, "tilt.dev/") {
+			fieldErrors = append(fieldErrors, field.Invalid(fldPath.Key(k), k, "the tilt.dev/ prefix is reserved"))
+		}
+	}
 	return fieldErrors
 }
 
@@ -320,6 +469,29 @@ type KubernetesApplyStatus struct {
 	// +optional
 	Conditions []metav1.Condition `json:"conditions,omitempty" protobuf:"bytes,7,rep,name=conditions"`
 
+	// A preview of what the next apply will change in the cluster.
+	//
+	// Only computed when spec.diffPreview is set,
+	// and cleared once the changes have been applied.
+	//
+	// +optional
+	Diff *KubernetesApplyDiff `json:"diff,omitempty" protobuf:"bytes,8,opt,name=diff"`
+
+	// The YAML of recent successful applies, newest first.
+	//
+	// Used to roll back a bad deploy. Capped by count and by total size.
+	// Empty when the objects are applied with a custom command.
+	//
+	// +optional
+	History []KubernetesApplyRevision `json:"history,omitempty" protobuf:"bytes,9,rep,name=history"`
+
+	// The revision in History that's currently deployed.
+	//
+	// Usually the newest revision, unless the apply was rolled back.
+	//
+	// +optional
+	CurrentRevision int32 `json:"currentRevision,omitempty" protobuf:"varint,10,opt,name=currentRevision"`
+
 	// TODO(nick): We should also add some sort of status field to this
 	// status (like waiting, active, done).
 }
@@ -334,8 +506,128 @@ const (
 	// settings or due to a Node being recycled). This condition allows Tilt to
 	// bypass Pod monitoring for this resource.
 	ApplyConditionJobComplete string = "JobComplete"
+
+	// ApplyConditionMutatedByWebhook means that a mutating admission webhook
+	// changed some of the applied objects (e.g., by injecting a sidecar).
+	//
+	// These changes are expected, so Tilt doesn't treat them as drift
+	// or re-apply the objects. The message describes what changed.
+	ApplyConditionMutatedByWebhook string = "MutatedByWebhook"
+
+	// ApplyConditionInputsUnchanged means that Tilt skipped the most recent run of
+	// the apply command, because none of its inputs (including the files it
+	// depends on) changed since the last successful run.
+	//
+	// Tilt never skips a run that the user triggered.
+	//
+	// The message contains the hash of the inputs.
+	ApplyConditionInputsUnchanged string = "InputsUnchanged"
+
+	// ApplyConditionPruned means that the apply deleted objects that
+	// were removed from the YAML. The message lists the deleted objects.
+	ApplyConditionPruned string = "Pruned"
+
+	// ApplyConditionRolloutComplete means that every applied object
+	// matched by a WaitFor check has passed it.
+	//
+	// Only set when the spec has WaitFor checks. While the checks are pending,
+	// the status is False with reason RolloutWaiting. If a check doesn't pass
+	// before its timeout, the reason is RolloutTimeout.
+	ApplyConditionRolloutComplete string = "RolloutComplete"
+
+	// ApplyConditionNamespaceApprovalRequired means that Tilt didn't apply
+	// the YAML, because it hardcodes namespaces that this project hasn't
+	// deployed to before.
+	//
+	// The message lists the namespaces, comma-separated. Tilt applies the
+	// YAML once the user approves them.
+	ApplyConditionNamespaceApprovalRequired string = "NamespaceApprovalRequired"
+)
+
+const (
+	ApplyReasonRolloutWaiting  = "RolloutWaiting"
+	ApplyReasonRolloutTimeout  = "RolloutTimeout"
+	ApplyReasonRolloutComplete = "RolloutComplete"
 )
 
+// KubernetesApplyRevision is a YAML that was successfully applied.
+type KubernetesApplyRevision struct {
+	// Identifies the revision. Each new YAML gets a higher number
+	// than the revisions before it.
+	Revision int32 `json:"revision" protobuf:"varint,1,opt,name=revision"`
+
+	// The YAML that was applied.
+	YAML string `json:"yaml" protobuf:"bytes,2,opt,name=yaml"`
+
+	// Timestamp of when this YAML was first applied.
+	//
+	// +optional
+	ApplyTime metav1.MicroTime `json:"applyTime,omitempty" protobuf:"bytes,3,opt,name=applyTime"`
+}
+
+// KubernetesApplyDiff compares the YAML that's about to be applied
+// against the objects that are currently in the cluster,
+// similar to `kubectl diff`.
+type KubernetesApplyDiff struct {
+	// Timestamp of when the diff was computed.
+	//
+	// +optional
+	ComputeTime metav1.MicroTime `json:"computeTime,omitempty" protobuf:"bytes,1,opt,name=computeTime"`
+
+	// The hash of the inputs that the diff was computed for.
+	//
+	// Compare with AppliedInputHash to check if the diff has been applied.
+	//
+	// +optional
+	InputHash string `json:"inputHash,omitempty" protobuf:"bytes,2,opt,name=inputHash"`
+
+	// The objects that would change. Objects that wouldn't change are omitted.
+	//
+	// +optional
+	Objects []KubernetesApplyObjectDiff `json:"objects,omitempty" protobuf:"bytes,3,rep,name=objects"`
+
+	// An error computing the diff.
+	//
+	// +optional
+	Error string `json:"error,omitempty" protobuf:"bytes,4,opt,name=error"`
+}
+
+// KubernetesApplyObjectDiff describes the change to one object.
+type KubernetesApplyObjectDiff struct {
+	// The kind of the object, e.g., Deployment.
+	Kind string `json:"kind" protobuf:"bytes,1,opt,name=kind"`
+
+	// The namespace of the object. Empty for cluster-scoped objects.
+	//
+	// +optional
+	Namespace string `json:"namespace,omitempty" protobuf:"bytes,2,opt,name=namespace"`
+
+	// The name of the object.
+	Name string `json:"name" protobuf:"bytes,3,opt,name=name"`
+
+	// What the apply would do to the object: "create" or "update".
+	Action string `json:"action" protobuf:"bytes,4,opt,name=action"`
+
+	// A unified diff from the live object to the object
+	// the server would store after the apply, as YAML.
+	//
+	// +optional
+	Diff string `json:"diff,omitempty" protobuf:"bytes,5,opt,name=diff"`
+}
+
+const (
+	KubernetesApplyDiffActionCreate = "create"
+	KubernetesApplyDiffActionUpdate = "update"
+)
+
+// AnnotationAllowedNamespaces opts a KubernetesApply in to namespace approval.
+//
+// The value lists the namespaces that the apply may touch without asking,
+// comma-separated. Applying to any other namespace (besides the cluster's
+// default namespace, and namespaces the user has approved for this project
+// before) requires approval.
+const AnnotationAllowedNamespaces = "tilt.dev/allowed-namespaces"
+
 // KubernetesApply implements ObjectWithStatusSubResource interface.
 var _ resource.ObjectWithStatusSubResource = &KubernetesApply{}
 
@@ -370,6 +662,69 @@ type KubernetesImageLocator struct {
 	Object *KubernetesImageObjectDescriptor `json:"object,omitempty" protobuf:"bytes,3,opt,name=object"`
 }
 
+// The default time to wait for a rollout check to pass.
+const KubernetesApplyWaitForTimeoutDefault = 5 * time.Minute
+
+// A rollout check on the applied objects.
+//
+// Modeled after `kubectl wait --for`.
+type KubernetesApplyWaitFor struct {
+	// Selects which applied objects to check.
+	ObjectSelector ObjectSelector `json:"objectSelector" protobuf:"bytes,1,opt,name=objectSelector"`
+
+	// The type of a status condition that must be True,
+	// e.g., "Available" for a Deployment or "Complete" for a Job.
+	//
+	// Exactly one of Condition OR JSONPath MUST be provided.
+	//
+	// +optional
+	Condition string `json:"condition,omitempty" protobuf:"bytes,2,opt,name=condition"`
+
+	// A JSONPath into the object, e.g., "{.status.phase}".
+	//
+	// Useful for CRDs that don't report their status as conditions.
+	//
+	// +optional
+	JSONPath string `json:"jsonPath,omitempty" protobuf:"bytes,3,opt,name=jsonPath"`
+
+	// The value the field at JSONPath must have.
+	//
+	// If empty, the field must be present and non-empty.
+	//
+	// +optional
+	Value string `json:"value,omitempty" protobuf:"bytes,4,opt,name=value"`
+
+	// How long to wait after the apply for the check to pass.
+	//
+	// If not specified, defaults to 5m.
+	//
+	// +optional
+	Timeout metav1.Duration `json:"timeout,omitempty" protobuf:"bytes,5,opt,name=timeout"`
+}
+
+func (in KubernetesApplyWaitFor) validateAsSubfield(path *field.Path) field.ErrorList {
+	var fieldErrors field.ErrorList
+	if (in.Condition == "") == (in.JSONPath == "") {
+		fieldErrors = append(fieldErrors, field.Invalid(
+			path,
+			in,
+			"must specify exactly ONE of .condition or .jsonPath"))
+	}
+	if in.Value != "" && in.JSONPath == "" {
+		fieldErrors = append(fieldErrors, field.Invalid(
+			path.Child("value"),
+			in.Value,
+			"only valid with .jsonPath"))
+	}
+	if in.Timeout.Duration < 0 {
+		fieldErrors = append(fieldErrors, field.Invalid(
+			path.Child("timeout"),
+			in.Timeout.Duration.String(),
+			"must be non-negative"))
+	}
+	return fieldErrors
+}
+
 type KubernetesImageObjectDescriptor struct {
 	// The name of the field that contains the image repository.
 	RepoField string `json:"repoField" protobuf:"bytes,1,opt,name=repoField"`
@@ -97,6 +97,22 @@ type KubernetesDiscoverySpec struct {
 	//
 	// +optional
 	Cluster string `json:"cluster" protobuf:"bytes,5,opt,name=cluster"`
+
+	// Extra namespaces to look in for Pods that match the ExtraSelectors.
+	//
+	// Pods are always discovered in the namespaces of the Watches. Use this
+	// when an operator creates Pods in a different namespace than the objects
+	// it manages.
+	//
+	// +optional
+	ExtraNamespaces []string `json:"extraNamespaces,omitempty" protobuf:"bytes,6,rep,name=extraNamespaces"`
+
+	// Look in all namespaces for Pods that match the ExtraSelectors.
+	//
+	// Requires permission to list and watch Pods across the cluster.
+	//
+	// +optional
+	AllNamespaces bool `json:"allNamespaces,omitempty" protobuf:"varint,7,opt,name=allNamespaces"`
 }
 
 // KubernetesWatchRef is similar to v1.ObjectReference from the Kubernetes API and is used to determine
@@ -214,6 +230,16 @@ func (in *KubernetesDiscovery) Validate(_ context.Context) field.ErrorList {
 			fieldErrors = append(fieldErrors, field.Required(watchPath.Index(i), "Namespace must be provided"))
 		}
 	}
+	nsPath := field.NewPath("spec", "extraNamespaces")
+	for i, ns := range in.Spec.ExtraNamespaces {
+		if ns == "" {
+			fieldErrors = append(fieldErrors, field.Required(nsPath.Index(i), "Namespace must not be empty"))
+		}
+	}
+	if (len(in.Spec.ExtraNamespaces) > 0 || in.Spec.AllNamespaces) && len(in.Spec.ExtraSelectors) == 0 {
+		fieldErrors = append(fieldErrors, field.Required(field.NewPath("spec", "extraSelectors"),
+			"extraSelectors are required to discover pods in extra namespaces"))
+	}
 	return fieldErrors
 }
 
@@ -242,6 +268,54 @@ type KubernetesDiscoveryStatus struct {
 	//
 	// +optional
 	Running *KubernetesDiscoveryStateRunning `json:"running,omitempty" protobuf:"bytes,4,opt,name=running"`
+
+	// Namespaces where Pods couldn't be watched (e.g., because of
+	// missing RBAC permissions). Discovery continues in the other namespaces.
+	//
+	// +optional
+	NamespaceErrors []KubernetesDiscoveryNamespaceError `json:"namespaceErrors,omitempty" protobuf:"bytes,5,rep,name=namespaceErrors"`
+
+	// Events are recent problems with the discovered Pods that Kubernetes
+	// reported (e.g., FailedScheduling, image pull back-offs, or containers
+	// that were OOMKilled).
+	//
+	// Only the most recent events for each Pod are kept.
+	//
+	// +optional
+	Events []KubernetesDiscoveryEvent `json:"events,omitempty" protobuf:"bytes,6,rep,name=events"`
+}
+
+// KubernetesDiscoveryEvent is a problem with a discovered Pod.
+//
+// Mirrors v1.Event from the Kubernetes API. OOMKilled containers are reported
+// as events too, even though Kubernetes only reports them in the Pod status.
+type KubernetesDiscoveryEvent struct {
+	// PodName is the name of the Pod that the event is about.
+	PodName string `json:"podName" protobuf:"bytes,1,opt,name=podName"`
+	// PodNamespace is the namespace of the Pod that the event is about.
+	PodNamespace string `json:"podNamespace" protobuf:"bytes,2,opt,name=podNamespace"`
+	// ContainerName is the container that the event is about, if any.
+	//
+	// +optional
+	ContainerName string `json:"containerName,omitempty" protobuf:"bytes,3,opt,name=containerName"`
+	// Reason is a short, machine-readable reason for the event (e.g., FailedScheduling).
+	Reason string `json:"reason" protobuf:"bytes,4,opt,name=reason"`
+	// Message is a human-readable description of the event.
+	Message string `json:"message" protobuf:"bytes,5,opt,name=message"`
+	// Count is the number of times the event has occurred.
+	Count int32 `json:"count" protobuf:"varint,6,opt,name=count"`
+	// LastTimestamp is the last time the event occurred.
+	LastTimestamp metav1.Time `json:"lastTimestamp,omitempty" protobuf:"bytes,7,opt,name=lastTimestamp"`
+}
+
+type KubernetesDiscoveryNamespaceError struct {
+	// The namespace that couldn't be watched. Empty for all namespaces.
+	//
+	// +optional
+	Namespace string `json:"namespace,omitempty" protobuf:"bytes,1,opt,name=namespace"`
+
+	// The error from watching the namespace.
+	Error string `json:"error" protobuf:"bytes,2,opt,name=error"`
 }
 
 type KubernetesDiscoveryStateWaiting struct {
@@ -252,6 +326,13 @@ type KubernetesDiscoveryStateWaiting struct {
 type KubernetesDiscoveryStateRunning struct {
 	// StartTime is when Kubernetes resource discovery began.
 	StartTime metav1.MicroTime `json:"startTime" protobuf:"bytes,1,opt,name=startTime"`
+
+	// PollingReason is set when Pods can't be watched (e.g., because RBAC
+	// doesn't allow it), so discovery lists them periodically instead.
+	// Pod status and live updates are slower while polling.
+	//
+	// +optional
+	PollingReason string `json:"pollingReason,omitempty" protobuf:"bytes,2,opt,name=pollingReason"`
 }
 
 // KubernetesDiscovery implements ObjectWithStatusSubResource interface.
@@ -0,0 +1,277 @@
+/*
+Copyright 2022 The Tilt Dev Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/tilt-dev/tilt-apiserver/pkg/server/builder/resource"
+	"github.com/tilt-dev/tilt-apiserver/pkg/server/builder/resource/resourcestrategy"
+)
+
+// The port that LocalIngress serves HTTPS on by default.
+//
+// Browsers resolve *.localhost to the local machine, so a LocalIngress
+// for myapp.localhost is reachable at https://myapp.localhost:10443/
+const LocalIngressDefaultPort = 10443
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// LocalIngress serves a friendly local hostname (e.g., myapp.localhost)
+// over HTTPS, and proxies requests to Tilt-managed port forwards.
+//
+// The URL stays the same when pods restart or port forwards
+// move to a different local port.
+//
+// +k8s:openapi-gen=true
+// +tilt:starlark-gen=true
+type LocalIngress struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	Spec   LocalIngressSpec   `json:"spec,omitempty" protobuf:"bytes,2,opt,name=spec"`
+	Status LocalIngressStatus `json:"status,omitempty" protobuf:"bytes,3,opt,name=status"`
+}
+
+// LocalIngressList
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type LocalIngressList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	Items []LocalIngress `json:"items" protobuf:"bytes,2,rep,name=items"`
+}
+
+// LocalIngressSpec defines the hostname to serve and where to route requests.
+type LocalIngressSpec struct {
+	// The hostname to serve, e.g., myapp.localhost
+	//
+	// Hostnames under .localhost resolve to the local machine without
+	// any DNS setup. Other hostnames need an entry in /etc/hosts, and
+	// their domain in TILT_LOCAL_INGRESS_DOMAINS, because the local CA
+	// only signs certificates for the domains it was created with.
+	Host string `json:"host" protobuf:"bytes,1,opt,name=host"`
+
+	// The local port to serve HTTPS on. Defaults to 10443.
+	//
+	// LocalIngresses on the same port share a server, and are
+	// routed by hostname.
+	//
+	// +optional
+	Port int32 `json:"port,omitempty" protobuf:"varint,2,opt,name=port"`
+
+	// Where to send requests, by path prefix. The longest matching prefix wins.
+	Routes []LocalIngressRoute `json:"routes" protobuf:"bytes,3,rep,name=routes"`
+}
+
+// LocalIngressRoute sends requests under a path prefix to a resource's
+// port forward, or to a local port.
+type LocalIngressRoute struct {
+	// The path prefix to match. Defaults to "/".
+	//
+	// +optional
+	Path string `json:"path,omitempty" protobuf:"bytes,1,opt,name=path"`
+
+	// The resource whose port forward should receive requests.
+	//
+	// Exactly one of Resource or LocalPort is required.
+	//
+	// +optional
+	Resource string `json:"resource,omitempty" protobuf:"bytes,2,opt,name=resource"`
+
+	// The container port of the resource's port forward. If not specified,
+	// uses the resource's first port forward.
+	//
+	// +optional
+	ContainerPort int32 `json:"containerPort,omitempty" protobuf:"varint,3,opt,name=containerPort"`
+
+	// A port on localhost that should receive requests (e.g., a server
+	// started by a local_resource).
+	//
+	// Exactly one of Resource or LocalPort is required.
+	//
+	// +optional
+	LocalPort int32 `json:"localPort,omitempty" protobuf:"varint,4,opt,name=localPort"`
+}
+
+var _ resource.Object = &LocalIngress{}
+var _ resourcestrategy.Validater = &LocalIngress{}
+var _ resourcestrategy.Defaulter = &LocalIngress{}
+
+func (in *LocalIngress) GetSpec() interface{} {
+	return in.Spec
+}
+
+func (in *LocalIngress) GetObjectMeta() *metav1.ObjectMeta {
+	return &in.ObjectMeta
+}
+
+func (in *LocalIngress) NamespaceScoped() bool {
+	return false
+}
+
+func (in *LocalIngress) New() runtime.Object {
+	return &LocalIngress{}
+}
+
+func (in *LocalIngress) NewList() runtime.Object {
+	return &LocalIngressList{}
+}
+
+func (in *LocalIngress) GetGroupVersionResource() schema.GroupVersionResource {
+	return schema.GroupVersionResource{
+		Group:    "tilt.dev",
+		Version:  "v1alpha1",
+		Resource: "localingresses",
+	}
+}
+
+func (in *LocalIngress) IsStorageVersion() bool {
+	return true
+}
+
+func (in *LocalIngress) Default() {
+	if in.Spec.Port == 0 {
+		in.Spec.Port = LocalIngressDefaultPort
+	}
+	for i := range in.Spec.Routes {
+		if in.Spec.Routes[i].Path == "" {
+			in.Spec.Routes[i].Path = "/"
+		}
+	}
+}
+
+func (in *LocalIngress) Validate(ctx context.Context) field.ErrorList {
+	var fieldErrors field.ErrorList
+	hostPath := field.NewPath("spec.host")
+	if in.Spec.Host == "" {
+		fieldErrors = append(fieldErrors, field.Required(hostPath, "A host is required"))
+	} else {
+		for _, msg := range validation.IsDNS1123Subdomain(in.Spec.Host) {
+			fieldErrors = append(fieldErrors, field.Invalid(hostPath, in.Spec.Host, msg))
+		}
+	}
+
+	if in.Spec.Port < 0 || in.Spec.Port > 65535 {
+		fieldErrors = append(fieldErrors, field.Invalid(field.NewPath("spec.port"), in.Spec.Port,
+			"must be between 1 and 65535"))
+	}
+
+	routesPath := field.NewPath("spec.routes")
+	if len(in.Spec.Routes) == 0 {
+		fieldErrors = append(fieldErrors, field.Required(routesPath, "At least one route is required"))
+	}
+
+	paths := make(map[string]bool)
+	for i, r := range in.Spec.Routes {
+		p := routesPath.Index(i)
+		path := r.Path
+		if path == "" {
+			path = "/"
+		}
+		if !strings.HasPrefix(path, "/") {
+			fieldErrors = append(fieldErrors, field.Invalid(p.Child("path"), r.Path, "must start with /"))
+		} else if paths[path] {
+			fieldErrors = append(fieldErrors, field.Duplicate(p.Child("path"), r.Path))
+		}
+		paths[path] = true
+
+		if (r.Resource == "") == (r.LocalPort == 0) {
+			fieldErrors = append(fieldErrors, field.Invalid(p, r,
+				"Exactly one of Resource or LocalPort is required"))
+		}
+		if r.LocalPort < 0 || r.LocalPort > 65535 {
+			fieldErrors = append(fieldErrors, field.Invalid(p.Child("localPort"), r.LocalPort,
+				"must be between 1 and 65535"))
+		}
+		if r.ContainerPort < 0 || r.ContainerPort > 65535 {
+			fieldErrors = append(fieldErrors, field.Invalid(p.Child("containerPort"), r.ContainerPort,
+				"must be between 1 and 65535"))
+		}
+	}
+	return fieldErrors
+}
+
+var _ resource.ObjectList = &LocalIngressList{}
+
+func (in *LocalIngressList) GetListMeta() *metav1.ListMeta {
+	return &in.ListMeta
+}
+
+// LocalIngressStatus defines the observed state of LocalIngress
+type LocalIngressStatus struct {
+	// The URL that the LocalIngress serves, e.g., https://myapp.localhost:10443/
+	//
+	// +optional
+	URL string `json:"url,omitempty" protobuf:"bytes,1,opt,name=url"`
+
+	// The certificate authority that signs the LocalIngress certificates.
+	//
+	// Add it to your system or browser trust store to avoid
+	// certificate warnings.
+	//
+	// +optional
+	CACertPath string `json:"caCertPath,omitempty" protobuf:"bytes,2,opt,name=caCertPath"`
+
+	// Where each route currently sends requests.
+	//
+	// +optional
+	Routes []LocalIngressRouteStatus `json:"routes,omitempty" protobuf:"bytes,3,rep,name=routes"`
+
+	// A problem serving the hostname (e.g., the port is already in use).
+	//
+	// +optional
+	Error string `json:"error,omitempty" protobuf:"bytes,4,opt,name=error"`
+}
+
+type LocalIngressRouteStatus struct {
+	// The path prefix of the route.
+	Path string `json:"path" protobuf:"bytes,1,opt,name=path"`
+
+	// The address that requests are sent to, e.g., localhost:53212
+	//
+	// +optional
+	Target string `json:"target,omitempty" protobuf:"bytes,2,opt,name=target"`
+
+	// Why requests can't be routed (e.g., the resource has no ready port forward).
+	//
+	// +optional
+	Error string `json:"error,omitempty" protobuf:"bytes,3,opt,name=error"`
+}
+
+// LocalIngress implements ObjectWithStatusSubResource interface.
+var _ resource.ObjectWithStatusSubResource = &LocalIngress{}
+
+func (in *LocalIngress) GetStatus() resource.StatusSubResource {
+	return in.Status
+}
+
+// LocalIngressStatus{} implements StatusSubResource interface.
+var _ resource.StatusSubResource = &LocalIngressStatus{}
+
+func (in LocalIngressStatus) CopyTo(parent resource.ObjectWithStatusSubResource) {
+	parent.(*LocalIngress).Status = in
+}
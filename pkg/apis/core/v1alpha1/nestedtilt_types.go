@@ -0,0 +1,169 @@
+/*
+Copyright 2022 The Tilt Dev Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"path/filepath"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/tilt-dev/tilt-apiserver/pkg/server/builder/resource"
+	"github.com/tilt-dev/tilt-apiserver/pkg/server/builder/resource/resourcestrategy"
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// NestedTilt runs a child Tilt instance for another Tiltfile,
+// and mirrors its resources into this Tilt's UI.
+//
+// Useful for composing several independently-owned Tilt setups
+// without merging their Tiltfiles.
+//
+// +k8s:openapi-gen=true
+// +tilt:starlark-gen=true
+type NestedTilt struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	Spec   NestedTiltSpec   `json:"spec,omitempty" protobuf:"bytes,2,opt,name=spec"`
+	Status NestedTiltStatus `json:"status,omitempty" protobuf:"bytes,3,opt,name=status"`
+}
+
+// NestedTiltList
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type NestedTiltList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	Items []NestedTilt `json:"items" protobuf:"bytes,2,rep,name=items"`
+}
+
+// NestedTiltSpec defines how to run the child Tilt instance.
+type NestedTiltSpec struct {
+	// The directory that contains the child Tiltfile.
+	//
+	// The child Tilt runs with this directory as its working directory.
+	//
+	// +tilt:local-path=true
+	Path string `json:"path" protobuf:"bytes,1,opt,name=path"`
+
+	// The port for the child Tilt's web UI and API.
+	//
+	// Must be different from the port of every other running Tilt instance.
+	Port int32 `json:"port" protobuf:"varint,2,opt,name=port"`
+
+	// Arguments to the child Tiltfile.
+	//
+	// By default, a list of arguments indicates the list of resources
+	// in the child Tiltfile that should be enabled.
+	//
+	// +optional
+	Args []string `json:"args,omitempty" protobuf:"bytes,3,rep,name=args"`
+}
+
+var _ resource.Object = &NestedTilt{}
+var _ resourcestrategy.Validater = &NestedTilt{}
+
+func (in *NestedTilt) GetSpec() interface{} {
+	return in.Spec
+}
+
+func (in *NestedTilt) GetObjectMeta() *metav1.ObjectMeta {
+	return &in.ObjectMeta
+}
+
+func (in *NestedTilt) NamespaceScoped() bool {
+	return false
+}
+
+func (in *NestedTilt) New() runtime.Object {
+	return &NestedTilt{}
+}
+
+func (in *NestedTilt) NewList() runtime.Object {
+	return &NestedTiltList{}
+}
+
+func (in *NestedTilt) GetGroupVersionResource() schema.GroupVersionResource {
+	return schema.GroupVersionResource{
+		Group:    "tilt.dev",
+		Version:  "v1alpha1",
+		Resource: "nestedtilts",
+	}
+}
+
+func (in *NestedTilt) IsStorageVersion() bool {
+	return true
+}
+
+func (in *NestedTilt) Validate(ctx context.Context) field.ErrorList {
+	var fieldErrors field.ErrorList
+	if !filepath.IsAbs(in.Spec.Path) {
+		fieldErrors = append(fieldErrors, field.Invalid(
+			field.NewPath("spec.path"),
+			in.Spec.Path,
+			"must be an absolute path"))
+	}
+	if in.Spec.Port <= 0 || in.Spec.Port > 65535 {
+		fieldErrors = append(fieldErrors, field.Invalid(
+			field.NewPath("spec.port"),
+			in.Spec.Port,
+			"must be between 1 and 65535"))
+	}
+	return fieldErrors
+}
+
+var _ resource.ObjectList = &NestedTiltList{}
+
+func (in *NestedTiltList) GetListMeta() *metav1.ListMeta {
+	return &in.ListMeta
+}
+
+// NestedTiltStatus defines the observed state of NestedTilt
+type NestedTiltStatus struct {
+	// The URL of the child Tilt's web UI.
+	// +optional
+	WebURL string `json:"webURL,omitempty" protobuf:"bytes,1,opt,name=webURL"`
+
+	// The names of the child Tilt's resources, as of the last sync.
+	// +optional
+	Resources []string `json:"resources,omitempty" protobuf:"bytes,2,rep,name=resources"`
+
+	// Contains information about any problems talking to the child Tilt.
+	// +optional
+	Error string `json:"error,omitempty" protobuf:"bytes,3,opt,name=error"`
+}
+
+// NestedTilt implements ObjectWithStatusSubResource interface.
+var _ resource.ObjectWithStatusSubResource = &NestedTilt{}
+
+func (in *NestedTilt) GetStatus() resource.StatusSubResource {
+	return in.Status
+}
+
+// NestedTiltStatus{} implements StatusSubResource interface.
+var _ resource.StatusSubResource = &NestedTiltStatus{}
+
+func (in NestedTiltStatus) CopyTo(parent resource.ObjectWithStatusSubResource) {
+	parent.(*NestedTilt).Status = in
+}
@@ -54,7 +54,9 @@ type PortForwardList struct {
 
 // PortForwardSpec defines the desired state of PortForward
 type PortForwardSpec struct {
-	// The name of the pod to port forward to/from. Required.
+	// The name of the pod to port forward to/from.
+	//
+	// Exactly one of PodName, ServiceName, or PodSelector is required.
 	PodName string `json:"podName" protobuf:"bytes,1,opt,name=podName"`
 
 	// The namespace of the pod to port forward to/from. Defaults to the kubecontext default namespace.
@@ -71,6 +73,27 @@ type PortForwardSpec struct {
 	//
 	// +optional
 	Cluster string `json:"cluster" protobuf:"bytes,4,opt,name=cluster"`
+
+	// The name of a Service to port forward to/from.
+	//
+	// Tilt forwards to a ready pod behind the Service, and fails over
+	// to another ready pod if that pod goes away. Each ContainerPort is
+	// treated as a port on the Service, and mapped to its target port.
+	//
+	// Requires a Namespace.
+	//
+	// +optional
+	ServiceName string `json:"serviceName,omitempty" protobuf:"bytes,5,opt,name=serviceName"`
+
+	// Labels that select the pods to port forward to/from.
+	//
+	// Tilt forwards to a ready pod that matches, and fails over
+	// to another ready pod if that pod goes away.
+	//
+	// Requires a Namespace.
+	//
+	// +optional
+	PodSelector map[string]string `json:"podSelector,omitempty" protobuf:"bytes,6,rep,name=podSelector"`
 }
 
 // Forward defines a port forward to execute on a given pod.
@@ -140,8 +163,22 @@ func (in *PortForward) IsStorageVersion() bool {
 
 func (in *PortForward) Validate(_ context.Context) field.ErrorList {
 	var fieldErrors field.ErrorList
-	if in.Spec.PodName == "" {
-		fieldErrors = append(fieldErrors, field.Required(field.NewPath("spec.podName"), "PodName cannot be empty"))
+	targets := 0
+	for _, set := range []bool{in.Spec.PodName != "", in.Spec.ServiceName != "", len(in.Spec.PodSelector) != 0} {
+		if set {
+			targets++
+		}
+	}
+	if targets == 0 {
+		fieldErrors = append(fieldErrors, field.Required(field.NewPath("spec.podName"),
+			"One of PodName, ServiceName, or PodSelector is required"))
+	} else if targets > 1 {
+		fieldErrors = append(fieldErrors, field.Invalid(field.NewPath("spec.podName"), in.Spec.PodName,
+			"Only one of PodName, ServiceName, or PodSelector may be specified"))
+	}
+	if targets > 0 && in.Spec.PodName == "" && in.Spec.Namespace == "" {
+		fieldErrors = append(fieldErrors, field.Required(field.NewPath("spec.namespace"),
+			"Namespace is required when forwarding to a Service or PodSelector"))
 	}
 	forwardsPath := field.NewPath("spec.forwards")
 	if len(in.Spec.Forwards) == 0 {
@@ -215,8 +252,47 @@ type ForwardStatus struct {
 	// Error is a human-readable description if a problem was encountered
 	// while initializing the forward.
 	Error string `json:"error,omitempty" protobuf:"bytes,5,opt,name=error"`
+
+	// State is the state of the connection to the pod.
+	//
+	// +optional
+	State ForwardState `json:"state,omitempty" protobuf:"bytes,6,opt,name=state,casttype=ForwardState"`
+
+	// RetryCount is the number of times the forward has reconnected
+	// since the PortForward was created or last changed.
+	//
+	// +optional
+	RetryCount int32 `json:"retryCount,omitempty" protobuf:"varint,7,opt,name=retryCount"`
+
+	// NextRetryTime is when the forward will next try to reconnect.
+	//
+	// Only set when the State is Retrying.
+	//
+	// +optional
+	NextRetryTime metav1.MicroTime `json:"nextRetryTime,omitempty" protobuf:"bytes,8,opt,name=nextRetryTime"`
+
+	// PodName is the name of the pod that the forward is connected to.
+	//
+	// When forwarding to a Service or PodSelector, this changes when
+	// Tilt fails over to another pod.
+	//
+	// +optional
+	PodName string `json:"podName,omitempty" protobuf:"bytes,9,opt,name=podName"`
 }
 
+type ForwardState string
+
+const (
+	// The forwarder is connecting to the pod.
+	ForwardStateConnecting ForwardState = "Connecting"
+
+	// The forwarder is listening, and forwarding traffic to the pod.
+	ForwardStateReady ForwardState = "Ready"
+
+	// The connection to the pod broke, and the forwarder is waiting to reconnect.
+	ForwardStateRetrying ForwardState = "Retrying"
+)
+
 // PortForward implements ObjectWithStatusSubResource interface.
 var _ resource.ObjectWithStatusSubResource = &PortForward{}
 
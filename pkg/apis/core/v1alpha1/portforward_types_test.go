@@ -0,0 +1,43 @@
+package v1alpha1_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+func TestPortForward_Validate_Target(t *testing.T) {
+	forwards := []v1alpha1.Forward{{ContainerPort: 8080}}
+	var cases = []struct {
+		name          string
+		spec          v1alpha1.PortForwardSpec
+		expectedError string
+	}{
+		{"pod", v1alpha1.PortForwardSpec{PodName: "pod"}, ""},
+		{"service", v1alpha1.PortForwardSpec{ServiceName: "svc", Namespace: "default"}, ""},
+		{"selector", v1alpha1.PortForwardSpec{PodSelector: map[string]string{"app": "foo"}, Namespace: "default"}, ""},
+		{"none", v1alpha1.PortForwardSpec{}, "spec.podName: Required value"},
+		{"pod and service", v1alpha1.PortForwardSpec{PodName: "pod", ServiceName: "svc", Namespace: "default"},
+			"Only one of PodName, ServiceName, or PodSelector may be specified"},
+		{"service without namespace", v1alpha1.PortForwardSpec{ServiceName: "svc"},
+			"spec.namespace: Required value"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			pf := &v1alpha1.PortForward{Spec: tc.spec}
+			pf.Spec.Forwards = forwards
+			errs := pf.Validate(context.Background())
+			if tc.expectedError == "" {
+				assert.Empty(t, errs)
+				return
+			}
+			if assert.Len(t, errs, 1) {
+				assert.Contains(t, errs[0].Error(), tc.expectedError)
+			}
+		})
+	}
+}
@@ -74,6 +74,8 @@ func AllResourceObjects() []resource.Object {
 		&Cluster{},
 		&DockerComposeService{},
 		&DockerComposeLogStream{},
+		&NestedTilt{},
+		&LocalIngress{},
 
 		// Hey! You! If you're adding a new top-level type, add the type object here.
 	}
@@ -102,6 +104,8 @@ func AllResourceLists() []runtime.Object {
 		&ClusterList{},
 		&DockerComposeServiceList{},
 		&DockerComposeLogStreamList{},
+		&NestedTiltList{},
+		&LocalIngressList{},
 
 		// Hey! You! If you're adding a new top-level type, add the List type here.
 	}
@@ -11,6 +11,24 @@ type RestartOnSpec struct {
 	// UIButtons that can trigger a restart.
 	// +optional
 	UIButtons []string `json:"uiButtons,omitempty" protobuf:"bytes,2,rep,name=uiButtons"`
+
+	// ObjectFields on other API objects that can trigger a restart
+	// when their value changes.
+	// +optional
+	ObjectFields []ObjectFieldSource `json:"objectFields,omitempty" protobuf:"bytes,3,rep,name=objectFields"`
+}
+
+// ObjectFieldSource watches one field of an API object.
+type ObjectFieldSource struct {
+	// Kind of the object to watch, e.g., "ConfigMap".
+	Kind string `json:"kind" protobuf:"bytes,1,opt,name=kind"`
+
+	// Name of the object to watch.
+	Name string `json:"name" protobuf:"bytes,2,opt,name=name"`
+
+	// FieldPath is a JSONPath expression selecting the field to watch,
+	// e.g., "{.data.version}".
+	FieldPath string `json:"fieldPath" protobuf:"bytes,3,opt,name=fieldPath"`
 }
 
 // StartOnSpec indicates the set of objects that can trigger a start/restart of this object.
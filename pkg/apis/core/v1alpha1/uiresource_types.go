@@ -281,6 +281,26 @@ type UIBuildRunning struct {
 	// The log span where the build logs are stored in the logstore.
 	// +optional
 	SpanID string `json:"spanID,omitempty" protobuf:"bytes,2,opt,name=spanID"`
+
+	// The image build stage in progress, as BuildKit names it
+	// (e.g., "[2/5] RUN npm install"). Empty if no image is building.
+	// +optional
+	CurrentStage string `json:"currentStage,omitempty" protobuf:"bytes,3,opt,name=currentStage"`
+
+	// The number of image build stages that have finished, including cached stages.
+	// +optional
+	CompletedStages int32 `json:"completedStages,omitempty" protobuf:"varint,4,opt,name=completedStages"`
+
+	// The number of image build stages that BuildKit has reported so far.
+	// +optional
+	TotalStages int32 `json:"totalStages,omitempty" protobuf:"varint,5,opt,name=totalStages"`
+
+	// How far along the image builds are, from 0 to 100.
+	//
+	// Counts finished stages, plus the bytes done in stages that report
+	// progress, like pulling a base image.
+	// +optional
+	Progress int32 `json:"progress,omitempty" protobuf:"varint,6,opt,name=progress"`
 }
 
 // UIBuildRunning respresents a finished build/update in the user interface.
@@ -311,6 +331,24 @@ type UIBuildTerminated struct {
 	// build+deploy to reset the pod state to what's on disk.
 	// +optional
 	IsCrashRebuild bool `json:"isCrashRebuild,omitempty" protobuf:"varint,6,opt,name=isCrashRebuild"`
+
+	// The number of image build stages in the images that this build built.
+	// Zero if the build didn't build any images.
+	// +optional
+	TotalStages int32 `json:"totalStages,omitempty" protobuf:"varint,7,opt,name=totalStages"`
+
+	// The number of image build stages that BuildKit reused from its cache.
+	// +optional
+	CachedStages int32 `json:"cachedStages,omitempty" protobuf:"varint,8,opt,name=cachedStages"`
+
+	// The slowest image build stage that BuildKit didn't reuse from its cache,
+	// as BuildKit names it (e.g., "[2/5] RUN npm install").
+	// +optional
+	SlowestStage string `json:"slowestStage,omitempty" protobuf:"bytes,9,opt,name=slowestStage"`
+
+	// How long the slowest stage took, in milliseconds.
+	// +optional
+	SlowestStageMillis int64 `json:"slowestStageMillis,omitempty" protobuf:"varint,10,opt,name=slowestStageMillis"`
 }
 
 // UIResourceKubernetes contains status information specific to Kubernetes.
@@ -355,6 +393,11 @@ type UIResourceKubernetes struct {
 	// for this resource.
 	// +optional
 	DisplayNames []string `json:"displayNames,omitempty" protobuf:"bytes,9,rep,name=displayNames"`
+
+	// A preview of the changes the next apply will make to the cluster,
+	// as a unified diff. Only populated for resources with diff previews.
+	// +optional
+	PendingDiff string `json:"pendingDiff,omitempty" protobuf:"bytes,10,opt,name=pendingDiff"`
 }
 
 // UIResourceLocal contains status information specific to local commands.
@@ -383,6 +426,21 @@ type UIResourceStateWaiting struct {
 	//
 	// +optional
 	On []UIResourceStateWaitingOnRef `json:"on,omitempty" protobuf:"bytes,2,rep,name=on"`
+
+	// A human-readable message explaining why the resource is waiting.
+	// +optional
+	Message string `json:"message,omitempty" protobuf:"bytes,3,opt,name=message"`
+
+	// MessageCode is a stable identifier for the message, from Tilt's message
+	// catalog (e.g., WaitingForDependency). Clients should match on the code
+	// rather than the message text.
+	// +optional
+	MessageCode string `json:"messageCode,omitempty" protobuf:"bytes,4,opt,name=messageCode"`
+
+	// MessageArgs are the arguments that the message was rendered with,
+	// so that clients can render their own (e.g., translated) message.
+	// +optional
+	MessageArgs map[string]string `json:"messageArgs,omitempty" protobuf:"bytes,5,rep,name=messageArgs"`
 }
 
 type UIResourceStateWaitingOnRef struct {
@@ -426,4 +484,15 @@ type UIResourceCondition struct {
 	// A human readable message indicating details about the transition.
 	// +optional
 	Message string `json:"message,omitempty" protobuf:"bytes,5,opt,name=message"`
+
+	// MessageCode is a stable identifier for the message, from Tilt's message
+	// catalog (e.g., ResourceUpdateError). Clients should match on the code
+	// rather than the message text.
+	// +optional
+	MessageCode string `json:"messageCode,omitempty" protobuf:"bytes,7,opt,name=messageCode"`
+
+	// MessageArgs are the arguments that the message was rendered with,
+	// so that clients can render their own (e.g., translated) message.
+	// +optional
+	MessageArgs map[string]string `json:"messageArgs,omitempty" protobuf:"bytes,8,rep,name=messageArgs"`
 }
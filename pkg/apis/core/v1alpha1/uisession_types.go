@@ -182,6 +182,32 @@ type UISessionStatus struct {
 	// project in LocalStorage or other persistent storage.
 	// +optional
 	TiltfileKey string `json:"tiltfileKey,omitempty" protobuf:"bytes,11,opt,name=tiltfileKey"`
+
+	// Crashes reports internal errors that Tilt recovered from.
+	// Tilt keeps running, but the component that crashed may not work
+	// correctly until Tilt restarts.
+	// +optional
+	Crashes []UISessionCrash `json:"crashes,omitempty" protobuf:"bytes,13,rep,name=crashes"`
+
+	// VersionWarnings explains how the running Tilt differs from the latest
+	// release, or from the tilt binary that editor integrations use.
+	//
+	// Only populated if the Tiltfile opts in with
+	// version_settings(check_latest_release=True).
+	// +optional
+	VersionWarnings []string `json:"versionWarnings,omitempty" protobuf:"bytes,14,rep,name=versionWarnings"`
+
+	// ReleaseHighlights lists the first few changelog items from the
+	// latest release, if it's newer than the running version.
+	// +optional
+	ReleaseHighlights []string `json:"releaseHighlights,omitempty" protobuf:"bytes,15,rep,name=releaseHighlights"`
+
+	// Idle reports the resources that Tilt disabled because nobody was
+	// using it, and its progress bringing them back.
+	//
+	// Only populated if the Tiltfile opts in with idle_settings().
+	// +optional
+	Idle *UISessionIdleStatus `json:"idle,omitempty" protobuf:"bytes,16,opt,name=idle"`
 }
 
 // UISession implements ObjectWithStatusSubResource interface.
@@ -215,6 +241,11 @@ type UIFeatureFlag struct {
 	// The value of the flag.
 	// +optional
 	Value bool `json:"value,omitempty" protobuf:"varint,2,opt,name=value"`
+
+	// Where the value came from: "default", "rollout" (the flag is being
+	// rolled out gradually, and this project has it), or "tiltfile".
+	// +optional
+	Source string `json:"source,omitempty" protobuf:"bytes,3,opt,name=source"`
 }
 
 // Information about the running tilt binary.
@@ -236,6 +267,43 @@ type TiltBuild struct {
 	Dev bool `json:"dev,omitempty" protobuf:"varint,4,opt,name=dev"`
 }
 
+// A panic in a Tilt controller or subscriber that Tilt recovered from.
+type UISessionCrash struct {
+	// The controller or subscriber that crashed.
+	Component string `json:"component" protobuf:"bytes,1,opt,name=component"`
+
+	// The panic message, with secrets redacted.
+	// +optional
+	Message string `json:"message,omitempty" protobuf:"bytes,2,opt,name=message"`
+
+	// When the component first crashed with this message.
+	// +optional
+	Time metav1.Time `json:"time,omitempty" protobuf:"bytes,3,opt,name=time"`
+
+	// How many times the component has crashed with this message.
+	// +optional
+	Count int32 `json:"count,omitempty" protobuf:"varint,4,opt,name=count"`
+}
+
+// Resources that Tilt disabled because it was idle.
+type UISessionIdleStatus struct {
+	// When Tilt disabled the resources. Empty once they're resuming.
+	// +optional
+	ShutDownAt metav1.Time `json:"shutDownAt,omitempty" protobuf:"bytes,1,opt,name=shutDownAt"`
+
+	// When Tilt started bringing the resources back.
+	// +optional
+	ResumeStartTime metav1.Time `json:"resumeStartTime,omitempty" protobuf:"bytes,2,opt,name=resumeStartTime"`
+
+	// The resources that Tilt disabled.
+	// +optional
+	Resources []string `json:"resources,omitempty" protobuf:"bytes,3,rep,name=resources"`
+
+	// The resources that have deployed since Tilt started bringing them back.
+	// +optional
+	ResumedResources []string `json:"resumedResources,omitempty" protobuf:"bytes,4,rep,name=resumedResources"`
+}
+
 // Information about how the Tilt binary handles updates.
 type VersionSettings struct {
 	// Whether version updates have been enabled/disabled from the Tiltfile.
@@ -0,0 +1,8 @@
+package model
+
+// Settings for developers working against remote clusters over slow links.
+type BandwidthSettings struct {
+	// Start Tilt in low-bandwidth mode. The mode can be switched at
+	// runtime from the UI; the Tiltfile only sets the initial value.
+	Low bool
+}
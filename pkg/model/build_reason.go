@@ -1,6 +1,9 @@
 package model
 
-import "strings"
+import (
+	"fmt"
+	"strings"
+)
 
 type BuildReason int
 
@@ -37,6 +40,14 @@ const (
 	// Building manifestA will mark imageB
 	// with changed dependencies.
 	BuildReasonFlagChangedDeps
+
+	// An external system fired a trigger declared with external_trigger().
+	BuildReasonFlagTriggerExternal
+
+	// The user asked for the triggered build to skip the build cache.
+	//
+	// Always accompanies a trigger.
+	BuildReasonFlagNoCache
 )
 
 func (r BuildReason) With(flag BuildReason) BuildReason {
@@ -76,12 +87,15 @@ var translations = map[BuildReason]string{
 	BuildReasonFlagTriggerUnknown:  "Unknown Trigger",
 	BuildReasonFlagTiltfileArgs:    "Tilt Args",
 	BuildReasonFlagChangedDeps:     "Dependency Updated",
+	BuildReasonFlagTriggerExternal: "External Trigger",
+	BuildReasonFlagNoCache:         "No Cache",
 }
 
 var triggerBuildReasons = []BuildReason{
 	BuildReasonFlagTriggerWeb,
 	BuildReasonFlagTriggerCLI,
 	BuildReasonFlagTriggerUnknown,
+	BuildReasonFlagTriggerExternal,
 }
 
 var allBuildReasons = []BuildReason{
@@ -94,6 +108,8 @@ var allBuildReasons = []BuildReason{
 	BuildReasonFlagChangedDeps,
 	BuildReasonFlagTriggerUnknown,
 	BuildReasonFlagTiltfileArgs,
+	BuildReasonFlagTriggerExternal,
+	BuildReasonFlagNoCache,
 }
 
 func (r BuildReason) String() string {
@@ -103,6 +119,9 @@ func (r BuildReason) String() string {
 	// build reason, because it was explicitly specified by the user rather than implicit.
 	for _, v := range triggerBuildReasons {
 		if r.Has(v) {
+			if r.Has(BuildReasonFlagNoCache) {
+				return fmt.Sprintf("%s (%s)", translations[v], translations[BuildReasonFlagNoCache])
+			}
 			return translations[v]
 		}
 	}
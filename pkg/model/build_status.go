@@ -6,6 +6,9 @@ import (
 
 const BuildHistoryLimit = 2
 
+// How many triggers we remember for each resource.
+const TriggerHistoryLimit = 50
+
 type BuildType string
 
 const BuildTypeImage BuildType = "image"
@@ -31,6 +34,18 @@ type BuildRecord struct {
 	WarningCount int
 }
 
+// Why a resource was rebuilt, recorded when its build starts.
+type TriggerRecord struct {
+	Time   time.Time
+	Reason BuildReason
+
+	// The files that changed, if the build was triggered by file changes.
+	Files []string
+
+	// The log span of the resulting build.
+	SpanID LogSpanID
+}
+
 func (bs BuildRecord) Empty() bool {
 	return bs.StartTime.IsZero()
 }
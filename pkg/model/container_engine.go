@@ -0,0 +1,28 @@
+package model
+
+// The container engines that Tilt can build and run images with.
+type ContainerEngine string
+
+const (
+	// The Docker daemon that Tilt finds from the environment.
+	ContainerEngineDocker ContainerEngine = "docker"
+
+	// Podman, through its Docker-compatible API socket.
+	ContainerEnginePodman ContainerEngine = "podman"
+)
+
+var ContainerEngines = []ContainerEngine{ContainerEngineDocker, ContainerEnginePodman}
+
+// The container engine that a project builds and runs images with.
+type ContainerEngineSettings struct {
+	Engine ContainerEngine
+
+	// The address of the engine's API socket, e.g., unix:///run/podman/podman.sock.
+	//
+	// Empty means the Docker host from the environment.
+	Host string
+}
+
+func (s ContainerEngineSettings) IsPodman() bool {
+	return s.Engine == ContainerEnginePodman
+}
@@ -11,12 +11,32 @@ const DockerPruneDefaultInterval = time.Hour
 // Keep the last 2 builds of an image
 const DockerPruneDefaultKeepRecent = 2
 
+// Volumes can hold data that's slow to rebuild (like a database), so give them longer.
+const DockerPruneDefaultVolumeMaxAge = time.Hour * 24
+
 type DockerPruneSettings struct {
 	Enabled    bool
 	MaxAge     time.Duration // "prune Docker objects older than X"
 	NumBuilds  int           // "prune every Y builds" (takes precedence over "prune every Z hours")
 	Interval   time.Duration // "prune every Z hours"
 	KeepRecent int           // Keep the most recent N builds of a tag.
+
+	KeepRecentByImage map[string]int // Overrides KeepRecent for specific image names
+	KeepRefs          []string       // Never prune images matching these refs
+
+	PruneVolumes bool          // Also prune dangling volumes created by Tilt
+	VolumeMaxAge time.Duration // "prune volumes older than X"
+
+	ByCluster map[string]DockerPruneSettings // Overrides for specific Clusters, by name
+}
+
+// The settings for the Cluster with the given name.
+func (s DockerPruneSettings) ForCluster(name string) DockerPruneSettings {
+	override, ok := s.ByCluster[name]
+	if ok {
+		return override
+	}
+	return s
 }
 
 func DefaultDockerPruneSettings() DockerPruneSettings {
@@ -0,0 +1,24 @@
+package model
+
+// A source outside of Tilt that can trigger a manifest to update,
+// like a schema registry that publishes a new version.
+//
+// Declared with external_trigger() in the Tiltfile.
+type ExternalTrigger struct {
+	// External systems can fire the trigger by sending
+	// a POST request to /api/triggers/{name} on the Tilt server.
+	Name string
+
+	// (Optional) Fires the trigger when a field of a Tilt API object changes.
+	//
+	// The object is in kind/name format, like "configmap/schema-version".
+	WatchObject string
+
+	// A JSONPath expression selecting the field of WatchObject to watch,
+	// like "{.data.version}".
+	WatchField string
+}
+
+func (t ExternalTrigger) IsWatch() bool {
+	return t.WatchObject != ""
+}
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"strings"
+)
+
+// A label recording the group a manifest was put in with the Tiltfile
+// group() builtin.
+//
+// Groups are hierarchical paths like "payments/api/db". Label values can't
+// contain slashes, so the label stores the path with dots instead.
+const ManifestGroupLabel = "tilt.dev/group"
+
+const GroupPathSeparator = "/"
+
+const groupLabelSeparator = "."
+
+func GroupPathToLabelValue(path string) string {
+	return strings.ReplaceAll(path, GroupPathSeparator, groupLabelSeparator)
+}
+
+func GroupPathFromLabels(labels map[string]string) string {
+	return strings.ReplaceAll(labels[ManifestGroupLabel], groupLabelSeparator, GroupPathSeparator)
+}
+
+// Returns true if path is the group itself or is nested somewhere under it.
+func GroupContains(group, path string) bool {
+	if group == "" || path == "" {
+		return false
+	}
+	return path == group || strings.HasPrefix(path, group+GroupPathSeparator)
+}
+
+// The group path of the manifest, or the empty string if it's not in a group.
+func (m Manifest) GroupPath() string {
+	return GroupPathFromLabels(m.Labels)
+}
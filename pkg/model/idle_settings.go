@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// How long before shutting down an idle environment to warn about it.
+const IdleDefaultWarnBefore = 15 * time.Minute
+
+// Settings for shutting down dev environments that nobody is using,
+// so that they don't hold on to shared cluster capacity overnight.
+type IdleSettings struct {
+	// Disable all resources after this long without file changes,
+	// triggers, or clicks in the UI. Zero means never.
+	Timeout time.Duration
+
+	// Log a warning this long before disabling resources.
+	WarnBefore time.Duration
+
+	// Exit Tilt after disabling resources.
+	Exit bool
+}
+
+func (s IdleSettings) Enabled() bool {
+	return s.Timeout > 0
+}
@@ -119,6 +119,8 @@ func (i ImageTarget) ImageMapDeps() []string {
 		return bd.ImageMaps
 	case CustomBuild:
 		return bd.ImageMaps
+	case KoBuild:
+		return bd.ImageMaps
 	}
 	return nil
 }
@@ -131,6 +133,9 @@ func (i ImageTarget) WithImageMapDeps(names []string) ImageTarget {
 	case CustomBuild:
 		bd.ImageMaps = sliceutils.Dedupe(names)
 		i.BuildDetails = bd
+	case KoBuild:
+		bd.ImageMaps = sliceutils.Dedupe(names)
+		i.BuildDetails = bd
 	default:
 		if len(names) > 0 {
 			panic(fmt.Sprintf("image does not support image deps: %v", i.ID()))
@@ -169,6 +174,14 @@ func (i ImageTarget) Validate() error {
 				"[Validate] CustomBuild command must not be empty",
 			)
 		}
+	case OCIArtifactBuild:
+		if len(bd.Files) == 0 {
+			return fmt.Errorf("[Validate] OCI artifact %q has no files", i.ImageMapSpec.Selector)
+		}
+	case KoBuild:
+		if bd.ImportPath == "" {
+			return fmt.Errorf("[Validate] ko build %q missing import path", i.ImageMapSpec.Selector)
+		}
 	case DockerComposeBuild:
 		if bd.Service == "" {
 			return fmt.Errorf("[Validate] DockerComposeBuild missing service name")
@@ -205,6 +218,26 @@ func (i ImageTarget) IsCustomBuild() bool {
 	return ok
 }
 
+func (i ImageTarget) OCIArtifactBuildInfo() OCIArtifactBuild {
+	ret, _ := i.BuildDetails.(OCIArtifactBuild)
+	return ret
+}
+
+func (i ImageTarget) IsOCIArtifactBuild() bool {
+	_, ok := i.BuildDetails.(OCIArtifactBuild)
+	return ok
+}
+
+func (i ImageTarget) KoBuildInfo() KoBuild {
+	ret, _ := i.BuildDetails.(KoBuild)
+	return ret
+}
+
+func (i ImageTarget) IsKoBuild() bool {
+	_, ok := i.BuildDetails.(KoBuild)
+	return ok
+}
+
 func (i ImageTarget) DockerComposeBuildInfo() DockerComposeBuild {
 	ret, _ := i.BuildDetails.(DockerComposeBuild)
 	return ret
@@ -246,6 +279,10 @@ func (i ImageTarget) LocalPaths() []string {
 		return []string{bd.Context}
 	case CustomBuild:
 		return append([]string(nil), bd.Deps...)
+	case OCIArtifactBuild:
+		return append([]string(nil), bd.Deps...)
+	case KoBuild:
+		return append([]string(nil), bd.Deps...)
 	case DockerComposeBuild:
 		return []string{bd.Context}
 	}
@@ -258,6 +295,10 @@ func (i ImageTarget) ClusterNeeds() v1alpha1.ClusterImageNeeds {
 		return bd.DockerImageSpec.ClusterNeeds
 	case CustomBuild:
 		return bd.CmdImageSpec.ClusterNeeds
+	case OCIArtifactBuild:
+		return bd.CmdImageSpec.ClusterNeeds
+	case KoBuild:
+		return bd.CmdImageSpec.ClusterNeeds
 	}
 	return v1alpha1.ClusterImageNeedsBase
 }
@@ -328,6 +369,22 @@ func (i ImageTarget) inferImageProperties(clusterNeeds v1alpha1.ClusterImageNeed
 		i.BuildDetails = cb
 	}
 
+	ab, ok := i.BuildDetails.(OCIArtifactBuild)
+	if ok {
+		ab.CmdImageSpec.Ref = i.ImageMapSpec.Selector
+		ab.CmdImageSpec.ClusterNeeds = clusterNeeds
+		ab.CmdImageSpec.Cluster = clusterName
+		i.BuildDetails = ab
+	}
+
+	kb, ok := i.BuildDetails.(KoBuild)
+	if ok {
+		kb.CmdImageSpec.Ref = i.ImageMapSpec.Selector
+		kb.CmdImageSpec.ClusterNeeds = clusterNeeds
+		kb.CmdImageSpec.Cluster = clusterName
+		i.BuildDetails = kb
+	}
+
 	return i, nil
 }
 
@@ -367,6 +424,62 @@ func (cb CustomBuild) SkipsPush() bool {
 		cb.OutputMode == v1alpha1.CmdImageOutputRemote
 }
 
+// OCIArtifactBuild packages local files (e.g., a WASM module or a Helm chart)
+// as a non-runnable OCI artifact, and pushes it to the registry.
+type OCIArtifactBuild struct {
+	// An optional command that produces the files. Args is empty
+	// if the files are checked in.
+	v1alpha1.CmdImageSpec
+
+	// Files to package. Each file becomes one layer of the artifact.
+	Files []string
+
+	// The media type of the artifact config, which registries and tools use to
+	// identify the kind of artifact (e.g., application/vnd.wasm.config.v1+json).
+	ArtifactType string
+
+	// The media type of each layer.
+	LayerMediaType string
+
+	// Deps is a list of file paths that are dependencies of the artifact.
+	Deps []string
+}
+
+func (OCIArtifactBuild) buildDetails() {}
+
+// KoBuild compiles a Go package on the host, and layers the binary
+// onto a base image without a Dockerfile, the same way https://ko.build does.
+type KoBuild struct {
+	// The directory to run `go build` in, plus the usual image
+	// properties (ref, cluster) shared with other CmdImages.
+	v1alpha1.CmdImageSpec
+
+	// The Go package to build (e.g., ./cmd/server or example.com/app/cmd/server).
+	ImportPath string
+
+	// The image to put the binary on top of.
+	// If empty, we use KoDefaultBaseImage.
+	BaseImage string
+
+	// Extra flags for `go build` (e.g., -ldflags=-s).
+	BuildFlags []string
+
+	// Extra environment variables for `go build` (e.g., GOFLAGS=-mod=vendor).
+	Env []string
+
+	// The platform to compile for and pull the base image for (e.g., linux/arm64).
+	// If empty, we use the cluster's platform.
+	Platform string
+
+	// Deps is a list of file paths that are dependencies of the binary.
+	Deps []string
+}
+
+// The same default as ko.
+const KoDefaultBaseImage = "cgr.dev/chainguard/static"
+
+func (KoBuild) buildDetails() {}
+
 type DockerComposeBuild struct {
 	// Service is the name of the Docker Compose service as defined in docker-compose.yaml.
 	Service string
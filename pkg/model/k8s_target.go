@@ -34,6 +34,24 @@ type K8sTarget struct {
 
 	PodReadinessMode PodReadinessMode
 
+	// By default, service mesh sidecars (like istio-proxy) don't block
+	// readiness. Set this for apps that need the mesh before they can serve.
+	WaitForSidecars bool
+
+	// Delete objects from the cluster when they're removed from the YAML,
+	// even if they were applied before Tilt restarted.
+	Prune bool
+
+	// Namespaces that the YAML may hardcode without the user's approval.
+	//
+	// Applying to any other namespace (besides the cluster's default)
+	// waits until the user approves it.
+	AllowedNamespaces []string
+
+	// Friendly local hostnames (e.g., myapp.localhost) that proxy
+	// to this resource's port forwards.
+	Hostnames []Hostname
+
 	// Map configRef -> number of times we (expect to) inject it.
 	// NOTE(maia): currently this map is only for use in metrics, though someday
 	// we want a better way of mapping configRefs -> their injection point(s)
@@ -152,3 +170,19 @@ func FilterLiveUpdateOnly(imageMapDeps []string, imageTargets []ImageTarget) []s
 	}
 	return result
 }
+
+// A hostname and path prefix served by a LocalIngress.
+type Hostname struct {
+	Host string
+
+	// The path prefix, e.g., /api. Defaults to "/".
+	Path string
+}
+
+func (h Hostname) URL() string {
+	path := h.Path
+	if path == "" {
+		path = "/"
+	}
+	return fmt.Sprintf("https://%s:%d%s", h.Host, v1alpha1.LocalIngressDefaultPort, path)
+}
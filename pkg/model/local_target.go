@@ -24,6 +24,11 @@ type LocalTarget struct {
 
 	ReadinessProbe *v1alpha1.Probe
 
+	// The URL of a service that Tilt doesn't run (e.g., a shared staging API),
+	// declared with external_resource(). Tilt polls its health with the
+	// ReadinessProbe instead of running a serve_cmd.
+	ExternalURL string
+
 	// Move this to CmdServerSpec when we move CmdServer to API
 	ServeCmdDisableSource *v1alpha1.DisableSource
 }
@@ -83,6 +88,15 @@ func (lt LocalTarget) WithReadinessProbe(probeSpec *v1alpha1.Probe) LocalTarget
 	return lt
 }
 
+func (lt LocalTarget) WithExternalURL(u string) LocalTarget {
+	lt.ExternalURL = u
+	return lt
+}
+
+func (lt LocalTarget) IsExternal() bool {
+	return lt.ExternalURL != ""
+}
+
 func (lt LocalTarget) ID() TargetID {
 	return TargetID{
 		Name: lt.Name,
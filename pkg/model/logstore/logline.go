@@ -69,10 +69,10 @@ func (b *logLineBuilder) buildSpaceLine(options logOptions) LogLine {
 	segment := b.segments[0]
 	spanID := segment.SpanID
 	time := segment.Time
-	if options.showManifestPrefix && span.ManifestName != "" {
+	if options.showManifestPrefix {
 		shouldSkip := options.skipFirstLineManifestPrefix && b.isFirstLine
 		if !shouldSkip {
-			sb.WriteString(SourcePrefix(span.ManifestName))
+			sb.WriteString(options.prefixFormat.Prefix(span.ManifestName, spanID, time))
 		}
 	}
 	sb.WriteString("\n")
@@ -93,20 +93,15 @@ func (b *logLineBuilder) buildMainLine(options logOptions) LogLine {
 	progressMustPrint := segment.Fields[logger.FieldNameProgressMustPrint] == "1"
 
 	sb := strings.Builder{}
-	if options.showManifestPrefix && span.ManifestName != "" {
+	if options.showManifestPrefix {
 		shouldSkip := options.skipFirstLineManifestPrefix && b.isFirstLine
 		if !shouldSkip {
-			sb.WriteString(SourcePrefix(span.ManifestName))
+			sb.WriteString(options.prefixFormat.Prefix(span.ManifestName, spanID, time))
 		}
 	}
 
 	if segment.Anchor {
-		// TODO(nick): Add Terminal colors when supported.
-		if segment.Level == logger.WarnLvl {
-			sb.WriteString("WARNING: ")
-		} else if segment.Level == logger.ErrorLvl {
-			sb.WriteString("ERROR: ")
-		}
+		sb.WriteString(options.prefixFormat.LevelLabel(segment.Level))
 	}
 
 	for _, segment := range b.segments {
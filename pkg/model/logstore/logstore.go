@@ -488,6 +488,7 @@ func (s *LogStore) ContinuingLinesWithOptions(checkpoint Checkpoint, opts LineOp
 		spans:                       spans,
 		showManifestPrefix:          !opts.SuppressPrefix,
 		skipFirstLineManifestPrefix: isSameSpanContinuation,
+		prefixFormat:                opts.PrefixFormat,
 	})
 
 	if isSameSpanContinuation {
@@ -592,6 +593,18 @@ func (s *LogStore) SpanLog(spanID SpanID) string {
 	return s.toLogString(logOptions{spans: spans})
 }
 
+// The range of checkpoints [from, to) covered by the span.
+//
+// Other spans may interleave logs inside this range.
+// Returns false if the span doesn't exist (or has been truncated away).
+func (s *LogStore) SpanRange(spanID SpanID) (from Checkpoint, to Checkpoint, ok bool) {
+	span, ok := s.spans[spanID]
+	if !ok {
+		return 0, 0, false
+	}
+	return s.checkpointFromIndex(span.FirstSegmentIndex), s.checkpointFromIndex(span.LastSegmentIndex + 1), true
+}
+
 func (s *LogStore) Warnings(spanID SpanID) []string {
 	spans, ok := s.idToSpanMap(spanID)
 	if !ok {
@@ -630,6 +643,24 @@ func (s *LogStore) ManifestLog(mn model.ManifestName) string {
 	return s.toLogString(logOptions{spans: spans})
 }
 
+// The log lines of a manifest that started at or after the given time.
+//
+// If since is zero, returns all the lines that we still have.
+func (s *LogStore) ManifestLinesSince(mn model.ManifestName, since time.Time) []LogLine {
+	lines := s.toLogLines(logOptions{spans: s.spansForManifest(mn)})
+	if since.IsZero() {
+		return lines
+	}
+
+	result := []LogLine{}
+	for _, line := range lines {
+		if !line.Time.Before(since) {
+			result = append(result, line)
+		}
+	}
+	return result
+}
+
 func (s *LogStore) startAndLastIndices(spans map[SpanID]*Span) (startIndex, lastIndex int) {
 	earliestStartIndex := -1
 	latestEndIndex := -1
@@ -655,11 +686,13 @@ type logOptions struct {
 	spans                       map[SpanID]*Span // only print logs for these spans
 	showManifestPrefix          bool
 	skipFirstLineManifestPrefix bool
+	prefixFormat                PrefixFormat
 }
 
 type LineOptions struct {
 	ManifestNames  model.ManifestNameSet // only print logs for these manifests
 	SuppressPrefix bool
+	PrefixFormat   PrefixFormat
 }
 
 func (s *LogStore) toLogString(options logOptions) string {
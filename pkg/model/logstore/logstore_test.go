@@ -3,12 +3,14 @@ package logstore
 import (
 	"fmt"
 	"os"
+	"regexp"
 	"runtime"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/tilt-dev/tilt/pkg/logger"
 	"github.com/tilt-dev/tilt/pkg/model"
@@ -318,6 +320,95 @@ func TestManifestLog(t *testing.T) {
 	assert.Equal(t, "a\nb\n", l.ManifestLog("back"))
 }
 
+func TestManifestLinesSince(t *testing.T) {
+	start := time.Now()
+	l := NewLogStore()
+	l.Append(newTestLogEvent("fe", start, "1\n2\n"), nil)
+	l.Append(newGlobalTestLogEvent("global\n"), nil)
+	l.Append(newTestLogEvent("fe", start.Add(time.Minute), "3\n"), nil)
+	l.Append(newTestLogEvent("back", start.Add(time.Minute), "a\n"), nil)
+
+	assert.Equal(t, "1\n2\n3\n", linesToString(l.ManifestLinesSince("fe", time.Time{})))
+	assert.Equal(t, "3\n", linesToString(l.ManifestLinesSince("fe", start.Add(time.Second))))
+	assert.Equal(t, "", linesToString(l.ManifestLinesSince("fe", start.Add(time.Hour))))
+	assert.Equal(t, "a\n", linesToString(l.ManifestLinesSince("back", start)))
+}
+
+func TestSearch(t *testing.T) {
+	l := NewLogStore()
+	l.Append(newTestLogEvent("fe", time.Now(), "starting\nerror: \x1b[31mboom\x1b[0m\nretrying\n"), nil)
+	l.Append(newTestLogEvent("back", time.Now(), "error: unrelated\n"), nil)
+	l.Append(newTestLogEvent("fe", time.Now(), "ok\n"), nil)
+
+	matches := l.Search(SearchOptions{Pattern: regexp.MustCompile("error: b.*m$"), Context: 1})
+	require.Len(t, matches, 1)
+	assert.Equal(t, model.ManifestName("fe"), matches[0].ManifestName)
+	assert.Equal(t, "error: boom", matches[0].Text)
+	assert.Equal(t, []string{"starting"}, matches[0].Before)
+	assert.Equal(t, []string{"retrying"}, matches[0].After)
+
+	matches = l.Search(SearchOptions{Pattern: regexp.MustCompile("^error")})
+	require.Len(t, matches, 2)
+	assert.Equal(t, model.ManifestName("back"), matches[1].ManifestName)
+	assert.Nil(t, matches[1].Before)
+
+	// Context lines come from the same resource.
+	matches = l.Search(SearchOptions{
+		Pattern:       regexp.MustCompile("retrying"),
+		ManifestNames: model.ManifestNameSet{"fe": true},
+		Context:       2,
+	})
+	require.Len(t, matches, 1)
+	assert.Equal(t, []string{"starting", "error: boom"}, matches[0].Before)
+	assert.Equal(t, []string{"ok"}, matches[0].After)
+
+	matches = l.Search(SearchOptions{
+		Pattern:       regexp.MustCompile("error"),
+		ManifestNames: model.ManifestNameSet{"back": true},
+	})
+	require.Len(t, matches, 1)
+	assert.Equal(t, "error: unrelated", matches[0].Text)
+
+	assert.Len(t, l.Search(SearchOptions{Pattern: regexp.MustCompile("error"), SpanID: "nonexistent"}), 0)
+}
+
+func TestSegmentsSince(t *testing.T) {
+	l := NewLogStore()
+	l.Append(newTestLogEvent("fe", time.Now(), "starting\n"), nil)
+	warn := newTestLogEvent("be", time.Now(), "low disk\n")
+	warn.level = logger.WarnLvl
+	l.Append(warn, nil)
+	l.Append(newGlobalTestLogEvent("global\n"), nil)
+
+	segments, c := l.SegmentsSince(0, SegmentOptions{})
+	require.Len(t, segments, 3)
+	assert.Equal(t, l.Checkpoint(), c)
+	assert.Equal(t, model.ManifestName("fe"), segments[0].ManifestName)
+	assert.Equal(t, "starting\n", segments[0].Text)
+	assert.Equal(t, logger.InfoLvl, segments[0].Level)
+	assert.Equal(t, model.ManifestName(""), segments[2].ManifestName)
+
+	segments, _ = l.SegmentsSince(0, SegmentOptions{MinLevel: logger.WarnLvl})
+	require.Len(t, segments, 1)
+	assert.Equal(t, "low disk\n", segments[0].Text)
+
+	segments, _ = l.SegmentsSince(0, SegmentOptions{ManifestNames: model.ManifestNameSet{"fe": true}})
+	require.Len(t, segments, 1)
+	assert.Equal(t, SpanID("fe"), segments[0].SpanID)
+
+	segments, _ = l.SegmentsSince(0, SegmentOptions{SpanID: "be"})
+	require.Len(t, segments, 1)
+
+	l.Append(newTestLogEvent("fe", time.Now(), "done\n"), nil)
+	segments, c2 := l.SegmentsSince(c, SegmentOptions{})
+	require.Len(t, segments, 1)
+	assert.Equal(t, "done\n", segments[0].Text)
+	assert.Equal(t, c+1, c2)
+
+	segments, _ = l.SegmentsSince(c2, SegmentOptions{})
+	assert.Empty(t, segments)
+}
+
 func TestManifestLogContinuation(t *testing.T) {
 	l := NewLogStore()
 	l.Append(newGlobalTestLogEvent("1\n2\n"), nil)
@@ -395,6 +486,22 @@ func TestWarnings(t *testing.T) {
 	assertSnapshot(t, l.String())
 }
 
+func TestSpanRange(t *testing.T) {
+	l := NewLogStore()
+	l.Append(newTestLogEvent("be", time.Now(), "be 1\n"), nil)
+	l.Append(newTestLogEvent("fe", time.Now(), "fe 1\n"), nil)
+	l.Append(newTestLogEvent("be", time.Now(), "be 2\n"), nil)
+	l.Append(newTestLogEvent("fe", time.Now(), "fe 2\n"), nil)
+
+	from, to, ok := l.SpanRange("fe")
+	require.True(t, ok)
+	assert.Equal(t, Checkpoint(1), from)
+	assert.Equal(t, Checkpoint(4), to)
+
+	_, _, ok = l.SpanRange("nonexistent")
+	assert.False(t, ok)
+}
+
 func TestErrors(t *testing.T) {
 	l := NewLogStore()
 	l.Append(testLogEvent{
@@ -2,21 +2,213 @@ package logstore
 
 import (
 	"fmt"
+	"hash/fnv"
 	"strings"
+	"time"
 
+	"github.com/mattn/go-runewidth"
+
+	"github.com/tilt-dev/tilt/pkg/logger"
 	"github.com/tilt-dev/tilt/pkg/model"
 )
 
+// The default width of the resource name column.
+const DefaultPrefixWidth = 13
+
+const minPrefixWidth = 4
+const maxPrefixWidth = 64
+
+// How to shorten resource names that don't fit in the prefix column.
+type PrefixTruncation string
+
+const (
+	PrefixTruncateEnd    PrefixTruncation = "end"    // "my-long-servi…"
+	PrefixTruncateMiddle PrefixTruncation = "middle" // "my-long…rvice"
+	PrefixTruncateStart  PrefixTruncation = "start"  // "…-long-service"
+)
+
+// Colors for the log prefix.
+type PrefixTheme string
+
+const (
+	PrefixThemeNone  PrefixTheme = "none"
+	PrefixThemeDark  PrefixTheme = "dark"  // For terminals with a dark background.
+	PrefixThemeLight PrefixTheme = "light" // For terminals with a light background.
+)
+
+// Which log streams get a timestamp.
+type TimestampMode string
+
+const (
+	TimestampsOff     TimestampMode = "off"
+	TimestampsAll     TimestampMode = "all"
+	TimestampsBuild   TimestampMode = "build"   // Only logs from builds and Tiltfile loads.
+	TimestampsRuntime TimestampMode = "runtime" // Only logs from running resources.
+)
+
+var AllPrefixTruncations = []PrefixTruncation{PrefixTruncateEnd, PrefixTruncateMiddle, PrefixTruncateStart}
+var AllPrefixThemes = []PrefixTheme{PrefixThemeNone, PrefixThemeDark, PrefixThemeLight}
+var AllTimestampModes = []TimestampMode{TimestampsOff, TimestampsAll, TimestampsBuild, TimestampsRuntime}
+
+// Resource name colors, as ANSI SGR codes. We avoid red, which we use for errors.
+var darkThemeColors = []string{"36", "32", "33", "35", "94", "96", "92", "95"}
+var lightThemeColors = []string{"34", "35", "36", "32", "94", "95"}
+
+const (
+	ansiReset  = "\x1b[0m"
+	ansiDim    = "2"
+	ansiYellow = "33"
+	ansiRed    = "31"
+)
+
+// PrefixFormat controls the prefix at the start of each log line
+// when we print logs to the terminal.
+//
+// The zero value is the default format: a 13-column resource name,
+// truncated at the end, without colors or timestamps.
+type PrefixFormat struct {
+	Width      int              `json:"width,omitempty"`
+	Truncation PrefixTruncation `json:"truncation,omitempty"`
+	Theme      PrefixTheme      `json:"theme,omitempty"`
+	Timestamps TimestampMode    `json:"timestamps,omitempty"`
+}
+
+func (f PrefixFormat) Validate() error {
+	if f.Width != 0 && (f.Width < minPrefixWidth || f.Width > maxPrefixWidth) {
+		return fmt.Errorf("prefix width must be between %d and %d, got %d", minPrefixWidth, maxPrefixWidth, f.Width)
+	}
+	if f.Truncation != "" && !containsString(AllPrefixTruncations, f.Truncation) {
+		return fmt.Errorf("invalid prefix truncation %q. Must be one of: %v", f.Truncation, AllPrefixTruncations)
+	}
+	if f.Theme != "" && !containsString(AllPrefixThemes, f.Theme) {
+		return fmt.Errorf("invalid theme %q. Must be one of: %v", f.Theme, AllPrefixThemes)
+	}
+	if f.Timestamps != "" && !containsString(AllTimestampModes, f.Timestamps) {
+		return fmt.Errorf("invalid timestamps mode %q. Must be one of: %v", f.Timestamps, AllTimestampModes)
+	}
+	return nil
+}
+
+func containsString[T ~string](list []T, s T) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
 func SourcePrefix(n model.ManifestName) string {
+	return PrefixFormat{}.Prefix(n, "", time.Time{})
+}
+
+// The prefix for a log line from the given resource and span.
+func (f PrefixFormat) Prefix(n model.ManifestName, spanID SpanID, t time.Time) string {
+	sb := strings.Builder{}
+	if !t.IsZero() && f.showTimestamp(spanID) {
+		sb.WriteString(f.colorize(ansiDim, t.Local().Format("15:04:05")))
+		sb.WriteString(" ")
+	}
+
 	if n == "" || n == model.MainTiltfileManifestName {
+		return sb.String()
+	}
+
+	sb.WriteString(f.colorize(f.nameColor(n), f.fit(string(n))))
+	sb.WriteString(" │ ")
+	return sb.String()
+}
+
+// The label at the start of a warning or error.
+func (f PrefixFormat) LevelLabel(level logger.Level) string {
+	if level == logger.WarnLvl {
+		return f.colorize(ansiYellow, "WARNING:") + " "
+	} else if level == logger.ErrorLvl {
+		return f.colorize(ansiRed, "ERROR:") + " "
+	}
+	return ""
+}
+
+func (f PrefixFormat) showTimestamp(spanID SpanID) bool {
+	switch f.Timestamps {
+	case TimestampsAll:
+		return true
+	case TimestampsBuild:
+		return isBuildSpan(spanID)
+	case TimestampsRuntime:
+		return !isBuildSpan(spanID)
+	}
+	return false
+}
+
+func isBuildSpan(spanID SpanID) bool {
+	s := string(spanID)
+	return strings.HasPrefix(s, "build:") || strings.HasPrefix(s, "tiltfile:")
+}
+
+func (f PrefixFormat) colorize(color, s string) string {
+	if color == "" || f.Theme == "" || f.Theme == PrefixThemeNone {
+		return s
+	}
+	return "\x1b[" + color + "m" + s + ansiReset
+}
+
+// Each resource gets a stable color, so that its logs are easy to pick out.
+func (f PrefixFormat) nameColor(n model.ManifestName) string {
+	var colors []string
+	switch f.Theme {
+	case PrefixThemeDark:
+		colors = darkThemeColors
+	case PrefixThemeLight:
+		colors = lightThemeColors
+	default:
 		return ""
 	}
-	max := 13
-	spaces := ""
-	if len(n) > max {
-		n = n[:max-1] + "…"
-	} else {
-		spaces = strings.Repeat(" ", max-len(n))
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(n))
+	return colors[h.Sum32()%uint32(len(colors))]
+}
+
+// Right-aligns the name in the prefix column, truncating it if it doesn't fit.
+//
+// Measures the name in terminal columns rather than bytes, so that names
+// with wide or multi-byte characters line up.
+func (f PrefixFormat) fit(name string) string {
+	width := f.Width
+	if width == 0 {
+		width = DefaultPrefixWidth
+	}
+
+	nameWidth := runewidth.StringWidth(name)
+	if nameWidth <= width {
+		return strings.Repeat(" ", width-nameWidth) + name
+	}
+
+	const ellipsis = "…"
+	switch f.Truncation {
+	case PrefixTruncateStart:
+		return ellipsis + truncateLeft(name, width-1)
+	case PrefixTruncateMiddle:
+		tailWidth := (width - 1) / 2
+		headWidth := width - 1 - tailWidth
+		return runewidth.Truncate(name, headWidth, "") + ellipsis + truncateLeft(name, tailWidth)
+	default:
+		return runewidth.Truncate(name, width, ellipsis)
+	}
+}
+
+// Returns the longest suffix of s that fits in w columns.
+func truncateLeft(s string, w int) string {
+	runes := []rune(s)
+	width := 0
+	i := len(runes)
+	for i > 0 {
+		rw := runewidth.RuneWidth(runes[i-1])
+		if width+rw > w {
+			break
+		}
+		width += rw
+		i--
 	}
-	return fmt.Sprintf("%s%s │ ", spaces, n)
+	return string(runes[i:])
 }
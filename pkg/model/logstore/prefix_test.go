@@ -0,0 +1,93 @@
+package logstore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestSourcePrefix(t *testing.T) {
+	assert.Equal(t, "", SourcePrefix(""))
+	assert.Equal(t, "", SourcePrefix(model.MainTiltfileManifestName))
+	assert.Equal(t, "           fe │ ", SourcePrefix("fe"))
+	assert.Equal(t, "my-long-serv… │ ", SourcePrefix("my-long-service"))
+}
+
+func TestPrefixTruncation(t *testing.T) {
+	name := model.ManifestName("my-long-service")
+	cases := []struct {
+		truncation PrefixTruncation
+		expected   string
+	}{
+		{"", "my-long-… │ "},
+		{PrefixTruncateEnd, "my-long-… │ "},
+		{PrefixTruncateStart, "…-service │ "},
+		{PrefixTruncateMiddle, "my-l…vice │ "},
+	}
+	for _, c := range cases {
+		t.Run(string(c.truncation), func(t *testing.T) {
+			f := PrefixFormat{Width: 9, Truncation: c.truncation}
+			assert.Equal(t, c.expected, f.Prefix(name, "", time.Time{}))
+		})
+	}
+}
+
+func TestPrefixWideCharacters(t *testing.T) {
+	f := PrefixFormat{Width: 6}
+
+	// Each of these characters takes up two columns.
+	assert.Equal(t, "  日本 │ ", f.Prefix("日本", "", time.Time{}))
+	assert.Equal(t, "日本… │ ", f.Prefix("日本語サービス", "", time.Time{}))
+}
+
+func TestPrefixTheme(t *testing.T) {
+	f := PrefixFormat{Width: 4, Theme: PrefixThemeDark}
+	prefix := f.Prefix("fe", "", time.Time{})
+	assert.Regexp(t, "^\x1b\\[\\d+m  fe\x1b\\[0m │ $", prefix)
+
+	// Colors are stable.
+	assert.Equal(t, prefix, f.Prefix("fe", "", time.Time{}))
+
+	assert.Equal(t, "\x1b[31mERROR:\x1b[0m ", f.LevelLabel(logger.ErrorLvl))
+	assert.Equal(t, "ERROR: ", PrefixFormat{}.LevelLabel(logger.ErrorLvl))
+}
+
+func TestPrefixTimestamps(t *testing.T) {
+	ts := time.Date(2021, 1, 2, 3, 4, 5, 0, time.Local)
+	build := PrefixFormat{Width: 4, Timestamps: TimestampsBuild}
+	assert.Equal(t, "03:04:05   fe │ ", build.Prefix("fe", "build:1", ts))
+	assert.Equal(t, "  fe │ ", build.Prefix("fe", "pod:fe:1", ts))
+	assert.Equal(t, "03:04:05 ", build.Prefix(model.MainTiltfileManifestName, "tiltfile:(Tiltfile):1", ts))
+
+	runtime := PrefixFormat{Width: 4, Timestamps: TimestampsRuntime}
+	assert.Equal(t, "  fe │ ", runtime.Prefix("fe", "build:1", ts))
+	assert.Equal(t, "03:04:05   fe │ ", runtime.Prefix("fe", "pod:fe:1", ts))
+}
+
+func TestPrefixFormatValidate(t *testing.T) {
+	assert.NoError(t, PrefixFormat{}.Validate())
+	assert.NoError(t, PrefixFormat{Width: 20, Truncation: PrefixTruncateStart, Theme: PrefixThemeLight, Timestamps: TimestampsAll}.Validate())
+	assert.Error(t, PrefixFormat{Width: 2}.Validate())
+	assert.Error(t, PrefixFormat{Truncation: "sideways"}.Validate())
+	assert.Error(t, PrefixFormat{Theme: "neon"}.Validate())
+	assert.Error(t, PrefixFormat{Timestamps: "sometimes"}.Validate())
+}
+
+func TestContinuingLinesWithPrefixFormat(t *testing.T) {
+	l := NewLogStore()
+	c1 := l.Checkpoint()
+
+	now := time.Now()
+	l.Append(testLogEvent{name: "my-long-service", message: "hello\n", ts: now}, nil)
+
+	lines := l.ContinuingLinesWithOptions(c1, LineOptions{
+		PrefixFormat: PrefixFormat{Width: 9, Truncation: PrefixTruncateStart},
+	})
+	assert.Equal(t, []LogLine{
+		LogLine{Text: "…-service │ hello\n", SpanID: "my-long-service", Time: now},
+	}, lines)
+}
@@ -0,0 +1,122 @@
+package logstore
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// Matches ANSI escape sequences, so that colors don't break up the text we search.
+var ansiEscapeRe = regexp.MustCompile(`\x1b\[[0-9;?]*[a-zA-Z]`)
+
+type SearchOptions struct {
+	// The pattern to search for.
+	Pattern *regexp.Regexp
+
+	// If present, only search the logs of these manifests.
+	ManifestNames model.ManifestNameSet
+
+	// If present, only search the logs of this span.
+	SpanID SpanID
+
+	// How many lines before and after each match to include.
+	//
+	// Context lines always come from the same manifest as the match.
+	Context int
+}
+
+type SearchMatch struct {
+	ManifestName model.ManifestName
+	SpanID       SpanID
+	Time         time.Time
+
+	// The matching line, without colors or the trailing newline.
+	Text string
+
+	// The lines of the same manifest before and after the match.
+	Before []string
+	After  []string
+}
+
+// Searches the log lines that we still have, in order.
+func (s *LogStore) Search(opts SearchOptions) []SearchMatch {
+	if opts.Pattern == nil {
+		return nil
+	}
+
+	spans := s.spans
+	if opts.SpanID != "" {
+		var ok bool
+		spans, ok = s.idToSpanMap(opts.SpanID)
+		if !ok {
+			return nil
+		}
+	}
+	if len(opts.ManifestNames) != 0 {
+		filtered := make(map[SpanID]*Span, len(spans))
+		for spanID, span := range spans {
+			if opts.ManifestNames[span.ManifestName] {
+				filtered[spanID] = span
+			}
+		}
+		spans = filtered
+	}
+
+	lines := s.toLogLines(logOptions{spans: spans})
+
+	// Group the lines by manifest, so that the context of a match
+	// doesn't include lines from other resources.
+	texts := make([]string, len(lines))
+	manifestNames := make([]model.ManifestName, len(lines))
+	positions := make([]int, len(lines))
+	linesByManifest := make(map[model.ManifestName][]int)
+	for i, line := range lines {
+		texts[i] = SearchableText(line.Text)
+		if span, ok := s.spans[line.SpanID]; ok {
+			manifestNames[i] = span.ManifestName
+		}
+		positions[i] = len(linesByManifest[manifestNames[i]])
+		linesByManifest[manifestNames[i]] = append(linesByManifest[manifestNames[i]], i)
+	}
+
+	result := []SearchMatch{}
+	for i, line := range lines {
+		if !opts.Pattern.MatchString(texts[i]) {
+			continue
+		}
+
+		match := SearchMatch{
+			ManifestName: manifestNames[i],
+			SpanID:       line.SpanID,
+			Time:         line.Time,
+			Text:         texts[i],
+		}
+		if opts.Context > 0 {
+			indices := linesByManifest[manifestNames[i]]
+			pos := positions[i]
+			start := pos - opts.Context
+			if start < 0 {
+				start = 0
+			}
+			end := pos + 1 + opts.Context
+			if end > len(indices) {
+				end = len(indices)
+			}
+			for _, index := range indices[start:pos] {
+				match.Before = append(match.Before, texts[index])
+			}
+			for _, index := range indices[pos+1 : end] {
+				match.After = append(match.After, texts[index])
+			}
+		}
+		result = append(result, match)
+	}
+	return result
+}
+
+// The text of a log line as we search it, without colors or the trailing newline.
+func SearchableText(text string) string {
+	return strings.TrimRight(ansiEscapeRe.ReplaceAllString(text, ""), "\r\n")
+}
@@ -0,0 +1,69 @@
+package logstore
+
+import (
+	"time"
+
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+type SegmentOptions struct {
+	// If present, only include the segments of these manifests.
+	ManifestNames model.ManifestNameSet
+
+	// If present, only include the segments of this span.
+	SpanID SpanID
+
+	// If present, only include segments at this level or above.
+	// Segments without a level count as info.
+	MinLevel logger.Level
+}
+
+type Segment struct {
+	ManifestName model.ManifestName
+	SpanID       SpanID
+	Time         time.Time
+	Level        logger.Level
+
+	// The raw text of the segment. It may be part of a line,
+	// and may contain colors.
+	Text string
+}
+
+// Returns the segments added since the checkpoint, and a checkpoint to continue from.
+//
+// Unlike ContinuingLines, segments aren't joined into lines, so that streaming
+// clients see logs as soon as they're written.
+func (s *LogStore) SegmentsSince(c Checkpoint, opts SegmentOptions) ([]Segment, Checkpoint) {
+	result := []Segment{}
+	for _, seg := range s.segments[s.checkpointToIndex(c):] {
+		if opts.SpanID != "" && seg.SpanID != opts.SpanID {
+			continue
+		}
+
+		var mn model.ManifestName
+		if span, ok := s.spans[seg.SpanID]; ok {
+			mn = span.ManifestName
+		}
+		if len(opts.ManifestNames) != 0 && !opts.ManifestNames[mn] {
+			continue
+		}
+
+		level := seg.Level
+		if level == logger.NoneLvl {
+			level = logger.InfoLvl
+		}
+		if !opts.MinLevel.ShouldDisplay(level) {
+			continue
+		}
+
+		result = append(result, Segment{
+			ManifestName: mn,
+			SpanID:       seg.SpanID,
+			Time:         seg.Time,
+			Level:        level,
+			Text:         string(seg.Text),
+		})
+	}
+	return result, s.Checkpoint()
+}
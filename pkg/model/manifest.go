@@ -63,6 +63,9 @@ type Manifest struct {
 	SourceTiltfile ManifestName
 
 	Labels map[string]string
+
+	// Sources outside of Tilt that can trigger an update of this manifest.
+	ExternalTriggers []ExternalTrigger
 }
 
 func (m Manifest) ID() TargetID {
@@ -162,6 +165,13 @@ func (m Manifest) PodReadinessMode() PodReadinessMode {
 	return PodReadinessNone
 }
 
+func (m Manifest) WaitForSidecars() bool {
+	if k8sTarget, ok := m.DeployTarget.(K8sTarget); ok {
+		return k8sTarget.WaitForSidecars
+	}
+	return false
+}
+
 func (m Manifest) WithDeployTarget(t TargetSpec) Manifest {
 	switch typedTarget := t.(type) {
 	case K8sTarget:
@@ -232,6 +242,20 @@ func (m Manifest) WithLabels(labels map[string]string) Manifest {
 	return m
 }
 
+func (m Manifest) WithExternalTriggers(triggers []ExternalTrigger) Manifest {
+	m.ExternalTriggers = append([]ExternalTrigger{}, triggers...)
+	return m
+}
+
+func (m Manifest) HasExternalTrigger(name string) bool {
+	for _, t := range m.ExternalTriggers {
+		if t.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
 func (m Manifest) Validate() error {
 	if m.Name == "" {
 		return fmt.Errorf("[validate] manifest missing name: %+v", m)
@@ -263,6 +287,9 @@ func (m *Manifest) ClusterName() string {
 		return v1alpha1.ClusterNameDocker
 	}
 	if m.IsK8s() {
+		if cluster := m.K8sTarget().Cluster; cluster != "" {
+			return cluster
+		}
 		return v1alpha1.ClusterNameDefault
 	}
 	return ""
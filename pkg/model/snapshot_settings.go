@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// How long a link to an uploaded snapshot stays valid.
+//
+// S3 caps presigned URLs at 7 days.
+const SnapshotDefaultLinkExpiry = 7 * 24 * time.Hour
+
+type SnapshotSettings struct {
+	// Where to upload snapshots, e.g. s3://my-bucket/tilt-snapshots or gs://my-bucket.
+	//
+	// Empty means use the TILT_SNAPSHOT_STORAGE_URL environment variable.
+	StorageURL string
+
+	// How long the link to an uploaded snapshot stays valid.
+	LinkExpiry time.Duration
+}
+
+func DefaultSnapshotSettings() SnapshotSettings {
+	return SnapshotSettings{LinkExpiry: SnapshotDefaultLinkExpiry}
+}
@@ -13,6 +13,7 @@ const (
 type UpdateSettings struct {
 	maxParallelUpdates int           // max number of updates to run concurrently
 	k8sUpsertTimeout   time.Duration // timeout for k8s upsert operations
+	k8sJobRetention    time.Duration // how long to keep completed jobs; 0 keeps them forever
 
 	// A list of images to suppress the warning for.
 	SuppressUnusedImageWarnings []string
@@ -52,6 +53,21 @@ func (us UpdateSettings) WithK8sUpsertTimeout(timeout time.Duration) UpdateSetti
 	return us
 }
 
+// How long to keep Jobs that Tilt created after they complete successfully.
+//
+// Zero means that Tilt never deletes completed Jobs.
+func (us UpdateSettings) K8sJobRetention() time.Duration {
+	if us.k8sJobRetention < 0 {
+		return 0
+	}
+	return us.k8sJobRetention
+}
+
+func (us UpdateSettings) WithK8sJobRetention(retention time.Duration) UpdateSettings {
+	us.k8sJobRetention = retention
+	return us
+}
+
 func DefaultUpdateSettings() UpdateSettings {
 	return UpdateSettings{
 		maxParallelUpdates: DefaultMaxParallelUpdates,
@@ -2,4 +2,7 @@ package model
 
 type VersionSettings struct {
 	CheckUpdates bool
+
+	// Opt-in to comparing the running version against the latest release.
+	CheckLatestRelease bool
 }
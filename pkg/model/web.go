@@ -94,6 +94,14 @@ type WebPort int
 type WebDevPort int
 type WebURL url.URL
 
+// The token that clients must send to the web server's API.
+// Empty if the API doesn't require one.
+type WebAuthToken string
+
+// The query parameter that logs a browser in to the web server
+// with the auth token.
+const WebAuthTokenParam = "token"
+
 func (u WebURL) String() string {
 	url := (*url.URL)(&u)
 	return url.String()
@@ -102,3 +110,14 @@ func (u WebURL) String() string {
 func (u WebURL) Empty() bool {
 	return WebURL{} == u
 }
+
+// The URL that logs a browser in with the given token, if any.
+func (u WebURL) WithAuthToken(token WebAuthToken) WebURL {
+	if token == "" {
+		return u
+	}
+	query := (*url.URL)(&u).Query()
+	query.Set(WebAuthTokenParam, string(token))
+	u.RawQuery = query.Encode()
+	return u
+}
@@ -31,6 +31,7 @@ import (
 
 func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenAPIDefinition {
 	return map[string]common.OpenAPIDefinition{
+		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.BuildKitConnection":                schema_pkg_apis_core_v1alpha1_BuildKitConnection(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.Cluster":                           schema_pkg_apis_core_v1alpha1_Cluster(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ClusterConnection":                 schema_pkg_apis_core_v1alpha1_ClusterConnection(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ClusterConnectionStatus":           schema_pkg_apis_core_v1alpha1_ClusterConnectionStatus(ref),
@@ -65,17 +66,20 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DisableSource":                     schema_pkg_apis_core_v1alpha1_DisableSource(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DisableStatus":                     schema_pkg_apis_core_v1alpha1_DisableStatus(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerClusterConnection":           schema_pkg_apis_core_v1alpha1_DockerClusterConnection(ref),
+		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerComposeFileObject":           schema_pkg_apis_core_v1alpha1_DockerComposeFileObject(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerComposeLogStream":            schema_pkg_apis_core_v1alpha1_DockerComposeLogStream(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerComposeLogStreamList":        schema_pkg_apis_core_v1alpha1_DockerComposeLogStreamList(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerComposeLogStreamSpec":        schema_pkg_apis_core_v1alpha1_DockerComposeLogStreamSpec(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerComposeLogStreamStatus":      schema_pkg_apis_core_v1alpha1_DockerComposeLogStreamStatus(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerComposeProject":              schema_pkg_apis_core_v1alpha1_DockerComposeProject(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerComposeService":              schema_pkg_apis_core_v1alpha1_DockerComposeService(ref),
+		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerComposeServiceDependency":    schema_pkg_apis_core_v1alpha1_DockerComposeServiceDependency(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerComposeServiceList":          schema_pkg_apis_core_v1alpha1_DockerComposeServiceList(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerComposeServiceSpec":          schema_pkg_apis_core_v1alpha1_DockerComposeServiceSpec(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerComposeServiceStatus":        schema_pkg_apis_core_v1alpha1_DockerComposeServiceStatus(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerContainerState":              schema_pkg_apis_core_v1alpha1_DockerContainerState(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerImage":                       schema_pkg_apis_core_v1alpha1_DockerImage(ref),
+		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerImageLayer":                  schema_pkg_apis_core_v1alpha1_DockerImageLayer(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerImageList":                   schema_pkg_apis_core_v1alpha1_DockerImageList(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerImageSpec":                   schema_pkg_apis_core_v1alpha1_DockerImageSpec(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerImageStageStatus":            schema_pkg_apis_core_v1alpha1_DockerImageStageStatus(ref),
@@ -83,7 +87,10 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerImageStateCompleted":         schema_pkg_apis_core_v1alpha1_DockerImageStateCompleted(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerImageStateWaiting":           schema_pkg_apis_core_v1alpha1_DockerImageStateWaiting(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerImageStatus":                 schema_pkg_apis_core_v1alpha1_DockerImageStatus(ref),
+		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerNetworkAttachment":           schema_pkg_apis_core_v1alpha1_DockerNetworkAttachment(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerPortBinding":                 schema_pkg_apis_core_v1alpha1_DockerPortBinding(ref),
+		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerPruneRun":                    schema_pkg_apis_core_v1alpha1_DockerPruneRun(ref),
+		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerPruneStatus":                 schema_pkg_apis_core_v1alpha1_DockerPruneStatus(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ExecAction":                        schema_pkg_apis_core_v1alpha1_ExecAction(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.Extension":                         schema_pkg_apis_core_v1alpha1_Extension(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ExtensionList":                     schema_pkg_apis_core_v1alpha1_ExtensionList(ref),
@@ -112,13 +119,19 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ImageMapStatus":                    schema_pkg_apis_core_v1alpha1_ImageMapStatus(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesApply":                   schema_pkg_apis_core_v1alpha1_KubernetesApply(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesApplyCmd":                schema_pkg_apis_core_v1alpha1_KubernetesApplyCmd(ref),
+		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesApplyDiff":               schema_pkg_apis_core_v1alpha1_KubernetesApplyDiff(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesApplyList":               schema_pkg_apis_core_v1alpha1_KubernetesApplyList(ref),
+		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesApplyObjectDiff":         schema_pkg_apis_core_v1alpha1_KubernetesApplyObjectDiff(ref),
+		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesApplyRevision":           schema_pkg_apis_core_v1alpha1_KubernetesApplyRevision(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesApplySpec":               schema_pkg_apis_core_v1alpha1_KubernetesApplySpec(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesApplyStatus":             schema_pkg_apis_core_v1alpha1_KubernetesApplyStatus(ref),
+		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesApplyWaitFor":            schema_pkg_apis_core_v1alpha1_KubernetesApplyWaitFor(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesClusterConnection":       schema_pkg_apis_core_v1alpha1_KubernetesClusterConnection(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesClusterConnectionStatus": schema_pkg_apis_core_v1alpha1_KubernetesClusterConnectionStatus(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesDiscovery":               schema_pkg_apis_core_v1alpha1_KubernetesDiscovery(ref),
+		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesDiscoveryEvent":          schema_pkg_apis_core_v1alpha1_KubernetesDiscoveryEvent(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesDiscoveryList":           schema_pkg_apis_core_v1alpha1_KubernetesDiscoveryList(ref),
+		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesDiscoveryNamespaceError": schema_pkg_apis_core_v1alpha1_KubernetesDiscoveryNamespaceError(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesDiscoverySpec":           schema_pkg_apis_core_v1alpha1_KubernetesDiscoverySpec(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesDiscoveryStateRunning":   schema_pkg_apis_core_v1alpha1_KubernetesDiscoveryStateRunning(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesDiscoveryStateWaiting":   schema_pkg_apis_core_v1alpha1_KubernetesDiscoveryStateWaiting(ref),
@@ -140,6 +153,17 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.LiveUpdateStateFailed":             schema_pkg_apis_core_v1alpha1_LiveUpdateStateFailed(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.LiveUpdateStatus":                  schema_pkg_apis_core_v1alpha1_LiveUpdateStatus(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.LiveUpdateSync":                    schema_pkg_apis_core_v1alpha1_LiveUpdateSync(ref),
+		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.LocalIngress":                      schema_pkg_apis_core_v1alpha1_LocalIngress(ref),
+		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.LocalIngressList":                  schema_pkg_apis_core_v1alpha1_LocalIngressList(ref),
+		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.LocalIngressRoute":                 schema_pkg_apis_core_v1alpha1_LocalIngressRoute(ref),
+		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.LocalIngressRouteStatus":           schema_pkg_apis_core_v1alpha1_LocalIngressRouteStatus(ref),
+		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.LocalIngressSpec":                  schema_pkg_apis_core_v1alpha1_LocalIngressSpec(ref),
+		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.LocalIngressStatus":                schema_pkg_apis_core_v1alpha1_LocalIngressStatus(ref),
+		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.NestedTilt":                        schema_pkg_apis_core_v1alpha1_NestedTilt(ref),
+		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.NestedTiltList":                    schema_pkg_apis_core_v1alpha1_NestedTiltList(ref),
+		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.NestedTiltSpec":                    schema_pkg_apis_core_v1alpha1_NestedTiltSpec(ref),
+		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.NestedTiltStatus":                  schema_pkg_apis_core_v1alpha1_NestedTiltStatus(ref),
+		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ObjectFieldSource":                 schema_pkg_apis_core_v1alpha1_ObjectFieldSource(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ObjectSelector":                    schema_pkg_apis_core_v1alpha1_ObjectSelector(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.Pod":                               schema_pkg_apis_core_v1alpha1_Pod(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.PodCondition":                      schema_pkg_apis_core_v1alpha1_PodCondition(ref),
@@ -213,6 +237,8 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.UIResourceStatus":                  schema_pkg_apis_core_v1alpha1_UIResourceStatus(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.UIResourceTargetSpec":              schema_pkg_apis_core_v1alpha1_UIResourceTargetSpec(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.UISession":                         schema_pkg_apis_core_v1alpha1_UISession(ref),
+		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.UISessionCrash":                    schema_pkg_apis_core_v1alpha1_UISessionCrash(ref),
+		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.UISessionIdleStatus":               schema_pkg_apis_core_v1alpha1_UISessionIdleStatus(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.UISessionList":                     schema_pkg_apis_core_v1alpha1_UISessionList(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.UISessionSpec":                     schema_pkg_apis_core_v1alpha1_UISessionSpec(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.UISessionStatus":                   schema_pkg_apis_core_v1alpha1_UISessionStatus(ref),
@@ -274,6 +300,56 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 	}
 }
 
+func schema_pkg_apis_core_v1alpha1_BuildKitConnection(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "Connection spec for a remote BuildKit daemon.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"address": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The address of the BuildKit daemon.\n\ntcp://host:port and unix:///path/to/buildkitd.sock connect directly.\n\nkube-pod://pod-name connects to a buildkitd pod through `kubectl exec`, like the kubernetes driver of `docker buildx`. The namespace, container, and kubeconfig context can be set as query parameters (e.g., kube-pod://buildkitd-0?namespace=buildkit). The context defaults to the Cluster's context.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"serverName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The name to verify the daemon's TLS certificate against.\n\nIf not specified, will use the host in the address.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"caCert": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Path to the CA certificate that signed the daemon's certificate.\n\nIf specified, connects over TLS.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"cert": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Path to the client certificate, for daemons that require mutual TLS.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"key": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Path to the client key, for daemons that require mutual TLS.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"address"},
+			},
+		},
+	}
+}
+
 func schema_pkg_apis_core_v1alpha1_Cluster(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -437,11 +513,17 @@ func schema_pkg_apis_core_v1alpha1_ClusterSpec(ref common.ReferenceCallback) com
 							Ref:         ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.RegistryHosting"),
 						},
 					},
+					"buildKit": {
+						SchemaProps: spec.SchemaProps{
+							Description: "BuildKit determines where images for this Cluster should be built.\n\nIf specified, images are built on a remote BuildKit daemon instead of the local Docker daemon, and BuildKit pushes them to the registry as part of the build.\n\nOnly supported for Kubernetes clusters.",
+							Ref:         ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.BuildKitConnection"),
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ClusterConnection", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.RegistryHosting"},
+			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.BuildKitConnection", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ClusterConnection", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.RegistryHosting"},
 	}
 }
 
@@ -491,11 +573,31 @@ func schema_pkg_apis_core_v1alpha1_ClusterStatus(ref common.ReferenceCallback) c
 							Format:      "",
 						},
 					},
+					"dockerPrune": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The most recent runs of the Docker pruner against this cluster's container runtime.",
+							Ref:         ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerPruneStatus"),
+						},
+					},
+					"conditions": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Conditions describe the health of the connection, based on Tilt's periodic checks against the cluster.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("k8s.io/apimachinery/pkg/apis/meta/v1.Condition"),
+									},
+								},
+							},
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ClusterConnectionStatus", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.RegistryHosting", "k8s.io/apimachinery/pkg/apis/meta/v1.MicroTime"},
+			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ClusterConnectionStatus", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerPruneStatus", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.RegistryHosting", "k8s.io/apimachinery/pkg/apis/meta/v1.Condition", "k8s.io/apimachinery/pkg/apis/meta/v1.MicroTime"},
 	}
 }
 
@@ -1714,6 +1816,49 @@ func schema_pkg_apis_core_v1alpha1_DockerClusterConnection(ref common.ReferenceC
 	}
 }
 
+func schema_pkg_apis_core_v1alpha1_DockerComposeFileObject(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "A secret or config in a Docker Compose project.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The name of the secret or config in the Docker Compose file.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"file": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The file that holds the contents.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"environment": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The environment variable that holds the contents.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"contentHash": {
+						SchemaProps: spec.SchemaProps{
+							Description: "A hash of the contents, so that the spec changes when they do.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"name"},
+			},
+		},
+	}
+}
+
 func schema_pkg_apis_core_v1alpha1_DockerComposeLogStream(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -1919,6 +2064,28 @@ func schema_pkg_apis_core_v1alpha1_DockerComposeProject(ref common.ReferenceCall
 							Format:      "",
 						},
 					},
+					"profiles": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Profiles to activate. Passed to docker-compose as `--profile NAME`.\n\nIf omitted, every service in the project is included, regardless of its profiles. If set, only services without profiles and services in an active profile are included. Use \"*\" to activate all profiles.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"provider": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The compose provider to run.\n\nOne of \"docker\" (`docker compose`, falling back to `docker-compose`), \"docker-compose\", \"podman\" (`podman compose`, falling back to `podman-compose`), \"nerdctl\" (`nerdctl compose`), or the path to a compose binary.\n\nIf omitted, Tilt uses $TILT_DOCKER_COMPOSE_PROVIDER if set, then $TILT_DOCKER_COMPOSE_CMD if set, and \"docker\" otherwise.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 				},
 			},
 		},
@@ -1972,6 +2139,35 @@ func schema_pkg_apis_core_v1alpha1_DockerComposeService(ref common.ReferenceCall
 	}
 }
 
+func schema_pkg_apis_core_v1alpha1_DockerComposeServiceDependency(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "A dependency on another service in the same Docker Compose project.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"service": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The name of the service.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"condition": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The condition the service must reach. One of service_started, service_healthy, or service_completed_successfully.\n\nDefaults to service_started.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"service"},
+			},
+		},
+	}
+}
+
 func schema_pkg_apis_core_v1alpha1_DockerComposeServiceList(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -2064,12 +2260,70 @@ func schema_pkg_apis_core_v1alpha1_DockerComposeServiceSpec(ref common.Reference
 							Ref:         ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DisableSource"),
 						},
 					},
+					"dependsOn": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Other services in the project that must reach a condition before this service starts.\n\nMirrors `depends_on` in the Docker Compose file.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerComposeServiceDependency"),
+									},
+								},
+							},
+						},
+					},
+					"labels": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Labels on the service in the Docker Compose file.\n\nTilt reads lifecycle hooks from these, like `tilt.restart-cmd`, the command that live update runs instead of restarting the container.",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"secrets": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Secrets that the service uses, from the top-level `secrets:` block of the Docker Compose file.\n\nDocker Compose doesn't recreate a container when a secret's contents change, so Tilt tracks a hash of them, and recreates the service when the hash changes.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerComposeFileObject"),
+									},
+								},
+							},
+						},
+					},
+					"configs": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Configs that the service uses, from the top-level `configs:` block of the Docker Compose file.\n\nTracked the same way as Secrets.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerComposeFileObject"),
+									},
+								},
+							},
+						},
+					},
 				},
 				Required: []string{"service", "project"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DisableSource", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerComposeProject"},
+			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DisableSource", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerComposeFileObject", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerComposeProject", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerComposeServiceDependency"},
 	}
 }
 
@@ -2141,11 +2395,39 @@ func schema_pkg_apis_core_v1alpha1_DockerComposeServiceStatus(ref common.Referen
 							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.MicroTime"),
 						},
 					},
+					"configHash": {
+						SchemaProps: spec.SchemaProps{
+							Description: "A hash of the service's resolved configuration when we last brought it up.\n\nEdits to a compose project only recreate the services whose configuration hash changed.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"lastApplyReason": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Why we last brought up this service, e.g., because it was never deployed, or because its configuration changed.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"networks": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The Docker networks that the container is attached to.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerNetworkAttachment"),
+									},
+								},
+							},
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DisableStatus", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerContainerState", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerPortBinding", "k8s.io/apimachinery/pkg/apis/meta/v1.MicroTime"},
+			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DisableStatus", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerContainerState", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerNetworkAttachment", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerPortBinding", "k8s.io/apimachinery/pkg/apis/meta/v1.MicroTime"},
 	}
 }
 
@@ -2198,6 +2480,20 @@ func schema_pkg_apis_core_v1alpha1_DockerContainerState(ref common.ReferenceCall
 							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.MicroTime"),
 						},
 					},
+					"health": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The result of the container's healthcheck. Can be one of \"starting\", \"healthy\", or \"unhealthy\". Empty if the container has no healthcheck.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"healthOutput": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Output from the most recent run of the container's healthcheck.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 				},
 			},
 		},
@@ -2253,6 +2549,47 @@ func schema_pkg_apis_core_v1alpha1_DockerImage(ref common.ReferenceCallback) com
 	}
 }
 
+func schema_pkg_apis_core_v1alpha1_DockerImageLayer(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "DockerImageLayer describes one layer of a built image.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"id": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The ID of the layer's image, if the image is still around.\n\nLayers from a base image, or built with BuildKit, usually don't have one.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"createdBy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The instruction that created the layer, e.g., `RUN npm install`.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"sizeBytes": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The size of the layer, in bytes.\n\nInstructions that only change the image config, like ENV or CMD, make empty layers.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"cached": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Whether the build reused the layer, from the build cache or a base image, rather than building it again.\n\nDocker keeps the creation time of reused layers, so a layer that was created before the build started was reused.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
 func schema_pkg_apis_core_v1alpha1_DockerImageList(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -2477,6 +2814,13 @@ func schema_pkg_apis_core_v1alpha1_DockerImageSpec(ref common.ReferenceCallback)
 							Format:      "",
 						},
 					},
+					"noCache": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Build without using the cache, and don't re-use an image previously built from the same inputs.\n\nEquivalent to `--no-cache` in the Docker CLI.\n\nTilt sets this on a single build when you trigger a resource with `tilt trigger --no-cache`.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
 				},
 				Required: []string{"ref"},
 			},
@@ -2527,11 +2871,25 @@ func schema_pkg_apis_core_v1alpha1_DockerImageStageStatus(ref common.ReferenceCa
 							Format:      "",
 						},
 					},
-				},
-				Required: []string{"name"},
-			},
-		},
-		Dependencies: []string{
+					"current": {
+						SchemaProps: spec.SchemaProps{
+							Description: "How many bytes of work the stage has done, for stages that report progress, like pulling a base image or sending the build context.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"total": {
+						SchemaProps: spec.SchemaProps{
+							Description: "How many bytes of work the stage has in total. Zero if unknown.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+				},
+				Required: []string{"name"},
+			},
+		},
+		Dependencies: []string{
 			"k8s.io/apimachinery/pkg/apis/meta/v1.MicroTime"},
 	}
 }
@@ -2674,11 +3032,83 @@ func schema_pkg_apis_core_v1alpha1_DockerImageStatus(ref common.ReferenceCallbac
 							},
 						},
 					},
+					"layers": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The layers of the most recent successfully built image, from newest to oldest, like `docker history`.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerImageLayer"),
+									},
+								},
+							},
+						},
+					},
+					"imageSizeBytes": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The total size of the most recent successfully built image, in bytes.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"imageSizeDeltaBytes": {
+						SchemaProps: spec.SchemaProps{
+							Description: "How much the image size changed from the previous successful build, in bytes.\n\nZero on the first build.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerImageStageStatus", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerImageStateBuilding", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerImageStateCompleted", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerImageStateWaiting"},
+			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerImageLayer", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerImageStageStatus", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerImageStateBuilding", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerImageStateCompleted", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerImageStateWaiting"},
+	}
+}
+
+func schema_pkg_apis_core_v1alpha1_DockerNetworkAttachment(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "How a container is attached to a Docker network.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The name of the network.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"aliases": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The names that other containers on the network can use to reach this one, like the name of the Docker Compose service.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"ipAddress": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The IP address of the container on the network.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"name"},
+			},
+		},
 	}
 }
 
@@ -2710,9 +3140,116 @@ func schema_pkg_apis_core_v1alpha1_DockerPortBinding(ref common.ReferenceCallbac
 							Format:      "",
 						},
 					},
+					"protocol": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The protocol of the port, \"tcp\" or \"udp\".",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_core_v1alpha1_DockerPruneRun(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "DockerPruneRun is the result of one run of the Docker pruner.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"startTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "When the pruner started.",
+							Default:     map[string]interface{}{},
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.MicroTime"),
+						},
+					},
+					"finishTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "When the pruner finished.",
+							Default:     map[string]interface{}{},
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.MicroTime"),
+						},
+					},
+					"containersDeleted": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The number of stopped containers removed.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"imagesDeleted": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The number of images removed.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"cachesDeleted": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The number of build cache entries removed.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"volumesDeleted": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The number of volumes removed.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"spaceReclaimed": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The disk space reclaimed, in bytes.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"error": {
+						SchemaProps: spec.SchemaProps{
+							Description: "An error that stopped the pruner before it finished.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.MicroTime"},
+	}
+}
+
+func schema_pkg_apis_core_v1alpha1_DockerPruneStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "DockerPruneStatus records what Tilt's Docker pruner has cleaned up.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"runs": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Recent prune runs, oldest first.\n\nTilt only keeps the last few runs.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerPruneRun"),
+									},
+								},
+							},
+						},
+					},
 				},
 			},
 		},
+		Dependencies: []string{
+			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerPruneRun"},
 	}
 }
 
@@ -3422,6 +3959,34 @@ func schema_pkg_apis_core_v1alpha1_ForwardStatus(ref common.ReferenceCallback) c
 							Format:      "",
 						},
 					},
+					"state": {
+						SchemaProps: spec.SchemaProps{
+							Description: "State is the state of the connection to the pod.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"retryCount": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RetryCount is the number of times the forward has reconnected since the PortForward was created or last changed.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"nextRetryTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "NextRetryTime is when the forward will next try to reconnect.\n\nOnly set when the State is Retrying.",
+							Default:     map[string]interface{}{},
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.MicroTime"),
+						},
+					},
+					"podName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PodName is the name of the pod that the forward is connected to.\n\nWhen forwarding to a Service or PodSelector, this changes when Tilt fails over to another pod.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 				},
 				Required: []string{"localPort", "containerPort", "addresses"},
 			},
@@ -3828,6 +4393,20 @@ func schema_pkg_apis_core_v1alpha1_ImageMapStatus(ref common.ReferenceCallback)
 							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.MicroTime"),
 						},
 					},
+					"digest": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The digest of the image manifest that Tilt pushed to the registry, e.g., sha256:0123...\n\nWhen set, the image references above are pinned to this digest, so that the cluster runs exactly the image we built, even if someone else pushes the same tag to a shared registry.\n\nEmpty if Tilt didn't push the image, or the registry didn't report a digest.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"registry": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The host of the registry that Tilt pushed the image to, as seen from the local network.\n\nTogether with the tag, tells tools that clean up old tags where Tilt's images live.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 				},
 			},
 		},
@@ -3934,6 +4513,56 @@ func schema_pkg_apis_core_v1alpha1_KubernetesApplyCmd(ref common.ReferenceCallba
 	}
 }
 
+func schema_pkg_apis_core_v1alpha1_KubernetesApplyDiff(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "KubernetesApplyDiff compares the YAML that's about to be applied against the objects that are currently in the cluster, similar to `kubectl diff`.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"computeTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Timestamp of when the diff was computed.",
+							Default:     map[string]interface{}{},
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.MicroTime"),
+						},
+					},
+					"inputHash": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The hash of the inputs that the diff was computed for.\n\nCompare with AppliedInputHash to check if the diff has been applied.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"objects": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The objects that would change. Objects that wouldn't change are omitted.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesApplyObjectDiff"),
+									},
+								},
+							},
+						},
+					},
+					"error": {
+						SchemaProps: spec.SchemaProps{
+							Description: "An error computing the diff.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesApplyObjectDiff", "k8s.io/apimachinery/pkg/apis/meta/v1.MicroTime"},
+	}
+}
+
 func schema_pkg_apis_core_v1alpha1_KubernetesApplyList(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -3983,41 +4612,132 @@ func schema_pkg_apis_core_v1alpha1_KubernetesApplyList(ref common.ReferenceCallb
 	}
 }
 
-func schema_pkg_apis_core_v1alpha1_KubernetesApplySpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_core_v1alpha1_KubernetesApplyObjectDiff(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "KubernetesApplySpec defines the desired state of KubernetesApply",
+				Description: "KubernetesApplyObjectDiff describes the change to one object.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"yaml": {
+					"kind": {
 						SchemaProps: spec.SchemaProps{
-							Description: "YAML to apply to the cluster.\n\nExactly one of YAML OR ApplyCmd MUST be provided.",
+							Description: "The kind of the object, e.g., Deployment.",
+							Default:     "",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"imageMaps": {
+					"namespace": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Names of image maps that this applier depends on.\n\nThe controller will watch all the image maps, and redeploy the entire YAML if any of the maps resolve to a new image.",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Default: "",
-										Type:    []string{"string"},
-										Format:  "",
-									},
-								},
-							},
+							Description: "The namespace of the object. Empty for cluster-scoped objects.",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"imageLocators": {
+					"name": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Descriptors of how to find images in the YAML.\n\nNeeded when injecting images into CRDs.",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
+							Description: "The name of the object.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"action": {
+						SchemaProps: spec.SchemaProps{
+							Description: "What the apply would do to the object: \"create\" or \"update\".",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"diff": {
+						SchemaProps: spec.SchemaProps{
+							Description: "A unified diff from the live object to the object the server would store after the apply, as YAML.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"kind", "name", "action"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_core_v1alpha1_KubernetesApplyRevision(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "KubernetesApplyRevision is a YAML that was successfully applied.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"revision": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Identifies the revision. Each new YAML gets a higher number than the revisions before it.",
+							Default:     0,
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"yaml": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The YAML that was applied.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"applyTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Timestamp of when this YAML was first applied.",
+							Default:     map[string]interface{}{},
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.MicroTime"),
+						},
+					},
+				},
+				Required: []string{"revision", "yaml"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.MicroTime"},
+	}
+}
+
+func schema_pkg_apis_core_v1alpha1_KubernetesApplySpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "KubernetesApplySpec defines the desired state of KubernetesApply",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"yaml": {
+						SchemaProps: spec.SchemaProps{
+							Description: "YAML to apply to the cluster.\n\nExactly one of YAML OR ApplyCmd MUST be provided.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"imageMaps": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Names of image maps that this applier depends on.\n\nThe controller will watch all the image maps, and redeploy the entire YAML if any of the maps resolve to a new image.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"imageLocators": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Descriptors of how to find images in the YAML.\n\nNeeded when injecting images into CRDs.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
 										Default: map[string]interface{}{},
 										Ref:     ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesImageLocator"),
@@ -4089,11 +4809,107 @@ func schema_pkg_apis_core_v1alpha1_KubernetesApplySpec(ref common.ReferenceCallb
 							Format:      "",
 						},
 					},
+					"waitFor": {
+						SchemaProps: spec.SchemaProps{
+							Description: "WaitFor determines rollout checks on the applied objects.\n\nThe apply isn't considered done until every object matched by a check passes it (e.g., a Deployment is Available or a Job is Complete). While waiting, the RolloutComplete condition is False.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesApplyWaitFor"),
+									},
+								},
+							},
+						},
+					},
+					"applyOrder": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ApplyOrder splits the objects into stages that are applied one after another.\n\nEach object is applied in the stage of the first selector that matches it. Objects that don't match any selector are applied in a final stage. Within a stage, objects are applied in kind order (e.g., Namespaces and CustomResourceDefinitions first).\n\nObjects of a kind defined by a CustomResourceDefinition in the same apply wait for the CustomResourceDefinition to be established.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ObjectSelector"),
+									},
+								},
+							},
+						},
+					},
+					"commonLabels": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Labels to add to every object in the YAML, including pod templates.\n\nUseful for attributing dev workloads to a team or owner (e.g., for cost tracking). Overrides labels with the same key in the YAML.\n\nOnly supported with YAML, not ApplyCmd.",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"commonAnnotations": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Annotations to add to every object in the YAML, including pod templates.\n\nOverrides annotations with the same key in the YAML.\n\nOnly supported with YAML, not ApplyCmd.",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"pruneOwner": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Opts in to pruning: deleting objects that were removed from the YAML.\n\nWhen set, Tilt records this owner on each object it applies, and deletes objects with this owner that are no longer in the YAML, even if they were applied before Tilt restarted. The owner should be unique to the project and resource, so that projects sharing a cluster don't prune each other's objects.\n\nOnly supported with YAML, not ApplyCmd.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"resetVolumes": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Volume claims that the Reset Volumes button may delete.\n\nEach entry is either the name of a PersistentVolumeClaim in the YAML, or the name of a volumeClaimTemplate on a StatefulSet in the YAML.\n\nOnly supported with YAML, not ApplyCmd.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"gitOpsHandoff": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Opts in to taking over objects that a GitOps tool (Flux or Argo CD) manages.\n\nAfter each apply, Tilt suspends reconciliation of the applied objects that the tool manages, so that it doesn't revert Tilt's changes. When the objects are deleted, Tilt resumes reconciliation instead, so that the tool restores the objects to their declared state.\n\nOnly supported with YAML, not ApplyCmd.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"diffPreview": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Opts in to diff previews.\n\nWhen set, Tilt compares the YAML against the cluster each time the inputs change, and stores what the next apply would change in the status.\n\nOnly supported with YAML, not ApplyCmd.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DisableSource", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesApplyCmd", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesDiscoveryTemplateSpec", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesImageLocator", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.PodLogStreamTemplateSpec", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.PortForwardTemplateSpec", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.RestartOnSpec", "k8s.io/apimachinery/pkg/apis/meta/v1.Duration"},
+			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DisableSource", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesApplyCmd", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesApplyWaitFor", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesDiscoveryTemplateSpec", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesImageLocator", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ObjectSelector", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.PodLogStreamTemplateSpec", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.PortForwardTemplateSpec", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.RestartOnSpec", "k8s.io/apimachinery/pkg/apis/meta/v1.Duration"},
 	}
 }
 
@@ -4159,11 +4975,88 @@ func schema_pkg_apis_core_v1alpha1_KubernetesApplyStatus(ref common.ReferenceCal
 							},
 						},
 					},
+					"diff": {
+						SchemaProps: spec.SchemaProps{
+							Description: "A preview of what the next apply will change in the cluster.\n\nOnly computed when spec.diffPreview is set, and cleared once the changes have been applied.",
+							Ref:         ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesApplyDiff"),
+						},
+					},
+					"history": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The YAML of recent successful applies, newest first.\n\nUsed to roll back a bad deploy. Capped by count and by total size. Empty when the objects are applied with a custom command.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesApplyRevision"),
+									},
+								},
+							},
+						},
+					},
+					"currentRevision": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The revision in History that's currently deployed.\n\nUsually the newest revision, unless the apply was rolled back.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DisableStatus", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesApplyDiff", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesApplyRevision", "k8s.io/apimachinery/pkg/apis/meta/v1.Condition", "k8s.io/apimachinery/pkg/apis/meta/v1.MicroTime"},
+	}
+}
+
+func schema_pkg_apis_core_v1alpha1_KubernetesApplyWaitFor(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "A rollout check on the applied objects.\n\nModeled after `kubectl wait --for`.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"objectSelector": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Selects which applied objects to check.",
+							Default:     map[string]interface{}{},
+							Ref:         ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ObjectSelector"),
+						},
+					},
+					"condition": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The type of a status condition that must be True, e.g., \"Available\" for a Deployment or \"Complete\" for a Job.\n\nExactly one of Condition OR JSONPath MUST be provided.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"jsonPath": {
+						SchemaProps: spec.SchemaProps{
+							Description: "A JSONPath into the object, e.g., \"{.status.phase}\".\n\nUseful for CRDs that don't report their status as conditions.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"value": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The value the field at JSONPath must have.\n\nIf empty, the field must be present and non-empty.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"timeout": {
+						SchemaProps: spec.SchemaProps{
+							Description: "How long to wait after the apply for the check to pass.\n\nIf not specified, defaults to 5m.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
+						},
+					},
 				},
+				Required: []string{"objectSelector"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DisableStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.Condition", "k8s.io/apimachinery/pkg/apis/meta/v1.MicroTime"},
+			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ObjectSelector", "k8s.io/apimachinery/pkg/apis/meta/v1.Duration"},
 	}
 }
 
@@ -4292,55 +5185,153 @@ func schema_pkg_apis_core_v1alpha1_KubernetesDiscovery(ref common.ReferenceCallb
 	}
 }
 
-func schema_pkg_apis_core_v1alpha1_KubernetesDiscoveryList(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_core_v1alpha1_KubernetesDiscoveryEvent(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "KubernetesDiscoveryList",
+				Description: "KubernetesDiscoveryEvent is a problem with a discovered Pod.\n\nMirrors v1.Event from the Kubernetes API. OOMKilled containers are reported as events too, even though Kubernetes only reports them in the Pod status.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"kind": {
+					"podName": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Description: "PodName is the name of the Pod that the event is about.",
+							Default:     "",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"apiVersion": {
+					"podNamespace": {
 						SchemaProps: spec.SchemaProps{
-							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Description: "PodNamespace is the namespace of the Pod that the event is about.",
+							Default:     "",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"metadata": {
+					"containerName": {
 						SchemaProps: spec.SchemaProps{
-							Default: map[string]interface{}{},
-							Ref:     ref("k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"),
+							Description: "ContainerName is the container that the event is about, if any.",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"items": {
+					"reason": {
 						SchemaProps: spec.SchemaProps{
-							Type: []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Default: map[string]interface{}{},
-										Ref:     ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesDiscovery"),
-									},
-								},
-							},
+							Description: "Reason is a short, machine-readable reason for the event (e.g., FailedScheduling).",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-				},
-				Required: []string{"items"},
-			},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Message is a human-readable description of the event.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"count": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Count is the number of times the event has occurred.",
+							Default:     0,
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"lastTimestamp": {
+						SchemaProps: spec.SchemaProps{
+							Description: "LastTimestamp is the last time the event occurred.",
+							Default:     map[string]interface{}{},
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+				},
+				Required: []string{"podName", "podNamespace", "reason", "message", "count"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
+func schema_pkg_apis_core_v1alpha1_KubernetesDiscoveryList(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "KubernetesDiscoveryList",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"),
+						},
+					},
+					"items": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesDiscovery"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"items"},
+			},
 		},
 		Dependencies: []string{
 			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesDiscovery", "k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"},
 	}
 }
 
+func schema_pkg_apis_core_v1alpha1_KubernetesDiscoveryNamespaceError(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"namespace": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The namespace that couldn't be watched. Empty for all namespaces.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"error": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The error from watching the namespace.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"error"},
+			},
+		},
+	}
+}
+
 func schema_pkg_apis_core_v1alpha1_KubernetesDiscoverySpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -4396,6 +5387,28 @@ func schema_pkg_apis_core_v1alpha1_KubernetesDiscoverySpec(ref common.ReferenceC
 							Format:      "",
 						},
 					},
+					"extraNamespaces": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Extra namespaces to look in for Pods that match the ExtraSelectors.\n\nPods are always discovered in the namespaces of the Watches. Use this when an operator creates Pods in a different namespace than the objects it manages.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"allNamespaces": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Look in all namespaces for Pods that match the ExtraSelectors.\n\nRequires permission to list and watch Pods across the cluster.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
 				},
 				Required: []string{"watches"},
 			},
@@ -4418,6 +5431,13 @@ func schema_pkg_apis_core_v1alpha1_KubernetesDiscoveryStateRunning(ref common.Re
 							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.MicroTime"),
 						},
 					},
+					"pollingReason": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PollingReason is set when Pods can't be watched (e.g., because RBAC doesn't allow it), so discovery lists them periodically instead. Pod status and live updates are slower while polling.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 				},
 				Required: []string{"startTime"},
 			},
@@ -4488,12 +5508,40 @@ func schema_pkg_apis_core_v1alpha1_KubernetesDiscoveryStatus(ref common.Referenc
 							Ref:         ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesDiscoveryStateRunning"),
 						},
 					},
+					"namespaceErrors": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Namespaces where Pods couldn't be watched (e.g., because of missing RBAC permissions). Discovery continues in the other namespaces.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesDiscoveryNamespaceError"),
+									},
+								},
+							},
+						},
+					},
+					"events": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Events are recent problems with the discovered Pods that Kubernetes reported (e.g., FailedScheduling, image pull back-offs, or containers that were OOMKilled).\n\nOnly the most recent events for each Pod are kept.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesDiscoveryEvent"),
+									},
+								},
+							},
+						},
+					},
 				},
 				Required: []string{"pods"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesDiscoveryStateRunning", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesDiscoveryStateWaiting", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.Pod", "k8s.io/apimachinery/pkg/apis/meta/v1.MicroTime"},
+			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesDiscoveryEvent", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesDiscoveryNamespaceError", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesDiscoveryStateRunning", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.KubernetesDiscoveryStateWaiting", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.Pod", "k8s.io/apimachinery/pkg/apis/meta/v1.MicroTime"},
 	}
 }
 
@@ -5161,22 +6209,510 @@ func schema_pkg_apis_core_v1alpha1_LiveUpdateSync(ref common.ReferenceCallback)
 				Properties: map[string]spec.Schema{
 					"localPath": {
 						SchemaProps: spec.SchemaProps{
-							Description: "A relative path to local files. Required.\n\nComputed relative to the live-update BasePath.",
+							Description: "A relative path to local files. Required.\n\nComputed relative to the live-update BasePath.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"containerPath": {
+						SchemaProps: spec.SchemaProps{
+							Description: "An absolute path inside the container. Required.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"localPath", "containerPath"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_core_v1alpha1_LocalIngress(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "LocalIngress serves a friendly local hostname (e.g., myapp.localhost) over HTTPS, and proxies requests to Tilt-managed port forwards.\n\nThe URL stays the same when pods restart or port forwards move to a different local port.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.LocalIngressSpec"),
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.LocalIngressStatus"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.LocalIngressSpec", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.LocalIngressStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+	}
+}
+
+func schema_pkg_apis_core_v1alpha1_LocalIngressList(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "LocalIngressList",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"),
+						},
+					},
+					"items": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.LocalIngress"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"items"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.LocalIngress", "k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"},
+	}
+}
+
+func schema_pkg_apis_core_v1alpha1_LocalIngressRoute(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "LocalIngressRoute sends requests under a path prefix to a resource's port forward, or to a local port.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"path": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The path prefix to match. Defaults to \"/\".",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"resource": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The resource whose port forward should receive requests.\n\nExactly one of Resource or LocalPort is required.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"containerPort": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The container port of the resource's port forward. If not specified, uses the resource's first port forward.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"localPort": {
+						SchemaProps: spec.SchemaProps{
+							Description: "A port on localhost that should receive requests (e.g., a server started by a local_resource).\n\nExactly one of Resource or LocalPort is required.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_core_v1alpha1_LocalIngressRouteStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"path": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The path prefix of the route.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"target": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The address that requests are sent to, e.g., localhost:53212",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"error": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Why requests can't be routed (e.g., the resource has no ready port forward).",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"path"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_core_v1alpha1_LocalIngressSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "LocalIngressSpec defines the hostname to serve and where to route requests.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"host": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The hostname to serve, e.g., myapp.localhost\n\nHostnames under .localhost resolve to the local machine without any DNS setup. Other hostnames need an entry in /etc/hosts, and their domain in TILT_LOCAL_INGRESS_DOMAINS, because the local CA only signs certificates for the domains it was created with.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"port": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The local port to serve HTTPS on. Defaults to 10443.\n\nLocalIngresses on the same port share a server, and are routed by hostname.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"routes": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Where to send requests, by path prefix. The longest matching prefix wins.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.LocalIngressRoute"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"host", "routes"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.LocalIngressRoute"},
+	}
+}
+
+func schema_pkg_apis_core_v1alpha1_LocalIngressStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "LocalIngressStatus defines the observed state of LocalIngress",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"url": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The URL that the LocalIngress serves, e.g., https://myapp.localhost:10443/",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"caCertPath": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The certificate authority that signs the LocalIngress certificates.\n\nAdd it to your system or browser trust store to avoid certificate warnings.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"routes": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Where each route currently sends requests.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.LocalIngressRouteStatus"),
+									},
+								},
+							},
+						},
+					},
+					"error": {
+						SchemaProps: spec.SchemaProps{
+							Description: "A problem serving the hostname (e.g., the port is already in use).",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.LocalIngressRouteStatus"},
+	}
+}
+
+func schema_pkg_apis_core_v1alpha1_NestedTilt(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "NestedTilt runs a child Tilt instance for another Tiltfile, and mirrors its resources into this Tilt's UI.\n\nUseful for composing several independently-owned Tilt setups without merging their Tiltfiles.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.NestedTiltSpec"),
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.NestedTiltStatus"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.NestedTiltSpec", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.NestedTiltStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+	}
+}
+
+func schema_pkg_apis_core_v1alpha1_NestedTiltList(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "NestedTiltList",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"),
+						},
+					},
+					"items": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.NestedTilt"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"items"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.NestedTilt", "k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"},
+	}
+}
+
+func schema_pkg_apis_core_v1alpha1_NestedTiltSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "NestedTiltSpec defines how to run the child Tilt instance.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"path": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The directory that contains the child Tiltfile.\n\nThe child Tilt runs with this directory as its working directory.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"port": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The port for the child Tilt's web UI and API.\n\nMust be different from the port of every other running Tilt instance.",
+							Default:     0,
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"args": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Arguments to the child Tiltfile.\n\nBy default, a list of arguments indicates the list of resources in the child Tiltfile that should be enabled.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"path", "port"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_core_v1alpha1_NestedTiltStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "NestedTiltStatus defines the observed state of NestedTilt",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"webURL": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The URL of the child Tilt's web UI.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"resources": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The names of the child Tilt's resources, as of the last sync.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"error": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Contains information about any problems talking to the child Tilt.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_core_v1alpha1_ObjectFieldSource(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ObjectFieldSource watches one field of an API object.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind of the object to watch, e.g., \"ConfigMap\".",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name of the object to watch.",
 							Default:     "",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"containerPath": {
+					"fieldPath": {
 						SchemaProps: spec.SchemaProps{
-							Description: "An absolute path inside the container. Required.",
+							Description: "FieldPath is a JSONPath expression selecting the field to watch, e.g., \"{.data.version}\".",
 							Default:     "",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
 				},
-				Required: []string{"localPath", "containerPath"},
+				Required: []string{"kind", "name", "fieldPath"},
 			},
 		},
 	}
@@ -5832,7 +7368,7 @@ func schema_pkg_apis_core_v1alpha1_PortForwardSpec(ref common.ReferenceCallback)
 				Properties: map[string]spec.Schema{
 					"podName": {
 						SchemaProps: spec.SchemaProps{
-							Description: "The name of the pod to port forward to/from. Required.",
+							Description: "The name of the pod to port forward to/from.\n\nExactly one of PodName, ServiceName, or PodSelector is required.",
 							Default:     "",
 							Type:        []string{"string"},
 							Format:      "",
@@ -5867,6 +7403,29 @@ func schema_pkg_apis_core_v1alpha1_PortForwardSpec(ref common.ReferenceCallback)
 							Format:      "",
 						},
 					},
+					"serviceName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The name of a Service to port forward to/from.\n\nTilt forwards to a ready pod behind the Service, and fails over to another ready pod if that pod goes away. Each ContainerPort is treated as a port on the Service, and mapped to its target port.\n\nRequires a Namespace.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"podSelector": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Labels that select the pods to port forward to/from.\n\nTilt forwards to a ready pod that matches, and fails over to another ready pod if that pod goes away.\n\nRequires a Namespace.",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
 				},
 				Required: []string{"podName", "forwards"},
 			},
@@ -6088,9 +7647,25 @@ func schema_pkg_apis_core_v1alpha1_RestartOnSpec(ref common.ReferenceCallback) c
 							},
 						},
 					},
+					"objectFields": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ObjectFields on other API objects that can trigger a restart when their value changes.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ObjectFieldSource"),
+									},
+								},
+							},
+						},
+					},
 				},
 			},
 		},
+		Dependencies: []string{
+			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.ObjectFieldSource"},
 	}
 }
 
@@ -7229,6 +8804,34 @@ func schema_pkg_apis_core_v1alpha1_UIBuildRunning(ref common.ReferenceCallback)
 							Format:      "",
 						},
 					},
+					"currentStage": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The image build stage in progress, as BuildKit names it (e.g., \"[2/5] RUN npm install\"). Empty if no image is building.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"completedStages": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The number of image build stages that have finished, including cached stages.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"totalStages": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The number of image build stages that BuildKit has reported so far.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"progress": {
+						SchemaProps: spec.SchemaProps{
+							Description: "How far along the image builds are, from 0 to 100.\n\nCounts finished stages, plus the bytes done in stages that report progress, like pulling a base image.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
 				},
 			},
 		},
@@ -7294,6 +8897,34 @@ func schema_pkg_apis_core_v1alpha1_UIBuildTerminated(ref common.ReferenceCallbac
 							Format:      "",
 						},
 					},
+					"totalStages": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The number of image build stages in the images that this build built. Zero if the build didn't build any images.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"cachedStages": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The number of image build stages that BuildKit reused from its cache.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"slowestStage": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The slowest image build stage that BuildKit didn't reuse from its cache, as BuildKit names it (e.g., \"[2/5] RUN npm install\").",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"slowestStageMillis": {
+						SchemaProps: spec.SchemaProps{
+							Description: "How long the slowest stage took, in milliseconds.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
 				},
 			},
 		},
@@ -7626,6 +9257,13 @@ func schema_pkg_apis_core_v1alpha1_UIFeatureFlag(ref common.ReferenceCallback) c
 							Format:      "",
 						},
 					},
+					"source": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Where the value came from: \"default\", \"rollout\" (the flag is being rolled out gradually, and this project has it), or \"tiltfile\".",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 				},
 			},
 		},
@@ -7867,6 +9505,29 @@ func schema_pkg_apis_core_v1alpha1_UIResourceCondition(ref common.ReferenceCallb
 							Format:      "",
 						},
 					},
+					"messageCode": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MessageCode is a stable identifier for the message, from Tilt's message catalog (e.g., ResourceUpdateError). Clients should match on the code rather than the message text.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"messageArgs": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MessageArgs are the arguments that the message was rendered with, so that clients can render their own (e.g., translated) message.",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
 				},
 				Required: []string{"type", "status"},
 			},
@@ -7954,6 +9615,13 @@ func schema_pkg_apis_core_v1alpha1_UIResourceKubernetes(ref common.ReferenceCall
 							},
 						},
 					},
+					"pendingDiff": {
+						SchemaProps: spec.SchemaProps{
+							Description: "A preview of the changes the next apply will make to the cluster, as a unified diff. Only populated for resources with diff previews.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 				},
 			},
 		},
@@ -8104,6 +9772,36 @@ func schema_pkg_apis_core_v1alpha1_UIResourceStateWaiting(ref common.ReferenceCa
 							},
 						},
 					},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Description: "A human-readable message explaining why the resource is waiting.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"messageCode": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MessageCode is a stable identifier for the message, from Tilt's message catalog (e.g., WaitingForDependency). Clients should match on the code rather than the message text.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"messageArgs": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MessageArgs are the arguments that the message was rendered with, so that clients can render their own (e.g., translated) message.",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
 				},
 				Required: []string{"reason"},
 			},
@@ -8397,6 +10095,110 @@ func schema_pkg_apis_core_v1alpha1_UISession(ref common.ReferenceCallback) commo
 	}
 }
 
+func schema_pkg_apis_core_v1alpha1_UISessionCrash(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "A panic in a Tilt controller or subscriber that Tilt recovered from.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"component": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The controller or subscriber that crashed.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The panic message, with secrets redacted.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"time": {
+						SchemaProps: spec.SchemaProps{
+							Description: "When the component first crashed with this message.",
+							Default:     map[string]interface{}{},
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"count": {
+						SchemaProps: spec.SchemaProps{
+							Description: "How many times the component has crashed with this message.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+				Required: []string{"component"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
+func schema_pkg_apis_core_v1alpha1_UISessionIdleStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "Resources that Tilt disabled because it was idle.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"shutDownAt": {
+						SchemaProps: spec.SchemaProps{
+							Description: "When Tilt disabled the resources. Empty once they're resuming.",
+							Default:     map[string]interface{}{},
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"resumeStartTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "When Tilt started bringing the resources back.",
+							Default:     map[string]interface{}{},
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"resources": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The resources that Tilt disabled.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"resumedResources": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The resources that have deployed since Tilt started bringing them back.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
 func schema_pkg_apis_core_v1alpha1_UISessionList(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -8555,11 +10357,61 @@ func schema_pkg_apis_core_v1alpha1_UISessionStatus(ref common.ReferenceCallback)
 							Format:      "",
 						},
 					},
+					"crashes": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Crashes reports internal errors that Tilt recovered from. Tilt keeps running, but the component that crashed may not work correctly until Tilt restarts.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.UISessionCrash"),
+									},
+								},
+							},
+						},
+					},
+					"versionWarnings": {
+						SchemaProps: spec.SchemaProps{
+							Description: "VersionWarnings explains how the running Tilt differs from the latest release, or from the tilt binary that editor integrations use.\n\nOnly populated if the Tiltfile opts in with version_settings(check_latest_release=True).",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"releaseHighlights": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ReleaseHighlights lists the first few changelog items from the latest release, if it's newer than the running version.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"idle": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Idle reports the resources that Tilt disabled because nobody was using it, and its progress bringing them back.\n\nOnly populated if the Tiltfile opts in with idle_settings().",
+							Ref:         ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.UISessionIdleStatus"),
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.TiltBuild", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.UIFeatureFlag", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.VersionSettings", "k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.TiltBuild", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.UIFeatureFlag", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.UISessionCrash", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.UISessionIdleStatus", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.VersionSettings", "k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
 	}
 }
 
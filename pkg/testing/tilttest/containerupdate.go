@@ -0,0 +1,84 @@
+package tilttest
+
+import (
+	"context"
+	"io"
+
+	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/containerupdate"
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/store/liveupdates"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// Container is a running container that a live update copies files into.
+//
+// PodID and Namespace are empty for containers outside Kubernetes.
+type Container struct {
+	PodID         string
+	ContainerID   string
+	ContainerName string
+	Namespace     string
+}
+
+// ContainerUpdater live-updates a running container: it copies the archive
+// into the container, deletes the given files, and runs the commands.
+type ContainerUpdater interface {
+	UpdateContainer(ctx context.Context, c Container, archiveToCopy io.Reader,
+		filesToDelete []string, cmds []model.Cmd, hotReload bool) error
+}
+
+// A call to FakeContainerUpdater.UpdateContainer.
+type UpdateContainerCall struct {
+	Container Container
+	Archive   io.Reader
+	ToDelete  []string
+	Cmds      []model.Cmd
+	HotReload bool
+}
+
+// FakeContainerUpdater records live updates instead of running them.
+type FakeContainerUpdater struct {
+	f containerupdate.FakeContainerUpdater
+}
+
+var _ ContainerUpdater = &FakeContainerUpdater{}
+
+func NewFakeContainerUpdater() *FakeContainerUpdater {
+	return &FakeContainerUpdater{}
+}
+
+// SetUpdateErr makes the next call to UpdateContainer fail with err.
+func (cu *FakeContainerUpdater) SetUpdateErr(err error) {
+	cu.f.SetUpdateErr(err)
+}
+
+func (cu *FakeContainerUpdater) UpdateContainer(ctx context.Context, c Container, archiveToCopy io.Reader,
+	filesToDelete []string, cmds []model.Cmd, hotReload bool) error {
+	return cu.f.UpdateContainer(ctx, liveupdates.Container{
+		PodID:         k8s.PodID(c.PodID),
+		ContainerID:   container.ID(c.ContainerID),
+		ContainerName: container.Name(c.ContainerName),
+		Namespace:     k8s.Namespace(c.Namespace),
+	}, archiveToCopy, filesToDelete, cmds, hotReload)
+}
+
+// Calls are the live updates so far, oldest first.
+func (cu *FakeContainerUpdater) Calls() []UpdateContainerCall {
+	result := make([]UpdateContainerCall, 0, len(cu.f.Calls))
+	for _, call := range cu.f.Calls {
+		result = append(result, UpdateContainerCall{
+			Container: Container{
+				PodID:         call.ContainerInfo.PodID.String(),
+				ContainerID:   call.ContainerInfo.ContainerID.String(),
+				ContainerName: call.ContainerInfo.ContainerName.String(),
+				Namespace:     call.ContainerInfo.Namespace.String(),
+			},
+			Archive:   call.Archive,
+			ToDelete:  call.ToDelete,
+			Cmds:      call.Cmds,
+			HotReload: call.HotReload,
+		})
+	}
+	return result
+}
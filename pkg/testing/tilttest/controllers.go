@@ -0,0 +1,140 @@
+package tilttest
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/tilt-dev/tilt-apiserver/pkg/server/builder/resource"
+	"github.com/tilt-dev/tilt/internal/controllers/fake"
+)
+
+// Reconciler is a controller for Tilt API objects.
+//
+// It's the same shape as the controllers that Tilt runs itself.
+type Reconciler interface {
+	reconcile.Reconciler
+	CreateBuilder(mgr ctrl.Manager) (*builder.Builder, error)
+}
+
+// Object is a Tilt API object, like the ones in pkg/apis/core/v1alpha1.
+type Object interface {
+	ctrlclient.Object
+	resource.Object
+}
+
+// ControllerFixtureBuilder sets up the client for a ControllerFixture.
+//
+// Construct the reconciler under test with the builder's Client,
+// then pass it to Build.
+type ControllerFixtureBuilder struct {
+	t testing.TB
+	b *fake.ControllerFixtureBuilder
+}
+
+func NewControllerFixtureBuilder(t testing.TB) *ControllerFixtureBuilder {
+	return &ControllerFixtureBuilder{t: t, b: fake.NewControllerFixtureBuilder(t)}
+}
+
+// Client reads and writes the in-memory API server.
+func (b *ControllerFixtureBuilder) Client() ctrlclient.Client {
+	return b.b.Client
+}
+
+// Context is canceled when the test finishes.
+func (b *ControllerFixtureBuilder) Context() context.Context {
+	return b.b.Context()
+}
+
+func (b *ControllerFixtureBuilder) Build(r Reconciler) *ControllerFixture {
+	b.t.Helper()
+	return &ControllerFixture{t: b.t, f: b.b.Build(r)}
+}
+
+// ControllerFixture runs a single reconciler against an in-memory API server.
+//
+// Writes through the fixture (Create, Update, UpdateStatus, Delete) reconcile
+// the object immediately, so tests can assert on the result right away.
+// Any error fails the test.
+type ControllerFixture struct {
+	t testing.TB
+	f *fake.ControllerFixture
+}
+
+func (f *ControllerFixture) Context() context.Context {
+	return f.f.Context()
+}
+
+func (f *ControllerFixture) Client() ctrlclient.Client {
+	return f.f.Client
+}
+
+// Stdout is everything the reconciler has logged so far.
+func (f *ControllerFixture) Stdout() string {
+	return f.f.Stdout()
+}
+
+func (f *ControllerFixture) Reconcile(key types.NamespacedName) (ctrl.Result, error) {
+	f.t.Helper()
+	return f.f.Reconcile(key)
+}
+
+func (f *ControllerFixture) MustReconcile(key types.NamespacedName) ctrl.Result {
+	f.t.Helper()
+	return f.f.MustReconcile(key)
+}
+
+// Get reads the object into out. Returns false if it doesn't exist.
+func (f *ControllerFixture) Get(key types.NamespacedName, out Object) bool {
+	f.t.Helper()
+	return f.f.Get(key, out)
+}
+
+func (f *ControllerFixture) MustGet(key types.NamespacedName, out Object) {
+	f.t.Helper()
+	f.f.MustGet(key, out)
+}
+
+func (f *ControllerFixture) List(out ctrlclient.ObjectList) {
+	f.t.Helper()
+	f.f.List(out)
+}
+
+func (f *ControllerFixture) Create(o Object) ctrl.Result {
+	f.t.Helper()
+	return f.f.Create(o)
+}
+
+// Update updates the object metadata and spec.
+func (f *ControllerFixture) Update(o Object) ctrl.Result {
+	f.t.Helper()
+	return f.f.Update(o)
+}
+
+// Create or update.
+func (f *ControllerFixture) Upsert(o Object) ctrl.Result {
+	f.t.Helper()
+	return f.f.Upsert(o)
+}
+
+func (f *ControllerFixture) UpdateStatus(o Object) ctrl.Result {
+	f.t.Helper()
+	return f.f.UpdateStatus(o)
+}
+
+// Delete returns false if the object didn't exist, in which case
+// there's nothing to reconcile.
+func (f *ControllerFixture) Delete(o Object) (bool, ctrl.Result) {
+	f.t.Helper()
+	return f.f.Delete(o)
+}
+
+// NewFakeTiltClient returns an in-memory client for Tilt API objects.
+func NewFakeTiltClient() ctrlclient.Client {
+	return fake.NewFakeTiltClient()
+}
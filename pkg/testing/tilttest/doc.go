@@ -0,0 +1,9 @@
+// Package tilttest provides fakes and fixtures for testing code that extends
+// or integrates with Tilt.
+//
+// The fakes wrap the ones that Tilt's own tests use, so they behave the way
+// Tilt expects. Their interfaces only use types from pkg/, the Kubernetes
+// controller libraries, and the Docker and Compose APIs, so code outside Tilt
+// can use them. The fixtures run on the same in-memory API server that Tilt's
+// own controller tests use.
+package tilttest
@@ -0,0 +1,158 @@
+package tilttest
+
+import (
+	"context"
+	"io"
+
+	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/volume"
+
+	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/docker"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// DockerClient is the part of Tilt's Docker client that works on
+// containers, images, and volumes.
+//
+// Building images and switching between Docker daemons stay internal to Tilt.
+type DockerClient interface {
+	ContainerInspect(ctx context.Context, containerID string) (types.ContainerJSON, error)
+	ContainerList(ctx context.Context, options types.ContainerListOptions) ([]types.Container, error)
+	ContainerRestartNoWait(ctx context.Context, containerID string) error
+
+	// Execute a command in a container, streaming the command output to `out`.
+	ExecInContainer(ctx context.Context, containerID string, cmd model.Cmd, in io.Reader, out io.Writer) error
+
+	ImagePull(ctx context.Context, ref reference.Named) (reference.Canonical, error)
+	ImagePush(ctx context.Context, ref reference.NamedTagged) (io.ReadCloser, error)
+	ImageTag(ctx context.Context, source, target string) error
+	ImageInspectWithRaw(ctx context.Context, imageID string) (types.ImageInspect, []byte, error)
+	ImageRemove(ctx context.Context, imageID string, options types.ImageRemoveOptions) ([]types.ImageDeleteResponseItem, error)
+
+	VolumeList(ctx context.Context, filter filters.Args) (volume.VolumeListOKBody, error)
+	VolumeRemove(ctx context.Context, volumeID string, force bool) error
+}
+
+// A call to FakeDockerClient.ExecInContainer.
+type DockerExecCall struct {
+	Container string
+	Cmd       model.Cmd
+}
+
+// FakeDockerClient records calls to Docker instead of making them,
+// and returns canned responses.
+//
+// Containers are running unless SetContainerState says otherwise.
+type FakeDockerClient struct {
+	f *docker.FakeClient
+}
+
+var _ DockerClient = &FakeDockerClient{}
+
+func NewFakeDockerClient() *FakeDockerClient {
+	return &FakeDockerClient{f: docker.NewFakeClient()}
+}
+
+// SetExecError makes the next call to ExecInContainer fail with err.
+func (c *FakeDockerClient) SetExecError(err error) {
+	c.f.SetExecError(err)
+}
+
+// SetContainerListOutput sets the containers that the next ContainerList
+// returns, keyed by the name filter.
+func (c *FakeDockerClient) SetContainerListOutput(output map[string][]types.Container) {
+	c.f.SetContainerListOutput(output)
+}
+
+func (c *FakeDockerClient) SetContainerState(containerID string, state types.ContainerState) {
+	c.f.Containers[containerID] = state
+}
+
+// SetImage adds an image for ImageInspectWithRaw to find.
+func (c *FakeDockerClient) SetImage(imageID string, inspect types.ImageInspect) {
+	c.f.Images[imageID] = inspect
+}
+
+// SetVolume adds a volume for VolumeList to find.
+func (c *FakeDockerClient) SetVolume(v *types.Volume) {
+	if c.f.Volumes == nil {
+		c.f.Volumes = map[string]*types.Volume{}
+	}
+	c.f.Volumes[v.Name] = v
+}
+
+func (c *FakeDockerClient) ContainerInspect(ctx context.Context, containerID string) (types.ContainerJSON, error) {
+	return c.f.ContainerInspect(ctx, containerID)
+}
+
+func (c *FakeDockerClient) ContainerList(ctx context.Context, options types.ContainerListOptions) ([]types.Container, error) {
+	return c.f.ContainerList(ctx, options)
+}
+
+func (c *FakeDockerClient) ContainerRestartNoWait(ctx context.Context, containerID string) error {
+	return c.f.ContainerRestartNoWait(ctx, containerID)
+}
+
+func (c *FakeDockerClient) ExecInContainer(ctx context.Context, containerID string, cmd model.Cmd, in io.Reader, out io.Writer) error {
+	return c.f.ExecInContainer(ctx, container.ID(containerID), cmd, in, out)
+}
+
+func (c *FakeDockerClient) ImagePull(ctx context.Context, ref reference.Named) (reference.Canonical, error) {
+	return c.f.ImagePull(ctx, ref)
+}
+
+func (c *FakeDockerClient) ImagePush(ctx context.Context, ref reference.NamedTagged) (io.ReadCloser, error) {
+	return c.f.ImagePush(ctx, ref)
+}
+
+func (c *FakeDockerClient) ImageTag(ctx context.Context, source, target string) error {
+	return c.f.ImageTag(ctx, source, target)
+}
+
+func (c *FakeDockerClient) ImageInspectWithRaw(ctx context.Context, imageID string) (types.ImageInspect, []byte, error) {
+	return c.f.ImageInspectWithRaw(ctx, imageID)
+}
+
+func (c *FakeDockerClient) ImageRemove(ctx context.Context, imageID string, options types.ImageRemoveOptions) ([]types.ImageDeleteResponseItem, error) {
+	return c.f.ImageRemove(ctx, imageID, options)
+}
+
+func (c *FakeDockerClient) VolumeList(ctx context.Context, filter filters.Args) (volume.VolumeListOKBody, error) {
+	return c.f.VolumeList(ctx, filter)
+}
+
+func (c *FakeDockerClient) VolumeRemove(ctx context.Context, volumeID string, force bool) error {
+	return c.f.VolumeRemove(ctx, volumeID, force)
+}
+
+// ExecCalls are the commands run in containers so far, oldest first.
+func (c *FakeDockerClient) ExecCalls() []DockerExecCall {
+	result := []DockerExecCall{}
+	for _, call := range c.f.ExecCalls {
+		result = append(result, DockerExecCall(call))
+	}
+	return result
+}
+
+// Restarts is how many times the container was restarted.
+func (c *FakeDockerClient) Restarts(containerID string) int {
+	return c.f.RestartsByContainer[containerID]
+}
+
+// PushCount is how many images were pushed.
+func (c *FakeDockerClient) PushCount() int {
+	return c.f.PushCount
+}
+
+// RemovedImageIDs are the images removed so far, sorted.
+func (c *FakeDockerClient) RemovedImageIDs() []string {
+	return append([]string{}, c.f.RemovedImageIDs...)
+}
+
+// RemovedVolumes are the volumes removed so far, sorted.
+func (c *FakeDockerClient) RemovedVolumes() []string {
+	return append([]string{}, c.f.RemovedVolumes...)
+}
@@ -0,0 +1,145 @@
+package tilttest
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/compose-spec/compose-go/types"
+
+	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/dockercompose"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+// DockerComposeClient runs Docker Compose commands for Tilt's
+// DockerComposeService and DockerComposeProject objects.
+type DockerComposeClient interface {
+	Up(ctx context.Context, spec v1alpha1.DockerComposeServiceSpec, shouldBuild, recreate bool, stdout, stderr io.Writer) error
+	Down(ctx context.Context, spec v1alpha1.DockerComposeProject, deleteVolumes bool, stdout, stderr io.Writer) error
+	Rm(ctx context.Context, specs []v1alpha1.DockerComposeServiceSpec, stdout, stderr io.Writer) error
+	StreamLogs(ctx context.Context, spec v1alpha1.DockerComposeServiceSpec) io.ReadCloser
+	StreamEvents(ctx context.Context, spec v1alpha1.DockerComposeProject) (<-chan string, error)
+	Project(ctx context.Context, spec v1alpha1.DockerComposeProject) (*types.Project, error)
+	ContainerID(ctx context.Context, spec v1alpha1.DockerComposeServiceSpec) (string, error)
+}
+
+// A call to FakeDockerComposeClient.Up.
+type DockerComposeUpCall struct {
+	Spec        v1alpha1.DockerComposeServiceSpec
+	ShouldBuild bool
+	Recreate    bool
+}
+
+// A call to FakeDockerComposeClient.Down.
+type DockerComposeDownCall struct {
+	Project       v1alpha1.DockerComposeProject
+	DeleteVolumes bool
+}
+
+// A call to FakeDockerComposeClient.Rm.
+type DockerComposeRmCall struct {
+	Specs []v1alpha1.DockerComposeServiceSpec
+}
+
+// FakeDockerComposeClient records Docker Compose commands instead of running
+// them, and returns canned output.
+type FakeDockerComposeClient struct {
+	f *dockercompose.FakeDCClient
+}
+
+var _ DockerComposeClient = &FakeDockerComposeClient{}
+
+func NewFakeDockerComposeClient(t *testing.T, ctx context.Context) *FakeDockerComposeClient {
+	return &FakeDockerComposeClient{f: dockercompose.NewFakeDockerComposeClient(t, ctx)}
+}
+
+// SetConfig sets the Compose YAML that Project loads.
+func (c *FakeDockerComposeClient) SetConfig(yaml string) {
+	c.f.ConfigOutput = yaml
+}
+
+// SetContainerID sets the container that ContainerID returns for every service.
+func (c *FakeDockerComposeClient) SetContainerID(id string) {
+	c.f.ContainerIdOutput = container.ID(id)
+}
+
+// SetLogs sets the log lines that StreamLogs returns for the service.
+// The log stream ends when the channel is closed.
+func (c *FakeDockerComposeClient) SetLogs(service string, lines <-chan string) {
+	c.f.RunLogOutput[service] = lines
+}
+
+// SetDownErr makes the next call to Down fail with err.
+func (c *FakeDockerComposeClient) SetDownErr(err error) {
+	c.f.DownError = err
+}
+
+// SetRmErr makes the next call to Rm fail with err.
+func (c *FakeDockerComposeClient) SetRmErr(err error) {
+	c.f.RmError = err
+}
+
+// SendContainerEvent sends a container event, like "start" or "die",
+// to everyone streaming events.
+func (c *FakeDockerComposeClient) SendContainerEvent(service, action, containerID string) error {
+	return c.f.SendEvent(dockercompose.Event{
+		Type:    dockercompose.TypeContainer,
+		Action:  action,
+		ID:      containerID,
+		Service: service,
+	})
+}
+
+func (c *FakeDockerComposeClient) Up(ctx context.Context, spec v1alpha1.DockerComposeServiceSpec, shouldBuild, recreate bool, stdout, stderr io.Writer) error {
+	return c.f.Up(ctx, spec, shouldBuild, recreate, stdout, stderr)
+}
+
+func (c *FakeDockerComposeClient) Down(ctx context.Context, spec v1alpha1.DockerComposeProject, deleteVolumes bool, stdout, stderr io.Writer) error {
+	return c.f.Down(ctx, spec, deleteVolumes, stdout, stderr)
+}
+
+func (c *FakeDockerComposeClient) Rm(ctx context.Context, specs []v1alpha1.DockerComposeServiceSpec, stdout, stderr io.Writer) error {
+	return c.f.Rm(ctx, specs, stdout, stderr)
+}
+
+func (c *FakeDockerComposeClient) StreamLogs(ctx context.Context, spec v1alpha1.DockerComposeServiceSpec) io.ReadCloser {
+	return c.f.StreamLogs(ctx, spec)
+}
+
+func (c *FakeDockerComposeClient) StreamEvents(ctx context.Context, spec v1alpha1.DockerComposeProject) (<-chan string, error) {
+	return c.f.StreamEvents(ctx, spec)
+}
+
+func (c *FakeDockerComposeClient) Project(ctx context.Context, spec v1alpha1.DockerComposeProject) (*types.Project, error) {
+	return c.f.Project(ctx, spec)
+}
+
+func (c *FakeDockerComposeClient) ContainerID(ctx context.Context, spec v1alpha1.DockerComposeServiceSpec) (string, error) {
+	id, err := c.f.ContainerID(ctx, spec)
+	return id.String(), err
+}
+
+func (c *FakeDockerComposeClient) UpCalls() []DockerComposeUpCall {
+	result := []DockerComposeUpCall{}
+	for _, call := range c.f.UpCalls() {
+		result = append(result, DockerComposeUpCall(call))
+	}
+	return result
+}
+
+func (c *FakeDockerComposeClient) DownCalls() []DockerComposeDownCall {
+	result := []DockerComposeDownCall{}
+	for _, call := range c.f.DownCalls() {
+		result = append(result, DockerComposeDownCall{Project: call.Proj, DeleteVolumes: call.DeleteVolumes})
+	}
+	return result
+}
+
+func (c *FakeDockerComposeClient) RmCalls() []DockerComposeRmCall {
+	result := []DockerComposeRmCall{}
+	for _, call := range c.f.RmCalls() {
+		result = append(result, DockerComposeRmCall(call))
+	}
+	return result
+}
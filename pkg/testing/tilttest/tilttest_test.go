@@ -0,0 +1,115 @@
+package tilttest_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model"
+	"github.com/tilt-dev/tilt/pkg/testing/tilttest"
+)
+
+// Copies the "greeting" key of each ConfigMap into a "reply" key.
+type replyReconciler struct {
+	client ctrlclient.Client
+}
+
+func (r *replyReconciler) CreateBuilder(mgr ctrl.Manager) (*builder.Builder, error) {
+	return ctrl.NewControllerManagedBy(mgr).For(&v1alpha1.ConfigMap{}), nil
+}
+
+func (r *replyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	var cm v1alpha1.ConfigMap
+	err := r.client.Get(ctx, req.NamespacedName, &cm)
+	if err != nil {
+		return ctrl.Result{}, ctrlclient.IgnoreNotFound(err)
+	}
+	if cm.Data["reply"] == cm.Data["greeting"] {
+		return ctrl.Result{}, nil
+	}
+	cm.Data["reply"] = cm.Data["greeting"]
+	return ctrl.Result{}, r.client.Update(ctx, &cm)
+}
+
+func TestControllerFixture(t *testing.T) {
+	cfb := tilttest.NewControllerFixtureBuilder(t)
+	f := cfb.Build(&replyReconciler{client: cfb.Client()})
+
+	f.Create(&v1alpha1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "hello"},
+		Data:       map[string]string{"greeting": "hi"},
+	})
+
+	var cm v1alpha1.ConfigMap
+	f.MustGet(types.NamespacedName{Name: "hello"}, &cm)
+	assert.Equal(t, "hi", cm.Data["reply"])
+}
+
+func TestControllerFixtureDelete(t *testing.T) {
+	cfb := tilttest.NewControllerFixtureBuilder(t)
+	f := cfb.Build(&replyReconciler{client: cfb.Client()})
+
+	cm := &v1alpha1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "hello"},
+		Data:       map[string]string{"greeting": "hi"},
+	}
+	f.Create(cm)
+
+	deleted, _ := f.Delete(cm)
+	assert.True(t, deleted)
+	assert.False(t, f.Get(types.NamespacedName{Name: "hello"}, &v1alpha1.ConfigMap{}))
+
+	deleted, _ = f.Delete(cm)
+	assert.False(t, deleted)
+}
+
+func TestFakeDockerClient(t *testing.T) {
+	dCli := tilttest.NewFakeDockerClient()
+	err := dCli.ExecInContainer(context.Background(), "my-container",
+		model.ToUnixCmd("echo hi"), nil, &bytes.Buffer{})
+	assert.NoError(t, err)
+	assert.Equal(t, []tilttest.DockerExecCall{
+		{Container: "my-container", Cmd: model.ToUnixCmd("echo hi")},
+	}, dCli.ExecCalls())
+
+	assert.NoError(t, dCli.ContainerRestartNoWait(context.Background(), "my-container"))
+	assert.Equal(t, 1, dCli.Restarts("my-container"))
+}
+
+func TestFakeDockerComposeClient(t *testing.T) {
+	ctx := context.Background()
+	dcCli := tilttest.NewFakeDockerComposeClient(t, ctx)
+	dcCli.SetContainerID("db-container")
+
+	spec := v1alpha1.DockerComposeServiceSpec{Service: "db"}
+	err := dcCli.Up(ctx, spec, false, false, &bytes.Buffer{}, &bytes.Buffer{})
+	assert.NoError(t, err)
+	assert.Equal(t, []tilttest.DockerComposeUpCall{{Spec: spec}}, dcCli.UpCalls())
+
+	id, err := dcCli.ContainerID(ctx, spec)
+	assert.NoError(t, err)
+	assert.Equal(t, "db-container", id)
+}
+
+func TestFakeContainerUpdater(t *testing.T) {
+	cu := tilttest.NewFakeContainerUpdater()
+	cu.SetUpdateErr(errors.New("oh no"))
+
+	c := tilttest.Container{PodID: "pod", ContainerID: "cid", ContainerName: "app", Namespace: "default"}
+	err := cu.UpdateContainer(context.Background(), c,
+		&bytes.Buffer{}, []string{"/app/old.txt"}, nil, false)
+	assert.EqualError(t, err, "oh no")
+	if assert.Len(t, cu.Calls(), 1) {
+		assert.Equal(t, c, cu.Calls()[0].Container)
+		assert.Equal(t, []string{"/app/old.txt"}, cu.Calls()[0].ToDelete)
+	}
+}
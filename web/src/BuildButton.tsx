@@ -17,7 +17,8 @@ export type StartBuildButtonProps = {
   isSelected?: boolean
   hasPendingChanges: boolean
   isQueued: boolean
-  onStartBuild: () => void
+  // Shift-clicking the button starts a build without the cache.
+  onStartBuild: (noCache?: boolean) => void
   analyticsTags: Tags
   className?: string
 }
@@ -103,7 +104,7 @@ function StartBuildButton(props: StartBuildButtonProps) {
       // stopPropagation prevents the overview card from opening.
       e.stopPropagation()
 
-      props.onStartBuild()
+      props.onStartBuild(e.shiftKey)
     },
     [props.onStartBuild]
   )
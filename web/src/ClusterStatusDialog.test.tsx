@@ -4,6 +4,9 @@ import {
   ClusterStatusDialog,
   ClusterStatusDialogProps,
   CLUSTER_STATUS_HEALTHY,
+  clusterHealthStatus,
+  dockerPruneSummary,
+  formatBytes,
   getDefaultCluster,
 } from "./ClusterStatusDialog"
 import { clusterConnection } from "./testdata"
@@ -106,6 +109,114 @@ describe("ClusterStatusDialog", () => {
     expect(screen.queryByTestId("healthy-icon")).toBeNull()
   })
 
+  it("displays the slow response message with the unhealthy icon if the cluster is slow", () => {
+    const cluster = clusterConnection()
+    cluster.status!.conditions = [
+      { type: "Reachable", status: "True", reason: "Reachable" },
+      {
+        type: "Responsive",
+        status: "False",
+        reason: "SlowResponse",
+        message: "Cluster took 4.2s to respond",
+      },
+    ]
+
+    render(
+      <ClusterStatusDialog
+        {...DEFAULT_TEST_PROPS}
+        clusterConnection={cluster}
+      />
+    )
+
+    expect(screen.getByTestId("unhealthy-icon")).toBeTruthy()
+    expect(screen.getByText("Cluster took 4.2s to respond")).toBeTruthy()
+  })
+
+  describe("clusterHealthStatus", () => {
+    it("prefers the error over the conditions", () => {
+      const cluster = clusterConnection("connection refused")
+      cluster.status!.conditions = [
+        { type: "Responsive", status: "False", message: "slow" },
+      ]
+      expect(clusterHealthStatus(cluster.status)).toEqual("connection refused")
+    })
+
+    it("is healthy if the cluster is responsive", () => {
+      const cluster = clusterConnection()
+      cluster.status!.conditions = [{ type: "Responsive", status: "True" }]
+      expect(clusterHealthStatus(cluster.status)).toEqual(
+        CLUSTER_STATUS_HEALTHY
+      )
+    })
+  })
+
+  it("does NOT render Docker prune history if there is none", () => {
+    render(
+      <ClusterStatusDialog
+        {...DEFAULT_TEST_PROPS}
+        clusterConnection={HEALTHY_CLUSTER}
+      />
+    )
+
+    expect(screen.queryByLabelText("Docker prune history")).toBeNull()
+  })
+
+  it("renders Docker prune history, most recent first", () => {
+    const cluster = clusterConnection()
+    cluster.status!.dockerPrune = {
+      runs: [
+        {
+          startTime: "2022-01-01T00:00:00Z",
+          finishTime: "2022-01-01T00:00:01Z",
+          imagesDeleted: 1,
+          spaceReclaimed: "1000",
+        },
+        {
+          startTime: "2022-01-01T01:00:00Z",
+          finishTime: "2022-01-01T01:00:01Z",
+          error: "Cannot connect to the Docker daemon",
+        },
+      ],
+    }
+
+    render(
+      <ClusterStatusDialog
+        {...DEFAULT_TEST_PROPS}
+        clusterConnection={cluster}
+      />
+    )
+
+    const items = screen.getAllByRole("listitem").map((li) => li.textContent)
+    expect(items).toHaveLength(2)
+    expect(items[0]).toContain("Cannot connect to the Docker daemon")
+    expect(items[1]).toContain(
+      "Removed 1 image, 0 containers, 0 caches; reclaimed 1kB"
+    )
+  })
+
+  describe("dockerPruneSummary", () => {
+    it("only mentions volumes if any were removed", () => {
+      expect(
+        dockerPruneSummary({
+          imagesDeleted: 2,
+          containersDeleted: 1,
+          cachesDeleted: 3,
+          spaceReclaimed: "2500000",
+        })
+      ).toEqual("Removed 2 images, 1 container, 3 caches; reclaimed 2.5MB")
+      expect(dockerPruneSummary({ volumesDeleted: 1 })).toEqual(
+        "Removed 0 images, 0 containers, 0 caches, 1 volume; reclaimed 0B"
+      )
+    })
+
+    it("formats bytes", () => {
+      expect(formatBytes(0)).toEqual("0B")
+      expect(formatBytes(999)).toEqual("999B")
+      expect(formatBytes(1234567)).toEqual("1.2MB")
+      expect(formatBytes(5e12)).toEqual("5TB")
+    })
+  })
+
   describe("getDefaultCluster", () => {
     const defaultCluster = clusterConnection()
     const nonDefaultClusterA = clusterConnection()
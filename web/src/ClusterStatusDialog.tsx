@@ -1,4 +1,5 @@
 import React from "react"
+import TimeAgo from "react-timeago"
 import styled from "styled-components"
 import { ReactComponent as HealthySvg } from "./assets/svg/checkmark-small.svg"
 import { ReactComponent as UnhealthySvg } from "./assets/svg/close.svg"
@@ -8,7 +9,8 @@ import FloatDialog, {
 } from "./FloatDialog"
 import SrOnly from "./SrOnly"
 import { Color, FontSize, SizeUnit } from "./style-helpers"
-import { Cluster } from "./types"
+import { timeAgoFormatter } from "./timeFormatters"
+import { Cluster, DockerPruneRun } from "./types"
 
 export type ClusterStatusDialogProps = {
   clusterConnection?: Cluster
@@ -79,6 +81,35 @@ const ClusterPropertyList = styled.dl`
   }
 `
 
+const DockerPruneHeading = styled.h3`
+  font-size: ${FontSize.small};
+  font-weight: normal;
+  margin: ${SizeUnit(0.5)} 0 ${SizeUnit(0.25)};
+  text-decoration: underline;
+  text-underline-position: under;
+`
+
+const DockerPruneList = styled.ul`
+  list-style: none;
+  margin: unset;
+  padding: unset;
+`
+
+const DockerPruneItem = styled.li`
+  font-size: ${FontSize.small};
+`
+
+const DockerPruneTime = styled.span`
+  color: ${Color.gray50};
+  display: block;
+  font-size: ${FontSize.smallest};
+`
+
+const DockerPruneError = styled.span`
+  color: ${Color.red};
+  display: block;
+`
+
 export function getDefaultCluster(clusters?: Cluster[]): Cluster | undefined {
   if (!clusters || !clusters.length) {
     return
@@ -137,6 +168,80 @@ function K8sClusterProperties({
   )
 }
 
+const BYTE_UNITS = ["B", "kB", "MB", "GB", "TB"]
+
+export function formatBytes(bytes: number): string {
+  let value = bytes
+  let unit = 0
+  while (value >= 1000 && unit < BYTE_UNITS.length - 1) {
+    value /= 1000
+    unit++
+  }
+  const rounded = unit === 0 ? value : Math.round(value * 10) / 10
+  return `${rounded}${BYTE_UNITS[unit]}`
+}
+
+function pluralize(count: number, noun: string) {
+  return `${count} ${noun}${count === 1 ? "" : "s"}`
+}
+
+export function dockerPruneSummary(run: DockerPruneRun): string {
+  const removed = [
+    pluralize(run.imagesDeleted ?? 0, "image"),
+    pluralize(run.containersDeleted ?? 0, "container"),
+    pluralize(run.cachesDeleted ?? 0, "cache"),
+  ]
+  if (run.volumesDeleted) {
+    removed.push(pluralize(run.volumesDeleted, "volume"))
+  }
+  const reclaimed = formatBytes(Number(run.spaceReclaimed ?? 0))
+  return `Removed ${removed.join(", ")}; reclaimed ${reclaimed}`
+}
+
+function DockerPruneHistory({
+  clusterStatus,
+}: {
+  clusterStatus?: Cluster["status"]
+}) {
+  const runs = clusterStatus?.dockerPrune?.runs
+  if (!runs?.length) {
+    return null
+  }
+
+  // Show the most recent run first.
+  const items = [...runs].reverse().map((run, i) => (
+    <DockerPruneItem key={`${run.startTime}-${i}`}>
+      <DockerPruneTime>
+        <TimeAgo date={run.finishTime ?? ""} formatter={timeAgoFormatter} />
+      </DockerPruneTime>
+      {dockerPruneSummary(run)}
+      {run.error ? <DockerPruneError>{run.error}</DockerPruneError> : null}
+    </DockerPruneItem>
+  ))
+
+  return (
+    <section aria-label="Docker prune history">
+      <DockerPruneHeading>Docker Prune</DockerPruneHeading>
+      <DockerPruneList>{items}</DockerPruneList>
+    </section>
+  )
+}
+
+// The status line for the cluster: its error if it has one, or how
+// it's degraded if it's slow to respond, or healthy.
+export function clusterHealthStatus(clusterStatus?: Cluster["status"]) {
+  if (clusterStatus?.error) {
+    return clusterStatus.error
+  }
+  const responsive = clusterStatus?.conditions?.find(
+    (c) => c.type === "Responsive"
+  )
+  if (responsive?.status === "False") {
+    return responsive.message || "Degraded"
+  }
+  return CLUSTER_STATUS_HEALTHY
+}
+
 export function ClusterStatusDialog(props: ClusterStatusDialogProps) {
   const { open, onClose, anchorEl, clusterConnection } = props
 
@@ -144,8 +249,7 @@ export function ClusterStatusDialog(props: ClusterStatusDialogProps) {
     return null
   }
 
-  const clusterStatus =
-    clusterConnection.status?.error ?? CLUSTER_STATUS_HEALTHY
+  const clusterStatus = clusterHealthStatus(clusterConnection.status)
   const clusterStatusIcon =
     clusterStatus === CLUSTER_STATUS_HEALTHY ? (
       <HealthyIcon role="presentation" data-testid="healthy-icon" />
@@ -174,6 +278,7 @@ export function ClusterStatusDialog(props: ClusterStatusDialogProps) {
       anchorEl={anchorEl}
     >
       <K8sClusterProperties clusterStatus={clusterConnection.status} />
+      <DockerPruneHistory clusterStatus={clusterConnection.status} />
     </FloatDialog>
   )
 }
@@ -0,0 +1,22 @@
+import { render, screen } from "@testing-library/react"
+import React from "react"
+import DownloadLogs from "./DownloadLogs"
+import { ResourceName } from "./types"
+
+describe("DownloadLogs", () => {
+  it("links to the logs of all resources", () => {
+    render(<DownloadLogs resourceName={ResourceName.all} />)
+
+    const link = screen.getByRole("link", { name: "Download All Logs" })
+    expect(link.getAttribute("href")).toEqual("/api/logs/archive")
+  })
+
+  it("links to the logs of one resource", () => {
+    render(<DownloadLogs resourceName="my-app" />)
+
+    const link = screen.getByRole("link", { name: "Download Logs" })
+    expect(link.getAttribute("href")).toEqual(
+      "/api/logs/archive?resources=my-app"
+    )
+  })
+})
@@ -0,0 +1,53 @@
+import React from "react"
+import styled from "styled-components"
+import { InstrumentedButton } from "./instrumentedComponents"
+import {
+  AnimDuration,
+  Color,
+  FontSize,
+  mixinResetButtonStyle,
+} from "./style-helpers"
+import { ResourceName } from "./types"
+
+const DownloadLogsButton = styled(InstrumentedButton)`
+  ${mixinResetButtonStyle};
+  margin-left: 1rem;
+  font-size: ${FontSize.small};
+  color: ${Color.white};
+  transition: color ${AnimDuration.default} ease;
+
+  &:hover {
+    color: ${Color.blue};
+  }
+`
+
+export interface DownloadLogsProps {
+  resourceName: string
+}
+
+// The server keeps the complete logs, even the ones that
+// were cleared or truncated in the browser.
+export const logArchiveURL = (resourceName: string) => {
+  if (resourceName === ResourceName.all) {
+    return "/api/logs/archive"
+  }
+  return `/api/logs/archive?resources=${encodeURIComponent(resourceName)}`
+}
+
+const DownloadLogs: React.FC<DownloadLogsProps> = ({ resourceName }) => {
+  const all = resourceName === ResourceName.all
+  const label = all ? "Download All Logs" : "Download Logs"
+
+  return (
+    <DownloadLogsButton
+      href={logArchiveURL(resourceName)}
+      download
+      analyticsName="ui.web.downloadLogs"
+      analyticsTags={{ all: all.toString() }}
+    >
+      {label}
+    </DownloadLogsButton>
+  )
+}
+
+export default DownloadLogs
@@ -1,5 +1,9 @@
 export class Hold {
   reason: string
+  // A stable code from the message catalog (e.g., WaitingForDependency),
+  // and the message rendered in English.
+  messageCode: string
+  message: string
   count: number = 0
   resources: string[] = []
   images: string[] = []
@@ -7,6 +11,8 @@ export class Hold {
 
   constructor(waiting: Proto.v1alpha1UIResourceStateWaiting) {
     this.reason = waiting.reason ?? ""
+    this.messageCode = waiting.messageCode ?? ""
+    this.message = waiting.message ?? ""
     for (const ref of waiting.on ?? []) {
       this.count++
       if (ref.kind === "UIResource" && ref.name) {
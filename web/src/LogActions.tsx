@@ -2,6 +2,7 @@ import React, { useEffect } from "react"
 import { useStorageState } from "react-storage-hooks"
 import styled from "styled-components"
 import ClearLogs from "./ClearLogs"
+import DownloadLogs from "./DownloadLogs"
 import { InstrumentedButton } from "./instrumentedComponents"
 import {
   AnimDuration,
@@ -120,6 +121,7 @@ const LogActions: React.FC<LogActionsProps> = ({
   return (
     <LogActionsGroup>
       <LogsFontSize />
+      {isSnapshot || <DownloadLogs resourceName={resourceName} />}
       {isSnapshot || <ClearLogs resourceName={resourceName} />}
     </LogActionsGroup>
   )
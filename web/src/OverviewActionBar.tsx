@@ -16,6 +16,7 @@ import { ReactComponent as CheckmarkSvg } from "./assets/svg/checkmark.svg"
 import { ReactComponent as CloseSvg } from "./assets/svg/close.svg"
 import { ReactComponent as CopySvg } from "./assets/svg/copy.svg"
 import { ReactComponent as FilterSvg } from "./assets/svg/filter.svg"
+import FloatDialog from "./FloatDialog"
 import { ReactComponent as LinkSvg } from "./assets/svg/link.svg"
 import {
   InstrumentedButton,
@@ -626,6 +627,47 @@ export function CopyButton(props: CopyButtonProps) {
   )
 }
 
+let PendingDiffText = styled.pre`
+  font-family: ${Font.monospace};
+  font-size: ${FontSize.smallest};
+  line-height: 1.5;
+  max-height: 60vh;
+  overflow: auto;
+  margin: 0;
+`
+
+type PendingDiffButtonProps = {
+  resourceName: string
+  diff: string
+}
+
+// Shows the changes the next apply will make to the cluster,
+// so that they can be reviewed before triggering an update.
+export function PendingDiffButton(props: PendingDiffButtonProps) {
+  let [anchorEl, setAnchorEl] = useState<Element | null>(null)
+
+  return (
+    <>
+      <ButtonRoot
+        onClick={(e) => setAnchorEl(e.currentTarget)}
+        analyticsName="ui.web.actionBar.pendingDiff"
+        aria-label={`Show pending changes for ${props.resourceName}`}
+      >
+        Pending Changes
+      </ButtonRoot>
+      <FloatDialog
+        id="pendingDiff"
+        title={`Pending changes: ${props.resourceName}`}
+        open={!!anchorEl}
+        anchorEl={anchorEl}
+        onClose={() => setAnchorEl(null)}
+      >
+        <PendingDiffText>{props.diff}</PendingDiffText>
+      </FloatDialog>
+    </>
+  )
+}
+
 let ActionBarRoot = styled.div`
   background-color: ${Color.gray10};
 `
@@ -715,6 +757,7 @@ export default function OverviewActionBar(props: OverviewActionBarProps) {
 
   let endpoints = resource?.status?.endpointLinks || []
   let podId = resource?.status?.k8sResourceInfo?.podName || ""
+  let pendingDiff = resource?.status?.k8sResourceInfo?.pendingDiff || ""
   const resourceName = resource
     ? resource.metadata?.name || ""
     : ResourceName.all
@@ -753,6 +796,15 @@ export default function OverviewActionBar(props: OverviewActionBarProps) {
   if (podId && !isDisabled) {
     topRowEls.push(<CopyButton podId={podId} key="copyPodId" />)
   }
+  if (pendingDiff && !isDisabled) {
+    topRowEls.push(
+      <PendingDiffButton
+        resourceName={resourceName}
+        diff={pendingDiff}
+        key="pendingDiff"
+      />
+    )
+  }
 
   const widgets = OverviewWidgets({ buttons: buttons?.default })
   if (widgets && !isDisabled) {
@@ -36,8 +36,10 @@ import Features, { Flag, useFeatures } from "./feature"
 import { Hold } from "./Hold"
 import {
   getResourceLabels,
+  groupDisplayName,
   GroupByLabelView,
-  orderLabels,
+  groupResources,
+  nestLabels,
   TILTFILE_LABEL,
   UNLABELED_LABEL,
 } from "./labels"
@@ -67,7 +69,7 @@ import { useResourceSelection } from "./ResourceSelectionContext"
 import { resourceIsDisabled, resourceTargetType } from "./ResourceStatus"
 import { TableGroupStatusSummary } from "./ResourceStatusSummary"
 import { ShowMoreButton } from "./ShowMoreButton"
-import { buildStatus, runtimeStatus } from "./status"
+import { buildStatus, LastBuildDescription, runtimeStatus } from "./status"
 import { Color, Font, FontSize, SizeUnit } from "./style-helpers"
 import { isZeroTime, timeDiff } from "./time"
 import {
@@ -91,6 +93,8 @@ type TableWrapperProps = {
 
 type TableGroupProps = {
   label: string
+  // Set for label groups, so that nested groups can be found
+  view?: GroupByLabelView<RowValues>
   setGlobalSortBy: (id: string) => void
   focused: string
 } & TableOptions<RowValues>
@@ -277,6 +281,11 @@ export const OverviewGroupName = styled.span`
 
 export const OverviewGroupDetails = styled(AccordionDetails)`
   ${AccordionDetailsStyleResetMixin}
+
+  /* Indent nested groups under their parent */
+  ${OverviewGroup} {
+    margin-left: ${SizeUnit(1)};
+  }
 `
 const TABLE_TYPE_TAGS: Tags = { type: AnalyticsType.Grid }
 
@@ -426,6 +435,7 @@ function uiResourceToCell(
       buildStatus: buildStatus(r, alertIndex),
       buildAlertCount: buildAlerts(r, alertIndex).length,
       lastBuildDur: lastBuildDur,
+      lastBuildReport: LastBuildDescription(lastBuild),
       runtimeStatus: runtimeStatus(r, alertIndex),
       runtimeAlertCount: runtimeAlerts(r, alertIndex).length,
       hold: res.waiting ? new Hold(res.waiting) : null,
@@ -499,7 +509,7 @@ export function labeledResourcesToTableCells(
   const tiltfile: RowValues[] = []
 
   if (resources === undefined) {
-    return { labels: [], labelsToResources, tiltfile, unlabeled }
+    return nestLabels(labelsToResources, tiltfile, unlabeled)
   }
 
   resources.forEach((r) => {
@@ -521,10 +531,7 @@ export function labeledResourcesToTableCells(
     }
   })
 
-  // Labels are always displayed in sorted order
-  const labels = orderLabels(Object.keys(labelsToResources))
-
-  return { labels, labelsToResources, tiltfile, unlabeled }
+  return nestLabels(labelsToResources, tiltfile, unlabeled)
 }
 
 export function ResourceTableHeadRow({
@@ -726,13 +733,16 @@ export function Table(props: TableProps) {
 }
 
 function TableGroup(props: TableGroupProps) {
-  const { label, ...tableProps } = props
+  const { label, view, ...tableProps } = props
+  const allRows = view ? groupResources(view, label) : tableProps.data
 
-  if (tableProps.data.length === 0) {
+  if (allRows.length === 0) {
     return null
   }
 
-  const formattedLabel = label === UNLABELED_LABEL ? <em>{label}</em> : label
+  const displayName = groupDisplayName(label)
+  const formattedLabel =
+    label === UNLABELED_LABEL ? <em>{displayName}</em> : displayName
   const labelNameId = `tableOverview-${label}`
 
   const { getGroup, toggleGroupExpanded } = useResourceGroups()
@@ -740,6 +750,8 @@ function TableGroup(props: TableGroupProps) {
   const handleChange = (_e: ChangeEvent<{}>) =>
     toggleGroupExpanded(label, AnalyticsType.Grid)
 
+  const subgroups = view?.subgroups[label] ?? []
+
   return (
     <OverviewGroup expanded={expanded} onChange={handleChange}>
       <OverviewGroupSummary id={labelNameId}>
@@ -747,11 +759,20 @@ function TableGroup(props: TableGroupProps) {
         <OverviewGroupName>{formattedLabel}</OverviewGroupName>
         <TableGroupStatusSummary
           labelText={`Status summary for ${label} group`}
-          resources={tableProps.data}
+          resources={allRows}
         />
       </OverviewGroupSummary>
       <OverviewGroupDetails>
         <Table {...tableProps} />
+        {subgroups.map((subgroup) => (
+          <TableGroup
+            {...tableProps}
+            key={subgroup}
+            label={subgroup}
+            view={view}
+            data={view?.labelsToResources[subgroup] ?? []}
+          />
+        ))}
       </OverviewGroupDetails>
     </OverviewGroup>
   )
@@ -769,10 +790,12 @@ export function TableGroupedByLabels({
   )
 
   const totalOrder = useMemo(() => {
-    let totalOrder = []
-    data.labels.forEach((label) =>
+    let totalOrder: RowValues[] = []
+    const addGroupOrder = (label: string) => {
       totalOrder.push(...enabledRowsFirst(data.labelsToResources[label]))
-    )
+      ;(data.subgroups[label] ?? []).forEach(addGroupOrder)
+    }
+    data.labels.forEach(addGroupOrder)
     totalOrder.push(...enabledRowsFirst(data.unlabeled))
     totalOrder.push(...enabledRowsFirst(data.tiltfile))
     return totalOrder
@@ -801,6 +824,7 @@ export function TableGroupedByLabels({
         <TableGroup
           key={label}
           label={label}
+          view={data}
           data={data.labelsToResources[label]}
           columns={COLUMNS}
           useControlledState={useControlledState}
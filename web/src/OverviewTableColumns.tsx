@@ -49,6 +49,7 @@ type OverviewTableResourceStatus = {
   buildStatus: ResourceStatus
   buildAlertCount: number
   lastBuildDur: moment.Duration | null
+  lastBuildReport?: string
   runtimeStatus: ResourceStatus
   runtimeAlertCount: number
   hold?: Hold | null
@@ -375,7 +376,7 @@ export function TableBuildButtonColumn({ row }: CellProps<RowValues>) {
 
   const trigger = row.original.trigger
   let onStartBuild = useCallback(
-    () => startBuild(row.values.name),
+    (noCache?: boolean) => startBuild(row.values.name, noCache),
     [row.values.name]
   )
   return (
@@ -438,6 +439,7 @@ export function TableStatusColumn({ row }: CellProps<RowValues>) {
       <OverviewTableStatus
         status={status.buildStatus}
         lastBuildDur={status.lastBuildDur}
+        lastBuildReport={status.lastBuildReport}
         isBuild={true}
         resourceName={row.values.name}
         hold={status.hold}
@@ -4,7 +4,12 @@ import styled from "styled-components"
 import { AnalyticsType } from "./analytics"
 import { Flag, useFeatures } from "./feature"
 import { InstrumentedCheckbox } from "./instrumentedComponents"
-import { getResourceLabels, TILTFILE_LABEL, UNLABELED_LABEL } from "./labels"
+import {
+  getResourceLabels,
+  TILTFILE_LABEL,
+  UNLABELED_LABEL,
+  withParentGroups,
+} from "./labels"
 import { CollapseButton, ExpandButton } from "./resourceListOptionsButtons"
 import { useResourceListOptions } from "./ResourceListOptionsContext"
 import { resourceIsDisabled } from "./ResourceStatus"
@@ -54,7 +59,8 @@ function toGroups(
     }
   })
 
-  let groups = Object.keys(hasLabels)
+  // Nested groups are collapsed and expanded along with their parents
+  let groups = withParentGroups(Object.keys(hasLabels))
   if (groups.length) {
     if (hasTiltfile) {
       groups.push(TILTFILE_LABEL)
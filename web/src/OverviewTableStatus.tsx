@@ -85,12 +85,14 @@ type OverviewTableStatusProps = {
   status: ResourceStatus
   resourceName: string
   lastBuildDur?: moment.Duration | null
+  lastBuildReport?: string
   isBuild?: boolean
   hold?: Hold | null
 }
 
 export default function OverviewTableStatus(props: OverviewTableStatusProps) {
-  let { status, lastBuildDur, isBuild, resourceName, hold } = props
+  let { status, lastBuildDur, lastBuildReport, isBuild, resourceName, hold } =
+    props
   let icon = null
   let msg = ""
   let tooltip = ""
@@ -130,6 +132,7 @@ export default function OverviewTableStatus(props: OverviewTableStatusProps) {
         />
       )
       msg = isBuild ? `Updated${buildDurText}` : "Runtime Ready"
+      tooltip = isBuild ? lastBuildReport ?? "" : ""
       classes = "is-warning"
       break
     }
@@ -140,6 +143,7 @@ export default function OverviewTableStatus(props: OverviewTableStatusProps) {
         : ""
       icon = <CheckmarkSmallSvg role="presentation" />
       msg = isBuild ? `Updated${buildDurText}` : "Runtime Ready"
+      tooltip = isBuild ? lastBuildReport ?? "" : ""
       classes = "is-healthy"
       break
 
@@ -13,8 +13,10 @@ import {
 } from "./constants"
 import { FeaturesContext, Flag, useFeatures } from "./feature"
 import {
+  groupDisplayName,
   GroupByLabelView,
-  orderLabels,
+  groupResources,
+  nestLabels,
   TILTFILE_LABEL,
   UNLABELED_LABEL,
 } from "./labels"
@@ -167,6 +169,11 @@ const SidebarGroupDetails = styled(AccordionDetails)`
       margin-right: unset;
     }
   }
+
+  /* Indent nested groups under their parent */
+  ${SidebarLabelSection} {
+    margin-left: ${SizeUnit(1 / 2)};
+  }
 `
 
 const GROUP_INFO_TOOLTIP_ID = "sidebar-groups-info"
@@ -294,19 +301,28 @@ function SidebarListSectionItems(props: SidebarSectionProps) {
   )
 }
 
-function SidebarGroupListSection(props: { label: string } & SidebarProps) {
-  if (props.items.length === 0) {
+type SidebarGroupListSectionProps = SidebarProps & {
+  label: string
+  // Set for label groups, so that nested groups can be found
+  view?: GroupByLabelView<SidebarItem>
+}
+
+function SidebarGroupListSection(props: SidebarGroupListSectionProps) {
+  const { label, view, ...sidebarProps } = props
+  const allItems = view ? groupResources(view, label) : props.items
+  if (allItems.length === 0) {
     return null
   }
 
+  const displayName = groupDisplayName(label)
   const formattedLabel =
-    props.label === UNLABELED_LABEL ? <em>{props.label}</em> : props.label
-  const labelNameId = `sidebarItem-${props.label}`
+    label === UNLABELED_LABEL ? <em>{displayName}</em> : displayName
+  const labelNameId = `sidebarItem-${label}`
 
   const { getGroup, toggleGroupExpanded } = useResourceGroups()
-  let { expanded } = getGroup(props.label)
+  let { expanded } = getGroup(label)
 
-  let isSelected = props.items.some((item) => item.name == props.selected)
+  let isSelected = allItems.some((item) => item.name == props.selected)
 
   if (isSelected) {
     // If an item in the group is selected, expand the group
@@ -319,7 +335,9 @@ function SidebarGroupListSection(props: { label: string } & SidebarProps) {
   }
 
   const handleChange = (_e: ChangeEvent<{}>) =>
-    toggleGroupExpanded(props.label, AnalyticsType.Detail)
+    toggleGroupExpanded(label, AnalyticsType.Detail)
+
+  const subgroups = view?.subgroups[label] ?? []
 
   // TODO (lizz): Improve the accessibility interface for accordion feature by adding focus styles
   // according to https://www.w3.org/TR/wai-aria-practices-1.1/examples/accordion/accordion.html
@@ -329,12 +347,21 @@ function SidebarGroupListSection(props: { label: string } & SidebarProps) {
         <ResourceGroupSummaryIcon role="presentation" />
         <SidebarGroupName>{formattedLabel}</SidebarGroupName>
         <SidebarGroupStatusSummary
-          labelText={`Status summary for ${props.label} group`}
-          resources={props.items}
+          labelText={`Status summary for ${label} group`}
+          resources={allItems}
         />
       </SidebarGroupSummary>
       <SidebarGroupDetails aria-labelledby={labelNameId}>
-        <SidebarListSection {...props} />
+        {props.items.length > 0 && <SidebarListSection {...sidebarProps} />}
+        {subgroups.map((subgroup) => (
+          <SidebarGroupListSection
+            {...sidebarProps}
+            key={`sidebarItem-${subgroup}`}
+            label={subgroup}
+            view={view}
+            items={view?.labelsToResources[subgroup] ?? []}
+          />
+        ))}
       </SidebarGroupDetails>
     </SidebarLabelSection>
   )
@@ -363,25 +390,23 @@ function resourcesLabelView(
     }
   })
 
-  // Labels are always displayed in sorted order
-  const labels = orderLabels(Object.keys(labelsToResources))
-
-  return { labels, labelsToResources, tiltfile, unlabeled }
+  return nestLabels(labelsToResources, tiltfile, unlabeled)
 }
 
 function SidebarGroupedByLabels(props: SidebarGroupedByProps) {
-  const { labels, labelsToResources, tiltfile, unlabeled } = resourcesLabelView(
-    props.items
-  )
+  const view = resourcesLabelView(props.items)
+  const { labels, labelsToResources, subgroups, tiltfile, unlabeled } = view
 
   // NOTE(nick): We need the visual order of the items to pass
   // to the keyboard navigation component. The problem is that
   // each section component does its own ordering. So we cheat
   // here and replicate the logic for determining the order.
   let totalOrder: SidebarItem[] = []
-  labels.map((label) => {
+  const addGroupOrder = (label: string) => {
     totalOrder.push(...enabledItemsFirst(labelsToResources[label]))
-  })
+    ;(subgroups[label] ?? []).forEach(addGroupOrder)
+  }
+  labels.forEach(addGroupOrder)
   totalOrder.push(...enabledItemsFirst(unlabeled))
   totalOrder.push(...enabledItemsFirst(tiltfile))
 
@@ -392,6 +417,7 @@ function SidebarGroupedByLabels(props: SidebarGroupedByProps) {
           {...props}
           key={`sidebarItem-${label}`}
           label={label}
+          view={view}
           items={labelsToResources[label]}
         />
       ))}
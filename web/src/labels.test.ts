@@ -1,5 +1,12 @@
 import Features, { Flag } from "./feature"
-import { getResourceLabels, resourcesHaveLabels } from "./labels"
+import {
+  getResourceLabels,
+  GROUP_LABEL,
+  groupDisplayName,
+  groupResources,
+  nestLabels,
+  resourcesHaveLabels,
+} from "./labels"
 import { nResourceView, nResourceWithLabelsView } from "./testdata"
 
 describe("Resource label helpers", () => {
@@ -73,6 +80,46 @@ describe("Resource label helpers", () => {
         }
         expect(getResourceLabels(resource)).toEqual(["anotherLabel"])
       })
+
+      it("returns the group path for grouped resources", () => {
+        const resource = nResourceView(1).uiResources[0]
+        resource.metadata!.labels = {
+          [GROUP_LABEL]: "payments.api",
+          anotherLabel: "anotherLabel",
+        }
+        expect(getResourceLabels(resource)).toEqual([
+          "anotherLabel",
+          "payments/api",
+        ])
+      })
+    })
+  })
+
+  describe("nestLabels", () => {
+    it("nests groups under their parents, adding missing parents", () => {
+      const view = nestLabels(
+        {
+          "payments/api": ["api"],
+          "payments/api/db": ["db"],
+          frontend: ["web"],
+        },
+        [],
+        []
+      )
+      expect(view.labels).toEqual(["frontend", "payments"])
+      expect(view.subgroups).toEqual({
+        payments: ["payments/api"],
+        "payments/api": ["payments/api/db"],
+      })
+      expect(view.labelsToResources["payments"]).toEqual([])
+      expect(groupResources(view, "payments")).toEqual(["api", "db"])
+    })
+  })
+
+  describe("groupDisplayName", () => {
+    it("shows nested groups by the last part of their path", () => {
+      expect(groupDisplayName("payments/api")).toEqual("api")
+      expect(groupDisplayName("frontend")).toEqual("frontend")
     })
   })
 })
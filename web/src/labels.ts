@@ -6,9 +6,16 @@ import { UIResource } from "./types"
 export const UNLABELED_LABEL = "unlabeled"
 export const TILTFILE_LABEL = "Tiltfile"
 
+// Set on resources that were put in a group with the Tiltfile `group()`
+// builtin. The value is the group path with dots instead of slashes.
+export const GROUP_LABEL = "tilt.dev/group"
+
 export type GroupByLabelView<T> = {
+  // Top-level labels and groups, in display order
   labels: string[]
   labelsToResources: { [key: string]: T[] }
+  // Nested groups, keyed by the path of their parent group
+  subgroups: { [key: string]: string[] }
   tiltfile: T[]
   unlabeled: T[]
 }
@@ -58,12 +65,21 @@ export function getResourceLabels(resource: UIResource): string[] {
   }
 
   // Return the labels in the form of a list, not a map
-  return Object.keys(labelsMap)
+  const labels = Object.keys(labelsMap)
     .filter((label) => {
       const labelHasPrefix = label.includes("/")
       return !labelHasPrefix
     })
     .map((label) => labelsMap[label])
+
+  // Groups are keyed by their full path (e.g., "payments/api"), which
+  // can't collide with a label value, since those can't contain slashes
+  const group = labelsMap[GROUP_LABEL]
+  if (group) {
+    labels.push(group.replace(/\./g, "/"))
+  }
+
+  return labels
 }
 
 // Order labels alphabetically A - Z
@@ -71,6 +87,79 @@ export function orderLabels(labels: string[]) {
   return [...labels].sort((a, b) => a.localeCompare(b))
 }
 
+// Returns the path of the group that a nested group belongs to,
+// or undefined for labels and top-level groups
+export function parentGroup(label: string): string | undefined {
+  const i = label.lastIndexOf("/")
+  return i === -1 ? undefined : label.slice(0, i)
+}
+
+// Nested groups are displayed by the last part of their path,
+// since they're already shown inside their parent
+export function groupDisplayName(label: string): string {
+  return label.slice(label.lastIndexOf("/") + 1)
+}
+
+// Adds the parent groups of any nested groups, so that
+// "payments/api" also gives "payments"
+export function withParentGroups(labels: string[]): string[] {
+  const result = new Set(labels)
+  labels.forEach((label) => {
+    let parent = parentGroup(label)
+    while (parent !== undefined && !result.has(parent)) {
+      result.add(parent)
+      parent = parentGroup(parent)
+    }
+  })
+  return Array.from(result)
+}
+
+// Builds a label view from resources that have already been sorted
+// into labels, nesting groups under their parents. Parent groups with
+// no resources of their own are added to `labelsToResources` empty.
+export function nestLabels<T>(
+  labelsToResources: { [key: string]: T[] },
+  tiltfile: T[],
+  unlabeled: T[]
+): GroupByLabelView<T> {
+  const labels: string[] = []
+  const subgroups: { [key: string]: string[] } = {}
+
+  // Labels are always displayed in sorted order
+  orderLabels(withParentGroups(Object.keys(labelsToResources))).forEach(
+    (label) => {
+      if (!labelsToResources.hasOwnProperty(label)) {
+        labelsToResources[label] = []
+      }
+
+      const parent = parentGroup(label)
+      if (parent === undefined) {
+        labels.push(label)
+        return
+      }
+      if (!subgroups.hasOwnProperty(parent)) {
+        subgroups[parent] = []
+      }
+      subgroups[parent].push(label)
+    }
+  )
+
+  return { labels, labelsToResources, subgroups, tiltfile, unlabeled }
+}
+
+// Returns the resources in a group and all its nested groups,
+// listing each resource once
+export function groupResources<T>(
+  view: GroupByLabelView<T>,
+  label: string
+): T[] {
+  const result = new Set(view.labelsToResources[label] ?? [])
+  ;(view.subgroups[label] ?? []).forEach((subgroup) => {
+    groupResources(view, subgroup).forEach((r) => result.add(r))
+  })
+  return Array.from(result)
+}
+
 // This helper function takes a template type for the resources
 // and a label accessor function
 export function resourcesHaveLabels<T>(
@@ -3,6 +3,7 @@ import LogStore, { LogAlert, LogAlertIndex } from "./LogStore"
 import {
   buildStatus,
   combinedStatus,
+  LastBuildDescription,
   PendingBuildDescription,
   runtimeStatus,
 } from "./status"
@@ -231,3 +232,27 @@ describe("PendingBuildDescription", () => {
     expect(PendingBuildDescription(hold)).toBe("Update: waiting on 1 object")
   })
 })
+
+describe("LastBuildDescription", () => {
+  it("is empty if the build didn't build any images", () => {
+    expect(LastBuildDescription(null)).toBe("")
+    expect(LastBuildDescription({ totalStages: 0 })).toBe("")
+  })
+
+  it("shows cached stages", () => {
+    expect(LastBuildDescription({ totalStages: 3, cachedStages: 3 })).toBe(
+      "Update: 3 of 3 image steps cached"
+    )
+  })
+
+  it("shows the slowest stage", () => {
+    expect(
+      LastBuildDescription({
+        totalStages: 3,
+        cachedStages: 1,
+        slowestStage: "[2/3] RUN make",
+        slowestStageMillis: "12000",
+      })
+    ).toBe("Update: 1 of 3 image steps cached; slowest: [2/3] RUN make (12s)")
+  })
+})
@@ -1,10 +1,13 @@
+import moment from "moment"
 import { buildWarningCount, runtimeWarningCount } from "./alerts"
 import { Hold } from "./Hold"
 import { LogAlertIndex } from "./LogStore"
 import { resourceIsDisabled } from "./ResourceStatus"
+import { formatBuildDuration } from "./time"
 import {
   ResourceStatus,
   RuntimeStatus,
+  UIBuild,
   UIResource,
   UIResourceStatus,
   UpdateStatus,
@@ -128,4 +131,22 @@ export function PendingBuildDescription(hold?: Hold | null): string {
   return text
 }
 
+// Summarizes how much of a finished build's image builds came from the
+// BuildKit cache, and which stage was slowest.
+export function LastBuildDescription(build?: UIBuild | null): string {
+  const total = build?.totalStages ?? 0
+  if (!build || total === 0) {
+    return ""
+  }
+
+  const cached = build.cachedStages ?? 0
+  const steps = total > 1 ? "steps" : "step"
+  let text = `Update: ${cached} of ${total} image ${steps} cached`
+  if (build.slowestStage) {
+    const d = moment.duration(Number(build.slowestStageMillis ?? 0))
+    text += `; slowest: ${build.slowestStage} (${formatBuildDuration(d)})`
+  }
+  return text
+}
+
 export { buildStatus, runtimeStatus, combinedStatus }
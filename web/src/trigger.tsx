@@ -26,7 +26,8 @@ export function buildButtonTooltip(
   }
 }
 
-export function startBuild(name: string) {
+// If noCache is set, the build skips the build cache.
+export function startBuild(name: string, noCache?: boolean) {
   let url = `/api/trigger`
 
   fetch(url, {
@@ -34,6 +35,7 @@ export function startBuild(name: string) {
     body: JSON.stringify({
       manifest_names: [name],
       build_reason: 16 /* BuildReasonFlagTriggerWeb */,
+      no_cache: !!noCache,
     }),
   }).then((response) => {
     if (!response.ok) {
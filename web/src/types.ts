@@ -157,3 +157,4 @@ export type UIButtonStatus = Proto.v1alpha1UIButtonStatus
 export type UIInputSpec = Proto.v1alpha1UIInputSpec
 export type UIInputStatus = Proto.v1alpha1UIInputStatus
 export type Cluster = Proto.v1alpha1Cluster
+export type DockerPruneRun = Proto.v1alpha1DockerPruneRun
@@ -428,6 +428,22 @@ declare namespace Proto {
     fatalError?: string;
     tiltStartTime?: string;
     tiltfileKey?: string;
+    crashes?: v1alpha1UISessionCrash[];
+    versionWarnings?: string[];
+    releaseHighlights?: string[];
+    idle?: v1alpha1UISessionIdleStatus;
+  }
+  export interface v1alpha1UISessionIdleStatus {
+    shutDownAt?: string;
+    resumeStartTime?: string;
+    resources?: string[];
+    resumedResources?: string[];
+  }
+  export interface v1alpha1UISessionCrash {
+    component?: string;
+    message?: string;
+    time?: string;
+    count?: number;
   }
   export interface v1alpha1UISessionSpec {}
   export interface v1alpha1UISession {
@@ -532,6 +548,9 @@ declare namespace Proto {
      * +optional
      */
     on?: v1alpha1UIResourceStateWaitingOnRef[];
+    message?: string;
+    messageCode?: string;
+    messageArgs?: { [key: string]: string };
   }
   export interface v1alpha1UIResourceSpec {}
   export interface v1alpha1UIResourceLocal {
@@ -566,6 +585,7 @@ declare namespace Proto {
     podRestarts?: number;
     spanID?: string;
     displayNames?: string[];
+    pendingDiff?: string;
   }
   export interface v1alpha1UIResourceCondition {
     /**
@@ -579,6 +599,8 @@ declare namespace Proto {
     lastTransitionTime?: string;
     reason?: string;
     message?: string;
+    messageCode?: string;
+    messageArgs?: { [key: string]: string };
   }
   export interface v1alpha1UIResource {
     metadata?: v1ObjectMeta;
@@ -616,6 +638,7 @@ declare namespace Proto {
   export interface v1alpha1UIFeatureFlag {
     name?: string;
     value?: boolean;
+    source?: string;
   }
   export interface v1alpha1UIComponentLocation {
     /**
@@ -703,10 +726,56 @@ declare namespace Proto {
     finishTime?: string;
     spanID?: string;
     isCrashRebuild?: boolean;
+    /**
+     * The number of image build stages in the images that this build built.
+     * Zero if the build didn't build any images.
+     * +optional
+     */
+    totalStages?: number;
+    /**
+     * The number of image build stages that BuildKit reused from its cache.
+     * +optional
+     */
+    cachedStages?: number;
+    /**
+     * The slowest image build stage that BuildKit didn't reuse from its cache,
+     * as BuildKit names it (e.g., "[2/5] RUN npm install").
+     * +optional
+     */
+    slowestStage?: string;
+    /**
+     * How long the slowest stage took, in milliseconds.
+     * +optional
+     */
+    slowestStageMillis?: string;
   }
   export interface v1alpha1UIBuildRunning {
     startTime?: string;
     spanID?: string;
+    /**
+     * The image build stage in progress, as BuildKit names it
+     * (e.g., "[2/5] RUN npm install"). Empty if no image is building.
+     * +optional
+     */
+    currentStage?: string;
+    /**
+     * The number of image build stages that have finished, including cached stages.
+     * +optional
+     */
+    completedStages?: number;
+    /**
+     * The number of image build stages that BuildKit has reported so far.
+     * +optional
+     */
+    totalStages?: number;
+    /**
+     * How far along the image builds are, from 0 to 100.
+     *
+     * Counts finished stages, plus the bytes done in stages that report
+     * progress, like pulling a base image.
+     * +optional
+     */
+    progress?: number;
   }
   export interface v1alpha1UIBoolInputStatus {
     value?: boolean;
@@ -932,6 +1001,84 @@ declare namespace Proto {
      * +optional
      */
     version?: string;
+    /**
+     * The most recent runs of the Docker pruner against this cluster's
+     * container runtime.
+     *
+     * +optional
+     */
+    dockerPrune?: v1alpha1DockerPruneStatus;
+    /**
+     * Conditions describe the health of the connection, based on
+     * Tilt's periodic checks against the cluster.
+     *
+     * +optional
+     */
+    conditions?: v1Condition[];
+  }
+  export interface v1Condition {
+    type?: string;
+    status?: string;
+    observedGeneration?: string;
+    lastTransitionTime?: string;
+    reason?: string;
+    message?: string;
+  }
+  export interface v1alpha1DockerPruneStatus {
+    /**
+     * Recent prune runs, oldest first.
+     *
+     * Tilt only keeps the last few runs.
+     *
+     * +optional
+     */
+    runs?: v1alpha1DockerPruneRun[];
+  }
+  export interface v1alpha1DockerPruneRun {
+    /**
+     * When the pruner started.
+     */
+    startTime?: string;
+    /**
+     * When the pruner finished.
+     */
+    finishTime?: string;
+    /**
+     * The number of stopped containers removed.
+     *
+     * +optional
+     */
+    containersDeleted?: number;
+    /**
+     * The number of images removed.
+     *
+     * +optional
+     */
+    imagesDeleted?: number;
+    /**
+     * The number of build cache entries removed.
+     *
+     * +optional
+     */
+    cachesDeleted?: number;
+    /**
+     * The number of volumes removed.
+     *
+     * +optional
+     */
+    volumesDeleted?: number;
+    /**
+     * The disk space reclaimed, in bytes.
+     *
+     * +optional
+     */
+    spaceReclaimed?: string;
+    /**
+     * An error that stopped the pruner before it finished.
+     *
+     * +optional
+     */
+    error?: string;
   }
   export interface v1alpha1ClusterSpec {
     /**
@@ -950,6 +1097,60 @@ declare namespace Proto {
      * +optional
      */
     defaultRegistry?: v1alpha1RegistryHosting;
+    /**
+     * BuildKit determines where images for this Cluster should be built.
+     *
+     * If specified, images are built on a remote BuildKit daemon instead of
+     * the local Docker daemon, and BuildKit pushes them to the registry
+     * as part of the build.
+     *
+     * Only supported for Kubernetes clusters.
+     *
+     * +optional
+     */
+    buildKit?: v1alpha1BuildKitConnection;
+  }
+  export interface v1alpha1BuildKitConnection {
+    /**
+     * The address of the BuildKit daemon.
+     *
+     * tcp://host:port and unix:///path/to/buildkitd.sock connect directly.
+     *
+     * kube-pod://pod-name connects to a buildkitd pod through `kubectl exec`,
+     * like the kubernetes driver of `docker buildx`. The namespace, container,
+     * and kubeconfig context can be set as query parameters
+     * (e.g., kube-pod://buildkitd-0?namespace=buildkit). The context defaults
+     * to the Cluster's context.
+     */
+    address?: string;
+    /**
+     * The name to verify the daemon's TLS certificate against.
+     *
+     * If not specified, will use the host in the address.
+     *
+     * +optional
+     */
+    serverName?: string;
+    /**
+     * Path to the CA certificate that signed the daemon's certificate.
+     *
+     * If specified, connects over TLS.
+     *
+     * +optional
+     */
+    caCert?: string;
+    /**
+     * Path to the client certificate, for daemons that require mutual TLS.
+     *
+     * +optional
+     */
+    cert?: string;
+    /**
+     * Path to the client key, for daemons that require mutual TLS.
+     *
+     * +optional
+     */
+    key?: string;
   }
   export interface v1alpha1ClusterConnectionStatus {
     /**